@@ -77,9 +77,11 @@ func runControllerManager(stopCh context.Context) (err error) {
 			KubernetesOptions: conf.KubernetesOptions,
 			JenkinsOptions:    conf.JenkinsOptions,
 			S3Options:         conf.S3Options,
+			HistoryOptions:    conf.HistoryOptions,
 			LeaderElection:    s.LeaderElection,
 			LeaderElect:       s.LeaderElect,
 			WebhookCertDir:    s.WebhookCertDir,
+			Mode:              s.Mode,
 		}
 	} else {
 		klog.Fatal("Failed to load configuration from disk", err)
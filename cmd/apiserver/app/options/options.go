@@ -38,6 +38,8 @@ import (
 	"net/http"
 	"strings"
 
+	"kubesphere.io/devops/pkg/client/history"
+	fakehistory "kubesphere.io/devops/pkg/client/history/fake"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/s3"
 	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
@@ -68,6 +70,7 @@ func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.JenkinsOptions.AddFlags(fss.FlagSet("devops"), s.JenkinsOptions)
 	s.SonarQubeOptions.AddFlags(fss.FlagSet("sonarqube"), s.SonarQubeOptions)
 	s.S3Options.AddFlags(fss.FlagSet("s3"), s.S3Options)
+	s.HistoryOptions.AddFlags(fss.FlagSet("history"), s.HistoryOptions)
 	s.ArgoCDOption.AddFlags(fss.FlagSet("argocd"))
 	s.FluxCDOption.AddFlags(fss.FlagSet("fluxcd"))
 
@@ -112,6 +115,18 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		}
 	}
 
+	if s.HistoryOptions.Enabled() {
+		if s.HistoryOptions.DSN == fakeInterface && s.DebugMode {
+			apiServer.HistoryClient = fakehistory.NewFakeHistory()
+		} else {
+			historyClient, err := history.NewHistoryClient(s.HistoryOptions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to the history database, error: %v", err)
+			}
+			apiServer.HistoryClient = historyClient
+		}
+	}
+
 	if !s.JenkinsOptions.SkipVerify && s.JenkinsOptions.Host != "" {
 		devopsClient, err := jclient.NewJenkinsClient(s.JenkinsOptions)
 		if err != nil {
@@ -160,6 +175,9 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		}
 		server.Addr = fmt.Sprintf(":%d", s.GenericServerRunOptions.SecurePort)
 	}
+	if s.GenericServerRunOptions.GRPCPort != 0 {
+		apiServer.GRPCAddr = fmt.Sprintf(":%d", s.GenericServerRunOptions.GRPCPort)
+	}
 
 	sch := scheme.Scheme
 	_ = v1.SchemeBuilder.AddToScheme(sch)
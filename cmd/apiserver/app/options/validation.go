@@ -26,6 +26,7 @@ func (s *ServerRunOptions) Validate() []error {
 	errors = append(errors, s.KubernetesOptions.Validate()...)
 	errors = append(errors, s.SonarQubeOptions.Validate()...)
 	errors = append(errors, s.S3Options.Validate()...)
+	errors = append(errors, s.HistoryOptions.Validate()...)
 
 	return errors
 }
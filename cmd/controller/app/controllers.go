@@ -18,82 +18,226 @@ package app
 
 import (
 	"kubesphere.io/devops/controllers/addon"
+	"kubesphere.io/devops/controllers/agentnetwork"
+	"kubesphere.io/devops/controllers/agentusage"
+	"kubesphere.io/devops/controllers/approval"
 	"kubesphere.io/devops/controllers/argocd"
+	"kubesphere.io/devops/controllers/argoworkflows"
+	"kubesphere.io/devops/controllers/backup"
+	"kubesphere.io/devops/controllers/buildcache"
+	"kubesphere.io/devops/controllers/bulkoperation"
+	"kubesphere.io/devops/controllers/chatops"
+	ctrlcore "kubesphere.io/devops/controllers/core"
+	"kubesphere.io/devops/controllers/cost"
+	"kubesphere.io/devops/controllers/deploycredential"
+	"kubesphere.io/devops/controllers/ephemeralnamespace"
 	"kubesphere.io/devops/controllers/fluxcd"
 	"kubesphere.io/devops/controllers/gitrepository"
+	historycontroller "kubesphere.io/devops/controllers/history"
+	"kubesphere.io/devops/controllers/jenkins/agentpreset"
 	"kubesphere.io/devops/controllers/jenkins/devopscredential"
 	"kubesphere.io/devops/controllers/jenkins/devopsproject"
+	"kubesphere.io/devops/controllers/jenkins/jenkinsstatus"
+	"kubesphere.io/devops/controllers/jenkinsfilerecord"
+	"kubesphere.io/devops/controllers/jira"
+	"kubesphere.io/devops/controllers/ldapgroup"
+	"kubesphere.io/devops/controllers/licensescan"
+	"kubesphere.io/devops/controllers/logarchive"
+	"kubesphere.io/devops/controllers/matrix"
+	"kubesphere.io/devops/controllers/ownership"
+	"kubesphere.io/devops/controllers/packagemanager"
+	"kubesphere.io/devops/controllers/pipelinesource"
+	"kubesphere.io/devops/controllers/provenance"
+	"kubesphere.io/devops/controllers/release"
+	"kubesphere.io/devops/controllers/runevents"
+	"kubesphere.io/devops/controllers/secretscan"
+	"kubesphere.io/devops/controllers/sharedresource"
+	"kubesphere.io/devops/controllers/ttl"
+	"kubesphere.io/devops/controllers/workspace"
 	"kubesphere.io/devops/pkg/jwt/token"
 	"kubesphere.io/devops/pkg/server/errors"
 
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/plugin"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/featuregate"
 	"k8s.io/klog/v2"
 	"kubesphere.io/devops/cmd/controller/app/options"
 	"kubesphere.io/devops/controllers/jenkins/config"
 	jenkinspipeline "kubesphere.io/devops/controllers/jenkins/pipeline"
 	"kubesphere.io/devops/controllers/jenkins/pipelinerun"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/client/history"
 	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/client/ldap"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/slack"
+	"kubesphere.io/devops/pkg/client/smtp"
+	"kubesphere.io/devops/pkg/features"
 	"kubesphere.io/devops/pkg/informers"
+	"kubesphere.io/devops/pkg/models/argoworkflow"
+	chatopsmodel "kubesphere.io/devops/pkg/models/chatops"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func addControllers(mgr manager.Manager, client k8s.Client, informerFactory informers.InformerFactory,
 	devopsClient devops.Interface, jenkinsCore core.JenkinsCore,
 	s *options.DevOpsControllerManagerOptions) error {
-	if devopsClient == nil {
+	if s.RunControllers() && devopsClient == nil {
 		return errors.New("devopsClient should not be nil")
 	}
 
 	reconcilers := getAllControllers(mgr, client, informerFactory, devopsClient, s, jenkinsCore)
-	reconcilers["pipeline"] = func(mgr manager.Manager) (err error) {
-		tokenIssuer := token.NewTokenIssuer(s.JWTOptions.Secret, s.JWTOptions.MaximumClockSkew)
-		// add PipelineRun controller
-		if err = (&pipelinerun.Reconciler{
+	tokenIssuer := token.NewTokenIssuer(s.JWTOptions.Secret, s.JWTOptions.MaximumClockSkew)
+	reconcilers["pipelinerun"] = func(mgr manager.Manager) error {
+		return (&pipelinerun.Reconciler{
 			Client:               mgr.GetClient(),
 			Scheme:               mgr.GetScheme(),
 			DevOpsClient:         devopsClient,
 			JenkinsCore:          jenkinsCore,
 			TokenIssuer:          tokenIssuer,
 			PipelineRunDataStore: s.FeatureOptions.PipelineRunDataStore,
-		}).SetupWithManager(mgr); err != nil {
-			klog.Errorf("unable to create pipelinerun-controller, err: %v", err)
-			return
-		}
-
-		// add PipelineRun Synchronizer
-		if err = (&pipelinerun.SyncReconciler{
+			SyncPeriod:           s.FeatureOptions.PipelineRunSyncPeriod,
+			IdleSyncPeriod:       s.FeatureOptions.PipelineRunIdleSyncPeriod,
+			ExecutorCapacity:     s.FeatureOptions.JenkinsExecutorCapacity,
+			MaxQueueLength:       s.FeatureOptions.JenkinsMaxQueueLength,
+		}).SetupWithManager(mgr)
+	}
+	reconcilers["pipelinerunsync"] = func(mgr manager.Manager) error {
+		return (&pipelinerun.SyncReconciler{
 			Client:      mgr.GetClient(),
 			JenkinsCore: jenkinsCore,
-		}).SetupWithManager(mgr); err != nil {
-			klog.Errorf("unable to create pipelinerun-synchronizer, err: %v", err)
-			return
-		}
-
-		// add Pipeline metadata controller
-		err = (&jenkinspipeline.Reconciler{
+		}).SetupWithManager(mgr)
+	}
+	reconcilers["pipelinerunaudit"] = func(mgr manager.Manager) error {
+		return (&pipelinerun.Auditor{
+			Client:      mgr.GetClient(),
+			JenkinsCore: jenkinsCore,
+			Policy:      s.FeatureOptions.PipelineRunAuditPolicy,
+		}).SetupWithManager(mgr)
+	}
+	reconcilers["pipelinemetadata"] = func(mgr manager.Manager) error {
+		return (&jenkinspipeline.Reconciler{
 			Client:      mgr.GetClient(),
 			JenkinsCore: jenkinsCore,
 		}).SetupWithManager(mgr)
-		return
+	}
+
+	for _, item := range s.FeatureOptions.SelectedControllers {
+		name := strings.TrimPrefix(item, "-")
+		if _, isGroup := controllerGroups[name]; name != "*" && !isGroup && reconcilers[name] == nil {
+			return fmt.Errorf("unknown controller: %s", name)
+		}
+	}
+
+	if s.RunControllers() {
+		ctrlcore.DefaultStuckDetector.Threshold = s.FeatureOptions.StuckThreshold
+		ctrlcore.DefaultStuckDetector.Recorder = mgr.GetEventRecorderFor("stuck-detector")
+		if err := mgr.Add(ctrlcore.DefaultStuckDetector); err != nil {
+			return err
+		}
+	}
+
+	// the controllers are not gated by the plugins if Jenkins is unreachable on startup
+	var plugins []v1alpha3.PluginStatus
+	if s.RunControllers() && !s.Demo {
+		var err error
+		if plugins, err = jenkinsstatus.Preflight(context.Background(), mgr.GetAPIReader(),
+			&plugin.Manager{JenkinsCore: jenkinsCore}); err != nil {
+			klog.Warningf("skip the preflight check of Jenkins plugins, error: %v", err)
+		}
 	}
 
 	// Add all controllers into manager.
-	for name, ok := range s.FeatureOptions.GetControllers() {
+	for name, ok := range s.FeatureOptions.ResolveControllers(controllerGroups) {
 		ctrl := reconcilers[name]
 		if ctrl == nil || !ok {
 			klog.V(4).Infof("%s is not going to run due to dependent component disabled.", name)
 			continue
 		}
+		if webhookControllers[name] && !s.RunWebhooks() || !webhookControllers[name] && !s.RunControllers() {
+			klog.Infof("%s is not going to run in the %s mode.", name, s.Mode)
+			continue
+		}
+		if s.Demo && jenkinsControllers[name] {
+			klog.Infof("%s is not going to run in the demo mode.", name)
+			continue
+		}
+		if gate, gated := controllerGates[name]; gated && !features.Enabled(gate) {
+			klog.Infof("%s is not going to run due to the feature gate %s disabled.", name, gate)
+			continue
+		}
+		if notReady := jenkinsstatus.NotReady(plugins, controllerPlugins[name]); len(notReady) > 0 {
+			klog.Warningf("%s is not going to run due to the Jenkins plugins %v not ready.", name, notReady)
+			continue
+		}
 
 		if err := ctrl(mgr); err != nil {
-			klog.Error(err, "add controller to manager failed ", name)
+			klog.Errorf("unable to create %s controller, err: %v", name, err)
 			return err
 		}
 	}
 	return nil
 }
 
+// controllerGroups maps the names of the controller groups to the controllers they consist of,
+// it keeps the names of --enabled-controllers working while each controller can be selected by --controllers
+var controllerGroups = map[string][]string{
+	"pipeline": {"pipelinerun", "pipelinerunsync", "pipelinerunaudit", "pipelinemetadata", "pipelinerunttl"},
+	"jenkins": {"credential", "devopsproject", "jenkinspipeline", "jenkinsfile", "agentlabels", "pipelinedrift",
+		"jenkinsstatus"},
+}
+
+// webhookControllers are the admission webhooks, they are the only ones running in the webhook-only mode
+var webhookControllers = map[string]bool{
+	"credentialwebhook":    true,
+	"agentpresetwebhook":   true,
+	"agentimagewebhook":    true,
+	"agentsecuritywebhook": true,
+}
+
+// jenkinsControllers talk to Jenkins directly instead of through the DevOps client, they don't run in the demo mode
+var jenkinsControllers = map[string]bool{
+	"pipelinerun":      true,
+	"pipelinerunsync":  true,
+	"pipelinerunaudit": true,
+	"pipelinemetadata": true,
+	"jenkinsfile":      true,
+	"agentlabels":      true,
+	"jenkinsstatus":    true,
+}
+
+// controllerGates maps the controllers to the feature gates which they depend on
+var controllerGates = map[string]featuregate.Feature{
+	"argocd":               features.GitOps,
+	"argocd-image-updater": features.GitOps,
+	"fluxcd":               features.GitOps,
+	"argoworkflows":        features.ArgoWorkflows,
+	"chatops":              features.Notifications,
+	"pipelinepost":         features.Notifications,
+	"approvalmail":         features.Notifications,
+	"approvalslack":        features.Notifications,
+	"pipelinesource":       features.PipelineSource,
+}
+
+// controllerPlugins maps the controllers to the Jenkins plugins which they depend on, see jenkinsstatus.DefaultRequirements
+var controllerPlugins = map[string][]string{
+	"pipelinerun":      {"workflow-aggregator"},
+	"pipelinerunsync":  {"workflow-aggregator"},
+	"pipelinerunaudit": {"workflow-aggregator"},
+	"pipelinemetadata": {"workflow-aggregator"},
+	"jenkinspipeline":  {"workflow-aggregator"},
+	"jenkinsfile":      {"pipeline-model-definition"},
+	"jenkinsagent":     {"kubernetes"},
+	"jenkinsconfig":    {"kubernetes", "configuration-as-code"},
+}
+
 func getAllControllers(mgr manager.Manager, client k8s.Client, informerFactory informers.InformerFactory,
 	devopsClient devops.Interface, s *options.DevOpsControllerManagerOptions, jenkinsCore core.JenkinsCore) map[string]func(mgr manager.Manager) error {
 
@@ -166,6 +310,298 @@ func getAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 			}
 			return err
 		},
+		"argoworkflows": func(mgr manager.Manager) error {
+			return (&argoworkflows.Reconciler{
+				Client: mgr.GetClient(),
+				Options: argoworkflow.Options{
+					ServiceAccountName: s.FeatureOptions.ArgoWorkflowsServiceAccount,
+				},
+			}).SetupWithManager(mgr)
+		},
+		"matrix": func(mgr manager.Manager) error {
+			return (&matrix.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"ephemeralnamespace": func(mgr manager.Manager) error {
+			return (&ephemeralnamespace.Reconciler{
+				Client:      mgr.GetClient(),
+				TokenClient: client.Kubernetes().CoreV1(),
+			}).SetupWithManager(mgr)
+		},
+		"agentnetwork": func(mgr manager.Manager) error {
+			return (&agentnetwork.Reconciler{
+				Client:           mgr.GetClient(),
+				WorkerNamespace:  s.JenkinsOptions.WorkerNamespace,
+				JenkinsNamespace: s.FeatureOptions.SystemNamespace,
+			}).SetupWithManager(mgr)
+		},
+		"packagemanager": func(mgr manager.Manager) error {
+			return (&packagemanager.Reconciler{
+				Client:          mgr.GetClient(),
+				WorkerNamespace: s.JenkinsOptions.WorkerNamespace,
+			}).SetupWithManager(mgr)
+		},
+		"buildcache": func(mgr manager.Manager) error {
+			return (&buildcache.Reconciler{
+				Client:          mgr.GetClient(),
+				WorkerNamespace: s.JenkinsOptions.WorkerNamespace,
+			}).SetupWithManager(mgr)
+		},
+		"buildcachemetrics": func(mgr manager.Manager) error {
+			return (&buildcache.MetricsReconciler{
+				Client:       mgr.GetClient(),
+				DevOpsClient: devopsClient,
+			}).SetupWithManager(mgr)
+		},
+		"deploycredential": func(mgr manager.Manager) error {
+			return (&deploycredential.Reconciler{
+				Client:      mgr.GetClient(),
+				TokenClient: client.Kubernetes().CoreV1(),
+			}).SetupWithManager(mgr)
+		},
+		"sharedresource": func(mgr manager.Manager) error {
+			return (&sharedresource.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"chatops": func(mgr manager.Manager) error {
+			return (&chatops.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"pipelinepost": func(mgr manager.Manager) error {
+			return (&chatops.PostReconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"provenance": func(mgr manager.Manager) error {
+			reconciler := &provenance.Reconciler{
+				Client:       mgr.GetClient(),
+				DevOpsClient: devopsClient,
+				BuilderID:    s.FeatureOptions.ProvenanceBuilderID,
+				RekorURL:     s.FeatureOptions.ProvenanceRekorURL,
+			}
+			if s.FeatureOptions.ProvenanceSigningKey != "" {
+				reconciler.SigningKey = types.NamespacedName{
+					Namespace: s.FeatureOptions.SystemNamespace,
+					Name:      s.FeatureOptions.ProvenanceSigningKey,
+				}
+			}
+			return reconciler.SetupWithManager(mgr)
+		},
+		"jenkinsfilerecord": func(mgr manager.Manager) error {
+			return (&jenkinsfilerecord.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"runevents": func(mgr manager.Manager) error {
+			return (&runevents.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"licensescan": func(mgr manager.Manager) error {
+			return (&licensescan.Reconciler{
+				Client:       mgr.GetClient(),
+				DevOpsClient: devopsClient,
+			}).SetupWithManager(mgr)
+		},
+		"secretscan": func(mgr manager.Manager) error {
+			return (&secretscan.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"release": func(mgr manager.Manager) error {
+			return (&release.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"jira": func(mgr manager.Manager) error {
+			return (&jira.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"cost": func(mgr manager.Manager) error {
+			reconciler := &cost.Reconciler{
+				Client: mgr.GetClient(),
+			}
+			if s.FeatureOptions.CostPriceTable != "" {
+				reconciler.PriceTable = types.NamespacedName{
+					Namespace: s.FeatureOptions.SystemNamespace,
+					Name:      s.FeatureOptions.CostPriceTable,
+				}
+			}
+			return reconciler.SetupWithManager(mgr)
+		},
+		"agentusage": func(mgr manager.Manager) error {
+			return (&agentusage.Reconciler{
+				Client:       mgr.GetClient(),
+				SamplePeriod: s.FeatureOptions.AgentUsageSamplePeriod,
+			}).SetupWithManager(mgr)
+		},
+		"workspace": func(mgr manager.Manager) error {
+			return (&workspace.Reconciler{
+				Client:      mgr.GetClient(),
+				RoleMapping: s.FeatureOptions.WorkspaceRoleMapping,
+			}).SetupWithManager(mgr)
+		},
+		"backup": func(mgr manager.Manager) error {
+			if s.S3Options == nil || s.S3Options.Endpoint == "" {
+				return errors.New("the s3 configuration is required by the backup controller")
+			}
+			storage, err := s3.NewS3Client(s.S3Options)
+			if err != nil {
+				return err
+			}
+			return (&backup.Reconciler{
+				Client:  mgr.GetClient(),
+				Storage: storage,
+			}).SetupWithManager(mgr)
+		},
+		"logarchive": func(mgr manager.Manager) error {
+			if s.S3Options == nil || s.S3Options.Endpoint == "" {
+				return errors.New("the s3 configuration is required by the logarchive controller")
+			}
+			storage, err := s3.NewS3Client(s.S3Options)
+			if err != nil {
+				return err
+			}
+			return (&logarchive.Reconciler{
+				Client:       mgr.GetClient(),
+				DevOpsClient: devopsClient,
+				Storage:      storage,
+				Compression:  s.FeatureOptions.LogArchiveCompression,
+				SyncPeriod:   s.FeatureOptions.LogArchiveSyncPeriod,
+			}).SetupWithManager(mgr)
+		},
+		"history": func(mgr manager.Manager) error {
+			if !s.HistoryOptions.Enabled() {
+				return errors.New("the history configuration is required by the history controller")
+			}
+			store, err := history.NewHistoryClient(s.HistoryOptions)
+			if err != nil {
+				return err
+			}
+			return (&historycontroller.Reconciler{
+				Client:    mgr.GetClient(),
+				Store:     store,
+				Retention: s.HistoryOptions.Retention,
+			}).SetupWithManager(mgr)
+		},
+		"pipelinerunttl": func(mgr manager.Manager) error {
+			return (&ttl.Reconciler{
+				Client:         mgr.GetClient(),
+				WaitForArchive: s.HistoryOptions.Enabled(),
+			}).SetupWithManager(mgr)
+		},
+		"bulkoperation": func(mgr manager.Manager) error {
+			return (&bulkoperation.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"pipelinesource": func(mgr manager.Manager) error {
+			return (&pipelinesource.Reconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
+		"ldapgroup": func(mgr manager.Manager) error {
+			if !s.LDAPOptions.Enabled() {
+				return errors.New("the ldap configuration is required by the ldapgroup controller")
+			}
+			directory, err := ldap.NewLDAPClient(s.LDAPOptions)
+			if err != nil {
+				return err
+			}
+			return (&ldapgroup.Reconciler{
+				Client:     mgr.GetClient(),
+				LDAP:       directory,
+				SyncPeriod: s.LDAPOptions.SyncPeriod,
+			}).SetupWithManager(mgr)
+		},
+		"pipelineownership": func(mgr manager.Manager) error {
+			if !s.LDAPOptions.Enabled() {
+				return errors.New("the ldap configuration is required by the pipelineownership controller")
+			}
+			directory, err := ldap.NewLDAPClient(s.LDAPOptions)
+			if err != nil {
+				return err
+			}
+			return (&ownership.Reconciler{
+				Client:     mgr.GetClient(),
+				LDAP:       directory,
+				SyncPeriod: s.LDAPOptions.SyncPeriod,
+			}).SetupWithManager(mgr)
+		},
+		"approvalmail": func(mgr manager.Manager) error {
+			if !s.SMTPOptions.Enabled() {
+				return errors.New("the smtp configuration is required by the approvalmail controller")
+			}
+			mail, err := smtp.NewSMTPClient(s.SMTPOptions)
+			if err != nil {
+				return err
+			}
+			return (&approval.MailReconciler{
+				Client:          mgr.GetClient(),
+				Mail:            mail,
+				TokenIssuer:     tokenIssuer,
+				ExternalURL:     s.SMTPOptions.ExternalURL,
+				TokenExpiration: s.SMTPOptions.TokenExpiration,
+			}).SetupWithManager(mgr)
+		},
+		"approvalslack": func(mgr manager.Manager) error {
+			// the tokens are kept along with the signing secret of the Slack slash commands
+			botToken, err := chatopsmodel.GetSecret(context.Background(), mgr.GetAPIReader(), chatopsmodel.SecretKeySlackBotToken)
+			if err != nil {
+				return err
+			}
+			appToken, err := chatopsmodel.GetSecret(context.Background(), mgr.GetAPIReader(), chatopsmodel.SecretKeySlackAppToken)
+			if err != nil {
+				return err
+			}
+			slackClient := slack.New(slack.DefaultServer, botToken, appToken)
+			if err = (&approval.SlackReconciler{
+				Client: mgr.GetClient(),
+				Slack:  slackClient,
+			}).SetupWithManager(mgr); err != nil {
+				return err
+			}
+			return (&approval.SlackListener{
+				Client:       mgr.GetClient(),
+				Slack:        slackClient,
+				DevOpsClient: devopsClient,
+			}).SetupWithManager(mgr)
+		},
+		"credentialwebhook": func(mgr manager.Manager) error {
+			return (&devopscredential.Validator{}).SetupWithManager(mgr)
+		},
+		"agentpresetwebhook": func(mgr manager.Manager) error {
+			defaulter := &agentpreset.Defaulter{Client: mgr.GetClient()}
+			if s.FeatureOptions.AgentSecurityBaseline != "" {
+				defaulter.SecurityBaseline = types.NamespacedName{
+					Namespace: s.FeatureOptions.SystemNamespace,
+					Name:      s.FeatureOptions.AgentSecurityBaseline,
+				}
+			}
+			return defaulter.SetupWithManager(mgr)
+		},
+		"agentsecuritywebhook": func(mgr manager.Manager) error {
+			return (&agentpreset.ExemptionValidator{Client: mgr.GetClient()}).SetupWithManager(mgr)
+		},
+		"agentimagewebhook": func(mgr manager.Manager) error {
+			validator := &agentpreset.ImageValidator{Client: mgr.GetClient()}
+			if s.FeatureOptions.AgentImagePolicy != "" {
+				validator.ClusterPolicy = types.NamespacedName{
+					Namespace: s.FeatureOptions.SystemNamespace,
+					Name:      s.FeatureOptions.AgentImagePolicy,
+				}
+			}
+			return validator.SetupWithManager(mgr)
+		},
+		"credentialusage": func(mgr manager.Manager) error {
+			return (&devopscredential.UsageReconciler{
+				Client: mgr.GetClient(),
+			}).SetupWithManager(mgr)
+		},
 		"jenkinsagent": func(mgr manager.Manager) error {
 			return jenkinsPodTemplate.SetupWithManager(mgr)
 		},
@@ -183,36 +619,58 @@ func getAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 				ReloadCasCDelay: s.JenkinsOptions.ReloadCasCDelay,
 			}, s.JenkinsOptions))
 		},
-		"jenkins": func(mgr manager.Manager) error {
-			err := mgr.Add(devopscredential.NewController(client.Kubernetes(),
+		"credential": func(mgr manager.Manager) error {
+			return mgr.Add(devopscredential.NewController(client.Kubernetes(),
 				devopsClient,
 				informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
-				informerFactory.KubernetesSharedInformerFactory().Core().V1().Secrets()))
-			if err == nil {
-				err = mgr.Add(devopsproject.NewController(client.Kubernetes(),
-					client.KubeSphere(), devopsClient,
-					informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
-					informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().DevOpsProjects()))
-			}
-			if err == nil {
-				err = mgr.Add(jenkinspipeline.NewController(client.Kubernetes(),
-					client.KubeSphere(), devopsClient,
-					informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
-					informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().Pipelines()))
-			}
-
-			if err == nil {
-				jenkinsfileReconciler := &jenkinspipeline.JenkinsfileReconciler{
-					Client:      mgr.GetClient(),
-					TokenIssuer: tokenIssuer,
-					JenkinsCore: jenkinsCore,
-				}
-				err = jenkinsfileReconciler.SetupWithManager(mgr)
+				informerFactory.KubernetesSharedInformerFactory().Core().V1().Secrets()).
+				WithExternalSecretReader(mgr.GetAPIReader()))
+		},
+		"devopsproject": func(mgr manager.Manager) error {
+			folderNamer, err := jenkins.NewFolderNamer(s.JenkinsOptions)
+			if err != nil {
+				return err
 			}
-			if err == nil {
-				err = jenkinsAgentLabelsReconciler.SetupWithManager(mgr)
+			return mgr.Add(devopsproject.NewController(client.Kubernetes(),
+				client.KubeSphere(), devopsClient,
+				informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
+				informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().DevOpsProjects()).
+				WithFolderNamer(folderNamer))
+		},
+		"jenkinspipeline": func(mgr manager.Manager) error {
+			return mgr.Add(jenkinspipeline.NewController(client.Kubernetes(),
+				client.KubeSphere(), devopsClient,
+				informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
+				informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().Pipelines()))
+		},
+		"jenkinsfile": func(mgr manager.Manager) error {
+			return (&jenkinspipeline.JenkinsfileReconciler{
+				Client:      mgr.GetClient(),
+				TokenIssuer: tokenIssuer,
+				JenkinsCore: jenkinsCore,
+			}).SetupWithManager(mgr)
+		},
+		"agentlabels": func(mgr manager.Manager) error {
+			return jenkinsAgentLabelsReconciler.SetupWithManager(mgr)
+		},
+		"jenkinsstatus": func(mgr manager.Manager) error {
+			return (&jenkinsstatus.Reconciler{
+				Client:      mgr.GetClient(),
+				Plugins:     &plugin.Manager{JenkinsCore: jenkinsCore},
+				CheckPeriod: s.FeatureOptions.JenkinsPluginCheckPeriod,
+			}).SetupWithManager(mgr)
+		},
+		"pipelinedrift": func(mgr manager.Manager) error {
+			if s.FeatureOptions.PipelineDriftCheckInterval <= 0 {
+				klog.Info("pipelinedrift controller is disabled due to a non-positive check interval")
+				return nil
 			}
-			return err
+			return (&jenkinspipeline.DriftReconciler{
+				Client:        mgr.GetClient(),
+				DevOpsClient:  devopsClient,
+				Interval:      s.FeatureOptions.PipelineDriftCheckInterval,
+				DefaultPolicy: s.FeatureOptions.PipelineDriftPolicy,
+			}).SetupWithManager(mgr)
 		},
 		argocdReconciler.GetGroupName(): func(mgr manager.Manager) (err error) {
 			if err = argocdReconciler.SetupWithManager(mgr); err != nil {
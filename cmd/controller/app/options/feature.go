@@ -17,20 +17,78 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	cliflag "k8s.io/component-base/cli/flag"
+	"kubesphere.io/devops/controllers/agentusage"
+	"kubesphere.io/devops/controllers/core"
+	"kubesphere.io/devops/controllers/jenkins/pipelinerun"
+	"kubesphere.io/devops/controllers/workspace"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/features"
+	"kubesphere.io/devops/pkg/models/agentsecurity"
+	"kubesphere.io/devops/pkg/models/cost"
+	"kubesphere.io/devops/pkg/models/imagepolicy"
+	"kubesphere.io/devops/pkg/models/logarchive"
+	"kubesphere.io/devops/pkg/models/provenance"
 	"kubesphere.io/devops/pkg/utils/reflectutils"
 )
 
 // FeatureOptions provide some feature options, such as specifying the controller to be enabled.
 type FeatureOptions struct {
-	Controllers          map[string]bool
+	Controllers map[string]bool
+	// SelectedControllers is a list of controllers to enable or disable, such as '*,-credential'.
+	// It overrides Controllers if it is not empty
+	SelectedControllers  []string
 	SystemNamespace      string
 	ExternalAddress      string
 	ClusterName          string
 	PipelineRunDataStore string
+	// PipelineDriftCheckInterval is the interval of checking if the Jenkins jobs were modified out of the Pipelines
+	PipelineDriftCheckInterval time.Duration
+	// PipelineDriftPolicy is the default policy when a Jenkins job drifted from its Pipeline
+	PipelineDriftPolicy string
+	// PipelineRunSyncPeriod is the period of polling Jenkins for a running PipelineRun
+	PipelineRunSyncPeriod time.Duration
+	// PipelineRunIdleSyncPeriod is the period of polling Jenkins for a queued or paused PipelineRun
+	PipelineRunIdleSyncPeriod time.Duration
+	// PipelineRunAuditPolicy is report or repair, it decides how to handle the inconsistencies between the PipelineRuns
+	// and the Jenkins builds which are found after the controller starts
+	PipelineRunAuditPolicy string
+	// JenkinsExecutorCapacity is the maximum number of unfinished PipelineRuns in Jenkins, it's unlimited if it's zero
+	JenkinsExecutorCapacity int
+	// JenkinsMaxQueueLength is the length of Jenkins queue which holds the pending PipelineRuns, it's unlimited if it's zero
+	JenkinsMaxQueueLength int
+	// ArgoWorkflowsServiceAccount is the service account to run the Argo Workflows of PipelineRuns
+	ArgoWorkflowsServiceAccount string
+	// ProvenanceBuilderID is the builder identity in the SLSA provenance of PipelineRuns
+	ProvenanceBuilderID string
+	// ProvenanceSigningKey is the name of Secret in the system namespace which contains the key to sign the provenance
+	ProvenanceSigningKey string
+	// ProvenanceRekorURL is the address of Rekor which the signed provenance is uploaded to
+	ProvenanceRekorURL string
+	// CostPriceTable is the name of ConfigMap in the system namespace which contains the price table of agent pods
+	CostPriceTable string
+	// AgentImagePolicy is the name of ConfigMap in the system namespace which contains the cluster-wide policy of agent images
+	AgentImagePolicy string
+	// AgentSecurityBaseline is the name of ConfigMap in the system namespace which contains the security baseline of agent pods
+	AgentSecurityBaseline string
+	// AgentUsageSamplePeriod is the period of sampling the resource usage of the running agent pods
+	AgentUsageSamplePeriod time.Duration
+	// WorkspaceRoleMapping maps the roles of KubeSphere workspace to the Roles of DevOpsProject
+	WorkspaceRoleMapping map[string]string
+	// StuckThreshold is the duration of keeping failing before a resource is reported as stuck, it's disabled if it's zero
+	StuckThreshold time.Duration
+	// LogArchiveSyncPeriod is the period of archiving the log of a running PipelineRun
+	LogArchiveSyncPeriod time.Duration
+	// LogArchiveCompression is how the chunks of the archived logs are compressed
+	LogArchiveCompression string
+	// JenkinsPluginCheckPeriod is the period of checking if the required plugins are installed in Jenkins
+	JenkinsPluginCheckPeriod time.Duration
 }
 
 // GetControllers returns the controllers map
@@ -59,14 +117,103 @@ func (o *FeatureOptions) GetControllers() map[string]bool {
 	return defaultMap
 }
 
+// ResolveControllers returns the controllers to enable or disable by the selection from users.
+// The groups map a name to the controllers it consists of, selecting a group selects all of its members.
+// Without any selection, all the controllers of GetControllers are selected. Otherwise, '*' stands for the controllers
+// of GetControllers, 'foo' enables the controller foo, and '-foo' disables it.
+// The '*' is applied before the other items, and the latter items take precedence.
+func (o *FeatureOptions) ResolveControllers(groups map[string][]string) map[string]bool {
+	resolved := map[string]bool{}
+	set := func(name string, enabled bool) {
+		if members, ok := groups[name]; ok {
+			for _, member := range members {
+				resolved[member] = enabled
+			}
+			return
+		}
+		resolved[name] = enabled
+	}
+	setDefaults := func() {
+		for name, enabled := range o.GetControllers() {
+			set(name, enabled)
+		}
+	}
+
+	if len(o.SelectedControllers) == 0 {
+		setDefaults()
+		return resolved
+	}
+	for _, item := range o.SelectedControllers {
+		if item == "*" {
+			setDefaults()
+			break
+		}
+	}
+	for _, item := range o.SelectedControllers {
+		switch {
+		case item == "*":
+		case strings.HasPrefix(item, "-"):
+			set(strings.TrimPrefix(item, "-"), false)
+		default:
+			set(item, true)
+		}
+	}
+	return resolved
+}
+
 // NewFeatureOptions provide default options
 func NewFeatureOptions() *FeatureOptions {
 	return &FeatureOptions{}
 }
 
 // Validate checks validation of FeatureOptions.
-func (o *FeatureOptions) Validate() []error {
-	return []error{}
+func (o *FeatureOptions) Validate() (errs []error) {
+	errs = []error{}
+	switch o.PipelineDriftPolicy {
+	case "", v1alpha3.PipelineDriftPolicyReport, v1alpha3.PipelineDriftPolicyRepair, v1alpha3.PipelineDriftPolicyIgnore:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported pipeline drift policy: %q, should be %s, %s or %s",
+			o.PipelineDriftPolicy, v1alpha3.PipelineDriftPolicyReport, v1alpha3.PipelineDriftPolicyRepair,
+			v1alpha3.PipelineDriftPolicyIgnore))
+	}
+	for _, item := range o.SelectedControllers {
+		if name := strings.TrimPrefix(item, "-"); name == "" || name == "*" && item != "*" {
+			errs = append(errs, fmt.Errorf("invalid controller selection: %q", item))
+		}
+	}
+	if o.PipelineRunSyncPeriod < 0 || o.PipelineRunIdleSyncPeriod < 0 {
+		errs = append(errs, fmt.Errorf("the sync period of PipelineRun cannot be negative"))
+	}
+	switch o.PipelineRunAuditPolicy {
+	case "", pipelinerun.AuditPolicyReport, pipelinerun.AuditPolicyRepair:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported PipelineRun audit policy: %q, should be %s or %s",
+			o.PipelineRunAuditPolicy, pipelinerun.AuditPolicyReport, pipelinerun.AuditPolicyRepair))
+	}
+	if o.JenkinsExecutorCapacity < 0 || o.JenkinsMaxQueueLength < 0 {
+		errs = append(errs, fmt.Errorf("the executor capacity or max queue length of Jenkins cannot be negative"))
+	}
+	if o.StuckThreshold < 0 {
+		errs = append(errs, fmt.Errorf("the stuck threshold of resources cannot be negative"))
+	}
+	if o.AgentUsageSamplePeriod < 0 {
+		errs = append(errs, fmt.Errorf("the sample period of agent usage cannot be negative"))
+	}
+	if o.LogArchiveSyncPeriod < 0 {
+		errs = append(errs, fmt.Errorf("the sync period of log archive cannot be negative"))
+	}
+	if o.JenkinsPluginCheckPeriod < 0 {
+		errs = append(errs, fmt.Errorf("the check period of Jenkins plugins cannot be negative"))
+	}
+	if o.LogArchiveCompression != "" {
+		if err := logarchive.ValidateCompression(o.LogArchiveCompression); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if o.ProvenanceRekorURL != "" && o.ProvenanceSigningKey == "" {
+		errs = append(errs, fmt.Errorf("the provenance signing key is required by uploading to Rekor"))
+	}
+	return
 }
 
 // ApplyTo fills up FeatureOptions config with options
@@ -78,12 +225,74 @@ func (o *FeatureOptions) ApplyTo(options *FeatureOptions) {
 func (o *FeatureOptions) AddFlags(fs *pflag.FlagSet, c *FeatureOptions) {
 	fs.Var(cliflag.NewMapStringBool(&o.Controllers), "enabled-controllers", "A set of key=value pairs that describe feature options for controllers. "+
 		"Options are:\n"+strings.Join(c.knownControllers(), "\n"))
+	features.DefaultMutableFeatureGate.AddFlag(fs)
+	fs.StringSliceVar(&o.SelectedControllers, "controllers", nil, "A list of controllers to enable. '*' enables all the "+
+		"controllers which are enabled by --enabled-controllers, 'foo' enables the controller named 'foo', '-foo' disables it. "+
+		"It overrides --enabled-controllers if it is not empty, such as '*,-credential'")
 	fs.StringVarP(&o.SystemNamespace, "system-namespace", "", "kubesphere-devops-system",
 		"The system namespace that contains ConfigMap, Secrets e.g.")
 	fs.StringVarP(&o.ExternalAddress, "external-address", "", "", "The external address for the UI")
 	fs.StringVarP(&o.ClusterName, "cluster-name", "", "default", "Current cluster name")
 	fs.StringVarP(&o.PipelineRunDataStore, "pipelinerun-data-store", "", "configmap",
 		"The data store type of the PipelineRun data, could be empty or configmap")
+	fs.DurationVarP(&o.PipelineDriftCheckInterval, "pipeline-drift-check-interval", "", 10*time.Minute,
+		"The interval of checking if the Jenkins jobs were modified out of the Pipelines, disable it if it is zero")
+	fs.StringVarP(&o.PipelineDriftPolicy, "pipeline-drift-policy", "", v1alpha3.PipelineDriftPolicyReport,
+		"The default policy when a Jenkins job drifted from its Pipeline, could be report, repair or ignore. "+
+			"It can be overridden by the annotation "+v1alpha3.PipelineDriftPolicyAnnoKey+" of a Pipeline")
+	fs.DurationVarP(&o.PipelineRunSyncPeriod, "pipelinerun-sync-period", "", 3*time.Second,
+		"The period of polling Jenkins for the status of a running PipelineRun")
+	fs.DurationVarP(&o.PipelineRunIdleSyncPeriod, "pipelinerun-idle-sync-period", "", 15*time.Second,
+		"The period of polling Jenkins for the status of a queued or paused PipelineRun")
+	fs.StringVarP(&o.PipelineRunAuditPolicy, "pipelinerun-audit-policy", "", pipelinerun.AuditPolicyReport,
+		"How to handle the unfinished Jenkins builds without PipelineRuns, and the running PipelineRuns without Jenkins "+
+			"builds, which are found after the controller starts. Could be report or repair")
+	fs.IntVarP(&o.JenkinsExecutorCapacity, "jenkins-executor-capacity", "", 0,
+		"The maximum number of unfinished PipelineRuns in Jenkins, the others are queued by their priority. It is unlimited if it is zero")
+	fs.IntVarP(&o.JenkinsMaxQueueLength, "jenkins-max-queue-length", "", 0,
+		"The pending PipelineRuns are held while the Jenkins queue has at least this many builds. It is unlimited if it is zero")
+	fs.StringVarP(&o.ArgoWorkflowsServiceAccount, "argo-workflows-service-account", "", "",
+		"The service account to run the Argo Workflows of PipelineRuns, the default service account of the namespace is used if it is empty")
+	fs.StringVarP(&o.ProvenanceBuilderID, "provenance-builder-id", "", provenance.DefaultBuilderID,
+		"The builder identity in the SLSA provenance of PipelineRuns")
+	fs.StringVarP(&o.ProvenanceSigningKey, "provenance-signing-key", "", "",
+		"The name of Secret in the system namespace which contains a PEM encoded ECDSA private key in the key "+
+			provenance.SecretKeySigningKey+". The provenance is not signed if it is empty")
+	fs.StringVarP(&o.ProvenanceRekorURL, "provenance-rekor-url", "", "",
+		"The address of Rekor, such as https://rekor.sigstore.dev. The signed provenance is not uploaded if it is empty")
+	fs.StringVarP(&o.CostPriceTable, "cost-price-table", "", "",
+		"The name of ConfigMap in the system namespace which contains the price table of agent pods in the key "+
+			cost.ConfigMapKeyPriceTable+". The cost of PipelineRuns is zero if it is empty, but the usage is still recorded")
+	fs.StringVarP(&o.AgentImagePolicy, "agent-image-policy", "", "",
+		"The name of ConfigMap in the system namespace which contains the cluster-wide policy of the images of agent pods in the key "+
+			imagepolicy.ConfigMapKeyPolicy+". There is no cluster-wide policy if it is empty, but the policies of DevOpsProjects still apply")
+	fs.StringVarP(&o.AgentSecurityBaseline, "agent-security-baseline", "", "",
+		"The name of ConfigMap in the system namespace which contains the security contexts enforced on the agent pods in the key "+
+			agentsecurity.ConfigMapKeyBaseline+". There is no security baseline if it is empty")
+	fs.DurationVarP(&o.AgentUsageSamplePeriod, "agent-usage-sample-period", "", agentusage.DefaultSamplePeriod,
+		"The period of sampling the resource usage of the running agent pods from the metrics server")
+	fs.DurationVarP(&o.StuckThreshold, "stuck-threshold", "", core.DefaultStuckThreshold,
+		"A resource is reported as stuck by an event and metrics once its reconciling keeps failing for longer than it, "+
+			"disable it if it is zero")
+	fs.DurationVarP(&o.LogArchiveSyncPeriod, "log-archive-sync-period", "", 30*time.Second,
+		"The period of archiving the new log of a running PipelineRun as a chunk")
+	fs.StringVarP(&o.LogArchiveCompression, "log-archive-compression", "", logarchive.CompressionGzip,
+		"The compression of the chunks of the archived logs, could be "+logarchive.CompressionGzip+" or "+logarchive.CompressionNone)
+	fs.DurationVarP(&o.JenkinsPluginCheckPeriod, "jenkins-plugin-check-period", "", 10*time.Minute,
+		"The period of checking if the required plugins are installed in Jenkins with the minimum versions")
+	fs.Var(cliflag.NewMapStringString(&o.WorkspaceRoleMapping), "workspace-role-mapping",
+		"A set of workspaceRole=projectRole pairs that map the members of KubeSphere workspace to the Roles of its DevOpsProjects, "+
+			"such as admin=admin,viewer=viewer. The workspace members who have the other roles are not bound. The default is "+
+			formatRoleMapping(workspace.DefaultRoleMapping))
+}
+
+func formatRoleMapping(mapping map[string]string) string {
+	pairs := make([]string, 0, len(mapping))
+	for key, val := range mapping {
+		pairs = append(pairs, key+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
 }
 
 func (o *FeatureOptions) knownControllers() []string {
@@ -19,6 +19,7 @@ package options
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
@@ -117,8 +118,186 @@ func TestFeatureOptions(t *testing.T) {
 	opt.AddFlags(flagSet, opt)
 	assert.True(t, flagSet.HasFlags())
 	assert.NotNil(t, flagSet.Lookup("enabled-controllers"))
+	assert.NotNil(t, flagSet.Lookup("feature-gates"))
+	assert.NotNil(t, flagSet.Lookup("controllers"))
+	assert.NotNil(t, flagSet.Lookup("stuck-threshold"))
 	assert.NotNil(t, flagSet.Lookup("system-namespace"))
 	assert.NotNil(t, flagSet.Lookup("external-address"))
 	assert.NotNil(t, flagSet.Lookup("cluster-name"))
 	assert.NotNil(t, flagSet.Lookup("pipelinerun-data-store"))
+	assert.NotNil(t, flagSet.Lookup("pipelinerun-sync-period"))
+	assert.NotNil(t, flagSet.Lookup("pipelinerun-idle-sync-period"))
+	assert.NotNil(t, flagSet.Lookup("argo-workflows-service-account"))
+	assert.NotNil(t, flagSet.Lookup("jenkins-executor-capacity"))
+	assert.NotNil(t, flagSet.Lookup("jenkins-max-queue-length"))
+	assert.NotNil(t, flagSet.Lookup("jenkins-plugin-check-period"))
+}
+
+func TestFeatureOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		syncPeriod   time.Duration
+		capacity     int
+		queue        int
+		signingKey   string
+		rekorURL     string
+		sample       time.Duration
+		selection    []string
+		stuck        time.Duration
+		pluginPeriod time.Duration
+		auditPolicy  string
+		wantErr      bool
+	}{{
+		name:   "empty policy",
+		policy: "",
+	}, {
+		name:   "report",
+		policy: "report",
+	}, {
+		name:   "repair",
+		policy: "repair",
+	}, {
+		name:   "ignore",
+		policy: "ignore",
+	}, {
+		name:    "unknown policy",
+		policy:  "fake",
+		wantErr: true,
+	}, {
+		name:       "positive sync period",
+		syncPeriod: time.Second,
+	}, {
+		name:       "negative sync period",
+		syncPeriod: -time.Second,
+		wantErr:    true,
+	}, {
+		name:     "limited executor capacity",
+		capacity: 2,
+	}, {
+		name:     "negative executor capacity",
+		capacity: -1,
+		wantErr:  true,
+	}, {
+		name:    "negative max queue length",
+		queue:   -1,
+		wantErr: true,
+	}, {
+		name:       "upload the signed provenance to Rekor",
+		signingKey: "provenance-key",
+		rekorURL:   "https://rekor.sigstore.dev",
+	}, {
+		name:     "upload to Rekor without signing key",
+		rekorURL: "https://rekor.sigstore.dev",
+		wantErr:  true,
+	}, {
+		name:    "negative sample period of agent usage",
+		sample:  -time.Second,
+		wantErr: true,
+	}, {
+		name:    "negative stuck threshold",
+		stuck:   -time.Minute,
+		wantErr: true,
+	}, {
+		name:         "negative check period of Jenkins plugins",
+		pluginPeriod: -time.Minute,
+		wantErr:      true,
+	}, {
+		name:        "repair the inconsistencies of PipelineRuns",
+		auditPolicy: "repair",
+	}, {
+		name:        "unknown audit policy",
+		auditPolicy: "fake",
+		wantErr:     true,
+	}, {
+		name:      "select the controllers",
+		selection: []string{"*", "-credential", "pipelinerun"},
+	}, {
+		name:      "empty controller name",
+		selection: []string{"-"},
+		wantErr:   true,
+	}, {
+		name:      "disable all the controllers",
+		selection: []string{"-*"},
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &FeatureOptions{PipelineDriftPolicy: tt.policy, PipelineRunSyncPeriod: tt.syncPeriod,
+				JenkinsExecutorCapacity: tt.capacity, JenkinsMaxQueueLength: tt.queue,
+				ProvenanceSigningKey: tt.signingKey, ProvenanceRekorURL: tt.rekorURL, AgentUsageSamplePeriod: tt.sample,
+				SelectedControllers: tt.selection, StuckThreshold: tt.stuck, JenkinsPluginCheckPeriod: tt.pluginPeriod,
+				PipelineRunAuditPolicy: tt.auditPolicy}
+			assert.Equal(t, tt.wantErr, len(o.Validate()) > 0)
+		})
+	}
+}
+
+func TestFeatureOptions_ResolveControllers(t *testing.T) {
+	groups := map[string][]string{
+		"pipeline": {"pipelinerun", "pipelinerunsync"},
+		"jenkins":  {"credential", "devopsproject"},
+	}
+	tests := []struct {
+		name        string
+		controllers map[string]bool
+		selection   []string
+		want        map[string]bool
+	}{{
+		name: "no selection",
+		controllers: map[string]bool{
+			"all":     false,
+			"jenkins": true,
+			"fake":    true,
+		},
+		want: map[string]bool{
+			"credential":    true,
+			"devopsproject": true,
+			"fake":          true,
+		},
+	}, {
+		name: "disable a member of the defaults",
+		controllers: map[string]bool{
+			"all":      false,
+			"jenkins":  true,
+			"pipeline": true,
+		},
+		selection: []string{"*", "-credential"},
+		want: map[string]bool{
+			"credential":      false,
+			"devopsproject":   true,
+			"pipelinerun":     true,
+			"pipelinerunsync": true,
+		},
+	}, {
+		name: "only enable the specific controllers",
+		controllers: map[string]bool{
+			"jenkins": true,
+		},
+		selection: []string{"pipelinerun", "credential"},
+		want: map[string]bool{
+			"pipelinerun": true,
+			"credential":  true,
+		},
+	}, {
+		name: "disable a group",
+		controllers: map[string]bool{
+			"all":      false,
+			"jenkins":  true,
+			"pipeline": true,
+		},
+		selection: []string{"-jenkins", "*"},
+		want: map[string]bool{
+			"credential":      false,
+			"devopsproject":   false,
+			"pipelinerun":     true,
+			"pipelinerunsync": true,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &FeatureOptions{Controllers: tt.controllers, SelectedControllers: tt.selection}
+			assert.Equal(t, tt.want, o.ResolveControllers(groups))
+		})
+	}
 }
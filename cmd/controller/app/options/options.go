@@ -18,14 +18,18 @@ package options
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 	"time"
 
 	"kubesphere.io/devops/pkg/config"
 
 	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/client/history"
 	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/client/ldap"
 	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/smtp"
 
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -41,11 +45,23 @@ type DevOpsControllerManagerOptions struct {
 	LeaderElect       bool
 	LeaderElection    *leaderelection.LeaderElectionConfig
 	WebhookCertDir    string
+	WebhookOptions    *WebhookOptions
 	S3Options         *s3.Options
+	HistoryOptions    *history.Options
+	LDAPOptions       *ldap.Options
+	SMTPOptions       *smtp.Options
 	FeatureOptions    *FeatureOptions
 	JWTOptions        *JWTOptions
 	ArgoCDOption      *config.ArgoCDOption
 
+	// MetricsBindAddress is the address which the metrics endpoint binds to, it's disabled if the address is 0
+	MetricsBindAddress string
+	// HealthProbeBindAddress is the address which the health probes /healthz and /readyz bind to, they are disabled
+	// if the address is empty
+	HealthProbeBindAddress string
+	// GracefulShutdownTimeout is the duration of waiting for the in-flight reconciles after receiving the stop signal
+	GracefulShutdownTimeout time.Duration
+
 	// KubeSphere is using sigs.k8s.io/application as fundamental object to implement Application Management.
 	// There are other projects also built on sigs.k8s.io/application, when KubeSphere installed along side
 	// them, conflicts happen. So we leave an option to only reconcile applications  matched with the given
@@ -54,8 +70,25 @@ type DevOpsControllerManagerOptions struct {
 	//      "kubesphere.io/creator=" means reconcile applications with this label key
 	//      "!kubesphere.io/creator" means exclude applications with this key
 	ApplicationSelector string
+
+	// Mode decides what the process runs, could be all, controllers-only, or webhook-only.
+	// It allows running the admission webhooks in a separate deployment without the leader election
+	Mode string
+
+	// Demo runs the controllers against an in-memory DevOps server instead of Jenkins,
+	// it's for trying the controllers out and the end-to-end tests
+	Demo bool
 }
 
+const (
+	// ModeAll runs both the controllers and the admission webhooks
+	ModeAll = "all"
+	// ModeControllersOnly runs the controllers without the admission webhooks
+	ModeControllersOnly = "controllers-only"
+	// ModeWebhookOnly runs the admission webhooks without the controllers
+	ModeWebhookOnly = "webhook-only"
+)
+
 func NewDevOpsControllerManagerOptions() *DevOpsControllerManagerOptions {
 	s := &DevOpsControllerManagerOptions{
 		JenkinsOptions: jenkins.NewJenkinsOptions(),
@@ -65,11 +98,19 @@ func NewDevOpsControllerManagerOptions() *DevOpsControllerManagerOptions {
 			RetryPeriod:   5 * time.Second,
 		},
 		FeatureOptions:      NewFeatureOptions(),
+		WebhookOptions:      NewWebhookOptions(),
 		LeaderElect:         false,
 		WebhookCertDir:      "",
+		MetricsBindAddress:  ":8080",
 		ApplicationSelector: "",
+		Mode:                ModeAll,
 		KubernetesOptions:   &k8s.KubernetesOptions{},
 		ArgoCDOption:        &config.ArgoCDOption{},
+		LDAPOptions:         ldap.NewLDAPOptions(),
+		SMTPOptions:         smtp.NewSMTPOptions(),
+
+		// less than the default termination grace period of pods
+		GracefulShutdownTimeout: 25 * time.Second,
 	}
 
 	return s
@@ -82,6 +123,9 @@ func (s *DevOpsControllerManagerOptions) Flags() cliflag.NamedFlagSets {
 	s.JenkinsOptions.AddFlags(fss.FlagSet("devops"), s.JenkinsOptions)
 	s.FeatureOptions.AddFlags(fss.FlagSet("feature"), s.FeatureOptions)
 	s.ArgoCDOption.AddFlags(fss.FlagSet("argocd"))
+	s.WebhookOptions.AddFlags(fss.FlagSet("webhook"), s.WebhookOptions)
+	s.LDAPOptions.AddFlags(fss.FlagSet("ldap"), s.LDAPOptions)
+	s.SMTPOptions.AddFlags(fss.FlagSet("smtp"), s.SMTPOptions)
 
 	fs := fss.FlagSet("leaderelection")
 	s.bindLeaderElectionFlags(s.LeaderElection, fs)
@@ -96,10 +140,26 @@ func (s *DevOpsControllerManagerOptions) Flags() cliflag.NamedFlagSets {
 		"{TempDir}/k8s-webhook-server/serving-certs")
 
 	gfs := fss.FlagSet("generic")
+	gfs.StringVar(&s.MetricsBindAddress, "metrics-bind-address", s.MetricsBindAddress, ""+
+		"The address which the metrics endpoint binds to, such as 127.0.0.1:8080. Set it to 0 to disable the metrics")
+	gfs.StringVar(&s.HealthProbeBindAddress, "health-probe-bind-address", s.HealthProbeBindAddress, ""+
+		"The address which the health probes /healthz and /readyz bind to, such as :8081. The probes are disabled if "+
+		"it's empty")
+	gfs.DurationVar(&s.GracefulShutdownTimeout, "graceful-shutdown-timeout", s.GracefulShutdownTimeout, ""+
+		"The duration of waiting for the in-flight reconciles, such as submitting builds to Jenkins, after receiving "+
+		"the stop signal. It should be less than the termination grace period of the pod")
+
 	gfs.StringVar(&s.ApplicationSelector, "application-selector", s.ApplicationSelector, ""+
 		"Only reconcile application(sigs.k8s.io/application) objects match given selector, this could avoid conflicts with "+
 		"other projects built on top of sig-application. Default behavior is to reconcile all of application objects.")
 
+	gfs.StringVar(&s.Mode, "mode", s.Mode, "What the process runs, could be "+ModeAll+", "+ModeControllersOnly+
+		" or "+ModeWebhookOnly+". The leader election is disabled in the "+ModeWebhookOnly+
+		" mode, so that every replica serves the admission webhooks")
+
+	gfs.BoolVar(&s.Demo, "demo", s.Demo, "Run the controllers against an in-memory DevOps server instead of Jenkins. "+
+		"The builds finish on the second fetch, and the controllers which talk to Jenkins directly don't run in this mode")
+
 	kfs := fss.FlagSet("klog")
 	local := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(local)
@@ -116,6 +176,17 @@ func (s *DevOpsControllerManagerOptions) Validate() []error {
 	errs = append(errs, s.JenkinsOptions.Validate()...)
 	errs = append(errs, s.KubernetesOptions.Validate()...)
 	errs = append(errs, s.FeatureOptions.Validate()...)
+	errs = append(errs, s.HistoryOptions.Validate()...)
+	errs = append(errs, s.LDAPOptions.Validate()...)
+	errs = append(errs, s.SMTPOptions.Validate()...)
+	errs = append(errs, s.WebhookOptions.Validate()...)
+
+	switch s.Mode {
+	case ModeAll, ModeControllersOnly, ModeWebhookOnly:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported mode: %q, should be %s, %s or %s",
+			s.Mode, ModeAll, ModeControllersOnly, ModeWebhookOnly))
+	}
 
 	if len(s.ApplicationSelector) != 0 {
 		_, err := labels.Parse(s.ApplicationSelector)
@@ -127,6 +198,16 @@ func (s *DevOpsControllerManagerOptions) Validate() []error {
 	return errs
 }
 
+// RunControllers returns true if the controllers should run in the current mode
+func (s *DevOpsControllerManagerOptions) RunControllers() bool {
+	return s.Mode != ModeWebhookOnly
+}
+
+// RunWebhooks returns true if the admission webhooks should run in the current mode
+func (s *DevOpsControllerManagerOptions) RunWebhooks() bool {
+	return s.Mode != ModeControllersOnly
+}
+
 func (s *DevOpsControllerManagerOptions) bindLeaderElectionFlags(l *leaderelection.LeaderElectionConfig, fs *pflag.FlagSet) {
 	fs.DurationVar(&l.LeaseDuration, "leader-elect-lease-duration", l.LeaseDuration, ""+
 		"The duration that non-leader candidates will wait after observing a leadership "+
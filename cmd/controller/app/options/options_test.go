@@ -18,6 +18,7 @@ package options
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,6 +33,7 @@ func TestOption(t *testing.T) {
 	assert.NotNil(t, flags.FlagSet("devops"))
 	assert.NotNil(t, flags.FlagSet("feature"))
 	assert.NotNil(t, flags.FlagSet("argocd"))
+	assert.NotNil(t, flags.FlagSet("webhook"))
 	assert.NotNil(t, flags.FlagSet("generic"))
 	assert.NotNil(t, flags.FlagSet("leaderelection"))
 	assert.NotNil(t, flags.FlagSet("klog"))
@@ -42,3 +44,48 @@ func TestOption(t *testing.T) {
 	opt.ApplicationSelector = "!@#$"
 	assert.NotNil(t, opt.Validate())
 }
+
+func TestOptionMode(t *testing.T) {
+	opt := NewDevOpsControllerManagerOptions()
+	assert.Equal(t, ModeAll, opt.Mode)
+	assert.True(t, opt.RunControllers())
+	assert.True(t, opt.RunWebhooks())
+
+	opt.Mode = ModeWebhookOnly
+	assert.Nil(t, opt.Validate())
+	assert.False(t, opt.RunControllers())
+	assert.True(t, opt.RunWebhooks())
+
+	opt.Mode = ModeControllersOnly
+	assert.Nil(t, opt.Validate())
+	assert.True(t, opt.RunControllers())
+	assert.False(t, opt.RunWebhooks())
+
+	opt.Mode = "fake"
+	assert.NotNil(t, opt.Validate())
+}
+
+func TestOptionBindAddresses(t *testing.T) {
+	opt := NewDevOpsControllerManagerOptions()
+	assert.Equal(t, "", opt.WebhookOptions.Host)
+	assert.Equal(t, 8443, opt.WebhookOptions.Port)
+	assert.Equal(t, ":8080", opt.MetricsBindAddress)
+	assert.Equal(t, "", opt.HealthProbeBindAddress)
+	assert.Equal(t, 25*time.Second, opt.GracefulShutdownTimeout)
+
+	flags := opt.Flags()
+	assert.Nil(t, flags.FlagSet("webhook").Parse([]string{"--webhook-host=127.0.0.1", "--webhook-port=9443"}))
+	assert.Nil(t, flags.FlagSet("generic").Parse([]string{"--metrics-bind-address=0",
+		"--health-probe-bind-address=:8081", "--graceful-shutdown-timeout=1m"}))
+	assert.Equal(t, "127.0.0.1", opt.WebhookOptions.Host)
+	assert.Equal(t, 9443, opt.WebhookOptions.Port)
+	assert.Equal(t, "0", opt.MetricsBindAddress)
+	assert.Equal(t, ":8081", opt.HealthProbeBindAddress)
+	assert.Equal(t, time.Minute, opt.GracefulShutdownTimeout)
+	assert.Nil(t, opt.Validate())
+
+	opt.WebhookOptions.Port = 0
+	assert.NotNil(t, opt.Validate())
+	opt.WebhookOptions.Port = 65536
+	assert.NotNil(t, opt.Validate())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// WebhookOptions contain the options of the webhook server and managing its serving certificates
+type WebhookOptions struct {
+	// Host is the address which the webhook server binds to, it binds to all addresses if it's empty
+	Host string
+	// Port is the port which the webhook server serves on
+	Port int
+	// CertRotation indicates whether to generate and rotate the self-signed certificates instead of cert-manager
+	CertRotation bool
+	// Namespace is where the webhook service and the certificate Secret are
+	Namespace string
+	// SecretName is the Secret which stores the certificates
+	SecretName string
+	// ServiceName is the service of the webhook server
+	ServiceName string
+	// MutatingWebhooks and ValidatingWebhooks are the webhook configurations to inject the CA bundle
+	MutatingWebhooks   []string
+	ValidatingWebhooks []string
+}
+
+// NewWebhookOptions provides default options
+func NewWebhookOptions() *WebhookOptions {
+	return &WebhookOptions{
+		// use 8443 instead of 443 because binding port 443 requires the root permission
+		Port:               8443,
+		Namespace:          "kubesphere-devops-system",
+		SecretName:         "ks-devops-webhook-server-cert",
+		ServiceName:        "ks-devops-webhook-service",
+		MutatingWebhooks:   []string{"ks-devops-mutating-webhook-configuration"},
+		ValidatingWebhooks: []string{"ks-devops-validating-webhook-configuration"},
+	}
+}
+
+// AddFlags adds flags of WebhookOptions into the flag set
+func (o *WebhookOptions) AddFlags(fs *pflag.FlagSet, c *WebhookOptions) {
+	fs.StringVar(&o.Host, "webhook-host", c.Host, "The address which the webhook server binds to, "+
+		"it binds to all addresses if it's empty")
+	fs.IntVar(&o.Port, "webhook-port", c.Port, "The port which the webhook server serves on, change it if the port "+
+		"conflicts with the other processes, such as running in the host network")
+	fs.BoolVar(&o.CertRotation, "webhook-cert-rotation", c.CertRotation, ""+
+		"Generate the self-signed serving certificates of webhooks, rotate them before expiring, and inject the CA bundle "+
+		"into the webhook configurations. Disable it if the certificates are managed by cert-manager")
+	fs.StringVar(&o.Namespace, "webhook-namespace", c.Namespace, "The namespace of the webhook service and the certificate Secret")
+	fs.StringVar(&o.SecretName, "webhook-cert-secret", c.SecretName, "The name of Secret which stores the webhook certificates")
+	fs.StringVar(&o.ServiceName, "webhook-service", c.ServiceName, "The name of the webhook service")
+	fs.StringSliceVar(&o.MutatingWebhooks, "webhook-mutating-configurations", c.MutatingWebhooks,
+		"The MutatingWebhookConfigurations to inject the CA bundle")
+	fs.StringSliceVar(&o.ValidatingWebhooks, "webhook-validating-configurations", c.ValidatingWebhooks,
+		"The ValidatingWebhookConfigurations to inject the CA bundle")
+}
+
+// Validate checks validation of WebhookOptions
+func (o *WebhookOptions) Validate() (errs []error) {
+	if o != nil && (o.Port <= 0 || o.Port > 65535) {
+		errs = append(errs, fmt.Errorf("invalid webhook port: %d", o.Port))
+	}
+	if o != nil && o.CertRotation && (o.Namespace == "" || o.SecretName == "" || o.ServiceName == "") {
+		errs = append(errs, fmt.Errorf("the namespace, secret, and service of webhook are required by the certificate rotation"))
+	}
+	return
+}
+
+// GetDNSNames returns the DNS names of the webhook service
+func (o *WebhookOptions) GetDNSNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", o.ServiceName, o.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", o.ServiceName, o.Namespace),
+	}
+}
@@ -19,17 +19,26 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"kubesphere.io/devops/cmd/controller/app/options"
+	"kubesphere.io/devops/controllers/webhookcert"
 	"kubesphere.io/devops/pkg/apis"
 	"kubesphere.io/devops/pkg/client/devops"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
 	"kubesphere.io/devops/pkg/client/devops/jclient"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/client/ldap"
+	"kubesphere.io/devops/pkg/client/smtp"
 	"kubesphere.io/devops/pkg/config"
 	"kubesphere.io/devops/pkg/indexers"
 	"kubesphere.io/devops/pkg/informers"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"github.com/spf13/cobra"
@@ -50,12 +59,21 @@ func NewControllerManagerCommand() *cobra.Command {
 		if conf.ArgoCDOption == nil {
 			conf.ArgoCDOption = &config.ArgoCDOption{}
 		}
+		if conf.LDAPOptions == nil {
+			conf.LDAPOptions = ldap.NewLDAPOptions()
+		}
+		if conf.SMTPOptions == nil {
+			conf.SMTPOptions = smtp.NewSMTPOptions()
+		}
 		// make sure LeaderElection is not nil
 		// override devops controller manager options
 		s = &options.DevOpsControllerManagerOptions{
 			KubernetesOptions: conf.KubernetesOptions,
 			JenkinsOptions:    conf.JenkinsOptions,
 			S3Options:         conf.S3Options,
+			HistoryOptions:    conf.HistoryOptions,
+			LDAPOptions:       conf.LDAPOptions,
+			SMTPOptions:       conf.SMTPOptions,
 			JWTOptions: &options.JWTOptions{
 				Secret:           conf.AuthenticationOptions.JwtSecret,
 				MaximumClockSkew: conf.AuthenticationOptions.MaximumClockSkew,
@@ -65,6 +83,9 @@ func NewControllerManagerCommand() *cobra.Command {
 			LeaderElection: s.LeaderElection,
 			LeaderElect:    s.LeaderElect,
 			WebhookCertDir: s.WebhookCertDir,
+			WebhookOptions: s.WebhookOptions,
+			Mode:           s.Mode,
+			Demo:           s.Demo,
 		}
 	} else {
 		klog.Fatal("Failed to load configuration from disk", err)
@@ -121,8 +142,12 @@ func Run(s *options.DevOpsControllerManagerOptions, ctx context.Context) error {
 	}
 
 	// Init DevOps client while Jenkins options and Jenkins host
+	// The admission webhooks do not talk to Jenkins
 	var devopsClient devops.Interface
-	if s.JenkinsOptions != nil && len(s.JenkinsOptions.Host) != 0 {
+	if s.RunControllers() && s.Demo {
+		klog.Info("running in the demo mode, the DevOps server is in memory")
+		devopsClient = fakedevops.New()
+	} else if s.RunControllers() && s.JenkinsOptions != nil && len(s.JenkinsOptions.Host) != 0 {
 		// Make sure that Jenkins host is not empty
 		devopsClient, err = jclient.NewJenkinsClient(s.JenkinsOptions)
 		if !s.JenkinsOptions.SkipVerify && err != nil {
@@ -149,26 +174,30 @@ func Run(s *options.DevOpsControllerManagerOptions, ctx context.Context) error {
 		kubernetesClient.ApiExtensions())
 
 	mgrOptions := manager.Options{
-		CertDir: s.WebhookCertDir,
-		Port:    8443,
-	}
-
-	if s.LeaderElect {
-		mgrOptions = manager.Options{
-			CertDir:                 s.WebhookCertDir,
-			Port:                    8443,
-			LeaderElection:          s.LeaderElect,
-			LeaderElectionNamespace: "kubesphere-devops-system",
-			LeaderElectionID:        "ks-devops-controller-manager-leader-election",
-			LeaseDuration:           &s.LeaderElection.LeaseDuration,
-			RetryPeriod:             &s.LeaderElection.RetryPeriod,
-			RenewDeadline:           &s.LeaderElection.RenewDeadline,
-		}
+		CertDir:                 s.WebhookCertDir,
+		Host:                    s.WebhookOptions.Host,
+		Port:                    s.WebhookOptions.Port,
+		MetricsBindAddress:      s.MetricsBindAddress,
+		HealthProbeBindAddress:  s.HealthProbeBindAddress,
+		GracefulShutdownTimeout: &s.GracefulShutdownTimeout,
+	}
+
+	if s.LeaderElect && !s.RunControllers() {
+		klog.Infof("leader election is disabled in the %s mode", s.Mode)
+	} else if s.LeaderElect {
+		mgrOptions.LeaderElection = s.LeaderElect
+		mgrOptions.LeaderElectionNamespace = "kubesphere-devops-system"
+		mgrOptions.LeaderElectionID = "ks-devops-controller-manager-leader-election"
+		mgrOptions.LeaseDuration = &s.LeaderElection.LeaseDuration
+		mgrOptions.RetryPeriod = &s.LeaderElection.RetryPeriod
+		mgrOptions.RenewDeadline = &s.LeaderElection.RenewDeadline
+		// the process exits once the manager stops, so step down right away instead of making the next leader wait
+		// until the lease expires
+		mgrOptions.LeaderElectionReleaseOnCancel = true
 	}
 
 	klog.V(0).Info("setting up manager")
 	ctrl.SetLogger(klogr.New())
-	// Use 8443 instead of 443 cause we need root permission to bind port 443
 	// Init controller manager
 	mgr, err := manager.New(kubernetesClient.Config(), mgrOptions)
 	if err != nil {
@@ -180,6 +209,23 @@ func Run(s *options.DevOpsControllerManagerOptions, ctx context.Context) error {
 	// register common meta types into schemas.
 	metav1.AddToGroupVersion(mgr.GetScheme(), metav1.SchemeGroupVersion)
 
+	if err = addHealthChecks(mgr, s); err != nil {
+		return fmt.Errorf("unable to set up the health checks: %v", err)
+	}
+
+	if s.RunWebhooks() && s.WebhookOptions != nil && s.WebhookOptions.CertRotation {
+		if err = setupWebhookCertRotator(ctx, mgr, kubernetesClient, s); err != nil {
+			return fmt.Errorf("unable to set up the webhook certificates: %v", err)
+		}
+	}
+
+	// talk to the Jenkins folders resolved from the namespaces of the DevOps projects
+	folderResolver := jenkins.NewFolderResolver(mgr.GetClient())
+	jenkinsCore.RoundTripper = folderResolver.WrapTransport(nil)
+	if jenkinsClient, ok := devopsClient.(*jclient.JenkinsClient); ok {
+		jenkinsClient.UseFolderResolver(folderResolver)
+	}
+
 	if err = addControllers(mgr,
 		kubernetesClient,
 		informerFactory,
@@ -189,7 +235,7 @@ func Run(s *options.DevOpsControllerManagerOptions, ctx context.Context) error {
 		return fmt.Errorf("unable to register controllers to the manager: %v", err)
 	}
 
-	if err = indexers.CreatePipelineRunSCMRefNameIndexer(mgr.GetCache()); err != nil {
+	if err = indexers.CreateControllerIndexers(mgr.GetCache()); err != nil {
 		return err
 	}
 
@@ -204,3 +250,38 @@ func Run(s *options.DevOpsControllerManagerOptions, ctx context.Context) error {
 
 	return nil
 }
+
+// addHealthChecks adds the checks of the health probes if they are enabled. The readiness waits for the webhook server
+// to start, so the webhooks don't receive any requests before they are able to serve.
+func addHealthChecks(mgr manager.Manager, s *options.DevOpsControllerManagerOptions) error {
+	if s.HealthProbeBindAddress == "" || s.HealthProbeBindAddress == "0" {
+		return nil
+	}
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+	if s.RunWebhooks() {
+		return mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker())
+	}
+	return mgr.AddReadyzCheck("ping", healthz.Ping)
+}
+
+// setupWebhookCertRotator prepares the certificates before the webhook server starts, then keeps rotating them
+func setupWebhookCertRotator(ctx context.Context, mgr manager.Manager, client k8s.Client,
+	s *options.DevOpsControllerManagerOptions) error {
+	certDir := s.WebhookCertDir
+	if certDir == "" {
+		certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+
+	rotator := webhookcert.NewRotator(client.Kubernetes(), metav1.ObjectMeta{
+		Namespace: s.WebhookOptions.Namespace,
+		Name:      s.WebhookOptions.SecretName,
+	}, certDir, s.WebhookOptions.GetDNSNames())
+	rotator.MutatingWebhooks = s.WebhookOptions.MutatingWebhooks
+	rotator.ValidatingWebhooks = s.WebhookOptions.ValidatingWebhooks
+	if err := rotator.Rotate(ctx); err != nil {
+		return err
+	}
+	return mgr.Add(rotator)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/apis"
+	"kubesphere.io/devops/pkg/client/clientset/versioned/scheme"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/models/backup"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type backupOption struct {
+	*ToolOption
+
+	s3Options         *s3.Options
+	archive           string
+	file              string
+	encryptionKey     string
+	devopsProjects    []string
+	includeRunHistory bool
+
+	client client.Client
+}
+
+func (o *backupOption) addFlags(flags *pflag.FlagSet) {
+	o.s3Options.AddFlags(flags, s3.NewS3Options())
+	flags.StringVar(&o.archive, "archive", "",
+		"The key of the archive in the object storage")
+	flags.StringVar(&o.file, "file", "",
+		"The local file path of the archive, the object storage will be ignored if it was set")
+	flags.StringVar(&o.encryptionKey, "encryption-key", "",
+		"The passphrase to encrypt or decrypt the credentials")
+}
+
+func (o *backupOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.encryptionKey == "" {
+		return errors.New("--encryption-key is required")
+	}
+	if o.file == "" && o.s3Options.Endpoint == "" {
+		return errors.New("either --file or --s3-endpoint is required")
+	}
+	if err = o.initK8sClient(); err != nil {
+		return
+	}
+
+	sch := runtime.NewScheme()
+	_ = scheme.AddToScheme(sch)
+	apis.AddToScheme(sch)
+	o.client, err = client.New(o.K8sClient.Config(), client.Options{Scheme: sch})
+	return
+}
+
+func (o *backupOption) backupRunE(cmd *cobra.Command, args []string) (err error) {
+	var archive *backup.Archive
+	if archive, err = backup.Export(context.TODO(), o.client, backup.Options{
+		DevOpsProjects:    o.devopsProjects,
+		IncludeRunHistory: o.includeRunHistory,
+		EncryptionKey:     o.encryptionKey,
+	}); err != nil {
+		return
+	}
+
+	if o.file != "" {
+		var file *os.File
+		if file, err = os.Create(o.file); err != nil {
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		err = archive.Encode(file)
+	} else {
+		if o.archive == "" {
+			o.archive = fmt.Sprintf("devops-backup-%s.json.gz", time.Now().Format("20060102150405"))
+		}
+
+		var storage s3.Interface
+		if storage, err = s3.NewS3Client(o.s3Options); err == nil {
+			err = backup.Upload(storage, o.archive, archive)
+		}
+	}
+
+	if err == nil {
+		klog.Infof("backed up %d DevOpsProjects, %d Pipelines, %d credentials and %d PipelineRuns",
+			len(archive.DevOpsProjects), len(archive.Pipelines), len(archive.Credentials), len(archive.PipelineRuns))
+	}
+	return
+}
+
+func (o *backupOption) restoreRunE(cmd *cobra.Command, args []string) (err error) {
+	var archive *backup.Archive
+	if o.file != "" {
+		var file *os.File
+		if file, err = os.Open(o.file); err != nil {
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		archive, err = backup.Decode(file)
+	} else {
+		if o.archive == "" {
+			return errors.New("--archive is required when restoring from the object storage")
+		}
+
+		var storage s3.Interface
+		if storage, err = s3.NewS3Client(o.s3Options); err == nil {
+			archive, err = backup.Download(storage, o.archive)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	var report *backup.Report
+	if report, err = backup.Restore(context.TODO(), o.client, archive, o.encryptionKey); err == nil {
+		for _, name := range report.Created {
+			klog.Infof("created %s", name)
+		}
+		for _, name := range report.Skipped {
+			klog.Infof("skipped %s due to it already exists", name)
+		}
+	}
+	return
+}
+
+// NewBackupCmd creates a command for backing up the DevOps resources
+func NewBackupCmd() (cmd *cobra.Command) {
+	opt := &backupOption{
+		ToolOption: toolOpt,
+		s3Options:  &s3.Options{},
+	}
+
+	backupCmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "Back up DevOpsProjects, Pipelines, credentials and run history into an archive",
+		PreRunE: opt.preRunE,
+		RunE:    opt.backupRunE,
+	}
+
+	flags := backupCmd.Flags()
+	opt.addFlags(flags)
+	flags.StringSliceVar(&opt.devopsProjects, "devops-projects", nil,
+		"The names of DevOpsProject to back up, back up all of them if it is empty")
+	flags.BoolVar(&opt.includeRunHistory, "include-run-history", false,
+		"Back up the completed PipelineRuns as well")
+	return backupCmd
+}
+
+// NewRestoreCmd creates a command for restoring the DevOps resources
+func NewRestoreCmd() (cmd *cobra.Command) {
+	opt := &backupOption{
+		ToolOption: toolOpt,
+		s3Options:  &s3.Options{},
+	}
+
+	restoreCmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restore DevOpsProjects, Pipelines, credentials and run history from an archive",
+		PreRunE: opt.preRunE,
+		RunE:    opt.restoreRunE,
+	}
+
+	opt.addFlags(restoreCmd.Flags())
+	return restoreCmd
+}
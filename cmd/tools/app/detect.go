@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/spf13/cobra"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/models/detector"
+)
+
+type detectOption struct {
+	*ToolOption
+
+	repoURL string
+	token   string
+	branch  string
+}
+
+func (o *detectOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.repoURL == "" {
+		err = errors.New("--repo-url is required")
+	}
+	return
+}
+
+func (o *detectOption) runE(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.TODO()
+	var provider, server, repo string
+	if provider, server, repo, err = git.ParseRepositoryURL(o.repoURL); err != nil {
+		return
+	}
+	var scmClient *scm.Client
+	if scmClient, err = factory.NewClient(provider, server, o.token); err != nil {
+		return
+	}
+
+	branch := o.branch
+	if branch == "" {
+		var repository *scm.Repository
+		if repository, _, err = scmClient.Repositories.Find(ctx, repo); err != nil {
+			return
+		}
+		branch = repository.Branch
+	}
+	var result *detector.Result
+	if result, err = detector.Detect(ctx, scmClient, repo, branch, nil); err != nil {
+		return
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// NewDetectCmd creates a command for detecting the toolchains of a repository
+func NewDetectCmd() (cmd *cobra.Command) {
+	opt := &detectOption{
+		ToolOption: toolOpt,
+	}
+
+	detectCmd := &cobra.Command{
+		Use:     "detect",
+		Short:   "Detect the toolchains of a repository, and print the recommended builder images and template parameters",
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := detectCmd.Flags()
+	flags.StringVar(&opt.repoURL, "repo-url", "",
+		"The URL of the repository, only the repositories on github.com and gitlab.com are supported")
+	flags.StringVar(&opt.token, "token", "",
+		"The token to access the repository")
+	flags.StringVar(&opt.branch, "branch", "",
+		"The branch to inspect, it is the default branch if it is empty")
+	return detectCmd
+}
@@ -56,5 +56,8 @@ func NewToolsCmd() (cmd *cobra.Command) {
 		"The configmap name of DevOps service")
 
 	rootCmd.AddCommand(NewInitCmd())
+	rootCmd.AddCommand(NewBackupCmd())
+	rootCmd.AddCommand(NewRestoreCmd())
+	rootCmd.AddCommand(NewDetectCmd())
 	return rootCmd
 }
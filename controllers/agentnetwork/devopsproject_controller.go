@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentnetwork
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// agentLabelKey is the label key of Jenkins agent pods which are created by the Jenkins Kubernetes plugin
+	agentLabelKey = "jenkins"
+	// agentLabelValue is the label value of Jenkins agent pods
+	agentLabelValue = "slave"
+	// namespaceNameLabelKey is the label which is set to the name of every namespace by Kubernetes
+	namespaceNameLabelKey = "kubernetes.io/metadata.name"
+	dnsPort               = 53
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+
+// Reconciler applies the agent NetworkPolicies of DevOpsProjects into the namespace of Jenkins agents. The agent pods
+// are labeled with their DevOpsProject by the agent preset webhook, then each NetworkPolicy only selects the agent
+// pods of its DevOpsProject.
+type Reconciler struct {
+	client.Client
+	// WorkerNamespace is the namespace of the Jenkins agent pods
+	WorkerNamespace string
+	// JenkinsNamespace is the namespace of Jenkins, the agent pods are always allowed to reach it
+	JenkinsNamespace string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile creates or updates the agent NetworkPolicy of a DevOpsProject, or deletes it if it's not required
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		// the NetworkPolicy is owned by the DevOpsProject, it's deleted by the garbage collector
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{
+		Namespace: r.WorkerNamespace,
+		Name:      GetNetworkPolicyName(project.Name),
+	}}
+	if project.Spec.AgentNetwork == nil || !project.DeletionTimestamp.IsZero() {
+		err = client.IgnoreNotFound(r.Delete(ctx, policy))
+		return
+	}
+
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		policy.Labels = map[string]string{constants.DevOpsProjectLabelKey: project.Name}
+		policy.Spec = r.renderNetworkPolicy(project)
+		return controllerutil.SetControllerReference(project, policy, r.Scheme())
+	}); err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "ApplyNetworkPolicyFailed",
+			"failed to apply the NetworkPolicy of agent pods, error: %v", err)
+	}
+	return
+}
+
+// renderNetworkPolicy returns the NetworkPolicy which only allows the agent pods of the DevOpsProject to reach the DNS,
+// Jenkins and the destinations of the egress rules
+func (r *Reconciler) renderNetworkPolicy(project *v1alpha3.DevOpsProject) networkingv1.NetworkPolicySpec {
+	udp, tcp := v1.ProtocolUDP, v1.ProtocolTCP
+	port := intstr.FromInt(dnsPort)
+	egress := []networkingv1.NetworkPolicyEgressRule{{
+		Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &port}, {Protocol: &tcp, Port: &port}},
+	}, {
+		To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{namespaceNameLabelKey: r.JenkinsNamespace},
+		}}},
+	}}
+	for i := range project.Spec.AgentNetwork.Egress {
+		egress = append(egress, *project.Spec.AgentNetwork.Egress[i].DeepCopy())
+	}
+
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{
+			agentLabelKey:                   agentLabelValue,
+			constants.DevOpsProjectLabelKey: project.Name,
+		}},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		Egress:      egress,
+	}
+}
+
+// GetNetworkPolicyName returns the name of the agent NetworkPolicy of a DevOpsProject
+func GetNetworkPolicyName(project string) string {
+	return "agent-" + project
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "agentnetwork"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Complete(r)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentnetwork
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, networkingv1.AddToScheme(schema))
+
+	registry := networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+	}
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", UID: "uid"},
+		Spec: v1alpha3.DevOpsProjectSpec{AgentNetwork: &v1alpha3.AgentNetworkPolicy{
+			Egress: []networkingv1.NetworkPolicyEgressRule{registry},
+		}},
+	}
+	noPolicyProject := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(project, noPolicyProject).Build()
+	r := &Reconciler{
+		Client:           c,
+		WorkerNamespace:  "worker",
+		JenkinsNamespace: "system",
+		log:              logr.Discard(),
+		recorder:         &record.FakeRecorder{},
+	}
+	reconcile := func(name string) {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		assert.Nil(t, err)
+	}
+
+	reconcile("demo")
+	policy := &networkingv1.NetworkPolicy{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "worker", Name: "agent-demo"}, policy))
+	assert.Equal(t, map[string]string{agentLabelKey: agentLabelValue, constants.DevOpsProjectLabelKey: "demo"},
+		policy.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, policy.Spec.PolicyTypes)
+	assert.Len(t, policy.Spec.Egress, 3)
+	assert.Len(t, policy.Spec.Egress[0].Ports, 2)
+	assert.Equal(t, "system", policy.Spec.Egress[1].To[0].NamespaceSelector.MatchLabels[namespaceNameLabelKey])
+	assert.Equal(t, registry, policy.Spec.Egress[2])
+	assert.Len(t, policy.OwnerReferences, 1)
+
+	// the modified NetworkPolicy is reverted
+	policy.Spec.Egress = nil
+	assert.Nil(t, c.Update(ctx, policy))
+	reconcile("demo")
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "worker", Name: "agent-demo"}, policy))
+	assert.Len(t, policy.Spec.Egress, 3)
+
+	// the NetworkPolicy is deleted once the template is removed
+	project.Spec.AgentNetwork = nil
+	assert.Nil(t, c.Update(ctx, project))
+	reconcile("demo")
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "worker", Name: "agent-demo"}, policy)))
+
+	reconcile("other")
+	reconcile("missing")
+	list := &networkingv1.NetworkPolicyList{}
+	assert.Nil(t, c.List(ctx, list))
+	assert.Empty(t, list.Items)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"kubesphere.io/devops/controllers/predicate"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+// DefaultSamplePeriod is the default period of sampling the resource usage of agent pods
+const DefaultSamplePeriod = 15 * time.Second
+
+// Reconciler records the resource usage of the running Jenkins agent pods into the status of their PipelineRuns
+type Reconciler struct {
+	client.Client
+	// MetricsReader reads the PodMetrics from the metrics server, they cannot be watched
+	MetricsReader client.Reader
+	// SamplePeriod is the period of sampling the resource usage of a running agent pod
+	SamplePeriod time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile adds a sample of the resource usage of an agent pod into its PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile Pod: %s", req.String()))
+
+	pod := &v1.Pod{}
+	if err = r.Get(ctx, req.NamespacedName, pod); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	var build *pipelinerun.AgentBuild
+	if build, err = pipelinerun.ParseRunURL(ctx, jenkins.NewFolderResolver(r.Client),
+		pod.Annotations[pipelinerun.RunURLAnnoKey]); err != nil || build == nil || pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+		return
+	}
+	var pipelineRun *v1alpha3.PipelineRun
+	if pipelineRun, err = build.FindPipelineRun(ctx, r.Client); err != nil || pipelineRun == nil {
+		return
+	}
+
+	result.RequeueAfter = r.SamplePeriod
+	podMetrics := &metricsv1beta1.PodMetrics{}
+	if err = r.MetricsReader.Get(ctx, req.NamespacedName, podMetrics); err != nil {
+		// the metrics server might not have scraped the pod yet
+		if !apierrors.IsNotFound(err) {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "MetricsUnavailable",
+				"failed to get the metrics of agent pod %s, error: %v", pod.Name, err)
+		}
+		err = nil
+		return
+	}
+
+	patch := client.MergeFromWithOptions(pipelineRun.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	usage := pipelineRun.Status.GetAgentUsage(pod.Name)
+	if usage == nil {
+		pipelineRun.Status.AgentUsage = append(pipelineRun.Status.AgentUsage, v1alpha3.AgentPodUsage{Pod: pod.Name})
+		usage = &pipelineRun.Status.AgentUsage[len(pipelineRun.Status.AgentUsage)-1]
+	}
+	if pipelinerun.AddAgentUsageSample(usage, pod, podMetrics) {
+		err = r.Status().Patch(ctx, pipelineRun, patch)
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "agentusage-pod"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.SamplePeriod <= 0 {
+		r.SamplePeriod = DefaultSamplePeriod
+	}
+	if r.MetricsReader == nil {
+		r.MetricsReader = mgr.GetAPIReader()
+	}
+	if err := metricsv1beta1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(
+			predicate.NewFilterHasAnnotation(pipelinerun.RunURLAnnoKey)))).
+		Complete(r)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, metricsv1beta1.AddToScheme(schema))
+
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "pipeline-abc",
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "3"},
+		},
+	}
+	// the folder of the DevOps project is named by the prefix strategy
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-ns",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "ns"},
+	}
+	newPod := func(name string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "jenkins",
+				Name:        name,
+				Annotations: map[string]string{pipelinerun.RunURLAnnoKey: "job/host-ns/job/pipeline/3/"},
+			},
+			Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "base"}}},
+			Status: v1.PodStatus{Phase: phase},
+		}
+	}
+	sampleTime := metav1.NewTime(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+	podMetrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "jenkins", Name: "agent"},
+		Timestamp:  sampleTime,
+		Window:     metav1.Duration{Duration: 10 * time.Second},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name:  "base",
+			Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		}},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipelineRun, project,
+		newPod("agent", v1.PodRunning), newPod("pending", v1.PodPending), newPod("no-metrics", v1.PodRunning)).Build()
+	metricsReader := fake.NewClientBuilder().WithScheme(schema).WithObjects(podMetrics).Build()
+	r := &Reconciler{
+		Client:        c,
+		MetricsReader: metricsReader,
+		SamplePeriod:  DefaultSamplePeriod,
+		log:           logr.Discard(),
+		recorder:      &record.FakeRecorder{},
+	}
+	reconcile := func(name string) ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "jenkins", Name: name}})
+		assert.Nil(t, err)
+		return result
+	}
+	getUsage := func() []v1alpha3.AgentPodUsage {
+		pr := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pipeline-abc"}, pr))
+		return pr.Status.AgentUsage
+	}
+
+	assert.Zero(t, reconcile("pending").RequeueAfter)
+	assert.Zero(t, reconcile("not-found").RequeueAfter)
+	assert.Equal(t, DefaultSamplePeriod, reconcile("no-metrics").RequeueAfter)
+	assert.Empty(t, getUsage())
+
+	assert.Equal(t, DefaultSamplePeriod, reconcile("agent").RequeueAfter)
+	usage := getUsage()
+	if assert.Len(t, usage, 1) {
+		assert.Equal(t, "agent", usage[0].Pod)
+		assert.Equal(t, int32(1), usage[0].Samples)
+		assert.Equal(t, int64(10000), usage[0].Containers[0].CPUTimeMillis)
+	}
+
+	// the same sample is skipped
+	reconcile("agent")
+	assert.Equal(t, int32(1), getUsage()[0].Samples)
+
+	podMetrics.Timestamp = metav1.NewTime(sampleTime.Add(15 * time.Second))
+	assert.Nil(t, metricsReader.Update(ctx, podMetrics))
+	reconcile("agent")
+	usage = getUsage()
+	assert.Equal(t, int32(2), usage[0].Samples)
+	assert.Equal(t, int64(25000), usage[0].Containers[0].CPUTimeMillis)
+}
+
+func TestReconciler_GetName(t *testing.T) {
+	assert.Equal(t, "agentusage-pod", (&Reconciler{}).GetName())
+}
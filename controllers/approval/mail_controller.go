@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/smtp"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/approval"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=iam.kubesphere.io,resources=users,verbs=get;list;watch
+
+// MailReconciler sends the approval emails to the approvers of Pipeline once a PipelineRun is waiting for approval,
+// the emails contain the links which approve or reject the input step with one-time tokens
+type MailReconciler struct {
+	client.Client
+	Mail        smtp.Interface
+	TokenIssuer token.Issuer
+	// ExternalURL is the address of the apiserver which the links point to
+	ExternalURL string
+	// TokenExpiration is how long the links are valid
+	TokenExpiration time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// Reconcile sends the approval emails of the new paused input steps, the nonces of the steps are recorded before
+// sending, so that the emails of a step are sent only once
+func (r *MailReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	nonces := approval.GetNonces(pipelineRun)
+	if pipelineRun.HasCompleted() {
+		// the links are not valid anymore
+		if len(nonces) > 0 {
+			approval.SetNonces(pipelineRun, nil)
+			err = r.Update(ctx, pipelineRun)
+		}
+		return
+	}
+	if pipelineRun.Status.Phase != v1alpha3.Pending {
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace,
+		Name: pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]}, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	var approvers []approval.Approver
+	if approvers, err = approval.GetApprovers(ctx, r.Client, pipeline); err != nil || len(approvers) == 0 {
+		return
+	}
+	var stages []pipelinerun.NodeDetail
+	if stages, err = pipelinerun.GetStages(ctx, r.Client, pipelineRun); err != nil {
+		return
+	}
+
+	var steps []approval.PausedStep
+	for _, step := range approval.GetPausedSteps(stages) {
+		if _, sent := nonces[step.Key()]; !sent && len(approval.GetRecipients(approvers, step.Input)) > 0 {
+			nonces[step.Key()] = approval.NewNonce()
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return
+	}
+	approval.SetNonces(pipelineRun, nonces)
+	if err = r.Update(ctx, pipelineRun); err != nil {
+		return
+	}
+
+	for i := range steps {
+		for _, approver := range approval.GetRecipients(approvers, steps[i].Input) {
+			if sendErr := r.send(ctx, pipelineRun, &steps[i], nonces[steps[i].Key()], approver); sendErr != nil {
+				// the emails are not resent, the approvers could still approve the step in the console
+				r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "SendApprovalMailFailed",
+					"failed to send the approval email to %s, error: %v", approver.Email, sendErr)
+			}
+		}
+	}
+	return
+}
+
+func (r *MailReconciler) send(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, step *approval.PausedStep,
+	nonce string, approver approval.Approver) error {
+	links := map[string]string{}
+	for _, action := range []string{approval.ActionApprove, approval.ActionReject} {
+		tokenString, err := approval.Issue(r.TokenIssuer, &approval.Request{
+			Namespace:   pipelineRun.Namespace,
+			PipelineRun: pipelineRun.Name,
+			NodeID:      step.NodeID,
+			StepID:      step.StepID,
+			InputID:     step.Input.ID,
+			Action:      action,
+			Approver:    approver.Name,
+			Nonce:       nonce,
+		}, r.TokenExpiration)
+		if err != nil {
+			return err
+		}
+		links[action] = approval.GetLink(r.ExternalURL, tokenString)
+	}
+
+	message, err := approval.NewMessage(pipelineRun, step, approver, links[approval.ActionApprove],
+		links[approval.ActionReject], r.now().Add(r.TokenExpiration))
+	if err != nil {
+		return err
+	}
+	return r.Mail.Send(ctx, message)
+}
+
+// GetName returns the name of this reconciler
+func (r *MailReconciler) GetName() string {
+	return "approval-mail"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *MailReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.now == nil {
+		r.now = time.Now
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakesmtp "kubesphere.io/devops/pkg/client/smtp/fake"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/approval"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const pausedStages = `[{"id":"10","displayName":"deploy","state":"PAUSED","steps":[
+{"id":"11","displayName":"Wait for interactive input","state":"PAUSED",
+"input":{"id":"Deploy","message":"Deploy to production?","ok":"Yes","submitter":"alice"}}]}]`
+
+func TestMailReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo", Annotations: map[string]string{
+			// the emails are taken from the users instead of the Pipeline
+			v1alpha3.PipelineApproversAnnoKey: "alice=attacker@example.com,bob",
+		}},
+	}
+	newUser := func(name, email string) *unstructured.Unstructured {
+		user := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"email": email},
+		}}
+		user.SetGroupVersionKind(approval.UserGVK)
+		user.SetName(name)
+		return user
+	}
+	newPipelineRun := func(phase v1alpha3.RunPhase, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "demo"},
+				Annotations: map[string]string{v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: pausedStages}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+		for key, value := range annotations {
+			pr.Annotations[key] = value
+		}
+		if phase == v1alpha3.Succeeded {
+			now := metav1.Now()
+			pr.Status.CompletionTime = &now
+		}
+		return pr
+	}
+	issuer := token.NewTokenIssuer("secret", time.Second)
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		sendErr     error
+		verify      func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder)
+	}{{
+		name:        "running",
+		pipelineRun: newPipelineRun(v1alpha3.Running, nil),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder) {
+			assert.Empty(t, mail.Messages)
+			assert.Empty(t, approval.GetNonces(pr))
+		},
+	}, {
+		name:        "waiting for approval",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, nil),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder) {
+			nonces := approval.GetNonces(pr)
+			assert.Len(t, nonces, 1)
+			// only the submitter receives the email
+			if assert.Len(t, mail.Messages, 1) {
+				assert.Equal(t, []string{"alice@example.com"}, mail.Messages[0].To)
+				assert.Contains(t, mail.Messages[0].HTML, "Deploy to production?")
+
+				links := regexp.MustCompile(`href="([^"]+)"`).FindAllStringSubmatch(mail.Messages[0].HTML, -1)
+				if assert.Len(t, links, 2) {
+					link, err := url.Parse(links[0][1])
+					assert.Nil(t, err)
+					assert.Equal(t, "https://devops.example.com"+approval.Path, link.Scheme+"://"+link.Host+link.Path)
+					request, err := approval.Parse(issuer, link.Query().Get("token"))
+					assert.Nil(t, err)
+					assert.Equal(t, &approval.Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "10",
+						StepID: "11", InputID: "Deploy", Action: approval.ActionApprove, Approver: "alice",
+						Nonce: nonces["10/11"]}, request)
+				}
+			}
+		},
+	}, {
+		name:        "the emails were sent",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, map[string]string{v1alpha3.PipelineRunApprovalNoncesAnnoKey: `{"10/11":"nonce"}`}),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder) {
+			assert.Empty(t, mail.Messages)
+			assert.Equal(t, map[string]string{"10/11": "nonce"}, approval.GetNonces(pr))
+		},
+	}, {
+		name:        "failed to send",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, nil),
+		sendErr:     errors.New("connection refused"),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder) {
+			// not resent
+			assert.Len(t, approval.GetNonces(pr), 1)
+			assert.Len(t, recorder.Events, 1)
+		},
+	}, {
+		name:        "completed",
+		pipelineRun: newPipelineRun(v1alpha3.Succeeded, map[string]string{v1alpha3.PipelineRunApprovalNoncesAnnoKey: `{"10/11":"nonce"}`}),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, mail *fakesmtp.FakeSMTP, recorder *record.FakeRecorder) {
+			assert.Empty(t, mail.Messages)
+			assert.NotContains(t, pr.Annotations, v1alpha3.PipelineRunApprovalNoncesAnnoKey)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline.DeepCopy(), tt.pipelineRun,
+				newUser("alice", "alice@example.com"), newUser("bob", "bob@example.com")).Build()
+			mail := fakesmtp.NewFakeSMTP()
+			mail.Err = tt.sendErr
+			recorder := record.NewFakeRecorder(10)
+			r := &MailReconciler{
+				Client:          c,
+				Mail:            mail,
+				TokenIssuer:     issuer,
+				ExternalURL:     "https://devops.example.com/",
+				TokenExpiration: time.Hour,
+				log:             logr.Discard(),
+				recorder:        recorder,
+				now:             time.Now,
+			}
+			key := types.NamespacedName{Namespace: "ns", Name: "demo-abc"}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			assert.Nil(t, err)
+
+			pr := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey(key), pr))
+			tt.verify(t, pr, mail, recorder)
+		})
+	}
+}
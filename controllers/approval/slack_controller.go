@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	devopsclient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/slack"
+	"kubesphere.io/devops/pkg/models/approval"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// SlackReconciler posts the interactive approval messages to the Slack channel of Pipeline once a PipelineRun is
+// waiting for approval, the messages contain the buttons which approve or reject the input step
+type SlackReconciler struct {
+	client.Client
+	Slack slack.Interface
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile posts the approval messages of the new paused input steps, then records them. The messages are
+// removed from the PipelineRun once it completes, so that their buttons are not valid anymore.
+func (r *SlackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	messages := approval.GetSlackMessages(pipelineRun)
+	if pipelineRun.HasCompleted() {
+		if len(messages) > 0 {
+			approval.SetSlackMessages(pipelineRun, nil)
+			err = r.Update(ctx, pipelineRun)
+		}
+		return
+	}
+	if pipelineRun.Status.Phase != v1alpha3.Pending {
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace,
+		Name: pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]}, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	channel := approval.GetSlackChannel(pipeline)
+	if channel == "" {
+		return
+	}
+	var stages []pipelinerun.NodeDetail
+	if stages, err = pipelinerun.GetStages(ctx, r.Client, pipelineRun); err != nil {
+		return
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	posted := false
+	for _, step := range approval.GetPausedSteps(stages) {
+		if _, ok := messages[step.Key()]; ok {
+			continue
+		}
+		ts, postErr := r.Slack.PostMessage(ctx, approval.NewSlackMessage(pipelineRun, &step, channel))
+		if postErr != nil {
+			// the message is not posted again, the approvers could still approve the step in the console
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "PostSlackApprovalFailed",
+				"failed to post the approval message to Slack channel %s, error: %v", channel, postErr)
+			ts = ""
+		}
+		messages[step.Key()] = approval.SlackMessage{Channel: channel, TS: ts}
+		posted = true
+	}
+	if !posted {
+		return
+	}
+	approval.SetSlackMessages(pipelineRun, messages)
+	err = r.Patch(ctx, pipelineRun, patch)
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *SlackReconciler) GetName() string {
+	return "approval-slack"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *SlackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
+
+// authorizeFunc returns true if the user is allowed to approve the PipelineRuns in the namespace
+type authorizeFunc func(ctx context.Context, user, namespace string) (bool, error)
+
+// SlackListener receives the clicks on the buttons of the approval messages in the Socket Mode of Slack, so that
+// the apiserver does not have to be exposed to Slack. The Slack user must be mapped to a KubeSphere user, who is a
+// submitter of the input step and is allowed to update the PipelineRuns, before the step is approved or rejected.
+type SlackListener struct {
+	client.Client
+	Slack        slack.Interface
+	DevOpsClient devopsclient.Interface
+
+	log       logr.Logger
+	authorize authorizeFunc
+}
+
+// Start listens to Slack until the context is done, it reconnects once the connection is closed
+func (l *SlackListener) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := l.Slack.Listen(ctx, l.handle); err != nil {
+			l.log.Error(err, "failed to listen to Slack in the Socket Mode")
+		}
+	}, 5*time.Second)
+	return nil
+}
+
+// NeedLeaderElection makes sure only one replica handles the clicks
+func (l *SlackListener) NeedLeaderElection() bool {
+	return true
+}
+
+func (l *SlackListener) handle(ctx context.Context, envelope *slack.Envelope) {
+	if envelope.Type != slack.EnvelopeTypeInteractive {
+		return
+	}
+	action, err := approval.ParseSlackAction(envelope.Payload)
+	if err != nil || action == nil {
+		return
+	}
+
+	text, err := l.submit(ctx, action)
+	if err != nil {
+		if postErr := l.Slack.PostEphemeral(ctx, &slack.Message{Channel: action.Message.Channel, User: action.UserID,
+			Text: err.Error()}); postErr != nil {
+			l.log.Error(postErr, "failed to reply to the Slack user", "user", action.UserID)
+		}
+		return
+	}
+	if err = l.Slack.UpdateMessage(ctx, approval.NewSlackResult(action.Message, text)); err != nil {
+		l.log.Error(err, "failed to update the approval message", "channel", action.Message.Channel)
+	}
+}
+
+// submit verifies the Slack user, then proceeds or aborts the input step in Jenkins. The returned error is the
+// message which replies to the Slack user.
+func (l *SlackListener) submit(ctx context.Context, action *approval.SlackAction) (text string, err error) {
+	pipelineRunKey := action.Namespace + "/" + action.PipelineRun
+	if action.Approver, err = approval.GetSlackUser(ctx, l.Client, action.UserID); err != nil {
+		l.log.Error(err, "failed to get the mapped users of Slack")
+		return "", fmt.Errorf("failed to verify your Slack account, please try again later")
+	} else if action.Approver == "" {
+		return "", fmt.Errorf("your Slack account is not mapped to a KubeSphere user")
+	}
+
+	pr := &v1alpha3.PipelineRun{}
+	if err = l.Get(ctx, client.ObjectKey{Namespace: action.Namespace, Name: action.PipelineRun}, pr); err != nil {
+		return "", fmt.Errorf("failed to get PipelineRun %s, error: %v", pipelineRunKey, client.IgnoreNotFound(err))
+	}
+	var stages []pipelinerun.NodeDetail
+	if stages, err = pipelinerun.GetStages(ctx, l.Client, pr); err != nil {
+		return "", fmt.Errorf("failed to get the stages of PipelineRun %s", pipelineRunKey)
+	}
+	var step *approval.PausedStep
+	for _, paused := range approval.GetPausedSteps(stages) {
+		if paused.NodeID == action.NodeID && paused.StepID == action.StepID {
+			step = &paused
+			break
+		}
+	}
+	if step == nil {
+		return "", fmt.Errorf("the input step of PipelineRun %s is not waiting for approval anymore", pipelineRunKey)
+	}
+	if !approval.IsSubmitter(step.Input, action.Approver) {
+		return "", fmt.Errorf("user %s is not a submitter of the input step", action.Approver)
+	}
+	if allowed, authErr := l.authorize(ctx, action.Approver, action.Namespace); authErr != nil || !allowed {
+		return "", fmt.Errorf("user %s is not allowed to approve PipelineRun %s", action.Approver, pipelineRunKey)
+	}
+
+	if pr, err = approval.ConsumeSlackMessage(ctx, l.Client, action); err != nil {
+		return "", err
+	}
+	if err = approval.Submit(l.DevOpsClient, pr, &action.Request); err != nil {
+		l.log.Error(err, "failed to submit the input step", "pipelinerun", pipelineRunKey)
+		return "", fmt.Errorf("failed to %s the input step, please try again in the console", action.Action)
+	}
+	l.log.Info(fmt.Sprintf("the input step of PipelineRun %s was %s by %s from Slack", pipelineRunKey,
+		pastTense[action.Action], action.Approver))
+	return fmt.Sprintf("PipelineRun *%s* was %s in stage *%s* by <@%s> (%s).", pipelineRunKey,
+		pastTense[action.Action], step.NodeName, action.UserID, action.Approver), nil
+}
+
+var pastTense = map[string]string{approval.ActionApprove: "approved", approval.ActionReject: "rejected"}
+
+// subjectAccessReview checks if the user is allowed to update PipelineRuns in the namespace
+func (l *SlackListener) subjectAccessReview(ctx context.Context, user, namespace string) (bool, error) {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: user,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "update",
+				Group:     devops.GroupName,
+				Resource:  "pipelineruns",
+			},
+		},
+	}
+	if err := l.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// SetupWithManager adds the listener to a manager
+func (l *SlackListener) SetupWithManager(mgr ctrl.Manager) error {
+	l.log = ctrl.Log.WithName("approval-slack-listener")
+	if l.authorize == nil {
+		l.authorize = l.subjectAccessReview
+	}
+	return mgr.Add(l)
+}
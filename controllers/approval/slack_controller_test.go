@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/client/slack"
+	fakeslack "kubesphere.io/devops/pkg/client/slack/fake"
+	"kubesphere.io/devops/pkg/models/approval"
+	"kubesphere.io/devops/pkg/models/chatops"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSlackReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo", Annotations: map[string]string{
+			v1alpha3.PipelineSlackApprovalChannelAnnoKey: "C1",
+		}},
+	}
+	newPipelineRun := func(phase v1alpha3.RunPhase, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "demo"},
+				Annotations: map[string]string{v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: pausedStages}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+		for key, value := range annotations {
+			pr.Annotations[key] = value
+		}
+		if phase == v1alpha3.Succeeded {
+			now := metav1.Now()
+			pr.Status.CompletionTime = &now
+		}
+		return pr
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		postErr     error
+		verify      func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder)
+	}{{
+		name:        "running",
+		pipelineRun: newPipelineRun(v1alpha3.Running, nil),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder) {
+			assert.Empty(t, slackClient.Messages)
+			assert.Empty(t, approval.GetSlackMessages(pr))
+		},
+	}, {
+		name:        "waiting for approval",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, nil),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder) {
+			if assert.Len(t, slackClient.Messages, 1) {
+				assert.Equal(t, "C1", slackClient.Messages[0].Channel)
+				assert.Contains(t, slackClient.Messages[0].Text, "Deploy to production?")
+			}
+			assert.Equal(t, map[string]approval.SlackMessage{"10/11": {Channel: "C1", TS: "1"}}, approval.GetSlackMessages(pr))
+		},
+	}, {
+		name: "the message was posted",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, map[string]string{
+			v1alpha3.PipelineRunSlackApprovalsAnnoKey: `{"10/11":{"channel":"C1","ts":"1.2"}}`}),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder) {
+			assert.Empty(t, slackClient.Messages)
+			assert.Equal(t, map[string]approval.SlackMessage{"10/11": {Channel: "C1", TS: "1.2"}}, approval.GetSlackMessages(pr))
+		},
+	}, {
+		name:        "failed to post",
+		pipelineRun: newPipelineRun(v1alpha3.Pending, nil),
+		postErr:     errors.New("channel_not_found"),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder) {
+			// not posted again
+			assert.Equal(t, map[string]approval.SlackMessage{"10/11": {Channel: "C1"}}, approval.GetSlackMessages(pr))
+			assert.Len(t, recorder.Events, 1)
+		},
+	}, {
+		name: "completed",
+		pipelineRun: newPipelineRun(v1alpha3.Succeeded, map[string]string{
+			v1alpha3.PipelineRunSlackApprovalsAnnoKey: `{"10/11":{"channel":"C1","ts":"1.2"}}`}),
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack, recorder *record.FakeRecorder) {
+			assert.Empty(t, slackClient.Messages)
+			assert.NotContains(t, pr.Annotations, v1alpha3.PipelineRunSlackApprovalsAnnoKey)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline.DeepCopy(), tt.pipelineRun).Build()
+			slackClient := fakeslack.NewFakeSlack()
+			slackClient.Err = tt.postErr
+			recorder := record.NewFakeRecorder(10)
+			r := &SlackReconciler{
+				Client:   c,
+				Slack:    slackClient,
+				log:      logr.Discard(),
+				recorder: recorder,
+			}
+			key := types.NamespacedName{Namespace: "ns", Name: "demo-abc"}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			assert.Nil(t, err)
+
+			pr := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey(key), pr))
+			tt.verify(t, pr, slackClient, recorder)
+		})
+	}
+}
+
+func TestSlackListener_handle(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	users := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: chatops.DefaultSecretNamespace, Name: approval.SlackUsersConfigMapName},
+		Data:       map[string]string{"U1": "alice", "U2": "bob", "U3": "carol"},
+	}
+	newPipelineRun := func() *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc",
+				Labels: map[string]string{v1alpha3.PipelineNameLabelKey: "demo"},
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunIDAnnoKey:           "1",
+					v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: pausedStages,
+					v1alpha3.PipelineRunSlackApprovalsAnnoKey:      `{"10/11":{"channel":"C1","ts":"1.2"}}`,
+				}},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Pending},
+		}
+	}
+	newEnvelope := func(user, action string) *slack.Envelope {
+		value, _ := json.Marshal(map[string]string{"namespace": "ns", "pipelinerun": "demo-abc", "node": "10",
+			"step": "11", "input": "Deploy"})
+		payload, _ := json.Marshal(map[string]interface{}{
+			"type":      "block_actions",
+			"user":      map[string]string{"id": user},
+			"container": map[string]string{"channel_id": "C1", "message_ts": "1.2"},
+			"actions":   []interface{}{map[string]string{"action_id": action, "value": string(value)}},
+		})
+		return &slack.Envelope{EnvelopeID: "1", Type: slack.EnvelopeTypeInteractive, Payload: payload}
+	}
+	// alice is the submitter, carol is a submitter but not allowed to update the PipelineRuns
+	authorize := func(ctx context.Context, user, namespace string) (bool, error) {
+		return user != "carol", nil
+	}
+	const pausedStagesWithSubmitters = `[{"id":"10","displayName":"deploy","state":"PAUSED","steps":[
+{"id":"11","displayName":"Wait for interactive input","state":"PAUSED",
+"input":{"id":"Deploy","message":"Deploy to production?","ok":"Yes","submitter":"alice,carol"}}]}]`
+
+	tests := []struct {
+		name      string
+		envelopes []*slack.Envelope
+		stages    string
+		verify    func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack)
+	}{{
+		name:      "approved",
+		envelopes: []*slack.Envelope{newEnvelope("U1", approval.ActionApprove)},
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Empty(t, approval.GetSlackMessages(pr))
+			assert.Empty(t, slackClient.Ephemerals)
+			if assert.Len(t, slackClient.Updates, 1) {
+				assert.Equal(t, "1.2", slackClient.Updates[0].TS)
+				assert.Equal(t, "PipelineRun *ns/demo-abc* was approved in stage *deploy* by <@U1> (alice).",
+					slackClient.Updates[0].Text)
+			}
+		},
+	}, {
+		name:      "clicked twice",
+		envelopes: []*slack.Envelope{newEnvelope("U1", approval.ActionReject), newEnvelope("U1", approval.ActionApprove)},
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, slackClient.Updates, 1)
+			assert.Len(t, slackClient.Ephemerals, 1)
+		},
+	}, {
+		name:      "not mapped",
+		envelopes: []*slack.Envelope{newEnvelope("U4", approval.ActionApprove)},
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, approval.GetSlackMessages(pr), 1)
+			if assert.Len(t, slackClient.Ephemerals, 1) {
+				assert.Equal(t, "U4", slackClient.Ephemerals[0].User)
+				assert.Equal(t, "your Slack account is not mapped to a KubeSphere user", slackClient.Ephemerals[0].Text)
+			}
+		},
+	}, {
+		name:      "not a submitter",
+		envelopes: []*slack.Envelope{newEnvelope("U2", approval.ActionApprove)},
+		stages:    pausedStagesWithSubmitters,
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, approval.GetSlackMessages(pr), 1)
+			if assert.Len(t, slackClient.Ephemerals, 1) {
+				assert.Equal(t, "user bob is not a submitter of the input step", slackClient.Ephemerals[0].Text)
+			}
+		},
+	}, {
+		name:      "not authorized",
+		envelopes: []*slack.Envelope{newEnvelope("U3", approval.ActionApprove)},
+		stages:    pausedStagesWithSubmitters,
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, approval.GetSlackMessages(pr), 1)
+			if assert.Len(t, slackClient.Ephemerals, 1) {
+				assert.Equal(t, "user carol is not allowed to approve PipelineRun ns/demo-abc", slackClient.Ephemerals[0].Text)
+			}
+		},
+	}, {
+		name:      "not waiting for approval",
+		envelopes: []*slack.Envelope{newEnvelope("U1", approval.ActionApprove)},
+		stages:    `[{"id":"10","displayName":"deploy","state":"FINISHED"}]`,
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, approval.GetSlackMessages(pr), 1)
+			assert.Len(t, slackClient.Ephemerals, 1)
+		},
+	}, {
+		name: "other events",
+		envelopes: []*slack.Envelope{{Type: "events_api"}, {Type: slack.EnvelopeTypeInteractive,
+			Payload: json.RawMessage(`{"type":"block_actions","actions":[{"action_id":"other"}]}`)}},
+		verify: func(t *testing.T, pr *v1alpha3.PipelineRun, slackClient *fakeslack.FakeSlack) {
+			assert.Len(t, approval.GetSlackMessages(pr), 1)
+			assert.Empty(t, slackClient.Updates)
+			assert.Empty(t, slackClient.Ephemerals)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := newPipelineRun()
+			if tt.stages != "" {
+				pr.Annotations[v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey] = tt.stages
+			}
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(users.DeepCopy(), pr).Build()
+			slackClient := fakeslack.NewFakeSlack()
+			slackClient.Envelopes = tt.envelopes
+			l := &SlackListener{
+				Client:       c,
+				Slack:        slackClient,
+				DevOpsClient: fakedevops.NewFakeDevops(nil),
+				log:          logr.Discard(),
+				authorize:    authorize,
+			}
+			assert.Nil(t, l.Slack.Listen(context.Background(), l.handle))
+
+			updated := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(pr), updated))
+			tt.verify(t, updated, slackClient)
+		})
+	}
+}
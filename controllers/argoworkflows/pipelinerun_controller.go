@@ -0,0 +1,309 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argoworkflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/argoworkflow"
+	"kubesphere.io/devops/pkg/models/freezewindow"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	compileFailedReason = "CompileFailed"
+	cancelledReason     = "Cancelled"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=freezewindows;clusterfreezewindows,verbs=get;list;watch
+//+kubebuilder:rbac:groups=argoproj.io,resources=workflows,verbs=get;list;watch;create;update;patch;delete
+
+// Reconciler executes the PipelineRuns as Argo Workflows if the engine of their Pipelines is Argo Workflows
+type Reconciler struct {
+	client.Client
+	Options argoworkflow.Options
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile creates the Workflow of a PipelineRun, and synchronizes the status of the Workflow to the PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.Buildable() || !pipelineRun.DeletionTimestamp.IsZero() ||
+		pipelineRun.Spec.PipelineRef == nil || pipelineRun.Spec.PipelineRef.Name == "" {
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRun.Spec.PipelineRef.Name}, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if pipeline.GetEngine() != v1alpha3.PipelineEngineArgoWorkflows || pipelineRun.IsMatrixParent(pipeline) {
+		return
+	}
+
+	wf := newWorkflow()
+	if err = r.Get(ctx, req.NamespacedName, wf); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return
+		}
+		if !pipelineRun.IsStopRequested() {
+			var window *freezewindow.Window
+			if window, err = freezewindow.Find(ctx, r.Client, pipeline, time.Now()); err != nil {
+				return
+			} else if window != nil {
+				return r.hold(ctx, pipelineRun, window)
+			}
+			if pipelineRun.IsWaitingForEphemeralNamespace(pipeline) {
+				// the PipelineRun is reconciled again once the ephemeral namespace is annotated
+				return
+			}
+		}
+		err = r.createWorkflow(ctx, pipeline, pipelineRun)
+		return
+	}
+
+	if err = r.controlWorkflow(ctx, wf, pipelineRun.Spec.Action); err != nil {
+		return
+	}
+
+	status := pipelineRun.Status.DeepCopy()
+	if applyWorkflowStatus(wf, status, pipelineRun.IsStopRequested(), time.Now()) {
+		pipelineRun.Status = *status
+		err = r.Status().Update(ctx, pipelineRun)
+	}
+	return
+}
+
+// hold keeps the PipelineRun from running during the freeze window
+func (r *Reconciler) hold(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, window *freezewindow.Window) (ctrl.Result, error) {
+	if freezewindow.Hold(&pipelineRun.Status, window, time.Now()) {
+		if err := r.Status().Update(ctx, pipelineRun); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, v1alpha3.Frozen, "held by freeze window %s with policy %s", window.Name, window.Policy)
+	}
+	if window.Policy == v1alpha3.FreezePolicyReject {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Until(window.End)}, nil
+}
+
+func newWorkflow() *unstructured.Unstructured {
+	wf := &unstructured.Unstructured{}
+	wf.SetGroupVersionKind(argoworkflow.WorkflowGVK)
+	return wf
+}
+
+// createWorkflow compiles the PipelineRun into a Workflow, then creates it
+func (r *Reconciler) createWorkflow(ctx context.Context, pipeline *v1alpha3.Pipeline, pipelineRun *v1alpha3.PipelineRun) (err error) {
+	now := metav1.Now()
+	if pipelineRun.IsStopRequested() {
+		finishStatus(&pipelineRun.Status, v1alpha3.Cancelled, cancelledReason,
+			"the PipelineRun was stopped before being triggered", now)
+		return r.Status().Update(ctx, pipelineRun)
+	}
+
+	var result *argoworkflow.Result
+	if result, err = argoworkflow.Compile(pipeline, pipelineRun, r.Options); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, compileFailedReason, "failed to compile the Workflow, error: %v", err)
+		finishStatus(&pipelineRun.Status, v1alpha3.Failed, compileFailedReason, err.Error(), now)
+		return r.Status().Update(ctx, pipelineRun)
+	}
+	if len(result.Warnings) > 0 {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "CompileWarning", "some parts of the Jenkinsfile were ignored: %s",
+			strings.Join(result.Warnings, "; "))
+	}
+
+	wf := result.Workflow
+	if err = controllerutil.SetControllerReference(pipelineRun, wf, r.Scheme()); err != nil {
+		return
+	}
+	if err = r.Create(ctx, wf); err != nil && !apierrors.IsAlreadyExists(err) {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "TriggerFailed", "failed to create the Workflow, error: %v", err)
+		return
+	}
+	r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, v1alpha3.Started, "Created Workflow %s", wf.GetName())
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunWorkflowAnnoKey] = wf.GetName()
+	if err = r.Patch(ctx, pipelineRun, patch); err != nil {
+		return
+	}
+
+	pipelineRun.Status.StartTime = &now
+	pipelineRun.Status.UpdateTime = &now
+	pipelineRun.Status.Phase = v1alpha3.Pending
+	freezewindow.Thaw(&pipelineRun.Status, now.Time)
+	return r.Status().Update(ctx, pipelineRun)
+}
+
+// controlWorkflow stops, suspends or resumes the Workflow according to the action of the PipelineRun
+func (r *Reconciler) controlWorkflow(ctx context.Context, wf *unstructured.Unstructured, action *v1alpha3.Action) error {
+	if action == nil {
+		return nil
+	}
+	var field string
+	var value interface{}
+	switch *action {
+	case v1alpha3.Stop:
+		// stop all the running steps immediately
+		field, value = "shutdown", "Terminate"
+	case v1alpha3.SoftStop:
+		// wait for the running steps, and skip the rest steps
+		field, value = "shutdown", "Stop"
+	case v1alpha3.Pause:
+		field, value = "suspend", true
+	case v1alpha3.Resume:
+		field, value = "suspend", false
+	default:
+		return nil
+	}
+	if current, found, _ := unstructured.NestedFieldNoCopy(wf.Object, "spec", field); found && current == value ||
+		(!found && value == false) {
+		return nil
+	}
+	patch := client.MergeFrom(wf.DeepCopy())
+	if err := unstructured.SetNestedField(wf.Object, value, "spec", field); err != nil {
+		return err
+	}
+	return r.Patch(ctx, wf, patch)
+}
+
+// applyWorkflowStatus applies the status of the Workflow to the PipelineRun status, it returns true if it changed
+func applyWorkflowStatus(wf *unstructured.Unstructured, status *v1alpha3.PipelineRunStatus, stopRequested bool, now time.Time) bool {
+	phase, _, _ := unstructured.NestedString(wf.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(wf.Object, "status", "message")
+	metaNow := metav1.NewTime(now)
+
+	var runPhase v1alpha3.RunPhase
+	switch phase {
+	case "", "Pending":
+		runPhase = v1alpha3.Pending
+	case "Running":
+		runPhase = v1alpha3.Running
+	case "Succeeded":
+		runPhase = v1alpha3.Succeeded
+	case "Failed", "Error":
+		runPhase = v1alpha3.Failed
+		if stopRequested {
+			runPhase = v1alpha3.Cancelled
+		}
+	default:
+		runPhase = v1alpha3.Unknown
+	}
+	if status.Phase == runPhase {
+		return false
+	}
+
+	if startedAt := getTime(wf, "startedAt"); startedAt != nil {
+		status.StartTime = startedAt
+	}
+	switch runPhase {
+	case v1alpha3.Succeeded, v1alpha3.Failed, v1alpha3.Cancelled:
+		completedAt := getTime(wf, "finishedAt")
+		if completedAt == nil {
+			completedAt = &metaNow
+		}
+		reason := phase
+		if runPhase == v1alpha3.Cancelled {
+			reason = cancelledReason
+		}
+		finishStatus(status, runPhase, reason, message, *completedAt)
+		status.UpdateTime = &metaNow
+	default:
+		status.Phase = runPhase
+		status.UpdateTime = &metaNow
+		status.AddCondition(&v1alpha3.Condition{
+			Type:          v1alpha3.ConditionReady,
+			Status:        v1alpha3.ConditionUnknown,
+			Reason:        phase,
+			Message:       message,
+			LastProbeTime: metaNow,
+		})
+	}
+	return true
+}
+
+// finishStatus marks the PipelineRun status as completed
+func finishStatus(status *v1alpha3.PipelineRunStatus, phase v1alpha3.RunPhase, reason, message string, completionTime metav1.Time) {
+	conditionStatus := v1alpha3.ConditionFalse
+	if phase == v1alpha3.Succeeded {
+		conditionStatus = v1alpha3.ConditionTrue
+	}
+	status.Phase = phase
+	status.CompletionTime = &completionTime
+	status.UpdateTime = &completionTime
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionSucceeded,
+		Status:        conditionStatus,
+		Reason:        reason,
+		Message:       message,
+		LastProbeTime: completionTime,
+	})
+}
+
+func getTime(wf *unstructured.Unstructured, field string) *metav1.Time {
+	value, _, _ := unstructured.NestedString(wf.Object, "status", field)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		metaTime := metav1.NewTime(t)
+		return &metaTime
+	}
+	return nil
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "argo-workflows-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Owns(newWorkflow()).
+		Complete(r)
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argoworkflows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipeline := func(engine, jenkinsfile string) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "demo",
+				Namespace:   "ns",
+				Annotations: map[string]string{v1alpha3.PipelineEngineAnnoKey: engine},
+			},
+			Spec: v1alpha3.PipelineSpec{
+				Type:     v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{Name: "demo", Jenkinsfile: jenkinsfile},
+			},
+		}
+	}
+	newPipelineRun := func(action *v1alpha3.Action, status v1alpha3.PipelineRunStatus) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns"},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "demo"},
+				Action:      action,
+			},
+			Status: status,
+		}
+	}
+	newWorkflowWithStatus := func(status map[string]interface{}) *unstructured.Unstructured {
+		wf := newWorkflow()
+		wf.SetName("demo-abc")
+		wf.SetNamespace("ns")
+		if status != nil {
+			wf.Object["status"] = status
+		}
+		return wf
+	}
+	jenkinsfile := "pipeline { stages { stage('build') { steps { sh 'make' } } } }"
+	stop, softStop := v1alpha3.Stop, v1alpha3.SoftStop
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		verify  func(t *testing.T, c client.Client)
+	}{{
+		name: "PipelineRun not found",
+	}, {
+		name: "Pipeline of Jenkins",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineJenkins, jenkinsfile),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, newWorkflow())))
+		},
+	}, {
+		name: "create the Workflow",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			wf := newWorkflow()
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, wf))
+			assert.Equal(t, 1, len(wf.GetOwnerReferences()))
+			assert.Equal(t, "demo-abc", wf.GetOwnerReferences()[0].Name)
+			assert.Equal(t, "argo", wf.Object["spec"].(map[string]interface{})["serviceAccountName"])
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, "demo-abc", pipelineRun.Annotations[v1alpha3.PipelineRunWorkflowAnnoKey])
+			assert.Equal(t, v1alpha3.Pending, pipelineRun.Status.Phase)
+			assert.NotNil(t, pipelineRun.Status.StartTime)
+		},
+	}, {
+		name: "held by a freeze window",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{}),
+			&v1alpha3.FreezeWindow{
+				ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "ns"},
+				Spec: v1alpha3.FreezeWindowSpec{Ranges: []v1alpha3.FreezeRange{{
+					Start: metav1.NewTime(time.Now().Add(-time.Hour)), End: metav1.NewTime(time.Now().Add(time.Hour)),
+				}}},
+			},
+		},
+		verify: func(t *testing.T, c client.Client) {
+			assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, newWorkflow())))
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Pending, pipelineRun.Status.Phase)
+			assert.Equal(t, v1alpha3.ConditionFrozen, pipelineRun.Status.GetLatestCondition().Type)
+		},
+	}, {
+		name: "failed to compile the Workflow",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, "pipeline {"),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Failed, pipelineRun.Status.Phase)
+			assert.True(t, pipelineRun.HasCompleted())
+			assert.Equal(t, compileFailedReason, pipelineRun.Status.GetLatestCondition().Reason)
+		},
+	}, {
+		name: "stopped before the Workflow was created",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(&stop, v1alpha3.PipelineRunStatus{}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Cancelled, pipelineRun.Status.Phase)
+			assert.True(t, apierrors.IsNotFound(c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, newWorkflow())))
+		},
+	}, {
+		name: "the Workflow is running",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{Phase: v1alpha3.Pending}),
+			newWorkflowWithStatus(map[string]interface{}{"phase": "Running", "startedAt": "2022-09-01T08:00:00Z"}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Running, pipelineRun.Status.Phase)
+			assert.Equal(t, "2022-09-01T08:00:00Z", pipelineRun.Status.StartTime.UTC().Format(time.RFC3339))
+			assert.False(t, pipelineRun.HasCompleted())
+		},
+	}, {
+		name: "the Workflow succeeded",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(nil, v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running}),
+			newWorkflowWithStatus(map[string]interface{}{"phase": "Succeeded", "finishedAt": "2022-09-01T08:10:00Z"}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Succeeded, pipelineRun.Status.Phase)
+			assert.Equal(t, "2022-09-01T08:10:00Z", pipelineRun.Status.CompletionTime.UTC().Format(time.RFC3339))
+			assert.Equal(t, v1alpha3.ConditionTrue, pipelineRun.Status.GetLatestCondition().Status)
+		},
+	}, {
+		name: "stop the Workflow",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(&stop, v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running}),
+			newWorkflowWithStatus(map[string]interface{}{"phase": "Running"}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			wf := newWorkflow()
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, wf))
+			shutdown, _, _ := unstructured.NestedString(wf.Object, "spec", "shutdown")
+			assert.Equal(t, "Terminate", shutdown)
+		},
+	}, {
+		name: "the Workflow was stopped",
+		objects: []client.Object{
+			newPipeline(v1alpha3.PipelineEngineArgoWorkflows, jenkinsfile),
+			newPipelineRun(&softStop, v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running}),
+			newWorkflowWithStatus(map[string]interface{}{"phase": "Failed", "message": "Stopped with strategy 'Stop'"}),
+		},
+		verify: func(t *testing.T, c client.Client) {
+			wf := newWorkflow()
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, wf))
+			shutdown, _, _ := unstructured.NestedString(wf.Object, "spec", "shutdown")
+			assert.Equal(t, "Stop", shutdown)
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.Equal(t, v1alpha3.Cancelled, pipelineRun.Status.Phase)
+			assert.Equal(t, "Stopped with strategy 'Stop'", pipelineRun.Status.GetLatestCondition().Message)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.objects...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+			r.Options.ServiceAccountName = "argo"
+			_, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: "ns", Name: "demo-abc"},
+			})
+			assert.Nil(t, err)
+			if tt.verify != nil {
+				tt.verify(t, c)
+			}
+		})
+	}
+}
+
+func TestReconciler_controlWorkflow(t *testing.T) {
+	pause, resume := v1alpha3.Pause, v1alpha3.Resume
+	wf := newWorkflow()
+	wf.SetName("demo-abc")
+	wf.SetNamespace("ns")
+	c := fake.NewClientBuilder().WithObjects(wf).Build()
+	r := &Reconciler{Client: c}
+
+	assert.Nil(t, r.controlWorkflow(context.Background(), wf, nil))
+	assert.Nil(t, r.controlWorkflow(context.Background(), wf, &resume))
+	_, found, _ := unstructured.NestedBool(wf.Object, "spec", "suspend")
+	assert.False(t, found)
+
+	assert.Nil(t, r.controlWorkflow(context.Background(), wf, &pause))
+	suspend, _, _ := unstructured.NestedBool(wf.Object, "spec", "suspend")
+	assert.True(t, suspend)
+
+	assert.Nil(t, r.controlWorkflow(context.Background(), wf, &resume))
+	suspend, _, _ = unstructured.NestedBool(wf.Object, "spec", "suspend")
+	assert.False(t, suspend)
+}
+
+func TestReconciler_GetName(t *testing.T) {
+	assert.Equal(t, "argo-workflows-pipelinerun", (&Reconciler{}).GetName())
+}
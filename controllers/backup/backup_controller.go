@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/models/backup"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsbackups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsbackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects;pipelines;pipelineruns,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconciler backs up the DevOps resources into the object storage according to DevOpsBackup
+type Reconciler struct {
+	client.Client
+	Storage s3.Interface
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile backs up the DevOps resources when it is time to do it
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsBackup: %s", req.String()))
+
+	devopsBackup := &v1alpha3.DevOpsBackup{}
+	if err = r.Get(ctx, req.NamespacedName, devopsBackup); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if devopsBackup.Spec.Suspend || !devopsBackup.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	if waiting, due := getNextBackup(devopsBackup, now); !due {
+		result.RequeueAfter = waiting
+		return
+	}
+
+	status := devopsBackup.Status.DeepCopy()
+	var key string
+	if key, err = r.backup(ctx, devopsBackup, now); err != nil {
+		status.Phase = v1alpha3.BackupPhaseFailed
+		status.Message = err.Error()
+		r.recorder.Eventf(devopsBackup, v1.EventTypeWarning, "BackupFailed", "failed to back up, error: %v", err)
+	} else {
+		backupTime := metav1.NewTime(now)
+		status.Phase = v1alpha3.BackupPhaseCompleted
+		status.Message = ""
+		status.LastBackupTime = &backupTime
+		status.Archives = r.pruneArchives(append(status.Archives, key), devopsBackup.Spec.MaxArchives)
+		r.recorder.Eventf(devopsBackup, v1.EventTypeNormal, "BackupCompleted", "backed up into %s", key)
+	}
+
+	devopsBackup.Status = *status
+	if updateErr := r.Status().Update(ctx, devopsBackup); updateErr != nil && err == nil {
+		err = updateErr
+	}
+	if err == nil && devopsBackup.Spec.Interval != nil {
+		result.RequeueAfter = devopsBackup.Spec.Interval.Duration
+	}
+	return
+}
+
+// getNextBackup returns the waiting duration of the next backup, or it is due
+func getNextBackup(devopsBackup *v1alpha3.DevOpsBackup, now time.Time) (waiting time.Duration, due bool) {
+	lastBackupTime := devopsBackup.Status.LastBackupTime
+	if lastBackupTime == nil {
+		due = true
+		return
+	}
+	// only back up once if there is no interval
+	if devopsBackup.Spec.Interval == nil || devopsBackup.Spec.Interval.Duration <= 0 {
+		return
+	}
+
+	waiting = lastBackupTime.Add(devopsBackup.Spec.Interval.Duration).Sub(now)
+	due = waiting <= 0
+	return
+}
+
+func (r *Reconciler) backup(ctx context.Context, devopsBackup *v1alpha3.DevOpsBackup, now time.Time) (key string, err error) {
+	var encryptionKey string
+	if encryptionKey, err = r.getEncryptionKey(ctx, devopsBackup.Spec.EncryptionSecret); err != nil {
+		return
+	}
+
+	var archive *backup.Archive
+	if archive, err = backup.Export(ctx, r.Client, backup.Options{
+		DevOpsProjects:    devopsBackup.Spec.DevOpsProjects,
+		IncludeRunHistory: devopsBackup.Spec.IncludeRunHistory,
+		EncryptionKey:     encryptionKey,
+	}); err != nil {
+		return
+	}
+
+	key = path.Join(devopsBackup.Spec.Prefix, fmt.Sprintf("%s-%s.json.gz", devopsBackup.Name, now.Format("20060102150405")))
+	err = backup.Upload(r.Storage, key, archive)
+	return
+}
+
+func (r *Reconciler) getEncryptionKey(ctx context.Context, ref v1.SecretReference) (key string, err error) {
+	secret := &v1.Secret{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return
+	}
+	if key = string(secret.Data[v1alpha3.BackupEncryptionKey]); key == "" {
+		err = fmt.Errorf("no '%s' found in secret %s/%s", v1alpha3.BackupEncryptionKey, ref.Namespace, ref.Name)
+	}
+	return
+}
+
+// pruneArchives deletes the oldest archives which are out of the max number
+func (r *Reconciler) pruneArchives(archives []string, maxArchives int) []string {
+	if maxArchives <= 0 || len(archives) <= maxArchives {
+		return archives
+	}
+
+	expired := archives[:len(archives)-maxArchives]
+	for _, key := range expired {
+		if err := r.Storage.Delete(key); err != nil {
+			r.log.Error(err, "failed to delete the expired archive", "key", key)
+		}
+	}
+	return archives[len(archives)-maxArchives:]
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "devops-backup"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha3.DevOpsBackup{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
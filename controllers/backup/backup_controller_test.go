@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetNextBackup(t *testing.T) {
+	now := time.Now()
+	lastBackupTime := metav1.NewTime(now.Add(-time.Minute))
+
+	tests := []struct {
+		name        string
+		backup      *v1alpha3.DevOpsBackup
+		wantWaiting time.Duration
+		wantDue     bool
+	}{{
+		name:    "never backed up",
+		backup:  &v1alpha3.DevOpsBackup{},
+		wantDue: true,
+	}, {
+		name: "backed up once without interval",
+		backup: &v1alpha3.DevOpsBackup{
+			Status: v1alpha3.DevOpsBackupStatus{LastBackupTime: &lastBackupTime},
+		},
+		wantDue: false,
+	}, {
+		name: "not the time to back up",
+		backup: &v1alpha3.DevOpsBackup{
+			Spec:   v1alpha3.DevOpsBackupSpec{Interval: &metav1.Duration{Duration: time.Hour}},
+			Status: v1alpha3.DevOpsBackupStatus{LastBackupTime: &lastBackupTime},
+		},
+		wantWaiting: 59 * time.Minute,
+		wantDue:     false,
+	}, {
+		name: "it is time to back up",
+		backup: &v1alpha3.DevOpsBackup{
+			Spec:   v1alpha3.DevOpsBackupSpec{Interval: &metav1.Duration{Duration: time.Second}},
+			Status: v1alpha3.DevOpsBackupStatus{LastBackupTime: &lastBackupTime},
+		},
+		wantWaiting: -59 * time.Second,
+		wantDue:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waiting, due := getNextBackup(tt.backup, now)
+			assert.Equal(t, tt.wantWaiting, waiting.Round(time.Second))
+			assert.Equal(t, tt.wantDue, due)
+		})
+	}
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.SchemeBuilder.AddToScheme(schema))
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "backup"},
+		Data:       map[string][]byte{v1alpha3.BackupEncryptionKey: []byte("key")},
+	}
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+	}
+	lastBackupTime := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name       string
+		backup     *v1alpha3.DevOpsBackup
+		objects    []client.Object
+		archives   []string
+		wantErr    bool
+		wantResult ctrl.Result
+		verify     func(t *testing.T, backup *v1alpha3.DevOpsBackup, storage *fakes3.FakeS3)
+	}{{
+		name:   "suspended",
+		backup: &v1alpha3.DevOpsBackup{Spec: v1alpha3.DevOpsBackupSpec{Suspend: true}},
+		verify: func(t *testing.T, backup *v1alpha3.DevOpsBackup, storage *fakes3.FakeS3) {
+			assert.Empty(t, backup.Status.Phase)
+		},
+	}, {
+		name: "no encryption secret",
+		backup: &v1alpha3.DevOpsBackup{Spec: v1alpha3.DevOpsBackupSpec{
+			EncryptionSecret: v1.SecretReference{Namespace: "ns", Name: "backup"},
+		}},
+		wantErr: true,
+		verify: func(t *testing.T, backup *v1alpha3.DevOpsBackup, storage *fakes3.FakeS3) {
+			assert.Equal(t, v1alpha3.BackupPhaseFailed, backup.Status.Phase)
+			assert.Nil(t, backup.Status.LastBackupTime)
+		},
+	}, {
+		name: "back up once",
+		backup: &v1alpha3.DevOpsBackup{Spec: v1alpha3.DevOpsBackupSpec{
+			EncryptionSecret: v1.SecretReference{Namespace: "ns", Name: "backup"},
+			Prefix:           "devops",
+		}},
+		objects: []client.Object{secret.DeepCopy(), project.DeepCopy()},
+		verify: func(t *testing.T, backup *v1alpha3.DevOpsBackup, storage *fakes3.FakeS3) {
+			assert.Equal(t, v1alpha3.BackupPhaseCompleted, backup.Status.Phase)
+			assert.NotNil(t, backup.Status.LastBackupTime)
+			assert.Equal(t, 1, len(backup.Status.Archives))
+			assert.Regexp(t, "^devops/fake-[0-9]{14}.json.gz$", backup.Status.Archives[0])
+			assert.NotNil(t, storage.Storage[backup.Status.Archives[0]])
+		},
+	}, {
+		name: "scheduled backup with expired archives",
+		backup: &v1alpha3.DevOpsBackup{
+			Spec: v1alpha3.DevOpsBackupSpec{
+				Interval:         &metav1.Duration{Duration: time.Minute},
+				EncryptionSecret: v1.SecretReference{Namespace: "ns", Name: "backup"},
+				MaxArchives:      2,
+			},
+			Status: v1alpha3.DevOpsBackupStatus{
+				LastBackupTime: &lastBackupTime,
+				Archives:       []string{"a", "b"},
+			},
+		},
+		objects:    []client.Object{secret.DeepCopy(), project.DeepCopy()},
+		archives:   []string{"a", "b"},
+		wantResult: ctrl.Result{RequeueAfter: time.Minute},
+		verify: func(t *testing.T, backup *v1alpha3.DevOpsBackup, storage *fakes3.FakeS3) {
+			assert.Equal(t, 2, len(backup.Status.Archives))
+			assert.Equal(t, "b", backup.Status.Archives[0])
+			assert.Nil(t, storage.Storage["a"])
+			assert.NotNil(t, storage.Storage["b"])
+		},
+	}, {
+		name: "not the time to back up",
+		backup: &v1alpha3.DevOpsBackup{
+			Spec: v1alpha3.DevOpsBackupSpec{
+				Interval: &metav1.Duration{Duration: 2 * time.Hour},
+			},
+			Status: v1alpha3.DevOpsBackupStatus{LastBackupTime: &lastBackupTime},
+		},
+		wantResult: ctrl.Result{RequeueAfter: time.Hour},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.backup.Name = "fake"
+			storage := fakes3.NewFakeS3()
+			for _, key := range tt.archives {
+				assert.Nil(t, storage.Upload(key, key, nil))
+			}
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(append(tt.objects, tt.backup)...).Build()
+			r := &Reconciler{
+				Client:   c,
+				Storage:  storage,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "fake"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantResult, ctrl.Result{RequeueAfter: result.RequeueAfter.Round(time.Minute)})
+
+			if tt.verify != nil {
+				backup := &v1alpha3.DevOpsBackup{}
+				assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Name: "fake"}, backup))
+				tt.verify(t, backup, storage)
+			}
+		})
+	}
+}
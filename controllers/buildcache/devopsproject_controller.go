@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/buildcache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+
+// Reconciler syncs the S3 credentials of the build caches of DevOpsProjects into the Secrets in the namespace of
+// Jenkins agents. The Secrets are referred by the environment variables which the agent preset webhook injects.
+type Reconciler struct {
+	client.Client
+	// WorkerNamespace is the namespace of the Jenkins agent pods
+	WorkerNamespace string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile syncs the credential of a DevOpsProject from its admin namespace, or deletes the synced Secret if the
+// S3 cache is removed
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		// the Secret is owned by the DevOpsProject, it's deleted by the garbage collector
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: r.WorkerNamespace,
+		Name:      buildcache.GetSecretName(project.Name),
+	}}
+	cache := project.Spec.BuildCache
+	if cache == nil || cache.S3 == nil || !project.DeletionTimestamp.IsZero() {
+		err = client.IgnoreNotFound(r.Delete(ctx, secret))
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" {
+		return
+	}
+
+	var data map[string][]byte
+	if data, err = buildcache.RenderSecret(cache, func(name string) (*v1.Secret, error) {
+		credential := &v1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, credential)
+		return credential, err
+	}); err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "SyncBuildCacheFailed",
+			"failed to sync the credential of the build cache, error: %v", err)
+		return
+	}
+
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = map[string]string{constants.DevOpsProjectLabelKey: project.Name}
+		secret.Type = v1.SecretTypeOpaque
+		secret.Data = data
+		return controllerutil.SetControllerReference(project, secret, r.Scheme())
+	}); err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "SyncBuildCacheFailed",
+			"failed to apply the credential of the build cache, error: %v", err)
+	}
+	return
+}
+
+// findProjects returns the DevOpsProject whose build cache refers to the credential
+func (r *Reconciler) findProjects(credential client.Object) (requests []reconcile.Request) {
+	ctx := context.Background()
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: credential.GetNamespace()}, ns); err != nil {
+		return
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return
+	}
+	if cache := project.Spec.BuildCache; cache != nil && cache.S3 != nil && cache.S3.CredentialID == credential.GetName() {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: project.Name}})
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "buildcache"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Owns(&v1.Secret{}).
+		// the credential is synced again once it's changed
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findProjects)).
+		Complete(r)
+}
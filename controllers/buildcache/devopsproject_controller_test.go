@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/buildcache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", UID: "uid"},
+		Spec: v1alpha3.DevOpsProjectSpec{BuildCache: &v1alpha3.BuildCache{
+			S3: &v1alpha3.S3BuildCache{Bucket: "cache", Region: "us-east-1", CredentialID: "s3"},
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo-ns"},
+	}
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo-ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "demo"},
+	}}
+	credential := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-ns", Name: "s3"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			v1alpha3.BasicAuthUsernameKey: []byte("access"),
+			v1alpha3.BasicAuthPasswordKey: []byte("secret"),
+		},
+	}
+	registryProject := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Spec: v1alpha3.DevOpsProjectSpec{BuildCache: &v1alpha3.BuildCache{
+			Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"},
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "other-ns"},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(project, ns, registryProject).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:          c,
+		WorkerNamespace: "worker",
+		log:             logr.Discard(),
+		recorder:        recorder,
+	}
+	reconcileProject := func(name string) error {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		return err
+	}
+	secretKey := client.ObjectKey{Namespace: "worker", Name: "buildcache-demo"}
+
+	// the credential is not found
+	assert.NotNil(t, reconcileProject("demo"))
+	assert.Len(t, recorder.Events, 1)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, secretKey, &v1.Secret{})))
+
+	assert.Nil(t, c.Create(ctx, credential))
+	assert.Nil(t, reconcileProject("demo"))
+	secret := &v1.Secret{}
+	assert.Nil(t, c.Get(ctx, secretKey, secret))
+	assert.Equal(t, map[string]string{constants.DevOpsProjectLabelKey: "demo"}, secret.Labels)
+	assert.Equal(t, "access", string(secret.Data[buildcache.AccessKeyIDKey]))
+	assert.Equal(t, "secret", string(secret.Data[buildcache.SecretAccessKeyKey]))
+	assert.Len(t, secret.OwnerReferences, 1)
+
+	// the credential is synced again once it's changed
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "demo"}}}, r.findProjects(credential))
+	assert.Empty(t, r.findProjects(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "demo-ns", Name: "other"}}))
+	assert.Empty(t, r.findProjects(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "fake", Name: "s3"}}))
+	credential.Data[v1alpha3.BasicAuthPasswordKey] = []byte("new-secret")
+	assert.Nil(t, c.Update(ctx, credential))
+	assert.Nil(t, reconcileProject("demo"))
+	assert.Nil(t, c.Get(ctx, secretKey, secret))
+	assert.Equal(t, "new-secret", string(secret.Data[buildcache.SecretAccessKeyKey]))
+
+	// the Secret is deleted once the S3 cache is removed
+	project = &v1alpha3.DevOpsProject{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Name: "demo"}, project))
+	project.Spec.BuildCache = &v1alpha3.BuildCache{Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"}}
+	assert.Nil(t, c.Update(ctx, project))
+	assert.Nil(t, reconcileProject("demo"))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, secretKey, secret)))
+
+	// the registry cache doesn't need a Secret
+	assert.Nil(t, reconcileProject("other"))
+	assert.Nil(t, reconcileProject("missing"))
+	list := &v1.SecretList{}
+	assert.Nil(t, c.List(ctx, list, client.InNamespace("worker")))
+	assert.Empty(t, list.Items)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	stepsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_build_cache_steps_total",
+		Help: "The cacheable steps of the image builds of PipelineRuns",
+	}, []string{"namespace", "pipeline", "builder"})
+	hitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_build_cache_hits_total",
+		Help: "The steps of the image builds of PipelineRuns which hit the build cache",
+	}, []string{"namespace", "pipeline", "builder"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stepsTotal, hitsTotal)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/buildcache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// moreDataHeader is the header of the progressive text API, it's true if the log is still being written
+	moreDataHeader = "X-More-Data"
+	// drainPeriod is the period of waiting for Jenkins to flush the log after the PipelineRun completed
+	drainPeriod = 3 * time.Second
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// MetricsReconciler collects the build cache usage of the completed PipelineRuns. The build image step prints a
+// report after building an image, the reports are parsed from the log and added up into the metrics.
+type MetricsReconciler struct {
+	client.Client
+	DevOpsClient devops.Interface
+
+	log logr.Logger
+}
+
+// Reconcile parses the cache reports from the log of a completed PipelineRun, it only works if the build cache is
+// configured in the DevOpsProject
+func (r *MetricsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	runID, exists := pipelineRun.GetPipelineRunID()
+	if _, recorded := pipelineRun.Annotations[v1alpha3.PipelineRunBuildCacheAnnoKey]; !exists || recorded ||
+		!pipelineRun.HasCompleted() {
+		return
+	}
+	var configured bool
+	if configured, err = r.isConfigured(ctx, pipelineRun.Namespace); err != nil || !configured {
+		return
+	}
+
+	var data []byte
+	var header http.Header
+	if data, header, err = r.getLog(pipelineRun, runID); err != nil {
+		return
+	}
+	if header.Get(moreDataHeader) == "true" {
+		// Jenkins might still be flushing the log after the PipelineRun completed
+		result.RequeueAfter = drainPeriod
+		return
+	}
+
+	reports := buildcache.ParseReports(string(data))
+	var steps, cached int
+	for _, report := range reports {
+		steps += report.Steps
+		cached += report.Cached
+	}
+	// the usage is recorded before the metrics are added, so they are not added twice if the patch fails
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunBuildCacheAnnoKey] = fmt.Sprintf("%d/%d", cached, steps)
+	if err = client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch)); err != nil {
+		return
+	}
+
+	pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+	for _, report := range reports {
+		stepsTotal.WithLabelValues(pipelineRun.Namespace, pipelineName, report.Builder).Add(float64(report.Steps))
+		hitsTotal.WithLabelValues(pipelineRun.Namespace, pipelineName, report.Builder).Add(float64(report.Cached))
+	}
+	return
+}
+
+// isConfigured returns true if the build cache is configured in the DevOpsProject which the namespace belongs to
+func (r *MetricsReconciler) isConfigured(ctx context.Context, namespace string) (bool, error) {
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return false, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return project.Spec.BuildCache != nil, nil
+}
+
+// getLog fetches the whole log of a PipelineRun through the progressive text API
+func (r *MetricsReconciler) getLog(pipelineRun *v1alpha3.PipelineRun, runID string) ([]byte, http.Header, error) {
+	pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{RawQuery: url.Values{"start": []string{"0"}}.Encode()},
+	}
+	if pipelineRun.Spec.IsMultiBranchPipeline() && pipelineRun.Spec.SCM != nil {
+		return r.DevOpsClient.GetBranchProgressiveRunLog(pipelineRun.Namespace, pipelineName,
+			pipelineRun.Spec.SCM.RefName, runID, params)
+	}
+	return r.DevOpsClient.GetProgressiveRunLog(pipelineRun.Namespace, pipelineName, runID, params)
+}
+
+// GetName returns the name of this reconciler
+func (r *MetricsReconciler) GetName() string {
+	return "buildcache-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *MetricsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
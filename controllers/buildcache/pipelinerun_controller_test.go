@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMetricsReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "project"},
+	}}
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "project"},
+		Spec: v1alpha3.DevOpsProjectSpec{BuildCache: &v1alpha3.BuildCache{
+			Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"},
+		}},
+	}
+	key := types.NamespacedName{Namespace: "ns", Name: "pr"}
+	newPipelineRun := func(pipeline, runID string, completed bool, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+				Annotations: map[string]string{},
+			},
+		}
+		if runID != "" {
+			pr.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = runID
+		}
+		for k, v := range annotations {
+			pr.Annotations[k] = v
+		}
+		if completed {
+			pr.Status.CompletionTime = &metav1.Time{Time: metav1.Now().Time}
+		}
+		return pr
+	}
+	log := `+ printf '[build-cache] builder=%s steps=%d cached=%d\n' buildkit 5 3
+[build-cache] builder=buildkit steps=5 cached=3
+[build-cache] builder=kaniko steps=4 cached=1
+`
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		objects     []client.Object
+		logMore     bool
+		requeue     bool
+		usage       string
+		hits        float64
+		steps       float64
+	}{{
+		name:        "not completed",
+		pipelineRun: newPipelineRun("running", "1", false, nil),
+		objects:     []client.Object{ns, project},
+	}, {
+		name:        "not triggered",
+		pipelineRun: newPipelineRun("untriggered", "", true, nil),
+		objects:     []client.Object{ns, project},
+	}, {
+		name:        "not configured",
+		pipelineRun: newPipelineRun("unconfigured", "1", true, nil),
+		objects:     []client.Object{ns},
+	}, {
+		name:        "already recorded",
+		pipelineRun: newPipelineRun("recorded", "1", true, map[string]string{v1alpha3.PipelineRunBuildCacheAnnoKey: "1/2"}),
+		objects:     []client.Object{ns, project},
+		usage:       "1/2",
+	}, {
+		name:        "log is being flushed",
+		pipelineRun: newPipelineRun("flushing", "1", true, nil),
+		objects:     []client.Object{ns, project},
+		logMore:     true,
+		requeue:     true,
+	}, {
+		name:        "record the usage",
+		pipelineRun: newPipelineRun("pipeline", "1", true, nil),
+		objects:     []client.Object{ns, project},
+		usage:       "4/9",
+		hits:        3,
+		steps:       5,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineName := tt.pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+			devopsClient := fakedevops.New("ns")
+			devopsClient.Data = map[string]interface{}{
+				"ns-" + pipelineName + "-1":      log,
+				"ns-" + pipelineName + "-1-more": tt.logMore,
+			}
+
+			objects := []client.Object{tt.pipelineRun}
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(append(objects, tt.objects...)...).Build()
+			r := &MetricsReconciler{
+				Client:       c,
+				DevOpsClient: devopsClient,
+				log:          logr.Discard(),
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.requeue, result.RequeueAfter > 0)
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), key, pipelineRun))
+			usage, recorded := pipelineRun.Annotations[v1alpha3.PipelineRunBuildCacheAnnoKey]
+			assert.Equal(t, tt.usage != "", recorded)
+			assert.Equal(t, tt.usage, usage)
+			assert.Equal(t, tt.hits, testutil.ToFloat64(hitsTotal.WithLabelValues("ns", pipelineName, "buildkit")))
+			assert.Equal(t, tt.steps, testutil.ToFloat64(stepsTotal.WithLabelValues("ns", pipelineName, "buildkit")))
+		})
+	}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkoperation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=bulkoperations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=bulkoperations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;create;update
+
+// checkpointSize is the number of the operated items between two updates of the status
+const checkpointSize = 10
+
+// Reconciler operates the Pipelines or PipelineRuns of a namespace according to BulkOperation
+type Reconciler struct {
+	client.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// target is a Pipeline or PipelineRun to operate
+type target struct {
+	name string
+	// operate returns the name of the created PipelineRun if there is
+	operate func(ctx context.Context) (pipelineRun string, err error)
+}
+
+// Reconcile operates all the targets of a BulkOperation, the progress is recorded into the status
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile BulkOperation: %s", req.String()))
+
+	operation := &v1alpha3.BulkOperation{}
+	if err = r.Get(ctx, req.NamespacedName, operation); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if operation.HasCompleted() || !operation.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	var targets []target
+	if targets, err = r.getTargets(ctx, operation); err != nil {
+		return
+	}
+
+	status := &operation.Status
+	if status.Phase == "" {
+		now := metav1.Now()
+		status.Phase = v1alpha3.BulkOperationRunning
+		status.StartTime = &now
+	}
+	// the operated items are skipped if the operation was interrupted
+	operated := make(map[string]bool, len(status.Items))
+	for _, item := range status.Items {
+		operated[item.Name] = true
+	}
+	status.Total = len(operated)
+	for _, target := range targets {
+		if !operated[target.name] {
+			status.Total++
+		}
+	}
+
+	count := 0
+	for _, target := range targets {
+		if operated[target.name] {
+			continue
+		}
+		count++
+		item := v1alpha3.BulkOperationItem{Name: target.name, Result: v1alpha3.BulkOperationItemSucceeded}
+		var opErr error
+		if item.PipelineRun, opErr = target.operate(ctx); opErr != nil {
+			item.Result = v1alpha3.BulkOperationItemFailed
+			item.Message = opErr.Error()
+			status.Failed++
+		} else {
+			status.Succeeded++
+		}
+		status.Items = append(status.Items, item)
+
+		if count%checkpointSize == 0 {
+			if err = r.Status().Update(ctx, operation); err != nil {
+				return
+			}
+		}
+	}
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	if status.Failed > 0 {
+		status.Phase = v1alpha3.BulkOperationFailed
+		r.recorder.Eventf(operation, v1.EventTypeWarning, "BulkOperationFailed",
+			"%d of %d items failed", status.Failed, status.Total)
+	} else {
+		status.Phase = v1alpha3.BulkOperationSucceeded
+		r.recorder.Eventf(operation, v1.EventTypeNormal, "BulkOperationSucceeded",
+			"operated %d items", status.Total)
+	}
+	err = r.Status().Update(ctx, operation)
+	return
+}
+
+// getTargets returns the Pipelines or PipelineRuns to operate according to the action
+func (r *Reconciler) getTargets(ctx context.Context, operation *v1alpha3.BulkOperation) (targets []target, err error) {
+	switch operation.Spec.Action {
+	case v1alpha3.BulkActionDisablePipelines:
+		targets, err = r.getPipelinesToDisable(ctx, operation)
+	case v1alpha3.BulkActionAbortRuns:
+		targets, err = r.getRunsToAbort(ctx, operation)
+	case v1alpha3.BulkActionRetriggerFailedRuns:
+		targets, err = r.getRunsToRetrigger(ctx, operation)
+	default:
+		err = fmt.Errorf("unsupported action: %s", operation.Spec.Action)
+	}
+	return
+}
+
+func (r *Reconciler) getPipelinesToDisable(ctx context.Context, operation *v1alpha3.BulkOperation) (targets []target, err error) {
+	pipelineList := &v1alpha3.PipelineList{}
+	if err = r.List(ctx, pipelineList, client.InNamespace(operation.Namespace)); err != nil {
+		return
+	}
+	for i := range pipelineList.Items {
+		pipeline := &pipelineList.Items[i]
+		if !isSelected(operation, pipeline.Name) {
+			continue
+		}
+		targets = append(targets, target{
+			name: pipeline.Name,
+			operate: func(ctx context.Context) (string, error) {
+				return "", r.suspend(ctx, types.NamespacedName{Namespace: pipeline.Namespace, Name: pipeline.Name})
+			},
+		})
+	}
+	return
+}
+
+// suspend makes a Pipeline ignore all the triggers, the Pipeline controller disables its Jenkins job
+func (r *Reconciler) suspend(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipeline := &v1alpha3.Pipeline{}
+		if err := r.Get(ctx, key, pipeline); err != nil {
+			return err
+		}
+		if pipeline.IsSuspended() {
+			return nil
+		}
+		pipeline.Spec.Suspend = true
+		return r.Update(ctx, pipeline)
+	})
+}
+
+func (r *Reconciler) getRunsToAbort(ctx context.Context, operation *v1alpha3.BulkOperation) (targets []target, err error) {
+	var runs []*v1alpha3.PipelineRun
+	if runs, err = r.listPipelineRuns(ctx, operation); err != nil {
+		return
+	}
+	for i := range runs {
+		run := runs[i]
+		if run.HasCompleted() {
+			continue
+		}
+		targets = append(targets, target{
+			name: run.Name,
+			operate: func(ctx context.Context) (string, error) {
+				return "", r.abort(ctx, types.NamespacedName{Namespace: run.Namespace, Name: run.Name})
+			},
+		})
+	}
+	return
+}
+
+// abort requests to stop a PipelineRun, the PipelineRun controller takes care of the rest
+func (r *Reconciler) abort(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		run := &v1alpha3.PipelineRun{}
+		if err := r.Get(ctx, key, run); err != nil {
+			return err
+		}
+		if run.HasCompleted() || (run.Spec.Action != nil && *run.Spec.Action == v1alpha3.Stop) {
+			return nil
+		}
+		action := v1alpha3.Stop
+		run.Spec.Action = &action
+		return r.Update(ctx, run)
+	})
+}
+
+func (r *Reconciler) getRunsToRetrigger(ctx context.Context, operation *v1alpha3.BulkOperation) (targets []target, err error) {
+	var runs []*v1alpha3.PipelineRun
+	if runs, err = r.listPipelineRuns(ctx, operation); err != nil {
+		return
+	}
+
+	// find the latest PipelineRun of each branch of each Pipeline
+	latestRuns := map[string]*v1alpha3.PipelineRun{}
+	for _, run := range runs {
+		// the PipelineRuns of a matrix are re-triggered by their parent
+		if _, ok := run.Labels[v1alpha3.PipelineRunMatrixParentLabelKey]; ok {
+			continue
+		}
+		key := run.Labels[v1alpha3.PipelineNameLabelKey]
+		if run.Spec.SCM != nil {
+			key += "/" + run.Spec.SCM.RefName
+		}
+		if latest, ok := latestRuns[key]; !ok || latest.CreationTimestamp.Before(&run.CreationTimestamp) {
+			latestRuns[key] = run
+		}
+	}
+
+	for _, run := range latestRuns {
+		if run.Status.Phase != v1alpha3.Failed {
+			continue
+		}
+		failedRun := run
+		targets = append(targets, target{
+			name: failedRun.Name,
+			operate: func(ctx context.Context) (string, error) {
+				return r.retrigger(ctx, operation, failedRun)
+			},
+		})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].name < targets[j].name
+	})
+	return
+}
+
+// retrigger creates a PipelineRun with the same parameters and SCM of a failed one
+func (r *Reconciler) retrigger(ctx context.Context, operation *v1alpha3.BulkOperation, run *v1alpha3.PipelineRun) (string, error) {
+	pipeline := &v1alpha3.Pipeline{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Namespace: run.Namespace,
+		Name:      run.Labels[v1alpha3.PipelineNameLabelKey],
+	}, pipeline); err != nil {
+		return "", err
+	}
+	if pipeline.IsSuspended() {
+		return "", fmt.Errorf("pipeline %s is suspended", pipeline.Name)
+	}
+
+	newRun := pipelinerun.CreateBarePipelineRun(pipeline, run.Spec.Parameters, run.Spec.SCM.DeepCopy())
+	newRun.Annotations[v1alpha3.BulkOperationAnnoKey] = operation.Name
+	if creator := operation.Annotations[v1alpha3.PipelineRunCreatorAnnoKey]; creator != "" {
+		newRun.Annotations[v1alpha3.PipelineRunCreatorAnnoKey] = creator
+	}
+	if err := r.Create(ctx, newRun); err != nil {
+		return "", err
+	}
+	return newRun.Name, nil
+}
+
+// listPipelineRuns returns the PipelineRuns of the selected Pipelines and branch, sorted by name
+func (r *Reconciler) listPipelineRuns(ctx context.Context, operation *v1alpha3.BulkOperation) (runs []*v1alpha3.PipelineRun, err error) {
+	runList := &v1alpha3.PipelineRunList{}
+	if err = r.List(ctx, runList, client.InNamespace(operation.Namespace)); err != nil {
+		return
+	}
+	for i := range runList.Items {
+		run := &runList.Items[i]
+		pipelineName := run.Labels[v1alpha3.PipelineNameLabelKey]
+		if pipelineName == "" || !isSelected(operation, pipelineName) {
+			continue
+		}
+		if branch := operation.Spec.Branch; branch != "" && (run.Spec.SCM == nil || run.Spec.SCM.RefName != branch) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Name < runs[j].Name
+	})
+	return
+}
+
+// isSelected returns true if the Pipeline is selected by the operation
+func isSelected(operation *v1alpha3.BulkOperation, pipeline string) bool {
+	if len(operation.Spec.Pipelines) == 0 {
+		return true
+	}
+	for _, name := range operation.Spec.Pipelines {
+		if name == pipeline {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "bulkoperation"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha3.BulkOperation{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkoperation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newPipeline := func(name string) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			TypeMeta:   metav1.TypeMeta{Kind: "Pipeline", APIVersion: v1alpha3.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec:       v1alpha3.PipelineSpec{Type: v1alpha3.MultiBranchPipelineType},
+		}
+	}
+	newRun := func(name, pipeline, branch string, age time.Duration, phase v1alpha3.RunPhase) *v1alpha3.PipelineRun {
+		run := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "ns",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				SCM:        &v1alpha3.SCM{RefName: branch},
+				Parameters: []v1alpha3.Parameter{{Name: "name", Value: name}},
+			},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+		if phase == v1alpha3.Succeeded || phase == v1alpha3.Failed {
+			completionTime := metav1.NewTime(now.Add(-age).Add(time.Minute))
+			run.Status.CompletionTime = &completionTime
+		}
+		return run
+	}
+	objects := []client.Object{
+		newPipeline("p1"), newPipeline("p2"), newPipeline("p3"),
+		newRun("p1-1", "p1", "main", 3*time.Hour, v1alpha3.Succeeded),
+		newRun("p1-2", "p1", "main", 2*time.Hour, v1alpha3.Failed),
+		newRun("p1-3", "p1", "dev", time.Hour, v1alpha3.Running),
+		newRun("p2-1", "p2", "main", 2*time.Hour, v1alpha3.Failed),
+		newRun("p2-2", "p2", "main", time.Hour, v1alpha3.Succeeded),
+		newRun("p3-1", "p3", "main", time.Hour, v1alpha3.Running),
+		newRun("p3-2", "p3", "main", time.Minute, v1alpha3.Pending),
+	}
+
+	tests := []struct {
+		name      string
+		operation *v1alpha3.BulkOperation
+		wantErr   bool
+		verify    func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation)
+	}{{
+		name: "disable all the Pipelines",
+		operation: &v1alpha3.BulkOperation{
+			Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionDisablePipelines},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Equal(t, []string{"p1", "p2", "p3"}, getSuspendedPipelines(t, c))
+			assert.Equal(t, v1alpha3.BulkOperationSucceeded, operation.Status.Phase)
+			assert.Equal(t, 3, operation.Status.Total)
+			assert.Equal(t, 3, operation.Status.Succeeded)
+			assert.Equal(t, v1alpha3.BulkOperationItem{Name: "p2", Result: v1alpha3.BulkOperationItemSucceeded},
+				operation.Status.Items[1])
+			assert.NotNil(t, operation.Status.StartTime)
+			assert.NotNil(t, operation.Status.CompletionTime)
+		},
+	}, {
+		name: "abort the running PipelineRuns of a branch",
+		operation: &v1alpha3.BulkOperation{
+			Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionAbortRuns, Branch: "main"},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Equal(t, v1alpha3.BulkOperationSucceeded, operation.Status.Phase)
+			assert.Equal(t, []v1alpha3.BulkOperationItem{
+				{Name: "p3-1", Result: v1alpha3.BulkOperationItemSucceeded},
+				{Name: "p3-2", Result: v1alpha3.BulkOperationItemSucceeded},
+			}, operation.Status.Items)
+			for name, stopped := range map[string]bool{"p3-1": true, "p3-2": true, "p1-3": false, "p1-2": false} {
+				run := &v1alpha3.PipelineRun{}
+				assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: name}, run))
+				assert.Equal(t, stopped, run.Spec.Action != nil && *run.Spec.Action == v1alpha3.Stop, name)
+			}
+		},
+	}, {
+		name: "re-trigger the latest failed PipelineRuns",
+		operation: &v1alpha3.BulkOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1alpha3.PipelineRunCreatorAnnoKey: "admin"},
+			},
+			Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionRetriggerFailedRuns, Branch: "main"},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Equal(t, v1alpha3.BulkOperationSucceeded, operation.Status.Phase)
+			assert.Equal(t, 1, len(operation.Status.Items))
+			item := operation.Status.Items[0]
+			assert.Equal(t, "p1-2", item.Name)
+			assert.NotEmpty(t, item.PipelineRun)
+
+			run := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: item.PipelineRun}, run))
+			assert.Equal(t, "fake", run.Annotations[v1alpha3.BulkOperationAnnoKey])
+			assert.Equal(t, "admin", run.Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+			assert.Equal(t, "p1", run.Labels[v1alpha3.PipelineNameLabelKey])
+			assert.Equal(t, "main", run.Spec.SCM.RefName)
+			assert.Equal(t, []v1alpha3.Parameter{{Name: "name", Value: "p1-2"}}, run.Spec.Parameters)
+		},
+	}, {
+		name: "only the selected Pipelines",
+		operation: &v1alpha3.BulkOperation{
+			Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionAbortRuns, Pipelines: []string{"p1"}},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Equal(t, []v1alpha3.BulkOperationItem{
+				{Name: "p1-3", Result: v1alpha3.BulkOperationItemSucceeded},
+			}, operation.Status.Items)
+		},
+	}, {
+		name: "skip the operated items",
+		operation: &v1alpha3.BulkOperation{
+			Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionDisablePipelines},
+			Status: v1alpha3.BulkOperationStatus{
+				Phase:     v1alpha3.BulkOperationRunning,
+				Succeeded: 1,
+				Items:     []v1alpha3.BulkOperationItem{{Name: "p1", Result: v1alpha3.BulkOperationItemSucceeded}},
+			},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Equal(t, []string{"p2", "p3"}, getSuspendedPipelines(t, c))
+			assert.Equal(t, 3, operation.Status.Total)
+			assert.Equal(t, 3, operation.Status.Succeeded)
+		},
+	}, {
+		name: "completed operation",
+		operation: &v1alpha3.BulkOperation{
+			Spec:   v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionDisablePipelines},
+			Status: v1alpha3.BulkOperationStatus{Phase: v1alpha3.BulkOperationSucceeded},
+		},
+		verify: func(t *testing.T, c client.Client, operation *v1alpha3.BulkOperation) {
+			assert.Empty(t, getSuspendedPipelines(t, c))
+		},
+	}, {
+		name: "unknown action",
+		operation: &v1alpha3.BulkOperation{
+			Spec: v1alpha3.BulkOperationSpec{Action: "fake"},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.operation.Namespace = "ns"
+			tt.operation.Name = "fake"
+			var initObjects []client.Object
+			for _, obj := range append(objects, tt.operation) {
+				initObjects = append(initObjects, obj.DeepCopyObject().(client.Object))
+			}
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(initObjects...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "fake"}})
+			assert.Equal(t, tt.wantErr, err != nil, fmt.Sprint(err))
+
+			if tt.verify != nil {
+				operation := &v1alpha3.BulkOperation{}
+				assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "fake"}, operation))
+				tt.verify(t, c, operation)
+			}
+		})
+	}
+}
+
+func getSuspendedPipelines(t *testing.T, c client.Client) (names []string) {
+	pipelineList := &v1alpha3.PipelineList{}
+	assert.Nil(t, c.List(context.TODO(), pipelineList))
+	for _, pipeline := range pipelineList.Items {
+		if pipeline.IsSuspended() {
+			names = append(names, pipeline.Name)
+		}
+	}
+	return
+}
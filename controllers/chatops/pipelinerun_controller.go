@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/chatops"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+
+// Reconciler replies the result of a PipelineRun to the chat which triggered it
+type Reconciler struct {
+	client.Client
+	HTTPClient *http.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile sends the result of a completed PipelineRun to the reply URL, then removes the URL
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	replyURL := pipelineRun.GetAnnotations()[v1alpha3.PipelineRunChatOpsReplyAnnoKey]
+	if replyURL == "" || !pipelineRun.HasCompleted() {
+		return
+	}
+
+	source := pipelineRun.GetAnnotations()[v1alpha3.PipelineRunChatOpsSourceAnnoKey]
+	if err = chatops.Reply(r.HTTPClient, source, replyURL, GetMessage(pipelineRun)); err != nil {
+		// the reply URLs of chats expire soon, so do not retry
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "ReplyFailed", "failed to reply to %s, error: %v", source, err)
+	}
+
+	delete(pipelineRun.Annotations, v1alpha3.PipelineRunChatOpsReplyAnnoKey)
+	err = r.Update(ctx, pipelineRun)
+	return
+}
+
+// GetMessage returns the message of a completed PipelineRun
+func GetMessage(pipelineRun *v1alpha3.PipelineRun) (message string) {
+	message = fmt.Sprintf("PipelineRun %s/%s is %s", pipelineRun.Namespace, pipelineRun.Name, pipelineRun.Status.Phase)
+	if start, end := pipelineRun.Status.StartTime, pipelineRun.Status.CompletionTime; start != nil && end != nil {
+		message += fmt.Sprintf(" in %s", end.Sub(start.Time).Round(time.Second))
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "chatops-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
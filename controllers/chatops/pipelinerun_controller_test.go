@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/chatops"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	var replies []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&reply)
+		replies = append(replies, reply)
+	}))
+	defer server.Close()
+
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+	newPipelineRun := func(phase v1alpha3.RunPhase, completionTime *metav1.Time) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns", Annotations: map[string]string{
+				v1alpha3.PipelineRunChatOpsSourceAnnoKey: chatops.SourceSlack,
+				v1alpha3.PipelineRunChatOpsReplyAnnoKey:  server.URL,
+			}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase, StartTime: &start, CompletionTime: completionTime},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		verify      func(t *testing.T, c client.Client)
+	}{{
+		name:        "the PipelineRun is running",
+		pipelineRun: newPipelineRun(v1alpha3.Running, nil),
+		verify: func(t *testing.T, c client.Client) {
+			assert.Empty(t, replies)
+		},
+	}, {
+		name:        "the PipelineRun succeeded",
+		pipelineRun: newPipelineRun(v1alpha3.Succeeded, &end),
+		verify: func(t *testing.T, c client.Client) {
+			if assert.Equal(t, 1, len(replies)) {
+				assert.Equal(t, "PipelineRun ns/demo-abc is Succeeded in 1m30s", replies[0]["text"])
+			}
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+			assert.NotContains(t, pipelineRun.Annotations, v1alpha3.PipelineRunChatOpsReplyAnnoKey)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replies = nil
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun).Build()
+			r := &Reconciler{
+				Client:     c,
+				HTTPClient: server.Client(),
+				log:        logr.Discard(),
+				recorder:   &record.FakeRecorder{},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "demo-abc"}})
+			assert.Nil(t, err)
+			tt.verify(t, c)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/chatops"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// PostNotifyWindow is how long after a PipelineRun completes its post notifications are still sent. It prevents the
+// PipelineRuns which completed long ago from being notified once the post actions are added to their Pipeline.
+const PostNotifyWindow = 10 * time.Minute
+
+// PostReconciler sends the result of a completed PipelineRun to the chats in the post actions of its Pipeline
+type PostReconciler struct {
+	client.Client
+	HTTPClient *http.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile sends the notifications of the post conditions which match the phase of a completed PipelineRun, then
+// marks the PipelineRun so the notifications are only sent once
+func (r *PostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.HasCompleted() || pipelineRun.Annotations[v1alpha3.PipelineRunPostNotifiedAnnoKey] != "" {
+		return
+	}
+	if completion := pipelineRun.Status.CompletionTime; completion != nil && time.Since(completion.Time) > PostNotifyWindow {
+		return
+	}
+
+	var pipeline *v1alpha3.Pipeline
+	if ref := pipelineRun.Spec.PipelineRef; ref != nil && ref.Name != "" {
+		pipeline = &v1alpha3.Pipeline{}
+		if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: ref.Name}, pipeline); err != nil {
+			if err = client.IgnoreNotFound(err); err != nil {
+				return
+			}
+			pipeline = nil
+		}
+	}
+	conditions := pipelineRun.GetPost(pipeline).Conditions()
+	if len(conditions) == 0 {
+		return
+	}
+
+	message := GetMessage(pipelineRun)
+	for _, condition := range conditions {
+		if !condition.Matches(pipelineRun.Status.Phase) {
+			continue
+		}
+		for _, notification := range condition.Actions.Notifications {
+			if notifyErr := r.notify(ctx, pipelineRun.Namespace, notification, message); notifyErr != nil {
+				// the result is not worth retrying once the PipelineRun has completed for a while, so do not retry
+				r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "PostNotifyFailed",
+					"failed to send the post notification of %s to %s, error: %v", condition.Name, notification.Chat, notifyErr)
+			}
+		}
+		if len(condition.Actions.Cleanup) > 0 && pipeline.GetEngine() != v1alpha3.PipelineEngineArgoWorkflows {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "PostCleanupIgnored",
+				"the post cleanup steps of %s are only supported by the engine %s, use the post section of the Jenkinsfile instead",
+				condition.Name, v1alpha3.PipelineEngineArgoWorkflows)
+		}
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunPostNotifiedAnnoKey] = time.Now().UTC().Format(time.RFC3339)
+	err = r.Patch(ctx, pipelineRun, patch)
+	return
+}
+
+// notify sends the message to the incoming webhook of a chat, the URL is read from the Secret
+func (r *PostReconciler) notify(ctx context.Context, namespace string, notification v1alpha3.PostNotification,
+	message string) (err error) {
+	secret := &v1.Secret{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: notification.SecretRef.Name}, secret); err != nil {
+		return
+	}
+	url := strings.TrimSpace(string(secret.Data[notification.SecretRef.Key]))
+	if !chatops.IsTrustedReplyURL(notification.Chat, url) {
+		return fmt.Errorf("the URL in the key %s of the Secret %s is not an incoming webhook of %s",
+			notification.SecretRef.Key, notification.SecretRef.Name, notification.Chat)
+	}
+	return chatops.Reply(r.HTTPClient, notification.Chat, url, message)
+}
+
+// GetName returns the name of this reconciler
+func (r *PostReconciler) GetName() string {
+	return "pipelinepost"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *PostReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
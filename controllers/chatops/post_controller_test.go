@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// redirectTransport sends all the requests to the test server, and records their hosts
+type redirectTransport struct {
+	target *url.URL
+	hosts  []string
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.hosts = append(t.hosts, req.URL.Host)
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPostReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	var messages []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		messages = append(messages, message)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhooks", Namespace: "ns"},
+		Data: map[string][]byte{
+			"slack":    []byte("https://hooks.slack.com/services/T123/B456/abc\n"),
+			"dingtalk": []byte("https://oapi.dingtalk.com/robot/send?access_token=abc"),
+			"evil":     []byte("https://evil.example.com/hooks"),
+		},
+	}
+	notify := func(chat, key string) v1alpha3.PostNotification {
+		return v1alpha3.PostNotification{Chat: chat, SecretRef: v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "webhooks"}, Key: key,
+		}}
+	}
+	post := &v1alpha3.PipelinePost{
+		Always:  &v1alpha3.PostActions{Notifications: []v1alpha3.PostNotification{notify("slack", "slack")}},
+		Success: &v1alpha3.PostActions{Notifications: []v1alpha3.PostNotification{notify("dingtalk", "dingtalk")}},
+		Failure: &v1alpha3.PostActions{
+			Notifications: []v1alpha3.PostNotification{notify("slack", "evil")},
+			Cleanup:       []string{"kubectl delete ns preview"},
+		},
+	}
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{Post: post},
+	}
+
+	start := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+	newPipelineRun := func(phase v1alpha3.RunPhase, completionTime *metav1.Time) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns"},
+			Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}},
+			Status:     v1alpha3.PipelineRunStatus{Phase: phase, StartTime: &start, CompletionTime: completionTime},
+		}
+	}
+	getPipelineRun := func(t *testing.T, c client.Client) *v1alpha3.PipelineRun {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+		return pipelineRun
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		objects     []runtime.Object
+		verify      func(t *testing.T, c client.Client, hosts []string, events []string)
+	}{{
+		name:        "the PipelineRun is running",
+		pipelineRun: newPipelineRun(v1alpha3.Running, nil),
+		objects:     []runtime.Object{pipeline, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Empty(t, hosts)
+			assert.NotContains(t, getPipelineRun(t, c).Annotations, v1alpha3.PipelineRunPostNotifiedAnnoKey)
+		},
+	}, {
+		name:        "the PipelineRun succeeded",
+		pipelineRun: newPipelineRun(v1alpha3.Succeeded, &end),
+		objects:     []runtime.Object{pipeline, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Equal(t, []string{"hooks.slack.com", "oapi.dingtalk.com"}, hosts)
+			if assert.Equal(t, 2, len(messages)) {
+				assert.Equal(t, "PipelineRun ns/demo-abc is Succeeded in 1m30s", messages[0]["text"])
+				assert.Equal(t, "text", messages[1]["msgtype"])
+			}
+			assert.Empty(t, events)
+			assert.NotEmpty(t, getPipelineRun(t, c).Annotations[v1alpha3.PipelineRunPostNotifiedAnnoKey])
+		},
+	}, {
+		name:        "the PipelineRun failed",
+		pipelineRun: newPipelineRun(v1alpha3.Failed, &end),
+		objects:     []runtime.Object{pipeline, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			// the untrusted URL is not requested
+			assert.Equal(t, []string{"hooks.slack.com"}, hosts)
+			if assert.Equal(t, 2, len(events)) {
+				assert.Contains(t, events[0], "PostNotifyFailed")
+				assert.Contains(t, events[0], "is not an incoming webhook of slack")
+				assert.Contains(t, events[1], "PostCleanupIgnored")
+			}
+			assert.NotEmpty(t, getPipelineRun(t, c).Annotations[v1alpha3.PipelineRunPostNotifiedAnnoKey])
+		},
+	}, {
+		name: "the notifications were sent",
+		pipelineRun: func() *v1alpha3.PipelineRun {
+			pipelineRun := newPipelineRun(v1alpha3.Succeeded, &end)
+			pipelineRun.Annotations = map[string]string{v1alpha3.PipelineRunPostNotifiedAnnoKey: "2022-01-01T00:00:00Z"}
+			return pipelineRun
+		}(),
+		objects: []runtime.Object{pipeline, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Empty(t, hosts)
+		},
+	}, {
+		name: "the PipelineRun completed long ago",
+		pipelineRun: func() *v1alpha3.PipelineRun {
+			completion := metav1.NewTime(time.Now().Add(-PostNotifyWindow - time.Minute))
+			return newPipelineRun(v1alpha3.Succeeded, &completion)
+		}(),
+		objects: []runtime.Object{pipeline, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Empty(t, hosts)
+		},
+	}, {
+		name: "the snapshot of Pipeline spec takes precedence",
+		pipelineRun: func() *v1alpha3.PipelineRun {
+			pipelineRun := newPipelineRun(v1alpha3.Succeeded, &end)
+			pipelineRun.Spec.PipelineSpec = &v1alpha3.PipelineSpec{Post: &v1alpha3.PipelinePost{
+				Success: &v1alpha3.PostActions{Notifications: []v1alpha3.PostNotification{notify("slack", "slack")}},
+			}}
+			return pipelineRun
+		}(),
+		objects: []runtime.Object{secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Equal(t, []string{"hooks.slack.com"}, hosts)
+		},
+	}, {
+		name:        "the Pipeline has no post actions",
+		pipelineRun: newPipelineRun(v1alpha3.Succeeded, &end),
+		objects: []runtime.Object{&v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		}, secret},
+		verify: func(t *testing.T, c client.Client, hosts []string, events []string) {
+			assert.Empty(t, hosts)
+			assert.NotContains(t, getPipelineRun(t, c).Annotations, v1alpha3.PipelineRunPostNotifiedAnnoKey)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages = nil
+			transport := &redirectTransport{target: target}
+			recorder := record.NewFakeRecorder(10)
+			c := fake.NewClientBuilder().WithScheme(schema).
+				WithRuntimeObjects(append(tt.objects, tt.pipelineRun)...).Build()
+			r := &PostReconciler{
+				Client:     c,
+				HTTPClient: &http.Client{Transport: transport},
+				log:        logr.Discard(),
+				recorder:   recorder,
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "demo-abc"}})
+			assert.Nil(t, err)
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			tt.verify(t, c, transport.hosts, events)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDrainTimeout is the default duration of finishing an in-flight operation after the manager is stopped
+const DefaultDrainTimeout = 20 * time.Second
+
+// ShuttingDown returns true if the context of a reconcile is cancelled because the manager is stopping,
+// the reconcilers should not start any new operation against the external systems, such as Jenkins
+func ShuttingDown(ctx context.Context) bool {
+	return ctx.Err() != nil
+}
+
+// Drain returns a context which is not cancelled along with the context of the reconcile, but expires after the
+// timeout. It lets an in-flight operation persist its state after the manager is stopped, the manager waits for the
+// reconciles until its graceful shutdown timeout.
+func Drain(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(detachedContext{parent: ctx}, timeout)
+}
+
+// detachedContext keeps the values of its parent without the deadline and the cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+func TestDrain(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey("key"), "value"))
+	assert.False(t, ShuttingDown(parent))
+
+	ctx, drainCancel := Drain(parent, time.Minute)
+	defer drainCancel()
+	cancel()
+	assert.True(t, ShuttingDown(parent))
+	// the drained context is still alive after the parent is cancelled
+	assert.Nil(t, ctx.Err())
+	assert.Equal(t, "value", ctx.Value(contextKey("key")))
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.True(t, deadline.After(time.Now()))
+
+	// the drained context expires after the timeout
+	ctx, drainCancel = Drain(parent, time.Millisecond)
+	defer drainCancel()
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
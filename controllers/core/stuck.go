@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultStuckThreshold is the default duration of keeping failing before an item is considered as stuck
+	DefaultStuckThreshold = 30 * time.Minute
+	// ReasonReconcileStuck is the reason of the event which is emitted for a stuck resource
+	ReasonReconcileStuck = "ReconcileStuck"
+)
+
+var (
+	stuckItems = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ks_devops_workqueue_stuck_items",
+		Help: "The number of items which keep failing in the backoff of a workqueue for longer than the threshold",
+	}, []string{"name"})
+	stuckItemsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_workqueue_stuck_items_total",
+		Help: "The total number of items which were found stuck in the backoff of a workqueue",
+	}, []string{"name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(stuckItems, stuckItemsTotal)
+}
+
+// DefaultStuckDetector tracks the workqueues which are created by NewTrackedRateLimiter
+var DefaultStuckDetector = NewStuckDetector(DefaultStuckThreshold)
+
+// NewTrackedRateLimiter returns the default controller rate limiter which is tracked by DefaultStuckDetector.
+// The kind is used to emit the events to the resources, the items of workqueue should be reconcile.Request or
+// the namespace/name keys
+func NewTrackedRateLimiter(name string, kind schema.GroupVersionKind) workqueue.RateLimiter {
+	return DefaultStuckDetector.Track(name, kind, workqueue.DefaultControllerRateLimiter())
+}
+
+// StuckDetector is a watchdog of the items which keep failing in the backoff of workqueues.
+// It emits a warning event to the resource and updates the metrics once an item is stuck longer than the threshold
+type StuckDetector struct {
+	// Threshold is the duration of keeping failing before an item is considered as stuck, it's disabled if it's zero
+	Threshold time.Duration
+	// Period is the period of checking the items, it's the half of Threshold if it's zero
+	Period time.Duration
+	// Recorder emits the events, there is no event if it's nil
+	Recorder record.EventRecorder
+
+	mutex  sync.Mutex
+	items  map[trackedKey]*backoffItem
+	queues map[string]bool
+	now    func() time.Time
+}
+
+type trackedKey struct {
+	queue string
+	item  interface{}
+}
+
+type backoffItem struct {
+	kind     schema.GroupVersionKind
+	since    time.Time
+	requeues int
+	reported bool
+}
+
+// NewStuckDetector creates an instance of StuckDetector
+func NewStuckDetector(threshold time.Duration) *StuckDetector {
+	return &StuckDetector{
+		Threshold: threshold,
+		items:     map[trackedKey]*backoffItem{},
+		queues:    map[string]bool{},
+		now:       time.Now,
+	}
+}
+
+// Track wraps a rate limiter to track the items in its backoff
+func (d *StuckDetector) Track(name string, kind schema.GroupVersionKind, rateLimiter workqueue.RateLimiter) workqueue.RateLimiter {
+	d.mutex.Lock()
+	d.queues[name] = true
+	d.mutex.Unlock()
+	return &trackedRateLimiter{RateLimiter: rateLimiter, name: name, kind: kind, detector: d}
+}
+
+// Start checks the items periodically until the context is done
+func (d *StuckDetector) Start(ctx context.Context) error {
+	if d.Threshold <= 0 {
+		klog.Info("the stuck detector of workqueues is disabled")
+		return nil
+	}
+	period := d.Period
+	if period <= 0 {
+		period = d.Threshold / 2
+	}
+	wait.UntilWithContext(ctx, func(context.Context) {
+		d.check()
+	}, period)
+	return nil
+}
+
+// NeedLeaderElection returns true because the workqueues are only active in the leader
+func (d *StuckDetector) NeedLeaderElection() bool {
+	return true
+}
+
+func (d *StuckDetector) failed(queue string, kind schema.GroupVersionKind, item interface{}) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	key := trackedKey{queue: queue, item: item}
+	if tracked, ok := d.items[key]; ok {
+		tracked.requeues++
+		return
+	}
+	d.items[key] = &backoffItem{kind: kind, since: d.now(), requeues: 1}
+}
+
+func (d *StuckDetector) forget(queue string, item interface{}) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.items, trackedKey{queue: queue, item: item})
+}
+
+// check updates the metrics and emits the events for the newly stuck items
+func (d *StuckDetector) check() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := d.now()
+	counts := map[string]int{}
+	for queue := range d.queues {
+		counts[queue] = 0
+	}
+	for key, tracked := range d.items {
+		duration := now.Sub(tracked.since)
+		if duration < d.Threshold {
+			continue
+		}
+		counts[key.queue]++
+		if tracked.reported {
+			continue
+		}
+		tracked.reported = true
+		stuckItemsTotal.WithLabelValues(key.queue).Inc()
+		klog.Warningf("the item %v of workqueue %s is stuck in backoff for %s with %d retries",
+			key.item, key.queue, duration.Round(time.Second), tracked.requeues)
+		if ref := objectReference(tracked.kind, key.item); ref != nil && d.Recorder != nil {
+			d.Recorder.Eventf(ref, v1.EventTypeWarning, ReasonReconcileStuck,
+				"the %s controller has been failing to reconcile it for %s with %d retries",
+				key.queue, duration.Round(time.Second), tracked.requeues)
+		}
+	}
+	for queue, count := range counts {
+		stuckItems.WithLabelValues(queue).Set(float64(count))
+	}
+}
+
+// objectReference returns the reference of a workqueue item, it returns nil if the item is unknown
+func objectReference(kind schema.GroupVersionKind, item interface{}) *v1.ObjectReference {
+	var namespace, name string
+	switch key := item.(type) {
+	case reconcile.Request:
+		namespace, name = key.Namespace, key.Name
+	case string:
+		var err error
+		if namespace, name, err = cache.SplitMetaNamespaceKey(key); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if kind.Kind == "" || name == "" {
+		return nil
+	}
+	apiVersion, kindName := kind.ToAPIVersionAndKind()
+	return &v1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kindName,
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
+
+// trackedRateLimiter reports the failures and forgets of items to the stuck detector
+type trackedRateLimiter struct {
+	workqueue.RateLimiter
+	name     string
+	kind     schema.GroupVersionKind
+	detector *StuckDetector
+}
+
+// When is called when an item failed or requeued
+func (t *trackedRateLimiter) When(item interface{}) time.Duration {
+	t.detector.failed(t.name, t.kind, item)
+	return t.RateLimiter.When(item)
+}
+
+// Forget is called when an item succeeded
+func (t *trackedRateLimiter) Forget(item interface{}) {
+	t.detector.forget(t.name, item)
+	t.RateLimiter.Forget(item)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestStuckDetector(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder := record.NewFakeRecorder(10)
+	detector := NewStuckDetector(10 * time.Minute)
+	detector.Recorder = recorder
+	detector.now = func() time.Time { return now }
+
+	kind := schema.GroupVersionKind{Group: "devops.kubesphere.io", Version: "v1alpha3", Kind: "PipelineRun"}
+	limiter := detector.Track("fake-pipelinerun", kind, workqueue.DefaultControllerRateLimiter())
+	stuck := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "stuck"}}
+	recovered := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "recovered"}}
+
+	limiter.When(stuck)
+	limiter.When(recovered)
+	assert.Equal(t, 1, limiter.NumRequeues(stuck))
+
+	now = now.Add(5 * time.Minute)
+	limiter.When(stuck)
+	limiter.Forget(recovered)
+	detector.check()
+	assert.Equal(t, float64(0), testutil.ToFloat64(stuckItems.WithLabelValues("fake-pipelinerun")))
+	assert.Empty(t, recorder.Events)
+
+	now = now.Add(6 * time.Minute)
+	detector.check()
+	assert.Equal(t, float64(1), testutil.ToFloat64(stuckItems.WithLabelValues("fake-pipelinerun")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(stuckItemsTotal.WithLabelValues("fake-pipelinerun")))
+	assert.Equal(t, "Warning ReconcileStuck the fake-pipelinerun controller has been failing to reconcile it for 11m0s with 2 retries",
+		<-recorder.Events)
+
+	// the stuck item is only reported once
+	detector.check()
+	assert.Equal(t, float64(1), testutil.ToFloat64(stuckItemsTotal.WithLabelValues("fake-pipelinerun")))
+	assert.Empty(t, recorder.Events)
+
+	// it's not stuck once it succeeded
+	limiter.Forget(stuck)
+	detector.check()
+	assert.Equal(t, float64(0), testutil.ToFloat64(stuckItems.WithLabelValues("fake-pipelinerun")))
+}
+
+func TestStuckDetectorDisabled(t *testing.T) {
+	detector := NewStuckDetector(0)
+	assert.Nil(t, detector.Start(context.TODO()))
+	assert.True(t, detector.NeedLeaderElection())
+}
+
+func TestObjectReference(t *testing.T) {
+	kind := v1.SchemeGroupVersion.WithKind("Secret")
+	assert.Equal(t, &v1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "ns", Name: "name"},
+		objectReference(kind, "ns/name"))
+	assert.Equal(t, &v1.ObjectReference{APIVersion: "v1", Kind: "Secret", Name: "name"},
+		objectReference(kind, "name"))
+	assert.Equal(t, &v1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "ns", Name: "name"},
+		objectReference(kind, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"}}))
+	assert.Nil(t, objectReference(kind, "a/b/c"))
+	assert.Nil(t, objectReference(kind, 1))
+	assert.Nil(t, objectReference(schema.GroupVersionKind{}, "ns/name"))
+}
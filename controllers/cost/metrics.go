@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	costTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_pipelinerun_cost_total",
+		Help: "The compute cost of the finished agent pods of PipelineRuns",
+	}, []string{"namespace", "pipeline", "currency"})
+	cpuCoreSecondsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_pipelinerun_cpu_core_seconds_total",
+		Help: "The CPU core seconds requested by the finished agent pods of PipelineRuns",
+	}, []string{"namespace", "pipeline"})
+	memoryGiBSecondsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ks_devops_pipelinerun_memory_gib_seconds_total",
+		Help: "The memory GiB seconds requested by the finished agent pods of PipelineRuns",
+	}, []string{"namespace", "pipeline"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(costTotal, cpuCoreSecondsTotal, memoryGiBSecondsTotal)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/controllers/predicate"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/models/cost"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods;nodes;configmaps,verbs=get;list;watch
+
+// syncPeriod is the period of updating the cost of the running agent pods
+const syncPeriod = time.Minute
+
+// Reconciler records the compute cost of PipelineRuns from their Jenkins agent pods
+type Reconciler struct {
+	client.Client
+	// PriceTable is the ConfigMap which contains the price table, the resources are free if it's empty or not found
+	PriceTable types.NamespacedName
+
+	now      func() time.Time
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile puts the usage of an agent pod into the cost annotation of its PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile Pod: %s", req.String()))
+
+	pod := &v1.Pod{}
+	if err = r.Get(ctx, req.NamespacedName, pod); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	var build *pipelinerun.AgentBuild
+	if build, err = pipelinerun.ParseRunURL(ctx, jenkins.NewFolderResolver(r.Client),
+		pod.Annotations[pipelinerun.RunURLAnnoKey]); err != nil || build == nil || pod.Status.StartTime == nil {
+		return
+	}
+
+	var pipelineRun *v1alpha3.PipelineRun
+	if pipelineRun, err = build.FindPipelineRun(ctx, r.Client); err != nil || pipelineRun == nil {
+		return
+	}
+
+	var table *cost.PriceTable
+	if table, err = r.getPriceTable(ctx); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "InvalidPriceTable", "failed to get the price table, error: %v", err)
+		return
+	}
+	var nodeLabels map[string]string
+	if pod.Spec.NodeName != "" {
+		node := &v1.Node{}
+		if err = r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil && !apierrors.IsNotFound(err) {
+			return
+		}
+		nodeLabels = node.Labels
+	}
+	usage := cost.PodUsage(pod, table.PriceOf(nodeLabels), r.now())
+
+	runCost, _ := cost.GetCost(pipelineRun)
+	if runCost == nil {
+		runCost = &cost.Cost{Currency: table.Currency}
+	}
+	previous := runCost.Set(usage)
+	if previous != nil && previous.Finished {
+		// the cost of a finished pod is final
+		return
+	}
+	if !usage.Finished {
+		result.RequeueAfter = syncPeriod
+	}
+	if previous != nil && *previous == usage {
+		return
+	}
+
+	patch := client.MergeFromWithOptions(pipelineRun.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if err = cost.SetCost(pipelineRun, runCost); err != nil {
+		return
+	}
+	if err = r.Patch(ctx, pipelineRun, patch); err != nil {
+		return
+	}
+	if usage.Finished {
+		costTotal.WithLabelValues(build.Namespace, build.Pipeline, runCost.Currency).Add(usage.Cost)
+		cpuCoreSecondsTotal.WithLabelValues(build.Namespace, build.Pipeline).Add(usage.CPUCoreSeconds())
+		memoryGiBSecondsTotal.WithLabelValues(build.Namespace, build.Pipeline).Add(usage.MemoryGiBSeconds())
+	}
+	return
+}
+
+// getPriceTable returns the price table, all the resources are free if there is no price table
+func (r *Reconciler) getPriceTable(ctx context.Context) (*cost.PriceTable, error) {
+	if r.PriceTable.Name == "" {
+		return &cost.PriceTable{Currency: cost.DefaultCurrency}, nil
+	}
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, r.PriceTable, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &cost.PriceTable{Currency: cost.DefaultCurrency}, nil
+		}
+		return nil, err
+	}
+	return cost.ParsePriceTable([]byte(cm.Data[cost.ConfigMapKeyPriceTable]))
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "cost-pod"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.now == nil {
+		r.now = time.Now
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(
+			predicate.NewFilterHasAnnotation(pipelinerun.RunURLAnnoKey)))).
+		Complete(r)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/cost"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	startTime := metav1.NewTime(start)
+	priceTable := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "prices"},
+		Data: map[string]string{cost.ConfigMapKeyPriceTable: `
+currency: CNY
+default:
+  cpuCoreHour: 0.2
+rules:
+- nodeSelector:
+    lifecycle: spot
+  cpuCoreHour: 0.1
+`},
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"lifecycle": "spot"}}}
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "pipeline-abc",
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "3"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "feature/a"}},
+	}
+	otherRun := pipelineRun.DeepCopy()
+	otherRun.Name = "pipeline-def"
+	otherRun.Spec.SCM.RefName = "main"
+	// the folder of the DevOps project is named by the prefix strategy
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-ns",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "ns"},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "jenkins",
+			Name:        "agent",
+			Annotations: map[string]string{pipelinerun.RunURLAnnoKey: "job/host-ns/job/pipeline/job/feature%252Fa/3/"},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{{Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			}}},
+		},
+		Status: v1.PodStatus{StartTime: &startTime},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).
+		WithObjects(priceTable, node, pipelineRun, otherRun, project, pod).Build()
+	now := start.Add(30 * time.Minute)
+	r := &Reconciler{
+		Client:     c,
+		PriceTable: types.NamespacedName{Namespace: "system", Name: "prices"},
+		now:        func() time.Time { return now },
+		log:        logr.Discard(),
+		recorder:   &record.FakeRecorder{},
+	}
+	reconcile := func() ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "jenkins", Name: "agent"}})
+		assert.Nil(t, err)
+		return result
+	}
+	getCost := func(name string) *cost.Cost {
+		pr := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: name}, pr))
+		runCost, err := cost.GetCost(pr)
+		assert.Nil(t, err)
+		return runCost
+	}
+
+	// the cost of a running pod is updated periodically
+	assert.Equal(t, syncPeriod, reconcile().RequeueAfter)
+	runCost := getCost("pipeline-abc")
+	assert.Equal(t, "CNY", runCost.Currency)
+	assert.Equal(t, 0.1, runCost.Total)
+	assert.Equal(t, float64(3600), runCost.CPUCoreSeconds)
+	assert.Nil(t, getCost("pipeline-def"))
+
+	// the cost of a terminated pod is final, and it's counted in the metrics once
+	pod.Status.Phase = v1.PodSucceeded
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: metav1.NewTime(start.Add(time.Hour))}},
+	}}
+	assert.Nil(t, c.Status().Update(ctx, pod))
+	now = start.Add(2 * time.Hour)
+	assert.Zero(t, reconcile().RequeueAfter)
+	runCost = getCost("pipeline-abc")
+	assert.Equal(t, 0.2, runCost.Total)
+	assert.True(t, runCost.Pods[0].Finished)
+	assert.Equal(t, 0.2, testutil.ToFloat64(costTotal.WithLabelValues("ns", "pipeline", "CNY")))
+	assert.Equal(t, float64(7200), testutil.ToFloat64(cpuCoreSecondsTotal.WithLabelValues("ns", "pipeline")))
+
+	now = start.Add(3 * time.Hour)
+	assert.Zero(t, reconcile().RequeueAfter)
+	assert.Equal(t, 0.2, getCost("pipeline-abc").Total)
+	assert.Equal(t, 0.2, testutil.ToFloat64(costTotal.WithLabelValues("ns", "pipeline", "CNY")))
+}
+
+func TestReconciler_ReconcileIgnored(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	startTime := metav1.Now()
+	pods := []*v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "jenkins", Name: "invalid-url",
+			Annotations: map[string]string{pipelinerun.RunURLAnnoKey: "job/ns/3/"}},
+		Status: v1.PodStatus{StartTime: &startTime},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Namespace: "jenkins", Name: "pending",
+			Annotations: map[string]string{pipelinerun.RunURLAnnoKey: "job/ns/job/pipeline/3/"}},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Namespace: "jenkins", Name: "no-pipelinerun",
+			Annotations: map[string]string{pipelinerun.RunURLAnnoKey: "job/ns/job/pipeline/3/"}},
+		Status: v1.PodStatus{StartTime: &startTime},
+	}}
+
+	c := fake.NewClientBuilder().WithScheme(schema).Build()
+	r := &Reconciler{Client: c, now: time.Now, log: logr.Discard(), recorder: &record.FakeRecorder{}}
+	for _, pod := range append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jenkins", Name: "not-found"}}) {
+		if pod.Name != "not-found" {
+			assert.Nil(t, c.Create(context.Background(), pod))
+		}
+		result, err := r.Reconcile(context.Background(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}})
+		assert.Nil(t, err, pod.Name)
+		assert.Zero(t, result.RequeueAfter, pod.Name)
+	}
+}
+
+func TestReconciler_GetName(t *testing.T) {
+	assert.Equal(t, "cost-pod", (&Reconciler{}).GetName())
+}
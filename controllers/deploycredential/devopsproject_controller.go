@@ -0,0 +1,378 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploycredential
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// contextName is the name of the cluster, user and context in the kubeconfig
+	contextName = "deploy"
+	// rotateRatio is the ratio of the lifetime of a token, the token is rotated once it's passed
+	rotateRatio = 0.8
+	// minRotateInterval is the minimum interval of rotating a token
+	minRotateInterval = time.Minute
+
+	// rootCAConfigMap is the ConfigMap which is published into every namespace by Kubernetes with the CA of API server
+	rootCAConfigMap = "kube-root-ca.crt"
+	rootCAKey       = "ca.crt"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces;configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts;secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=edit;view
+
+// Reconciler issues the deploy credentials of DevOpsProjects. Each credential is a kubeconfig of a ServiceAccount in
+// the admin namespace, which is only bound to a ClusterRole in the target namespace. The token of the ServiceAccount
+// is short-lived, and it's rotated before it expires.
+type Reconciler struct {
+	client.Client
+	// TokenClient requests the tokens of the ServiceAccounts
+	TokenClient corev1client.ServiceAccountsGetter
+	// Server is the address of the API server in the kubeconfig
+	Server string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// Reconcile issues or rotates the deploy credentials of a DevOpsProject, and deletes the ones which were removed
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" || !project.DeletionTimestamp.IsZero() {
+		// the issued resources are owned by the DevOpsProject, they are deleted by the garbage collector
+		return
+	}
+
+	var errs []error
+	desired := map[string]string{}
+	for i := range project.Spec.DeployCredentials {
+		credential := &project.Spec.DeployCredentials[i]
+		desired[credential.Name] = credential.Namespace
+
+		var rotateAfter time.Duration
+		if rotateAfter, err = r.issue(ctx, project, namespace, credential); err != nil {
+			r.recorder.Eventf(project, v1.EventTypeWarning, "IssueFailed",
+				"failed to issue the deploy credential %s, error: %v", credential.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		if result.RequeueAfter == 0 || rotateAfter < result.RequeueAfter {
+			result.RequeueAfter = rotateAfter
+		}
+	}
+	if err = r.cleanup(ctx, project, namespace, desired); err != nil {
+		errs = append(errs, err)
+	}
+	err = utilerrors.NewAggregate(errs)
+	return
+}
+
+// issue makes sure the resources of a deploy credential exist, then rotates the token if it's going to expire.
+// It returns the duration after which the token should be rotated.
+func (r *Reconciler) issue(ctx context.Context, project *v1alpha3.DevOpsProject, namespace string,
+	credential *v1alpha3.DeployCredential) (rotateAfter time.Duration, err error) {
+	if err = r.checkTargetNamespace(ctx, project, credential.Namespace); err != nil {
+		return
+	}
+
+	secret := &v1.Secret{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: credential.Name}, secret); err == nil {
+		if secret.Labels[v1alpha3.DeployCredentialLabelKey] != project.Name {
+			err = fmt.Errorf("secret %s/%s exists but it is not a deploy credential", namespace, credential.Name)
+			return
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return
+	}
+
+	labels := map[string]string{v1alpha3.DeployCredentialLabelKey: project.Name}
+	serviceAccount := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:      GetServiceAccountName(credential.Name),
+		Namespace: namespace,
+	}}
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, serviceAccount, func() error {
+		serviceAccount.Labels = labels
+		return controllerutil.SetControllerReference(project, serviceAccount, r.Scheme())
+	}); err != nil {
+		return
+	}
+	if err = r.bindRole(ctx, project, serviceAccount, credential); err != nil {
+		return
+	}
+
+	lifetime := time.Duration(credential.GetExpirationSeconds()) * time.Second
+	rotateBefore := lifetime - time.Duration(float64(lifetime)*rotateRatio)
+	if expiration, ok := r.getValidExpiration(secret, credential); ok {
+		if rotateAfter = expiration.Sub(r.now()) - rotateBefore; rotateAfter > 0 {
+			return
+		}
+	}
+
+	var kubeconfig []byte
+	var expiration time.Time
+	if kubeconfig, expiration, err = r.createKubeConfig(ctx, serviceAccount, credential); err != nil {
+		return
+	}
+	secret = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credential.Name, Namespace: namespace}}
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = labels
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		// the rotated token must be synchronized to Jenkins
+		secret.Annotations[v1alpha3.CredentialAutoSyncAnnoKey] = "true"
+		secret.Annotations[v1alpha3.CredentialExpirationAnnoKey] = expiration.Format(time.RFC3339)
+		secret.Type = v1alpha3.SecretTypeKubeConfig
+		secret.Data = map[string][]byte{v1alpha3.KubeConfigSecretKey: kubeconfig}
+		return controllerutil.SetControllerReference(project, secret, r.Scheme())
+	}); err == nil {
+		r.recorder.Eventf(project, v1.EventTypeNormal, "Issued", "issued the deploy credential %s which expires at %s",
+			credential.Name, expiration.Format(time.RFC3339))
+		// the API server might issue a token with a shorter lifetime than the requested one
+		if rotateAfter = expiration.Sub(r.now()) - rotateBefore; rotateAfter < minRotateInterval {
+			rotateAfter = minRotateInterval
+		}
+	}
+	return
+}
+
+// checkTargetNamespace makes sure the target namespace belongs to the same workspace as the DevOpsProject
+func (r *Reconciler) checkTargetNamespace(ctx context.Context, project *v1alpha3.DevOpsProject, name string) (err error) {
+	ns := &v1.Namespace{}
+	if err = r.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+		return
+	}
+	workspace := project.Labels[constants.WorkspaceLabelKey]
+	if workspace == "" || ns.Labels[constants.WorkspaceLabelKey] != workspace {
+		err = fmt.Errorf("namespace %s does not belong to the workspace of DevOpsProject %s", name, project.Name)
+	}
+	return
+}
+
+// bindRole binds the ClusterRole to the ServiceAccount in the target namespace
+func (r *Reconciler) bindRole(ctx context.Context, project *v1alpha3.DevOpsProject, serviceAccount *v1.ServiceAccount,
+	credential *v1alpha3.DeployCredential) (err error) {
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     credential.GetClusterRole(),
+	}
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{
+		Name:      getRoleBindingName(project, credential.Name),
+		Namespace: credential.Namespace,
+	}}
+	if err = r.Get(ctx, client.ObjectKeyFromObject(binding), binding); err == nil {
+		if binding.RoleRef == roleRef {
+			return
+		}
+		// the role of a RoleBinding is immutable
+		if err = r.Delete(ctx, binding); err != nil {
+			return
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return
+	}
+
+	binding = &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getRoleBindingName(project, credential.Name),
+			Namespace: credential.Namespace,
+			Labels:    map[string]string{v1alpha3.DeployCredentialLabelKey: project.Name},
+		},
+		RoleRef: roleRef,
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccount.Name,
+			Namespace: serviceAccount.Namespace,
+		}},
+	}
+	if err = controllerutil.SetControllerReference(project, binding, r.Scheme()); err == nil {
+		err = r.Create(ctx, binding)
+	}
+	return
+}
+
+// getValidExpiration returns the expiration of the token in the credential, it's invalid if the credential does not
+// target the namespace any more
+func (r *Reconciler) getValidExpiration(secret *v1.Secret, credential *v1alpha3.DeployCredential) (expiration time.Time, ok bool) {
+	var err error
+	if expiration, err = time.Parse(time.RFC3339, secret.Annotations[v1alpha3.CredentialExpirationAnnoKey]); err != nil {
+		return
+	}
+	var config *clientcmdapi.Config
+	if config, err = clientcmd.Load(secret.Data[v1alpha3.KubeConfigSecretKey]); err != nil {
+		return
+	}
+	if kubeContext, found := config.Contexts[contextName]; found && kubeContext.Namespace == credential.Namespace {
+		cluster, found := config.Clusters[contextName]
+		ok = found && cluster.Server == r.Server
+	}
+	return
+}
+
+// createKubeConfig requests a token of the ServiceAccount, then returns the kubeconfig with it
+func (r *Reconciler) createKubeConfig(ctx context.Context, serviceAccount *v1.ServiceAccount,
+	credential *v1alpha3.DeployCredential) (data []byte, expiration time.Time, err error) {
+	rootCA := &v1.ConfigMap{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: serviceAccount.Namespace, Name: rootCAConfigMap}, rootCA); err != nil {
+		return
+	}
+
+	expirationSeconds := credential.GetExpirationSeconds()
+	var token *authenticationv1.TokenRequest
+	if token, err = r.TokenClient.ServiceAccounts(serviceAccount.Namespace).CreateToken(ctx, serviceAccount.Name,
+		&authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds}},
+		metav1.CreateOptions{}); err != nil {
+		return
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[contextName] = &clientcmdapi.Cluster{
+		Server:                   r.Server,
+		CertificateAuthorityData: []byte(rootCA.Data[rootCAKey]),
+	}
+	config.AuthInfos[contextName] = &clientcmdapi.AuthInfo{Token: token.Status.Token}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   contextName,
+		AuthInfo:  contextName,
+		Namespace: credential.Namespace,
+	}
+	config.CurrentContext = contextName
+	if data, err = clientcmd.Write(*config); err == nil {
+		expiration = token.Status.ExpirationTimestamp.Time
+	}
+	return
+}
+
+// cleanup deletes the resources of the deploy credentials which were removed, or whose target namespace was changed
+func (r *Reconciler) cleanup(ctx context.Context, project *v1alpha3.DevOpsProject, namespace string,
+	desired map[string]string) (err error) {
+	selector := client.MatchingLabels{v1alpha3.DeployCredentialLabelKey: project.Name}
+
+	secrets := &v1.SecretList{}
+	if err = r.List(ctx, secrets, client.InNamespace(namespace), selector); err != nil {
+		return
+	}
+	for i := range secrets.Items {
+		if _, ok := desired[secrets.Items[i].Name]; !ok {
+			if err = r.deleteIfExists(ctx, &secrets.Items[i]); err != nil {
+				return
+			}
+			r.recorder.Eventf(project, v1.EventTypeNormal, "Revoked", "revoked the deploy credential %s", secrets.Items[i].Name)
+		}
+	}
+
+	serviceAccounts := &v1.ServiceAccountList{}
+	if err = r.List(ctx, serviceAccounts, client.InNamespace(namespace), selector); err != nil {
+		return
+	}
+	expected := map[string]bool{}
+	for name := range desired {
+		expected[GetServiceAccountName(name)] = true
+	}
+	for i := range serviceAccounts.Items {
+		if !expected[serviceAccounts.Items[i].Name] {
+			if err = r.deleteIfExists(ctx, &serviceAccounts.Items[i]); err != nil {
+				return
+			}
+		}
+	}
+
+	bindings := &rbacv1.RoleBindingList{}
+	if err = r.List(ctx, bindings, selector); err != nil {
+		return
+	}
+	expected = map[string]bool{}
+	for name, targetNamespace := range desired {
+		expected[targetNamespace+"/"+getRoleBindingName(project, name)] = true
+	}
+	for i := range bindings.Items {
+		if !expected[bindings.Items[i].Namespace+"/"+bindings.Items[i].Name] {
+			if err = r.deleteIfExists(ctx, &bindings.Items[i]); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (r *Reconciler) deleteIfExists(ctx context.Context, obj client.Object) error {
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
+
+// GetServiceAccountName returns the name of the ServiceAccount of a deploy credential
+func GetServiceAccountName(credential string) string {
+	return "deploy-" + credential
+}
+
+func getRoleBindingName(project *v1alpha3.DevOpsProject, credential string) string {
+	return fmt.Sprintf("deploy-%s-%s", project.Name, credential)
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "deploycredential"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.Server == "" {
+		r.Server = mgr.GetConfig().Host
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Owns(&v1.Secret{}).
+		Complete(r)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploycredential
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, rbacv1.AddToScheme(schema))
+
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", UID: "uid",
+			Labels: map[string]string{constants.WorkspaceLabelKey: "ws"}},
+		Spec: v1alpha3.DevOpsProjectSpec{DeployCredentials: []v1alpha3.DeployCredential{
+			{Name: "staging", Namespace: "staging"},
+			{Name: "other", Namespace: "other"},
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+	}
+	staging := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging",
+		Labels: map[string]string{constants.WorkspaceLabelKey: "ws"}}}
+	other := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other",
+		Labels: map[string]string{constants.WorkspaceLabelKey: "another"}}}
+	rootCA := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: rootCAConfigMap},
+		Data: map[string]string{rootCAKey: "fake-ca"}}
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tokens := 0
+	tokenClient := k8sfake.NewSimpleClientset()
+	tokenClient.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		request := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		tokens++
+		request.Status = authenticationv1.TokenRequestStatus{
+			Token:               fmt.Sprintf("token-%d", tokens),
+			ExpirationTimestamp: metav1.NewTime(now.Add(time.Duration(*request.Spec.ExpirationSeconds) * time.Second)),
+		}
+		return true, request, nil
+	})
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(project, staging, other, rootCA).Build()
+	r := &Reconciler{
+		Client:      c,
+		TokenClient: tokenClient.CoreV1(),
+		Server:      "https://kubernetes.default.svc",
+		log:         logr.Discard(),
+		recorder:    &record.FakeRecorder{},
+		now:         func() time.Time { return now },
+	}
+	reconcile := func() (ctrl.Result, error) {
+		return r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: "demo"}})
+	}
+
+	// the namespace of another workspace is refused
+	result, err := reconcile()
+	assert.NotNil(t, err)
+	assert.Equal(t, 48*time.Minute, result.RequeueAfter)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "other"}, &v1.Secret{})))
+
+	serviceAccount := &v1.ServiceAccount{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "deploy-staging"}, serviceAccount))
+	binding := &rbacv1.RoleBinding{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "staging", Name: "deploy-demo-staging"}, binding))
+	assert.Equal(t, "edit", binding.RoleRef.Name)
+	assert.Equal(t, []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "deploy-staging", Namespace: "demo"}},
+		binding.Subjects)
+
+	credential := &v1.Secret{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "staging"}, credential))
+	assert.Equal(t, v1alpha3.SecretTypeKubeConfig, credential.Type)
+	assert.Equal(t, "true", credential.Annotations[v1alpha3.CredentialAutoSyncAnnoKey])
+	assert.Equal(t, "2022-01-01T01:00:00Z", credential.Annotations[v1alpha3.CredentialExpirationAnnoKey])
+	config, err := clientcmd.Load(credential.Data[v1alpha3.KubeConfigSecretKey])
+	assert.Nil(t, err)
+	assert.Equal(t, "token-1", config.AuthInfos[contextName].Token)
+	assert.Equal(t, "staging", config.Contexts[config.CurrentContext].Namespace)
+	assert.Equal(t, "fake-ca", string(config.Clusters[contextName].CertificateAuthorityData))
+
+	// the token is not rotated until 80% of its lifetime has passed
+	project.Spec.DeployCredentials = project.Spec.DeployCredentials[:1]
+	assert.Nil(t, c.Update(ctx, project))
+	now = now.Add(30 * time.Minute)
+	result, err = reconcile()
+	assert.Nil(t, err)
+	assert.Equal(t, 18*time.Minute, result.RequeueAfter)
+	assert.Equal(t, 1, tokens)
+
+	now = now.Add(20 * time.Minute)
+	result, err = reconcile()
+	assert.Nil(t, err)
+	assert.Equal(t, 48*time.Minute, result.RequeueAfter)
+	assert.Equal(t, 2, tokens)
+
+	// the role is changed
+	project.Spec.DeployCredentials[0].ClusterRole = "view"
+	assert.Nil(t, c.Update(ctx, project))
+	_, err = reconcile()
+	assert.Nil(t, err)
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "staging", Name: "deploy-demo-staging"}, binding))
+	assert.Equal(t, "view", binding.RoleRef.Name)
+
+	// a Secret which is not issued by the controller is not overwritten
+	project.Spec.DeployCredentials = append(project.Spec.DeployCredentials, v1alpha3.DeployCredential{Name: "rootCA", Namespace: "staging"})
+	assert.Nil(t, c.Create(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "rootCA"}}))
+	assert.Nil(t, c.Update(ctx, project))
+	_, err = reconcile()
+	assert.NotNil(t, err)
+
+	// the removed credentials are revoked
+	project.Spec.DeployCredentials = nil
+	assert.Nil(t, c.Update(ctx, project))
+	_, err = reconcile()
+	assert.Nil(t, err)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "staging"}, credential)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "deploy-staging"}, serviceAccount)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "staging", Name: "deploy-demo-staging"}, binding)))
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "demo", Name: "rootCA"}, credential))
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ephemeralnamespace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/utils"
+	"kubesphere.io/devops/pkg/utils/k8sutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// resourceName is the name of the ResourceQuota, LimitRange, ServiceAccount and RoleBinding in the ephemeral namespace
+	resourceName = "ephemeral"
+	// rootCAConfigMap is the ConfigMap which is published into every namespace by Kubernetes with the CA of API server
+	rootCAConfigMap = "kube-root-ca.crt"
+	rootCAKey       = "ca.crt"
+
+	maxNamespaceLength = 63
+
+	// NamespaceParameter is the parameter passed to the PipelineRun with the name of ephemeral namespace
+	NamespaceParameter = "EPHEMERAL_NAMESPACE"
+	// KubeConfigParameter is the parameter passed to the PipelineRun with the ID of kubeconfig credential
+	KubeConfigParameter = "EPHEMERAL_KUBECONFIG"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=namespaces;resourcequotas;limitranges;serviceaccounts,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=admin;edit;view
+
+// Reconciler creates a quota-limited namespace for each PipelineRun which asks for it,
+// injects the kubeconfig of the namespace as a credential, then deletes them after the PipelineRun completes
+type Reconciler struct {
+	client.Client
+	// TokenClient requests the tokens of the ServiceAccounts
+	TokenClient corev1client.ServiceAccountsGetter
+	// Server is the address of the API server in the kubeconfig
+	Server string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile prepares or cleans up the ephemeral namespace of a PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	if !pipelineRun.DeletionTimestamp.IsZero() || pipelineRun.HasCompleted() {
+		err = r.cleanup(ctx, pipelineRun)
+		return
+	}
+	if pipelineRun.Spec.PipelineRef == nil || pipelineRun.Spec.PipelineRef.Name == "" {
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRun.Spec.PipelineRef.Name}, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	options := pipelineRun.GetEphemeralNamespace(pipeline)
+	if options == nil || pipelineRun.IsMatrixParent(pipeline) ||
+		!pipelineRun.Buildable() || !pipelineRun.IsWaitingForEphemeralNamespace(pipeline) {
+		return
+	}
+
+	if k8sutil.AddFinalizer(&pipelineRun.ObjectMeta, v1alpha3.EphemeralNamespaceFinalizerName) {
+		if err = r.Update(ctx, pipelineRun); err != nil {
+			return
+		}
+	}
+
+	namespace := GetNamespaceName(pipelineRun)
+	if err = r.prepare(ctx, pipelineRun, namespace, options); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "PrepareFailed", "failed to prepare the ephemeral namespace %s, error: %v", namespace, err)
+		return
+	}
+	rootCA := &v1.ConfigMap{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: rootCAConfigMap}, rootCA); err != nil {
+		if apierrors.IsNotFound(err) {
+			// wait for the root CA publisher of Kubernetes
+			err = nil
+			result.RequeueAfter = time.Second
+		}
+		return
+	}
+
+	if err = r.injectKubeConfig(ctx, pipelineRun, namespace, options, []byte(rootCA.Data[rootCAKey])); err != nil {
+		return
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunEphemeralNamespaceAnnoKey] = namespace
+	pipelineRun.Spec.Parameters = setParameter(pipelineRun.Spec.Parameters, NamespaceParameter, namespace)
+	pipelineRun.Spec.Parameters = setParameter(pipelineRun.Spec.Parameters, KubeConfigParameter, pipelineRun.GetEphemeralKubeConfigName())
+	if err = r.Patch(ctx, pipelineRun, patch); err == nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, "NamespaceReady", "ephemeral namespace %s is ready, the kubeconfig credential is %s",
+			namespace, pipelineRun.GetEphemeralKubeConfigName())
+	}
+	return
+}
+
+func setParameter(parameters []v1alpha3.Parameter, name, value string) []v1alpha3.Parameter {
+	for i := range parameters {
+		if parameters[i].Name == name {
+			parameters[i].Value = value
+			return parameters
+		}
+	}
+	return append(parameters, v1alpha3.Parameter{Name: name, Value: value})
+}
+
+// GetNamespaceName returns the name of ephemeral namespace of a PipelineRun
+func GetNamespaceName(pipelineRun *v1alpha3.PipelineRun) string {
+	name := fmt.Sprintf("%s-%s", pipelineRun.Namespace, pipelineRun.Name)
+	if len(name) <= maxNamespaceLength {
+		return name
+	}
+	hash := utils.ComputeHash(pipelineRun.Namespace + "/" + pipelineRun.Name)
+	return fmt.Sprintf("%s-%s", name[:maxNamespaceLength-len(hash)-1], hash)
+}
+
+// prepare creates the namespace and the resources in it
+func (r *Reconciler) prepare(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, namespace string,
+	options *v1alpha3.EphemeralNamespace) (err error) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   namespace,
+		Labels: map[string]string{v1alpha3.EphemeralNamespaceLabelKey: string(pipelineRun.UID)},
+	}}
+	if err = r.Get(ctx, client.ObjectKeyFromObject(ns), ns); err == nil {
+		if ns.Labels[v1alpha3.EphemeralNamespaceLabelKey] != string(pipelineRun.UID) {
+			err = fmt.Errorf("namespace %s exists but does not belong to the PipelineRun", namespace)
+			return
+		}
+	} else if err = client.IgnoreNotFound(err); err != nil {
+		return
+	} else if err = r.Create(ctx, ns); err != nil {
+		return
+	}
+
+	meta := metav1.ObjectMeta{Name: resourceName, Namespace: namespace}
+	quota := &v1.ResourceQuota{ObjectMeta: meta}
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		quota.Spec.Hard = options.GetQuota()
+		return nil
+	}); err != nil {
+		return
+	}
+	// the pods without requests or limits are rejected by the quota, so give them the defaults
+	limitRange := &v1.LimitRange{ObjectMeta: meta}
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, limitRange, func() error {
+		limitRange.Spec.Limits = []v1.LimitRangeItem{{
+			Type: v1.LimitTypeContainer,
+			Default: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("500m"),
+				v1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+			DefaultRequest: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		}}
+		return nil
+	}); err != nil {
+		return
+	}
+	if err = r.createIfNotExist(ctx, &v1.ServiceAccount{ObjectMeta: meta}); err != nil {
+		return
+	}
+	err = r.createIfNotExist(ctx, &rbacv1.RoleBinding{
+		ObjectMeta: meta,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     options.GetClusterRole(),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      resourceName,
+			Namespace: namespace,
+		}},
+	})
+	return
+}
+
+func (r *Reconciler) createIfNotExist(ctx context.Context, obj client.Object) error {
+	if err := r.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// injectKubeConfig requests a token of the service account, then creates a kubeconfig credential with it in the
+// namespace of the PipelineRun
+func (r *Reconciler) injectKubeConfig(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, namespace string,
+	options *v1alpha3.EphemeralNamespace, rootCA []byte) (err error) {
+	expirationSeconds := options.GetExpirationSeconds()
+	var token *authenticationv1.TokenRequest
+	if token, err = r.TokenClient.ServiceAccounts(namespace).CreateToken(ctx, resourceName,
+		&authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds}},
+		metav1.CreateOptions{}); err != nil {
+		return
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[resourceName] = &clientcmdapi.Cluster{
+		Server:                   r.Server,
+		CertificateAuthorityData: rootCA,
+	}
+	config.AuthInfos[resourceName] = &clientcmdapi.AuthInfo{Token: token.Status.Token}
+	config.Contexts[resourceName] = &clientcmdapi.Context{
+		Cluster:   resourceName,
+		AuthInfo:  resourceName,
+		Namespace: namespace,
+	}
+	config.CurrentContext = resourceName
+
+	var data []byte
+	if data, err = clientcmd.Write(*config); err != nil {
+		return
+	}
+
+	credential := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      pipelineRun.GetEphemeralKubeConfigName(),
+		Namespace: pipelineRun.Namespace,
+	}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, credential, func() error {
+		credential.Type = v1alpha3.SecretTypeKubeConfig
+		credential.Data = map[string][]byte{v1alpha3.KubeConfigSecretKey: data}
+		return controllerutil.SetControllerReference(pipelineRun, credential, r.Scheme())
+	})
+	return
+}
+
+// cleanup deletes the ephemeral namespace and the kubeconfig credential, then removes the finalizer
+func (r *Reconciler) cleanup(ctx context.Context, pipelineRun *v1alpha3.PipelineRun) (err error) {
+	if !controllerutil.ContainsFinalizer(pipelineRun, v1alpha3.EphemeralNamespaceFinalizerName) {
+		return
+	}
+
+	ns := &v1.Namespace{}
+	if err = r.Get(ctx, client.ObjectKey{Name: GetNamespaceName(pipelineRun)}, ns); err == nil {
+		if ns.Labels[v1alpha3.EphemeralNamespaceLabelKey] == string(pipelineRun.UID) {
+			if err = r.Delete(ctx, ns); client.IgnoreNotFound(err) != nil {
+				return
+			}
+			r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, "NamespaceDeleted", "deleted the ephemeral namespace %s", ns.Name)
+		}
+	} else if err = client.IgnoreNotFound(err); err != nil {
+		return
+	}
+
+	credential := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      pipelineRun.GetEphemeralKubeConfigName(),
+		Namespace: pipelineRun.Namespace,
+	}}
+	if err = r.Delete(ctx, credential); client.IgnoreNotFound(err) != nil {
+		return
+	}
+
+	k8sutil.RemoveFinalizer(&pipelineRun.ObjectMeta, v1alpha3.EphemeralNamespaceFinalizerName)
+	err = r.Update(ctx, pipelineRun)
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "ephemeral-namespace"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.Server == "" {
+		r.Server = mgr.GetConfig().Host
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ephemeralnamespace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, rbacv1.AddToScheme(schema))
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec: v1alpha3.PipelineSpec{
+			Type:               v1alpha3.NoScmPipelineType,
+			EphemeralNamespace: &v1alpha3.EphemeralNamespace{},
+		},
+	}
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns", UID: "uid"},
+		Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}},
+	}
+
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "ns", Name: "demo-abc"}
+	var expirationSeconds int64
+	tokenClient := k8sfake.NewSimpleClientset()
+	tokenClient.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		request := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		expirationSeconds = *request.Spec.ExpirationSeconds
+		request.Status = authenticationv1.TokenRequestStatus{Token: "token"}
+		return true, request, nil
+	})
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline, pipelineRun).Build()
+	r := &Reconciler{
+		Client:      c,
+		TokenClient: tokenClient.CoreV1(),
+		Server:      "https://kubernetes.default.svc",
+		log:         logr.Discard(),
+		recorder:    &record.FakeRecorder{},
+	}
+	reconcile := func() ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.Nil(t, err)
+		return result
+	}
+
+	// the namespace is created, then wait for the root CA
+	assert.NotZero(t, reconcile().RequeueAfter)
+	ns := &v1.Namespace{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Name: "ns-demo-abc"}, ns))
+	assert.Equal(t, "uid", ns.Labels[v1alpha3.EphemeralNamespaceLabelKey])
+	quota := &v1.ResourceQuota{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "ns-demo-abc", Name: resourceName}, quota))
+	assert.Equal(t, v1alpha3.DefaultEphemeralNamespaceQuota, quota.Spec.Hard)
+	limitRange := &v1.LimitRange{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "ns-demo-abc", Name: resourceName}, limitRange))
+	if assert.Equal(t, 1, len(limitRange.Spec.Limits)) {
+		assert.NotEmpty(t, limitRange.Spec.Limits[0].DefaultRequest)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "ns-demo-abc", Name: resourceName}, roleBinding))
+	assert.Equal(t, "admin", roleBinding.RoleRef.Name)
+
+	// the root CA is published
+	assert.Nil(t, c.Create(ctx, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-demo-abc", Name: rootCAConfigMap},
+		Data: map[string]string{rootCAKey: "fake-ca"}}))
+	assert.Zero(t, reconcile().RequeueAfter)
+	assert.Equal(t, v1alpha3.DefaultEphemeralNamespaceExpirationSeconds, expirationSeconds)
+
+	assert.Nil(t, c.Get(ctx, key, pipelineRun))
+	assert.Equal(t, "ns-demo-abc", pipelineRun.Annotations[v1alpha3.PipelineRunEphemeralNamespaceAnnoKey])
+	assert.Contains(t, pipelineRun.Finalizers, v1alpha3.EphemeralNamespaceFinalizerName)
+	assert.False(t, pipelineRun.IsWaitingForEphemeralNamespace(pipeline))
+	assert.Equal(t, []v1alpha3.Parameter{
+		{Name: NamespaceParameter, Value: "ns-demo-abc"}, {Name: KubeConfigParameter, Value: "demo-abc-kubeconfig"},
+	}, pipelineRun.Spec.Parameters)
+
+	credential := &v1.Secret{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "demo-abc-kubeconfig"}, credential))
+	assert.Equal(t, v1alpha3.SecretTypeKubeConfig, credential.Type)
+	config, err := clientcmd.Load(credential.Data[v1alpha3.KubeConfigSecretKey])
+	assert.Nil(t, err)
+	assert.Equal(t, "token", config.AuthInfos[resourceName].Token)
+	assert.Equal(t, []byte("fake-ca"), config.Clusters[resourceName].CertificateAuthorityData)
+	assert.Equal(t, "ns-demo-abc", config.Contexts[config.CurrentContext].Namespace)
+
+	// clean up after the PipelineRun completed
+	now := metav1.Now()
+	pipelineRun.Status.CompletionTime = &now
+	assert.Nil(t, c.Status().Update(ctx, pipelineRun))
+	reconcile()
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Name: "ns-demo-abc"}, ns)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "demo-abc-kubeconfig"}, credential)))
+	assert.Nil(t, c.Get(ctx, key, pipelineRun))
+	assert.NotContains(t, pipelineRun.Finalizers, v1alpha3.EphemeralNamespaceFinalizerName)
+}
+
+func TestEphemeralNamespace_GetQuota(t *testing.T) {
+	quota := v1.ResourceList{v1.ResourceLimitsCPU: resource.MustParse("2")}
+	assert.Equal(t, quota, (&v1alpha3.EphemeralNamespace{Quota: quota}).GetQuota())
+	assert.Equal(t, v1alpha3.DefaultEphemeralNamespaceQuota, (&v1alpha3.EphemeralNamespace{}).GetQuota())
+}
+
+func TestReconciler_ReconcileNamespaceConflict(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{EphemeralNamespace: &v1alpha3.EphemeralNamespace{}},
+	}
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns", UID: "uid"},
+		Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}},
+	}
+	existing := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-demo-abc"}}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline, pipelineRun, existing).Build()
+	r := &Reconciler{Client: c, log: logr.Discard(), recorder: &record.FakeRecorder{}}
+
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "demo-abc"}})
+	assert.NotNil(t, err)
+}
+
+func TestGetNamespaceName(t *testing.T) {
+	short := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc"}}
+	assert.Equal(t, "ns-demo-abc", GetNamespaceName(short))
+
+	long := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: strings.Repeat("a", 70)}}
+	name := GetNamespaceName(long)
+	assert.Equal(t, maxNamespaceLength, len(name))
+	assert.True(t, strings.HasPrefix(name, "ns-aaa"))
+	assert.NotEqual(t, name, GetNamespaceName(&v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: strings.Repeat("a", 71)}}))
+}
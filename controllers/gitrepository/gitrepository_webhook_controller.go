@@ -29,6 +29,9 @@ import (
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Reconciler reconciles a GitRepository object
@@ -40,6 +43,7 @@ type Reconciler struct {
 
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=webhooks,verbs=get;list;update;patch
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=gitrepositories,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -262,5 +266,21 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.log = ctrl.Log.WithName(r.GetName())
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha3.GitRepository{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findGitRepositories)).
 		Complete(r)
 }
+
+// findGitRepositories returns the GitRepositories which reference the secret, then their webhooks are updated with the
+// new token
+func (r *Reconciler) findGitRepositories(secret client.Object) (requests []reconcile.Request) {
+	repoList := &v1alpha3.GitRepositoryList{}
+	if err := r.List(context.Background(), repoList,
+		client.MatchingFields{v1alpha3.GitRepositorySecretField: client.ObjectKeyFromObject(secret).String()}); err != nil {
+		r.log.Error(err, "failed to list the GitRepositories", "secret", client.ObjectKeyFromObject(secret))
+		return
+	}
+	for i := range repoList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&repoList.Items[i])})
+	}
+	return
+}
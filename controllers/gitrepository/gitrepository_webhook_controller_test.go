@@ -38,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func Test_getRepo(t *testing.T) {
@@ -891,3 +892,50 @@ func TestReconciler_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconciler_findGitRepositories(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "token",
+			Namespace: "ns",
+		},
+	}
+	repo := &v1alpha3.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "repo",
+			Namespace: "ns",
+		},
+		Spec: v1alpha3.GitRepositorySpec{
+			Secret: &v1.SecretReference{Name: "token"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		client       client.Client
+		wantRequests []reconcile.Request
+	}{{
+		name:   "no GitRepositories",
+		client: fake.NewFakeClientWithScheme(schema),
+	}, {
+		name:   "a GitRepository references the secret",
+		client: fake.NewFakeClientWithScheme(schema, repo.DeepCopy()),
+		wantRequests: []reconcile.Request{{
+			NamespacedName: types.NamespacedName{Namespace: "ns", Name: "repo"},
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{
+				Client: tt.client,
+				log:    logr.New(log.NullLogSink{}),
+			}
+			assert.Equal(t, tt.wantRequests, r.findGitRepositories(secret.DeepCopy()))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/history"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch;delete
+
+// Reconciler archives the completed PipelineRuns into the history database, then removes them from the cluster
+// once they are out of the retention
+type Reconciler struct {
+	client.Client
+	Store history.Interface
+	// Retention is how long an archived PipelineRun is kept in the cluster, it is never deleted if it's zero
+	Retention time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile archives a completed PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.HasCompleted() || !pipelineRun.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	if _, archived := pipelineRun.Annotations[v1alpha3.PipelineRunArchivedAnnoKey]; !archived {
+		if err = r.Store.Archive(ctx, history.NewRecord(pipelineRun)); err != nil {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "ArchiveFailed", "failed to archive the PipelineRun, error: %v", err)
+			return
+		}
+
+		patch := client.MergeFrom(pipelineRun.DeepCopy())
+		if pipelineRun.Annotations == nil {
+			pipelineRun.Annotations = map[string]string{}
+		}
+		pipelineRun.Annotations[v1alpha3.PipelineRunArchivedAnnoKey] = time.Now().Format(time.RFC3339)
+		if err = r.Patch(ctx, pipelineRun, patch); err != nil {
+			return
+		}
+	}
+
+	if r.Retention <= 0 {
+		return
+	}
+	if waiting := time.Until(pipelineRun.Status.CompletionTime.Add(r.Retention)); waiting > 0 {
+		result.RequeueAfter = waiting
+		return
+	}
+	r.log.V(4).Info("delete the archived PipelineRun which is out of the retention", "pipelinerun", req.String())
+	err = client.IgnoreNotFound(r.Delete(ctx, pipelineRun))
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "history-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
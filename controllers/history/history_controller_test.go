@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/history"
+	fakehistory "kubesphere.io/devops/pkg/client/history/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type brokenStore struct {
+	history.Interface
+}
+
+func (s *brokenStore) Archive(context.Context, *history.Record) error {
+	return errors.New("connection refused")
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipelineRun := func(completed time.Duration, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				UID:         types.UID("uid"),
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+				Annotations: annotations,
+			},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+		}
+		if completed > 0 {
+			pr.Status.CompletionTime = &metav1.Time{Time: time.Now().Add(-completed)}
+		}
+		return pr
+	}
+	archived := map[string]string{v1alpha3.PipelineRunArchivedAnnoKey: "2022-01-01T00:00:00Z"}
+
+	tests := []struct {
+		name         string
+		pipelineRun  *v1alpha3.PipelineRun
+		store        history.Interface
+		retention    time.Duration
+		wantErr      bool
+		wantRecords  int
+		wantArchived bool
+		wantDeleted  bool
+		wantRequeue  bool
+	}{{
+		name:        "not completed",
+		pipelineRun: newPipelineRun(0, nil),
+		store:       fakehistory.NewFakeHistory(),
+	}, {
+		name:         "archive a completed PipelineRun",
+		pipelineRun:  newPipelineRun(time.Minute, nil),
+		store:        fakehistory.NewFakeHistory(),
+		wantRecords:  1,
+		wantArchived: true,
+	}, {
+		name:         "keep it in the retention",
+		pipelineRun:  newPipelineRun(time.Minute, nil),
+		store:        fakehistory.NewFakeHistory(),
+		retention:    time.Hour,
+		wantRecords:  1,
+		wantArchived: true,
+		wantRequeue:  true,
+	}, {
+		name:        "delete it out of the retention",
+		pipelineRun: newPipelineRun(2*time.Hour, archived),
+		store:       fakehistory.NewFakeHistory(),
+		retention:   time.Hour,
+		wantDeleted: true,
+	}, {
+		name:        "failed to archive",
+		pipelineRun: newPipelineRun(2*time.Hour, nil),
+		store:       &brokenStore{},
+		retention:   time.Hour,
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun.DeepCopy()).Build()
+			r := &Reconciler{
+				Client:    c,
+				Store:     tt.store,
+				Retention: tt.retention,
+				log:       logr.Discard(),
+				recorder:  &record.FakeRecorder{},
+			}
+
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter > 0)
+			if store, ok := tt.store.(*fakehistory.FakeHistory); ok {
+				assert.Len(t, store.Records, tt.wantRecords)
+			}
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			err = c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun)
+			if tt.wantDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			assert.Nil(t, err)
+			_, ok := pipelineRun.Annotations[v1alpha3.PipelineRunArchivedAnnoKey]
+			assert.Equal(t, tt.wantArchived, ok)
+		})
+	}
+}
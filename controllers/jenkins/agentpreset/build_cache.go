@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/buildcache"
+)
+
+// injectBuildCache tells the build containers where the build cache of the DevOpsProject is, the build image step
+// imports and exports the layer cache through it. The existing environment variables of the containers are kept.
+func injectBuildCache(spec *v1.PodSpec, project *v1alpha3.DevOpsProject) {
+	env := buildcache.Env(project.Name, project.Spec.BuildCache)
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name == jnlpContainerName {
+			continue
+		}
+		for _, item := range env {
+			if !hasEnv(container, item.Name) {
+				container.Env = append(container.Env, item)
+			}
+		}
+	}
+}
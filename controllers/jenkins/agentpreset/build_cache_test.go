@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func Test_injectBuildCache(t *testing.T) {
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+		Spec: v1alpha3.DevOpsProjectSpec{BuildCache: &v1alpha3.BuildCache{
+			Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"},
+		}},
+	}
+	spec := &v1.PodSpec{Containers: []v1.Container{{
+		Name: "buildkit",
+		Env:  []v1.EnvVar{{Name: "BUILD_CACHE_REPOSITORY", Value: "registry.example.com/custom"}},
+	}, {
+		Name: "jnlp",
+	}}}
+	injectBuildCache(spec, project)
+
+	// the existing environment variables are kept
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "BUILD_CACHE_REPOSITORY", Value: "registry.example.com/custom"},
+		{Name: "BUILD_CACHE_TYPE", Value: "registry"},
+	}, spec.Containers[0].Env)
+	assert.Empty(t, spec.Containers[1].Env)
+
+	// the variables are only injected once
+	injectBuildCache(spec, project)
+	assert.Len(t, spec.Containers[0].Env, 2)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/imagepolicy"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidatingWebhookPath is the path of the webhook which checks the images of the Jenkins agent pods.
+const ValidatingWebhookPath = "/validate-jenkins-agent"
+
+//+kubebuilder:webhook:path=/validate-jenkins-agent,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=agentimage.devops.kubesphere.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=configmaps;namespaces,verbs=get
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+
+// ImageValidator checks the images of the Jenkins agent pods against the cluster-wide policy and the policy of
+// the DevOpsProject.
+type ImageValidator struct {
+	client.Client
+	// ClusterPolicy is the ConfigMap which contains the cluster-wide policy, there is no cluster-wide policy
+	// if it's empty or not found
+	ClusterPolicy types.NamespacedName
+}
+
+var _ admission.Handler = &ImageValidator{}
+
+// Handle denies the created pod if it is a Jenkins agent and its images violate an enforced policy.
+func (v *ImageValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if pod.Labels[agentLabelKey] != agentLabelValue {
+		return admission.Allowed("")
+	}
+
+	build, err := getBuild(ctx, v.Client, pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	policies, err := v.getPolicies(ctx, build.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	var denied, warnings []string
+	for _, policy := range policies {
+		for _, image := range getImages(&pod.Spec) {
+			violations := imagepolicy.Check(policy, image)
+			if policy.GetMode() == v1alpha3.GateModeEnforce {
+				denied = append(denied, violations...)
+			} else {
+				warnings = append(warnings, violations...)
+			}
+		}
+	}
+	if len(denied) > 0 {
+		return admission.Denied(strings.Join(denied, "; ")).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// getPolicies returns the cluster-wide policy and the policy of the DevOpsProject which owns the namespace, if any
+func (v *ImageValidator) getPolicies(ctx context.Context, namespace string) (policies []*v1alpha3.AgentImagePolicy, err error) {
+	if v.ClusterPolicy.Name != "" {
+		cm := &v1.ConfigMap{}
+		if err = v.Get(ctx, v.ClusterPolicy, cm); err == nil {
+			var policy *v1alpha3.AgentImagePolicy
+			if policy, err = imagepolicy.ParsePolicy([]byte(cm.Data[imagepolicy.ConfigMapKeyPolicy])); err != nil {
+				return
+			}
+			policies = append(policies, policy)
+		} else if !apierrors.IsNotFound(err) {
+			return
+		}
+	}
+
+	var project *v1alpha3.DevOpsProject
+	if project, err = getProject(ctx, v.Client, namespace); project != nil && project.Spec.AgentImagePolicy != nil {
+		policies = append(policies, project.Spec.AgentImagePolicy)
+	}
+	return
+}
+
+// getImages returns the distinct images of the init containers and containers
+func getImages(spec *v1.PodSpec) (images []string) {
+	found := map[string]bool{}
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			if !found[container.Image] {
+				found[container.Image] = true
+				images = append(images, container.Image)
+			}
+		}
+	}
+	return
+}
+
+// SetupWithManager registers the webhook into the webhook server of the manager.
+func (v *ImageValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidatingWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/imagepolicy"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestImageValidator_Handle(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "demo"},
+	}}
+	project := &v1alpha3.DevOpsProject{
+		// the folder is named by the prefix strategy
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+		Spec:   v1alpha3.DevOpsProjectSpec{AgentImagePolicy: &v1alpha3.AgentImagePolicy{PinTags: true}},
+	}
+	clusterPolicy := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "agent-image-policy"},
+		Data: map[string]string{imagepolicy.ConfigMapKeyPolicy: `
+mode: Warn
+allowedRegistries:
+- harbor.example.com
+- docker.io/jenkins
+`},
+	}
+
+	newAgent := func(runURL string, images ...string) runtime.RawExtension {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{agentLabelKey: agentLabelValue},
+				Annotations: map[string]string{pipelinerun.RunURLAnnoKey: runURL},
+			},
+			Spec: v1.PodSpec{InitContainers: []v1.Container{{Name: "init", Image: images[0]}}},
+		}
+		for _, image := range images {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: "container", Image: image})
+		}
+		data, _ := json.Marshal(pod)
+		return runtime.RawExtension{Raw: data}
+	}
+
+	tests := []struct {
+		name         string
+		object       runtime.RawExtension
+		policy       string
+		wantAllowed  bool
+		wantWarnings int
+		wantCode     int32
+	}{{
+		name:        "not a Jenkins agent",
+		object:      runtime.RawExtension{Raw: []byte("{}")},
+		wantAllowed: true,
+	}, {
+		name:        "the images are allowed",
+		object:      newAgent("job/demo/job/build/1/", "harbor.example.com/ci/maven:3.8", "jenkins/inbound-agent:4.10-3"),
+		policy:      clusterPolicy.Data[imagepolicy.ConfigMapKeyPolicy],
+		wantAllowed: true,
+	}, {
+		name:         "the cluster-wide policy only warns",
+		object:       newAgent("job/other/job/build/1/", "maven", "jenkins/inbound-agent:4.10-3"),
+		policy:       clusterPolicy.Data[imagepolicy.ConfigMapKeyPolicy],
+		wantAllowed:  true,
+		wantWarnings: 1,
+	}, {
+		name:         "the tag is not pinned",
+		object:       newAgent("job/demo/job/build/1/", "maven:latest"),
+		policy:       clusterPolicy.Data[imagepolicy.ConfigMapKeyPolicy],
+		wantWarnings: 1,
+		wantCode:     403,
+	}, {
+		name:         "the policy of the project in the folder which is not named after the namespace",
+		object:       newAgent("job/host-demo/job/build/1/", "maven:latest"),
+		policy:       clusterPolicy.Data[imagepolicy.ConfigMapKeyPolicy],
+		wantWarnings: 1,
+		wantCode:     403,
+	}, {
+		name:     "invalid cluster-wide policy",
+		object:   newAgent("job/demo/job/build/1/", "maven:3.8"),
+		policy:   "mode: Audit",
+		wantCode: 500,
+	}, {
+		name:     "invalid object",
+		object:   runtime.RawExtension{Raw: []byte("fake")},
+		wantCode: 400,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := clusterPolicy.DeepCopy()
+			cm.Data[imagepolicy.ConfigMapKeyPolicy] = tt.policy
+			validator := &ImageValidator{
+				Client: fake.NewClientBuilder().WithScheme(schema).
+					WithObjects(ns, project, cm).Build(),
+				ClusterPolicy: types.NamespacedName{Namespace: "system", Name: "agent-image-policy"},
+			}
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    tt.object,
+			}})
+			assert.Equal(t, tt.wantAllowed, resp.Allowed)
+			assert.Len(t, resp.Warnings, tt.wantWarnings)
+			if !tt.wantAllowed {
+				assert.Equal(t, tt.wantCode, resp.Result.Code)
+			}
+		})
+	}
+}
+
+func Test_getImages(t *testing.T) {
+	assert.Equal(t, []string{"alpine", "maven"}, getImages(&v1.PodSpec{
+		InitContainers: []v1.Container{{Image: "alpine"}},
+		Containers:     []v1.Container{{Image: "maven"}, {Image: "alpine"}},
+	}))
+}
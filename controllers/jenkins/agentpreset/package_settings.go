@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/packagemanager"
+)
+
+const (
+	// packageSettingsVolume is the volume of the package manager settings of the DevOpsProject
+	packageSettingsVolume = "package-settings"
+	// jnlpContainerName is the name of the container which connects the agent to Jenkins, it doesn't run the builds
+	jnlpContainerName = "jnlp"
+)
+
+// mountPackageSettings mounts the rendered package manager settings of the DevOpsProject into the build containers,
+// and points Maven and npm to them. The existing volumes, mounts and environment variables of the pod are kept.
+func mountPackageSettings(spec *v1.PodSpec, project *v1alpha3.DevOpsProject) {
+	for _, volume := range spec.Volumes {
+		if volume.Name == packageSettingsVolume {
+			return
+		}
+	}
+	// the Secret is optional, so the agent could start before the settings are rendered
+	optional := true
+	spec.Volumes = append(spec.Volumes, v1.Volume{
+		Name: packageSettingsVolume,
+		VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+			SecretName: packagemanager.GetSecretName(project.Name),
+			Optional:   &optional,
+		}},
+	})
+
+	settings := project.Spec.PackageManagers
+	var env []v1.EnvVar
+	if settings.Maven != nil || settings.Proxy != nil {
+		// MAVEN_ARGS is supported since Maven 3.9
+		env = append(env, v1.EnvVar{Name: "MAVEN_ARGS",
+			Value: fmt.Sprintf("-s %s/%s", packagemanager.MountPath, packagemanager.MavenSettingsKey)})
+	}
+	if settings.NPM != nil || settings.Proxy != nil {
+		env = append(env, v1.EnvVar{Name: "NPM_CONFIG_USERCONFIG",
+			Value: fmt.Sprintf("%s/%s", packagemanager.MountPath, packagemanager.NPMConfigKey)})
+	}
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name == jnlpContainerName || hasMountPath(container, packagemanager.MountPath) {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      packageSettingsVolume,
+			MountPath: packagemanager.MountPath,
+			ReadOnly:  true,
+		})
+		for _, item := range env {
+			if !hasEnv(container, item.Name) {
+				container.Env = append(container.Env, item)
+			}
+		}
+	}
+}
+
+func hasMountPath(container *v1.Container, path string) bool {
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnv(container *v1.Container, name string) bool {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
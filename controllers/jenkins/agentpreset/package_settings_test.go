@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func Test_mountPackageSettings(t *testing.T) {
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+		Spec: v1alpha3.DevOpsProjectSpec{PackageManagers: &v1alpha3.PackageManagers{
+			Maven: &v1alpha3.MavenSettings{},
+			NPM:   &v1alpha3.NPMSettings{},
+		}},
+	}
+	spec := &v1.PodSpec{Containers: []v1.Container{{
+		Name: "maven",
+		Env:  []v1.EnvVar{{Name: "MAVEN_ARGS", Value: "-B"}},
+	}, {
+		Name: "jnlp",
+	}}}
+	mountPackageSettings(spec, project)
+
+	optional := true
+	assert.Equal(t, []v1.Volume{{
+		Name: packageSettingsVolume,
+		VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+			SecretName: "packages-demo",
+			Optional:   &optional,
+		}},
+	}}, spec.Volumes)
+	assert.Equal(t, []v1.VolumeMount{{Name: packageSettingsVolume, MountPath: "/etc/devops/packages", ReadOnly: true}},
+		spec.Containers[0].VolumeMounts)
+	// the existing environment variables are kept
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "MAVEN_ARGS", Value: "-B"},
+		{Name: "NPM_CONFIG_USERCONFIG", Value: "/etc/devops/packages/.npmrc"},
+	}, spec.Containers[0].Env)
+	assert.Empty(t, spec.Containers[1].VolumeMounts)
+	assert.Empty(t, spec.Containers[1].Env)
+
+	// the settings are only mounted once
+	mountPackageSettings(spec, project)
+	assert.Len(t, spec.Volumes, 1)
+	assert.Len(t, spec.Containers[0].VolumeMounts, 1)
+
+	project.Spec.PackageManagers = &v1alpha3.PackageManagers{Maven: &v1alpha3.MavenSettings{}}
+	spec = &v1.PodSpec{Containers: []v1.Container{{Name: "maven"}}}
+	mountPackageSettings(spec, project)
+	assert.Equal(t, []v1.EnvVar{{Name: "MAVEN_ARGS", Value: "-s /etc/devops/packages/settings.xml"}}, spec.Containers[0].Env)
+}
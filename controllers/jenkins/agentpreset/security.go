@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/agentsecurity"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ExemptionWebhookPath is the path of the webhook which checks the exemptions of the agent security baseline.
+const ExemptionWebhookPath = "/validate-agent-security-exemption"
+
+// getSecurityBaseline returns the security baseline of the agent pods of a Pipeline, it returns nil if there is no
+// baseline or the Pipeline is exempted
+func (d *Defaulter) getSecurityBaseline(ctx context.Context, namespace, pipelineName string) (baseline *agentsecurity.Baseline, err error) {
+	if d.SecurityBaseline.Name == "" {
+		return
+	}
+	cm := &v1.ConfigMap{}
+	if err = d.Get(ctx, d.SecurityBaseline, cm); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if baseline, err = agentsecurity.ParseBaseline([]byte(cm.Data[agentsecurity.ConfigMapKeyBaseline])); err != nil {
+		return
+	}
+
+	if namespace == "" || pipelineName == "" {
+		return
+	}
+	pipeline := &v1alpha3.Pipeline{}
+	if err = d.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pipelineName}, pipeline); err != nil {
+		return baseline, client.IgnoreNotFound(err)
+	}
+	if pipeline.Annotations[v1alpha3.PipelineAgentSecurityExemptAnnoKey] == "true" {
+		baseline = nil
+	}
+	return
+}
+
+//+kubebuilder:webhook:path=/validate-agent-security-exemption,mutating=false,failurePolicy=fail,sideEffects=None,groups=devops.kubesphere.io,resources=pipelines,verbs=create;update,versions=v1alpha3,name=agentsecurity.devops.kubesphere.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// ExemptionValidator only allows the users who can exempt pipelines/agentsecurity to exempt a Pipeline from the
+// agent security baseline.
+type ExemptionValidator struct {
+	client.Client
+}
+
+var _ admission.Handler = &ExemptionValidator{}
+
+// Handle denies the created or updated Pipeline if it's newly exempted by a user without the permission.
+func (v *ExemptionValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pipeline := &v1alpha3.Pipeline{}
+	if err := json.Unmarshal(req.Object.Raw, pipeline); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if pipeline.Annotations[v1alpha3.PipelineAgentSecurityExemptAnnoKey] != "true" {
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update {
+		oldPipeline := &v1alpha3.Pipeline{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldPipeline); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if oldPipeline.Annotations[v1alpha3.PipelineAgentSecurityExemptAnnoKey] == "true" {
+			return admission.Allowed("")
+		}
+	}
+
+	allowed, err := v.subjectAccessReview(ctx, req.UserInfo, pipeline)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return admission.Denied(fmt.Sprintf("user %s is not allowed to %s pipelines/%s, cannot set the annotation %s",
+			req.UserInfo.Username, agentsecurity.ExemptVerb, agentsecurity.ExemptSubresource,
+			v1alpha3.PipelineAgentSecurityExemptAnnoKey))
+	}
+	return admission.Allowed("")
+}
+
+// subjectAccessReview checks if the user is allowed to exempt the Pipeline from the security baseline
+func (v *ExemptionValidator) subjectAccessReview(ctx context.Context, user authenticationv1.UserInfo,
+	pipeline *v1alpha3.Pipeline) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   pipeline.Namespace,
+				Name:        pipeline.Name,
+				Verb:        agentsecurity.ExemptVerb,
+				Group:       devops.GroupName,
+				Resource:    v1alpha3.ResourcePluralPipeline,
+				Subresource: agentsecurity.ExemptSubresource,
+			},
+		},
+	}
+	if err := v.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// SetupWithManager registers the webhook into the webhook server of the manager.
+func (v *ExemptionValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ExemptionWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
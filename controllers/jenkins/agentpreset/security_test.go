@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/agentsecurity"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefaulter_SecurityBaseline(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	baseline := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "baseline"},
+		Data: map[string]string{agentsecurity.ConfigMapKeyBaseline: `
+pod:
+  runAsNonRoot: true
+`},
+	}
+	exempted := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "demo", Name: "exempted",
+		Annotations: map[string]string{v1alpha3.PipelineAgentSecurityExemptAnnoKey: "true"},
+	}}
+	// the folder is named by the prefix strategy
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+	}
+	newAgent := func(runURL string) runtime.RawExtension {
+		data, _ := json.Marshal(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{agentLabelKey: agentLabelValue},
+				Annotations: map[string]string{pipelinerun.RunURLAnnoKey: runURL},
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Name: "jnlp"}}},
+		})
+		return runtime.RawExtension{Raw: data}
+	}
+
+	tests := []struct {
+		name        string
+		object      runtime.RawExtension
+		baseline    string
+		wantPatched bool
+		wantCode    int32
+	}{{
+		name:        "the baseline is applied to the agents without a DevOpsProject",
+		object:      newAgent("job/demo/job/build/1/"),
+		baseline:    baseline.Data[agentsecurity.ConfigMapKeyBaseline],
+		wantPatched: true,
+	}, {
+		name:     "the Pipeline is exempted",
+		object:   newAgent("job/demo/job/exempted/job/main/1/"),
+		baseline: baseline.Data[agentsecurity.ConfigMapKeyBaseline],
+	}, {
+		name:     "the Pipeline in the folder which is not named after the namespace is exempted",
+		object:   newAgent("job/host-demo/job/exempted/job/main/1/"),
+		baseline: baseline.Data[agentsecurity.ConfigMapKeyBaseline],
+	}, {
+		name:   "the baseline is empty",
+		object: newAgent("job/demo/job/build/1/"),
+	}, {
+		name:     "invalid baseline",
+		object:   newAgent("job/demo/job/build/1/"),
+		baseline: "pod: fake",
+		wantCode: 500,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := baseline.DeepCopy()
+			cm.Data[agentsecurity.ConfigMapKeyBaseline] = tt.baseline
+			defaulter := &Defaulter{
+				Client:           fake.NewClientBuilder().WithScheme(schema).WithObjects(cm, exempted, project).Build(),
+				SecurityBaseline: types.NamespacedName{Namespace: "system", Name: "baseline"},
+			}
+			resp := defaulter.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    tt.object,
+			}})
+			assert.Equal(t, tt.wantCode == 0, resp.Allowed)
+			assert.Equal(t, tt.wantPatched, len(resp.Patches) > 0)
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, resp.Result.Code)
+			}
+		})
+	}
+}
+
+// reviewClient allows the SubjectAccessReviews of the admin
+type reviewClient struct {
+	client.Client
+}
+
+func (c *reviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	review := obj.(*authorizationv1.SubjectAccessReview)
+	attrs := review.Spec.ResourceAttributes
+	review.Status.Allowed = review.Spec.User == "admin" && attrs.Verb == agentsecurity.ExemptVerb &&
+		attrs.Resource == "pipelines" && attrs.Subresource == agentsecurity.ExemptSubresource
+	return nil
+}
+
+func TestExemptionValidator_Handle(t *testing.T) {
+	newPipeline := func(exempt string) runtime.RawExtension {
+		pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "build"}}
+		if exempt != "" {
+			pipeline.Annotations = map[string]string{v1alpha3.PipelineAgentSecurityExemptAnnoKey: exempt}
+		}
+		data, _ := json.Marshal(pipeline)
+		return runtime.RawExtension{Raw: data}
+	}
+
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		user        string
+		object      runtime.RawExtension
+		oldObject   runtime.RawExtension
+		wantAllowed bool
+	}{{
+		name:        "not exempted",
+		operation:   admissionv1.Create,
+		user:        "user",
+		object:      newPipeline(""),
+		wantAllowed: true,
+	}, {
+		name:      "exempted by a user without the permission",
+		operation: admissionv1.Create,
+		user:      "user",
+		object:    newPipeline("true"),
+	}, {
+		name:        "exempted by the admin",
+		operation:   admissionv1.Update,
+		user:        "admin",
+		object:      newPipeline("true"),
+		oldObject:   newPipeline(""),
+		wantAllowed: true,
+	}, {
+		name:        "a Pipeline which was exempted is updated",
+		operation:   admissionv1.Update,
+		user:        "user",
+		object:      newPipeline("true"),
+		oldObject:   newPipeline("true"),
+		wantAllowed: true,
+	}, {
+		name:      "invalid object",
+		operation: admissionv1.Create,
+		object:    runtime.RawExtension{Raw: []byte("fake")},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &ExemptionValidator{Client: &reviewClient{}}
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tt.operation,
+				UserInfo:  authenticationv1.UserInfo{Username: tt.user},
+				Object:    tt.object,
+				OldObject: tt.oldObject,
+			}})
+			assert.Equal(t, tt.wantAllowed, resp.Allowed)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// MutatingWebhookPath is the path of the webhook which applies the agent presets to the Jenkins agent pods.
+const MutatingWebhookPath = "/mutate-jenkins-agent"
+
+const (
+	// agentLabelKey is the label key of Jenkins agent pods which are created by the Jenkins Kubernetes plugin
+	agentLabelKey = "jenkins"
+	// agentLabelValue is the label value of Jenkins agent pods
+	agentLabelValue = "slave"
+	// agentJenkinsLabelKey is the label key of the Jenkins agent labels, the labels are joined with '_'
+	agentJenkinsLabelKey = "jenkins/label"
+)
+
+//+kubebuilder:webhook:path=/mutate-jenkins-agent,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=agent.devops.kubesphere.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects;pipelines,verbs=get
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=list;watch
+
+// Defaulter applies the agent preset and the package manager settings of the DevOpsProject to the Jenkins agent pods,
+// and labels them with the DevOpsProject. The security baseline is applied to all the Jenkins agent pods except the
+// exempted Pipelines.
+type Defaulter struct {
+	client.Client
+	// SecurityBaseline is the ConfigMap which contains the security baseline, there is no baseline
+	// if it's empty or not found
+	SecurityBaseline types.NamespacedName
+}
+
+var _ admission.Handler = &Defaulter{}
+
+// Handle labels the created pod and applies the agent preset and security baseline to it if it is a Jenkins agent.
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if pod.Labels[agentLabelKey] != agentLabelValue {
+		return admission.Allowed("")
+	}
+
+	build, err := getBuild(ctx, d.Client, pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	project, err := getProject(ctx, d.Client, build.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	baseline, err := d.getSecurityBaseline(ctx, build.Namespace, build.Pipeline)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if project == nil && baseline.IsEmpty() {
+		return admission.Allowed("")
+	}
+
+	if project != nil {
+		// the label lets the agent NetworkPolicy of the DevOpsProject select the pod
+		pod.Labels[constants.DevOpsProjectLabelKey] = project.Name
+		if preset := project.Spec.Agent; preset != nil && matchLabel(pod.Labels[agentJenkinsLabelKey], preset.Label) {
+			applyAgentPreset(&pod.Spec, preset)
+		}
+		if project.Spec.PackageManagers != nil {
+			mountPackageSettings(&pod.Spec, project)
+		}
+		if project.Spec.BuildCache != nil {
+			injectBuildCache(&pod.Spec, project)
+		}
+	}
+	if !baseline.IsEmpty() {
+		if err = baseline.Apply(&pod.Spec); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+	data, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, data)
+}
+
+// getProject returns the DevOpsProject which owns the namespace, it returns nil if there is no such DevOpsProject
+func getProject(ctx context.Context, c client.Client, namespace string) (*v1alpha3.DevOpsProject, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+	ns := &v1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return nil, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := c.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return project, nil
+}
+
+// getBuild returns the Jenkins build which the agent pod runs for, it's empty if the pod has no valid run URL
+func getBuild(ctx context.Context, c client.Client, pod *v1.Pod) (*pipelinerun.AgentBuild, error) {
+	build, err := pipelinerun.ParseRunURL(ctx, jenkins.NewFolderResolver(c), pod.Annotations[pipelinerun.RunURLAnnoKey])
+	if err != nil || build != nil {
+		return build, err
+	}
+	return &pipelinerun.AgentBuild{}, nil
+}
+
+// matchLabel checks if the Jenkins agent labels of a pod contain the label of the preset
+func matchLabel(agentLabels, label string) bool {
+	if label == "" {
+		return true
+	}
+	for _, item := range strings.Split(agentLabels, "_") {
+		if item == label {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAgentPreset applies the preset to the pod spec, the existing settings of the pod are kept
+func applyAgentPreset(spec *v1.PodSpec, preset *v1alpha3.AgentPreset) {
+	if preset.Resources != nil {
+		for i := range spec.Containers {
+			mergeResources(&spec.Containers[i].Resources, preset.Resources)
+		}
+	}
+
+	for key, value := range preset.NodeSelector {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = map[string]string{}
+		}
+		if _, ok := spec.NodeSelector[key]; !ok {
+			spec.NodeSelector[key] = value
+		}
+	}
+
+	if len(spec.Tolerations) == 0 && len(preset.Tolerations) > 0 {
+		spec.Tolerations = append([]v1.Toleration{}, preset.Tolerations...)
+	}
+}
+
+// mergeResources adds the default requests and limits which are not set, a default value is skipped
+// if it conflicts with the other side which the container set, like a request larger than the limit
+func mergeResources(target, defaults *v1.ResourceRequirements) {
+	for name, quantity := range defaults.Requests {
+		if _, ok := target.Requests[name]; ok {
+			continue
+		}
+		if limit, ok := target.Limits[name]; ok && quantity.Cmp(limit) > 0 {
+			continue
+		}
+		if target.Requests == nil {
+			target.Requests = v1.ResourceList{}
+		}
+		target.Requests[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range defaults.Limits {
+		if _, ok := target.Limits[name]; ok {
+			continue
+		}
+		if request, ok := target.Requests[name]; ok && quantity.Cmp(request) < 0 {
+			continue
+		}
+		if target.Limits == nil {
+			target.Limits = v1.ResourceList{}
+		}
+		target.Limits[name] = quantity.DeepCopy()
+	}
+}
+
+// SetupWithManager registers the webhook into the webhook server of the manager.
+func (d *Defaulter) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(MutatingWebhookPath, &webhook.Admission{Handler: d})
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentpreset
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefaulter_Handle(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "demo"},
+	}}
+	project := &v1alpha3.DevOpsProject{
+		// the folder is named by the prefix strategy
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+		Spec: v1alpha3.DevOpsProjectSpec{Agent: &v1alpha3.AgentPreset{
+			Label: "maven",
+			Resources: &v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+			NodeSelector: map[string]string{"node-role": "ci"},
+			Tolerations:  []v1.Toleration{{Key: "ci", Operator: v1.TolerationOpExists}},
+		}},
+	}
+	noPresetNS := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "other",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "other"},
+	}}
+	noPresetProject := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+
+	newAgent := func(runURL, label string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{agentLabelKey: agentLabelValue, agentJenkinsLabelKey: label},
+				Annotations: map[string]string{pipelinerun.RunURLAnnoKey: runURL},
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Name: "maven"}, {Name: "jnlp"}}},
+		}
+	}
+	toRaw := func(pod *v1.Pod) runtime.RawExtension {
+		data, _ := json.Marshal(pod)
+		return runtime.RawExtension{Raw: data}
+	}
+
+	tests := []struct {
+		name        string
+		object      runtime.RawExtension
+		wantAllowed bool
+		wantPatched bool
+		wantCode    int32
+	}{{
+		name:        "not a Jenkins agent",
+		object:      toRaw(&v1.Pod{}),
+		wantAllowed: true,
+	}, {
+		name:        "the agent of a project with preset",
+		object:      toRaw(newAgent("job/demo/job/build/1/", "maven")),
+		wantAllowed: true,
+		wantPatched: true,
+	}, {
+		name:        "the agent in the folder which is not named after the namespace",
+		object:      toRaw(newAgent("job/host-demo/job/build/1/", "maven")),
+		wantAllowed: true,
+		wantPatched: true,
+	}, {
+		name:        "the agent label does not match, the pod is only labeled",
+		object:      toRaw(newAgent("job/demo/job/build/1/", "nodejs")),
+		wantAllowed: true,
+		wantPatched: true,
+	}, {
+		name:        "the project does not have preset, the pod is only labeled",
+		object:      toRaw(newAgent("job/other/job/build/1/", "maven")),
+		wantAllowed: true,
+		wantPatched: true,
+	}, {
+		name:        "the project does not exist",
+		object:      toRaw(newAgent("job/fake/job/build/1/", "maven")),
+		wantAllowed: true,
+	}, {
+		name:     "invalid object",
+		object:   runtime.RawExtension{Raw: []byte("fake")},
+		wantCode: 400,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaulter := &Defaulter{Client: fake.NewClientBuilder().WithScheme(schema).
+				WithObjects(ns, project, noPresetNS, noPresetProject).Build()}
+			resp := defaulter.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    tt.object,
+			}})
+			assert.Equal(t, tt.wantAllowed, resp.Allowed)
+			assert.Equal(t, tt.wantPatched, len(resp.Patches) > 0)
+			for i, patch := range resp.Patches {
+				if patch.Path == "/metadata/labels/kubesphere.io~1devopsproject" {
+					break
+				}
+				assert.NotEqual(t, len(resp.Patches)-1, i, "the pod is not labeled with the DevOpsProject")
+			}
+			if !tt.wantAllowed {
+				assert.Equal(t, tt.wantCode, resp.Result.Code)
+			}
+		})
+	}
+}
+
+func Test_applyAgentPreset(t *testing.T) {
+	preset := &v1alpha3.AgentPreset{
+		Resources: &v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("2Gi")},
+		},
+		NodeSelector: map[string]string{"node-role": "ci", "arch": "amd64"},
+		Tolerations:  []v1.Toleration{{Key: "ci", Operator: v1.TolerationOpExists}},
+	}
+
+	spec := &v1.PodSpec{
+		Containers: []v1.Container{{
+			Name: "maven",
+			Resources: v1.ResourceRequirements{
+				// the request of memory conflicts with the default limit
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+				// the limit of CPU conflicts with the default request
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+			},
+		}, {
+			Name: "jnlp",
+		}},
+		NodeSelector: map[string]string{"arch": "arm64"},
+		Tolerations:  []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpExists}},
+	}
+	applyAgentPreset(spec, preset)
+
+	assert.Equal(t, v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+	}, spec.Containers[0].Resources)
+	assert.Equal(t, *preset.Resources, spec.Containers[1].Resources)
+	assert.Equal(t, map[string]string{"node-role": "ci", "arch": "arm64"}, spec.NodeSelector)
+	assert.Equal(t, []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpExists}}, spec.Tolerations)
+
+	spec = &v1.PodSpec{}
+	applyAgentPreset(spec, preset)
+	assert.Equal(t, preset.Tolerations, spec.Tolerations)
+}
+
+func Test_getBuild(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+	}).Build()
+	newPod := func(runURL string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{pipelinerun.RunURLAnnoKey: runURL}}}
+	}
+
+	tests := []struct {
+		runURL        string
+		wantNamespace string
+		wantPipeline  string
+	}{{
+		runURL:        "job/demo/job/build/1/",
+		wantNamespace: "demo",
+		wantPipeline:  "build",
+	}, {
+		runURL:        "/job/demo/job/build/job/main/1/",
+		wantNamespace: "demo",
+		wantPipeline:  "build",
+	}, {
+		runURL:        "job/host-demo/job/build/1/",
+		wantNamespace: "demo",
+		wantPipeline:  "build",
+	}, {
+		runURL: "",
+	}, {
+		runURL: "view/all",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.runURL, func(t *testing.T) {
+			build, err := getBuild(context.Background(), c, newPod(tt.runURL))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantNamespace, build.Namespace)
+			assert.Equal(t, tt.wantPipeline, build.Pipeline)
+		})
+	}
+}
+
+func Test_matchLabel(t *testing.T) {
+	assert.True(t, matchLabel("maven", ""))
+	assert.True(t, matchLabel("maven", "maven"))
+	assert.True(t, matchLabel("base_maven", "maven"))
+	assert.False(t, matchLabel("nodejs", "maven"))
+}
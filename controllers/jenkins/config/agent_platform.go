@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// containersYAMLAnnoKey is the annotation key of the YAML which is merged into the Jenkins PodTemplate
+const containersYAMLAnnoKey = "containers.yaml"
+
+// agentVariant is a Jenkins PodTemplate for a specific platform
+type agentVariant struct {
+	podTemplate  *v1.PodTemplate
+	nodeSelector map[string]string
+}
+
+// expandPodTemplate expands a PodTemplate into the variants for each architecture. The first architecture keeps the
+// original name, the others are named with the suffix of the architecture, like maven-arm64.
+func expandPodTemplate(podTemplate *v1.PodTemplate) (variants []agentVariant, err error) {
+	os := podTemplate.Annotations[ANNOAgentOS]
+	if os != "" && os != "linux" && os != "windows" {
+		err = fmt.Errorf("unsupported operating system: %s", os)
+		return
+	}
+
+	var archs []string
+	for _, arch := range strings.Split(podTemplate.Annotations[ANNOAgentArchs], ",") {
+		if arch = strings.TrimSpace(arch); arch != "" {
+			archs = append(archs, arch)
+		}
+	}
+
+	images := map[string]map[string]string{}
+	if data := podTemplate.Annotations[ANNOAgentImages]; data != "" {
+		if err = json.Unmarshal([]byte(data), &images); err != nil {
+			err = fmt.Errorf("invalid images of architectures: %v", err)
+			return
+		}
+	}
+
+	if os == "" && len(archs) == 0 {
+		variants = []agentVariant{{podTemplate: podTemplate}}
+		return
+	}
+	if len(archs) == 0 {
+		archs = []string{""}
+	}
+
+	for i, arch := range archs {
+		variant := agentVariant{podTemplate: podTemplate.DeepCopy(), nodeSelector: map[string]string{}}
+		if i > 0 {
+			variant.podTemplate.Name = fmt.Sprintf("%s-%s", podTemplate.Name, arch)
+		}
+		if os != "" {
+			variant.nodeSelector[v1.LabelOSStable] = os
+		}
+		if arch != "" {
+			variant.nodeSelector[v1.LabelArchStable] = arch
+		}
+
+		containers := variant.podTemplate.Template.Spec.Containers
+		for j := range containers {
+			if image, ok := images[arch][containers[j].Name]; ok {
+				containers[j].Image = image
+			}
+		}
+		if err = setNodeSelector(variant.podTemplate, variant.nodeSelector); err != nil {
+			return
+		}
+		variants = append(variants, variant)
+	}
+	return
+}
+
+// setNodeSelector merges the node selector into the YAML of the PodTemplate, because the node selector of the
+// PodTemplate is not converted into the Jenkins PodTemplate
+func setNodeSelector(podTemplate *v1.PodTemplate, nodeSelector map[string]string) (err error) {
+	pod := map[string]interface{}{}
+	if data := podTemplate.Annotations[containersYAMLAnnoKey]; data != "" {
+		if err = yaml.Unmarshal([]byte(data), &pod); err != nil {
+			return fmt.Errorf("invalid YAML of PodTemplate: %v", err)
+		}
+	}
+
+	var selector map[string]string
+	if selector, _, err = unstructured.NestedStringMap(pod, "spec", "nodeSelector"); err != nil {
+		return
+	}
+	if selector == nil {
+		selector = map[string]string{}
+	}
+	for key, value := range nodeSelector {
+		selector[key] = value
+	}
+	if err = unstructured.SetNestedStringMap(pod, selector, "spec", "nodeSelector"); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = yaml.Marshal(pod); err == nil {
+		podTemplate.Annotations[containersYAMLAnnoKey] = string(data)
+	}
+	return
+}
+
+// hasMatchedNodes checks if there are nodes in the cluster which match the node selector
+func (r *PodTemplateReconciler) hasMatchedNodes(ctx context.Context, nodeSelector map[string]string) (bool, error) {
+	if len(nodeSelector) == 0 {
+		return true, nil
+	}
+	nodes := &v1.NodeList{}
+	if err := r.List(ctx, nodes, client.MatchingLabels(nodeSelector)); err != nil {
+		return false, err
+	}
+	return len(nodes.Items) > 0, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	mgrcore "kubesphere.io/devops/controllers/core"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPlatformPodTemplate(annotations map[string]string) *v1.PodTemplate {
+	return &v1.PodTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "maven",
+			Annotations: annotations,
+		},
+		Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "maven", Image: "kubesphere/builder-maven:v3.2.0"},
+			{Name: "jnlp", Image: "jenkins/inbound-agent:4.10-2"},
+		}}},
+	}
+}
+
+func Test_expandPodTemplate(t *testing.T) {
+	t.Run("without platform", func(t *testing.T) {
+		podTemplate := newPlatformPodTemplate(nil)
+		variants, err := expandPodTemplate(podTemplate)
+		assert.Nil(t, err)
+		assert.Equal(t, []agentVariant{{podTemplate: podTemplate}}, variants)
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		variants, err := expandPodTemplate(newPlatformPodTemplate(map[string]string{ANNOAgentOS: "windows"}))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(variants))
+		assert.Equal(t, "maven", variants[0].podTemplate.Name)
+		assert.Equal(t, map[string]string{v1.LabelOSStable: "windows"}, variants[0].nodeSelector)
+		assert.Equal(t, "spec:\n  nodeSelector:\n    kubernetes.io/os: windows\n",
+			variants[0].podTemplate.Annotations[containersYAMLAnnoKey])
+	})
+
+	t.Run("multiple architectures", func(t *testing.T) {
+		variants, err := expandPodTemplate(newPlatformPodTemplate(map[string]string{
+			ANNOAgentOS:           "linux",
+			ANNOAgentArchs:        "amd64, arm64",
+			ANNOAgentImages:       `{"arm64":{"maven":"kubesphere/builder-maven:v3.2.0-arm64"}}`,
+			containersYAMLAnnoKey: "spec:\n  nodeSelector:\n    disk: ssd\n",
+		}))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(variants))
+
+		assert.Equal(t, "maven", variants[0].podTemplate.Name)
+		assert.Equal(t, "kubesphere/builder-maven:v3.2.0", variants[0].podTemplate.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "spec:\n  nodeSelector:\n    disk: ssd\n    kubernetes.io/arch: amd64\n    kubernetes.io/os: linux\n",
+			variants[0].podTemplate.Annotations[containersYAMLAnnoKey])
+
+		assert.Equal(t, "maven-arm64", variants[1].podTemplate.Name)
+		assert.Equal(t, map[string]string{v1.LabelOSStable: "linux", v1.LabelArchStable: "arm64"}, variants[1].nodeSelector)
+		assert.Equal(t, "kubesphere/builder-maven:v3.2.0-arm64", variants[1].podTemplate.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "jenkins/inbound-agent:4.10-2", variants[1].podTemplate.Template.Spec.Containers[1].Image)
+	})
+
+	t.Run("invalid platform", func(t *testing.T) {
+		_, err := expandPodTemplate(newPlatformPodTemplate(map[string]string{ANNOAgentOS: "darwin"}))
+		assert.NotNil(t, err)
+		_, err = expandPodTemplate(newPlatformPodTemplate(map[string]string{ANNOAgentArchs: "arm64", ANNOAgentImages: "fake"}))
+		assert.NotNil(t, err)
+		_, err = expandPodTemplate(newPlatformPodTemplate(map[string]string{ANNOAgentArchs: "arm64", containersYAMLAnnoKey: "fake"}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPodTemplateReconciler_ReconcileVariants(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	cascData, err := ioutil.ReadFile("testdata/casc.yaml")
+	assert.Nil(t, err)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-devops-system", Name: "jenkins-casc-config"},
+		Data:       map[string]string{"jenkins_user.yaml": string(cascData)},
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node",
+		Labels: map[string]string{v1.LabelOSStable: "linux", v1.LabelArchStable: "amd64"},
+	}}
+	podTemplate := newPlatformPodTemplate(map[string]string{ANNOAgentArchs: "amd64,arm64"})
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(cm, node, podTemplate).Build()
+	recorder := &record.FakeRecorder{Events: make(chan string, 10)}
+	r := &PodTemplateReconciler{Client: c}
+	err = r.SetupWithManager(&mgrcore.FakeManager{Scheme: schema})
+	assert.Nil(t, err)
+	r.log, r.recorder = logr.Discard(), recorder
+
+	_, err = r.Reconcile(context.Background(), controllerruntime.Request{
+		NamespacedName: types.NamespacedName{Namespace: "ns", Name: "maven"}})
+	assert.Nil(t, err)
+
+	err = c.Get(context.Background(), types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, cm)
+	assert.Nil(t, err)
+	assert.Contains(t, cm.Data["jenkins_user.yaml"], "name: maven\n")
+	assert.NotContains(t, cm.Data["jenkins_user.yaml"], "maven-arm64")
+	assert.Equal(t, 1, len(recorder.Events))
+	assert.Contains(t, <-recorder.Events, "NoMatchedNodes")
+}
@@ -35,3 +35,12 @@ const (
 const reconcilerGroupName = "jenkins"
 
 const podTemplateFinalizer = "podtemplate.devops.kubesphere.io/finalizer"
+
+const (
+	// ANNOAgentOS is the operating system of the agent pods, like linux or windows
+	ANNOAgentOS = "agent.devops.kubesphere.io/os"
+	// ANNOAgentArchs is the comma separated architectures of the agent pods, like amd64,arm64
+	ANNOAgentArchs = "agent.devops.kubesphere.io/archs"
+	// ANNOAgentImages is a JSON map from the architecture to the images of containers, like {"arm64":{"maven":"image"}}
+	ANNOAgentImages = "agent.devops.kubesphere.io/images"
+)
@@ -116,6 +116,8 @@ func (r *AgentLabelsReconciler) getOrCreateJenkinsCore(annotations map[string]st
 		URL:      r.JenkinsClient.URL,
 		UserName: creator,
 		Token:    accessToken,
+
+		RoundTripper: r.JenkinsClient.RoundTripper,
 	}
 	return jenkinsCore, nil
 }
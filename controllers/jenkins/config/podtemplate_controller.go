@@ -21,6 +21,7 @@ import (
 	"github.com/go-logr/logr"
 	k8s "github.com/jenkins-zh/jenkins-client/pkg/k8s"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"kubesphere.io/devops/controllers/predicate"
@@ -32,6 +33,7 @@ import (
 
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;update
 //+kubebuilder:rbac:groups="",resources=podtemplates,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 
 // PodTemplateReconciler responsible for the Jenkins podTemplate sync
 type PodTemplateReconciler struct {
@@ -83,16 +85,32 @@ func (r *PodTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		Config: []byte(data),
 	}
 
+	// a PodTemplate could be expanded into multiple variants for different platforms
+	var variants []agentVariant
+	if variants, err = expandPodTemplate(podTemplate); err != nil {
+		r.recorder.Eventf(podTemplate, v1.EventTypeWarning, "InvalidPlatform", "Invalid platform of PodTemplate: %v", err)
+		if podTemplate.DeletionTimestamp.IsZero() {
+			return
+		}
+		// make sure a deleting PodTemplate could be removed
+		variants, err = []agentVariant{{podTemplate: podTemplate}}, nil
+	}
+
 	// manipulate the data
 	if podTemplate.DeletionTimestamp.IsZero() {
-		if err = casc.ReplaceOrAddPodTemplate(podTemplate); err == nil {
+		if err = r.syncVariants(ctx, &casc, podTemplate, variants); err == nil {
 			cm.Data[r.TargetConfigMapKey] = casc.GetConfigAsString()
 
 			// write back the data
 			err = r.Update(ctx, cm)
 		}
 	} else {
-		if err = casc.RemovePodTemplate(podTemplate.Name); err == nil {
+		for _, variant := range variants {
+			if err = casc.RemovePodTemplate(variant.podTemplate.Name); err != nil {
+				break
+			}
+		}
+		if err == nil {
 			cm.Data[r.TargetConfigMapKey] = casc.GetConfigAsString()
 			k8sutil.RemoveFinalizer(&podTemplate.ObjectMeta, podTemplateFinalizer)
 			if err = r.Update(ctx, podTemplate); err == nil {
@@ -109,6 +127,29 @@ func (r *PodTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return
 }
 
+// syncVariants adds the variants into the Jenkins CasC, the variants without matched nodes are removed
+func (r *PodTemplateReconciler) syncVariants(ctx context.Context, casc *k8s.JenkinsConfig, podTemplate *v1.PodTemplate,
+	variants []agentVariant) (err error) {
+	for _, variant := range variants {
+		var matched bool
+		if matched, err = r.hasMatchedNodes(ctx, variant.nodeSelector); err != nil {
+			return
+		}
+
+		if matched {
+			err = casc.ReplaceOrAddPodTemplate(variant.podTemplate)
+		} else {
+			r.recorder.Eventf(podTemplate, v1.EventTypeWarning, "NoMatchedNodes",
+				"No nodes match %v, the PodTemplate %s is not available", labels.Set(variant.nodeSelector), variant.podTemplate.Name)
+			err = casc.RemovePodTemplate(variant.podTemplate.Name)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 // GetName returns the name of this reconcile
 func (r *PodTemplateReconciler) GetName() string {
 	return "pod-template"
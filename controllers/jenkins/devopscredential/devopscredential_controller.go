@@ -19,12 +19,10 @@ package devopscredential
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"reflect"
 	"strings"
 	"time"
 
-	"github.com/emicklei/go-restful"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,13 +37,17 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"kubesphere.io/devops/controllers/core"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	devopsutil "kubesphere.io/devops/pkg/client/devops/util"
 	"kubesphere.io/devops/pkg/constants"
 	"kubesphere.io/devops/pkg/utils"
 	"kubesphere.io/devops/pkg/utils/k8sutil"
+	"kubesphere.io/devops/pkg/utils/secretutil"
 	"kubesphere.io/devops/pkg/utils/sliceutil"
 )
 
@@ -68,6 +70,8 @@ type Controller struct {
 	workerLoopPeriod time.Duration
 
 	devopsClient devopsClient.Interface
+
+	externalSecretReader client.Reader
 }
 
 // NewController creates an instance of the DevOpsProject controller
@@ -86,7 +90,7 @@ func NewController(client clientset.Interface,
 	v := &Controller{
 		client:           client,
 		devopsClient:     devopsClient,
-		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "devopscredential"),
+		workqueue:        workqueue.NewNamedRateLimitingQueue(core.NewTrackedRateLimiter("devopscredential", v1.SchemeGroupVersion.WithKind("Secret")), "devopscredential"),
 		secretLister:     secretInformer.Lister(),
 		secretSynced:     secretInformer.Informer().HasSynced,
 		namespaceLister:  namespaceInformer.Lister(),
@@ -247,7 +251,7 @@ func (c *Controller) syncHandler(key string) error {
 
 		//If the sync is successful, return handle
 		if state, ok := copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey]; ok && state == constants.StatusSuccessful {
-			specHash := utils.ComputeHash(copySecret.Data)
+			specHash := computeDataHash(copySecret)
 			oldHash := copySecret.Annotations[devopsv1alpha3.DevOpsCredentialDataHash] // don't need to check if it's nil, only compare if they're different
 			if specHash == oldHash {
 				// it was synced successfully, and there's any change with the Pipeline spec, skip this round
@@ -260,39 +264,57 @@ func (c *Controller) syncHandler(key string) error {
 		if !sliceutil.HasString(secret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName) {
 			copySecret.ObjectMeta.Finalizers = append(copySecret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName)
 		}
-		// Check secret config exists, otherwise we will create it.
-		// if secret exists, update config
-		_, err := c.devopsClient.GetCredentialInProject(nsName, copySecret.Name)
-		if err == nil {
-			if _, ok := copySecret.Annotations[devopsv1alpha3.CredentialAutoSyncAnnoKey]; ok {
-				_, err := c.devopsClient.UpdateCredentialInProject(nsName, copySecret)
-				if err != nil {
-					klog.V(8).Info(err, fmt.Sprintf("failed to update secret %s ", key))
-					return err
+		// the data of an external credential might be not ready, wait for the ExternalSecret instead of syncing it
+		materialized, reason, err := c.isMaterialized(copySecret)
+		if err != nil {
+			klog.V(8).Info(err, fmt.Sprintf("failed to check the ExternalSecret of secret %s ", key))
+			return err
+		}
+		if !materialized {
+			klog.V(4).Infof("credential '%s' is pending: %s", key, reason)
+			copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusPending
+			copySecret.Annotations[devopsv1alpha3.CredentialSyncMsgAnnoKey] = reason
+			c.workqueue.AddAfter(key, externalSecretRequeuePeriod)
+		} else {
+			// Check secret config exists, otherwise we will create it.
+			// if secret exists, update config
+			var syncErr error
+			credential, err := c.devopsClient.GetCredentialInProject(nsName, copySecret.Name)
+			if err == nil {
+				// the data of an external credential is always owned by the external secret store,
+				// and the credential is moved into another domain once its hostnames were changed
+				domain, _ := devopsutil.GetCredentialDomain(copySecret)
+				if _, ok := copySecret.Annotations[devopsv1alpha3.CredentialAutoSyncAnnoKey]; ok || secretutil.IsExternalCredential(copySecret) ||
+					credential.Domain != domain {
+					if _, syncErr = c.devopsClient.UpdateCredentialInProject(nsName, copySecret); syncErr != nil {
+						klog.V(8).Info(syncErr, fmt.Sprintf("failed to update secret %s ", key))
+					}
 				}
+			} else if _, syncErr = c.devopsClient.CreateCredentialInProject(nsName, copySecret); syncErr != nil {
+				klog.V(8).Info(syncErr, fmt.Sprintf("failed to create secret %s ", key))
 			}
-		} else {
-			_, err = c.devopsClient.CreateCredentialInProject(nsName, copySecret)
-			if err != nil {
-				klog.V(8).Info(err, fmt.Sprintf("failed to create secret %s ", key))
-				return err
+
+			if syncErr != nil {
+				// retrying does not help until the secret or Jenkins is changed, record the reason instead
+				if devopsClient.IsRetryable(syncErr) {
+					return syncErr
+				}
+				copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusFailed
+				copySecret.Annotations[devopsv1alpha3.CredentialSyncMsgAnnoKey] = fmt.Sprintf("%s: %v",
+					devopsClient.ReasonForError(syncErr), syncErr)
+			} else {
+				//If there is no early return, then the sync is successful.
+				copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusSuccessful
+				delete(copySecret.Annotations, devopsv1alpha3.CredentialSyncMsgAnnoKey)
 			}
 		}
-		//If there is no early return, then the sync is successful.
-		copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusSuccessful
 	} else {
 		// Finalizers processing logic
 		if sliceutil.HasString(copySecret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName) {
 			delSuccess := false
 			if _, err := c.devopsClient.DeleteCredentialInProject(nsName, secret.Name); err != nil {
-				// the status code should be 404 if the credential does not exists
-				if srvErr, ok := err.(restful.ServiceError); ok {
-					delSuccess = srvErr.Code == http.StatusNotFound
-				} else if srvErr, ok := err.(*devopsClient.ErrorResponse); ok {
-					delSuccess = srvErr.Response.StatusCode == http.StatusNotFound
-				} else {
-					klog.Error(fmt.Sprintf("unexpected error type: %v, should be *restful.ServiceError", err))
-				}
+				// the credential does not exist
+				delSuccess = devopsClient.IsNotFound(err)
 
 				klog.V(8).Info(err, fmt.Sprintf("failed to delete secret %s in devops", key))
 			} else {
@@ -328,3 +350,12 @@ func isDevOpsProjectAdminNamespace(namespace *v1.Namespace) bool {
 		devopsv1alpha3.ResourceKindDevOpsProject, "")
 
 }
+
+// computeDataHash returns the hash of the data and the hostnames of a credential, only the data is hashed if there are
+// no hostnames
+func computeDataHash(secret *v1.Secret) string {
+	if hostnames, ok := secret.Annotations[devopsv1alpha3.CredentialHostnamesAnnoKey]; ok {
+		return utils.ComputeHash(map[string]interface{}{"data": secret.Data, "hostnames": hostnames})
+	}
+	return utils.ComputeHash(secret.Data)
+}
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	v1 "k8s.io/api/core/v1"
 
 	fakeDevOps "kubesphere.io/devops/pkg/client/devops/fake"
@@ -36,6 +38,7 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/utils"
 )
 
 var (
@@ -347,3 +350,33 @@ func TestNotUpdateCredential(t *testing.T) {
 	f.expectCredential = []*v1.Secret{initSecret}
 	f.run(getKey(expectSecret, t))
 }
+
+func TestMoveCredentialIntoDomain(t *testing.T) {
+	f := newFixture(t)
+	nsName := "test-123"
+	secretName := "test"
+	projectName := "test_project"
+
+	ns := newNamespace(nsName, projectName)
+	initSecret := newSecret(nsName, secretName, nil, true, false, false)
+	restrictedSecret := newSecret(nsName, secretName, nil, true, false, false)
+	restrictedSecret.Annotations[devops.CredentialHostnamesAnnoKey] = "github.com"
+	expectSecret := restrictedSecret.DeepCopy()
+	expectSecret.Annotations[devops.CredentialSyncStatusAnnoKey] = constants.StatusSuccessful
+	f.secretLister = append(f.secretLister, restrictedSecret)
+	f.namespaceLister = append(f.namespaceLister, ns)
+	f.kubeobjects = append(f.kubeobjects, restrictedSecret)
+	f.initDevOpsProject = nsName
+	f.initCredential = []*v1.Secret{initSecret}
+	f.expectCredential = []*v1.Secret{expectSecret}
+	f.run(getKey(restrictedSecret, t))
+}
+
+func TestComputeDataHash(t *testing.T) {
+	secret := newSecret("ns", "test", map[string][]byte{"a": []byte("aa")}, false, false, false)
+	hash := computeDataHash(secret)
+	assert.Equal(t, utils.ComputeHash(secret.Data), hash)
+
+	secret.Annotations[devops.CredentialHostnamesAnnoKey] = "github.com"
+	assert.NotEqual(t, hash, computeDataHash(secret))
+}
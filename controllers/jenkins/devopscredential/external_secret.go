@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/utils/secretutil"
+)
+
+//+kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets,verbs=get
+
+// externalSecretGVK is the kind of ExternalSecret provided by External Secrets Operator
+var externalSecretGVK = schema.GroupVersionKind{
+	Group:   "external-secrets.io",
+	Version: "v1beta1",
+	Kind:    "ExternalSecret",
+}
+
+// externalSecretRequeuePeriod is the period to check an ExternalSecret again if it is not ready
+const externalSecretRequeuePeriod = 30 * time.Second
+
+// WithExternalSecretReader sets the reader which is used to look up ExternalSecrets.
+// The credentials referencing an ExternalSecret will stay pending if it's not set.
+func (c *Controller) WithExternalSecretReader(reader client.Reader) *Controller {
+	c.externalSecretReader = reader
+	return c
+}
+
+// isMaterialized checks whether the data of the credential is ready to sync to Jenkins.
+// A credential without an ExternalSecret is always materialized.
+// It returns the reason if the credential is not materialized.
+func (c *Controller) isMaterialized(secret *v1.Secret) (materialized bool, reason string, err error) {
+	if !secretutil.IsExternalCredential(secret) {
+		materialized = true
+		return
+	}
+	name := secret.Annotations[devopsv1alpha3.CredentialExternalSecretAnnoKey]
+	if c.externalSecretReader == nil {
+		reason = "ExternalSecret lookup is not enabled"
+		return
+	}
+
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetGroupVersionKind(externalSecretGVK)
+	if err = c.externalSecretReader.Get(context.Background(), types.NamespacedName{
+		Namespace: secret.Namespace,
+		Name:      name,
+	}, externalSecret); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			reason = fmt.Sprintf("ExternalSecret %s not found", name)
+		}
+		return
+	}
+
+	// the ExternalSecret writes into the secret which has the same name by default
+	target, _, _ := unstructured.NestedString(externalSecret.Object, "spec", "target", "name")
+	if target == "" {
+		target = name
+	}
+	if target != secret.Name {
+		reason = fmt.Sprintf("ExternalSecret %s targets secret %s instead of %s", name, target, secret.Name)
+		return
+	}
+	if ready, message := getExternalSecretReadyCondition(externalSecret); !ready {
+		reason = fmt.Sprintf("ExternalSecret %s is not ready", name)
+		if message != "" {
+			reason = fmt.Sprintf("%s: %s", reason, message)
+		}
+		return
+	}
+	if len(secret.Data) == 0 {
+		reason = fmt.Sprintf("waiting for ExternalSecret %s to write the data", name)
+		return
+	}
+	if validateErr := secretutil.ValidateCredential(secret); validateErr != nil {
+		reason = validateErr.Error()
+		return
+	}
+	materialized = true
+	return
+}
+
+func getExternalSecretReadyCondition(externalSecret *unstructured.Unstructured) (ready bool, message string) {
+	conditions, _, _ := unstructured.NestedSlice(externalSecret.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		ready = condition["status"] == string(v1.ConditionTrue)
+		message, _ = condition["message"].(string)
+		return
+	}
+	return
+}
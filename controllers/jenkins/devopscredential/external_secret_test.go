@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+func newExternalSecret(namespace, name, target string, conditions ...interface{}) *unstructured.Unstructured {
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetGroupVersionKind(externalSecretGVK)
+	externalSecret.SetNamespace(namespace)
+	externalSecret.SetName(name)
+	if target != "" {
+		_ = unstructured.SetNestedField(externalSecret.Object, target, "spec", "target", "name")
+	}
+	if len(conditions) > 0 {
+		_ = unstructured.SetNestedSlice(externalSecret.Object, conditions, "status", "conditions")
+	}
+	return externalSecret
+}
+
+func newExternalCredential(namespace, name, externalSecret string, data map[string][]byte) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Annotations: map[string]string{
+				devops.CredentialExternalSecretAnnoKey: externalSecret,
+			},
+		},
+		Type: devops.SecretTypeBearerToken,
+		Data: data,
+	}
+}
+
+func TestController_isMaterialized(t *testing.T) {
+	readyCondition := map[string]interface{}{"type": "Ready", "status": "True"}
+	notReadyCondition := map[string]interface{}{"type": "Ready", "status": "False", "message": "could not get secret data from provider"}
+	token := map[string][]byte{devops.BearerTokenKey: []byte("fake-token")}
+
+	tests := []struct {
+		name             string
+		noReader         bool
+		externalSecrets  []*unstructured.Unstructured
+		secret           *v1.Secret
+		wantMaterialized bool
+		wantReason       string
+	}{{
+		name:             "normal credential",
+		secret:           newSecret("ns", "token", nil, false, false, false),
+		wantMaterialized: true,
+	}, {
+		name:       "no ExternalSecret reader",
+		noReader:   true,
+		secret:     newExternalCredential("ns", "token", "token", token),
+		wantReason: "ExternalSecret lookup is not enabled",
+	}, {
+		name:       "ExternalSecret not found",
+		secret:     newExternalCredential("ns", "token", "token", token),
+		wantReason: "ExternalSecret token not found",
+	}, {
+		name:            "ExternalSecret targets another secret",
+		externalSecrets: []*unstructured.Unstructured{newExternalSecret("ns", "token", "another", readyCondition)},
+		secret:          newExternalCredential("ns", "token", "token", token),
+		wantReason:      "ExternalSecret token targets secret another instead of token",
+	}, {
+		name:            "ExternalSecret is not ready",
+		externalSecrets: []*unstructured.Unstructured{newExternalSecret("ns", "token", "", notReadyCondition)},
+		secret:          newExternalCredential("ns", "token", "token", token),
+		wantReason:      "ExternalSecret token is not ready: could not get secret data from provider",
+	}, {
+		name:            "ExternalSecret without status",
+		externalSecrets: []*unstructured.Unstructured{newExternalSecret("ns", "token", "")},
+		secret:          newExternalCredential("ns", "token", "token", token),
+		wantReason:      "ExternalSecret token is not ready",
+	}, {
+		name:            "data is not written yet",
+		externalSecrets: []*unstructured.Unstructured{newExternalSecret("ns", "token", "", readyCondition)},
+		secret:          newExternalCredential("ns", "token", "token", nil),
+		wantReason:      "waiting for ExternalSecret token to write the data",
+	}, {
+		name:            "invalid data",
+		externalSecrets: []*unstructured.Unstructured{newExternalSecret("ns", "token", "", readyCondition)},
+		secret: newExternalCredential("ns", "token", "token", map[string][]byte{
+			devops.BearerTokenKey: []byte("Bearer fake-token"),
+		}),
+		wantReason: "invalid bearer-token credential: token should not contain whitespaces",
+	}, {
+		name:             "materialized",
+		externalSecrets:  []*unstructured.Unstructured{newExternalSecret("ns", "aws-token", "token", readyCondition)},
+		secret:           newExternalCredential("ns", "token", "aws-token", token),
+		wantMaterialized: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{}
+			if !tt.noReader {
+				builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+				for i := range tt.externalSecrets {
+					builder.WithObjects(tt.externalSecrets[i])
+				}
+				c.WithExternalSecretReader(builder.Build())
+			}
+			materialized, reason, err := c.isMaterialized(tt.secret)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantMaterialized, materialized)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestPendingExternalCredential(t *testing.T) {
+	f := newFixture(t)
+	nsName := "test-123"
+	secretName := "test"
+	projectName := "test_project"
+
+	ns := newNamespace(nsName, projectName)
+	secret := newExternalCredential(nsName, secretName, secretName, nil)
+	expectSecret := secret.DeepCopy()
+	expectSecret.Finalizers = []string{devops.CredentialFinalizerName}
+	expectSecret.Annotations[devops.CredentialSyncStatusAnnoKey] = constants.StatusPending
+	expectSecret.Annotations[devops.CredentialSyncMsgAnnoKey] = "ExternalSecret lookup is not enabled"
+
+	f.secretLister = append(f.secretLister, secret)
+	f.namespaceLister = append(f.namespaceLister, ns)
+	f.kubeobjects = append(f.kubeobjects, secret)
+	f.initDevOpsProject = nsName
+	// the credential should not be synced to Jenkins until it's materialized
+	f.expectCredential = []*v1.Secret{}
+	f.expectUpdateSecretAction(expectSecret)
+	f.run(getKey(secret, t))
+}
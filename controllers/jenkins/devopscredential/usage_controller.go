@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/credential"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+
+// UsageReconciler tracks the Pipelines which reference a credential, then records them in the credential annotations
+type UsageReconciler struct {
+	client.Client
+
+	log logr.Logger
+}
+
+// Reconcile updates the Pipelines which reference the credential
+func (r *UsageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile the usage of credential: %s", req.String()))
+
+	secret := &v1.Secret{}
+	if err = r.Get(ctx, req.NamespacedName, secret); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !isCredential(secret) || !secret.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	pipelineList := &devopsv1alpha3.PipelineList{}
+	if err = r.List(ctx, pipelineList, client.InNamespace(secret.Namespace),
+		client.MatchingFields{devopsv1alpha3.PipelineCredentialsField: secret.Name}); err != nil {
+		return
+	}
+	usedBy := make([]string, 0)
+	for i := range pipelineList.Items {
+		pipeline := &pipelineList.Items[i]
+		if _, ok := credential.GetReferencedCredentials(&pipeline.Spec)[secret.Name]; ok {
+			usedBy = append(usedBy, pipeline.Name)
+		}
+	}
+	sort.Strings(usedBy)
+
+	usedByText := strings.Join(usedBy, ",")
+	if secret.Annotations[devopsv1alpha3.CredentialUsedByAnnoKey] == usedByText {
+		return
+	}
+	patch := client.MergeFrom(secret.DeepCopy())
+	if usedByText == "" {
+		delete(secret.Annotations, devopsv1alpha3.CredentialUsedByAnnoKey)
+	} else {
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[devopsv1alpha3.CredentialUsedByAnnoKey] = usedByText
+	}
+	err = r.Patch(ctx, secret, patch)
+	return
+}
+
+// findCredentials returns all the credentials in the namespace of a Pipeline, because we don't know
+// which credentials were referenced by the Pipeline before it was changed
+func (r *UsageReconciler) findCredentials(pipeline client.Object) (requests []reconcile.Request) {
+	secretList := &v1.SecretList{}
+	if err := r.List(context.Background(), secretList, client.InNamespace(pipeline.GetNamespace())); err != nil {
+		r.log.Error(err, "failed to list the credentials", "namespace", pipeline.GetNamespace())
+		return
+	}
+	for i := range secretList.Items {
+		if secret := &secretList.Items[i]; isCredential(secret) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: secret.Namespace,
+				Name:      secret.Name,
+			}})
+		}
+	}
+	return
+}
+
+// pipelineSpecChanged filters out the updates of Pipelines which don't change the spec. The generation of a Pipeline
+// is increased by the status updates as well, because the Pipeline has no status subresource.
+var pipelineSpecChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPipeline, oldOK := e.ObjectOld.(*devopsv1alpha3.Pipeline)
+		newPipeline, newOK := e.ObjectNew.(*devopsv1alpha3.Pipeline)
+		return !oldOK || !newOK || !reflect.DeepEqual(oldPipeline.Spec, newPipeline.Spec)
+	},
+}
+
+func isCredential(secret *v1.Secret) bool {
+	return strings.HasPrefix(string(secret.Type), devopsv1alpha3.DevOpsCredentialPrefix)
+}
+
+// GetName returns the name of this reconciler
+func (r *UsageReconciler) GetName() string {
+	return "credential-usage"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *UsageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1.Secret{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			secret, ok := object.(*v1.Secret)
+			return ok && isCredential(secret)
+		}))).
+		Watches(&source.Kind{Type: &devopsv1alpha3.Pipeline{}}, handler.EnqueueRequestsFromMapFunc(r.findCredentials),
+			builder.WithPredicates(pipelineSpecChanged)).
+		Complete(r)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newUsageTestPipeline(name, jenkinsfile string) *devops.Pipeline {
+	return &devops.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+		Spec: devops.PipelineSpec{
+			Type:     devops.NoScmPipelineType,
+			Pipeline: &devops.NoScmPipeline{Jenkinsfile: jenkinsfile},
+		},
+	}
+}
+
+func TestUsageReconciler_Reconcile(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, devops.AddToScheme(schema))
+
+	tests := []struct {
+		name       string
+		secret     *v1.Secret
+		wantUsedBy string
+		wantExist  bool
+	}{{
+		name: "not a credential",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+			Type:       v1.SecretTypeOpaque,
+		},
+	}, {
+		name: "used by Pipelines",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+			Type:       devops.SecretTypeBasicAuth,
+		},
+		wantUsedBy: "a,b",
+		wantExist:  true,
+	}, {
+		name: "not used anymore",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "token", Annotations: map[string]string{
+				devops.CredentialUsedByAnnoKey: "a",
+			}},
+			Type: devops.SecretTypeSecretText,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.secret,
+				newUsageTestPipeline("b", "git credentialsId: 'git', url: 'x'"),
+				newUsageTestPipeline("a", "checkout scmGit(userRemoteConfigs: [[credentialsId: 'git', url: 'x']])"),
+				newUsageTestPipeline("c", "echo 'git'")).Build()
+			r := &UsageReconciler{Client: c, log: logr.Discard()}
+
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: tt.secret.Namespace, Name: tt.secret.Name,
+			}})
+			assert.Nil(t, err)
+
+			secret := &v1.Secret{}
+			assert.Nil(t, c.Get(context.TODO(), client.ObjectKeyFromObject(tt.secret), secret))
+			usedBy, ok := secret.Annotations[devops.CredentialUsedByAnnoKey]
+			assert.Equal(t, tt.wantExist, ok)
+			assert.Equal(t, tt.wantUsedBy, usedBy)
+		})
+	}
+
+	t.Run("secret not found", func(t *testing.T) {
+		r := &UsageReconciler{Client: fake.NewClientBuilder().WithScheme(schema).Build(), log: logr.Discard()}
+		_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "fake"}})
+		assert.Nil(t, err)
+	})
+}
+
+func TestUsageReconciler_findCredentials(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, devops.AddToScheme(schema))
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+		Type:       devops.SecretTypeBasicAuth,
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "opaque"},
+		Type:       v1.SecretTypeOpaque,
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "git"},
+		Type:       devops.SecretTypeBasicAuth,
+	}).Build()
+	r := &UsageReconciler{Client: c, log: logr.Discard()}
+
+	requests := r.findCredentials(newUsageTestPipeline("a", ""))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "git"}}}, requests)
+}
+
+func TestPipelineSpecChanged(t *testing.T) {
+	oldPipeline := newUsageTestPipeline("demo", "node { echo 1 }")
+	oldPipeline.Generation = 1
+
+	statusChanged := oldPipeline.DeepCopy()
+	statusChanged.Generation = 2
+	statusChanged.Status.SuspendTime = &metav1.Time{}
+	assert.False(t, pipelineSpecChanged.Update(event.UpdateEvent{ObjectOld: oldPipeline, ObjectNew: statusChanged}))
+
+	specChanged := statusChanged.DeepCopy()
+	specChanged.Spec.Pipeline.Jenkinsfile = "node { echo 2 }"
+	assert.True(t, pipelineSpecChanged.Update(event.UpdateEvent{ObjectOld: statusChanged, ObjectNew: specChanged}))
+
+	assert.True(t, pipelineSpecChanged.Create(event.CreateEvent{Object: oldPipeline}))
+	assert.True(t, pipelineSpecChanged.Delete(event.DeleteEvent{Object: oldPipeline}))
+}
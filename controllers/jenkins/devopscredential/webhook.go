@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/utils/secretutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidatingWebhookPath is the path of the webhook which validates the DevOps credentials.
+const ValidatingWebhookPath = "/validate-devops-credential"
+
+//+kubebuilder:webhook:path=/validate-devops-credential,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=secrets,verbs=create;update,versions=v1,name=credential.devops.kubesphere.io,admissionReviewVersions=v1
+
+// Validator validates the data of DevOps credentials according to their types.
+type Validator struct {
+}
+
+var _ admission.Handler = &Validator{}
+
+// Handle validates the created or updated secret if it is a DevOps credential.
+func (v *Validator) Handle(_ context.Context, req admission.Request) admission.Response {
+	secret := &v1.Secret{}
+	if err := json.Unmarshal(req.Object.Raw, secret); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldSecret := &v1.Secret{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldSecret); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// don't block the updates which don't touch the data, like the sync status annotations
+		if oldSecret.Type == secret.Type && reflect.DeepEqual(oldSecret.Data, secret.Data) {
+			return admission.Allowed("")
+		}
+	}
+
+	if err := secretutil.ValidateCredential(secret); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// SetupWithManager registers the webhook into the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidatingWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestValidator_Handle(t *testing.T) {
+	toRaw := func(secret *v1.Secret) runtime.RawExtension {
+		data, _ := json.Marshal(secret)
+		return runtime.RawExtension{Raw: data}
+	}
+	validToken := &v1.Secret{
+		Type: v1alpha3.SecretTypeBearerToken,
+		Data: map[string][]byte{v1alpha3.BearerTokenKey: []byte("token")},
+	}
+	invalidToken := &v1.Secret{Type: v1alpha3.SecretTypeBearerToken}
+
+	tests := []struct {
+		name        string
+		request     admissionv1.AdmissionRequest
+		wantAllowed bool
+		wantCode    int32
+	}{{
+		name: "create a valid credential",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    toRaw(validToken),
+		},
+		wantAllowed: true,
+	}, {
+		name: "create an invalid credential",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    toRaw(invalidToken),
+		},
+		wantCode: 403,
+	}, {
+		name: "create a normal secret",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    toRaw(&v1.Secret{Type: v1.SecretTypeOpaque}),
+		},
+		wantAllowed: true,
+	}, {
+		name: "update an invalid credential without touching the data",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    toRaw(invalidToken),
+			OldObject: toRaw(invalidToken),
+		},
+		wantAllowed: true,
+	}, {
+		name: "update a credential with invalid data",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    toRaw(invalidToken),
+			OldObject: toRaw(validToken),
+		},
+		wantCode: 403,
+	}, {
+		name: "invalid object",
+		request: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte("fake")},
+		},
+		wantCode: 400,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &Validator{}
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: tt.request})
+			assert.Equal(t, tt.wantAllowed, resp.Allowed)
+			if !tt.wantAllowed {
+				assert.Equal(t, tt.wantCode, resp.Result.Code)
+			}
+		})
+	}
+}
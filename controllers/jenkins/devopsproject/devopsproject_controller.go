@@ -19,11 +19,9 @@ package devopsproject
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"reflect"
 	"time"
 
-	"github.com/emicklei/go-restful"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,10 +38,12 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"kubesphere.io/devops/controllers/core"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	"kubesphere.io/devops/pkg/client/clientset/versioned/scheme"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	"kubesphere.io/devops/pkg/constants"
 	"kubesphere.io/devops/pkg/utils/k8sutil"
 	"kubesphere.io/devops/pkg/utils/sliceutil"
@@ -56,6 +56,12 @@ import (
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;update;watch
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;update;create;watch
 
+// Valid values for event reasons of the Jenkins folder
+const (
+	JenkinsFolderInvalid   = "JenkinsFolderInvalid"
+	JenkinsFolderCollision = "JenkinsFolderCollision"
+)
+
 // Controller is the controller of the DevOpsProject
 type Controller struct {
 	client           clientset.Interface
@@ -75,6 +81,7 @@ type Controller struct {
 	workerLoopPeriod time.Duration
 
 	devopsClient devopsClient.Interface
+	folderNamer  *jenkins.FolderNamer
 }
 
 // NewController creates the instance of controller
@@ -94,7 +101,7 @@ func NewController(client clientset.Interface,
 		client:              client,
 		devopsClient:        devopsClinet,
 		kubesphereClient:    kubesphereClient,
-		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "devopsproject"),
+		workqueue:           workqueue.NewNamedRateLimitingQueue(core.NewTrackedRateLimiter("devopsproject", devopsv1alpha3.GroupVersion.WithKind(devopsv1alpha3.ResourceKindDevOpsProject)), "devopsproject"),
 		devOpsProjectLister: devopsInformer.Lister(),
 		devOpsProjectSynced: devopsInformer.Informer().HasSynced,
 		namespaceLister:     namespaceInformer.Lister(),
@@ -315,14 +322,9 @@ func (c *Controller) syncHandler(key string) error {
 		//	return err
 		//}
 
-		// Check project exists, otherwise we will create it.
-		_, err := c.devopsClient.GetDevOpsProject(copyProject.Status.AdminNamespace)
-		if err != nil {
-			_, err := c.devopsClient.CreateDevOpsProject(copyProject.Status.AdminNamespace)
-			if err != nil {
-				klog.V(8).Info(err, fmt.Sprintf("failed to get project %s ", key))
-				return err
-			}
+		if err := c.syncJenkinsFolder(copyProject); err != nil {
+			klog.V(8).Info(err, fmt.Sprintf("failed to sync the Jenkins folder of project %s ", key))
+			return err
 		}
 
 		//If there is no early return, then the sync is successful.
@@ -343,14 +345,8 @@ func (c *Controller) syncHandler(key string) error {
 		if sliceutil.HasString(project.ObjectMeta.Finalizers, devopsv1alpha3.DevOpsProjectFinalizerName) {
 			delSuccess := false
 			if err := c.deleteDevOpsProjectInDevOps(project); err != nil {
-				// the status code should be 404 if the job does not exists
-				if srvErr, ok := err.(restful.ServiceError); ok {
-					delSuccess = srvErr.Code == http.StatusNotFound
-				} else if srvErr, ok := err.(*devopsClient.ErrorResponse); ok {
-					delSuccess = srvErr.Response.StatusCode == http.StatusNotFound
-				} else {
-					klog.Error(fmt.Sprintf("unexpected error type: %v, should be *restful.ServiceError", err))
-				}
+				// the job does not exist
+				delSuccess = devopsClient.IsNotFound(err)
 
 				klog.V(8).Info(err, fmt.Sprintf("failed to delete resource %s in devops", key))
 			} else {
@@ -410,6 +406,72 @@ func (c *Controller) syncHandler(key string) error {
 //	return project, nil
 //}
 
+// WithFolderNamer sets the namer of the Jenkins folders, the folders are named after the namespaces if it's nil
+func (c *Controller) WithFolderNamer(namer *jenkins.FolderNamer) *Controller {
+	c.folderNamer = namer
+	return c
+}
+
+// syncJenkinsFolder makes sure the Jenkins folder of the project exists. The folder name is assigned once and kept in
+// the annotation of the project, because the other components resolve the folder from it.
+func (c *Controller) syncJenkinsFolder(project *devopsv1alpha3.DevOpsProject) (err error) {
+	data := jenkins.FolderNameData{
+		Namespace:  project.Status.AdminNamespace,
+		Project:    project.Name,
+		ProjectUID: string(project.UID),
+	}
+	folder := project.Annotations[devopsv1alpha3.DevOpsProjectJenkinsFolderAnnoKey]
+	if folder != "" {
+		// check project exists, otherwise we will create it
+		if _, err = c.devopsClient.GetDevOpsProject(folder); err == nil {
+			return
+		}
+	} else {
+		if folder, err = c.folderNamer.Name(data); err != nil {
+			c.eventRecorder.Event(project, v1.EventTypeWarning, JenkinsFolderInvalid, err.Error())
+			return
+		}
+		if err = c.checkFolderCollision(project, folder); err != nil {
+			c.eventRecorder.Event(project, v1.EventTypeWarning, JenkinsFolderCollision, err.Error())
+			return
+		}
+	}
+
+	if _, err = c.devopsClient.CreateOwnedDevOpsProject(folder, c.folderNamer.Owner(data)); err != nil {
+		if devopsClient.ReasonForError(err) == devopsClient.ErrorReasonConflict {
+			c.eventRecorder.Event(project, v1.EventTypeWarning, JenkinsFolderCollision, err.Error())
+		}
+		return
+	}
+	if project.Annotations == nil {
+		project.Annotations = map[string]string{}
+	}
+	project.Annotations[devopsv1alpha3.DevOpsProjectJenkinsFolderAnnoKey] = folder
+	return
+}
+
+// checkFolderCollision returns an error if the folder is used by another project in this cluster
+func (c *Controller) checkFolderCollision(project *devopsv1alpha3.DevOpsProject, folder string) error {
+	projects, err := c.devOpsProjectLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, another := range projects {
+		if another.UID == project.UID {
+			continue
+		}
+		// the folder of a project without the annotation is its namespace
+		anotherFolder := another.Annotations[devopsv1alpha3.DevOpsProjectJenkinsFolderAnnoKey]
+		if anotherFolder == "" {
+			anotherFolder = another.Status.AdminNamespace
+		}
+		if anotherFolder == folder || another.Status.AdminNamespace == folder {
+			return fmt.Errorf("the Jenkins folder %s is used by the DevOps project %s", folder, another.Name)
+		}
+	}
+	return nil
+}
+
 func (c *Controller) deleteDevOpsProjectInDevOps(project *devopsv1alpha3.DevOpsProject) (err error) {
 	err = c.devopsClient.DeleteDevOpsProject(project.Status.AdminNamespace)
 	return
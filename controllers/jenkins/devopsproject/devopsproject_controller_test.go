@@ -20,6 +20,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+
 	v1 "k8s.io/api/core/v1"
 
 	devopsprojects "kubesphere.io/devops/pkg/api/devops/v1alpha3"
@@ -355,3 +360,56 @@ func TestDeleteDevOpsProjectsWithNull(t *testing.T) {
 	f.expectUpdateDevOpsProjectAction(expectProject)
 	f.run(getKey(project, t))
 }
+
+func TestSyncJenkinsFolder(t *testing.T) {
+	newProject := func(name, folder string) *devops.DevOpsProject {
+		project := newDevOpsProject(name, name, true, true)
+		project.UID = k8stypes.UID("uid-" + name)
+		if folder != "" {
+			project.Annotations = map[string]string{devops.DevOpsProjectJenkinsFolderAnnoKey: folder}
+		}
+		return project
+	}
+	f := newFixture(t)
+	f.devopsProjectLister = []*devops.DevOpsProject{
+		newProject("demo", ""),
+		newProject("kept", "kept-folder"),
+		// the new folder of the project b is used by this project
+		newProject("taken", "host-b"),
+		// the new folder of the project c is the namespace of this legacy project
+		newProject("host-c", ""),
+	}
+	f.initDevOpsProject = []string{"host-c"}
+	c, _, _, dI := f.newController()
+	namer, err := jenkins.NewFolderNamer(&jenkins.Options{
+		FolderNaming: jenkins.FolderNamingPrefix, FolderPrefix: "host-", ClusterName: "host",
+	})
+	assert.Nil(t, err)
+	c.WithFolderNamer(namer)
+
+	project := newProject("demo", "")
+	assert.Nil(t, c.syncJenkinsFolder(project))
+	assert.Equal(t, "host-demo", project.Annotations[devops.DevOpsProjectJenkinsFolderAnnoKey])
+	assert.Equal(t, "host/uid-demo", dI.Projects["host-demo"])
+
+	// the assigned folder is never renamed
+	project = newProject("kept", "kept-folder")
+	assert.Nil(t, c.syncJenkinsFolder(project))
+	assert.Equal(t, "kept-folder", project.Annotations[devops.DevOpsProjectJenkinsFolderAnnoKey])
+	assert.Contains(t, dI.Projects, "kept-folder")
+
+	// the folders used by the other projects in this cluster
+	for _, name := range []string{"b", "c"} {
+		project = newProject(name, "")
+		assert.NotNil(t, c.syncJenkinsFolder(project))
+		assert.Empty(t, project.Annotations[devops.DevOpsProjectJenkinsFolderAnnoKey])
+	}
+	assert.Equal(t, true, dI.Projects["host-c"])
+
+	// the folder is owned by another cluster
+	dI.Projects["host-d"] = "another/uid-d"
+	project = newProject("d", "")
+	err = c.syncJenkinsFolder(project)
+	assert.Equal(t, devopsClient.ErrorReasonConflict, devopsClient.ReasonForError(err))
+	assert.Empty(t, project.Annotations[devops.DevOpsProjectJenkinsFolderAnnoKey])
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// ReasonPluginsReady indicates all the required plugins are ready
+	ReasonPluginsReady = "PluginsReady"
+	// ReasonPluginsNotReady indicates some required plugins are missing, outdated, or inactive
+	ReasonPluginsNotReady = "PluginsNotReady"
+	// ReasonCheckFailed indicates the plugins of Jenkins could not be listed
+	ReasonCheckFailed = "CheckFailed"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=jenkinsstatuses,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=jenkinsstatuses/status,verbs=get;update;patch
+
+// Reconciler periodically checks if the required plugins are installed in Jenkins with the minimum versions,
+// then reports them in the status of the JenkinsStatus
+type Reconciler struct {
+	client.Client
+	Plugins PluginLister
+	// CheckPeriod is the period between two checks
+	CheckPeriod time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile checks the plugins of Jenkins
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile JenkinsStatus: %s", req.String()))
+	if req.Name != v1alpha3.JenkinsStatusName {
+		return
+	}
+
+	jenkinsStatus := &v1alpha3.JenkinsStatus{}
+	if err = r.Get(ctx, req.NamespacedName, jenkinsStatus); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	result.RequeueAfter = r.CheckPeriod
+
+	plugins, checkErr := Check(r.Plugins, MergeRequirements(DefaultRequirements, jenkinsStatus.Spec.Plugins))
+	now := metav1.Now()
+	condition := newPluginsCondition(plugins, checkErr, now)
+	if previous := jenkinsStatus.Status.GetCondition(v1alpha3.ConditionPluginsReady); condition.Status == v1alpha3.ConditionFalse &&
+		(previous == nil || previous.Status != v1alpha3.ConditionFalse || previous.Message != condition.Message) {
+		r.recorder.Event(jenkinsStatus, v1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	// keep the last known plugins if Jenkins is unreachable
+	if checkErr == nil {
+		jenkinsStatus.Status.Plugins = plugins
+	}
+	jenkinsStatus.Status.LastCheckTime = &now
+	jenkinsStatus.Status.SetCondition(condition)
+	err = r.Status().Update(ctx, jenkinsStatus)
+	return
+}
+
+func newPluginsCondition(plugins []v1alpha3.PluginStatus, checkErr error, now metav1.Time) v1alpha3.Condition {
+	condition := v1alpha3.Condition{
+		Type:               v1alpha3.ConditionPluginsReady,
+		Status:             v1alpha3.ConditionTrue,
+		Reason:             ReasonPluginsReady,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	}
+	if checkErr != nil {
+		condition.Status = v1alpha3.ConditionUnknown
+		condition.Reason = ReasonCheckFailed
+		condition.Message = checkErr.Error()
+		return condition
+	}
+
+	var problems []string
+	for _, item := range plugins {
+		switch item.State {
+		case v1alpha3.PluginMissing:
+			problems = append(problems, fmt.Sprintf("%s is missing", item.Name))
+		case v1alpha3.PluginInactive:
+			problems = append(problems, fmt.Sprintf("%s is inactive", item.Name))
+		case v1alpha3.PluginOutdated:
+			problems = append(problems, fmt.Sprintf("%s %s is older than %s", item.Name, item.Version, item.MinVersion))
+		}
+	}
+	if len(problems) > 0 {
+		condition.Status = v1alpha3.ConditionFalse
+		condition.Reason = ReasonPluginsNotReady
+		condition.Message = strings.Join(problems, ", ")
+	}
+	return condition
+}
+
+// ensureJenkinsStatus creates the JenkinsStatus if it does not exist, so the plugins are checked on startup
+func (r *Reconciler) ensureJenkinsStatus(ctx context.Context) error {
+	jenkinsStatus := &v1alpha3.JenkinsStatus{ObjectMeta: metav1.ObjectMeta{Name: v1alpha3.JenkinsStatusName}}
+	if err := r.Create(ctx, jenkinsStatus); err != nil && !apierrors.IsAlreadyExists(err) {
+		r.log.Error(err, "failed to create the JenkinsStatus")
+	}
+	return nil
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "jenkins-status"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if err := mgr.Add(manager.RunnableFunc(r.ensureJenkinsStatus)); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&v1alpha3.JenkinsStatus{}).
+		Complete(r)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsstatus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	var allInstalled []plugin.InstalledPlugin
+	for _, requirement := range DefaultRequirements {
+		allInstalled = append(allInstalled, installed(requirement.Name, requirement.MinVersion, true))
+	}
+	lastCheck := metav1.NewTime(time.Now().Add(-time.Hour))
+	knownPlugins := []v1alpha3.PluginStatus{{Name: "kubernetes", State: v1alpha3.PluginReady}}
+
+	tests := []struct {
+		name            string
+		spec            v1alpha3.JenkinsStatusSpec
+		status          v1alpha3.JenkinsStatusStatus
+		lister          PluginLister
+		wantStatus      v1alpha3.ConditionStatus
+		wantReason      string
+		wantMessage     string
+		wantPlugins     int
+		wantEvent       bool
+		keepsPlugins    bool
+		keepsTransition bool
+	}{{
+		name:        "all plugins are ready",
+		lister:      &fakeLister{plugins: allInstalled},
+		wantStatus:  v1alpha3.ConditionTrue,
+		wantReason:  ReasonPluginsReady,
+		wantPlugins: len(DefaultRequirements),
+	}, {
+		name:        "an extra plugin is missing",
+		spec:        v1alpha3.JenkinsStatusSpec{Plugins: []v1alpha3.PluginRequirement{{Name: "git"}}},
+		lister:      &fakeLister{plugins: allInstalled},
+		wantStatus:  v1alpha3.ConditionFalse,
+		wantReason:  ReasonPluginsNotReady,
+		wantMessage: "git is missing",
+		wantPlugins: len(DefaultRequirements) + 1,
+		wantEvent:   true,
+	}, {
+		name: "a plugin is outdated",
+		spec: v1alpha3.JenkinsStatusSpec{Plugins: []v1alpha3.PluginRequirement{{Name: "kubernetes", MinVersion: "9.0"}}},
+		status: v1alpha3.JenkinsStatusStatus{Conditions: []v1alpha3.Condition{{
+			Type: v1alpha3.ConditionPluginsReady, Status: v1alpha3.ConditionFalse, Reason: ReasonPluginsNotReady,
+			Message: "kubernetes 1.30.0 is older than 9.0", LastTransitionTime: lastCheck,
+		}}},
+		lister:          &fakeLister{plugins: allInstalled},
+		wantStatus:      v1alpha3.ConditionFalse,
+		wantReason:      ReasonPluginsNotReady,
+		wantMessage:     "kubernetes 1.30.0 is older than 9.0",
+		wantPlugins:     len(DefaultRequirements),
+		keepsTransition: true,
+	}, {
+		name:         "Jenkins is unreachable",
+		status:       v1alpha3.JenkinsStatusStatus{Plugins: knownPlugins},
+		lister:       &fakeLister{err: errors.New("connection refused")},
+		wantStatus:   v1alpha3.ConditionUnknown,
+		wantReason:   ReasonCheckFailed,
+		wantMessage:  "failed to get the plugins of Jenkins, error: connection refused",
+		wantPlugins:  1,
+		keepsPlugins: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.JenkinsStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: v1alpha3.JenkinsStatusName},
+				Spec:       tt.spec,
+				Status:     tt.status,
+			}).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Client:      c,
+				Plugins:     tt.lister,
+				CheckPeriod: time.Minute,
+				log:         logr.Discard(),
+				recorder:    recorder,
+			}
+
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: v1alpha3.JenkinsStatusName}})
+			assert.Nil(t, err)
+			assert.Equal(t, time.Minute, result.RequeueAfter)
+			assert.Equal(t, tt.wantEvent, len(recorder.Events) > 0)
+
+			jenkinsStatus := &v1alpha3.JenkinsStatus{}
+			assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Name: v1alpha3.JenkinsStatusName}, jenkinsStatus))
+			assert.NotNil(t, jenkinsStatus.Status.LastCheckTime)
+			assert.Len(t, jenkinsStatus.Status.Plugins, tt.wantPlugins)
+			if tt.keepsPlugins {
+				assert.Equal(t, knownPlugins, jenkinsStatus.Status.Plugins)
+			}
+			condition := jenkinsStatus.Status.GetCondition(v1alpha3.ConditionPluginsReady)
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, tt.wantStatus, condition.Status)
+				assert.Equal(t, tt.wantReason, condition.Reason)
+				assert.Equal(t, tt.wantMessage, condition.Message)
+				assert.Equal(t, tt.keepsTransition, condition.LastTransitionTime.Unix() == lastCheck.Unix())
+			}
+		})
+	}
+
+	// the other JenkinsStatus is ignored
+	c := fake.NewClientBuilder().WithScheme(schema).Build()
+	r := &Reconciler{Client: c, Plugins: &fakeLister{}, log: logr.Discard()}
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "fake"}})
+	assert.Nil(t, err)
+	assert.Zero(t, result.RequeueAfter)
+}
+
+func TestReconciler_ensureJenkinsStatus(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	c := fake.NewClientBuilder().WithScheme(schema).Build()
+	r := &Reconciler{Client: c, log: logr.Discard()}
+
+	// it's fine to call it more than once
+	assert.Nil(t, r.ensureJenkinsStatus(context.TODO()))
+	assert.Nil(t, r.ensureJenkinsStatus(context.TODO()))
+	assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Name: v1alpha3.JenkinsStatusName}, &v1alpha3.JenkinsStatus{}))
+}
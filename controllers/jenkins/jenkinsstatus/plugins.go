@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsstatus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/plugin"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PluginLister lists the installed plugins of Jenkins, it's implemented by plugin.Manager
+type PluginLister interface {
+	GetPlugins(depth int) (*plugin.InstalledPluginList, error)
+}
+
+// DefaultRequirements are the plugins which the features of DevOps depend on
+var DefaultRequirements = []v1alpha3.PluginRequirement{
+	{Name: "workflow-aggregator", MinVersion: "2.6"},
+	{Name: "pipeline-model-definition", MinVersion: "1.9.0"},
+	{Name: "kubernetes", MinVersion: "1.30.0"},
+	{Name: "configuration-as-code", MinVersion: "1.55"},
+	{Name: "generic-webhook-trigger", MinVersion: "1.72"},
+}
+
+// MergeRequirements returns the default requirements overridden or extended by the extra ones
+func MergeRequirements(defaults, extra []v1alpha3.PluginRequirement) []v1alpha3.PluginRequirement {
+	result := make([]v1alpha3.PluginRequirement, 0, len(defaults)+len(extra))
+	index := map[string]int{}
+	for _, requirement := range append(append([]v1alpha3.PluginRequirement{}, defaults...), extra...) {
+		if i, ok := index[requirement.Name]; ok {
+			result[i] = requirement
+			continue
+		}
+		index[requirement.Name] = len(result)
+		result = append(result, requirement)
+	}
+	return result
+}
+
+// Check returns the states of the required plugins in Jenkins
+func Check(lister PluginLister, requirements []v1alpha3.PluginRequirement) ([]v1alpha3.PluginStatus, error) {
+	list, err := lister.GetPlugins(1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the plugins of Jenkins, error: %v", err)
+	}
+	installed := map[string]plugin.InstalledPlugin{}
+	if list != nil {
+		for _, item := range list.Plugins {
+			installed[item.ShortName] = item
+		}
+	}
+
+	plugins := make([]v1alpha3.PluginStatus, 0, len(requirements))
+	for _, requirement := range requirements {
+		status := v1alpha3.PluginStatus{Name: requirement.Name, MinVersion: requirement.MinVersion}
+		item, ok := installed[requirement.Name]
+		switch {
+		case !ok:
+			status.State = v1alpha3.PluginMissing
+		case !item.Active || !item.Enabled:
+			status.State = v1alpha3.PluginInactive
+		case requirement.MinVersion != "" && compareVersion(item.Version, requirement.MinVersion) < 0:
+			status.State = v1alpha3.PluginOutdated
+		default:
+			status.State = v1alpha3.PluginReady
+		}
+		if ok {
+			status.Version = item.Version
+		}
+		plugins = append(plugins, status)
+	}
+	return plugins, nil
+}
+
+// Preflight checks the plugins once, the requirements of the existing JenkinsStatus are taken into account.
+// It's supposed to be called before the manager starts, so the reader should not depend on the cache.
+func Preflight(ctx context.Context, reader client.Reader, lister PluginLister) ([]v1alpha3.PluginStatus, error) {
+	var extra []v1alpha3.PluginRequirement
+	status := &v1alpha3.JenkinsStatus{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: v1alpha3.JenkinsStatusName}, status); err == nil {
+		extra = status.Spec.Plugins
+	}
+	return Check(lister, MergeRequirements(DefaultRequirements, extra))
+}
+
+// NotReady returns the names of the plugins which were checked but not ready
+func NotReady(plugins []v1alpha3.PluginStatus, names []string) (notReady []string) {
+	for _, name := range names {
+		for _, item := range plugins {
+			if item.Name == name && item.State != v1alpha3.PluginReady {
+				notReady = append(notReady, name)
+			}
+		}
+	}
+	return
+}
+
+// compareVersion compares the leading numeric parts of two plugin versions, such as 1.31.3 of 1.31.3-beta or 3893 of
+// 3893.v213a_42768d35. It returns -1, 0, or 1 like strings.Compare.
+func compareVersion(a, b string) int {
+	x, y := parseVersion(a), parseVersion(b)
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var m, n int
+		if i < len(x) {
+			m = x[i]
+		}
+		if i < len(y) {
+			n = y[i]
+		}
+		if m != n {
+			if m < n {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(version string) (numbers []int) {
+	for _, part := range strings.Split(version, ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		number, err := strconv.Atoi(part[:end])
+		if err != nil {
+			return
+		}
+		numbers = append(numbers, number)
+		if end < len(part) {
+			// the qualifier ends the numeric parts, such as -beta-1
+			return
+		}
+	}
+	return
+}
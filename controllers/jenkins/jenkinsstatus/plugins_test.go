@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsstatus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeLister struct {
+	plugins []plugin.InstalledPlugin
+	err     error
+}
+
+func (l *fakeLister) GetPlugins(int) (*plugin.InstalledPluginList, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return &plugin.InstalledPluginList{Plugins: l.plugins}, nil
+}
+
+func installed(name, version string, active bool) plugin.InstalledPlugin {
+	return plugin.InstalledPlugin{
+		Plugin:    plugin.Plugin{Active: active, Enabled: active},
+		ShortName: name,
+		Version:   version,
+	}
+}
+
+func Test_compareVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.31.3", b: "1.30.0", want: 1},
+		{a: "1.30", b: "1.30.0", want: 0},
+		{a: "2.6", b: "2.10", want: -1},
+		{a: "1.9.0-beta-1", b: "1.9.0", want: 0},
+		{a: "3893.v213a_42768d35", b: "1.55", want: 1},
+		{a: "", b: "1.0", want: -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersion(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestMergeRequirements(t *testing.T) {
+	result := MergeRequirements([]v1alpha3.PluginRequirement{
+		{Name: "kubernetes", MinVersion: "1.30.0"},
+		{Name: "git"},
+	}, []v1alpha3.PluginRequirement{
+		{Name: "git", MinVersion: "4.0.0"},
+		{Name: "docker-workflow"},
+	})
+	assert.Equal(t, []v1alpha3.PluginRequirement{
+		{Name: "kubernetes", MinVersion: "1.30.0"},
+		{Name: "git", MinVersion: "4.0.0"},
+		{Name: "docker-workflow"},
+	}, result)
+}
+
+func TestCheck(t *testing.T) {
+	lister := &fakeLister{plugins: []plugin.InstalledPlugin{
+		installed("kubernetes", "1.31.3", true),
+		installed("git", "3.9.0", true),
+		installed("docker-workflow", "1.26", false),
+	}}
+	plugins, err := Check(lister, []v1alpha3.PluginRequirement{
+		{Name: "kubernetes", MinVersion: "1.30.0"},
+		{Name: "git", MinVersion: "4.0.0"},
+		{Name: "docker-workflow"},
+		{Name: "workflow-aggregator"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []v1alpha3.PluginStatus{
+		{Name: "kubernetes", MinVersion: "1.30.0", Version: "1.31.3", State: v1alpha3.PluginReady},
+		{Name: "git", MinVersion: "4.0.0", Version: "3.9.0", State: v1alpha3.PluginOutdated},
+		{Name: "docker-workflow", Version: "1.26", State: v1alpha3.PluginInactive},
+		{Name: "workflow-aggregator", State: v1alpha3.PluginMissing},
+	}, plugins)
+	assert.Equal(t, []string{"git", "workflow-aggregator"},
+		NotReady(plugins, []string{"kubernetes", "git", "workflow-aggregator", "unknown"}))
+
+	_, err = Check(&fakeLister{err: errors.New("connection refused")}, DefaultRequirements)
+	assert.NotNil(t, err)
+}
+
+func TestPreflight(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	lister := &fakeLister{plugins: []plugin.InstalledPlugin{installed("kubernetes", "1.31.3", true)}}
+
+	// only the default requirements without the JenkinsStatus
+	c := fake.NewClientBuilder().WithScheme(schema).Build()
+	plugins, err := Preflight(context.TODO(), c, lister)
+	assert.Nil(t, err)
+	assert.Len(t, plugins, len(DefaultRequirements))
+
+	c = fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.JenkinsStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha3.JenkinsStatusName},
+		Spec: v1alpha3.JenkinsStatusSpec{Plugins: []v1alpha3.PluginRequirement{
+			{Name: "kubernetes", MinVersion: "1.32.0"},
+			{Name: "git"},
+		}},
+	}).Build()
+	plugins, err = Preflight(context.TODO(), c, lister)
+	assert.Nil(t, err)
+	assert.Len(t, plugins, len(DefaultRequirements)+1)
+	assert.Equal(t, []string{"kubernetes", "git"}, NotReady(plugins, []string{"kubernetes", "git"}))
+}
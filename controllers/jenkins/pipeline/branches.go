@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// branchesPageSize is the number of branches queried in a single request
+	branchesPageSize = 100
+	// branchesWorkers is the maximum number of concurrent requests for querying the branches of a Pipeline
+	branchesWorkers = 5
+	// branchesCacheTTL is the maximum age of the branches which are reused across reconciles
+	branchesCacheTTL = time.Minute
+)
+
+// branchesGetter gets the branches of a multi-branch Pipeline from Jenkins.
+type branchesGetter interface {
+	GetBranches(option job.GetBranchesOption) ([]job.PipelineBranch, error)
+}
+
+// getAllBranches queries all branches of a multi-branch Pipeline page by page. The first page is queried alone,
+// then the rest pages are queried concurrently by a bounded number of workers in case of a lot of branches.
+func getAllBranches(getter branchesGetter, namespace, pipelineName string, pageSize, workers int) ([]job.PipelineBranch, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	getPage := func(start int) ([]job.PipelineBranch, error) {
+		return getter.GetBranches(job.GetBranchesOption{
+			Folders:      []string{namespace},
+			PipelineName: pipelineName,
+			Start:        start,
+			Limit:        pageSize,
+		})
+	}
+
+	branches, err := getPage(0)
+	if err != nil || len(branches) < pageSize {
+		return branches, err
+	}
+	for start := pageSize; ; start += pageSize * workers {
+		pages := make([][]job.PipelineBranch, workers)
+		errs := make([]error, workers)
+		wg := sync.WaitGroup{}
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pages[i], errs[i] = getPage(start + i*pageSize)
+			}(i)
+		}
+		wg.Wait()
+		if err := utilerrors.NewAggregate(errs); err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			branches = append(branches, page...)
+			if len(page) < pageSize {
+				return branches, nil
+			}
+		}
+	}
+}
+
+type branchesCacheEntry struct {
+	fingerprint string
+	branches    string
+	cachedAt    time.Time
+}
+
+// branchesCache keeps the branches of multi-branch Pipelines, the branches are reused until the metadata of the
+// Pipeline changes or they are expired. The expired entries are evicted when setting, including the deleted Pipelines.
+type branchesCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[types.NamespacedName]branchesCacheEntry
+}
+
+func newBranchesCache(ttl time.Duration) *branchesCache {
+	return &branchesCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[types.NamespacedName]branchesCacheEntry{},
+	}
+}
+
+// get returns the cached branches if the fingerprint is the same and not expired.
+func (c *branchesCache) get(key types.NamespacedName, fingerprint string) (branches string, ok bool) {
+	if c == nil || fingerprint == "" {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, exist := c.entries[key]
+	if !exist || entry.fingerprint != fingerprint || c.now().Sub(entry.cachedAt) > c.ttl {
+		return
+	}
+	return entry.branches, true
+}
+
+func (c *branchesCache) set(key types.NamespacedName, fingerprint, branches string) {
+	if c == nil || fingerprint == "" {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if now.Sub(entry.cachedAt) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = branchesCacheEntry{
+		fingerprint: fingerprint,
+		branches:    branches,
+		cachedAt:    now,
+	}
+}
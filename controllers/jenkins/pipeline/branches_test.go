@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type fakeBranchesGetter struct {
+	mutex    sync.Mutex
+	total    int
+	fail     bool
+	failFrom int
+	requests []job.GetBranchesOption
+}
+
+func (g *fakeBranchesGetter) GetBranches(option job.GetBranchesOption) (branches []job.PipelineBranch, err error) {
+	g.mutex.Lock()
+	g.requests = append(g.requests, option)
+	g.mutex.Unlock()
+	if g.fail && option.Start >= g.failFrom {
+		err = errors.New("fake error")
+		return
+	}
+	for i := option.Start; i < g.total && i < option.Start+option.Limit; i++ {
+		branch := job.PipelineBranch{}
+		branch.Name = fmt.Sprintf("branch-%d", i)
+		branches = append(branches, branch)
+	}
+	return
+}
+
+func Test_getAllBranches(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		fail         bool
+		failFrom     int
+		workers      int
+		wantErr      bool
+		wantRequests int
+	}{{
+		name:         "no branches",
+		workers:      3,
+		wantRequests: 1,
+	}, {
+		name:         "less than one page",
+		total:        9,
+		workers:      3,
+		wantRequests: 1,
+	}, {
+		name:         "exactly one page",
+		total:        10,
+		workers:      3,
+		wantRequests: 4,
+	}, {
+		name:         "a lot of branches",
+		total:        75,
+		workers:      3,
+		wantRequests: 1 + 3*3,
+	}, {
+		name:         "without workers",
+		total:        25,
+		wantRequests: 3,
+	}, {
+		name:         "failed to query the first page",
+		total:        25,
+		fail:         true,
+		workers:      3,
+		wantErr:      true,
+		wantRequests: 1,
+	}, {
+		name:         "failed to query the rest pages",
+		total:        25,
+		fail:         true,
+		failFrom:     20,
+		workers:      3,
+		wantErr:      true,
+		wantRequests: 4,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := &fakeBranchesGetter{total: tt.total, fail: tt.fail, failFrom: tt.failFrom}
+			branches, err := getAllBranches(getter, "ns", "pipeline", 10, tt.workers)
+			assert.Equal(t, tt.wantRequests, len(getter.requests))
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.total, len(branches))
+			for i := range branches {
+				assert.Equal(t, fmt.Sprintf("branch-%d", i), branches[i].Name)
+			}
+
+			starts := make([]int, 0, len(getter.requests))
+			for _, request := range getter.requests {
+				assert.Equal(t, []string{"ns"}, request.Folders)
+				assert.Equal(t, "pipeline", request.PipelineName)
+				assert.Equal(t, 10, request.Limit)
+				starts = append(starts, request.Start)
+			}
+			sort.Ints(starts)
+			for i := range starts {
+				assert.Equal(t, i*10, starts[i])
+			}
+		})
+	}
+}
+
+func Test_branchesCache(t *testing.T) {
+	now := time.Now()
+	cache := newBranchesCache(time.Minute)
+	cache.now = func() time.Time {
+		return now
+	}
+	keyA := types.NamespacedName{Namespace: "ns", Name: "a"}
+	keyB := types.NamespacedName{Namespace: "ns", Name: "b"}
+
+	_, ok := cache.get(keyA, "v1")
+	assert.False(t, ok)
+
+	cache.set(keyA, "v1", "[a]")
+	branches, ok := cache.get(keyA, "v1")
+	assert.True(t, ok)
+	assert.Equal(t, "[a]", branches)
+
+	// the metadata changed
+	_, ok = cache.get(keyA, "v2")
+	assert.False(t, ok)
+	// without metadata
+	_, ok = cache.get(keyA, "")
+	assert.False(t, ok)
+	cache.set(keyB, "", "[b]")
+	_, ok = cache.get(keyB, "")
+	assert.False(t, ok)
+
+	// expired entries are evicted
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.get(keyA, "v1")
+	assert.False(t, ok)
+	cache.set(keyB, "v1", "[b]")
+	assert.Equal(t, 1, len(cache.entries))
+
+	// a nil cache does nothing
+	var nilCache *branchesCache
+	nilCache.set(keyA, "v1", "[a]")
+	_, ok = nilCache.get(keyA, "v1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DriftDetected indicates the Jenkins job was modified out of the Pipeline
+	DriftDetected = "DriftDetected"
+	// DriftRepaired indicates the Jenkins job was repaired according to the Pipeline
+	DriftRepaired = "DriftRepaired"
+	// FailedDriftRepair indicates the controller fails to repair the Jenkins job
+	FailedDriftRepair = "FailedDriftRepair"
+)
+
+// DriftReconciler periodically compares the Jenkins job with the one which the Pipeline should generate,
+// then reports the drift in the status or repairs the Jenkins job.
+type DriftReconciler struct {
+	client.Client
+	DevOpsClient devopsClient.Interface
+	// Interval is the period between two detections of a Pipeline
+	Interval time.Duration
+	// DefaultPolicy is the policy for the Pipelines which have no drift policy annotation
+	DefaultPolicy string
+
+	recorder record.EventRecorder
+	log      logr.Logger
+}
+
+// Reconcile detects the drift of a Pipeline
+func (r *DriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := r.log.WithValues("Pipeline", req.NamespacedName)
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, req.NamespacedName, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	policy := r.getDriftPolicy(pipeline)
+	if !pipeline.DeletionTimestamp.IsZero() || policy == v1alpha3.PipelineDriftPolicyIgnore {
+		return
+	}
+	result.RequeueAfter = r.Interval
+
+	// only the Pipeline which was synchronized into Jenkins is comparable
+	if pipeline.Annotations[v1alpha3.PipelineSyncStatusAnnoKey] != constants.StatusSuccessful {
+		return
+	}
+	if specHash, ok := pipeline.Annotations[v1alpha3.PipelineSpecHash]; ok && specHash != utils.ComputeHash(pipeline.Spec) {
+		return
+	}
+
+	var fields []string
+	if fields, err = r.detectDrift(pipeline); err != nil {
+		log.Error(err, "unable to detect the drift of the Pipeline")
+		return
+	}
+
+	now := metav1.Now()
+	drift := &v1alpha3.PipelineDrift{
+		Drifted:       len(fields) > 0,
+		Fields:        fields,
+		LastCheckTime: &now,
+	}
+	if pipeline.Status.Drift != nil {
+		drift.LastRepairTime = pipeline.Status.Drift.LastRepairTime
+	}
+
+	if drift.Drifted {
+		if policy == v1alpha3.PipelineDriftPolicyRepair {
+			if _, err = r.DevOpsClient.UpdateProjectPipeline(pipeline.Namespace, pipeline); err != nil {
+				r.recorder.Eventf(pipeline, v1.EventTypeWarning, FailedDriftRepair,
+					"Failed to repair the Jenkins job, err = %v", err)
+				return
+			}
+			r.recorder.Eventf(pipeline, v1.EventTypeNormal, DriftRepaired,
+				"The Jenkins job was repaired, the drifted fields: %s", strings.Join(fields, ", "))
+			drift.Drifted = false
+			drift.LastRepairTime = &now
+		} else if old := pipeline.Status.Drift; old == nil || !reflect.DeepEqual(old.Fields, fields) {
+			r.recorder.Eventf(pipeline, v1.EventTypeWarning, DriftDetected,
+				"The Jenkins job was modified out of the Pipeline, the drifted fields: %s", strings.Join(fields, ", "))
+		}
+	}
+
+	err = r.updateDrift(ctx, drift, req.NamespacedName)
+	return
+}
+
+func (r *DriftReconciler) getDriftPolicy(pipeline *v1alpha3.Pipeline) string {
+	switch policy := pipeline.Annotations[v1alpha3.PipelineDriftPolicyAnnoKey]; policy {
+	case v1alpha3.PipelineDriftPolicyReport, v1alpha3.PipelineDriftPolicyRepair, v1alpha3.PipelineDriftPolicyIgnore:
+		return policy
+	}
+	if r.DefaultPolicy == "" {
+		return v1alpha3.PipelineDriftPolicyReport
+	}
+	return r.DefaultPolicy
+}
+
+// detectDrift returns the paths of the fields which are different between the Jenkins job and the Pipeline
+func (r *DriftReconciler) detectDrift(pipeline *v1alpha3.Pipeline) (fields []string, err error) {
+	var expected *v1alpha3.PipelineSpec
+	if expected, err = jenkins.GetExpectedPipelineSpec(pipeline.Namespace, pipeline); err != nil {
+		return
+	}
+
+	var actual *v1alpha3.Pipeline
+	if actual, err = r.DevOpsClient.GetProjectPipelineConfig(pipeline.Namespace, pipeline.Name); err != nil {
+		return
+	}
+	return getDriftedFields(expected, &actual.Spec)
+}
+
+func (r *DriftReconciler) updateDrift(ctx context.Context, drift *v1alpha3.PipelineDrift, pipelineKey client.ObjectKey) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipeline := &v1alpha3.Pipeline{}
+		if err := r.Get(ctx, pipelineKey, pipeline); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		pipeline.Status.Drift = drift
+		return r.Status().Update(ctx, pipeline)
+	})
+}
+
+// getDriftedFields compares two Pipeline specs in JSON format, returns the paths of the different fields
+func getDriftedFields(expected, actual *v1alpha3.PipelineSpec) (fields []string, err error) {
+	var expectedMap, actualMap map[string]interface{}
+	if expectedMap, err = toMap(expected); err != nil {
+		return
+	}
+	if actualMap, err = toMap(actual); err != nil {
+		return
+	}
+	fields = diffMap("", expectedMap, actualMap)
+	sort.Strings(fields)
+	return
+}
+
+func toMap(spec *v1alpha3.PipelineSpec) (result map[string]interface{}, err error) {
+	var data []byte
+	if data, err = json.Marshal(spec); err == nil {
+		err = json.Unmarshal(data, &result)
+	}
+	return
+}
+
+func diffMap(prefix string, expected, actual map[string]interface{}) (fields []string) {
+	keys := map[string]bool{}
+	for key := range expected {
+		keys[key] = true
+	}
+	for key := range actual {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		expectedChild, expectedIsMap := expected[key].(map[string]interface{})
+		actualChild, actualIsMap := actual[key].(map[string]interface{})
+		if expectedIsMap && actualIsMap {
+			fields = append(fields, diffMap(path, expectedChild, actualChild)...)
+		} else if !reflect.DeepEqual(expected[key], actual[key]) {
+			fields = append(fields, path)
+		}
+	}
+	return
+}
+
+// SetupWithManager setups reconciler with controller manager.
+func (r *DriftReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("pipeline-drift-controller")
+	r.log = ctrl.Log.WithName("pipeline-drift-controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pipeline-drift").
+		WithEventFilter(pipelineMetadataPredicate).
+		For(&v1alpha3.Pipeline{}).
+		Complete(r)
+}
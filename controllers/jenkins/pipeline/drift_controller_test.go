@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/constants"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestDriftReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	defaultReq := controllerruntime.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "ns",
+			Name:      "name",
+		},
+	}
+	newPipeline := func(jenkinsfile string, annotations map[string]string) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "name",
+				Annotations: annotations,
+			},
+			Spec: v1alpha3.PipelineSpec{
+				Type: v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{
+					Name:        "name",
+					Jenkinsfile: jenkinsfile,
+				},
+			},
+		}
+	}
+	synced := map[string]string{
+		v1alpha3.PipelineSyncStatusAnnoKey: constants.StatusSuccessful,
+	}
+
+	tests := []struct {
+		name        string
+		pipeline    *v1alpha3.Pipeline
+		jenkinsJob  *v1alpha3.Pipeline
+		policy      string
+		wantErr     bool
+		wantRequeue time.Duration
+		verify      func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops)
+	}{{
+		name:     "not found",
+		pipeline: &v1alpha3.Pipeline{},
+	}, {
+		name: "ignored by annotation",
+		pipeline: newPipeline("a", map[string]string{
+			v1alpha3.PipelineSyncStatusAnnoKey:  constants.StatusSuccessful,
+			v1alpha3.PipelineDriftPolicyAnnoKey: v1alpha3.PipelineDriftPolicyIgnore,
+		}),
+		jenkinsJob: newPipeline("b", nil),
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.Nil(t, pipeline.Status.Drift)
+		},
+	}, {
+		name:        "not synchronized yet",
+		pipeline:    newPipeline("a", nil),
+		jenkinsJob:  newPipeline("b", nil),
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.Nil(t, pipeline.Status.Drift)
+		},
+	}, {
+		name: "the latest spec is not synchronized yet",
+		pipeline: newPipeline("a", map[string]string{
+			v1alpha3.PipelineSyncStatusAnnoKey: constants.StatusSuccessful,
+			v1alpha3.PipelineSpecHash:          "fake",
+		}),
+		jenkinsJob:  newPipeline("b", nil),
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.Nil(t, pipeline.Status.Drift)
+		},
+	}, {
+		name:        "no Jenkins job found",
+		pipeline:    newPipeline("a", synced),
+		wantErr:     true,
+		wantRequeue: time.Minute,
+	}, {
+		name:        "no drift",
+		pipeline:    newPipeline("a", synced),
+		jenkinsJob:  newPipeline("a", nil),
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.False(t, pipeline.Status.Drift.Drifted)
+			assert.NotNil(t, pipeline.Status.Drift.LastCheckTime)
+		},
+	}, {
+		name:        "report the drift",
+		pipeline:    newPipeline("a", synced),
+		jenkinsJob:  newPipeline("b", nil),
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.True(t, pipeline.Status.Drift.Drifted)
+			assert.Equal(t, []string{"pipeline.jenkinsfile"}, pipeline.Status.Drift.Fields)
+			assert.Nil(t, pipeline.Status.Drift.LastRepairTime)
+			assert.Equal(t, "b", devops.Pipelines["ns"]["name"].Spec.Pipeline.Jenkinsfile)
+		},
+	}, {
+		name:        "repair the drift",
+		pipeline:    newPipeline("a", synced),
+		jenkinsJob:  newPipeline("b", nil),
+		policy:      v1alpha3.PipelineDriftPolicyRepair,
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.False(t, pipeline.Status.Drift.Drifted)
+			assert.NotNil(t, pipeline.Status.Drift.LastRepairTime)
+			assert.Equal(t, "a", devops.Pipelines["ns"]["name"].Spec.Pipeline.Jenkinsfile)
+		},
+	}, {
+		name: "repair the drift by annotation",
+		pipeline: newPipeline("a", map[string]string{
+			v1alpha3.PipelineSyncStatusAnnoKey:  constants.StatusSuccessful,
+			v1alpha3.PipelineDriftPolicyAnnoKey: v1alpha3.PipelineDriftPolicyRepair,
+		}),
+		jenkinsJob:  newPipeline("b", nil),
+		wantRequeue: time.Minute,
+		verify: func(t *testing.T, pipeline *v1alpha3.Pipeline, devops *fakedevops.Devops) {
+			assert.False(t, pipeline.Status.Drift.Drifted)
+			assert.Equal(t, "a", devops.Pipelines["ns"]["name"].Spec.Pipeline.Jenkinsfile)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devops := fakedevops.New("ns")
+			devops.Pipelines["ns"] = map[string]*v1alpha3.Pipeline{}
+			if tt.jenkinsJob != nil {
+				devops.Pipelines["ns"]["name"] = tt.jenkinsJob
+			}
+
+			r := &DriftReconciler{
+				Client:        fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipeline.DeepCopy()).Build(),
+				DevOpsClient:  devops,
+				Interval:      time.Minute,
+				DefaultPolicy: tt.policy,
+				log:           logr.New(log.NullLogSink{}),
+				recorder:      &record.FakeRecorder{},
+			}
+			result, err := r.Reconcile(context.TODO(), defaultReq)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter)
+
+			if tt.verify != nil {
+				pipeline := &v1alpha3.Pipeline{}
+				assert.Nil(t, r.Get(context.TODO(), defaultReq.NamespacedName, pipeline))
+				tt.verify(t, pipeline, devops)
+			}
+		})
+	}
+}
+
+func TestGetDriftedFields(t *testing.T) {
+	expected := &v1alpha3.PipelineSpec{
+		Type: v1alpha3.MultiBranchPipelineType,
+		MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+			Name:       "name",
+			ScriptPath: "Jenkinsfile",
+			GitSource:  &v1alpha3.GitSource{Url: "https://a.com"},
+		},
+	}
+	actual := expected.DeepCopy()
+	fields, err := getDriftedFields(expected, actual)
+	assert.Nil(t, err)
+	assert.Empty(t, fields)
+
+	actual.MultiBranchPipeline.ScriptPath = "build/Jenkinsfile"
+	actual.MultiBranchPipeline.GitSource = nil
+	actual.MultiBranchPipeline.Description = "changed"
+	fields, err = getDriftedFields(expected, actual)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"multi_branch_pipeline.description",
+		"multi_branch_pipeline.git_source",
+		"multi_branch_pipeline.script_path",
+	}, fields)
+}
+
+func TestDriftReconciler_getDriftPolicy(t *testing.T) {
+	r := &DriftReconciler{}
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyReport, r.getDriftPolicy(&v1alpha3.Pipeline{}))
+
+	r.DefaultPolicy = v1alpha3.PipelineDriftPolicyRepair
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyRepair, r.getDriftPolicy(&v1alpha3.Pipeline{}))
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyRepair, r.getDriftPolicy(&v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1alpha3.PipelineDriftPolicyAnnoKey: "fake"}},
+	}))
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyIgnore, r.getDriftPolicy(&v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1alpha3.PipelineDriftPolicyAnnoKey: "ignore"}},
+	}))
+
+	r.DefaultPolicy = v1alpha3.PipelineDriftPolicyIgnore
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyIgnore, r.getDriftPolicy(&v1alpha3.Pipeline{}))
+	assert.Equal(t, v1alpha3.PipelineDriftPolicyReport, r.getDriftPolicy(&v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1alpha3.PipelineDriftPolicyAnnoKey: "report"}},
+	}))
+}
@@ -219,6 +219,8 @@ func (r *JenkinsfileReconciler) getOrCreateJenkinsCore(annotations map[string]st
 		URL:      r.JenkinsCore.URL,
 		UserName: creator,
 		Token:    accessToken,
+
+		RoundTripper: r.JenkinsCore.RoundTripper,
 	}
 	return jenkinsCore, nil
 }
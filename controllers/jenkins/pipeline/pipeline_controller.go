@@ -19,14 +19,12 @@ package pipeline
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"reflect"
 	"time"
 
 	"kubesphere.io/devops/pkg/utils"
 	"kubesphere.io/devops/pkg/utils/sliceutil"
 
-	"github.com/emicklei/go-restful"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,10 +41,12 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"kubesphere.io/devops/controllers/core"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	kubesphereclient "kubesphere.io/devops/pkg/client/clientset/versioned"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	devopsinformers "kubesphere.io/devops/pkg/client/informers/externalversions/devops/v1alpha3"
 	devopslisters "kubesphere.io/devops/pkg/client/listers/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/constants"
@@ -89,7 +89,7 @@ func NewController(client clientset.Interface,
 		client:              client,
 		devopsClient:        devopsClient,
 		kubesphereClient:    kubesphereClient,
-		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pipeline"),
+		workqueue:           workqueue.NewNamedRateLimitingQueue(core.NewTrackedRateLimiter("pipeline", devopsv1alpha3.GroupVersion.WithKind(devopsv1alpha3.ResourceKindPipeline)), "pipeline"),
 		devOpsProjectLister: devopsInformer.Lister(),
 		pipelineSynced:      devopsInformer.Informer().HasSynced,
 		namespaceLister:     namespaceInformer.Lister(),
@@ -112,7 +112,7 @@ func NewController(client clientset.Interface,
 			_, requestSync := newPipeline.Annotations[devopsv1alpha3.PipelineRequestToSyncRunsAnnoKey]
 
 			if !reflect.DeepEqual(oldPipeline.Spec, newPipeline.Spec) ||
-				(!reflect.DeepEqual(oldPipeline.ObjectMeta, newPipeline.ObjectMeta) && !requestSync) {
+				(isMetadataChanged(&oldPipeline.ObjectMeta, &newPipeline.ObjectMeta) && !requestSync) {
 				v.enqueuePipeline(newObj)
 			}
 		},
@@ -121,6 +121,18 @@ func NewController(client clientset.Interface,
 	return v
 }
 
+// isMetadataChanged returns true if the metadata was changed. The fields which are changed by every update are ignored,
+// because the status of Pipeline is not a subresource, updating the status shouldn't sync the Jenkins job.
+func isMetadataChanged(oldMeta, newMeta *metav1.ObjectMeta) bool {
+	oldMeta, newMeta = oldMeta.DeepCopy(), newMeta.DeepCopy()
+	for _, meta := range []*metav1.ObjectMeta{oldMeta, newMeta} {
+		meta.ResourceVersion = ""
+		meta.Generation = 0
+		meta.ManagedFields = nil
+	}
+	return !reflect.DeepEqual(oldMeta, newMeta)
+}
+
 // enqueuePipeline takes a Foo resource and converts it into a namespace/name
 // string which is then put onto the work workqueue. This method should *not* be
 // passed resources of any type other than DevOpsProject.
@@ -260,40 +272,48 @@ func (c *Controller) syncHandler(key string) error {
 
 		// Check pipeline config exists, otherwise we will create it.
 		// if pipeline exists, check & update config
+		var syncErr error
+		// the config is rendered again only to compare its hash with the synced one, the error is reported by syncing
+		configHash, hashErr := jenkins.GetPipelineConfigHash(nsName, copyPipeline)
 		jenkinsPipeline, err := c.devopsClient.GetProjectPipelineConfig(nsName, pipeline.Name)
 		if err == nil {
-			if !reflect.DeepEqual(jenkinsPipeline.Spec, copyPipeline.Spec) {
-				_, err := c.devopsClient.UpdateProjectPipeline(nsName, copyPipeline)
-				if err != nil {
-					klog.V(8).Info(err, fmt.Sprintf("failed to update pipeline config %s ", key))
-					return err
+			if hashErr == nil && configHash == copyPipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey] {
+				klog.V(8).Info(fmt.Sprintf("the Jenkins job config of pipeline %s has no changes", key))
+			} else if !reflect.DeepEqual(jenkinsPipeline.Spec, copyPipeline.Spec) {
+				if _, syncErr = c.devopsClient.UpdateProjectPipeline(nsName, copyPipeline); syncErr != nil {
+					klog.V(8).Info(syncErr, fmt.Sprintf("failed to update pipeline config %s ", key))
 				}
 			} else {
 				klog.V(8).Info(fmt.Sprintf("nothing was changed, pipeline '%v'", copyPipeline.Spec))
 			}
+		} else if _, syncErr = c.devopsClient.CreateProjectPipeline(nsName, copyPipeline); syncErr != nil {
+			klog.V(8).Info(syncErr, fmt.Sprintf("failed to create copyPipeline %s ", key))
+		}
+
+		if syncErr != nil {
+			// retrying does not help until the Pipeline or Jenkins is changed, record the reason instead
+			if devopsClient.IsRetryable(syncErr) {
+				return syncErr
+			}
+			setSyncFailed(copyPipeline, syncErr)
 		} else {
-			_, err = c.devopsClient.CreateProjectPipeline(nsName, copyPipeline)
-			if err != nil {
-				klog.V(8).Info(err, fmt.Sprintf("failed to create copyPipeline %s ", key))
-				return err
+			// the Jenkins job is disabled if the Pipeline is suspended
+			setSuspendTime(&copyPipeline.Status, copyPipeline.IsSuspended(), time.Now())
+
+			//If there is no early return, then the sync is successful.
+			copyPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] = constants.StatusSuccessful
+			delete(copyPipeline.Annotations, devopsv1alpha3.PipelineSyncMsgAnnoKey)
+			if hashErr == nil {
+				copyPipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey] = configHash
 			}
 		}
-
-		//If there is no early return, then the sync is successful.
-		copyPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] = constants.StatusSuccessful
 	} else {
 		// Finalizers processing logic
 		if sliceutil.HasString(copyPipeline.ObjectMeta.Finalizers, devopsv1alpha3.PipelineFinalizerName) {
 			delSuccess := false
 			if _, err := c.devopsClient.DeleteProjectPipeline(nsName, pipeline.Name); err != nil {
-				// the status code should be 404 if the job does not exist
-				if srvErr, ok := err.(restful.ServiceError); ok {
-					delSuccess = srvErr.Code == http.StatusNotFound
-				} else if srvErr, ok := err.(*devopsClient.ErrorResponse); ok {
-					delSuccess = srvErr.Response.StatusCode == http.StatusNotFound
-				} else {
-					klog.Error(fmt.Sprintf("unexpected error type: %v, should be *restful.ServiceError", err))
-				}
+				// the job does not exist
+				delSuccess = devopsClient.IsNotFound(err)
 
 				klog.V(8).Info(err, fmt.Sprintf("failed to delete pipeline %s in devops", key))
 			} else {
@@ -331,18 +351,54 @@ func (c *Controller) updatePipeline(ctx context.Context, name string, nsName str
 			return
 		}
 
-		if newPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] == pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] &&
-			newPipeline.Annotations[devopsv1alpha3.PipelineSpecHash] == pipeline.Annotations[devopsv1alpha3.PipelineSpecHash] &&
-			reflect.DeepEqual(newPipeline.ObjectMeta.Finalizers, pipeline.ObjectMeta.Finalizers) {
-			return nil
+		if newPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] != pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] ||
+			newPipeline.Annotations[devopsv1alpha3.PipelineSpecHash] != pipeline.Annotations[devopsv1alpha3.PipelineSpecHash] ||
+			newPipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey] != pipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey] ||
+			!reflect.DeepEqual(newPipeline.ObjectMeta.Finalizers, pipeline.ObjectMeta.Finalizers) {
+			if newPipeline, err = c.updatePipelineMetadata(ctx, newPipeline, pipeline); err != nil {
+				return
+			}
 		}
-		if pipeline.Annotations != nil {
-			// update annotations
-			newPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] = pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey]
-			newPipeline.Annotations[devopsv1alpha3.PipelineSpecHash] = pipeline.Annotations[devopsv1alpha3.PipelineSpecHash]
+
+		// the status is a subresource, it's not changed by updating the Pipeline
+		if !reflect.DeepEqual(newPipeline.Status.SuspendTime, pipeline.Status.SuspendTime) {
+			newPipeline.Status.SuspendTime = pipeline.Status.SuspendTime
+			_, err = c.kubesphereClient.DevopsV1alpha3().Pipelines(newPipeline.Namespace).UpdateStatus(ctx, newPipeline, metav1.UpdateOptions{})
 		}
-		newPipeline.ObjectMeta.Finalizers = pipeline.ObjectMeta.Finalizers
-		_, err = c.kubesphereClient.DevopsV1alpha3().Pipelines(nsName).Update(ctx, newPipeline, metav1.UpdateOptions{})
-		return err
+		return
 	})
 }
+
+// updatePipelineMetadata updates the sync annotations and the finalizers of the latest Pipeline
+func (c *Controller) updatePipelineMetadata(ctx context.Context, newPipeline, pipeline *devopsv1alpha3.Pipeline) (*devopsv1alpha3.Pipeline, error) {
+	if pipeline.Annotations != nil {
+		// update annotations
+		newPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] = pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey]
+		newPipeline.Annotations[devopsv1alpha3.PipelineSpecHash] = pipeline.Annotations[devopsv1alpha3.PipelineSpecHash]
+		if configHash, ok := pipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey]; ok {
+			newPipeline.Annotations[devopsv1alpha3.PipelineJenkinsConfigHashAnnoKey] = configHash
+		}
+		if msg, ok := pipeline.Annotations[devopsv1alpha3.PipelineSyncMsgAnnoKey]; ok {
+			newPipeline.Annotations[devopsv1alpha3.PipelineSyncMsgAnnoKey] = msg
+		} else {
+			delete(newPipeline.Annotations, devopsv1alpha3.PipelineSyncMsgAnnoKey)
+		}
+	}
+	newPipeline.ObjectMeta.Finalizers = pipeline.ObjectMeta.Finalizers
+	return c.kubesphereClient.DevopsV1alpha3().Pipelines(newPipeline.Namespace).Update(ctx, newPipeline, metav1.UpdateOptions{})
+}
+
+// setSyncFailed records the reason why the Pipeline failed to sync into Jenkins
+func setSyncFailed(pipeline *devopsv1alpha3.Pipeline, err error) {
+	pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] = constants.StatusFailed
+	pipeline.Annotations[devopsv1alpha3.PipelineSyncMsgAnnoKey] = fmt.Sprintf("%s: %v", devopsClient.ReasonForError(err), err)
+}
+
+// setSuspendTime records since when the Pipeline has been suspended, or clears it once the Pipeline is resumed
+func setSuspendTime(status *devopsv1alpha3.PipelineStatus, suspended bool, now time.Time) {
+	if !suspended {
+		status.SuspendTime = nil
+	} else if status.SuspendTime == nil {
+		status.SuspendTime = &metav1.Time{Time: now}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package pipeline
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -24,8 +25,10 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/golang/mock/gomock"
+	devopsclient "kubesphere.io/devops/pkg/client/devops"
 	fakeDevOps "kubesphere.io/devops/pkg/client/devops/fake"
 	"kubesphere.io/devops/pkg/client/devops/jclient"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
 
 	"kubesphere.io/devops/pkg/constants"
 
@@ -370,8 +373,13 @@ func TestCreatePipeline(t *testing.T) {
 
 	expectPipeline := pipeline.DeepCopy()
 	expectPipeline.Finalizers = []string{devops.PipelineFinalizerName}
+	configHash, err := jenkins.GetPipelineConfigHash(nsName, pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
 	expectPipeline.Annotations = map[string]string{
-		devops.PipelineSyncStatusAnnoKey: constants.StatusSuccessful,
+		devops.PipelineSyncStatusAnnoKey:        constants.StatusSuccessful,
+		devops.PipelineJenkinsConfigHashAnnoKey: configHash,
 	}
 	f.expectPipeline = []*devops.Pipeline{expectPipeline}
 
@@ -438,3 +446,85 @@ func TestUpdatePipelineConfig(t *testing.T) {
 	f.expectPipeline = []*devops.Pipeline{expectPipeline}
 	f.run(getKey(modifiedPipeline, t))
 }
+
+func TestSkipUnchangedPipelineConfig(t *testing.T) {
+	f := newFixture(t)
+	nsName := "test-123"
+	pipelineName := "test"
+	projectName := "test_project"
+	spec := devops.PipelineSpec{
+		Type: devops.NoScmPipelineType,
+		Pipeline: &devops.NoScmPipeline{
+			Name:        pipelineName,
+			Jenkinsfile: "node {}",
+		},
+	}
+
+	ns := newNamespace(nsName, projectName)
+	// the spec hash was changed, but the rendered config is the same as the synced one
+	jenkinsPipeline := newPipeline(nsName, pipelineName, devops.PipelineSpec{Type: devops.NoScmPipelineType}, true, true)
+	pipeline := newPipeline(nsName, pipelineName, spec, true, true)
+	configHash, err := jenkins.GetPipelineConfigHash(nsName, pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Annotations[devops.PipelineSpecHash] = "outdated"
+	pipeline.Annotations[devops.PipelineJenkinsConfigHashAnnoKey] = configHash
+
+	f.pipelineLister = append(f.pipelineLister, pipeline)
+	f.namespaceLister = append(f.namespaceLister, ns)
+	f.objects = append(f.objects, pipeline)
+	f.initDevOpsProject = nsName
+	f.initPipeline = []*devops.Pipeline{jenkinsPipeline}
+	// the Jenkins job is not updated
+	f.expectPipeline = []*devops.Pipeline{jenkinsPipeline}
+	f.run(getKey(pipeline, t))
+}
+
+func Test_setSuspendTime(t *testing.T) {
+	now := time.Now()
+	status := &devops.PipelineStatus{}
+
+	setSuspendTime(status, true, now)
+	if status.SuspendTime == nil || !status.SuspendTime.Time.Equal(now) {
+		t.Fatalf("expect suspend time %v, got %v", now, status.SuspendTime)
+	}
+	// keep the time since when the Pipeline was suspended
+	setSuspendTime(status, true, now.Add(time.Hour))
+	if !status.SuspendTime.Time.Equal(now) {
+		t.Fatalf("expect suspend time %v, got %v", now, status.SuspendTime)
+	}
+	setSuspendTime(status, false, now)
+	if status.SuspendTime != nil {
+		t.Fatalf("expect no suspend time, got %v", status.SuspendTime)
+	}
+}
+
+func Test_setSyncFailed(t *testing.T) {
+	pipeline := newPipeline("ns", "pipeline", devops.PipelineSpec{}, true, true)
+
+	setSyncFailed(pipeline, devopsclient.NewError(http.StatusConflict, "the job type is different"))
+	if status := pipeline.Annotations[devops.PipelineSyncStatusAnnoKey]; status != constants.StatusFailed {
+		t.Fatalf("expect sync status %s, got %s", constants.StatusFailed, status)
+	}
+	if msg := pipeline.Annotations[devops.PipelineSyncMsgAnnoKey]; msg != "Conflict: the job type is different" {
+		t.Fatalf("unexpected sync message: %s", msg)
+	}
+}
+
+func Test_isMetadataChanged(t *testing.T) {
+	oldMeta := &metav1.ObjectMeta{Name: "pipeline", ResourceVersion: "1", Generation: 1}
+
+	// only the status was updated
+	newMeta := oldMeta.DeepCopy()
+	newMeta.ResourceVersion = "2"
+	newMeta.Generation = 2
+	if isMetadataChanged(oldMeta, newMeta) {
+		t.Fatal("expect the metadata is not changed")
+	}
+
+	newMeta.Annotations = map[string]string{"key": "value"}
+	if !isMetadataChanged(oldMeta, newMeta) {
+		t.Fatal("expect the metadata is changed")
+	}
+}
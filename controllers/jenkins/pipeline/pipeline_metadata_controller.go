@@ -26,6 +26,7 @@ import (
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"github.com/jenkins-zh/jenkins-client/pkg/job"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
@@ -48,6 +49,8 @@ type Reconciler struct {
 	JenkinsCore core.JenkinsCore
 	recorder    record.EventRecorder
 	log         logr.Logger
+	// branches caches the branches of multi-branch Pipelines across reconciles
+	branches *branchesCache
 }
 
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch;create;update;patch;delete
@@ -117,15 +120,19 @@ func (r *Reconciler) obtainAndUpdatePipelineBranches(pipeline *v1alpha3.Pipeline
 		// skip non multi-branch Pipeline
 		return nil
 	}
-	boClient := job.BlueOceanClient{
+	// reuse the branches if the metadata of the Pipeline did not change
+	key := types.NamespacedName{Namespace: pipeline.Namespace, Name: pipeline.Name}
+	fingerprint := pipeline.Annotations[v1alpha3.PipelineJenkinsMetadataAnnoKey]
+	if branchesJSON, ok := r.branches.get(key, fingerprint); ok {
+		pipeline.Annotations[v1alpha3.PipelineJenkinsBranchesAnnoKey] = branchesJSON
+		return nil
+	}
+
+	boClient := &job.BlueOceanClient{
 		JenkinsCore:  r.JenkinsCore,
 		Organization: "jenkins",
 	}
-	jobBranches, err := boClient.GetBranches(job.GetBranchesOption{
-		Folders:      []string{pipeline.Namespace},
-		PipelineName: pipeline.Name,
-		Limit:        100000,
-	})
+	jobBranches, err := getAllBranches(boClient, pipeline.Namespace, pipeline.Name, branchesPageSize, branchesWorkers)
 	if err != nil {
 		return err
 	}
@@ -137,6 +144,7 @@ func (r *Reconciler) obtainAndUpdatePipelineBranches(pipeline *v1alpha3.Pipeline
 
 	// update annotation
 	pipeline.Annotations[v1alpha3.PipelineJenkinsBranchesAnnoKey] = string(branchesJSON)
+	r.branches.set(key, fingerprint, string(branchesJSON))
 	return nil
 }
 
@@ -189,6 +197,7 @@ var pipelineMetadataPredicate = predicate.Funcs{
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("pipeline-metadata-controller")
 	r.log = ctrl.Log.WithName("pipeline-metadata-controller")
+	r.branches = newBranchesCache(branchesCacheTTL)
 	return ctrl.NewControllerManagedBy(mgr).
 		WithEventFilter(pipelineMetadataPredicate).
 		For(&v1alpha3.Pipeline{}).
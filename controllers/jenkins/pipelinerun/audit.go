@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// AuditPolicyReport only reports the inconsistencies between the PipelineRuns and the Jenkins builds
+	AuditPolicyReport = "report"
+	// AuditPolicyRepair adopts the orphaned Jenkins builds, and completes the PipelineRuns whose builds are missing
+	AuditPolicyRepair = "repair"
+)
+
+// Valid values for event reasons of the audit
+const (
+	OrphanedBuildDetected = "OrphanedBuildDetected"
+	OrphanedBuildAdopted  = "OrphanedBuildAdopted"
+	MissingBuildDetected  = "MissingBuildDetected"
+	MissingBuildRepaired  = "MissingBuildRepaired"
+)
+
+// missingBuildReason is the reason of the condition of a PipelineRun whose Jenkins build is missing
+const missingBuildReason = "BuildNotFound"
+
+var auditInconsistencies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ks_devops_pipelinerun_audit_inconsistencies",
+	Help: "The number of inconsistencies between the PipelineRuns and the Jenkins builds found by the last audit",
+}, []string{"type"})
+
+func init() {
+	metrics.Registry.MustRegister(auditInconsistencies)
+}
+
+// AuditReport contains the inconsistencies between the PipelineRuns and the Jenkins builds
+type AuditReport struct {
+	// OrphanedBuilds are the unfinished Jenkins builds without any PipelineRun, such as namespace/pipeline#1
+	OrphanedBuilds []string
+	// MissingBuilds are the running PipelineRuns whose Jenkins builds don't exist, such as namespace/name
+	MissingBuilds []string
+}
+
+// Auditor checks the PipelineRuns against the Jenkins builds once the controller starts, because a restart might
+// interrupt a reconcile between talking to Jenkins and recording the result. It finds the unfinished Jenkins builds
+// which are not owned by any PipelineRun, and the running PipelineRuns whose Jenkins builds don't exist.
+type Auditor struct {
+	client.Client
+	JenkinsCore core.JenkinsCore
+	// Policy is report or repair, it's report if it's empty
+	Policy string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// Start audits all the Jenkins Pipelines once
+func (a *Auditor) Start(ctx context.Context) error {
+	report := a.audit(ctx)
+	auditInconsistencies.WithLabelValues("orphaned_build").Set(float64(len(report.OrphanedBuilds)))
+	auditInconsistencies.WithLabelValues("missing_build").Set(float64(len(report.MissingBuilds)))
+	a.log.Info("finished auditing PipelineRuns", "orphanedBuilds", report.OrphanedBuilds,
+		"missingBuilds", report.MissingBuilds, "policy", a.getPolicy())
+	return nil
+}
+
+// NeedLeaderElection returns true, so only the leader repairs the inconsistencies
+func (a *Auditor) NeedLeaderElection() bool {
+	return true
+}
+
+func (a *Auditor) audit(ctx context.Context) (report *AuditReport) {
+	report = &AuditReport{}
+	// the builds queued after it might be submitted by the PipelineRuns which are not listed yet
+	startedAt := a.now().Add(-submissionClockSkew)
+
+	pipelineList := &v1alpha3.PipelineList{}
+	if err := a.List(ctx, pipelineList); err != nil {
+		a.log.Error(err, "unable to list Pipelines")
+		return
+	}
+	for i := range pipelineList.Items {
+		pipeline := &pipelineList.Items[i]
+		if pipeline.GetEngine() != v1alpha3.PipelineEngineJenkins || !pipeline.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := a.auditPipeline(ctx, pipeline, startedAt, report); err != nil {
+			a.log.Error(err, "unable to audit the PipelineRuns", "Pipeline", client.ObjectKeyFromObject(pipeline))
+		}
+	}
+	return
+}
+
+func (a *Auditor) auditPipeline(ctx context.Context, pipeline *v1alpha3.Pipeline, startedAt time.Time,
+	report *AuditReport) error {
+	// list the PipelineRuns before the builds, so a build is always listed after the PipelineRun recorded it
+	prList := &v1alpha3.PipelineRunList{}
+	if err := a.List(ctx, prList, client.InNamespace(pipeline.Namespace), client.MatchingLabels{
+		v1alpha3.PipelineNameLabelKey: pipeline.Name,
+	}); err != nil {
+		return err
+	}
+	handler := &jenkinsHandler{&a.JenkinsCore}
+	jobRuns, err := handler.getPipelineRuns(pipeline.Namespace, pipeline.Name)
+	if err != nil {
+		return err
+	}
+
+	orphans, missing := findInconsistencies(pipeline, jobRuns, prList.Items, startedAt)
+	for i := range orphans {
+		jobRun := &orphans[i]
+		report.OrphanedBuilds = append(report.OrphanedBuilds, getBuildName(pipeline, jobRun))
+		a.repairOrphanedBuild(ctx, pipeline, jobRun)
+	}
+	for i := range missing {
+		pr := &missing[i]
+		report.MissingBuilds = append(report.MissingBuilds, client.ObjectKeyFromObject(pr).String())
+		a.repairMissingBuild(ctx, pr)
+	}
+	return nil
+}
+
+// findInconsistencies returns the unfinished Jenkins builds which are not owned by any PipelineRun, and the running
+// PipelineRuns whose Jenkins builds don't exist. The builds which are being submitted are left to the reconciler.
+func findInconsistencies(pipeline *v1alpha3.Pipeline, jobRuns []job.PipelineRun, pipelineRuns []v1alpha3.PipelineRun,
+	startedAt time.Time) (orphans []job.PipelineRun, missing []v1alpha3.PipelineRun) {
+	isMultiBranch := pipeline.IsMultiBranch()
+	finder := newPipelineRunFinder(pipelineRuns)
+	owned := map[string]bool{}
+	for i := range jobRuns {
+		jobRun := &jobRuns[i]
+		if pr, ok := finder.find(jobRun, isMultiBranch); ok {
+			owned[pr.Name] = true
+		}
+	}
+
+	// the builds which are going to be adopted by the PipelineRuns in the submitting stage
+	submitting := map[*job.PipelineRun]bool{}
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		if pr.GetReconcileStage() != v1alpha3.ReconcileStageSubmitting || pr.HasStarted() {
+			continue
+		}
+		if jobRun := findSubmittedBuild(pr, jobRuns, pipelineRuns, isMultiBranch); jobRun != nil {
+			submitting[jobRun] = true
+		}
+	}
+
+	for i := range jobRuns {
+		jobRun := &jobRuns[i]
+		if jobRun.State == Finished.String() || submitting[jobRun] || !getQueuedTime(jobRun).Before(startedAt) {
+			continue
+		}
+		if _, ok := finder.find(jobRun, isMultiBranch); !ok {
+			orphans = append(orphans, *jobRun)
+		}
+	}
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		if pr.HasStarted() && !pr.HasCompleted() && pr.DeletionTimestamp.IsZero() && !owned[pr.Name] {
+			missing = append(missing, *pr)
+		}
+	}
+	return
+}
+
+// repairOrphanedBuild creates a PipelineRun for the orphaned build, then the reconciler keeps its status up to date
+func (a *Auditor) repairOrphanedBuild(ctx context.Context, pipeline *v1alpha3.Pipeline, jobRun *job.PipelineRun) {
+	name := getBuildName(pipeline, jobRun)
+	if a.getPolicy() != AuditPolicyRepair {
+		a.recorder.Eventf(pipeline, corev1.EventTypeWarning, OrphanedBuildDetected,
+			"The Jenkins build %s has no PipelineRun", name)
+		return
+	}
+	pr := createBarePipelineRun(pipeline, jobRun)
+	if err := a.Create(ctx, pr); err != nil {
+		a.log.Error(err, "unable to adopt the orphaned Jenkins build", "build", name)
+		a.recorder.Eventf(pipeline, corev1.EventTypeWarning, OrphanedBuildDetected,
+			"The Jenkins build %s has no PipelineRun, and failed to adopt it: %v", name, err)
+		return
+	}
+	a.recorder.Eventf(pipeline, corev1.EventTypeNormal, OrphanedBuildAdopted,
+		"Created PipelineRun %s for the orphaned Jenkins build %s", pr.Name, name)
+}
+
+// repairMissingBuild completes the PipelineRun whose build doesn't exist, otherwise it's polled forever
+func (a *Auditor) repairMissingBuild(ctx context.Context, pr *v1alpha3.PipelineRun) {
+	runID, _ := pr.GetPipelineRunID()
+	if a.getPolicy() != AuditPolicyRepair {
+		a.recorder.Eventf(pr, corev1.EventTypeWarning, MissingBuildDetected,
+			"The Jenkins build %s of the running PipelineRun doesn't exist", runID)
+		return
+	}
+	markBuildMissing(&pr.Status, a.now())
+	if err := a.Status().Update(ctx, pr); err != nil {
+		a.log.Error(err, "unable to complete the PipelineRun", "PipelineRun", client.ObjectKeyFromObject(pr))
+		a.recorder.Eventf(pr, corev1.EventTypeWarning, MissingBuildDetected,
+			"The Jenkins build %s of the running PipelineRun doesn't exist, and failed to complete it: %v", runID, err)
+		return
+	}
+	a.recorder.Eventf(pr, corev1.EventTypeNormal, MissingBuildRepaired,
+		"Completed the PipelineRun as Unknown because its Jenkins build %s doesn't exist", runID)
+}
+
+// markBuildMissing completes the status as unknown, because the result of the missing build is not able to know
+func markBuildMissing(status *v1alpha3.PipelineRunStatus, now time.Time) {
+	metaNow := v1.NewTime(now)
+	status.Phase = v1alpha3.Unknown
+	status.CompletionTime = &metaNow
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSucceeded,
+		Status:             v1alpha3.ConditionUnknown,
+		Reason:             missingBuildReason,
+		Message:            "the Jenkins build of the PipelineRun doesn't exist",
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+}
+
+func getBuildName(pipeline *v1alpha3.Pipeline, jobRun *job.PipelineRun) string {
+	if pipeline.IsMultiBranch() {
+		return fmt.Sprintf("%s/%s/%s#%s", pipeline.Namespace, pipeline.Name, jobRun.Pipeline, jobRun.ID)
+	}
+	return fmt.Sprintf("%s/%s#%s", pipeline.Namespace, pipeline.Name, jobRun.ID)
+}
+
+func (a *Auditor) getPolicy() string {
+	if a.Policy == "" {
+		return AuditPolicyReport
+	}
+	return a.Policy
+}
+
+// SetupWithManager adds the auditor into the manager, it runs once the caches are synced
+func (a *Auditor) SetupWithManager(mgr ctrl.Manager) error {
+	a.recorder = mgr.GetEventRecorderFor("pipelinerun-auditor")
+	a.log = ctrl.Log.WithName("pipelinerun-auditor")
+	if a.now == nil {
+		a.now = time.Now
+	}
+	return mgr.Add(a)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_findInconsistencies(t *testing.T) {
+	submittedAt := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	startedAt := submittedAt.Add(10 * time.Minute)
+	newRunningJob := func(id string, queuedAt time.Time) job.PipelineRun {
+		jobRun := newJobRun(id, "", queuedAt)
+		jobRun.State = Running.String()
+		return jobRun
+	}
+	newRun := func(name, runID string) v1alpha3.PipelineRun {
+		pr := v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if runID != "" {
+			pr.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = runID
+		}
+		return pr
+	}
+
+	finished := newJobRun("1", "", submittedAt.Add(-time.Hour))
+	finished.State = Finished.String()
+	jobRuns := []job.PipelineRun{
+		finished,
+		newRunningJob("2", submittedAt.Add(-20*time.Minute)),
+		newRunningJob("3", submittedAt.Add(-10*time.Minute)),
+		// queued after the audit started
+		newRunningJob("4", startedAt.Add(time.Second)),
+		// submitted by the PipelineRun in the submitting stage
+		newRunningJob("5", submittedAt.Add(10*time.Second)),
+	}
+
+	submitting := newRun("submitting", "")
+	submitting.Annotations[v1alpha3.PipelineRunReconcileStageAnnoKey] = string(v1alpha3.ReconcileStageSubmitting)
+	submitting.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey] = submittedAt.Format(time.RFC3339)
+	completed := newRun("completed", "8")
+	completed.Status.CompletionTime = &metav1.Time{Time: submittedAt}
+	pipelineRuns := []v1alpha3.PipelineRun{
+		newRun("running", "2"),
+		newRun("missing", "9"),
+		completed,
+		submitting,
+	}
+
+	orphans, missing := findInconsistencies(&v1alpha3.Pipeline{}, jobRuns, pipelineRuns, startedAt)
+	assert.Equal(t, 1, len(orphans))
+	assert.Equal(t, "3", orphans[0].ID)
+	assert.Equal(t, 1, len(missing))
+	assert.Equal(t, "missing", missing[0].Name)
+
+	orphans, missing = findInconsistencies(&v1alpha3.Pipeline{}, nil, nil, startedAt)
+	assert.Empty(t, orphans)
+	assert.Empty(t, missing)
+}
+
+func TestAuditor_audit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/pipelines/pipeline/runs/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"1","state":"RUNNING","enQueueTime":"2022-10-01T08:00:00.000+0000"},
+{"id":"2","state":"RUNNING","enQueueTime":"2022-10-01T08:00:00.000+0000"}]`))
+	}))
+	defer server.Close()
+
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"}}
+	pipeline.Spec.Type = v1alpha3.NoScmPipelineType
+	// the Pipeline which doesn't exist in Jenkins is skipped
+	another := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "another"}}
+	newRun := func(name, runID string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        name,
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: runID},
+		}}
+	}
+	now := time.Date(2022, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		policy string
+		verify func(t *testing.T, c client.Client, report *AuditReport)
+	}{{
+		name: "report",
+		verify: func(t *testing.T, c client.Client, report *AuditReport) {
+			assert.Equal(t, []string{"ns/pipeline#2"}, report.OrphanedBuilds)
+			assert.Equal(t, []string{"ns/missing"}, report.MissingBuilds)
+
+			prList := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, c.List(context.Background(), prList))
+			assert.Equal(t, 2, len(prList.Items))
+			for _, pr := range prList.Items {
+				assert.False(t, pr.HasCompleted())
+			}
+		},
+	}, {
+		name:   "repair",
+		policy: AuditPolicyRepair,
+		verify: func(t *testing.T, c client.Client, report *AuditReport) {
+			assert.Equal(t, []string{"ns/pipeline#2"}, report.OrphanedBuilds)
+			assert.Equal(t, []string{"ns/missing"}, report.MissingBuilds)
+
+			prList := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, c.List(context.Background(), prList))
+			assert.Equal(t, 3, len(prList.Items))
+			for _, pr := range prList.Items {
+				runID, _ := pr.GetPipelineRunID()
+				switch runID {
+				case "1":
+					assert.False(t, pr.HasCompleted())
+				case "2":
+					// adopted the orphaned build
+					assert.Equal(t, "pipeline", pr.Labels[v1alpha3.PipelineNameLabelKey])
+				case "9":
+					assert.Equal(t, v1alpha3.Unknown, pr.Status.Phase)
+					assert.True(t, pr.HasCompleted())
+					assert.Equal(t, missingBuildReason, getCondition(&pr.Status, v1alpha3.ConditionSucceeded).Reason)
+				default:
+					assert.Fail(t, "unexpected PipelineRun", runID)
+				}
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).
+				WithObjects(pipeline.DeepCopy(), another.DeepCopy(), newRun("running", "1"), newRun("missing", "9")).Build()
+			auditor := &Auditor{
+				Client:      c,
+				JenkinsCore: core.JenkinsCore{URL: server.URL},
+				Policy:      tt.policy,
+				log:         logr.Discard(),
+				recorder:    record.NewFakeRecorder(10),
+				now:         func() time.Time { return now },
+			}
+			tt.verify(t, c, auditor.audit(context.Background()))
+		})
+	}
+}
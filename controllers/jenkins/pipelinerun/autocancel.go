@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// cancelSupersededRuns stops the unfinished PipelineRuns of the same pull request which were created earlier than
+// the given one, since their commits are superseded. It only works if the Pipeline opts in.
+func (r *Reconciler) cancelSupersededRuns(ctx context.Context, pr *v1alpha3.PipelineRun, pipeline *v1alpha3.Pipeline) error {
+	if !pipeline.Spec.AutoCancelsPullRequests() || pr.Spec.SCM == nil || !pr.Spec.SCM.IsPullRequest() ||
+		pr.HasCompleted() || pr.IsStopRequested() {
+		return nil
+	}
+
+	runList, err := r.listPipelineRuns(ctx, pr.Namespace, pipeline.Name)
+	if err != nil {
+		return err
+	}
+
+	action := v1alpha3.Stop
+	for i := range runList.Items {
+		item := &runList.Items[i]
+		if item.Name == pr.Name || item.Spec.SCM == nil || item.Spec.SCM.RefName != pr.Spec.SCM.RefName ||
+			item.HasCompleted() || item.IsStopRequested() || !isLater(pr, item) {
+			continue
+		}
+		patch := client.MergeFrom(item.DeepCopy())
+		item.Spec.Action = &action
+		if err := r.Patch(ctx, item, patch); err != nil {
+			if err = client.IgnoreNotFound(err); err != nil {
+				return err
+			}
+			continue
+		}
+		r.recorder.Eventf(item, corev1.EventTypeNormal, v1alpha3.AutoCancelled,
+			"Stopping PipelineRun %s/%s because %s builds a newer commit of %s", item.Namespace, item.Name, pr.Name, pr.Spec.SCM.RefName)
+	}
+	return nil
+}
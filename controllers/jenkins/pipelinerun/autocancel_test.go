@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_cancelSupersededRuns(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newRun := func(name, ref string, age time.Duration, completed bool) *v1alpha3.PipelineRun {
+		run := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "ns",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "pipeline"},
+				SCM:         &v1alpha3.SCM{RefName: ref},
+			},
+		}
+		if completed {
+			run.Status.CompletionTime = &metav1.Time{Time: now}
+		}
+		return run
+	}
+	optIn := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{Triggers: &v1alpha3.PipelineTriggers{AutoCancelPullRequests: true}},
+	}
+	others := []client.Object{
+		newRun("older", "PR-1", time.Hour, false),
+		newRun("completed", "PR-1", time.Hour, true),
+		newRun("newer", "PR-1", -time.Hour, false),
+		newRun("another", "PR-2", time.Hour, false),
+		newRun("branch", "master", time.Hour, false),
+	}
+
+	tests := []struct {
+		name     string
+		pipeline *v1alpha3.Pipeline
+		run      *v1alpha3.PipelineRun
+		stopped  []string
+	}{{
+		name:     "not opt in",
+		pipeline: &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"}},
+		run:      newRun("run", "PR-1", 0, false),
+	}, {
+		name:     "not a pull request",
+		pipeline: optIn,
+		run:      newRun("run", "master", 0, false),
+	}, {
+		name:     "stop the older runs of the same pull request",
+		pipeline: optIn,
+		run:      newRun("run", "PR-1", 0, false),
+		stopped:  []string{"older"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []client.Object{tt.run}
+			for _, item := range others {
+				objects = append(objects, item.DeepCopyObject().(client.Object))
+			}
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(objects...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+			assert.Nil(t, r.cancelSupersededRuns(context.Background(), tt.run.DeepCopy(), tt.pipeline))
+
+			runList := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, c.List(context.Background(), runList))
+			var stopped []string
+			for i := range runList.Items {
+				if runList.Items[i].IsStopRequested() {
+					stopped = append(stopped, runList.Items[i].Name)
+				}
+			}
+			assert.Equal(t, tt.stopped, stopped)
+		})
+	}
+}
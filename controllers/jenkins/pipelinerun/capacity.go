@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/queue"
+	corev1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// loadCacheTTL is the period to reuse the load of Jenkins, it avoids querying Jenkins for each pending PipelineRun
+const loadCacheTTL = 5 * time.Second
+
+// jenkinsLoad is the load of Jenkins
+type jenkinsLoad struct {
+	QueueLength    int
+	BusyExecutors  int `json:"busyExecutors"`
+	TotalExecutors int `json:"totalExecutors"`
+}
+
+// String returns a readable message of the load
+func (l *jenkinsLoad) String() string {
+	return fmt.Sprintf("%d builds in the Jenkins queue, %d/%d executors are busy",
+		l.QueueLength, l.BusyExecutors, l.TotalExecutors)
+}
+
+// loadGetter returns the current load of Jenkins
+type loadGetter interface {
+	getLoad() (*jenkinsLoad, error)
+}
+
+// getLoad queries the queue and executors of Jenkins
+func (handler *jenkinsHandler) getLoad() (load *jenkinsLoad, err error) {
+	load = &jenkinsLoad{}
+	if err = handler.JenkinsCore.RequestWithData(http.MethodGet, "/computer/api/json?tree=busyExecutors,totalExecutors",
+		nil, nil, http.StatusOK, load); err != nil {
+		return
+	}
+	queueClient := &queue.Client{JenkinsCore: *handler.JenkinsCore}
+	var jobQueue *queue.JobQueue
+	if jobQueue, err = queueClient.Get(); err == nil && jobQueue != nil {
+		load.QueueLength = len(jobQueue.Items)
+	}
+	return
+}
+
+// capacityChecker checks if Jenkins is able to accept more builds
+type capacityChecker struct {
+	getter loadGetter
+	// maxQueueLength is the length of Jenkins queue which means Jenkins is saturated
+	maxQueueLength int
+
+	lock     sync.Mutex
+	load     *jenkinsLoad
+	loadTime time.Time
+}
+
+func newCapacityChecker(jenkinsCore *core.JenkinsCore, maxQueueLength int) *capacityChecker {
+	return &capacityChecker{getter: &jenkinsHandler{jenkinsCore}, maxQueueLength: maxQueueLength}
+}
+
+// saturated returns true with the load of Jenkins if no more builds should be submitted now
+func (c *capacityChecker) saturated(now time.Time) (saturated bool, load *jenkinsLoad, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.load == nil || now.Sub(c.loadTime) >= loadCacheTTL {
+		if load, err = c.getter.getLoad(); err != nil {
+			return
+		}
+		c.load, c.loadTime = load, now
+	}
+	load = c.load
+	saturated = load.QueueLength >= c.maxQueueLength
+	return
+}
+
+// submitted counts the new build into the cached load, the next PipelineRun sees it before the cache expires
+func (c *capacityChecker) submitted() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.load != nil {
+		c.load.QueueLength++
+	}
+}
+
+// holdIfSaturated keeps the pending PipelineRun out of Jenkins while Jenkins is saturated.
+// It returns wait as true if the PipelineRun should be held.
+func (r *Reconciler) holdIfSaturated(ctx context.Context, pr *v1alpha3.PipelineRun) (result ctrl.Result, wait bool, err error) {
+	now := time.Now()
+	var load *jenkinsLoad
+	if wait, load, err = r.capacity.saturated(now); err != nil || !wait {
+		return
+	}
+
+	if result.RequeueAfter = r.IdleSyncPeriod; result.RequeueAfter <= 0 {
+		result.RequeueAfter = defaultQueuePeriod
+	}
+	if scheduler.WaitForCapacity(&pr.Status, fmt.Sprintf("Jenkins is saturated: %s", load), now) {
+		if err = r.updateStatus(ctx, &pr.Status, client.ObjectKeyFromObject(pr)); err != nil {
+			return
+		}
+		r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Queued, "PipelineRun %s/%s is waiting for the capacity of Jenkins: %s",
+			pr.Namespace, pr.Name, load)
+	}
+	return
+}
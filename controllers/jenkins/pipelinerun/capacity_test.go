@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeLoadGetter struct {
+	load  jenkinsLoad
+	err   error
+	count int
+}
+
+func (g *fakeLoadGetter) getLoad() (*jenkinsLoad, error) {
+	g.count++
+	load := g.load
+	return &load, g.err
+}
+
+func Test_jenkinsHandler_getLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computer/api/json":
+			_, _ = w.Write([]byte(`{"busyExecutors":2,"totalExecutors":4}`))
+		case "/queue/api/json":
+			_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2},{"id":3}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	load, err := (&jenkinsHandler{&core.JenkinsCore{URL: server.URL}}).getLoad()
+	assert.Nil(t, err)
+	assert.Equal(t, &jenkinsLoad{QueueLength: 3, BusyExecutors: 2, TotalExecutors: 4}, load)
+	assert.Equal(t, "3 builds in the Jenkins queue, 2/4 executors are busy", load.String())
+}
+
+func Test_capacityChecker_saturated(t *testing.T) {
+	now := time.Now()
+	getter := &fakeLoadGetter{load: jenkinsLoad{QueueLength: 1}}
+	checker := &capacityChecker{getter: getter, maxQueueLength: 2}
+
+	saturated, _, err := checker.saturated(now)
+	assert.Nil(t, err)
+	assert.False(t, saturated)
+
+	// the new build is counted before querying Jenkins again
+	checker.submitted()
+	saturated, load, err := checker.saturated(now.Add(time.Second))
+	assert.Nil(t, err)
+	assert.True(t, saturated)
+	assert.Equal(t, 2, load.QueueLength)
+	assert.Equal(t, 1, getter.count)
+
+	// query Jenkins again after the cache expired
+	saturated, _, err = checker.saturated(now.Add(loadCacheTTL))
+	assert.Nil(t, err)
+	assert.False(t, saturated)
+	assert.Equal(t, 2, getter.count)
+
+	getter.err = errors.New("fake")
+	_, _, err = checker.saturated(now.Add(2 * loadCacheTTL))
+	assert.NotNil(t, err)
+}
+
+func TestReconciler_holdIfSaturated(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	run := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns"}}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(run).Build()
+	getter := &fakeLoadGetter{load: jenkinsLoad{QueueLength: 3, BusyExecutors: 4, TotalExecutors: 4}}
+	r := &Reconciler{
+		Client:   c,
+		capacity: &capacityChecker{getter: getter, maxQueueLength: 3},
+		log:      logr.Discard(),
+		recorder: &record.FakeRecorder{},
+	}
+
+	result, wait, err := r.holdIfSaturated(context.Background(), run.DeepCopy())
+	assert.Nil(t, err)
+	assert.True(t, wait)
+	assert.Equal(t, defaultQueuePeriod, result.RequeueAfter)
+	held := &v1alpha3.PipelineRun{}
+	assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(run), held))
+	assert.True(t, scheduler.IsQueued(held))
+	assert.Equal(t, v1alpha3.Pending, held.Status.Phase)
+
+	// Jenkins has free capacity
+	r.capacity = &capacityChecker{getter: getter, maxQueueLength: 4}
+	_, wait, err = r.holdIfSaturated(context.Background(), held)
+	assert.Nil(t, err)
+	assert.False(t, wait)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// supersededReason is the reason of the Stopped condition of a PipelineRun which was superseded by a later one
+const supersededReason = "Superseded"
+
+// debounce coalesces the PipelineRuns triggered by the successive webhook events of the same branch. The latest one
+// waits until the end of the debounce window of the Pipeline, and the earlier pending ones are cancelled.
+// It returns wait as true if the PipelineRun should not be triggered now.
+func (r *Reconciler) debounce(ctx context.Context, pr *v1alpha3.PipelineRun, pipeline *v1alpha3.Pipeline) (result ctrl.Result, wait bool, err error) {
+	window := pipeline.Spec.GetDebounceWindow()
+	if window <= 0 || !isDebounceable(pr) {
+		return
+	}
+
+	var runList *v1alpha3.PipelineRunList
+	if runList, err = r.listPipelineRuns(ctx, pr.Namespace, pipeline.Name); err != nil {
+		return
+	}
+
+	now := time.Now()
+	latest := pr
+	var superseded []*v1alpha3.PipelineRun
+	for i := range runList.Items {
+		item := &runList.Items[i]
+		if item.Name == pr.Name || !isDebounceable(item) || item.Spec.SCM.RefName != pr.Spec.SCM.RefName {
+			continue
+		}
+		if isLater(item, latest) {
+			superseded = append(superseded, latest)
+			latest = item
+		} else {
+			superseded = append(superseded, item)
+		}
+	}
+
+	for _, item := range superseded {
+		status := item.Status.DeepCopy()
+		supersedePipelineRunStatus(status, latest, now)
+		if err = r.updateStatus(ctx, status, client.ObjectKeyFromObject(item)); err != nil {
+			return
+		}
+		r.recorder.Eventf(item, corev1.EventTypeNormal, v1alpha3.Superseded, "PipelineRun %s/%s was superseded by %s",
+			item.Namespace, item.Name, latest.Name)
+	}
+	if latest != pr {
+		// the PipelineRun itself was superseded
+		wait = true
+		return
+	}
+
+	end := pr.CreationTimestamp.Add(window)
+	if !now.Before(end) {
+		return
+	}
+	wait = true
+	result.RequeueAfter = end.Sub(now)
+	if scheduler.WaitForQuietPeriod(&pr.Status, end, now) {
+		if err = r.updateStatus(ctx, &pr.Status, client.ObjectKeyFromObject(pr)); err != nil {
+			return
+		}
+		r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Queued, "PipelineRun %s/%s is waiting for the successive webhook events until %s",
+			pr.Namespace, pr.Name, end.UTC().Format(time.RFC3339))
+	}
+	return
+}
+
+// isDebounceable returns true if the PipelineRun was triggered by an SCM webhook, and it's not triggered yet
+func isDebounceable(pr *v1alpha3.PipelineRun) bool {
+	return pr.Annotations[v1alpha3.PipelineRunTriggerAnnoKey] == "webhook" && pr.Spec.SCM != nil &&
+		!pr.HasStarted() && !pr.HasCompleted() && !pr.IsStopRequested() && pr.DeletionTimestamp.IsZero()
+}
+
+// isLater returns true if the PipelineRun a was created later than b
+func isLater(a, b *v1alpha3.PipelineRun) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
+
+func supersedePipelineRunStatus(status *v1alpha3.PipelineRunStatus, by *v1alpha3.PipelineRun, now time.Time) {
+	metaNow := v1.NewTime(now)
+	message := fmt.Sprintf("the PipelineRun was superseded by %s before being triggered", by.Name)
+	status.Phase = v1alpha3.Cancelled
+	status.CompletionTime = &metaNow
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSucceeded,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             supersededReason,
+		Message:            message,
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionStopped,
+		Status:             v1alpha3.ConditionTrue,
+		Reason:             supersededReason,
+		Message:            message,
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+}
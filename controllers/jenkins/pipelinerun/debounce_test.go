@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_debounce(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newRun := func(name, branch, trigger string, age time.Duration) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "ns",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+				Annotations:       map[string]string{v1alpha3.PipelineRunTriggerAnnoKey: trigger},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "pipeline"},
+				SCM:         &v1alpha3.SCM{RefName: branch},
+			},
+		}
+	}
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"},
+		Spec: v1alpha3.PipelineSpec{Triggers: &v1alpha3.PipelineTriggers{
+			Debounce: &metav1.Duration{Duration: time.Minute},
+		}},
+	}
+	getRun := func(t *testing.T, c client.Client, name string) *v1alpha3.PipelineRun {
+		run := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: name}, run))
+		return run
+	}
+
+	tests := []struct {
+		name     string
+		pipeline *v1alpha3.Pipeline
+		run      *v1alpha3.PipelineRun
+		others   []client.Object
+		wait     bool
+		requeue  bool
+		verify   func(t *testing.T, c client.Client)
+	}{{
+		name:     "no debounce window",
+		pipeline: &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"}},
+		run:      newRun("a", "master", "webhook", 0),
+	}, {
+		name:     "not triggered by webhook",
+		pipeline: pipeline,
+		run:      newRun("a", "master", "chatops", 0),
+	}, {
+		name:     "wait in the debounce window",
+		pipeline: pipeline,
+		run:      newRun("a", "master", "webhook", 0),
+		wait:     true,
+		requeue:  true,
+		verify: func(t *testing.T, c client.Client) {
+			run := getRun(t, c, "a")
+			assert.Equal(t, v1alpha3.Pending, run.Status.Phase)
+			assert.Equal(t, "InQuietPeriod", getCondition(&run.Status, v1alpha3.ConditionQueued).Reason)
+		},
+	}, {
+		name:     "the debounce window is over",
+		pipeline: pipeline,
+		run:      newRun("a", "master", "webhook", 2*time.Minute),
+	}, {
+		name:     "cancel the superseded PipelineRuns",
+		pipeline: pipeline,
+		run:      newRun("c", "master", "webhook", 0),
+		others: []client.Object{
+			newRun("a", "master", "webhook", 2*time.Minute),
+			newRun("b", "master", "webhook", time.Minute/2),
+			newRun("d", "dev", "webhook", time.Minute/2),
+		},
+		wait:    true,
+		requeue: true,
+		verify: func(t *testing.T, c client.Client) {
+			for _, name := range []string{"a", "b"} {
+				run := getRun(t, c, name)
+				assert.Equal(t, v1alpha3.Cancelled, run.Status.Phase)
+				assert.Equal(t, supersededReason, getCondition(&run.Status, v1alpha3.ConditionStopped).Reason)
+			}
+			assert.Equal(t, v1alpha3.RunPhase(""), getRun(t, c, "d").Status.Phase)
+		},
+	}, {
+		name:     "superseded by a later one",
+		pipeline: pipeline,
+		run:      newRun("a", "master", "webhook", 2*time.Minute),
+		others:   []client.Object{newRun("b", "master", "webhook", 0)},
+		wait:     true,
+		verify: func(t *testing.T, c client.Client) {
+			assert.Equal(t, v1alpha3.Cancelled, getRun(t, c, "a").Status.Phase)
+			assert.Equal(t, v1alpha3.RunPhase(""), getRun(t, c, "b").Status.Phase)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(append(tt.others, tt.run)...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+			result, wait, err := r.debounce(context.Background(), tt.run.DeepCopy(), tt.pipeline)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wait, wait)
+			assert.Equal(t, tt.requeue, result.RequeueAfter > 0)
+			if tt.verify != nil {
+				tt.verify(t, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelineenvironment"
+)
+
+// injectEnvironments returns the spec of the PipelineRun with the variables of the PipelineEnvironments which select
+// it, the parameters of the PipelineRun are not changed. The names of the environments are recorded in the annotations,
+// changed is true if the annotations were changed.
+func (r *Reconciler) injectEnvironments(ctx context.Context, pr *v1alpha3.PipelineRun, pipeline *v1alpha3.Pipeline) (
+	spec *v1alpha3.PipelineRunSpec, changed bool, err error) {
+	spec = &pr.Spec
+	var environments []v1alpha3.PipelineEnvironment
+	if environments, err = pipelineenvironment.Find(ctx, r.Client, pipeline, pr); err != nil || len(environments) == 0 {
+		return
+	}
+	var variables []v1alpha3.Parameter
+	if variables, err = pipelineenvironment.Resolve(ctx, r.Client, pr.Namespace, environments); err != nil {
+		return
+	}
+
+	spec = pr.Spec.DeepCopy()
+	spec.Parameters = pipelineenvironment.Inject(spec.Parameters, variables)
+	names := strings.Join(pipelineenvironment.GetNames(environments), ",")
+	if pr.Annotations == nil {
+		pr.Annotations = make(map[string]string)
+	}
+	if pr.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey] != names {
+		pr.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey] = names
+		changed = true
+	}
+	return
+}
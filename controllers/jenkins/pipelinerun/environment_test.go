@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_injectEnvironments(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"}}
+	newRun := func() *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "run"},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "pipeline"},
+				SCM:         &v1alpha3.SCM{RefName: "release-1.0"},
+				Parameters:  []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}},
+			},
+		}
+	}
+	production := &v1alpha3.PipelineEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "production"},
+		Spec: v1alpha3.PipelineEnvironmentSpec{
+			Branches: []string{"release-*"},
+			Env:      []v1alpha3.EnvironmentVariable{{Name: "REGION", Value: "us"}, {Name: "REPLICAS", Value: "2"}},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		wantParams  []v1alpha3.Parameter
+		wantAnno    string
+		wantChanged bool
+		wantErr     bool
+	}{{
+		name:       "no environments",
+		wantParams: []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}},
+	}, {
+		name:        "inject the variables",
+		objects:     []client.Object{production.DeepCopy()},
+		wantParams:  []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}, {Name: "REGION", Value: "us"}},
+		wantAnno:    "production",
+		wantChanged: true,
+	}, {
+		name: "credential not found",
+		objects: []client.Object{&v1alpha3.PipelineEnvironment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "broken"},
+			Spec: v1alpha3.PipelineEnvironmentSpec{
+				SecretRefs: []v1alpha3.EnvironmentSecretRef{{Name: "TOKEN", SecretName: "fake"}},
+			},
+		}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.objects...).Build()}
+			pr := newRun()
+			spec, changed, err := r.injectEnvironments(context.Background(), pr, pipeline)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantParams, spec.Parameters)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantAnno, pr.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey])
+			// the parameters of PipelineRun are kept as they were given
+			assert.Equal(t, newRun().Spec.Parameters, pr.Spec.Parameters)
+		})
+	}
+}
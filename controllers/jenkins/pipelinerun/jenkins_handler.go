@@ -18,11 +18,13 @@ package pipelinerun
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/jenkins-zh/jenkins-client/pkg/queue"
 	"k8s.io/klog/v2"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/models/pipelinerun"
@@ -125,6 +127,12 @@ func (handler *jenkinsHandler) triggerJenkinsJob(devopsProjectName, pipelineName
 	})
 }
 
+// getPipelineRuns lists the builds of a Pipeline, including the builds of all branches of a multi-branch Pipeline.
+func (handler *jenkinsHandler) getPipelineRuns(devopsProjectName, pipelineName string) ([]job.PipelineRun, error) {
+	c := job.BlueOceanClient{JenkinsCore: *handler.JenkinsCore, Organization: "jenkins"}
+	return c.GetPipelineRuns(pipelineName, devopsProjectName)
+}
+
 func (handler *jenkinsHandler) deleteJenkinsJobHistory(pipelineRun *v1alpha3.PipelineRun) (err error) {
 	var buildNum int
 	if buildNum = getJenkinsBuildNumber(pipelineRun); buildNum < 0 {
@@ -144,6 +152,29 @@ func (handler *jenkinsHandler) deleteJenkinsJobHistory(pipelineRun *v1alpha3.Pip
 	return
 }
 
+// stopJenkinsBuild sends the stop signal to the Jenkins build of a PipelineRun.
+func (handler *jenkinsHandler) stopJenkinsBuild(pipelineRun *v1alpha3.PipelineRun, signal buildStopSignal) (err error) {
+	var buildNum int
+	if buildNum = getJenkinsBuildNumber(pipelineRun); buildNum < 0 {
+		return fmt.Errorf("unable to stop PipelineRun due to not found valid run ID")
+	}
+
+	jenkinsClient := job.Client{JenkinsCore: *handler.JenkinsCore}
+	api := fmt.Sprintf("%s/%d/%s", getJenkinsJobPath(pipelineRun), buildNum, signal)
+	_, err = jenkinsClient.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	return
+}
+
+// cancelQueuedBuild cancels an item of the Jenkins queue
+func (handler *jenkinsHandler) cancelQueuedBuild(queueID string) error {
+	id, err := strconv.Atoi(queueID)
+	if err != nil {
+		return fmt.Errorf("invalid queue ID: %s", queueID)
+	}
+	queueClient := &queue.Client{JenkinsCore: *handler.JenkinsCore}
+	return queueClient.Cancel(id)
+}
+
 // getJenkinsJobPath returns the corresponding Jenkins job path
 // only a regular or multi-branch Pipeline supported
 func getJenkinsJobPath(run *v1alpha3.PipelineRun) (jobPath string) {
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// badgeActionClass is the class of the action which is added by the step addBadge of Jenkins badge plugin
+const badgeActionClass = "com.jenkinsci.plugins.badge.action.BadgeAction"
+
+// buildMetadataHandler is able to read and write the metadata of the Jenkins build of a PipelineRun
+type buildMetadataHandler interface {
+	setBuildDescription(pr *v1alpha3.PipelineRun, description string) error
+	getBuildBadges(pr *v1alpha3.PipelineRun) ([]v1alpha3.Badge, error)
+}
+
+// getPropagatedMetadata returns the labels and annotations of a PipelineRun which should be propagated
+// to the Jenkins build, as sorted lines of "key: value"
+func getPropagatedMetadata(pr *v1alpha3.PipelineRun) string {
+	var lines []string
+	collect := func(items map[string]string) {
+		for key, value := range items {
+			if strings.HasPrefix(key, v1alpha3.PipelineRunMetadataPrefix) {
+				lines = append(lines, fmt.Sprintf("%s: %s",
+					strings.TrimPrefix(key, v1alpha3.PipelineRunMetadataPrefix), value))
+			}
+		}
+	}
+	collect(pr.Labels)
+	collect(pr.Annotations)
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// setBuildDescription submits the description of the Jenkins build
+func (handler *jenkinsHandler) setBuildDescription(pr *v1alpha3.PipelineRun, description string) (err error) {
+	var buildNum int
+	if buildNum = getJenkinsBuildNumber(pr); buildNum < 0 {
+		return fmt.Errorf("unable to set the build description due to not found valid run ID")
+	}
+
+	api := fmt.Sprintf("%s/%d/submitDescription", getJenkinsJobPath(pr), buildNum)
+	payload := strings.NewReader(url.Values{"description": []string{description}}.Encode())
+	_, err = handler.JenkinsCore.RequestWithoutData(http.MethodPost, api,
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, payload, http.StatusOK)
+	return
+}
+
+// buildActions is the actions of a Jenkins build, only the fields of badges are retrieved
+type buildActions struct {
+	Actions []struct {
+		Class    string `json:"_class"`
+		IconPath string `json:"iconPath"`
+		Text     string `json:"text"`
+		Link     string `json:"link"`
+	} `json:"actions"`
+}
+
+// getBuildBadges returns the badges of the Jenkins build
+func (handler *jenkinsHandler) getBuildBadges(pr *v1alpha3.PipelineRun) (badges []v1alpha3.Badge, err error) {
+	var buildNum int
+	if buildNum = getJenkinsBuildNumber(pr); buildNum < 0 {
+		return nil, fmt.Errorf("unable to get the build badges due to not found valid run ID")
+	}
+
+	actions := &buildActions{}
+	api := fmt.Sprintf("%s/%d/api/json?tree=actions[_class,iconPath,text,link]", getJenkinsJobPath(pr), buildNum)
+	if err = handler.JenkinsCore.RequestWithData(http.MethodGet, api, nil, nil, http.StatusOK, actions); err != nil {
+		return
+	}
+	for _, action := range actions.Actions {
+		if action.Class != badgeActionClass || (action.Text == "" && action.IconPath == "") {
+			continue
+		}
+		badges = append(badges, v1alpha3.Badge{Icon: action.IconPath, Text: action.Text, Link: action.Link})
+	}
+	return
+}
+
+// syncBuildMetadata keeps the metadata of a PipelineRun and its Jenkins build consistent. The propagated labels
+// and annotations are submitted as the build description if the Pipeline did not set one, the description and
+// badges of the build are imported into the status. The status must be applied with the build before.
+func syncBuildMetadata(handler buildMetadataHandler, pr *v1alpha3.PipelineRun, status *v1alpha3.PipelineRunStatus,
+	build *job.PipelineRun) error {
+	if build == nil {
+		return nil
+	}
+	status.Description = build.Description
+
+	// the build does not exist until it leaves the Jenkins queue
+	if build.Description == "" && build.State != Queued.String() {
+		if metadata := getPropagatedMetadata(pr); metadata != "" {
+			if err := handler.setBuildDescription(pr, metadata); err != nil {
+				return err
+			}
+			status.Description = metadata
+		}
+	}
+
+	// badges are fetched once the build finished, because they could be added at any step
+	if build.State == Finished.String() {
+		badges, err := handler.getBuildBadges(pr)
+		if err != nil {
+			return err
+		}
+		status.Badges = badges
+	}
+	return nil
+}
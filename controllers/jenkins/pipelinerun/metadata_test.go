@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+type fakeMetadataHandler struct {
+	description string
+	badges      []v1alpha3.Badge
+	err         error
+}
+
+func (h *fakeMetadataHandler) setBuildDescription(pr *v1alpha3.PipelineRun, description string) error {
+	h.description = description
+	return h.err
+}
+
+func (h *fakeMetadataHandler) getBuildBadges(pr *v1alpha3.PipelineRun) ([]v1alpha3.Badge, error) {
+	return h.badges, h.err
+}
+
+func newMetadataPipelineRun() *v1alpha3.PipelineRun {
+	return &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "run",
+			Labels: map[string]string{
+				v1alpha3.PipelineRunMetadataPrefix + "team": "backend",
+				"app": "demo",
+			},
+			Annotations: map[string]string{
+				v1alpha3.PipelineRunMetadataPrefix + "commit": "abc123",
+				v1alpha3.JenkinsPipelineRunIDAnnoKey:          "3",
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef: &corev1.ObjectReference{Name: "pipeline"},
+		},
+	}
+}
+
+func Test_getPropagatedMetadata(t *testing.T) {
+	assert.Equal(t, "commit: abc123\nteam: backend", getPropagatedMetadata(newMetadataPipelineRun()))
+	assert.Empty(t, getPropagatedMetadata(&v1alpha3.PipelineRun{}))
+}
+
+func Test_syncBuildMetadata(t *testing.T) {
+	newBuild := func(state JenkinsRunState, description string) *job.PipelineRun {
+		build := &job.PipelineRun{}
+		build.State = state.String()
+		build.Description = description
+		return build
+	}
+	badges := []v1alpha3.Badge{{Icon: "success.gif", Text: "deployed"}}
+
+	tests := []struct {
+		name            string
+		build           *job.PipelineRun
+		handler         *fakeMetadataHandler
+		wantDescription string
+		wantSubmitted   string
+		wantBadges      []v1alpha3.Badge
+		wantErr         bool
+	}{{
+		name:    "no build",
+		handler: &fakeMetadataHandler{},
+	}, {
+		name:    "queued build",
+		build:   newBuild(Queued, ""),
+		handler: &fakeMetadataHandler{},
+	}, {
+		name:            "propagate the metadata",
+		build:           newBuild(Running, ""),
+		handler:         &fakeMetadataHandler{},
+		wantDescription: "commit: abc123\nteam: backend",
+		wantSubmitted:   "commit: abc123\nteam: backend",
+	}, {
+		name:            "keep the description set by the Pipeline",
+		build:           newBuild(Running, "deploy to production"),
+		handler:         &fakeMetadataHandler{},
+		wantDescription: "deploy to production",
+	}, {
+		name:            "import badges of a finished build",
+		build:           newBuild(Finished, "deploy to production"),
+		handler:         &fakeMetadataHandler{badges: badges},
+		wantDescription: "deploy to production",
+		wantBadges:      badges,
+	}, {
+		name:          "failed to submit the description",
+		build:         newBuild(Running, ""),
+		handler:       &fakeMetadataHandler{err: errors.New("fake")},
+		wantSubmitted: "commit: abc123\nteam: backend",
+		wantErr:       true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &v1alpha3.PipelineRunStatus{}
+			err := syncBuildMetadata(tt.handler, newMetadataPipelineRun(), status, tt.build)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantDescription, status.Description)
+			assert.Equal(t, tt.wantSubmitted, tt.handler.description)
+			assert.Equal(t, tt.wantBadges, status.Badges)
+		})
+	}
+}
+
+func Test_jenkinsHandler_buildMetadata(t *testing.T) {
+	var description string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/job/ns/job/pipeline/3/submitDescription":
+			description = r.FormValue("description")
+		case r.Method == http.MethodGet && r.URL.Path == "/job/ns/job/pipeline/3/api/json":
+			_, _ = w.Write([]byte(`{"actions":[{"_class":"hudson.model.CauseAction"},
+{"_class":"com.jenkinsci.plugins.badge.action.BadgeAction","iconPath":"success.gif","text":"deployed","link":"https://example.com"},
+{"_class":"com.jenkinsci.plugins.badge.action.BadgeAction"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	handler := &jenkinsHandler{&core.JenkinsCore{URL: server.URL}}
+	pr := newMetadataPipelineRun()
+	assert.Nil(t, handler.setBuildDescription(pr, "team: backend"))
+	assert.Equal(t, "team: backend", description)
+
+	badges, err := handler.getBuildBadges(pr)
+	assert.Nil(t, err)
+	assert.Equal(t, []v1alpha3.Badge{{Icon: "success.gif", Text: "deployed", Link: "https://example.com"}}, badges)
+
+	// without a valid run ID
+	delete(pr.Annotations, v1alpha3.JenkinsPipelineRunIDAnnoKey)
+	assert.NotNil(t, handler.setBuildDescription(pr, ""))
+	_, err = handler.getBuildBadges(pr)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updatePipelineSummary aggregates the PipelineRuns into the status of their Pipeline. It's called for every change of
+// the PipelineRuns, but the status of Pipeline is only updated if the summary was changed. The status is a subresource,
+// so updating it does not change the generation of Pipeline.
+func (r *Reconciler) updatePipelineSummary(ctx context.Context, pipelineKey client.ObjectKey) error {
+	pipelineRuns, err := r.listPipelineRuns(ctx, pipelineKey.Namespace, pipelineKey.Name)
+	if err != nil {
+		return err
+	}
+	summary := pipelinerun.Summarize(pipelineRuns.Items)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipeline := &v1alpha3.Pipeline{}
+		if err := r.Get(ctx, pipelineKey, pipeline); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if equality.Semantic.DeepEqual(summary, pipeline.Status.Runs) {
+			return nil
+		}
+
+		pipeline.Status.Runs = summary
+		return r.Status().Update(ctx, pipeline)
+	})
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSummarizedRun(name, branch string, created time.Time, phase v1alpha3.RunPhase) v1alpha3.PipelineRun {
+	pr := v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}},
+	}
+	if branch != "" {
+		pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+	}
+	pr.Status.Phase = phase
+	if phase == v1alpha3.Succeeded || phase == v1alpha3.Failed {
+		completed := metav1.NewTime(created.Add(time.Minute))
+		pr.Status.CompletionTime = &completed
+	}
+	return pr
+}
+
+func TestReconciler_updatePipelineSummary(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"}}
+	succeeded := newSummarizedRun("run-1", "", now, v1alpha3.Succeeded)
+	running := newSummarizedRun("run-2", "", now.Add(time.Minute), v1alpha3.Running)
+	other := newSummarizedRun("other", "", now, v1alpha3.Failed)
+	other.Namespace = "another"
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline, &succeeded, &running, &other).Build()
+	r := &Reconciler{Client: c}
+	pipelineKey := client.ObjectKeyFromObject(pipeline)
+	assert.Nil(t, r.updatePipelineSummary(context.Background(), pipelineKey))
+
+	result := &v1alpha3.Pipeline{}
+	assert.Nil(t, c.Get(context.Background(), pipelineKey, result))
+	if assert.NotNil(t, result.Status.Runs) {
+		assert.Equal(t, 1, result.Status.Runs.FinishedRuns)
+		assert.Equal(t, 100, result.Status.Runs.SuccessRate)
+		assert.Equal(t, "run-1", result.Status.Runs.LastSuccessfulRun)
+		if assert.Len(t, result.Status.Runs.LatestRuns, 1) {
+			assert.Equal(t, "run-2", result.Status.Runs.LatestRuns[0].Name)
+		}
+	}
+
+	// the Pipeline is not updated if nothing changed
+	assert.Nil(t, r.updatePipelineSummary(context.Background(), pipelineKey))
+	unchanged := &v1alpha3.Pipeline{}
+	assert.Nil(t, c.Get(context.Background(), pipelineKey, unchanged))
+	assert.Equal(t, result.ResourceVersion, unchanged.ResourceVersion)
+
+	// ignore the deleted Pipeline
+	assert.Nil(t, r.updatePipelineSummary(context.Background(), client.ObjectKey{Namespace: "ns", Name: "fake"}))
+}
@@ -36,16 +36,34 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	ctrlcore "kubesphere.io/devops/controllers/core"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/freezewindow"
+	"kubesphere.io/devops/pkg/models/scheduler"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // tokenExpireIn indicates that the temporary token issued by controller will be expired in some time.
 const tokenExpireIn time.Duration = 5 * time.Minute
 
+const (
+	// DefaultSyncPeriod is the default period of polling Jenkins for a running PipelineRun.
+	DefaultSyncPeriod = 3 * time.Second
+	// DefaultIdleSyncPeriod is the default period of polling Jenkins for a queued or paused PipelineRun.
+	DefaultIdleSyncPeriod = 15 * time.Second
+	// eventDrivenResyncPeriod is the fallback period of polling Jenkins for a running PipelineRun which
+	// receives events from Jenkins. It is only used to catch up the progress of stages.
+	eventDrivenResyncPeriod = 30 * time.Second
+)
+
 // BuildNotExistMsg indicates the build with pipelinerun-id not exist in jenkins
 const BuildNotExistMsg = "not found resources"
 
@@ -61,10 +79,27 @@ type Reconciler struct {
 	TokenIssuer          token.Issuer
 	recorder             record.EventRecorder
 	PipelineRunDataStore string
+	// SyncPeriod is the period of polling Jenkins for a running PipelineRun
+	SyncPeriod time.Duration
+	// IdleSyncPeriod is the period of polling Jenkins for a queued or paused PipelineRun
+	IdleSyncPeriod time.Duration
+	// ExecutorCapacity is the maximum number of unfinished PipelineRuns in Jenkins, it's unlimited if it's zero
+	ExecutorCapacity int
+	// MaxQueueLength is the length of Jenkins queue which stops submitting more builds, it's unlimited if it's zero
+	MaxQueueLength int
+
+	capacity *capacityChecker
 }
 
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=freezewindows;clusterfreezewindows,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=sharedresources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=sharedresources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineenvironments,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -80,6 +115,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// propagate the changes of the PipelineRun to the status of its Pipeline
+	if pipelineRun.Spec.PipelineRef != nil && pipelineRun.Spec.PipelineRef.Name != "" {
+		pipelineKey := client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRun.Spec.PipelineRef.Name}
+		if err := r.updatePipelineSummary(ctx, pipelineKey); err != nil {
+			log.Error(err, "unable to update the summary of PipelineRuns", "Pipeline", pipelineKey)
+		}
+	}
+
 	jHandler := &jenkinsHandler{&r.JenkinsCore}
 
 	// don't modify the cache in other places, like informer cache.
@@ -114,6 +157,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		log.Error(err, "unable to get pipeline")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if pipeline.GetEngine() != v1alpha3.PipelineEngineJenkins {
+		// the PipelineRun is executed by another engine
+		return ctrl.Result{}, nil
+	}
+	if pipelineRunCopied.IsMatrixParent(pipeline) {
+		// the PipelineRun is expanded by the matrix controller
+		return ctrl.Result{}, nil
+	}
+
+	// stop the earlier PipelineRuns of the same pull request
+	if err := r.cancelSupersededRuns(ctx, pipelineRunCopied, pipeline); err != nil {
+		log.Error(err, "unable to cancel the superseded PipelineRuns")
+		return ctrl.Result{}, err
+	}
 
 	namespaceName := pipeline.Namespace
 	pipelineName := pipeline.GetName()
@@ -131,6 +188,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		log.V(5).Info("pipeline has already started, and we are retrieving run data from Jenkins.")
 		pipelineBuild, err := jHandler.getPipelineRunResult(namespaceName, pipelineName, pipelineRunCopied)
 		if err != nil {
+			if err.Error() == BuildNotExistMsg && pipelineRunCopied.IsStopRequested() && isQueuedInJenkins(pipelineRunCopied) {
+				// the queued build disappeared after it was cancelled from the Jenkins queue
+				cancelPipelineRunStatus(&pipelineRunCopied.Status, time.Now())
+				if err := r.updateStatus(ctx, &pipelineRunCopied.Status, req.NamespacedName); err != nil {
+					log.Error(err, "unable to update PipelineRun status.")
+					return ctrl.Result{}, err
+				}
+				r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Stopping, "Cancelled PipelineRun %s from the Jenkins queue", req.NamespacedName)
+				return ctrl.Result{}, nil
+			}
 			if err.Error() == BuildNotExistMsg { // retry if get pipelinerun failed by not exist
 				runID, _ := pipelineRunCopied.GetPipelineRunID()
 				log.Info(fmt.Sprintf("get pipelinerun data(id: %s) error with not exit, retry.", runID))
@@ -141,22 +208,51 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{}, err
 		}
 
+		nodeDetails, err := jHandler.getPipelineNodeDetails(pipelineName, namespaceName, pipelineRunCopied)
+		if err != nil {
+			log.Error(err, "unable to get PipelineRun nodes detail")
+			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.RetrieveFailed, "Failed to retrieve nodes detail from Jenkins, and error was %v", err)
+		}
+
+		// the applier treats the queued state as running, so keep the original state for polling
+		jenkinsState := pipelineBuild.State
+
 		// update pipelinerun status with pipelineBuild
 		status := pipelineRunCopied.Status.DeepCopy()
 		pbApplier := pipelineBuildApplier{pipelineBuild}
 		pbApplier.apply(status)
-		// Because the status is a subresource of PipelineRun, we have to update status separately.
-		// See also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
-		if err := r.updateStatus(ctx, status, req.NamespacedName); err != nil {
-			log.Error(err, "unable to update PipelineRun status.")
+		// reflect why the build is waiting in the Jenkins queue
+		if jenkinsState == Queued.String() {
+			scheduler.WaitInJenkinsQueue(status, pipelineBuild.CauseOfBlockage, time.Now())
+		} else {
+			scheduler.Dequeue(status, time.Now())
+		}
+
+		// stop the PipelineRun if someone requested
+		stopper := stopHandler{stopper: jHandler, now: time.Now()}
+		if err := stopper.handle(pipelineRunCopied, status, pipelineBuild, nodeDetails); err != nil {
+			log.Error(err, "unable to stop PipelineRun.")
+			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.StopFailed, "Failed to stop PipelineRun %s, and error was %v", req.NamespacedName, err)
 			return ctrl.Result{}, err
 		}
+		if stopped := getCondition(status, v1alpha3.ConditionStopped); stopped != nil &&
+			!reflect.DeepEqual(stopped, getCondition(&pipelineRunCopied.Status, v1alpha3.ConditionStopped)) {
+			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Stopping, "Stopping PipelineRun %s: %s", req.NamespacedName, stopped.Message)
+		}
 
-		nodeDetails, err := jHandler.getPipelineNodeDetails(pipelineName, namespaceName, pipelineRunCopied)
-		if err != nil {
-			log.Error(err, "unable to get PipelineRun nodes detail")
-			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.RetrieveFailed, "Failed to retrieve nodes detail from Jenkins, and error was %v", err)
+		// keep the metadata consistent between the PipelineRun and the Jenkins build
+		if err := syncBuildMetadata(jHandler, pipelineRunCopied, status, pipelineBuild); err != nil {
+			log.Error(err, "unable to sync the metadata of Jenkins build")
+		}
+
+		// the result is recorded in the finalizing stage, which is repeated until the status is completed
+		if !status.CompletionTime.IsZero() {
+			if _, err := moveToStage(pipelineRunCopied, v1alpha3.ReconcileStageFinalizing, time.Now()); err != nil {
+				log.Error(err, "unable to finalize PipelineRun.")
+				return ctrl.Result{}, err
+			}
 		}
+
 		runResultJSON, err := json.Marshal(pipelineBuild)
 		if err != nil {
 			log.Error(err, "unable to marshal result data to JSON")
@@ -185,22 +281,162 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{}, err
 		}
 
+		// Because the status is a subresource of PipelineRun, we have to update status separately.
+		// See also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
+		// The status is updated at last, so the PipelineRun is not completed until the stages and result are stored.
+		if err := r.updateStatus(ctx, status, req.NamespacedName); err != nil {
+			log.Error(err, "unable to update PipelineRun status.")
+			return ctrl.Result{}, err
+		}
+
 		r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Updated, "Updated running data for PipelineRun %s", req.NamespacedName)
 		// until the status is okay
-		// TODO make the RequeueAfter configurable
-		return ctrl.Result{RequeueAfter: 3 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.getResyncPeriod(pipelineRunCopied, jenkinsState)}, nil
 	}
 
+	// resume the submission which was interrupted, the Jenkins build might have been submitted already
+	if pipelineRunCopied.GetReconcileStage() == v1alpha3.ReconcileStageSubmitting {
+		jobRun, err := r.recoverSubmission(ctx, jHandler, pipelineRunCopied, pipeline)
+		if err != nil {
+			log.Error(err, "unable to find the submitted Jenkins build")
+			return ctrl.Result{}, err
+		}
+		if jobRun != nil {
+			log.Info("Adopted the submitted Jenkins build", "runID", jobRun.ID)
+			return ctrl.Result{}, r.markStarted(ctx, pipelineRunCopied, jobRun)
+		}
+	}
+
+	// give up triggering the PipelineRun which was stopped before being triggered
+	if pipelineRunCopied.IsStopRequested() {
+		if err := r.updateLabelsAndAnnotations(ctx, pipelineRunCopied); err != nil {
+			log.Error(err, "unable to update PipelineRun labels and annotations.")
+			return ctrl.Result{}, err
+		}
+		cancelPipelineRunStatus(&pipelineRunCopied.Status, time.Now())
+		if err := r.updateStatus(ctx, &pipelineRunCopied.Status, req.NamespacedName); err != nil {
+			log.Error(err, "unable to update PipelineRun status.")
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Stopping, "Cancelled PipelineRun %s before triggering", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// hold or cancel the PipelineRun if the Pipeline is suspended
+	if pipeline.IsSuspended() {
+		if suspendPipelineRunStatus(&pipelineRunCopied.Status, pipeline, time.Now()) {
+			if err := r.updateStatus(ctx, &pipelineRunCopied.Status, req.NamespacedName); err != nil {
+				log.Error(err, "unable to update PipelineRun status.")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Suspended, "PipelineRun %s is %s because Pipeline %s is suspended",
+				req.NamespacedName, getCondition(&pipelineRunCopied.Status, v1alpha3.ConditionSuspended).Reason, pipelineName)
+		}
+		// the held PipelineRun is reconciled again once the Pipeline is resumed
+		return ctrl.Result{}, nil
+	}
+
+	// coalesce the successive webhook events of the same branch
+	if result, wait, err := r.debounce(ctx, pipelineRunCopied, pipeline); err != nil || wait {
+		if err != nil {
+			log.Error(err, "unable to debounce PipelineRun")
+		}
+		return result, err
+	}
+
+	// hold the PipelineRun if the Pipeline is frozen
+	window, err := freezewindow.Find(ctx, r.Client, pipeline, time.Now())
+	if err != nil {
+		log.Error(err, "unable to find freeze windows")
+		return ctrl.Result{}, err
+	}
+	if window != nil {
+		if freezewindow.Hold(&pipelineRunCopied.Status, window, time.Now()) {
+			if err := r.updateStatus(ctx, &pipelineRunCopied.Status, req.NamespacedName); err != nil {
+				log.Error(err, "unable to update PipelineRun status.")
+				return ctrl.Result{}, err
+			}
+			r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Frozen, "PipelineRun %s is held by freeze window %s with policy %s",
+				req.NamespacedName, window.Name, window.Policy)
+		}
+		if window.Policy == v1alpha3.FreezePolicyReject {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Until(window.End)}, nil
+	}
+
+	// wait until the ephemeral namespace is ready
+	if pipelineRunCopied.IsWaitingForEphemeralNamespace(pipeline) {
+		// the PipelineRun is reconciled again once the ephemeral namespace is annotated
+		log.V(5).Info("waiting for the ephemeral namespace")
+		return ctrl.Result{}, nil
+	}
+
+	// hold the PipelineRun instead of piling builds into the Jenkins queue
+	if r.capacity != nil {
+		if result, wait, err := r.holdIfSaturated(ctx, pipelineRunCopied); err != nil || wait {
+			if err != nil {
+				log.Error(err, "unable to get the load of Jenkins")
+			}
+			return result, err
+		}
+	}
+
+	// wait for the executors if the capacity of Jenkins is limited
+	if r.ExecutorCapacity > 0 {
+		if result, wait, err := r.schedule(ctx, pipelineRunCopied); err != nil || wait {
+			if err != nil {
+				log.Error(err, "unable to schedule PipelineRun")
+			}
+			return result, err
+		}
+	}
+
+	// lock the shared resources until the PipelineRun completes
+	if names := pipelineRunCopied.GetSharedResources(pipeline); len(names) > 0 {
+		if result, wait, err := r.acquireSharedResources(ctx, pipelineRunCopied, names); err != nil || wait {
+			if err != nil {
+				log.Error(err, "unable to acquire the shared resources")
+			}
+			return result, err
+		}
+	}
+
+	// don't submit any new build once the manager is stopping, the PipelineRun is picked up by the next leader
+	if ctrlcore.ShuttingDown(ctx) {
+		log.Info("Skipped submitting the PipelineRun because the controller is shutting down")
+		return ctrl.Result{}, nil
+	}
+	// finish the submission after the manager is stopped, otherwise the build is orphaned until the next leader adopts it
+	ctx, cancel := ctrlcore.Drain(ctx, ctrlcore.DefaultDrainTimeout)
+	defer cancel()
+
 	// get or create JenkinsCore if the PipelineRun has creator annotation
 	jenkinsCore, err := r.getOrCreateJenkinsCore(pipelineRunCopied.GetAnnotations())
 	if err != nil {
 		r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.TriggerFailed, "Failed to trigger PipelineRun %s, and error was %v", req.NamespacedName, err)
 		return ctrl.Result{}, err
 	}
+	// pass the variables of the PipelineEnvironments to the build instead of baking them into the Jenkinsfile
+	prSpec, injected, err := r.injectEnvironments(ctx, pipelineRunCopied, pipeline)
+	if err != nil {
+		log.Error(err, "unable to inject the PipelineEnvironments")
+		r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.TriggerFailed, "Failed to inject the PipelineEnvironments into PipelineRun %s, and error was %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+	// persist the stage before submitting, so the build is looked up instead of being submitted again after restarting
+	if changed, err := moveToStage(pipelineRunCopied, v1alpha3.ReconcileStageSubmitting, time.Now()); err != nil {
+		return ctrl.Result{}, err
+	} else if changed || injected {
+		if err := r.updateLabelsAndAnnotations(ctx, pipelineRunCopied); err != nil {
+			log.Error(err, "unable to update PipelineRun labels and annotations.")
+			return ctrl.Result{}, err
+		}
+	}
 	// create trigger handler
 	triggerHandler := &jenkinsHandler{jenkinsCore}
 	// first run
-	jobRun, err := triggerHandler.triggerJenkinsJob(namespaceName, pipelineName, &pipelineRunCopied.Spec)
+	jobRun, err := triggerHandler.triggerJenkinsJob(namespaceName, pipelineName, prSpec)
 	if err != nil {
 		log.Error(err, "unable to run pipeline", "namespace", namespaceName, "pipeline", pipeline.Name)
 		r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeWarning, v1alpha3.TriggerFailed, "Failed to trigger PipelineRun %s, and error was %v", req.NamespacedName, err)
@@ -220,31 +456,35 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	log.Info("Triggered a PipelineRun", "runID", jobRun.ID)
-
-	// set Jenkins run ID
-	if pipelineRunCopied.Annotations == nil {
-		pipelineRunCopied.Annotations = make(map[string]string)
+	if r.capacity != nil {
+		r.capacity.submitted()
 	}
-	pipelineRunCopied.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = jobRun.ID
+	return ctrl.Result{}, r.markStarted(ctx, pipelineRunCopied, jobRun)
+}
 
-	// the Update method only updates fields except subresource: status
-	if err := r.updateLabelsAndAnnotations(ctx, pipelineRunCopied); err != nil {
-		log.Error(err, "unable to update PipelineRun labels and annotations.")
-		return ctrl.Result{}, err
+// getResyncPeriod returns the period to requeue an unfinished PipelineRun. It polls Jenkins less frequently when the
+// PipelineRun is waiting in the queue or for an input, or Jenkins pushes the run events to us.
+func (r *Reconciler) getResyncPeriod(pr *v1alpha3.PipelineRun, jenkinsState string) (period time.Duration) {
+	period = r.SyncPeriod
+	if period <= 0 {
+		period = DefaultSyncPeriod
 	}
 
-	pipelineRunCopied.Status.StartTime = &v1.Time{Time: time.Now()}
-	pipelineRunCopied.Status.UpdateTime = &v1.Time{Time: time.Now()}
-	// due to the status is subresource of PipelineRun, we have to update status separately.
-	// see also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
+	var slowPeriod time.Duration
+	switch jenkinsState {
+	case Queued.String(), Paused.String():
+		if slowPeriod = r.IdleSyncPeriod; slowPeriod <= 0 {
+			slowPeriod = DefaultIdleSyncPeriod
+		}
+	}
+	if _, ok := pr.Annotations[v1alpha3.JenkinsPipelineRunEventAnnoKey]; ok && slowPeriod < eventDrivenResyncPeriod {
+		slowPeriod = eventDrivenResyncPeriod
+	}
 
-	if err := r.updateStatus(ctx, &pipelineRunCopied.Status, req.NamespacedName); err != nil {
-		log.Error(err, "unable to update PipelineRun status.")
-		return ctrl.Result{}, err
+	if slowPeriod > period {
+		period = slowPeriod
 	}
-	r.recorder.Eventf(pipelineRunCopied, corev1.EventTypeNormal, v1alpha3.Started, "Started PipelineRun %s", req.NamespacedName)
-	// requeue after 1 second
-	return ctrl.Result{}, nil
+	return
 }
 
 func (r *Reconciler) storePipelineRunData(nodeDetailsJSON string, pipelineRunCopied *v1alpha3.PipelineRun) (err error) {
@@ -295,6 +535,14 @@ func (r *Reconciler) hasSamePipelineRun(jobRun *job.PipelineRun, pipeline *v1alp
 	return
 }
 
+// listPipelineRuns lists the PipelineRuns which reference the Pipeline from the field index
+func (r *Reconciler) listPipelineRuns(ctx context.Context, namespace, pipelineName string) (*v1alpha3.PipelineRunList, error) {
+	runList := &v1alpha3.PipelineRunList{}
+	err := r.List(ctx, runList, client.InNamespace(namespace),
+		client.MatchingFields{v1alpha3.PipelineRunPipelineRefField: pipelineName})
+	return runList, err
+}
+
 func getSCMRefName(prSpec *v1alpha3.PipelineRunSpec) (string, error) {
 	var branch = ""
 	if prSpec.IsMultiBranchPipeline() {
@@ -334,11 +582,16 @@ func (r *Reconciler) updateStatus(ctx context.Context, desiredStatus *v1alpha3.P
 		if err != nil {
 			return err
 		}
-		if reflect.DeepEqual(*desiredStatus, prToUpdate.Status) {
+		// the usage of agent pods and the scan results are maintained by the other controllers
+		status := desiredStatus.DeepCopy()
+		status.AgentUsage = prToUpdate.Status.AgentUsage
+		status.SecretScan = prToUpdate.Status.SecretScan
+		status.LicenseScan = prToUpdate.Status.LicenseScan
+		if reflect.DeepEqual(*status, prToUpdate.Status) {
 			return nil
 		}
 		prToUpdate = *prToUpdate.DeepCopy()
-		prToUpdate.Status = *desiredStatus
+		prToUpdate.Status = *status
 		return r.Status().Update(ctx, &prToUpdate)
 	})
 }
@@ -378,6 +631,8 @@ func (r *Reconciler) getOrCreateJenkinsCore(annotations map[string]string) (*cor
 		URL:      r.JenkinsCore.URL,
 		UserName: creator,
 		Token:    accessToken,
+
+		RoundTripper: r.JenkinsCore.RoundTripper,
 	}
 	return jenkinsCore, nil
 }
@@ -387,7 +642,15 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// the name should obey Kubernetes naming convention: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
 	r.recorder = mgr.GetEventRecorderFor("pipelinerun-controller")
 	r.log = ctrl.Log.WithName("pipelinerun-controller")
+	if r.MaxQueueLength > 0 {
+		r.capacity = newCapacityChecker(&r.JenkinsCore, r.MaxQueueLength)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			RateLimiter: ctrlcore.NewTrackedRateLimiter("pipelinerun", v1alpha3.GroupVersion.WithKind("PipelineRun")),
+		}).
 		For(&v1alpha3.PipelineRun{}).
+		Watches(&source.Kind{Type: &v1alpha3.Pipeline{}}, handler.EnqueueRequestsFromMapFunc(r.findHeldPipelineRuns),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
@@ -41,6 +41,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"testing"
+	"time"
+
 	// nolint
 	// The fakeclient will undeprecated starting with v0.7.0
 	// Reference:
@@ -516,3 +518,124 @@ func TestStorePipelineRunData(t *testing.T) {
 	}
 	assert.Nil(t, r.storePipelineRunData("", pipelineRun.DeepCopy()))
 }
+
+func TestReconciler_getResyncPeriod(t *testing.T) {
+	eventAnnotations := map[string]string{
+		v1alpha3.JenkinsPipelineRunIDAnnoKey:    "1",
+		v1alpha3.JenkinsPipelineRunEventAnnoKey: "run.started",
+	}
+	tests := []struct {
+		name           string
+		syncPeriod     time.Duration
+		idleSyncPeriod time.Duration
+		annotations    map[string]string
+		jenkinsState   string
+		want           time.Duration
+	}{{
+		name:         "running without any options",
+		jenkinsState: Running.String(),
+		want:         DefaultSyncPeriod,
+	}, {
+		name:         "queued without any options",
+		jenkinsState: Queued.String(),
+		want:         DefaultIdleSyncPeriod,
+	}, {
+		name:           "running with options",
+		syncPeriod:     5 * time.Second,
+		idleSyncPeriod: time.Minute,
+		jenkinsState:   Running.String(),
+		want:           5 * time.Second,
+	}, {
+		name:           "paused with options",
+		syncPeriod:     5 * time.Second,
+		idleSyncPeriod: time.Minute,
+		jenkinsState:   Paused.String(),
+		want:           time.Minute,
+	}, {
+		name:           "idle period is smaller than the sync period",
+		syncPeriod:     10 * time.Second,
+		idleSyncPeriod: time.Second,
+		jenkinsState:   Queued.String(),
+		want:           10 * time.Second,
+	}, {
+		name:         "running with Jenkins event",
+		annotations:  eventAnnotations,
+		jenkinsState: Running.String(),
+		want:         eventDrivenResyncPeriod,
+	}, {
+		name:           "queued with Jenkins event",
+		idleSyncPeriod: time.Minute,
+		annotations:    eventAnnotations,
+		jenkinsState:   Queued.String(),
+		want:           time.Minute,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{SyncPeriod: tt.syncPeriod, IdleSyncPeriod: tt.idleSyncPeriod}
+			pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			assert.Equal(t, tt.want, r.getResyncPeriod(pr, tt.jenkinsState))
+		})
+	}
+}
+
+func TestReconciler_updateStatusKeepsExternalFields(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	agentUsage := []v1alpha3.AgentPodUsage{{Pod: "agent", Samples: 3}}
+	secretScan := &v1alpha3.SecretScanResult{Revision: "abc", Mode: v1alpha3.GateModeWarn}
+	licenseScan := &v1alpha3.LicenseScanResult{Report: "bom.json", Mode: v1alpha3.GateModeWarn}
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pr"},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running, AgentUsage: agentUsage,
+			SecretScan: secretScan, LicenseScan: licenseScan},
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pr).Build()
+	r := &Reconciler{Client: c}
+
+	// the desired status was computed before the agent usage was sampled and the scans happened
+	desiredStatus := &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded}
+	assert.Nil(t, r.updateStatus(context.Background(), desiredStatus, client.ObjectKeyFromObject(pr)))
+
+	updated := &v1alpha3.PipelineRun{}
+	assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(pr), updated))
+	assert.Equal(t, v1alpha3.Succeeded, updated.Status.Phase)
+	assert.Equal(t, agentUsage, updated.Status.AgentUsage)
+	assert.Equal(t, secretScan, updated.Status.SecretScan)
+	assert.Equal(t, licenseScan, updated.Status.LicenseScan)
+}
+
+func TestPipelineRunReconcile_shuttingDown(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{}
+	pipeline.SetName("pipeline")
+	pipeline.SetNamespace("ns")
+	pipelineRun := &v1alpha3.PipelineRun{}
+	pipelineRun.SetName("name")
+	pipelineRun.SetNamespace("ns")
+	pipelineRun.Spec.PipelineRef = &v1.ObjectReference{Namespace: "ns", Name: "pipeline"}
+
+	k8sclient := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline, pipelineRun).Build()
+	r := &Reconciler{
+		Client:   k8sclient,
+		log:      logr.New(log.NullLogSink{}),
+		recorder: &record.FakeRecorder{},
+	}
+	// the manager is stopping
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"}})
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	// the PipelineRun is not submitted
+	latest := &v1alpha3.PipelineRun{}
+	err = k8sclient.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "name"}, latest)
+	assert.Nil(t, err)
+	assert.NotEqual(t, v1alpha3.ReconcileStageSubmitting, latest.GetReconcileStage())
+	assert.False(t, latest.HasStarted())
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	corev1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultQueuePeriod is the period of checking if a queued PipelineRun could be triggered
+const defaultQueuePeriod = 15 * time.Second
+
+// schedule decides if the pending PipelineRun could be triggered when the executors of Jenkins are limited.
+// It returns wait as true if the PipelineRun should stay in the queue.
+func (r *Reconciler) schedule(ctx context.Context, pr *v1alpha3.PipelineRun) (result ctrl.Result, wait bool, err error) {
+	runList := &v1alpha3.PipelineRunList{}
+	if err = r.List(ctx, runList); err != nil {
+		return
+	}
+
+	now := time.Now()
+	decision := scheduler.Schedule(pr, runList.Items, r.ExecutorCapacity, isQueuedInJenkins)
+	if !decision.Admitted {
+		wait = true
+		if result.RequeueAfter = r.IdleSyncPeriod; result.RequeueAfter <= 0 {
+			result.RequeueAfter = defaultQueuePeriod
+		}
+		if scheduler.Enqueue(&pr.Status, decision.Position, now) {
+			if err = r.updateStatus(ctx, &pr.Status, client.ObjectKeyFromObject(pr)); err != nil {
+				return
+			}
+			r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Queued, "PipelineRun %s/%s is waiting for the executors, position %d in the queue",
+				pr.Namespace, pr.Name, decision.Position)
+		}
+		return
+	}
+
+	if decision.Victim != nil {
+		err = r.preempt(ctx, decision.Victim, pr, now)
+	}
+	return
+}
+
+// preempt stops the Jenkins build of the victim which is still in the Jenkins queue, then puts it back to our queue
+func (r *Reconciler) preempt(ctx context.Context, victim, by *v1alpha3.PipelineRun, now time.Time) (err error) {
+	victim = victim.DeepCopy()
+	handler := &jenkinsHandler{&r.JenkinsCore}
+	if err = handler.stopJenkinsBuild(victim, stopSignal); err != nil {
+		return fmt.Errorf("failed to preempt PipelineRun %s/%s, error: %v", victim.Namespace, victim.Name, err)
+	}
+
+	scheduler.Preempt(victim, by, now)
+	status := victim.Status.DeepCopy()
+	if err = r.Update(ctx, victim); err != nil {
+		return
+	}
+	if err = r.updateStatus(ctx, status, client.ObjectKeyFromObject(victim)); err != nil {
+		return
+	}
+	r.recorder.Eventf(victim, corev1.EventTypeNormal, v1alpha3.Preempted, "PipelineRun %s/%s was preempted by %s/%s",
+		victim.Namespace, victim.Name, by.Namespace, by.Name)
+	return
+}
+
+// isQueuedInJenkins returns true if the last synchronized state of the PipelineRun is queued
+func isQueuedInJenkins(pr *v1alpha3.PipelineRun) bool {
+	build := &job.PipelineRun{}
+	if err := json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), build); err != nil {
+		return false
+	}
+	return build.State == Queued.String()
+}
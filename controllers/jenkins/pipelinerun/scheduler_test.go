@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_schedule(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			requestedPaths = append(requestedPaths, r.URL.Path)
+		} else {
+			// the crumb is disabled
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	newRun := func(name string, priority int32, jenkinsState string) *v1alpha3.PipelineRun {
+		run := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "ns",
+				CreationTimestamp: metav1.NewTime(now),
+				Annotations:       map[string]string{},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "pipeline"},
+				Priority:    priority,
+			},
+		}
+		if jenkinsState != "" {
+			run.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = "3"
+			run.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey] = `{"state":"` + jenkinsState + `"}`
+			run.Status.StartTime = &metav1.Time{Time: now}
+		}
+		return run
+	}
+
+	tests := []struct {
+		name   string
+		run    *v1alpha3.PipelineRun
+		others []client.Object
+		wait   bool
+		verify func(t *testing.T, c client.Client, run *v1alpha3.PipelineRun)
+	}{{
+		name:   "free capacity",
+		run:    newRun("a", 0, ""),
+		others: []client.Object{newRun("b", 0, Running.String())},
+	}, {
+		name:   "wait for the executors",
+		run:    newRun("a", 0, ""),
+		others: []client.Object{newRun("b", 0, Running.String()), newRun("c", 0, Queued.String())},
+		wait:   true,
+		verify: func(t *testing.T, c client.Client, run *v1alpha3.PipelineRun) {
+			assert.True(t, scheduler.IsQueued(run))
+			assert.Equal(t, v1alpha3.Pending, run.Status.Phase)
+		},
+	}, {
+		name:   "preempt the queued PipelineRun in Jenkins",
+		run:    newRun("a", 1, ""),
+		others: []client.Object{newRun("b", 0, Running.String()), newRun("c", 0, Queued.String())},
+		verify: func(t *testing.T, c client.Client, _ *v1alpha3.PipelineRun) {
+			assert.Equal(t, []string{"/job/ns/job/pipeline/3/stop"}, requestedPaths)
+			victim := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: "c"}, victim))
+			assert.False(t, victim.HasStarted())
+			assert.True(t, scheduler.IsQueued(victim))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestedPaths = nil
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(append(tt.others, tt.run)...).Build()
+			r := &Reconciler{
+				Client:           c,
+				JenkinsCore:      core.JenkinsCore{URL: server.URL},
+				ExecutorCapacity: 2,
+				log:              logr.Discard(),
+				recorder:         &record.FakeRecorder{},
+			}
+			result, wait, err := r.schedule(context.Background(), tt.run.DeepCopy())
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wait, wait)
+			assert.Equal(t, tt.wait, result.RequeueAfter > 0)
+			if tt.verify != nil {
+				run := &v1alpha3.PipelineRun{}
+				assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(tt.run), run))
+				tt.verify(t, c, run)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	"kubesphere.io/devops/pkg/models/sharedresource"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// acquireSharedResources locks the SharedResources for the pending PipelineRun before triggering it.
+// It returns wait as true if the PipelineRun should wait for the resources held by others.
+func (r *Reconciler) acquireSharedResources(ctx context.Context, pr *v1alpha3.PipelineRun,
+	names []string) (result ctrl.Result, wait bool, err error) {
+	now := time.Now()
+	var acquired bool
+	var message string
+	if acquired, message, err = sharedresource.Acquire(ctx, r.Client, pr, names, now); err != nil || acquired {
+		return
+	}
+
+	wait = true
+	if result.RequeueAfter = r.IdleSyncPeriod; result.RequeueAfter <= 0 {
+		result.RequeueAfter = defaultQueuePeriod
+	}
+	if scheduler.WaitForResource(&pr.Status, message, now) {
+		if err = r.updateStatus(ctx, &pr.Status, client.ObjectKeyFromObject(pr)); err != nil {
+			return
+		}
+		r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Queued, "PipelineRun %s/%s is %s",
+			pr.Namespace, pr.Name, message)
+	}
+	return
+}
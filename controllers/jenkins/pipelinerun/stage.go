@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/freezewindow"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// submissionClockSkew tolerates the difference between the clocks of the controller and Jenkins when looking for the
+// build which was submitted before the controller restarted
+const submissionClockSkew = time.Minute
+
+// stageTransitions lists the stages which a stage is allowed to move to. The stages never move backward, so a stale
+// reconciliation is not able to submit the Jenkins build again.
+var stageTransitions = map[v1alpha3.ReconcileStage][]v1alpha3.ReconcileStage{
+	v1alpha3.ReconcileStagePending:    {v1alpha3.ReconcileStageSubmitting, v1alpha3.ReconcileStageRunning},
+	v1alpha3.ReconcileStageSubmitting: {v1alpha3.ReconcileStageRunning},
+	v1alpha3.ReconcileStageRunning:    {v1alpha3.ReconcileStageFinalizing},
+}
+
+// moveToStage moves the PipelineRun to the stage, nothing changes if it's in the stage already. It only changes the
+// annotations, the caller should persist them.
+func moveToStage(pr *v1alpha3.PipelineRun, stage v1alpha3.ReconcileStage, now time.Time) (changed bool, err error) {
+	current := pr.GetReconcileStage()
+	if current != stage && !isAllowedTransition(current, stage) {
+		return false, fmt.Errorf("unable to move PipelineRun %s/%s from stage %s to %s", pr.Namespace, pr.Name, current, stage)
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = make(map[string]string)
+	}
+	if pr.Annotations[v1alpha3.PipelineRunReconcileStageAnnoKey] != string(stage) {
+		pr.Annotations[v1alpha3.PipelineRunReconcileStageAnnoKey] = string(stage)
+		changed = true
+	}
+	// keep the time of the first submission, the builds submitted by the retries are all after it
+	if _, ok := pr.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey]; !ok && stage == v1alpha3.ReconcileStageSubmitting {
+		pr.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey] = now.UTC().Format(time.RFC3339)
+		changed = true
+	}
+	return
+}
+
+func isAllowedTransition(from, to v1alpha3.ReconcileStage) bool {
+	for _, stage := range stageTransitions[from] {
+		if stage == to {
+			return true
+		}
+	}
+	return false
+}
+
+// getSubmittedTime returns the time when the controller started to submit the Jenkins build
+func getSubmittedTime(pr *v1alpha3.PipelineRun) (submittedAt time.Time, ok bool) {
+	value, exist := pr.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey]
+	if !exist {
+		return
+	}
+	var err error
+	if submittedAt, err = time.Parse(time.RFC3339, value); err != nil {
+		return
+	}
+	return submittedAt, true
+}
+
+// findSubmittedBuild finds the Jenkins build which was submitted for the PipelineRun, but the controller restarted
+// before recording its run ID. It's the earliest build which was queued after the submission, and is not owned by
+// the other PipelineRuns.
+func findSubmittedBuild(pr *v1alpha3.PipelineRun, jobRuns []job.PipelineRun, pipelineRuns []v1alpha3.PipelineRun,
+	isMultiBranch bool) (found *job.PipelineRun) {
+	submittedAt, ok := getSubmittedTime(pr)
+	if !ok {
+		return
+	}
+	branch := ""
+	if isMultiBranch && pr.Spec.SCM != nil {
+		branch = pr.Spec.SCM.RefName
+	}
+	earliest := submittedAt.Add(-submissionClockSkew)
+	finder := newPipelineRunFinder(pipelineRuns)
+	for i := range jobRuns {
+		jobRun := &jobRuns[i]
+		if isMultiBranch && jobRun.Pipeline != branch {
+			continue
+		}
+		if _, owned := finder.find(jobRun, isMultiBranch); owned {
+			continue
+		}
+		queuedAt := getQueuedTime(jobRun)
+		if queuedAt.Before(earliest) {
+			continue
+		}
+		if found == nil || queuedAt.Before(getQueuedTime(found)) {
+			found = jobRun
+		}
+	}
+	return
+}
+
+// getQueuedTime returns the time when the build entered the Jenkins queue
+func getQueuedTime(jobRun *job.PipelineRun) time.Time {
+	if jobRun.EnQueueTime.IsZero() {
+		return jobRun.StartTime.Time
+	}
+	return jobRun.EnQueueTime.Time
+}
+
+// recoverSubmission looks for the Jenkins build which was submitted before the controller restarted
+func (r *Reconciler) recoverSubmission(ctx context.Context, handler *jenkinsHandler, pr *v1alpha3.PipelineRun,
+	pipeline *v1alpha3.Pipeline) (*job.PipelineRun, error) {
+	jobRuns, err := handler.getPipelineRuns(pipeline.Namespace, pipeline.Name)
+	if err != nil {
+		return nil, err
+	}
+	pipelineRuns, err := r.listPipelineRuns(ctx, pipeline.Namespace, pipeline.Name)
+	if err != nil {
+		return nil, err
+	}
+	return findSubmittedBuild(pr, jobRuns, pipelineRuns.Items, pipeline.IsMultiBranch()), nil
+}
+
+// markStarted records the run ID of the submitted Jenkins build, then moves the PipelineRun to the running stage
+func (r *Reconciler) markStarted(ctx context.Context, pr *v1alpha3.PipelineRun, jobRun *job.PipelineRun) error {
+	if pr.Annotations == nil {
+		pr.Annotations = make(map[string]string)
+	}
+	pr.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = jobRun.ID
+	if _, err := moveToStage(pr, v1alpha3.ReconcileStageRunning, time.Now()); err != nil {
+		return err
+	}
+	// the Update method only updates fields except subresource: status
+	if err := r.updateLabelsAndAnnotations(ctx, pr); err != nil {
+		r.log.Error(err, "unable to update PipelineRun labels and annotations.")
+		return err
+	}
+
+	now := time.Now()
+	pr.Status.StartTime = &v1.Time{Time: now}
+	pr.Status.UpdateTime = &v1.Time{Time: now}
+	freezewindow.Thaw(&pr.Status, now)
+	resumePipelineRunStatus(&pr.Status, now)
+	scheduler.Dequeue(&pr.Status, now)
+	// due to the status is subresource of PipelineRun, we have to update status separately.
+	// see also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
+	if err := r.updateStatus(ctx, &pr.Status, client.ObjectKeyFromObject(pr)); err != nil {
+		r.log.Error(err, "unable to update PipelineRun status.")
+		return err
+	}
+	r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Started, "Started PipelineRun %s/%s", pr.Namespace, pr.Name)
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_moveToStage(t *testing.T) {
+	now := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	pr := &v1alpha3.PipelineRun{}
+	changed, err := moveToStage(pr, v1alpha3.ReconcileStageSubmitting, now)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, v1alpha3.ReconcileStageSubmitting, pr.GetReconcileStage())
+	assert.Equal(t, "2022-10-01T08:00:00Z", pr.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey])
+
+	// the time of the first submission is kept
+	changed, err = moveToStage(pr, v1alpha3.ReconcileStageSubmitting, now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "2022-10-01T08:00:00Z", pr.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey])
+
+	pr.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = "1"
+	changed, err = moveToStage(pr, v1alpha3.ReconcileStageRunning, now)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	// never move backward
+	_, err = moveToStage(pr, v1alpha3.ReconcileStageSubmitting, now)
+	assert.NotNil(t, err)
+
+	changed, err = moveToStage(pr, v1alpha3.ReconcileStageFinalizing, now)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	changed, err = moveToStage(pr, v1alpha3.ReconcileStageFinalizing, now)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	// the stage of a legacy PipelineRun is inferred from its run ID
+	legacy := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"},
+	}}
+	changed, err = moveToStage(legacy, v1alpha3.ReconcileStageRunning, now)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, string(v1alpha3.ReconcileStageRunning), legacy.Annotations[v1alpha3.PipelineRunReconcileStageAnnoKey])
+}
+
+func newJobRun(id, branch string, queuedAt time.Time) job.PipelineRun {
+	jobRun := job.PipelineRun{}
+	jobRun.ID = id
+	jobRun.Pipeline = branch
+	jobRun.EnQueueTime = job.Time{Time: queuedAt}
+	return jobRun
+}
+
+func Test_findSubmittedBuild(t *testing.T) {
+	submittedAt := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	newRun := func(name, branch, runID string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if branch != "" {
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		if runID != "" {
+			pr.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = runID
+		}
+		return pr
+	}
+	submitting := newRun("submitting", "", "")
+	submitting.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey] = submittedAt.Format(time.RFC3339)
+	submittingBranch := newRun("submitting", "main", "")
+	submittingBranch.Annotations[v1alpha3.PipelineRunSubmittedAtAnnoKey] = submittedAt.Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		pr            *v1alpha3.PipelineRun
+		jobRuns       []job.PipelineRun
+		pipelineRuns  []v1alpha3.PipelineRun
+		isMultiBranch bool
+		want          string
+	}{{
+		name:    "no submitted time",
+		pr:      newRun("pending", "", ""),
+		jobRuns: []job.PipelineRun{newJobRun("1", "pipeline", submittedAt)},
+	}, {
+		name:    "no builds",
+		pr:      submitting,
+		jobRuns: []job.PipelineRun{},
+	}, {
+		name:    "the earliest build after the submission",
+		pr:      submitting,
+		jobRuns: []job.PipelineRun{newJobRun("3", "pipeline", submittedAt.Add(2*time.Second)), newJobRun("2", "pipeline", submittedAt.Add(time.Second)), newJobRun("1", "pipeline", submittedAt.Add(-time.Hour))},
+		want:    "2",
+	}, {
+		name:         "the build is owned by another PipelineRun",
+		pr:           submitting,
+		jobRuns:      []job.PipelineRun{newJobRun("2", "pipeline", submittedAt.Add(2*time.Second)), newJobRun("1", "pipeline", submittedAt.Add(time.Second))},
+		pipelineRuns: []v1alpha3.PipelineRun{*newRun("other", "", "1")},
+		want:         "2",
+	}, {
+		name:    "tolerate the clock skew",
+		pr:      submitting,
+		jobRuns: []job.PipelineRun{newJobRun("1", "pipeline", submittedAt.Add(-10*time.Second))},
+		want:    "1",
+	}, {
+		name:          "the build of another branch",
+		pr:            submittingBranch,
+		jobRuns:       []job.PipelineRun{newJobRun("1", "dev", submittedAt.Add(time.Second))},
+		isMultiBranch: true,
+	}, {
+		name:          "the build of the same branch",
+		pr:            submittingBranch,
+		jobRuns:       []job.PipelineRun{newJobRun("1", "dev", submittedAt.Add(time.Second)), newJobRun("1", "main", submittedAt.Add(time.Second))},
+		pipelineRuns:  []v1alpha3.PipelineRun{*newRun("dev", "dev", "1")},
+		isMultiBranch: true,
+		want:          "1",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := findSubmittedBuild(tt.pr, tt.jobRuns, tt.pipelineRuns, tt.isMultiBranch)
+			if tt.want == "" {
+				assert.Nil(t, found)
+			} else if assert.NotNil(t, found) {
+				assert.Equal(t, tt.want, found.ID)
+			}
+		})
+	}
+}
+
+func TestReconciler_resumeSubmission(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	submittedAt := time.Now().Add(-time.Minute)
+	var jobRuns []job.PipelineRun
+	var submitted int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "crumbIssuer"):
+			// the crumb is disabled
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost:
+			submitted++
+			_, _ = w.Write([]byte(`{"id":"9"}`))
+		default:
+			_ = json.NewEncoder(w).Encode(jobRuns)
+		}
+	}))
+	defer server.Close()
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType},
+	}
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "run", Namespace: "ns",
+			Labels: map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{
+				v1alpha3.PipelineRunReconcileStageAnnoKey: string(v1alpha3.ReconcileStageSubmitting),
+				v1alpha3.PipelineRunSubmittedAtAnnoKey:    submittedAt.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}},
+	}
+
+	tests := []struct {
+		name          string
+		jobRuns       []job.PipelineRun
+		wantRunID     string
+		wantSubmitted int
+	}{{
+		name:          "adopt the build which was submitted before restarting",
+		jobRuns:       []job.PipelineRun{newJobRun("5", "pipeline", submittedAt.Add(time.Second))},
+		wantRunID:     "5",
+		wantSubmitted: 0,
+	}, {
+		name:          "submit again if the build was not submitted",
+		jobRuns:       []job.PipelineRun{newJobRun("4", "pipeline", submittedAt.Add(-time.Hour))},
+		wantRunID:     "9",
+		wantSubmitted: 1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobRuns = tt.jobRuns
+			submitted = 0
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline.DeepCopy(), pr.DeepCopy()).Build()
+			r := &Reconciler{
+				Client:      c,
+				JenkinsCore: core.JenkinsCore{URL: server.URL},
+				log:         logr.Discard(),
+				recorder:    &record.FakeRecorder{},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pr)})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantSubmitted, submitted)
+
+			result := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(pr), result))
+			runID, _ := result.GetPipelineRunID()
+			assert.Equal(t, tt.wantRunID, runID)
+			assert.Equal(t, v1alpha3.ReconcileStageRunning, result.GetReconcileStage())
+			assert.NotNil(t, result.Status.StartTime)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+)
+
+// stopGracePeriod is the period to wait for a stopping Jenkins build before sending a stronger signal.
+const stopGracePeriod = 30 * time.Second
+
+// buildStopSignal is the way to stop a Jenkins build.
+type buildStopSignal string
+
+const (
+	// stopSignal aborts a build, which is the same as clicking the abort button on Jenkins.
+	stopSignal buildStopSignal = "stop"
+	// termSignal forcibly terminates a build which does not respond to the stop signal.
+	termSignal buildStopSignal = "term"
+	// killSignal kills a build which does not respond to the term signal, without any cleanup.
+	killSignal buildStopSignal = "kill"
+)
+
+// Reasons of the Stopped condition.
+const (
+	waitingForNodesReason = "WaitingForNodes"
+	abortingReason        = "Aborting"
+	terminatingReason     = "Terminating"
+	killingReason         = "Killing"
+	dequeuingReason       = "Dequeuing"
+	abortedReason         = "Aborted"
+	cancelledReason       = "Cancelled"
+	completedReason       = "Completed"
+)
+
+// buildStopper is able to send stop signals to the Jenkins build of a PipelineRun.
+type buildStopper interface {
+	stopJenkinsBuild(pr *v1alpha3.PipelineRun, signal buildStopSignal) error
+	// cancelQueuedBuild takes the build which is not started yet out of the Jenkins queue
+	cancelQueuedBuild(queueID string) error
+}
+
+// stopHandler handles the stop action of a started PipelineRun.
+type stopHandler struct {
+	stopper buildStopper
+	now     time.Time
+}
+
+// handle sends the stop signals to Jenkins according to the action of the PipelineRun, and reflects the progress
+// into the Stopped condition of the status. The status must be applied with the build before.
+func (h stopHandler) handle(pr *v1alpha3.PipelineRun, status *v1alpha3.PipelineRunStatus, build *job.PipelineRun,
+	nodes []pipelinerun.NodeDetail) error {
+	if !pr.IsStopRequested() || build == nil {
+		return nil
+	}
+	stopped := getCondition(status, v1alpha3.ConditionStopped)
+	if build.State == Finished.String() {
+		h.whenBuildFinished(status, stopped, build.Result)
+		return nil
+	}
+	if build.State == Queued.String() && build.QueueID != "" {
+		// the build cannot be aborted before leaving the queue, it disappears once it's cancelled
+		if err := h.stopper.cancelQueuedBuild(build.QueueID); err != nil {
+			return fmt.Errorf("failed to cancel the queued build of Jenkins, error: %v", err)
+		}
+		h.setStoppedCondition(status, stopped, v1alpha3.ConditionUnknown, dequeuingReason,
+			fmt.Sprintf("cancelled the item %s of the Jenkins queue", build.QueueID))
+		return nil
+	}
+
+	if *pr.Spec.Action == v1alpha3.SoftStop && (stopped == nil || stopped.Reason == waitingForNodesReason) {
+		if runningNodes := h.getRunningNodes(pr, nodes); len(runningNodes) > 0 {
+			h.setStoppedCondition(status, stopped, v1alpha3.ConditionUnknown, waitingForNodesReason,
+				fmt.Sprintf("waiting for the running nodes to finish: %s", strings.Join(runningNodes, ", ")))
+			return nil
+		}
+	}
+
+	signal, reason := h.nextSignal(pr, stopped)
+	if signal == "" {
+		return nil
+	}
+	if err := h.stopper.stopJenkinsBuild(pr, signal); err != nil {
+		return fmt.Errorf("failed to send %s signal to Jenkins, error: %v", signal, err)
+	}
+	h.setStoppedCondition(status, stopped, v1alpha3.ConditionUnknown, reason,
+		fmt.Sprintf("sent %s signal to Jenkins", signal))
+	return nil
+}
+
+// getRunningNodes returns the running nodes that the PipelineRun has to wait for. The running nodes are recorded
+// into the annotations at the first time, and the nodes started afterwards will be ignored.
+func (h stopHandler) getRunningNodes(pr *v1alpha3.PipelineRun, nodes []pipelinerun.NodeDetail) []string {
+	nodeStates := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		nodeStates[node.ID] = node.State
+	}
+
+	recordedNodes, recorded := pr.Annotations[v1alpha3.PipelineRunSoftStopNodesAnnoKey]
+	if !recorded {
+		var ids []string
+		for _, node := range nodes {
+			if isNodeRunning(node.State) {
+				ids = append(ids, node.ID)
+			}
+		}
+		if pr.Annotations == nil {
+			pr.Annotations = map[string]string{}
+		}
+		recordedNodes = strings.Join(ids, ",")
+		pr.Annotations[v1alpha3.PipelineRunSoftStopNodesAnnoKey] = recordedNodes
+	}
+
+	var runningNodes []string
+	for _, id := range strings.Split(recordedNodes, ",") {
+		if id != "" && isNodeRunning(nodeStates[id]) {
+			runningNodes = append(runningNodes, id)
+		}
+	}
+	sort.Strings(runningNodes)
+	return runningNodes
+}
+
+// nextSignal returns the signal which should be sent to Jenkins according to the current Stopped condition.
+// Only the hard stop will be escalated to term and kill signal if the build is still running after the grace period.
+func (h stopHandler) nextSignal(pr *v1alpha3.PipelineRun, stopped *v1alpha3.Condition) (buildStopSignal, string) {
+	if stopped == nil || stopped.Reason == waitingForNodesReason {
+		return stopSignal, abortingReason
+	}
+	if *pr.Spec.Action != v1alpha3.Stop || h.now.Sub(stopped.LastTransitionTime.Time) < stopGracePeriod {
+		return "", ""
+	}
+	switch stopped.Reason {
+	case abortingReason:
+		return termSignal, terminatingReason
+	case terminatingReason:
+		return killSignal, killingReason
+	}
+	return "", ""
+}
+
+func (h stopHandler) whenBuildFinished(status *v1alpha3.PipelineRunStatus, stopped *v1alpha3.Condition, result string) {
+	if result == Aborted.String() {
+		status.Phase = v1alpha3.Cancelled
+		h.setStoppedCondition(status, stopped, v1alpha3.ConditionTrue, abortedReason, "the PipelineRun has been aborted")
+		return
+	}
+	h.setStoppedCondition(status, stopped, v1alpha3.ConditionFalse, completedReason,
+		fmt.Sprintf("the PipelineRun has completed with result %s before being stopped", result))
+}
+
+func (h stopHandler) setStoppedCondition(status *v1alpha3.PipelineRunStatus, stopped *v1alpha3.Condition,
+	conditionStatus v1alpha3.ConditionStatus, reason, message string) {
+	if stopped != nil && stopped.Status == conditionStatus && stopped.Reason == reason && stopped.Message == message {
+		return
+	}
+	now := v1.NewTime(h.now)
+	condition := v1alpha3.Condition{
+		Type:               v1alpha3.ConditionStopped,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	}
+	if stopped != nil && stopped.Reason == reason {
+		condition.LastTransitionTime = stopped.LastTransitionTime
+	}
+	status.AddCondition(&condition)
+}
+
+// cancelPipelineRunStatus marks the status as cancelled for the PipelineRun which was stopped before being triggered.
+func cancelPipelineRunStatus(status *v1alpha3.PipelineRunStatus, now time.Time) {
+	metaNow := v1.NewTime(now)
+	status.Phase = v1alpha3.Cancelled
+	status.CompletionTime = &metaNow
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSucceeded,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             cancelledReason,
+		Message:            "the PipelineRun was stopped before being triggered",
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionStopped,
+		Status:             v1alpha3.ConditionTrue,
+		Reason:             cancelledReason,
+		Message:            "the PipelineRun was stopped before being triggered",
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+}
+
+func getCondition(status *v1alpha3.PipelineRunStatus, conditionType v1alpha3.ConditionType) *v1alpha3.Condition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			condition := status.Conditions[i]
+			return &condition
+		}
+	}
+	return nil
+}
+
+func isNodeRunning(state string) bool {
+	return state == Running.String() || state == Queued.String() || state == Paused.String()
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+)
+
+type fakeBuildStopper struct {
+	signals   []buildStopSignal
+	cancelled []string
+	err       error
+}
+
+func (s *fakeBuildStopper) stopJenkinsBuild(_ *v1alpha3.PipelineRun, signal buildStopSignal) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.signals = append(s.signals, signal)
+	return nil
+}
+
+func (s *fakeBuildStopper) cancelQueuedBuild(queueID string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.cancelled = append(s.cancelled, queueID)
+	return nil
+}
+
+func Test_stopHandler_handle(t *testing.T) {
+	now := time.Now()
+	longAgo := metav1.NewTime(now.Add(-2 * stopGracePeriod))
+	hardStop := v1alpha3.Stop
+	softStop := v1alpha3.SoftStop
+	runningBuild := &job.PipelineRun{BlueItemRun: job.BlueItemRun{State: Running.String()}}
+	nodes := []pipelinerun.NodeDetail{{
+		Node: job.Node{ID: "1", State: Finished.String()},
+	}, {
+		Node: job.Node{ID: "2", State: Running.String()},
+	}}
+	stoppedCondition := func(reason string, transitionTime metav1.Time) []v1alpha3.Condition {
+		return []v1alpha3.Condition{{
+			Type:               v1alpha3.ConditionStopped,
+			Status:             v1alpha3.ConditionUnknown,
+			Reason:             reason,
+			LastTransitionTime: transitionTime,
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		action          *v1alpha3.Action
+		annotations     map[string]string
+		conditions      []v1alpha3.Condition
+		build           *job.PipelineRun
+		nodes           []pipelinerun.NodeDetail
+		stopErr         error
+		wantErr         bool
+		wantSignals     []buildStopSignal
+		wantCancelled   []string
+		wantReason      string
+		wantStatus      v1alpha3.ConditionStatus
+		wantPhase       v1alpha3.RunPhase
+		wantAnnotations map[string]string
+	}{{
+		name:  "no action",
+		build: runningBuild,
+	}, {
+		name:        "hard stop",
+		action:      &hardStop,
+		build:       runningBuild,
+		wantSignals: []buildStopSignal{stopSignal},
+		wantReason:  abortingReason,
+		wantStatus:  v1alpha3.ConditionUnknown,
+	}, {
+		name:       "hard stop within the grace period",
+		action:     &hardStop,
+		conditions: stoppedCondition(abortingReason, metav1.NewTime(now)),
+		build:      runningBuild,
+		wantReason: abortingReason,
+		wantStatus: v1alpha3.ConditionUnknown,
+	}, {
+		name:        "escalate to term signal",
+		action:      &hardStop,
+		conditions:  stoppedCondition(abortingReason, longAgo),
+		build:       runningBuild,
+		wantSignals: []buildStopSignal{termSignal},
+		wantReason:  terminatingReason,
+		wantStatus:  v1alpha3.ConditionUnknown,
+	}, {
+		name:        "escalate to kill signal",
+		action:      &hardStop,
+		conditions:  stoppedCondition(terminatingReason, longAgo),
+		build:       runningBuild,
+		wantSignals: []buildStopSignal{killSignal},
+		wantReason:  killingReason,
+		wantStatus:  v1alpha3.ConditionUnknown,
+	}, {
+		name:            "soft stop waits for the running nodes",
+		action:          &softStop,
+		build:           runningBuild,
+		nodes:           nodes,
+		wantReason:      waitingForNodesReason,
+		wantStatus:      v1alpha3.ConditionUnknown,
+		wantAnnotations: map[string]string{v1alpha3.PipelineRunSoftStopNodesAnnoKey: "2"},
+	}, {
+		name:        "soft stop after the recorded nodes finished",
+		action:      &softStop,
+		annotations: map[string]string{v1alpha3.PipelineRunSoftStopNodesAnnoKey: "1"},
+		conditions:  stoppedCondition(waitingForNodesReason, longAgo),
+		build:       runningBuild,
+		nodes:       nodes,
+		wantSignals: []buildStopSignal{stopSignal},
+		wantReason:  abortingReason,
+		wantStatus:  v1alpha3.ConditionUnknown,
+	}, {
+		name:       "soft stop does not escalate",
+		action:     &softStop,
+		conditions: stoppedCondition(abortingReason, longAgo),
+		build:      runningBuild,
+		nodes:      nodes,
+		wantReason: abortingReason,
+		wantStatus: v1alpha3.ConditionUnknown,
+	}, {
+		name:        "soft stop without running nodes",
+		action:      &softStop,
+		build:       runningBuild,
+		wantSignals: []buildStopSignal{stopSignal},
+		wantReason:  abortingReason,
+		wantStatus:  v1alpha3.ConditionUnknown,
+		wantAnnotations: map[string]string{
+			v1alpha3.PipelineRunSoftStopNodesAnnoKey: "",
+		},
+	}, {
+		name:       "aborted",
+		action:     &hardStop,
+		conditions: stoppedCondition(abortingReason, longAgo),
+		build:      &job.PipelineRun{BlueItemRun: job.BlueItemRun{State: Finished.String(), Result: Aborted.String()}},
+		wantReason: abortedReason,
+		wantStatus: v1alpha3.ConditionTrue,
+		wantPhase:  v1alpha3.Cancelled,
+	}, {
+		name:       "completed before being stopped",
+		action:     &softStop,
+		conditions: stoppedCondition(waitingForNodesReason, longAgo),
+		build:      &job.PipelineRun{BlueItemRun: job.BlueItemRun{State: Finished.String(), Result: Success.String()}},
+		wantReason: completedReason,
+		wantStatus: v1alpha3.ConditionFalse,
+	}, {
+		name:          "cancel the queued build",
+		action:        &hardStop,
+		build:         &job.PipelineRun{BlueItemRun: job.BlueItemRun{State: Queued.String()}, QueueID: "12"},
+		wantCancelled: []string{"12"},
+		wantReason:    dequeuingReason,
+		wantStatus:    v1alpha3.ConditionUnknown,
+	}, {
+		name:    "failed to cancel the queued build",
+		action:  &softStop,
+		build:   &job.PipelineRun{BlueItemRun: job.BlueItemRun{State: Queued.String()}, QueueID: "12"},
+		stopErr: errors.New("fake"),
+		wantErr: true,
+	}, {
+		name:    "failed to stop",
+		action:  &hardStop,
+		build:   runningBuild,
+		stopErr: errors.New("fake"),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &v1alpha3.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       v1alpha3.PipelineRunSpec{Action: tt.action},
+			}
+			status := &v1alpha3.PipelineRunStatus{Conditions: tt.conditions}
+			stopper := &fakeBuildStopper{err: tt.stopErr}
+			handler := stopHandler{stopper: stopper, now: now}
+
+			err := handler.handle(pr, status, tt.build, tt.nodes)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantSignals, stopper.signals)
+			assert.Equal(t, tt.wantCancelled, stopper.cancelled)
+			assert.Equal(t, tt.wantPhase, status.Phase)
+			if tt.wantAnnotations != nil {
+				assert.Equal(t, tt.wantAnnotations, pr.Annotations)
+			}
+			stopped := getCondition(status, v1alpha3.ConditionStopped)
+			if tt.wantReason == "" {
+				assert.Nil(t, stopped)
+				return
+			}
+			if assert.NotNil(t, stopped) {
+				assert.Equal(t, tt.wantReason, stopped.Reason)
+				assert.Equal(t, tt.wantStatus, stopped.Status)
+			}
+		})
+	}
+}
+
+func Test_cancelPipelineRunStatus(t *testing.T) {
+	status := &v1alpha3.PipelineRunStatus{}
+	cancelPipelineRunStatus(status, time.Now())
+	assert.Equal(t, v1alpha3.Cancelled, status.Phase)
+	assert.False(t, status.CompletionTime.IsZero())
+	succeeded := getCondition(status, v1alpha3.ConditionSucceeded)
+	if assert.NotNil(t, succeeded) {
+		assert.Equal(t, v1alpha3.ConditionFalse, succeeded.Status)
+	}
+	stopped := getCondition(status, v1alpha3.ConditionStopped)
+	if assert.NotNil(t, stopped) {
+		assert.Equal(t, v1alpha3.ConditionTrue, stopped.Status)
+		assert.Equal(t, cancelledReason, stopped.Reason)
+	}
+}
+
+func Test_jenkinsHandler_stopJenkinsBuild(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			requestedPath = r.URL.Path
+		} else {
+			// the crumb is disabled
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler := &jenkinsHandler{&core.JenkinsCore{URL: server.URL}}
+
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "3"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef: &v1.ObjectReference{Name: "pipeline"},
+			SCM:         &v1alpha3.SCM{RefName: "main"},
+		},
+	}
+	assert.Nil(t, handler.stopJenkinsBuild(pr, termSignal))
+	assert.Equal(t, "/job/ns/job/pipeline/job/main/3/term", requestedPath)
+
+	delete(pr.Annotations, v1alpha3.JenkinsPipelineRunIDAnnoKey)
+	assert.NotNil(t, handler.stopJenkinsBuild(pr, stopSignal))
+}
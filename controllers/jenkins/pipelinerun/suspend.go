@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	heldReason    = "Held"
+	resumedReason = "Resumed"
+)
+
+// suspendPipelineRunStatus holds or cancels the PipelineRun which isn't triggered yet, because its Pipeline is
+// suspended. It returns true if the status was changed.
+func suspendPipelineRunStatus(status *v1alpha3.PipelineRunStatus, pipeline *v1alpha3.Pipeline, now time.Time) bool {
+	metaNow := v1.NewTime(now)
+	if pipeline.GetSuspendPolicy() == v1alpha3.SuspendPolicyCancel {
+		message := fmt.Sprintf("the PipelineRun was cancelled because Pipeline %s is suspended", pipeline.Name)
+		status.Phase = v1alpha3.Cancelled
+		status.CompletionTime = &metaNow
+		status.UpdateTime = &metaNow
+		status.AddCondition(&v1alpha3.Condition{
+			Type:          v1alpha3.ConditionSucceeded,
+			Status:        v1alpha3.ConditionFalse,
+			Reason:        v1alpha3.Suspended,
+			Message:       message,
+			LastProbeTime: metaNow,
+		})
+		status.AddCondition(&v1alpha3.Condition{
+			Type:          v1alpha3.ConditionSuspended,
+			Status:        v1alpha3.ConditionTrue,
+			Reason:        cancelledReason,
+			Message:       message,
+			LastProbeTime: metaNow,
+		})
+		return true
+	}
+
+	if suspended := getCondition(status, v1alpha3.ConditionSuspended); suspended != nil && suspended.Status == v1alpha3.ConditionTrue {
+		return false
+	}
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSuspended,
+		Status:             v1alpha3.ConditionTrue,
+		Reason:             heldReason,
+		Message:            fmt.Sprintf("the PipelineRun is held until Pipeline %s is resumed", pipeline.Name),
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+	return true
+}
+
+// resumePipelineRunStatus marks the held PipelineRun as resumed
+func resumePipelineRunStatus(status *v1alpha3.PipelineRunStatus, now time.Time) {
+	if suspended := getCondition(status, v1alpha3.ConditionSuspended); suspended == nil || suspended.Status != v1alpha3.ConditionTrue {
+		return
+	}
+	metaNow := v1.NewTime(now)
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSuspended,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             resumedReason,
+		Message:            "the Pipeline has been resumed",
+		LastProbeTime:      metaNow,
+		LastTransitionTime: metaNow,
+	})
+}
+
+// findHeldPipelineRuns returns the held PipelineRuns of a resumed Pipeline
+func (r *Reconciler) findHeldPipelineRuns(object client.Object) (requests []reconcile.Request) {
+	pipeline, ok := object.(*v1alpha3.Pipeline)
+	if !ok || pipeline.IsSuspended() {
+		return
+	}
+
+	runList, err := r.listPipelineRuns(context.Background(), pipeline.Namespace, pipeline.Name)
+	if err != nil {
+		r.log.Error(err, "unable to list the PipelineRuns", "Pipeline", client.ObjectKeyFromObject(pipeline))
+		return
+	}
+	for i := range runList.Items {
+		item := &runList.Items[i]
+		if item.Spec.PipelineRef == nil || item.Spec.PipelineRef.Name != pipeline.Name || item.HasStarted() || item.HasCompleted() {
+			continue
+		}
+		if suspended := getCondition(&item.Status, v1alpha3.ConditionSuspended); suspended != nil && suspended.Status == v1alpha3.ConditionTrue {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(item)})
+		}
+	}
+	return
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_suspendPipelineRunStatus(t *testing.T) {
+	now := time.Now()
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{Suspend: true},
+	}
+
+	t.Run("cancel by default", func(t *testing.T) {
+		status := &v1alpha3.PipelineRunStatus{}
+		assert.True(t, suspendPipelineRunStatus(status, pipeline, now))
+		assert.Equal(t, v1alpha3.Cancelled, status.Phase)
+		assert.NotNil(t, status.CompletionTime)
+		succeeded := getCondition(status, v1alpha3.ConditionSucceeded)
+		if assert.NotNil(t, succeeded) {
+			assert.Equal(t, v1alpha3.ConditionFalse, succeeded.Status)
+			assert.Equal(t, v1alpha3.Suspended, succeeded.Reason)
+		}
+	})
+
+	t.Run("hold until resumed", func(t *testing.T) {
+		holdPipeline := pipeline.DeepCopy()
+		holdPipeline.Spec.SuspendPolicy = v1alpha3.SuspendPolicyHold
+		status := &v1alpha3.PipelineRunStatus{}
+		assert.True(t, suspendPipelineRunStatus(status, holdPipeline, now))
+		assert.Empty(t, status.Phase)
+		assert.Nil(t, status.CompletionTime)
+		suspended := getCondition(status, v1alpha3.ConditionSuspended)
+		if assert.NotNil(t, suspended) {
+			assert.Equal(t, v1alpha3.ConditionTrue, suspended.Status)
+			assert.Equal(t, heldReason, suspended.Reason)
+		}
+		// already held
+		assert.False(t, suspendPipelineRunStatus(status, holdPipeline, now.Add(time.Minute)))
+
+		resumePipelineRunStatus(status, now.Add(time.Hour))
+		suspended = getCondition(status, v1alpha3.ConditionSuspended)
+		if assert.NotNil(t, suspended) {
+			assert.Equal(t, v1alpha3.ConditionFalse, suspended.Status)
+			assert.Equal(t, resumedReason, suspended.Reason)
+		}
+	})
+
+	t.Run("resume a PipelineRun which is not held", func(t *testing.T) {
+		status := &v1alpha3.PipelineRunStatus{}
+		resumePipelineRunStatus(status, now)
+		assert.Empty(t, status.Conditions)
+	})
+}
+
+func TestReconciler_findHeldPipelineRuns(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newRun := func(name, pipeline string, held, started bool) *v1alpha3.PipelineRun {
+		run := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: pipeline}},
+		}
+		if held {
+			run.Status.AddCondition(&v1alpha3.Condition{Type: v1alpha3.ConditionSuspended, Status: v1alpha3.ConditionTrue})
+		}
+		if started {
+			run.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}
+		}
+		return run
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		newRun("held", "pipeline", true, false),
+		newRun("pending", "pipeline", false, false),
+		newRun("started", "pipeline", true, true),
+		newRun("another", "another", true, false),
+	).Build()
+	r := &Reconciler{Client: c, log: logr.Discard()}
+
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "ns"}}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: "ns", Name: "held"}}},
+		r.findHeldPipelineRuns(pipeline))
+
+	// still suspended
+	pipeline.Spec.Suspend = true
+	assert.Empty(t, r.findHeldPipelineRuns(pipeline))
+	assert.Empty(t, r.findHeldPipelineRuns(&v1alpha3.PipelineRun{}))
+}
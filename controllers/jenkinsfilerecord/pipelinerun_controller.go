@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsfilerecord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconciler records the Jenkinsfile which the PipelineRuns ran
+type Reconciler struct {
+	client.Client
+	// SCMClientFactory creates the clients to load the Jenkinsfile from the SCM, it's built from the Client if it's nil
+	SCMClientFactory git.SCMClientFactory
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile stores the Jenkinsfile of a started PipelineRun into a ConfigMap, then marks its digest and revision
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.HasStarted() || jenkinsfile.IsRecorded(pipelineRun) || pipelineRun.Spec.PipelineSpec == nil {
+		return
+	}
+
+	var content, revision string
+	pipelineSpec := pipelineRun.Spec.PipelineSpec
+	if pipelineRun.Spec.IsMultiBranchPipeline() {
+		// the revision is known once the PipelineRun checked out the SCM
+		if revision = jenkinsfile.GetRevision(pipelineRun); revision == "" {
+			return
+		}
+		if pipelineSpec.MultiBranchPipeline != nil {
+			content, err = jenkinsfile.Load(ctx, r.SCMClientFactory, pipelineRun.Namespace, pipelineSpec.MultiBranchPipeline, revision)
+			if err == git.ErrUnsupportedSource {
+				// record the revision only, the Jenkinsfile can be found in the repository by it
+				err = nil
+			} else if err != nil {
+				r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "JenkinsfileFailed", "failed to load the Jenkinsfile, error: %v", err)
+				return
+			}
+		}
+	} else if pipelineSpec.Pipeline != nil {
+		content = pipelineSpec.Pipeline.Jenkinsfile
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	if content != "" {
+		cm := &v1.ConfigMap{Data: map[string]string{jenkinsfile.ConfigMapKeyJenkinsfile: content}}
+		cm.Namespace = pipelineRun.Namespace
+		cm.Name = jenkinsfile.GetConfigMapName(pipelineRun)
+		if err = controllerutil.SetControllerReference(pipelineRun, cm, r.Scheme()); err != nil {
+			return
+		}
+		if err = r.Create(ctx, cm); apierrors.IsAlreadyExists(err) {
+			err = r.Update(ctx, cm)
+		}
+		if err != nil {
+			return
+		}
+		pipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey] = jenkinsfile.Digest(content)
+	}
+	if revision != "" {
+		pipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey] = revision
+	}
+	if !jenkinsfile.IsRecorded(pipelineRun) {
+		return
+	}
+	err = r.Patch(ctx, pipelineRun, patch)
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "jenkinsfilerecord-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.SCMClientFactory == nil {
+		r.SCMClientFactory = git.NewSCMClientFactory(r.Client)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
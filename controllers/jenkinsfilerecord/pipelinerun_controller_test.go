@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsfilerecord
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "abc" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 File Not Found"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"file_path":"Jenkinsfile","content":"%s"}`,
+			base64.StdEncoding.EncodeToString([]byte("pipeline {}")))
+	}))
+	defer server.Close()
+
+	newPipelineRun := func(pipelineSpec *v1alpha3.PipelineSpec, annotations map[string]string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+				Annotations: annotations,
+			},
+			Spec: v1alpha3.PipelineRunSpec{PipelineSpec: pipelineSpec},
+		}
+	}
+	noScmSpec := &v1alpha3.PipelineSpec{
+		Type:     v1alpha3.NoScmPipelineType,
+		Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: "pipeline {}"},
+	}
+	newMultiBranchSpec := func(sourceType string) *v1alpha3.PipelineSpec {
+		return &v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+				SourceType:   sourceType,
+				GitlabSource: &v1alpha3.GitlabSource{Owner: "group", Repo: "app"},
+			},
+		}
+	}
+	started := func(revision string) map[string]string {
+		annotations := map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}
+		if revision != "" {
+			annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey] = fmt.Sprintf(`{"id":"1","commitId":"%s"}`, revision)
+		}
+		return annotations
+	}
+	getPipelineRun := func(t *testing.T, c client.Client) *v1alpha3.PipelineRun {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun))
+		return pipelineRun
+	}
+	verifyRecorded := func(revision string) func(t *testing.T, c client.Client) {
+		return func(t *testing.T, c client.Client) {
+			cm := &v1.ConfigMap{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-jenkinsfile"}, cm))
+			assert.Equal(t, "pipeline {}", cm.Data[jenkinsfile.ConfigMapKeyJenkinsfile])
+			pipelineRun := getPipelineRun(t, c)
+			assert.Equal(t, jenkinsfile.Digest("pipeline {}"), pipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey])
+			assert.Equal(t, revision, pipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey])
+		}
+	}
+	verifyNotRecorded := func(t *testing.T, c client.Client) {
+		cm := &v1.ConfigMap{}
+		assert.NotNil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-jenkinsfile"}, cm))
+		assert.False(t, jenkinsfile.IsRecorded(getPipelineRun(t, c)))
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		wantErr     bool
+		verify      func(t *testing.T, c client.Client)
+	}{{
+		name:        "the PipelineRun has not started",
+		pipelineRun: newPipelineRun(noScmSpec, nil),
+		verify:      verifyNotRecorded,
+	}, {
+		name:        "Pipeline without SCM",
+		pipelineRun: newPipelineRun(noScmSpec, started("")),
+		verify:      verifyRecorded(""),
+	}, {
+		name:        "multi-branch Pipeline has not checked out the SCM",
+		pipelineRun: newPipelineRun(newMultiBranchSpec(v1alpha3.SourceTypeGitlab), started("")),
+		verify:      verifyNotRecorded,
+	}, {
+		name:        "multi-branch Pipeline",
+		pipelineRun: newPipelineRun(newMultiBranchSpec(v1alpha3.SourceTypeGitlab), started("abc")),
+		verify:      verifyRecorded("abc"),
+	}, {
+		name:        "multi-branch Pipeline with an unsupported source",
+		pipelineRun: newPipelineRun(newMultiBranchSpec(v1alpha3.SourceTypeSVN), started("abc")),
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := getPipelineRun(t, c)
+			assert.Equal(t, "abc", pipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey])
+			assert.NotContains(t, pipelineRun.Annotations, v1alpha3.PipelineRunJenkinsfileDigestAnnoKey)
+		},
+	}, {
+		name:        "failed to load the Jenkinsfile",
+		pipelineRun: newPipelineRun(newMultiBranchSpec(v1alpha3.SourceTypeGitlab), started("def")),
+		wantErr:     true,
+		verify:      verifyNotRecorded,
+	}, {
+		name: "the Jenkinsfile was recorded",
+		pipelineRun: newPipelineRun(noScmSpec, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1",
+			v1alpha3.PipelineRunJenkinsfileDigestAnnoKey: "digest"}),
+		verify: func(t *testing.T, c client.Client) {
+			cm := &v1.ConfigMap{}
+			assert.NotNil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-jenkinsfile"}, cm))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun).Build()
+			r := &Reconciler{
+				Client: c,
+				SCMClientFactory: func(string, string, *v1.SecretReference) (*scm.Client, error) {
+					return gitlab.New(server.URL)
+				},
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{Events: make(chan string, 10)},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			tt.verify(t, c)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	jiraclient "kubesphere.io/devops/pkg/client/jira"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/issue"
+	"kubesphere.io/devops/pkg/models/release"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// ClientFactory creates the clients of Jira
+type ClientFactory func(server, username, token string) jiraclient.Interface
+
+// Reconciler links the PipelineRuns to the Jira issues, and comments their results on the issues
+type Reconciler struct {
+	client.Client
+	// SCMClientFactory creates the clients to get the commit messages, it's built from the Client if it's nil
+	SCMClientFactory git.SCMClientFactory
+	// JiraClientFactory creates the clients of Jira, it's jira.New if it's nil
+	JiraClientFactory ClientFactory
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile parses the issue keys from the branch and the commit message of a PipelineRun once it checked out the SCM,
+// then comments the result on the issues once it completes. It only works if Jira is configured in the DevOpsProject.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	var integration *v1alpha3.JiraIntegration
+	if integration, err = r.getIntegration(ctx, pipelineRun.Namespace); err != nil || integration == nil {
+		return
+	}
+
+	if _, linked := pipelineRun.Annotations[v1alpha3.PipelineRunIssuesAnnoKey]; !linked {
+		// the revision is known once the PipelineRun checked out the SCM
+		revision := release.GetRevision(pipelineRun)
+		if revision == "" && !pipelineRun.HasCompleted() {
+			return
+		}
+		var keys []string
+		if keys, err = r.parseKeys(ctx, pipelineRun, integration, revision); err != nil {
+			return
+		}
+		if err = r.annotate(ctx, pipelineRun, v1alpha3.PipelineRunIssuesAnnoKey, keys); err != nil {
+			return
+		}
+	}
+
+	keys := issue.GetKeys(pipelineRun)
+	if !pipelineRun.HasCompleted() || len(keys) == 0 {
+		return
+	}
+	reported := issue.GetReported(pipelineRun)
+	var pending []string
+	for _, key := range keys {
+		if !contains(reported, key) {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	var jiraClient jiraclient.Interface
+	if jiraClient, err = r.newJiraClient(ctx, pipelineRun.Namespace, integration); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, v1alpha3.IssueUpdateFailed,
+			"failed to create the client of Jira, error: %v", err)
+		return
+	}
+	comment := issue.Comment(pipelineRun, release.GetRevision(pipelineRun))
+	for _, key := range pending {
+		if err = jiraClient.AddComment(ctx, key, comment); err == jiraclient.ErrIssueNotFound {
+			// the key might not be an issue, such as UTF-8
+			r.log.V(4).Info("skip the issue which is not found", "issue", key, "PipelineRun", req.String())
+			err = nil
+		} else if err != nil {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, v1alpha3.IssueUpdateFailed,
+				"failed to comment on the issue %s, error: %v", key, err)
+			break
+		}
+		reported = append(reported, key)
+	}
+	// the reported issues are recorded even if some of them failed, so they are not commented again
+	if annotateErr := r.annotate(ctx, pipelineRun, v1alpha3.PipelineRunIssuesReportedAnnoKey, reported); err == nil {
+		err = annotateErr
+	}
+	return
+}
+
+// getIntegration returns the Jira integration of the DevOpsProject which the namespace belongs to
+func (r *Reconciler) getIntegration(ctx context.Context, namespace string) (*v1alpha3.JiraIntegration, error) {
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return nil, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return project.Spec.Jira, nil
+}
+
+// parseKeys returns the issue keys in the branch name and the message of the revision
+func (r *Reconciler) parseKeys(ctx context.Context, pipelineRun *v1alpha3.PipelineRun,
+	integration *v1alpha3.JiraIntegration, revision string) (keys []string, err error) {
+	var texts []string
+	if pipelineRun.Spec.SCM != nil {
+		texts = append(texts, pipelineRun.Spec.SCM.RefName)
+	}
+	if revision != "" && pipelineRun.Spec.IsMultiBranchPipeline() && pipelineRun.Spec.PipelineSpec.MultiBranchPipeline != nil {
+		var message string
+		if message, err = r.getCommitMessage(ctx, pipelineRun, revision); err == git.ErrUnsupportedSource {
+			err = nil
+		} else if err != nil {
+			return
+		}
+		texts = append(texts, message)
+	}
+	keys = issue.ParseKeys(integration.ProjectKeys, texts...)
+	return
+}
+
+// getCommitMessage returns the message of the revision from the SCM with the credential of the Pipeline
+func (r *Reconciler) getCommitMessage(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, revision string) (
+	message string, err error) {
+	pipeline := pipelineRun.Spec.PipelineSpec.MultiBranchPipeline
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(pipeline); err != nil {
+		return
+	}
+	var secretRef *v1.SecretReference
+	if credentialID := pipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: pipelineRun.Namespace, Name: credentialID}
+	}
+	var scmClient *scm.Client
+	if scmClient, err = r.SCMClientFactory(provider, server, secretRef); err != nil {
+		return
+	}
+	var commit *scm.Commit
+	if commit, _, err = scmClient.Git.FindCommit(ctx, repo, revision); err == nil {
+		message = commit.Message
+	}
+	return
+}
+
+// newJiraClient creates the client of Jira with the basic-auth credential in the DevOpsProject
+func (r *Reconciler) newJiraClient(ctx context.Context, namespace string, integration *v1alpha3.JiraIntegration) (
+	jiraclient.Interface, error) {
+	secret := &v1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: integration.CredentialID}, secret); err != nil {
+		return nil, err
+	}
+	return r.JiraClientFactory(integration.Server, string(secret.Data[v1alpha3.BasicAuthUsernameKey]),
+		string(secret.Data[v1alpha3.BasicAuthPasswordKey])), nil
+}
+
+func (r *Reconciler) annotate(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, key string, values []string) error {
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = make(map[string]string)
+	}
+	pipelineRun.Annotations[key] = strings.Join(values, ",")
+	return client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch))
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "jira-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.SCMClientFactory == nil {
+		r.SCMClientFactory = git.NewSCMClientFactory(r.Client)
+	}
+	if r.JiraClientFactory == nil {
+		r.JiraClientFactory = jiraclient.New
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
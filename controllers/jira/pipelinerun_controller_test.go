@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	jiraclient "kubesphere.io/devops/pkg/client/jira"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeJira struct {
+	username, token string
+	comments        map[string]string
+	failed          map[string]bool
+}
+
+func (f *fakeJira) AddComment(_ context.Context, issueKey, body string) error {
+	if issueKey == "DEVOPS-404" {
+		return jiraclient.ErrIssueNotFound
+	}
+	if f.failed[issueKey] {
+		return errors.New("fake error")
+	}
+	f.comments[issueKey] = body
+	return nil
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/app/commits/c1":
+			_, _ = w.Write([]byte(`{"sha":"c1","commit":{"message":"DEVOPS-2 Fix the login\n\nSee also OPS-1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "project"},
+	}}
+	newProject := func(integration *v1alpha3.JiraIntegration) *v1alpha3.DevOpsProject {
+		return &v1alpha3.DevOpsProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "project"},
+			Spec:       v1alpha3.DevOpsProjectSpec{Jira: integration},
+		}
+	}
+	integration := &v1alpha3.JiraIntegration{Server: "https://jira.example.com", CredentialID: "jira",
+		ProjectKeys: []string{"DEVOPS"}}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "jira"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+		Data:       map[string][]byte{v1alpha3.BasicAuthUsernameKey: []byte("admin"), v1alpha3.BasicAuthPasswordKey: []byte("token")},
+	}
+	newPipelineRun := func(revision string, completed bool, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+				Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				SCM: &v1alpha3.SCM{RefName: "feature/DEVOPS-1-login"},
+				PipelineSpec: &v1alpha3.PipelineSpec{
+					Type: v1alpha3.MultiBranchPipelineType,
+					MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+						SourceType:   v1alpha3.SourceTypeGithub,
+						GitHubSource: &v1alpha3.GithubSource{Owner: "org", Repo: "app"},
+					},
+				},
+			},
+		}
+		if revision != "" {
+			pr.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey] = revision
+		}
+		if completed {
+			now := metav1.Now()
+			pr.Status.Phase = v1alpha3.Succeeded
+			pr.Status.CompletionTime = &now
+		}
+		for key, value := range annotations {
+			pr.Annotations[key] = value
+		}
+		return pr
+	}
+
+	tests := []struct {
+		name         string
+		pipelineRun  *v1alpha3.PipelineRun
+		integration  *v1alpha3.JiraIntegration
+		failed       map[string]bool
+		wantErr      bool
+		wantIssues   *string
+		wantReported string
+		wantComments []string
+	}{{
+		name:        "Jira is not configured",
+		pipelineRun: newPipelineRun("c1", true, nil),
+	}, {
+		name:        "the SCM is not checked out",
+		pipelineRun: newPipelineRun("", false, nil),
+		integration: integration,
+	}, {
+		name:        "link the running PipelineRun",
+		pipelineRun: newPipelineRun("c1", false, nil),
+		integration: integration,
+		wantIssues:  stringPtr("DEVOPS-1,DEVOPS-2"),
+	}, {
+		name:         "comment on the linked issues once completed",
+		pipelineRun:  newPipelineRun("c1", true, nil),
+		integration:  integration,
+		wantIssues:   stringPtr("DEVOPS-1,DEVOPS-2"),
+		wantReported: "DEVOPS-1,DEVOPS-2",
+		wantComments: []string{"DEVOPS-1", "DEVOPS-2"},
+	}, {
+		name: "skip the reported issues and the issues not found",
+		pipelineRun: newPipelineRun("c1", true, map[string]string{
+			v1alpha3.PipelineRunIssuesAnnoKey:         "DEVOPS-1,DEVOPS-3,DEVOPS-404",
+			v1alpha3.PipelineRunIssuesReportedAnnoKey: "DEVOPS-1",
+		}),
+		integration:  integration,
+		wantIssues:   stringPtr("DEVOPS-1,DEVOPS-3,DEVOPS-404"),
+		wantReported: "DEVOPS-1,DEVOPS-3,DEVOPS-404",
+		wantComments: []string{"DEVOPS-3"},
+	}, {
+		name: "failed to comment",
+		pipelineRun: newPipelineRun("c1", true, map[string]string{
+			v1alpha3.PipelineRunIssuesAnnoKey: "DEVOPS-1,DEVOPS-2",
+		}),
+		integration:  integration,
+		failed:       map[string]bool{"DEVOPS-2": true},
+		wantErr:      true,
+		wantIssues:   stringPtr("DEVOPS-1,DEVOPS-2"),
+		wantReported: "DEVOPS-1",
+		wantComments: []string{"DEVOPS-1"},
+	}, {
+		name:        "no issues",
+		pipelineRun: newPipelineRun("fake", true, nil),
+		integration: integration,
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).
+				WithObjects(tt.pipelineRun, ns.DeepCopy(), newProject(tt.integration), secret.DeepCopy()).Build()
+			jira := &fakeJira{comments: map[string]string{}, failed: tt.failed}
+			r := &Reconciler{
+				Client: c,
+				SCMClientFactory: func(string, string, *v1.SecretReference) (*scm.Client, error) {
+					return github.New(server.URL)
+				},
+				JiraClientFactory: func(server, username, token string) jiraclient.Interface {
+					assert.Equal(t, "https://jira.example.com", server)
+					jira.username, jira.token = username, token
+					return jira
+				},
+				log:      logr.Discard(),
+				recorder: record.NewFakeRecorder(10),
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: "pr"}, pipelineRun))
+			issues, linked := pipelineRun.Annotations[v1alpha3.PipelineRunIssuesAnnoKey]
+			if tt.wantIssues == nil {
+				assert.False(t, linked)
+			} else {
+				assert.Equal(t, *tt.wantIssues, issues)
+			}
+			assert.Equal(t, tt.wantReported, pipelineRun.Annotations[v1alpha3.PipelineRunIssuesReportedAnnoKey])
+			var commented []string
+			for _, key := range []string{"DEVOPS-1", "DEVOPS-2", "DEVOPS-3"} {
+				if comment, ok := jira.comments[key]; ok {
+					commented = append(commented, key)
+					assert.Contains(t, comment, "*Succeeded*")
+					assert.Equal(t, "admin", jira.username)
+					assert.Equal(t, "token", jira.token)
+				}
+			}
+			assert.Equal(t, tt.wantComments, commented)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldapgroup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/ldap"
+	"kubesphere.io/devops/pkg/models/member"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=bind
+
+// Reconciler binds the members of LDAP or Active Directory groups to the roles of DevOpsProjects periodically,
+// the groups are mapped by the annotation devops.kubesphere.io/ldap-groups of DevOpsProject
+type Reconciler struct {
+	client.Client
+	LDAP ldap.Interface
+	// SyncPeriod is the period of synchronizing the group members of a DevOpsProject
+	SyncPeriod time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile makes the RoleBindings of a DevOpsProject consistent with the members of its LDAP groups
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" {
+		// the namespace is not ready, the status change will trigger it again
+		return
+	}
+
+	var desired []*rbacv1.RoleBinding
+	groupRoles := ParseGroupRoles(project.Annotations[v1alpha3.DevOpsProjectLDAPGroupsAnnoKey])
+	if len(groupRoles) > 0 && project.DeletionTimestamp.IsZero() {
+		result.RequeueAfter = r.SyncPeriod
+		if desired, err = r.getDesiredRoleBindings(ctx, project, namespace, groupRoles); err != nil {
+			r.recorder.Eventf(project, v1.EventTypeWarning, "LDAPSyncFailed",
+				"failed to get the members of LDAP groups, error: %v", err)
+			return
+		}
+	}
+
+	var created, revoked []string
+	created, revoked, err = member.SyncRoleBindings(ctx, r.Client, namespace, v1alpha3.LDAPGroupBindingLabelKey, desired)
+	if err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "LDAPSyncFailed",
+			"failed to sync the members of LDAP groups, error: %v", err)
+	}
+	if len(created) > 0 || len(revoked) > 0 {
+		r.log.Info("synced the members of LDAP groups", "namespace", namespace, "bound", created, "revoked", revoked)
+	}
+	return
+}
+
+func (r *Reconciler) getDesiredRoleBindings(ctx context.Context, project *v1alpha3.DevOpsProject, namespace string,
+	groupRoles map[string]string) (bindings []*rbacv1.RoleBinding, err error) {
+	groups := make([]string, 0, len(groupRoles))
+	for group := range groupRoles {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var members map[string][]string
+	if members, err = r.LDAP.GetGroupMembers(ctx, groups); err != nil {
+		return
+	}
+
+	names := map[string]bool{}
+	var missing []string
+	for _, group := range groups {
+		users, ok := members[group]
+		if !ok {
+			// the group was deleted or it does not match the group filter, its members are revoked
+			missing = append(missing, group)
+			continue
+		}
+		role := groupRoles[group]
+		for _, username := range users {
+			name := strings.ToLower(fmt.Sprintf("ldap-%s-%s", username, role))
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				r.log.Info("skipped the LDAP user whose name is invalid", "user", username, "errors", errs)
+				continue
+			}
+			if names[name] {
+				continue
+			}
+			names[name] = true
+			bindings = append(bindings, member.NewRoleBinding(namespace, name, username, role,
+				map[string]string{v1alpha3.LDAPGroupBindingLabelKey: "true"}))
+		}
+	}
+	if len(missing) > 0 {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "LDAPGroupNotFound",
+			"cannot find the LDAP groups: %s", strings.Join(missing, ", "))
+	}
+	return
+}
+
+// ParseGroupRoles parses the mapping of LDAP groups and roles, such as devs=operator,admins=admin
+func ParseGroupRoles(value string) (groupRoles map[string]string) {
+	groupRoles = map[string]string{}
+	for _, pair := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n'
+	}) {
+		index := strings.LastIndex(pair, "=")
+		if index <= 0 {
+			continue
+		}
+		group := strings.TrimSpace(pair[:index])
+		role := strings.TrimSpace(pair[index+1:])
+		if group != "" && role != "" {
+			groupRoles[group] = role
+		}
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "ldapgroup"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.SyncPeriod <= 0 {
+		r.SyncPeriod = ldap.DefaultSyncPeriod
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Complete(r)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldapgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/ldap/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseGroupRoles(t *testing.T) {
+	assert.Equal(t, map[string]string{}, ParseGroupRoles(""))
+	assert.Equal(t, map[string]string{
+		"devs":    "operator",
+		"cn=a":    "admin",
+		"viewers": "viewer",
+	}, ParseGroupRoles("devs=operator, cn=a=admin\nviewers = viewer,invalid,=admin,empty="))
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, rbacv1.AddToScheme(schema))
+
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "project",
+			Annotations: map[string]string{v1alpha3.DevOpsProjectLDAPGroupsAnnoKey: "devs=operator,admins=admin,missing=viewer"},
+		},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "project-ns"},
+	}
+	// a member who left the group
+	left := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "project-ns",
+			Name:      "ldap-bob-operator",
+			Labels:    map[string]string{v1alpha3.LDAPGroupBindingLabelKey: "true"},
+		},
+		RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "operator"},
+	}
+
+	c := fakeclient.NewClientBuilder().WithScheme(schema).WithObjects(project, left).Build()
+	directory := fake.NewFakeLDAP(map[string][]string{
+		"devs":   {"alice", "Carol", "invalid_name"},
+		"admins": {"alice"},
+	})
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:     c,
+		LDAP:       directory,
+		SyncPeriod: time.Minute,
+		log:        logr.Discard(),
+		recorder:   recorder,
+	}
+
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "project"}}
+	result, err := r.Reconcile(ctx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+	assert.Equal(t, "Warning LDAPGroupNotFound cannot find the LDAP groups: missing", <-recorder.Events)
+
+	bindings := &rbacv1.RoleBindingList{}
+	assert.Nil(t, c.List(ctx, bindings, client.InNamespace("project-ns")))
+	names := map[string]string{}
+	for _, item := range bindings.Items {
+		names[item.Name] = item.Subjects[0].Name
+	}
+	assert.Equal(t, map[string]string{
+		"ldap-alice-admin":    "alice",
+		"ldap-alice-operator": "alice",
+		"ldap-carol-operator": "Carol",
+	}, names)
+
+	// keep the RoleBindings if LDAP is unavailable
+	directory.Err = errors.New("connection refused")
+	_, err = r.Reconcile(ctx, request)
+	assert.NotNil(t, err)
+	assert.Nil(t, c.List(ctx, bindings, client.InNamespace("project-ns")))
+	assert.Len(t, bindings.Items, 3)
+
+	// revoke all the RoleBindings once the annotation is removed
+	project.Annotations = nil
+	assert.Nil(t, c.Update(ctx, project))
+	result, err = r.Reconcile(ctx, request)
+	assert.Nil(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Nil(t, c.List(ctx, bindings, client.InNamespace("project-ns")))
+	assert.Empty(t, bindings.Items)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensescan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/licensescan"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconciler checks the licenses of the dependencies of the PipelineRuns against the policies of DevOpsProjects
+type Reconciler struct {
+	client.Client
+	DevOpsClient devops.Interface
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile checks the SBOM once a PipelineRun archived it, then stops the PipelineRun if the licenses violate
+// the policy and the policy is enforced
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	runID, exists := pipelineRun.GetPipelineRunID()
+	if !exists || pipelineRun.Status.LicenseScan != nil {
+		return
+	}
+	var policy *v1alpha3.LicenseScanPolicy
+	if policy, err = r.getPolicy(ctx, pipelineRun.Namespace); err != nil || policy == nil {
+		return
+	}
+
+	var data []byte
+	if data, err = r.getReport(pipelineRun, runID, policy.GetReport()); err != nil || data == nil {
+		// the SBOM might be archived later, the PipelineRun is reconciled again once its status is synced
+		return
+	}
+	var bom *licensescan.BOM
+	if bom, err = licensescan.Parse(data); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "LicenseScanFailed", "failed to parse %s, error: %v",
+			policy.GetReport(), err)
+		err = nil
+		return
+	}
+
+	components := bom.GetComponents()
+	now := metav1.Now()
+	scanResult := &v1alpha3.LicenseScanResult{
+		Report:     policy.GetReport(),
+		Mode:       policy.GetMode(),
+		ScanTime:   &now,
+		Components: int32(len(components)),
+		Violations: licensescan.NewChecker(policy).Check(components),
+	}
+	if err = r.updateStatus(ctx, req.NamespacedName, scanResult); err != nil || len(scanResult.Violations) == 0 {
+		return
+	}
+
+	r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, v1alpha3.LicenseViolated,
+		"The licenses of %d components violate the policy", len(scanResult.Violations))
+	if scanResult.Mode == v1alpha3.GateModeEnforce && !pipelineRun.HasCompleted() && !pipelineRun.IsStopRequested() {
+		action := v1alpha3.Stop
+		patch := client.MergeFrom(pipelineRun.DeepCopy())
+		pipelineRun.Spec.Action = &action
+		err = client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch))
+	}
+	return
+}
+
+// getPolicy returns the license scan policy of the DevOpsProject which the namespace belongs to
+func (r *Reconciler) getPolicy(ctx context.Context, namespace string) (*v1alpha3.LicenseScanPolicy, error) {
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return nil, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return project.Spec.LicenseScan, nil
+}
+
+// getReport downloads the archived SBOM from Jenkins, the data is nil if it's not archived
+func (r *Reconciler) getReport(pipelineRun *v1alpha3.PipelineRun, runID, report string) (data []byte, err error) {
+	pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+	var artifact *http.Response
+	if pipelineRun.Spec.IsMultiBranchPipeline() && pipelineRun.Spec.SCM != nil {
+		artifact, err = r.DevOpsClient.GetBranchArtifactStream(pipelineRun.Namespace, pipelineName,
+			pipelineRun.Spec.SCM.RefName, runID, report, nil)
+	} else {
+		artifact, err = r.DevOpsClient.GetArtifactStream(pipelineRun.Namespace, pipelineName, runID, report, nil)
+	}
+	if err != nil || artifact == nil {
+		return
+	}
+	defer func() {
+		_ = artifact.Body.Close()
+	}()
+
+	switch artifact.StatusCode {
+	case http.StatusOK:
+		data, err = io.ReadAll(artifact.Body)
+	case http.StatusNotFound:
+	default:
+		err = fmt.Errorf("failed to download %s, status code: %d", report, artifact.StatusCode)
+	}
+	return
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, key types.NamespacedName, scanResult *v1alpha3.LicenseScanResult) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		if err := r.Get(ctx, key, pipelineRun); err != nil {
+			return err
+		}
+		pipelineRun.Status.LicenseScan = scanResult
+		return r.Status().Update(ctx, pipelineRun)
+	})
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "licensescan-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
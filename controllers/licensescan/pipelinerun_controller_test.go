@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensescan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	bom := `{"bomFormat":"CycloneDX","components":[
+{"name":"app","version":"v1","licenses":[{"license":{"id":"Apache-2.0"}}]},
+{"name":"lib","version":"v2","licenses":[{"license":{"id":"AGPL-3.0-only"}}]}]}`
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "project"},
+	}}
+	newProject := func(policy *v1alpha3.LicenseScanPolicy) *v1alpha3.DevOpsProject {
+		return &v1alpha3.DevOpsProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "project"},
+			Spec:       v1alpha3.DevOpsProjectSpec{LicenseScan: policy},
+		}
+	}
+	newPipelineRun := func(runID string, branch string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "pr",
+				Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			},
+		}
+		if runID != "" {
+			pr.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: runID}
+		}
+		if branch != "" {
+			pr.Spec.PipelineSpec = &v1alpha3.PipelineSpec{Type: v1alpha3.MultiBranchPipelineType}
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		return pr
+	}
+	getPipelineRun := func(t *testing.T, c client.Client) *v1alpha3.PipelineRun {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun))
+		return pipelineRun
+	}
+	verifyNotScanned := func(t *testing.T, c client.Client) {
+		pipelineRun := getPipelineRun(t, c)
+		assert.Nil(t, pipelineRun.Status.LicenseScan)
+		assert.False(t, pipelineRun.IsStopRequested())
+	}
+	denied := []v1alpha3.LicenseViolation{{Component: "lib@v2", Licenses: []string{"AGPL-3.0-only"}, Reason: v1alpha3.LicenseDenied}}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		policy      *v1alpha3.LicenseScanPolicy
+		verify      func(t *testing.T, c client.Client)
+	}{{
+		name:        "denied license in enforce mode",
+		pipelineRun: newPipelineRun("1", ""),
+		policy:      &v1alpha3.LicenseScanPolicy{Deny: []string{"AGPL-3.0-only"}},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := getPipelineRun(t, c)
+			if assert.NotNil(t, pipelineRun.Status.LicenseScan) {
+				assert.Equal(t, "bom.json", pipelineRun.Status.LicenseScan.Report)
+				assert.Equal(t, int32(2), pipelineRun.Status.LicenseScan.Components)
+				assert.Equal(t, denied, pipelineRun.Status.LicenseScan.Violations)
+			}
+			assert.True(t, pipelineRun.IsStopRequested())
+		},
+	}, {
+		name:        "denied license in warn mode of a multi-branch Pipeline",
+		pipelineRun: newPipelineRun("1", "main"),
+		policy:      &v1alpha3.LicenseScanPolicy{Mode: v1alpha3.GateModeWarn, Deny: []string{"AGPL-3.0-only"}},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := getPipelineRun(t, c)
+			if assert.NotNil(t, pipelineRun.Status.LicenseScan) {
+				assert.Equal(t, denied, pipelineRun.Status.LicenseScan.Violations)
+			}
+			assert.False(t, pipelineRun.IsStopRequested())
+		},
+	}, {
+		name:        "compliant licenses",
+		pipelineRun: newPipelineRun("1", ""),
+		policy:      &v1alpha3.LicenseScanPolicy{Deny: []string{"GPL-3.0-only"}},
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := getPipelineRun(t, c)
+			if assert.NotNil(t, pipelineRun.Status.LicenseScan) {
+				assert.Empty(t, pipelineRun.Status.LicenseScan.Violations)
+			}
+			assert.False(t, pipelineRun.IsStopRequested())
+		},
+	}, {
+		name:        "invalid SBOM",
+		pipelineRun: newPipelineRun("2", ""),
+		policy:      &v1alpha3.LicenseScanPolicy{},
+		verify:      verifyNotScanned,
+	}, {
+		name:        "SBOM is not archived",
+		pipelineRun: newPipelineRun("1", ""),
+		policy:      &v1alpha3.LicenseScanPolicy{Report: "sbom.json"},
+		verify:      verifyNotScanned,
+	}, {
+		name:        "license scan is not enabled",
+		pipelineRun: newPipelineRun("1", ""),
+		verify:      verifyNotScanned,
+	}, {
+		name:        "the PipelineRun has not started",
+		pipelineRun: newPipelineRun("", ""),
+		policy:      &v1alpha3.LicenseScanPolicy{},
+		verify:      verifyNotScanned,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).
+				WithObjects(tt.pipelineRun, ns.DeepCopy(), newProject(tt.policy)).Build()
+			devopsClient := fakedevops.New("ns")
+			devopsClient.Data = map[string]interface{}{
+				"ns-pipeline-1-bom.json":      bom,
+				"ns-pipeline-main-1-bom.json": bom,
+				"ns-pipeline-2-bom.json":      `{"spdxVersion":"SPDX-2.3"}`,
+			}
+			r := &Reconciler{
+				Client:       c,
+				DevOpsClient: devopsClient,
+				log:          logr.Discard(),
+				recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Nil(t, err)
+			tt.verify(t, c)
+		})
+	}
+}
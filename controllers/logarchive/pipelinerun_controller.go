@@ -0,0 +1,245 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/artifactrouting"
+	"kubesphere.io/devops/pkg/models/logarchive"
+	"kubesphere.io/devops/pkg/models/logmask"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// textSizeHeader is the header of the progressive text API, it's the offset of the next fetching
+	textSizeHeader = "X-Text-Size"
+	// moreDataHeader is the header of the progressive text API, it's true if the log is still being written
+	moreDataHeader = "X-More-Data"
+
+	// drainPeriod is the period of fetching the rest log after the PipelineRun completed
+	drainPeriod = 3 * time.Second
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=artifactroutingpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconciler archives the Jenkins build logs of the PipelineRuns into the object storage incrementally. The log is
+// fetched through the progressive text API while the PipelineRun is running, then each fetched piece is compressed
+// and stored as a chunk, so a huge log never needs to be fetched at once.
+type Reconciler struct {
+	client.Client
+	DevOpsClient devops.Interface
+	Storage      s3.Interface
+	// Compression is how the chunks are compressed, see logarchive.ValidateCompression
+	Compression string
+	// SyncPeriod is the minimum period of fetching the log of a running PipelineRun
+	SyncPeriod time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// Reconcile fetches the new log of a PipelineRun since the last offset, and stores it as the next chunk
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	runID, exists := pipelineRun.GetPipelineRunID()
+	if !exists {
+		return
+	}
+	var progress *logarchive.Progress
+	if progress, err = logarchive.GetProgress(pipelineRun); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "LogArchiveFailed", err.Error())
+		err = nil
+		return
+	}
+	if progress == nil {
+		// the route is pinned by the progress, so the chunks of a log are always stored together
+		var route artifactrouting.Route
+		if route, err = r.getRoute(ctx, pipelineRun.Namespace); err != nil {
+			return
+		}
+		progress = logarchive.NewProgress(pipelineRun, r.Compression)
+		progress.Bucket, progress.Prefix = route.Bucket, route.Key(progress.Prefix)
+	} else if progress.Completed {
+		return
+	}
+
+	// the PipelineRun is reconciled whenever its status is synced, don't fetch the log more often than the period
+	completed := pipelineRun.HasCompleted()
+	now := r.getNow()
+	if !completed && progress.SyncTime != nil {
+		if wait := progress.SyncTime.Add(r.SyncPeriod).Sub(now); wait > 0 {
+			result.RequeueAfter = wait
+			return
+		}
+	}
+
+	var data []byte
+	var header http.Header
+	if data, header, err = r.getLog(pipelineRun, runID, progress.Offset); err != nil {
+		return
+	}
+	next := progress.Offset + int64(len(data))
+	if size, parseErr := strconv.ParseInt(header.Get(textSizeHeader), 10, 64); parseErr == nil {
+		next = size
+	}
+	if len(data) > 0 {
+		// the credentials are masked before archiving, the offsets are still the same as the Jenkins log
+		var masker *logmask.Masker
+		if masker, err = logmask.ForPipelineRun(ctx, r.Client, pipelineRun); err != nil {
+			return
+		}
+		data = masker.Mask(data)
+
+		var options []s3.UploadOption
+		if options, err = r.getUploadOptions(ctx, pipelineRun.Namespace); err != nil {
+			return
+		}
+		if err = progress.Upload(r.Storage, data, next, options...); err != nil {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "LogArchiveFailed",
+				"failed to upload the log chunk %d, error: %v", progress.Chunks, err)
+			return
+		}
+	}
+	moreData := header.Get(moreDataHeader) == "true"
+	progress.Completed = completed && !moreData
+	progress.SyncTime = &metav1.Time{Time: now}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunLogArchiveAnnoKey] = progress.String()
+	if err = client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch)); err != nil {
+		return
+	}
+
+	switch {
+	case !completed:
+		result.RequeueAfter = r.SyncPeriod
+	case moreData:
+		// Jenkins might still be flushing the log after the PipelineRun completed
+		result.RequeueAfter = drainPeriod
+	}
+	return
+}
+
+// getLog fetches the log of a PipelineRun from the offset through the progressive text API
+func (r *Reconciler) getLog(pipelineRun *v1alpha3.PipelineRun, runID string, offset int64) ([]byte, http.Header, error) {
+	pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{RawQuery: url.Values{"start": []string{strconv.FormatInt(offset, 10)}}.Encode()},
+	}
+	if pipelineRun.Spec.IsMultiBranchPipeline() && pipelineRun.Spec.SCM != nil {
+		return r.DevOpsClient.GetBranchProgressiveRunLog(pipelineRun.Namespace, pipelineName,
+			pipelineRun.Spec.SCM.RefName, runID, params)
+	}
+	return r.DevOpsClient.GetProgressiveRunLog(pipelineRun.Namespace, pipelineName, runID, params)
+}
+
+// getUploadOptions returns the options of uploading the chunks, the chunks are encrypted by the KMS key of the
+// DevOpsProject which the namespace belongs to
+func (r *Reconciler) getUploadOptions(ctx context.Context, namespace string) ([]s3.UploadOption, error) {
+	project, err := r.getProject(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// the bucket and the prefix are pinned by the progress, only the KMS key follows the DevOpsProject
+	return artifactrouting.Match(project, nil).UploadOptions(), nil
+}
+
+// getRoute returns where the log of the namespace is archived by the ArtifactRoutingPolicies
+func (r *Reconciler) getRoute(ctx context.Context, namespace string) (route artifactrouting.Route, err error) {
+	var project *v1alpha3.DevOpsProject
+	if project, err = r.getProject(ctx, namespace); err != nil || project == nil {
+		return
+	}
+	policies := &v1alpha3.ArtifactRoutingPolicyList{}
+	if err = r.List(ctx, policies); err != nil {
+		return
+	}
+	route = artifactrouting.Match(project, policies.Items)
+	return
+}
+
+// getProject returns the DevOpsProject which the namespace belongs to, it's nil if there is no such one
+func (r *Reconciler) getProject(ctx context.Context, namespace string) (*v1alpha3.DevOpsProject, error) {
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return nil, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return project, nil
+}
+
+func (r *Reconciler) getNow() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "logarchive-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
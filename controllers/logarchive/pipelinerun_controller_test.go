@@ -0,0 +1,270 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/logarchive"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "project"},
+	}}
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "project"},
+		Spec: v1alpha3.DevOpsProjectSpec{
+			StorageEncryption: &v1alpha3.StorageEncryption{KMSKeyID: "project-key"},
+		},
+	}
+	key := types.NamespacedName{Namespace: "ns", Name: "pr"}
+	newPipelineRun := func(runID string, branch string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "pr",
+				UID:       "uid",
+				Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			},
+		}
+		if runID != "" {
+			pr.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: runID}
+		}
+		if branch != "" {
+			pr.Spec.PipelineSpec = &v1alpha3.PipelineSpec{Type: v1alpha3.MultiBranchPipelineType}
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		return pr
+	}
+	getProgress := func(t *testing.T, c client.Client) *logarchive.Progress {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), key, pipelineRun))
+		progress, err := logarchive.GetProgress(pipelineRun)
+		assert.Nil(t, err)
+		return progress
+	}
+	complete := func(t *testing.T, c client.Client) {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), key, pipelineRun))
+		pipelineRun.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+		assert.Nil(t, c.Status().Update(context.Background(), pipelineRun))
+	}
+
+	tests := []struct {
+		name   string
+		runID  string
+		branch string
+		logKey string
+	}{{
+		name:   "Pipeline",
+		runID:  "1",
+		logKey: "ns-pipeline-1",
+	}, {
+		name:   "multi-branch Pipeline",
+		runID:  "1",
+		branch: "main",
+		logKey: "ns-pipeline-main-1",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).
+				WithObjects(newPipelineRun(tt.runID, tt.branch), ns.DeepCopy(), project.DeepCopy()).Build()
+			devopsClient := fakedevops.New("ns")
+			devopsClient.Data = map[string]interface{}{tt.logKey: "line 1\n", tt.logKey + "-more": true}
+			storage := fakes3.NewFakeS3()
+			// the sync time is stored in seconds
+			now := time.Now().Truncate(time.Second)
+			r := &Reconciler{
+				Client:       c,
+				DevOpsClient: devopsClient,
+				Storage:      storage,
+				Compression:  logarchive.CompressionGzip,
+				SyncPeriod:   time.Minute,
+				log:          logr.Discard(),
+				recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+				now:          func() time.Time { return now },
+			}
+			reconcile := func() ctrl.Result {
+				result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+				assert.Nil(t, err)
+				return result
+			}
+
+			// the first chunk is archived while the PipelineRun is running
+			assert.Equal(t, time.Minute, reconcile().RequeueAfter)
+			progress := getProgress(t, c)
+			if assert.NotNil(t, progress) {
+				assert.Equal(t, "pipelinerun-logs/ns/pr/uid", progress.Prefix)
+				assert.Equal(t, int64(7), progress.Offset)
+				assert.Equal(t, 1, progress.Chunks)
+				assert.False(t, progress.Completed)
+			}
+			if object := storage.Storage["pipelinerun-logs/ns/pr/uid/000000.log.gz"]; assert.NotNil(t, object) {
+				assert.Equal(t, "project-key", object.KMSKeyID)
+			}
+
+			// the log is not fetched again before the sync period
+			devopsClient.Data[tt.logKey] = "line 1\nline 2\n"
+			now = now.Add(time.Second)
+			assert.Equal(t, time.Minute-time.Second, reconcile().RequeueAfter)
+			assert.Equal(t, 1, getProgress(t, c).Chunks)
+
+			// the rest log is archived once the PipelineRun completed
+			devopsClient.Data[tt.logKey+"-more"] = false
+			complete(t, c)
+			assert.Equal(t, time.Duration(0), reconcile().RequeueAfter)
+			progress = getProgress(t, c)
+			if assert.NotNil(t, progress) {
+				assert.Equal(t, int64(14), progress.Offset)
+				assert.Equal(t, 2, progress.Chunks)
+				assert.True(t, progress.Completed)
+			}
+
+			buf := &bytes.Buffer{}
+			assert.Nil(t, progress.Copy(storage, buf))
+			assert.Equal(t, "line 1\nline 2\n", buf.String())
+
+			// nothing is fetched after the log was archived
+			devopsClient.Data[tt.logKey] = "line 1\nline 2\nline 3\n"
+			reconcile()
+			assert.Equal(t, 2, getProgress(t, c).Chunks)
+		})
+	}
+
+	t.Run("routed by the ArtifactRoutingPolicy", func(t *testing.T) {
+		policy := &v1alpha3.ArtifactRoutingPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+			Spec: v1alpha3.ArtifactRoutingPolicySpec{
+				Projects: []string{"project"},
+				Bucket:   "tenant-a",
+				Prefix:   "tenant-a/",
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(schema).
+			WithObjects(newPipelineRun("1", ""), ns.DeepCopy(), project.DeepCopy(), policy).Build()
+		devopsClient := fakedevops.New("ns")
+		devopsClient.Data = map[string]interface{}{"ns-pipeline-1": "line 1\n", "ns-pipeline-1-more": true}
+		storage := fakes3.NewFakeS3()
+		r := &Reconciler{
+			Client:       c,
+			DevOpsClient: devopsClient,
+			Storage:      storage,
+			Compression:  logarchive.CompressionNone,
+			SyncPeriod:   time.Minute,
+			log:          logr.Discard(),
+			recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		assert.Nil(t, err)
+
+		progress := getProgress(t, c)
+		if assert.NotNil(t, progress) {
+			assert.Equal(t, "tenant-a", progress.Bucket)
+			assert.Equal(t, "tenant-a/pipelinerun-logs/ns/pr/uid", progress.Prefix)
+		}
+		assert.Empty(t, storage.Storage)
+		if object := storage.Buckets["tenant-a"].Storage["tenant-a/pipelinerun-logs/ns/pr/uid/000000.log"]; assert.NotNil(t, object) {
+			assert.Equal(t, "project-key", object.KMSKeyID)
+		}
+
+		// the archived chunks stay in the bucket after the policy was deleted
+		assert.Nil(t, c.Delete(context.Background(), policy))
+		devopsClient.Data["ns-pipeline-1"] = "line 1\nline 2\n"
+		r.now = func() time.Time { return time.Now().Add(time.Hour) }
+		_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(storage.Buckets["tenant-a"].Storage))
+	})
+
+	t.Run("the credentials are masked before archiving", func(t *testing.T) {
+		pipeline := &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+			Spec: v1alpha3.PipelineSpec{
+				Type: v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{
+					Jenkinsfile: `withCredentials([string(credentialsId: 'token', variable: 'TOKEN')]) {}`,
+				},
+			},
+		}
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "token"},
+			Data:       map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("s3cr3t-value")},
+		}
+		c := fake.NewClientBuilder().WithScheme(schema).
+			WithObjects(newPipelineRun("1", ""), pipeline, secret).Build()
+		devopsClient := fakedevops.New("ns")
+		devopsClient.Data = map[string]interface{}{"ns-pipeline-1": "token s3cr3t-value\n", "ns-pipeline-1-more": true}
+		storage := fakes3.NewFakeS3()
+		r := &Reconciler{
+			Client:       c,
+			DevOpsClient: devopsClient,
+			Storage:      storage,
+			Compression:  logarchive.CompressionNone,
+			SyncPeriod:   time.Minute,
+			log:          logr.Discard(),
+			recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		assert.Nil(t, err)
+
+		progress := getProgress(t, c)
+		if assert.NotNil(t, progress) {
+			// the offset is still the same as the Jenkins log
+			assert.Equal(t, int64(19), progress.Offset)
+		}
+		data, err := storage.Read("pipelinerun-logs/ns/pr/uid/000000.log")
+		assert.Nil(t, err)
+		assert.NotContains(t, string(data), "s3cr3t-value")
+		assert.Equal(t, "token ****\n", string(data))
+	})
+
+	t.Run("the PipelineRun has not started", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(schema).WithObjects(newPipelineRun("", "")).Build()
+		r := &Reconciler{
+			Client:       c,
+			DevOpsClient: fakedevops.New("ns"),
+			Storage:      fakes3.NewFakeS3(),
+			log:          logr.Discard(),
+			recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+		}
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		assert.Nil(t, err)
+		assert.Nil(t, getProgress(t, c))
+	})
+}
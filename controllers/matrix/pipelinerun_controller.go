@@ -0,0 +1,317 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	invalidMatrixReason = "InvalidMatrix"
+	expandedReason      = "Expanded"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+
+// Reconciler expands the PipelineRuns of a matrix Pipeline into one PipelineRun for each combination,
+// then aggregates the status of the expanded PipelineRuns into the original one
+type Reconciler struct {
+	client.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile expands a matrix PipelineRun, and aggregates the status of its children
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.Buildable() || !pipelineRun.DeletionTimestamp.IsZero() ||
+		pipelineRun.Spec.PipelineRef == nil || pipelineRun.Spec.PipelineRef.Name == "" {
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: pipelineRun.Spec.PipelineRef.Name}, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	matrix := pipelineRun.GetMatrix(pipeline)
+	if matrix == nil {
+		return
+	}
+
+	combinations := matrix.Combinations()
+	if len(combinations) == 0 || len(combinations) > v1alpha3.MaxMatrixCombinations {
+		message := fmt.Sprintf("the matrix has %d combinations, expected 1 to %d", len(combinations), v1alpha3.MaxMatrixCombinations)
+		r.recorder.Event(pipelineRun, v1.EventTypeWarning, invalidMatrixReason, message)
+		finishStatus(&pipelineRun.Status, v1alpha3.Failed, invalidMatrixReason, message, metav1.Now())
+		err = r.Status().Update(ctx, pipelineRun)
+		return
+	}
+
+	childList := &v1alpha3.PipelineRunList{}
+	if err = r.List(ctx, childList, client.InNamespace(pipelineRun.Namespace),
+		client.MatchingLabels{v1alpha3.PipelineRunMatrixParentLabelKey: pipelineRun.Name}); err != nil {
+		return
+	}
+	children := childList.Items
+
+	if pipelineRun.IsStopRequested() {
+		err = r.stopChildren(ctx, children, *pipelineRun.Spec.Action)
+	} else {
+		children, err = r.expand(ctx, pipeline, pipelineRun, combinations, children)
+	}
+	if err != nil {
+		return
+	}
+
+	status := pipelineRun.Status.DeepCopy()
+	if aggregateStatus(status, len(combinations), children, pipelineRun.IsStopRequested(), time.Now()) {
+		pipelineRun.Status = *status
+		err = r.Status().Update(ctx, pipelineRun)
+	}
+	return
+}
+
+// expand creates the missing children of the PipelineRun, then returns all of them
+func (r *Reconciler) expand(ctx context.Context, pipeline *v1alpha3.Pipeline, pipelineRun *v1alpha3.PipelineRun,
+	combinations [][]v1alpha3.Parameter, children []v1alpha3.PipelineRun) ([]v1alpha3.PipelineRun, error) {
+	existing := make(map[string]bool, len(children))
+	for _, child := range children {
+		existing[child.Name] = true
+	}
+
+	var created int
+	for i, combination := range combinations {
+		child := newChild(pipeline, pipelineRun, i, combination)
+		if existing[child.Name] {
+			continue
+		}
+		if err := controllerutil.SetControllerReference(pipelineRun, child, r.Scheme()); err != nil {
+			return children, err
+		}
+		if err := r.Create(ctx, child); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				continue
+			}
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "ExpandFailed", "failed to create PipelineRun %s, error: %v", child.Name, err)
+			return children, err
+		}
+		children = append(children, *child)
+		created++
+	}
+	if created > 0 {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, expandedReason, "Created %d PipelineRun(s) of the matrix", created)
+	}
+	return children, nil
+}
+
+// stopChildren passes the stop action of the parent to the children which are not completed
+func (r *Reconciler) stopChildren(ctx context.Context, children []v1alpha3.PipelineRun, action v1alpha3.Action) error {
+	for i := range children {
+		child := &children[i]
+		if child.HasCompleted() || child.IsStopRequested() {
+			continue
+		}
+		patch := client.MergeFrom(child.DeepCopy())
+		child.Spec.Action = &action
+		if err := r.Patch(ctx, child, patch); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return nil
+}
+
+// newChild returns the PipelineRun of a combination, the parameters of the combination override the ones of the parent
+func newChild(pipeline *v1alpha3.Pipeline, parent *v1alpha3.PipelineRun, index int, combination []v1alpha3.Parameter) *v1alpha3.PipelineRun {
+	var pipelineSpec *v1alpha3.PipelineSpec
+	if parent.Spec.PipelineSpec != nil {
+		pipelineSpec = parent.Spec.PipelineSpec.DeepCopy()
+	} else {
+		pipelineSpec = pipeline.Spec.DeepCopy()
+	}
+	pipelineSpec.Matrix = nil
+
+	overridden := make(map[string]bool, len(combination))
+	for _, param := range combination {
+		overridden[param.Name] = true
+	}
+	var parameters []v1alpha3.Parameter
+	for _, param := range parent.Spec.Parameters {
+		if !overridden[param.Name] {
+			parameters = append(parameters, param)
+		}
+	}
+	parameters = append(parameters, combination...)
+
+	return &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", parent.Name, index),
+			Namespace: parent.Namespace,
+			Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey:            pipeline.Name,
+				v1alpha3.PipelineRunMatrixParentLabelKey: parent.Name,
+			},
+			Annotations: map[string]string{
+				v1alpha3.PipelineRunMatrixCombinationAnnoKey: v1alpha3.FormatMatrixCombination(combination),
+				v1alpha3.PipelineRunCreatorAnnoKey:           parent.Annotations[v1alpha3.PipelineRunCreatorAnnoKey],
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef:  parent.Spec.PipelineRef.DeepCopy(),
+			PipelineSpec: pipelineSpec,
+			Parameters:   parameters,
+			SCM:          parent.Spec.SCM.DeepCopy(),
+		},
+	}
+}
+
+// aggregateStatus computes the status of the parent from its children, returns true if the status was changed.
+// The parent completes once all the expanded children have completed.
+func aggregateStatus(status *v1alpha3.PipelineRunStatus, total int, children []v1alpha3.PipelineRun, stopRequested bool, now time.Time) bool {
+	counts := map[v1alpha3.RunPhase]int{}
+	var completed int
+	var startTime, completionTime *metav1.Time
+	for i := range children {
+		child := &children[i]
+		if child.HasCompleted() {
+			completed++
+			counts[child.Status.Phase]++
+			if completionTime == nil || completionTime.Before(child.Status.CompletionTime) {
+				completionTime = child.Status.CompletionTime
+			}
+		} else if child.Status.Phase == v1alpha3.Running {
+			counts[v1alpha3.Running]++
+		}
+		if child.Status.StartTime != nil && (startTime == nil || child.Status.StartTime.Before(startTime)) {
+			startTime = child.Status.StartTime
+		}
+	}
+
+	metaNow := metav1.NewTime(now)
+	message := summarize(counts, total)
+	allCompleted := completed == len(children) && (len(children) == total || stopRequested)
+
+	var phase v1alpha3.RunPhase
+	switch {
+	case allCompleted && counts[v1alpha3.Failed] > 0:
+		phase = v1alpha3.Failed
+	case allCompleted && (stopRequested || counts[v1alpha3.Cancelled] > 0):
+		phase = v1alpha3.Cancelled
+	case allCompleted && counts[v1alpha3.Unknown] > 0:
+		phase = v1alpha3.Unknown
+	case allCompleted:
+		phase = v1alpha3.Succeeded
+	case counts[v1alpha3.Running] > 0 || completed > 0:
+		phase = v1alpha3.Running
+	default:
+		phase = v1alpha3.Pending
+	}
+
+	if latest := status.GetLatestCondition(); status.Phase == phase && latest != nil && latest.Message == message {
+		return false
+	}
+	if startTime != nil {
+		status.StartTime = startTime
+	}
+	if allCompleted {
+		if completionTime == nil {
+			completionTime = &metaNow
+		}
+		finishStatus(status, phase, string(phase), message, *completionTime)
+		return true
+	}
+	status.Phase = phase
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionReady,
+		Status:        v1alpha3.ConditionUnknown,
+		Reason:        string(phase),
+		Message:       message,
+		LastProbeTime: metaNow,
+	})
+	return true
+}
+
+// summarize returns a message like "1 Failed, 2 Succeeded of 4 combinations"
+func summarize(counts map[v1alpha3.RunPhase]int, total int) string {
+	var items []string
+	for phase, count := range counts {
+		items = append(items, fmt.Sprintf("%d %s", count, phase))
+	}
+	if len(items) == 0 {
+		return fmt.Sprintf("waiting for %d combinations", total)
+	}
+	sort.Strings(items)
+	return fmt.Sprintf("%s of %d combinations", strings.Join(items, ", "), total)
+}
+
+// finishStatus marks the PipelineRun status as completed
+func finishStatus(status *v1alpha3.PipelineRunStatus, phase v1alpha3.RunPhase, reason, message string, completionTime metav1.Time) {
+	conditionStatus := v1alpha3.ConditionFalse
+	if phase == v1alpha3.Succeeded {
+		conditionStatus = v1alpha3.ConditionTrue
+	}
+	status.Phase = phase
+	status.CompletionTime = &completionTime
+	status.UpdateTime = &completionTime
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionSucceeded,
+		Status:        conditionStatus,
+		Reason:        reason,
+		Message:       message,
+		LastProbeTime: completionTime,
+	})
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "matrix-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Owns(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
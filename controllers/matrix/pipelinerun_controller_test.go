@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matrix
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipeline := func(matrix *v1alpha3.Matrix) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+			Spec: v1alpha3.PipelineSpec{
+				Type:     v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{Name: "demo"},
+				Matrix:   matrix,
+			},
+		}
+	}
+	newPipelineRun := func(action *v1alpha3.Action) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns", UID: "uid"},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef: &v1.ObjectReference{Name: "demo"},
+				Parameters:  []v1alpha3.Parameter{{Name: "go", Value: "1.16"}, {Name: "debug", Value: "true"}},
+				Action:      action,
+			},
+		}
+	}
+	newChild := func(name string, phase v1alpha3.RunPhase, completed bool) *v1alpha3.PipelineRun {
+		child := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+				Labels:    map[string]string{v1alpha3.PipelineRunMatrixParentLabelKey: "demo-abc"},
+			},
+			Spec:   v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+		if completed {
+			now := metav1.Now()
+			child.Status.CompletionTime = &now
+		}
+		return child
+	}
+	matrix := &v1alpha3.Matrix{Axes: []v1alpha3.MatrixAxis{{
+		Name: "go", Values: []string{"1.17", "1.18"},
+	}, {
+		Name: "os", Values: []string{"linux"},
+	}}}
+	stop := v1alpha3.Stop
+
+	getParent := func(t *testing.T, c client.Client) *v1alpha3.PipelineRun {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc"}, pipelineRun))
+		return pipelineRun
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		verify  func(t *testing.T, c client.Client)
+	}{{
+		name:    "not a matrix Pipeline",
+		objects: []client.Object{newPipeline(nil), newPipelineRun(nil)},
+		verify: func(t *testing.T, c client.Client) {
+			list := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, c.List(context.Background(), list))
+			assert.Equal(t, 1, len(list.Items))
+			assert.Equal(t, v1alpha3.RunPhase(""), getParent(t, c).Status.Phase)
+		},
+	}, {
+		name:    "expand the PipelineRun",
+		objects: []client.Object{newPipeline(matrix), newPipelineRun(nil)},
+		verify: func(t *testing.T, c client.Client) {
+			child := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc-1"}, child))
+			assert.Equal(t, "demo-abc", child.Labels[v1alpha3.PipelineRunMatrixParentLabelKey])
+			assert.Equal(t, "go=1.18,os=linux", child.Annotations[v1alpha3.PipelineRunMatrixCombinationAnnoKey])
+			assert.Equal(t, []v1alpha3.Parameter{
+				{Name: "debug", Value: "true"}, {Name: "go", Value: "1.18"}, {Name: "os", Value: "linux"},
+			}, child.Spec.Parameters)
+			assert.Nil(t, child.Spec.PipelineSpec.Matrix)
+			assert.Equal(t, "demo-abc", child.OwnerReferences[0].Name)
+			assert.False(t, child.IsMatrixParent(nil))
+
+			parent := getParent(t, c)
+			assert.Equal(t, v1alpha3.Pending, parent.Status.Phase)
+			assert.Equal(t, "waiting for 2 combinations", parent.Status.GetLatestCondition().Message)
+		},
+	}, {
+		name: "some children are still running",
+		objects: []client.Object{newPipeline(matrix), newPipelineRun(nil),
+			newChild("demo-abc-0", v1alpha3.Succeeded, true), newChild("demo-abc-1", v1alpha3.Running, false)},
+		verify: func(t *testing.T, c client.Client) {
+			parent := getParent(t, c)
+			assert.Equal(t, v1alpha3.Running, parent.Status.Phase)
+			assert.False(t, parent.HasCompleted())
+			assert.Equal(t, "1 Running, 1 Succeeded of 2 combinations", parent.Status.GetLatestCondition().Message)
+		},
+	}, {
+		name: "one of the children failed",
+		objects: []client.Object{newPipeline(matrix), newPipelineRun(nil),
+			newChild("demo-abc-0", v1alpha3.Succeeded, true), newChild("demo-abc-1", v1alpha3.Failed, true)},
+		verify: func(t *testing.T, c client.Client) {
+			parent := getParent(t, c)
+			assert.Equal(t, v1alpha3.Failed, parent.Status.Phase)
+			assert.True(t, parent.HasCompleted())
+			condition := parent.Status.GetLatestCondition()
+			assert.Equal(t, v1alpha3.ConditionSucceeded, condition.Type)
+			assert.Equal(t, v1alpha3.ConditionFalse, condition.Status)
+		},
+	}, {
+		name: "all the children succeeded",
+		objects: []client.Object{newPipeline(matrix), newPipelineRun(nil),
+			newChild("demo-abc-0", v1alpha3.Succeeded, true), newChild("demo-abc-1", v1alpha3.Succeeded, true)},
+		verify: func(t *testing.T, c client.Client) {
+			parent := getParent(t, c)
+			assert.Equal(t, v1alpha3.Succeeded, parent.Status.Phase)
+			assert.Equal(t, v1alpha3.ConditionTrue, parent.Status.GetLatestCondition().Status)
+		},
+	}, {
+		name: "stop the children",
+		objects: []client.Object{newPipeline(matrix), newPipelineRun(&stop),
+			newChild("demo-abc-0", v1alpha3.Running, false)},
+		verify: func(t *testing.T, c client.Client) {
+			child := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc-0"}, child))
+			assert.True(t, child.IsStopRequested())
+			// the missing children should not be created
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "demo-abc-1"}, child)
+			assert.NotNil(t, err)
+			assert.Equal(t, v1alpha3.Running, getParent(t, c).Status.Phase)
+		},
+	}, {
+		name: "too many combinations",
+		objects: []client.Object{newPipeline(&v1alpha3.Matrix{Axes: []v1alpha3.MatrixAxis{{Name: "go"}}}),
+			newPipelineRun(nil)},
+		verify: func(t *testing.T, c client.Client) {
+			parent := getParent(t, c)
+			assert.Equal(t, v1alpha3.Failed, parent.Status.Phase)
+			assert.Equal(t, invalidMatrixReason, parent.Status.GetLatestCondition().Reason)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.objects...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: "ns", Name: "demo-abc"},
+			})
+			assert.Nil(t, err)
+			tt.verify(t, c)
+		})
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	now := time.Now()
+	status := &v1alpha3.PipelineRunStatus{}
+	assert.True(t, aggregateStatus(status, 2, nil, false, now))
+	assert.Equal(t, v1alpha3.Pending, status.Phase)
+	// nothing changed
+	assert.False(t, aggregateStatus(status, 2, nil, false, now))
+
+	// stopped before any children were created
+	assert.True(t, aggregateStatus(status, 2, nil, true, now))
+	assert.Equal(t, v1alpha3.Cancelled, status.Phase)
+	assert.NotNil(t, status.CompletionTime)
+}
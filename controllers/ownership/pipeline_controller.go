@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/ldap"
+	"kubesphere.io/devops/pkg/models/ownership"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines/status,verbs=get;update;patch
+
+// Reconciler validates the owners of the Pipelines against the LDAP or Active Directory groups periodically,
+// so the Pipelines whose owners left their teams show up in the stale report
+type Reconciler struct {
+	client.Client
+	LDAP ldap.Interface
+	// SyncPeriod is the period of validating the ownership of a Pipeline
+	SyncPeriod time.Duration
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile validates the ownership of a Pipeline and records the result into its status
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile Pipeline: %s", req.String()))
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err = r.Get(ctx, req.NamespacedName, pipeline); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipeline.DeletionTimestamp.IsZero() {
+		return
+	}
+	spec := pipeline.Spec.Ownership
+	if spec == nil {
+		if pipeline.Status.Ownership != nil {
+			err = r.updateOwnership(ctx, nil, req.NamespacedName)
+		}
+		return
+	}
+
+	var members map[string][]string
+	if spec.Owner != "" && spec.Team != "" {
+		result.RequeueAfter = r.SyncPeriod
+		if members, err = r.LDAP.GetGroupMembers(ctx, []string{spec.Team}); err != nil {
+			// keep the last result, it's validated again in the next period
+			r.recorder.Eventf(pipeline, v1.EventTypeWarning, "OwnershipCheckFailed",
+				"failed to get the members of the team %s, error: %v", spec.Team, err)
+			return
+		}
+	}
+
+	status := &v1alpha3.OwnershipStatus{Owner: spec.Owner, Team: spec.Team}
+	status.Valid, status.Reason = ownership.Validate(spec, members)
+	if reflect.DeepEqual(status, pipeline.Status.Ownership) {
+		return
+	}
+	if !status.Valid {
+		r.recorder.Event(pipeline, v1.EventTypeWarning, "InvalidOwner", status.Reason)
+	}
+	err = r.updateOwnership(ctx, status, req.NamespacedName)
+	return
+}
+
+func (r *Reconciler) updateOwnership(ctx context.Context, status *v1alpha3.OwnershipStatus, pipelineKey client.ObjectKey) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipeline := &v1alpha3.Pipeline{}
+		if err := r.Get(ctx, pipelineKey, pipeline); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		pipeline.Status.Ownership = status
+		return r.Status().Update(ctx, pipeline)
+	})
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "ownership-pipeline"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.Pipeline{}, builder.WithPredicates(predicate.Funcs{
+			// the Pipelines are updated frequently by the PipelineRuns, only the ownership changes matter
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldPipeline, okOld := e.ObjectOld.(*v1alpha3.Pipeline)
+				newPipeline, okNew := e.ObjectNew.(*v1alpha3.Pipeline)
+				return okOld && okNew && !reflect.DeepEqual(oldPipeline.Spec.Ownership, newPipeline.Spec.Ownership)
+			},
+		})).
+		Complete(r)
+}
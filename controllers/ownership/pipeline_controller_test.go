@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/ldap/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipeline := func(ownership *v1alpha3.PipelineOwnership, status *v1alpha3.OwnershipStatus) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+			Spec:       v1alpha3.PipelineSpec{Ownership: ownership},
+			Status:     v1alpha3.PipelineStatus{Ownership: status},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pipeline    *v1alpha3.Pipeline
+		ldapErr     error
+		wantErr     bool
+		wantRequeue bool
+		wantStatus  *v1alpha3.OwnershipStatus
+	}{{
+		name:        "valid owner",
+		pipeline:    newPipeline(&v1alpha3.PipelineOwnership{Owner: "alice", Team: "devs", Contact: "#devs"}, nil),
+		wantRequeue: true,
+		wantStatus:  &v1alpha3.OwnershipStatus{Owner: "alice", Team: "devs", Valid: true},
+	}, {
+		name:        "the owner left the team",
+		pipeline:    newPipeline(&v1alpha3.PipelineOwnership{Owner: "bob", Team: "devs"}, &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Valid: true}),
+		wantRequeue: true,
+		wantStatus:  &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Reason: "bob is not a member of the team devs"},
+	}, {
+		name:        "team not found",
+		pipeline:    newPipeline(&v1alpha3.PipelineOwnership{Owner: "alice", Team: "ops"}, nil),
+		wantRequeue: true,
+		wantStatus:  &v1alpha3.OwnershipStatus{Owner: "alice", Team: "ops", Reason: "the team ops does not exist"},
+	}, {
+		name:       "no team",
+		pipeline:   newPipeline(&v1alpha3.PipelineOwnership{Owner: "alice"}, nil),
+		wantStatus: &v1alpha3.OwnershipStatus{Owner: "alice", Reason: "the owner and the team are required"},
+	}, {
+		name:     "the ownership was removed",
+		pipeline: newPipeline(nil, &v1alpha3.OwnershipStatus{Owner: "alice", Team: "devs", Valid: true}),
+	}, {
+		name:        "keep the last result if LDAP is unavailable",
+		pipeline:    newPipeline(&v1alpha3.PipelineOwnership{Owner: "bob", Team: "devs"}, &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Valid: true}),
+		ldapErr:     errors.New("unavailable"),
+		wantErr:     true,
+		wantRequeue: true,
+		wantStatus:  &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Valid: true},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipeline).Build()
+			directory := fake.NewFakeLDAP(map[string][]string{"devs": {"alice"}})
+			directory.Err = tt.ldapErr
+			r := &Reconciler{
+				Client:     c,
+				LDAP:       directory,
+				SyncPeriod: time.Minute,
+				log:        logr.Discard(),
+				recorder:   record.NewFakeRecorder(10),
+			}
+			key := types.NamespacedName{Namespace: "ns", Name: "pipeline"}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter == time.Minute)
+
+			pipeline := &v1alpha3.Pipeline{}
+			assert.Nil(t, c.Get(context.Background(), key, pipeline))
+			assert.Equal(t, tt.wantStatus, pipeline.Status.Ownership)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packagemanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/packagemanager"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+
+// Reconciler renders the package manager settings of DevOpsProjects into the Secrets in the namespace of Jenkins
+// agents. The Secrets are mounted into the agent pods of their DevOpsProjects by the agent preset webhook.
+type Reconciler struct {
+	client.Client
+	// WorkerNamespace is the namespace of the Jenkins agent pods
+	WorkerNamespace string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile renders the settings of a DevOpsProject with the credentials in its admin namespace, or deletes the
+// rendered Secret if the settings are removed
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		// the Secret is owned by the DevOpsProject, it's deleted by the garbage collector
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: r.WorkerNamespace,
+		Name:      packagemanager.GetSecretName(project.Name),
+	}}
+	if project.Spec.PackageManagers == nil || !project.DeletionTimestamp.IsZero() {
+		err = client.IgnoreNotFound(r.Delete(ctx, secret))
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" {
+		return
+	}
+
+	var data map[string][]byte
+	if data, err = packagemanager.Render(project.Spec.PackageManagers, func(name string) (*v1.Secret, error) {
+		credential := &v1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, credential)
+		return credential, err
+	}); err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "RenderPackageSettingsFailed",
+			"failed to render the package manager settings, error: %v", err)
+		return
+	}
+
+	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = map[string]string{constants.DevOpsProjectLabelKey: project.Name}
+		secret.Type = v1.SecretTypeOpaque
+		secret.Data = data
+		return controllerutil.SetControllerReference(project, secret, r.Scheme())
+	}); err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "RenderPackageSettingsFailed",
+			"failed to apply the package manager settings, error: %v", err)
+	}
+	return
+}
+
+// findProjects returns the DevOpsProject whose package manager settings refer to the credential
+func (r *Reconciler) findProjects(credential client.Object) (requests []reconcile.Request) {
+	ctx := context.Background()
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: credential.GetNamespace()}, ns); err != nil {
+		return
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil ||
+		project.Spec.PackageManagers == nil {
+		return
+	}
+	for _, id := range packagemanager.GetCredentialIDs(project.Spec.PackageManagers) {
+		if id == credential.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: project.Name}})
+			break
+		}
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "packagemanager"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Owns(&v1.Secret{}).
+		// the settings are rendered again once the credentials are changed
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findProjects)).
+		Complete(r)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packagemanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/packagemanager"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", UID: "uid"},
+		Spec: v1alpha3.DevOpsProjectSpec{PackageManagers: &v1alpha3.PackageManagers{
+			NPM: &v1alpha3.NPMSettings{Registry: "https://npm.example.com/", CredentialID: "npm"},
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo-ns"},
+	}
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo-ns",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "demo"},
+	}}
+	credential := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-ns", Name: "npm"},
+		Type:       v1alpha3.SecretTypeSecretText,
+		Data:       map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("token")},
+	}
+	noSettingsProject := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(project, ns, noSettingsProject).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:          c,
+		WorkerNamespace: "worker",
+		log:             logr.Discard(),
+		recorder:        recorder,
+	}
+	reconcileProject := func(name string) error {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		return err
+	}
+	secretKey := client.ObjectKey{Namespace: "worker", Name: "packages-demo"}
+
+	// the credential is not found
+	assert.NotNil(t, reconcileProject("demo"))
+	assert.Len(t, recorder.Events, 1)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, secretKey, &v1.Secret{})))
+
+	assert.Nil(t, c.Create(ctx, credential))
+	assert.Nil(t, reconcileProject("demo"))
+	secret := &v1.Secret{}
+	assert.Nil(t, c.Get(ctx, secretKey, secret))
+	assert.Equal(t, map[string]string{constants.DevOpsProjectLabelKey: "demo"}, secret.Labels)
+	assert.Equal(t, "registry=https://npm.example.com/\n//npm.example.com/:_authToken=token\n",
+		string(secret.Data[packagemanager.NPMConfigKey]))
+	assert.Len(t, secret.OwnerReferences, 1)
+
+	// the settings are rendered again once the credential is changed
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "demo"}}}, r.findProjects(credential))
+	assert.Empty(t, r.findProjects(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "demo-ns", Name: "other"}}))
+	assert.Empty(t, r.findProjects(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "fake", Name: "npm"}}))
+	credential.Data[v1alpha3.SecretTextSecretKey] = []byte("new-token")
+	assert.Nil(t, c.Update(ctx, credential))
+	assert.Nil(t, reconcileProject("demo"))
+	assert.Nil(t, c.Get(ctx, secretKey, secret))
+	assert.Contains(t, string(secret.Data[packagemanager.NPMConfigKey]), ":_authToken=new-token")
+
+	// the Secret is deleted once the settings are removed
+	project = &v1alpha3.DevOpsProject{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Name: "demo"}, project))
+	project.Spec.PackageManagers = nil
+	assert.Nil(t, c.Update(ctx, project))
+	assert.Nil(t, reconcileProject("demo"))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, secretKey, secret)))
+
+	assert.Nil(t, reconcileProject("other"))
+	assert.Nil(t, reconcileProject("missing"))
+	list := &v1.SecretList{}
+	assert.Nil(t, c.List(ctx, list, client.InNamespace("worker")))
+	assert.Empty(t, list.Items)
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinesource
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/models/pipelinesource"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelinesources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelinesources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=gitrepositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines;templates,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// GitClientFactory creates the client of the git provider of a GitRepository
+type GitClientFactory func(repo *v1alpha3.GitRepository) (*scm.Client, error)
+
+// Reconciler applies the Pipelines and Templates which are defined in a Git repository according to PipelineSource
+type Reconciler struct {
+	client.Client
+	// GitClientFactory creates the clients to read the repositories, they're read by the API of git providers by default
+	GitClientFactory GitClientFactory
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile applies the definitions once the revision of the repository or the PipelineSource changed
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineSource: %s", req.String()))
+
+	source := &v1alpha3.PipelineSource{}
+	if err = r.Get(ctx, req.NamespacedName, source); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if source.Spec.Suspend || !source.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	status := source.Status.DeepCopy()
+	var changed bool
+	if changed, err = r.sync(ctx, source, status); err != nil {
+		status.Phase = v1alpha3.PipelineSourcePhaseFailed
+		status.Message = err.Error()
+		r.recorder.Eventf(source, v1.EventTypeWarning, "SyncFailed", "failed to sync, error: %v", err)
+	} else if !changed {
+		result.RequeueAfter = source.Spec.GetInterval()
+		return
+	} else {
+		now := metav1.Now()
+		status.Phase = v1alpha3.PipelineSourcePhaseSynced
+		status.Message = ""
+		status.ObservedGeneration = source.Generation
+		status.LastSyncTime = &now
+		r.recorder.Eventf(source, v1.EventTypeNormal, "Synced", "applied %d resources of revision %s",
+			len(status.Resources), status.Revision)
+	}
+
+	source.Status = *status
+	if updateErr := r.Status().Update(ctx, source); updateErr != nil && err == nil {
+		err = updateErr
+	}
+	if err == nil {
+		result.RequeueAfter = source.Spec.GetInterval()
+	}
+	return
+}
+
+// sync applies the definitions of the latest revision, it does nothing if the revision was applied
+func (r *Reconciler) sync(ctx context.Context, source *v1alpha3.PipelineSource, status *v1alpha3.PipelineSourceStatus) (changed bool, err error) {
+	repo := &v1alpha3.GitRepository{}
+	if err = r.Get(ctx, types.NamespacedName{Namespace: source.Namespace, Name: source.Spec.GitRepository}, repo); err != nil {
+		return
+	}
+	repoName := getRepoName(repo)
+	if repoName == "" {
+		err = fmt.Errorf("cannot find the owner and name of GitRepository %s", repo.Name)
+		return
+	}
+
+	var gitClient *scm.Client
+	if gitClient, err = r.GitClientFactory(repo); err != nil {
+		return
+	}
+	ref := source.Spec.Ref
+	if ref == "" {
+		var repository *scm.Repository
+		if repository, _, err = gitClient.Repositories.Find(ctx, repoName); err != nil {
+			err = fmt.Errorf("failed to find the default branch of %s, error: %v", repoName, err)
+			return
+		}
+		ref = repository.Branch
+	}
+	var revision string
+	if revision, _, err = gitClient.Git.FindRef(ctx, repoName, ref); err != nil {
+		err = fmt.Errorf("failed to find the revision of %s, error: %v", ref, err)
+		return
+	}
+	if revision == "" {
+		revision = ref
+	}
+	if status.Phase == v1alpha3.PipelineSourcePhaseSynced && status.Revision == revision &&
+		status.ObservedGeneration == source.Generation {
+		return
+	}
+
+	var definitions []pipelinesource.Definition
+	if definitions, err = pipelinesource.Load(ctx, gitClient.Contents, repoName,
+		strings.Trim(source.Spec.Path, "/"), revision, source.Spec.Recursive); err != nil {
+		return
+	}
+
+	var resources []v1alpha3.PipelineSourceResource
+	if resources, err = r.apply(ctx, source, definitions); err != nil {
+		return
+	}
+	if source.Spec.Prune {
+		if err = r.prune(ctx, source, resources); err != nil {
+			return
+		}
+	}
+	changed = true
+	status.Revision = revision
+	status.Resources = resources
+	return
+}
+
+// apply creates or updates the definitions in the namespace of the PipelineSource
+func (r *Reconciler) apply(ctx context.Context, source *v1alpha3.PipelineSource,
+	definitions []pipelinesource.Definition) (resources []v1alpha3.PipelineSourceResource, err error) {
+	paths := map[string]string{}
+	for i := range definitions {
+		definition := &definitions[i]
+		obj := definition.Object
+		kind := definition.Kind()
+		key := kind + "/" + obj.GetName()
+		if existingPath, ok := paths[key]; ok {
+			err = fmt.Errorf("%s is defined in both %q and %q", key, existingPath, definition.Path)
+			return
+		}
+		paths[key] = definition.Path
+
+		if obj.GetNamespace() != "" && obj.GetNamespace() != source.Namespace {
+			err = fmt.Errorf("%s in %q cannot be applied to namespace %s", key, definition.Path, obj.GetNamespace())
+			return
+		}
+		obj.SetNamespace(source.Namespace)
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[v1alpha3.PipelineSourceLabelKey] = source.Name
+		obj.SetLabels(labels)
+
+		if err = r.applyObject(ctx, source, obj); err != nil {
+			err = fmt.Errorf("failed to apply %s in %q, error: %v", key, definition.Path, err)
+			return
+		}
+		resources = append(resources, v1alpha3.PipelineSourceResource{
+			Kind: kind,
+			Name: obj.GetName(),
+			Path: definition.Path,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return
+}
+
+func (r *Reconciler) applyObject(ctx context.Context, source *v1alpha3.PipelineSource, obj client.Object) (err error) {
+	existing := obj.DeepCopyObject().(client.Object)
+	if err = r.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			err = r.Create(ctx, obj)
+		}
+		return
+	}
+	if owner := existing.GetLabels()[v1alpha3.PipelineSourceLabelKey]; owner != source.Name {
+		return fmt.Errorf("it exists but is not managed by this PipelineSource")
+	}
+
+	updated := existing.DeepCopyObject().(client.Object)
+	switch desired := obj.(type) {
+	case *v1alpha3.Pipeline:
+		updated.(*v1alpha3.Pipeline).Spec = desired.Spec
+	case *v1alpha3.Template:
+		updated.(*v1alpha3.Template).Spec = desired.Spec
+	}
+	updated.SetLabels(mergeMap(updated.GetLabels(), obj.GetLabels()))
+	updated.SetAnnotations(mergeMap(updated.GetAnnotations(), obj.GetAnnotations()))
+	if equality.Semantic.DeepEqual(existing, updated) {
+		return
+	}
+	return r.Update(ctx, updated)
+}
+
+func mergeMap(target, source map[string]string) map[string]string {
+	if len(source) == 0 {
+		return target
+	}
+	if target == nil {
+		target = make(map[string]string, len(source))
+	}
+	for key, val := range source {
+		target[key] = val
+	}
+	return target
+}
+
+// prune deletes the Pipelines and Templates which were managed by the PipelineSource but removed from the repository
+func (r *Reconciler) prune(ctx context.Context, source *v1alpha3.PipelineSource, resources []v1alpha3.PipelineSourceResource) (err error) {
+	applied := map[string]bool{}
+	for _, resource := range resources {
+		applied[resource.Kind+"/"+resource.Name] = true
+	}
+	options := []client.ListOption{
+		client.InNamespace(source.Namespace),
+		client.MatchingLabels{v1alpha3.PipelineSourceLabelKey: source.Name},
+	}
+
+	var managed []client.Object
+	pipelines := &v1alpha3.PipelineList{}
+	if err = r.List(ctx, pipelines, options...); err != nil {
+		return
+	}
+	for i := range pipelines.Items {
+		if !applied["Pipeline/"+pipelines.Items[i].Name] {
+			managed = append(managed, &pipelines.Items[i])
+		}
+	}
+	templates := &v1alpha3.TemplateList{}
+	if err = r.List(ctx, templates, options...); err != nil {
+		return
+	}
+	for i := range templates.Items {
+		if !applied["Template/"+templates.Items[i].Name] {
+			managed = append(managed, &templates.Items[i])
+		}
+	}
+
+	for _, obj := range managed {
+		if err = r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return
+		}
+		err = nil
+		r.recorder.Eventf(source, v1.EventTypeNormal, "Pruned", "deleted %s which was removed from the repository", obj.GetName())
+	}
+	return
+}
+
+// getRepoName returns the full name of a GitRepository, such as owner/repo
+func getRepoName(repo *v1alpha3.GitRepository) string {
+	if repo.Spec.Owner != "" && repo.Spec.Repo != "" {
+		return repo.Spec.Owner + "/" + repo.Spec.Repo
+	}
+	address, err := url.Parse(repo.Spec.URL)
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSuffix(strings.Trim(address.Path, "/"), ".git")
+	if !strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+func (r *Reconciler) newGitClient(repo *v1alpha3.GitRepository) (*scm.Client, error) {
+	spec := repo.Spec.DeepCopy()
+	if spec.Secret != nil && spec.Secret.Namespace == "" {
+		spec.Secret.Namespace = repo.Namespace
+	}
+	factory := git.NewClientFactory(spec.Provider, spec.Secret, r.Client)
+	factory.Server = spec.Server
+	return factory.GetClient()
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "pipelinesource"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.GitClientFactory == nil {
+		r.GitClientFactory = r.newGitClient
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineSource{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
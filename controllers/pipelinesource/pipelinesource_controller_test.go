@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		name = filepath.Join(dir, "owner", "repo", name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+		assert.Nil(t, os.WriteFile(name, []byte(content), 0644))
+	}
+	writeFile("pipelines/build.yaml", `apiVersion: devops.kubesphere.io/v1alpha3
+kind: Pipeline
+metadata:
+  name: build
+  labels:
+    app: demo
+spec:
+  type: pipeline
+  pipeline:
+    name: build
+    jenkinsfile: echo 1
+`)
+	gitClient, data := fake.NewDefault()
+	data.ContentDir = dir
+	data.TestRef = "sha-1"
+
+	source := &v1alpha3.PipelineSource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "source", Generation: 1},
+		Spec: v1alpha3.PipelineSourceSpec{
+			GitRepository: "repo",
+			Ref:           "master",
+			Path:          "/pipelines/",
+			Prune:         true,
+		},
+	}
+	repo := &v1alpha3.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "repo"},
+		Spec:       v1alpha3.GitRepositorySpec{Provider: "github", URL: "https://github.com/owner/repo.git"},
+	}
+	removed := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns",
+		Name:      "removed",
+		Labels:    map[string]string{v1alpha3.PipelineSourceLabelKey: "source"},
+	}}
+	unmanaged := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unmanaged"}}
+
+	c := fakeclient.NewClientBuilder().WithScheme(schema).WithObjects(source, repo, removed, unmanaged).Build()
+	r := &Reconciler{
+		Client: c,
+		GitClientFactory: func(repo *v1alpha3.GitRepository) (*scm.Client, error) {
+			return gitClient, nil
+		},
+		log:      logr.Discard(),
+		recorder: &record.FakeRecorder{},
+	}
+
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "source"}}
+	result, err := r.Reconcile(ctx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha3.DefaultPipelineSourceInterval, result.RequeueAfter)
+
+	assert.Nil(t, c.Get(ctx, client.ObjectKeyFromObject(source), source))
+	assert.Equal(t, v1alpha3.PipelineSourcePhaseSynced, source.Status.Phase)
+	assert.Equal(t, "sha-1", source.Status.Revision)
+	assert.Equal(t, int64(1), source.Status.ObservedGeneration)
+	assert.Equal(t, []v1alpha3.PipelineSourceResource{{
+		Kind: "Pipeline", Name: "build", Path: "pipelines/build.yaml",
+	}}, source.Status.Resources)
+
+	pipeline := &v1alpha3.Pipeline{}
+	assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "build"}, pipeline))
+	assert.Equal(t, "echo 1", pipeline.Spec.Pipeline.Jenkinsfile)
+	assert.Equal(t, map[string]string{"app": "demo", v1alpha3.PipelineSourceLabelKey: "source"}, pipeline.Labels)
+	// the removed one is pruned, but the unmanaged one is kept
+	assert.NotNil(t, c.Get(ctx, client.ObjectKeyFromObject(removed), &v1alpha3.Pipeline{}))
+	assert.Nil(t, c.Get(ctx, client.ObjectKeyFromObject(unmanaged), &v1alpha3.Pipeline{}))
+
+	// apply the new revision
+	writeFile("pipelines/build.yaml", `apiVersion: devops.kubesphere.io/v1alpha3
+kind: Pipeline
+metadata:
+  name: build
+spec:
+  type: pipeline
+  pipeline:
+    name: build
+    jenkinsfile: echo 2
+`)
+	data.TestRef = "sha-2"
+	_, err = r.Reconcile(ctx, request)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "build"}, pipeline))
+	assert.Equal(t, "echo 2", pipeline.Spec.Pipeline.Jenkinsfile)
+	assert.Nil(t, c.Get(ctx, client.ObjectKeyFromObject(source), source))
+	assert.Equal(t, "sha-2", source.Status.Revision)
+
+	// refuse to take over the unmanaged Pipeline
+	writeFile("pipelines/unmanaged.yaml", `apiVersion: devops.kubesphere.io/v1alpha3
+kind: Pipeline
+metadata:
+  name: unmanaged
+`)
+	data.TestRef = "sha-3"
+	_, err = r.Reconcile(ctx, request)
+	assert.NotNil(t, err)
+	assert.Nil(t, c.Get(ctx, client.ObjectKeyFromObject(source), source))
+	assert.Equal(t, v1alpha3.PipelineSourcePhaseFailed, source.Status.Phase)
+	assert.Equal(t, "sha-2", source.Status.Revision)
+}
+
+func Test_getRepoName(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   v1alpha3.GitRepositorySpec
+		expect string
+	}{{
+		name:   "owner and repo",
+		spec:   v1alpha3.GitRepositorySpec{Owner: "owner", Repo: "repo", URL: "https://github.com/other/other"},
+		expect: "owner/repo",
+	}, {
+		name:   "url",
+		spec:   v1alpha3.GitRepositorySpec{URL: "https://gitlab.com/group/sub/repo.git"},
+		expect: "group/sub/repo",
+	}, {
+		name: "invalid url",
+		spec: v1alpha3.GitRepositorySpec{URL: "https://gitlab.com/repo"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, getRepoName(&v1alpha3.GitRepository{Spec: tt.spec}))
+		})
+	}
+}
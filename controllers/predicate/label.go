@@ -30,6 +30,14 @@ func NewFilterHasLabel(label string) Filter {
 	}
 }
 
+// NewFilterHasAnnotation creates a filter that contains the specific annotation
+func NewFilterHasAnnotation(annotation string) Filter {
+	return func(object client.Object) (ok bool) {
+		_, ok = object.GetAnnotations()[annotation]
+		return
+	}
+}
+
 // NewPredicateFuncs creates a filter function
 func NewPredicateFuncs(filter Filter) k8spredicate.Funcs {
 	return k8spredicate.NewPredicateFuncs(filter)
@@ -34,3 +34,21 @@ func TestNewFilterHasLabel(t *testing.T) {
 	ok = filter(&v1.ConfigMap{})
 	assert.False(t, ok)
 }
+
+func TestNewFilterHasAnnotation(t *testing.T) {
+	filter := NewFilterHasAnnotation("fake")
+	assert.NotNil(t, filter)
+
+	ok := filter(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{
+			"fake": "good",
+		},
+	}})
+	assert.True(t, ok)
+	ok = filter(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{
+			"fake": "good",
+		},
+	}})
+	assert.False(t, ok)
+}
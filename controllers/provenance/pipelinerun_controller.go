@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/models/provenance"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconciler generates the SLSA provenance of the completed PipelineRuns
+type Reconciler struct {
+	client.Client
+	DevOpsClient devops.Interface
+	// BuilderID is the identity of this builder in the provenance
+	BuilderID string
+	// SigningKey is the Secret which contains a PEM encoded ECDSA private key, the provenance is not signed if it's empty
+	SigningKey types.NamespacedName
+	// RekorURL is the address of Rekor, the signed provenance is not uploaded if it's empty
+	RekorURL   string
+	HTTPClient *http.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile stores the provenance of a completed PipelineRun into a ConfigMap, then marks the PipelineRun
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	runID, exists := pipelineRun.GetPipelineRunID()
+	if !exists || !pipelineRun.HasCompleted() || pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceAnnoKey] != "" {
+		return
+	}
+
+	var fingerprints []devops.Fingerprint
+	pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+	if pipelineRun.Spec.IsMultiBranchPipeline() && pipelineRun.Spec.SCM != nil {
+		fingerprints, err = r.DevOpsClient.GetBranchFingerprints(pipelineRun.Namespace, pipelineName, pipelineRun.Spec.SCM.RefName, runID)
+	} else {
+		fingerprints, err = r.DevOpsClient.GetFingerprints(pipelineRun.Namespace, pipelineName, runID)
+	}
+	if err != nil {
+		r.log.Error(err, "failed to get the fingerprints", "PipelineRun", req.String())
+		return
+	}
+
+	statement := provenance.Generate(pipelineRun, r.BuilderID, fingerprints)
+	cm := &v1.ConfigMap{Data: map[string]string{}}
+	cm.Namespace = pipelineRun.Namespace
+	cm.Name = provenance.GetConfigMapName(pipelineRun)
+	var data []byte
+	if data, err = json.MarshalIndent(statement, "", "  "); err != nil {
+		return
+	}
+	cm.Data[provenance.ConfigMapKeyStatement] = string(data)
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if r.SigningKey.Name != "" {
+		var rekorEntry string
+		if data, rekorEntry, err = r.sign(ctx, statement); err != nil {
+			r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "ProvenanceFailed", "failed to sign the provenance, error: %v", err)
+			return
+		}
+		cm.Data[provenance.ConfigMapKeyEnvelope] = string(data)
+		if rekorEntry != "" {
+			pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceRekorAnnoKey] = rekorEntry
+		}
+	}
+
+	if err = controllerutil.SetControllerReference(pipelineRun, cm, r.Scheme()); err != nil {
+		return
+	}
+	if err = r.Create(ctx, cm); apierrors.IsAlreadyExists(err) {
+		err = r.Update(ctx, cm)
+	}
+	if err != nil {
+		return
+	}
+
+	pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceAnnoKey] = cm.Name
+	err = r.Patch(ctx, pipelineRun, patch)
+	return
+}
+
+// sign returns the signed envelope, and the UUID of Rekor log entry if the Rekor is enabled
+func (r *Reconciler) sign(ctx context.Context, statement *provenance.Statement) (data []byte, rekorEntry string, err error) {
+	secret := &v1.Secret{}
+	if err = r.Get(ctx, r.SigningKey, secret); err != nil {
+		return
+	}
+	var signer *provenance.Signer
+	if signer, err = provenance.NewSigner(secret.Data[provenance.SecretKeySigningKey]); err != nil {
+		return
+	}
+	var envelope *provenance.Envelope
+	if envelope, err = signer.Sign(statement); err != nil {
+		return
+	}
+	if data, err = json.Marshal(envelope); err != nil {
+		return
+	}
+
+	if r.RekorURL != "" {
+		var publicKey []byte
+		if publicKey, err = signer.PublicKeyPEM(); err == nil {
+			rekorEntry, err = provenance.UploadToRekor(r.HTTPClient, r.RekorURL, envelope, publicKey)
+		}
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "provenance-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/models/provenance"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	keyData, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	signingKey := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-devops-system", Name: "provenance-key"},
+		Data: map[string][]byte{
+			provenance.SecretKeySigningKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entry-uuid":{}}`))
+	}))
+	defer server.Close()
+
+	now := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	newPipelineRun := func(completionTime *metav1.Time, annotations map[string]string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+				Annotations: annotations,
+			},
+			Status: v1alpha3.PipelineRunStatus{StartTime: &now, CompletionTime: completionTime},
+		}
+	}
+	getStatement := func(t *testing.T, c client.Client, key string) (statement *provenance.Statement) {
+		cm := &v1.ConfigMap{}
+		if !assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-provenance"}, cm)) {
+			return
+		}
+		statement = &provenance.Statement{}
+		if key == provenance.ConfigMapKeyEnvelope {
+			envelope := &provenance.Envelope{}
+			assert.Nil(t, json.Unmarshal([]byte(cm.Data[key]), envelope))
+			signer, _ := provenance.NewSigner(signingKey.Data[provenance.SecretKeySigningKey])
+			assert.Nil(t, signer.Verify(envelope))
+			return
+		}
+		assert.Nil(t, json.Unmarshal([]byte(cm.Data[key]), statement))
+		return
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		signingKey  types.NamespacedName
+		rekorURL    string
+		wantErr     bool
+		verify      func(t *testing.T, c client.Client)
+	}{{
+		name:        "the PipelineRun is running",
+		pipelineRun: newPipelineRun(nil, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}),
+		verify: func(t *testing.T, c client.Client) {
+			cm := &v1.ConfigMap{}
+			assert.NotNil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-provenance"}, cm))
+		},
+	}, {
+		name:        "unsigned provenance",
+		pipelineRun: newPipelineRun(&now, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}),
+		verify: func(t *testing.T, c client.Client) {
+			statement := getStatement(t, c, provenance.ConfigMapKeyStatement)
+			if assert.NotNil(t, statement) {
+				assert.Equal(t, []provenance.Subject{{Name: "app.jar", Digest: map[string]string{"md5": "123"}}}, statement.Subject)
+			}
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun))
+			assert.Equal(t, "pr-provenance", pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceAnnoKey])
+		},
+	}, {
+		name:        "signed provenance uploaded to Rekor",
+		pipelineRun: newPipelineRun(&now, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}),
+		signingKey:  types.NamespacedName{Namespace: "kubesphere-devops-system", Name: "provenance-key"},
+		rekorURL:    server.URL,
+		verify: func(t *testing.T, c client.Client) {
+			getStatement(t, c, provenance.ConfigMapKeyEnvelope)
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun))
+			assert.Equal(t, "pr-provenance", pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceAnnoKey])
+			assert.Equal(t, "entry-uuid", pipelineRun.Annotations[v1alpha3.PipelineRunProvenanceRekorAnnoKey])
+		},
+	}, {
+		name:        "signing key not found",
+		pipelineRun: newPipelineRun(&now, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}),
+		signingKey:  types.NamespacedName{Namespace: "kubesphere-devops-system", Name: "fake"},
+		wantErr:     true,
+		verify: func(t *testing.T, c client.Client) {
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr"}, pipelineRun))
+			assert.NotContains(t, pipelineRun.Annotations, v1alpha3.PipelineRunProvenanceAnnoKey)
+		},
+	}, {
+		name: "the provenance exists",
+		pipelineRun: newPipelineRun(&now, map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1",
+			v1alpha3.PipelineRunProvenanceAnnoKey: "pr-provenance"}),
+		verify: func(t *testing.T, c client.Client) {
+			cm := &v1.ConfigMap{}
+			assert.NotNil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-provenance"}, cm))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun, signingKey.DeepCopy()).Build()
+			devopsClient := fakedevops.New("ns")
+			devopsClient.Data = map[string]interface{}{
+				"ns-pipeline-1-fingerprints": []devops.Fingerprint{{FileName: "app.jar", Hash: "123"}},
+			}
+			r := &Reconciler{
+				Client:       c,
+				DevOpsClient: devopsClient,
+				SigningKey:   tt.signingKey,
+				RekorURL:     tt.rekorURL,
+				HTTPClient:   server.Client(),
+				log:          logr.Discard(),
+				recorder:     &record.FakeRecorder{Events: make(chan string, 10)},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			tt.verify(t, c)
+		})
+	}
+}
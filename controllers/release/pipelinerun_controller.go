@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/models/release"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconciler creates the Git tags and SCM releases of the succeeded PipelineRuns
+type Reconciler struct {
+	client.Client
+	// SCMClientFactory creates the clients to create the tags and releases, it's built from the Client if it's nil
+	SCMClientFactory git.SCMClientFactory
+
+	log      logr.Logger
+	recorder record.EventRecorder
+	now      func() time.Time
+}
+
+// Reconcile creates the tag of the revision which a succeeded PipelineRun was built from, if the release of its
+// Pipeline is configured. The tag is recorded in the annotations, so a PipelineRun is released only once.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	// the snapshot of the Pipeline is used, so the PipelineRuns before the release was configured are not released
+	if pipelineRun.Status.Phase != v1alpha3.Succeeded || !pipelineRun.Spec.IsMultiBranchPipeline() ||
+		pipelineRun.Spec.PipelineSpec.MultiBranchPipeline == nil || pipelineRun.Spec.PipelineSpec.Release == nil ||
+		pipelineRun.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] != "" {
+		return
+	}
+	options := pipelineRun.Spec.PipelineSpec.Release
+	revision := release.GetRevision(pipelineRun)
+	if revision == "" || !release.Selects(options, pipelineRun) {
+		return
+	}
+
+	var tag string
+	if tag, err = r.release(ctx, pipelineRun, options, revision); err == git.ErrUnsupportedSource ||
+		err == release.ErrUnsupportedProvider {
+		r.log.V(4).Info("skip releasing the unsupported source", "PipelineRun", req.String())
+		err = nil
+		return
+	} else if err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, v1alpha3.ReleaseFailed,
+			"failed to release the revision %s, error: %v", revision, err)
+		return
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = make(map[string]string)
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] = tag
+	if err = client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch)); err == nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeNormal, v1alpha3.Released, "Released the revision %s as %s", revision, tag)
+	}
+	return
+}
+
+// release publishes the tag with the credential of the Pipeline, then returns the name of the tag
+func (r *Reconciler) release(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, options *v1alpha3.Release,
+	revision string) (tag string, err error) {
+	pipeline := pipelineRun.Spec.PipelineSpec.MultiBranchPipeline
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(pipeline); err != nil {
+		return
+	}
+
+	data := release.NewData(pipelineRun, revision, r.now())
+	if tag, err = release.Render(options.Tag, data); err != nil {
+		return
+	} else if tag == "" {
+		err = fmt.Errorf("the tag is empty")
+		return
+	}
+	data.Tag = tag
+	messageTemplate := options.Message
+	if messageTemplate == "" {
+		messageTemplate = release.DefaultMessage
+	}
+	input := &release.Input{Tag: tag, Revision: revision, Options: options}
+	if input.Message, err = release.Render(messageTemplate, data); err != nil {
+		return
+	}
+	var previous *v1alpha3.PipelineRun
+	if previous, err = release.FindPreviousRelease(ctx, r.Client, pipelineRun); err != nil {
+		return
+	} else if previous != nil {
+		input.PreviousTag = previous.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey]
+		input.PreviousRevision = release.GetRevision(previous)
+	}
+
+	var secretRef *v1.SecretReference
+	if credentialID := pipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: pipelineRun.Namespace, Name: credentialID}
+	}
+	var scmClient *scm.Client
+	if scmClient, err = r.SCMClientFactory(provider, server, secretRef); err == nil {
+		err = release.Publish(ctx, scmClient, provider, repo, input)
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "release-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	r.now = time.Now
+	if r.SCMClientFactory == nil {
+		r.SCMClientFactory = git.NewSCMClientFactory(r.Client)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	var releaseBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/org/app/git/ref/tags/v1.0.2", "GET /repos/org/app/releases/tags/v1.0.2":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /repos/org/app/git/tags":
+			_, _ = w.Write([]byte(`{"sha":"tag-object"}`))
+		case "POST /repos/org/app/git/refs":
+			w.WriteHeader(http.StatusCreated)
+		case "GET /repos/org/app/commits/c1":
+			_, _ = w.Write([]byte(`{"sha":"c1"}`))
+		case "GET /repos/org/app/commits":
+			_, _ = w.Write([]byte(`[{"sha":"c2","commit":{"message":"Second"}},{"sha":"c1","commit":{"message":"First"}}]`))
+		case "POST /repos/org/app/releases":
+			release := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&release)
+			releaseBody, _ = release["body"].(string)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	newPipelineRun := func(name, runID, branch, revision string, options *v1alpha3.Release) *v1alpha3.PipelineRun {
+		now := metav1.Now()
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      name,
+				Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunIDAnnoKey:           runID,
+					v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey: revision,
+				},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				SCM: &v1alpha3.SCM{RefName: branch},
+				PipelineSpec: &v1alpha3.PipelineSpec{
+					Type: v1alpha3.MultiBranchPipelineType,
+					MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+						SourceType:   v1alpha3.SourceTypeGithub,
+						GitHubSource: &v1alpha3.GithubSource{Owner: "org", Repo: "app"},
+					},
+					Release: options,
+				},
+			},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded, CompletionTime: &now},
+		}
+	}
+	options := &v1alpha3.Release{Branches: []string{"master"}, Tag: "v1.0.{{.RunID}}", CreateRelease: true}
+	previous := newPipelineRun("previous", "1", "master", "c1", options)
+	previous.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] = "v1.0.1"
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		wantTag     string
+		wantErr     bool
+		wantBody    string
+	}{{
+		name:        "release the succeeded PipelineRun",
+		pipelineRun: newPipelineRun("pr", "2", "master", "c2", options),
+		wantTag:     "v1.0.2",
+		wantBody:    "## Changes since v1.0.1\n\n### Commits\n\n* Second (c2)\n",
+	}, {
+		name:        "release is not configured",
+		pipelineRun: newPipelineRun("pr", "2", "master", "c2", nil),
+	}, {
+		name:        "branch is not released",
+		pipelineRun: newPipelineRun("pr", "2", "feature", "c2", options),
+	}, {
+		name: "not succeeded",
+		pipelineRun: func() *v1alpha3.PipelineRun {
+			pr := newPipelineRun("pr", "2", "master", "c2", options)
+			pr.Status.Phase = v1alpha3.Failed
+			return pr
+		}(),
+	}, {
+		name: "failed to create the tag",
+		pipelineRun: newPipelineRun("pr", "3", "master", "c3", &v1alpha3.Release{
+			Tag: "v1.0.{{.RunID}}",
+		}),
+		wantErr: true,
+	}, {
+		name: "invalid tag template",
+		pipelineRun: newPipelineRun("pr", "2", "master", "c2", &v1alpha3.Release{
+			Tag: "v{{.Parameters.VERSION}}",
+		}),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			releaseBody = ""
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun, previous.DeepCopy()).Build()
+			r := &Reconciler{
+				Client: c,
+				SCMClientFactory: func(string, string, *v1.SecretReference) (*scm.Client, error) {
+					return github.New(server.URL)
+				},
+				log:      logr.Discard(),
+				recorder: record.NewFakeRecorder(10),
+				now:      time.Now,
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+
+			pipelineRun := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: "pr"}, pipelineRun))
+			assert.Equal(t, tt.wantTag, pipelineRun.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey])
+			assert.Equal(t, tt.wantBody, releaseBody)
+		})
+	}
+}
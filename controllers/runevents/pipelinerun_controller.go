@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"kubesphere.io/devops/pkg/models/runevents"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+
+// Reconciler records the significant events of the PipelineRuns, they are kept after the Jenkins builds were discarded
+type Reconciler struct {
+	client.Client
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile appends the events of a PipelineRun which have not been recorded into its events ConfigMap
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	cm := &v1.ConfigMap{}
+	exists := true
+	if err = r.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace,
+		Name: runevents.GetConfigMapName(pipelineRun)}, cm); apierrors.IsNotFound(err) {
+		exists, err = false, nil
+	}
+	if err != nil {
+		return
+	}
+	var recorded []runevents.Event
+	if recorded, err = runevents.Parse(cm.Data[runevents.ConfigMapKeyEvents]); err != nil {
+		r.log.Error(err, "the recorded events are invalid, record them again", "pipelinerun", req.String())
+		recorded = nil
+	}
+
+	var stages []pipelinerun.NodeDetail
+	if stages, err = pipelinerun.GetStages(ctx, r.Client, pipelineRun); err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "EventsFailed", "failed to parse the stages, error: %v", err)
+		return
+	}
+
+	now := time.Now()
+	events := runevents.Observe(pipelineRun, stages, recorded, now)
+	if len(events) == 0 {
+		return
+	}
+	var data []byte
+	if data, err = json.Marshal(runevents.Append(recorded, events, now)); err != nil {
+		return
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[runevents.ConfigMapKeyEvents] = string(data)
+	if exists {
+		err = r.Update(ctx, cm)
+		return
+	}
+	cm.Namespace = pipelineRun.Namespace
+	cm.Name = runevents.GetConfigMapName(pipelineRun)
+	if err = controllerutil.SetControllerReference(pipelineRun, cm, r.Scheme()); err != nil {
+		return
+	}
+	err = r.Create(ctx, cm)
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "runevents-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
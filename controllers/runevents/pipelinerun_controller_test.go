@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runevents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/runevents"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	startTime := metav1.Now()
+	newPipelineRun := func(stages string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				Annotations: map[string]string{v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: stages},
+			},
+			Status: v1alpha3.PipelineRunStatus{StartTime: &startTime},
+		}
+	}
+	getEvents := func(t *testing.T, c client.Client) []runevents.Event {
+		cm := &v1.ConfigMap{}
+		assert.Nil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-events"}, cm))
+		assert.Equal(t, "pr", cm.OwnerReferences[0].Name)
+		events, err := runevents.Parse(cm.Data[runevents.ConfigMapKeyEvents])
+		assert.Nil(t, err)
+		return events
+	}
+	eventTypes := func(events []runevents.Event) (result []runevents.EventType) {
+		for _, event := range events {
+			result = append(result, event.Type)
+		}
+		return
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *v1alpha3.PipelineRun
+		objects     []client.Object
+		wantErr     bool
+		verify      func(t *testing.T, c client.Client)
+	}{{
+		name:        "record the events",
+		pipelineRun: newPipelineRun(`[{"id":"1","displayName":"build","state":"RUNNING","startTime":"2022-10-01T10:00:00.000+0000"}]`),
+		verify: func(t *testing.T, c client.Client) {
+			assert.Equal(t, []runevents.EventType{runevents.EventSubmitted, runevents.EventStageStarted, runevents.EventStarted},
+				eventTypes(getEvents(t, c)))
+		},
+	}, {
+		name:        "append the events",
+		pipelineRun: newPipelineRun(`[{"id":"1","displayName":"build","state":"FINISHED","result":"SUCCESS","startTime":"2022-10-01T10:00:00.000+0000"}]`),
+		objects: []client.Object{&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pr-events",
+				OwnerReferences: []metav1.OwnerReference{{Name: "pr"}}},
+			Data: map[string]string{runevents.ConfigMapKeyEvents: `[{"sequence":1,"type":"Submitted","time":"2022-10-01T09:00:00Z"},` +
+				`{"sequence":2,"type":"StageStarted","time":"2022-10-01T10:00:00Z","nodeId":"1"}]`},
+		}},
+		verify: func(t *testing.T, c client.Client) {
+			events := getEvents(t, c)
+			assert.Equal(t, []runevents.EventType{runevents.EventSubmitted, runevents.EventStageStarted,
+				runevents.EventStageFinished, runevents.EventStarted}, eventTypes(events))
+			assert.Equal(t, 4, events[3].Sequence)
+		},
+	}, {
+		name:        "invalid stages",
+		pipelineRun: newPipelineRun("invalid"),
+		wantErr:     true,
+		verify: func(t *testing.T, c client.Client) {
+			cm := &v1.ConfigMap{}
+			assert.NotNil(t, c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: "pr-events"}, cm))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun).WithObjects(tt.objects...).Build()
+			r := &Reconciler{
+				Client:   c,
+				log:      logr.Discard(),
+				recorder: &record.FakeRecorder{Events: make(chan string, 10)},
+			}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			tt.verify(t, c)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretscan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	"kubesphere.io/devops/pkg/models/secretscan"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconciler scans the source checkouts of the PipelineRuns for committed secrets
+type Reconciler struct {
+	client.Client
+	// SCMClientFactory creates the clients to get the changes from the SCM, it's built from the Client if it's nil
+	SCMClientFactory git.SCMClientFactory
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile scans the changes of the revision which a PipelineRun checked out, then stops the PipelineRun
+// if secrets are detected and the policy of its DevOpsProject is enforced
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !pipelineRun.Spec.IsMultiBranchPipeline() || pipelineRun.Spec.PipelineSpec.MultiBranchPipeline == nil ||
+		pipelineRun.Status.SecretScan != nil {
+		return
+	}
+	// the revision is known once the PipelineRun checked out the SCM
+	revision := jenkinsfile.GetRevision(pipelineRun)
+	if revision == "" {
+		return
+	}
+	var policy *v1alpha3.SecretScanPolicy
+	if policy, err = r.getPolicy(ctx, pipelineRun.Namespace); err != nil || policy == nil {
+		return
+	}
+
+	var changes []*scm.Change
+	if changes, err = r.listChanges(ctx, pipelineRun, revision); err == git.ErrUnsupportedSource {
+		r.log.V(4).Info("skip scanning the unsupported source", "PipelineRun", req.String())
+		err = nil
+		return
+	} else if err != nil {
+		r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, "SecretScanFailed", "failed to get the changes of %s, error: %v", revision, err)
+		return
+	}
+
+	now := metav1.Now()
+	scanResult := &v1alpha3.SecretScanResult{
+		Revision: revision,
+		Mode:     policy.GetMode(),
+		ScanTime: &now,
+		Findings: secretscan.NewScanner(policy.ExcludePaths).Scan(changes),
+	}
+	if err = r.updateStatus(ctx, req.NamespacedName, scanResult); err != nil || len(scanResult.Findings) == 0 {
+		return
+	}
+
+	r.recorder.Eventf(pipelineRun, v1.EventTypeWarning, v1alpha3.SecretsDetected,
+		"Detected %d secrets in the revision %s", len(scanResult.Findings), revision)
+	if scanResult.Mode == v1alpha3.GateModeEnforce && !pipelineRun.HasCompleted() && !pipelineRun.IsStopRequested() {
+		action := v1alpha3.Stop
+		patch := client.MergeFrom(pipelineRun.DeepCopy())
+		pipelineRun.Spec.Action = &action
+		err = client.IgnoreNotFound(r.Patch(ctx, pipelineRun, patch))
+	}
+	return
+}
+
+// getPolicy returns the secret scan policy of the DevOpsProject which the namespace belongs to
+func (r *Reconciler) getPolicy(ctx context.Context, namespace string) (*v1alpha3.SecretScanPolicy, error) {
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return nil, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return project.Spec.SecretScan, nil
+}
+
+// listChanges returns the changes of the revision from the SCM with the credential of the Pipeline
+func (r *Reconciler) listChanges(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, revision string) (changes []*scm.Change, err error) {
+	pipeline := pipelineRun.Spec.PipelineSpec.MultiBranchPipeline
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(pipeline); err != nil {
+		return
+	}
+	var secretRef *v1.SecretReference
+	if credentialID := pipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: pipelineRun.Namespace, Name: credentialID}
+	}
+
+	var scmClient *scm.Client
+	if scmClient, err = r.SCMClientFactory(provider, server, secretRef); err == nil {
+		changes, _, err = scmClient.Git.ListChanges(ctx, repo, revision, &scm.ListOptions{})
+	}
+	return
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, key types.NamespacedName, scanResult *v1alpha3.SecretScanResult) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pipelineRun := &v1alpha3.PipelineRun{}
+		if err := r.Get(ctx, key, pipelineRun); err != nil {
+			return err
+		}
+		pipelineRun.Status.SecretScan = scanResult
+		return r.Status().Update(ctx, pipelineRun)
+	})
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "secretscan-pipelinerun"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if r.SCMClientFactory == nil {
+		r.SCMClientFactory = git.NewSCMClientFactory(r.Client)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedresource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/sharedresource"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=sharedresources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=sharedresources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines;pipelineruns,verbs=get;list;watch
+
+// Reconciler releases the SharedResources held by the completed PipelineRuns, and keeps the wait queues up to date.
+// The PipelineRuns acquire the resources by themselves before being triggered.
+type Reconciler struct {
+	client.Client
+
+	log logr.Logger
+}
+
+// Reconcile refreshes the holders and the wait queue of a SharedResource
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile SharedResource: %s", req.String()))
+
+	resource := &v1alpha3.SharedResource{}
+	if err = r.Get(ctx, req.NamespacedName, resource); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	if !resource.DeletionTimestamp.IsZero() {
+		return
+	}
+
+	var claims *sharedresource.Claims
+	if claims, err = sharedresource.NewClaims(ctx, r.Client, resource.Namespace); err != nil {
+		return
+	}
+	if claims.Refresh(resource, time.Now()) {
+		err = r.Status().Update(ctx, resource)
+	}
+	return
+}
+
+// findSharedResources returns the SharedResources which the PipelineRun asks for
+func (r *Reconciler) findSharedResources(object client.Object) (requests []reconcile.Request) {
+	pr, ok := object.(*v1alpha3.PipelineRun)
+	if !ok {
+		return
+	}
+	var pipeline *v1alpha3.Pipeline
+	if pr.Spec.PipelineSpec == nil && pr.Spec.PipelineRef != nil {
+		pipeline = &v1alpha3.Pipeline{}
+		if err := r.Get(context.Background(), client.ObjectKey{Namespace: pr.Namespace, Name: pr.Spec.PipelineRef.Name},
+			pipeline); err != nil {
+			return
+		}
+	}
+	for _, name := range pr.GetSharedResources(pipeline) {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: pr.Namespace, Name: name}})
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "sharedresource"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.SharedResource{}).
+		Watches(&source.Kind{Type: &v1alpha3.PipelineRun{}}, handler.EnqueueRequestsFromMapFunc(r.findSharedResources)).
+		Complete(r)
+}
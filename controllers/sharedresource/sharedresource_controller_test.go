@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedresource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	since := metav1.NewTime(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+	completed := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "completed"},
+		Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}},
+		Status:     v1alpha3.PipelineRunStatus{CompletionTime: &since},
+	}
+	pending := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pending"},
+		Spec:       v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(completed, pending, &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+		Spec:       v1alpha3.PipelineSpec{SharedResources: []string{"staging"}},
+	}, &v1alpha3.SharedResource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "staging"},
+		Status: v1alpha3.SharedResourceStatus{
+			Holders: []v1alpha3.SharedResourceClaim{{PipelineRun: "completed", Since: since}},
+		},
+	}).Build()
+
+	reconciler := &Reconciler{Client: c, log: logr.Discard()}
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: "ns", Name: "staging"}}},
+		reconciler.findSharedResources(pending))
+
+	key := client.ObjectKey{Namespace: "ns", Name: "staging"}
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	assert.Nil(t, err)
+	resource := &v1alpha3.SharedResource{}
+	assert.Nil(t, c.Get(context.Background(), key, resource))
+	assert.Empty(t, resource.Status.Holders)
+	assert.Equal(t, 1, len(resource.Status.Waiting))
+	assert.Equal(t, "pending", resource.Status.Waiting[0].PipelineRun)
+
+	// not found
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "ns", Name: "fake"}})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;delete
+
+// Reconciler deletes the finished PipelineRuns once their spec.ttlSecondsAfterFinished expires, the same as the TTL
+// controller of Jobs. The resources owned by a PipelineRun are deleted in the foreground before the PipelineRun itself.
+type Reconciler struct {
+	client.Client
+	// WaitForArchive keeps a PipelineRun until it's archived into the history database, even if its TTL has expired
+	WaitForArchive bool
+
+	log logr.Logger
+}
+
+// Reconcile deletes a PipelineRun if its TTL has expired, or requeues it until the expiration
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile PipelineRun: %s", req.String()))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err = r.Get(ctx, req.NamespacedName, pipelineRun); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	expireAt, ok := getExpireTime(pipelineRun)
+	if !ok || !pipelineRun.DeletionTimestamp.IsZero() {
+		return
+	}
+	if waiting := time.Until(expireAt); waiting > 0 {
+		result.RequeueAfter = waiting
+		return
+	}
+	if _, archived := pipelineRun.Annotations[v1alpha3.PipelineRunArchivedAnnoKey]; r.WaitForArchive && !archived {
+		// the history controller updates the PipelineRun once it's archived, then it comes back here
+		return
+	}
+
+	r.log.V(4).Info("delete the PipelineRun whose TTL expired", "pipelinerun", req.String())
+	// the preconditions make sure the TTL was not changed since it was checked
+	err = client.IgnoreNotFound(r.Delete(ctx, pipelineRun,
+		client.PropagationPolicy(metav1.DeletePropagationForeground),
+		client.Preconditions{UID: &pipelineRun.UID, ResourceVersion: &pipelineRun.ResourceVersion}))
+	return
+}
+
+// getExpireTime returns the time when the TTL of a finished PipelineRun expires, it's false if the PipelineRun has not
+// finished or has no TTL
+func getExpireTime(pipelineRun *v1alpha3.PipelineRun) (expireAt time.Time, ok bool) {
+	if pipelineRun.Spec.TTLSecondsAfterFinished == nil || !pipelineRun.HasCompleted() {
+		return
+	}
+	ttl := time.Duration(*pipelineRun.Spec.TTLSecondsAfterFinished) * time.Second
+	return pipelineRun.Status.CompletionTime.Add(ttl), true
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "pipelinerun-ttl"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipelineRun := func(ttl *int32, completed time.Duration, annotations map[string]string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "pr",
+				UID:         types.UID("uid"),
+				Annotations: annotations,
+			},
+			Spec:   v1alpha3.PipelineRunSpec{TTLSecondsAfterFinished: ttl},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+		}
+		if completed > 0 {
+			pr.Status.CompletionTime = &metav1.Time{Time: time.Now().Add(-completed)}
+		}
+		return pr
+	}
+	archived := map[string]string{v1alpha3.PipelineRunArchivedAnnoKey: "2022-01-01T00:00:00Z"}
+
+	tests := []struct {
+		name           string
+		pipelineRun    *v1alpha3.PipelineRun
+		waitForArchive bool
+		wantDeleted    bool
+		wantRequeue    bool
+	}{{
+		name:        "without TTL",
+		pipelineRun: newPipelineRun(nil, time.Hour, nil),
+	}, {
+		name:        "not finished",
+		pipelineRun: newPipelineRun(pointer.Int32(0), 0, nil),
+	}, {
+		name:        "TTL not expired",
+		pipelineRun: newPipelineRun(pointer.Int32(3600), time.Minute, nil),
+		wantRequeue: true,
+	}, {
+		name:        "TTL expired",
+		pipelineRun: newPipelineRun(pointer.Int32(60), time.Hour, nil),
+		wantDeleted: true,
+	}, {
+		name:        "zero TTL",
+		pipelineRun: newPipelineRun(pointer.Int32(0), time.Second, nil),
+		wantDeleted: true,
+	}, {
+		name:           "wait for the archive",
+		pipelineRun:    newPipelineRun(pointer.Int32(60), time.Hour, nil),
+		waitForArchive: true,
+	}, {
+		name:           "TTL expired and archived",
+		pipelineRun:    newPipelineRun(pointer.Int32(60), time.Hour, archived),
+		waitForArchive: true,
+		wantDeleted:    true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(tt.pipelineRun.DeepCopy()).Build()
+			r := &Reconciler{
+				Client:         c,
+				WaitForArchive: tt.waitForArchive,
+				log:            logr.Discard(),
+			}
+
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "pr"}})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter > 0)
+
+			err = c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "pr"}, &v1alpha3.PipelineRun{})
+			assert.Equal(t, tt.wantDeleted, apierrors.IsNotFound(err), err)
+		})
+	}
+}
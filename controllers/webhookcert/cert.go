@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// keyPair is a PEM encoded certificate and its private key
+type keyPair struct {
+	cert []byte
+	key  []byte
+}
+
+// generateCerts creates a self-signed CA, and a serving certificate signed by it for the given DNS names
+func generateCerts(dnsNames []string, now time.Time, validity time.Duration) (ca, serving *keyPair, err error) {
+	var caKey, servingKey *ecdsa.PrivateKey
+	if caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return
+	}
+	if servingKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: "ks-devops-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	var caDER []byte
+	if caDER, err = x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey); err != nil {
+		return
+	}
+
+	servingTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	var servingDER []byte
+	if servingDER, err = x509.CreateCertificate(rand.Reader, servingTemplate, caTemplate, &servingKey.PublicKey, caKey); err != nil {
+		return
+	}
+
+	if ca, err = encodeKeyPair(caDER, caKey); err != nil {
+		return
+	}
+	serving, err = encodeKeyPair(servingDER, servingKey)
+	return
+}
+
+func encodeKeyPair(der []byte, key *ecdsa.PrivateKey) (*keyPair, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// validateCerts checks if the serving certificate is signed by the CA, is valid for the DNS names,
+// and is not going to expire before the deadline
+func validateCerts(caCert, servingCert []byte, dnsNames []string, deadline time.Time) error {
+	caBlock, _ := pem.Decode(caCert)
+	servingBlock, _ := pem.Decode(servingCert)
+	if caBlock == nil || servingBlock == nil {
+		return errors.New("invalid PEM encoded certificate")
+	}
+	ca, err := x509.ParseCertificate(caBlock.Bytes)
+	if err != nil {
+		return err
+	}
+	serving, err := x509.ParseCertificate(servingBlock.Bytes)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(serving.RawIssuer, ca.RawSubject) {
+		return errors.New("the certificate is not issued by the CA")
+	}
+	if err = serving.CheckSignatureFrom(ca); err != nil {
+		return err
+	}
+	for _, dnsName := range dnsNames {
+		if err = serving.VerifyHostname(dnsName); err != nil {
+			return err
+		}
+	}
+	if serving.NotAfter.Before(deadline) || ca.NotAfter.Before(deadline) {
+		return errors.New("the certificate is going to expire")
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;update
+
+const (
+	// DefaultValidity is the default validity of the generated certificates
+	DefaultValidity = 365 * 24 * time.Hour
+	// DefaultRotateBefore is the default duration before the expiration to rotate the certificates
+	DefaultRotateBefore = 30 * 24 * time.Hour
+	// DefaultCheckInterval is the default interval of checking the certificates
+	DefaultCheckInterval = time.Hour
+
+	// SecretKeyCACert is the key of the CA certificate in the Secret
+	SecretKeyCACert = "ca.crt"
+)
+
+// Rotator keeps the self-signed serving certificates of the webhook server up to date. It stores the certificates
+// in a Secret which is shared by all the replicas, writes them into the certificate directory of the webhook server,
+// and injects the CA bundle into the webhook configurations.
+type Rotator struct {
+	Client kubernetes.Interface
+	// Secret is the Secret which stores the certificates, it's created if it does not exist
+	Secret metav1.ObjectMeta
+	// CertDir is the directory of the webhook server to place tls.crt and tls.key
+	CertDir string
+	// DNSNames are the names which the serving certificate is valid for
+	DNSNames []string
+	// MutatingWebhooks and ValidatingWebhooks are the names of the webhook configurations to inject the CA bundle
+	MutatingWebhooks   []string
+	ValidatingWebhooks []string
+
+	Validity      time.Duration
+	RotateBefore  time.Duration
+	CheckInterval time.Duration
+
+	log logr.Logger
+	now func() time.Time
+}
+
+// NewRotator creates a Rotator with the default durations
+func NewRotator(client kubernetes.Interface, secret metav1.ObjectMeta, certDir string, dnsNames []string) *Rotator {
+	return &Rotator{
+		Client:        client,
+		Secret:        secret,
+		CertDir:       certDir,
+		DNSNames:      dnsNames,
+		Validity:      DefaultValidity,
+		RotateBefore:  DefaultRotateBefore,
+		CheckInterval: DefaultCheckInterval,
+		log:           ctrl.Log.WithName("webhook-cert-rotator"),
+		now:           time.Now,
+	}
+}
+
+// Start checks the certificates periodically until the context is done
+func (r *Rotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Rotate(ctx); err != nil {
+				r.log.Error(err, "failed to rotate the webhook certificates")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false because every replica needs the certificates for its webhook server
+func (r *Rotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Rotate renews the certificates if they are invalid or going to expire, then makes sure the webhook server
+// and the webhook configurations are using them. It must be called once before the webhook server starts.
+func (r *Rotator) Rotate(ctx context.Context) (err error) {
+	var secret *v1.Secret
+	if secret, err = r.ensureSecret(ctx); err != nil {
+		return
+	}
+	if err = r.writeCerts(secret); err != nil {
+		return
+	}
+	err = r.injectCABundle(ctx, secret.Data[SecretKeyCACert])
+	return
+}
+
+func (r *Rotator) ensureSecret(ctx context.Context) (secret *v1.Secret, err error) {
+	secrets := r.Client.CoreV1().Secrets(r.Secret.Namespace)
+	err = retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() (err error) {
+		var exists bool
+		if secret, err = secrets.Get(ctx, r.Secret.Name, metav1.GetOptions{}); err == nil {
+			exists = true
+		} else if apierrors.IsNotFound(err) {
+			secret = &v1.Secret{ObjectMeta: r.Secret, Type: v1.SecretTypeTLS}
+		} else {
+			return
+		}
+
+		now := r.now()
+		if exists && validateCerts(secret.Data[SecretKeyCACert], secret.Data[v1.TLSCertKey], r.DNSNames,
+			now.Add(r.RotateBefore)) == nil {
+			return
+		}
+
+		r.log.Info("generate the webhook certificates", "secret", r.Secret.Namespace+"/"+r.Secret.Name)
+		var ca, serving *keyPair
+		if ca, serving, err = generateCerts(r.DNSNames, now, r.Validity); err != nil {
+			return
+		}
+		secret.Data = map[string][]byte{
+			SecretKeyCACert:     ca.cert,
+			v1.TLSCertKey:       serving.cert,
+			v1.TLSPrivateKeyKey: serving.key,
+		}
+		if exists {
+			secret, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		} else {
+			secret, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		}
+		return
+	})
+	return
+}
+
+// writeCerts writes the certificate files if they are changed, the webhook server watches and reloads them
+func (r *Rotator) writeCerts(secret *v1.Secret) (err error) {
+	if err = os.MkdirAll(r.CertDir, 0755); err != nil {
+		return
+	}
+	for _, key := range []string{v1.TLSPrivateKeyKey, v1.TLSCertKey} {
+		file := filepath.Join(r.CertDir, key)
+		if data, readErr := ioutil.ReadFile(file); readErr == nil && bytes.Equal(data, secret.Data[key]) {
+			continue
+		}
+		if err = ioutil.WriteFile(file, secret.Data[key], 0600); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *Rotator) injectCABundle(ctx context.Context, caBundle []byte) error {
+	webhooks := r.Client.AdmissionregistrationV1()
+	for _, name := range r.MutatingWebhooks {
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			config, err := webhooks.MutatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			changed := false
+			for i := range config.Webhooks {
+				if !bytes.Equal(config.Webhooks[i].ClientConfig.CABundle, caBundle) {
+					config.Webhooks[i].ClientConfig.CABundle = caBundle
+					changed = true
+				}
+			}
+			if changed {
+				_, err = webhooks.MutatingWebhookConfigurations().Update(ctx, config, metav1.UpdateOptions{})
+			}
+			return err
+		}); r.ignoreNotFound(err, "MutatingWebhookConfiguration", name) != nil {
+			return err
+		}
+	}
+	for _, name := range r.ValidatingWebhooks {
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			config, err := webhooks.ValidatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			changed := false
+			for i := range config.Webhooks {
+				if !bytes.Equal(config.Webhooks[i].ClientConfig.CABundle, caBundle) {
+					config.Webhooks[i].ClientConfig.CABundle = caBundle
+					changed = true
+				}
+			}
+			if changed {
+				_, err = webhooks.ValidatingWebhookConfigurations().Update(ctx, config, metav1.UpdateOptions{})
+			}
+			return err
+		}); r.ignoreNotFound(err, "ValidatingWebhookConfiguration", name) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rotator) ignoreNotFound(err error, kind, name string) error {
+	if apierrors.IsNotFound(err) {
+		r.log.Info(fmt.Sprintf("skip injecting the CA bundle due to %s %s is not found", kind, name))
+		return nil
+	}
+	return err
+}
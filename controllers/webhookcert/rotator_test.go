@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRotator_Rotate(t *testing.T) {
+	now := time.Now()
+	dnsNames := []string{"webhook.ns.svc", "webhook.ns.svc.cluster.local"}
+	client := fake.NewSimpleClientset(&admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "mutating"},
+		Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "a"}, {Name: "b"}},
+	}, &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "validating"},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "c"}},
+	})
+
+	rotator := NewRotator(client, metav1.ObjectMeta{Namespace: "ns", Name: "webhook-cert"}, t.TempDir(), dnsNames)
+	rotator.MutatingWebhooks = []string{"missing", "mutating"}
+	rotator.ValidatingWebhooks = []string{"validating"}
+	rotator.log = logr.Discard()
+	rotator.now = func() time.Time {
+		return now
+	}
+
+	getSecret := func() *v1.Secret {
+		secret, err := client.CoreV1().Secrets("ns").Get(context.TODO(), "webhook-cert", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return secret
+	}
+	assertInjected := func(caBundle []byte) {
+		mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.TODO(), "mutating", metav1.GetOptions{})
+		assert.Nil(t, err)
+		for _, webhook := range mutating.Webhooks {
+			assert.Equal(t, caBundle, webhook.ClientConfig.CABundle)
+		}
+		validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.TODO(), "validating", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, caBundle, validating.Webhooks[0].ClientConfig.CABundle)
+	}
+
+	// generate the certificates at the first time
+	assert.Nil(t, rotator.Rotate(context.TODO()))
+	secret := getSecret()
+	assert.Nil(t, validateCerts(secret.Data[SecretKeyCACert], secret.Data[v1.TLSCertKey], dnsNames, now))
+	for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey} {
+		data, err := ioutil.ReadFile(filepath.Join(rotator.CertDir, key))
+		assert.Nil(t, err)
+		assert.Equal(t, secret.Data[key], data)
+	}
+	assertInjected(secret.Data[SecretKeyCACert])
+
+	// keep the valid certificates
+	assert.Nil(t, rotator.Rotate(context.TODO()))
+	assert.Equal(t, secret.Data, getSecret().Data)
+
+	// rotate the certificates which are going to expire
+	rotator.now = func() time.Time {
+		return now.Add(DefaultValidity - DefaultRotateBefore/2)
+	}
+	assert.Nil(t, rotator.Rotate(context.TODO()))
+	rotated := getSecret()
+	assert.NotEqual(t, secret.Data[SecretKeyCACert], rotated.Data[SecretKeyCACert])
+	assertInjected(rotated.Data[SecretKeyCACert])
+
+	// rotate the certificates which are not valid for the DNS names
+	rotator.DNSNames = []string{"another.ns.svc"}
+	assert.Nil(t, rotator.Rotate(context.TODO()))
+	assert.NotEqual(t, rotated.Data[v1.TLSCertKey], getSecret().Data[v1.TLSCertKey])
+}
+
+func TestValidateCerts(t *testing.T) {
+	now := time.Now()
+	ca, serving, err := generateCerts([]string{"webhook.ns.svc"}, now, time.Hour)
+	assert.Nil(t, err)
+	anotherCA, _, err := generateCerts([]string{"webhook.ns.svc"}, now, time.Hour)
+	assert.Nil(t, err)
+
+	assert.Nil(t, validateCerts(ca.cert, serving.cert, []string{"webhook.ns.svc"}, now))
+	assert.NotNil(t, validateCerts(ca.cert, serving.cert, []string{"webhook.ns.svc"}, now.Add(2*time.Hour)))
+	assert.NotNil(t, validateCerts(anotherCA.cert, serving.cert, []string{"webhook.ns.svc"}, now))
+	assert.NotNil(t, validateCerts(ca.cert, serving.cert, []string{"another.ns.svc"}, now))
+	assert.NotNil(t, validateCerts(nil, serving.cert, nil, now))
+}
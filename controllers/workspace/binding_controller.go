@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/member"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups=iam.kubesphere.io,resources=workspacerolebindings,verbs=get;list;watch
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=bind
+
+// DefaultRoleMapping maps the roles of KubeSphere workspace to the roles of DevOpsProject.
+// The regular members are not mapped, they only access the DevOpsProjects which they were invited to.
+var DefaultRoleMapping = map[string]string{
+	"admin":            "admin",
+	"self-provisioner": "operator",
+	"viewer":           "viewer",
+}
+
+// workspaceRoleBindingGVK is the GroupVersionKind of the workspace members of KubeSphere
+var workspaceRoleBindingGVK = schema.GroupVersionKind{
+	Group:   "iam.kubesphere.io",
+	Version: "v1alpha2",
+	Kind:    "WorkspaceRoleBinding",
+}
+
+// Reconciler binds the members of a KubeSphere workspace to the roles of its DevOpsProjects,
+// the RoleBindings are revoked once the members are removed from the workspace
+type Reconciler struct {
+	client.Client
+	// RoleMapping maps the workspace roles, such as admin, to the Roles in the namespace of DevOpsProject
+	RoleMapping map[string]string
+
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile makes the RoleBindings of a DevOpsProject consistent with the members of its workspace
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.log.V(6).Info(fmt.Sprintf("start to reconcile DevOpsProject: %s", req.String()))
+
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.Get(ctx, req.NamespacedName, project); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" {
+		// the namespace is not ready, the status change will trigger it again
+		return
+	}
+
+	workspace := project.Labels[constants.WorkspaceLabelKey]
+	var desired []*rbacv1.RoleBinding
+	if workspace != "" && project.DeletionTimestamp.IsZero() {
+		if desired, err = r.getDesiredRoleBindings(ctx, workspace, namespace); err != nil {
+			return
+		}
+	}
+
+	var created, revoked []string
+	created, revoked, err = member.SyncRoleBindings(ctx, r.Client, namespace, v1alpha3.WorkspaceBindingLabelKey, desired)
+	if err != nil {
+		r.recorder.Eventf(project, v1.EventTypeWarning, "BindWorkspaceMemberFailed",
+			"failed to sync the workspace members, error: %v", err)
+	}
+	if len(created) > 0 || len(revoked) > 0 {
+		r.log.Info("synced the workspace members", "namespace", namespace, "bound", created, "revoked", revoked)
+	}
+	return
+}
+
+// getDesiredRoleBindings returns the RoleBindings of the users who are the members of the workspace
+func (r *Reconciler) getDesiredRoleBindings(ctx context.Context, workspace, namespace string) (bindings []*rbacv1.RoleBinding, err error) {
+	members := &unstructured.UnstructuredList{}
+	members.SetGroupVersionKind(workspaceRoleBindingGVK.GroupVersion().WithKind(workspaceRoleBindingGVK.Kind + "List"))
+	if err = r.List(ctx, members, client.MatchingLabels{constants.WorkspaceLabelKey: workspace}); err != nil {
+		return
+	}
+
+	for i := range members.Items {
+		item := &members.Items[i]
+		workspaceRole, _, _ := unstructured.NestedString(item.Object, "roleRef", "name")
+		role, ok := r.RoleMapping[strings.TrimPrefix(workspaceRole, workspace+"-")]
+		if !ok {
+			continue
+		}
+		subjects, _, _ := unstructured.NestedSlice(item.Object, "subjects")
+		for _, subject := range subjects {
+			subjectMap, _ := subject.(map[string]interface{})
+			if kind, _ := subjectMap["kind"].(string); kind != rbacv1.UserKind {
+				continue
+			}
+			username, _ := subjectMap["name"].(string)
+			if username == "" {
+				continue
+			}
+			bindings = append(bindings, newRoleBinding(workspace, namespace, username, role))
+		}
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].Name < bindings[j].Name
+	})
+	return
+}
+
+func newRoleBinding(workspace, namespace, username, role string) *rbacv1.RoleBinding {
+	// the prefix avoids the conflicts with the members which were invited to the DevOpsProject directly
+	return member.NewRoleBinding(namespace, fmt.Sprintf("workspace-%s-%s", username, role), username, role,
+		map[string]string{v1alpha3.WorkspaceBindingLabelKey: workspace})
+}
+
+// mapToDevOpsProjects finds the DevOpsProjects in the same workspace of a workspace member
+func (r *Reconciler) mapToDevOpsProjects(obj client.Object) (requests []reconcile.Request) {
+	workspace := obj.GetLabels()[constants.WorkspaceLabelKey]
+	if workspace == "" {
+		return
+	}
+	projects := &v1alpha3.DevOpsProjectList{}
+	if err := r.List(context.Background(), projects, client.MatchingLabels{constants.WorkspaceLabelKey: workspace}); err != nil {
+		r.log.Error(err, "failed to list the DevOpsProjects", "workspace", workspace)
+		return
+	}
+	for i := range projects.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: projects.Items[i].Name},
+		})
+	}
+	return
+}
+
+// GetName returns the name of this reconciler
+func (r *Reconciler) GetName() string {
+	return "workspace-binding"
+}
+
+// SetupWithManager setups the reconciler with a manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = ctrl.Log.WithName(r.GetName())
+	r.recorder = mgr.GetEventRecorderFor(r.GetName())
+	if len(r.RoleMapping) == 0 {
+		r.RoleMapping = DefaultRoleMapping
+	}
+
+	workspaceMember := &unstructured.Unstructured{}
+	workspaceMember.SetGroupVersionKind(workspaceRoleBindingGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.GetName()).
+		For(&v1alpha3.DevOpsProject{}).
+		Watches(&source.Kind{Type: workspaceMember}, handler.EnqueueRequestsFromMapFunc(r.mapToDevOpsProjects)).
+		Complete(r)
+}
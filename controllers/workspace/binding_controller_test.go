@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newMember(workspace, name, role string, users ...string) *unstructured.Unstructured {
+	subjects := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		subjects = append(subjects, map[string]interface{}{
+			"apiGroup": rbacv1.GroupName,
+			"kind":     rbacv1.UserKind,
+			"name":     user,
+		})
+	}
+	member := &unstructured.Unstructured{Object: map[string]interface{}{
+		"roleRef": map[string]interface{}{
+			"apiGroup": "iam.kubesphere.io",
+			"kind":     "WorkspaceRole",
+			"name":     workspace + "-" + role,
+		},
+		"subjects": subjects,
+	}}
+	member.SetGroupVersionKind(workspaceRoleBindingGVK)
+	member.SetName(name)
+	member.SetLabels(map[string]string{constants.WorkspaceLabelKey: workspace})
+	return member
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, rbacv1.AddToScheme(schema))
+
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "project",
+			Labels: map[string]string{constants.WorkspaceLabelKey: "ws"},
+		},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "project-ns"},
+	}
+	// a member who was removed from the workspace
+	removed := newRoleBinding("ws", "project-ns", "bob", "viewer")
+	// a member who was invited to the DevOpsProject directly
+	invited := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "project-ns", Name: "carol-operator"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "operator"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(project, removed, invited,
+		newMember("ws", "alice-ws-admin", "admin", "alice"),
+		newMember("ws", "dave-ws-regular", "regular", "dave"),
+		newMember("ws", "erin-ws-viewer", "viewer", "erin"),
+		newMember("other", "bob-other-viewer", "viewer", "bob")).Build()
+	r := &Reconciler{
+		Client:      c,
+		RoleMapping: DefaultRoleMapping,
+		log:         logr.Discard(),
+		recorder:    &record.FakeRecorder{},
+	}
+
+	ctx := context.Background()
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "project"}})
+	assert.Nil(t, err)
+
+	bindings := &rbacv1.RoleBindingList{}
+	assert.Nil(t, c.List(ctx, bindings, client.InNamespace("project-ns")))
+	names := map[string]string{}
+	for _, item := range bindings.Items {
+		names[item.Name] = item.RoleRef.Name
+	}
+	assert.Equal(t, map[string]string{
+		"workspace-alice-admin": "admin",
+		"workspace-erin-viewer": "viewer",
+		"carol-operator":        "operator",
+	}, names)
+
+	// all the synced RoleBindings are revoked after the DevOpsProject left the workspace
+	project.Labels = nil
+	assert.Nil(t, c.Update(ctx, project))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "project"}})
+	assert.Nil(t, err)
+	assert.Nil(t, c.List(ctx, bindings, client.InNamespace("project-ns")))
+	if assert.Len(t, bindings.Items, 1) {
+		assert.Equal(t, "carol-operator", bindings.Items[0].Name)
+	}
+}
+
+func TestReconciler_mapToDevOpsProjects(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		&v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{
+			Name: "a", Labels: map[string]string{constants.WorkspaceLabelKey: "ws"}}},
+		&v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{
+			Name: "b", Labels: map[string]string{constants.WorkspaceLabelKey: "other"}}}).Build()
+	r := &Reconciler{Client: c, log: logr.Discard()}
+
+	requests := r.mapToDevOpsProjects(newMember("ws", "alice-ws-admin", "admin", "alice"))
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "a", requests[0].Name)
+	}
+	assert.Empty(t, r.mapToDevOpsProjects(&unstructured.Unstructured{}))
+}
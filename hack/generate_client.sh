@@ -4,4 +4,4 @@ set -e
 
 GV="$1"
 
-./hack/generate_group.sh all kubesphere.io/devops/pkg/client kubesphere.io/devops/api "${GV}" --output-base=./  -h "$PWD/hack/boilerplate.go.txt"
+./hack/generate_group.sh all kubesphere.io/devops/pkg/client kubesphere.io/devops/pkg/api "${GV:-devops:v1alpha1,v1alpha3}" --output-base=./  -h "$PWD/hack/boilerplate.go.txt"
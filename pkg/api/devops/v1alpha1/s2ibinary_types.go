@@ -37,6 +37,13 @@ const (
 	S2iBinaryLabelKey      = "s2ibinary-name.kubesphere.io"
 )
 
+const (
+	// S2iBinaryBucketAnnoKey is the bucket where the binary was uploaded, it's the default bucket if it's absent
+	S2iBinaryBucketAnnoKey = "s2ibinary.kubesphere.io/bucket"
+	// S2iBinaryObjectKeyAnnoKey is the object key of the uploaded binary, it's "namespace-name" if it's absent
+	S2iBinaryObjectKeyAnnoKey = "s2ibinary.kubesphere.io/object-key"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
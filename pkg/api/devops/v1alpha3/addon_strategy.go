@@ -58,6 +58,7 @@ func (a AddonInstallStrategy) IsValid() bool {
 	}
 }
 
+// +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:resource:scope="Cluster"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
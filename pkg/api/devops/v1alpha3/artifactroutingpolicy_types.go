@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArtifactRoutingPolicySpec defines where the objects of the selected DevOps projects are stored
+type ArtifactRoutingPolicySpec struct {
+	// Projects are the names of the selected DevOpsProjects. The policies which select a DevOps project by its name
+	// take precedence over the ones which select it by its workspace.
+	// +optional
+	Projects []string `json:"projects,omitempty"`
+	// Workspaces are the names of the workspaces, all the DevOps projects in them are selected.
+	// +optional
+	Workspaces []string `json:"workspaces,omitempty"`
+	// Bucket is the bucket of the objects, the default bucket of the object storage is used if it's empty.
+	// The S3 credentials must be able to access it.
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
+	// Prefix is prepended to the keys of the objects, such as tenant-a/.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Bucket",type=string,JSONPath=`.spec.bucket`
+//+kubebuilder:printcolumn:name="Prefix",type=string,JSONPath=`.spec.prefix`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:scope=Cluster,categories="devops"
+
+// ArtifactRoutingPolicy routes the objects of DevOps projects, such as the S2I binaries and the archived logs, into
+// their own bucket or key prefix instead of the default bucket, so the data of the tenants are separated
+type ArtifactRoutingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ArtifactRoutingPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ArtifactRoutingPolicyList contains a list of ArtifactRoutingPolicy
+type ArtifactRoutingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArtifactRoutingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArtifactRoutingPolicy{}, &ArtifactRoutingPolicyList{})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupEncryptionKey is the key of the encryption passphrase in the Secret referenced by a DevOpsBackup
+const BackupEncryptionKey = "key"
+
+// DevOpsBackupSpec defines the desired state of DevOpsBackup
+type DevOpsBackupSpec struct {
+	// Interval is the period between two backups, only back up once if it is empty
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Suspend indicates whether to stop the scheduled backups
+	Suspend bool `json:"suspend,omitempty"`
+	// DevOpsProjects are the names of DevOpsProject to back up, back up all of them if it is empty
+	DevOpsProjects []string `json:"devopsProjects,omitempty"`
+	// IncludeRunHistory indicates whether to back up the completed PipelineRuns
+	IncludeRunHistory bool `json:"includeRunHistory,omitempty"`
+	// EncryptionSecret refers to a Secret which contains the passphrase to encrypt the credentials
+	EncryptionSecret v1.SecretReference `json:"encryptionSecret"`
+	// Prefix is the key prefix of the archives in the object storage
+	Prefix string `json:"prefix,omitempty"`
+	// MaxArchives is the number of archives to keep, keep all of them if it is zero
+	MaxArchives int `json:"maxArchives,omitempty"`
+}
+
+// DevOpsBackupStatus defines the observed state of DevOpsBackup
+type DevOpsBackupStatus struct {
+	Phase BackupPhase `json:"phase,omitempty"`
+	// Message describes the reason of the failed backup
+	Message string `json:"message,omitempty"`
+	// LastBackupTime is the time of the last successful backup
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+	// Archives are the keys of the archives in the object storage, the latest one is at the end
+	Archives []string `json:"archives,omitempty"`
+}
+
+// BackupPhase represents the phase of a DevOpsBackup
+type BackupPhase string
+
+const (
+	// BackupPhaseCompleted indicates the last backup was successful
+	BackupPhaseCompleted BackupPhase = "Completed"
+	// BackupPhaseFailed indicates the last backup was failed
+	BackupPhaseFailed BackupPhase = "Failed"
+)
+
+//+genclient
+//+genclient:nonNamespaced
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,categories="devops"
+//+kubebuilder:printcolumn:name="Interval",type="string",JSONPath=".spec.interval"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="LastBackup",type="date",JSONPath=".status.lastBackupTime"
+
+// DevOpsBackup is the Schema for backing up DevOps resources into the object storage
+type DevOpsBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DevOpsBackupSpec   `json:"spec,omitempty"`
+	Status DevOpsBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DevOpsBackupList contains a list of DevOpsBackup
+type DevOpsBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DevOpsBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DevOpsBackup{}, &DevOpsBackupList{})
+}
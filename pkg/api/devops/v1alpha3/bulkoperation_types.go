@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops"
+)
+
+// BulkOperationAnnoKey is the annotation key of the BulkOperation which created a PipelineRun
+const BulkOperationAnnoKey = devops.GroupName + "/bulk-operation"
+
+// BulkAction is the action of a BulkOperation
+type BulkAction string
+
+const (
+	// BulkActionDisablePipelines suspends the Pipelines, then all their triggers are ignored
+	BulkActionDisablePipelines BulkAction = "DisablePipelines"
+	// BulkActionAbortRuns aborts the unfinished PipelineRuns
+	BulkActionAbortRuns BulkAction = "AbortRuns"
+	// BulkActionRetriggerFailedRuns re-triggers the latest PipelineRuns if they failed
+	BulkActionRetriggerFailedRuns BulkAction = "RetriggerFailedRuns"
+)
+
+// BulkOperationSpec defines the desired state of BulkOperation
+type BulkOperationSpec struct {
+	// Action is what to do with the Pipelines or PipelineRuns
+	// +kubebuilder:validation:Enum=DisablePipelines;AbortRuns;RetriggerFailedRuns
+	Action BulkAction `json:"action"`
+	// Pipelines are the names of Pipelines to operate, all the Pipelines in the namespace are operated if it is empty
+	// +optional
+	Pipelines []string `json:"pipelines,omitempty"`
+	// Branch is the SCM reference name of the PipelineRuns to operate, such as main. The PipelineRuns of all the
+	// branches and the Pipelines without SCM are operated if it is empty. It is ignored by DisablePipelines.
+	// For RetriggerFailedRuns, the latest PipelineRun of each branch is checked.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+}
+
+// BulkOperationPhase is the phase of a BulkOperation
+type BulkOperationPhase string
+
+const (
+	// BulkOperationRunning indicates the operation is in progress
+	BulkOperationRunning BulkOperationPhase = "Running"
+	// BulkOperationSucceeded indicates all the items were operated successfully
+	BulkOperationSucceeded BulkOperationPhase = "Succeeded"
+	// BulkOperationFailed indicates some items failed
+	BulkOperationFailed BulkOperationPhase = "Failed"
+)
+
+// BulkOperationItemResult is the result of an item of a BulkOperation
+type BulkOperationItemResult string
+
+const (
+	// BulkOperationItemSucceeded indicates the item was operated successfully
+	BulkOperationItemSucceeded BulkOperationItemResult = "Succeeded"
+	// BulkOperationItemFailed indicates the item failed
+	BulkOperationItemFailed BulkOperationItemResult = "Failed"
+)
+
+// BulkOperationItem is the result of operating a Pipeline or PipelineRun
+type BulkOperationItem struct {
+	// Name is the name of the Pipeline or PipelineRun
+	Name   string                  `json:"name"`
+	Result BulkOperationItemResult `json:"result"`
+	// Message describes the reason of the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+	// PipelineRun is the name of the PipelineRun which was created by re-triggering
+	// +optional
+	PipelineRun string `json:"pipelineRun,omitempty"`
+}
+
+// BulkOperationStatus defines the observed state of BulkOperation
+type BulkOperationStatus struct {
+	// +optional
+	Phase BulkOperationPhase `json:"phase,omitempty"`
+	// StartTime is the time when the operation started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when all the items were operated
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Total is the number of the items to operate
+	// +optional
+	Total int `json:"total,omitempty"`
+	// Succeeded is the number of the items which were operated successfully
+	// +optional
+	Succeeded int `json:"succeeded,omitempty"`
+	// Failed is the number of the failed items
+	// +optional
+	Failed int `json:"failed,omitempty"`
+	// Items are the results of the operated items
+	// +optional
+	Items []BulkOperationItem `json:"items,omitempty"`
+	// Message describes the reason if the operation could not start
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// HasCompleted returns true if all the items were operated
+func (b *BulkOperation) HasCompleted() bool {
+	return b.Status.Phase == BulkOperationSucceeded || b.Status.Phase == BulkOperationFailed
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:categories="devops"
+//+kubebuilder:printcolumn:name="Action",type="string",JSONPath=".spec.action"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.total"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// BulkOperation is the Schema for operating the Pipelines or PipelineRuns of a DevOpsProject asynchronously
+type BulkOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BulkOperationSpec   `json:"spec,omitempty"`
+	Status BulkOperationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BulkOperationList contains a list of BulkOperation
+type BulkOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BulkOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BulkOperation{}, &BulkOperationList{})
+}
@@ -22,6 +22,8 @@ import (
 
 var _ TemplateObject = &ClusterTemplate{}
 
+//+genclient
+//+genclient:nonNamespaced
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
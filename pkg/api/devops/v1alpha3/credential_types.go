@@ -62,11 +62,34 @@ const (
 
 	// SecretTypeKubeConfig contains data.
 	//
-	// Required at least one of fields:
-	// - Secret.Data["secret"] - secret
+	// Required fields:
+	// - Secret.Data["content"] - content of the kubeconfig
 	SecretTypeKubeConfig v1.SecretType = DevOpsCredentialPrefix + "kubeconfig"
 	// KubeConfigSecretKey is the key of the secret for SecretTypeKubeConfig secrets
 	KubeConfigSecretKey = "content"
+
+	// SecretTypeDockerRegistry contains data needed for docker registry authentication.
+	//
+	// Required fields:
+	// - Secret.Data["server"] - address of the docker registry, such as docker.io or https://harbor.example.com
+	// - Secret.Data["username"] - username used for authentication
+	// - Secret.Data["password"] - password or access token needed for authentication
+	SecretTypeDockerRegistry v1.SecretType = DevOpsCredentialPrefix + "docker-registry"
+	// DockerRegistryServerKey is the key of the registry address for SecretTypeDockerRegistry secrets
+	DockerRegistryServerKey = "server"
+	// DockerRegistryUsernameKey is the key of the username for SecretTypeDockerRegistry secrets
+	DockerRegistryUsernameKey = "username"
+	// DockerRegistryPasswordKey is the key of the password for SecretTypeDockerRegistry secrets
+	DockerRegistryPasswordKey = "password"
+
+	// SecretTypeBearerToken contains a bearer token, such as the access token of a SCM or an API.
+	//
+	// Required fields:
+	// - Secret.Data["token"] - bearer token
+	SecretTypeBearerToken v1.SecretType = DevOpsCredentialPrefix + "bearer-token"
+	// BearerTokenKey is the key of the token for SecretTypeBearerToken secrets
+	BearerTokenKey = "token"
+
 	//	CredentialAutoSyncAnnoKey is used to indicate whether the secret is automatically synchronized to devops.
 	//	In the old version, the credential is stored in jenkins and cannot be obtained.
 	//	This field is set to ensure that the secret is not overwritten by a nil value.
@@ -74,6 +97,22 @@ const (
 	CredentialSyncStatusAnnoKey = DevOpsCredentialPrefix + "syncstatus"
 	CredentialSyncTimeAnnoKey   = DevOpsCredentialPrefix + "synctime"
 	CredentialSyncMsgAnnoKey    = DevOpsCredentialPrefix + "syncmsg"
+
+	// CredentialExternalSecretAnnoKey is the name of an ExternalSecret (external-secrets.io) in the same namespace.
+	// The ExternalSecret is responsible for materializing the data of the credential from an external secret store,
+	// such as AWS Secrets Manager or GCP Secret Manager. The credential will not be synced to Jenkins until the data is ready.
+	CredentialExternalSecretAnnoKey = DevOpsCredentialPrefix + "external-secret"
+
+	// CredentialUsedByAnnoKey is the comma-separated names of the Pipelines which reference the credential
+	CredentialUsedByAnnoKey = DevOpsCredentialPrefix + "usedby"
+
+	// CredentialHostnamesAnnoKey is the comma-separated hostnames which the credential is restricted to, such as
+	// github.com,*.example.com. The credential is stored in a Jenkins credential domain of the project folder with the
+	// hostname specification, then Jenkins refuses to use it for the other hosts.
+	CredentialHostnamesAnnoKey = DevOpsCredentialPrefix + "hostnames"
+
+	// CredentialExpirationAnnoKey is the time when the token of an issued kubeconfig credential expires, in RFC3339
+	CredentialExpirationAnnoKey = DevOpsCredentialPrefix + "expiration"
 )
 
 var supportedCredentialTypes = []v1.SecretType{
@@ -81,6 +120,8 @@ var supportedCredentialTypes = []v1.SecretType{
 	SecretTypeSSHAuth,
 	SecretTypeSecretText,
 	SecretTypeKubeConfig,
+	SecretTypeDockerRegistry,
+	SecretTypeBearerToken,
 }
 
 // GetSupportedCredentialTypes gets all supported credential types. The return value is unmodifiable.
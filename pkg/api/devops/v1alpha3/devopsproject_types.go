@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,11 +33,362 @@ const (
 	DevOpsProjectFinalizerName     = "devopsproject.finalizers.kubesphere.io"
 	DevOpeProjectSyncStatusAnnoKey = DevOpsProjectPrefix + "syncstatus"
 	DevOpeProjectSyncTimeAnnoKey   = DevOpsProjectPrefix + "synctime"
+	// DevOpsProjectJenkinsFolderAnnoKey is the name of the Jenkins folder of the DevOpsProject, it's assigned once
+	DevOpsProjectJenkinsFolderAnnoKey = DevOpsProjectPrefix + "jenkins-folder"
 )
 
 // DevOpsProjectSpec defines the desired state of DevOpsProject
 type DevOpsProjectSpec struct {
 	Argo *Argo `json:"argo,omitempty"`
+	// Agent is the default preset of the Jenkins agent pods which run the Pipelines of this project
+	// +optional
+	Agent *AgentPreset `json:"agent,omitempty"`
+	// AgentImagePolicy restricts the container images of the Jenkins agent pods which run the Pipelines of this
+	// project, it applies in addition to the cluster-wide policy
+	// +optional
+	AgentImagePolicy *AgentImagePolicy `json:"agentImagePolicy,omitempty"`
+	// AgentNetwork restricts the egress traffic of the Jenkins agent pods which run the Pipelines of this project
+	// +optional
+	AgentNetwork *AgentNetworkPolicy `json:"agentNetwork,omitempty"`
+	// SecretScan enables scanning the source checkouts of the Pipelines in this project for committed secrets
+	// +optional
+	SecretScan *SecretScanPolicy `json:"secretScan,omitempty"`
+	// LicenseScan enables checking the licenses of the dependencies of the Pipelines in this project
+	// +optional
+	LicenseScan *LicenseScanPolicy `json:"licenseScan,omitempty"`
+	// DeployCredentials are the kubeconfig credentials which are issued for the deploy stages of the Pipelines in this
+	// project, their tokens are short-lived and rotated by the controller
+	// +optional
+	DeployCredentials []DeployCredential `json:"deployCredentials,omitempty"`
+	// StorageEncryption is the encryption of the objects of this project in the object storage, such as the S2I
+	// binaries. The objects are encrypted by the default key of the object storage if it's not set.
+	// +optional
+	StorageEncryption *StorageEncryption `json:"storageEncryption,omitempty"`
+	// Jira links the PipelineRuns of this project to the Jira issues, and reports the results of the PipelineRuns to
+	// the issues
+	// +optional
+	Jira *JiraIntegration `json:"jira,omitempty"`
+	// PackageManagers are the mirrors, proxy and credentials of the package managers, they are rendered into the
+	// settings.xml of Maven and the .npmrc of npm which are mounted into the Jenkins agent pods of this project
+	// +optional
+	PackageManagers *PackageManagers `json:"packageManagers,omitempty"`
+	// BuildCache is the remote layer cache of the image builds, it's shared across the PipelineRuns of this project
+	// +optional
+	BuildCache *BuildCache `json:"buildCache,omitempty"`
+}
+
+// GateMode indicates what to do with a PipelineRun which fails a quality gate, such as the secret scan
+// +kubebuilder:validation:Enum=Enforce;Warn
+type GateMode string
+
+const (
+	// GateModeEnforce stops the PipelineRun
+	GateModeEnforce GateMode = "Enforce"
+	// GateModeWarn only records the findings
+	GateModeWarn GateMode = "Warn"
+)
+
+// SecretScanPolicy is the policy of scanning the source checkouts for committed secrets
+type SecretScanPolicy struct {
+	// Mode is what to do with a PipelineRun when secrets are detected, it's Enforce by default
+	// +optional
+	Mode GateMode `json:"mode,omitempty"`
+	// ExcludePaths are the glob patterns of the files which are not scanned, such as testdata/*.
+	// A pattern without a slash matches the file name in any directory.
+	// +optional
+	ExcludePaths []string `json:"excludePaths,omitempty"`
+}
+
+// GetMode returns the mode of the policy
+func (p *SecretScanPolicy) GetMode() GateMode {
+	if p.Mode == "" {
+		return GateModeEnforce
+	}
+	return p.Mode
+}
+
+// DefaultLicenseReport is the path of the archived CycloneDX SBOM which the licenses are checked from
+const DefaultLicenseReport = "bom.json"
+
+// LicenseScanPolicy is the policy of the licenses of the dependencies, it's checked against the CycloneDX SBOM
+// which is archived by the PipelineRuns
+type LicenseScanPolicy struct {
+	// Mode is what to do with a PipelineRun when the licenses violate the policy, it's Enforce by default
+	// +optional
+	Mode GateMode `json:"mode,omitempty"`
+	// Allow is the list of the allowed SPDX license IDs, such as Apache-2.0.
+	// All licenses except the denied ones are allowed if it's empty.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+	// Deny is the list of the denied SPDX license IDs, such as AGPL-3.0-only
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+	// Report is the path of the archived CycloneDX SBOM in JSON format, it's bom.json by default
+	// +optional
+	Report string `json:"report,omitempty"`
+}
+
+// GetMode returns the mode of the policy
+func (p *LicenseScanPolicy) GetMode() GateMode {
+	if p.Mode == "" {
+		return GateModeEnforce
+	}
+	return p.Mode
+}
+
+// GetReport returns the path of the archived SBOM
+func (p *LicenseScanPolicy) GetReport() string {
+	if p.Report == "" {
+		return DefaultLicenseReport
+	}
+	return p.Report
+}
+
+// JiraIntegration links the PipelineRuns to the Jira issues whose keys are in the branch names or the commit messages,
+// such as DEVOPS-12. The results of the PipelineRuns are commented on the issues when they complete.
+type JiraIntegration struct {
+	// Server is the address of Jira, such as https://jira.example.com
+	Server string `json:"server"`
+	// CredentialID is the name of a basic-auth credential in this project, its username and password are the username
+	// and the API token of Jira
+	CredentialID string `json:"credentialID"`
+	// ProjectKeys are the keys of the Jira projects, such as DEVOPS. The issues of any project are linked if it's empty.
+	// +optional
+	ProjectKeys []string `json:"projectKeys,omitempty"`
+}
+
+// PackageManagers is the configuration of the package managers in the Jenkins agent pods. The credentials are the
+// names of the credentials in this project.
+type PackageManagers struct {
+	// Proxy is the HTTP proxy which both Maven and npm access the repositories through
+	// +optional
+	Proxy *PackageProxy `json:"proxy,omitempty"`
+	// Maven is rendered into the settings.xml of Maven
+	// +optional
+	Maven *MavenSettings `json:"maven,omitempty"`
+	// NPM is rendered into the .npmrc of npm
+	// +optional
+	NPM *NPMSettings `json:"npm,omitempty"`
+}
+
+// PackageProxy is an HTTP proxy of the package managers, it proxies both the HTTP and HTTPS repositories
+type PackageProxy struct {
+	// Host is the host name or IP address of the proxy
+	Host string `json:"host"`
+	// Port is the port of the proxy
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// NonProxyHosts are the hosts which are accessed directly, such as *.example.com
+	// +optional
+	NonProxyHosts []string `json:"nonProxyHosts,omitempty"`
+	// CredentialID is the name of a basic-auth credential of the proxy
+	// +optional
+	CredentialID string `json:"credentialID,omitempty"`
+}
+
+// MavenSettings are the mirrors and the server credentials of Maven
+type MavenSettings struct {
+	// Mirrors are the mirrors of the repositories, such as a Nexus which mirrors the central repository
+	// +optional
+	Mirrors []MavenMirror `json:"mirrors,omitempty"`
+	// Servers are the credentials of the repositories which are declared in the POMs, such as the repositories
+	// of the distributionManagement
+	// +optional
+	Servers []MavenServer `json:"servers,omitempty"`
+}
+
+// MavenMirror is a mirror of the Maven repositories
+type MavenMirror struct {
+	// ID is the ID of the mirror
+	ID string `json:"id"`
+	// URL is the address of the mirror
+	URL string `json:"url"`
+	// MirrorOf are the IDs of the mirrored repositories, such as central or external:*, it's * by default
+	// +optional
+	MirrorOf string `json:"mirrorOf,omitempty"`
+	// CredentialID is the name of a basic-auth credential of the mirror
+	// +optional
+	CredentialID string `json:"credentialID,omitempty"`
+}
+
+// GetMirrorOf returns the IDs of the mirrored repositories
+func (m *MavenMirror) GetMirrorOf() string {
+	if m.MirrorOf == "" {
+		return "*"
+	}
+	return m.MirrorOf
+}
+
+// MavenServer is the credential of a Maven repository
+type MavenServer struct {
+	// ID is the ID of the repository
+	ID string `json:"id"`
+	// CredentialID is the name of a basic-auth credential of the repository
+	CredentialID string `json:"credentialID"`
+}
+
+// NPMSettings are the registries and their credentials of npm
+type NPMSettings struct {
+	// Registry is the default registry, such as https://registry.npmmirror.com/
+	// +optional
+	Registry string `json:"registry,omitempty"`
+	// CredentialID is the name of a basic-auth or secret-text credential of the default registry, the secret text
+	// is taken as the auth token
+	// +optional
+	CredentialID string `json:"credentialID,omitempty"`
+	// Scopes are the registries of the scoped packages
+	// +optional
+	Scopes []NPMScope `json:"scopes,omitempty"`
+}
+
+// NPMScope is the registry of the packages in a scope
+type NPMScope struct {
+	// Scope is the name of the scope, such as @example
+	Scope string `json:"scope"`
+	// Registry is the registry of the scope
+	Registry string `json:"registry"`
+	// CredentialID is the name of a basic-auth or secret-text credential of the registry
+	// +optional
+	CredentialID string `json:"credentialID,omitempty"`
+}
+
+// BuildCache is where the image builders store the layer cache. Only one of the backends should be set.
+type BuildCache struct {
+	// Registry stores the cache as images in a registry, it's supported by both Kaniko and BuildKit
+	// +optional
+	Registry *RegistryBuildCache `json:"registry,omitempty"`
+	// S3 stores the cache in a bucket of an S3 compatible storage, it's only supported by BuildKit
+	// +optional
+	S3 *S3BuildCache `json:"s3,omitempty"`
+}
+
+// RegistryBuildCache is a repository of the layer cache. The builds push the cache with the registry credential of
+// the build image step, so the credential must be allowed to push to the repository as well.
+type RegistryBuildCache struct {
+	// Repository is the repository of the cache, such as registry.example.com/demo/cache
+	Repository string `json:"repository"`
+	// Insecure allows accessing the registry through plain HTTP, such as an in-cluster cache registry
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// S3BuildCache is a bucket of the layer cache
+type S3BuildCache struct {
+	// Bucket is the name of the bucket
+	Bucket string `json:"bucket"`
+	// Region is the region of the bucket
+	Region string `json:"region"`
+	// Endpoint is the address of an S3 compatible storage, such as http://minio.example.com:9000
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// UsePathStyle accesses the bucket through the path instead of the host name, it's required by MinIO
+	// +optional
+	UsePathStyle bool `json:"usePathStyle,omitempty"`
+	// CredentialID is the name of a basic-auth credential, the username is the access key ID and the password is
+	// the secret access key
+	CredentialID string `json:"credentialID"`
+}
+
+const (
+	// DefaultDeployCredentialRole is the default ClusterRole of a deploy credential
+	DefaultDeployCredentialRole = "edit"
+	// DefaultDeployCredentialExpirationSeconds is the default lifetime of the token of a deploy credential
+	DefaultDeployCredentialExpirationSeconds int64 = 3600
+)
+
+// DeployCredential is a kubeconfig credential in the admin namespace of a DevOpsProject. It's bound to a ServiceAccount
+// which is only allowed to access the target namespace, and the token of the ServiceAccount is bound to the credential.
+type DeployCredential struct {
+	// Name is the name of the kubeconfig credential
+	Name string `json:"name"`
+	// Namespace is the target namespace which the credential is allowed to access, it must belong to the same
+	// workspace as the DevOpsProject
+	Namespace string `json:"namespace"`
+	// ClusterRole is bound to the ServiceAccount in the target namespace, it's edit by default
+	// +kubebuilder:validation:Enum=edit;view
+	// +optional
+	ClusterRole string `json:"clusterRole,omitempty"`
+	// ExpirationSeconds is the lifetime of the token, it's 3600 by default.
+	// The token is rotated when 80% of its lifetime has passed.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// GetClusterRole returns the ClusterRole which is bound to the ServiceAccount
+func (c *DeployCredential) GetClusterRole() string {
+	if c.ClusterRole == "" {
+		return DefaultDeployCredentialRole
+	}
+	return c.ClusterRole
+}
+
+// GetExpirationSeconds returns the lifetime of the token
+func (c *DeployCredential) GetExpirationSeconds() int64 {
+	if c.ExpirationSeconds == nil {
+		return DefaultDeployCredentialExpirationSeconds
+	}
+	return *c.ExpirationSeconds
+}
+
+// AgentPreset is the default settings of Jenkins agent pods, the settings are only applied
+// when the pod template of a Pipeline does not set them
+type AgentPreset struct {
+	// Label is the Jenkins agent label which the preset applies to, such as maven.
+	// The preset applies to all the agents of the project if it's empty.
+	// +optional
+	Label string `json:"label,omitempty"`
+	// Resources are the default resource requests and limits of the agent containers
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector is merged into the node selector of the agent pods
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are the tolerations of the agent pods which do not have any tolerations
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// AgentImagePolicy is the policy of the container images which the Jenkins agent pods are allowed to use
+type AgentImagePolicy struct {
+	// Mode is what to do with an agent pod when its images violate the policy, it's Enforce by default
+	// +optional
+	Mode GateMode `json:"mode,omitempty"`
+	// AllowedRegistries are the registries or repository prefixes which the images must come from, such as
+	// harbor.example.com or docker.io/jenkins. All registries are allowed if it's empty.
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// PinTags requires the images to have an explicit tag other than latest, or a digest
+	// +optional
+	PinTags bool `json:"pinTags,omitempty"`
+	// RequireDigest requires the images to be referenced by a digest, such as alpine@sha256:...
+	// +optional
+	RequireDigest bool `json:"requireDigest,omitempty"`
+}
+
+// GetMode returns the mode of the policy
+func (p *AgentImagePolicy) GetMode() GateMode {
+	if p.Mode == "" {
+		return GateModeEnforce
+	}
+	return p.Mode
+}
+
+// AgentNetworkPolicy is the template of the NetworkPolicy of the Jenkins agent pods. Besides the egress rules, the
+// agent pods are always allowed to reach the DNS and Jenkins.
+type AgentNetworkPolicy struct {
+	// Egress are the egress rules of the agent pods, such as the SCM, the image registries and the proxy.
+	// The agent pods could only reach the DNS and Jenkins if it's empty.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// StorageEncryption is the server-side encryption of the objects in the object storage
+type StorageEncryption struct {
+	// KMSKeyID is the ID or ARN of the AWS KMS key which the objects are encrypted by, the S3 credentials must be
+	// allowed to use the key
+	KMSKeyID string `json:"kmsKeyID"`
 }
 
 // Argo represents the Argo CD specification
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// EphemeralNamespaceFinalizerName is the finalizer of PipelineRun which makes sure its ephemeral namespace is deleted
+	EphemeralNamespaceFinalizerName = "ephemeralnamespace.finalizers.kubesphere.io"
+	// DefaultEphemeralNamespaceExpirationSeconds is the default lifetime of the token of an ephemeral namespace
+	DefaultEphemeralNamespaceExpirationSeconds int64 = 3 * 3600
+)
+
+// DefaultEphemeralNamespaceQuota is the hard limits of an ephemeral namespace which does not set the quota
+var DefaultEphemeralNamespaceQuota = v1.ResourceList{
+	v1.ResourceRequestsCPU:    resource.MustParse("2"),
+	v1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+	v1.ResourceLimitsCPU:      resource.MustParse("4"),
+	v1.ResourceLimitsMemory:   resource.MustParse("8Gi"),
+	v1.ResourcePods:           resource.MustParse("20"),
+}
+
+// EphemeralNamespace asks for a namespace for each PipelineRun, which is deleted after the PipelineRun completes
+type EphemeralNamespace struct {
+	// ClusterRole is bound to the service account of the namespace, it's admin by default.
+	// +optional
+	ClusterRole string `json:"clusterRole,omitempty" description:"cluster role bound in the namespace"`
+	// Quota is the hard limits of the namespace, it's 2 CPUs and 4Gi memory of requests, 4 CPUs and 8Gi memory of
+	// limits, and 20 pods by default.
+	// +optional
+	Quota v1.ResourceList `json:"quota,omitempty" description:"hard limits of the namespace"`
+	// ExpirationSeconds is the lifetime of the token in the kubeconfig, it's 10800 by default.
+	// The token is revoked once the namespace is deleted after the PipelineRun completes.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty" description:"lifetime of the token in the kubeconfig"`
+}
+
+// GetClusterRole returns the cluster role which is bound in the namespace
+func (e *EphemeralNamespace) GetClusterRole() string {
+	if e.ClusterRole == "" {
+		return "admin"
+	}
+	return e.ClusterRole
+}
+
+// GetQuota returns the hard limits of the namespace
+func (e *EphemeralNamespace) GetQuota() v1.ResourceList {
+	if len(e.Quota) == 0 {
+		return DefaultEphemeralNamespaceQuota.DeepCopy()
+	}
+	return e.Quota
+}
+
+// GetExpirationSeconds returns the lifetime of the token in the kubeconfig
+func (e *EphemeralNamespace) GetExpirationSeconds() int64 {
+	if e.ExpirationSeconds == nil {
+		return DefaultEphemeralNamespaceExpirationSeconds
+	}
+	return *e.ExpirationSeconds
+}
+
+// GetEphemeralNamespace returns the ephemeral namespace options of the PipelineRun.
+// The snapshot of Pipeline spec takes precedence over the given Pipeline.
+func (pr *PipelineRun) GetEphemeralNamespace(pipeline *Pipeline) *EphemeralNamespace {
+	if pr.Spec.PipelineSpec != nil {
+		return pr.Spec.PipelineSpec.EphemeralNamespace
+	} else if pipeline != nil {
+		return pipeline.Spec.EphemeralNamespace
+	}
+	return nil
+}
+
+// IsWaitingForEphemeralNamespace returns true if the PipelineRun asks for an ephemeral namespace which is not ready yet.
+func (pr *PipelineRun) IsWaitingForEphemeralNamespace(pipeline *Pipeline) bool {
+	return pr.GetEphemeralNamespace(pipeline) != nil && pr.Annotations[PipelineRunEphemeralNamespaceAnnoKey] == ""
+}
+
+// GetEphemeralKubeConfigName returns the name of the kubeconfig credential of the ephemeral namespace.
+func (pr *PipelineRun) GetEphemeralKubeConfigName() string {
+	return pr.Name + "-kubeconfig"
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FreezePolicy indicates what to do with the new PipelineRuns during a freeze window
+type FreezePolicy string
+
+const (
+	// FreezePolicyQueue holds the new PipelineRuns until the freeze window ends, it's the default policy
+	FreezePolicyQueue FreezePolicy = "Queue"
+	// FreezePolicyReject cancels the new PipelineRuns during the freeze window
+	FreezePolicyReject FreezePolicy = "Reject"
+)
+
+// FreezeWindowSpec defines the periods during which the new PipelineRuns are not allowed to run
+type FreezeWindowSpec struct {
+	// Schedule is a cron expression (minute hour day-of-month month day-of-week) of the start of recurring periods,
+	// it works together with Duration.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// Duration is the length of each recurring period, 31 days at most.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// TimeZone is the location name of the Schedule, such as Asia/Shanghai. It's UTC if empty.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Ranges are the absolute periods.
+	// +optional
+	Ranges []FreezeRange `json:"ranges,omitempty"`
+	// Policy indicates what to do with the new PipelineRuns, Queue or Reject. It's Queue by default.
+	// +kubebuilder:validation:Enum=Queue;Reject
+	// +optional
+	Policy FreezePolicy `json:"policy,omitempty"`
+	// PipelineSelector selects the Pipelines which are frozen, all Pipelines are frozen if it's nil.
+	// +optional
+	PipelineSelector *metav1.LabelSelector `json:"pipelineSelector,omitempty"`
+	// Message tells the users why the Pipelines are frozen.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FreezeRange is an absolute period
+type FreezeRange struct {
+	Start metav1.Time `json:"start"`
+	End   metav1.Time `json:"end"`
+}
+
+// GetPolicy returns the policy of the freeze window
+func (spec *FreezeWindowSpec) GetPolicy() FreezePolicy {
+	if spec.Policy == "" {
+		return FreezePolicyQueue
+	}
+	return spec.Policy
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Policy",type=string,JSONPath=`.spec.policy`
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:categories="devops"
+
+// FreezeWindow freezes the Pipelines of a DevOps project
+type FreezeWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FreezeWindowSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FreezeWindowList contains a list of FreezeWindow
+type FreezeWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FreezeWindow `json:"items"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Policy",type=string,JSONPath=`.spec.policy`
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:scope=Cluster,categories="devops"
+
+// ClusterFreezeWindow freezes the Pipelines of all DevOps projects
+type ClusterFreezeWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FreezeWindowSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterFreezeWindowList contains a list of ClusterFreezeWindow
+type ClusterFreezeWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterFreezeWindow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FreezeWindow{}, &FreezeWindowList{}, &ClusterFreezeWindow{}, &ClusterFreezeWindowList{})
+}
@@ -36,8 +36,100 @@ const (
 	PipelineNameLabelKey = devops.GroupName + "/pipeline"
 	// PipelineRunCreatorAnnoKey is annotation key of PipelineRun's creator
 	PipelineRunCreatorAnnoKey = devops.GroupName + "/creator"
+	// PipelineRunRestoredAnnoKey is annotation key of PipelineRun which was restored from a backup archive.
+	PipelineRunRestoredAnnoKey = devops.GroupName + "/restored"
+	// JenkinsPipelineRunEventAnnoKey is annotation key of the latest event of Jenkins PipelineRun, such as run.started.
+	// The PipelineRun controller relies on the events instead of polling frequently once it has this annotation.
+	JenkinsPipelineRunEventAnnoKey = devops.GroupName + "/jenkins-pipelinerun-event"
+	// PipelineRunWorkflowAnnoKey is annotation key of the Argo Workflow which executes the PipelineRun.
+	PipelineRunWorkflowAnnoKey = devops.GroupName + "/argo-workflow"
+	// PipelineRunSoftStopNodesAnnoKey is annotation key of the nodes which were running when the PipelineRun was requested to soft stop.
+	PipelineRunSoftStopNodesAnnoKey = devops.GroupName + "/soft-stop-nodes"
+	// PipelineRunMatrixParentLabelKey is label key of the PipelineRun which a matrix PipelineRun was expanded from.
+	PipelineRunMatrixParentLabelKey = devops.GroupName + "/matrix-parent"
+	// PipelineRunMatrixCombinationAnnoKey is annotation key of the matrix combination of a PipelineRun, such as go=1.17,os=linux.
+	PipelineRunMatrixCombinationAnnoKey = devops.GroupName + "/matrix-combination"
+	// PipelineRunEphemeralNamespaceAnnoKey is annotation key of the ephemeral namespace which is ready for the PipelineRun.
+	PipelineRunEphemeralNamespaceAnnoKey = devops.GroupName + "/ephemeral-namespace"
+	// EphemeralNamespaceLabelKey is label key of the ephemeral namespaces, the value is the UID of PipelineRun.
+	EphemeralNamespaceLabelKey = devops.GroupName + "/ephemeral-pipelinerun"
+	// PipelineRunChatOpsSourceAnnoKey is annotation key of the chat where the PipelineRun was triggered, such as slack.
+	PipelineRunChatOpsSourceAnnoKey = devops.GroupName + "/chatops-source"
+	// PipelineRunChatOpsReplyAnnoKey is annotation key of the URL to reply the result of PipelineRun to the chat.
+	PipelineRunChatOpsReplyAnnoKey = devops.GroupName + "/chatops-reply-url"
+	// PipelineRunTriggerTokenAnnoKey is annotation key of the ID of trigger token which created the PipelineRun.
+	PipelineRunTriggerTokenAnnoKey = devops.GroupName + "/trigger-token"
+	// PipelineRunIdempotencyKeyAnnoKey is annotation key of the idempotency key of the request which created the PipelineRun.
+	PipelineRunIdempotencyKeyAnnoKey = devops.GroupName + "/idempotency-key"
+	// PipelineRunProvenanceAnnoKey is annotation key of the ConfigMap which stores the SLSA provenance of PipelineRun.
+	PipelineRunProvenanceAnnoKey = devops.GroupName + "/provenance"
+	// PipelineRunProvenanceRekorAnnoKey is annotation key of the UUID of Rekor log entry of the provenance.
+	PipelineRunProvenanceRekorAnnoKey = devops.GroupName + "/provenance-rekor-entry"
+	// PipelineRunArchivedAnnoKey is annotation key of the time when the PipelineRun was archived into the history database.
+	PipelineRunArchivedAnnoKey = devops.GroupName + "/archived"
+	// PipelineRunTriggerAnnoKey is annotation key of the way how the PipelineRun was triggered, such as webhook.
+	PipelineRunTriggerAnnoKey = devops.GroupName + "/trigger"
+	// PipelineRunCostAnnoKey is annotation key of the compute cost of the agent pods of PipelineRun.
+	PipelineRunCostAnnoKey = devops.GroupName + "/cost"
+	// PipelineRunJenkinsfileDigestAnnoKey is annotation key of the SHA-256 digest of the Jenkinsfile which the PipelineRun ran.
+	PipelineRunJenkinsfileDigestAnnoKey = devops.GroupName + "/jenkinsfile-digest"
+	// PipelineRunJenkinsfileRevisionAnnoKey is annotation key of the commit ID which the Jenkinsfile was loaded from.
+	PipelineRunJenkinsfileRevisionAnnoKey = devops.GroupName + "/jenkinsfile-revision"
+	// PipelineRunFindingsAnnoKey is annotation key of the ConfigMap which stores the static analysis findings of PipelineRun.
+	PipelineRunFindingsAnnoKey = devops.GroupName + "/findings"
+	// PipelineRunLogArchiveAnnoKey is annotation key of the progress of archiving the log of PipelineRun into the object storage.
+	PipelineRunLogArchiveAnnoKey = devops.GroupName + "/log-archive"
+	// PipelineRunReconcileStageAnnoKey is annotation key of the stage of reconciling PipelineRun with Jenkins.
+	PipelineRunReconcileStageAnnoKey = devops.GroupName + "/reconcile-stage"
+	// PipelineRunSubmittedAtAnnoKey is annotation key of the time when the controller started to submit the Jenkins build.
+	PipelineRunSubmittedAtAnnoKey = devops.GroupName + "/submitted-at"
+	// PipelineRunEnvironmentsAnnoKey is annotation key of the PipelineEnvironments whose variables were passed to the PipelineRun, such as staging,shared.
+	PipelineRunEnvironmentsAnnoKey = devops.GroupName + "/environments"
+	// PipelineRunReleaseTagAnnoKey is annotation key of the Git tag which was created for the succeeded PipelineRun.
+	PipelineRunReleaseTagAnnoKey = devops.GroupName + "/release-tag"
+	// PipelineRunIssuesAnnoKey is annotation key of the issue keys which are linked to the PipelineRun, separated by commas.
+	PipelineRunIssuesAnnoKey = devops.GroupName + "/issues"
+	// PipelineRunIssuesReportedAnnoKey is annotation key of the issue keys which the result of the PipelineRun was
+	// commented on, separated by commas.
+	PipelineRunIssuesReportedAnnoKey = devops.GroupName + "/issues-reported"
+	// PipelineRunApprovalNoncesAnnoKey is annotation key of the nonces of the input steps whose approval emails were sent,
+	// the links in the emails are valid only until the nonces are consumed.
+	PipelineRunApprovalNoncesAnnoKey = devops.GroupName + "/approval-nonces"
+	// PipelineRunSlackApprovalsAnnoKey is annotation key of the Slack messages of the input steps which are waiting for
+	// approval, the buttons in the messages are valid only until the messages are consumed.
+	PipelineRunSlackApprovalsAnnoKey = devops.GroupName + "/slack-approvals"
+	// PipelineRunBuildCacheAnnoKey is annotation key of the build cache usage of the PipelineRun, such as 3/5 which means
+	// 3 of the 5 cacheable steps of its image builds hit the cache. The usage is only recorded once.
+	PipelineRunBuildCacheAnnoKey = devops.GroupName + "/build-cache"
+	// PipelineRunPostNotifiedAnnoKey is annotation key of the time when the post notifications of the PipelineRun were
+	// sent, the notifications are only sent once.
+	PipelineRunPostNotifiedAnnoKey = devops.GroupName + "/post-notified"
+	// DeployCredentialLabelKey is label key of the resources of the deploy credentials, the value is the DevOpsProject name.
+	DeployCredentialLabelKey = devops.GroupName + "/deploy-credential"
+	// WorkspaceBindingLabelKey is label key of the RoleBindings which were synced from the workspace members, the value is the workspace name.
+	WorkspaceBindingLabelKey = devops.GroupName + "/workspace-binding"
+	// DevOpsProjectLDAPGroupsAnnoKey is annotation key of the LDAP groups which are mapped to the roles of DevOpsProject, such as devs=operator.
+	DevOpsProjectLDAPGroupsAnnoKey = devops.GroupName + "/ldap-groups"
+	// LDAPGroupBindingLabelKey is label key of the RoleBindings which were synced from the members of LDAP groups.
+	LDAPGroupBindingLabelKey = devops.GroupName + "/ldap-group-binding"
+	// PipelineSourceLabelKey is label key of the Pipelines and Templates which are managed by a PipelineSource, the value is its name.
+	PipelineSourceLabelKey = devops.GroupName + "/pipeline-source"
+	// OnboardingLanguageLabelKey is label key of the ClusterTemplates which bootstrap the Pipelines of the onboarded
+	// repositories, the value is the language of the repositories, such as go.
+	OnboardingLanguageLabelKey = devops.GroupName + "/onboarding-language"
+	// PipelineRunMetadataPrefix is the prefix of labels and annotations of PipelineRun which are propagated to the Jenkins build.
+	PipelineRunMetadataPrefix = "metadata.devops.kubesphere.io/"
 	// PipelineRunSCMRefNameField is the field name of SCM reference name in PipelineRun spec.
 	PipelineRunSCMRefNameField = "spec.scm.ref-name"
+	// PipelineRunSCMRefTypeField is the field name of SCM reference type in PipelineRun spec, the pull requests and merge
+	// requests are both indexed as pr.
+	PipelineRunSCMRefTypeField = "spec.scm.ref-type"
+	// PipelineRunPipelineRefField is the field name of the referenced Pipeline name in PipelineRun spec.
+	PipelineRunPipelineRefField = "spec.pipelineRef.name"
+	// PipelineCredentialsField is the field name of the credentials referenced by Pipeline spec.
+	PipelineCredentialsField = "spec.credentials"
+	// GitRepositorySecretField is the field name of the secret referenced by GitRepository spec, the value is namespace/name.
+	GitRepositorySecretField = "spec.secret"
 	// PipelineRunIdentifierIndexerName is an indexer name of PipelineRun identifier.
 	PipelineRunIdentifierIndexerName = "pipelinerun.identifier"
 )
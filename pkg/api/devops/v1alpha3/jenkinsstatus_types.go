@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JenkinsStatusName is the name of the only JenkinsStatus, it's created by the controller
+const JenkinsStatusName = "jenkins"
+
+// ConditionPluginsReady indicates all the required plugins are installed in Jenkins with the minimum versions
+const ConditionPluginsReady ConditionType = "PluginsReady"
+
+// JenkinsStatusSpec defines the plugins which are required in Jenkins
+type JenkinsStatusSpec struct {
+	// Plugins are the required plugins in addition to the built-in requirements, an item overrides the built-in
+	// requirement which has the same name
+	// +optional
+	Plugins []PluginRequirement `json:"plugins,omitempty"`
+}
+
+// PluginRequirement is a plugin which is required in Jenkins
+type PluginRequirement struct {
+	// Name is the short name of the plugin, such as kubernetes
+	Name string `json:"name"`
+	// MinVersion is the minimum version of the plugin, any version is acceptable if it is empty
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+}
+
+// JenkinsStatusStatus defines the observed state of JenkinsStatus
+type JenkinsStatusStatus struct {
+	// Plugins are the states of the required plugins
+	// +optional
+	Plugins []PluginStatus `json:"plugins,omitempty"`
+	// Conditions consist of the condition PluginsReady
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+	// LastCheckTime is the time of checking the plugins of Jenkins last time
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// PluginStatus is the state of a required plugin in Jenkins
+type PluginStatus struct {
+	Name string `json:"name"`
+	// MinVersion is the minimum version of the requirement
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+	// Version is the installed version, it is empty if the plugin is missing
+	// +optional
+	Version string      `json:"version,omitempty"`
+	State   PluginState `json:"state"`
+}
+
+// PluginState represents the state of a required plugin
+type PluginState string
+
+const (
+	// PluginReady indicates the plugin is active with the minimum version at least
+	PluginReady PluginState = "Ready"
+	// PluginMissing indicates the plugin is not installed
+	PluginMissing PluginState = "Missing"
+	// PluginOutdated indicates the installed version is lower than the minimum version
+	PluginOutdated PluginState = "Outdated"
+	// PluginInactive indicates the plugin is installed but disabled or failed to load
+	PluginInactive PluginState = "Inactive"
+)
+
+// GetCondition returns the condition of the type, it's nil if not found
+func (status *JenkinsStatusStatus) GetCondition(conditionType ConditionType) *Condition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates a condition, the transition time is kept if the status is not changed
+func (status *JenkinsStatusStatus) SetCondition(condition Condition) {
+	existing := status.GetCondition(condition.Type)
+	if existing == nil {
+		status.Conditions = append(status.Conditions, condition)
+		return
+	}
+	if existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+	*existing = condition
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,categories="devops"
+//+kubebuilder:printcolumn:name="PluginsReady",type="string",JSONPath=".status.conditions[?(@.type==\"PluginsReady\")].status"
+//+kubebuilder:printcolumn:name="LastCheck",type="date",JSONPath=".status.lastCheckTime"
+
+// JenkinsStatus is the Schema for reporting the health of Jenkins, such as the missing or outdated plugins
+type JenkinsStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   JenkinsStatusSpec   `json:"spec,omitempty"`
+	Status JenkinsStatusStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// JenkinsStatusList contains a list of JenkinsStatus
+type JenkinsStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []JenkinsStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&JenkinsStatus{}, &JenkinsStatusList{})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxMatrixCombinations is the maximum number of PipelineRuns which a matrix can be expanded into.
+const MaxMatrixCombinations = 256
+
+// Matrix describes the axes of parameters, a PipelineRun is expanded into one PipelineRun for each combination.
+type Matrix struct {
+	// Axes are the parameters and their values
+	Axes []MatrixAxis `json:"axes" description:"axes of the matrix"`
+	// Excludes are the combinations which should be skipped.
+	// A combination is excluded if it matches all the parameters of any item.
+	// +optional
+	Excludes []map[string]string `json:"excludes,omitempty" description:"combinations to be excluded"`
+}
+
+// MatrixAxis is a parameter with all its values.
+type MatrixAxis struct {
+	Name   string   `json:"name" description:"name of the parameter"`
+	Values []string `json:"values" description:"values of the parameter"`
+}
+
+// IsEmpty returns true if there is nothing to expand.
+func (m *Matrix) IsEmpty() bool {
+	return m == nil || len(m.Axes) == 0
+}
+
+// Combinations returns all the combinations of the axes in order, except the excluded ones.
+func (m *Matrix) Combinations() (combinations [][]Parameter) {
+	if m.IsEmpty() {
+		return
+	}
+	combinations = [][]Parameter{nil}
+	for _, axis := range m.Axes {
+		var expanded [][]Parameter
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				next := make([]Parameter, len(combination), len(combination)+1)
+				copy(next, combination)
+				expanded = append(expanded, append(next, Parameter{Name: axis.Name, Value: value}))
+			}
+		}
+		combinations = expanded
+	}
+
+	result := combinations[:0]
+	for _, combination := range combinations {
+		if !m.isExcluded(combination) {
+			result = append(result, combination)
+		}
+	}
+	return result
+}
+
+func (m *Matrix) isExcluded(combination []Parameter) bool {
+	for _, exclude := range m.Excludes {
+		if len(exclude) == 0 {
+			continue
+		}
+		matched := 0
+		for _, param := range combination {
+			if value, ok := exclude[param.Name]; ok && value == param.Value {
+				matched++
+			}
+		}
+		if matched == len(exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatMatrixCombination formats a combination as name=value pairs sorted by name, such as go=1.17,os=linux.
+func FormatMatrixCombination(combination []Parameter) string {
+	pairs := make([]string, 0, len(combination))
+	for _, param := range combination {
+		pairs = append(pairs, param.Name+"="+param.Value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// GetMatrix returns the matrix of the PipelineRun if it should be expanded instead of running by itself.
+// The snapshot of Pipeline spec takes precedence over the given Pipeline.
+func (pr *PipelineRun) GetMatrix(pipeline *Pipeline) *Matrix {
+	if pr.Labels[PipelineRunMatrixParentLabelKey] != "" {
+		return nil
+	}
+	var matrix *Matrix
+	if pr.Spec.PipelineSpec != nil {
+		matrix = pr.Spec.PipelineSpec.Matrix
+	} else if pipeline != nil {
+		matrix = pipeline.Spec.Matrix
+	}
+	if matrix.IsEmpty() {
+		return nil
+	}
+	return matrix
+}
+
+// IsMatrixParent returns true if the PipelineRun is expanded into multiple PipelineRuns by the matrix.
+func (pr *PipelineRun) IsMatrixParent(pipeline *Pipeline) bool {
+	return pr.GetMatrix(pipeline) != nil
+}
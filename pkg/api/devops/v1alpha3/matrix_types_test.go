@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatrix_Combinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix *Matrix
+		want   []string
+	}{{
+		name:   "nil matrix",
+		matrix: nil,
+		want:   nil,
+	}, {
+		name: "single axis",
+		matrix: &Matrix{Axes: []MatrixAxis{{
+			Name: "go", Values: []string{"1.17", "1.18"},
+		}}},
+		want: []string{"go=1.17", "go=1.18"},
+	}, {
+		name: "two axes with an exclude",
+		matrix: &Matrix{Axes: []MatrixAxis{{
+			Name: "go", Values: []string{"1.17", "1.18"},
+		}, {
+			Name: "os", Values: []string{"linux", "windows"},
+		}}, Excludes: []map[string]string{{
+			"go": "1.17", "os": "windows",
+		}}},
+		want: []string{"go=1.17,os=linux", "go=1.18,os=linux", "go=1.18,os=windows"},
+	}, {
+		name: "an axis without values",
+		matrix: &Matrix{Axes: []MatrixAxis{{
+			Name: "go", Values: []string{"1.17"},
+		}, {
+			Name: "os",
+		}}},
+		want: nil,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, combination := range tt.matrix.Combinations() {
+				got = append(got, FormatMatrixCombination(combination))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPipelineRun_IsMatrixParent(t *testing.T) {
+	matrix := &Matrix{Axes: []MatrixAxis{{Name: "go", Values: []string{"1.17"}}}}
+	pipeline := &Pipeline{Spec: PipelineSpec{Matrix: matrix}}
+
+	assert.False(t, (&PipelineRun{}).IsMatrixParent(nil))
+	assert.False(t, (&PipelineRun{}).IsMatrixParent(&Pipeline{}))
+	assert.True(t, (&PipelineRun{}).IsMatrixParent(pipeline))
+	// the snapshot takes precedence over the Pipeline
+	assert.False(t, (&PipelineRun{Spec: PipelineRunSpec{PipelineSpec: &PipelineSpec{}}}).IsMatrixParent(pipeline))
+	assert.True(t, (&PipelineRun{Spec: PipelineRunSpec{PipelineSpec: &PipelineSpec{Matrix: matrix}}}).IsMatrixParent(nil))
+	// the expanded PipelineRuns are not parents
+	assert.False(t, (&PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{PipelineRunMatrixParentLabelKey: "parent"},
+	}}).IsMatrixParent(pipeline))
+}
@@ -18,6 +18,8 @@ package v1alpha3
 
 import (
 	"fmt"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -47,6 +49,27 @@ const (
 	PipelineJenkinsfileEditModeAnnoKey = PipelinePrefix + "jenkinsfile.edit.mode"
 	// PipelineJenkinsfileValidateAnnoKey is the annotation key of the Jenkinsfile validate, success or failure
 	PipelineJenkinsfileValidateAnnoKey = PipelinePrefix + "jenkinsfile.validate"
+	// PipelineDriftPolicyAnnoKey is the annotation key of the policy when the Jenkins job drifted from the Pipeline
+	PipelineDriftPolicyAnnoKey = PipelinePrefix + "drift-policy"
+	// PipelineEngineAnnoKey is the annotation key of the engine which executes the PipelineRuns of the Pipeline
+	PipelineEngineAnnoKey = PipelinePrefix + "engine"
+	// PipelineChatOpsCommandsAnnoKey is the annotation key of the chat commands which trigger the Pipeline, separated by comma
+	PipelineChatOpsCommandsAnnoKey = PipelinePrefix + "chatops-commands"
+	// PipelineChatOpsArgsAnnoKey is the annotation key of the parameter names of the chat command arguments, separated by comma
+	PipelineChatOpsArgsAnnoKey = PipelinePrefix + "chatops-args"
+	// PipelinePublicBadgeAnnoKey is the annotation key which allows anyone to get the status badges of the Pipeline, true or false
+	PipelinePublicBadgeAnnoKey = PipelinePrefix + "public-badge"
+	// PipelineAgentSecurityExemptAnnoKey is the annotation key which exempts the agent pods of the Pipeline from the
+	// security baseline, true or false. Only the users who can exempt pipelines/agentsecurity are allowed to set it
+	PipelineAgentSecurityExemptAnnoKey = PipelinePrefix + "agent-security-exempt"
+	// PipelineApproversAnnoKey is the annotation key of the approvers who receive the approval emails of the input steps,
+	// such as alice,bob. The emails are taken from the KubeSphere users
+	PipelineApproversAnnoKey = PipelinePrefix + "approvers"
+	// PipelineSlackApprovalChannelAnnoKey is the annotation key of the Slack channel which the interactive approval
+	// messages of the input steps are posted to, such as C0123456789
+	PipelineSlackApprovalChannelAnnoKey = PipelinePrefix + "slack-approval-channel"
+	// PipelineJenkinsConfigHashAnnoKey is the annotation key of the hash of the Jenkins job config XML which was synced
+	PipelineJenkinsConfigHashAnnoKey = PipelinePrefix + "jenkins-config-hash"
 
 	// PipelineJenkinsfileEditModeJSON indicates the Jenkinsfile editing mode is JSON
 	PipelineJenkinsfileEditModeJSON = "json"
@@ -57,6 +80,18 @@ const (
 	PipelineJenkinsfileValidateSuccess = "success"
 	// PipelineJenkinsfileValidateFailure indicates the Jenkinsfile validate is failure
 	PipelineJenkinsfileValidateFailure = "failure"
+
+	// PipelineDriftPolicyReport indicates only reporting the drift in the status
+	PipelineDriftPolicyReport = "report"
+	// PipelineDriftPolicyRepair indicates repairing the Jenkins job according to the Pipeline
+	PipelineDriftPolicyRepair = "repair"
+	// PipelineDriftPolicyIgnore indicates skipping the drift detection
+	PipelineDriftPolicyIgnore = "ignore"
+
+	// PipelineEngineJenkins indicates the PipelineRuns are executed by Jenkins, it's the default engine
+	PipelineEngineJenkins = "jenkins"
+	// PipelineEngineArgoWorkflows indicates the PipelineRuns are compiled to Argo Workflows
+	PipelineEngineArgoWorkflows = "argo-workflows"
 )
 
 // PipelineSpec defines the desired state of Pipeline
@@ -64,12 +99,159 @@ type PipelineSpec struct {
 	Type                PipelineType         `json:"type" description:"type of devops pipeline, in scm or no scm"`
 	Pipeline            *NoScmPipeline       `json:"pipeline,omitempty" description:"no scm pipeline structs"`
 	MultiBranchPipeline *MultiBranchPipeline `json:"multi_branch_pipeline,omitempty" description:"in scm pipeline structs"`
+	// Matrix expands a PipelineRun into multiple PipelineRuns, one for each combination of the parameters
+	Matrix *Matrix `json:"matrix,omitempty" description:"matrix of parameters"`
+	// EphemeralNamespace asks for a namespace for each PipelineRun, which is deleted after the PipelineRun completes
+	EphemeralNamespace *EphemeralNamespace `json:"ephemeralNamespace,omitempty" description:"ephemeral namespace of each PipelineRun"`
+	// SharedResources are the names of SharedResources which each PipelineRun locks from being triggered to completion
+	SharedResources []string `json:"sharedResources,omitempty" description:"names of the shared resources locked by each PipelineRun"`
+	// Release creates a Git tag and an SCM release of the revision once a PipelineRun succeeds
+	Release *Release `json:"release,omitempty" description:"tag and release of the succeeded PipelineRuns"`
+	// Triggers are the conditions of triggering the Pipeline by SCM webhooks
+	Triggers *PipelineTriggers `json:"triggers,omitempty" description:"conditions of triggering the Pipeline by SCM webhooks"`
+	// Suspend tells all the triggers to ignore the Pipeline, such as the SCM webhooks, the cron, the upstream jobs,
+	// the trigger tokens and the ChatOps commands. It doesn't stop the PipelineRuns which have already started.
+	Suspend bool `json:"suspend,omitempty" description:"ignore all the triggers of the Pipeline"`
+	// SuspendPolicy decides what to do with the PipelineRuns which are not triggered yet while the Pipeline is
+	// suspended. Defaults to Cancel.
+	SuspendPolicy SuspendPolicy `json:"suspendPolicy,omitempty" description:"policy of the queued PipelineRuns while the Pipeline is suspended"`
+
+	// Ownership tells who is responsible for the Pipeline, the owner is validated against the LDAP groups
+	Ownership *PipelineOwnership `json:"ownership,omitempty" description:"owner, team and on-call contact of the Pipeline"`
+
+	// Post are the notifications and the cleanup steps after each PipelineRun completes, like the post section of a
+	// declarative Jenkinsfile
+	Post *PipelinePost `json:"post,omitempty" description:"actions after each PipelineRun completes"`
+}
+
+// PipelineOwnership is the owner and the on-call contact of a Pipeline
+type PipelineOwnership struct {
+	// Owner is the username of the person who is responsible for the Pipeline, it must be a member of the team
+	Owner string `json:"owner,omitempty" description:"username of the owner"`
+	// Team is the LDAP or Active Directory group which the Pipeline belongs to
+	Team string `json:"team,omitempty" description:"LDAP group of the owner"`
+	// Contact is the on-call contact of the Pipeline, such as an email address, a chat channel or a pager
+	Contact string `json:"contact,omitempty" description:"on-call contact"`
+}
+
+// SuspendPolicy is the policy of the queued PipelineRuns while the Pipeline is suspended
+// +kubebuilder:validation:Enum=Cancel;Hold
+type SuspendPolicy string
+
+const (
+	// SuspendPolicyCancel cancels the queued PipelineRuns
+	SuspendPolicyCancel SuspendPolicy = "Cancel"
+	// SuspendPolicyHold holds the queued PipelineRuns until the Pipeline is resumed
+	SuspendPolicyHold SuspendPolicy = "Hold"
+)
+
+// PipelineTriggers are the conditions of triggering a Pipeline by SCM webhooks
+type PipelineTriggers struct {
+	// Paths filters the webhook events by the changed files, all the events trigger the Pipeline if it's empty
+	Paths *PathFilter `json:"paths,omitempty" description:"filter of the changed files"`
+	// Debounce is the window of coalescing the successive webhook events of the same branch into a single
+	// PipelineRun. The PipelineRun waits until no more events come within the window, and the superseded
+	// PipelineRuns are cancelled.
+	Debounce *metav1.Duration `json:"debounce,omitempty" description:"window of coalescing the successive webhook events"`
+	// AutoCancelPullRequests stops the unfinished PipelineRuns of the earlier commits once a new commit of the same
+	// pull request is built
+	AutoCancelPullRequests bool `json:"autoCancelPullRequests,omitempty" description:"stop the superseded PipelineRuns of the same pull request"`
+}
+
+// PathFilter filters the webhook events by the changed files. An event triggers the Pipeline only if one of the
+// changed files matches any of the include globs, and doesn't match any of the exclude globs.
+// The globs are relative to the root of the repository, "**" matches zero or more directories.
+type PathFilter struct {
+	// Include are the globs of the watched files, all the files are watched if it's empty
+	Include []string `json:"include,omitempty" description:"globs of the watched files"`
+	// Exclude are the globs of the ignored files
+	Exclude []string `json:"exclude,omitempty" description:"globs of the ignored files"`
+}
+
+// GetPathFilter returns the path filter of the SCM webhooks, or nil if there is none
+func (s *PipelineSpec) GetPathFilter() *PathFilter {
+	if s.Triggers == nil {
+		return nil
+	}
+	return s.Triggers.Paths
+}
+
+// AutoCancelsPullRequests returns true if the superseded PipelineRuns of the same pull request should be stopped
+func (s *PipelineSpec) AutoCancelsPullRequests() bool {
+	return s.Triggers != nil && s.Triggers.AutoCancelPullRequests
+}
+
+// GetDebounceWindow returns the debounce window of the webhook events, or zero if there is none
+func (s *PipelineSpec) GetDebounceWindow() time.Duration {
+	if s.Triggers == nil || s.Triggers.Debounce == nil {
+		return 0
+	}
+	return s.Triggers.Debounce.Duration
 }
 
 // PipelineStatus defines the observed state of Pipeline
 type PipelineStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// Drift describes whether the Jenkins job was modified out of the Pipeline
+	Drift *PipelineDrift `json:"drift,omitempty"`
+	// SuspendTime is the time since when the Jenkins job has been disabled, it's empty if the Pipeline isn't suspended
+	SuspendTime *metav1.Time `json:"suspendTime,omitempty"`
+	// Runs aggregates the PipelineRuns, so the health of the Pipeline is shown without fetching all its PipelineRuns
+	Runs *PipelineRunsSummary `json:"runs,omitempty"`
+
+	// Ownership is the result of validating the ownership of the Pipeline
+	Ownership *OwnershipStatus `json:"ownership,omitempty"`
+}
+
+// OwnershipStatus is the result of validating the ownership of a Pipeline against the LDAP groups
+type OwnershipStatus struct {
+	// Owner is the owner which was validated
+	Owner string `json:"owner,omitempty"`
+	// Team is the team which was validated
+	Team string `json:"team,omitempty"`
+	// Valid indicates whether the owner is a member of the team
+	Valid bool `json:"valid"`
+	// Reason explains why the ownership is invalid
+	Reason string `json:"reason,omitempty"`
+}
+
+// PipelineRunsSummary is the aggregated status of the PipelineRuns of a Pipeline
+type PipelineRunsSummary struct {
+	// LatestRuns are the latest PipelineRuns of each branch, the branch is empty if it's not a multi-branch Pipeline
+	LatestRuns []BranchRunSummary `json:"latestRuns,omitempty"`
+	// LastSuccessfulRun is the name of the PipelineRun which succeeded last
+	LastSuccessfulRun string `json:"lastSuccessfulRun,omitempty"`
+	// FinishedRuns is the number of the PipelineRuns which succeeded or failed
+	FinishedRuns int `json:"finishedRuns"`
+	// SucceededRuns is the number of the PipelineRuns which succeeded
+	SucceededRuns int `json:"succeededRuns"`
+	// SuccessRate is the percentage of the succeeded PipelineRuns in the finished ones
+	SuccessRate int `json:"successRate"`
+}
+
+// BranchRunSummary is the summary of the latest PipelineRun of a branch
+type BranchRunSummary struct {
+	// Branch is the SCM reference name of the PipelineRun
+	Branch string `json:"branch,omitempty"`
+	// Name is the name of the PipelineRun
+	Name string `json:"name"`
+	// Phase is the phase of the PipelineRun
+	Phase RunPhase `json:"phase,omitempty"`
+	// StartTime is the time when the PipelineRun was triggered
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the PipelineRun completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PipelineDrift represents the configuration drift between the Pipeline and its Jenkins job
+type PipelineDrift struct {
+	// Drifted indicates whether the Jenkins job is different from the one which the Pipeline should generate
+	Drifted bool `json:"drifted"`
+	// Fields are the paths of the different fields in the Pipeline spec, e.g. pipeline.jenkinsfile
+	Fields []string `json:"fields,omitempty"`
+	// LastCheckTime is the time of the last drift detection
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// LastRepairTime is the time of the last repair of the Jenkins job
+	LastRepairTime *metav1.Time `json:"lastRepairTime,omitempty"`
 }
 
 // +genclient
@@ -78,8 +260,10 @@ type PipelineStatus struct {
 // Pipeline is the Schema for the pipelines API
 // +k8s:openapi-gen=true
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The type of a Pipeline"
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`,description="Whether a Pipeline ignores all the triggers"
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="The age of a Pipeline"
 // +kubebuilder:resource:shortName="pip",categories="devops"
+// +kubebuilder:subresource:status
 type Pipeline struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -105,6 +289,27 @@ func (p *Pipeline) IsMultiBranch() bool {
 	return p.Spec.Type == MultiBranchPipelineType
 }
 
+// IsSuspended returns true if the Pipeline ignores all the triggers
+func (p *Pipeline) IsSuspended() bool {
+	return p.Spec.Suspend
+}
+
+// GetSuspendPolicy returns the policy of the queued PipelineRuns while the Pipeline is suspended
+func (p *Pipeline) GetSuspendPolicy() SuspendPolicy {
+	if p.Spec.SuspendPolicy == "" {
+		return SuspendPolicyCancel
+	}
+	return p.Spec.SuspendPolicy
+}
+
+// GetEngine returns the engine which executes the PipelineRuns of this Pipeline.
+func (p *Pipeline) GetEngine() string {
+	if p == nil || p.Annotations[PipelineEngineAnnoKey] == "" {
+		return PipelineEngineJenkins
+	}
+	return p.Annotations[PipelineEngineAnnoKey]
+}
+
 // PipelineType is an alias of string that represents the type of Pipelines
 type PipelineType string
 
@@ -172,6 +377,29 @@ func (b *MultiBranchPipeline) GetGitURL() string {
 	return ""
 }
 
+// GetCredentialID returns the id of the credential which is used to access the SCM
+func (b *MultiBranchPipeline) GetCredentialID() string {
+	switch b.SourceType {
+	case SourceTypeGit:
+		if b.GitSource != nil {
+			return b.GitSource.CredentialId
+		}
+	case SourceTypeGithub:
+		if b.GitHubSource != nil {
+			return b.GitHubSource.CredentialId
+		}
+	case SourceTypeGitlab:
+		if b.GitlabSource != nil {
+			return b.GitlabSource.CredentialId
+		}
+	case SourceTypeBitbucket:
+		if b.BitbucketServerSource != nil {
+			return b.BitbucketServerSource.CredentialId
+		}
+	}
+	return ""
+}
+
 type GitSource struct {
 	ScmId            string          `json:"scm_id,omitempty" description:"uid of scm"`
 	Url              string          `json:"url,omitempty" mapstructure:"url" description:"url of git source"`
@@ -238,6 +466,12 @@ type GitCloneOption struct {
 	Shallow bool `json:"shallow,omitempty" mapstructure:"shallow" description:"Whether to use git shallow clone"`
 	Timeout int  `json:"timeout,omitempty" mapstructure:"timeout" description:"git clone timeout mins"`
 	Depth   int  `json:"depth,omitempty" mapstructure:"depth" description:"git clone depth"`
+	// Submodules checks out the submodules recursively with the credentials of the parent repository
+	Submodules bool `json:"submodules,omitempty" mapstructure:"submodules" description:"Whether to check out the submodules recursively"`
+	// LFS pulls the Git LFS files after checkout
+	LFS bool `json:"lfs,omitempty" mapstructure:"lfs" description:"Whether to pull the Git LFS files after checkout"`
+	// SSHCredentialId is the SSH deploy key used for checkout, the credential_id is still used to scan the repository
+	SSHCredentialId string `json:"ssh_credential_id,omitempty" mapstructure:"ssh_credential_id" description:"SSH deploy key credential id used to check out the source"`
 }
 
 type SvnSource struct {
@@ -18,7 +18,9 @@ package v1alpha3
 
 import (
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"testing"
+	"time"
 )
 
 func TestPipeline_IsMultiBranch(t *testing.T) {
@@ -63,6 +65,16 @@ func TestPipeline_IsMultiBranch(t *testing.T) {
 	}
 }
 
+func TestPipeline_GetEngine(t *testing.T) {
+	var nilPipeline *Pipeline
+	assert.Equal(t, PipelineEngineJenkins, nilPipeline.GetEngine())
+	assert.Equal(t, PipelineEngineJenkins, (&Pipeline{}).GetEngine())
+
+	pipeline := &Pipeline{}
+	pipeline.Annotations = map[string]string{PipelineEngineAnnoKey: PipelineEngineArgoWorkflows}
+	assert.Equal(t, PipelineEngineArgoWorkflows, pipeline.GetEngine())
+}
+
 func TestMultiBranchPipeline_GetGitURL(t *testing.T) {
 	type fields struct {
 		SourceType            string
@@ -124,3 +136,59 @@ func TestMultiBranchPipeline_GetGitURL(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiBranchPipeline_GetCredentialID(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline *MultiBranchPipeline
+		want     string
+	}{{
+		name:     "github",
+		pipeline: &MultiBranchPipeline{SourceType: SourceTypeGithub, GitHubSource: &GithubSource{CredentialId: "github"}},
+		want:     "github",
+	}, {
+		name:     "gitlab",
+		pipeline: &MultiBranchPipeline{SourceType: SourceTypeGitlab, GitlabSource: &GitlabSource{CredentialId: "gitlab"}},
+		want:     "gitlab",
+	}, {
+		name:     "git",
+		pipeline: &MultiBranchPipeline{SourceType: SourceTypeGit, GitSource: &GitSource{CredentialId: "git"}},
+		want:     "git",
+	}, {
+		name: "bitbucket",
+		pipeline: &MultiBranchPipeline{SourceType: SourceTypeBitbucket,
+			BitbucketServerSource: &BitbucketServerSource{CredentialId: "bitbucket"}},
+		want: "bitbucket",
+	}, {
+		name:     "source is missing",
+		pipeline: &MultiBranchPipeline{SourceType: SourceTypeGithub},
+		want:     "",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pipeline.GetCredentialID())
+		})
+	}
+}
+
+func TestPipelineSpec_GetPathFilter(t *testing.T) {
+	assert.Nil(t, (&PipelineSpec{}).GetPathFilter())
+	assert.Nil(t, (&PipelineSpec{Triggers: &PipelineTriggers{}}).GetPathFilter())
+
+	filter := &PathFilter{Include: []string{"services/api/**"}}
+	assert.Equal(t, filter, (&PipelineSpec{Triggers: &PipelineTriggers{Paths: filter}}).GetPathFilter())
+}
+
+func TestPipelineSpec_AutoCancelsPullRequests(t *testing.T) {
+	assert.False(t, (&PipelineSpec{}).AutoCancelsPullRequests())
+	assert.False(t, (&PipelineSpec{Triggers: &PipelineTriggers{}}).AutoCancelsPullRequests())
+	assert.True(t, (&PipelineSpec{Triggers: &PipelineTriggers{AutoCancelPullRequests: true}}).AutoCancelsPullRequests())
+}
+
+func TestPipelineSpec_GetDebounceWindow(t *testing.T) {
+	assert.Equal(t, time.Duration(0), (&PipelineSpec{}).GetDebounceWindow())
+	assert.Equal(t, time.Duration(0), (&PipelineSpec{Triggers: &PipelineTriggers{}}).GetDebounceWindow())
+	assert.Equal(t, time.Minute, (&PipelineSpec{Triggers: &PipelineTriggers{
+		Debounce: &metav1.Duration{Duration: time.Minute},
+	}}).GetDebounceWindow())
+}
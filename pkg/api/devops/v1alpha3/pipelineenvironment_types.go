@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineEnvironmentSpec defines the values which are passed to the PipelineRuns of the selected Pipelines
+type PipelineEnvironmentSpec struct {
+	// PipelineSelector selects the Pipelines, all Pipelines of the DevOps project are selected if it's nil.
+	// +optional
+	PipelineSelector *metav1.LabelSelector `json:"pipelineSelector,omitempty"`
+	// Branches are the glob patterns of the branches or tags, such as release-*. The PipelineRuns of any branch or
+	// without a branch are selected if it's empty.
+	// +optional
+	Branches []string `json:"branches,omitempty"`
+	// Env are the environment variables.
+	// +optional
+	Env []EnvironmentVariable `json:"env,omitempty"`
+	// ConfigFiles are the contents of the ConfigMaps, each one is passed as a variable.
+	// +optional
+	ConfigFiles []EnvironmentConfigFile `json:"configFiles,omitempty"`
+	// SecretRefs are the credentials of the DevOps project, the ID of each one is passed as a variable.
+	// +optional
+	SecretRefs []EnvironmentSecretRef `json:"secretRefs,omitempty"`
+}
+
+// EnvironmentVariable is a variable with a literal value
+type EnvironmentVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// EnvironmentConfigFile is a variable whose value is the content of a key of a ConfigMap
+type EnvironmentConfigFile struct {
+	// Name is the name of the variable.
+	Name string `json:"name"`
+	// ConfigMap is the name of the ConfigMap in the DevOps project.
+	ConfigMap string `json:"configMap"`
+	// Key is the key of the ConfigMap.
+	Key string `json:"key"`
+}
+
+// EnvironmentSecretRef is a variable whose value is the ID of a credential
+type EnvironmentSecretRef struct {
+	// Name is the name of the variable.
+	Name string `json:"name"`
+	// SecretName is the name of the credential in the DevOps project.
+	SecretName string `json:"secretName"`
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Branches",type=string,JSONPath=`.spec.branches`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:categories="devops"
+
+// PipelineEnvironment is the Schema for the environment specifics of Pipelines, such as the variables, config files and
+// credentials of a deployment environment, which are injected into the PipelineRuns instead of the Jenkinsfile
+type PipelineEnvironment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PipelineEnvironmentSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PipelineEnvironmentList contains a list of PipelineEnvironment
+type PipelineEnvironmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineEnvironment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PipelineEnvironment{}, &PipelineEnvironmentList{})
+}
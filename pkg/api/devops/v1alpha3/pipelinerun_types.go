@@ -17,10 +17,12 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"regexp"
 	"sort"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -47,6 +49,18 @@ type PipelineRunSpec struct {
 	// Action indicates what we need to do with current PipelineRun.
 	// +optional
 	Action *Action `json:"action,omitempty"`
+
+	// Priority decides the order of the queued PipelineRuns when the executors of Jenkins are limited.
+	// The PipelineRuns with higher priority are triggered first, it's 0 by default.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a PipelineRun which has finished. Once the TTL expires, the
+	// PipelineRun is deleted along with the resources it owns. It's deleted right after finishing if it's 0,
+	// and it's never deleted automatically if it's unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
 // PipelineRunStatus defines the observed state of PipelineRun
@@ -72,8 +86,162 @@ type PipelineRunStatus struct {
 	// Current phase of PipelineRun.
 	// +optional
 	Phase RunPhase `json:"phase,omitempty"`
+
+	// Description is the description of the Jenkins build, it could be set by the Pipeline or
+	// propagated from the metadata of the PipelineRun.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Badges are the badges which the Pipeline added to the Jenkins build.
+	// +optional
+	Badges []Badge `json:"badges,omitempty"`
+
+	// AgentUsage is the resource usage of the Jenkins agent pods which ran for the PipelineRun, it's sampled from
+	// the metrics server. It's maintained by the agent usage controller.
+	// +optional
+	AgentUsage []AgentPodUsage `json:"agentUsage,omitempty"`
+
+	// SecretScan is the result of scanning the source checkout for committed secrets.
+	// It's maintained by the secret scan controller.
+	// +optional
+	SecretScan *SecretScanResult `json:"secretScan,omitempty"`
+
+	// LicenseScan is the result of checking the licenses of the dependencies.
+	// It's maintained by the license scan controller.
+	// +optional
+	LicenseScan *LicenseScanResult `json:"licenseScan,omitempty"`
+}
+
+// SecretScanResult is the result of scanning the source checkout of a PipelineRun for committed secrets.
+type SecretScanResult struct {
+	// Revision is the commit ID which was scanned.
+	Revision string `json:"revision"`
+	// Mode is the mode of the policy when the scan happened.
+	Mode GateMode `json:"mode"`
+	// ScanTime is the time when the scan happened.
+	// +optional
+	ScanTime *metav1.Time `json:"scanTime,omitempty"`
+	// Findings are the detected secrets, the secrets are redacted.
+	// +optional
+	Findings []SecretFinding `json:"findings,omitempty"`
+}
+
+// LicenseScanResult is the result of checking the licenses of the dependencies of a PipelineRun.
+type LicenseScanResult struct {
+	// Report is the path of the archived SBOM which was checked.
+	Report string `json:"report"`
+	// Mode is the mode of the policy when the check happened.
+	Mode GateMode `json:"mode"`
+	// ScanTime is the time when the check happened.
+	// +optional
+	ScanTime *metav1.Time `json:"scanTime,omitempty"`
+	// Components is the number of the components in the SBOM.
+	// +optional
+	Components int32 `json:"components,omitempty"`
+	// Violations are the components whose licenses violate the policy.
+	// +optional
+	Violations []LicenseViolation `json:"violations,omitempty"`
+}
+
+// LicenseViolationReason is the reason why the licenses of a component violate the policy.
+type LicenseViolationReason string
+
+const (
+	// LicenseDenied means a license of the component is denied.
+	LicenseDenied LicenseViolationReason = "Denied"
+	// LicenseNotAllowed means none of the licenses of the component is allowed.
+	LicenseNotAllowed LicenseViolationReason = "NotAllowed"
+	// LicenseUnknown means the component has no license, and there's an allow list.
+	LicenseUnknown LicenseViolationReason = "Unknown"
+)
+
+// LicenseViolation is a component whose licenses violate the policy.
+type LicenseViolation struct {
+	// Component is the name and version of the component, such as github.com/gin-gonic/gin@v1.8.1.
+	Component string `json:"component"`
+	// Licenses are the licenses of the component.
+	// +optional
+	Licenses []string `json:"licenses,omitempty"`
+	// Reason is why the licenses violate the policy.
+	Reason LicenseViolationReason `json:"reason"`
+}
+
+// SecretFinding is a secret which was detected in a file.
+type SecretFinding struct {
+	// Rule is the ID of the rule which detected the secret, such as github-token.
+	Rule string `json:"rule"`
+	// File is the path of the file in the repository.
+	File string `json:"file"`
+	// Line is the line number of the secret in the file.
+	// +optional
+	Line int32 `json:"line,omitempty"`
+	// Secret is the redacted secret, only the first few characters are kept.
+	Secret string `json:"secret"`
+}
+
+// AgentPodUsage is the resource usage of an agent pod.
+type AgentPodUsage struct {
+	// Pod is the name of the agent pod.
+	Pod string `json:"pod"`
+	// Samples is the number of the samples of the resource usage.
+	// +optional
+	Samples int32 `json:"samples,omitempty"`
+	// LastSampleTime is the time of the latest sample.
+	// +optional
+	LastSampleTime *metav1.Time `json:"lastSampleTime,omitempty"`
+	// Containers are the resource usage of the containers.
+	// +optional
+	Containers []ContainerUsage `json:"containers,omitempty"`
 }
 
+// ContainerUsage is the resource usage of a container in an agent pod.
+type ContainerUsage struct {
+	// Name is the name of the container.
+	Name string `json:"name"`
+	// Resources are the resource requests and limits of the container, compare them with the usage for right-sizing.
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// PeakCPU is the maximum CPU usage in the samples.
+	// +optional
+	PeakCPU resource.Quantity `json:"peakCPU,omitempty"`
+	// PeakMemory is the maximum memory working set in the samples.
+	// +optional
+	PeakMemory resource.Quantity `json:"peakMemory,omitempty"`
+	// AverageCPU is the average CPU usage of the samples.
+	// +optional
+	AverageCPU resource.Quantity `json:"averageCPU,omitempty"`
+	// AverageMemory is the average memory working set of the samples.
+	// +optional
+	AverageMemory resource.Quantity `json:"averageMemory,omitempty"`
+	// CPUTimeMillis is the total CPU time in milliseconds which is estimated from the samples.
+	// +optional
+	CPUTimeMillis int64 `json:"cpuTimeMillis,omitempty"`
+}
+
+// GetAgentUsage returns the resource usage of an agent pod, it's nil if the pod is not found
+func (s *PipelineRunStatus) GetAgentUsage(pod string) *AgentPodUsage {
+	for i := range s.AgentUsage {
+		if s.AgentUsage[i].Pod == pod {
+			return &s.AgentUsage[i]
+		}
+	}
+	return nil
+}
+
+// Badge is a badge of Jenkins build, such as the badges added by the step addBadge.
+type Badge struct {
+	// Icon is the icon of the badge, such as success.gif.
+	// +optional
+	Icon string `json:"icon,omitempty"`
+	// Text is the text of the badge.
+	// +optional
+	Text string `json:"text,omitempty"`
+	// Link is the link of the badge.
+	// +optional
+	Link string `json:"link,omitempty"`
+}
+
+// +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.metadata.annotations.devops\.kubesphere\.io/jenkins-pipelinerun-id`,description="The id of a PipelineRun"
@@ -153,9 +321,15 @@ func (pr *PipelineRun) LabelAsAnOrphan() {
 	pr.Labels[PipelineRunOrphanLabelKey] = "true"
 }
 
+// IsStopRequested indicates if someone has requested to stop the PipelineRun.
+func (pr *PipelineRun) IsStopRequested() bool {
+	return pr.Spec.Action != nil && (*pr.Spec.Action == Stop || *pr.Spec.Action == SoftStop)
+}
+
 // Buildable returns true if the PipelineRun is buildable, false otherwise.
 func (pr *PipelineRun) Buildable() bool {
-	return !pr.HasCompleted() && pr.Labels[PipelineRunOrphanLabelKey] != "true"
+	return !pr.HasCompleted() && pr.Labels[PipelineRunOrphanLabelKey] != "true" &&
+		pr.Annotations[PipelineRunRestoredAnnoKey] != "true"
 }
 
 // IsMultiBranchPipeline indicates if the PipelineRun belongs a multi-branch pipeline.
@@ -233,6 +407,58 @@ type SCM struct {
 	RefName string `json:"refName"`
 }
 
+// pullRequestRefNamePattern matches the names of pull requests of multi-branch Pipelines, such as PR-1 or MR-1-head
+var pullRequestRefNamePattern = regexp.MustCompile(`^(PR|MR)-\d+(-.+)?$`)
+
+// IsPullRequest returns true if the reference is a pull request or merge request
+func (scm *SCM) IsPullRequest() bool {
+	return scm.RefType == PullRequest || scm.RefType == MergeRequest || pullRequestRefNamePattern.MatchString(scm.RefName)
+}
+
+// GetRefType returns the reference type which is indexed, the merge requests are treated as pull requests, and the
+// reference is a branch if the type is unknown.
+func (scm *SCM) GetRefType() RefType {
+	switch {
+	case scm.IsPullRequest():
+		return PullRequest
+	case scm.RefType == "":
+		return Branch
+	}
+	return scm.RefType
+}
+
+// ReconcileStage is the stage of reconciling a PipelineRun with Jenkins. It's persisted in the annotations, so the
+// controller resumes from the stage after restarting instead of submitting the Jenkins build again.
+type ReconcileStage string
+
+const (
+	// ReconcileStagePending indicates that the Jenkins build has not been submitted.
+	ReconcileStagePending ReconcileStage = "Pending"
+	// ReconcileStageSubmitting indicates that the Jenkins build might have been submitted, but its run ID was not recorded.
+	ReconcileStageSubmitting ReconcileStage = "Submitting"
+	// ReconcileStageRunning indicates that the Jenkins build was submitted, and its progress is being synced.
+	ReconcileStageRunning ReconcileStage = "Running"
+	// ReconcileStageFinalizing indicates that the Jenkins build has finished, and its result is being recorded.
+	ReconcileStageFinalizing ReconcileStage = "Finalizing"
+)
+
+// GetReconcileStage returns the stage of reconciling the PipelineRun. The stage of the PipelineRuns which were created
+// before the stages were introduced is inferred from the run ID.
+func (pr *PipelineRun) GetReconcileStage() ReconcileStage {
+	stage := ReconcileStage(pr.Annotations[PipelineRunReconcileStageAnnoKey])
+	switch stage {
+	case ReconcileStageRunning, ReconcileStageFinalizing:
+		return stage
+	}
+	if pr.HasStarted() {
+		return ReconcileStageRunning
+	}
+	if stage == ReconcileStageSubmitting {
+		return stage
+	}
+	return ReconcileStagePending
+}
+
 // RunPhase is a label for the condition of a PipelineRun at the current time.
 type RunPhase string
 
@@ -262,6 +488,19 @@ const (
 	// ConditionSucceeded indicates that the pipeline has finished.
 	// For pipeline which runs to completion
 	ConditionSucceeded ConditionType = "Succeeded"
+
+	// ConditionStopped indicates that the pipeline has been requested to stop.
+	// It's unknown until the stopping pipeline has finished.
+	ConditionStopped ConditionType = "Stopped"
+
+	// ConditionFrozen indicates that the pipeline is held by a freeze window.
+	ConditionFrozen ConditionType = "Frozen"
+
+	// ConditionQueued indicates that the pipeline is waiting for the executors.
+	ConditionQueued ConditionType = "Queued"
+
+	// ConditionSuspended indicates that the pipeline is held because its Pipeline is suspended.
+	ConditionSuspended ConditionType = "Suspended"
 )
 
 // ConditionStatus is the status of the current condition.
@@ -311,6 +550,8 @@ type Action string
 const (
 	// Stop indicates we need to stop the current PipelineRun.
 	Stop Action = "Stop"
+	// SoftStop indicates we need to stop the current PipelineRun after the running stages have finished.
+	SoftStop Action = "SoftStop"
 	// Pause indicates we need to pause the current PipelineRun.
 	Pause Action = "Pause"
 	// Resume indicates we need to resume the current PipelineRun.
@@ -327,6 +568,32 @@ const (
 	TriggerFailed string = "TriggerFailed"
 	// RetrieveFailed indicates that it failed to retrieve the latest running data
 	RetrieveFailed string = "RetrieveFailed"
+	// Stopping indicates that the stop request of PipelineRun has been sent to Jenkins
+	Stopping string = "Stopping"
+	// StopFailed indicates that it failed to stop the PipelineRun
+	StopFailed string = "StopFailed"
+	// Frozen indicates that the PipelineRun is held by a freeze window
+	Frozen string = "Frozen"
+	// Queued indicates that the PipelineRun is waiting for the executors
+	Queued string = "Queued"
+	// Preempted indicates that the PipelineRun was taken out of the Jenkins queue by a higher priority one
+	Preempted string = "Preempted"
+	// Superseded indicates that the pending PipelineRun was cancelled by a later webhook event of the same branch
+	Superseded string = "Superseded"
+	// AutoCancelled indicates that the PipelineRun was stopped because a new commit of the same pull request is built
+	AutoCancelled string = "AutoCancelled"
+	// Suspended indicates that the PipelineRun was held or cancelled because its Pipeline is suspended
+	Suspended string = "Suspended"
+	// SecretsDetected indicates that secrets were detected in the source checkout of the PipelineRun
+	SecretsDetected string = "SecretsDetected"
+	// LicenseViolated indicates that the licenses of the dependencies of the PipelineRun violate the policy
+	LicenseViolated string = "LicenseViolated"
+	// Released indicates that the revision of the PipelineRun was tagged and released
+	Released string = "Released"
+	// ReleaseFailed indicates that it failed to create the tag or release of the PipelineRun
+	ReleaseFailed string = "ReleaseFailed"
+	// IssueUpdateFailed indicates that it failed to comment the result of the PipelineRun on the linked issues
+	IssueUpdateFailed string = "IssueUpdateFailed"
 )
 
 func init() {
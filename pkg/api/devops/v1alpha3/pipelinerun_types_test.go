@@ -145,6 +145,16 @@ func TestPipelineRun_Buildable(t *testing.T) {
 		name:   "not completed yet",
 		fields: fields{},
 		want:   true,
+	}, {
+		name: "not buildable due to it was restored from a backup archive",
+		fields: fields{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{
+					PipelineRunRestoredAnnoKey: "true",
+				},
+			},
+		},
+		want: false,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -200,6 +210,49 @@ func TestPipelineRun_HasStarted(t *testing.T) {
 	}
 }
 
+func TestPipelineRun_GetReconcileStage(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        ReconcileStage
+	}{{
+		name: "no annotations",
+		want: ReconcileStagePending,
+	}, {
+		name:        "submitting",
+		annotations: map[string]string{PipelineRunReconcileStageAnnoKey: string(ReconcileStageSubmitting)},
+		want:        ReconcileStageSubmitting,
+	}, {
+		name:        "started before the stages were introduced",
+		annotations: map[string]string{JenkinsPipelineRunIDAnnoKey: "1"},
+		want:        ReconcileStageRunning,
+	}, {
+		name: "the run ID was recorded while submitting",
+		annotations: map[string]string{
+			PipelineRunReconcileStageAnnoKey: string(ReconcileStageSubmitting),
+			JenkinsPipelineRunIDAnnoKey:      "1",
+		},
+		want: ReconcileStageRunning,
+	}, {
+		name: "finalizing",
+		annotations: map[string]string{
+			PipelineRunReconcileStageAnnoKey: string(ReconcileStageFinalizing),
+			JenkinsPipelineRunIDAnnoKey:      "1",
+		},
+		want: ReconcileStageFinalizing,
+	}, {
+		name:        "unknown stage",
+		annotations: map[string]string{PipelineRunReconcileStageAnnoKey: "fake"},
+		want:        ReconcileStagePending,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &PipelineRun{ObjectMeta: v1.ObjectMeta{Annotations: tt.annotations}}
+			assert.Equal(t, tt.want, pr.GetReconcileStage())
+		})
+	}
+}
+
 func TestBuildPipelineRunIdentifier(t *testing.T) {
 	type args struct {
 		pipelineName string
@@ -240,3 +293,39 @@ func TestBuildPipelineRunIdentifier(t *testing.T) {
 		})
 	}
 }
+
+func TestSCM_IsPullRequest(t *testing.T) {
+	tests := []struct {
+		scm  SCM
+		want bool
+	}{
+		{scm: SCM{RefName: "master"}, want: false},
+		{scm: SCM{RefName: "PR-12"}, want: true},
+		{scm: SCM{RefName: "MR-3-head"}, want: true},
+		{scm: SCM{RefName: "PR-review"}, want: false},
+		{scm: SCM{RefType: PullRequest, RefName: "feature"}, want: true},
+		{scm: SCM{RefType: Branch, RefName: "feature"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scm.RefName, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.scm.IsPullRequest())
+		})
+	}
+}
+
+func TestSCM_GetRefType(t *testing.T) {
+	tests := []struct {
+		scm  SCM
+		want RefType
+	}{
+		{scm: SCM{RefName: "master"}, want: Branch},
+		{scm: SCM{RefName: "MR-3-head"}, want: PullRequest},
+		{scm: SCM{RefType: MergeRequest, RefName: "feature"}, want: PullRequest},
+		{scm: SCM{RefType: Tag, RefName: "v1.0.0"}, want: Tag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scm.RefName, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.scm.GetRefType())
+		})
+	}
+}
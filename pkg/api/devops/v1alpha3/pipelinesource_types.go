@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineSourceSpec defines a directory in a Git repository which holds the definitions of Pipelines and Templates
+type PipelineSourceSpec struct {
+	// GitRepository is the name of GitRepository in the same namespace.
+	GitRepository string `json:"gitRepository"`
+	// Ref is the branch, tag or commit to read. It's the default branch if empty.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+	// Path is the directory of the YAML files in the repository. It's the root directory if empty.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Recursive indicates whether to read the YAML files in the subdirectories.
+	// +optional
+	Recursive bool `json:"recursive,omitempty"`
+	// Interval is the period of checking the repository for changes, it's 5 minutes by default.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Prune indicates whether to delete the Pipelines and Templates which were removed from the repository.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+	// Suspend indicates whether to stop synchronizing the repository.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// PipelineSourcePhase represents the phase of a PipelineSource
+type PipelineSourcePhase string
+
+const (
+	// PipelineSourcePhaseSynced indicates the definitions were applied
+	PipelineSourcePhaseSynced PipelineSourcePhase = "Synced"
+	// PipelineSourcePhaseFailed indicates the last synchronization was failed
+	PipelineSourcePhaseFailed PipelineSourcePhase = "Failed"
+)
+
+// PipelineSourceStatus defines the observed state of PipelineSource
+type PipelineSourceStatus struct {
+	Phase PipelineSourcePhase `json:"phase,omitempty"`
+	// Message describes the reason of the failed synchronization
+	Message string `json:"message,omitempty"`
+	// Revision is the commit which was applied
+	Revision string `json:"revision,omitempty"`
+	// ObservedGeneration is the generation of the spec which was applied
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastSyncTime is the time of the last successful synchronization
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// Resources are the Pipelines and Templates which are managed by the PipelineSource
+	Resources []PipelineSourceResource `json:"resources,omitempty"`
+}
+
+// PipelineSourceResource is a resource which was applied from a file in the repository
+type PipelineSourceResource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Path is the file path in the repository
+	Path string `json:"path,omitempty"`
+}
+
+// GetInterval returns the period of checking the repository for changes
+func (s *PipelineSourceSpec) GetInterval() time.Duration {
+	if s.Interval == nil || s.Interval.Duration <= 0 {
+		return DefaultPipelineSourceInterval
+	}
+	return s.Interval.Duration
+}
+
+// DefaultPipelineSourceInterval is the default period of checking the repository of a PipelineSource
+const DefaultPipelineSourceInterval = 5 * time.Minute
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:categories="devops"
+//+kubebuilder:printcolumn:name="Repository",type="string",JSONPath=".spec.gitRepository"
+//+kubebuilder:printcolumn:name="Path",type="string",JSONPath=".spec.path"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.revision"
+//+kubebuilder:printcolumn:name="LastSync",type="date",JSONPath=".status.lastSyncTime"
+
+// PipelineSource is the Schema for managing the Pipelines and Templates from a Git repository
+type PipelineSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PipelineSourceSpec   `json:"spec,omitempty"`
+	Status PipelineSourceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PipelineSourceList contains a list of PipelineSource
+type PipelineSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PipelineSource{}, &PipelineSourceList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// PipelinePost describes the actions after a PipelineRun completes, like the post section of a declarative Jenkinsfile.
+// The actions run in the order of always, failure and success.
+type PipelinePost struct {
+	// Always runs no matter what the result of the PipelineRun is
+	// +optional
+	Always *PostActions `json:"always,omitempty" description:"actions of all the completed PipelineRuns"`
+	// Success runs only if the PipelineRun succeeded
+	// +optional
+	Success *PostActions `json:"success,omitempty" description:"actions of the succeeded PipelineRuns"`
+	// Failure runs only if the PipelineRun didn't succeed, including the cancelled ones
+	// +optional
+	Failure *PostActions `json:"failure,omitempty" description:"actions of the PipelineRuns which didn't succeed"`
+}
+
+// PostActions are the notifications and the cleanup steps of a post condition
+type PostActions struct {
+	// Notifications are the chats which the result of the PipelineRun is sent to
+	// +optional
+	Notifications []PostNotification `json:"notifications,omitempty" description:"chats which the result is sent to"`
+	// Cleanup are the shell scripts which run in the workspace. Only the engine Argo Workflows supports them, use the
+	// post section of the Jenkinsfile instead with the engine Jenkins.
+	// +optional
+	Cleanup []string `json:"cleanup,omitempty" description:"shell scripts which run in the workspace"`
+}
+
+// PostNotification sends the result of a PipelineRun to a chat by its incoming webhook
+type PostNotification struct {
+	// Chat is the kind of the chat, such as slack
+	// +kubebuilder:validation:Enum=slack;dingtalk
+	Chat string `json:"chat" description:"kind of the chat, slack or dingtalk"`
+	// SecretRef is the key of the Secret in the same namespace which stores the URL of the incoming webhook
+	SecretRef v1.SecretKeySelector `json:"secretRef" description:"key of the Secret which stores the incoming webhook URL"`
+}
+
+// PostConditionAlways, PostConditionSuccess and PostConditionFailure are the supported post conditions
+const (
+	PostConditionAlways  = "always"
+	PostConditionSuccess = "success"
+	PostConditionFailure = "failure"
+)
+
+// PostCondition is a post condition with its actions
+type PostCondition struct {
+	Name    string
+	Actions *PostActions
+}
+
+// Conditions returns the post conditions which have actions in the order of always, failure and success
+func (p *PipelinePost) Conditions() (conditions []PostCondition) {
+	if p == nil {
+		return
+	}
+	for _, condition := range []PostCondition{
+		{Name: PostConditionAlways, Actions: p.Always},
+		{Name: PostConditionFailure, Actions: p.Failure},
+		{Name: PostConditionSuccess, Actions: p.Success},
+	} {
+		if condition.Actions != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return
+}
+
+// Matches returns true if the post condition matches the phase of a completed PipelineRun
+func (c PostCondition) Matches(phase RunPhase) bool {
+	switch c.Name {
+	case PostConditionSuccess:
+		return phase == Succeeded
+	case PostConditionFailure:
+		return phase != Succeeded
+	default:
+		return true
+	}
+}
+
+// GetPost returns the post actions of the PipelineRun.
+// The snapshot of Pipeline spec takes precedence over the given Pipeline.
+func (pr *PipelineRun) GetPost(pipeline *Pipeline) *PipelinePost {
+	if pr.Spec.PipelineSpec != nil {
+		return pr.Spec.PipelineSpec.Post
+	} else if pipeline != nil {
+		return pipeline.Spec.Post
+	}
+	return nil
+}
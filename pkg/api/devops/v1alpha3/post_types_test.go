@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelinePost_Conditions(t *testing.T) {
+	always := &PostActions{Cleanup: []string{"make clean"}}
+	success := &PostActions{Cleanup: []string{"echo done"}}
+	failure := &PostActions{Cleanup: []string{"echo failed"}}
+
+	assert.Nil(t, (*PipelinePost)(nil).Conditions())
+	assert.Nil(t, (&PipelinePost{}).Conditions())
+	assert.Equal(t, []PostCondition{
+		{Name: PostConditionAlways, Actions: always},
+		{Name: PostConditionFailure, Actions: failure},
+		{Name: PostConditionSuccess, Actions: success},
+	}, (&PipelinePost{Always: always, Success: success, Failure: failure}).Conditions())
+	assert.Equal(t, []PostCondition{
+		{Name: PostConditionSuccess, Actions: success},
+	}, (&PipelinePost{Success: success}).Conditions())
+}
+
+func TestPostCondition_Matches(t *testing.T) {
+	tests := []struct {
+		condition string
+		phase     RunPhase
+		want      bool
+	}{
+		{condition: PostConditionAlways, phase: Succeeded, want: true},
+		{condition: PostConditionAlways, phase: Failed, want: true},
+		{condition: PostConditionSuccess, phase: Succeeded, want: true},
+		{condition: PostConditionSuccess, phase: Failed, want: false},
+		{condition: PostConditionFailure, phase: Succeeded, want: false},
+		{condition: PostConditionFailure, phase: Failed, want: true},
+		{condition: PostConditionFailure, phase: Cancelled, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+string(tt.phase), func(t *testing.T) {
+			assert.Equal(t, tt.want, PostCondition{Name: tt.condition}.Matches(tt.phase))
+		})
+	}
+}
+
+func TestPipelineRun_GetPost(t *testing.T) {
+	post := &PipelinePost{Always: &PostActions{}}
+	pipeline := &Pipeline{Spec: PipelineSpec{Post: post}}
+
+	assert.Nil(t, (&PipelineRun{}).GetPost(nil))
+	assert.Equal(t, post, (&PipelineRun{}).GetPost(pipeline))
+	// the snapshot takes precedence over the Pipeline
+	assert.Nil(t, (&PipelineRun{Spec: PipelineRunSpec{PipelineSpec: &PipelineSpec{}}}).GetPost(pipeline))
+	assert.Equal(t, post, (&PipelineRun{Spec: PipelineRunSpec{PipelineSpec: &PipelineSpec{Post: post}}}).GetPost(nil))
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+// Release creates an annotated Git tag of the revision once a PipelineRun succeeds, and optionally an SCM release
+// of the tag with the changelog since the previous release
+type Release struct {
+	// Branches are the glob patterns of the branches whose PipelineRuns are released, such as master or release-*.
+	// The PipelineRuns of all branches except the pull requests are released if it's empty.
+	// +optional
+	Branches []string `json:"branches,omitempty" description:"glob patterns of the released branches"`
+	// Tag is a Go template of the tag name, such as v1.0.{{.RunID}}. The fields are .Pipeline, .Branch, .RunID,
+	// .Revision, .ShortRevision, .Date in the format of 20060102, and .Parameters of the PipelineRun.
+	Tag string `json:"tag" description:"template of the tag name"`
+	// Message is a Go template of the message of the annotated tag, it's "Release {{.Tag}}" by default.
+	// +optional
+	Message string `json:"message,omitempty" description:"template of the tag message"`
+	// CreateRelease creates a GitHub or GitLab release of the tag, the changelog is its description.
+	// +optional
+	CreateRelease bool `json:"createRelease,omitempty" description:"create an SCM release of the tag"`
+	// Draft marks the GitHub release as a draft.
+	// +optional
+	Draft bool `json:"draft,omitempty" description:"mark the release as a draft"`
+	// Prerelease marks the GitHub release as a prerelease.
+	// +optional
+	Prerelease bool `json:"prerelease,omitempty" description:"mark the release as a prerelease"`
+}
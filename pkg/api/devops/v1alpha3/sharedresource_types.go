@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedResourceSpec defines a resource which is shared by the PipelineRuns, such as a staging environment
+type SharedResourceSpec struct {
+	// Description tells what the resource is.
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Capacity is the number of PipelineRuns which are able to hold the resource at the same time, it's 1 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Capacity int32 `json:"capacity,omitempty"`
+}
+
+// GetCapacity returns the number of PipelineRuns which are able to hold the resource at the same time
+func (spec *SharedResourceSpec) GetCapacity() int {
+	if spec.Capacity <= 0 {
+		return 1
+	}
+	return int(spec.Capacity)
+}
+
+// SharedResourceStatus defines the holders and the wait queue of a SharedResource
+type SharedResourceStatus struct {
+	// Holders are the PipelineRuns which hold the resource.
+	// +optional
+	Holders []SharedResourceClaim `json:"holders,omitempty"`
+	// Waiting are the PipelineRuns which wait for the resource, the first one acquires it first.
+	// +optional
+	Waiting []SharedResourceClaim `json:"waiting,omitempty"`
+}
+
+// SharedResourceClaim is a PipelineRun which holds or waits for a SharedResource
+type SharedResourceClaim struct {
+	// PipelineRun is the name of the PipelineRun in the same namespace.
+	PipelineRun string `json:"pipelineRun"`
+	// Since is the time when the PipelineRun acquired the resource or began to wait for it.
+	Since metav1.Time `json:"since"`
+}
+
+//+genclient
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Capacity",type=integer,JSONPath=`.spec.capacity`
+//+kubebuilder:printcolumn:name="Holder",type=string,JSONPath=`.status.holders[0].pipelineRun`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+//+kubebuilder:resource:categories="devops"
+
+// SharedResource is a resource locked by the PipelineRuns of a DevOps project, like the lockable resources of Jenkins
+type SharedResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedResourceSpec   `json:"spec,omitempty"`
+	Status SharedResourceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SharedResourceList contains a list of SharedResource
+type SharedResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedResource `json:"items"`
+}
+
+// GetSharedResources returns the names of SharedResources which the PipelineRun has to lock.
+// The snapshot of Pipeline spec takes precedence over the given Pipeline.
+func (pr *PipelineRun) GetSharedResources(pipeline *Pipeline) []string {
+	if pr.Spec.PipelineSpec != nil {
+		return pr.Spec.PipelineSpec.SharedResources
+	} else if pipeline != nil {
+		return pipeline.Spec.SharedResources
+	}
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedResource{}, &SharedResourceList{})
+}
@@ -50,7 +50,10 @@ func (t *StepTemplateSpec) Render(param map[string]interface{}, secret *v1.Secre
 	}
 
 	if err == nil && t.Container != "" {
-		output = fmt.Sprintf(`
+		// the container could refer to the parameters, such as {{.param.builder}}
+		var container string
+		if container, err = dslRender(t.Container, param, secret); err == nil {
+			output = fmt.Sprintf(`
 {
   "arguments": {
 	"isLiteral": true,
@@ -58,7 +61,8 @@ func (t *StepTemplateSpec) Render(param map[string]interface{}, secret *v1.Secre
   },
   "children": [%s],
   "name": "container"
-}`, t.Container, output)
+}`, container, output)
+		}
 	}
 
 	output = jsonFormat(output)
@@ -75,7 +79,7 @@ func jsonFormat(content string) string {
 
 func wrapWithCredential(secretType, secretName, target string) string {
 	switch secretType {
-	case string(v1.SecretTypeBasicAuth), string(SecretTypeBasicAuth):
+	case string(v1.SecretTypeBasicAuth), string(SecretTypeBasicAuth), string(SecretTypeDockerRegistry):
 		target = fmt.Sprintf(`{
       "arguments": {
         "isLiteral": false,
@@ -84,7 +88,7 @@ func wrapWithCredential(secretType, secretName, target string) string {
       "children": [%s],
       "name": "withCredentials"
     }`, secretName, target)
-	case string(v1.SecretTypeBootstrapToken), string(SecretTypeSecretText):
+	case string(v1.SecretTypeBootstrapToken), string(SecretTypeSecretText), string(SecretTypeBearerToken):
 		target = fmt.Sprintf(`{
   "arguments": {
     "isLiteral": false,
@@ -119,7 +123,9 @@ func dslRender(dslTpl string, param map[string]interface{}, secret *v1.Secret) (
 	output = dslTpl
 
 	var tpl *template.Template
-	if tpl, err = template.New("shell").Parse(output); err != nil {
+	if tpl, err = template.New("shell").Funcs(template.FuncMap{
+		"shellQuote": shellQuote,
+	}).Parse(output); err != nil {
 		return
 	}
 
@@ -143,12 +149,26 @@ func shellRender(shellTpl string, param map[string]interface{}, secret *v1.Secre
 	"key": "script",
 	"value": {
 	  "isLiteral": true,
-	  "value": "%s"
+	  "value": %s
 	}
   }
 ],
 "name": "sh"
-}`, strings.ReplaceAll(output, "\n", "\\n"))
+}`, jsonString(output))
 	}
 	return
 }
+
+// shellQuote returns the single-quoted value which is safe to be an argument of the shell scripts
+func shellQuote(value interface{}) string {
+	return "'" + strings.ReplaceAll(fmt.Sprint(value), "'", `'\''`) + "'"
+}
+
+// jsonString returns the quoted JSON string, the quotes and backslashes of the shell scripts are escaped
+func jsonString(value string) string {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSpace(buf.String())
+}
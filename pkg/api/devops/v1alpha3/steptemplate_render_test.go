@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"testing"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_handler_stepTemplateRender(t *testing.T) {
@@ -164,6 +166,14 @@ func Test_wrapWithCredential(t *testing.T) {
 			target:     "echo 1",
 		},
 		want: readFile("testdata/credential-string.json"),
+	}, {
+		name: "secret as bearer token type",
+		args: args{
+			secretType: string(SecretTypeBearerToken),
+			secretName: "config",
+			target:     "echo 1",
+		},
+		want: readFile("testdata/credential-string.json"),
 	}, {
 		name: "secret as ssh auth type",
 		args: args{
@@ -193,3 +203,101 @@ func readFile(file string) string {
 	}
 	return ""
 }
+
+func TestBuiltinStepTemplates(t *testing.T) {
+	kubeconfig := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{Name: "deployer"},
+		Type:       SecretTypeKubeConfig,
+	}
+	registry := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{Name: "registry"},
+		Type:       SecretTypeBasicAuth,
+	}
+	tests := []struct {
+		name   string
+		file   string
+		param  map[string]interface{}
+		secret *v1.Secret
+		want   []string
+	}{{
+		name:   "kustomize by default",
+		file:   "kubernetes-deploy.yaml",
+		param:  map[string]interface{}{"path": "deploy/overlays/prod", "namespace": "prod"},
+		secret: kubeconfig,
+		want: []string{
+			`trap 'rm -f \"$KUBECONFIG\"' EXIT`,
+			"kubectl kustomize 'deploy/overlays/prod' > manifests.yaml",
+			"kubectl apply --server-side --force-conflicts --field-manager=ks-devops -n 'prod' -f manifests.yaml",
+			"--timeout='5m'",
+			"kubeconfigContent(credentialsId: 'deployer', variable: 'VARIABLE')",
+		},
+	}, {
+		name: "helm with a values file",
+		file: "kubernetes-deploy.yaml",
+		param: map[string]interface{}{"renderer": "helm", "path": "charts/demo", "namespace": "prod",
+			"release": "demo", "values": "values-prod.yaml", "timeout": "10m"},
+		secret: kubeconfig,
+		want: []string{
+			"helm template 'demo' 'charts/demo' --namespace 'prod' -f 'values-prod.yaml' > manifests.yaml",
+			"--timeout='10m'",
+		},
+	}, {
+		name:   "quote the parameters of the shell",
+		file:   "kubernetes-deploy.yaml",
+		param:  map[string]interface{}{"renderer": "plain", "path": "deploy; rm -rf /", "namespace": "it's $(id)"},
+		secret: kubeconfig,
+		want: []string{
+			"kubectl create --dry-run=client -o yaml -R -f 'deploy; rm -rf /' > manifests.yaml",
+			`-n 'it'\\''s $(id)' -f manifests.yaml`,
+		},
+	}, {
+		name:   "build an image by Kaniko by default",
+		file:   "build-image.yaml",
+		param:  map[string]interface{}{"image": "registry.example.com/demo/app:v1"},
+		secret: registry,
+		want: []string{
+			`"value": "kaniko"`,
+			"usernamePassword(credentialsId: 'registry'",
+			`printf '{\"auths\":{\"%s\":{\"auth\":\"%s\"}}}' \"$REGISTRY\" \"$AUTH\" > $DOCKER_CONFIG/config.json`,
+			`CACHE_REPO=\nexport DOCKER_CONFIG`,
+			// the cache of the DevOpsProject is used if the cache repository is not given
+			`CACHE_REPO=$BUILD_CACHE_REPOSITORY`,
+			"/kaniko/executor --context . --dockerfile ./Dockerfile --destination $IMAGE $ARGS 2>&1 | tee $LOG",
+			`printf '[build-cache] builder=%s steps=%d cached=%d\\n' kaniko`,
+		},
+	}, {
+		name: "build an image by BuildKit with the cache",
+		file: "build-image.yaml",
+		param: map[string]interface{}{"builder": "buildkit", "image": "registry.example.com/demo/app:v1",
+			"context": "app", "dockerfile": "docker/Dockerfile", "buildArgs": "VERSION=v1 COMMIT=abc",
+			"cacheRepo": "registry.example.com/demo/app-cache"},
+		want: []string{
+			`"value": "buildkit"`,
+			`for arg in VERSION=v1 COMMIT=abc; do ARGS=\"$ARGS --opt build-arg:$arg\"; done`,
+			"CACHE_REPO=registry.example.com/demo/app-cache\\n",
+			`CACHE=type=registry,ref=$BUILD_CACHE_REPOSITORY:$NAME`,
+			`CACHE=type=s3,$BUILD_CACHE_S3,name=$NAME`,
+			`ARGS=\"$ARGS --export-cache $CACHE,mode=max --import-cache $CACHE\"`,
+			"buildctl-daemonless.sh build --progress=plain --frontend dockerfile.v0 --local context=app " +
+				"--local dockerfile=app --opt filename=docker/Dockerfile $ARGS --output type=image,name=$IMAGE,push=true " +
+				"2>&1 | tee $LOG",
+			`printf '[build-cache] builder=%s steps=%d cached=%d\\n' buildkit`,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile("../../../../config/steptemplates/" + tt.file)
+			assert.Nil(t, err)
+			stepTemplate := &ClusterStepTemplate{}
+			assert.Nil(t, yaml.Unmarshal(data, stepTemplate))
+
+			output, err := stepTemplate.Spec.Render(tt.param, tt.secret)
+			assert.Nil(t, err)
+			assert.True(t, json.Valid([]byte(output)), output)
+			for _, item := range tt.want {
+				assert.Contains(t, output, item)
+			}
+			assert.NotContains(t, output, "<no value>")
+		})
+	}
+}
@@ -90,6 +90,8 @@ var (
 	StepTemplatePhaseInit StepTemplatePhase = "init"
 )
 
+//+genclient
+//+genclient:nonNamespaced
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
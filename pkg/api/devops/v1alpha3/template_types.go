@@ -74,6 +74,7 @@ type ParameterValidation struct {
 	Message string `json:"message"`
 }
 
+//+genclient
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
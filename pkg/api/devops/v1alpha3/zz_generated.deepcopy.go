@@ -23,6 +23,7 @@ package v1alpha3
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -206,6 +207,108 @@ func (in *AddonStrategyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPodUsage) DeepCopyInto(out *AgentPodUsage) {
+	*out = *in
+	if in.LastSampleTime != nil {
+		in, out := &in.LastSampleTime, &out.LastSampleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPodUsage.
+func (in *AgentPodUsage) DeepCopy() *AgentPodUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPodUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentImagePolicy) DeepCopyInto(out *AgentImagePolicy) {
+	*out = *in
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentImagePolicy.
+func (in *AgentImagePolicy) DeepCopy() *AgentImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentImagePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentNetworkPolicy) DeepCopyInto(out *AgentNetworkPolicy) {
+	*out = *in
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentNetworkPolicy.
+func (in *AgentNetworkPolicy) DeepCopy() *AgentNetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentNetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPreset) DeepCopyInto(out *AgentPreset) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPreset.
+func (in *AgentPreset) DeepCopy() *AgentPreset {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPreset)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationDestination) DeepCopyInto(out *ApplicationDestination) {
 	*out = *in
@@ -295,51 +398,25 @@ func (in *Argo) DeepCopy() *Argo {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BitbucketServerSource) DeepCopyInto(out *BitbucketServerSource) {
-	*out = *in
-	if in.DiscoverPRFromForks != nil {
-		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
-		*out = new(DiscoverPRFromForks)
-		**out = **in
-	}
-	if in.CloneOption != nil {
-		in, out := &in.CloneOption, &out.CloneOption
-		*out = new(GitCloneOption)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketServerSource.
-func (in *BitbucketServerSource) DeepCopy() *BitbucketServerSource {
-	if in == nil {
-		return nil
-	}
-	out := new(BitbucketServerSource)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStepTemplate) DeepCopyInto(out *ClusterStepTemplate) {
+func (in *ArtifactRoutingPolicy) DeepCopyInto(out *ArtifactRoutingPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStepTemplate.
-func (in *ClusterStepTemplate) DeepCopy() *ClusterStepTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactRoutingPolicy.
+func (in *ArtifactRoutingPolicy) DeepCopy() *ArtifactRoutingPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStepTemplate)
+	out := new(ArtifactRoutingPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterStepTemplate) DeepCopyObject() runtime.Object {
+func (in *ArtifactRoutingPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -347,31 +424,31 @@ func (in *ClusterStepTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStepTemplateList) DeepCopyInto(out *ClusterStepTemplateList) {
+func (in *ArtifactRoutingPolicyList) DeepCopyInto(out *ArtifactRoutingPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterStepTemplate, len(*in))
+		*out = make([]ArtifactRoutingPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStepTemplateList.
-func (in *ClusterStepTemplateList) DeepCopy() *ClusterStepTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactRoutingPolicyList.
+func (in *ArtifactRoutingPolicyList) DeepCopy() *ArtifactRoutingPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStepTemplateList)
+	out := new(ArtifactRoutingPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterStepTemplateList) DeepCopyObject() runtime.Object {
+func (in *ArtifactRoutingPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -379,102 +456,139 @@ func (in *ClusterStepTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterTemplate) DeepCopyInto(out *ClusterTemplate) {
+func (in *ArtifactRoutingPolicySpec) DeepCopyInto(out *ArtifactRoutingPolicySpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workspaces != nil {
+		in, out := &in.Workspaces, &out.Workspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplate.
-func (in *ClusterTemplate) DeepCopy() *ClusterTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactRoutingPolicySpec.
+func (in *ArtifactRoutingPolicySpec) DeepCopy() *ArtifactRoutingPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterTemplate)
+	out := new(ArtifactRoutingPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterTemplate) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Badge) DeepCopyInto(out *Badge) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Badge.
+func (in *Badge) DeepCopy() *Badge {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(Badge)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterTemplateList) DeepCopyInto(out *ClusterTemplateList) {
+func (in *BranchRunSummary) DeepCopyInto(out *BranchRunSummary) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ClusterTemplate, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateList.
-func (in *ClusterTemplateList) DeepCopy() *ClusterTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchRunSummary.
+func (in *BranchRunSummary) DeepCopy() *BranchRunSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterTemplateList)
+	out := new(BranchRunSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterTemplateList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitbucketServerSource) DeepCopyInto(out *BitbucketServerSource) {
+	*out = *in
+	if in.DiscoverPRFromForks != nil {
+		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
+		*out = new(DiscoverPRFromForks)
+		**out = **in
 	}
-	return nil
+	if in.CloneOption != nil {
+		in, out := &in.CloneOption, &out.CloneOption
+		*out = new(GitCloneOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketServerSource.
+func (in *BitbucketServerSource) DeepCopy() *BitbucketServerSource {
+	if in == nil {
+		return nil
+	}
+	out := new(BitbucketServerSource)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
+func (in *BuildCache) DeepCopyInto(out *BuildCache) {
 	*out = *in
-	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(RegistryBuildCache)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BuildCache)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildCache.
+func (in *BuildCache) DeepCopy() *BuildCache {
 	if in == nil {
 		return nil
 	}
-	out := new(Condition)
+	out := new(BuildCache)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DevOpsProject) DeepCopyInto(out *DevOpsProject) {
+func (in *BulkOperation) DeepCopyInto(out *BulkOperation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProject.
-func (in *DevOpsProject) DeepCopy() *DevOpsProject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkOperation.
+func (in *BulkOperation) DeepCopy() *BulkOperation {
 	if in == nil {
 		return nil
 	}
-	out := new(DevOpsProject)
+	out := new(BulkOperation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DevOpsProject) DeepCopyObject() runtime.Object {
+func (in *BulkOperation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -482,31 +596,46 @@ func (in *DevOpsProject) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DevOpsProjectList) DeepCopyInto(out *DevOpsProjectList) {
+func (in *BulkOperationItem) DeepCopyInto(out *BulkOperationItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkOperationItem.
+func (in *BulkOperationItem) DeepCopy() *BulkOperationItem {
+	if in == nil {
+		return nil
+	}
+	out := new(BulkOperationItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BulkOperationList) DeepCopyInto(out *BulkOperationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DevOpsProject, len(*in))
+		*out = make([]BulkOperation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectList.
-func (in *DevOpsProjectList) DeepCopy() *DevOpsProjectList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkOperationList.
+func (in *BulkOperationList) DeepCopy() *BulkOperationList {
 	if in == nil {
 		return nil
 	}
-	out := new(DevOpsProjectList)
+	out := new(BulkOperationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DevOpsProjectList) DeepCopyObject() runtime.Object {
+func (in *BulkOperationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -514,146 +643,2073 @@ func (in *DevOpsProjectList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DevOpsProjectSpec) DeepCopyInto(out *DevOpsProjectSpec) {
+func (in *BulkOperationSpec) DeepCopyInto(out *BulkOperationSpec) {
 	*out = *in
-	if in.Argo != nil {
-		in, out := &in.Argo, &out.Argo
-		*out = new(Argo)
-		(*in).DeepCopyInto(*out)
+	if in.Pipelines != nil {
+		in, out := &in.Pipelines, &out.Pipelines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectSpec.
-func (in *DevOpsProjectSpec) DeepCopy() *DevOpsProjectSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkOperationSpec.
+func (in *BulkOperationSpec) DeepCopy() *BulkOperationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DevOpsProjectSpec)
+	out := new(BulkOperationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DevOpsProjectStatus) DeepCopyInto(out *DevOpsProjectStatus) {
+func (in *BulkOperationStatus) DeepCopyInto(out *BulkOperationStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BulkOperationItem, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectStatus.
-func (in *DevOpsProjectStatus) DeepCopy() *DevOpsProjectStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BulkOperationStatus.
+func (in *BulkOperationStatus) DeepCopy() *BulkOperationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DevOpsProjectStatus)
+	out := new(BulkOperationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DiscarderProperty) DeepCopyInto(out *DiscarderProperty) {
+func (in *ClusterFreezeWindow) DeepCopyInto(out *ClusterFreezeWindow) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscarderProperty.
-func (in *DiscarderProperty) DeepCopy() *DiscarderProperty {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFreezeWindow.
+func (in *ClusterFreezeWindow) DeepCopy() *ClusterFreezeWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(DiscarderProperty)
+	out := new(ClusterFreezeWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DiscoverPRFromForks) DeepCopyInto(out *DiscoverPRFromForks) {
-	*out = *in
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterFreezeWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoverPRFromForks.
-func (in *DiscoverPRFromForks) DeepCopy() *DiscoverPRFromForks {
-	if in == nil {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFreezeWindowList) DeepCopyInto(out *ClusterFreezeWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterFreezeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFreezeWindowList.
+func (in *ClusterFreezeWindowList) DeepCopy() *ClusterFreezeWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFreezeWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterFreezeWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStepTemplate) DeepCopyInto(out *ClusterStepTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStepTemplate.
+func (in *ClusterStepTemplate) DeepCopy() *ClusterStepTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStepTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterStepTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStepTemplateList) DeepCopyInto(out *ClusterStepTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterStepTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStepTemplateList.
+func (in *ClusterStepTemplateList) DeepCopy() *ClusterStepTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStepTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterStepTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTemplate) DeepCopyInto(out *ClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplate.
+func (in *ClusterTemplate) DeepCopy() *ClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTemplateList) DeepCopyInto(out *ClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateList.
+func (in *ClusterTemplateList) DeepCopy() *ClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerUsage) DeepCopyInto(out *ContainerUsage) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	out.PeakCPU = in.PeakCPU.DeepCopy()
+	out.PeakMemory = in.PeakMemory.DeepCopy()
+	out.AverageCPU = in.AverageCPU.DeepCopy()
+	out.AverageMemory = in.AverageMemory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerUsage.
+func (in *ContainerUsage) DeepCopy() *ContainerUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsBackup) DeepCopyInto(out *DevOpsBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsBackup.
+func (in *DevOpsBackup) DeepCopy() *DevOpsBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevOpsBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsBackupList) DeepCopyInto(out *DevOpsBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DevOpsBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsBackupList.
+func (in *DevOpsBackupList) DeepCopy() *DevOpsBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevOpsBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsBackupSpec) DeepCopyInto(out *DevOpsBackupSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DevOpsProjects != nil {
+		in, out := &in.DevOpsProjects, &out.DevOpsProjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.EncryptionSecret = in.EncryptionSecret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsBackupSpec.
+func (in *DevOpsBackupSpec) DeepCopy() *DevOpsBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsBackupStatus) DeepCopyInto(out *DevOpsBackupStatus) {
+	*out = *in
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Archives != nil {
+		in, out := &in.Archives, &out.Archives
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsBackupStatus.
+func (in *DevOpsBackupStatus) DeepCopy() *DevOpsBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployCredential) DeepCopyInto(out *DeployCredential) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployCredential.
+func (in *DeployCredential) DeepCopy() *DeployCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsProject) DeepCopyInto(out *DevOpsProject) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProject.
+func (in *DevOpsProject) DeepCopy() *DevOpsProject {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevOpsProject) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsProjectList) DeepCopyInto(out *DevOpsProjectList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DevOpsProject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectList.
+func (in *DevOpsProjectList) DeepCopy() *DevOpsProjectList {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsProjectList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DevOpsProjectList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsProjectSpec) DeepCopyInto(out *DevOpsProjectSpec) {
+	*out = *in
+	if in.Argo != nil {
+		in, out := &in.Argo, &out.Argo
+		*out = new(Argo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(AgentPreset)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AgentImagePolicy != nil {
+		in, out := &in.AgentImagePolicy, &out.AgentImagePolicy
+		*out = new(AgentImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AgentNetwork != nil {
+		in, out := &in.AgentNetwork, &out.AgentNetwork
+		*out = new(AgentNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretScan != nil {
+		in, out := &in.SecretScan, &out.SecretScan
+		*out = new(SecretScanPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LicenseScan != nil {
+		in, out := &in.LicenseScan, &out.LicenseScan
+		*out = new(LicenseScanPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployCredentials != nil {
+		in, out := &in.DeployCredentials, &out.DeployCredentials
+		*out = make([]DeployCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageEncryption != nil {
+		in, out := &in.StorageEncryption, &out.StorageEncryption
+		*out = new(StorageEncryption)
+		**out = **in
+	}
+	if in.Jira != nil {
+		in, out := &in.Jira, &out.Jira
+		*out = new(JiraIntegration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PackageManagers != nil {
+		in, out := &in.PackageManagers, &out.PackageManagers
+		*out = new(PackageManagers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildCache != nil {
+		in, out := &in.BuildCache, &out.BuildCache
+		*out = new(BuildCache)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectSpec.
+func (in *DevOpsProjectSpec) DeepCopy() *DevOpsProjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsProjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevOpsProjectStatus) DeepCopyInto(out *DevOpsProjectStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectStatus.
+func (in *DevOpsProjectStatus) DeepCopy() *DevOpsProjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DevOpsProjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscarderProperty) DeepCopyInto(out *DiscarderProperty) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscarderProperty.
+func (in *DiscarderProperty) DeepCopy() *DiscarderProperty {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscarderProperty)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoverPRFromForks) DeepCopyInto(out *DiscoverPRFromForks) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoverPRFromForks.
+func (in *DiscoverPRFromForks) DeepCopy() *DiscoverPRFromForks {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoverPRFromForks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentConfigFile) DeepCopyInto(out *EnvironmentConfigFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentConfigFile.
+func (in *EnvironmentConfigFile) DeepCopy() *EnvironmentConfigFile {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentConfigFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentSecretRef) DeepCopyInto(out *EnvironmentSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentSecretRef.
+func (in *EnvironmentSecretRef) DeepCopy() *EnvironmentSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentVariable) DeepCopyInto(out *EnvironmentVariable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentVariable.
+func (in *EnvironmentVariable) DeepCopy() *EnvironmentVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralNamespace) DeepCopyInto(out *EphemeralNamespace) {
+	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralNamespace.
+func (in *EphemeralNamespace) DeepCopy() *EphemeralNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeRange) DeepCopyInto(out *FreezeRange) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeRange.
+func (in *FreezeRange) DeepCopy() *FreezeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindow) DeepCopyInto(out *FreezeWindow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindow.
+func (in *FreezeWindow) DeepCopy() *FreezeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FreezeWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowList) DeepCopyInto(out *FreezeWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FreezeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowList.
+func (in *FreezeWindowList) DeepCopy() *FreezeWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FreezeWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowSpec) DeepCopyInto(out *FreezeWindowSpec) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Ranges != nil {
+		in, out := &in.Ranges, &out.Ranges
+		*out = make([]FreezeRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PipelineSelector != nil {
+		in, out := &in.PipelineSelector, &out.PipelineSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowSpec.
+func (in *FreezeWindowSpec) DeepCopy() *FreezeWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericVariable) DeepCopyInto(out *GenericVariable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericVariable.
+func (in *GenericVariable) DeepCopy() *GenericVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericWebhook) DeepCopyInto(out *GenericWebhook) {
+	*out = *in
+	if in.RequestVariables != nil {
+		in, out := &in.RequestVariables, &out.RequestVariables
+		*out = make([]GenericVariable, len(*in))
+		copy(*out, *in)
+	}
+	if in.HeaderVariables != nil {
+		in, out := &in.HeaderVariables, &out.HeaderVariables
+		*out = make([]GenericVariable, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericWebhook.
+func (in *GenericWebhook) DeepCopy() *GenericWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitCloneOption) DeepCopyInto(out *GitCloneOption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCloneOption.
+func (in *GitCloneOption) DeepCopy() *GitCloneOption {
+	if in == nil {
+		return nil
+	}
+	out := new(GitCloneOption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepository) DeepCopyInto(out *GitRepository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepository.
+func (in *GitRepository) DeepCopy() *GitRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitRepository) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepositoryList) DeepCopyInto(out *GitRepositoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryList.
+func (in *GitRepositoryList) DeepCopy() *GitRepositoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepositoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitRepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepositorySpec) DeepCopyInto(out *GitRepositorySpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositorySpec.
+func (in *GitRepositorySpec) DeepCopy() *GitRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepositoryStatus) DeepCopyInto(out *GitRepositoryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryStatus.
+func (in *GitRepositoryStatus) DeepCopy() *GitRepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSource) DeepCopyInto(out *GitSource) {
+	*out = *in
+	if in.CloneOption != nil {
+		in, out := &in.CloneOption, &out.CloneOption
+		*out = new(GitCloneOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
+func (in *GitSource) DeepCopy() *GitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubSource) DeepCopyInto(out *GithubSource) {
+	*out = *in
+	if in.DiscoverPRFromForks != nil {
+		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
+		*out = new(DiscoverPRFromForks)
+		**out = **in
+	}
+	if in.CloneOption != nil {
+		in, out := &in.CloneOption, &out.CloneOption
+		*out = new(GitCloneOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubSource.
+func (in *GithubSource) DeepCopy() *GithubSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabSource) DeepCopyInto(out *GitlabSource) {
+	*out = *in
+	if in.DiscoverPRFromForks != nil {
+		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
+		*out = new(DiscoverPRFromForks)
+		**out = **in
+	}
+	if in.CloneOption != nil {
+		in, out := &in.CloneOption, &out.CloneOption
+		*out = new(GitCloneOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabSource.
+func (in *GitlabSource) DeepCopy() *GitlabSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTToken) DeepCopyInto(out *JWTToken) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTToken.
+func (in *JWTToken) DeepCopy() *JWTToken {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsStatus) DeepCopyInto(out *JenkinsStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsStatus.
+func (in *JenkinsStatus) DeepCopy() *JenkinsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *JenkinsStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsStatusList) DeepCopyInto(out *JenkinsStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]JenkinsStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsStatusList.
+func (in *JenkinsStatusList) DeepCopy() *JenkinsStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *JenkinsStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsStatusSpec) DeepCopyInto(out *JenkinsStatusSpec) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginRequirement, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsStatusSpec.
+func (in *JenkinsStatusSpec) DeepCopy() *JenkinsStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsStatusStatus) DeepCopyInto(out *JenkinsStatusStatus) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsStatusStatus.
+func (in *JenkinsStatusStatus) DeepCopy() *JenkinsStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JiraIntegration) DeepCopyInto(out *JiraIntegration) {
+	*out = *in
+	if in.ProjectKeys != nil {
+		in, out := &in.ProjectKeys, &out.ProjectKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JiraIntegration.
+func (in *JiraIntegration) DeepCopy() *JiraIntegration {
+	if in == nil {
+		return nil
+	}
+	out := new(JiraIntegration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseScanPolicy) DeepCopyInto(out *LicenseScanPolicy) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseScanPolicy.
+func (in *LicenseScanPolicy) DeepCopy() *LicenseScanPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseScanPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseScanResult) DeepCopyInto(out *LicenseScanResult) {
+	*out = *in
+	if in.ScanTime != nil {
+		in, out := &in.ScanTime, &out.ScanTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]LicenseViolation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseScanResult.
+func (in *LicenseScanResult) DeepCopy() *LicenseScanResult {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseScanResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseViolation) DeepCopyInto(out *LicenseViolation) {
+	*out = *in
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseViolation.
+func (in *LicenseViolation) DeepCopy() *LicenseViolation {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseViolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Matrix) DeepCopyInto(out *Matrix) {
+	*out = *in
+	if in.Axes != nil {
+		in, out := &in.Axes, &out.Axes
+		*out = make([]MatrixAxis, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Excludes != nil {
+		in, out := &in.Excludes, &out.Excludes
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Matrix.
+func (in *Matrix) DeepCopy() *Matrix {
+	if in == nil {
+		return nil
+	}
+	out := new(Matrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixAxis) DeepCopyInto(out *MatrixAxis) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixAxis.
+func (in *MatrixAxis) DeepCopy() *MatrixAxis {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixAxis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MavenMirror) DeepCopyInto(out *MavenMirror) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MavenMirror.
+func (in *MavenMirror) DeepCopy() *MavenMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(MavenMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MavenServer) DeepCopyInto(out *MavenServer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MavenServer.
+func (in *MavenServer) DeepCopy() *MavenServer {
+	if in == nil {
+		return nil
+	}
+	out := new(MavenServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MavenSettings) DeepCopyInto(out *MavenSettings) {
+	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]MavenMirror, len(*in))
+		copy(*out, *in)
+	}
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]MavenServer, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MavenSettings.
+func (in *MavenSettings) DeepCopy() *MavenSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(MavenSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiBranchJobTrigger) DeepCopyInto(out *MultiBranchJobTrigger) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiBranchJobTrigger.
+func (in *MultiBranchJobTrigger) DeepCopy() *MultiBranchJobTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiBranchJobTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiBranchPipeline) DeepCopyInto(out *MultiBranchPipeline) {
+	*out = *in
+	if in.Discarder != nil {
+		in, out := &in.Discarder, &out.Discarder
+		*out = new(DiscarderProperty)
+		**out = **in
+	}
+	if in.TimerTrigger != nil {
+		in, out := &in.TimerTrigger, &out.TimerTrigger
+		*out = new(TimerTrigger)
+		**out = **in
+	}
+	if in.GitSource != nil {
+		in, out := &in.GitSource, &out.GitSource
+		*out = new(GitSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHubSource != nil {
+		in, out := &in.GitHubSource, &out.GitHubSource
+		*out = new(GithubSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitlabSource != nil {
+		in, out := &in.GitlabSource, &out.GitlabSource
+		*out = new(GitlabSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SvnSource != nil {
+		in, out := &in.SvnSource, &out.SvnSource
+		*out = new(SvnSource)
+		**out = **in
+	}
+	if in.SingleSvnSource != nil {
+		in, out := &in.SingleSvnSource, &out.SingleSvnSource
+		*out = new(SingleSvnSource)
+		**out = **in
+	}
+	if in.BitbucketServerSource != nil {
+		in, out := &in.BitbucketServerSource, &out.BitbucketServerSource
+		*out = new(BitbucketServerSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MultiBranchJobTrigger != nil {
+		in, out := &in.MultiBranchJobTrigger, &out.MultiBranchJobTrigger
+		*out = new(MultiBranchJobTrigger)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiBranchPipeline.
+func (in *MultiBranchPipeline) DeepCopy() *MultiBranchPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiBranchPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPMScope) DeepCopyInto(out *NPMScope) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPMScope.
+func (in *NPMScope) DeepCopy() *NPMScope {
+	if in == nil {
+		return nil
+	}
+	out := new(NPMScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPMSettings) DeepCopyInto(out *NPMSettings) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]NPMScope, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPMSettings.
+func (in *NPMSettings) DeepCopy() *NPMSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(NPMSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NoScmPipeline) DeepCopyInto(out *NoScmPipeline) {
+	*out = *in
+	if in.Discarder != nil {
+		in, out := &in.Discarder, &out.Discarder
+		*out = new(DiscarderProperty)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterDefinition, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimerTrigger != nil {
+		in, out := &in.TimerTrigger, &out.TimerTrigger
+		*out = new(TimerTrigger)
+		**out = **in
+	}
+	if in.RemoteTrigger != nil {
+		in, out := &in.RemoteTrigger, &out.RemoteTrigger
+		*out = new(RemoteTrigger)
+		**out = **in
+	}
+	if in.GenericWebhook != nil {
+		in, out := &in.GenericWebhook, &out.GenericWebhook
+		*out = new(GenericWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NoScmPipeline.
+func (in *NoScmPipeline) DeepCopy() *NoScmPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(NoScmPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedResourceKey) DeepCopyInto(out *OrphanedResourceKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResourceKey.
+func (in *OrphanedResourceKey) DeepCopy() *OrphanedResourceKey {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedResourceKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedResourcesMonitorSettings) DeepCopyInto(out *OrphanedResourcesMonitorSettings) {
+	*out = *in
+	if in.Warn != nil {
+		in, out := &in.Warn, &out.Warn
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Ignore != nil {
+		in, out := &in.Ignore, &out.Ignore
+		*out = make([]OrphanedResourceKey, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResourcesMonitorSettings.
+func (in *OrphanedResourcesMonitorSettings) DeepCopy() *OrphanedResourcesMonitorSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedResourcesMonitorSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnershipStatus) DeepCopyInto(out *OwnershipStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnershipStatus.
+func (in *OwnershipStatus) DeepCopy() *OwnershipStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnershipStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageManagers) DeepCopyInto(out *PackageManagers) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(PackageProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maven != nil {
+		in, out := &in.Maven, &out.Maven
+		*out = new(MavenSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NPM != nil {
+		in, out := &in.NPM, &out.NPM
+		*out = new(NPMSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageManagers.
+func (in *PackageManagers) DeepCopy() *PackageManagers {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageManagers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageProxy) DeepCopyInto(out *PackageProxy) {
+	*out = *in
+	if in.NonProxyHosts != nil {
+		in, out := &in.NonProxyHosts, &out.NonProxyHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageProxy.
+func (in *PackageProxy) DeepCopy() *PackageProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Parameter) DeepCopyInto(out *Parameter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
+func (in *Parameter) DeepCopy() *Parameter {
+	if in == nil {
+		return nil
+	}
+	out := new(Parameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterDefinition) DeepCopyInto(out *ParameterDefinition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterDefinition.
+func (in *ParameterDefinition) DeepCopy() *ParameterDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterInStep) DeepCopyInto(out *ParameterInStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterInStep.
+func (in *ParameterInStep) DeepCopy() *ParameterInStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterInStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterValidation) DeepCopyInto(out *ParameterValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterValidation.
+func (in *ParameterValidation) DeepCopy() *ParameterValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathFilter) DeepCopyInto(out *PathFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathFilter.
+func (in *PathFilter) DeepCopy() *PathFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(PathFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pipeline) DeepCopyInto(out *Pipeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pipeline.
+func (in *Pipeline) DeepCopy() *Pipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(Pipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Pipeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineDrift) DeepCopyInto(out *PipelineDrift) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRepairTime != nil {
+		in, out := &in.LastRepairTime, &out.LastRepairTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineDrift.
+func (in *PipelineDrift) DeepCopy() *PipelineDrift {
+	if in == nil {
 		return nil
 	}
-	out := new(DiscoverPRFromForks)
+	out := new(PipelineDrift)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GenericVariable) DeepCopyInto(out *GenericVariable) {
+func (in *PipelineEnvironment) DeepCopyInto(out *PipelineEnvironment) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericVariable.
-func (in *GenericVariable) DeepCopy() *GenericVariable {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineEnvironment.
+func (in *PipelineEnvironment) DeepCopy() *PipelineEnvironment {
 	if in == nil {
 		return nil
 	}
-	out := new(GenericVariable)
+	out := new(PipelineEnvironment)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineEnvironment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GenericWebhook) DeepCopyInto(out *GenericWebhook) {
+func (in *PipelineEnvironmentList) DeepCopyInto(out *PipelineEnvironmentList) {
 	*out = *in
-	if in.RequestVariables != nil {
-		in, out := &in.RequestVariables, &out.RequestVariables
-		*out = make([]GenericVariable, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PipelineEnvironment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineEnvironmentList.
+func (in *PipelineEnvironmentList) DeepCopy() *PipelineEnvironmentList {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineEnvironmentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineEnvironmentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineEnvironmentSpec) DeepCopyInto(out *PipelineEnvironmentSpec) {
+	*out = *in
+	if in.PipelineSelector != nil {
+		in, out := &in.PipelineSelector, &out.PipelineSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]EnvironmentVariable, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigFiles != nil {
+		in, out := &in.ConfigFiles, &out.ConfigFiles
+		*out = make([]EnvironmentConfigFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make([]EnvironmentSecretRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineEnvironmentSpec.
+func (in *PipelineEnvironmentSpec) DeepCopy() *PipelineEnvironmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineEnvironmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineList) DeepCopyInto(out *PipelineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Pipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineList.
+func (in *PipelineList) DeepCopy() *PipelineList {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineOwnership) DeepCopyInto(out *PipelineOwnership) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineOwnership.
+func (in *PipelineOwnership) DeepCopy() *PipelineOwnership {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineOwnership)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinePost) DeepCopyInto(out *PipelinePost) {
+	*out = *in
+	if in.Always != nil {
+		in, out := &in.Always, &out.Always
+		*out = new(PostActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Success != nil {
+		in, out := &in.Success, &out.Success
+		*out = new(PostActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failure != nil {
+		in, out := &in.Failure, &out.Failure
+		*out = new(PostActions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinePost.
+func (in *PipelinePost) DeepCopy() *PipelinePost {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinePost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRun) DeepCopyInto(out *PipelineRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRun.
+func (in *PipelineRun) DeepCopy() *PipelineRun {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRunList) DeepCopyInto(out *PipelineRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PipelineRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunList.
+func (in *PipelineRunList) DeepCopy() *PipelineRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRunSpec) DeepCopyInto(out *PipelineRunSpec) {
+	*out = *in
+	if in.PipelineRef != nil {
+		in, out := &in.PipelineRef, &out.PipelineRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.PipelineSpec != nil {
+		in, out := &in.PipelineSpec, &out.PipelineSpec
+		*out = new(PipelineSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]Parameter, len(*in))
 		copy(*out, *in)
 	}
-	if in.HeaderVariables != nil {
-		in, out := &in.HeaderVariables, &out.HeaderVariables
-		*out = make([]GenericVariable, len(*in))
+	if in.SCM != nil {
+		in, out := &in.SCM, &out.SCM
+		*out = new(SCM)
+		**out = **in
+	}
+	if in.Action != nil {
+		in, out := &in.Action, &out.Action
+		*out = new(Action)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunSpec.
+func (in *PipelineRunSpec) DeepCopy() *PipelineRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRunStatus) DeepCopyInto(out *PipelineRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdateTime != nil {
+		in, out := &in.UpdateTime, &out.UpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Badges != nil {
+		in, out := &in.Badges, &out.Badges
+		*out = make([]Badge, len(*in))
 		copy(*out, *in)
 	}
+	if in.AgentUsage != nil {
+		in, out := &in.AgentUsage, &out.AgentUsage
+		*out = make([]AgentPodUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretScan != nil {
+		in, out := &in.SecretScan, &out.SecretScan
+		*out = new(SecretScanResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LicenseScan != nil {
+		in, out := &in.LicenseScan, &out.LicenseScan
+		*out = new(LicenseScanResult)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericWebhook.
-func (in *GenericWebhook) DeepCopy() *GenericWebhook {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunStatus.
+func (in *PipelineRunStatus) DeepCopy() *PipelineRunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GenericWebhook)
+	out := new(PipelineRunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitCloneOption) DeepCopyInto(out *GitCloneOption) {
+func (in *PipelineRunsSummary) DeepCopyInto(out *PipelineRunsSummary) {
 	*out = *in
+	if in.LatestRuns != nil {
+		in, out := &in.LatestRuns, &out.LatestRuns
+		*out = make([]BranchRunSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCloneOption.
-func (in *GitCloneOption) DeepCopy() *GitCloneOption {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunsSummary.
+func (in *PipelineRunsSummary) DeepCopy() *PipelineRunsSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(GitCloneOption)
+	out := new(PipelineRunsSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitRepository) DeepCopyInto(out *GitRepository) {
+func (in *PipelineSource) DeepCopyInto(out *PipelineSource) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepository.
-func (in *GitRepository) DeepCopy() *GitRepository {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSource.
+func (in *PipelineSource) DeepCopy() *PipelineSource {
 	if in == nil {
 		return nil
 	}
-	out := new(GitRepository)
+	out := new(PipelineSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitRepository) DeepCopyObject() runtime.Object {
+func (in *PipelineSource) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -661,31 +2717,31 @@ func (in *GitRepository) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitRepositoryList) DeepCopyInto(out *GitRepositoryList) {
+func (in *PipelineSourceList) DeepCopyInto(out *PipelineSourceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GitRepository, len(*in))
+		*out = make([]PipelineSource, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryList.
-func (in *GitRepositoryList) DeepCopy() *GitRepositoryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSourceList.
+func (in *PipelineSourceList) DeepCopy() *PipelineSourceList {
 	if in == nil {
 		return nil
 	}
-	out := new(GitRepositoryList)
+	out := new(PipelineSourceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitRepositoryList) DeepCopyObject() runtime.Object {
+func (in *PipelineSourceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -693,655 +2749,582 @@ func (in *GitRepositoryList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitRepositorySpec) DeepCopyInto(out *GitRepositorySpec) {
+func (in *PipelineSourceResource) DeepCopyInto(out *PipelineSourceResource) {
 	*out = *in
-	if in.Secret != nil {
-		in, out := &in.Secret, &out.Secret
-		*out = new(corev1.SecretReference)
-		**out = **in
-	}
-	if in.Webhooks != nil {
-		in, out := &in.Webhooks, &out.Webhooks
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositorySpec.
-func (in *GitRepositorySpec) DeepCopy() *GitRepositorySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSourceResource.
+func (in *PipelineSourceResource) DeepCopy() *PipelineSourceResource {
 	if in == nil {
 		return nil
 	}
-	out := new(GitRepositorySpec)
+	out := new(PipelineSourceResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitRepositoryStatus) DeepCopyInto(out *GitRepositoryStatus) {
+func (in *PipelineSourceSpec) DeepCopyInto(out *PipelineSourceSpec) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryStatus.
-func (in *GitRepositoryStatus) DeepCopy() *GitRepositoryStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSourceSpec.
+func (in *PipelineSourceSpec) DeepCopy() *PipelineSourceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitRepositoryStatus)
+	out := new(PipelineSourceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitSource) DeepCopyInto(out *GitSource) {
+func (in *PipelineSourceStatus) DeepCopyInto(out *PipelineSourceStatus) {
 	*out = *in
-	if in.CloneOption != nil {
-		in, out := &in.CloneOption, &out.CloneOption
-		*out = new(GitCloneOption)
-		**out = **in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PipelineSourceResource, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
-func (in *GitSource) DeepCopy() *GitSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSourceStatus.
+func (in *PipelineSourceStatus) DeepCopy() *PipelineSourceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GitSource)
+	out := new(PipelineSourceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubSource) DeepCopyInto(out *GithubSource) {
+func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
-	if in.DiscoverPRFromForks != nil {
-		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
-		*out = new(DiscoverPRFromForks)
-		**out = **in
+	if in.Pipeline != nil {
+		in, out := &in.Pipeline, &out.Pipeline
+		*out = new(NoScmPipeline)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.CloneOption != nil {
-		in, out := &in.CloneOption, &out.CloneOption
-		*out = new(GitCloneOption)
+	if in.MultiBranchPipeline != nil {
+		in, out := &in.MultiBranchPipeline, &out.MultiBranchPipeline
+		*out = new(MultiBranchPipeline)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = new(Matrix)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EphemeralNamespace != nil {
+		in, out := &in.EphemeralNamespace, &out.EphemeralNamespace
+		*out = new(EphemeralNamespace)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedResources != nil {
+		in, out := &in.SharedResources, &out.SharedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Release != nil {
+		in, out := &in.Release, &out.Release
+		*out = new(Release)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = new(PipelineTriggers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ownership != nil {
+		in, out := &in.Ownership, &out.Ownership
+		*out = new(PipelineOwnership)
 		**out = **in
 	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = new(PipelinePost)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubSource.
-func (in *GithubSource) DeepCopy() *GithubSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
+func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubSource)
+	out := new(PipelineSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabSource) DeepCopyInto(out *GitlabSource) {
+func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
 	*out = *in
-	if in.DiscoverPRFromForks != nil {
-		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
-		*out = new(DiscoverPRFromForks)
-		**out = **in
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(PipelineDrift)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.CloneOption != nil {
-		in, out := &in.CloneOption, &out.CloneOption
-		*out = new(GitCloneOption)
+	if in.SuspendTime != nil {
+		in, out := &in.SuspendTime, &out.SuspendTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = new(PipelineRunsSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ownership != nil {
+		in, out := &in.Ownership, &out.Ownership
+		*out = new(OwnershipStatus)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabSource.
-func (in *GitlabSource) DeepCopy() *GitlabSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStatus.
+func (in *PipelineStatus) DeepCopy() *PipelineStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabSource)
+	out := new(PipelineStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JWTToken) DeepCopyInto(out *JWTToken) {
+func (in *PipelineTriggers) DeepCopyInto(out *PipelineTriggers) {
 	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = new(PathFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Debounce != nil {
+		in, out := &in.Debounce, &out.Debounce
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTToken.
-func (in *JWTToken) DeepCopy() *JWTToken {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineTriggers.
+func (in *PipelineTriggers) DeepCopy() *PipelineTriggers {
 	if in == nil {
 		return nil
 	}
-	out := new(JWTToken)
+	out := new(PipelineTriggers)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiBranchJobTrigger) DeepCopyInto(out *MultiBranchJobTrigger) {
+func (in *PluginRequirement) DeepCopyInto(out *PluginRequirement) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiBranchJobTrigger.
-func (in *MultiBranchJobTrigger) DeepCopy() *MultiBranchJobTrigger {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginRequirement.
+func (in *PluginRequirement) DeepCopy() *PluginRequirement {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiBranchJobTrigger)
+	out := new(PluginRequirement)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiBranchPipeline) DeepCopyInto(out *MultiBranchPipeline) {
+func (in *PluginStatus) DeepCopyInto(out *PluginStatus) {
 	*out = *in
-	if in.Discarder != nil {
-		in, out := &in.Discarder, &out.Discarder
-		*out = new(DiscarderProperty)
-		**out = **in
-	}
-	if in.TimerTrigger != nil {
-		in, out := &in.TimerTrigger, &out.TimerTrigger
-		*out = new(TimerTrigger)
-		**out = **in
-	}
-	if in.GitSource != nil {
-		in, out := &in.GitSource, &out.GitSource
-		*out = new(GitSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.GitHubSource != nil {
-		in, out := &in.GitHubSource, &out.GitHubSource
-		*out = new(GithubSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.GitlabSource != nil {
-		in, out := &in.GitlabSource, &out.GitlabSource
-		*out = new(GitlabSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.SvnSource != nil {
-		in, out := &in.SvnSource, &out.SvnSource
-		*out = new(SvnSource)
-		**out = **in
-	}
-	if in.SingleSvnSource != nil {
-		in, out := &in.SingleSvnSource, &out.SingleSvnSource
-		*out = new(SingleSvnSource)
-		**out = **in
-	}
-	if in.BitbucketServerSource != nil {
-		in, out := &in.BitbucketServerSource, &out.BitbucketServerSource
-		*out = new(BitbucketServerSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.MultiBranchJobTrigger != nil {
-		in, out := &in.MultiBranchJobTrigger, &out.MultiBranchJobTrigger
-		*out = new(MultiBranchJobTrigger)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiBranchPipeline.
-func (in *MultiBranchPipeline) DeepCopy() *MultiBranchPipeline {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginStatus.
+func (in *PluginStatus) DeepCopy() *PluginStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiBranchPipeline)
+	out := new(PluginStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NoScmPipeline) DeepCopyInto(out *NoScmPipeline) {
+func (in *PostActions) DeepCopyInto(out *PostActions) {
 	*out = *in
-	if in.Discarder != nil {
-		in, out := &in.Discarder, &out.Discarder
-		*out = new(DiscarderProperty)
-		**out = **in
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]PostNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Parameters != nil {
-		in, out := &in.Parameters, &out.Parameters
-		*out = make([]ParameterDefinition, len(*in))
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.TimerTrigger != nil {
-		in, out := &in.TimerTrigger, &out.TimerTrigger
-		*out = new(TimerTrigger)
-		**out = **in
-	}
-	if in.RemoteTrigger != nil {
-		in, out := &in.RemoteTrigger, &out.RemoteTrigger
-		*out = new(RemoteTrigger)
-		**out = **in
-	}
-	if in.GenericWebhook != nil {
-		in, out := &in.GenericWebhook, &out.GenericWebhook
-		*out = new(GenericWebhook)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NoScmPipeline.
-func (in *NoScmPipeline) DeepCopy() *NoScmPipeline {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostActions.
+func (in *PostActions) DeepCopy() *PostActions {
 	if in == nil {
 		return nil
 	}
-	out := new(NoScmPipeline)
+	out := new(PostActions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OrphanedResourceKey) DeepCopyInto(out *OrphanedResourceKey) {
+func (in *PostCondition) DeepCopyInto(out *PostCondition) {
 	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = new(PostActions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResourceKey.
-func (in *OrphanedResourceKey) DeepCopy() *OrphanedResourceKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostCondition.
+func (in *PostCondition) DeepCopy() *PostCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(OrphanedResourceKey)
+	out := new(PostCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OrphanedResourcesMonitorSettings) DeepCopyInto(out *OrphanedResourcesMonitorSettings) {
+func (in *PostNotification) DeepCopyInto(out *PostNotification) {
 	*out = *in
-	if in.Warn != nil {
-		in, out := &in.Warn, &out.Warn
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Ignore != nil {
-		in, out := &in.Ignore, &out.Ignore
-		*out = make([]OrphanedResourceKey, len(*in))
-		copy(*out, *in)
-	}
+	in.SecretRef.DeepCopyInto(&out.SecretRef)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResourcesMonitorSettings.
-func (in *OrphanedResourcesMonitorSettings) DeepCopy() *OrphanedResourcesMonitorSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostNotification.
+func (in *PostNotification) DeepCopy() *PostNotification {
 	if in == nil {
 		return nil
 	}
-	out := new(OrphanedResourcesMonitorSettings)
+	out := new(PostNotification)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Parameter) DeepCopyInto(out *Parameter) {
+func (in *ProjectRole) DeepCopyInto(out *ProjectRole) {
 	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JWTTokens != nil {
+		in, out := &in.JWTTokens, &out.JWTTokens
+		*out = make([]JWTToken, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
-func (in *Parameter) DeepCopy() *Parameter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectRole.
+func (in *ProjectRole) DeepCopy() *ProjectRole {
 	if in == nil {
 		return nil
 	}
-	out := new(Parameter)
+	out := new(ProjectRole)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ParameterDefinition) DeepCopyInto(out *ParameterDefinition) {
+func (in *RegistryBuildCache) DeepCopyInto(out *RegistryBuildCache) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterDefinition.
-func (in *ParameterDefinition) DeepCopy() *ParameterDefinition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryBuildCache.
+func (in *RegistryBuildCache) DeepCopy() *RegistryBuildCache {
 	if in == nil {
 		return nil
 	}
-	out := new(ParameterDefinition)
+	out := new(RegistryBuildCache)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ParameterInStep) DeepCopyInto(out *ParameterInStep) {
+func (in *Release) DeepCopyInto(out *Release) {
 	*out = *in
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterInStep.
-func (in *ParameterInStep) DeepCopy() *ParameterInStep {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Release.
+func (in *Release) DeepCopy() *Release {
 	if in == nil {
 		return nil
 	}
-	out := new(ParameterInStep)
+	out := new(Release)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ParameterValidation) DeepCopyInto(out *ParameterValidation) {
+func (in *RemoteTrigger) DeepCopyInto(out *RemoteTrigger) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterValidation.
-func (in *ParameterValidation) DeepCopy() *ParameterValidation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteTrigger.
+func (in *RemoteTrigger) DeepCopy() *RemoteTrigger {
 	if in == nil {
 		return nil
 	}
-	out := new(ParameterValidation)
+	out := new(RemoteTrigger)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Pipeline) DeepCopyInto(out *Pipeline) {
+func (in *S3BuildCache) DeepCopyInto(out *S3BuildCache) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pipeline.
-func (in *Pipeline) DeepCopy() *Pipeline {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BuildCache.
+func (in *S3BuildCache) DeepCopy() *S3BuildCache {
 	if in == nil {
 		return nil
 	}
-	out := new(Pipeline)
+	out := new(S3BuildCache)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Pipeline) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineList) DeepCopyInto(out *PipelineList) {
+func (in *SCM) DeepCopyInto(out *SCM) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Pipeline, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineList.
-func (in *PipelineList) DeepCopy() *PipelineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCM.
+func (in *SCM) DeepCopy() *SCM {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineList)
+	out := new(SCM)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PipelineList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineRun) DeepCopyInto(out *PipelineRun) {
+func (in *SecretFinding) DeepCopyInto(out *SecretFinding) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRun.
-func (in *PipelineRun) DeepCopy() *PipelineRun {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretFinding.
+func (in *SecretFinding) DeepCopy() *SecretFinding {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineRun)
+	out := new(SecretFinding)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PipelineRun) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineRunList) DeepCopyInto(out *PipelineRunList) {
+func (in *SecretInStep) DeepCopyInto(out *SecretInStep) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PipelineRun, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Mapping != nil {
+		in, out := &in.Mapping, &out.Mapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunList.
-func (in *PipelineRunList) DeepCopy() *PipelineRunList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretInStep.
+func (in *SecretInStep) DeepCopy() *SecretInStep {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineRunList)
+	out := new(SecretInStep)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PipelineRunList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineRunSpec) DeepCopyInto(out *PipelineRunSpec) {
+func (in *SecretScanPolicy) DeepCopyInto(out *SecretScanPolicy) {
 	*out = *in
-	if in.PipelineRef != nil {
-		in, out := &in.PipelineRef, &out.PipelineRef
-		*out = new(corev1.ObjectReference)
-		**out = **in
-	}
-	if in.PipelineSpec != nil {
-		in, out := &in.PipelineSpec, &out.PipelineSpec
-		*out = new(PipelineSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Parameters != nil {
-		in, out := &in.Parameters, &out.Parameters
-		*out = make([]Parameter, len(*in))
+	if in.ExcludePaths != nil {
+		in, out := &in.ExcludePaths, &out.ExcludePaths
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.SCM != nil {
-		in, out := &in.SCM, &out.SCM
-		*out = new(SCM)
-		**out = **in
-	}
-	if in.Action != nil {
-		in, out := &in.Action, &out.Action
-		*out = new(Action)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunSpec.
-func (in *PipelineRunSpec) DeepCopy() *PipelineRunSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretScanPolicy.
+func (in *SecretScanPolicy) DeepCopy() *SecretScanPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineRunSpec)
+	out := new(SecretScanPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineRunStatus) DeepCopyInto(out *PipelineRunStatus) {
+func (in *SecretScanResult) DeepCopyInto(out *SecretScanResult) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
-	if in.UpdateTime != nil {
-		in, out := &in.UpdateTime, &out.UpdateTime
+	if in.ScanTime != nil {
+		in, out := &in.ScanTime, &out.ScanTime
 		*out = (*in).DeepCopy()
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]SecretFinding, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunStatus.
-func (in *PipelineRunStatus) DeepCopy() *PipelineRunStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretScanResult.
+func (in *SecretScanResult) DeepCopy() *SecretScanResult {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineRunStatus)
+	out := new(SecretScanResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
+func (in *SharedResource) DeepCopyInto(out *SharedResource) {
 	*out = *in
-	if in.Pipeline != nil {
-		in, out := &in.Pipeline, &out.Pipeline
-		*out = new(NoScmPipeline)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.MultiBranchPipeline != nil {
-		in, out := &in.MultiBranchPipeline, &out.MultiBranchPipeline
-		*out = new(MultiBranchPipeline)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
-func (in *PipelineSpec) DeepCopy() *PipelineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResource.
+func (in *SharedResource) DeepCopy() *SharedResource {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineSpec)
+	out := new(SharedResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
+func (in *SharedResourceClaim) DeepCopyInto(out *SharedResourceClaim) {
 	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStatus.
-func (in *PipelineStatus) DeepCopy() *PipelineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceClaim.
+func (in *SharedResourceClaim) DeepCopy() *SharedResourceClaim {
 	if in == nil {
 		return nil
 	}
-	out := new(PipelineStatus)
+	out := new(SharedResourceClaim)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProjectRole) DeepCopyInto(out *ProjectRole) {
+func (in *SharedResourceList) DeepCopyInto(out *SharedResourceList) {
 	*out = *in
-	if in.Policies != nil {
-		in, out := &in.Policies, &out.Policies
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.JWTTokens != nil {
-		in, out := &in.JWTTokens, &out.JWTTokens
-		*out = make([]JWTToken, len(*in))
-		copy(*out, *in)
-	}
-	if in.Groups != nil {
-		in, out := &in.Groups, &out.Groups
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectRole.
-func (in *ProjectRole) DeepCopy() *ProjectRole {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceList.
+func (in *SharedResourceList) DeepCopy() *SharedResourceList {
 	if in == nil {
 		return nil
 	}
-	out := new(ProjectRole)
+	out := new(SharedResourceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteTrigger) DeepCopyInto(out *RemoteTrigger) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteTrigger.
-func (in *RemoteTrigger) DeepCopy() *RemoteTrigger {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(RemoteTrigger)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SCM) DeepCopyInto(out *SCM) {
+func (in *SharedResourceSpec) DeepCopyInto(out *SharedResourceSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCM.
-func (in *SCM) DeepCopy() *SCM {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceSpec.
+func (in *SharedResourceSpec) DeepCopy() *SharedResourceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SCM)
+	out := new(SharedResourceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretInStep) DeepCopyInto(out *SecretInStep) {
+func (in *SharedResourceStatus) DeepCopyInto(out *SharedResourceStatus) {
 	*out = *in
-	if in.Mapping != nil {
-		in, out := &in.Mapping, &out.Mapping
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Holders != nil {
+		in, out := &in.Holders, &out.Holders
+		*out = make([]SharedResourceClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Waiting != nil {
+		in, out := &in.Waiting, &out.Waiting
+		*out = make([]SharedResourceClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretInStep.
-func (in *SecretInStep) DeepCopy() *SecretInStep {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceStatus.
+func (in *SharedResourceStatus) DeepCopy() *SharedResourceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretInStep)
+	out := new(SharedResourceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1412,6 +3395,21 @@ func (in *StepTemplateStatus) DeepCopy() *StepTemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageEncryption) DeepCopyInto(out *StorageEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEncryption.
+func (in *StorageEncryption) DeepCopy() *StorageEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SvnSource) DeepCopyInto(out *SvnSource) {
 	*out = *in
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	rt "runtime"
 	"time"
@@ -33,18 +34,23 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
 	unionauth "k8s.io/apiserver/pkg/authentication/request/union"
+	tokenunion "k8s.io/apiserver/pkg/authentication/token/union"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha1"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
-	devopsbearertoken "kubesphere.io/devops/pkg/apiserver/authentication/authenticators/bearertoken"
 	"kubesphere.io/devops/pkg/apiserver/authentication/request/anonymous"
+	"kubesphere.io/devops/pkg/apiserver/authentication/request/public"
 	"kubesphere.io/devops/pkg/apiserver/filters"
 	"kubesphere.io/devops/pkg/apiserver/request"
 	"kubesphere.io/devops/pkg/indexers"
 	"kubesphere.io/devops/pkg/kapis/oauth"
 	"kubesphere.io/devops/pkg/models/auth"
+	"kubesphere.io/devops/pkg/models/stats"
+	"kubesphere.io/devops/pkg/rpc"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/emicklei/go-restful"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -54,6 +60,9 @@ import (
 
 	"kubesphere.io/devops/pkg/client/cache"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jclient"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/client/history"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/s3"
 	"kubesphere.io/devops/pkg/client/sonarqube"
@@ -82,6 +91,11 @@ type APIServer struct {
 
 	Server *http.Server
 
+	// GRPCAddr is the address of the gRPC server, it's disabled if the address is empty
+	GRPCAddr string
+
+	grpcServer *grpc.Server
+
 	Config *apiserverconfig.Config
 
 	// webservice container, where all webservice defines
@@ -101,12 +115,18 @@ type APIServer struct {
 
 	S3Client s3.Interface
 
+	// HistoryClient stores the history of PipelineRuns, it is nil if the history database is not configured
+	HistoryClient history.Interface
+
 	SonarClient sonarqube.SonarInterface
 
 	// controller-runtime cache
 	RuntimeCache runtimecache.Cache
 
 	Client client.Client
+
+	// statsCollector counts the completed PipelineRuns for the dashboard
+	statsCollector *stats.Collector
 }
 
 func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
@@ -127,9 +147,10 @@ func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
 
 	s.Server.Handler = s.container
 
-	s.buildHandlerChain(stopCh)
-
-	return nil
+	if err := s.buildHandlerChain(stopCh); err != nil {
+		return err
+	}
+	return s.buildGRPCServer()
 }
 
 // Install all KubeSphere api groups
@@ -142,9 +163,16 @@ func (s *APIServer) installKubeSphereAPIs() {
 		UserName: s.Config.JenkinsOptions.Username,
 		Token:    s.Config.JenkinsOptions.Password,
 	}
+	// talk to the Jenkins folders resolved from the namespaces of the DevOps projects
+	folderResolver := jenkins.NewFolderResolver(s.Client)
+	jenkinsCore.RoundTripper = folderResolver.WrapTransport(nil)
+	if jenkinsClient, ok := s.DevopsClient.(*jclient.JenkinsClient); ok {
+		jenkinsClient.UseFolderResolver(folderResolver)
+	}
 
 	var wss []*restful.WebService
 	tokenIssue := getTokenIssue(s.Config)
+	s.statsCollector = stats.NewCollector(stats.DefaultRetention)
 
 	v1alpha2WSS, err := devopsv1alpha2.AddToContainer(s.container,
 		s.InformerFactory.KubeSphereSharedInformerFactory(),
@@ -157,7 +185,8 @@ func (s *APIServer) installKubeSphereAPIs() {
 		jenkinsCore)
 	utilruntime.Must(err)
 	wss = append(wss, v1alpha2WSS...)
-	wss = append(wss, devopsv1alpha3.AddToContainer(s.container, s.DevopsClient, s.KubernetesClient, s.Client, tokenIssue, jenkinsCore)...)
+	wss = append(wss, devopsv1alpha3.AddToContainer(s.container, s.DevopsClient, s.KubernetesClient, s.Client, tokenIssue, jenkinsCore, s.statsCollector, s.HistoryClient, s.S3Client,
+		s.Config.AuthMode, s.Config.JenkinsOptions.ScriptAuditMaxSize)...)
 	wss = append(wss, oauth.AddToContainer(s.container,
 		auth.NewTokenOperator(
 			s.CacheClient,
@@ -177,9 +206,15 @@ func (s *APIServer) Run(stopCh context.Context) (err error) {
 	if err := indexers.CreatePipelineRunSCMRefNameIndexer(s.RuntimeCache); err != nil {
 		return err
 	}
+	if err := indexers.CreatePipelineRunSCMRefTypeIndexer(s.RuntimeCache); err != nil {
+		return err
+	}
 	if err := indexers.CreatePipelineRunIdentityIndexer(s.RuntimeCache); err != nil {
 		return err
 	}
+	if err := s.statsCollector.Watch(stopCh, s.RuntimeCache); err != nil {
+		return err
+	}
 
 	err = s.waitForResourceSync(stopCh)
 	if err != nil {
@@ -192,8 +227,25 @@ func (s *APIServer) Run(stopCh context.Context) (err error) {
 	go func() {
 		<-stopCh.Done()
 		_ = s.Server.Shutdown(ctx)
+		if s.grpcServer != nil {
+			// the streams last until the PipelineRuns complete, so don't wait for them
+			s.grpcServer.Stop()
+		}
 	}()
 
+	if s.grpcServer != nil {
+		var listener net.Listener
+		if listener, err = net.Listen("tcp", s.GRPCAddr); err != nil {
+			return err
+		}
+		go func() {
+			klog.V(0).Infof("Start serving gRPC on %s", s.GRPCAddr)
+			if err := s.grpcServer.Serve(listener); err != nil {
+				klog.Errorf("failed to serve gRPC, error: %v", err)
+			}
+		}()
+	}
+
 	klog.V(0).Infof("Start listening on %s", s.Server.Addr)
 	klog.V(0).Infof("Open the swagger-ui from http://localhost%s/apidocs/?url=http://localhost:9090/apidocs.json", s.Server.Addr)
 	if s.Server.TLSConfig != nil {
@@ -205,7 +257,7 @@ func (s *APIServer) Run(stopCh context.Context) (err error) {
 	return err
 }
 
-func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
+func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) error {
 	requestInfoResolver := &request.RequestInfoFactory{
 		APIPrefixes:          sets.NewString("api", "apis", "kapis", "kapi"),
 		GrouplessAPIPrefixes: sets.NewString("api", "kapi"),
@@ -214,20 +266,61 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 	handler := s.Server.Handler
 	handler = filters.WithKubeAPIServer(handler, s.KubernetesClient.Config(), &errorResponder{})
 
-	authenticators := make([]authenticator.Request, 0)
-	authenticators = append(authenticators, anonymous.NewAuthenticator())
+	authz, err := s.getAuthorizer()
+	if err != nil {
+		return err
+	}
+	handler = filters.WithAuthorization(handler, authz)
 
-	switch s.Config.AuthMode {
-	case apiserverconfig.AuthModeToken:
-		authenticators = append(authenticators, bearertoken.New(devopsbearertoken.New()))
-	default:
-		// TODO error handle
+	tokenAuthenticators, err := s.getTokenAuthenticators()
+	if err != nil {
+		return err
+	}
+	// the webhooks have their own authentication, they are treated as anonymous
+	authenticators := []authenticator.Request{
+		public.NewAuthenticator(),
+		anonymous.NewAuthenticator(),
+		bearertoken.New(tokenunion.New(tokenAuthenticators...)),
 	}
 
 	handler = filters.WithAuthentication(handler, unionauth.New(authenticators...))
 	handler = filters.WithRequestInfo(handler, requestInfoResolver)
 
 	s.Server.Handler = handler
+	return nil
+}
+
+// buildGRPCServer creates the gRPC server if its address is set, the authentication and authorization are the same
+// as the REST APIs. The gRPC port is not behind the proxy of ks-apiserver, so the server refuses to start unless
+// the signatures of the tokens are verified and the requests are authorized.
+func (s *APIServer) buildGRPCServer() error {
+	if s.GRPCAddr == "" {
+		return nil
+	}
+	if s.Config.AuthMode != apiserverconfig.AuthModeVerified {
+		return fmt.Errorf("the gRPC server requires the auth mode %s, but it is %q",
+			apiserverconfig.AuthModeVerified, s.Config.AuthMode)
+	}
+	authz, err := s.getAuthorizer()
+	if err != nil {
+		return err
+	}
+	if authz == nil {
+		return fmt.Errorf("the gRPC server requires the authorization mode %s, but it is %q",
+			apiserverconfig.AuthorizationModeSubjectAccessReview, s.Config.AuthorizationMode)
+	}
+	tokenAuthenticators, err := s.getTokenAuthenticators()
+	if err != nil {
+		return err
+	}
+
+	var opts []grpc.ServerOption
+	if s.Server.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.Server.TLSConfig)))
+	}
+	s.grpcServer = rpc.NewServer(rpc.NewPipelineService(s.Client, s.DevopsClient, authz),
+		tokenunion.New(tokenAuthenticators...), opts...)
+	return nil
 }
 
 func (s *APIServer) waitForResourceSync(stopCh context.Context) error {
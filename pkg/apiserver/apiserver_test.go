@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiserverconfig "kubesphere.io/devops/pkg/config"
+)
+
+func TestAPIServer_buildGRPCServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		grpcAddr string
+		config   *apiserverconfig.Config
+		wantErr  bool
+	}{{
+		name:   "gRPC is disabled",
+		config: &apiserverconfig.Config{AuthMode: apiserverconfig.AuthModeToken},
+	}, {
+		name:     "the signatures of tokens are not verified",
+		grpcAddr: ":9091",
+		config: &apiserverconfig.Config{AuthMode: apiserverconfig.AuthModeToken,
+			AuthorizationMode: apiserverconfig.AuthorizationModeSubjectAccessReview},
+		wantErr: true,
+	}, {
+		name:     "the requests are not authorized",
+		grpcAddr: ":9091",
+		config: &apiserverconfig.Config{AuthMode: apiserverconfig.AuthModeVerified,
+			AuthorizationMode: apiserverconfig.AuthorizationModeAlwaysAllow},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{GRPCAddr: tt.grpcAddr, Config: tt.config}
+			err := s.buildGRPCServer()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Nil(t, s.grpcServer)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	tokencache "k8s.io/apiserver/pkg/authentication/token/cache"
+	"k8s.io/apiserver/pkg/authentication/token/tokenfile"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/apiserver/plugin/pkg/authenticator/token/oidc"
+
+	devopsbearertoken "kubesphere.io/devops/pkg/apiserver/authentication/authenticators/bearertoken"
+	"kubesphere.io/devops/pkg/apiserver/authentication/authenticators/tokenreview"
+	"kubesphere.io/devops/pkg/apiserver/authorization/subjectaccessreview"
+	apiserverconfig "kubesphere.io/devops/pkg/config"
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+const (
+	// tokenReviewSuccessTTL and tokenReviewFailureTTL are the same as the defaults of the webhook token authenticator of kube-apiserver
+	tokenReviewSuccessTTL = 2 * time.Minute
+	tokenReviewFailureTTL = 10 * time.Second
+)
+
+// getTokenAuthenticators returns the authenticators of the bearer tokens in order, the KubeSphere tokens are the last
+func (s *APIServer) getTokenAuthenticators() (authenticators []authenticator.Token, err error) {
+	if options := s.Config.AuthenticationOptions; options != nil {
+		if options.StaticTokenFile != "" {
+			var staticTokens authenticator.Token
+			if staticTokens, err = tokenfile.NewCSV(options.StaticTokenFile); err != nil {
+				return nil, fmt.Errorf("failed to load the static tokens: %v", err)
+			}
+			authenticators = append(authenticators, staticTokens)
+		}
+		if options.OIDC != nil {
+			oidcOptions := oidc.Options{
+				IssuerURL:      options.OIDC.IssuerURL,
+				ClientID:       options.OIDC.ClientID,
+				UsernameClaim:  options.OIDC.UsernameClaim,
+				UsernamePrefix: options.OIDC.UsernamePrefix,
+				GroupsClaim:    options.OIDC.GroupsClaim,
+				GroupsPrefix:   options.OIDC.GroupsPrefix,
+			}
+			if oidcOptions.UsernameClaim == "" {
+				oidcOptions.UsernameClaim = "sub"
+			}
+			if options.OIDC.CAFile != "" {
+				if oidcOptions.CAContentProvider, err = dynamiccertificates.NewDynamicCAContentFromFile("oidc-authenticator", options.OIDC.CAFile); err != nil {
+					return nil, fmt.Errorf("failed to load the CA of OIDC: %v", err)
+				}
+			}
+			var oidcAuthenticator authenticator.Token
+			if oidcAuthenticator, err = oidc.New(oidcOptions); err != nil {
+				return nil, fmt.Errorf("failed to create the OIDC authenticator: %v", err)
+			}
+			authenticators = append(authenticators, oidcAuthenticator)
+		}
+		if options.TokenReview {
+			authenticators = append(authenticators, tokencache.New(
+				tokenreview.New(s.KubernetesClient.Kubernetes().AuthenticationV1().TokenReviews()),
+				false, tokenReviewSuccessTTL, tokenReviewFailureTTL))
+		}
+	}
+
+	switch s.Config.AuthMode {
+	case apiserverconfig.AuthModeToken, "":
+		authenticators = append(authenticators, devopsbearertoken.New())
+	case apiserverconfig.AuthModeVerified:
+		if s.Config.AuthenticationOptions == nil {
+			return nil, fmt.Errorf("the authentication options are required by the auth mode: %s", s.Config.AuthMode)
+		}
+		authenticators = append(authenticators, devopsbearertoken.NewVerified(getTokenIssue(s.Config)))
+	default:
+		return nil, fmt.Errorf("unsupported auth mode: %s", s.Config.AuthMode)
+	}
+	return
+}
+
+// getAuthorizer returns the authorizer of the requests, it's nil if all the authenticated requests are allowed
+func (s *APIServer) getAuthorizer() (authorizer.Authorizer, error) {
+	switch s.Config.AuthorizationMode {
+	case apiserverconfig.AuthorizationModeAlwaysAllow, "":
+		return nil, nil
+	case apiserverconfig.AuthorizationModeSubjectAccessReview:
+		return subjectaccessreview.New(s.KubernetesClient.Kubernetes().AuthorizationV1().SubjectAccessReviews()), nil
+	default:
+		return nil, fmt.Errorf("unsupported authorization mode: %s", s.Config.AuthorizationMode)
+	}
+}
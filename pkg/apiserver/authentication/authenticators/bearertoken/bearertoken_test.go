@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bearertoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	jwt "kubesphere.io/devops/pkg/jwt/token"
+)
+
+func TestVerifiedTokenAuthenticator(t *testing.T) {
+	issuer := jwt.NewTokenIssuer("secret", time.Second)
+	token, err := issuer.IssueTo(&user.DefaultInfo{Name: "admin"}, jwt.AccessToken, time.Hour)
+	assert.Nil(t, err)
+
+	resp, ok, err := NewVerified(issuer).AuthenticateToken(context.TODO(), token)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "admin", resp.User.GetName())
+	assert.Contains(t, resp.User.GetGroups(), user.AllAuthenticated)
+
+	// signed by another secret
+	_, ok, err = NewVerified(jwt.NewTokenIssuer("another", time.Second)).AuthenticateToken(context.TODO(), token)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = NewVerified(issuer).AuthenticateToken(context.TODO(), "not-a-jwt")
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	// the scoped tokens are not accepted
+	approvalToken, err := issuer.IssueTo(&user.DefaultInfo{Name: "admin"}, jwt.ApprovalToken, time.Hour)
+	assert.Nil(t, err)
+	_, ok, err = NewVerified(issuer).AuthenticateToken(context.TODO(), approvalToken)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	issuer := jwt.NewTokenIssuer("secret", time.Second)
+	token, err := issuer.IssueTo(&user.DefaultInfo{Name: "admin"}, jwt.AccessToken, time.Hour)
+	assert.Nil(t, err)
+
+	resp, ok, err := New().AuthenticateToken(context.TODO(), token)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "admin", resp.User.GetName())
+
+	// the scoped tokens are not accepted
+	for _, tokenType := range []jwt.TokenType{jwt.TriggerToken, jwt.ApprovalToken} {
+		scopedToken, err := issuer.IssueTo(&user.DefaultInfo{Name: "admin"}, tokenType, time.Hour)
+		assert.Nil(t, err)
+		_, ok, err = New().AuthenticateToken(context.TODO(), scopedToken)
+		assert.Nil(t, err)
+		assert.False(t, ok, tokenType)
+	}
+}
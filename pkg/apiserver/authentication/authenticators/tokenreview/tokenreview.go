@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenreview
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authenticationclient "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+// tokenAuthenticator authenticates the bearer tokens by the TokenReview API of Kubernetes
+type tokenAuthenticator struct {
+	client authenticationclient.TokenReviewInterface
+}
+
+// New creates an authenticator which asks Kubernetes to review the tokens, such as the tokens of service accounts
+func New(client authenticationclient.TokenReviewInterface) authenticator.Token {
+	return &tokenAuthenticator{client: client}
+}
+
+func (a *tokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+	review, err := a.client.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	if !review.Status.Authenticated {
+		return nil, false, nil
+	}
+
+	info := &user.DefaultInfo{
+		Name:   review.Status.User.Username,
+		UID:    review.Status.User.UID,
+		Groups: review.Status.User.Groups,
+	}
+	if len(review.Status.User.Extra) > 0 {
+		info.Extra = make(map[string][]string, len(review.Status.User.Extra))
+		for key, value := range review.Status.User.Extra {
+			info.Extra[key] = value
+		}
+	}
+	return &authenticator.Response{User: info}, true, nil
+}
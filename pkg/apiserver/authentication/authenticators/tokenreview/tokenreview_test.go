@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenreview
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAuthenticateToken(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "good" {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User: authenticationv1.UserInfo{
+					Username: "system:serviceaccount:ns:sa",
+					UID:      "uid",
+					Groups:   []string{"system:serviceaccounts"},
+					Extra:    map[string]authenticationv1.ExtraValue{"key": {"value"}},
+				},
+			}
+		}
+		return true, review, nil
+	})
+	authenticator := New(client.AuthenticationV1().TokenReviews())
+
+	resp, ok, err := authenticator.AuthenticateToken(context.TODO(), "good")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "system:serviceaccount:ns:sa", resp.User.GetName())
+	assert.Equal(t, "uid", resp.User.GetUID())
+	assert.Equal(t, []string{"system:serviceaccounts"}, resp.User.GetGroups())
+	assert.Equal(t, map[string][]string{"key": {"value"}}, resp.User.GetExtra())
+
+	resp, ok, err = authenticator.AuthenticateToken(context.TODO(), "bad")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, resp)
+}
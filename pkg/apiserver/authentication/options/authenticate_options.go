@@ -49,6 +49,30 @@ type AuthenticationOptions struct {
 	OAuthOptions *oauth.Options `json:"oauthOptions" yaml:"oauthOptions"`
 	// KubectlImage is the image address we use to create kubectl pod for users who have admin access to the cluster.
 	KubectlImage string `json:"kubectlImage" yaml:"kubectlImage"`
+	// TokenReview authenticates the bearer tokens by the TokenReview API of Kubernetes, such as the tokens of service accounts
+	TokenReview bool `json:"tokenReview" yaml:"tokenReview"`
+	// StaticTokenFile is a CSV file of the static tokens, each line is: token,user,uid,"group1,group2"
+	StaticTokenFile string `json:"staticTokenFile" yaml:"staticTokenFile"`
+	// OIDC authenticates the ID tokens issued by an OpenID Connect provider
+	OIDC *OIDCOptions `json:"oidc,omitempty" yaml:"oidc,omitempty"`
+}
+
+// OIDCOptions are the options of the OpenID Connect provider which issues the ID tokens
+type OIDCOptions struct {
+	// IssuerURL is the URL of the provider, only the HTTPS scheme is accepted
+	IssuerURL string `json:"issuerURL" yaml:"issuerURL"`
+	// ClientID is the audience of the ID tokens
+	ClientID string `json:"clientID" yaml:"clientID"`
+	// CAFile is the CA bundle to verify the provider, the system ones are used if it's empty
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	// UsernameClaim is the claim of the username, it's sub by default
+	UsernameClaim string `json:"usernameClaim,omitempty" yaml:"usernameClaim,omitempty"`
+	// UsernamePrefix is prepended to the usernames to avoid the conflicts with the other users, such as oidc:
+	UsernamePrefix string `json:"usernamePrefix,omitempty" yaml:"usernamePrefix,omitempty"`
+	// GroupsClaim is the claim of the groups, the groups are ignored if it's empty
+	GroupsClaim string `json:"groupsClaim,omitempty" yaml:"groupsClaim,omitempty"`
+	// GroupsPrefix is prepended to the groups
+	GroupsPrefix string `json:"groupsPrefix,omitempty" yaml:"groupsPrefix,omitempty"`
 }
 
 func NewAuthenticateOptions() *AuthenticationOptions {
@@ -73,6 +97,9 @@ func (options *AuthenticationOptions) Validate() []error {
 	if options.AuthenticateRateLimiterMaxTries > options.LoginHistoryMaximumEntries {
 		errs = append(errs, errors.New("authenticateRateLimiterMaxTries MUST not be greater than loginHistoryMaximumEntries"))
 	}
+	if options.OIDC != nil && (options.OIDC.IssuerURL == "" || options.OIDC.ClientID == "") {
+		errs = append(errs, errors.New("both the issuerURL and clientID of OIDC are required"))
+	}
 	return errs
 }
 
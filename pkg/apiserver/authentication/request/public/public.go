@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package public
+
+import (
+	"net/http"
+	"regexp"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// publicPathPattern matches the webhooks and badges which have their own authentication, and the OAuth endpoints
+var publicPathPattern = regexp.MustCompile(`^((/kapis/devops\.kubesphere\.io)?/v1alpha[23]/(webhooks?|badges)/|/oauth/)`)
+
+// IsPublicPath checks if the path is public, the requests to it are not authenticated or authorized by the apiserver
+func IsPublicPath(path string) bool {
+	return publicPathPattern.MatchString(path)
+}
+
+// Authenticator treats the requests to the public paths as anonymous, no matter what the Authorization header is
+type Authenticator struct{}
+
+// NewAuthenticator creates an authenticator for the public paths
+func NewAuthenticator() authenticator.Request {
+	return &Authenticator{}
+}
+
+// AuthenticateRequest authenticates the requests to the public paths only
+func (a *Authenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
+	if !IsPublicPath(req.URL.Path) {
+		return nil, false, nil
+	}
+	return &authenticator.Response{
+		User: &user.DefaultInfo{
+			Name:   user.Anonymous,
+			Groups: []string{user.AllUnauthenticated},
+		},
+	}, true, nil
+}
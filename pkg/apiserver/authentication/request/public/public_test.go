@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package public
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestIsPublicPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		public bool
+	}{
+		{path: "/kapis/devops.kubesphere.io/v1alpha3/webhooks/scm", public: true},
+		{path: "/kapis/devops.kubesphere.io/v1alpha3/webhooks/trigger/token", public: true},
+		{path: "/v1alpha3/webhooks/jenkins", public: true},
+		{path: "/kapis/devops.kubesphere.io/v1alpha2/webhook/github", public: true},
+		{path: "/kapis/devops.kubesphere.io/v1alpha3/badges/ns/pipelines/p", public: true},
+		{path: "/oauth/authenticate", public: true},
+		{path: "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/pipelines", public: false},
+		{path: "/kapis/devops.kubesphere.io/v1alpha3/namespaces/webhooks/pipelines", public: false},
+		{path: "/webhooks/scm", public: false},
+		{path: "/", public: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.public, IsPublicPath(tt.path))
+		})
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	req := httptest.NewRequest("POST", "/kapis/devops.kubesphere.io/v1alpha3/webhooks/trigger/token", nil)
+	req.Header.Set("Authorization", "Bearer trigger-token")
+	resp, ok, err := NewAuthenticator().AuthenticateRequest(req)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, user.Anonymous, resp.User.GetName())
+
+	req = httptest.NewRequest("GET", "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/pipelines", nil)
+	resp, ok, err = NewAuthenticator().AuthenticateRequest(req)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, resp)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subjectaccessreview
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+const (
+	// allowedTTL and deniedTTL are the same as the defaults of the webhook authorizer of kube-apiserver
+	allowedTTL = 5 * time.Minute
+	deniedTTL  = 30 * time.Second
+	cacheSize  = 1024
+)
+
+type decision struct {
+	decision authorizer.Decision
+	reason   string
+}
+
+// sarAuthorizer authorizes the requests by the SubjectAccessReview API of Kubernetes, so the RBAC rules of the
+// DevOps resources decide who can access the DevOps apiserver
+type sarAuthorizer struct {
+	client authorizationclient.SubjectAccessReviewInterface
+	cache  *cache.LRUExpireCache
+}
+
+// New creates an authorizer which reviews the access by Kubernetes
+func New(client authorizationclient.SubjectAccessReviewInterface) authorizer.Authorizer {
+	return &sarAuthorizer{client: client, cache: cache.NewLRUExpireCache(cacheSize)}
+}
+
+// Authorize asks Kubernetes if the user is allowed to access the resource, the result is cached for a while
+func (a *sarAuthorizer) Authorize(ctx context.Context, attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	review := &authorizationv1.SubjectAccessReview{Spec: getSpec(attrs)}
+	key, err := json.Marshal(review.Spec)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	if cached, ok := a.cache.Get(string(key)); ok {
+		result := cached.(decision)
+		return result.decision, result.reason, nil
+	}
+
+	if review, err = a.client.Create(ctx, review, metav1.CreateOptions{}); err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	result := decision{decision: authorizer.DecisionNoOpinion, reason: review.Status.Reason}
+	ttl := deniedTTL
+	if review.Status.Allowed {
+		result.decision = authorizer.DecisionAllow
+		ttl = allowedTTL
+	} else if review.Status.Denied {
+		result.decision = authorizer.DecisionDeny
+	}
+	a.cache.Add(string(key), result, ttl)
+	return result.decision, result.reason, nil
+}
+
+func getSpec(attrs authorizer.Attributes) (spec authorizationv1.SubjectAccessReviewSpec) {
+	if u := attrs.GetUser(); u != nil {
+		spec.User = u.GetName()
+		spec.UID = u.GetUID()
+		spec.Groups = u.GetGroups()
+		if extra := u.GetExtra(); len(extra) > 0 {
+			spec.Extra = make(map[string]authorizationv1.ExtraValue, len(extra))
+			for key, value := range extra {
+				spec.Extra[key] = value
+			}
+		}
+	}
+	if attrs.IsResourceRequest() {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   attrs.GetNamespace(),
+			Verb:        attrs.GetVerb(),
+			Group:       attrs.GetAPIGroup(),
+			Version:     attrs.GetAPIVersion(),
+			Resource:    attrs.GetResource(),
+			Subresource: attrs.GetSubresource(),
+			Name:        attrs.GetName(),
+		}
+	} else {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: attrs.GetPath(),
+			Verb: attrs.GetVerb(),
+		}
+	}
+	return
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subjectaccessreview
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	var reviews []*authorizationv1.SubjectAccessReview
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviews = append(reviews, review)
+		if review.Spec.User == "admin" {
+			review.Status.Allowed = true
+		} else {
+			review.Status.Denied = true
+			review.Status.Reason = "not admin"
+		}
+		return true, review, nil
+	})
+	authz := New(client.AuthorizationV1().SubjectAccessReviews())
+
+	attrs := authorizer.AttributesRecord{
+		User:            &user.DefaultInfo{Name: "admin", Groups: []string{user.AllAuthenticated}},
+		ResourceRequest: true,
+		Verb:            "list",
+		Namespace:       "project",
+		APIGroup:        "devops.kubesphere.io",
+		APIVersion:      "v1alpha3",
+		Resource:        "pipelines",
+	}
+	decision, _, err := authz.Authorize(context.TODO(), attrs)
+	assert.Nil(t, err)
+	assert.Equal(t, authorizer.DecisionAllow, decision)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, &authorizationv1.ResourceAttributes{
+			Namespace: "project",
+			Verb:      "list",
+			Group:     "devops.kubesphere.io",
+			Version:   "v1alpha3",
+			Resource:  "pipelines",
+		}, reviews[0].Spec.ResourceAttributes)
+		assert.Equal(t, []string{user.AllAuthenticated}, reviews[0].Spec.Groups)
+	}
+
+	// the same access is cached
+	decision, _, err = authz.Authorize(context.TODO(), attrs)
+	assert.Nil(t, err)
+	assert.Equal(t, authorizer.DecisionAllow, decision)
+	assert.Len(t, reviews, 1)
+
+	decision, reason, err := authz.Authorize(context.TODO(), authorizer.AttributesRecord{
+		User: &user.DefaultInfo{Name: "guest"},
+		Verb: "get",
+		Path: "/metrics",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, authorizer.DecisionDeny, decision)
+	assert.Equal(t, "not admin", reason)
+	if assert.Len(t, reviews, 2) {
+		assert.Nil(t, reviews[1].Spec.ResourceAttributes)
+		assert.Equal(t, &authorizationv1.NonResourceAttributes{Path: "/metrics", Verb: "get"}, reviews[1].Spec.NonResourceAttributes)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/devops/pkg/apiserver/authentication/request/public"
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+// WithAuthorization installs authorization handler to handler chain.
+// The requests to the public paths, such as the webhooks, are not authorized here.
+func WithAuthorization(handler http.Handler, authz authorizer.Authorizer) http.Handler {
+	if authz == nil {
+		klog.Warningf("Authorization is disabled")
+		return handler
+	}
+	s := serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if public.IsPublicPath(req.URL.Path) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		ctx := req.Context()
+		attributes, err := getAuthorizerAttributes(req)
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+		decision, reason, err := authz.Authorize(ctx, attributes)
+		if decision == authorizer.DecisionAllow {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+
+		klog.V(4).Infof("Forbidden: %#v, reason: %q", req.RequestURI, reason)
+		gv := schema.GroupVersion{Group: attributes.GetAPIGroup(), Version: attributes.GetAPIVersion()}
+		responsewriters.ErrorNegotiated(apierrors.NewForbidden(
+			schema.GroupResource{Group: attributes.GetAPIGroup(), Resource: attributes.GetResource()},
+			attributes.GetName(), errors.New(reason)), s, gv, w, req)
+	})
+}
+
+// getAuthorizerAttributes maps a request to the attributes of RBAC, the DevOps project is the namespace
+func getAuthorizerAttributes(req *http.Request) (authorizer.Attributes, error) {
+	requestInfo, found := request.RequestInfoFrom(req.Context())
+	if !found {
+		return nil, errors.New("no RequestInfo found in the context")
+	}
+	attributes := authorizer.AttributesRecord{
+		ResourceRequest: requestInfo.IsResourceRequest,
+		Path:            requestInfo.Path,
+		Verb:            requestInfo.Verb,
+	}
+	attributes.User, _ = request.UserFrom(req.Context())
+	if !requestInfo.IsResourceRequest {
+		attributes.Verb = strings.ToLower(req.Method)
+		return attributes, nil
+	}
+
+	attributes.APIGroup = requestInfo.APIGroup
+	attributes.APIVersion = requestInfo.APIVersion
+	attributes.Resource = requestInfo.Resource
+	attributes.Subresource = requestInfo.Subresource
+	attributes.Name = requestInfo.Name
+	attributes.Namespace = requestInfo.Namespace
+	if attributes.Namespace == "" {
+		attributes.Namespace = requestInfo.DevOps
+	}
+	return attributes, nil
+}
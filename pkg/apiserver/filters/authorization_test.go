@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+type fakeAuthorizer struct {
+	attributes authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	a.attributes = attrs
+	if attrs.GetUser() != nil && attrs.GetUser().GetName() == "admin" {
+		return authorizer.DecisionAllow, "", nil
+	}
+	return authorizer.DecisionNoOpinion, "forbidden", nil
+}
+
+func TestWithAuthorization(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	resolver := &request.RequestInfoFactory{
+		APIPrefixes:          sets.NewString("api", "apis", "kapis", "kapi"),
+		GrouplessAPIPrefixes: sets.NewString("api", "kapi"),
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		user       string
+		expectCode int
+		expectAttr authorizer.Attributes
+	}{{
+		name:       "allowed",
+		path:       "/kapis/devops.kubesphere.io/v1alpha3/devops/project/pipelines/p",
+		user:       "admin",
+		expectCode: http.StatusOK,
+		expectAttr: authorizer.AttributesRecord{
+			User:            &user.DefaultInfo{Name: "admin"},
+			ResourceRequest: true,
+			Path:            "/kapis/devops.kubesphere.io/v1alpha3/devops/project/pipelines/p",
+			Verb:            "get",
+			Namespace:       "project",
+			APIGroup:        "devops.kubesphere.io",
+			APIVersion:      "v1alpha3",
+			Resource:        "pipelines",
+			Name:            "p",
+		},
+	}, {
+		name:       "forbidden",
+		path:       "/kapis/devops.kubesphere.io/v1alpha3/namespaces/project/pipelines",
+		user:       "guest",
+		expectCode: http.StatusForbidden,
+	}, {
+		name:       "public path",
+		path:       "/kapis/devops.kubesphere.io/v1alpha3/webhooks/scm",
+		user:       user.Anonymous,
+		expectCode: http.StatusOK,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authz := &fakeAuthorizer{}
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			info, err := resolver.NewRequestInfo(req)
+			assert.Nil(t, err)
+			ctx := request.WithRequestInfo(req.Context(), info)
+			ctx = request.WithUser(ctx, &user.DefaultInfo{Name: tt.user})
+
+			recorder := httptest.NewRecorder()
+			WithAuthorization(handler, authz).ServeHTTP(recorder, req.WithContext(ctx))
+			assert.Equal(t, tt.expectCode, recorder.Code)
+			if tt.expectAttr != nil {
+				assert.Equal(t, tt.expectAttr, authz.attributes)
+			}
+		})
+	}
+}
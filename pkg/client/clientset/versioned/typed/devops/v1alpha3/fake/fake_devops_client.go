@@ -1,5 +1,5 @@
 /*
-Copyright 2020 The KubeSphere Authors.
+Copyright 2022 The KubeSphere Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -28,14 +28,82 @@ type FakeDevopsV1alpha3 struct {
 	*testing.Fake
 }
 
+func (c *FakeDevopsV1alpha3) Addons(namespace string) v1alpha3.AddonInterface {
+	return &FakeAddons{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) AddonStrategies() v1alpha3.AddonStrategyInterface {
+	return &FakeAddonStrategies{c}
+}
+
+func (c *FakeDevopsV1alpha3) ArtifactRoutingPolicies() v1alpha3.ArtifactRoutingPolicyInterface {
+	return &FakeArtifactRoutingPolicies{c}
+}
+
+func (c *FakeDevopsV1alpha3) BulkOperations(namespace string) v1alpha3.BulkOperationInterface {
+	return &FakeBulkOperations{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) ClusterFreezeWindows() v1alpha3.ClusterFreezeWindowInterface {
+	return &FakeClusterFreezeWindows{c}
+}
+
+func (c *FakeDevopsV1alpha3) ClusterStepTemplates() v1alpha3.ClusterStepTemplateInterface {
+	return &FakeClusterStepTemplates{c}
+}
+
+func (c *FakeDevopsV1alpha3) ClusterTemplates() v1alpha3.ClusterTemplateInterface {
+	return &FakeClusterTemplates{c}
+}
+
+func (c *FakeDevopsV1alpha3) DevOpsBackups() v1alpha3.DevOpsBackupInterface {
+	return &FakeDevOpsBackups{c}
+}
+
 func (c *FakeDevopsV1alpha3) DevOpsProjects() v1alpha3.DevOpsProjectInterface {
 	return &FakeDevOpsProjects{c}
 }
 
+func (c *FakeDevopsV1alpha3) FreezeWindows(namespace string) v1alpha3.FreezeWindowInterface {
+	return &FakeFreezeWindows{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) GitRepositories(namespace string) v1alpha3.GitRepositoryInterface {
+	return &FakeGitRepositories{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) JenkinsStatuses() v1alpha3.JenkinsStatusInterface {
+	return &FakeJenkinsStatuses{c}
+}
+
 func (c *FakeDevopsV1alpha3) Pipelines(namespace string) v1alpha3.PipelineInterface {
 	return &FakePipelines{c, namespace}
 }
 
+func (c *FakeDevopsV1alpha3) PipelineEnvironments(namespace string) v1alpha3.PipelineEnvironmentInterface {
+	return &FakePipelineEnvironments{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) PipelineRuns(namespace string) v1alpha3.PipelineRunInterface {
+	return &FakePipelineRuns{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) PipelineSources(namespace string) v1alpha3.PipelineSourceInterface {
+	return &FakePipelineSources{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) SharedResources(namespace string) v1alpha3.SharedResourceInterface {
+	return &FakeSharedResources{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) Templates(namespace string) v1alpha3.TemplateInterface {
+	return &FakeTemplates{c, namespace}
+}
+
+func (c *FakeDevopsV1alpha3) Webhooks(namespace string) v1alpha3.WebhookInterface {
+	return &FakeWebhooks{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDevopsV1alpha3) RESTClient() rest.Interface {
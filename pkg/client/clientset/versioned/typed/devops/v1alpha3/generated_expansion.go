@@ -1,5 +1,5 @@
 /*
-Copyright 2020 The KubeSphere Authors.
+Copyright 2022 The KubeSphere Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -18,6 +18,40 @@ limitations under the License.
 
 package v1alpha3
 
+type AddonExpansion interface{}
+
+type AddonStrategyExpansion interface{}
+
+type ArtifactRoutingPolicyExpansion interface{}
+
+type BulkOperationExpansion interface{}
+
+type ClusterFreezeWindowExpansion interface{}
+
+type ClusterStepTemplateExpansion interface{}
+
+type ClusterTemplateExpansion interface{}
+
+type DevOpsBackupExpansion interface{}
+
 type DevOpsProjectExpansion interface{}
 
+type FreezeWindowExpansion interface{}
+
+type GitRepositoryExpansion interface{}
+
+type JenkinsStatusExpansion interface{}
+
 type PipelineExpansion interface{}
+
+type PipelineEnvironmentExpansion interface{}
+
+type PipelineRunExpansion interface{}
+
+type PipelineSourceExpansion interface{}
+
+type SharedResourceExpansion interface{}
+
+type TemplateExpansion interface{}
+
+type WebhookExpansion interface{}
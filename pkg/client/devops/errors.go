@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+)
+
+// ErrorReason is the reason why a request to the DevOps server failed
+type ErrorReason string
+
+const (
+	// ErrorReasonNotFound means the resource does not exist
+	ErrorReasonNotFound ErrorReason = "NotFound"
+	// ErrorReasonUnauthorized means the credential is invalid or has no permission
+	ErrorReasonUnauthorized ErrorReason = "Unauthorized"
+	// ErrorReasonConflict means the resource already exists, or it's used by another one
+	ErrorReasonConflict ErrorReason = "Conflict"
+	// ErrorReasonRateLimited means the server asks the client to slow down
+	ErrorReasonRateLimited ErrorReason = "RateLimited"
+	// ErrorReasonBadRequest means the request is invalid, such as an invalid config
+	ErrorReasonBadRequest ErrorReason = "BadRequest"
+	// ErrorReasonServerError means the server failed or is unreachable
+	ErrorReasonServerError ErrorReason = "ServerError"
+)
+
+// Error is a typed error of the DevOps server, the callers could decide whether to retry by its reason
+type Error struct {
+	Reason  ErrorReason
+	Code    int
+	Message string
+}
+
+// NewError creates a typed error by the HTTP status code
+func NewError(code int, message string) *Error {
+	return &Error{Reason: reasonForCode(code), Code: code, Message: message}
+}
+
+// WrapError converts an error from the DevOps server into a typed error, the status code is detected from the error
+func WrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return err
+	}
+	return NewError(GetDevOpsStatusCode(err), err.Error())
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status code of the error
+func (e *Error) StatusCode() int {
+	return e.Code
+}
+
+// ReasonForError returns the reason of an error from the DevOps server, it's empty if the error is nil
+func ReasonForError(err error) ErrorReason {
+	if err == nil {
+		return ""
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Reason
+	}
+	if svcErr, ok := err.(restful.ServiceError); ok {
+		return reasonForCode(svcErr.Code)
+	}
+	return reasonForCode(GetDevOpsStatusCode(err))
+}
+
+// IsNotFound checks if the resource does not exist
+func IsNotFound(err error) bool {
+	return ReasonForError(err) == ErrorReasonNotFound
+}
+
+// IsUnauthorized checks if the credential is invalid or has no permission
+func IsUnauthorized(err error) bool {
+	return ReasonForError(err) == ErrorReasonUnauthorized
+}
+
+// IsConflict checks if the resource already exists or is used by another one
+func IsConflict(err error) bool {
+	return ReasonForError(err) == ErrorReasonConflict
+}
+
+// IsRateLimited checks if the server asks the client to slow down
+func IsRateLimited(err error) bool {
+	return ReasonForError(err) == ErrorReasonRateLimited
+}
+
+// IsServerError checks if the server failed or is unreachable
+func IsServerError(err error) bool {
+	return ReasonForError(err) == ErrorReasonServerError
+}
+
+// IsRetryable checks if the request might succeed later without any change. The other errors are permanent until
+// the request or the server is fixed, such as an invalid credential or a conflicting job.
+func IsRetryable(err error) bool {
+	switch ReasonForError(err) {
+	case ErrorReasonRateLimited, ErrorReasonServerError:
+		return true
+	}
+	return false
+}
+
+func reasonForCode(code int) ErrorReason {
+	switch {
+	case code == http.StatusNotFound:
+		return ErrorReasonNotFound
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrorReasonUnauthorized
+	case code == http.StatusConflict:
+		return ErrorReasonConflict
+	case code == http.StatusTooManyRequests:
+		return ErrorReasonRateLimited
+	case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
+		return ErrorReasonBadRequest
+	}
+	// the errors without a status code are mostly caused by the network
+	return ErrorReasonServerError
+}
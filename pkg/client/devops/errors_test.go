@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReasonForError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      ErrorReason
+		retryable bool
+	}{{
+		name: "nil",
+	}, {
+		name: "typed error",
+		err:  NewError(http.StatusNotFound, "not found"),
+		want: ErrorReasonNotFound,
+	}, {
+		name: "forbidden",
+		err:  restful.NewError(http.StatusForbidden, "forbidden"),
+		want: ErrorReasonUnauthorized,
+	}, {
+		name: "conflict",
+		err:  errors.New("unexpected status code: 409"),
+		want: ErrorReasonConflict,
+	}, {
+		name:      "rate limited",
+		err:       NewError(http.StatusTooManyRequests, "slow down"),
+		want:      ErrorReasonRateLimited,
+		retryable: true,
+	}, {
+		name: "invalid request",
+		err:  errors.New("bad request, code 400"),
+		want: ErrorReasonBadRequest,
+	}, {
+		name:      "server error",
+		err:       NewError(http.StatusBadGateway, "bad gateway"),
+		want:      ErrorReasonServerError,
+		retryable: true,
+	}, {
+		name:      "network error",
+		err:       errors.New("connection refused"),
+		want:      ErrorReasonServerError,
+		retryable: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReasonForError(tt.err))
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	assert.Nil(t, WrapError(nil))
+
+	err := WrapError(errors.New("not found resources"))
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "not found resources", err.Error())
+	assert.Equal(t, http.StatusNotFound, err.(*Error).StatusCode())
+
+	typed := NewError(http.StatusUnauthorized, "unauthorized")
+	assert.Same(t, typed, WrapError(typed))
+	assert.True(t, IsUnauthorized(typed))
+	assert.False(t, IsConflict(typed))
+}
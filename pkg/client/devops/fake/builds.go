@@ -0,0 +1,321 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+const (
+	// BuildStateQueued is the state of a build which is waiting for an executor
+	BuildStateQueued = "QUEUED"
+	// BuildStateRunning is the state of a build which is running
+	BuildStateRunning = "RUNNING"
+	// BuildStateFinished is the state of a build which has a result
+	BuildStateFinished = "FINISHED"
+
+	// BuildResultSuccess is the default result of the builds
+	BuildResultSuccess = "SUCCESS"
+	// BuildResultAborted is the result of the stopped builds
+	BuildResultAborted = "ABORTED"
+	// BuildResultUnknown is the result of the builds which have not finished
+	BuildResultUnknown = "UNKNOWN"
+)
+
+// buildStates are the states which a build goes through, the build moves to the next state each time it's fetched.
+// So a build is queued once it's triggered, then it's running on the first fetch, and finished on the second one.
+var buildStates = []string{BuildStateQueued, BuildStateRunning, BuildStateFinished}
+
+// jobKey identifies the job of a Pipeline, the branch is empty if it's not a multi-branch Pipeline
+type jobKey struct {
+	project  string
+	pipeline string
+	branch   string
+}
+
+// name returns the name of the job like BlueOcean does, it's the branch name in a multi-branch Pipeline
+func (k jobKey) name() string {
+	if k.branch != "" {
+		return k.branch
+	}
+	return k.pipeline
+}
+
+// build is a build of a job in the fake DevOps server
+type build struct {
+	id      int
+	fetched int
+	result  string
+}
+
+func (b *build) state() string {
+	if b.fetched >= len(buildStates) {
+		return BuildStateFinished
+	}
+	return buildStates[b.fetched]
+}
+
+func (b *build) finished() bool {
+	return b.state() == BuildStateFinished
+}
+
+// currentResult returns the result which is visible in the current state
+func (b *build) currentResult() string {
+	if b.finished() {
+		return b.result
+	}
+	return BuildResultUnknown
+}
+
+func (b *build) toPipelineRun(key jobKey) *devops.PipelineRun {
+	return &devops.PipelineRun{
+		ID:           strconv.Itoa(b.id),
+		Organization: "jenkins",
+		Pipeline:     key.name(),
+		State:        b.state(),
+		Result:       b.currentResult(),
+	}
+}
+
+func (b *build) toBuild() *devops.Build {
+	build := &devops.Build{
+		ID:       strconv.Itoa(b.id),
+		Number:   int64(b.id),
+		Building: !b.finished(),
+	}
+	if b.finished() {
+		build.Result = b.result
+	}
+	return build
+}
+
+// newNotFoundError returns the error of a missing resource like Jenkins does
+func newNotFoundError(kind string, names ...string) error {
+	var path []string
+	for _, name := range names {
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return devops.NewError(http.StatusNotFound, fmt.Sprintf("%s %s not found", kind, strings.Join(path, "/")))
+}
+
+// trigger creates a new build of a job, the caller should hold the lock
+func (d *Devops) trigger(key jobKey) (*build, error) {
+	if _, ok := d.Pipelines[key.project][key.pipeline]; !ok {
+		return nil, newNotFoundError("pipeline", key.project, key.pipeline)
+	}
+	if d.builds == nil {
+		d.builds = map[jobKey][]*build{}
+	}
+	result := d.BuildResults[strings.Join([]string{key.project, key.pipeline}, "-")]
+	if result == "" {
+		result = BuildResultSuccess
+	}
+	b := &build{id: len(d.builds[key]) + 1, result: result}
+	d.builds[key] = append(d.builds[key], b)
+	return b, nil
+}
+
+// findBuild finds a build of a job by its ID, the caller should hold the lock
+func (d *Devops) findBuild(key jobKey, runID string) (*build, error) {
+	id, _ := strconv.Atoi(runID)
+	if builds := d.builds[key]; id > 0 && id <= len(builds) {
+		return builds[id-1], nil
+	}
+	return nil, newNotFoundError("run", key.project, key.pipeline, key.branch, runID)
+}
+
+func (d *Devops) runPipeline(method string, key jobKey) (*devops.RunPipeline, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return nil, err
+	}
+	b, err := d.trigger(key)
+	if err != nil {
+		return nil, err
+	}
+	return &devops.RunPipeline{
+		ID:           strconv.Itoa(b.id),
+		Organization: "jenkins",
+		Pipeline:     key.name(),
+		State:        b.state(),
+		Result:       b.currentResult(),
+		QueueID:      strconv.Itoa(b.id),
+	}, nil
+}
+
+func (d *Devops) getPipelineRun(method string, key jobKey, runID string) (*devops.PipelineRun, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return nil, err
+	}
+	b, err := d.findBuild(key, runID)
+	if err != nil {
+		return nil, err
+	}
+	b.fetched++
+	return b.toPipelineRun(key), nil
+}
+
+func (d *Devops) stopPipeline(method string, key jobKey, runID string) (*devops.StopPipeline, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return nil, err
+	}
+	b, err := d.findBuild(key, runID)
+	if err != nil {
+		return nil, err
+	}
+	if !b.finished() {
+		b.fetched = len(buildStates)
+		b.result = BuildResultAborted
+	}
+	return &devops.StopPipeline{
+		ID:           runID,
+		Organization: "jenkins",
+		Pipeline:     key.name(),
+		State:        b.state(),
+		Result:       b.result,
+	}, nil
+}
+
+func (d *Devops) replayPipeline(method string, key jobKey, runID string) (*devops.ReplayPipeline, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return nil, err
+	}
+	if _, err := d.findBuild(key, runID); err != nil {
+		return nil, err
+	}
+	b, err := d.trigger(key)
+	if err != nil {
+		return nil, err
+	}
+	return &devops.ReplayPipeline{
+		ID:           strconv.Itoa(b.id),
+		Organization: "jenkins",
+		Pipeline:     key.name(),
+		State:        b.state(),
+		Result:       b.currentResult(),
+		QueueID:      strconv.Itoa(b.id),
+	}, nil
+}
+
+func (d *Devops) getBuildByType(method string, key jobKey, status string) (*devops.Build, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return nil, err
+	}
+	builds := d.builds[key]
+	for i := len(builds) - 1; i >= 0; i-- {
+		if b := builds[i]; matchBuildType(b, status) {
+			return b.toBuild(), nil
+		}
+	}
+	return nil, newNotFoundError(status, key.project, key.pipeline, key.branch)
+}
+
+// matchBuildType checks if a build is the kind of the build type, such as the last successful build
+func matchBuildType(b *build, status string) bool {
+	switch status {
+	case devops.LastBuild:
+		return true
+	case devops.LastCompletedBuild:
+		return b.finished()
+	case devops.LastSuccessfulBuild, devops.LastStableBuild:
+		return b.finished() && b.result == BuildResultSuccess
+	case devops.LastFailedBuild:
+		return b.finished() && b.result == "FAILURE"
+	case devops.LastUnstableBuild:
+		return b.finished() && b.result == "UNSTABLE"
+	case devops.LastUnsuccessfulBuild:
+		return b.finished() && b.result != BuildResultSuccess
+	}
+	return false
+}
+
+func (d *Devops) RunPipeline(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.RunPipeline, error) {
+	return d.runPipeline("RunPipeline", jobKey{project: projectName, pipeline: pipelineName})
+}
+
+// GetPipelineRun returns a build, the build moves to the next state each time it's fetched
+func (d *Devops) GetPipelineRun(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.PipelineRun, error) {
+	return d.getPipelineRun("GetPipelineRun", jobKey{project: projectName, pipeline: pipelineName}, runId)
+}
+
+// ListPipelineRuns lists the builds from the latest one without moving them to the next states
+func (d *Devops) ListPipelineRuns(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.PipelineRunList, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("ListPipelineRuns"); err != nil {
+		return nil, err
+	}
+	key := jobKey{project: projectName, pipeline: pipelineName}
+	if _, ok := d.Pipelines[projectName][pipelineName]; !ok {
+		return nil, newNotFoundError("pipeline", projectName, pipelineName)
+	}
+	builds := d.builds[key]
+	list := &devops.PipelineRunList{Items: []devops.PipelineRun{}, Total: len(builds)}
+	for i := len(builds) - 1; i >= 0; i-- {
+		list.Items = append(list.Items, *builds[i].toPipelineRun(key))
+	}
+	return list, nil
+}
+
+func (d *Devops) StopPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
+	return d.stopPipeline("StopPipeline", jobKey{project: projectName, pipeline: pipelineName}, runId)
+}
+
+func (d *Devops) ReplayPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
+	return d.replayPipeline("ReplayPipeline", jobKey{project: projectName, pipeline: pipelineName}, runId)
+}
+
+func (d *Devops) RunBranchPipeline(projectName, pipelineName, branchName string, httpParameters *devops.HttpParameters) (*devops.RunPipeline, error) {
+	return d.runPipeline("RunBranchPipeline", jobKey{project: projectName, pipeline: pipelineName, branch: branchName})
+}
+
+// GetBranchPipelineRun returns a build of a branch, the build moves to the next state each time it's fetched
+func (d *Devops) GetBranchPipelineRun(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.PipelineRun, error) {
+	return d.getPipelineRun("GetBranchPipelineRun", jobKey{project: projectName, pipeline: pipelineName, branch: branchName}, runId)
+}
+
+func (d *Devops) StopBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
+	return d.stopPipeline("StopBranchPipeline", jobKey{project: projectName, pipeline: pipelineName, branch: branchName}, runId)
+}
+
+func (d *Devops) ReplayBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
+	return d.replayPipeline("ReplayBranchPipeline", jobKey{project: projectName, pipeline: pipelineName, branch: branchName}, runId)
+}
+
+func (d *Devops) GetProjectPipelineBuildByType(projectId, pipelineId string, status string) (*devops.Build, error) {
+	return d.getBuildByType("GetProjectPipelineBuildByType", jobKey{project: projectId, pipeline: pipelineId}, status)
+}
+
+func (d *Devops) GetMultiBranchPipelineBuildByType(projectId, pipelineId, branch string, status string) (*devops.Build, error) {
+	return d.getBuildByType("GetMultiBranchPipelineBuildByType", jobKey{project: projectId, pipeline: pipelineId, branch: branch}, status)
+}
@@ -21,15 +21,19 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/emicklei/go-restful"
 	v1 "k8s.io/api/core/v1"
 
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/util"
 )
 
 type Devops struct {
@@ -40,6 +44,16 @@ type Devops struct {
 	Pipelines map[string]map[string]*devopsv1alpha3.Pipeline
 
 	Credentials map[string]map[string]*v1.Secret
+
+	// BuildResults are the results of the builds once they finished, the key is "project-pipeline".
+	// The builds succeed if there's no result of the Pipeline.
+	BuildResults map[string]string
+
+	// Failures are the errors returned by the methods instead of doing anything, the key is the method name
+	Failures map[string]error
+
+	builds map[jobKey][]*build
+	mutex  sync.Mutex
 }
 
 func New(projects ...string) *Devops {
@@ -74,6 +88,7 @@ func NewWithCredentials(project string, credentials ...*v1.Secret) *Devops {
 	d := &Devops{
 		Data:        nil,
 		Projects:    map[string]interface{}{},
+		Pipelines:   map[string]map[string]*devopsv1alpha3.Pipeline{},
 		Credentials: map[string]map[string]*v1.Secret{},
 	}
 
@@ -86,20 +101,54 @@ func NewWithCredentials(project string, credentials ...*v1.Secret) *Devops {
 }
 
 func (d *Devops) CreateDevOpsProject(projectId string) (string, error) {
-	if _, ok := d.Projects[projectId]; ok {
+	return d.createDevOpsProject("CreateDevOpsProject", projectId, "")
+}
+
+// CreateOwnedDevOpsProject creates the project, the owner is kept as the value of Projects
+func (d *Devops) CreateOwnedDevOpsProject(projectId, owner string) (string, error) {
+	return d.createDevOpsProject("CreateOwnedDevOpsProject", projectId, owner)
+}
+
+func (d *Devops) createDevOpsProject(method, projectId, owner string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure(method); err != nil {
+		return "", err
+	}
+	if existing, ok := d.Projects[projectId]; ok {
+		if existingOwner, _ := existing.(string); owner != "" && existingOwner != owner {
+			return "", devops.NewError(http.StatusConflict, fmt.Sprintf("the project %s is owned by %q",
+				projectId, existingOwner))
+		}
 		return projectId, nil
 	}
-	d.Projects[projectId] = true
-	d.Pipelines[projectId] = map[string]*devopsv1alpha3.Pipeline{}
-	d.Credentials[projectId] = map[string]*v1.Secret{}
+	if d.Projects[projectId] = true; owner != "" {
+		d.Projects[projectId] = owner
+	}
+	if d.Pipelines[projectId] == nil {
+		d.Pipelines[projectId] = map[string]*devopsv1alpha3.Pipeline{}
+	}
+	if d.Credentials[projectId] == nil {
+		d.Credentials[projectId] = map[string]*v1.Secret{}
+	}
 	return projectId, nil
 }
 
 func (d *Devops) DeleteDevOpsProject(projectId string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("DeleteDevOpsProject"); err != nil {
+		return err
+	}
 	if _, ok := d.Projects[projectId]; ok {
 		delete(d.Projects, projectId)
 		delete(d.Pipelines, projectId)
 		delete(d.Credentials, projectId)
+		for key := range d.builds {
+			if key.project == projectId {
+				delete(d.builds, key)
+			}
+		}
 		return nil
 	} else {
 		return &devops.ErrorResponse{
@@ -122,6 +171,11 @@ func (d *Devops) DeleteDevOpsProject(projectId string) error {
 }
 
 func (d *Devops) GetDevOpsProject(projectId string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("GetDevOpsProject"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Projects[projectId]; ok {
 		return projectId, nil
 	} else {
@@ -158,6 +212,26 @@ func (d *Devops) GetDevOpsProject(projectId string) (string, error) {
 	}
 }
 
+// Fail makes a method return the error instead of doing anything, the method works again if the error is nil
+func (d *Devops) Fail(method string, err error) *Devops {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.Failures == nil {
+		d.Failures = map[string]error{}
+	}
+	if err == nil {
+		delete(d.Failures, method)
+	} else {
+		d.Failures[method] = err
+	}
+	return d
+}
+
+// failure returns the error of a method if it's configured to fail, the caller should hold the lock
+func (d *Devops) failure(method string) error {
+	return d.Failures[method]
+}
+
 func NewFakeDevops(data map[string]interface{}) *Devops {
 	var fakeData Devops
 	fakeData.Data = data
@@ -176,90 +250,134 @@ func (d *Devops) CheckPipelineName(projectName, pipelineName string, httpParamet
 func (d *Devops) ListPipelines(httpParameters *devops.HttpParameters) (*devops.PipelineList, error) {
 	return nil, nil
 }
-func (d *Devops) GetPipelineRun(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.PipelineRun, error) {
-	return nil, nil
-}
-func (d *Devops) ListPipelineRuns(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.PipelineRunList, error) {
-	return nil, nil
-}
-func (d *Devops) StopPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
-	return nil, nil
-}
-func (d *Devops) ReplayPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
-	return nil, nil
-}
-func (d *Devops) RunPipeline(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.RunPipeline, error) {
-	return nil, nil
-}
 func (d *Devops) GetArtifacts(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) ([]devops.Artifacts, error) {
-	return nil, nil
+	artifacts, _ := d.Data[strings.Join([]string{projectName, pipelineName, runId, "artifacts"}, "-")].([]devops.Artifacts)
+	return artifacts, nil
 }
 func (d *Devops) DownloadArtifact(projectName, pipelineName, runId, filename string) (io.ReadCloser, error) {
 	return nil, nil
 }
+func (d *Devops) GetArtifactStream(projectName, pipelineName, runId, filename string, header http.Header) (*http.Response, error) {
+	return d.getArtifactStream(strings.Join([]string{projectName, pipelineName, runId, filename}, "-"), filename, header)
+}
+
+// getArtifactStream serves the artifact like Jenkins does, the artifact content is stored in Data
+func (d *Devops) getArtifactStream(key, filename string, header http.Header) (*http.Response, error) {
+	content, ok := d.Data[key].(string)
+	if !ok {
+		return nil, nil
+	}
+	request := httptest.NewRequest(http.MethodGet, "/"+filename, nil)
+	for k, v := range header {
+		request.Header[k] = v
+	}
+	recorder := httptest.NewRecorder()
+	http.ServeContent(recorder, request, filename, time.Time{}, strings.NewReader(content))
+	return recorder.Result(), nil
+}
 
 func (d *Devops) GetRunLog(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return nil, nil
 }
+func (d *Devops) GetProgressiveRunLog(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return d.getLog(strings.Join([]string{projectName, pipelineName, runId}, "-"), httpParameters)
+}
 func (d *Devops) GetStepLog(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
-	return nil, nil, nil
+	return d.getLog(strings.Join([]string{projectName, pipelineName, runId, nodeId, stepId}, "-"), httpParameters)
+}
+func (d *Devops) GetNodeLog(projectName, pipelineName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return d.getLog(strings.Join([]string{projectName, pipelineName, runId, nodeId}, "-"), httpParameters)
+}
+
+// getLog returns the log from the start offset like Jenkins does, the log text is stored in Data.
+// The log is still being written if the value of the key with the suffix "-more" is true.
+func (d *Devops) getLog(key string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	text, ok := d.Data[key].(string)
+	if !ok {
+		return nil, nil, nil
+	}
+	start := 0
+	if httpParameters != nil && httpParameters.Url != nil {
+		start, _ = strconv.Atoi(httpParameters.Url.Query().Get("start"))
+	}
+	if start > len(text) {
+		start = len(text)
+	}
+	header := http.Header{}
+	header.Set("X-Text-Size", strconv.Itoa(len(text)))
+	header.Set("X-More-Data", strconv.FormatBool(d.Data[key+"-more"] == true))
+	return []byte(text[start:]), header, nil
 }
 func (d *Devops) GetNodeSteps(projectName, pipelineName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	s := []string{projectName, pipelineName, runId, nodeId}
 	key := strings.Join(s, "-")
-	res := d.Data[key].([]devops.NodeSteps)
+	res, _ := d.Data[key].([]devops.NodeSteps)
 	return res, nil
 }
 func (d *Devops) GetPipelineRunNodes(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) ([]devops.PipelineRunNodes, error) {
 	s := []string{projectName, pipelineName, runId}
 	key := strings.Join(s, "-")
-	res := d.Data[key].([]devops.PipelineRunNodes)
+	res, _ := d.Data[key].([]devops.PipelineRunNodes)
 	return res, nil
 }
 func (d *Devops) SubmitInputStep(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return nil, nil
 }
+func (d *Devops) GetTestCases(projectName, pipelineName, runId string) ([]devops.TestCase, error) {
+	testCases, _ := d.Data[strings.Join([]string{projectName, pipelineName, runId, "tests"}, "-")].([]devops.TestCase)
+	return testCases, nil
+}
+func (d *Devops) GetFingerprints(projectName, pipelineName, runId string) ([]devops.Fingerprint, error) {
+	fingerprints, _ := d.Data[strings.Join([]string{projectName, pipelineName, runId, "fingerprints"}, "-")].([]devops.Fingerprint)
+	return fingerprints, nil
+}
 
 // BranchPipelinne operator interface
 func (d *Devops) GetBranchPipeline(projectName, pipelineName, branchName string, httpParameters *devops.HttpParameters) (*devops.BranchPipeline, error) {
 	return nil, nil
 }
-func (d *Devops) GetBranchPipelineRun(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.PipelineRun, error) {
-	return nil, nil
-}
-func (d *Devops) StopBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
-	return nil, nil
-}
-func (d *Devops) ReplayBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
-	return nil, nil
-}
-func (d *Devops) RunBranchPipeline(projectName, pipelineName, branchName string, httpParameters *devops.HttpParameters) (*devops.RunPipeline, error) {
-	return nil, nil
-}
 func (d *Devops) GetBranchArtifacts(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) ([]devops.Artifacts, error) {
-	return nil, nil
+	artifacts, _ := d.Data[strings.Join([]string{projectName, pipelineName, branchName, runId, "artifacts"}, "-")].([]devops.Artifacts)
+	return artifacts, nil
+}
+func (d *Devops) GetBranchArtifactStream(projectName, pipelineName, branchName, runId, filename string, header http.Header) (*http.Response, error) {
+	return d.getArtifactStream(strings.Join([]string{projectName, pipelineName, branchName, runId, filename}, "-"), filename, header)
 }
 func (d *Devops) GetBranchRunLog(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return nil, nil
 }
+func (d *Devops) GetBranchProgressiveRunLog(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return d.getLog(strings.Join([]string{projectName, pipelineName, branchName, runId}, "-"), httpParameters)
+}
 func (d *Devops) GetBranchStepLog(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
-	return nil, nil, nil
+	return d.getLog(strings.Join([]string{projectName, pipelineName, branchName, runId, nodeId, stepId}, "-"), httpParameters)
+}
+func (d *Devops) GetBranchNodeLog(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return d.getLog(strings.Join([]string{projectName, pipelineName, branchName, runId, nodeId}, "-"), httpParameters)
 }
 func (d *Devops) GetBranchNodeSteps(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	s := []string{projectName, pipelineName, branchName, runId, nodeId}
 	key := strings.Join(s, "-")
-	res := d.Data[key].([]devops.NodeSteps)
+	res, _ := d.Data[key].([]devops.NodeSteps)
 	return res, nil
 }
 func (d *Devops) GetBranchPipelineRunNodes(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) ([]devops.BranchPipelineRunNodes, error) {
 	s := []string{projectName, pipelineName, branchName, runId}
 	key := strings.Join(s, "-")
-	res := d.Data[key].([]devops.BranchPipelineRunNodes)
+	res, _ := d.Data[key].([]devops.BranchPipelineRunNodes)
 	return res, nil
 }
 func (d *Devops) SubmitBranchInputStep(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return nil, nil
 }
+func (d *Devops) GetBranchTestCases(projectName, pipelineName, branchName, runId string) ([]devops.TestCase, error) {
+	testCases, _ := d.Data[strings.Join([]string{projectName, pipelineName, branchName, runId, "tests"}, "-")].([]devops.TestCase)
+	return testCases, nil
+}
+func (d *Devops) GetBranchFingerprints(projectName, pipelineName, branchName, runId string) ([]devops.Fingerprint, error) {
+	fingerprints, _ := d.Data[strings.Join([]string{projectName, pipelineName, branchName, runId, "fingerprints"}, "-")].([]devops.Fingerprint)
+	return fingerprints, nil
+}
 func (d *Devops) GetPipelineBranch(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.PipelineBranch, error) {
 	return nil, nil
 }
@@ -312,14 +430,27 @@ func (d *Devops) CheckCron(projectName string, httpParameters *devops.HttpParame
 
 // CredentialOperator
 func (d *Devops) CreateCredentialInProject(projectId string, credential *v1.Secret) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("CreateCredentialInProject"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Credentials[projectId][credential.Name]; ok {
 		err := fmt.Errorf("credential name [%s] has been used", credential.Name)
-		return "", restful.NewError(http.StatusConflict, err.Error())
+		return "", devops.NewError(http.StatusConflict, err.Error())
+	}
+	if d.Credentials[projectId] == nil {
+		d.Credentials[projectId] = map[string]*v1.Secret{}
 	}
 	d.Credentials[projectId][credential.Name] = credential
 	return credential.Name, nil
 }
 func (d *Devops) UpdateCredentialInProject(projectId string, credential *v1.Secret) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("UpdateCredentialInProject"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Credentials[projectId][credential.Name]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -358,6 +489,11 @@ func (d *Devops) UpdateCredentialInProject(projectId string, credential *v1.Secr
 }
 
 func (d *Devops) GetCredentialInProject(projectId, id string) (*devops.Credential, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("GetCredentialInProject"); err != nil {
+		return nil, err
+	}
 	if _, ok := d.Credentials[projectId][id]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -391,9 +527,18 @@ func (d *Devops) GetCredentialInProject(projectId, id string) (*devops.Credentia
 		}
 		return nil, err
 	}
-	return &devops.Credential{Id: id}, nil
+	credential := &devops.Credential{Id: id, Domain: util.GlobalCredentialDomain}
+	if secret := d.Credentials[projectId][id]; secret != nil {
+		credential.Domain, _ = util.GetCredentialDomain(secret)
+	}
+	return credential, nil
 }
 func (d *Devops) DeleteCredentialInProject(projectId, id string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("DeleteCredentialInProject"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Credentials[projectId][id]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -431,27 +576,30 @@ func (d *Devops) DeleteCredentialInProject(projectId, id string) (string, error)
 	return "", nil
 }
 
-// BuildGetter
-func (d *Devops) GetProjectPipelineBuildByType(projectId, pipelineId string, status string) (*devops.Build, error) {
-	return nil, nil
-}
-func (d *Devops) GetMultiBranchPipelineBuildByType(projectId, pipelineId, branch string, status string) (*devops.Build, error) {
-	return nil, nil
-}
-
 // ProjectPipelineOperator
 func (d *Devops) CreateProjectPipeline(projectId string, pipeline *devopsv1alpha3.Pipeline) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("CreateProjectPipeline"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Pipelines[projectId][pipeline.Name]; ok {
 		err := fmt.Errorf("pipeline name [%s] has been used", pipeline.Name)
-		return "", restful.NewError(http.StatusConflict, err.Error())
+		return "", devops.NewError(http.StatusConflict, err.Error())
 	}
-	if d.Pipelines[projectId] != nil {
-		d.Pipelines[projectId][pipeline.Name] = pipeline
+	if d.Pipelines[projectId] == nil {
+		d.Pipelines[projectId] = map[string]*devopsv1alpha3.Pipeline{}
 	}
+	d.Pipelines[projectId][pipeline.Name] = pipeline
 	return "", nil
 }
 
 func (d *Devops) DeleteProjectPipeline(projectId string, pipelineId string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("DeleteProjectPipeline"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Pipelines[projectId][pipelineId]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -486,10 +634,20 @@ func (d *Devops) DeleteProjectPipeline(projectId string, pipelineId string) (str
 		return "", err
 	}
 	delete(d.Pipelines[projectId], pipelineId)
+	for key := range d.builds {
+		if key.project == projectId && key.pipeline == pipelineId {
+			delete(d.builds, key)
+		}
+	}
 	return "", nil
 }
 
 func (d *Devops) UpdateProjectPipeline(projectId string, pipeline *devopsv1alpha3.Pipeline) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("UpdateProjectPipeline"); err != nil {
+		return "", err
+	}
 	if _, ok := d.Pipelines[projectId][pipeline.Name]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -528,6 +686,11 @@ func (d *Devops) UpdateProjectPipeline(projectId string, pipeline *devopsv1alpha
 }
 
 func (d *Devops) GetProjectPipelineConfig(projectId, pipelineId string) (*devopsv1alpha3.Pipeline, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.failure("GetProjectPipelineConfig"); err != nil {
+		return nil, err
+	}
 	if _, ok := d.Pipelines[projectId][pipelineId]; !ok {
 		err := &devops.ErrorResponse{
 			Body: []byte{},
@@ -572,3 +735,35 @@ func (d *Devops) ReloadConfiguration() error {
 func (d *Devops) ApplyNewSource(string) error {
 	return nil
 }
+
+func (d *Devops) ListQueueItems() ([]devops.QueueItem, error) {
+	items, _ := d.Data["queue"].([]devops.QueueItem)
+	return items, nil
+}
+
+// RunScript records the script, and returns the output in the data if any
+func (d *Devops) RunScript(script string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.Data == nil {
+		d.Data = map[string]interface{}{}
+	}
+	scripts, _ := d.Data["scripts"].([]string)
+	d.Data["scripts"] = append(scripts, script)
+	if err, ok := d.Data["scriptError"].(error); ok {
+		return "", err
+	}
+	output, _ := d.Data["scriptOutput"].(string)
+	return output, nil
+}
+
+func (d *Devops) CancelQueueItem(id int) error {
+	items, _ := d.Data["queue"].([]devops.QueueItem)
+	for i := range items {
+		if items[i].ID == id {
+			d.Data["queue"] = append(items[:i:i], items[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%d", http.StatusNotFound)
+}
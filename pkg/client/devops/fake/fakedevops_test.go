@@ -17,11 +17,13 @@ limitations under the License.
 package fake
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
 )
 
 func TestCredential(t *testing.T) {
@@ -157,6 +159,73 @@ func TestPipeline(t *testing.T) {
 	assert.Nil(t, obj)
 }
 
+func TestBuilds(t *testing.T) {
+	pip := &devopsv1alpha3.Pipeline{}
+	pip.SetName("pip")
+	client := NewWithPipelines("project", pip)
+	client.BuildResults = map[string]string{"project-pip": "FAILURE"}
+
+	// the pipeline does not exist
+	_, err := client.RunPipeline("project", "fake", nil)
+	assert.True(t, devops.IsNotFound(err))
+
+	// the build is finished on the second fetch
+	run, err := client.RunPipeline("project", "pip", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", run.ID)
+	assert.Equal(t, BuildStateQueued, run.State)
+	var pipelineRun *devops.PipelineRun
+	for _, state := range []string{BuildStateRunning, BuildStateFinished} {
+		pipelineRun, err = client.GetPipelineRun("project", "pip", "1", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, state, pipelineRun.State)
+	}
+	assert.Equal(t, "FAILURE", pipelineRun.Result)
+	build, err := client.GetProjectPipelineBuildByType("project", "pip", devops.LastFailedBuild)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), build.Number)
+
+	// stop a build of a branch
+	run, err = client.RunBranchPipeline("project", "pip", "main", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", run.ID)
+	stop, err := client.StopBranchPipeline("project", "pip", "main", "1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, BuildResultAborted, stop.Result)
+	_, err = client.GetMultiBranchPipelineBuildByType("project", "pip", "main", devops.LastSuccessfulBuild)
+	assert.True(t, devops.IsNotFound(err))
+
+	// replay a build
+	replay, err := client.ReplayPipeline("project", "pip", "1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "2", replay.ID)
+	list, err := client.ListPipelineRuns("project", "pip", nil)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, list.Total) {
+		assert.Equal(t, "2", list.Items[0].ID)
+		assert.Equal(t, BuildStateQueued, list.Items[0].State)
+	}
+	_, err = client.GetPipelineRun("project", "pip", "3", nil)
+	assert.True(t, devops.IsNotFound(err))
+}
+
+func TestFail(t *testing.T) {
+	client := New("project")
+	client.Fail("CreateProjectPipeline", devops.NewError(http.StatusServiceUnavailable, "unavailable"))
+
+	pip := &devopsv1alpha3.Pipeline{}
+	pip.SetName("pip")
+	_, err := client.CreateProjectPipeline("project", pip)
+	assert.True(t, devops.IsRetryable(err))
+
+	// it works once the failure is cleared
+	client.Fail("CreateProjectPipeline", nil)
+	_, err = client.CreateProjectPipeline("project", pip)
+	assert.Nil(t, err)
+	_, err = client.GetProjectPipelineConfig("project", "pip")
+	assert.Nil(t, err)
+}
+
 func TestNotImplement(t *testing.T) {
 	client := New("fake")
 	assert.NotNil(t, client)
@@ -170,10 +239,6 @@ func TestNotImplement(t *testing.T) {
 		o3 interface{}
 	)
 
-	o1, o2 = client.GetProjectPipelineBuildByType("", "", "")
-	assertNils(t, o1, o2)
-	o1, o2 = client.GetMultiBranchPipelineBuildByType("", "", "", "")
-	assertNils(t, o1, o2)
 	o1, o2 = client.CheckCron("", nil)
 	assertNils(t, o1, o2)
 	o1, o2 = client.CheckScriptCompile("", "", nil)
@@ -182,42 +247,32 @@ func TestNotImplement(t *testing.T) {
 	assertNils(t, o1, o2)
 	o1, o2 = client.ListPipelines(nil)
 	assertNils(t, o1, o2)
-	o1, o2 = client.GetPipelineRun("", "", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.StopPipeline("", "", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.ReplayPipeline("", "", "", nil)
-	assertNils(t, o1, o2)
 	o1, o2 = client.GetArtifacts("", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2 = client.DownloadArtifact("", "", "", "")
 	assertNils(t, o1, o2)
+	o1, o2 = client.GetArtifactStream("", "", "", "", nil)
+	assertNils(t, o1, o2)
+	o1, o2 = client.GetBranchArtifactStream("", "", "", "", "", nil)
+	assertNils(t, o1, o2)
 	o1, o2 = client.GetRunLog("", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2, o3 = client.GetStepLog("", "", "", "", "", nil)
 	assertNils(t, o1, o2, o3)
-	o1, o2 = client.RunPipeline("", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.ListPipelineRuns("", "", nil)
-	assertNils(t, o1, o2)
+	o1, o2, o3 = client.GetNodeLog("", "", "", "", nil)
+	assertNils(t, o1, o2, o3)
 	o1, o2 = client.SubmitInputStep("", "", "", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2 = client.GetBranchPipeline("", "", "", nil)
 	assertNils(t, o1, o2)
-	o1, o2 = client.GetBranchPipelineRun("", "", "", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.StopBranchPipeline("", "", "", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.ReplayBranchPipeline("", "", "", "", nil)
-	assertNils(t, o1, o2)
-	o1, o2 = client.RunBranchPipeline("", "", "", nil)
-	assertNils(t, o1, o2)
 	o1, o2 = client.GetBranchArtifacts("", "", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2 = client.GetBranchRunLog("", "", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2, o3 = client.GetBranchStepLog("", "", "", "", "", "", nil)
 	assertNils(t, o1, o2, o3)
+	o1, o2, o3 = client.GetBranchNodeLog("", "", "", "", "", nil)
+	assertNils(t, o1, o2, o3)
 	o1, o2 = client.SubmitBranchInputStep("", "", "", "", "", "", nil)
 	assertNils(t, o1, o2)
 	o1, o2 = client.GetPipelineBranch("", "", nil)
@@ -17,12 +17,15 @@ limitations under the License.
 package devops
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/emicklei/go-restful"
 )
 
 type Interface interface {
@@ -37,9 +40,27 @@ type Interface interface {
 	ProjectOperator
 
 	ConfigurationOperator
+
+	QueueOperator
+
+	ScriptOperator
 }
 
+var (
+	// statusCodeSuffixPattern matches the error messages of the Jenkins client which end with the status code
+	statusCodeSuffixPattern = regexp.MustCompile(`, code (\d{3})$`)
+	// notFoundMessage is the error message of the Jenkins client when the status code is 404
+	notFoundMessage = "not found resources"
+)
+
 func GetDevOpsStatusCode(devopsErr error) int {
+	var typed *Error
+	if errors.As(devopsErr, &typed) {
+		return typed.Code
+	}
+	if svcErr, ok := devopsErr.(restful.ServiceError); ok {
+		return svcErr.Code
+	}
 	errStr := strings.TrimPrefix(devopsErr.Error(), "unexpected status code: ")
 	if code, err := strconv.Atoi(errStr); err == nil {
 		message := http.StatusText(code)
@@ -50,6 +71,14 @@ func GetDevOpsStatusCode(devopsErr error) int {
 	if jErr, ok := devopsErr.(*ErrorResponse); ok {
 		return jErr.Response.StatusCode
 	}
+	// the errors of the Jenkins client, such as "bad request, code 400"
+	if matches := statusCodeSuffixPattern.FindStringSubmatch(devopsErr.Error()); matches != nil {
+		code, _ := strconv.Atoi(matches[1])
+		return code
+	}
+	if devopsErr.Error() == notFoundMessage {
+		return http.StatusNotFound
+	}
 	return http.StatusInternalServerError
 }
 
@@ -60,6 +60,18 @@ func TestGetDevOpsStatusCode(t *testing.T) {
 		name: "a formatted error message",
 		args: args{devopsErr: fmt.Errorf("unexpected status code: 404")},
 		want: http.StatusNotFound,
+	}, {
+		name: "an error of the Jenkins client with the status code",
+		args: args{devopsErr: fmt.Errorf("the current user has not permission, code 403")},
+		want: http.StatusForbidden,
+	}, {
+		name: "not found error of the Jenkins client",
+		args: args{devopsErr: fmt.Errorf("not found resources")},
+		want: http.StatusNotFound,
+	}, {
+		name: "a typed error",
+		args: args{devopsErr: fmt.Errorf("wrapped: %w", NewError(http.StatusConflict, "conflict"))},
+		want: http.StatusConflict,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
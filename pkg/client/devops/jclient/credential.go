@@ -17,13 +17,24 @@ limitations under the License.
 package jclient
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	jcredential "github.com/jenkins-zh/jenkins-client/pkg/credential"
+	jutil "github.com/jenkins-zh/jenkins-client/pkg/util"
 	v1 "k8s.io/api/core/v1"
 	"kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/devops/util"
 )
 
-// CreateCredentialInProject creates a credential, then returns the ID
+// hostnameSpecificationClass is the Jenkins class which restricts the credentials of a domain to some hostnames
+const hostnameSpecificationClass = "com.cloudbees.plugins.credentials.domains.HostnameSpecification"
+
+// CreateCredentialInProject creates a credential in the credential store of the project folder, then returns the ID.
+// The credential which is restricted to some hostnames is created in its own domain.
+// It's idempotent, the credential is updated if it already exists.
 func (j *JenkinsClient) CreateCredentialInProject(projectID string, credential *v1.Secret) (id string, err error) {
 	client := j.getClient()
 
@@ -31,33 +42,179 @@ func (j *JenkinsClient) CreateCredentialInProject(projectID string, credential *
 	if cre, err = util.ConvertSecretToCredential(credential); err != nil {
 		return "", err
 	}
-	return "", client.CreateInFolder(projectID, cre)
+	domain, hostnames := util.GetCredentialDomain(credential)
+	if domain == util.GlobalCredentialDomain {
+		if err = client.CreateInFolder(projectID, cre); err != nil {
+			err = j.reconcileExistingCredential(projectID, credential, err)
+		}
+		return "", err
+	}
+
+	var store *folderCredentialStore
+	if store, err = j.getCredentialStore(projectID); err == nil {
+		if err = j.applyCredentialDomain(projectID, domain, hostnames, store.hasDomain(domain)); err == nil {
+			if err = j.createCredentialInDomain(projectID, domain, cre); err != nil {
+				err = j.reconcileExistingCredential(projectID, credential, err)
+			}
+		}
+	}
+	return
 }
 
-// UpdateCredentialInProject updates a credential
-func (j *JenkinsClient) UpdateCredentialInProject(projectID string, credential *v1.Secret) (id string, err error) {
-	client := j.getClient()
+// reconcileExistingCredential checks if the credential exists after failing to create it, since a previous attempt
+// might have created it before crashing. The existing credential is updated, otherwise the creating error is returned.
+func (j *JenkinsClient) reconcileExistingCredential(projectID string, credential *v1.Secret, createErr error) error {
+	store, err := j.getCredentialStore(projectID)
+	if err != nil || store.findDomain(credential.GetName()) == "" {
+		return createErr
+	}
+	_, err = j.UpdateCredentialInProject(projectID, credential)
+	return err
+}
 
+// UpdateCredentialInProject updates a credential, it's moved to another domain if its hostnames were changed
+func (j *JenkinsClient) UpdateCredentialInProject(projectID string, credential *v1.Secret) (id string, err error) {
 	var cre interface{}
 	if cre, err = util.ConvertSecretToCredential(credential); err != nil {
 		return "", err
 	}
 
-	err = client.UpdateInFolder(projectID, credential.GetName(), cre)
+	var store *folderCredentialStore
+	if store, err = j.getCredentialStore(projectID); err != nil {
+		return
+	}
+	domain, hostnames := util.GetCredentialDomain(credential)
+	current := store.findDomain(credential.GetName())
+	if current == "" || current == domain {
+		if domain != util.GlobalCredentialDomain {
+			if err = j.applyCredentialDomain(projectID, domain, hostnames, store.hasDomain(domain)); err != nil {
+				return
+			}
+		}
+		err = j.updateCredentialInDomain(projectID, domain, credential.GetName(), cre)
+		return
+	}
+
+	// the credential cannot be moved between the domains, delete it then create it again
+	if err = j.deleteCredentialInDomain(projectID, current, credential.GetName()); err != nil {
+		return
+	}
+	if domain == util.GlobalCredentialDomain {
+		err = j.getClient().CreateInFolder(projectID, cre)
+	} else if err = j.applyCredentialDomain(projectID, domain, hostnames, store.hasDomain(domain)); err == nil {
+		err = j.createCredentialInDomain(projectID, domain, cre)
+	}
 	return
 }
 
 // GetCredentialInProject returns a credential
 func (j *JenkinsClient) GetCredentialInProject(projectID, id string) (*devops.Credential, error) {
-	return j.jenkins.GetCredentialInProject(projectID, id)
+	store, err := j.getCredentialStore(projectID)
+	if err != nil {
+		return nil, err
+	}
+	domain := store.findDomain(id)
+	if domain == "" {
+		domain = util.GlobalCredentialDomain
+	}
+	return j.jenkins.GetCredentialInProjectDomain(projectID, domain, id)
 }
 
-// DeleteCredentialInProject deletes a credential
+// DeleteCredentialInProject deletes a credential, and its own domain if it's restricted to some hostnames
 func (j *JenkinsClient) DeleteCredentialInProject(projectID, id string) (string, error) {
-	client := j.getClient()
-	return id, client.DeleteInFolder(projectID, id)
+	store, err := j.getCredentialStore(projectID)
+	if err != nil {
+		return id, err
+	}
+	domain := store.findDomain(id)
+	if domain == "" {
+		domain = util.GlobalCredentialDomain
+	}
+	return id, j.deleteCredentialInDomain(projectID, domain, id)
 }
 
 func (j *JenkinsClient) getClient() *jcredential.CredentialsManager {
 	return &jcredential.CredentialsManager{JenkinsCore: j.Core}
 }
+
+// folderCredentialStore is the credential store of a Jenkins folder
+type folderCredentialStore struct {
+	Domains map[string]struct {
+		Credentials []struct {
+			ID string `json:"id"`
+		} `json:"credentials"`
+	} `json:"domains"`
+}
+
+func (s *folderCredentialStore) hasDomain(domain string) (ok bool) {
+	_, ok = s.Domains[domain]
+	return
+}
+
+// findDomain returns the domain which contains the credential, it's empty if the credential does not exist
+func (s *folderCredentialStore) findDomain(id string) string {
+	for domain, item := range s.Domains {
+		for _, credential := range item.Credentials {
+			if credential.ID == id {
+				return domain
+			}
+		}
+	}
+	return ""
+}
+
+func (j *JenkinsClient) getCredentialStore(projectID string) (store *folderCredentialStore, err error) {
+	request := core.NewRequest(fmt.Sprintf("/job/%s/credentials/store/folder/api/json?depth=2", projectID), &j.Core)
+	if err = request.Do(); err == nil {
+		store = &folderCredentialStore{}
+		err = request.GetObject(store)
+	}
+	return
+}
+
+// applyCredentialDomain creates or updates a credential domain with the hostname specification
+func (j *JenkinsClient) applyCredentialDomain(projectID, domain string, hostnames []string, exists bool) error {
+	api := fmt.Sprintf("/job/%s/credentials/store/folder/createDomain", projectID)
+	if exists {
+		api = fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/configSubmit", projectID, domain)
+	}
+	payload := map[string]interface{}{
+		"name":        domain,
+		"description": "managed by ks-devops, restrict the credential to the hostnames",
+		"specifications": []map[string]string{{
+			"stapler-class": hostnameSpecificationClass,
+			"includes":      strings.Join(hostnames, ","),
+			"excludes":      "",
+		}},
+	}
+	formData := url.Values{"json": {jutil.TOJSON(payload)}}
+	return core.NewRequest(api, &j.Core).AsPostFormRequest().WithValues(formData).Do()
+}
+
+func (j *JenkinsClient) createCredentialInDomain(projectID, domain string, cre interface{}) error {
+	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/createCredentials", projectID, domain)
+	formData := url.Values{"json": {fmt.Sprintf(`{"credentials": %s}`, jutil.TOJSON(cre))}}
+	return core.NewRequest(api, &j.Core).AsPostFormRequest().WithValues(formData).Do()
+}
+
+func (j *JenkinsClient) updateCredentialInDomain(projectID, domain, id string, cre interface{}) error {
+	if domain == util.GlobalCredentialDomain {
+		return j.getClient().UpdateInFolder(projectID, id, cre)
+	}
+	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/credential/%s/updateSubmit", projectID, domain, id)
+	formData := url.Values{"json": {jutil.TOJSON(cre)}}
+	return core.NewRequest(api, &j.Core).AsPostFormRequest().WithValues(formData).Do()
+}
+
+// deleteCredentialInDomain deletes a credential, the domain is deleted as well if it's not the global one
+func (j *JenkinsClient) deleteCredentialInDomain(projectID, domain, id string) (err error) {
+	if domain == util.GlobalCredentialDomain {
+		return j.getClient().DeleteInFolder(projectID, id)
+	}
+	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/credential/%s/doDelete", projectID, domain, id)
+	if err = core.NewRequest(api, &j.Core).WithPostMethod().Do(); err == nil {
+		api = fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/doDelete", projectID, domain)
+		err = core.NewRequest(api, &j.Core).WithPostMethod().Do()
+	}
+	return
+}
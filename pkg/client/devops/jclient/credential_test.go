@@ -17,7 +17,10 @@ limitations under the License.
 package jclient
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
@@ -48,6 +51,7 @@ func TestDeleteCredentialInProject(t *testing.T) {
 
 	const folder = "fake"
 	const id = "id"
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[{"id":"id"}]}}}`)
 	jcredential.PrepareForDeleteCredentialInFolder(roundTripper, "http://localhost", "", "", folder, id)
 	val, err := client.DeleteCredentialInProject(folder, id)
 	assert.Nil(t, err)
@@ -78,6 +82,7 @@ func TestUpdateCredentialInProject(t *testing.T) {
 
 	const folder = "fake"
 	const id = "id"
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[{"id":"id"}]}}}`)
 	jcredential.PrepareForUpdateCredentialInFolder(roundTripper, "http://localhost", "", "",
 		folder, id, strings.NewReader(formData.Encode()))
 	val, err := client.UpdateCredentialInProject(folder, secret.DeepCopy())
@@ -125,3 +130,115 @@ func TestCreateCredentialInProject(t *testing.T) {
 	_, err = client.CreateCredentialInProject(folder, unknownSecret.DeepCopy())
 	assert.NotNil(t, err)
 }
+
+func TestCreateExistingCredentialInProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	roundTripper := mhttp.NewMockRoundTripper(ctrl)
+	client := &JenkinsClient{
+		Core: core.JenkinsCore{
+			URL:          "http://localhost",
+			RoundTripper: roundTripper,
+		},
+	}
+
+	secret := &v1.Secret{}
+	secret.SetName("id")
+	secret.Type = devopsv1alpha3.SecretTypeSecretText
+	secret.Data = map[string][]byte{devopsv1alpha3.SecretTextSecretKey: []byte("secret")}
+	data, err := devopsutil.ConvertSecretToCredential(secret.DeepCopy())
+	assert.Nil(t, err)
+	const folder = "fake"
+	createRequest := func() {
+		formData := url.Values{"json": {fmt.Sprintf(`{"credentials": %s}`, util.TOJSON(data))}}
+		request, _ := http.NewRequest(http.MethodPost, "http://localhost/job/fake/credentials/store/folder/domain/_/createCredentials",
+			strings.NewReader(formData.Encode()))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		core.PrepareCommonPostWithResponseCode(request, "", http.StatusConflict, roundTripper, "", "", "http://localhost")
+	}
+
+	// the credential was created by a previous attempt, it's updated instead of failing
+	createRequest()
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[{"id":"id"}]}}}`)
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[{"id":"id"}]}}}`)
+	prepareForPostForm(roundTripper, "/job/fake/credentials/store/folder/domain/_/credential/id/updateSubmit", util.TOJSON(data))
+	_, err = client.CreateCredentialInProject(folder, secret.DeepCopy())
+	assert.Nil(t, err)
+
+	// the credential does not exist, the error is returned
+	createRequest()
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[]}}}`)
+	_, err = client.CreateCredentialInProject(folder, secret.DeepCopy())
+	assert.NotNil(t, err)
+}
+
+func TestCredentialInDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	roundTripper := mhttp.NewMockRoundTripper(ctrl)
+	client := &JenkinsClient{
+		Core: core.JenkinsCore{
+			URL:          "http://localhost",
+			RoundTripper: roundTripper,
+		},
+	}
+
+	secret := &v1.Secret{}
+	secret.SetName("id")
+	secret.SetAnnotations(map[string]string{devopsv1alpha3.CredentialHostnamesAnnoKey: "github.com, *.example.com"})
+	secret.Type = devopsv1alpha3.SecretTypeSecretText
+	secret.Data = map[string][]byte{devopsv1alpha3.SecretTextSecretKey: []byte("secret")}
+	data, err := devopsutil.ConvertSecretToCredential(secret.DeepCopy())
+	assert.Nil(t, err)
+	const folder = "fake"
+
+	// create the domain, then the credential in it
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{"credentials":[]}}}`)
+	prepareForPostForm(roundTripper, "/job/fake/credentials/store/folder/createDomain", util.TOJSON(map[string]interface{}{
+		"name":        "id",
+		"description": "managed by ks-devops, restrict the credential to the hostnames",
+		"specifications": []map[string]string{{
+			"stapler-class": hostnameSpecificationClass,
+			"includes":      "github.com,*.example.com",
+			"excludes":      "",
+		}},
+	}))
+	prepareForPostForm(roundTripper, "/job/fake/credentials/store/folder/domain/id/createCredentials",
+		fmt.Sprintf(`{"credentials": %s}`, util.TOJSON(data)))
+	_, err = client.CreateCredentialInProject(folder, secret.DeepCopy())
+	assert.Nil(t, err)
+
+	// move the credential back to the global domain
+	global := secret.DeepCopy()
+	global.SetAnnotations(nil)
+	prepareForGetCredentialStore(roundTripper, folder, `{"domains":{"_":{},"id":{"credentials":[{"id":"id"}]}}}`)
+	prepareForPost(roundTripper, "/job/fake/credentials/store/folder/domain/id/credential/id/doDelete")
+	prepareForPost(roundTripper, "/job/fake/credentials/store/folder/domain/id/doDelete")
+	formData := url.Values{}
+	formData.Add("json", fmt.Sprintf(`{"credentials": %s}`, util.TOJSON(data)))
+	jcredential.PrepareForCreateCredentialInFolder(roundTripper, "http://localhost", "", "",
+		folder, strings.NewReader(formData.Encode()))
+	_, err = client.UpdateCredentialInProject(folder, global)
+	assert.Nil(t, err)
+}
+
+func prepareForGetCredentialStore(roundTripper *mhttp.MockRoundTripper, folder, store string) {
+	request, _ := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("http://localhost/job/%s/credentials/store/folder/api/json?depth=2", folder), nil)
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Request:    request,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(store)),
+	}
+	roundTripper.EXPECT().RoundTrip(core.NewRequestMatcher(request)).Return(response, nil)
+}
+
+func prepareForPostForm(roundTripper *mhttp.MockRoundTripper, api, json string) {
+	formData := url.Values{"json": {json}}
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost"+api, strings.NewReader(formData.Encode()))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	core.PrepareCommonPost(request, "", roundTripper, "", "", "http://localhost")
+}
+
+func prepareForPost(roundTripper *mhttp.MockRoundTripper, api string) {
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost"+api, nil)
+	core.PrepareCommonPost(request, "", roundTripper, "", "", "http://localhost")
+}
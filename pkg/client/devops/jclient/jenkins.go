@@ -57,3 +57,14 @@ func NewJenkinsClient(options *jenkins.Options) (*JenkinsClient, error) {
 		jenkins: devopsClient, // For refactor purpose only
 	}, nil
 }
+
+// UseFolderResolver makes the client talk to the Jenkins folders resolved from the namespaces of the DevOps projects
+func (j *JenkinsClient) UseFolderResolver(resolver *jenkins.FolderResolver) {
+	j.Core.RoundTripper = resolver.WrapTransport(j.Core.RoundTripper)
+	if j.jenkins != nil {
+		// copy the HTTP client in case it's the default one
+		httpClient := *j.jenkins.Requester.Client
+		httpClient.Transport = resolver.WrapTransport(httpClient.Transport)
+		j.jenkins.Requester.Client = &httpClient
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package jclient
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -76,16 +77,64 @@ func (j *JenkinsClient) DownloadArtifact(projectName, pipelineName, runID, filen
 	return c.GetArtifact(projectName, pipelineName, jobRunID, filename)
 }
 
+// GetArtifactStream returns the response of downloading an artifact, the caller should close the body
+func (j *JenkinsClient) GetArtifactStream(projectName, pipelineName, runID, filename string, header http.Header) (*http.Response, error) {
+	return j.getArtifactStream(fmt.Sprintf("/job/%s/job/%s", projectName, pipelineName), runID, filename, header)
+}
+
+// GetBranchArtifactStream returns the response of downloading an artifact of a multi-branch pipeline
+func (j *JenkinsClient) GetBranchArtifactStream(projectName, pipelineName, branchName, runID, filename string, header http.Header) (*http.Response, error) {
+	return j.getArtifactStream(fmt.Sprintf("/job/%s/job/%s/job/%s", projectName, pipelineName, branchName),
+		runID, filename, header)
+}
+
+// artifactHeaders are the request headers which are passed to Jenkins
+var artifactHeaders = []string{"Range", "If-Range", "If-None-Match", "If-Modified-Since"}
+
+func (j *JenkinsClient) getArtifactStream(jobPath, runID, filename string, header http.Header) (*http.Response, error) {
+	if _, err := strconv.Atoi(runID); err != nil {
+		return nil, fmt.Errorf("runId error, not a number: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/%s/artifact/%s", j.Core.URL, jobPath, runID, filename), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = j.Core.AuthHandle(req); err != nil {
+		return nil, err
+	}
+	for _, key := range artifactHeaders {
+		if value := header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	client := j.Core.GetClient()
+	// the artifact might be too large to download within the default timeout.
+	// The redirection is followed, for instance, to S3 when the artifact manager plugin is enabled.
+	client.Timeout = 0
+	return client.Do(req)
+}
+
 // GetRunLog returns the log output of a pipeline run
 func (j *JenkinsClient) GetRunLog(projectName, pipelineName, runID string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return j.jenkins.GetRunLog(projectName, pipelineName, runID, httpParameters)
 }
 
+// GetProgressiveRunLog returns the log output of a pipeline run from the start offset
+func (j *JenkinsClient) GetProgressiveRunLog(projectName, pipelineName, runID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return j.jenkins.GetProgressiveRunLog(projectName, pipelineName, runID, httpParameters)
+}
+
 // GetStepLog returns the log output of a step
 func (j *JenkinsClient) GetStepLog(projectName, pipelineName, runID, nodeID, stepID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
 	return j.jenkins.GetStepLog(projectName, pipelineName, runID, nodeID, stepID, httpParameters)
 }
 
+// GetNodeLog returns the log output of a node
+func (j *JenkinsClient) GetNodeLog(projectName, pipelineName, runID, nodeID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return j.jenkins.GetNodeLog(projectName, pipelineName, runID, nodeID, httpParameters)
+}
+
 // GetNodeSteps returns the node steps
 func (j *JenkinsClient) GetNodeSteps(projectName, pipelineName, runID, nodeID string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	return j.jenkins.GetNodeSteps(projectName, pipelineName, runID, nodeID, httpParameters)
@@ -101,6 +150,16 @@ func (j *JenkinsClient) SubmitInputStep(projectName, pipelineName, runID, nodeID
 	return j.jenkins.SubmitInputStep(projectName, pipelineName, runID, nodeID, stepID, httpParameters)
 }
 
+// GetTestCases returns the test cases of a pipeline run
+func (j *JenkinsClient) GetTestCases(projectName, pipelineName, runID string) ([]devops.TestCase, error) {
+	return j.getTestCases(fmt.Sprintf("/job/%s/job/%s", projectName, pipelineName), runID)
+}
+
+// GetFingerprints returns the fingerprints of the archived files of a pipeline run
+func (j *JenkinsClient) GetFingerprints(projectName, pipelineName, runID string) ([]devops.Fingerprint, error) {
+	return j.getFingerprints(fmt.Sprintf("/job/%s/job/%s", projectName, pipelineName), runID)
+}
+
 // GetBranchPipeline returns the branch pipeline
 func (j *JenkinsClient) GetBranchPipeline(projectName, pipelineName, branchName string, httpParameters *devops.HttpParameters) (*devops.BranchPipeline, error) {
 	return j.jenkins.GetBranchPipeline(projectName, pipelineName, branchName, httpParameters)
@@ -136,11 +195,21 @@ func (j *JenkinsClient) GetBranchRunLog(projectName, pipelineName, branchName, r
 	return j.jenkins.GetBranchRunLog(projectName, pipelineName, branchName, runID, httpParameters)
 }
 
+// GetBranchProgressiveRunLog returns the log output of a pipeline run from the start offset
+func (j *JenkinsClient) GetBranchProgressiveRunLog(projectName, pipelineName, branchName, runID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return j.jenkins.GetBranchProgressiveRunLog(projectName, pipelineName, branchName, runID, httpParameters)
+}
+
 // GetBranchStepLog returns the log output of a pipeline step
 func (j *JenkinsClient) GetBranchStepLog(projectName, pipelineName, branchName, runID, nodeID, stepID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
 	return j.jenkins.GetBranchStepLog(projectName, pipelineName, branchName, runID, nodeID, stepID, httpParameters)
 }
 
+// GetBranchNodeLog returns the log output of a pipeline node
+func (j *JenkinsClient) GetBranchNodeLog(projectName, pipelineName, branchName, runID, nodeID string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	return j.jenkins.GetBranchNodeLog(projectName, pipelineName, branchName, runID, nodeID, httpParameters)
+}
+
 // GetBranchNodeSteps returns the node steps
 func (j *JenkinsClient) GetBranchNodeSteps(projectName, pipelineName, branchName, runID, nodeID string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	return j.jenkins.GetBranchNodeSteps(projectName, pipelineName, branchName, runID, nodeID, httpParameters)
@@ -156,6 +225,67 @@ func (j *JenkinsClient) SubmitBranchInputStep(projectName, pipelineName, branchN
 	return j.jenkins.SubmitBranchInputStep(projectName, pipelineName, branchName, runID, nodeID, stepID, httpParameters)
 }
 
+// GetBranchTestCases returns the test cases of a multi-branch pipeline run
+func (j *JenkinsClient) GetBranchTestCases(projectName, pipelineName, branchName, runID string) ([]devops.TestCase, error) {
+	return j.getTestCases(fmt.Sprintf("/job/%s/job/%s/job/%s", projectName, pipelineName, branchName), runID)
+}
+
+// GetBranchFingerprints returns the fingerprints of the archived files of a multi-branch pipeline run
+func (j *JenkinsClient) GetBranchFingerprints(projectName, pipelineName, branchName, runID string) ([]devops.Fingerprint, error) {
+	return j.getFingerprints(fmt.Sprintf("/job/%s/job/%s/job/%s", projectName, pipelineName, branchName), runID)
+}
+
+// getTestCases returns the test cases from the test report of JUnit plugin.
+// There is no test report if the pipeline run didn't publish any test results, it's not an error.
+func (j *JenkinsClient) getTestCases(jobPath, runID string) (testCases []devops.TestCase, err error) {
+	if _, err = strconv.Atoi(runID); err != nil {
+		return nil, fmt.Errorf("runId error, not a number: %v", err)
+	}
+	var (
+		statusCode int
+		data       []byte
+	)
+	api := fmt.Sprintf("%s/%s/testReport/api/json?tree=suites[cases[className,name,status]]", jobPath, runID)
+	if statusCode, data, err = j.Core.Request(http.MethodGet, api, nil, nil); err != nil {
+		return
+	}
+	switch statusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return
+	default:
+		err = j.Core.ErrorHandle(statusCode, data)
+		return
+	}
+
+	report := struct {
+		Suites []struct {
+			Cases []devops.TestCase `json:"cases"`
+		} `json:"suites"`
+	}{}
+	if err = json.Unmarshal(data, &report); err != nil {
+		return
+	}
+	for _, suite := range report.Suites {
+		testCases = append(testCases, suite.Cases...)
+	}
+	return
+}
+
+func (j *JenkinsClient) getFingerprints(jobPath, runID string) ([]devops.Fingerprint, error) {
+	if _, err := strconv.Atoi(runID); err != nil {
+		return nil, fmt.Errorf("runId error, not a number: %v", err)
+	}
+	build := struct {
+		Fingerprint []devops.Fingerprint `json:"fingerprint"`
+	}{}
+	api := fmt.Sprintf("%s/%s/api/json?tree=fingerprint[fileName,hash]", jobPath, runID)
+	if err := j.Core.RequestWithData(http.MethodGet, api, nil, nil, http.StatusOK, &build); err != nil {
+		return nil, err
+	}
+	return build.Fingerprint, nil
+}
+
 // GetPipelineBranch returns PipelineBranch
 func (j *JenkinsClient) GetPipelineBranch(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.PipelineBranch, error) {
 	return j.jenkins.GetPipelineBranch(projectName, pipelineName, httpParameters)
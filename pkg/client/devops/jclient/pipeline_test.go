@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestGetArtifactStream(t *testing.T) {
+	// simulate the storage which the artifact manager redirects to
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "build.txt", time.Time{}, strings.NewReader("0123456789"))
+	}))
+	defer storage.Close()
+
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		switch r.URL.Path {
+		case "/job/ns/job/pipeline/1/artifact/logs/build.txt":
+			http.ServeContent(w, r, "build.txt", time.Time{}, strings.NewReader("0123456789"))
+		case "/job/ns/job/pipeline/job/main/2/artifact/logs/build.txt":
+			http.Redirect(w, r, storage.URL+"/build.txt", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &JenkinsClient{Core: core.JenkinsCore{URL: server.URL}}
+	header := http.Header{}
+	header.Set("Range", "bytes=2-5")
+
+	resp, err := client.GetArtifactStream("ns", "pipeline", "1", "logs/build.txt", header)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "2345", string(data))
+
+	resp, err = client.GetBranchArtifactStream("ns", "pipeline", "main", "2", "logs/build.txt", header)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	data, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "2345", string(data))
+
+	resp, err = client.GetArtifactStream("ns", "pipeline", "3", "fake", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	_, err = client.GetArtifactStream("ns", "pipeline", "invalid", "fake", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"/job/ns/job/pipeline/1/artifact/logs/build.txt",
+		"/job/ns/job/pipeline/job/main/2/artifact/logs/build.txt", "/job/ns/job/pipeline/3/artifact/fake"}, requestedPaths)
+}
+
+func TestGetTestCasesAndFingerprints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/ns/job/pipeline/1/testReport/api/json":
+			_, _ = w.Write([]byte(`{"suites":[{"cases":[{"className":"a","name":"b","status":"FAILED"}]},` +
+				`{"cases":[{"className":"a","name":"c","status":"PASSED"}]}]}`))
+		case "/job/ns/job/pipeline/job/main/2/api/json":
+			_, _ = w.Write([]byte(`{"fingerprint":[{"fileName":"app.jar","hash":"abc"}]}`))
+		case "/job/ns/job/pipeline/3/testReport/api/json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &JenkinsClient{Core: core.JenkinsCore{URL: server.URL}}
+
+	testCases, err := client.GetTestCases("ns", "pipeline", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, []devops.TestCase{{ClassName: "a", Name: "b", Status: "FAILED"},
+		{ClassName: "a", Name: "c", Status: "PASSED"}}, testCases)
+
+	// there is no test report
+	testCases, err = client.GetBranchTestCases("ns", "pipeline", "main", "2")
+	assert.Nil(t, err)
+	assert.Empty(t, testCases)
+
+	_, err = client.GetTestCases("ns", "pipeline", "3")
+	assert.NotNil(t, err)
+	_, err = client.GetTestCases("ns", "pipeline", "invalid")
+	assert.NotNil(t, err)
+
+	fingerprints, err := client.GetBranchFingerprints("ns", "pipeline", "main", "2")
+	assert.Nil(t, err)
+	assert.Equal(t, []devops.Fingerprint{{FileName: "app.jar", Hash: "abc"}}, fingerprints)
+
+	_, err = client.GetFingerprints("ns", "pipeline", "1")
+	assert.NotNil(t, err)
+}
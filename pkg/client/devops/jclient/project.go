@@ -21,6 +21,11 @@ func (j *JenkinsClient) CreateDevOpsProject(projectID string) (string, error) {
 	return j.jenkins.CreateDevOpsProject(projectID)
 }
 
+// CreateOwnedDevOpsProject creates a devops project with its owner
+func (j *JenkinsClient) CreateOwnedDevOpsProject(projectID, owner string) (string, error) {
+	return j.jenkins.CreateOwnedDevOpsProject(projectID, owner)
+}
+
 // DeleteDevOpsProject deletes a devops project
 func (j *JenkinsClient) DeleteDevOpsProject(projectID string) error {
 	return j.jenkins.DeleteDevOpsProject(projectID)
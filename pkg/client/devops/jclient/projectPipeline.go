@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/emicklei/go-restful"
 	"github.com/jenkins-zh/jenkins-client/pkg/job"
 	"k8s.io/klog/v2"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
@@ -28,43 +27,52 @@ import (
 	"kubesphere.io/devops/pkg/client/devops"
 )
 
-// CreateProjectPipeline creates the pipeline
+// CreateProjectPipeline creates the pipeline. It's idempotent, the existing job of the same type is treated as created
+// and its config is reconciled, since a previous attempt might have created it before crashing.
 func (j *JenkinsClient) CreateProjectPipeline(projectID string, pipeline *v1alpha3.Pipeline) (string, error) {
 	jclient := job.Client{
 		JenkinsCore: j.Core,
 	}
-	projectPipelineName := fmt.Sprintf("%s %s", projectID, pipeline.Name)
-	job, _ := jclient.GetJob(projectPipelineName)
-	if job != nil {
-		err := fmt.Errorf("job name [%s] has been used", job.Name)
-		return "", restful.NewError(http.StatusConflict, err.Error())
-	}
+
+	var createPayload *job.CreateJobPayload
+	var err error
 	switch pipeline.Spec.Type {
 	case devopsv1alpha3.NoScmPipelineType:
-		createPayload, err := getCreatePayload(pipeline.Spec.Pipeline)
-		if err != nil {
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
-		}
-		err = jclient.CreateJobInFolder(*createPayload, projectID)
-		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
-		}
-		return pipeline.Name, nil
+		createPayload, err = getCreatePayload(pipeline.Spec.Pipeline)
 	case devopsv1alpha3.MultiBranchPipelineType:
-		createPayload, err := getCreateMultiBranchPipelinePayload(pipeline.Spec.MultiBranchPipeline)
-		if err != nil {
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
-		}
-		err = jclient.CreateJobInFolder(*createPayload, projectID)
-		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
-		}
-		return pipeline.Name, nil
+		createPayload, err = getCreateMultiBranchPipelinePayload(pipeline.Spec.MultiBranchPipeline)
 	default:
 		err := fmt.Errorf("error unsupport job type")
 		klog.Errorf("%+v", err)
-		return "", restful.NewError(http.StatusBadRequest, err.Error())
+		return "", devops.NewError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return "", devops.NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	projectPipelineName := fmt.Sprintf("%s %s", projectID, pipeline.Name)
+	if existing, _ := jclient.GetJob(projectPipelineName); existing != nil {
+		return j.reconcileExistingJob(projectID, pipeline, existing, createPayload.Mode)
+	}
+	if err = jclient.CreateJobInFolder(*createPayload, projectID); err != nil {
+		// the job might be created even if the response was lost, verify it before failing
+		if existing, _ := jclient.GetJob(projectPipelineName); existing != nil {
+			return j.reconcileExistingJob(projectID, pipeline, existing, createPayload.Mode)
+		}
+		return "", devops.WrapError(err)
+	}
+	return pipeline.Name, nil
+}
+
+// reconcileExistingJob updates the config of an existing job, it's a conflict if the job has another type
+func (j *JenkinsClient) reconcileExistingJob(projectID string, pipeline *v1alpha3.Pipeline, existing *job.Job,
+	mode string) (string, error) {
+	if existing.Type != mode {
+		err := fmt.Errorf("job name [%s] has been used by a job of %s", existing.Name, existing.Type)
+		return "", devops.NewError(http.StatusConflict, err.Error())
 	}
+	klog.V(4).Infof("job %s/%s already exists, reconcile its config", projectID, pipeline.Name)
+	return j.UpdateProjectPipeline(projectID, pipeline)
 }
 
 // DeleteProjectPipeline deletes pipeline
@@ -75,7 +83,7 @@ func (j *JenkinsClient) DeleteProjectPipeline(projectID string, pipelineID strin
 	projectPipelineName := fmt.Sprintf("%s %s", projectID, pipelineID)
 	err := jclient.Delete(projectPipelineName)
 	if err != nil {
-		return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		return "", devops.WrapError(err)
 	}
 	return pipelineID, nil
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+)
+
+func TestCreateProjectPipelineIdempotently(t *testing.T) {
+	const workflowJob = `{"_class":"org.jenkinsci.plugins.workflow.job.WorkflowJob","name":"%s"}`
+	const config = `<?xml version='1.1' encoding='UTF-8'?><flow-definition plugin="workflow-job"><actions/>` +
+		`<description></description><keepDependencies>false</keepDependencies><properties/>` +
+		`<definition class="org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition" plugin="workflow-cps">` +
+		`<script></script><sandbox>true</sandbox></definition><triggers/><disabled>false</disabled></flow-definition>`
+
+	// the jobs which exist in Jenkins
+	jobs := map[string]bool{"existing": true}
+	var updatedConfigs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/view/all"), "/job/ns/"), "/")
+		switch {
+		case r.Method == http.MethodPost && path == "createItem":
+			// the job is created, but the response is lost like Jenkins was restarted
+			jobs[r.FormValue("name")] = true
+			w.WriteHeader(http.StatusBadGateway)
+		case strings.HasSuffix(path, "/api/json"):
+			name := strings.TrimSuffix(strings.TrimPrefix(path, "job/"), "/api/json")
+			if !jobs[name] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(strings.ReplaceAll(workflowJob, "%s", name)))
+		case strings.HasSuffix(path, "/config.xml") && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(config))
+		case strings.HasSuffix(path, "/config.xml"):
+			data, _ := io.ReadAll(r.Body)
+			updatedConfigs = append(updatedConfigs, string(data))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewJenkinsClient(&jenkins.Options{Host: server.URL})
+	assert.Nil(t, err)
+	newPipeline := func(name string, pipelineType v1alpha3.PipelineType, script string) *v1alpha3.Pipeline {
+		pipeline := &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec:       v1alpha3.PipelineSpec{Type: pipelineType},
+		}
+		if pipelineType == v1alpha3.NoScmPipelineType {
+			pipeline.Spec.Pipeline = &v1alpha3.NoScmPipeline{Name: name, Jenkinsfile: script}
+		} else {
+			pipeline.Spec.MultiBranchPipeline = &v1alpha3.MultiBranchPipeline{Name: name}
+		}
+		return pipeline
+	}
+
+	// the existing job is reconciled instead of failing
+	name, err := client.CreateProjectPipeline("ns", newPipeline("existing", v1alpha3.NoScmPipelineType, "echo 1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "existing", name)
+	if assert.Equal(t, 1, len(updatedConfigs)) {
+		assert.Contains(t, updatedConfigs[0], "echo 1")
+	}
+
+	// the job was created though the creating request failed
+	name, err = client.CreateProjectPipeline("ns", newPipeline("lost", v1alpha3.NoScmPipelineType, "echo 2"))
+	assert.Nil(t, err)
+	assert.Equal(t, "lost", name)
+	if assert.Equal(t, 2, len(updatedConfigs)) {
+		assert.Contains(t, updatedConfigs[1], "echo 2")
+	}
+
+	// the existing job has another type
+	_, err = client.CreateProjectPipeline("ns", newPipeline("existing", v1alpha3.MultiBranchPipelineType, ""))
+	if assert.NotNil(t, err) {
+		assert.True(t, devops.IsConflict(err))
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/queue"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// ListQueueItems returns the items of the Jenkins queue which belong to the pipelines
+func (j *JenkinsClient) ListQueueItems() (items []devops.QueueItem, err error) {
+	jobQueue := struct {
+		Items []struct {
+			devops.QueueItem
+			Task struct {
+				URL string `json:"url"`
+			} `json:"task"`
+		} `json:"items"`
+	}{}
+	if err = j.Core.RequestWithData(http.MethodGet,
+		"/queue/api/json?tree=items[id,why,blocked,buildable,stuck,inQueueSince,task[url]]",
+		nil, nil, http.StatusOK, &jobQueue); err != nil {
+		return
+	}
+
+	items = []devops.QueueItem{}
+	for _, jobItem := range jobQueue.Items {
+		item := jobItem.QueueItem
+		// the items of other jobs, such as the branch indexing, are ignored
+		if item.ProjectName, item.PipelineName, item.BranchName, err = parseJobURL(jobItem.Task.URL); err != nil {
+			err = nil
+			continue
+		}
+		items = append(items, item)
+	}
+	return
+}
+
+// CancelQueueItem cancels an item of the Jenkins queue
+func (j *JenkinsClient) CancelQueueItem(id int) error {
+	queueClient := &queue.Client{JenkinsCore: j.Core}
+	return queueClient.Cancel(id)
+}
+
+// parseJobURL parses the URL of a job, like http://jenkins/job/<project>/job/<pipeline>/job/<branch>/
+func parseJobURL(jobURL string) (projectName, pipelineName, branchName string, err error) {
+	var parsedURL *url.URL
+	if parsedURL, err = url.Parse(jobURL); err != nil {
+		return
+	}
+	segments := strings.Split(strings.Trim(parsedURL.EscapedPath(), "/"), "/")
+	var names []string
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "job" {
+			var name string
+			if name, err = url.PathUnescape(segments[i+1]); err != nil {
+				return
+			}
+			names = append(names, name)
+			i++
+		}
+	}
+	switch len(names) {
+	case 3:
+		branchName = names[2]
+		fallthrough
+	case 2:
+		projectName, pipelineName = names[0], names[1]
+	default:
+		err = fmt.Errorf("%s is not a pipeline", jobURL)
+	}
+	return
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestListAndCancelQueueItems(t *testing.T) {
+	var cancelled string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/queue/api/json":
+			_, _ = w.Write([]byte(`{"items":[` +
+				`{"id":1,"why":"Waiting for next available executor","buildable":true,"inQueueSince":100,` +
+				`"task":{"url":"http://jenkins/job/ns/job/pipeline/"}},` +
+				`{"id":2,"why":"Waiting for resources [db]","blocked":true,` +
+				`"task":{"url":"http://jenkins/job/ns/job/multi-branch/job/feat%252Fa/"}},` +
+				`{"id":3,"task":{"url":"http://jenkins/job/ns/"}}]}`))
+		case "/queue/cancelItem":
+			cancelled = r.URL.Query().Get("id")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &JenkinsClient{Core: core.JenkinsCore{URL: server.URL}}
+
+	items, err := client.ListQueueItems()
+	assert.Nil(t, err)
+	assert.Equal(t, []devops.QueueItem{{
+		ID: 1, ProjectName: "ns", PipelineName: "pipeline", Why: "Waiting for next available executor",
+		Buildable: true, InQueueSince: 100,
+	}, {
+		ID: 2, ProjectName: "ns", PipelineName: "multi-branch", BranchName: "feat%2Fa",
+		Why: "Waiting for resources [db]", Blocked: true,
+	}}, items)
+
+	assert.Nil(t, client.CancelQueueItem(1))
+	assert.Equal(t, "1", cancelled)
+}
+
+func Test_parseJobURL(t *testing.T) {
+	projectName, pipelineName, branchName, err := parseJobURL("http://jenkins/job/ns/job/pipeline/job/main/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ns", "pipeline", "main"}, []string{projectName, pipelineName, branchName})
+
+	_, _, _, err = parseJobURL("http://jenkins/view/all/")
+	assert.NotNil(t, err)
+}
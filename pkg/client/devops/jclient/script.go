@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RunScript runs a Groovy script by the script console of Jenkins
+func (j *JenkinsClient) RunScript(script string) (output string, err error) {
+	var statusCode int
+	var data []byte
+	if statusCode, data, err = j.Core.Request(http.MethodPost, "/scriptText",
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		strings.NewReader(url.Values{"script": {script}}.Encode())); err != nil {
+		return
+	}
+	if statusCode != http.StatusOK {
+		err = j.Core.ErrorHandle(statusCode, data)
+		return
+	}
+	output = string(data)
+	return
+}
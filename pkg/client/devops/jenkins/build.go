@@ -22,8 +22,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/emicklei/go-restful"
-
 	"kubesphere.io/devops/pkg/client/devops"
 )
 
@@ -329,7 +327,7 @@ func (b *Build) Poll(options ...interface{}) (int, error) {
 func (j *Jenkins) GetProjectPipelineBuildByType(projectId, pipelineId string, status string) (build *devops.Build, err error) {
 	var job *Job
 	if job, err = j.GetJob(pipelineId, projectId); err != nil {
-		err = restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		err = devops.WrapError(err)
 	} else {
 		build, err = getBuildByType(job, status)
 	}
@@ -339,7 +337,7 @@ func (j *Jenkins) GetProjectPipelineBuildByType(projectId, pipelineId string, st
 func (j *Jenkins) GetMultiBranchPipelineBuildByType(projectId, pipelineId, branch string, status string) (build *devops.Build, err error) {
 	var job *Job
 	if job, err = j.GetJob(branch, projectId, pipelineId); err != nil {
-		err = restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		err = devops.WrapError(err)
 	} else {
 		build, err = getBuildByType(job, status)
 	}
@@ -25,12 +25,15 @@ import (
 )
 
 func (j *Jenkins) GetCredentialInProject(projectId, id string) (*devops.Credential, error) {
-	responseStruct := &devops.Credential{}
+	return j.GetCredentialInProjectDomain(projectId, "_", id)
+}
 
-	domain := "_"
+// GetCredentialInProjectDomain returns a credential in a domain of the project folder
+func (j *Jenkins) GetCredentialInProjectDomain(projectId, domain, id string) (*devops.Credential, error) {
+	responseStruct := &devops.Credential{}
 
 	response, err := j.Requester.GetJSON(
-		fmt.Sprintf("/job/%s/credentials/store/folder/domain/_/credential/%s", projectId, id),
+		fmt.Sprintf("/job/%s/credentials/store/folder/domain/%s/credential/%s", projectId, domain, id),
 		responseStruct, map[string]string{
 			"depth": "2",
 		})
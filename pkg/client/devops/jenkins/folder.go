@@ -20,6 +20,9 @@ import (
 	"strings"
 )
 
+// folderClass is the Jenkins class of folders
+const folderClass = "com.cloudbees.hudson.plugins.folder.Folder"
+
 type Folder struct {
 	Raw     *FolderResponse
 	Jenkins *Jenkins
@@ -44,7 +47,7 @@ func (f *Folder) GetName() string {
 }
 
 func (f *Folder) Create(name, description string) (*Folder, error) {
-	mode := "com.cloudbees.hudson.plugins.folder.Folder"
+	mode := folderClass
 	data := map[string]string{
 		"name":   name,
 		"mode":   mode,
@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Valid values of the Jenkins folder naming strategy
+const (
+	// FolderNamingNamespace names the folder after the namespace of the DevOps project, it's the default one
+	FolderNamingNamespace = "namespace"
+	// FolderNamingPrefix adds a prefix to the namespace
+	FolderNamingPrefix = "prefix"
+	// FolderNamingHashSuffix adds the hash of the cluster name to the namespace
+	FolderNamingHashSuffix = "hash-suffix"
+	// FolderNamingProjectUID names the folder after the UID of the DevOpsProject
+	FolderNamingProjectUID = "project-uid"
+	// FolderNamingTemplate renders the folder name with a custom Go template
+	FolderNamingTemplate = "template"
+)
+
+var supportedFolderNamings = []string{FolderNamingNamespace, FolderNamingPrefix, FolderNamingHashSuffix,
+	FolderNamingProjectUID, FolderNamingTemplate}
+
+var folderNamingTemplates = map[string]string{
+	FolderNamingNamespace:  "{{.Namespace}}",
+	FolderNamingPrefix:     "{{.Prefix}}{{.Namespace}}",
+	FolderNamingHashSuffix: "{{.Namespace}}-{{hash .Cluster}}",
+	FolderNamingProjectUID: "{{.ProjectUID}}",
+}
+
+// a Jenkins item name could not contain the characters like / or :, and should not start with a dot
+var folderNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,254}$`)
+
+// folderOwnerPrefix marks the owner in the description of the Jenkins folder
+const folderOwnerPrefix = "ks-devops-owner: "
+
+// FolderNameData is the data to render the name of a Jenkins folder
+type FolderNameData struct {
+	// Namespace is the admin namespace of the DevOps project
+	Namespace  string
+	Project    string
+	ProjectUID string
+	Prefix     string
+	Cluster    string
+}
+
+// FolderNamer renders the names of the Jenkins folders of the DevOps projects. The namespaces of the DevOps projects
+// might be the same across the clusters which share one Jenkins, the strategies other than namespace avoid it.
+type FolderNamer struct {
+	tpl     *template.Template
+	prefix  string
+	cluster string
+}
+
+// NewFolderNamer creates a namer by the options, it returns an error if the strategy is not supported or incomplete
+func NewFolderNamer(options *Options) (namer *FolderNamer, err error) {
+	strategy := options.FolderNaming
+	if strategy == "" {
+		strategy = FolderNamingNamespace
+	}
+
+	text, ok := folderNamingTemplates[strategy]
+	switch {
+	case strategy == FolderNamingTemplate:
+		if text = options.FolderTemplate; text == "" {
+			err = fmt.Errorf("the Jenkins folder template is required by the naming strategy %s", strategy)
+		}
+	case !ok:
+		err = fmt.Errorf("unsupported Jenkins folder naming strategy %q, supported: %v", strategy,
+			supportedFolderNamings)
+	case strategy == FolderNamingPrefix && options.FolderPrefix == "":
+		err = fmt.Errorf("the Jenkins folder prefix is required by the naming strategy %s", strategy)
+	case strategy == FolderNamingHashSuffix && options.ClusterName == "":
+		err = fmt.Errorf("the cluster name is required by the naming strategy %s", strategy)
+	}
+	if err != nil {
+		return
+	}
+
+	var tpl *template.Template
+	if tpl, err = template.New("folder").Option("missingkey=error").Funcs(template.FuncMap{
+		"hash":  hashName,
+		"lower": strings.ToLower,
+	}).Parse(text); err != nil {
+		err = fmt.Errorf("invalid Jenkins folder template: %v", err)
+		return
+	}
+	namer = &FolderNamer{tpl: tpl, prefix: options.FolderPrefix, cluster: options.ClusterName}
+	return
+}
+
+// Name renders the folder name of a DevOps project, the prefix and cluster come from the options
+func (n *FolderNamer) Name(data FolderNameData) (name string, err error) {
+	if n == nil {
+		return data.Namespace, nil
+	}
+	data.Prefix, data.Cluster = n.prefix, n.cluster
+
+	buf := &bytes.Buffer{}
+	if err = n.tpl.Execute(buf, data); err != nil {
+		return
+	}
+	if name = buf.String(); !folderNamePattern.MatchString(name) {
+		err = fmt.Errorf("the Jenkins folder name %q of the DevOps project %s is invalid", name, data.Project)
+	}
+	return
+}
+
+// Owner returns the owner of a DevOps project which is recorded in its Jenkins folder. It's empty if the cluster name
+// is not set, then the existing folder is always taken over as before.
+func (n *FolderNamer) Owner(data FolderNameData) string {
+	if n == nil || n.cluster == "" {
+		return ""
+	}
+	return n.cluster + "/" + data.ProjectUID
+}
+
+func hashName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func folderDescription(owner string) string {
+	if owner == "" {
+		return ""
+	}
+	return folderOwnerPrefix + owner
+}
+
+// getFolderOwner returns the owner from the description of a folder, it's empty if the folder has no owner
+func getFolderOwner(description string) string {
+	for _, line := range strings.Split(description, "\n") {
+		if strings.HasPrefix(line, folderOwnerPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, folderOwnerPrefix))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolderNamer(t *testing.T) {
+	data := FolderNameData{Namespace: "demo", Project: "demo", ProjectUID: "0b7d5a4e-3c1f-4c7a-9d2e-6f1b8a9c0d1e"}
+	tests := []struct {
+		name      string
+		options   Options
+		want      string
+		wantOwner string
+		wantErr   bool
+	}{{
+		name: "default",
+		want: "demo",
+	}, {
+		name:      "namespace with the cluster name",
+		options:   Options{FolderNaming: FolderNamingNamespace, ClusterName: "host"},
+		want:      "demo",
+		wantOwner: "host/0b7d5a4e-3c1f-4c7a-9d2e-6f1b8a9c0d1e",
+	}, {
+		name:    "prefix",
+		options: Options{FolderNaming: FolderNamingPrefix, FolderPrefix: "host-"},
+		want:    "host-demo",
+	}, {
+		name:      "hash suffix",
+		options:   Options{FolderNaming: FolderNamingHashSuffix, ClusterName: "host"},
+		want:      "demo-" + hashName("host"),
+		wantOwner: "host/0b7d5a4e-3c1f-4c7a-9d2e-6f1b8a9c0d1e",
+	}, {
+		name:    "project UID",
+		options: Options{FolderNaming: FolderNamingProjectUID},
+		want:    "0b7d5a4e-3c1f-4c7a-9d2e-6f1b8a9c0d1e",
+	}, {
+		name:      "template",
+		options:   Options{FolderNaming: FolderNamingTemplate, FolderTemplate: "{{lower .Cluster}}_{{.Project}}", ClusterName: "Host"},
+		want:      "host_demo",
+		wantOwner: "Host/0b7d5a4e-3c1f-4c7a-9d2e-6f1b8a9c0d1e",
+	}, {
+		name:    "unsupported strategy",
+		options: Options{FolderNaming: "random"},
+		wantErr: true,
+	}, {
+		name:    "prefix without the prefix",
+		options: Options{FolderNaming: FolderNamingPrefix},
+		wantErr: true,
+	}, {
+		name:    "hash suffix without the cluster name",
+		options: Options{FolderNaming: FolderNamingHashSuffix},
+		wantErr: true,
+	}, {
+		name:    "template without the template",
+		options: Options{FolderNaming: FolderNamingTemplate},
+		wantErr: true,
+	}, {
+		name:    "invalid template",
+		options: Options{FolderNaming: FolderNamingTemplate, FolderTemplate: "{{.Namespace"},
+		wantErr: true,
+	}, {
+		name:    "unknown field",
+		options: Options{FolderNaming: FolderNamingTemplate, FolderTemplate: "{{.Unknown}}"},
+		wantErr: true,
+	}, {
+		name:    "invalid name",
+		options: Options{FolderNaming: FolderNamingTemplate, FolderTemplate: "{{.Cluster}}/{{.Namespace}}"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namer, err := NewFolderNamer(&tt.options)
+			var name string
+			if err == nil {
+				name, err = namer.Name(data)
+			}
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, name)
+				assert.Equal(t, tt.wantOwner, namer.Owner(data))
+			}
+		})
+	}
+
+	// the nil namer names the folders after the namespaces
+	var namer *FolderNamer
+	name, err := namer.Name(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", name)
+	assert.Empty(t, namer.Owner(data))
+}
+
+func Test_getFolderOwner(t *testing.T) {
+	assert.Empty(t, getFolderOwner(""))
+	assert.Empty(t, getFolderOwner("a folder"))
+	assert.Equal(t, "host/uid", getFolderOwner(folderDescription("host/uid")))
+	assert.Equal(t, "host/uid", getFolderOwner("a folder\nks-devops-owner: host/uid\n"))
+	assert.Empty(t, folderDescription(""))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the prefixes of the Jenkins APIs which are followed by the folder of a DevOps project
+var folderPathPrefixes = []string{"/job/", "/blue/rest/organizations/jenkins/pipelines/"}
+
+// FolderResolver resolves the Jenkins folder of a DevOps project from the annotation of its DevOpsProject. The folder
+// is the namespace if the DevOpsProject doesn't have the annotation, so the folders created before keep working.
+type FolderResolver struct {
+	reader client.Reader
+}
+
+// NewFolderResolver creates a resolver, the reader is supposed to be backed by a cache
+func NewFolderResolver(reader client.Reader) *FolderResolver {
+	return &FolderResolver{reader: reader}
+}
+
+// FolderOf returns the Jenkins folder of a DevOps project by its admin namespace
+func (r *FolderResolver) FolderOf(ctx context.Context, namespace string) (folder string, err error) {
+	folder = namespace
+	// the admin namespace is named after the DevOpsProject
+	project := &v1alpha3.DevOpsProject{}
+	if err = r.reader.Get(ctx, client.ObjectKey{Name: namespace}, project); err != nil {
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+		return
+	}
+	if annotated := project.Annotations[v1alpha3.DevOpsProjectJenkinsFolderAnnoKey]; annotated != "" &&
+		project.Status.AdminNamespace == namespace {
+		folder = annotated
+	}
+	return
+}
+
+// NamespaceOf returns the admin namespace of a DevOps project by its Jenkins folder
+func (r *FolderResolver) NamespaceOf(ctx context.Context, folder string) (namespace string, err error) {
+	namespace = folder
+	projectList := &v1alpha3.DevOpsProjectList{}
+	if err = r.reader.List(ctx, projectList); err != nil {
+		return
+	}
+	for i := range projectList.Items {
+		project := &projectList.Items[i]
+		if project.Annotations[v1alpha3.DevOpsProjectJenkinsFolderAnnoKey] == folder && project.Status.AdminNamespace != "" {
+			namespace = project.Status.AdminNamespace
+			break
+		}
+	}
+	return
+}
+
+// WrapTransport returns a round tripper which replaces the namespaces in the Jenkins API paths with the folders, so
+// the callers keep using the namespaces. It uses http.DefaultTransport if next is nil.
+func (r *FolderResolver) WrapTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &folderRoundTripper{resolver: r, next: next}
+}
+
+type folderRoundTripper struct {
+	resolver *FolderResolver
+	next     http.RoundTripper
+}
+
+// RoundTrip replaces the first path segment after the folder prefixes
+func (t *folderRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, prefix := range folderPathPrefixes {
+		if !strings.HasPrefix(req.URL.Path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(req.URL.Path, prefix)
+		namespace, suffix := rest, ""
+		if index := strings.Index(rest, "/"); index >= 0 {
+			namespace, suffix = rest[:index], rest[index:]
+		}
+		if namespace == "" {
+			break
+		}
+
+		folder, err := t.resolver.FolderOf(req.Context(), namespace)
+		if err != nil {
+			return nil, err
+		}
+		if folder != namespace {
+			// the request should not be modified by a round tripper
+			req = req.Clone(req.Context())
+			req.URL.Path = prefix + folder + suffix
+			req.URL.RawPath = ""
+		}
+		break
+	}
+	return t.next.RoundTrip(req)
+}
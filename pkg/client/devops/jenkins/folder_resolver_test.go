@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFolderResolver(t *testing.T) *FolderResolver {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	return NewFolderResolver(fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+	}, &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+		Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: "legacy"},
+	}).Build())
+}
+
+func TestFolderResolver(t *testing.T) {
+	resolver := newFolderResolver(t)
+	ctx := context.Background()
+
+	folder, err := resolver.FolderOf(ctx, "demo")
+	assert.Nil(t, err)
+	assert.Equal(t, "host-demo", folder)
+	// the folders of the projects without the annotation are the namespaces
+	folder, err = resolver.FolderOf(ctx, "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, "legacy", folder)
+	folder, err = resolver.FolderOf(ctx, "unknown")
+	assert.Nil(t, err)
+	assert.Equal(t, "unknown", folder)
+
+	namespace, err := resolver.NamespaceOf(ctx, "host-demo")
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", namespace)
+	namespace, err = resolver.NamespaceOf(ctx, "legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, "legacy", namespace)
+}
+
+func TestFolderResolver_WrapTransport(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: newFolderResolver(t).WrapTransport(nil)}
+	for _, path := range []string{
+		"/job/demo/job/pipeline/api/json",
+		"/job/demo",
+		"/blue/rest/organizations/jenkins/pipelines/demo/pipelines/pipeline/runs/",
+		"/job/legacy/job/pipeline/api/json",
+		"/crumbIssuer/api/json",
+		"/job/",
+	} {
+		response, err := httpClient.Get(server.URL + path)
+		assert.Nil(t, err)
+		_ = response.Body.Close()
+	}
+	assert.Equal(t, []string{
+		"/job/host-demo/job/pipeline/api/json",
+		"/job/host-demo",
+		"/blue/rest/organizations/jenkins/pipelines/host-demo/pipelines/pipeline/runs/",
+		"/job/legacy/job/pipeline/api/json",
+		"/crumbIssuer/api/json",
+		"/job/",
+	}, paths)
+}
@@ -64,25 +64,7 @@ func AppendBitbucketServerSourceToEtree(source *etree.Element, gitSource *devops
 	if gitSource.DiscoverTags {
 		traits.CreateElement("com.cloudbees.jenkins.plugins.bitbucket.TagDiscoveryTrait")
 	}
-	if gitSource.CloneOption != nil {
-		cloneExtension := traits.CreateElement("jenkins.plugins.git.traits.CloneOptionTrait").CreateElement("extension")
-		cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
-		cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(gitSource.CloneOption.Shallow))
-		cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
-		cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
-		cloneExtension.CreateElement("reference")
-		if gitSource.CloneOption.Timeout >= 0 {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(gitSource.CloneOption.Timeout))
-		} else {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(10))
-		}
-
-		if gitSource.CloneOption.Depth >= 0 {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(gitSource.CloneOption.Depth))
-		} else {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(1))
-		}
-	}
+	appendCloneOptionToEtree(traits, gitSource.CloneOption, "com.cloudbees.jenkins.plugins.bitbucket.SSHCheckoutTrait")
 	if gitSource.RegexFilter != "" {
 		regexTraits := traits.CreateElement("jenkins.scm.impl.trait.RegexSCMHeadFilterTrait")
 		regexTraits.CreateAttr("plugin", "scm-api")
@@ -141,22 +123,7 @@ func GetBitbucketServerSourceFromEtree(source *etree.Element) *devopsv1alpha3.Bi
 			}
 		}
 
-		if cloneTrait := traits.SelectElement(
-			"jenkins.plugins.git.traits.CloneOptionTrait"); cloneTrait != nil {
-			if cloneExtension := cloneTrait.SelectElement(
-				"extension"); cloneExtension != nil {
-				s.CloneOption = &devopsv1alpha3.GitCloneOption{}
-				if value, err := strconv.ParseBool(cloneExtension.SelectElement("shallow").Text()); err == nil {
-					s.CloneOption.Shallow = value
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("timeout").Text(), 10, 32); err == nil {
-					s.CloneOption.Timeout = int(value)
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("depth").Text(), 10, 32); err == nil {
-					s.CloneOption.Depth = int(value)
-				}
-			}
-		}
+		s.CloneOption = getCloneOptionFromEtree(traits, "com.cloudbees.jenkins.plugins.bitbucket.SSHCheckoutTrait")
 
 		if regexTrait := traits.SelectElement(
 			"jenkins.scm.impl.trait.RegexSCMHeadFilterTrait"); regexTrait != nil {
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"strconv"
+
+	"github.com/beevik/etree"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const (
+	cloneOptionTrait     = "jenkins.plugins.git.traits.CloneOptionTrait"
+	submoduleOptionTrait = "jenkins.plugins.git.traits.SubmoduleOptionTrait"
+	gitLFSPullTrait      = "jenkins.plugins.git.traits.GitLFSPullTrait"
+)
+
+// appendCloneOptionToEtree appends the checkout traits of the clone option. The sshCheckoutTrait is the
+// SCM specific trait used to check out with a separate SSH credential, it can be empty if not supported.
+func appendCloneOptionToEtree(traits *etree.Element, cloneOption *devopsv1alpha3.GitCloneOption, sshCheckoutTrait string) {
+	if cloneOption == nil {
+		return
+	}
+	timeout, depth := cloneOption.Timeout, cloneOption.Depth
+	if timeout < 0 {
+		timeout = 10
+	}
+	if depth < 0 {
+		depth = 1
+	}
+
+	cloneExtension := traits.CreateElement(cloneOptionTrait).CreateElement("extension")
+	cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
+	cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(cloneOption.Shallow))
+	cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
+	cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
+	cloneExtension.CreateElement("reference")
+	cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(timeout))
+	cloneExtension.CreateElement("depth").SetText(strconv.Itoa(depth))
+
+	if cloneOption.Submodules {
+		submoduleExtension := traits.CreateElement(submoduleOptionTrait).CreateElement("extension")
+		submoduleExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.SubmoduleOption")
+		submoduleExtension.CreateElement("disableSubmodules").SetText(strconv.FormatBool(false))
+		submoduleExtension.CreateElement("recursiveSubmodules").SetText(strconv.FormatBool(true))
+		submoduleExtension.CreateElement("trackingSubmodules").SetText(strconv.FormatBool(false))
+		submoduleExtension.CreateElement("reference")
+		submoduleExtension.CreateElement("parentCredentials").SetText(strconv.FormatBool(true))
+		submoduleExtension.CreateElement("timeout").SetText(strconv.Itoa(timeout))
+		submoduleExtension.CreateElement("shallow").SetText(strconv.FormatBool(cloneOption.Shallow))
+		submoduleExtension.CreateElement("depth").SetText(strconv.Itoa(depth))
+	}
+	if cloneOption.LFS {
+		traits.CreateElement(gitLFSPullTrait).CreateElement("extension").
+			CreateAttr("class", "hudson.plugins.git.extensions.impl.GitLFSPull")
+	}
+	if cloneOption.SSHCredentialId != "" && sshCheckoutTrait != "" {
+		traits.CreateElement(sshCheckoutTrait).CreateElement("credentialsId").SetText(cloneOption.SSHCredentialId)
+	}
+}
+
+// getCloneOptionFromEtree parses the clone option from the checkout traits, returns nil if there is none
+func getCloneOptionFromEtree(traits *etree.Element, sshCheckoutTrait string) (cloneOption *devopsv1alpha3.GitCloneOption) {
+	if traits == nil {
+		return
+	}
+	if cloneTrait := traits.SelectElement(cloneOptionTrait); cloneTrait != nil {
+		if cloneExtension := cloneTrait.SelectElement("extension"); cloneExtension != nil {
+			cloneOption = &devopsv1alpha3.GitCloneOption{}
+			if value, err := strconv.ParseBool(elementText(cloneExtension, "shallow")); err == nil {
+				cloneOption.Shallow = value
+			}
+			if value, err := strconv.ParseInt(elementText(cloneExtension, "timeout"), 10, 32); err == nil {
+				cloneOption.Timeout = int(value)
+			}
+			if value, err := strconv.ParseInt(elementText(cloneExtension, "depth"), 10, 32); err == nil {
+				cloneOption.Depth = int(value)
+			}
+		}
+	}
+
+	if submoduleTrait := traits.SelectElement(submoduleOptionTrait); submoduleTrait != nil {
+		if submoduleExtension := submoduleTrait.SelectElement("extension"); submoduleExtension != nil &&
+			elementText(submoduleExtension, "disableSubmodules") != "true" {
+			cloneOption = ensureCloneOption(cloneOption)
+			cloneOption.Submodules = true
+		}
+	}
+	if lfsTrait := traits.SelectElement(gitLFSPullTrait); lfsTrait != nil {
+		cloneOption = ensureCloneOption(cloneOption)
+		cloneOption.LFS = true
+	}
+	if sshCheckoutTrait != "" {
+		if sshTrait := traits.SelectElement(sshCheckoutTrait); sshTrait != nil {
+			if credentialID := elementText(sshTrait, "credentialsId"); credentialID != "" {
+				cloneOption = ensureCloneOption(cloneOption)
+				cloneOption.SSHCredentialId = credentialID
+			}
+		}
+	}
+	return
+}
+
+func ensureCloneOption(cloneOption *devopsv1alpha3.GitCloneOption) *devopsv1alpha3.GitCloneOption {
+	if cloneOption == nil {
+		cloneOption = &devopsv1alpha3.GitCloneOption{}
+	}
+	return cloneOption
+}
+
+func elementText(parent *etree.Element, tag string) string {
+	if element := parent.SelectElement(tag); element != nil {
+		return element.Text()
+	}
+	return ""
+}
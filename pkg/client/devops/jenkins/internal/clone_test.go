@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestCloneOption(t *testing.T) {
+	cloneOption := &devopsv1alpha3.GitCloneOption{
+		Shallow:         true,
+		Timeout:         20,
+		Depth:           3,
+		Submodules:      true,
+		LFS:             true,
+		SSHCredentialId: "deploy-key",
+	}
+	PRForks := &devopsv1alpha3.DiscoverPRFromForks{
+		Strategy: 1,
+		Trust:    1,
+	}
+
+	// github
+	source := etree.NewDocument().CreateElement("source")
+	AppendGithubSourceToEtree(source, &devopsv1alpha3.GithubSource{DiscoverPRFromForks: PRForks, CloneOption: cloneOption})
+	assert.Equal(t, cloneOption, GetGithubSourcefromEtree(source).CloneOption)
+	assert.Equal(t, "deploy-key", source.FindElement(
+		"traits/org.jenkinsci.plugins.github__branch__source.SSHCheckoutTrait/credentialsId").Text())
+
+	// gitlab
+	source = etree.NewDocument().CreateElement("source")
+	AppendGitlabSourceToEtree(source, &devopsv1alpha3.GitlabSource{DiscoverPRFromForks: PRForks, CloneOption: cloneOption})
+	assert.Equal(t, cloneOption, GetGitlabSourceFromEtree(source).CloneOption)
+
+	// bitbucketServer
+	source = etree.NewDocument().CreateElement("source")
+	AppendBitbucketServerSourceToEtree(source, &devopsv1alpha3.BitbucketServerSource{DiscoverPRFromForks: PRForks, CloneOption: cloneOption})
+	assert.Equal(t, cloneOption, GetBitbucketServerSourceFromEtree(source).CloneOption)
+
+	// git has no SSH checkout trait, the SSH credential should be given as credential_id
+	source = etree.NewDocument().CreateElement("source")
+	AppendGitSourceToEtree(source, &devopsv1alpha3.GitSource{CloneOption: cloneOption})
+	gitCloneOption := GetGitSourcefromEtree(source).CloneOption
+	assert.True(t, gitCloneOption.Submodules)
+	assert.True(t, gitCloneOption.LFS)
+	assert.Empty(t, gitCloneOption.SSHCredentialId)
+	assert.Equal(t, "3", source.FindElement(
+		"traits/jenkins.plugins.git.traits.SubmoduleOptionTrait/extension/depth").Text())
+}
+
+func TestCloneOptionDefaults(t *testing.T) {
+	source := etree.NewDocument().CreateElement("source")
+	AppendGitSourceToEtree(source, &devopsv1alpha3.GitSource{CloneOption: &devopsv1alpha3.GitCloneOption{Timeout: -1, Depth: -1}})
+	assert.Equal(t, &devopsv1alpha3.GitCloneOption{Timeout: 10, Depth: 1}, GetGitSourcefromEtree(source).CloneOption)
+	assert.Nil(t, source.FindElement("traits/jenkins.plugins.git.traits.SubmoduleOptionTrait"))
+	assert.Nil(t, source.FindElement("traits/jenkins.plugins.git.traits.GitLFSPullTrait"))
+
+	source = etree.NewDocument().CreateElement("source")
+	AppendGitSourceToEtree(source, &devopsv1alpha3.GitSource{})
+	assert.Nil(t, GetGitSourcefromEtree(source).CloneOption)
+}
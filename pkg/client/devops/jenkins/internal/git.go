@@ -17,8 +17,6 @@ limitations under the License.
 package internal
 
 import (
-	"strconv"
-
 	"github.com/beevik/etree"
 	"k8s.io/klog/v2"
 
@@ -44,25 +42,7 @@ func AppendGitSourceToEtree(source *etree.Element, gitSource *devopsv1alpha3.Git
 	if gitSource.DiscoverTags {
 		traits.CreateElement("jenkins.plugins.git.traits.TagDiscoveryTrait")
 	}
-	if gitSource.CloneOption != nil {
-		cloneExtension := traits.CreateElement("jenkins.plugins.git.traits.CloneOptionTrait").CreateElement("extension")
-		cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
-		cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(gitSource.CloneOption.Shallow))
-		cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
-		cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
-		cloneExtension.CreateElement("reference")
-		if gitSource.CloneOption.Timeout >= 0 {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(gitSource.CloneOption.Timeout))
-		} else {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(10))
-		}
-
-		if gitSource.CloneOption.Depth >= 0 {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(gitSource.CloneOption.Depth))
-		} else {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(1))
-		}
-	}
+	appendCloneOptionToEtree(traits, gitSource.CloneOption, "")
 
 	if gitSource.RegexFilter != "" {
 		regexTraits := traits.CreateElement("jenkins.scm.impl.trait.RegexSCMHeadFilterTrait")
@@ -90,22 +70,7 @@ func GetGitSourcefromEtree(source *etree.Element) *devopsv1alpha3.GitSource {
 		"jenkins.plugins.git.traits.TagDiscoveryTrait"); tagDiscoverTrait != nil {
 		gitSource.DiscoverTags = true
 	}
-	if cloneTrait := traits.SelectElement(
-		"jenkins.plugins.git.traits.CloneOptionTrait"); cloneTrait != nil {
-		if cloneExtension := cloneTrait.SelectElement(
-			"extension"); cloneExtension != nil {
-			gitSource.CloneOption = &devopsv1alpha3.GitCloneOption{}
-			if value, err := strconv.ParseBool(cloneExtension.SelectElement("shallow").Text()); err == nil {
-				gitSource.CloneOption.Shallow = value
-			}
-			if value, err := strconv.ParseInt(cloneExtension.SelectElement("timeout").Text(), 10, 32); err == nil {
-				gitSource.CloneOption.Timeout = int(value)
-			}
-			if value, err := strconv.ParseInt(cloneExtension.SelectElement("depth").Text(), 10, 32); err == nil {
-				gitSource.CloneOption.Depth = int(value)
-			}
-		}
-	}
+	gitSource.CloneOption = getCloneOptionFromEtree(traits, "")
 	if regexTrait := traits.SelectElement(
 		"jenkins.scm.impl.trait.RegexSCMHeadFilterTrait"); regexTrait != nil {
 		if regex := regexTrait.SelectElement("regex"); regex != nil {
@@ -63,25 +63,7 @@ func AppendGithubSourceToEtree(source *etree.Element, githubSource *devopsv1alph
 	if githubSource.DiscoverTags {
 		traits.CreateElement("org.jenkinsci.plugins.github__branch__source.TagDiscoveryTrait")
 	}
-	if githubSource.CloneOption != nil {
-		cloneExtension := traits.CreateElement("jenkins.plugins.git.traits.CloneOptionTrait").CreateElement("extension")
-		cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
-		cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(githubSource.CloneOption.Shallow))
-		cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
-		cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
-		cloneExtension.CreateElement("reference")
-		if githubSource.CloneOption.Timeout >= 0 {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(githubSource.CloneOption.Timeout))
-		} else {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(10))
-		}
-
-		if githubSource.CloneOption.Depth >= 0 {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(githubSource.CloneOption.Depth))
-		} else {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(1))
-		}
-	}
+	appendCloneOptionToEtree(traits, githubSource.CloneOption, "org.jenkinsci.plugins.github__branch__source.SSHCheckoutTrait")
 	if githubSource.RegexFilter != "" {
 		regexTraits := traits.CreateElement("jenkins.scm.impl.trait.RegexSCMHeadFilterTrait")
 		regexTraits.CreateAttr("plugin", "scm-api")
@@ -138,22 +120,7 @@ func GetGithubSourcefromEtree(source *etree.Element) *devopsv1alpha3.GithubSourc
 				klog.Warningf("invalid Gitlab discover PR trust value: %s", trust[1])
 			}
 		}
-		if cloneTrait := traits.SelectElement(
-			"jenkins.plugins.git.traits.CloneOptionTrait"); cloneTrait != nil {
-			if cloneExtension := cloneTrait.SelectElement(
-				"extension"); cloneExtension != nil {
-				githubSource.CloneOption = &devopsv1alpha3.GitCloneOption{}
-				if value, err := strconv.ParseBool(cloneExtension.SelectElement("shallow").Text()); err == nil {
-					githubSource.CloneOption.Shallow = value
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("timeout").Text(), 10, 32); err == nil {
-					githubSource.CloneOption.Timeout = int(value)
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("depth").Text(), 10, 32); err == nil {
-					githubSource.CloneOption.Depth = int(value)
-				}
-			}
-		}
+		githubSource.CloneOption = getCloneOptionFromEtree(traits, "org.jenkinsci.plugins.github__branch__source.SSHCheckoutTrait")
 
 		if regexTrait := traits.SelectElement(
 			"jenkins.scm.impl.trait.RegexSCMHeadFilterTrait"); regexTrait != nil {
@@ -62,25 +62,7 @@ func AppendGitlabSourceToEtree(source *etree.Element, gitSource *devopsv1alpha3.
 		}
 		forkTrait.CreateElement("trust").CreateAttr("class", trustClass)
 	}
-	if gitSource.CloneOption != nil {
-		cloneExtension := traits.CreateElement("jenkins.plugins.git.traits.CloneOptionTrait").CreateElement("extension")
-		cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
-		cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(gitSource.CloneOption.Shallow))
-		cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
-		cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
-		cloneExtension.CreateElement("reference")
-		if gitSource.CloneOption.Timeout >= 0 {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(gitSource.CloneOption.Timeout))
-		} else {
-			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(10))
-		}
-
-		if gitSource.CloneOption.Depth >= 0 {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(gitSource.CloneOption.Depth))
-		} else {
-			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(1))
-		}
-	}
+	appendCloneOptionToEtree(traits, gitSource.CloneOption, "io.jenkins.plugins.gitlabbranchsource.SSHCheckoutTrait")
 	if gitSource.RegexFilter != "" {
 		regexTraits := traits.CreateElement("jenkins.scm.impl.trait.RegexSCMHeadFilterTrait")
 		regexTraits.CreateAttr("plugin", "scm-api")
@@ -137,22 +119,7 @@ func GetGitlabSourceFromEtree(source *etree.Element) (gitSource *devopsv1alpha3.
 				klog.Warningf("invalid Gitlab discover PR trust value: %s", trust[1])
 			}
 		}
-		if cloneTrait := traits.SelectElement(
-			"jenkins.plugins.git.traits.CloneOptionTrait"); cloneTrait != nil {
-			if cloneExtension := cloneTrait.SelectElement(
-				"extension"); cloneExtension != nil {
-				gitSource.CloneOption = &devopsv1alpha3.GitCloneOption{}
-				if value, err := strconv.ParseBool(cloneExtension.SelectElement("shallow").Text()); err == nil {
-					gitSource.CloneOption.Shallow = value
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("timeout").Text(), 10, 32); err == nil {
-					gitSource.CloneOption.Timeout = int(value)
-				}
-				if value, err := strconv.ParseInt(cloneExtension.SelectElement("depth").Text(), 10, 32); err == nil {
-					gitSource.CloneOption.Depth = int(value)
-				}
-			}
-		}
+		gitSource.CloneOption = getCloneOptionFromEtree(traits, "io.jenkins.plugins.gitlabbranchsource.SSHCheckoutTrait")
 
 		if regexTrait := traits.SelectElement(
 			"jenkins.scm.impl.trait.RegexSCMHeadFilterTrait"); regexTrait != nil {
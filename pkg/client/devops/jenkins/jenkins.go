@@ -318,6 +318,16 @@ func (j *Jenkins) GetRunLog(projectName, pipelineName, runId string, httpParamet
 	return res, err
 }
 
+// GetProgressiveRunLog returns the run log from the start offset with the headers of the progressive text
+func (j *Jenkins) GetProgressiveRunLog(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	PipelineOjb := &Pipeline{
+		HttpParameters: httpParameters,
+		Jenkins:        j,
+		Path:           fmt.Sprintf(GetRunLogUrl+httpParameters.Url.RawQuery, projectName, pipelineName, runId),
+	}
+	return PipelineOjb.GetProgressiveRunLog()
+}
+
 func (j *Jenkins) GetStepLog(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
 	PipelineOjb := &Pipeline{
 		HttpParameters: httpParameters,
@@ -328,6 +338,16 @@ func (j *Jenkins) GetStepLog(projectName, pipelineName, runId, nodeId, stepId st
 	return res, header, err
 }
 
+// GetNodeLog returns the log output of a node
+func (j *Jenkins) GetNodeLog(projectName, pipelineName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	PipelineOjb := &Pipeline{
+		HttpParameters: httpParameters,
+		Jenkins:        j,
+		Path:           fmt.Sprintf(GetNodeLogUrl+httpParameters.Url.RawQuery, projectName, pipelineName, runId, nodeId),
+	}
+	return PipelineOjb.GetNodeLog()
+}
+
 func (j *Jenkins) GetNodeSteps(projectName, pipelineName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	PipelineOjb := &Pipeline{
 		HttpParameters: httpParameters,
@@ -428,6 +448,16 @@ func (j *Jenkins) GetBranchRunLog(projectName, pipelineName, branchName, runId s
 	return res, err
 }
 
+// GetBranchProgressiveRunLog returns the run log of a branch from the start offset with the headers of the progressive text
+func (j *Jenkins) GetBranchProgressiveRunLog(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	PipelineOjb := &Pipeline{
+		HttpParameters: httpParameters,
+		Jenkins:        j,
+		Path:           fmt.Sprintf(GetBranchRunLogUrl+httpParameters.Url.RawQuery, projectName, pipelineName, branchName, runId),
+	}
+	return PipelineOjb.GetProgressiveRunLog()
+}
+
 func (j *Jenkins) GetBranchStepLog(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
 	PipelineOjb := &Pipeline{
 		HttpParameters: httpParameters,
@@ -438,6 +468,16 @@ func (j *Jenkins) GetBranchStepLog(projectName, pipelineName, branchName, runId,
 	return res, header, err
 }
 
+// GetBranchNodeLog returns the log output of a node of a multi-branch Pipeline
+func (j *Jenkins) GetBranchNodeLog(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]byte, http.Header, error) {
+	PipelineOjb := &Pipeline{
+		HttpParameters: httpParameters,
+		Jenkins:        j,
+		Path:           fmt.Sprintf(GetBranchNodeLogUrl+httpParameters.Url.RawQuery, projectName, pipelineName, branchName, runId, nodeId),
+	}
+	return PipelineOjb.GetNodeLog()
+}
+
 func (j *Jenkins) GetBranchNodeSteps(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *devops.HttpParameters) ([]devops.NodeSteps, error) {
 	PipelineOjb := &Pipeline{
 		HttpParameters: httpParameters,
@@ -35,6 +35,17 @@ type Options struct {
 	WorkerNamespace string        `json:"workerNamespace,omitempty" yaml:"workerNamespace"`
 	ReloadCasCDelay time.Duration `json:"reloadCasCDelay,omitempty" yaml:"reloadCasCDelay"`
 	SkipVerify      bool
+
+	// FolderNaming is the strategy to name the Jenkins folders of the DevOps projects, see also FolderNamer
+	FolderNaming   string `json:"folderNaming,omitempty" yaml:"folderNaming"`
+	FolderPrefix   string `json:"folderPrefix,omitempty" yaml:"folderPrefix"`
+	FolderTemplate string `json:"folderTemplate,omitempty" yaml:"folderTemplate"`
+	// ClusterName tells the clusters apart when they share one Jenkins
+	ClusterName string `json:"clusterName,omitempty" yaml:"clusterName"`
+
+	// ScriptAuditMaxSize is the max number of the audit records of the Jenkins maintenance scripts, all the records
+	// are kept unless it's positive
+	ScriptAuditMaxSize int `json:"scriptAuditMaxSize,omitempty" yaml:"scriptAuditMaxSize"`
 }
 
 // NewJenkinsOptions returns a `zero` instance
@@ -50,6 +61,8 @@ func NewJenkinsOptions() *Options {
 		// ConfigMap, so we use 70s as the default value of ReloadCasCDelay. Please see also:
 		// https://kubernetes.io/docs/reference/config-api/kubelet-config.v1beta1/#kubelet-config-k8s-io-v1beta1-KubeletConfiguration
 		ReloadCasCDelay: 70 * time.Second,
+
+		FolderNaming: FolderNamingNamespace,
 	}
 }
 
@@ -81,6 +94,10 @@ func (s *Options) Validate() []error {
 		errors = append(errors, fmt.Errorf("jenkins's maximum connections should be greater than 0"))
 	}
 
+	if _, err := NewFolderNamer(s); err != nil {
+		errors = append(errors, err)
+	}
+
 	return errors
 }
 
@@ -105,4 +122,18 @@ func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
 	fs.DurationVar(&s.ReloadCasCDelay, "reload-casc-delay", c.ReloadCasCDelay,
 		"ReloadCasCDelay specifies the total duration that controller should delay the reload action for "+
 			"jenkins-casc-config ConfigMap change, and it is only valid for controller manager.")
+
+	fs.StringVar(&s.FolderNaming, "jenkins-folder-naming", c.FolderNaming, fmt.Sprintf(
+		"The strategy to name the Jenkins folders of the new DevOps projects, supported: %v. "+
+			"The existing folders keep their names.", supportedFolderNamings))
+	fs.StringVar(&s.FolderPrefix, "jenkins-folder-prefix", c.FolderPrefix,
+		"The prefix of the Jenkins folders, it's required by the prefix naming strategy.")
+	fs.StringVar(&s.FolderTemplate, "jenkins-folder-template", c.FolderTemplate,
+		"The Go template to name the Jenkins folders, it's required by the template naming strategy.")
+	fs.StringVar(&s.ClusterName, "jenkins-cluster-name", c.ClusterName,
+		"The name of this cluster, it's required by the hash-suffix naming strategy. "+
+			"It's recorded in the Jenkins folders to detect the collisions between the clusters.")
+	fs.IntVar(&s.ScriptAuditMaxSize, "jenkins-script-audit-max-size", c.ScriptAuditMaxSize,
+		"The max number of the audit records of the Jenkins maintenance scripts, the oldest ones are deleted. "+
+			"All the records are kept if it's not positive.")
 }
@@ -600,6 +600,35 @@ func toCrontab(millis int64) string {
 
 }
 
+// setDisabledXml disables or enables the job, Jenkins never triggers a disabled job by cron, SCM or upstream jobs
+func setDisabledXml(config string, disabled bool) (string, error) {
+	config = replaceXmlVersion(config, "1.1", "1.0")
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(config); err != nil {
+		return "", err
+	}
+	if doc.Root() == nil {
+		return "", fmt.Errorf("no root element found in the job configuration")
+	}
+	addOrUpdateElement(doc.Root(), DisabledTag, strconv.FormatBool(disabled))
+
+	doc.Indent(2)
+	stringXml, err := doc.WriteToString()
+	if err != nil {
+		return "", err
+	}
+	return replaceXmlVersion(stringXml, "1.0", "1.1"), nil
+}
+
+// isDisabledXml returns true if the job is disabled
+func isDisabledXml(config string) bool {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(replaceXmlVersion(config, "1.1", "1.0")); err != nil || doc.Root() == nil {
+		return false
+	}
+	return getElementTextValueOrEmpty(doc.Root(), DisabledTag) == "true"
+}
+
 func addOrUpdateElement(parent *etree.Element, tag, text string) *etree.Element {
 	var e *etree.Element
 	if e = parent.SelectElement(tag); e == nil {
@@ -1232,3 +1232,36 @@ func Test_getElementTextValueOrEmpty(t *testing.T) {
 		})
 	}
 }
+
+func Test_setDisabledXml(t *testing.T) {
+	noScmConfig, err := createPipelineConfigXml(&devopsv1alpha3.NoScmPipeline{Jenkinsfile: "node{echo 'hello'}"})
+	assert.Nil(t, err)
+	multiBranchConfig, err := createMultiBranchPipelineConfigXml("project", &devopsv1alpha3.MultiBranchPipeline{
+		SourceType: devopsv1alpha3.SourceTypeGit,
+		GitSource:  &devopsv1alpha3.GitSource{Url: "https://github.com/kubesphere/devops"},
+	})
+	assert.Nil(t, err)
+
+	for _, config := range []string{noScmConfig, multiBranchConfig} {
+		assert.False(t, isDisabledXml(config))
+
+		disabled, err := setDisabledXml(config, true)
+		assert.Nil(t, err)
+		assert.True(t, isDisabledXml(disabled))
+
+		enabled, err := setDisabledXml(disabled, false)
+		assert.Nil(t, err)
+		assert.False(t, isDisabledXml(enabled))
+	}
+
+	// the other fields are kept
+	disabled, err := setDisabledXml(noScmConfig, true)
+	assert.Nil(t, err)
+	pipeline, err := parsePipelineConfigXml(disabled)
+	assert.Nil(t, err)
+	assert.Equal(t, "node{echo 'hello'}", pipeline.Jenkinsfile)
+
+	_, err = setDisabledXml("", true)
+	assert.NotNil(t, err)
+	assert.False(t, isDisabledXml("invalid"))
+}
@@ -17,17 +17,35 @@ limitations under the License.
 package jenkins
 
 import (
-	"github.com/emicklei/go-restful"
+	"fmt"
+	"net/http"
+
 	"k8s.io/klog/v2"
 
 	"kubesphere.io/devops/pkg/client/devops"
 )
 
+// CreateDevOpsProject creates the folder of a DevOps project. It's idempotent, the existing folder is treated as
+// created, since a previous attempt might have created it before crashing.
 func (j *Jenkins) CreateDevOpsProject(projectId string) (string, error) {
-	_, err := j.CreateFolder(projectId, "")
+	return j.CreateOwnedDevOpsProject(projectId, "")
+}
+
+// CreateOwnedDevOpsProject creates the folder of a DevOps project, and records the owner in its description. The
+// existing folder is treated as created only if it has the same owner, otherwise it returns a conflict error. Any
+// existing folder is accepted if the owner is empty.
+func (j *Jenkins) CreateOwnedDevOpsProject(projectId, owner string) (string, error) {
+	_, err := j.CreateFolder(projectId, folderDescription(owner))
 	if err != nil {
-		klog.Errorf("%+v", err)
-		return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		existing, getErr := j.GetJob(projectId)
+		if getErr != nil || existing.Raw.Class != folderClass {
+			klog.Errorf("%+v", err)
+			return "", devops.WrapError(err)
+		}
+		if existingOwner := getFolderOwner(existing.GetDescription()); owner != "" && existingOwner != owner {
+			return "", devops.NewError(http.StatusConflict, fmt.Sprintf(
+				"the Jenkins folder %s is owned by %q instead of %q", projectId, existingOwner, owner))
+		}
 	}
 	return projectId, nil
 }
@@ -35,7 +53,7 @@ func (j *Jenkins) CreateDevOpsProject(projectId string) (string, error) {
 func (j *Jenkins) DeleteDevOpsProject(projectId string) (err error) {
 	_, err = j.DeleteJob(projectId)
 	if err != nil {
-		return restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		return devops.WrapError(err)
 	}
 	return
 }
@@ -44,7 +62,7 @@ func (j *Jenkins) GetDevOpsProject(projectId string) (string, error) {
 	job, err := j.GetJob(projectId)
 	if err != nil {
 		klog.Errorf("%+v", err)
-		return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		return "", devops.WrapError(err)
 
 	}
 	return job.GetName(), nil
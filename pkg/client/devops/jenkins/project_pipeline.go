@@ -17,10 +17,11 @@ limitations under the License.
 package jenkins
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 
-	"github.com/emicklei/go-restful"
 	"k8s.io/klog/v2"
 
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
@@ -33,44 +34,50 @@ func (j *Jenkins) CreateProjectPipeline(projectId string, pipeline *devopsv1alph
 	case devopsv1alpha3.NoScmPipelineType:
 		config, err := createPipelineConfigXml(pipeline.Spec.Pipeline)
 		if err != nil {
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
+		}
+		if config, err = setDisabledXml(config, pipeline.Spec.Suspend); err != nil {
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
 		}
 
 		job, err := j.GetJob(pipeline.Name, projectId)
 		if job != nil {
 			err := fmt.Errorf("job name [%s] has been used", job.GetName())
-			return "", restful.NewError(http.StatusConflict, err.Error())
+			return "", devops.NewError(http.StatusConflict, err.Error())
 		}
 
 		if err != nil && devops.GetDevOpsStatusCode(err) != http.StatusNotFound {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		_, err = j.CreateJobInFolder(config, pipeline.Name, projectId)
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		return pipeline.Name, nil
 	case devopsv1alpha3.MultiBranchPipelineType:
 		config, err := createMultiBranchPipelineConfigXml(projectId, pipeline.Spec.MultiBranchPipeline)
 		if err != nil {
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
+		}
+		if config, err = setDisabledXml(config, pipeline.Spec.Suspend); err != nil {
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
 		}
 
 		job, err := j.GetJob(pipeline.Name, projectId)
 		if job != nil {
 			err := fmt.Errorf("job name [%s] has been used", job.GetName())
-			return "", restful.NewError(http.StatusConflict, err.Error())
+			return "", devops.NewError(http.StatusConflict, err.Error())
 		}
 
 		if err != nil && devops.GetDevOpsStatusCode(err) != http.StatusNotFound {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		_, err = j.CreateJobInFolder(config, pipeline.Name, projectId)
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		return pipeline.Name, nil
@@ -78,14 +85,14 @@ func (j *Jenkins) CreateProjectPipeline(projectId string, pipeline *devopsv1alph
 	default:
 		err := fmt.Errorf("error unsupport job type")
 		klog.Errorf("%+v", err)
-		return "", restful.NewError(http.StatusBadRequest, err.Error())
+		return "", devops.NewError(http.StatusBadRequest, err.Error())
 	}
 }
 
 func (j *Jenkins) DeleteProjectPipeline(projectId string, pipelineId string) (string, error) {
 	_, err := j.DeleteJob(pipelineId, projectId)
 	if err != nil {
-		return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		return "", devops.WrapError(err)
 	}
 	return pipelineId, nil
 
@@ -95,22 +102,25 @@ func (j *Jenkins) UpdateProjectPipeline(projectId string, pipeline *devopsv1alph
 	case devopsv1alpha3.NoScmPipelineType:
 		job, err := j.GetJob(pipeline.Name, projectId)
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 		config, err := job.GetConfig()
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		updatedConfig, err := updatePipelineConfigXml(config, pipeline.Spec.Pipeline)
 		if err != nil {
 			klog.Errorf("%+v", err)
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
+		}
+		if updatedConfig, err = setDisabledXml(updatedConfig, pipeline.Spec.Suspend); err != nil {
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
 		}
 
 		err = job.UpdateConfig(updatedConfig)
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 		return pipeline.Name, nil
 
@@ -118,19 +128,22 @@ func (j *Jenkins) UpdateProjectPipeline(projectId string, pipeline *devopsv1alph
 		config, err := createMultiBranchPipelineConfigXml(projectId, pipeline.Spec.MultiBranchPipeline)
 		if err != nil {
 			klog.Errorf("%+v", err)
-			return "", restful.NewError(http.StatusInternalServerError, err.Error())
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
+		}
+		if config, err = setDisabledXml(config, pipeline.Spec.Suspend); err != nil {
+			return "", devops.NewError(http.StatusInternalServerError, err.Error())
 		}
 
 		job, err := j.GetJob(pipeline.Spec.MultiBranchPipeline.Name, projectId)
 
 		if err != nil {
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		err = job.UpdateConfig(config)
 		if err != nil {
 			klog.Errorf("%+v", err)
-			return "", restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return "", devops.WrapError(err)
 		}
 
 		return pipeline.Name, nil
@@ -138,7 +151,7 @@ func (j *Jenkins) UpdateProjectPipeline(projectId string, pipeline *devopsv1alph
 	default:
 		err := fmt.Errorf("error unsupport job type")
 		klog.Errorf("%+v", err)
-		return "", restful.NewError(http.StatusBadRequest, err.Error())
+		return "", devops.NewError(http.StatusBadRequest, err.Error())
 	}
 }
 
@@ -146,44 +159,120 @@ func (j *Jenkins) GetProjectPipelineConfig(projectId, pipelineId string) (*devop
 	job, err := j.GetJob(pipelineId, projectId)
 	if err != nil {
 		klog.Errorf("%+v", err)
-		return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+		return nil, devops.WrapError(err)
 	}
 	switch job.Raw.Class {
 	case "org.jenkinsci.plugins.workflow.job.WorkflowJob":
 		config, err := job.GetConfig()
 		if err != nil {
-			return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return nil, devops.WrapError(err)
 		}
 		pipeline, err := parsePipelineConfigXml(config)
 		if err != nil {
-			return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return nil, devops.WrapError(err)
 		}
 		pipeline.Name = pipelineId
 		return &devopsv1alpha3.Pipeline{
 			Spec: devopsv1alpha3.PipelineSpec{
 				Type:     devopsv1alpha3.NoScmPipelineType,
 				Pipeline: pipeline,
+				Suspend:  isDisabledXml(config),
 			},
 		}, nil
 
 	case "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
 		config, err := job.GetConfig()
 		if err != nil {
-			return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return nil, devops.WrapError(err)
 		}
 		pipeline, err := parseMultiBranchPipelineConfigXml(config)
 		if err != nil {
-			return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+			return nil, devops.WrapError(err)
 		}
 		pipeline.Name = pipelineId
 		return &devopsv1alpha3.Pipeline{
 			Spec: devopsv1alpha3.PipelineSpec{
 				Type:                devopsv1alpha3.MultiBranchPipelineType,
 				MultiBranchPipeline: pipeline,
+				Suspend:             isDisabledXml(config),
 			},
 		}, nil
 	default:
 		klog.Errorf("%+v", err)
-		return nil, restful.NewError(http.StatusBadRequest, err.Error())
+		return nil, devops.NewError(http.StatusBadRequest, err.Error())
+	}
+}
+
+// GetExpectedPipelineSpec returns the Pipeline spec which is parsed from the generated Jenkins job configuration.
+// It is comparable with the spec from GetProjectPipelineConfig, because some fields cannot be kept after the conversion.
+func GetExpectedPipelineSpec(projectId string, pipeline *devopsv1alpha3.Pipeline) (*devopsv1alpha3.PipelineSpec, error) {
+	switch pipeline.Spec.Type {
+	case devopsv1alpha3.NoScmPipelineType:
+		if pipeline.Spec.Pipeline == nil {
+			return nil, fmt.Errorf("no pipeline found in the spec of %s", pipeline.Name)
+		}
+		config, err := createPipelineConfigXml(pipeline.Spec.Pipeline)
+		if err != nil {
+			return nil, err
+		}
+		noScmPipeline, err := parsePipelineConfigXml(config)
+		if err != nil {
+			return nil, err
+		}
+		noScmPipeline.Name = pipeline.Name
+		return &devopsv1alpha3.PipelineSpec{
+			Type:     devopsv1alpha3.NoScmPipelineType,
+			Pipeline: noScmPipeline,
+			Suspend:  pipeline.Spec.Suspend,
+		}, nil
+	case devopsv1alpha3.MultiBranchPipelineType:
+		if pipeline.Spec.MultiBranchPipeline == nil {
+			return nil, fmt.Errorf("no multi_branch_pipeline found in the spec of %s", pipeline.Name)
+		}
+		config, err := createMultiBranchPipelineConfigXml(projectId, pipeline.Spec.MultiBranchPipeline)
+		if err != nil {
+			return nil, err
+		}
+		multiBranchPipeline, err := parseMultiBranchPipelineConfigXml(config)
+		if err != nil {
+			return nil, err
+		}
+		multiBranchPipeline.Name = pipeline.Name
+		return &devopsv1alpha3.PipelineSpec{
+			Type:                devopsv1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: multiBranchPipeline,
+			Suspend:             pipeline.Spec.Suspend,
+		}, nil
+	default:
+		return nil, fmt.Errorf("error unsupport job type")
+	}
+}
+
+// GetPipelineConfigHash returns the hash of the job config XML which a Pipeline renders. An existing job only needs to
+// be updated when the hash changes, since Jenkins records every update in the config history and reloads the job.
+func GetPipelineConfigHash(projectId string, pipeline *devopsv1alpha3.Pipeline) (hash string, err error) {
+	var config string
+	switch pipeline.Spec.Type {
+	case devopsv1alpha3.NoScmPipelineType:
+		if pipeline.Spec.Pipeline == nil {
+			return "", fmt.Errorf("no pipeline found in the spec of %s", pipeline.Name)
+		}
+		config, err = createPipelineConfigXml(pipeline.Spec.Pipeline)
+	case devopsv1alpha3.MultiBranchPipelineType:
+		if pipeline.Spec.MultiBranchPipeline == nil {
+			return "", fmt.Errorf("no multi_branch_pipeline found in the spec of %s", pipeline.Name)
+		}
+		config, err = createMultiBranchPipelineConfigXml(projectId, pipeline.Spec.MultiBranchPipeline)
+	default:
+		err = fmt.Errorf("error unsupport job type")
+	}
+	if err != nil {
+		return
+	}
+	if config, err = setDisabledXml(config, pipeline.Spec.Suspend); err != nil {
+		return
 	}
+	sum := sha256.Sum256([]byte(config))
+	hash = hex.EncodeToString(sum[:])
+	return
 }
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestGetExpectedPipelineSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline *devopsv1alpha3.Pipeline
+		want     *devopsv1alpha3.PipelineSpec
+		wantErr  bool
+	}{{
+		name: "unknown type",
+		pipeline: &devopsv1alpha3.Pipeline{
+			Spec: devopsv1alpha3.PipelineSpec{Type: "fake"},
+		},
+		wantErr: true,
+	}, {
+		name: "no pipeline in spec",
+		pipeline: &devopsv1alpha3.Pipeline{
+			Spec: devopsv1alpha3.PipelineSpec{Type: devopsv1alpha3.NoScmPipelineType},
+		},
+		wantErr: true,
+	}, {
+		name: "no multi-branch pipeline in spec",
+		pipeline: &devopsv1alpha3.Pipeline{
+			Spec: devopsv1alpha3.PipelineSpec{Type: devopsv1alpha3.MultiBranchPipelineType},
+		},
+		wantErr: true,
+	}, {
+		name: "a normal pipeline",
+		pipeline: &devopsv1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "job"},
+			Spec: devopsv1alpha3.PipelineSpec{
+				Type: devopsv1alpha3.NoScmPipelineType,
+				Pipeline: &devopsv1alpha3.NoScmPipeline{
+					Name:              "display",
+					Description:       "for test",
+					DisableConcurrent: true,
+					Jenkinsfile:       "node{echo 'hello'}",
+				},
+			},
+		},
+		want: &devopsv1alpha3.PipelineSpec{
+			Type: devopsv1alpha3.NoScmPipelineType,
+			Pipeline: &devopsv1alpha3.NoScmPipeline{
+				Name:              "job",
+				Description:       "for test",
+				DisableConcurrent: true,
+				Jenkinsfile:       "node{echo 'hello'}",
+			},
+		},
+	}, {
+		name: "a multi-branch pipeline",
+		pipeline: &devopsv1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "job"},
+			Spec: devopsv1alpha3.PipelineSpec{
+				Type: devopsv1alpha3.MultiBranchPipelineType,
+				MultiBranchPipeline: &devopsv1alpha3.MultiBranchPipeline{
+					Name:       "job",
+					SourceType: devopsv1alpha3.SourceTypeGit,
+					ScriptPath: "Jenkinsfile",
+					GitSource: &devopsv1alpha3.GitSource{
+						Url: "https://github.com/kubesphere/ks-devops",
+					},
+				},
+			},
+		},
+		want: &devopsv1alpha3.PipelineSpec{
+			Type: devopsv1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &devopsv1alpha3.MultiBranchPipeline{
+				Name:       "job",
+				SourceType: devopsv1alpha3.SourceTypeGit,
+				ScriptPath: "Jenkinsfile",
+				GitSource: &devopsv1alpha3.GitSource{
+					Url: "https://github.com/kubesphere/ks-devops",
+				},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetExpectedPipelineSpec("project", tt.pipeline)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetPipelineConfigHash(t *testing.T) {
+	newPipeline := func(jenkinsfile string, suspend bool) *devopsv1alpha3.Pipeline {
+		return &devopsv1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "job"},
+			Spec: devopsv1alpha3.PipelineSpec{
+				Type:     devopsv1alpha3.NoScmPipelineType,
+				Pipeline: &devopsv1alpha3.NoScmPipeline{Name: "job", Jenkinsfile: jenkinsfile},
+				Suspend:  suspend,
+			},
+		}
+	}
+
+	hash, err := GetPipelineConfigHash("ns", newPipeline("node {}", false))
+	assert.Nil(t, err)
+	assert.Len(t, hash, 64)
+
+	// the same config always renders the same hash
+	same, err := GetPipelineConfigHash("ns", newPipeline("node {}", false))
+	assert.Nil(t, err)
+	assert.Equal(t, hash, same)
+
+	changed, err := GetPipelineConfigHash("ns", newPipeline("node { echo 'hi' }", false))
+	assert.Nil(t, err)
+	assert.NotEqual(t, hash, changed)
+
+	suspended, err := GetPipelineConfigHash("ns", newPipeline("node {}", true))
+	assert.Nil(t, err)
+	assert.NotEqual(t, hash, suspended)
+
+	multiBranch, err := GetPipelineConfigHash("ns", &devopsv1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "job"},
+		Spec: devopsv1alpha3.PipelineSpec{
+			Type: devopsv1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &devopsv1alpha3.MultiBranchPipeline{
+				Name:       "job",
+				SourceType: devopsv1alpha3.SourceTypeGit,
+				GitSource:  &devopsv1alpha3.GitSource{Url: "https://github.com/kubesphere/ks-devops"},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, multiBranch, 64)
+
+	_, err = GetPipelineConfigHash("ns", &devopsv1alpha3.Pipeline{Spec: devopsv1alpha3.PipelineSpec{Type: "fake"}})
+	assert.NotNil(t, err)
+	_, err = GetPipelineConfigHash("ns", &devopsv1alpha3.Pipeline{
+		Spec: devopsv1alpha3.PipelineSpec{Type: devopsv1alpha3.NoScmPipelineType},
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestCreateDevOpsProject(t *testing.T) {
+	// the items which exist in Jenkins, by their classes
+	items := map[string]string{
+		"existing": folderClass,
+		"pipeline": "org.jenkinsci.plugins.workflow.job.WorkflowJob",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/createItem"):
+			name := r.FormValue("name")
+			if _, ok := items[name]; ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			items[name] = folderClass
+		case strings.HasPrefix(r.URL.Path, "/job/") && strings.HasSuffix(r.URL.Path, "/api/json"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/job/"), "/api/json")
+			if class, ok := items[name]; ok {
+				_, _ = w.Write([]byte(`{"_class":"` + class + `","name":"` + name + `"}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDevopsClient(&Options{Host: server.URL})
+	assert.Nil(t, err)
+
+	name, err := client.CreateDevOpsProject("new")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", name)
+
+	// the folder was created by a previous attempt
+	name, err = client.CreateDevOpsProject("existing")
+	assert.Nil(t, err)
+	assert.Equal(t, "existing", name)
+
+	// the name is used by a job which is not a folder
+	_, err = client.CreateDevOpsProject("pipeline")
+	assert.NotNil(t, err)
+}
+
+func TestCreateOwnedDevOpsProject(t *testing.T) {
+	// the descriptions of the folders which exist in Jenkins
+	descriptions := map[string]string{
+		"legacy": "",
+		"owned":  "ks-devops-owner: cluster-a/uid-a",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/createItem"):
+			name := r.FormValue("name")
+			if _, ok := descriptions[name]; ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			payload := map[string]string{}
+			_ = json.Unmarshal([]byte(r.FormValue("json")), &payload)
+			descriptions[name] = payload["description"]
+		case strings.HasPrefix(r.URL.Path, "/job/") && strings.HasSuffix(r.URL.Path, "/api/json"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/job/"), "/api/json")
+			if description, ok := descriptions[name]; ok {
+				data, _ := json.Marshal(map[string]string{"_class": folderClass, "name": name, "description": description})
+				_, _ = w.Write(data)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDevopsClient(&Options{Host: server.URL})
+	assert.Nil(t, err)
+
+	_, err = client.CreateOwnedDevOpsProject("new", "cluster-a/uid-b")
+	assert.Nil(t, err)
+	assert.Equal(t, "ks-devops-owner: cluster-a/uid-b", descriptions["new"])
+
+	// the folder was created by a previous attempt of the same owner
+	_, err = client.CreateOwnedDevOpsProject("owned", "cluster-a/uid-a")
+	assert.Nil(t, err)
+
+	// the folder is owned by another cluster
+	_, err = client.CreateOwnedDevOpsProject("owned", "cluster-b/uid-c")
+	assert.Equal(t, devops.ErrorReasonConflict, devops.ReasonForError(err))
+	_, err = client.CreateOwnedDevOpsProject("legacy", "cluster-b/uid-c")
+	assert.Equal(t, devops.ErrorReasonConflict, devops.ReasonForError(err))
+
+	// any existing folder is taken over without the owner
+	_, err = client.CreateOwnedDevOpsProject("owned", "")
+	assert.Nil(t, err)
+}
@@ -66,6 +66,7 @@ type Pipeline struct {
 	} `json:"_links,omitempty" description:"references the reachable path to this resource."`
 	Actions         []interface{} `json:"actions,omitempty" description:"the list of all actions."`
 	Disabled        interface{}   `json:"disabled,omitempty" description:"disable or not, if disabled, can not do any action."`
+	Suspended       bool          `json:"suspended,omitempty" description:"suspended or not, if suspended, all the triggers are ignored."`
 	DisplayName     string        `json:"displayName,omitempty" description:"display name"`
 	FullDisplayName string        `json:"fullDisplayName,omitempty" description:"full display name"`
 	FullName        string        `json:"fullName,omitempty" description:"full name"`
@@ -415,6 +416,24 @@ type Artifacts struct {
 	URL          string `json:"url,omitempty" description:"The url for Download artifacts"`
 }
 
+// TestCase is a case of the test report of a pipeline run
+type TestCase struct {
+	ClassName string `json:"className,omitempty" description:"class name of the test case"`
+	Name      string `json:"name,omitempty" description:"name of the test case"`
+	Status    string `json:"status,omitempty" description:"status of the test case. e.g. PASSED, FAILED, REGRESSION, FIXED, SKIPPED"`
+}
+
+// IsFailed returns true if the test case failed
+func (c TestCase) IsFailed() bool {
+	return c.Status == "FAILED" || c.Status == "REGRESSION"
+}
+
+// Fingerprint is the MD5 checksum of a file which was archived by a pipeline run
+type Fingerprint struct {
+	FileName string `json:"fileName,omitempty" description:"name of the file"`
+	Hash     string `json:"hash,omitempty" description:"MD5 checksum of the file"`
+}
+
 // GetPipeBranch
 type PipelineBranch []PipelineBranchItem
 
@@ -553,6 +572,8 @@ type Parameter struct {
 
 type RunPayload struct {
 	Parameters []Parameter `json:"parameters,omitempty"`
+	// IdempotencyKey makes the retried requests create only one PipelineRun, the same as the header Idempotency-Key
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 // RunPipeline
@@ -1137,11 +1158,18 @@ type PipelineOperator interface {
 	RunPipeline(projectName, pipelineName string, httpParameters *HttpParameters) (*RunPipeline, error)
 	GetArtifacts(projectName, pipelineName, runId string, httpParameters *HttpParameters) ([]Artifacts, error)
 	DownloadArtifact(projectName, pipelineName, runId, filename string) (io.ReadCloser, error)
+	GetArtifactStream(projectName, pipelineName, runId, filename string, header http.Header) (*http.Response, error)
 	GetRunLog(projectName, pipelineName, runId string, httpParameters *HttpParameters) ([]byte, error)
+	// GetProgressiveRunLog returns the run log from the start offset, the headers X-Text-Size and X-More-Data
+	// are the next offset and whether the log is still being written
+	GetProgressiveRunLog(projectName, pipelineName, runId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
 	GetStepLog(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
+	GetNodeLog(projectName, pipelineName, runId, nodeId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
 	GetNodeSteps(projectName, pipelineName, runId, nodeId string, httpParameters *HttpParameters) ([]NodeSteps, error)
 	GetPipelineRunNodes(projectName, pipelineName, runId string, httpParameters *HttpParameters) ([]PipelineRunNodes, error)
 	SubmitInputStep(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *HttpParameters) ([]byte, error)
+	GetTestCases(projectName, pipelineName, runId string) ([]TestCase, error)
+	GetFingerprints(projectName, pipelineName, runId string) ([]Fingerprint, error)
 
 	//BranchPipelinne operator interface
 	GetBranchPipeline(projectName, pipelineName, branchName string, httpParameters *HttpParameters) (*BranchPipeline, error)
@@ -1150,11 +1178,16 @@ type PipelineOperator interface {
 	ReplayBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) (*ReplayPipeline, error)
 	RunBranchPipeline(projectName, pipelineName, branchName string, httpParameters *HttpParameters) (*RunPipeline, error)
 	GetBranchArtifacts(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) ([]Artifacts, error)
+	GetBranchArtifactStream(projectName, pipelineName, branchName, runId, filename string, header http.Header) (*http.Response, error)
 	GetBranchRunLog(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) ([]byte, error)
+	GetBranchProgressiveRunLog(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
 	GetBranchStepLog(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
+	GetBranchNodeLog(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *HttpParameters) ([]byte, http.Header, error)
 	GetBranchNodeSteps(projectName, pipelineName, branchName, runId, nodeId string, httpParameters *HttpParameters) ([]NodeSteps, error)
 	GetBranchPipelineRunNodes(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) ([]BranchPipelineRunNodes, error)
 	SubmitBranchInputStep(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *HttpParameters) ([]byte, error)
+	GetBranchTestCases(projectName, pipelineName, branchName, runId string) ([]TestCase, error)
+	GetBranchFingerprints(projectName, pipelineName, branchName, runId string) ([]Fingerprint, error)
 	GetPipelineBranch(projectName, pipelineName string, httpParameters *HttpParameters) (*PipelineBranch, error)
 	ScanBranch(projectName, pipelineName string, httpParameters *HttpParameters) ([]byte, error)
 
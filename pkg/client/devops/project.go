@@ -24,6 +24,8 @@ so we only need to create the project with the corresponding ID in the CI/CD sys
 */
 type ProjectOperator interface {
 	CreateDevOpsProject(projectId string) (string, error)
+	// CreateOwnedDevOpsProject creates the project with its owner, it's a conflict if the project has another owner
+	CreateOwnedDevOpsProject(projectId, owner string) (string, error)
 	DeleteDevOpsProject(projectId string) error
 	GetDevOpsProject(projectId string) (string, error)
 }
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+// QueueItem is a build which is waiting in the queue of Jenkins
+type QueueItem struct {
+	ID           int    `json:"id" description:"id of the queue item"`
+	ProjectName  string `json:"projectName" description:"name of the DevOps project"`
+	PipelineName string `json:"pipelineName" description:"name of the pipeline"`
+	BranchName   string `json:"branchName,omitempty" description:"name of the branch, only for multi-branch pipeline"`
+	Why          string `json:"why,omitempty" description:"the reason of waiting, e.g. Waiting for next available executor"`
+	Blocked      bool   `json:"blocked,omitempty" description:"the item is blocked, e.g. by a locked resource"`
+	Buildable    bool   `json:"buildable,omitempty" description:"the item is ready to build, but waiting for an executor"`
+	Stuck        bool   `json:"stuck,omitempty" description:"the item has been buildable for a long time"`
+	InQueueSince int64  `json:"inQueueSince,omitempty" description:"the time in milliseconds when the item entered the queue"`
+}
+
+// QueueOperator provides APIs for operating the build queue
+type QueueOperator interface {
+	// ListQueueItems returns all the items of the build queue
+	ListQueueItems() ([]QueueItem, error)
+
+	// CancelQueueItem takes an item out of the build queue
+	CancelQueueItem(id int) error
+}
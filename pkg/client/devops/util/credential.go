@@ -19,11 +19,12 @@ package util
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
-	"github.com/emicklei/go-restful"
 	jcredential "github.com/jenkins-zh/jenkins-client/pkg/credential"
 	v1 "k8s.io/api/core/v1"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
 )
 
 // ConvertSecretToCredential converts a secret to Jenkins credential type
@@ -46,8 +47,37 @@ func ConvertSecretToCredential(secret *v1.Secret) (interface{}, error) {
 	case devopsv1alpha3.SecretTypeKubeConfig:
 		secretContent := string(secret.Data[devopsv1alpha3.KubeConfigSecretKey])
 		return jcredential.NewKubeConfigCredential(name, secretContent), nil
+	case devopsv1alpha3.SecretTypeDockerRegistry:
+		// the docker registry is used as a username and password credential in Jenkins, e.g. docker.withRegistry
+		server := string(secret.Data[devopsv1alpha3.DockerRegistryServerKey])
+		username := string(secret.Data[devopsv1alpha3.DockerRegistryUsernameKey])
+		password := string(secret.Data[devopsv1alpha3.DockerRegistryPasswordKey])
+		credential := jcredential.NewUsernamePasswordCredential(name, username, password)
+		credential.Description = fmt.Sprintf("docker registry: %s", server)
+		return credential, nil
+	case devopsv1alpha3.SecretTypeBearerToken:
+		// the bearer token is used as a secret text credential in Jenkins
+		token := string(secret.Data[devopsv1alpha3.BearerTokenKey])
+		return jcredential.NewSecretTextCredential(name, token), nil
 	default:
 		err := fmt.Errorf("error unsupport credential type")
-		return nil, restful.NewError(http.StatusBadRequest, err.Error())
+		return nil, devops.NewError(http.StatusBadRequest, err.Error())
 	}
 }
+
+// GlobalCredentialDomain is the default credential domain of a Jenkins credential store
+const GlobalCredentialDomain = "_"
+
+// GetCredentialDomain returns the Jenkins credential domain and its hostnames of a credential. The credential which is
+// restricted to some hostnames is stored in a domain named after itself, others are stored in the global domain.
+func GetCredentialDomain(secret *v1.Secret) (domain string, hostnames []string) {
+	for _, hostname := range strings.Split(secret.GetAnnotations()[devopsv1alpha3.CredentialHostnamesAnnoKey], ",") {
+		if hostname = strings.TrimSpace(hostname); hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	if len(hostnames) == 0 {
+		return GlobalCredentialDomain, nil
+	}
+	return secret.GetName(), hostnames
+}
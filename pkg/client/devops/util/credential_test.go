@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	jcredential "github.com/jenkins-zh/jenkins-client/pkg/credential"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestConvertSecretToCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  *v1.Secret
+		want    interface{}
+		wantErr bool
+	}{{
+		name: "docker registry",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+			Type:       devopsv1alpha3.SecretTypeDockerRegistry,
+			Data: map[string][]byte{
+				devopsv1alpha3.DockerRegistryServerKey:   []byte("docker.io"),
+				devopsv1alpha3.DockerRegistryUsernameKey: []byte("admin"),
+				devopsv1alpha3.DockerRegistryPasswordKey: []byte("password"),
+			},
+		},
+		want: func() interface{} {
+			credential := jcredential.NewUsernamePasswordCredential("registry", "admin", "password")
+			credential.Description = "docker registry: docker.io"
+			return credential
+		}(),
+	}, {
+		name: "bearer token",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token"},
+			Type:       devopsv1alpha3.SecretTypeBearerToken,
+			Data: map[string][]byte{
+				devopsv1alpha3.BearerTokenKey: []byte("fake-token"),
+			},
+		},
+		want: jcredential.NewSecretTextCredential("token", "fake-token"),
+	}, {
+		name: "kubeconfig",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig"},
+			Type:       devopsv1alpha3.SecretTypeKubeConfig,
+			Data: map[string][]byte{
+				devopsv1alpha3.KubeConfigSecretKey: []byte("fake-kubeconfig"),
+			},
+		},
+		want: jcredential.NewKubeConfigCredential("kubeconfig", "fake-kubeconfig"),
+	}, {
+		name: "unknown type",
+		secret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "unknown"},
+			Type:       v1.SecretTypeOpaque,
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertSecretToCredential(tt.secret)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetCredentialDomain(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "git"}}
+	domain, hostnames := GetCredentialDomain(secret)
+	assert.Equal(t, GlobalCredentialDomain, domain)
+	assert.Nil(t, hostnames)
+
+	secret.Annotations = map[string]string{devopsv1alpha3.CredentialHostnamesAnnoKey: " , "}
+	domain, _ = GetCredentialDomain(secret)
+	assert.Equal(t, GlobalCredentialDomain, domain)
+
+	secret.Annotations[devopsv1alpha3.CredentialHostnamesAnnoKey] = "github.com, *.example.com"
+	domain, hostnames = GetCredentialDomain(secret)
+	assert.Equal(t, "git", domain)
+	assert.Equal(t, []string{"github.com", "*.example.com"}, hostnames)
+}
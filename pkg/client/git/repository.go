@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	goscm "github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// ErrUnsupportedSource means the files of the source cannot be accessed by the API of SCM provider
+var ErrUnsupportedSource = fmt.Errorf("unsupported source to access by the API of SCM provider")
+
+// SCMClientFactory creates an SCM client
+type SCMClientFactory func(provider, server string, secretRef *v1.SecretReference) (*goscm.Client, error)
+
+// NewSCMClientFactory returns a factory which creates the SCM clients with the credentials in Kubernetes
+func NewSCMClientFactory(k8sClient ResourceGetter) SCMClientFactory {
+	return func(provider, server string, secretRef *v1.SecretReference) (*goscm.Client, error) {
+		clientFactory := NewClientFactory(provider, secretRef, k8sClient)
+		clientFactory.Server = server
+		return clientFactory.GetClient()
+	}
+}
+
+// GetRepository returns the provider, server and full name of the repository of a multi-branch Pipeline
+func GetRepository(pipeline *v1alpha3.MultiBranchPipeline) (provider, server, repo string, err error) {
+	switch pipeline.SourceType {
+	case v1alpha3.SourceTypeGithub:
+		if source := pipeline.GitHubSource; source != nil {
+			return "github", source.ApiUri, source.Owner + "/" + source.Repo, nil
+		}
+	case v1alpha3.SourceTypeGitlab:
+		if source := pipeline.GitlabSource; source != nil {
+			return "gitlab", source.ApiUri, source.Owner + "/" + source.Repo, nil
+		}
+	case v1alpha3.SourceTypeBitbucket:
+		if source := pipeline.BitbucketServerSource; source != nil {
+			if server = source.ApiUri; server == "" {
+				server = "https://bitbucket.org"
+			}
+			return "bitbucket-server", server, source.Owner + "/" + source.Repo, nil
+		}
+	case v1alpha3.SourceTypeGit:
+		if source := pipeline.GitSource; source != nil {
+			return ParseRepositoryURL(source.Url)
+		}
+	}
+	err = ErrUnsupportedSource
+	return
+}
+
+// ParseRepositoryURL returns the provider, server and full name of a repository by its URL. Only the well-known
+// servers are supported, because the API of a self-hosted server is unknown.
+func ParseRepositoryURL(repoURL string) (provider, server, repo string, err error) {
+	if link, parseErr := url.Parse(repoURL); parseErr == nil {
+		repo = strings.TrimSuffix(strings.Trim(link.Path, "/"), ".git")
+		switch link.Host {
+		case "github.com":
+			return "github", "", repo, nil
+		case "gitlab.com":
+			return "gitlab", "", repo, nil
+		}
+	}
+	return "", "", "", ErrUnsupportedSource
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestGetRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		pipeline     *v1alpha3.MultiBranchPipeline
+		wantProvider string
+		wantServer   string
+		wantRepo     string
+		wantErr      bool
+	}{{
+		name: "github",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeGithub,
+			GitHubSource: &v1alpha3.GithubSource{Owner: "kubesphere", Repo: "ks-devops"}},
+		wantProvider: "github",
+		wantRepo:     "kubesphere/ks-devops",
+	}, {
+		name: "self-hosted gitlab",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeGitlab,
+			GitlabSource: &v1alpha3.GitlabSource{Owner: "group", Repo: "app", ApiUri: "https://gitlab.example.com"}},
+		wantProvider: "gitlab",
+		wantServer:   "https://gitlab.example.com",
+		wantRepo:     "group/app",
+	}, {
+		name: "bitbucket cloud",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeBitbucket,
+			BitbucketServerSource: &v1alpha3.BitbucketServerSource{Owner: "owner", Repo: "app"}},
+		wantProvider: "bitbucket-server",
+		wantServer:   "https://bitbucket.org",
+		wantRepo:     "owner/app",
+	}, {
+		name: "git with a well-known server",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeGit,
+			GitSource: &v1alpha3.GitSource{Url: "https://github.com/kubesphere/ks-devops.git"}},
+		wantProvider: "github",
+		wantRepo:     "kubesphere/ks-devops",
+	}, {
+		name: "git with a self-hosted server",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeGit,
+			GitSource: &v1alpha3.GitSource{Url: "https://git.example.com/app.git"}},
+		wantErr: true,
+	}, {
+		name:     "source is missing",
+		pipeline: &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeGithub},
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server, repo, err := GetRepository(tt.pipeline)
+			if tt.wantErr {
+				assert.Equal(t, ErrUnsupportedSource, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantProvider, provider)
+			assert.Equal(t, tt.wantServer, server)
+			assert.Equal(t, tt.wantRepo, repo)
+		})
+	}
+}
+
+func TestParseRepositoryURL(t *testing.T) {
+	provider, server, repo, err := ParseRepositoryURL("https://gitlab.com/group/app/")
+	assert.Nil(t, err)
+	assert.Equal(t, "gitlab", provider)
+	assert.Equal(t, "", server)
+	assert.Equal(t, "group/app", repo)
+
+	_, _, _, err = ParseRepositoryURL("https://git.example.com/app.git")
+	assert.Equal(t, ErrUnsupportedSource, err)
+	_, _, _, err = ParseRepositoryURL("://invalid")
+	assert.Equal(t, ErrUnsupportedSource, err)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"kubesphere.io/devops/pkg/client/history"
+)
+
+// FakeHistory is an in-memory history store
+type FakeHistory struct {
+	Records map[string]history.Record
+
+	mu sync.Mutex
+}
+
+// NewFakeHistory creates an in-memory history store with the given records
+func NewFakeHistory(records ...history.Record) *FakeHistory {
+	h := &FakeHistory{Records: map[string]history.Record{}}
+	for _, record := range records {
+		h.Records[record.UID] = record
+	}
+	return h
+}
+
+// Archive stores a record, it does nothing if the record was archived already
+func (h *FakeHistory) Archive(_ context.Context, record *history.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.Records[record.UID]; !ok {
+		h.Records[record.UID] = *record
+	}
+	return nil
+}
+
+// List returns the records which match the query from the latest one, and the total count of them
+func (h *FakeHistory) List(_ context.Context, query history.Query) (records []history.Record, total int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records = []history.Record{}
+	for _, record := range h.Records {
+		if matches(record, query) {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return millis(records[i]) > millis(records[j])
+	})
+
+	total = len(records)
+	if query.Offset > len(records) {
+		query.Offset = len(records)
+	}
+	records = records[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(records) {
+		records = records[:query.Limit]
+	}
+	return
+}
+
+// Get returns the latest record of a PipelineRun
+func (h *FakeHistory) Get(ctx context.Context, namespace, name string) (*history.Record, error) {
+	records, _, _ := h.List(ctx, history.Query{Namespace: namespace})
+	for i := range records {
+		if records[i].Name == name {
+			return &records[i], nil
+		}
+	}
+	return nil, history.ErrNotFound
+}
+
+func matches(record history.Record, query history.Query) bool {
+	if (query.Namespace != "" && record.Namespace != query.Namespace) ||
+		(query.Pipeline != "" && record.Pipeline != query.Pipeline) ||
+		(query.RefName != "" && record.RefName != query.RefName) ||
+		(query.Phase != "" && record.Phase != query.Phase) {
+		return false
+	}
+	if query.Since != nil && (record.CompletionTime == nil || record.CompletionTime.Before(*query.Since)) {
+		return false
+	}
+	if query.Until != nil && (record.CompletionTime == nil || !record.CompletionTime.Before(*query.Until)) {
+		return false
+	}
+	return true
+}
+
+func millis(record history.Record) int64 {
+	if record.CompletionTime == nil {
+		return 0
+	}
+	return record.CompletionTime.UnixNano()
+}
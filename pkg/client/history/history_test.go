@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestNewRecord(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "pr",
+			UID:       types.UID("uid"),
+			Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{
+				v1alpha3.JenkinsPipelineRunIDAnnoKey: "3",
+				v1alpha3.PipelineRunCreatorAnnoKey:   "admin",
+				v1alpha3.PipelineRunTriggerAnnoKey:   "webhook",
+				v1alpha3.JenkinsPipelineRunStatusAnnoKey: `{"changeSet":[{"commitId":"a","author":{"id":"alice","fullName":"Alice"}},` +
+					`{"commitId":"b","author":{"id":"bob"}}]}`,
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "main"}},
+		Status: v1alpha3.PipelineRunStatus{
+			Phase:          v1alpha3.Succeeded,
+			StartTime:      &metav1.Time{Time: start},
+			CompletionTime: &metav1.Time{Time: start.Add(time.Minute)},
+		},
+	}
+
+	record := NewRecord(pr)
+	assert.Equal(t, "uid", record.UID)
+	assert.Equal(t, "pipeline", record.Pipeline)
+	assert.Equal(t, "3", record.RunID)
+	assert.Equal(t, "main", record.RefName)
+	assert.Equal(t, "admin", record.Creator)
+	assert.Equal(t, "webhook", record.Trigger)
+	assert.Equal(t, "bob", record.Committer)
+	assert.Equal(t, v1alpha3.Succeeded, record.Phase)
+	assert.Equal(t, start, *record.StartTime)
+	assert.Equal(t, start.Add(time.Minute), *record.CompletionTime)
+
+	delete(pr.Annotations, v1alpha3.JenkinsPipelineRunStatusAnnoKey)
+	assert.Empty(t, NewRecord(pr).Committer)
+}
+
+func TestOptions(t *testing.T) {
+	assert.False(t, NewHistoryOptions().Enabled())
+	assert.Empty(t, NewHistoryOptions().Validate())
+	assert.Empty(t, (&Options{Driver: DriverMySQL, DSN: "dsn"}).Validate())
+	assert.Len(t, (&Options{Driver: "sqlite", DSN: "dsn", Retention: -1}).Validate(), 2)
+}
+
+func newMockStore(t *testing.T, driver string) (*sqlStore, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return newSQLStore(db, driver), mock
+}
+
+func TestMigrate(t *testing.T) {
+	store, mock := newMockStore(t, DriverPostgres)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS pipelinerun_history").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT trigger_type FROM pipelinerun_history WHERE 1 = 0")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT committer FROM pipelinerun_history WHERE 1 = 0")).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE pipelinerun_history ADD COLUMN committer VARCHAR(255) NOT NULL DEFAULT ''")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Nil(t, store.migrate(context.TODO()))
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	store, mock = newMockStore(t, DriverMySQL)
+	mock.ExpectExec("INDEX idx_pipeline_completion").WillReturnError(sql.ErrConnDone)
+	assert.NotNil(t, store.migrate(context.TODO()))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestArchive(t *testing.T) {
+	completion := time.Unix(1640995200, 0)
+	record := &Record{
+		UID:            "uid",
+		Namespace:      "ns",
+		Pipeline:       "pipeline",
+		Name:           "pr",
+		Phase:          v1alpha3.Failed,
+		CompletionTime: &completion,
+		Status:         &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Failed},
+		Trigger:        "webhook",
+		Committer:      "alice",
+	}
+
+	store, mock := newMockStore(t, DriverPostgres)
+	mock.ExpectExec(regexp.QuoteMeta("VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) ON CONFLICT (uid) DO NOTHING")).
+		WithArgs("uid", "ns", "pipeline", "pr", "", "", "Failed", "", int64(0), int64(1640995200000), `{"phase":"Failed"}`,
+			"webhook", "alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.Nil(t, store.Archive(context.TODO(), record))
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	store, mock = newMockStore(t, DriverMySQL)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT IGNORE INTO pipelinerun_history")).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Nil(t, store.Archive(context.TODO(), record))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestList(t *testing.T) {
+	since := time.Unix(1640995200, 0)
+	rows := sqlmock.NewRows([]string{"uid", "namespace", "pipeline", "name", "run_id", "ref_name", "phase", "creator",
+		"start_time", "completion_time", "status", "trigger_type", "committer"}).
+		AddRow("b", "ns", "pipeline", "pr-b", "2", "main", "Failed", "", int64(1640995200000), int64(1640995260000), `{"phase":"Failed"}`,
+			"webhook", "alice").
+		AddRow("a", "ns", "pipeline", "pr-a", "1", "main", "Succeeded", "admin", int64(0), int64(0), "null", "", "")
+
+	store, mock := newMockStore(t, DriverMySQL)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM pipelinerun_history WHERE namespace = ? AND pipeline = ? AND ref_name = ? AND completion_time >= ?")).
+		WithArgs("ns", "pipeline", "main", int64(1640995200000)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY completion_time DESC LIMIT 2 OFFSET 1")).
+		WithArgs("ns", "pipeline", "main", int64(1640995200000)).
+		WillReturnRows(rows)
+
+	records, total, err := store.List(context.TODO(), Query{
+		Namespace: "ns", Pipeline: "pipeline", RefName: "main", Since: &since, Limit: 2, Offset: 1,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, total)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "pr-b", records[0].Name)
+		assert.Equal(t, v1alpha3.Failed, records[0].Status.Phase)
+		assert.Equal(t, int64(1640995260), records[0].CompletionTime.Unix())
+		assert.Equal(t, "webhook", records[0].Trigger)
+		assert.Equal(t, "alice", records[0].Committer)
+		assert.Nil(t, records[1].StartTime)
+		assert.Nil(t, records[1].Status)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGet(t *testing.T) {
+	store, mock := newMockStore(t, DriverPostgres)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE namespace = $1 AND name = $2 ORDER BY completion_time DESC LIMIT 1")).
+		WithArgs("ns", "pr").
+		WillReturnRows(sqlmock.NewRows([]string{"uid"}))
+
+	record, err := store.Get(context.TODO(), "ns", "pr")
+	assert.Nil(t, record)
+	assert.Equal(t, ErrNotFound, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
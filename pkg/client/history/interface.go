@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// ErrNotFound indicates that there is no such record in the history
+var ErrNotFound = errors.New("the record is not found in the history")
+
+// Record is the summary of a completed PipelineRun
+type Record struct {
+	UID            string                      `json:"uid"`
+	Namespace      string                      `json:"namespace"`
+	Pipeline       string                      `json:"pipeline"`
+	Name           string                      `json:"name"`
+	RunID          string                      `json:"runId,omitempty"`
+	RefName        string                      `json:"refName,omitempty"`
+	Phase          v1alpha3.RunPhase           `json:"phase"`
+	Creator        string                      `json:"creator,omitempty"`
+	Trigger        string                      `json:"trigger,omitempty"`
+	Committer      string                      `json:"committer,omitempty"`
+	StartTime      *time.Time                  `json:"startTime,omitempty"`
+	CompletionTime *time.Time                  `json:"completionTime,omitempty"`
+	Status         *v1alpha3.PipelineRunStatus `json:"status,omitempty"`
+}
+
+// Query is the condition of listing records
+type Query struct {
+	Namespace string
+	Pipeline  string
+	RefName   string
+	Phase     v1alpha3.RunPhase
+	// Since and Until filter the records by the completion time
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
+
+// Interface is the storage of the history of PipelineRuns
+type Interface interface {
+	// Archive stores a record, it does nothing if the record was archived already
+	Archive(ctx context.Context, record *Record) error
+	// List returns the records which match the query from the latest one, and the total count of them
+	List(ctx context.Context, query Query) ([]Record, int, error)
+	// Get returns the latest record of a PipelineRun
+	Get(ctx context.Context, namespace, name string) (*Record, error)
+}
+
+// NewRecord creates a record from a PipelineRun
+func NewRecord(pr *v1alpha3.PipelineRun) *Record {
+	record := &Record{
+		UID:       string(pr.UID),
+		Namespace: pr.Namespace,
+		Pipeline:  pr.Labels[v1alpha3.PipelineNameLabelKey],
+		Name:      pr.Name,
+		Phase:     pr.Status.Phase,
+		Creator:   pr.Annotations[v1alpha3.PipelineRunCreatorAnnoKey],
+		Trigger:   pr.Annotations[v1alpha3.PipelineRunTriggerAnnoKey],
+		Committer: getCommitter(pr),
+		Status:    pr.Status.DeepCopy(),
+	}
+	if record.Pipeline == "" && pr.Spec.PipelineRef != nil {
+		record.Pipeline = pr.Spec.PipelineRef.Name
+	}
+	record.RunID, _ = pr.GetPipelineRunID()
+	if pr.Spec.SCM != nil {
+		record.RefName = pr.Spec.SCM.RefName
+	}
+	if pr.Status.StartTime != nil {
+		startTime := pr.Status.StartTime.Time
+		record.StartTime = &startTime
+	}
+	if pr.Status.CompletionTime != nil {
+		completionTime := pr.Status.CompletionTime.Time
+		record.CompletionTime = &completionTime
+	}
+	return record
+}
+
+// getCommitter returns the author of the latest commit in the change set of the Jenkins build
+func getCommitter(pr *v1alpha3.PipelineRun) string {
+	run := &job.PipelineRun{}
+	if err := json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), run); err != nil {
+		return ""
+	}
+	for i := len(run.ChangeSet) - 1; i >= 0; i-- {
+		if author := run.ChangeSet[i].Author; author != nil {
+			if author.FullName != "" {
+				return author.FullName
+			}
+			return author.ID
+		}
+	}
+	return ""
+}
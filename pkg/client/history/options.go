@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+)
+
+const (
+	// DriverPostgres is the driver name of PostgreSQL
+	DriverPostgres = "postgres"
+	// DriverMySQL is the driver name of MySQL
+	DriverMySQL = "mysql"
+)
+
+// Options contains configuration to access the database which stores the history of PipelineRuns
+type Options struct {
+	Driver string `json:"driver,omitempty" yaml:"driver"`
+	DSN    string `json:"dsn,omitempty" yaml:"dsn"`
+	// Retention is how long an archived PipelineRun is kept in the cluster, it is never deleted if it's zero
+	Retention time.Duration `json:"retention,omitempty" yaml:"retention"`
+}
+
+// NewHistoryOptions creates a default disabled Options(empty dsn)
+func NewHistoryOptions() *Options {
+	return &Options{
+		Driver: DriverPostgres,
+	}
+}
+
+// Enabled returns true if the history store is configured
+func (s *Options) Enabled() bool {
+	return s != nil && s.DSN != ""
+}
+
+// Validate check options values
+func (s *Options) Validate() []error {
+	var errors []error
+	if !s.Enabled() {
+		return errors
+	}
+
+	switch s.Driver {
+	case DriverPostgres, DriverMySQL:
+	default:
+		errors = append(errors, fmt.Errorf("unsupported history driver: %q, should be %s or %s",
+			s.Driver, DriverPostgres, DriverMySQL))
+	}
+	if s.Retention < 0 {
+		errors = append(errors, fmt.Errorf("the history retention cannot be negative"))
+	}
+	return errors
+}
+
+// ApplyTo overrides options if it's valid, which dsn is not empty
+func (s *Options) ApplyTo(options *Options) {
+	if s.DSN != "" {
+		reflectutils.Override(options, s)
+	}
+}
+
+// AddFlags add options flags to command line flags,
+// if history-dsn if left empty, following options will be ignored
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.StringVar(&s.DSN, "history-dsn", c.DSN, ""+
+		"Data source name of the database which stores the history of PipelineRuns, if left blank, the following options "+
+		"will be ignored.")
+
+	fs.StringVar(&s.Driver, "history-driver", c.Driver, "Driver of the history database, postgres or mysql.")
+
+	fs.DurationVar(&s.Retention, "history-retention", c.Retention, ""+
+		"How long an archived PipelineRun is kept in the cluster, it is never deleted if it is zero.")
+}
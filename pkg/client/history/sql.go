@@ -0,0 +1,279 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	// register the database drivers
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const tableName = "pipelinerun_history"
+
+const columns = "uid, namespace, pipeline, name, run_id, ref_name, phase, creator, start_time, completion_time, status, " +
+	"trigger_type, committer"
+
+// addedColumns are the columns which were added after the table was released, they are added into the existing tables
+// when migrating
+var addedColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "trigger_type", definition: "VARCHAR(64) NOT NULL DEFAULT ''"},
+	{name: "committer", definition: "VARCHAR(255) NOT NULL DEFAULT ''"},
+}
+
+// dialect holds the differences of SQL between the databases
+type dialect struct {
+	schema []string
+	insert string
+	// placeholder returns the placeholder of the n-th(start from 1) argument
+	placeholder func(n int) string
+}
+
+const createTable = `CREATE TABLE IF NOT EXISTS ` + tableName + ` (
+	uid VARCHAR(64) NOT NULL PRIMARY KEY,
+	namespace VARCHAR(253) NOT NULL,
+	pipeline VARCHAR(253) NOT NULL,
+	name VARCHAR(253) NOT NULL,
+	run_id VARCHAR(64) NOT NULL,
+	ref_name VARCHAR(255) NOT NULL,
+	phase VARCHAR(32) NOT NULL,
+	creator VARCHAR(255) NOT NULL,
+	start_time BIGINT NOT NULL,
+	completion_time BIGINT NOT NULL,
+	status TEXT NOT NULL,
+	trigger_type VARCHAR(64) NOT NULL DEFAULT '',
+	committer VARCHAR(255) NOT NULL DEFAULT ''%s
+)`
+
+var dialects = map[string]dialect{
+	DriverPostgres: {
+		schema: []string{
+			fmt.Sprintf(createTable, ""),
+			`CREATE INDEX IF NOT EXISTS idx_pipeline_completion ON ` + tableName + ` (namespace, pipeline, completion_time)`,
+		},
+		insert: `INSERT INTO ` + tableName + ` (` + columns + `) VALUES (%s) ON CONFLICT (uid) DO NOTHING`,
+		placeholder: func(n int) string {
+			return fmt.Sprintf("$%d", n)
+		},
+	},
+	DriverMySQL: {
+		schema: []string{
+			fmt.Sprintf(createTable, ",\n\tINDEX idx_pipeline_completion (namespace, pipeline, completion_time)"),
+		},
+		insert: `INSERT IGNORE INTO ` + tableName + ` (` + columns + `) VALUES (%s)`,
+		placeholder: func(int) string {
+			return "?"
+		},
+	},
+}
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// NewHistoryClient connects to the database and creates the table if it does not exist
+func NewHistoryClient(options *Options) (Interface, error) {
+	if _, ok := dialects[options.Driver]; !ok {
+		return nil, fmt.Errorf("unsupported history driver: %q", options.Driver)
+	}
+	db, err := sql.Open(options.Driver, options.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	store := newSQLStore(db, options.Driver)
+	if err = store.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func newSQLStore(db *sql.DB, driver string) *sqlStore {
+	return &sqlStore{db: db, dialect: dialects[driver]}
+}
+
+func (s *sqlStore) migrate(ctx context.Context) (err error) {
+	for _, statement := range s.dialect.schema {
+		if _, err = s.db.ExecContext(ctx, statement); err != nil {
+			err = fmt.Errorf("failed to create the history table, error: %v", err)
+			return
+		}
+	}
+	for _, column := range addedColumns {
+		// the column does not exist if it could not be selected
+		if _, err = s.db.ExecContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", column.name, tableName)); err == nil {
+			continue
+		}
+		if _, err = s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+			tableName, column.name, column.definition)); err != nil {
+			err = fmt.Errorf("failed to add the column %s into the history table, error: %v", column.name, err)
+			return
+		}
+	}
+	return
+}
+
+// Archive stores a record, it does nothing if the record was archived already
+func (s *sqlStore) Archive(ctx context.Context, record *Record) (err error) {
+	var status []byte
+	if status, err = json.Marshal(record.Status); err != nil {
+		return
+	}
+
+	args := []interface{}{record.UID, record.Namespace, record.Pipeline, record.Name, record.RunID, record.RefName,
+		string(record.Phase), record.Creator, toMillis(record.StartTime), toMillis(record.CompletionTime), string(status),
+		record.Trigger, record.Committer}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(s.dialect.insert, s.placeholders(1, len(args))), args...)
+	return
+}
+
+// List returns the records which match the query from the latest one, and the total count of them
+func (s *sqlStore) List(ctx context.Context, query Query) (records []Record, total int, err error) {
+	where, args := s.where(query)
+	if err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName+where, args...).Scan(&total); err != nil {
+		return
+	}
+
+	statement := "SELECT " + columns + " FROM " + tableName + where + " ORDER BY completion_time DESC"
+	if query.Limit > 0 {
+		statement += fmt.Sprintf(" LIMIT %d OFFSET %d", query.Limit, query.Offset)
+	}
+
+	var rows *sql.Rows
+	if rows, err = s.db.QueryContext(ctx, statement, args...); err != nil {
+		return
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	records = []Record{}
+	for rows.Next() {
+		var record *Record
+		if record, err = scanRecord(rows); err != nil {
+			return
+		}
+		records = append(records, *record)
+	}
+	err = rows.Err()
+	return
+}
+
+// Get returns the latest record of a PipelineRun
+func (s *sqlStore) Get(ctx context.Context, namespace, name string) (record *Record, err error) {
+	statement := fmt.Sprintf("SELECT %s FROM %s WHERE namespace = %s AND name = %s ORDER BY completion_time DESC LIMIT 1",
+		columns, tableName, s.dialect.placeholder(1), s.dialect.placeholder(2))
+	if record, err = scanRecord(s.db.QueryRowContext(ctx, statement, namespace, name)); err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
+
+func (s *sqlStore) where(query Query) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, s.dialect.placeholder(len(args))))
+	}
+
+	if query.Namespace != "" {
+		add("namespace = %s", query.Namespace)
+	}
+	if query.Pipeline != "" {
+		add("pipeline = %s", query.Pipeline)
+	}
+	if query.RefName != "" {
+		add("ref_name = %s", query.RefName)
+	}
+	if query.Phase != "" {
+		add("phase = %s", string(query.Phase))
+	}
+	if query.Since != nil {
+		add("completion_time >= %s", toMillis(query.Since))
+	}
+	if query.Until != nil {
+		add("completion_time < %s", toMillis(query.Until))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (s *sqlStore) placeholders(from, count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = s.dialect.placeholder(from + i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(row scanner) (record *Record, err error) {
+	var phase, status string
+	var startTime, completionTime int64
+	record = &Record{}
+	if err = row.Scan(&record.UID, &record.Namespace, &record.Pipeline, &record.Name, &record.RunID, &record.RefName,
+		&phase, &record.Creator, &startTime, &completionTime, &status, &record.Trigger, &record.Committer); err != nil {
+		record = nil
+		return
+	}
+
+	record.Phase = v1alpha3.RunPhase(phase)
+	record.StartTime = fromMillis(startTime)
+	record.CompletionTime = fromMillis(completionTime)
+	if status != "" && status != "null" {
+		record.Status = &v1alpha3.PipelineRunStatus{}
+		err = json.Unmarshal([]byte(status), record.Status)
+	}
+	return
+}
+
+func toMillis(t *time.Time) int64 {
+	if t == nil || t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(millis int64) *time.Time {
+	if millis == 0 {
+		return nil
+	}
+	t := time.Unix(0, millis*int64(time.Millisecond)).UTC()
+	return &t
+}
@@ -64,10 +64,44 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha1().S2iRuns().Informer()}, nil
 
 	// Group=devops.kubesphere.io, Version=v1alpha3
+	case v1alpha3.GroupVersion.WithResource("addons"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().Addons().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("addonstrategies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().AddonStrategies().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("artifactroutingpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().ArtifactRoutingPolicies().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("bulkoperations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().BulkOperations().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("clusterfreezewindows"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().ClusterFreezeWindows().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("clustersteptemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().ClusterStepTemplates().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("clustertemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().ClusterTemplates().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("devopsbackups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().DevOpsBackups().Informer()}, nil
 	case v1alpha3.GroupVersion.WithResource("devopsprojects"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().DevOpsProjects().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("freezewindows"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().FreezeWindows().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("gitrepositories"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().GitRepositories().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("jenkinsstatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().JenkinsStatuses().Informer()}, nil
 	case v1alpha3.GroupVersion.WithResource("pipelines"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().Pipelines().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("pipelineenvironments"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().PipelineEnvironments().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("pipelineruns"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().PipelineRuns().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("pipelinesources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().PipelineSources().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("sharedresources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().SharedResources().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("templates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().Templates().Informer()}, nil
+	case v1alpha3.GroupVersion.WithResource("webhooks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha3().Webhooks().Informer()}, nil
 
 	}
 
@@ -0,0 +1,31 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jira
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIssueNotFound means the issue does not exist, or it's not visible to the user
+var ErrIssueNotFound = errors.New("the issue is not found")
+
+// Interface updates the Jira issues
+type Interface interface {
+	// AddComment adds a comment in the Jira wiki markup to an issue
+	AddComment(ctx context.Context, issueKey, body string) error
+}
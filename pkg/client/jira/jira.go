@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+type client struct {
+	server   string
+	username string
+	token    string
+	client   *http.Client
+}
+
+// New creates a client of the Jira REST API v2, it authenticates with the username and the API token (or password)
+func New(server, username, token string) Interface {
+	return &client{
+		server:   strings.TrimSuffix(server, "/"),
+		username: username,
+		token:    token,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// AddComment adds a comment to an issue
+func (c *client) AddComment(ctx context.Context, issueKey, body string) error {
+	return c.post(ctx, fmt.Sprintf("/rest/api/2/issue/%s/comment", url.PathEscape(issueKey)),
+		map[string]string{"body": body})
+}
+
+func (c *client) post(ctx context.Context, api string, payload interface{}) (err error) {
+	var data []byte
+	if data, err = json.Marshal(payload); err != nil {
+		return
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.server+api, bytes.NewReader(data)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.username, c.token)
+
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		err = ErrIssueNotFound
+	case resp.StatusCode >= http.StatusMultipleChoices:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = fmt.Errorf("unexpected status code %d of %s, %s", resp.StatusCode, api, strings.TrimSpace(string(message)))
+	}
+	return
+}
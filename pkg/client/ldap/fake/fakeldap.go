@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+)
+
+// FakeLDAP is a fake LDAP client which holds the groups in memory
+type FakeLDAP struct {
+	Groups map[string][]string
+	Err    error
+}
+
+// NewFakeLDAP creates a fake LDAP client with the groups and their members
+func NewFakeLDAP(groups map[string][]string) *FakeLDAP {
+	return &FakeLDAP{Groups: groups}
+}
+
+// GetGroupMembers returns the members of the existing groups
+func (l *FakeLDAP) GetGroupMembers(ctx context.Context, groups []string) (members map[string][]string, err error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	members = map[string][]string{}
+	for _, group := range groups {
+		if users, ok := l.Groups[group]; ok {
+			members[group] = users
+		}
+	}
+	return
+}
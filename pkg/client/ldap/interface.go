@@ -0,0 +1,25 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldap
+
+import "context"
+
+// Interface searches the groups in LDAP or Active Directory
+type Interface interface {
+	// GetGroupMembers returns the usernames of the members of the groups, the missing groups are not in the result
+	GetGroupMembers(ctx context.Context, groups []string) (map[string][]string, error)
+}
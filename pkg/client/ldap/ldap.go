@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+type ldapClient struct {
+	options *Options
+}
+
+// NewLDAPClient creates a client which connects to the LDAP server for each search
+func NewLDAPClient(options *Options) (Interface, error) {
+	if !options.Enabled() {
+		return nil, fmt.Errorf("the host of LDAP is required")
+	}
+	return &ldapClient{options: options}, nil
+}
+
+func (c *ldapClient) connect() (conn *ldap.Conn, err error) {
+	if c.options.TLS {
+		conn, err = ldap.DialTLS("tcp", c.options.Host, &tls.Config{InsecureSkipVerify: c.options.InsecureSkipVerify})
+	} else {
+		conn, err = ldap.Dial("tcp", c.options.Host)
+	}
+	if err != nil {
+		return
+	}
+	if c.options.BindDN != "" {
+		if err = conn.Bind(c.options.BindDN, c.options.BindPassword); err != nil {
+			conn.Close()
+			conn = nil
+		}
+	}
+	return
+}
+
+// GetGroupMembers searches the groups which match the group filter, then resolves the usernames of their members
+func (c *ldapClient) GetGroupMembers(ctx context.Context, groups []string) (members map[string][]string, err error) {
+	members = map[string][]string{}
+	if len(groups) == 0 {
+		return
+	}
+
+	var conn *ldap.Conn
+	if conn, err = c.connect(); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var result *ldap.SearchResult
+	if result, err = conn.Search(ldap.NewSearchRequest(c.options.GroupSearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		GroupSearchFilter(c.options.GroupFilter, c.options.GroupNameAttribute, groups),
+		[]string{c.options.GroupNameAttribute, c.options.MemberAttribute}, nil)); err != nil {
+		return
+	}
+
+	// the same member might be in many groups
+	usernames := map[string]string{}
+	for _, entry := range result.Entries {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		name := entry.GetAttributeValue(c.options.GroupNameAttribute)
+		for _, value := range entry.GetAttributeValues(c.options.MemberAttribute) {
+			username, ok := usernames[value]
+			if !ok {
+				if username, err = c.getUsername(conn, value); err != nil {
+					return
+				}
+				usernames[value] = username
+			}
+			if username != "" {
+				members[name] = append(members[name], username)
+			}
+		}
+		sort.Strings(members[name])
+	}
+	return
+}
+
+// getUsername returns the username of a member, the member is the username already if it's not a DN
+func (c *ldapClient) getUsername(conn *ldap.Conn, member string) (username string, err error) {
+	if !strings.Contains(member, "=") || c.options.UserNameAttribute == "" {
+		return member, nil
+	}
+
+	var result *ldap.SearchResult
+	if result, err = conn.Search(ldap.NewSearchRequest(member,
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)", []string{c.options.UserNameAttribute}, nil)); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			// the member was deleted
+			err = nil
+		}
+		return
+	}
+	if len(result.Entries) > 0 {
+		username = result.Entries[0].GetAttributeValue(c.options.UserNameAttribute)
+	}
+	return
+}
+
+// GroupSearchFilter returns the filter which matches the groups by their names within the group filter
+func GroupSearchFilter(groupFilter, nameAttribute string, groups []string) string {
+	builder := &strings.Builder{}
+	builder.WriteString("(&")
+	if groupFilter != "" {
+		if !strings.HasPrefix(groupFilter, "(") {
+			groupFilter = "(" + groupFilter + ")"
+		}
+		builder.WriteString(groupFilter)
+	}
+	builder.WriteString("(|")
+	for _, group := range groups {
+		builder.WriteString(fmt.Sprintf("(%s=%s)", nameAttribute, ldap.EscapeFilter(group)))
+	}
+	builder.WriteString("))")
+	return builder.String()
+}
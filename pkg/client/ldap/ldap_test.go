@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *Options
+		errs    int
+	}{{
+		name:    "disabled",
+		options: NewLDAPOptions(),
+	}, {
+		name: "valid",
+		options: func() *Options {
+			options := NewLDAPOptions()
+			options.Host = "ldap.example.com:389"
+			options.GroupSearchBase = "ou=groups,dc=example,dc=org"
+			return options
+		}(),
+	}, {
+		name: "invalid",
+		options: &Options{
+			Host:       "ldap.example.com:389",
+			SyncPeriod: -time.Minute,
+		},
+		errs: 3,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.options.Validate(), tt.errs)
+		})
+	}
+}
+
+func TestGroupSearchFilter(t *testing.T) {
+	assert.Equal(t, "(&(objectClass=groupOfNames)(|(cn=devs)(cn=ops\\2a)))",
+		GroupSearchFilter("(objectClass=groupOfNames)", "cn", []string{"devs", "ops*"}))
+	assert.Equal(t, "(&(objectClass=group)(|(cn=devs)))",
+		GroupSearchFilter("objectClass=group", "cn", []string{"devs"}))
+	assert.Equal(t, "(&(|(cn=devs)))", GroupSearchFilter("", "cn", []string{"devs"}))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+)
+
+// DefaultSyncPeriod is the default period of synchronizing the group members to the DevOpsProjects
+const DefaultSyncPeriod = 10 * time.Minute
+
+// Options contains configuration to search the groups and their members in LDAP or Active Directory
+type Options struct {
+	// Host is the address of the LDAP server, such as ldap.example.com:389
+	Host string `json:"host,omitempty" yaml:"host"`
+	// TLS connects to the server by LDAPS, such as ldap.example.com:636
+	TLS bool `json:"tls,omitempty" yaml:"tls"`
+	// InsecureSkipVerify skips the verification of the server certificate
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify"`
+	// BindDN and BindPassword are the credential to search the directory
+	BindDN       string `json:"bindDN,omitempty" yaml:"bindDN"`
+	BindPassword string `json:"bindPassword,omitempty" yaml:"bindPassword"`
+	// GroupSearchBase is the base DN to search the groups, such as ou=groups,dc=example,dc=org
+	GroupSearchBase string `json:"groupSearchBase,omitempty" yaml:"groupSearchBase"`
+	// GroupFilter limits the groups which can be mapped to the DevOpsProjects, such as (objectClass=groupOfNames)
+	GroupFilter string `json:"groupFilter,omitempty" yaml:"groupFilter"`
+	// GroupNameAttribute is the attribute of the group name, such as cn
+	GroupNameAttribute string `json:"groupNameAttribute,omitempty" yaml:"groupNameAttribute"`
+	// MemberAttribute is the attribute of the group members, such as member or memberUid
+	MemberAttribute string `json:"memberAttribute,omitempty" yaml:"memberAttribute"`
+	// UserNameAttribute is the attribute of the username in the entries of the members, such as uid or sAMAccountName.
+	// It is used only when the members are DNs.
+	UserNameAttribute string `json:"userNameAttribute,omitempty" yaml:"userNameAttribute"`
+	// SyncPeriod is the period of synchronizing the group members to the DevOpsProjects
+	SyncPeriod time.Duration `json:"syncPeriod,omitempty" yaml:"syncPeriod"`
+}
+
+// NewLDAPOptions creates a default disabled Options(empty host)
+func NewLDAPOptions() *Options {
+	return &Options{
+		GroupFilter:        "(objectClass=groupOfNames)",
+		GroupNameAttribute: "cn",
+		MemberAttribute:    "member",
+		UserNameAttribute:  "uid",
+		SyncPeriod:         DefaultSyncPeriod,
+	}
+}
+
+// Enabled returns true if the LDAP server is configured
+func (s *Options) Enabled() bool {
+	return s != nil && s.Host != ""
+}
+
+// Validate check options values
+func (s *Options) Validate() []error {
+	var errors []error
+	if !s.Enabled() {
+		return errors
+	}
+
+	if s.GroupSearchBase == "" {
+		errors = append(errors, fmt.Errorf("the group search base of LDAP is required"))
+	}
+	if s.GroupNameAttribute == "" || s.MemberAttribute == "" {
+		errors = append(errors, fmt.Errorf("the group name and member attributes of LDAP are required"))
+	}
+	if s.SyncPeriod < 0 {
+		errors = append(errors, fmt.Errorf("the sync period of LDAP groups cannot be negative"))
+	}
+	return errors
+}
+
+// ApplyTo overrides options if it's valid, which host is not empty
+func (s *Options) ApplyTo(options *Options) {
+	if s.Host != "" {
+		reflectutils.Override(options, s)
+	}
+}
+
+// AddFlags add options flags to command line flags,
+// if ldap-host if left empty, following options will be ignored
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.StringVar(&s.Host, "ldap-host", c.Host, ""+
+		"Address of the LDAP server which the groups are synchronized from, such as ldap.example.com:389. "+
+		"If left blank, the following options will be ignored.")
+	fs.BoolVar(&s.TLS, "ldap-tls", c.TLS, "Connect to the LDAP server by LDAPS.")
+	fs.BoolVar(&s.InsecureSkipVerify, "ldap-insecure-skip-verify", c.InsecureSkipVerify,
+		"Skip the verification of the certificate of the LDAP server.")
+	fs.StringVar(&s.BindDN, "ldap-bind-dn", c.BindDN, "DN to bind the LDAP server.")
+	fs.StringVar(&s.BindPassword, "ldap-bind-password", c.BindPassword, "Password to bind the LDAP server.")
+	fs.StringVar(&s.GroupSearchBase, "ldap-group-search-base", c.GroupSearchBase,
+		"Base DN to search the groups, such as ou=groups,dc=example,dc=org.")
+	fs.StringVar(&s.GroupFilter, "ldap-group-filter", c.GroupFilter,
+		"Filter of the groups which can be mapped to the DevOpsProjects.")
+	fs.StringVar(&s.GroupNameAttribute, "ldap-group-name-attribute", c.GroupNameAttribute, "Attribute of the group name.")
+	fs.StringVar(&s.MemberAttribute, "ldap-member-attribute", c.MemberAttribute,
+		"Attribute of the group members, such as member or memberUid.")
+	fs.StringVar(&s.UserNameAttribute, "ldap-user-name-attribute", c.UserNameAttribute,
+		"Attribute of the username in the entries of the members, such as uid or sAMAccountName. It is used only when the members are DNs.")
+	fs.DurationVar(&s.SyncPeriod, "ldap-sync-period", c.SyncPeriod,
+		"Period of synchronizing the group members to the DevOpsProjects.")
+}
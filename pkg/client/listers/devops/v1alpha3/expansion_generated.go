@@ -1,5 +1,5 @@
 /*
-Copyright 2020 The KubeSphere Authors.
+Copyright 2022 The KubeSphere Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -18,10 +18,70 @@ limitations under the License.
 
 package v1alpha3
 
+// AddonListerExpansion allows custom methods to be added to
+// AddonLister.
+type AddonListerExpansion interface{}
+
+// AddonNamespaceListerExpansion allows custom methods to be added to
+// AddonNamespaceLister.
+type AddonNamespaceListerExpansion interface{}
+
+// AddonStrategyListerExpansion allows custom methods to be added to
+// AddonStrategyLister.
+type AddonStrategyListerExpansion interface{}
+
+// ArtifactRoutingPolicyListerExpansion allows custom methods to be added to
+// ArtifactRoutingPolicyLister.
+type ArtifactRoutingPolicyListerExpansion interface{}
+
+// BulkOperationListerExpansion allows custom methods to be added to
+// BulkOperationLister.
+type BulkOperationListerExpansion interface{}
+
+// BulkOperationNamespaceListerExpansion allows custom methods to be added to
+// BulkOperationNamespaceLister.
+type BulkOperationNamespaceListerExpansion interface{}
+
+// ClusterFreezeWindowListerExpansion allows custom methods to be added to
+// ClusterFreezeWindowLister.
+type ClusterFreezeWindowListerExpansion interface{}
+
+// ClusterStepTemplateListerExpansion allows custom methods to be added to
+// ClusterStepTemplateLister.
+type ClusterStepTemplateListerExpansion interface{}
+
+// ClusterTemplateListerExpansion allows custom methods to be added to
+// ClusterTemplateLister.
+type ClusterTemplateListerExpansion interface{}
+
+// DevOpsBackupListerExpansion allows custom methods to be added to
+// DevOpsBackupLister.
+type DevOpsBackupListerExpansion interface{}
+
 // DevOpsProjectListerExpansion allows custom methods to be added to
 // DevOpsProjectLister.
 type DevOpsProjectListerExpansion interface{}
 
+// FreezeWindowListerExpansion allows custom methods to be added to
+// FreezeWindowLister.
+type FreezeWindowListerExpansion interface{}
+
+// FreezeWindowNamespaceListerExpansion allows custom methods to be added to
+// FreezeWindowNamespaceLister.
+type FreezeWindowNamespaceListerExpansion interface{}
+
+// GitRepositoryListerExpansion allows custom methods to be added to
+// GitRepositoryLister.
+type GitRepositoryListerExpansion interface{}
+
+// GitRepositoryNamespaceListerExpansion allows custom methods to be added to
+// GitRepositoryNamespaceLister.
+type GitRepositoryNamespaceListerExpansion interface{}
+
+// JenkinsStatusListerExpansion allows custom methods to be added to
+// JenkinsStatusLister.
+type JenkinsStatusListerExpansion interface{}
+
 // PipelineListerExpansion allows custom methods to be added to
 // PipelineLister.
 type PipelineListerExpansion interface{}
@@ -29,3 +89,51 @@ type PipelineListerExpansion interface{}
 // PipelineNamespaceListerExpansion allows custom methods to be added to
 // PipelineNamespaceLister.
 type PipelineNamespaceListerExpansion interface{}
+
+// PipelineEnvironmentListerExpansion allows custom methods to be added to
+// PipelineEnvironmentLister.
+type PipelineEnvironmentListerExpansion interface{}
+
+// PipelineEnvironmentNamespaceListerExpansion allows custom methods to be added to
+// PipelineEnvironmentNamespaceLister.
+type PipelineEnvironmentNamespaceListerExpansion interface{}
+
+// PipelineRunListerExpansion allows custom methods to be added to
+// PipelineRunLister.
+type PipelineRunListerExpansion interface{}
+
+// PipelineRunNamespaceListerExpansion allows custom methods to be added to
+// PipelineRunNamespaceLister.
+type PipelineRunNamespaceListerExpansion interface{}
+
+// PipelineSourceListerExpansion allows custom methods to be added to
+// PipelineSourceLister.
+type PipelineSourceListerExpansion interface{}
+
+// PipelineSourceNamespaceListerExpansion allows custom methods to be added to
+// PipelineSourceNamespaceLister.
+type PipelineSourceNamespaceListerExpansion interface{}
+
+// SharedResourceListerExpansion allows custom methods to be added to
+// SharedResourceLister.
+type SharedResourceListerExpansion interface{}
+
+// SharedResourceNamespaceListerExpansion allows custom methods to be added to
+// SharedResourceNamespaceLister.
+type SharedResourceNamespaceListerExpansion interface{}
+
+// TemplateListerExpansion allows custom methods to be added to
+// TemplateLister.
+type TemplateListerExpansion interface{}
+
+// TemplateNamespaceListerExpansion allows custom methods to be added to
+// TemplateNamespaceLister.
+type TemplateNamespaceListerExpansion interface{}
+
+// WebhookListerExpansion allows custom methods to be added to
+// WebhookLister.
+type WebhookListerExpansion interface{}
+
+// WebhookNamespaceListerExpansion allows custom methods to be added to
+// WebhookNamespaceLister.
+type WebhookNamespaceListerExpansion interface{}
@@ -23,10 +23,13 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
+	s3client "kubesphere.io/devops/pkg/client/s3"
 )
 
 type FakeS3 struct {
 	Storage map[string]*Object
+	// Buckets are the other buckets than the default one, they're created when being accessed
+	Buckets map[string]*FakeS3
 }
 
 func NewFakeS3(objects ...*Object) *FakeS3 {
@@ -41,13 +44,16 @@ type Object struct {
 	Key      string
 	FileName string
 	Body     io.Reader
+	// KMSKeyID is the KMS key which the object is encrypted by
+	KMSKeyID string
 }
 
-func (s *FakeS3) Upload(key, fileName string, body io.Reader) error {
+func (s *FakeS3) Upload(key, fileName string, body io.Reader, options ...s3client.UploadOption) error {
 	s.Storage[key] = &Object{
 		Key:      key,
 		FileName: fileName,
 		Body:     body,
+		KMSKeyID: s3client.NewUploadOptions("", options...).KMSKeyID,
 	}
 	return nil
 }
@@ -64,6 +70,17 @@ func (s *FakeS3) Delete(key string) error {
 	return nil
 }
 
+// InBucket returns the fake storage of a bucket
+func (s *FakeS3) InBucket(bucket string) s3client.Interface {
+	if s.Buckets == nil {
+		s.Buckets = map[string]*FakeS3{}
+	}
+	if _, ok := s.Buckets[bucket]; !ok {
+		s.Buckets[bucket] = NewFakeS3()
+	}
+	return s.Buckets[bucket]
+}
+
 func (s *FakeS3) Read(key string) ([]byte, error) {
 	if o, ok := s.Storage[key]; ok && o.Body != nil {
 		data, err := ioutil.ReadAll(o.Body)
@@ -19,6 +19,8 @@ package fake
 import (
 	"fmt"
 	"testing"
+
+	s3client "kubesphere.io/devops/pkg/client/s3"
 )
 
 func TestFakeS3(t *testing.T) {
@@ -66,3 +68,19 @@ func TestFakeS3(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestFakeS3WithKMSKey(t *testing.T) {
+	s3 := NewFakeS3()
+	if err := s3.Upload("plain", "plain", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s3.Upload("encrypted", "encrypted", nil, s3client.WithKMSKey("key")); err != nil {
+		t.Fatal(err)
+	}
+	if s3.Storage["plain"].KMSKeyID != "" {
+		t.Fatal("the object should not be encrypted")
+	}
+	if s3.Storage["encrypted"].KMSKeyID != "key" {
+		t.Fatal("the object should be encrypted by the KMS key")
+	}
+}
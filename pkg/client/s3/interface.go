@@ -17,6 +17,7 @@ limitations under the License.
 package s3
 
 import (
+	"fmt"
 	"io"
 )
 
@@ -25,10 +26,75 @@ type Interface interface {
 	Read(key string) ([]byte, error)
 
 	// Upload uploads a object to storage and returns object location if succeeded
-	Upload(key, fileName string, body io.Reader) error
+	Upload(key, fileName string, body io.Reader, options ...UploadOption) error
 
 	GetDownloadURL(key string, fileName string) (string, error)
 
 	// Delete deletes an object by its key
 	Delete(key string) error
 }
+
+// BucketSwitcher is implemented by the storages which could access the other buckets with the same credentials
+type BucketSwitcher interface {
+	// InBucket returns a storage which accesses the objects in the bucket
+	InBucket(bucket string) Interface
+}
+
+// InBucket returns the storage of a bucket, it's the storage itself if the bucket is empty. It returns an error
+// instead of falling back to the default bucket if the storage could not switch the bucket.
+func InBucket(storage Interface, bucket string) (Interface, error) {
+	if bucket == "" {
+		return storage, nil
+	}
+	switcher, ok := storage.(BucketSwitcher)
+	if !ok {
+		return nil, fmt.Errorf("the object storage does not support the bucket %q", bucket)
+	}
+	return switcher.InBucket(bucket), nil
+}
+
+// UploadOptions are the options of uploading an object
+type UploadOptions struct {
+	// KMSKeyID is the AWS KMS key which the object is encrypted by on the server side
+	KMSKeyID string
+}
+
+// UploadOption sets an option of uploading an object
+type UploadOption func(*UploadOptions)
+
+// WithKMSKey encrypts the object by the KMS key, it overrides the default key of the client.
+// The default key is used if the keyID is empty.
+func WithKMSKey(keyID string) UploadOption {
+	return func(options *UploadOptions) {
+		if keyID != "" {
+			options.KMSKeyID = keyID
+		}
+	}
+}
+
+// NewUploadOptions returns the upload options which are set by the option functions
+func NewUploadOptions(defaultKMSKeyID string, options ...UploadOption) *UploadOptions {
+	uploadOptions := &UploadOptions{KMSKeyID: defaultKMSKeyID}
+	for _, option := range options {
+		option(uploadOptions)
+	}
+	return uploadOptions
+}
+
+// WithUploadOptions returns a storage which uploads all the objects with the options, the options of an upload take
+// precedence over them. The storage itself is returned if there are no options.
+func WithUploadOptions(storage Interface, options ...UploadOption) Interface {
+	if len(options) == 0 {
+		return storage
+	}
+	return &storageWithOptions{Interface: storage, options: options}
+}
+
+type storageWithOptions struct {
+	Interface
+	options []UploadOption
+}
+
+func (s *storageWithOptions) Upload(key, fileName string, body io.Reader, options ...UploadOption) error {
+	return s.Interface.Upload(key, fileName, body, append(append([]UploadOption{}, s.options...), options...)...)
+}
@@ -32,6 +32,9 @@ type Options struct {
 	SecretAccessKey string `json:"secretAccessKey,omitempty" yaml:"secretAccessKey"`
 	SessionToken    string `json:"sessionToken,omitempty" yaml:"sessionToken"`
 	Bucket          string `json:"bucket,omitempty" yaml:"bucket"`
+	// KMSKeyID is the default AWS KMS key which the objects are encrypted by, the objects of a DevOpsProject are
+	// encrypted by its own key if it has one
+	KMSKeyID string `json:"kmsKeyID,omitempty" yaml:"kmsKeyID"`
 }
 
 // NewS3Options creates a default disabled Options(empty endpoint)
@@ -80,6 +83,8 @@ func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
 
 	fs.StringVar(&s.Bucket, "s3-bucket", c.Bucket, "bucket name of s2i s3")
 
+	fs.StringVar(&s.KMSKeyID, "s3-kms-key-id", c.KMSKeyID, "default KMS key to encrypt the objects on the server side, "+
+		"the objects are not encrypted by KMS if it's empty")
 	fs.BoolVar(&s.DisableSSL, "s3-disable-SSL", c.DisableSSL, "disable ssl")
 
 	fs.BoolVar(&s.ForcePathStyle, "s3-force-path-style", c.ForcePathStyle, "force path style")
@@ -34,20 +34,31 @@ type Client struct {
 	s3Client  *s3.S3
 	s3Session *session.Session
 	bucket    string
+	kmsKeyID  string
 }
 
-func (s *Client) Upload(key, fileName string, body io.Reader) error {
+func (s *Client) Upload(key, fileName string, body io.Reader, options ...UploadOption) error {
 	uploader := s3manager.NewUploader(s.s3Session, func(uploader *s3manager.Uploader) {
 		uploader.PartSize = 5 * bytefmt.MEGABYTE
 		uploader.LeavePartsOnError = true
 	})
-	_, err := uploader.Upload(&s3manager.UploadInput{
+	_, err := uploader.Upload(s.newUploadInput(key, fileName, body, options...))
+	return err
+}
+
+// newUploadInput returns the input of uploading an object, the object is encrypted by the KMS key if there is one
+func (s *Client) newUploadInput(key, fileName string, body io.Reader, options ...UploadOption) *s3manager.UploadInput {
+	input := &s3manager.UploadInput{
 		Bucket:             aws.String(s.bucket),
 		Key:                aws.String(key),
 		Body:               body,
 		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=\"%s\"", fileName)),
-	})
-	return err
+	}
+	if uploadOptions := NewUploadOptions(s.kmsKeyID, options...); uploadOptions.KMSKeyID != "" {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSKeyId = aws.String(uploadOptions.KMSKeyID)
+	}
+	return input
 }
 
 func (s *Client) Read(key string) ([]byte, error) {
@@ -88,6 +99,13 @@ func (s *Client) Delete(key string) error {
 	return nil
 }
 
+// InBucket returns a client of the bucket which shares the session and the default KMS key with this one
+func (s *Client) InBucket(bucket string) Interface {
+	c := *s
+	c.bucket = bucket
+	return &c
+}
+
 func NewS3Client(options *Options) (Interface, error) {
 	cred := credentials.NewStaticCredentials(options.AccessKeyID, options.SecretAccessKey, options.SessionToken)
 
@@ -110,6 +128,7 @@ func NewS3Client(options *Options) (Interface, error) {
 	c.s3Client = s3.New(s)
 	c.s3Session = s
 	c.bucket = options.Bucket
+	c.kmsKeyID = options.KMSKeyID
 
 	return &c, nil
 }
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUploadInput(t *testing.T) {
+	tests := []struct {
+		name            string
+		defaultKMSKeyID string
+		options         []UploadOption
+		expectKMSKeyID  string
+	}{{
+		name: "no KMS key",
+	}, {
+		name:            "default KMS key",
+		defaultKMSKeyID: "default-key",
+		expectKMSKeyID:  "default-key",
+	}, {
+		name:            "the KMS key of the upload overrides the default one",
+		defaultKMSKeyID: "default-key",
+		options:         []UploadOption{WithKMSKey("project-key")},
+		expectKMSKeyID:  "project-key",
+	}, {
+		name:            "an empty KMS key of the upload does not override the default one",
+		defaultKMSKeyID: "default-key",
+		options:         []UploadOption{WithKMSKey("")},
+		expectKMSKeyID:  "default-key",
+	}, {
+		name:           "the KMS key of the upload without a default one",
+		options:        []UploadOption{WithKMSKey("project-key")},
+		expectKMSKeyID: "project-key",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{bucket: "bucket", kmsKeyID: tt.defaultKMSKeyID}
+			input := client.newUploadInput("key", "file.jar", nil, tt.options...)
+			assert.Equal(t, "bucket", aws.StringValue(input.Bucket))
+			assert.Equal(t, "key", aws.StringValue(input.Key))
+			assert.Equal(t, `attachment; filename="file.jar"`, aws.StringValue(input.ContentDisposition))
+			if tt.expectKMSKeyID == "" {
+				assert.Nil(t, input.ServerSideEncryption)
+				assert.Nil(t, input.SSEKMSKeyId)
+				return
+			}
+			assert.Equal(t, "aws:kms", aws.StringValue(input.ServerSideEncryption))
+			assert.Equal(t, tt.expectKMSKeyID, aws.StringValue(input.SSEKMSKeyId))
+		})
+	}
+}
+
+type storageWithoutBuckets struct {
+	Interface
+}
+
+func TestInBucket(t *testing.T) {
+	client := &Client{bucket: "default", kmsKeyID: "default-key"}
+
+	storage, err := InBucket(client, "")
+	assert.Nil(t, err)
+	assert.Same(t, client, storage)
+
+	storage, err = InBucket(client, "tenant-a")
+	assert.Nil(t, err)
+	input := storage.(*Client).newUploadInput("key", "file.jar", nil)
+	assert.Equal(t, "tenant-a", aws.StringValue(input.Bucket))
+	assert.Equal(t, "default-key", aws.StringValue(input.SSEKMSKeyId))
+	assert.Equal(t, "default", client.bucket)
+
+	_, err = InBucket(storageWithoutBuckets{}, "tenant-a")
+	assert.NotNil(t, err)
+	storage, err = InBucket(storageWithoutBuckets{}, "")
+	assert.Nil(t, err)
+	assert.NotNil(t, storage)
+}
+
+type uploadRecorder struct {
+	Interface
+	options *UploadOptions
+}
+
+func (r *uploadRecorder) Upload(key, fileName string, body io.Reader, options ...UploadOption) error {
+	r.options = NewUploadOptions("", options...)
+	return nil
+}
+
+func TestWithUploadOptions(t *testing.T) {
+	recorder := &uploadRecorder{}
+	assert.Same(t, recorder, WithUploadOptions(recorder))
+
+	storage := WithUploadOptions(recorder, WithKMSKey("project-key"))
+	assert.Nil(t, storage.Upload("key", "file.jar", nil))
+	assert.Equal(t, "project-key", recorder.options.KMSKeyID)
+
+	assert.Nil(t, storage.Upload("key", "file.jar", nil, WithKMSKey("other-key")))
+	assert.Equal(t, "other-key", recorder.options.KMSKeyID)
+}
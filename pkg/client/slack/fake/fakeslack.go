@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"strconv"
+
+	"kubesphere.io/devops/pkg/client/slack"
+)
+
+// FakeSlack is a fake Slack client which keeps the messages in memory
+type FakeSlack struct {
+	Messages   []*slack.Message
+	Updates    []*slack.Message
+	Ephemerals []*slack.Message
+	// Envelopes are delivered to the handler of Listen in order
+	Envelopes []*slack.Envelope
+	Err       error
+}
+
+// NewFakeSlack creates a fake Slack client
+func NewFakeSlack() *FakeSlack {
+	return &FakeSlack{}
+}
+
+// PostMessage keeps the message unless there is an error, the timestamp is the number of the posted messages
+func (s *FakeSlack) PostMessage(ctx context.Context, message *slack.Message) (string, error) {
+	if s.Err != nil {
+		return "", s.Err
+	}
+	s.Messages = append(s.Messages, message)
+	return strconv.Itoa(len(s.Messages)), nil
+}
+
+// UpdateMessage keeps the updated message unless there is an error
+func (s *FakeSlack) UpdateMessage(ctx context.Context, message *slack.Message) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Updates = append(s.Updates, message)
+	return nil
+}
+
+// PostEphemeral keeps the ephemeral message unless there is an error
+func (s *FakeSlack) PostEphemeral(ctx context.Context, message *slack.Message) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Ephemerals = append(s.Ephemerals, message)
+	return nil
+}
+
+// Listen delivers the envelopes to the handler
+func (s *FakeSlack) Listen(ctx context.Context, handle func(ctx context.Context, envelope *slack.Envelope)) error {
+	for _, envelope := range s.Envelopes {
+		handle(ctx, envelope)
+	}
+	return s.Err
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Message is a message of Slack, see also https://api.slack.com/reference/block-kit/blocks
+type Message struct {
+	Channel string        `json:"channel"`
+	Text    string        `json:"text"`
+	Blocks  []interface{} `json:"blocks,omitempty"`
+	// TS is the timestamp of the message to update
+	TS string `json:"ts,omitempty"`
+	// User is the only user who sees an ephemeral message
+	User string `json:"user,omitempty"`
+}
+
+// Envelope is an event which is received in the Socket Mode, such as the interactions with the buttons.
+// See also https://api.slack.com/apis/connections/socket-implement
+type Envelope struct {
+	EnvelopeID string          `json:"envelope_id,omitempty"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// EnvelopeTypeInteractive is the type of the envelopes of the interactions, such as clicking buttons
+const EnvelopeTypeInteractive = "interactive"
+
+// Interface sends the messages by the bot token, and receives the events in the Socket Mode by the app-level token
+type Interface interface {
+	// PostMessage posts a message to a channel, it returns the timestamp of the message
+	PostMessage(ctx context.Context, message *Message) (ts string, err error)
+	// UpdateMessage updates the message identified by the channel and the timestamp
+	UpdateMessage(ctx context.Context, message *Message) error
+	// PostEphemeral posts a message which is visible to the user only
+	PostEphemeral(ctx context.Context, message *Message) error
+	// Listen connects to Slack in the Socket Mode, then handles the events until the context is done or the
+	// connection is closed. The events are acknowledged before handling.
+	Listen(ctx context.Context, handle func(ctx context.Context, envelope *Envelope)) error
+}
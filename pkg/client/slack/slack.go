@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// DefaultServer is the address of the Web API of Slack
+	DefaultServer  = "https://slack.com/api"
+	requestTimeout = 30 * time.Second
+)
+
+type client struct {
+	server   string
+	botToken string
+	appToken string
+	client   *http.Client
+}
+
+// New creates a client of Slack. The bot token (xoxb-) posts the messages, and the app-level token (xapp-) with the
+// connections:write scope opens the connections of the Socket Mode.
+func New(server, botToken, appToken string) Interface {
+	return &client{
+		server:   strings.TrimSuffix(server, "/"),
+		botToken: botToken,
+		appToken: appToken,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// response is the common part of the responses of the Web API
+type response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	TS    string `json:"ts,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// PostMessage posts a message by chat.postMessage
+func (c *client) PostMessage(ctx context.Context, message *Message) (ts string, err error) {
+	var resp *response
+	if resp, err = c.call(ctx, "chat.postMessage", c.botToken, message); err == nil {
+		ts = resp.TS
+	}
+	return
+}
+
+// UpdateMessage updates a message by chat.update
+func (c *client) UpdateMessage(ctx context.Context, message *Message) (err error) {
+	_, err = c.call(ctx, "chat.update", c.botToken, message)
+	return
+}
+
+// PostEphemeral posts an ephemeral message by chat.postEphemeral
+func (c *client) PostEphemeral(ctx context.Context, message *Message) (err error) {
+	_, err = c.call(ctx, "chat.postEphemeral", c.botToken, message)
+	return
+}
+
+// Listen opens a connection by apps.connections.open, the connection is closed once the context is done.
+// It returns nil when Slack asks to disconnect, the caller should reconnect then.
+func (c *client) Listen(ctx context.Context, handle func(ctx context.Context, envelope *Envelope)) (err error) {
+	var resp *response
+	if resp, err = c.call(ctx, "apps.connections.open", c.appToken, nil); err != nil {
+		return
+	}
+	var config *websocket.Config
+	if config, err = websocket.NewConfig(resp.URL, c.server); err != nil {
+		return
+	}
+	var conn *websocket.Conn
+	if conn, err = websocket.DialConfig(config); err != nil {
+		return
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = conn.Close()
+	}()
+
+	for {
+		envelope := &Envelope{}
+		if err = websocket.JSON.Receive(conn, envelope); err != nil {
+			if ctx.Err() != nil {
+				err = nil
+			}
+			return
+		}
+		switch envelope.Type {
+		case "hello":
+			continue
+		case "disconnect":
+			return
+		}
+		if envelope.EnvelopeID != "" {
+			if err = websocket.JSON.Send(conn, map[string]string{"envelope_id": envelope.EnvelopeID}); err != nil {
+				return
+			}
+		}
+		handle(ctx, envelope)
+	}
+}
+
+func (c *client) call(ctx context.Context, method, token string, payload interface{}) (resp *response, err error) {
+	var data []byte
+	if payload != nil {
+		if data, err = json.Marshal(payload); err != nil {
+			return
+		}
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.server+"/"+method, bytes.NewReader(data)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var httpResp *http.Response
+	if httpResp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+	if httpResp.StatusCode >= http.StatusMultipleChoices {
+		err = fmt.Errorf("unexpected status code %d of %s", httpResp.StatusCode, method)
+		return
+	}
+	resp = &response{}
+	if err = json.NewDecoder(httpResp.Body).Decode(resp); err == nil && !resp.OK {
+		err = fmt.Errorf("failed to call %s, error: %s", method, resp.Error)
+	}
+	return
+}
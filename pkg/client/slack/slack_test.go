@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestPostMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := &Message{}
+		_ = json.NewDecoder(r.Body).Decode(message)
+		switch {
+		case r.Header.Get("Authorization") != "Bearer xoxb-token":
+			_, _ = w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		case r.URL.Path == "/chat.postMessage" && message.Channel == "C1":
+			_, _ = w.Write([]byte(`{"ok":true,"ts":"1.2"}`))
+		case r.URL.Path == "/chat.update" && message.TS == "1.2":
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "xoxb-token", "xapp-token")
+	ts, err := c.PostMessage(context.Background(), &Message{Channel: "C1", Text: "hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2", ts)
+	assert.Nil(t, c.UpdateMessage(context.Background(), &Message{Channel: "C1", TS: "1.2", Text: "updated"}))
+
+	_, err = c.PostMessage(context.Background(), &Message{Channel: "C2", Text: "hello"})
+	assert.EqualError(t, err, "failed to call chat.postMessage, error: channel_not_found")
+	err = New(server.URL, "invalid", "").PostEphemeral(context.Background(), &Message{Channel: "C1", User: "U1"})
+	assert.EqualError(t, err, "failed to call chat.postEphemeral, error: invalid_auth")
+}
+
+func TestListen(t *testing.T) {
+	acks := make(chan string, 1)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xapp-token" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"url":"ws` + strings.TrimPrefix(server.URL, "http") + `/socket"}`))
+	})
+	mux.Handle("/socket", websocket.Handler(func(conn *websocket.Conn) {
+		_ = websocket.JSON.Send(conn, &Envelope{Type: "hello"})
+		_ = websocket.JSON.Send(conn, &Envelope{EnvelopeID: "1", Type: EnvelopeTypeInteractive,
+			Payload: json.RawMessage(`{"type":"block_actions"}`)})
+		ack := map[string]string{}
+		_ = websocket.JSON.Receive(conn, &ack)
+		acks <- ack["envelope_id"]
+		_ = websocket.JSON.Send(conn, &Envelope{Type: "disconnect"})
+	}))
+
+	var envelopes []*Envelope
+	err := New(server.URL, "", "xapp-token").Listen(context.Background(), func(ctx context.Context, envelope *Envelope) {
+		envelopes = append(envelopes, envelope)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", <-acks)
+	if assert.Len(t, envelopes, 1) {
+		assert.Equal(t, EnvelopeTypeInteractive, envelopes[0].Type)
+		assert.JSONEq(t, `{"type":"block_actions"}`, string(envelopes[0].Payload))
+	}
+
+	err = New(server.URL, "", "invalid").Listen(context.Background(), nil)
+	assert.EqualError(t, err, "failed to call apps.connections.open, error: invalid_auth")
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"kubesphere.io/devops/pkg/client/smtp"
+)
+
+// FakeSMTP is a fake SMTP client which keeps the sent emails in memory
+type FakeSMTP struct {
+	Messages []*smtp.Message
+	Err      error
+}
+
+// NewFakeSMTP creates a fake SMTP client
+func NewFakeSMTP() *FakeSMTP {
+	return &FakeSMTP{}
+}
+
+// Send keeps the email unless there is an error
+func (s *FakeSMTP) Send(ctx context.Context, message *smtp.Message) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Messages = append(s.Messages, message)
+	return nil
+}
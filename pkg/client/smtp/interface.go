@@ -0,0 +1,32 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import "context"
+
+// Message is an email
+type Message struct {
+	To      []string
+	Subject string
+	// HTML is the body of the email in HTML
+	HTML string
+}
+
+// Interface sends emails
+type Interface interface {
+	Send(ctx context.Context, message *Message) error
+}
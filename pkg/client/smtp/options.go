@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+)
+
+// DefaultTokenExpiration is the default expiration of the links in the approval emails
+const DefaultTokenExpiration = 24 * time.Hour
+
+// Options contains configuration to send the approval emails by an SMTP server
+type Options struct {
+	// Host is the address of the SMTP server, such as smtp.example.com:587
+	Host string `json:"host,omitempty" yaml:"host"`
+	// Username and Password are the credential to authenticate with the server, the authentication is skipped if
+	// the username is empty
+	Username string `json:"username,omitempty" yaml:"username"`
+	Password string `json:"password,omitempty" yaml:"password"`
+	// From is the sender address of the emails, such as devops@example.com
+	From string `json:"from,omitempty" yaml:"from"`
+	// ExternalURL is the address of the apiserver which the approvers can access, such as https://devops.example.com.
+	// The approve and reject links in the emails point to it.
+	ExternalURL string `json:"externalURL,omitempty" yaml:"externalURL"`
+	// TokenExpiration is how long the approve and reject links in the emails are valid
+	TokenExpiration time.Duration `json:"tokenExpiration,omitempty" yaml:"tokenExpiration"`
+}
+
+// NewSMTPOptions creates a default disabled Options(empty host)
+func NewSMTPOptions() *Options {
+	return &Options{
+		TokenExpiration: DefaultTokenExpiration,
+	}
+}
+
+// Enabled returns true if the SMTP server is configured
+func (s *Options) Enabled() bool {
+	return s != nil && s.Host != ""
+}
+
+// Validate check options values
+func (s *Options) Validate() []error {
+	var errors []error
+	if !s.Enabled() {
+		return errors
+	}
+
+	if _, _, err := net.SplitHostPort(s.Host); err != nil {
+		errors = append(errors, fmt.Errorf("invalid SMTP host %q, it should be like smtp.example.com:587", s.Host))
+	}
+	if s.From == "" {
+		errors = append(errors, fmt.Errorf("the sender address of SMTP is required"))
+	}
+	if externalURL, err := url.Parse(s.ExternalURL); err != nil || externalURL.Scheme == "" || externalURL.Host == "" {
+		errors = append(errors, fmt.Errorf("invalid external URL %q of the approval emails", s.ExternalURL))
+	}
+	if s.TokenExpiration <= 0 {
+		errors = append(errors, fmt.Errorf("the token expiration of the approval emails should be positive"))
+	}
+	return errors
+}
+
+// ApplyTo overrides options if it's valid, which host is not empty
+func (s *Options) ApplyTo(options *Options) {
+	if s.Host != "" {
+		reflectutils.Override(options, s)
+	}
+}
+
+// AddFlags add options flags to command line flags,
+// if smtp-host if left empty, following options will be ignored
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.StringVar(&s.Host, "smtp-host", c.Host, ""+
+		"Address of the SMTP server which sends the approval emails, such as smtp.example.com:587. "+
+		"If left blank, the following options will be ignored.")
+	fs.StringVar(&s.Username, "smtp-username", c.Username, "Username to authenticate with the SMTP server.")
+	fs.StringVar(&s.Password, "smtp-password", c.Password, "Password to authenticate with the SMTP server.")
+	fs.StringVar(&s.From, "smtp-from", c.From, "Sender address of the approval emails.")
+	fs.StringVar(&s.ExternalURL, "smtp-external-url", c.ExternalURL,
+		"Address of the apiserver which the approvers can access, the links in the approval emails point to it.")
+	fs.DurationVar(&s.TokenExpiration, "smtp-token-expiration", c.TokenExpiration,
+		"How long the approve and reject links in the approval emails are valid.")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type smtpClient struct {
+	options *Options
+	// sendMail is smtp.SendMail, it's replaceable for testing
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPClient creates a client which connects to the SMTP server for each email.
+// The connection is upgraded by STARTTLS if the server supports it.
+func NewSMTPClient(options *Options) (Interface, error) {
+	if !options.Enabled() {
+		return nil, fmt.Errorf("the host of SMTP is required")
+	}
+	return &smtpClient{options: options, sendMail: smtp.SendMail}, nil
+}
+
+// Send sends the email to all the recipients at once
+func (c *smtpClient) Send(ctx context.Context, message *Message) (err error) {
+	var body []byte
+	if body, err = buildMessage(c.options.From, message, time.Now()); err != nil {
+		return
+	}
+	var auth smtp.Auth
+	if c.options.Username != "" {
+		host, _, _ := net.SplitHostPort(c.options.Host)
+		auth = smtp.PlainAuth("", c.options.Username, c.options.Password, host)
+	}
+	return c.sendMail(c.options.Host, auth, c.options.From, message.To, body)
+}
+
+// buildMessage builds the headers and body of an HTML email, the addresses are checked to avoid header injection
+func buildMessage(from string, message *Message, now time.Time) ([]byte, error) {
+	if len(message.To) == 0 {
+		return nil, fmt.Errorf("the recipients of the email are required")
+	}
+	for _, address := range append([]string{from}, message.To...) {
+		if _, err := mail.ParseAddress(address); err != nil || strings.ContainsAny(address, "\r\n") {
+			return nil, fmt.Errorf("invalid email address %q", address)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(message.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(message.HTML)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *Options
+		errs    int
+	}{{
+		name:    "disabled",
+		options: NewSMTPOptions(),
+	}, {
+		name: "valid",
+		options: func() *Options {
+			options := NewSMTPOptions()
+			options.Host = "smtp.example.com:587"
+			options.From = "devops@example.com"
+			options.ExternalURL = "https://devops.example.com"
+			return options
+		}(),
+	}, {
+		name: "invalid",
+		options: &Options{
+			Host:        "smtp.example.com",
+			ExternalURL: "devops.example.com",
+		},
+		errs: 4,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.options.Validate(), tt.errs)
+		})
+	}
+}
+
+func Test_buildMessage(t *testing.T) {
+	now := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	body, err := buildMessage("devops@example.com", &Message{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Approval required",
+		HTML:    "<p>hello</p>",
+	}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "From: devops@example.com\r\n"+
+		"To: alice@example.com, bob@example.com\r\n"+
+		"Subject: Approval required\r\n"+
+		"Date: Sat, 01 Oct 2022 08:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=UTF-8\r\n"+
+		"\r\n"+
+		"<p>hello</p>", string(body))
+
+	_, err = buildMessage("devops@example.com", &Message{Subject: "no recipients"}, now)
+	assert.NotNil(t, err)
+	_, err = buildMessage("devops@example.com", &Message{To: []string{"alice@example.com\r\nBcc: eve@example.com"}}, now)
+	assert.NotNil(t, err)
+}
+
+func TestSMTPClient_Send(t *testing.T) {
+	_, err := NewSMTPClient(NewSMTPOptions())
+	assert.NotNil(t, err)
+
+	options := &Options{Host: "smtp.example.com:587", Username: "devops", Password: "secret", From: "devops@example.com"}
+	c, err := NewSMTPClient(options)
+	assert.Nil(t, err)
+	var addr, from string
+	var to []string
+	var auth smtp.Auth
+	c.(*smtpClient).sendMail = func(a string, au smtp.Auth, f string, t []string, msg []byte) error {
+		addr, auth, from, to = a, au, f, t
+		return nil
+	}
+	err = c.Send(context.Background(), &Message{To: []string{"alice@example.com"}, Subject: "test", HTML: "body"})
+	assert.Nil(t, err)
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.NotNil(t, auth)
+	assert.Equal(t, "devops@example.com", from)
+	assert.Equal(t, []string{"alice@example.com"}, to)
+}
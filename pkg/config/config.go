@@ -28,7 +28,10 @@ import (
 	"github.com/spf13/viper"
 
 	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/client/history"
+	"kubesphere.io/devops/pkg/client/ldap"
 	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/smtp"
 )
 
 // Package config saves configuration for running KubeSphere components
@@ -75,6 +78,19 @@ type AuthMode string
 var (
 	// AuthModeToken let it use the token directly
 	AuthModeToken AuthMode = "token"
+	// AuthModeVerified verifies the signature and expiration of the KubeSphere tokens by the JWT secret
+	AuthModeVerified AuthMode = "verified"
+)
+
+// AuthorizationMode is the way to authorize the requests to the apiserver
+type AuthorizationMode string
+
+var (
+	// AuthorizationModeAlwaysAllow allows all the authenticated requests, they are supposed to be authorized by
+	// the KubeSphere apiserver in front of this one
+	AuthorizationModeAlwaysAllow AuthorizationMode = "AlwaysAllow"
+	// AuthorizationModeSubjectAccessReview authorizes the requests by the SubjectAccessReview API of Kubernetes
+	AuthorizationModeSubjectAccessReview AuthorizationMode = "SubjectAccessReview"
 )
 
 // Config defines everything needed for apiserver to deal with external services
@@ -83,11 +99,15 @@ type Config struct {
 	KubernetesOptions     *k8s.KubernetesOptions             `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
 	RedisOptions          *cache.Options                     `json:"redis,omitempty" yaml:"redis,omitempty" mapstructure:"redis"`
 	S3Options             *s3.Options                        `json:"s3,omitempty" yaml:"s3,omitempty" mapstructure:"s3"`
+	HistoryOptions        *history.Options                   `json:"history,omitempty" yaml:"history,omitempty" mapstructure:"history"`
+	LDAPOptions           *ldap.Options                      `json:"ldap,omitempty" yaml:"ldap,omitempty" mapstructure:"ldap"`
+	SMTPOptions           *smtp.Options                      `json:"smtp,omitempty" yaml:"smtp,omitempty" mapstructure:"smtp"`
 	SonarQubeOptions      *sonarqube.Options                 `json:"sonarqube,omitempty" yaml:"sonarQube,omitempty" mapstructure:"sonarqube"`
 	ArgoCDOption          *ArgoCDOption                      `json:"argocd,omitempty" yaml:"argocd,omitempty" mapstructure:"argocd"`
 	FluxCDOption          *FluxCDOption                      `json:"fluxcd,omitempty" yaml:"fluxcd,omitempty" mapstructure:"fluxcd"`
 	AuthenticationOptions *authoptions.AuthenticationOptions `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
 	AuthMode              AuthMode                           `json:"authMode,omitempty" yaml:"authMode,omitempty" mapstructure:"authMode"`
+	AuthorizationMode     AuthorizationMode                  `json:"authorizationMode,omitempty" yaml:"authorizationMode,omitempty" mapstructure:"authorizationMode"`
 	JWTSecret             string                             `json:"jwtSecret,omitempty" yaml:"jwtSecret,omitempty" mapstructure:"jwtSecret"`
 }
 
@@ -98,7 +118,11 @@ func New() *Config {
 		JenkinsOptions:    jenkins.NewJenkinsOptions(),
 		KubernetesOptions: k8s.NewKubernetesOptions(),
 		S3Options:         s3.NewS3Options(),
+		HistoryOptions:    history.NewHistoryOptions(),
+		LDAPOptions:       ldap.NewLDAPOptions(),
+		SMTPOptions:       smtp.NewSMTPOptions(),
 		AuthMode:          AuthModeToken,
+		AuthorizationMode: AuthorizationModeAlwaysAllow,
 		ArgoCDOption:      &ArgoCDOption{},
 		FluxCDOption:      &FluxCDOption{},
 	}
@@ -167,4 +191,12 @@ func (conf *Config) stripEmptyOptions() {
 	if conf.S3Options != nil && conf.S3Options.Endpoint == "" {
 		conf.S3Options = nil
 	}
+
+	if conf.HistoryOptions != nil && conf.HistoryOptions.DSN == "" {
+		conf.HistoryOptions = nil
+	}
+
+	if conf.LDAPOptions != nil && conf.LDAPOptions.Host == "" {
+		conf.LDAPOptions = nil
+	}
 }
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// GitOps enables the controllers of Argo CD and FluxCD applications
+	GitOps featuregate.Feature = "GitOps"
+
+	// ArgoWorkflows enables running PipelineRuns on Argo Workflows as an alternative backend of Jenkins
+	ArgoWorkflows featuregate.Feature = "ArgoWorkflows"
+
+	// Notifications enables sending the events of PipelineRuns to the chat tools
+	Notifications featuregate.Feature = "Notifications"
+
+	// PipelineSource enables managing Pipelines and Templates from a Git repository
+	PipelineSource featuregate.Feature = "PipelineSource"
+)
+
+// DefaultMutableFeatureGate is the central registry of the feature gates, it is only mutable while parsing flags
+var DefaultMutableFeatureGate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+// DefaultFeatureGate is a read-only view of DefaultMutableFeatureGate
+var DefaultFeatureGate featuregate.FeatureGate = DefaultMutableFeatureGate
+
+// defaultFeatureGates consists of all known feature keys.
+// To add a new feature, define a key for it above and add it here.
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	GitOps:         {Default: true, PreRelease: featuregate.Beta},
+	ArgoWorkflows:  {Default: true, PreRelease: featuregate.Beta},
+	Notifications:  {Default: true, PreRelease: featuregate.Beta},
+	PipelineSource: {Default: true, PreRelease: featuregate.Alpha},
+}
+
+func init() {
+	if err := DefaultMutableFeatureGate.Add(defaultFeatureGates); err != nil {
+		panic(err)
+	}
+}
+
+// Enabled returns true if the feature is enabled
+func Enabled(feature featuregate.Feature) bool {
+	return DefaultFeatureGate.Enabled(feature)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/featuregate"
+)
+
+func TestDefaultFeatureGates(t *testing.T) {
+	for _, feature := range []featuregate.Feature{GitOps, ArgoWorkflows, Notifications, PipelineSource} {
+		assert.True(t, Enabled(feature), "feature %s should be enabled by default", feature)
+	}
+
+	known := DefaultMutableFeatureGate.KnownFeatures()
+	assert.Contains(t, known, "GitOps=true|false (BETA - default=true)")
+	assert.Contains(t, known, "PipelineSource=true|false (ALPHA - default=true)")
+}
+
+func TestSetFeatureGates(t *testing.T) {
+	gate := DefaultMutableFeatureGate.DeepCopy()
+	assert.Nil(t, gate.Set("GitOps=false,Notifications=false"))
+	assert.False(t, gate.Enabled(GitOps))
+	assert.False(t, gate.Enabled(Notifications))
+	assert.True(t, gate.Enabled(ArgoWorkflows))
+
+	// the central registry is not affected by the copy
+	assert.True(t, Enabled(GitOps))
+
+	assert.NotNil(t, gate.Set("Unknown=true"))
+	assert.NotNil(t, gate.Set("GitOps=yes"))
+}
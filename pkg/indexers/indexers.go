@@ -18,12 +18,31 @@ package indexers
 
 import (
 	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/models/credential"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
 
+// CreateControllerIndexers creates the field indexers which the controllers look up the related resources with.
+func CreateControllerIndexers(runtimeCache cache.Cache) error {
+	for _, create := range []func(cache.Cache) error{
+		CreatePipelineRunSCMRefNameIndexer,
+		CreatePipelineRunPipelineRefIndexer,
+		CreatePipelineCredentialsIndexer,
+		CreateGitRepositorySecretIndexer,
+	} {
+		if err := create(runtimeCache); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreatePipelineRunSCMRefNameIndexer creates field indexer which could speed up listing PipelineRun by SCM reference name.
 func CreatePipelineRunSCMRefNameIndexer(runtimeCache cache.Cache) error {
 	return runtimeCache.IndexField(context.Background(),
@@ -43,6 +62,22 @@ func extractSCMFunc(o client.Object) []string {
 	return []string{pipelineRun.Spec.SCM.RefName}
 }
 
+// CreatePipelineRunSCMRefTypeIndexer creates field indexer which could speed up listing PipelineRun by SCM reference type.
+func CreatePipelineRunSCMRefTypeIndexer(runtimeCache cache.Cache) error {
+	return runtimeCache.IndexField(context.Background(),
+		&v1alpha3.PipelineRun{},
+		v1alpha3.PipelineRunSCMRefTypeField,
+		extractSCMRefTypeFunc)
+}
+
+func extractSCMRefTypeFunc(o client.Object) []string {
+	pipelineRun, ok := o.(*v1alpha3.PipelineRun)
+	if !ok || pipelineRun == nil || pipelineRun.Spec.SCM == nil {
+		return []string{}
+	}
+	return []string{string(pipelineRun.Spec.SCM.GetRefType())}
+}
+
 // CreatePipelineRunIdentityIndexer creates an indexer which aims for locating a PipelineRun with an identifier, like Pipeline name, SCM reference name and run ID.
 func CreatePipelineRunIdentityIndexer(runtimeCache cache.Cache) error {
 	// TODO Make the definition of index name in only one place
@@ -59,3 +94,61 @@ func extractPipelineRunIdentifier(o client.Object) []string {
 	}
 	return []string{pipelineRun.GetPipelineRunIdentifier()}
 }
+
+// CreatePipelineRunPipelineRefIndexer creates field indexer which could speed up listing PipelineRun by Pipeline name.
+func CreatePipelineRunPipelineRefIndexer(runtimeCache cache.Cache) error {
+	return runtimeCache.IndexField(context.Background(),
+		&v1alpha3.PipelineRun{},
+		v1alpha3.PipelineRunPipelineRefField,
+		extractPipelineRefFunc)
+}
+
+func extractPipelineRefFunc(o client.Object) []string {
+	pipelineRun, ok := o.(*v1alpha3.PipelineRun)
+	if !ok || pipelineRun == nil || pipelineRun.Spec.PipelineRef == nil || pipelineRun.Spec.PipelineRef.Name == "" {
+		return []string{}
+	}
+	return []string{pipelineRun.Spec.PipelineRef.Name}
+}
+
+// CreatePipelineCredentialsIndexer creates field indexer which could speed up listing Pipeline by referenced credential.
+func CreatePipelineCredentialsIndexer(runtimeCache cache.Cache) error {
+	return runtimeCache.IndexField(context.Background(),
+		&v1alpha3.Pipeline{},
+		v1alpha3.PipelineCredentialsField,
+		extractCredentialsFunc)
+}
+
+func extractCredentialsFunc(o client.Object) []string {
+	pipeline, ok := o.(*v1alpha3.Pipeline)
+	if !ok || pipeline == nil {
+		return []string{}
+	}
+	credentials := make([]string, 0)
+	for name := range credential.GetReferencedCredentials(&pipeline.Spec) {
+		credentials = append(credentials, name)
+	}
+	sort.Strings(credentials)
+	return credentials
+}
+
+// CreateGitRepositorySecretIndexer creates field indexer which could speed up listing GitRepository by referenced secret.
+func CreateGitRepositorySecretIndexer(runtimeCache cache.Cache) error {
+	return runtimeCache.IndexField(context.Background(),
+		&v1alpha3.GitRepository{},
+		v1alpha3.GitRepositorySecretField,
+		extractGitRepositorySecretFunc)
+}
+
+func extractGitRepositorySecretFunc(o client.Object) []string {
+	repo, ok := o.(*v1alpha3.GitRepository)
+	if !ok || repo == nil || repo.Spec.Secret == nil || repo.Spec.Secret.Name == "" {
+		return []string{}
+	}
+	// the secret is in the namespace of GitRepository if its namespace is empty
+	namespace := repo.Spec.Secret.Namespace
+	if namespace == "" {
+		namespace = repo.Namespace
+	}
+	return []string{types.NamespacedName{Namespace: namespace, Name: repo.Spec.Secret.Name}.String()}
+}
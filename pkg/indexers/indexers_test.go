@@ -51,6 +51,12 @@ func TestCreatePipelineRunSCMRefNameIndexer(t *testing.T) {
 	}
 }
 
+func TestCreatePipelineRunSCMRefTypeIndexer(t *testing.T) {
+	if err := CreatePipelineRunSCMRefTypeIndexer(&informertest.FakeInformers{}); err != nil {
+		t.Errorf("CreatePipelineRunSCMRefTypeIndexer() error = %v", err)
+	}
+}
+
 func TestCreatePipelineRunIdentityIndexer(t *testing.T) {
 	type args struct {
 		runtimeCache cache.Cache
@@ -115,6 +121,140 @@ func Test_extractSCMFunc(t *testing.T) {
 	}
 }
 
+func Test_extractSCMRefTypeFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		o    client.Object
+		want []string
+	}{{
+		name: "not expect Kind",
+		o:    &v1.ConfigMap{},
+		want: []string{},
+	}, {
+		name: "scm is nil",
+		o:    &v1alpha3.PipelineRun{},
+		want: []string{},
+	}, {
+		name: "branch",
+		o:    &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "master"}}},
+		want: []string{"branch"},
+	}, {
+		name: "pull request",
+		o:    &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "PR-1"}}},
+		want: []string{"pr"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractSCMRefTypeFunc(tt.o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractSCMRefTypeFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateControllerIndexers(t *testing.T) {
+	if err := CreateControllerIndexers(&informertest.FakeInformers{}); err != nil {
+		t.Errorf("CreateControllerIndexers() error = %v", err)
+	}
+}
+
+func Test_extractPipelineRefFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		o    client.Object
+		want []string
+	}{{
+		name: "not expect Kind",
+		o:    &v1.ConfigMap{},
+		want: []string{},
+	}, {
+		name: "no Pipeline reference",
+		o:    &v1alpha3.PipelineRun{},
+		want: []string{},
+	}, {
+		name: "valid PipelineRun",
+		o:    &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}}},
+		want: []string{"pipeline"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPipelineRefFunc(tt.o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractPipelineRefFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractCredentialsFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		o    client.Object
+		want []string
+	}{{
+		name: "not expect Kind",
+		o:    &v1.ConfigMap{},
+		want: []string{},
+	}, {
+		name: "no credentials",
+		o:    &v1alpha3.Pipeline{},
+		want: []string{},
+	}, {
+		name: "the credentials of SCM and Jenkinsfile",
+		o: &v1alpha3.Pipeline{Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+				SourceType: v1alpha3.SourceTypeGit,
+				GitSource:  &v1alpha3.GitSource{CredentialId: "git"},
+			},
+		}},
+		want: []string{"git"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractCredentialsFunc(tt.o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractCredentialsFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractGitRepositorySecretFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		o    client.Object
+		want []string
+	}{{
+		name: "not expect Kind",
+		o:    &v1.ConfigMap{},
+		want: []string{},
+	}, {
+		name: "no secret",
+		o:    &v1alpha3.GitRepository{},
+		want: []string{},
+	}, {
+		name: "the secret in the same namespace",
+		o: &v1alpha3.GitRepository{
+			ObjectMeta: v12.ObjectMeta{Namespace: "ns"},
+			Spec:       v1alpha3.GitRepositorySpec{Secret: &v1.SecretReference{Name: "token"}},
+		},
+		want: []string{"ns/token"},
+	}, {
+		name: "the secret in another namespace",
+		o: &v1alpha3.GitRepository{
+			ObjectMeta: v12.ObjectMeta{Namespace: "ns"},
+			Spec:       v1alpha3.GitRepositorySpec{Secret: &v1.SecretReference{Namespace: "another", Name: "token"}},
+		},
+		want: []string{"another/token"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractGitRepositorySecretFunc(tt.o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractGitRepositorySecretFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_extractPipelineRunIdentifier(t *testing.T) {
 	type args struct {
 		o client.Object
@@ -26,10 +26,19 @@ const (
 	AccessToken  TokenType = "access_token"
 	RefreshToken TokenType = "refresh_token"
 	StaticToken  TokenType = "static_token"
+	// TriggerToken is scoped to a Pipeline, it only triggers PipelineRuns of the Pipeline
+	TriggerToken TokenType = "trigger_token"
+	// ApprovalToken is scoped to an input step of a PipelineRun, it only approves or rejects the step once
+	ApprovalToken TokenType = "approval_token"
 )
 
 type TokenType string
 
+// IsScoped returns true if the token is only allowed to access its own endpoints, such as the trigger tokens
+func (t TokenType) IsScoped() bool {
+	return t == TriggerToken || t == ApprovalToken
+}
+
 // Issuer issues token to user, tokens are required to perform mutating requests to resources
 type Issuer interface {
 	// IssueTo issues a token a User, return error if issuing process failed
@@ -52,7 +52,6 @@ func (s *jwtTokenIssuer) VerifyWithoutClaimsValidation(tokenString string) (user
 	}
 
 	// set up the default values
-	tokenType = "clm.TokenType"
 	userInfo = &user.DefaultInfo{}
 
 	var token *jwt.Token
@@ -66,6 +65,9 @@ func (s *jwtTokenIssuer) VerifyWithoutClaimsValidation(tokenString string) (user
 			userInfo = &user.DefaultInfo{
 				Name: username,
 			}
+			if value, ok := mapClaims["token_type"].(string); ok {
+				tokenType = TokenType(value)
+			}
 		} else {
 			err = errors.New("no sub or username found from jwt, claims: %v", mapClaims)
 		}
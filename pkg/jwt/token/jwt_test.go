@@ -49,9 +49,11 @@ func TestTokenVerifyWithoutCacheValidate(t *testing.T) {
 	}
 
 	var usr user.Info
-	usr, _, err = issuer.VerifyWithoutClaimsValidation(tokenString)
+	var tokenType TokenType
+	usr, tokenType, err = issuer.VerifyWithoutClaimsValidation(tokenString)
 	assert.Nil(t, err)
 	assert.Equal(t, "admin", usr.GetName())
+	assert.Equal(t, AccessToken, tokenType)
 }
 
 func Test_getUserFromClaims(t *testing.T) {
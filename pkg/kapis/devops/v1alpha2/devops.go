@@ -158,6 +158,7 @@ func (h *ProjectPipelineHandler) ListPipelines(req *restful.Request, resp *restf
 			pipelineList.Items = append(pipelineList.Items, clientDevOps.Pipeline{
 				Name:        pipeline.Name,
 				Annotations: pipeline.Annotations,
+				Suspended:   pipeline.IsSuspended(),
 			})
 		}
 	}
@@ -175,10 +176,12 @@ func (h *ProjectPipelineHandler) ListPipelines(req *restful.Request, resp *restf
 	} else {
 		for i, _ := range res.Items {
 			if index, ok := pipelineMap[res.Items[i].Name]; ok {
-				// keep annotations field of pipelineList
+				// keep annotations and suspended fields of pipelineList
 				annotations := pipelineList.Items[index].Annotations
+				suspended := pipelineList.Items[index].Suspended
 				pipelineList.Items[index] = res.Items[i]
 				pipelineList.Items[index].Annotations = annotations
+				pipelineList.Items[index].Suspended = suspended
 			}
 		}
 	}
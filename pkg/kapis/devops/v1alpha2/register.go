@@ -689,7 +689,7 @@ func addJenkinsToContainer(webservice *restful.WebService, devopsClient devops.I
 		Returns(http.StatusOK, api.StatusOK, nil).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsJenkinsTag}))
 
-	jenkinsProxy := newJenkinsProxy(jenkinsClient, parse.Host, parse.Scheme, nil)
+	jenkinsProxy := newJenkinsProxy(jenkinsClient, parse.Host, parse.Scheme, jenkinsClient.RoundTripper)
 	// some Jenkins API against with POST method
 	webservice.Route(webservice.GET("/devops/{devops}/jenkins/{path:*}").
 		Param(webservice.PathParameter("path", "Path stands for any suffix path.")).
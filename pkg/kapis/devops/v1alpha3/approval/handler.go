@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/approval"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the links are opened by browsers, so the responses are pages instead of JSON
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Approval</title></head><body>
+<p>{{.Message}}</p>
+{{if .Token}}<form method="POST"><input type="hidden" name="token" value="{{.Token}}"><button type="submit">{{.Action}}</button></form>{{end}}
+</body></html>
+`))
+
+var pastTense = map[string]string{approval.ActionApprove: "approved", approval.ActionReject: "rejected"}
+
+type handler struct {
+	client       client.Client
+	devopsClient devops.Interface
+	issuer       token.Issuer
+}
+
+func newHandler(c client.Client, devopsClient devops.Interface, issuer token.Issuer) *handler {
+	return &handler{client: c, devopsClient: devopsClient, issuer: issuer}
+}
+
+// confirm asks the approver to confirm, since the links might be opened by the scanners of emails
+func (h *handler) confirm(req *restful.Request, resp *restful.Response) {
+	tokenString := req.QueryParameter("token")
+	request, err := approval.Parse(h.issuer, tokenString)
+	if err != nil {
+		writePage(resp, http.StatusUnauthorized, "The link is invalid or has expired.", "", "")
+		return
+	}
+	writePage(resp, http.StatusOK, "Confirm to "+request.Action+" the input step of PipelineRun "+
+		request.Namespace+"/"+request.PipelineRun+" as "+request.Approver+".", tokenString, request.Action)
+}
+
+// submit consumes the token, then proceeds or aborts the input step in Jenkins
+func (h *handler) submit(req *restful.Request, resp *restful.Response) {
+	tokenString, err := req.BodyParameter("token")
+	if err != nil || tokenString == "" {
+		tokenString = req.QueryParameter("token")
+	}
+	request, err := approval.Parse(h.issuer, tokenString)
+	if err != nil {
+		writePage(resp, http.StatusUnauthorized, "The link is invalid or has expired.", "", "")
+		return
+	}
+
+	ctx := req.Request.Context()
+	pr, err := approval.Consume(ctx, h.client, request)
+	if err != nil {
+		klog.V(4).Infof("failed to consume the approval token of %s/%s, error: %v", request.Namespace, request.PipelineRun, err)
+		writePage(resp, http.StatusConflict, "The link has been used, or the input step is not waiting for approval anymore.", "", "")
+		return
+	}
+	if err = approval.Submit(h.devopsClient, pr, request); err != nil {
+		klog.Errorf("failed to %s the input step of PipelineRun %s/%s, error: %v", request.Action, pr.Namespace, pr.Name, err)
+		writePage(resp, http.StatusInternalServerError, "Failed to "+request.Action+
+			" the input step, please try again in the console.", "", "")
+		return
+	}
+	klog.Infof("the input step of PipelineRun %s/%s was %s by %s from the approval email",
+		pr.Namespace, pr.Name, pastTense[request.Action], request.Approver)
+	writePage(resp, http.StatusOK, "The input step of PipelineRun "+pr.Namespace+"/"+pr.Name+" was "+
+		pastTense[request.Action]+".", "", "")
+}
+
+func writePage(resp *restful.Response, status int, message, tokenString, action string) {
+	resp.Header().Set("Content-Type", mimeHTML)
+	resp.WriteHeader(status)
+	_ = pageTemplate.Execute(resp, map[string]string{"Message": message, "Token": tokenString, "Action": action})
+}
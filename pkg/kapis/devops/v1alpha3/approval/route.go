@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const mimeHTML = "text/html; charset=utf-8"
+
+// RegisterRoutes registry the handlers of the links in the approval emails
+func RegisterRoutes(ws *restful.WebService, c client.Client, devopsClient devops.Interface, issuer token.Issuer) {
+	h := newHandler(c, devopsClient, issuer)
+
+	ws.Route(ws.GET("/webhooks/approvals").
+		To(h.confirm).
+		Doc("Show the page to confirm approving or rejecting an input step, the token is not consumed").
+		Param(ws.QueryParameter("token", "The approval token in the email")).
+		Produces("text/html").
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+
+	ws.Route(ws.POST("/webhooks/approvals").
+		To(h.submit).
+		Doc("Approve or reject an input step with a one-time approval token").
+		Consumes("application/x-www-form-urlencoded").
+		Param(ws.FormParameter("token", "The approval token in the email")).
+		Produces("text/html").
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/approval"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApprovalAPIs(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc",
+		Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "demo"},
+		Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}}}
+	approval.SetNonces(pr, map[string]string{"20/22": "nonce"})
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pr).Build()
+
+	issuer := token.NewTokenIssuer("secret", 0)
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c, fakedevops.NewFakeDevops(nil), issuer)
+	container.Add(ws)
+	request := func(method, tokenString string) *httptest.ResponseRecorder {
+		var httpRequest *http.Request
+		if method == http.MethodGet {
+			httpRequest, _ = http.NewRequest(method, "http://fake.com"+approval.Path+"?token="+tokenString, nil)
+		} else {
+			httpRequest, _ = http.NewRequest(method, "http://fake.com"+approval.Path,
+				strings.NewReader(url.Values{"token": []string{tokenString}}.Encode()))
+			httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		httpRequest.Header.Set("Accept", "text/html")
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	tokenString, err := approval.Issue(issuer, &approval.Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "20",
+		StepID: "22", InputID: "Deploy", Action: approval.ActionApprove, Approver: "alice", Nonce: "nonce"}, time.Hour)
+	assert.Nil(t, err)
+
+	// opening the link does not consume the token
+	resp := request(http.MethodGet, tokenString)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `name="token"`)
+	resp = request(http.MethodGet, "invalid")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = request(http.MethodPost, tokenString)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "was approved")
+
+	// the token is one-time
+	resp = request(http.MethodPost, tokenString)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package badge
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/badge"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	svgSuffix  = ".svg"
+	jsonSuffix = ".json"
+)
+
+type handler struct {
+	client client.Client
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c}
+}
+
+func (h *handler) getBadge(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	namespace, name := req.PathParameter("namespace"), req.PathParameter("badge")
+	pipelineName := strings.TrimSuffix(strings.TrimSuffix(name, svgSuffix), jsonSuffix)
+	notFound := restful.NewError(http.StatusNotFound, fmt.Sprintf("badge %s/%s not found", namespace, name))
+	if pipelineName == name {
+		kapis.HandleError(req, resp, notFound)
+		return
+	}
+
+	// hide the private Pipelines as if they don't exist
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pipelineName}, pipeline); err != nil {
+		if apierrors.IsNotFound(err) {
+			err = notFound
+		}
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if pipeline.Annotations[v1alpha3.PipelinePublicBadgeAnnoKey] != "true" {
+		kapis.HandleError(req, resp, notFound)
+		return
+	}
+
+	prList := &v1alpha3.PipelineRunList{}
+	if err := h.client.List(ctx, prList, client.InNamespace(namespace),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipelineName}); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	runs := prList.Items
+	if branch := req.QueryParameter("branch"); branch != "" {
+		runs = make([]v1alpha3.PipelineRun, 0, len(prList.Items))
+		for _, pr := range prList.Items {
+			if pr.Spec.SCM != nil && pr.Spec.SCM.RefName == branch {
+				runs = append(runs, pr)
+			}
+		}
+	}
+
+	statusBadge := badge.New(req.QueryParameter("label"), runs)
+	// the status changes at any time, the proxies like GitHub camo should not cache it
+	resp.AddHeader("Cache-Control", "no-cache, no-store, must-revalidate")
+	if strings.HasSuffix(name, jsonSuffix) {
+		_ = resp.WriteAsJson(statusBadge)
+		return
+	}
+	svg, err := statusBadge.SVG()
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	resp.AddHeader("Content-Type", "image/svg+xml;charset=utf-8")
+	_, _ = resp.Write(svg)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package badge
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/badge"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registers the status badges of Pipelines, they are public if the Pipeline allows
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	h := newHandler(c)
+
+	ws.Route(ws.GET("/badges/{namespace}/{badge}").
+		To(h.getBadge).
+		Doc("Get the status badge of the latest PipelineRun, the badge is {pipeline}.svg for an SVG image, "+
+			"or {pipeline}.json for the endpoint of shields.io. Only the Pipelines with the annotation "+
+			"pipeline.devops.kubesphere.io/public-badge: \"true\" have badges").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("badge", "Name of the Pipeline with the suffix .svg or .json")).
+		Param(ws.QueryParameter("branch", "The name of SCM reference, only for multi-branch pipeline")).
+		Param(ws.QueryParameter("label", "The label on the left side of the badge").DefaultValue(badge.DefaultLabel)).
+		Produces("image/svg+xml", restful.MIME_JSON).
+		Returns(http.StatusOK, api.StatusOK, badge.Badge{}))
+}
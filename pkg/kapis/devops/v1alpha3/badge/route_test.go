@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package badge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/badge"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetBadge(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	created := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	newPipelineRun := func(name, branch string, phase v1alpha3.RunPhase, age time.Duration) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "ns",
+				Name:              name,
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "public"},
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Spec:   v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: branch}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "public", Annotations: map[string]string{
+			v1alpha3.PipelinePublicBadgeAnnoKey: "true",
+		}},
+	}, &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "private"},
+	}, newPipelineRun("main-1", "main", v1alpha3.Succeeded, time.Hour),
+		newPipelineRun("dev-1", "dev", v1alpha3.Failed, time.Minute)).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c)
+	container.Add(ws)
+	request := func(uri string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(http.MethodGet, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	getBadge := func(uri string) *badge.Badge {
+		resp := request(uri)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		statusBadge := &badge.Badge{}
+		assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), statusBadge))
+		return statusBadge
+	}
+
+	assert.Equal(t, &badge.Badge{SchemaVersion: 1, Label: "build", Message: "failing", Color: "red"},
+		getBadge("/badges/ns/public.json"))
+	assert.Equal(t, &badge.Badge{SchemaVersion: 1, Label: "main", Message: "passing", Color: "brightgreen"},
+		getBadge("/badges/ns/public.json?branch=main&label=main"))
+	assert.Equal(t, "unknown", getBadge("/badges/ns/public.json?branch=feature").Message)
+
+	resp := request("/badges/ns/public.svg?branch=main")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "image/svg+xml;charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Header().Get("Cache-Control"), "no-cache")
+	assert.Contains(t, resp.Body.String(), "<title>build: passing</title>")
+
+	assert.Equal(t, http.StatusNotFound, request("/badges/ns/private.svg").Code)
+	assert.Equal(t, http.StatusNotFound, request("/badges/ns/fake.svg").Code)
+	assert.Equal(t, http.StatusNotFound, request("/badges/ns/public").Code)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkoperation
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/kapis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c}
+}
+
+func (h *handler) create(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	spec := v1alpha3.BulkOperationSpec{}
+	if err := req.ReadEntity(&spec); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	switch spec.Action {
+	case v1alpha3.BulkActionDisablePipelines, v1alpha3.BulkActionAbortRuns, v1alpha3.BulkActionRetriggerFailedRuns:
+	default:
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("unsupported action: %q, should be %s, %s or %s", spec.Action,
+			v1alpha3.BulkActionDisablePipelines, v1alpha3.BulkActionAbortRuns, v1alpha3.BulkActionRetriggerFailedRuns))
+		return
+	}
+
+	user, ok := apiserverrequest.UserFrom(ctx)
+	if !ok || user == nil || user.GetName() == "" {
+		kapis.HandleUnauthorized(resp, req, fmt.Errorf("a login user is required to create bulk operations"))
+		return
+	}
+
+	operation := &v1alpha3.BulkOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    req.PathParameter("namespace"),
+			GenerateName: strings.ToLower(string(spec.Action)) + "-",
+			Annotations: map[string]string{
+				v1alpha3.PipelineRunCreatorAnnoKey: user.GetName(),
+			},
+		},
+		Spec: spec,
+	}
+	if err := h.client.Create(ctx, operation); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusAccepted, operation)
+}
+
+func (h *handler) list(req *restful.Request, resp *restful.Response) {
+	operationList := &v1alpha3.BulkOperationList{}
+	if err := h.client.List(req.Request.Context(), operationList, client.InNamespace(req.PathParameter("namespace"))); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	operations := operationList.Items
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[j].CreationTimestamp.Before(&operations[i].CreationTimestamp)
+	})
+	_ = resp.WriteEntity(operations)
+}
+
+func (h *handler) get(req *restful.Request, resp *restful.Response) {
+	operation := &v1alpha3.BulkOperation{}
+	if err := h.client.Get(req.Request.Context(), client.ObjectKey{
+		Namespace: req.PathParameter("namespace"),
+		Name:      req.PathParameter("bulkoperation"),
+	}, operation); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(operation)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkoperation
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=bulkoperations,verbs=get;list;create
+
+// RegisterRoutes registry the handlers of the bulk operations, they are executed asynchronously by the controller
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	h := newHandler(c)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/bulkoperations").
+		To(h.create).
+		Doc("Create a bulk operation, such as disabling all the Pipelines, aborting all the running PipelineRuns of "+
+			"a branch, or re-triggering the latest failed PipelineRuns. Track its progress by the returned name").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipelines")).
+		Reads(v1alpha3.BulkOperationSpec{}).
+		Returns(http.StatusAccepted, api.StatusOK, v1alpha3.BulkOperation{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/bulkoperations").
+		To(h.list).
+		Doc("List the bulk operations, the latest one comes first").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipelines")).
+		Returns(http.StatusOK, api.StatusOK, []v1alpha3.BulkOperation{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/bulkoperations/{bulkoperation}").
+		To(h.get).
+		Doc("Get a bulk operation with its progress").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipelines")).
+		Param(ws.PathParameter("bulkoperation", "Name of the bulk operation")).
+		Returns(http.StatusOK, api.StatusOK, v1alpha3.BulkOperation{}))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulkoperation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBulkOperationAPIs(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	existing := &v1alpha3.BulkOperation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "abortruns-old",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Spec: v1alpha3.BulkOperationSpec{Action: v1alpha3.BulkActionAbortRuns},
+	}
+	latest := existing.DeepCopy()
+	latest.Name = "abortruns-new"
+	latest.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(existing, latest).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c)
+	container.Add(ws)
+	container.Filter(func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		if name := req.HeaderParameter("X-User"); name != "" {
+			req.Request = req.Request.WithContext(apiserverrequest.WithUser(req.Request.Context(), &user.DefaultInfo{Name: name}))
+		}
+		chain.ProcessFilter(req, resp)
+	})
+	request := func(method, uri, body string, header map[string]string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(method, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", restful.MIME_JSON)
+		for k, v := range header {
+			httpRequest.Header.Set(k, v)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	uri := "/namespaces/ns/bulkoperations"
+	alice := map[string]string{"X-User": "alice"}
+
+	// a login user is required
+	resp := request(http.MethodPost, uri, `{"action":"AbortRuns"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = request(http.MethodPost, uri, `{"action":"fake"}`, alice)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = request(http.MethodPost, uri, `{"action":"RetriggerFailedRuns","branch":"main"}`, alice)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	created := &v1alpha3.BulkOperation{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), created))
+	assert.True(t, strings.HasPrefix(created.Name, "retriggerfailedruns-"))
+	assert.Equal(t, "main", created.Spec.Branch)
+	assert.Equal(t, "alice", created.Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+
+	resp = request(http.MethodGet, uri+"/"+created.Name, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = request(http.MethodGet, uri+"/fake", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// the fake client does not set the creation timestamp of the created one
+	resp = request(http.MethodGet, uri, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var operations []v1alpha3.BulkOperation
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &operations))
+	if assert.Equal(t, 3, len(operations)) {
+		assert.Equal(t, "abortruns-new", operations[0].Name)
+		assert.Equal(t, "abortruns-old", operations[1].Name)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"errors"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/converter"
+)
+
+type handler struct {
+}
+
+// Payload represents the content which needs to be converted
+type Payload struct {
+	Data string `json:"data"`
+}
+
+func (h *handler) convertGitLabCI(req *restful.Request, resp *restful.Response) {
+	payload := &Payload{}
+	if err := req.ReadEntity(payload); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if payload.Data == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the content of .gitlab-ci.yml is empty"))
+		return
+	}
+
+	name := req.QueryParameter(NameQueryParameter.Data().Name)
+	result, err := (&converter.GitLabCIConverter{}).Convert(name, payload.Data)
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	_ = resp.WriteAsJson(result)
+}
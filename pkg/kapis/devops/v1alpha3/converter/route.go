@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/converter"
+)
+
+var (
+	// NameQueryParameter is the query parameter of the converted Pipeline name
+	NameQueryParameter = restful.QueryParameter("name", "The name of the converted Pipeline")
+)
+
+// RegisterRoutes registry the handlers of the converters
+func RegisterRoutes(service *restful.WebService) {
+	h := &handler{}
+	service.Route(service.POST("/convert/gitlab").
+		To(h.convertGitLabCI).
+		Param(NameQueryParameter).
+		Reads(&Payload{}, "The content of .gitlab-ci.yml should be in the 'data' field").
+		Doc("Convert a .gitlab-ci.yml into an equivalent Pipeline").
+		Returns(http.StatusOK, api.StatusOK, converter.Result{}))
+}
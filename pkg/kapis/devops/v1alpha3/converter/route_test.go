@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	runtimeSchema "k8s.io/apimachinery/pkg/runtime/schema"
+	"kubesphere.io/devops/pkg/api"
+	ksruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/converter"
+)
+
+func TestAPIs(t *testing.T) {
+	type args struct {
+		api     string
+		getBody func() io.Reader
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		verify   func([]byte, *testing.T)
+	}{{
+		name: "invalid request body",
+		args: args{
+			api: "/convert/gitlab",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`invalid`)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "empty content",
+		args: args{
+			api: "/convert/gitlab",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{}`)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "content without any jobs",
+		args: args{
+			api: "/convert/gitlab",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{"data":"stages: [build]"}`)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "convert with a specific name",
+		args: args{
+			api: "/convert/gitlab?name=demo",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{"data":"build:\n  script: make\n"}`)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &converter.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.Equal(t, "demo", result.Pipeline.Name)
+			assert.Contains(t, result.Pipeline.Spec.Pipeline.Jenkinsfile, "sh 'make'")
+		},
+	}, {
+		name: "convert with the default name",
+		args: args{
+			api: "/convert/gitlab",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{"data":"build:\n  script: make\n"}`)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &converter.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.Equal(t, "gitlab-ci", result.Pipeline.Name)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := ksruntime.NewWebService(runtimeSchema.GroupVersion{Group: api.GroupName, Version: "v1alpha3"})
+			RegisterRoutes(ws)
+			container := restful.NewContainer()
+			container.Add(ws)
+
+			httpRequest, _ := http.NewRequest(http.MethodPost,
+				"http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+tt.args.api, tt.args.getBody())
+			httpRequest.Header.Set("Content-Type", "application/json")
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+
+			if tt.verify != nil {
+				tt.verify(httpWriter.Body.Bytes(), t)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/cost"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultPeriod is the period of the report if the start time is not specified
+const defaultPeriod = 30 * 24 * time.Hour
+
+type handler struct {
+	client client.Client
+	now    func() time.Time
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c, now: time.Now}
+}
+
+func (h *handler) getReport(req *restful.Request, resp *restful.Response) {
+	namespace := req.PathParameter("namespace")
+	end, err := parseTime(req.QueryParameter("end"), h.now())
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	start, err := parseTime(req.QueryParameter("start"), end.Add(-defaultPeriod))
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if !start.Before(end) {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("the start time must be before the end time"))
+		return
+	}
+
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if pipeline := req.QueryParameter("pipeline"); pipeline != "" {
+		opts = append(opts, client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipeline})
+	}
+	prList := &v1alpha3.PipelineRunList{}
+	if err = h.client.List(req.Request.Context(), prList, opts...); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(cost.Aggregate(namespace, prList.Items, start, end))
+}
+
+func (h *handler) getPipelineRunCost(req *restful.Request, resp *restful.Response) {
+	namespace, name := req.PathParameter("namespace"), req.PathParameter("pipelinerun")
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(req.Request.Context(), client.ObjectKey{Namespace: namespace, Name: name}, pipelineRun); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	runCost, err := cost.GetCost(pipelineRun)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if runCost == nil {
+		kapis.HandleError(req, resp, restful.NewError(http.StatusNotFound,
+			fmt.Sprintf("the cost of PipelineRun %s/%s not found", namespace, name)))
+		return
+	}
+	_ = resp.WriteEntity(runCost)
+}
+
+func parseTime(value string, defaultTime time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultTime, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %q, it should be in RFC3339", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/cost"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registers the compute cost of PipelineRuns for the chargeback reports
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	h := newHandler(c)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/costs").
+		To(h.getReport).
+		Doc("Get the compute cost of the PipelineRuns in a DevOps project which were created in a period, "+
+			"aggregated by the Pipelines").
+		Param(ws.PathParameter("namespace", "Namespace of the DevOps project")).
+		Param(ws.QueryParameter("pipeline", "Only count the PipelineRuns of this Pipeline")).
+		Param(ws.QueryParameter("start", "The start time in RFC3339, such as 2022-06-01T00:00:00Z. "+
+			"It is 30 days before the end if it is empty")).
+		Param(ws.QueryParameter("end", "The end time in RFC3339, it is now if it is empty")).
+		Returns(http.StatusOK, api.StatusOK, cost.Report{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/cost").
+		To(h.getPipelineRunCost).
+		Doc("Get the compute cost of the agent pods of a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, cost.Cost{}))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/cost"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRoutes(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipelineRun := func(name, pipeline string, created time.Time, total float64) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              name,
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+		if total > 0 {
+			assert.Nil(t, cost.SetCost(pr, &cost.Cost{Currency: cost.DefaultCurrency, Total: total}))
+		}
+		return pr
+	}
+	now := time.Now()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		newPipelineRun("a-1", "a", now.Add(-time.Hour), 1),
+		newPipelineRun("b-1", "b", now.Add(-2*time.Hour), 2),
+		newPipelineRun("b-2", "b", now.Add(-40*24*time.Hour), 4),
+		newPipelineRun("b-3", "b", now.Add(-time.Hour), 0),
+	).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c)
+	container.Add(ws)
+
+	tests := []struct {
+		name      string
+		uri       string
+		wantCode  int
+		wantTotal float64
+	}{{
+		name:      "the last 30 days",
+		uri:       "/namespaces/ns/costs",
+		wantCode:  http.StatusOK,
+		wantTotal: 3,
+	}, {
+		name:      "specific period",
+		uri:       "/namespaces/ns/costs?start=" + now.Add(-50*24*time.Hour).UTC().Format(time.RFC3339),
+		wantCode:  http.StatusOK,
+		wantTotal: 7,
+	}, {
+		name:      "specific pipeline",
+		uri:       "/namespaces/ns/costs?pipeline=a",
+		wantCode:  http.StatusOK,
+		wantTotal: 1,
+	}, {
+		name:     "invalid start",
+		uri:      "/namespaces/ns/costs?start=yesterday",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "start after end",
+		uri:      "/namespaces/ns/costs?start=2022-06-02T00:00:00Z&end=2022-06-01T00:00:00Z",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:      "PipelineRun",
+		uri:       "/namespaces/ns/pipelineruns/b-1/cost",
+		wantCode:  http.StatusOK,
+		wantTotal: 2,
+	}, {
+		name:     "PipelineRun without cost",
+		uri:      "/namespaces/ns/pipelineruns/b-3/cost",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "PipelineRun not found",
+		uri:      "/namespaces/ns/pipelineruns/c-1/cost",
+		wantCode: http.StatusNotFound,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3"+tt.uri, nil)
+			resp := httptest.NewRecorder()
+			container.Dispatch(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code, resp.Body.String())
+			if tt.wantCode == http.StatusOK {
+				result := map[string]interface{}{}
+				assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				assert.Equal(t, tt.wantTotal, result["total"])
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/credential"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+}
+
+func (h *handler) getUsage(req *restful.Request, resp *restful.Response) {
+	projectName := req.PathParameter("devops")
+	credentialName := req.PathParameter("credential")
+	ctx := req.Request.Context()
+
+	project := &v1alpha3.DevOpsProject{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: projectName}, project); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	namespace := project.Status.AdminNamespace
+	if namespace == "" {
+		namespace = projectName
+	}
+
+	usage, err := credential.GetUsage(ctx, h.client, namespace, credentialName)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(usage)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/credential"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registry the handlers of the credentials
+func RegisterRoutes(service *restful.WebService, c client.Client) {
+	h := &handler{client: c}
+	service.Route(service.GET("/devops/{devops}/credentials/{credential}/usedBy").
+		To(h.getUsage).
+		Param(service.PathParameter("devops", "DevOps project name")).
+		Param(service.PathParameter("credential", "credential name")).
+		Doc("Get the Pipelines and the unfinished PipelineRuns which reference the credential").
+		Returns(http.StatusOK, api.StatusOK, credential.Usage{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
+}
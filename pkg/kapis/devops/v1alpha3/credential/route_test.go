@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ksruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/credential"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+
+	tests := []struct {
+		name     string
+		api      string
+		wantCode int
+		verify   func([]byte, *testing.T)
+	}{{
+		name:     "DevOps project not found",
+		api:      "/devops/missing/credentials/git/usedBy",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "credential not found",
+		api:      "/devops/project/credentials/missing/usedBy",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "normal case",
+		api:      "/devops/project/credentials/git/usedBy",
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			usage := &credential.Usage{}
+			assert.Nil(t, json.Unmarshal(data, usage))
+			assert.Equal(t, "git", usage.Credential)
+			assert.Equal(t, []credential.PipelineUsage{{
+				Name:       "demo",
+				References: []string{credential.ReferenceJenkinsfile},
+			}}, usage.Pipelines)
+			assert.Empty(t, usage.PipelineRuns)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.DevOpsProject{
+				ObjectMeta: metav1.ObjectMeta{Name: "project"},
+				Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: "ns"},
+			}, &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+				Type:       v1alpha3.SecretTypeBasicAuth,
+			}, &v1alpha3.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo"},
+				Spec: v1alpha3.PipelineSpec{
+					Type: v1alpha3.NoScmPipelineType,
+					Pipeline: &v1alpha3.NoScmPipeline{
+						Jenkinsfile: "git credentialsId: 'git', url: 'x'",
+					},
+				},
+			}).Build()
+			ws := ksruntime.NewWebService(v1alpha3.GroupVersion)
+			RegisterRoutes(ws, c)
+			container := restful.NewContainer()
+			container.Add(ws)
+
+			httpRequest, _ := http.NewRequest(http.MethodGet,
+				"http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+tt.api, nil)
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+
+			if tt.verify != nil {
+				tt.verify(httpWriter.Body.Bytes(), t)
+			}
+		})
+	}
+}
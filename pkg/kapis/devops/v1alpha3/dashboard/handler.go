@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/stats"
+)
+
+const (
+	defaultLimit = 5
+	maxLimit     = 100
+)
+
+type handler struct {
+	collector *stats.Collector
+}
+
+func newHandler(collector *stats.Collector) *handler {
+	return &handler{collector: collector}
+}
+
+func (h *handler) getSummary(req *restful.Request, resp *restful.Response) {
+	window, err := stats.ParseWindow(req.QueryParameter("window"), stats.DefaultRetention)
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	limit := defaultLimit
+	if limitParam := req.QueryParameter("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 || limit > maxLimit {
+			kapis.HandleBadRequest(resp, req, fmt.Errorf("the limit must be in [1, %d]", maxLimit))
+			return
+		}
+	}
+	_ = resp.WriteEntity(h.collector.Summarize(req.PathParameter("namespace"), window, limit))
+}
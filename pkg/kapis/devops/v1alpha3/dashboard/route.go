@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/stats"
+)
+
+// RegisterRoutes registers the statistics of DevOps projects for the overview dashboard
+func RegisterRoutes(ws *restful.WebService, collector *stats.Collector) {
+	h := newHandler(collector)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/dashboard").
+		To(h.getSummary).
+		Doc("Get the statistics of the completed PipelineRuns in a DevOps project, including the success rate, "+
+			"average duration, busiest Pipelines, and failure hotspots").
+		Param(ws.PathParameter("namespace", "Namespace of the DevOps project")).
+		Param(ws.QueryParameter("window", "The time window before now, such as 24h, 7d, 30d").DefaultValue("7d")).
+		Param(ws.QueryParameter("limit", "The maximum number of items in the rankings").
+			DataType("integer").DefaultValue("5")).
+		Returns(http.StatusOK, api.StatusOK, stats.Summary{}))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/stats"
+)
+
+func TestGetSummary(t *testing.T) {
+	completionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	collector := stats.NewCollector(stats.DefaultRetention)
+	collector.Observe(&v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pr", Labels: map[string]string{
+			v1alpha3.PipelineNameLabelKey: "pipeline",
+		}},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded, CompletionTime: &completionTime},
+	})
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, collector)
+	container.Add(ws)
+
+	tests := []struct {
+		name      string
+		uri       string
+		wantCode  int
+		wantTotal int
+	}{{
+		name:      "default window",
+		uri:       "/namespaces/ns/dashboard",
+		wantCode:  http.StatusOK,
+		wantTotal: 1,
+	}, {
+		name:      "another namespace",
+		uri:       "/namespaces/other/dashboard?window=30d&limit=10",
+		wantCode:  http.StatusOK,
+		wantTotal: 0,
+	}, {
+		name:     "invalid window",
+		uri:      "/namespaces/ns/dashboard?window=1y",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "invalid limit",
+		uri:      "/namespaces/ns/dashboard?limit=0",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpRequest, _ := http.NewRequest(http.MethodGet,
+				"http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+tt.uri, nil)
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+			if tt.wantCode == http.StatusOK {
+				summary := &stats.Summary{}
+				assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), summary))
+				assert.Equal(t, tt.wantTotal, summary.Total)
+			}
+		})
+	}
+}
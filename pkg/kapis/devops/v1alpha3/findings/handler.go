@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package findings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/sarif"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// maxReportSize is the maximum size of an uploaded SARIF report, the findings are stored in a ConfigMap
+const maxReportSize = 512 * 1024
+
+// pullRequestNumberPattern matches the number of a pull request, such as PR-1 or MR-1-head
+var pullRequestNumberPattern = regexp.MustCompile(`^(?:PR|MR)-(\d+)`)
+
+type handler struct {
+	client           client.Client
+	scmClientFactory git.SCMClientFactory
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c, scmClientFactory: git.NewSCMClientFactory(c)}
+}
+
+func (h *handler) uploadReport(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: req.PathParameter("namespace"),
+		Name: req.PathParameter("pipelinerun")}, pipelineRun); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Request.Body, maxReportSize+1))
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if len(data) > maxReportSize {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("the SARIF report is larger than %d bytes", maxReportSize))
+		return
+	}
+	uploaded, err := sarif.Parse(data)
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	existing, err := h.getFindings(ctx, pipelineRun)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	findings := sarif.Merge(existing, uploaded)
+	if err = h.storeFindings(ctx, pipelineRun, findings); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	report, err := h.aggregate(ctx, pipelineRun, findings)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if req.QueryParameter("annotate") == "true" {
+		if err = h.annotate(ctx, pipelineRun, report); err != nil {
+			kapis.HandleError(req, resp, fmt.Errorf("the findings were stored, but failed to comment on the pull request: %v", err))
+			return
+		}
+	}
+	_ = resp.WriteEntity(report)
+}
+
+func (h *handler) getPipelineRunFindings(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: req.PathParameter("namespace"),
+		Name: req.PathParameter("pipelinerun")}, pipelineRun); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	h.writeReport(req, resp, pipelineRun)
+}
+
+func (h *handler) getPipelineFindings(req *restful.Request, resp *restful.Response) {
+	namespace, pipeline := req.PathParameter("namespace"), req.PathParameter("pipeline")
+	pipelineRuns, err := h.listRecordedRuns(req.Request.Context(), namespace, pipeline, req.QueryParameter("branch"), false)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if len(pipelineRuns) == 0 {
+		kapis.HandleNotFound(resp, req, fmt.Errorf("no findings of Pipeline '%s/%s'", namespace, pipeline))
+		return
+	}
+	h.writeReport(req, resp, &pipelineRuns[0])
+}
+
+func (h *handler) writeReport(req *restful.Request, resp *restful.Response, pipelineRun *v1alpha3.PipelineRun) {
+	ctx := req.Request.Context()
+	findings, err := h.getFindings(ctx, pipelineRun)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if findings == nil {
+		kapis.HandleNotFound(resp, req, fmt.Errorf("no findings of PipelineRun '%s/%s'", pipelineRun.Namespace, pipelineRun.Name))
+		return
+	}
+	report, err := h.aggregate(ctx, pipelineRun, findings)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(report)
+}
+
+// aggregate compares the findings of a PipelineRun with the previous PipelineRun of the same branch
+func (h *handler) aggregate(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, findings []sarif.Finding) (report *sarif.Report, err error) {
+	var pipelineRuns []v1alpha3.PipelineRun
+	if pipelineRuns, err = h.listRecordedRuns(ctx, pipelineRun.Namespace, pipelineRun.Labels[v1alpha3.PipelineNameLabelKey],
+		getRefName(pipelineRun), true); err != nil {
+		return
+	}
+	var base *v1alpha3.PipelineRun
+	for i := range pipelineRuns {
+		if isEarlier(&pipelineRuns[i], pipelineRun) {
+			base = &pipelineRuns[i]
+			break
+		}
+	}
+	if base == nil {
+		report = sarif.Aggregate(pipelineRun.Name, "", findings, nil)
+		return
+	}
+	var baseFindings []sarif.Finding
+	if baseFindings, err = h.getFindings(ctx, base); err == nil {
+		report = sarif.Aggregate(pipelineRun.Name, base.Name, findings, baseFindings)
+	}
+	return
+}
+
+// listRecordedRuns returns the PipelineRuns of a Pipeline which have the findings, from the latest one.
+// The PipelineRuns of all branches are returned if the branch is empty and not exact.
+func (h *handler) listRecordedRuns(ctx context.Context, namespace, pipeline, branch string, exact bool) (
+	pipelineRuns []v1alpha3.PipelineRun, err error) {
+	runList := &v1alpha3.PipelineRunList{}
+	if err = h.client.List(ctx, runList, client.InNamespace(namespace),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipeline}); err != nil {
+		return
+	}
+	for _, item := range runList.Items {
+		if item.Annotations[v1alpha3.PipelineRunFindingsAnnoKey] == "" {
+			continue
+		}
+		if (branch != "" || exact) && getRefName(&item) != branch {
+			continue
+		}
+		pipelineRuns = append(pipelineRuns, item)
+	}
+	sort.SliceStable(pipelineRuns, func(i, j int) bool {
+		return isEarlier(&pipelineRuns[j], &pipelineRuns[i])
+	})
+	return
+}
+
+// getFindings returns the stored findings of a PipelineRun, it's nil if there are no findings
+func (h *handler) getFindings(ctx context.Context, pipelineRun *v1alpha3.PipelineRun) (findings []sarif.Finding, err error) {
+	cmName := pipelineRun.Annotations[v1alpha3.PipelineRunFindingsAnnoKey]
+	if cmName == "" {
+		return
+	}
+	cm := &v1.ConfigMap{}
+	if err = h.client.Get(ctx, client.ObjectKey{Namespace: pipelineRun.Namespace, Name: cmName}, cm); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	findings = []sarif.Finding{}
+	err = json.Unmarshal([]byte(cm.Data[sarif.ConfigMapKeyFindings]), &findings)
+	return
+}
+
+// storeFindings stores the findings into a ConfigMap which is owned by the PipelineRun, then marks the PipelineRun
+func (h *handler) storeFindings(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, findings []sarif.Finding) (err error) {
+	var data []byte
+	if data, err = json.Marshal(findings); err != nil {
+		return
+	}
+	cm := &v1.ConfigMap{Data: map[string]string{sarif.ConfigMapKeyFindings: string(data)}}
+	cm.Namespace = pipelineRun.Namespace
+	cm.Name = sarif.GetConfigMapName(pipelineRun)
+	if err = controllerutil.SetControllerReference(pipelineRun, cm, h.client.Scheme()); err != nil {
+		return
+	}
+	if err = h.client.Create(ctx, cm); apierrors.IsAlreadyExists(err) {
+		err = h.client.Update(ctx, cm)
+	}
+	if err != nil || pipelineRun.Annotations[v1alpha3.PipelineRunFindingsAnnoKey] == cm.Name {
+		return
+	}
+
+	patch := client.MergeFrom(pipelineRun.DeepCopy())
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunFindingsAnnoKey] = cm.Name
+	err = h.client.Patch(ctx, pipelineRun, patch)
+	return
+}
+
+// annotate comments the new findings on the pull request which the PipelineRun builds
+func (h *handler) annotate(ctx context.Context, pipelineRun *v1alpha3.PipelineRun, report *sarif.Report) (err error) {
+	comment := sarif.Comment(report)
+	if comment == "" {
+		return
+	}
+	spec := pipelineRun.Spec.PipelineSpec
+	if !pipelineRun.Spec.IsMultiBranchPipeline() || spec.MultiBranchPipeline == nil || pipelineRun.Spec.SCM == nil ||
+		!pipelineRun.Spec.SCM.IsPullRequest() {
+		return errors.New("the PipelineRun does not build a pull request")
+	}
+	matches := pullRequestNumberPattern.FindStringSubmatch(pipelineRun.Spec.SCM.RefName)
+	if matches == nil {
+		return fmt.Errorf("invalid pull request '%s'", pipelineRun.Spec.SCM.RefName)
+	}
+	number, _ := strconv.Atoi(matches[1])
+
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(spec.MultiBranchPipeline); err != nil {
+		return
+	}
+	var secretRef *v1.SecretReference
+	if credentialID := spec.MultiBranchPipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: pipelineRun.Namespace, Name: credentialID}
+	}
+	var scmClient *scm.Client
+	if scmClient, err = h.scmClientFactory(provider, server, secretRef); err == nil {
+		_, _, err = scmClient.PullRequests.CreateComment(ctx, repo, number, &scm.CommentInput{Body: comment})
+	}
+	return
+}
+
+// getRefName returns the branch or pull request which the PipelineRun builds, it's empty for the Pipelines without SCM
+func getRefName(pipelineRun *v1alpha3.PipelineRun) string {
+	if pipelineRun.Spec.SCM == nil {
+		return ""
+	}
+	return pipelineRun.Spec.SCM.RefName
+}
+
+// isEarlier returns true if a PipelineRun was created before another one
+func isEarlier(a, b *v1alpha3.PipelineRun) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package findings
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/sarif"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;patch
+
+// RegisterRoutes registers the APIs of the static analysis findings which are uploaded as SARIF reports
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	h := newHandler(c)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/pipelineruns/{pipelinerun}/sarif").
+		To(h.uploadReport).
+		Doc("Upload a SARIF 2.1.0 report of a PipelineRun. The findings of the same tool are replaced, and the "+
+			"findings of the other tools are kept").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("annotate", "Comment the new findings on the pull request if it is true")).
+		Consumes(restful.MIME_JSON).
+		Returns(http.StatusOK, api.StatusOK, sarif.Report{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/findings").
+		To(h.getPipelineRunFindings).
+		Doc("Get the findings of a PipelineRun, compared with the previous PipelineRun of the same branch").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, sarif.Report{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/findings").
+		To(h.getPipelineFindings).
+		Doc("Get the findings of the latest PipelineRun of a Pipeline which uploaded the SARIF reports, "+
+			"compared with the previous one of the same branch").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Param(ws.QueryParameter("branch", "The branch of a multi-branch Pipeline")).
+		Returns(http.StatusOK, api.StatusOK, sarif.Report{}))
+}
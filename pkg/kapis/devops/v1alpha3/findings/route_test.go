@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package findings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/sarif"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func sarifLog(results ...string) string {
+	return `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"lint"}},"results":[` +
+		strings.Join(results, ",") + `]}]}`
+}
+
+func sarifResult(rule, level, file string) string {
+	return `{"ruleId":"` + rule + `","level":"` + level + `","message":{"text":"` + rule + `"},` +
+		`"locations":[{"physicalLocation":{"artifactLocation":{"uri":"` + file + `"},"region":{"startLine":1}}}]}`
+}
+
+func TestFindingsAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	newRun := func(name string, created time.Time) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, UID: types.UID(name),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+				CreationTimestamp: metav1.NewTime(created)},
+			Spec: v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "main"}},
+		}
+	}
+	now := time.Now()
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(newRun("run-1", now.Add(-time.Hour)),
+		newRun("run-2", now.Add(-time.Minute))).Build()
+
+	container := restful.NewContainer()
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c)
+	container.Add(ws)
+	request := func(method, uri, body string) (*httptest.ResponseRecorder, *sarif.Report) {
+		httpRequest, _ := http.NewRequest(method, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", restful.MIME_JSON)
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		report := &sarif.Report{}
+		if httpWriter.Code == http.StatusOK {
+			assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), report))
+		}
+		return httpWriter, report
+	}
+
+	// no findings were uploaded
+	resp, _ := request(http.MethodGet, "/namespaces/ns/pipelines/pipeline/findings", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp, _ = request(http.MethodGet, "/namespaces/ns/pipelineruns/run-1/findings", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, _ = request(http.MethodPost, "/namespaces/ns/pipelineruns/run-1/sarif", `{"version":"1.0.0"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp, _ = request(http.MethodPost, "/namespaces/ns/pipelineruns/missing/sarif", sarifLog())
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, report := request(http.MethodPost, "/namespaces/ns/pipelineruns/run-1/sarif",
+		sarifLog(sarifResult("a", "error", "a.go"), sarifResult("b", "warning", "b.go")))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "", report.Base)
+	assert.Equal(t, 2, report.New)
+
+	// the findings are stored in a ConfigMap which is owned by the PipelineRun
+	pipelineRun := &v1alpha3.PipelineRun{}
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "ns", Name: "run-1"}, pipelineRun))
+	assert.Equal(t, "run-1-findings", pipelineRun.Annotations[v1alpha3.PipelineRunFindingsAnnoKey])
+	cm := &v1.ConfigMap{}
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "ns", Name: "run-1-findings"}, cm))
+	assert.Len(t, cm.OwnerReferences, 1)
+
+	resp, report = request(http.MethodPost, "/namespaces/ns/pipelineruns/run-2/sarif",
+		sarifLog(sarifResult("a", "error", "a.go"), sarifResult("c", "error", "c.go")))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "run-1", report.Base)
+	assert.Equal(t, 1, report.New)
+	assert.Equal(t, 1, report.Existing)
+	assert.Equal(t, 1, report.Fixed)
+	assert.Equal(t, map[string]int{"error": 2}, report.Levels)
+
+	// the findings of the latest PipelineRun are returned
+	resp, report = request(http.MethodGet, "/namespaces/ns/pipelines/pipeline/findings?branch=main", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "run-2", report.PipelineRun)
+	resp, _ = request(http.MethodGet, "/namespaces/ns/pipelines/pipeline/findings?branch=dev", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, report = request(http.MethodGet, "/namespaces/ns/pipelineruns/run-1/findings", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, report.Total)
+
+	// the PipelineRun does not build a pull request, so the new findings can not be commented
+	resp, _ = request(http.MethodPost, "/namespaces/ns/pipelineruns/run-2/sarif?annotate=true", sarifLog(sarifResult("d", "note", "d.go")))
+	assert.NotEqual(t, http.StatusOK, resp.Code)
+}
@@ -30,6 +30,7 @@ import (
 	"kubesphere.io/devops/pkg/models/devops"
 	servererr "kubesphere.io/devops/pkg/server/errors"
 	"kubesphere.io/devops/pkg/server/params"
+	"kubesphere.io/devops/pkg/utils/secretutil"
 )
 
 type devopsHandler struct {
@@ -310,6 +311,10 @@ func (h *devopsHandler) CreateCredential(request *restful.Request, response *res
 		kapis.HandleBadRequest(response, request, err)
 		return
 	}
+	if err = secretutil.ValidateCredential(&obj); err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
 
 	if client, err := h.getDevOps(request); err == nil {
 		created, err := client.CreateCredentialObj(devops, &obj)
@@ -329,6 +334,10 @@ func (h *devopsHandler) UpdateCredential(request *restful.Request, response *res
 		kapis.HandleBadRequest(response, request, err)
 		return
 	}
+	if err = secretutil.ValidateCredential(&obj); err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
 
 	if client, err := h.getDevOps(request); err == nil {
 		updated, err := client.UpdateCredentialObj(devops, &obj)
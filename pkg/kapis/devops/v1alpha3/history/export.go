@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/history"
+	"kubesphere.io/devops/pkg/kapis"
+)
+
+const (
+	// FormatCSV is the export format of comma-separated values with a header line
+	FormatCSV = "csv"
+	// FormatNDJSON is the export format of newline-delimited JSON objects
+	FormatNDJSON = "ndjson"
+
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+
+	// exportPageSize is the number of records which are read from the history database at a time
+	exportPageSize = 500
+)
+
+// exportedRun is a row of the exported runs
+type exportedRun struct {
+	Namespace        string            `json:"namespace"`
+	Pipeline         string            `json:"pipeline"`
+	Name             string            `json:"name"`
+	RunID            string            `json:"runId"`
+	Branch           string            `json:"branch"`
+	Phase            v1alpha3.RunPhase `json:"phase"`
+	Trigger          string            `json:"trigger"`
+	Creator          string            `json:"creator"`
+	Committer        string            `json:"committer"`
+	StartTime        string            `json:"startTime"`
+	CompletionTime   string            `json:"completionTime"`
+	DurationInMillis *int64            `json:"durationInMillis"`
+}
+
+var csvHeader = []string{"namespace", "pipeline", "name", "runId", "branch", "phase", "trigger", "creator", "committer",
+	"startTime", "completionTime", "durationInMillis"}
+
+func newExportedRun(record *history.Record) *exportedRun {
+	run := &exportedRun{
+		Namespace:      record.Namespace,
+		Pipeline:       record.Pipeline,
+		Name:           record.Name,
+		RunID:          record.RunID,
+		Branch:         record.RefName,
+		Phase:          record.Phase,
+		Trigger:        record.Trigger,
+		Creator:        record.Creator,
+		Committer:      record.Committer,
+		StartTime:      formatTime(record.StartTime),
+		CompletionTime: formatTime(record.CompletionTime),
+	}
+	if record.StartTime != nil && record.CompletionTime != nil {
+		duration := record.CompletionTime.Sub(*record.StartTime).Milliseconds()
+		run.DurationInMillis = &duration
+	}
+	return run
+}
+
+func (r *exportedRun) csvRow() []string {
+	duration := ""
+	if r.DurationInMillis != nil {
+		duration = strconv.FormatInt(*r.DurationInMillis, 10)
+	}
+	return []string{r.Namespace, r.Pipeline, r.Name, r.RunID, r.Branch, string(r.Phase), r.Trigger, r.Creator,
+		r.Committer, r.StartTime, r.CompletionTime, duration}
+}
+
+// runWriter writes the exported runs in a format
+type runWriter interface {
+	write(run *exportedRun) error
+	// flush sends the buffered runs to the client
+	flush() error
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := &csvWriter{writer: csv.NewWriter(w)}
+	return writer, writer.writer.Write(csvHeader)
+}
+
+func (w *csvWriter) write(run *exportedRun) error {
+	return w.writer.Write(run.csvRow())
+}
+
+func (w *csvWriter) flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) write(run *exportedRun) error {
+	// the encoder terminates each value with a newline
+	return w.encoder.Encode(run)
+}
+
+func (w *ndjsonWriter) flush() error {
+	return nil
+}
+
+// exportRecords streams the archived PipelineRuns of a namespace as CSV or NDJSON from the latest one
+func (h *handler) exportRecords(req *restful.Request, resp *restful.Response) {
+	format := req.QueryParameter("format")
+	if format == "" {
+		format = FormatCSV
+	}
+	contentType := map[string]string{FormatCSV: mimeCSV, FormatNDJSON: mimeNDJSON}[format]
+	if contentType == "" {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("unsupported format %q, it should be %s or %s",
+			format, FormatCSV, FormatNDJSON))
+		return
+	}
+
+	namespace := req.PathParameter("namespace")
+	historyQuery := history.Query{
+		Namespace: namespace,
+		Pipeline:  req.QueryParameter("pipeline"),
+		RefName:   req.QueryParameter("branch"),
+		Phase:     v1alpha3.RunPhase(req.QueryParameter("phase")),
+		Limit:     exportPageSize,
+	}
+	var err error
+	if historyQuery.Since, err = parseTime(req.QueryParameter("since")); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if historyQuery.Until, err = parseTime(req.QueryParameter("until")); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	// read the first page before writing the header, then the errors could still be returned in the status code
+	ctx := req.Request.Context()
+	records, _, err := h.store.List(ctx, historyQuery)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	resp.AddHeader("Content-Type", contentType+"; charset=utf-8")
+	resp.AddHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("%s-runs.%s", namespace, format),
+	}))
+	resp.WriteHeader(http.StatusOK)
+
+	var writer runWriter
+	if format == FormatCSV {
+		writer, err = newCSVWriter(resp)
+	} else {
+		writer = &ndjsonWriter{encoder: json.NewEncoder(resp)}
+	}
+	for err == nil {
+		if err = writeRecords(writer, records); err != nil || len(records) < exportPageSize {
+			break
+		}
+		resp.Flush()
+
+		historyQuery.Offset += len(records)
+		records, _, err = h.store.List(ctx, historyQuery)
+	}
+	if err != nil {
+		// it's too late to change the status code
+		klog.Errorf("failed to export the history of namespace '%s', error: %v", namespace, err)
+	}
+}
+
+func writeRecords(writer runWriter, records []history.Record) (err error) {
+	for i := range records {
+		if err = writer.write(newExportedRun(&records[i])); err != nil {
+			return
+		}
+	}
+	return writer.flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/query"
+	"kubesphere.io/devops/pkg/client/history"
+	"kubesphere.io/devops/pkg/kapis"
+)
+
+type handler struct {
+	store history.Interface
+}
+
+func newHandler(store history.Interface) *handler {
+	return &handler{store: store}
+}
+
+func (h *handler) listRecords(req *restful.Request, resp *restful.Response) {
+	pagination := query.ParseQueryParameter(req).Pagination
+	historyQuery := history.Query{
+		Namespace: req.PathParameter("namespace"),
+		Pipeline:  req.PathParameter("pipeline"),
+		RefName:   req.QueryParameter("branch"),
+		Phase:     v1alpha3.RunPhase(req.QueryParameter("phase")),
+		Limit:     pagination.Limit,
+		Offset:    pagination.Offset,
+	}
+
+	var err error
+	if historyQuery.Since, err = parseTime(req.QueryParameter("since")); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if historyQuery.Until, err = parseTime(req.QueryParameter("until")); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	records, total, err := h.store.List(req.Request.Context(), historyQuery)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	items := make([]interface{}, 0, len(records))
+	for i := range records {
+		items = append(items, records[i])
+	}
+	_ = resp.WriteEntity(api.NewListResult(items, total))
+}
+
+func (h *handler) getRecord(req *restful.Request, resp *restful.Response) {
+	record, err := h.store.Get(req.Request.Context(), req.PathParameter("namespace"), req.PathParameter("pipelinerun"))
+	if err == history.ErrNotFound {
+		kapis.HandleNotFound(resp, req, err)
+		return
+	} else if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(record)
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q, it should be in RFC3339 format", value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/apiserver/query"
+	"kubesphere.io/devops/pkg/client/history"
+)
+
+// RegisterRoutes registers the routes to query the archived PipelineRuns, nothing is registered if the history
+// database is not configured
+func RegisterRoutes(ws *restful.WebService, store history.Interface) {
+	if store == nil {
+		return
+	}
+	h := newHandler(store)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/history").
+		To(h.listRecords).
+		Doc("List the archived PipelineRuns of a Pipeline from the latest one, including the ones removed from the cluster").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Param(ws.QueryParameter("branch", "The SCM reference name of the PipelineRuns, such as main")).
+		Param(ws.QueryParameter("phase", "The phase of the PipelineRuns, such as Succeeded or Failed")).
+		Param(ws.QueryParameter("since", "Only the PipelineRuns completed at or after this RFC3339 time")).
+		Param(ws.QueryParameter("until", "Only the PipelineRuns completed before this RFC3339 time")).
+		Param(ws.QueryParameter(query.ParameterPage, "page").DataFormat("page=%d").DefaultValue("page=1")).
+		Param(ws.QueryParameter(query.ParameterLimit, "limit").DataType("integer").DefaultValue("10")).
+		Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{}}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/history").
+		To(h.getRecord).
+		Doc("Get the archived summary of a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, history.Record{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/history/export").
+		To(h.exportRecords).
+		Doc("Export the archived PipelineRuns of a namespace from the latest one, for the reports in external tools. "+
+			"The CSV has a header line, the NDJSON has a JSON object in each line").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipelines")).
+		Param(ws.QueryParameter("format", "The format of the export, csv or ndjson").DefaultValue(FormatCSV)).
+		Param(ws.QueryParameter("pipeline", "The name of the Pipeline, all the Pipelines are exported if it's empty")).
+		Param(ws.QueryParameter("branch", "The SCM reference name of the PipelineRuns, such as main")).
+		Param(ws.QueryParameter("phase", "The phase of the PipelineRuns, such as Succeeded or Failed")).
+		Param(ws.QueryParameter("since", "Only the PipelineRuns completed at or after this RFC3339 time")).
+		Param(ws.QueryParameter("until", "Only the PipelineRuns completed before this RFC3339 time")).
+		Produces(mimeCSV, mimeNDJSON).
+		Returns(http.StatusOK, api.StatusOK, nil))
+}
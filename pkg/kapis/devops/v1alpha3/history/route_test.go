@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/client/history"
+	"kubesphere.io/devops/pkg/client/history/fake"
+)
+
+func TestHistoryAPI(t *testing.T) {
+	older := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	store := fake.NewFakeHistory(history.Record{
+		UID: "a", Namespace: "ns", Pipeline: "pipeline", Name: "pr-a", RefName: "main",
+		Phase: v1alpha3.Succeeded, CompletionTime: &older,
+	}, history.Record{
+		UID: "b", Namespace: "ns", Pipeline: "pipeline", Name: "pr-b", RefName: "dev",
+		Phase: v1alpha3.Failed, CompletionTime: &newer,
+	}, history.Record{
+		UID: "c", Namespace: "ns", Pipeline: "another", Name: "pr-c", CompletionTime: &newer,
+	})
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, store)
+	container.Add(ws)
+
+	tests := []struct {
+		name      string
+		uri       string
+		wantCode  int
+		wantNames []string
+		wantTotal int
+	}{{
+		name:      "all records of a pipeline",
+		uri:       "/namespaces/ns/pipelines/pipeline/history",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"pr-b", "pr-a"},
+		wantTotal: 2,
+	}, {
+		name:      "paging",
+		uri:       "/namespaces/ns/pipelines/pipeline/history?page=2&limit=1",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"pr-a"},
+		wantTotal: 2,
+	}, {
+		name:      "filter by branch and phase",
+		uri:       "/namespaces/ns/pipelines/pipeline/history?branch=dev&phase=Failed",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"pr-b"},
+		wantTotal: 1,
+	}, {
+		name:      "filter by time",
+		uri:       "/namespaces/ns/pipelines/pipeline/history?since=2022-01-01T00:30:00Z&until=2022-01-02T00:00:00Z",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"pr-b"},
+		wantTotal: 1,
+	}, {
+		name:     "invalid time",
+		uri:      "/namespaces/ns/pipelines/pipeline/history?since=yesterday",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpWriter := dispatch(container, tt.uri)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			result := &struct {
+				Items      []history.Record `json:"items"`
+				TotalItems int              `json:"totalItems"`
+			}{}
+			assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), result))
+			assert.Equal(t, tt.wantTotal, result.TotalItems)
+			var names []string
+			for _, record := range result.Items {
+				names = append(names, record.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	httpWriter := dispatch(container, "/namespaces/ns/pipelineruns/pr-a/history")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	record := &history.Record{}
+	assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), record))
+	assert.Equal(t, "a", record.UID)
+
+	httpWriter = dispatch(container, "/namespaces/ns/pipelineruns/missing/history")
+	assert.Equal(t, http.StatusNotFound, httpWriter.Code)
+}
+
+func TestExportRecords(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	completion := start.Add(90 * time.Second)
+	store := fake.NewFakeHistory(history.Record{
+		UID: "a", Namespace: "ns", Pipeline: "pipeline", Name: "pr-a", RunID: "1", RefName: "main",
+		Phase: v1alpha3.Succeeded, Trigger: "webhook", Committer: "Alice, Bob", StartTime: &start, CompletionTime: &completion,
+	}, history.Record{
+		UID: "b", Namespace: "ns", Pipeline: "another", Name: "pr-b", Phase: v1alpha3.Failed, Creator: "admin",
+	}, history.Record{
+		UID: "c", Namespace: "other", Pipeline: "pipeline", Name: "pr-c",
+	})
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, store)
+	container.Add(ws)
+
+	httpWriter := dispatch(container, "/namespaces/ns/history/export")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", httpWriter.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=ns-runs.csv`, httpWriter.Header().Get("Content-Disposition"))
+	assert.Equal(t, "namespace,pipeline,name,runId,branch,phase,trigger,creator,committer,startTime,completionTime,durationInMillis\n"+
+		"ns,pipeline,pr-a,1,main,Succeeded,webhook,,\"Alice, Bob\",2022-01-01T00:00:00Z,2022-01-01T00:01:30Z,90000\n"+
+		"ns,another,pr-b,,,Failed,,admin,,,,\n", httpWriter.Body.String())
+
+	httpWriter = dispatch(container, "/namespaces/ns/history/export?format=ndjson&pipeline=pipeline")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", httpWriter.Header().Get("Content-Type"))
+	assert.Equal(t, `{"namespace":"ns","pipeline":"pipeline","name":"pr-a","runId":"1","branch":"main","phase":"Succeeded",`+
+		`"trigger":"webhook","creator":"","committer":"Alice, Bob","startTime":"2022-01-01T00:00:00Z",`+
+		`"completionTime":"2022-01-01T00:01:30Z","durationInMillis":90000}`+"\n", httpWriter.Body.String())
+
+	httpWriter = dispatch(container, "/namespaces/ns/history/export?phase=Running")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	assert.Equal(t, strings.Join(csvHeader, ",")+"\n", httpWriter.Body.String())
+
+	httpWriter = dispatch(container, "/namespaces/ns/history/export?format=xml")
+	assert.Equal(t, http.StatusBadRequest, httpWriter.Code)
+	httpWriter = dispatch(container, "/namespaces/ns/history/export?until=tomorrow")
+	assert.Equal(t, http.StatusBadRequest, httpWriter.Code)
+}
+
+func TestExportRecordsInPages(t *testing.T) {
+	var records []history.Record
+	for i := 0; i < exportPageSize+1; i++ {
+		completion := time.Unix(int64(i), 0)
+		records = append(records, history.Record{
+			UID: strconv.Itoa(i), Namespace: "ns", Pipeline: "pipeline", Name: fmt.Sprintf("pr-%d", i), CompletionTime: &completion,
+		})
+	}
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, fake.NewFakeHistory(records...))
+	container.Add(ws)
+
+	httpWriter := dispatch(container, "/namespaces/ns/history/export?format=ndjson")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	lines := strings.Split(strings.TrimSpace(httpWriter.Body.String()), "\n")
+	if assert.Len(t, lines, exportPageSize+1) {
+		assert.Contains(t, lines[0], fmt.Sprintf(`"name":"pr-%d"`, exportPageSize))
+		assert.Contains(t, lines[exportPageSize], `"name":"pr-0"`)
+	}
+}
+
+func TestRegisterRoutesWithoutStore(t *testing.T) {
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, nil)
+	assert.Empty(t, ws.Routes())
+}
+
+func dispatch(container *restful.Container, uri string) *httptest.ResponseRecorder {
+	httpRequest, _ := http.NewRequest(http.MethodGet, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+	httpWriter := httptest.NewRecorder()
+	container.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issue
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/issue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+}
+
+func (h *handler) listPipelineRuns(req *restful.Request, resp *restful.Response) {
+	key := req.PathParameter("issue")
+	if keys := issue.ParseKeys(nil, key); len(keys) != 1 || keys[0] != key {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("invalid issue key '%s'", key))
+		return
+	}
+	traces, err := issue.ListTraces(req.Request.Context(), h.client, req.PathParameter("namespace"), key)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(traces)
+}
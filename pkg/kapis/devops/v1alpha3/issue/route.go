@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issue
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/issue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list
+
+// RegisterRoutes registers the APIs of the traceability between the issues and the PipelineRuns
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	registerRoutes(ws, &handler{client: c})
+}
+
+func registerRoutes(ws *restful.WebService, h *handler) {
+	ws.Route(ws.GET("/namespaces/{namespace}/issues/{issue}/pipelineruns").
+		To(h.listPipelineRuns).
+		Doc("List the PipelineRuns which are linked to an issue, from the latest one. It tells where the changes of "+
+			"the issue were built, released and deployed").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRuns")).
+		Param(ws.PathParameter("issue", "Key of the issue, such as DEVOPS-12")).
+		Returns(http.StatusOK, api.StatusOK, []issue.Trace{}))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/issue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIssueAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+
+	created := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	newRun := func(name string, age time.Duration, issues string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name,
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+				Annotations: map[string]string{
+					v1alpha3.PipelineRunIssuesAnnoKey:       issues,
+					v1alpha3.JenkinsPipelineRunIDAnnoKey:    "1",
+					v1alpha3.PipelineRunReleaseTagAnnoKey:   "v1.0.1",
+					v1alpha3.PipelineRunEnvironmentsAnnoKey: "staging",
+				}},
+			Spec:   v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "master"}},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		newRun("run-1", time.Hour, "DEVOPS-1"),
+		newRun("run-2", time.Minute, "DEVOPS-1,DEVOPS-2"),
+		newRun("run-3", time.Minute, "DEVOPS-2")).Build()
+
+	container := restful.NewContainer()
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	registerRoutes(ws, &handler{client: c})
+	container.Add(ws)
+	request := func(uri string) (*httptest.ResponseRecorder, []issue.Trace) {
+		httpRequest, _ := http.NewRequest(http.MethodGet, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		var traces []issue.Trace
+		if httpWriter.Code == http.StatusOK {
+			assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), &traces))
+		}
+		return httpWriter, traces
+	}
+
+	resp, traces := request("/namespaces/ns/issues/DEVOPS-1/pipelineruns")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	if assert.Len(t, traces, 2) {
+		assert.Equal(t, "run-2", traces[0].PipelineRun)
+		assert.Equal(t, "run-1", traces[1].PipelineRun)
+		assert.Equal(t, issue.Trace{PipelineRun: "run-1", Pipeline: "app", RunID: "1", Branch: "master",
+			Phase: v1alpha3.Succeeded, Environments: []string{"staging"}, ReleaseTag: "v1.0.1"}, traces[1])
+	}
+
+	resp, traces = request("/namespaces/ns/issues/DEVOPS-3/pipelineruns")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, traces)
+	assert.Equal(t, "[]", resp.Body.String())
+
+	resp, _ = request("/namespaces/ns/issues/invalid/pipelineruns")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsscript
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/jenkinsscript"
+)
+
+// RunPayload contains the parameters of a script, they are passed to the script as the map 'params'
+type RunPayload struct {
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+type handler struct {
+	runner     *jenkinsscript.Runner
+	authorizer authorizer.Authorizer
+}
+
+func newHandler(runner *jenkinsscript.Runner, authz authorizer.Authorizer) *handler {
+	return &handler{runner: runner, authorizer: authz}
+}
+
+// authorize checks if the current user is allowed to do the verb, the response is written if not.
+// All the requests are forbidden without an authorizer, which is the case unless the auth mode is verified.
+func (h *handler) authorize(req *restful.Request, resp *restful.Response, verb, resource, name string) (
+	username string, allowed bool) {
+	if h.authorizer == nil {
+		kapis.HandleForbidden(resp, req, errors.New("the Jenkins scripts are disabled unless the auth mode is verified"))
+		return
+	}
+	ctx := req.Request.Context()
+	user, ok := apiserverrequest.UserFrom(ctx)
+	if !ok || user == nil || user.GetName() == "" {
+		kapis.HandleUnauthorized(resp, req, errors.New("a login user is required to access the Jenkins scripts"))
+		return
+	}
+
+	decision, reason, err := h.authorizer.Authorize(ctx, authorizer.AttributesRecord{
+		User:            user,
+		Verb:            verb,
+		APIGroup:        api.GroupName,
+		APIVersion:      "v1alpha3",
+		Resource:        resource,
+		Name:            name,
+		ResourceRequest: true,
+	})
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if decision != authorizer.DecisionAllow {
+		kapis.HandleForbidden(resp, req, fmt.Errorf("user '%s' cannot %s %s '%s': %s", user.GetName(), verb,
+			resource, name, reason))
+		return
+	}
+	return user.GetName(), true
+}
+
+func (h *handler) listScripts(req *restful.Request, resp *restful.Response) {
+	if _, allowed := h.authorize(req, resp, "list", "jenkinsscripts", ""); !allowed {
+		return
+	}
+	scripts, err := h.runner.ListScripts(req.Request.Context())
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(scripts)
+}
+
+func (h *handler) run(req *restful.Request, resp *restful.Response) {
+	name := req.PathParameter("script")
+	username, allowed := h.authorize(req, resp, "execute", "jenkinsscripts", name)
+	if !allowed {
+		return
+	}
+	payload := &RunPayload{}
+	if err := req.ReadEntity(payload); err != nil && err != io.EOF {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	execution, err := h.runner.Run(req.Request.Context(), name, payload.Parameters, username)
+	if err != nil && execution == nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	// the failed execution is returned as well, the error is in it
+	_ = resp.WriteEntity(execution)
+}
+
+func (h *handler) listExecutions(req *restful.Request, resp *restful.Response) {
+	if _, allowed := h.authorize(req, resp, "list", "jenkinsscriptexecutions", ""); !allowed {
+		return
+	}
+	executions, err := h.runner.ListExecutions(req.Request.Context())
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(executions)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsscript
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/models/jenkinsscript"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;delete
+
+// RegisterRoutes registry the handlers of the approved Jenkins scripts. The requests are authorized by the
+// authorizer, such as the SubjectAccessReview one, even if the apiserver allows all the authenticated requests.
+// All the requests are forbidden if the authorizer is nil. The audit records are kept unlimited unless
+// maxAuditSize is positive.
+func RegisterRoutes(ws *restful.WebService, c client.Client, jenkins devops.ScriptOperator, authz authorizer.Authorizer,
+	maxAuditSize int) {
+	runner := jenkinsscript.NewRunner(c, jenkins)
+	runner.MaxAuditSize = maxAuditSize
+	h := newHandler(runner, authz)
+
+	ws.Route(ws.GET("/jenkinsscripts").
+		To(h.listScripts).
+		Doc("List the approved Groovy scripts which could be run on Jenkins, it requires the verb 'list' of "+
+			"the resource 'jenkinsscripts'").
+		Returns(http.StatusOK, api.StatusOK, []jenkinsscript.Script{}))
+
+	ws.Route(ws.POST("/jenkinsscripts/{script}/run").
+		To(h.run).
+		Doc("Run an approved Groovy script on Jenkins, it requires the verb 'execute' of the resource "+
+			"'jenkinsscripts' with the script name").
+		Param(ws.PathParameter("script", "Name of the approved script")).
+		Reads(RunPayload{}).
+		Returns(http.StatusOK, api.StatusOK, jenkinsscript.Execution{}))
+
+	ws.Route(ws.GET("/jenkinsscriptexecutions").
+		To(h.listExecutions).
+		Doc("List the audit records of running the Jenkins scripts, it requires the verb 'list' of the resource "+
+			"'jenkinsscriptexecutions'").
+		Returns(http.StatusOK, api.StatusOK, []jenkinsscript.Execution{}))
+}
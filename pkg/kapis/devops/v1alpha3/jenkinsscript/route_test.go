@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsscript
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	ksruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/models/jenkinsscript"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestJenkinsScriptAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: jenkinsscript.DefaultNamespace, Name: jenkinsscript.DefaultAllowlistName},
+		Data:       map[string]string{"reload": "Jenkins.instance.reload()"},
+	}).Build()
+	jenkins := fakedevops.New()
+	jenkins.Data = map[string]interface{}{"scriptOutput": "Result: done"}
+
+	// the admin is allowed to do anything, the viewer is allowed to list the scripts only
+	authz := authorizer.AuthorizerFunc(func(_ context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+		switch {
+		case a.GetUser().GetName() == "admin", a.GetUser().GetName() == "viewer" && a.GetVerb() == "list":
+			return authorizer.DecisionAllow, "", nil
+		}
+		return authorizer.DecisionNoOpinion, "no RBAC rule", nil
+	})
+
+	container := restful.NewContainer()
+	ws := ksruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c, jenkins, authz, 0)
+	container.Add(ws)
+	container.Filter(func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		if name := req.HeaderParameter("X-User"); name != "" {
+			req.Request = req.Request.WithContext(apiserverrequest.WithUser(req.Request.Context(), &user.DefaultInfo{Name: name}))
+		}
+		chain.ProcessFilter(req, resp)
+	})
+	request := func(method, uri, body, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/kapis/devops.kubesphere.io/v1alpha3"+uri, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if username != "" {
+			req.Header.Set("X-User", username)
+		}
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/jenkinsscripts", "", "").Code)
+
+	recorder := request(http.MethodGet, "/jenkinsscripts", "", "viewer")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var scripts []jenkinsscript.Script
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &scripts))
+	assert.Len(t, scripts, 1)
+
+	// the viewer cannot run scripts
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/jenkinsscripts/reload/run", "", "viewer").Code)
+	assert.Nil(t, jenkins.Data["scripts"])
+
+	recorder = request(http.MethodPost, "/jenkinsscripts/reload/run", `{"parameters":{"a":"b"}}`, "admin")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	execution := &jenkinsscript.Execution{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), execution))
+	assert.Equal(t, jenkinsscript.PhaseSucceeded, execution.Phase)
+	assert.Equal(t, "admin", execution.User)
+	assert.Equal(t, "Result: done", execution.Output)
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "/jenkinsscripts/fake/run", "", "admin").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/jenkinsscripts/reload/run", "invalid", "admin").Code)
+
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/jenkinsscriptexecutions", "", "other").Code)
+	recorder = request(http.MethodGet, "/jenkinsscriptexecutions", "", "admin")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var executions []jenkinsscript.Execution
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &executions))
+	if assert.Len(t, executions, 1) {
+		assert.Equal(t, execution.ID, executions[0].ID)
+	}
+}
+
+func TestWithoutAuthorizer(t *testing.T) {
+	ws := ksruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, fake.NewClientBuilder().Build(), fakedevops.New(), nil, 0)
+	container := restful.NewContainer()
+	container.Add(ws)
+
+	req := httptest.NewRequest(http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3/jenkinsscripts", nil)
+	req = req.WithContext(apiserverrequest.WithUser(req.Context(), &user.DefaultInfo{Name: "admin"}))
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
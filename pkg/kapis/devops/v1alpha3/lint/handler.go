@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/lint"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+}
+
+// Payload represents the content which needs to be validated
+type Payload struct {
+	Data string `json:"data"`
+}
+
+func (h *handler) lint(req *restful.Request, resp *restful.Response) {
+	payload := &Payload{}
+	if err := req.ReadEntity(payload); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if payload.Data == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the content is empty"))
+		return
+	}
+
+	// the credentials are checked only in the namespace which is authorized by the path
+	linter := &lint.Linter{
+		Client:    h.client,
+		Namespace: req.PathParameter("namespace"),
+	}
+	var result *lint.Result
+	var err error
+	switch contentType := req.QueryParameter(TypeQueryParameter.Data().Name); contentType {
+	case "", TypeJenkinsfile:
+		result, err = linter.LintJenkinsfile(req.Request.Context(), payload.Data)
+	case TypeYAML:
+		result, err = linter.LintPipeline(req.Request.Context(), payload.Data)
+	default:
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("unknown type '%s'", contentType))
+		return
+	}
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteAsJson(result)
+}
+
+// DryRunPayload represents the Jenkinsfile and the inputs of a dry run
+type DryRunPayload struct {
+	// Data is the content of the Jenkinsfile, it's optional when running against an existing Pipeline
+	Data string `json:"data,omitempty"`
+	lint.DryRunOptions
+}
+
+func (h *handler) dryRun(req *restful.Request, resp *restful.Response) {
+	payload := &DryRunPayload{}
+	if err := req.ReadEntity(payload); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if payload.Data == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the content is empty"))
+		return
+	}
+	_ = resp.WriteAsJson(lint.DryRunJenkinsfile(payload.Data, payload.DryRunOptions))
+}
+
+func (h *handler) dryRunPipeline(req *restful.Request, resp *restful.Response) {
+	payload := &DryRunPayload{}
+	if err := req.ReadEntity(payload); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(req.Request.Context(), types.NamespacedName{
+		Namespace: req.PathParameter("namespace"),
+		Name:      req.PathParameter("pipeline"),
+	}, pipeline); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	// the Jenkinsfile in the payload is used to preview the changes of the Pipeline
+	if payload.Data != "" && pipeline.Spec.Pipeline != nil {
+		pipeline.Spec.Pipeline.Jenkinsfile = payload.Data
+	}
+
+	plan, err := lint.DryRunPipeline(pipeline, payload.DryRunOptions)
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	_ = resp.WriteAsJson(plan)
+}
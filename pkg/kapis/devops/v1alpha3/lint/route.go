@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/lint"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// TypeQueryParameter is the query parameter of the content type
+	TypeQueryParameter = restful.QueryParameter("type", "The type of the content, allowed values: jenkinsfile and yaml").
+		DefaultValue(TypeJenkinsfile)
+)
+
+const (
+	// TypeJenkinsfile indicates the content is a Jenkinsfile
+	TypeJenkinsfile = "jenkinsfile"
+	// TypeYAML indicates the content is a Pipeline in YAML format
+	TypeYAML = "yaml"
+)
+
+// RegisterRoutes registry the handlers of the Pipeline linter
+func RegisterRoutes(service *restful.WebService, c client.Client) {
+	h := &handler{client: c}
+	service.Route(service.POST("/lint").
+		To(h.lint).
+		Param(TypeQueryParameter).
+		Reads(&Payload{}, "The content of the Jenkinsfile or Pipeline should be in the 'data' field").
+		Doc("Validate a Jenkinsfile or Pipeline without creating any resources, the credentials are not checked").
+		Returns(http.StatusOK, api.StatusOK, lint.Result{}))
+
+	service.Route(service.POST("/namespaces/{namespace}/lint").
+		To(h.lint).
+		Param(service.PathParameter("namespace", "The DevOps project which the referenced credentials belong to")).
+		Param(TypeQueryParameter).
+		Reads(&Payload{}, "The content of the Jenkinsfile or Pipeline should be in the 'data' field").
+		Doc("Validate a Jenkinsfile or Pipeline, and check the referenced credentials in a DevOps project").
+		Returns(http.StatusOK, api.StatusOK, lint.Result{}))
+
+	service.Route(service.POST("/dryrun").
+		To(h.dryRun).
+		Reads(&DryRunPayload{}, "The content of the Jenkinsfile should be in the 'data' field").
+		Doc("Predict the execution plan of a Jenkinsfile by the branch and parameters without executing any steps").
+		Returns(http.StatusOK, api.StatusOK, lint.Plan{}))
+
+	service.Route(service.POST("/namespaces/{namespace}/pipelines/{pipeline}/dryrun").
+		To(h.dryRunPipeline).
+		Param(service.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(service.PathParameter("pipeline", "Name of the Pipeline")).
+		Reads(&DryRunPayload{}, "The Jenkinsfile of the Pipeline is used if the 'data' field is empty").
+		Doc("Predict the execution plan of a Pipeline by the branch and parameters without executing any steps").
+		Returns(http.StatusOK, api.StatusOK, lint.Plan{}))
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeSchema "k8s.io/apimachinery/pkg/runtime/schema"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ksruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/lint"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	jenkinsfile := `{"data":"pipeline {\n agent any\n stages {\n stage('a') {\n steps {\n git credentialsId: 'git', url: 'x'\n }\n }\n }\n}"}`
+	type args struct {
+		api     string
+		getBody func() io.Reader
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		verify   func([]byte, *testing.T)
+	}{{
+		name: "invalid request body",
+		args: args{
+			api: "/lint",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`invalid`)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "empty content",
+		args: args{
+			api: "/lint",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{}`)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "unknown type",
+		args: args{
+			api: "/lint?type=fake",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(jenkinsfile)
+			},
+		},
+		wantCode: http.StatusBadRequest,
+	}, {
+		name: "valid Jenkinsfile without checking credentials",
+		args: args{
+			api: "/lint",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(jenkinsfile)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &lint.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.True(t, result.Valid)
+			assert.Empty(t, result.Issues)
+		},
+	}, {
+		name: "valid Jenkinsfile with an existing credential",
+		args: args{
+			api: "/namespaces/ns/lint?type=jenkinsfile",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(jenkinsfile)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &lint.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.True(t, result.Valid)
+		},
+	}, {
+		name: "missing credential",
+		args: args{
+			api: "/namespaces/other/lint",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(jenkinsfile)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &lint.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.False(t, result.Valid)
+			assert.Equal(t, []lint.Issue{{Severity: lint.SeverityError, Line: 6,
+				Message: "credential 'git' is not found in DevOps project 'other'"}}, result.Issues)
+		},
+	}, {
+		name: "the query parameter cannot choose the namespace of the credentials",
+		args: args{
+			api: "/lint?devops=other",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(jenkinsfile)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &lint.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.True(t, result.Valid)
+		},
+	}, {
+		name: "invalid YAML Pipeline",
+		args: args{
+			api: "/lint?type=yaml",
+			getBody: func() io.Reader {
+				return bytes.NewBufferString(`{"data":"metadata:\n  name: demo\nspec:\n  type: pipeline"}`)
+			},
+		},
+		wantCode: http.StatusOK,
+		verify: func(data []byte, t *testing.T) {
+			result := &lint.Result{}
+			assert.Nil(t, json.Unmarshal(data, result))
+			assert.False(t, result.Valid)
+			assert.Equal(t, "spec.pipeline", result.Issues[0].Field)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+				Type:       v1alpha3.SecretTypeBasicAuth,
+			}).Build()
+			ws := ksruntime.NewWebService(runtimeSchema.GroupVersion{Group: api.GroupName, Version: "v1alpha3"})
+			RegisterRoutes(ws, c)
+			container := restful.NewContainer()
+			container.Add(ws)
+
+			httpRequest, _ := http.NewRequest(http.MethodPost,
+				"http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+tt.args.api, tt.args.getBody())
+			httpRequest.Header.Set("Content-Type", "application/json")
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+
+			if tt.verify != nil {
+				tt.verify(httpWriter.Body.Bytes(), t)
+			}
+		})
+	}
+}
+
+func TestDryRunAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+
+	jenkinsfile := "pipeline {\n agent any\n stages {\n stage('a') {\n when { branch 'main' }\n steps { sh 'make' }\n }\n }\n}"
+	tests := []struct {
+		name     string
+		api      string
+		body     string
+		wantCode int
+		verify   func(*lint.Plan, *testing.T)
+	}{{
+		name:     "invalid request body",
+		api:      "/dryrun",
+		body:     "invalid",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "empty content",
+		api:      "/dryrun",
+		body:     `{"branch":"main"}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "dry run a Jenkinsfile",
+		api:      "/dryrun",
+		body:     `{"data":"pipeline {\n agent any\n stages {\n stage('a') {\n when { branch 'dev' }\n steps { sh 'make' }\n }\n }\n}","branch":"main"}`,
+		wantCode: http.StatusOK,
+		verify: func(plan *lint.Plan, t *testing.T) {
+			assert.True(t, plan.Valid)
+			assert.Equal(t, lint.StageStatusSkipped, plan.Stages[0].Status)
+		},
+	}, {
+		name:     "dry run a Pipeline",
+		api:      "/namespaces/ns/pipelines/demo/dryrun",
+		body:     `{"branch":"main"}`,
+		wantCode: http.StatusOK,
+		verify: func(plan *lint.Plan, t *testing.T) {
+			assert.True(t, plan.Valid)
+			assert.Equal(t, lint.StageStatusRun, plan.Stages[0].Status)
+			assert.Equal(t, []string{"sh"}, plan.Stages[0].Steps)
+		},
+	}, {
+		name:     "dry run the changes of a Pipeline",
+		api:      "/namespaces/ns/pipelines/demo/dryrun",
+		body:     `{"data":"pipeline {","branch":"main"}`,
+		wantCode: http.StatusOK,
+		verify: func(plan *lint.Plan, t *testing.T) {
+			assert.False(t, plan.Valid)
+		},
+	}, {
+		name:     "Pipeline not found",
+		api:      "/namespaces/ns/pipelines/fake/dryrun",
+		body:     `{}`,
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "multi-branch Pipeline",
+		api:      "/namespaces/ns/pipelines/multi-branch/dryrun",
+		body:     `{}`,
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo"},
+				Spec: v1alpha3.PipelineSpec{
+					Type:     v1alpha3.NoScmPipelineType,
+					Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: jenkinsfile},
+				},
+			}, &v1alpha3.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "multi-branch"},
+				Spec:       v1alpha3.PipelineSpec{Type: v1alpha3.MultiBranchPipelineType},
+			}).Build()
+			ws := ksruntime.NewWebService(runtimeSchema.GroupVersion{Group: api.GroupName, Version: "v1alpha3"})
+			RegisterRoutes(ws, c)
+			container := restful.NewContainer()
+			container.Add(ws)
+
+			httpRequest, _ := http.NewRequest(http.MethodPost,
+				"http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+tt.api, bytes.NewBufferString(tt.body))
+			httpRequest.Header.Set("Content-Type", "application/json")
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			assert.Equal(t, tt.wantCode, httpWriter.Code)
+
+			if tt.verify != nil {
+				plan := &lint.Plan{}
+				assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), plan))
+				tt.verify(plan, t)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/logarchive"
+	"kubesphere.io/devops/pkg/models/logmask"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// moreDataHeader is true if the log of the PipelineRun has not been archived completely, like the Jenkins log API
+const moreDataHeader = "X-More-Data"
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// RegisterRoutes registers the route to read the archived logs of PipelineRuns, nothing is registered if the
+// object storage is not configured
+func RegisterRoutes(ws *restful.WebService, c client.Client, storage s3.Interface) {
+	if storage == nil {
+		return
+	}
+	h := &handler{client: c, storage: storage}
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/archived-log").
+		To(h.getArchivedLog).
+		Doc("Get the archived log of a PipelineRun from the object storage. The header "+moreDataHeader+
+			" is true if the PipelineRun is still being archived").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Produces("text/plain").
+		Returns(http.StatusOK, "The decompressed log text", nil))
+}
+
+type handler struct {
+	client  client.Client
+	storage s3.Interface
+}
+
+// maskWriter masks the secrets of each chunk before writing it
+type maskWriter struct {
+	masker *logmask.Masker
+	writer io.Writer
+}
+
+func (w *maskWriter) Write(data []byte) (int, error) {
+	if _, err := w.writer.Write(w.masker.Mask(data)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// getArchivedLog streams the archived chunks of a PipelineRun in order, the chunks archived before masking was
+// introduced are masked as well
+func (h *handler) getArchivedLog(req *restful.Request, resp *restful.Response) {
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(req.Request.Context(), client.ObjectKey{Namespace: req.PathParameter("namespace"),
+		Name: req.PathParameter("pipelinerun")}, pipelineRun); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	progress, err := logarchive.GetProgress(pipelineRun)
+	if err != nil {
+		kapis.HandleInternalError(resp, req, err)
+		return
+	}
+	if progress == nil {
+		kapis.HandleNotFound(resp, req, fmt.Errorf("the log of PipelineRun %s has not been archived", pipelineRun.Name))
+		return
+	}
+
+	masker, err := logmask.ForPipelineRun(req.Request.Context(), h.client, pipelineRun)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	resp.AddHeader("Content-Type", "text/plain; charset=utf-8")
+	resp.AddHeader(moreDataHeader, strconv.FormatBool(!progress.Completed))
+	resp.WriteHeader(http.StatusOK)
+	if err = progress.Copy(h.storage, &maskWriter{masker: masker, writer: resp}); err != nil {
+		// it's too late to change the status code
+		klog.Errorf("failed to read the archived log of PipelineRun '%s/%s', error: %v",
+			pipelineRun.Namespace, pipelineRun.Name, err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"kubesphere.io/devops/pkg/models/logarchive"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestArchivedLogAPI(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	archived := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "archived", UID: "uid"}}
+	archived.Spec.PipelineSpec = &v1alpha3.PipelineSpec{
+		Type: v1alpha3.NoScmPipelineType,
+		Pipeline: &v1alpha3.NoScmPipeline{
+			Jenkinsfile: `withCredentials([string(credentialsId: 'token', variable: 'TOKEN')]) {}`,
+		},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "token"},
+		Data:       map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("s3cr3t-value")},
+	}
+	progress := logarchive.NewProgress(archived, logarchive.CompressionGzip)
+	storage := fakes3.NewFakeS3()
+	assert.Nil(t, progress.Upload(storage, []byte("line 1\n"), 7))
+	// a chunk might be archived without masking by an old version
+	assert.Nil(t, progress.Upload(storage, []byte("token s3cr3t-value\n"), 26))
+	archived.Annotations = map[string]string{v1alpha3.PipelineRunLogArchiveAnnoKey: progress.String()}
+	notArchived := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "not-archived"}}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(archived, notArchived, secret).Build()
+
+	container := restful.NewContainer()
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c, storage)
+	container.Add(ws)
+	dispatch := func(uri string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(http.MethodGet, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	httpWriter := dispatch("/namespaces/ns/pipelineruns/archived/archived-log")
+	assert.Equal(t, http.StatusOK, httpWriter.Code)
+	assert.Equal(t, "line 1\ntoken ****\n", httpWriter.Body.String())
+	assert.Equal(t, "true", httpWriter.Header().Get(moreDataHeader))
+
+	httpWriter = dispatch("/namespaces/ns/pipelineruns/not-archived/archived-log")
+	assert.Equal(t, http.StatusNotFound, httpWriter.Code)
+	httpWriter = dispatch("/namespaces/ns/pipelineruns/missing/archived-log")
+	assert.Equal(t, http.StatusNotFound, httpWriter.Code)
+}
+
+func TestRegisterRoutesWithoutStorage(t *testing.T) {
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, nil, nil)
+	assert.Empty(t, ws.Routes())
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"kubesphere.io/devops/pkg/api/devops"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/template"
+	"kubesphere.io/devops/pkg/models/detector"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	"kubesphere.io/devops/pkg/models/onboarding"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Request is the repository to onboard
+type Request struct {
+	RepoURL      string `json:"repoURL"`
+	CredentialID string `json:"credentialId,omitempty"`
+	// Name is the name of the Pipeline, it's the name of the repository by default
+	Name string `json:"name,omitempty"`
+	// Branch is the branch to inspect and the base of the pull request, it's the default branch by default
+	Branch string `json:"branch,omitempty"`
+	// Language overrides the detected language if it's not empty
+	Language string `json:"language,omitempty"`
+	// Template is the name of the ClusterTemplate, it's selected by the language if it's empty
+	Template   string `json:"template,omitempty"`
+	ScriptPath string `json:"scriptPath,omitempty"`
+	// Parameters override the default parameters of the ClusterTemplate
+	Parameters      []template.Parameter `json:"parameters,omitempty"`
+	OpenPullRequest bool                 `json:"openPullRequest,omitempty"`
+}
+
+type handler struct {
+	client           client.Client
+	scmClientFactory git.SCMClientFactory
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c, scmClientFactory: git.NewSCMClientFactory(c)}
+}
+
+func (h *handler) onboard(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	namespace := req.PathParameter("namespace")
+	input := &Request{}
+	if err := req.ReadEntity(input); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	if input.RepoURL == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the repoURL is required"))
+		return
+	}
+	if input.Name == "" {
+		input.Name = onboarding.GetPipelineName(input.RepoURL)
+	}
+	if errs := validation.IsDNS1123Label(input.Name); len(errs) > 0 {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("invalid Pipeline name %q: %v", input.Name, errs))
+		return
+	}
+	if input.ScriptPath == "" {
+		input.ScriptPath = jenkinsfile.DefaultScriptPath
+	}
+
+	suggestion := &onboarding.Suggestion{Language: input.Language,
+		Pipeline: onboarding.NewPipeline(namespace, input.Name, input.RepoURL, input.CredentialID, input.ScriptPath)}
+	scmClient, provider, repo, err := h.getSCMClient(namespace, input.RepoURL, input.CredentialID)
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	if input.Branch, err = getBranch(ctx, scmClient, repo, input.Branch); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	var files []string
+	if files, err = detector.ListFiles(ctx, scmClient, repo, input.Branch); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if suggestion.Detection, err = detector.Detect(ctx, scmClient, repo, input.Branch, files); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if suggestion.Language == "" {
+		suggestion.Language = suggestion.Detection.Language
+	}
+
+	var clusterTemplate *v1alpha3.ClusterTemplate
+	if clusterTemplate, err = h.getTemplate(ctx, input.Template, suggestion.Language); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	suggestion.Template = clusterTemplate.Name
+	var rendered v1alpha3.TemplateObject
+	if rendered, err = template.Render(clusterTemplate, getParameters(clusterTemplate, input, suggestion)); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	suggestion.Jenkinsfile = rendered.GetAnnotations()[devops.GroupName+devops.RenderResultAnnoKey]
+
+	if input.OpenPullRequest {
+		for _, file := range files {
+			if file == input.ScriptPath {
+				kapis.HandleError(req, resp, restful.NewError(http.StatusConflict,
+					fmt.Sprintf("the %s exists in the repository already", input.ScriptPath)))
+				return
+			}
+		}
+		if suggestion.PullRequest, err = onboarding.OpenPullRequest(ctx, scmClient, provider, repo, input.Branch,
+			onboarding.BranchPrefix+input.Name, input.ScriptPath, []byte(suggestion.Jenkinsfile)); err != nil {
+			kapis.HandleError(req, resp, err)
+			return
+		}
+	}
+	_ = resp.WriteEntity(suggestion)
+}
+
+func (h *handler) detect(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	repoURL := req.QueryParameter("repoURL")
+	if repoURL == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the repoURL is required"))
+		return
+	}
+	scmClient, _, repo, err := h.getSCMClient(req.PathParameter("namespace"), repoURL, req.QueryParameter("credentialId"))
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	var branch string
+	if branch, err = getBranch(ctx, scmClient, repo, req.QueryParameter("branch")); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	var result *detector.Result
+	if result, err = detector.Detect(ctx, scmClient, repo, branch, nil); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(result)
+}
+
+// getSCMClient returns the client of the SCM provider which hosts the repository, and the full name of the repository
+func (h *handler) getSCMClient(namespace, repoURL, credentialID string) (scmClient *scm.Client, provider, repo string, err error) {
+	var server string
+	if provider, server, repo, err = git.ParseRepositoryURL(repoURL); err != nil {
+		return nil, "", "", restful.NewError(http.StatusBadRequest, err.Error())
+	}
+	var secretRef *v1.SecretReference
+	if credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: namespace, Name: credentialID}
+	}
+	scmClient, err = h.scmClientFactory(provider, server, secretRef)
+	return
+}
+
+// getBranch returns the branch, or the default branch of the repository if it's empty
+func getBranch(ctx context.Context, scmClient *scm.Client, repo, branch string) (string, error) {
+	if branch != "" {
+		return branch, nil
+	}
+	repository, _, err := scmClient.Repositories.Find(ctx, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the repository %s, error: %v", repo, err)
+	}
+	return repository.Branch, nil
+}
+
+func (h *handler) getTemplate(ctx context.Context, name, language string) (*v1alpha3.ClusterTemplate, error) {
+	if name != "" {
+		clusterTemplate := &v1alpha3.ClusterTemplate{}
+		err := h.client.Get(ctx, client.ObjectKey{Name: name}, clusterTemplate)
+		return clusterTemplate, err
+	}
+	if language == "" {
+		return nil, restful.NewError(http.StatusBadRequest, "cannot detect the language of the repository, "+
+			"please specify the language or the template")
+	}
+
+	templateList := &v1alpha3.ClusterTemplateList{}
+	if err := h.client.List(ctx, templateList, client.HasLabels{v1alpha3.OnboardingLanguageLabelKey}); err != nil {
+		return nil, err
+	}
+	clusterTemplate := onboarding.SelectTemplate(templateList.Items, language)
+	if clusterTemplate == nil {
+		return nil, restful.NewError(http.StatusNotFound, fmt.Sprintf("no ClusterTemplate for the language %s", language))
+	}
+	return clusterTemplate, nil
+}
+
+// getParameters returns the parameters of the template, the given ones override the repository ones, which override
+// the detected ones and the defaults of the template
+func getParameters(clusterTemplate *v1alpha3.ClusterTemplate, input *Request, suggestion *onboarding.Suggestion) (parameters []template.Parameter) {
+	values := map[string]interface{}{}
+	for _, parameter := range clusterTemplate.Spec.Parameters {
+		var value interface{}
+		if len(parameter.Default.Raw) > 0 && json.Unmarshal(parameter.Default.Raw, &value) == nil {
+			values[parameter.Name] = value
+		}
+	}
+	if suggestion.Detection != nil {
+		for name, value := range suggestion.Detection.Parameters {
+			values[name] = value
+		}
+	}
+	values["name"] = input.Name
+	values["repoURL"] = input.RepoURL
+	values["branch"] = input.Branch
+	values["language"] = suggestion.Language
+	values["credentialId"] = input.CredentialID
+	for _, parameter := range input.Parameters {
+		values[parameter.Name] = parameter.Value
+	}
+	for name, value := range values {
+		parameters = append(parameters, template.Parameter{Name: name, Value: value})
+	}
+	return
+}
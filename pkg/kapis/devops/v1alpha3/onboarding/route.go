@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/detector"
+	"kubesphere.io/devops/pkg/models/onboarding"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=clustertemplates,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// RegisterRoutes registers the API which onboards the repositories by the Pipelines generated from the ClusterTemplates
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	registerRoutes(ws, newHandler(c))
+}
+
+func registerRoutes(ws *restful.WebService, h *handler) {
+	ws.Route(ws.POST("/namespaces/{namespace}/onboarding").
+		To(h.onboard).
+		Doc("Generate a suggested Pipeline of a repository from the ClusterTemplate of its language, and optionally "+
+			"open a pull request which adds the Jenkinsfile to the repository. The Pipeline is not created.").
+		Param(ws.PathParameter("namespace", "Namespace of the DevOps project")).
+		Reads(Request{}).
+		Returns(http.StatusOK, api.StatusOK, onboarding.Suggestion{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/toolchains").
+		To(h.detect).
+		Doc("Detect the toolchains of a repository, and recommend the builder images, cache keys and template parameters").
+		Param(ws.PathParameter("namespace", "Namespace of the DevOps project")).
+		Param(ws.QueryParameter("repoURL", "URL of the repository").Required(true)).
+		Param(ws.QueryParameter("credentialId", "Name of the credential to access the repository")).
+		Param(ws.QueryParameter("branch", "Branch to inspect, it's the default branch by default")).
+		Returns(http.StatusOK, api.StatusOK, detector.Result{}))
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/detector"
+	"kubesphere.io/devops/pkg/models/onboarding"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRoutes(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	var pulls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/org/app":
+			_, _ = w.Write([]byte(`{"name":"app","default_branch":"main"}`))
+		case "GET /repos/org/app/contents/":
+			_, _ = w.Write([]byte(`[{"name":"go.mod","path":"go.mod","type":"file"},{"name":"main.go","path":"main.go","type":"file"}]`))
+		case "GET /repos/org/app/contents/go.mod":
+			_, _ = fmt.Fprintf(w, `{"path":"go.mod","content":"%s"}`, base64.StdEncoding.EncodeToString([]byte("go 1.16\n")))
+		case "GET /repos/org/legacy/contents/":
+			_, _ = w.Write([]byte(`[{"name":"Jenkinsfile","path":"Jenkinsfile","type":"file"},{"name":"pom.xml","path":"pom.xml","type":"file"}]`))
+		case "GET /repos/org/legacy/contents/pom.xml":
+			_, _ = w.Write([]byte(`{"path":"pom.xml","content":""}`))
+		case "GET /repos/org/app/branches/main":
+			_, _ = w.Write([]byte(`{"name":"main","commit":{"sha":"c1"}}`))
+		case "POST /repos/org/app/git/refs":
+			_, _ = w.Write([]byte(`{"ref":"refs/heads/onboarding/app","object":{"sha":"c1"}}`))
+		case "PUT /repos/org/app/contents/Jenkinsfile":
+			_, _ = w.Write([]byte(`{}`))
+		case "POST /repos/org/app/pulls":
+			pulls++
+			_, _ = w.Write([]byte(`{"number":3,"html_url":"https://github.com/org/app/pull/3"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.ClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "go", Labels: map[string]string{v1alpha3.OnboardingLanguageLabelKey: "go"}},
+		Spec: v1alpha3.TemplateSpec{
+			Parameters: []v1alpha3.TemplateParameter{{Name: "goVersion", Default: apiextensionv1.JSON{Raw: []byte(`"1.17"`)}}},
+			Template:   "build $(.params.name) from $(.params.repoURL)@$(.params.branch) with go $(.params.goVersion)",
+		},
+	}, &v1alpha3.ClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "maven"},
+		Spec:       v1alpha3.TemplateSpec{Template: "mvn package"},
+	}).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	registerRoutes(ws, &handler{client: c, scmClientFactory: func(string, string, *v1.SecretReference) (*scm.Client, error) {
+		return github.New(server.URL)
+	}})
+	container.Add(ws)
+
+	tests := []struct {
+		name            string
+		body            string
+		wantCode        int
+		wantLanguage    string
+		wantJenkinsfile string
+		wantPullRequest bool
+	}{{
+		name:            "detect the toolchains",
+		body:            `{"repoURL":"https://github.com/org/app.git"}`,
+		wantCode:        http.StatusOK,
+		wantLanguage:    "go",
+		wantJenkinsfile: "build app from https://github.com/org/app.git@main with go 1.16",
+	}, {
+		name:            "override the parameters",
+		body:            `{"repoURL":"https://github.com/org/app","name":"api","parameters":[{"name":"goVersion","value":"1.18"}]}`,
+		wantCode:        http.StatusOK,
+		wantLanguage:    "go",
+		wantJenkinsfile: "build api from https://github.com/org/app@main with go 1.18",
+	}, {
+		name:            "open a pull request",
+		body:            `{"repoURL":"https://github.com/org/app","openPullRequest":true}`,
+		wantCode:        http.StatusOK,
+		wantLanguage:    "go",
+		wantJenkinsfile: "build app from https://github.com/org/app@main with go 1.16",
+		wantPullRequest: true,
+	}, {
+		name:     "no template for the language",
+		body:     `{"repoURL":"https://github.com/org/legacy","branch":"master"}`,
+		wantCode: http.StatusNotFound,
+	}, {
+		name:            "specific template",
+		body:            `{"repoURL":"https://github.com/org/legacy","branch":"master","template":"maven"}`,
+		wantCode:        http.StatusOK,
+		wantLanguage:    "java",
+		wantJenkinsfile: "mvn package",
+	}, {
+		name:     "the Jenkinsfile exists",
+		body:     `{"repoURL":"https://github.com/org/legacy","branch":"master","template":"maven","openPullRequest":true}`,
+		wantCode: http.StatusConflict,
+	}, {
+		name:     "repository not found",
+		body:     `{"repoURL":"https://github.com/org/fake"}`,
+		wantCode: http.StatusInternalServerError,
+	}, {
+		name:     "unsupported source",
+		body:     `{"repoURL":"https://git.example.com/org/app"}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no repoURL",
+		body:     `{}`,
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/onboarding",
+				bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			container.Dispatch(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code, resp.Body.String())
+			if tt.wantCode == http.StatusOK {
+				suggestion := &onboarding.Suggestion{}
+				assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), suggestion))
+				assert.Equal(t, tt.wantLanguage, suggestion.Language)
+				assert.Equal(t, tt.wantJenkinsfile, suggestion.Jenkinsfile)
+				assert.Equal(t, "ns", suggestion.Pipeline.Namespace)
+				assert.Equal(t, tt.wantPullRequest, suggestion.PullRequest != nil)
+				assert.Equal(t, tt.wantLanguage, suggestion.Detection.Language)
+			}
+		})
+	}
+	assert.Equal(t, 1, pulls)
+
+	detections := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantImage string
+	}{{
+		name:      "default branch",
+		query:     "repoURL=https://github.com/org/app",
+		wantCode:  http.StatusOK,
+		wantImage: "golang:1.16",
+	}, {
+		name:      "specific branch",
+		query:     "repoURL=https://github.com/org/legacy&branch=master",
+		wantCode:  http.StatusOK,
+		wantImage: "maven:3-openjdk-11",
+	}, {
+		name:     "repository not found",
+		query:    "repoURL=https://github.com/org/fake",
+		wantCode: http.StatusInternalServerError,
+	}, {
+		name:     "unsupported source",
+		query:    "repoURL=https://git.example.com/org/app",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no repoURL",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range detections {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/toolchains?"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			container.Dispatch(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code, resp.Body.String())
+			if tt.wantCode == http.StatusOK {
+				result := &detector.Result{}
+				assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), result))
+				assert.Equal(t, tt.wantImage, result.Parameters["builderImage"])
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/ownership"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+	now    func() time.Time
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c, now: time.Now}
+}
+
+func (h *handler) getReport(req *restful.Request, resp *restful.Response) {
+	days := ownership.DefaultDays
+	if value := req.QueryParameter("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days <= 0 {
+			kapis.HandleBadRequest(resp, req, fmt.Errorf("invalid days %q, it should be a positive integer", value))
+			return
+		}
+	}
+
+	// it's a cluster-wide report if the namespace is empty
+	opts := []client.ListOption{client.InNamespace(req.PathParameter("namespace"))}
+	pipelineList := &v1alpha3.PipelineList{}
+	if err := h.client.List(req.Request.Context(), pipelineList, opts...); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	prList := &v1alpha3.PipelineRunList{}
+	if err := h.client.List(req.Request.Context(), prList, opts...); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(ownership.GetReport(pipelineList.Items, prList.Items, days, h.now()))
+}
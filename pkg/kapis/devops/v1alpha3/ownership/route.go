@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/ownership"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registers the report of the stale Pipelines, which helps the platform teams clean up the Pipelines
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	h := newHandler(c)
+
+	ws.Route(ws.GET("/stalepipelines").
+		To(h.getReport).
+		Doc("Get the Pipelines of all the DevOps projects which have no PipelineRuns in the last days or have no valid owner").
+		Param(ws.QueryParameter("days", "The number of days without PipelineRuns, defaults to 30")).
+		Returns(http.StatusOK, api.StatusOK, ownership.Report{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/stalepipelines").
+		To(h.getReport).
+		Doc("Get the Pipelines of a DevOps project which have no PipelineRuns in the last days or have no valid owner").
+		Param(ws.PathParameter("namespace", "Namespace of the DevOps project")).
+		Param(ws.QueryParameter("days", "The number of days without PipelineRuns, defaults to 30")).
+		Returns(http.StatusOK, api.StatusOK, ownership.Report{}))
+}
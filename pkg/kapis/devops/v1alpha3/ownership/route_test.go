@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/ownership"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRoutes(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newPipeline := func(namespace, name string, ownership *v1alpha3.PipelineOwnership) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name,
+				CreationTimestamp: metav1.NewTime(now.Add(-100 * 24 * time.Hour))},
+			Spec: v1alpha3.PipelineSpec{Ownership: ownership},
+		}
+	}
+	owned := &v1alpha3.PipelineOwnership{Owner: "alice", Team: "devs"}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		newPipeline("a", "unowned", nil),
+		newPipeline("a", "active", owned),
+		newPipeline("b", "idle", owned),
+		&v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "a",
+			Name:              "active-1",
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "active"},
+			CreationTimestamp: metav1.NewTime(now.Add(-10 * 24 * time.Hour)),
+		}},
+	).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c)
+	container.Add(ws)
+
+	tests := []struct {
+		name      string
+		uri       string
+		wantCode  int
+		wantNames []string
+	}{{
+		name:      "all the DevOps projects",
+		uri:       "/stalepipelines",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"unowned", "idle"},
+	}, {
+		name:      "a DevOps project",
+		uri:       "/namespaces/a/stalepipelines",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"unowned"},
+	}, {
+		name:      "specific days",
+		uri:       "/stalepipelines?days=5",
+		wantCode:  http.StatusOK,
+		wantNames: []string{"active", "unowned", "idle"},
+	}, {
+		name:     "invalid days",
+		uri:      "/stalepipelines?days=-1",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3"+tt.uri, nil)
+			resp := httptest.NewRecorder()
+			container.Dispatch(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code, resp.Body.String())
+			if tt.wantCode == http.StatusOK {
+				report := &ownership.Report{}
+				assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), report))
+				var names []string
+				for _, item := range report.Items {
+					names = append(names, item.Name)
+				}
+				assert.Equal(t, tt.wantNames, names)
+			}
+		})
+	}
+}
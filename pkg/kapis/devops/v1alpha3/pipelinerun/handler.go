@@ -17,26 +17,37 @@ limitations under the License.
 package pipelinerun
 
 import (
-	"bytes"
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"k8s.io/apimachinery/pkg/types"
-	cmstore "kubesphere.io/devops/pkg/store/configmap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"mime"
+	"net/http"
 	"net/url"
+	"path"
 	"strconv"
+	"time"
 
 	"kubesphere.io/devops/pkg/kapis"
 
 	"github.com/emicklei/go-restful"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/apiserver/query"
 	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
 	"kubesphere.io/devops/pkg/client/devops"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	"kubesphere.io/devops/pkg/models/logmask"
 	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"kubesphere.io/devops/pkg/models/provenance"
 	resourcesV1alpha3 "kubesphere.io/devops/pkg/models/resources/v1alpha3"
+	"kubesphere.io/devops/pkg/models/runevents"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -65,6 +76,11 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 		// by default, we have to guarantee backward compatibility
 		backward = true
 	}
+	refType, err := getRefType(request.QueryParameter("refType"))
+	if err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
 
 	queryParam := query.ParseQueryParameter(request)
 
@@ -86,8 +102,11 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 	opts := make([]client.ListOption, 0, 3)
 	opts = append(opts, client.InNamespace(pipeline.Namespace))
 	opts = append(opts, client.MatchingLabelsSelector{Selector: labelSelector})
+	// the cache only supports one field selector, the other field is filtered after listing
 	if branchName != "" {
 		opts = append(opts, client.MatchingFields{v1alpha3.PipelineRunSCMRefNameField: branchName})
+	} else if refType != "" {
+		opts = append(opts, client.MatchingFields{v1alpha3.PipelineRunSCMRefTypeField: string(refType)})
 	}
 
 	var prs v1alpha3.PipelineRunList
@@ -96,6 +115,9 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 		kapis.HandleError(request, response, err)
 		return
 	}
+	if refType != "" {
+		prs.Items = filterByRefType(prs.Items, refType)
+	}
 
 	var listHandler resourcesV1alpha3.ListHandler = listHandler{}
 	if backward {
@@ -105,6 +127,36 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 	_ = response.WriteAsJson(apiResult)
 }
 
+// listLatestPipelineRuns returns the latest PipelineRun of each branch, which is aggregated into the Pipeline status
+func (h *apiHandler) listLatestPipelineRuns(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	pipName := request.PathParameter("pipeline")
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(context.Background(), client.ObjectKey{Namespace: nsName, Name: pipName}, pipeline); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
+	summary := pipeline.Status.Runs
+	if summary == nil {
+		// the PipelineRuns haven't been aggregated by the controller yet
+		var prs v1alpha3.PipelineRunList
+		if err := h.client.List(context.Background(), &prs, client.InNamespace(nsName),
+			client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipName}); err != nil {
+			kapis.HandleError(request, response, err)
+			return
+		}
+		summary = pipelinerun.Summarize(prs.Items)
+	}
+
+	latestRuns := summary.LatestRuns
+	if latestRuns == nil {
+		latestRuns = []v1alpha3.BranchRunSummary{}
+	}
+	_ = response.WriteEntity(latestRuns)
+}
+
 func (h *apiHandler) createPipelineRun(request *restful.Request, response *restful.Response) {
 	nsName := request.PathParameter("namespace")
 	pipName := request.PathParameter("pipeline")
@@ -123,12 +175,17 @@ func (h *apiHandler) createPipelineRun(request *restful.Request, response *restf
 
 	var (
 		scm *v1alpha3.SCM
+		key string
 		err error
 	)
 	if scm, err = CreateScm(&pipeline.Spec, branch); err != nil {
 		kapis.HandleBadRequest(response, request, err)
 		return
 	}
+	if key, err = GetIdempotencyKey(request.HeaderParameter(IdempotencyKeyHeader), payload.IdempotencyKey); err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
 
 	// get current login user from request context
 	user, ok := apiserverrequest.UserFrom(request.Request.Context())
@@ -143,12 +200,20 @@ func (h *apiHandler) createPipelineRun(request *restful.Request, response *restf
 	if user.GetName() != "" {
 		pr.GetAnnotations()[v1alpha3.PipelineRunCreatorAnnoKey] = user.GetName()
 	}
-	if err := h.client.Create(context.Background(), pr); err != nil {
+	SetIdempotencyKey(pr, key)
+	created, replayed, err := CreateIdempotently(context.Background(), h.client, pr)
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		kapis.HandleConflict(response, request, err)
+		return
+	} else if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
+	if replayed {
+		response.AddHeader(IdempotentReplayedHeader, "true")
+	}
 
-	_ = response.WriteEntity(pr)
+	_ = response.WriteEntity(created)
 }
 
 func (h *apiHandler) getPipelineRun(request *restful.Request, response *restful.Response) {
@@ -164,6 +229,123 @@ func (h *apiHandler) getPipelineRun(request *restful.Request, response *restful.
 	_ = response.WriteEntity(&pr)
 }
 
+func (h *apiHandler) stopPipelineRun(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	prName := request.PathParameter("pipelinerun")
+	action, err := getStopAction(request.QueryParameter("mode"))
+	if err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
+
+	var pr v1alpha3.PipelineRun
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := h.client.Get(context.Background(), client.ObjectKey{Namespace: nsName, Name: prName}, &pr); err != nil {
+			return err
+		}
+		if pr.HasCompleted() {
+			return restful.NewError(http.StatusBadRequest, fmt.Sprintf("PipelineRun %s/%s has already completed", nsName, prName))
+		}
+		// the hard stop cannot be downgraded to the soft one
+		if pr.Spec.Action != nil && (*pr.Spec.Action == action || *pr.Spec.Action == v1alpha3.Stop) {
+			return nil
+		}
+		pr.Spec.Action = &action
+		return h.client.Update(context.Background(), &pr)
+	})
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(&pr)
+}
+
+func (h *apiHandler) listQueueItems(request *restful.Request, response *restful.Response) {
+	items, err := h.getQueueItems(request.PathParameter("namespace"), request.PathParameter("pipeline"))
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(items)
+}
+
+func (h *apiHandler) cancelQueueItem(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	pipName := request.PathParameter("pipeline")
+	id, err := strconv.Atoi(request.PathParameter("id"))
+	if err != nil {
+		kapis.HandleBadRequest(response, request, fmt.Errorf("invalid queue item id: %s", request.PathParameter("id")))
+		return
+	}
+
+	// only the items of the specified pipeline are allowed to be cancelled
+	items, err := h.getQueueItems(nsName, pipName)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	found := false
+	for _, item := range items {
+		found = found || item.ID == id
+	}
+	if !found {
+		kapis.HandleError(request, response, restful.NewError(http.StatusNotFound,
+			fmt.Sprintf("queue item %d of pipeline %s/%s not found", id, nsName, pipName)))
+		return
+	}
+
+	if err = h.devopsClient.CancelQueueItem(id); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	// stop the PipelineRun waiting for the item, then the controller will take it as cancelled
+	if err = h.stopQueuedPipelineRuns(request.Request.Context(), nsName, pipName, strconv.Itoa(id)); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// getQueueItems returns the items of the Jenkins queue which belong to the specified pipeline.
+func (h *apiHandler) getQueueItems(nsName, pipName string) ([]devops.QueueItem, error) {
+	items, err := h.devopsClient.ListQueueItems()
+	if err != nil {
+		return nil, err
+	}
+	pipelineItems := make([]devops.QueueItem, 0)
+	for _, item := range items {
+		if item.ProjectName == nsName && item.PipelineName == pipName {
+			pipelineItems = append(pipelineItems, item)
+		}
+	}
+	return pipelineItems, nil
+}
+
+func (h *apiHandler) stopQueuedPipelineRuns(ctx context.Context, nsName, pipName, queueID string) error {
+	var prs v1alpha3.PipelineRunList
+	if err := h.client.List(ctx, &prs, client.InNamespace(nsName),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipName}); err != nil {
+		return err
+	}
+	for i := range prs.Items {
+		pr := &prs.Items[i]
+		build := struct {
+			QueueID string `json:"queueId"`
+		}{}
+		if pr.HasCompleted() || pr.IsStopRequested() ||
+			json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), &build) != nil ||
+			build.QueueID != queueID {
+			continue
+		}
+		action := v1alpha3.Stop
+		pr.Spec.Action = &action
+		if err := h.client.Update(ctx, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *apiHandler) getNodeDetails(request *restful.Request, response *restful.Response) {
 	namespaceName := request.PathParameter("namespace")
 	pipelineRunName := request.PathParameter("pipelinerun")
@@ -176,22 +358,8 @@ func (h *apiHandler) getNodeDetails(request *restful.Request, response *restful.
 		return
 	}
 
-	// get stage status
-	stagesJSON, ok := pr.Annotations[v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey]
-	if !ok {
-		if pipelineRunStore, err := cmstore.NewConfigMapStore(ctx, types.NamespacedName{
-			Namespace: namespaceName,
-			Name:      pipelineRunName,
-		}, h.client); err != nil {
-			// If the stages status does not exist, set it as an empty array
-			stagesJSON = "[]"
-		} else {
-			stagesJSON = pipelineRunStore.GetStages()
-		}
-	}
-
-	var stages []pipelinerun.NodeDetail
-	if err := json.Unmarshal([]byte(stagesJSON), &stages); err != nil {
+	stages, err := pipelinerun.GetStages(ctx, h.client, pr)
+	if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
@@ -207,55 +375,507 @@ func (h *apiHandler) getNodeDetails(request *restful.Request, response *restful.
 	_ = response.WriteEntity(&stages)
 }
 
-// downloadArtifact API to download artifacts from Jenkins
-func (h *apiHandler) downloadArtifact(request *restful.Request, response *restful.Response) {
-	namespaceName := request.PathParameter("namespace")
-	pipelineRunName := request.PathParameter("pipelinerun")
-	filename := request.QueryParameter("filename")
-
-	// get pipelinerun
+func (h *apiHandler) getTimeline(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
 	pr := &v1alpha3.PipelineRun{}
-	err := h.client.Get(context.Background(), client.ObjectKey{Namespace: namespaceName, Name: pipelineRunName}, pr)
+	if err := h.client.Get(ctx, client.ObjectKey{
+		Namespace: request.PathParameter("namespace"),
+		Name:      request.PathParameter("pipelinerun"),
+	}, pr); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
+	stages, err := pipelinerun.GetStages(ctx, h.client, pr)
 	if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
+	var run *job.PipelineRun
+	if runJSON := pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]; runJSON != "" {
+		run = &job.PipelineRun{}
+		if err = json.Unmarshal([]byte(runJSON), run); err != nil {
+			kapis.HandleError(request, response, err)
+			return
+		}
+	}
+	_ = response.WriteEntity(pipelinerun.BuildTimeline(pr, run, stages, time.Now()))
+}
 
-	filename, err = url.QueryUnescape(filename)
+func (h *apiHandler) getNodeLog(request *restful.Request, response *restful.Response) {
+	nodeID := request.PathParameter("node")
+	h.getLog(request, response, func(pr *v1alpha3.PipelineRun, runID, branch string, params *devops.HttpParameters) ([]byte, http.Header, error) {
+		pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+		if branch != "" {
+			return h.devopsClient.GetBranchNodeLog(pr.Namespace, pipelineName, branch, runID, nodeID, params)
+		}
+		return h.devopsClient.GetNodeLog(pr.Namespace, pipelineName, runID, nodeID, params)
+	})
+}
+
+func (h *apiHandler) getStepLog(request *restful.Request, response *restful.Response) {
+	nodeID := request.PathParameter("node")
+	stepID := request.PathParameter("step")
+	h.getLog(request, response, func(pr *v1alpha3.PipelineRun, runID, branch string, params *devops.HttpParameters) ([]byte, http.Header, error) {
+		pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+		if branch != "" {
+			return h.devopsClient.GetBranchStepLog(pr.Namespace, pipelineName, branch, runID, nodeID, stepID, params)
+		}
+		return h.devopsClient.GetStepLog(pr.Namespace, pipelineName, runID, nodeID, stepID, params)
+	})
+}
+
+type logGetter func(pr *v1alpha3.PipelineRun, runID, branch string, params *devops.HttpParameters) ([]byte, http.Header, error)
+
+// getLog fetches the log of a PipelineRun from Jenkins, the log will be cut if the limit is given
+func (h *apiHandler) getLog(request *restful.Request, response *restful.Response, getter logGetter) {
+	logRange, err := parseLogRange(request.QueryParameter("start"), request.QueryParameter("limit"))
+	if err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
+
+	pr, runID, branch, err := h.getStartedPipelineRun(request)
 	if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
 
-	buildID, exists := pr.GetPipelineRunID()
-	if !exists {
-		kapis.HandleError(request, response, fmt.Errorf("unable to get PipelineRun nodes due to not found run ID"))
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{RawQuery: url.Values{"start": []string{strconv.FormatInt(logRange.start, 10)}}.Encode()},
+	}
+	log, header, err := getter(pr, runID, branch, params)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
+	masker, err := logmask.ForPipelineRun(request.Request.Context(), h.client, pr)
+	if err != nil {
+		kapis.HandleError(request, response, err)
 		return
 	}
+
+	// mask the log after cutting, then the offsets are still the same as the Jenkins log
+	log, textSize, moreData := logRange.cut(log, header)
+	log = masker.Mask(log)
+	response.AddHeader(logTextSizeHeader, strconv.FormatInt(textSize, 10))
+	response.AddHeader(logMoreDataHeader, strconv.FormatBool(moreData))
+	response.AddHeader(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+	_, _ = response.Write(log)
+}
+
+func (h *apiHandler) listArtifacts(request *restful.Request, response *restful.Response) {
+	pr, runID, branch, err := h.getStartedPipelineRun(request)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
 	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{},
+	}
+	var artifacts []devops.Artifacts
+	if branch != "" {
+		artifacts, err = h.devopsClient.GetBranchArtifacts(pr.Namespace, pipelineName, branch, runID, params)
+	} else {
+		artifacts, err = h.devopsClient.GetArtifacts(pr.Namespace, pipelineName, runID, params)
+	}
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	if artifacts == nil {
+		artifacts = []devops.Artifacts{}
+	}
+	_ = response.WriteEntity(artifacts)
+}
+
+// downloadArtifact API to download artifacts from Jenkins.
+// The artifact is streamed instead of being buffered, and the range requests are supported.
+func (h *apiHandler) downloadArtifact(request *restful.Request, response *restful.Response) {
+	filename, err := url.QueryUnescape(request.QueryParameter("filename"))
+	if err != nil {
+		kapis.HandleBadRequest(response, request, err)
+		return
+	}
+	if filename == "" {
+		kapis.HandleBadRequest(response, request, errors.New("the filename is required"))
+		return
+	}
+
+	pr, runID, branch, err := h.getStartedPipelineRun(request)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
 
 	// request the Jenkins API to download artifact
-	body, err := h.devopsClient.DownloadArtifact(namespaceName, pipelineName, buildID, filename)
+	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	var artifact *http.Response
+	if branch != "" {
+		artifact, err = h.devopsClient.GetBranchArtifactStream(pr.Namespace, pipelineName, branch, runID, filename, request.Request.Header)
+	} else {
+		artifact, err = h.devopsClient.GetArtifactStream(pr.Namespace, pipelineName, runID, filename, request.Request.Header)
+	}
 	if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
+	if artifact == nil {
+		kapis.HandleNotFound(response, request, fmt.Errorf("artifact '%s' not found", filename))
+		return
+	}
 	defer func() {
-		_ = body.Close()
+		_ = artifact.Body.Close()
 	}()
 
-	buf := &bytes.Buffer{}
-	if _, err = io.Copy(buf, body); err != nil {
+	switch artifact.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusNotModified, http.StatusRequestedRangeNotSatisfiable:
+	case http.StatusNotFound:
+		kapis.HandleNotFound(response, request, fmt.Errorf("artifact '%s' not found", filename))
+		return
+	default:
+		kapis.HandleError(request, response, restful.NewError(http.StatusBadGateway,
+			fmt.Sprintf("failed to get artifact '%s', the HTTP status code is %d", filename, artifact.StatusCode)))
+		return
+	}
+
+	body := bufio.NewReader(artifact.Body)
+	for _, key := range []string{"Content-Length", "Content-Range", "Accept-Ranges", "Last-Modified", "ETag"} {
+		if value := artifact.Header.Get(key); value != "" {
+			response.AddHeader(key, value)
+		}
+	}
+	if artifact.StatusCode == http.StatusOK || artifact.StatusCode == http.StatusPartialContent {
+		var head []byte
+		if artifact.StatusCode == http.StatusOK {
+			// only the beginning of the artifact is able to be sniffed
+			head, _ = body.Peek(512)
+		}
+		contentType := detectContentType(filename, artifact.Header.Get("Content-Type"), head)
+		response.AddHeader("Content-Type", contentType)
+		response.AddHeader("Content-Disposition", mime.FormatMediaType(getContentDisposition(request, contentType),
+			map[string]string{"filename": path.Base(filename)}))
+	}
+	// the artifacts are written by the Pipelines, never let the browsers guess the content type of them
+	response.AddHeader("X-Content-Type-Options", "nosniff")
+	response.WriteHeader(artifact.StatusCode)
+	if _, err = io.Copy(response, body); err != nil {
+		// it's too late to change the status code
+		klog.Errorf("failed to stream artifact '%s' of PipelineRun '%s/%s', error: %v", filename, pr.Namespace, pr.Name, err)
+	}
+}
+
+// getContentDisposition returns inline only if it's requested and the content type is safe to be rendered by the
+// browsers, otherwise the artifact is downloaded as an attachment
+func getContentDisposition(request *restful.Request, contentType string) string {
+	if inline, _ := strconv.ParseBool(request.QueryParameter("inline")); inline && isInlineSafe(contentType) {
+		return "inline"
+	}
+	return "attachment"
+}
+
+// getProvenance returns the SLSA provenance of a PipelineRun, it's a DSSE envelope if the provenance was signed
+func (h *apiHandler) getProvenance(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	pr := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: request.PathParameter("namespace"),
+		Name: request.PathParameter("pipelinerun")}, pr); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	cmName := pr.Annotations[v1alpha3.PipelineRunProvenanceAnnoKey]
+	if cmName == "" {
+		kapis.HandleError(request, response, restful.NewError(http.StatusNotFound,
+			fmt.Sprintf("not found the provenance of PipelineRun '%s/%s'", pr.Namespace, pr.Name)))
+		return
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: pr.Namespace, Name: cmName}, cm); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	data, ok := cm.Data[provenance.ConfigMapKeyEnvelope]
+	if !ok {
+		data = cm.Data[provenance.ConfigMapKeyStatement]
+	}
+	response.Header().Set(restful.HEADER_ContentType, restful.MIME_JSON)
+	_, _ = response.Write([]byte(data))
+}
+
+// getRunEvents returns the recorded events of a PipelineRun in order, they are available without Jenkins
+func (h *apiHandler) getRunEvents(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	pr := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: request.PathParameter("namespace"),
+		Name: request.PathParameter("pipelinerun")}, pr); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: pr.Namespace,
+		Name: runevents.GetConfigMapName(pr)}, cm); err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
+	events, err := runevents.Parse(cm.Data[runevents.ConfigMapKeyEvents])
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	if events == nil {
+		events = []runevents.Event{}
+	}
+	_ = response.WriteEntity(events)
+}
+
+// comparePipelineRuns compares a PipelineRun with a base PipelineRun of the same Pipeline
+func (h *apiHandler) comparePipelineRuns(request *restful.Request, response *restful.Response) {
+	namespaceName := request.PathParameter("namespace")
+	baseName := request.QueryParameter("base")
+	if baseName == "" {
+		kapis.HandleBadRequest(response, request, errors.New("the base PipelineRun is required"))
+		return
+	}
+
+	ctx := request.Request.Context()
+	target, err := h.getRunSnapshot(ctx, namespaceName, request.PathParameter("pipelinerun"))
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	base, err := h.getRunSnapshot(ctx, namespaceName, baseName)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	if base.PipelineRun.Labels[v1alpha3.PipelineNameLabelKey] != target.PipelineRun.Labels[v1alpha3.PipelineNameLabelKey] {
+		kapis.HandleBadRequest(response, request, fmt.Errorf("PipelineRun '%s' and '%s' don't belong to the same Pipeline",
+			baseName, target.PipelineRun.Name))
+		return
+	}
+	_ = response.WriteEntity(pipelinerun.Compare(base, target))
+}
+
+// diffJenkinsfile returns the difference of the recorded Jenkinsfile between two PipelineRuns of the same Pipeline
+func (h *apiHandler) diffJenkinsfile(request *restful.Request, response *restful.Response) {
+	namespaceName := request.PathParameter("namespace")
+	baseName := request.QueryParameter("base")
+	if baseName == "" {
+		kapis.HandleBadRequest(response, request, errors.New("the base PipelineRun is required"))
+		return
+	}
 
-	// add download header
-	response.AddHeader("Content-Type", "application/octet-stream")
-	response.AddHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	_, err = response.Write(buf.Bytes())
+	ctx := request.Request.Context()
+	target, targetContent, err := h.getJenkinsfile(ctx, namespaceName, request.PathParameter("pipelinerun"))
 	if err != nil {
 		kapis.HandleError(request, response, err)
 		return
 	}
+	base, baseContent, err := h.getJenkinsfile(ctx, namespaceName, baseName)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	if base.Labels[v1alpha3.PipelineNameLabelKey] != target.Labels[v1alpha3.PipelineNameLabelKey] {
+		kapis.HandleBadRequest(response, request, fmt.Errorf("PipelineRun '%s' and '%s' don't belong to the same Pipeline",
+			baseName, target.Name))
+		return
+	}
+
+	diff, err := jenkinsfile.Compare(base, target, baseContent, targetContent)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(diff)
+}
+
+// diffArtifacts compares the artifacts of a PipelineRun with a base PipelineRun of the same Pipeline, the base is the
+// last successful PipelineRun before it by default
+func (h *apiHandler) diffArtifacts(request *restful.Request, response *restful.Response) {
+	threshold := pipelinerun.DefaultSizeIncreaseThreshold
+	if value := request.QueryParameter("sizeThreshold"); value != "" {
+		var err error
+		if threshold, err = strconv.Atoi(value); err != nil || threshold < 0 {
+			kapis.HandleBadRequest(response, request, fmt.Errorf("invalid size threshold '%s'", value))
+			return
+		}
+	}
+
+	ctx := request.Request.Context()
+	namespaceName := request.PathParameter("namespace")
+	target := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespaceName, Name: request.PathParameter("pipelinerun")},
+		target); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	base := &v1alpha3.PipelineRun{}
+	if baseName := request.QueryParameter("base"); baseName != "" {
+		if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespaceName, Name: baseName}, base); err != nil {
+			kapis.HandleError(request, response, err)
+			return
+		}
+		if base.Labels[v1alpha3.PipelineNameLabelKey] != target.Labels[v1alpha3.PipelineNameLabelKey] {
+			kapis.HandleBadRequest(response, request, fmt.Errorf("PipelineRun '%s' and '%s' don't belong to the same Pipeline",
+				baseName, target.Name))
+			return
+		}
+	} else {
+		var err error
+		if base, err = h.getLastSucceeded(ctx, target); err != nil {
+			kapis.HandleError(request, response, err)
+			return
+		}
+	}
+
+	baseSnapshot, err := h.getArtifactSnapshot(base)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	targetSnapshot, err := h.getArtifactSnapshot(target)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(pipelinerun.CompareArtifacts(baseSnapshot, targetSnapshot, threshold))
+}
+
+// getLastSucceeded returns the last successful PipelineRun which was created before the target PipelineRun, they belong
+// to the same Pipeline, and the same branch if it's a multi-branch Pipeline
+func (h *apiHandler) getLastSucceeded(ctx context.Context, target *v1alpha3.PipelineRun) (*v1alpha3.PipelineRun, error) {
+	pipelineRuns := &v1alpha3.PipelineRunList{}
+	if err := h.client.List(ctx, pipelineRuns, client.InNamespace(target.Namespace),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: target.Labels[v1alpha3.PipelineNameLabelKey]}); err != nil {
+		return nil, err
+	}
+	var last *v1alpha3.PipelineRun
+	for i := range pipelineRuns.Items {
+		pr := &pipelineRuns.Items[i]
+		if pr.Name == target.Name || pr.Status.Phase != v1alpha3.Succeeded ||
+			getBranch(pr) != getBranch(target) || target.CreationTimestamp.Before(&pr.CreationTimestamp) {
+			continue
+		}
+		if last == nil || last.CreationTimestamp.Before(&pr.CreationTimestamp) ||
+			last.CreationTimestamp.Equal(&pr.CreationTimestamp) && last.Name < pr.Name {
+			last = pr
+		}
+	}
+	if last == nil {
+		return nil, restful.NewError(http.StatusNotFound,
+			fmt.Sprintf("not found a successful PipelineRun before PipelineRun '%s/%s'", target.Namespace, target.Name))
+	}
+	return last, nil
+}
+
+// getArtifactSnapshot returns the PipelineRun with its artifacts and their fingerprints.
+// Only the PipelineRun is returned if it's not started yet.
+func (h *apiHandler) getArtifactSnapshot(pr *v1alpha3.PipelineRun) (snapshot *pipelinerun.ArtifactSnapshot, err error) {
+	snapshot = &pipelinerun.ArtifactSnapshot{PipelineRun: pr}
+	runID, exists := pr.GetPipelineRunID()
+	if !exists {
+		return
+	}
+
+	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{},
+	}
+	if branch := getBranch(pr); branch != "" {
+		if snapshot.Artifacts, err = h.devopsClient.GetBranchArtifacts(pr.Namespace, pipelineName, branch, runID,
+			params); err == nil {
+			snapshot.Fingerprints, err = h.devopsClient.GetBranchFingerprints(pr.Namespace, pipelineName, branch, runID)
+		}
+	} else {
+		if snapshot.Artifacts, err = h.devopsClient.GetArtifacts(pr.Namespace, pipelineName, runID, params); err == nil {
+			snapshot.Fingerprints, err = h.devopsClient.GetFingerprints(pr.Namespace, pipelineName, runID)
+		}
+	}
+	return
+}
+
+// getBranch returns the branch of a PipelineRun of a multi-branch Pipeline
+func getBranch(pr *v1alpha3.PipelineRun) string {
+	if pr.Spec.IsMultiBranchPipeline() && pr.Spec.SCM != nil {
+		return pr.Spec.SCM.RefName
+	}
+	return ""
+}
+
+// getJenkinsfile returns a PipelineRun with the content of its recorded Jenkinsfile
+func (h *apiHandler) getJenkinsfile(ctx context.Context, namespace, name string) (pr *v1alpha3.PipelineRun, content string, err error) {
+	pr = &v1alpha3.PipelineRun{}
+	if err = h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pr); err != nil {
+		return
+	}
+	if pr.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey] == "" {
+		err = restful.NewError(http.StatusNotFound,
+			fmt.Sprintf("not found the recorded Jenkinsfile of PipelineRun '%s/%s'", namespace, name))
+		return
+	}
+	cm := &corev1.ConfigMap{}
+	if err = h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: jenkinsfile.GetConfigMapName(pr)}, cm); err == nil {
+		content = cm.Data[jenkinsfile.ConfigMapKeyJenkinsfile]
+	}
+	return
+}
+
+// getRunSnapshot returns the PipelineRun with its test cases and fingerprints of the archived files.
+// Only the PipelineRun is returned if it's not started yet.
+func (h *apiHandler) getRunSnapshot(ctx context.Context, namespace, name string) (snapshot *pipelinerun.RunSnapshot, err error) {
+	pr := &v1alpha3.PipelineRun{}
+	if err = h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pr); err != nil {
+		return
+	}
+	snapshot = &pipelinerun.RunSnapshot{PipelineRun: pr}
+	runID, exists := pr.GetPipelineRunID()
+	if !exists {
+		return
+	}
+
+	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	if pr.Spec.IsMultiBranchPipeline() && pr.Spec.SCM != nil {
+		branch := pr.Spec.SCM.RefName
+		if snapshot.TestCases, err = h.devopsClient.GetBranchTestCases(namespace, pipelineName, branch, runID); err == nil {
+			snapshot.Fingerprints, err = h.devopsClient.GetBranchFingerprints(namespace, pipelineName, branch, runID)
+		}
+	} else {
+		if snapshot.TestCases, err = h.devopsClient.GetTestCases(namespace, pipelineName, runID); err == nil {
+			snapshot.Fingerprints, err = h.devopsClient.GetFingerprints(namespace, pipelineName, runID)
+		}
+	}
+	return
+}
+
+// getStartedPipelineRun returns the PipelineRun, the run ID, and the branch name if it's a multi-branch Pipeline
+func (h *apiHandler) getStartedPipelineRun(request *restful.Request) (pr *v1alpha3.PipelineRun, runID, branch string, err error) {
+	namespaceName := request.PathParameter("namespace")
+	pipelineRunName := request.PathParameter("pipelinerun")
+
+	pr = &v1alpha3.PipelineRun{}
+	if err = h.client.Get(request.Request.Context(), client.ObjectKey{Namespace: namespaceName, Name: pipelineRunName}, pr); err != nil {
+		return
+	}
+	var exists bool
+	if runID, exists = pr.GetPipelineRunID(); !exists {
+		err = restful.NewError(http.StatusNotFound, fmt.Sprintf("not found run ID of PipelineRun '%s/%s'",
+			namespaceName, pipelineRunName))
+		return
+	}
+	if pr.Spec.IsMultiBranchPipeline() && pr.Spec.SCM != nil {
+		branch = pr.Spec.SCM.RefName
+	}
+	return
 }
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the key which makes the retried requests create only one PipelineRun
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is true if the PipelineRun in the response was created by an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// ErrIdempotencyKeyReused means the idempotency key was used by a request with different parameters or branch
+var ErrIdempotencyKeyReused = errors.New("the idempotency key was used by a different request")
+
+// GetIdempotencyKey returns the idempotency key from the header or the payload, they should be the same if both are set
+func GetIdempotencyKey(header, payload string) (key string, err error) {
+	if header != "" && payload != "" && header != payload {
+		err = fmt.Errorf("the idempotency key of the header %s is different from the payload", IdempotencyKeyHeader)
+		return
+	}
+	if key = header; key == "" {
+		key = payload
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		err = fmt.Errorf("the idempotency key is longer than %d characters", maxIdempotencyKeyLength)
+	}
+	return
+}
+
+// SetIdempotencyKey names the PipelineRun after the hash of the key instead of a generated name, then the API server
+// rejects the second PipelineRun with the same key of the same Pipeline. Nothing changes if the key is empty.
+func SetIdempotencyKey(pr *v1alpha3.PipelineRun, key string) {
+	if key == "" {
+		return
+	}
+	sum := sha256.Sum256([]byte(key))
+	pr.GenerateName = ""
+	pr.Name = fmt.Sprintf("%s-%s", pr.Labels[v1alpha3.PipelineNameLabelKey], hex.EncodeToString(sum[:])[:10])
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	pr.Annotations[v1alpha3.PipelineRunIdempotencyKeyAnnoKey] = key
+}
+
+// CreateIdempotently creates the PipelineRun. If a PipelineRun was created with the same idempotency key, it returns
+// the existing one, and replayed is true. It returns ErrIdempotencyKeyReused if the existing one was created by a
+// request with different parameters or branch.
+func CreateIdempotently(ctx context.Context, c client.Client, pr *v1alpha3.PipelineRun) (result *v1alpha3.PipelineRun,
+	replayed bool, err error) {
+	key, ok := pr.Annotations[v1alpha3.PipelineRunIdempotencyKeyAnnoKey]
+	if err = c.Create(ctx, pr); err == nil || !ok || !apierrors.IsAlreadyExists(err) {
+		return pr, false, err
+	}
+
+	existing := &v1alpha3.PipelineRun{}
+	if err = c.Get(ctx, client.ObjectKeyFromObject(pr), existing); err != nil {
+		return
+	}
+	if existing.Annotations[v1alpha3.PipelineRunIdempotencyKeyAnnoKey] != key || !isSameRequest(existing, pr) {
+		err = ErrIdempotencyKeyReused
+		return
+	}
+	return existing, true, nil
+}
+
+// isSameRequest returns true if the PipelineRuns have the same parameters and branch
+func isSameRequest(pr, another *v1alpha3.PipelineRun) bool {
+	if len(pr.Spec.Parameters) != len(another.Spec.Parameters) ||
+		(len(pr.Spec.Parameters) > 0 && !reflect.DeepEqual(pr.Spec.Parameters, another.Spec.Parameters)) {
+		return false
+	}
+	var ref, anotherRef string
+	if pr.Spec.SCM != nil {
+		ref = pr.Spec.SCM.RefName
+	}
+	if another.Spec.SCM != nil {
+		anotherRef = another.Spec.SCM.RefName
+	}
+	return ref == anotherRef
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestGetIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		payload string
+		want    string
+		wantErr bool
+	}{{
+		name: "no key",
+	}, {
+		name:   "header",
+		header: "key",
+		want:   "key",
+	}, {
+		name:    "payload",
+		payload: "key",
+		want:    "key",
+	}, {
+		name:    "the same key",
+		header:  "key",
+		payload: "key",
+		want:    "key",
+	}, {
+		name:    "different keys",
+		header:  "key",
+		payload: "another",
+		wantErr: true,
+	}, {
+		name:    "too long",
+		header:  strings.Repeat("k", 256),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := GetIdempotencyKey(tt.header, tt.payload)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, key)
+			}
+		})
+	}
+}
+
+func TestSetIdempotencyKey(t *testing.T) {
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"}}
+	pr := CreatePipelineRun(pipeline, nil, nil)
+	SetIdempotencyKey(pr, "")
+	assert.Equal(t, "pipeline-", pr.GenerateName)
+	assert.Empty(t, pr.Name)
+
+	SetIdempotencyKey(pr, "key")
+	assert.Empty(t, pr.GenerateName)
+	assert.Equal(t, "pipeline-2c70e12b7a", pr.Name)
+	assert.Equal(t, "key", pr.Annotations[v1alpha3.PipelineRunIdempotencyKeyAnnoKey])
+
+	// the name is stable, and different between the keys
+	another := CreatePipelineRun(pipeline, nil, nil)
+	SetIdempotencyKey(another, "key")
+	assert.Equal(t, pr.Name, another.Name)
+	SetIdempotencyKey(another, "another")
+	assert.NotEqual(t, pr.Name, another.Name)
+}
+
+func Test_isSameRequest(t *testing.T) {
+	newRun := func(branch string, parameters ...v1alpha3.Parameter) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{}
+		pr.Spec.Parameters = parameters
+		if branch != "" {
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		return pr
+	}
+	parameter := v1alpha3.Parameter{Name: "name", Value: "value"}
+	assert.True(t, isSameRequest(newRun(""), &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{
+		Parameters: []v1alpha3.Parameter{}}}))
+	assert.True(t, isSameRequest(newRun("main", parameter), newRun("main", parameter)))
+	assert.False(t, isSameRequest(newRun("main", parameter), newRun("dev", parameter)))
+	assert.False(t, isSameRequest(newRun("main", parameter), newRun("main")))
+	assert.False(t, isSameRequest(newRun("", parameter), newRun("", v1alpha3.Parameter{Name: "name", Value: "another"})))
+}
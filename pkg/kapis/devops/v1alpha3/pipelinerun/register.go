@@ -18,12 +18,15 @@ package pipelinerun
 
 import (
 	"net/http"
+	"strconv"
 
 	restfulspec "github.com/emicklei/go-restful-openapi"
 	"kubesphere.io/devops/pkg/constants"
 
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
 	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"kubesphere.io/devops/pkg/models/runevents"
 
 	"github.com/emicklei/go-restful"
 	"kubesphere.io/devops/pkg/api"
@@ -45,6 +48,8 @@ func RegisterRoutes(ws *restful.WebService, devopsClient devopsClient.Interface,
 		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
 		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
 		Param(ws.QueryParameter("branch", "The name of SCM reference")).
+		Param(ws.QueryParameter("refType", "The type of SCM reference, allowed values: branch, tag, pr and mr. "+
+			"The pull requests and merge requests are both listed by pr or mr")).
 		Param(ws.QueryParameter("backward", "Backward compatibility for v1alpha2 API "+
 			"`/devops/{devops}/pipelines/{pipeline}/runs`. By default, the backward is true. If you want to list "+
 			"full data of PipelineRuns, just set the parameters to false.").
@@ -52,14 +57,40 @@ func RegisterRoutes(ws *restful.WebService, devopsClient devopsClient.Interface,
 			DefaultValue("true")).
 		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRunList{}))
 
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/latestruns").
+		To(handler.listLatestPipelineRuns).
+		Doc("Get the latest PipelineRun of each branch of the specified pipeline, the branch is empty if it's not "+
+			"a multi-branch pipeline").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
+		Returns(http.StatusOK, api.StatusOK, []v1alpha3.BranchRunSummary{}))
+
 	ws.Route(ws.POST("/namespaces/{namespace}/pipelines/{pipeline}/pipelineruns").
 		To(handler.createPipelineRun).
 		Doc("Create a PipelineRun for the specified pipeline").
 		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
 		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
 		Param(ws.QueryParameter("branch", "The name of SCM reference, only for multi-branch pipeline")).
+		Param(ws.HeaderParameter(IdempotencyKeyHeader, "The retried requests with the same key create only one "+
+			"PipelineRun, the existing one is returned with the header "+IdempotentReplayedHeader)).
 		Reads(devops.RunPayload{}).
-		Returns(http.StatusCreated, api.StatusOK, v1alpha3.PipelineRun{}))
+		Returns(http.StatusCreated, api.StatusOK, v1alpha3.PipelineRun{}).
+		Returns(http.StatusConflict, "The idempotency key was used by a different request", nil))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/queue").
+		To(handler.listQueueItems).
+		Doc("Get the items of the Jenkins queue which belong to the specified pipeline").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
+		Returns(http.StatusOK, api.StatusOK, []devops.QueueItem{}))
+
+	ws.Route(ws.POST("/namespaces/{namespace}/pipelines/{pipeline}/queue/{id}/cancel").
+		To(handler.cancelQueueItem).
+		Doc("Cancel an item of the Jenkins queue, the PipelineRun waiting for it will be cancelled as well").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
+		Param(ws.PathParameter("id", "ID of the queue item").DataType("integer")).
+		Returns(http.StatusOK, api.StatusOK, nil))
 
 	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}").
 		To(handler.getPipelineRun).
@@ -68,6 +99,54 @@ func RegisterRoutes(ws *restful.WebService, devopsClient devopsClient.Interface,
 		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
 		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRun{}))
 
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/provenance").
+		To(handler.getProvenance).
+		Doc("Get the SLSA provenance of a completed PipelineRun, it is a DSSE envelope if the provenance was signed").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Produces(restful.MIME_JSON).
+		Returns(http.StatusOK, api.StatusOK, nil).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/compare").
+		To(handler.comparePipelineRuns).
+		Doc("Compare a PipelineRun with a base PipelineRun of the same Pipeline, including parameters, "+
+			"Jenkinsfile version, stage durations, test failures, and artifact checksums").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("base", "Name of the base PipelineRun, e.g. the last successful one").Required(true)).
+		Returns(http.StatusOK, api.StatusOK, pipelinerun.Comparison{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/artifacts/diff").
+		To(handler.diffArtifacts).
+		Doc("Compare the artifacts of a PipelineRun with a base PipelineRun of the same Pipeline by their sizes and "+
+			"checksums, the missing artifacts and the artifacts whose sizes increased unexpectedly are flagged").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("base", "Name of the base PipelineRun, it's the last successful one before the "+
+			"PipelineRun by default")).
+		Param(ws.QueryParameter("sizeThreshold", "The percentage of the size increase which flags an artifact").
+			DataType("integer").DefaultValue(strconv.Itoa(pipelinerun.DefaultSizeIncreaseThreshold))).
+		Returns(http.StatusOK, api.StatusOK, pipelinerun.ArtifactReport{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/jenkinsfile/diff").
+		To(handler.diffJenkinsfile).
+		Doc("Get the difference of the recorded Jenkinsfile from a base PipelineRun of the same Pipeline to a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("base", "Name of the base PipelineRun").Required(true)).
+		Returns(http.StatusOK, api.StatusOK, jenkinsfile.Diff{}))
+
+	ws.Route(ws.POST("/namespaces/{namespace}/pipelineruns/{pipelinerun}/stop").
+		To(handler.stopPipelineRun).
+		Doc("Stop a PipelineRun. The hard mode aborts the PipelineRun immediately, the soft mode stops it after "+
+			"the running stages have finished").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("mode", "The mode of stopping, allowed values: hard and soft").
+			DefaultValue(stopModeHard)).
+		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRun{}))
+
 	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/nodedetails").
 		To(handler.getNodeDetails).
 		Doc("Get node details including steps and approvable for a given Pipeline").
@@ -75,12 +154,64 @@ func RegisterRoutes(ws *restful.WebService, devopsClient devopsClient.Interface,
 		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
 		Returns(http.StatusOK, api.StatusOK, []pipelinerun.NodeDetail{}))
 
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/timeline").
+		To(handler.getTimeline).
+		Doc("Get the timeline of a PipelineRun, including the queue waits, stages and steps, for Gantt rendering").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, pipelinerun.Timeline{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/events").
+		To(handler.getRunEvents).
+		Doc("Get the recorded events of a PipelineRun in order, such as the stages started and finished, and the "+
+			"approvals requested and resolved. They are available after the Jenkins build was discarded").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, []runevents.Event{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/nodes/{node}/log").
+		To(handler.getNodeLog).
+		Doc("Get the log of a node, like a stage or a parallel branch, of a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.PathParameter("node", "ID of the node")).
+		Param(ws.QueryParameter("start", "The byte offset which the log starts from").DataType("integer").DefaultValue("0")).
+		Param(ws.QueryParameter("limit", "The max bytes of the log, there is no limit if it's zero").DataType("integer").DefaultValue("0")).
+		Produces("text/plain; charset=utf-8").
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/nodes/{node}/steps/{step}/log").
+		To(handler.getStepLog).
+		Doc("Get the log of a step of a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.PathParameter("node", "ID of the node")).
+		Param(ws.PathParameter("step", "ID of the step")).
+		Param(ws.QueryParameter("start", "The byte offset which the log starts from").DataType("integer").DefaultValue("0")).
+		Param(ws.QueryParameter("limit", "The max bytes of the log, there is no limit if it's zero").DataType("integer").DefaultValue("0")).
+		Produces("text/plain; charset=utf-8").
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/artifacts").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		To(handler.listArtifacts).
+		Doc("List the artifacts of a PipelineRun").
+		Returns(http.StatusOK, api.StatusOK, []devops.Artifacts{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}))
+
 	// download PipelineRun artifact
 	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/artifacts/download").
 		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
 		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
 		Param(ws.QueryParameter("filename", "artifact filename. e.g. artifact:v1.0.1")).
+		Param(ws.QueryParameter("inline", "Display the artifact in the browser instead of downloading it, "+
+			"only the plain text, PDF and images except SVG could be displayed").
+			DataType("bool").DefaultValue("false")).
+		Param(ws.HeaderParameter("Range", "The byte range of the artifact, e.g. bytes=0-1023")).
+		Doc("Download an artifact of a PipelineRun, the range requests are supported").
 		To(handler.downloadArtifact).
 		Returns(http.StatusOK, api.StatusOK, nil).
+		Returns(http.StatusPartialContent, "Partial Content", nil).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}))
 }
@@ -76,6 +76,30 @@ func TestAPIsExist(t *testing.T) {
 			method: http.MethodPost,
 			uri:    "/webhook/pipeline-event",
 		},
+	}, {
+		name: "get node log",
+		args: args{
+			method: http.MethodGet,
+			uri:    "/namespaces/fake/pipelineruns/fake/nodes/fake/log",
+		},
+	}, {
+		name: "get step log",
+		args: args{
+			method: http.MethodGet,
+			uri:    "/namespaces/fake/pipelineruns/fake/nodes/fake/steps/fake/log",
+		},
+	}, {
+		name: "list artifacts",
+		args: args{
+			method: http.MethodGet,
+			uri:    "/namespaces/fake/pipelineruns/fake/artifacts",
+		},
+	}, {
+		name: "stop PipelineRun",
+		args: args{
+			method: http.MethodPost,
+			uri:    "/namespaces/fake/pipelineruns/fake/stop",
+		},
 	}, {
 		name: "download artifact",
 		args: args{
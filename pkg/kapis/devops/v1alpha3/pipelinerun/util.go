@@ -19,6 +19,10 @@ package pipelinerun
 import (
 	"errors"
 	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -122,3 +126,119 @@ func CreateBarePipelineRun(pipeline *v1alpha3.Pipeline, parameters []v1alpha3.Pa
 	}
 	return pipelineRun
 }
+
+const (
+	// logTextSizeHeader is the header of the offset which the next request should start from
+	logTextSizeHeader = "X-Text-Size"
+	// logMoreDataHeader is the header which indicates if there is more log to fetch
+	logMoreDataHeader = "X-More-Data"
+)
+
+// logRange is the byte range of the log to fetch
+type logRange struct {
+	start int64
+	// limit is the max bytes of the log, zero means no limit
+	limit int64
+}
+
+func parseLogRange(start, limit string) (logRange, error) {
+	r := logRange{}
+	var err error
+	if start != "" {
+		if r.start, err = strconv.ParseInt(start, 10, 64); err != nil || r.start < 0 {
+			return r, fmt.Errorf("invalid start '%s', it should be a non-negative integer", start)
+		}
+	}
+	if limit != "" {
+		if r.limit, err = strconv.ParseInt(limit, 10, 64); err != nil || r.limit < 0 {
+			return r, fmt.Errorf("invalid limit '%s', it should be a non-negative integer", limit)
+		}
+	}
+	return r, nil
+}
+
+// cut cuts the log by the limit, then returns the offset of the next fetching, and whether there is more log
+func (r logRange) cut(log []byte, header http.Header) ([]byte, int64, bool) {
+	if r.limit > 0 && int64(len(log)) > r.limit {
+		return log[:r.limit], r.start + r.limit, true
+	}
+	textSize, err := strconv.ParseInt(header.Get(logTextSizeHeader), 10, 64)
+	if err != nil {
+		textSize = r.start + int64(len(log))
+	}
+	moreData, _ := strconv.ParseBool(header.Get(logMoreDataHeader))
+	return log, textSize, moreData
+}
+
+// detectContentType detects the content type of an artifact by the file extension first, then the content.
+// The given content type is used if it's a specific one.
+func detectContentType(filename, contentType string, head []byte) string {
+	if contentType != "" && !strings.HasPrefix(contentType, "application/octet-stream") {
+		return contentType
+	}
+	if byExt := mime.TypeByExtension(path.Ext(filename)); byExt != "" {
+		return byExt
+	}
+	if len(head) > 0 {
+		return http.DetectContentType(head)
+	}
+	return "application/octet-stream"
+}
+
+// inlineSafeContentTypes are the content types which cannot run scripts in the browsers,
+// the SVG images are excluded because they may contain scripts
+var inlineSafeContentTypes = map[string]bool{
+	"text/plain":      true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+}
+
+// isInlineSafe returns true if an artifact of the content type is safe to be displayed inline on the origin of the
+// apiserver. The HTML or SVG artifacts are written by the Pipelines, they could be used to run the stored XSS.
+func isInlineSafe(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && inlineSafeContentTypes[strings.ToLower(mediaType)]
+}
+
+const (
+	// stopModeHard aborts the PipelineRun immediately.
+	stopModeHard = "hard"
+	// stopModeSoft stops the PipelineRun after the running stages have finished.
+	stopModeSoft = "soft"
+)
+
+func getStopAction(mode string) (v1alpha3.Action, error) {
+	switch mode {
+	case "", stopModeHard:
+		return v1alpha3.Stop, nil
+	case stopModeSoft:
+		return v1alpha3.SoftStop, nil
+	}
+	return "", fmt.Errorf("invalid stop mode: %s, allowed values: %s and %s", mode, stopModeHard, stopModeSoft)
+}
+
+// getRefType parses the reference type of the PipelineRuns to list, the merge requests are listed as pull requests
+func getRefType(refType string) (v1alpha3.RefType, error) {
+	switch v1alpha3.RefType(refType) {
+	case "", v1alpha3.Branch, v1alpha3.Tag, v1alpha3.PullRequest:
+		return v1alpha3.RefType(refType), nil
+	case v1alpha3.MergeRequest:
+		return v1alpha3.PullRequest, nil
+	}
+	return "", fmt.Errorf("invalid reference type: %s, allowed values: %s, %s, %s and %s", refType,
+		v1alpha3.Branch, v1alpha3.Tag, v1alpha3.PullRequest, v1alpha3.MergeRequest)
+}
+
+// filterByRefType returns the PipelineRuns of the reference type
+func filterByRefType(prs []v1alpha3.PipelineRun, refType v1alpha3.RefType) (filtered []v1alpha3.PipelineRun) {
+	for i := range prs {
+		if scm := prs[i].Spec.SCM; scm != nil && scm.GetRefType() == refType {
+			filtered = append(filtered, prs[i])
+		}
+	}
+	return
+}
@@ -19,6 +19,7 @@ package pipelinerun
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"reflect"
 	"testing"
 
@@ -227,3 +228,92 @@ func TestCreatePipelineRun(t *testing.T) {
 	assert.Equal(t, pipelineRun.Namespace, pipeline.Namespace)
 	assert.NotNil(t, pipelineRun.Annotations)
 }
+
+func Test_parseLogRange(t *testing.T) {
+	tests := []struct {
+		start   string
+		limit   string
+		want    logRange
+		wantErr bool
+	}{
+		{want: logRange{}},
+		{start: "10", limit: "100", want: logRange{start: 10, limit: 100}},
+		{start: "-1", wantErr: true},
+		{start: "a", wantErr: true},
+		{limit: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.start+"-"+tt.limit, func(t *testing.T) {
+			got, err := parseLogRange(tt.start, tt.limit)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_logRange_cut(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Text-Size", "110")
+	header.Set("X-More-Data", "true")
+
+	log, textSize, moreData := logRange{start: 100}.cut([]byte("0123456789"), header)
+	assert.Equal(t, "0123456789", string(log))
+	assert.Equal(t, int64(110), textSize)
+	assert.True(t, moreData)
+
+	log, textSize, moreData = logRange{start: 100, limit: 4}.cut([]byte("0123456789"), nil)
+	assert.Equal(t, "0123", string(log))
+	assert.Equal(t, int64(104), textSize)
+	assert.True(t, moreData)
+
+	log, textSize, moreData = logRange{start: 100, limit: 20}.cut([]byte("0123456789"), nil)
+	assert.Equal(t, "0123456789", string(log))
+	assert.Equal(t, int64(110), textSize)
+	assert.False(t, moreData)
+}
+
+func Test_detectContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		head        []byte
+		want        string
+	}{{
+		name:        "specific content type",
+		filename:    "a.json",
+		contentType: "text/plain",
+		want:        "text/plain",
+	}, {
+		name:        "by the file extension",
+		filename:    "dir/a.json",
+		contentType: "application/octet-stream",
+		want:        "application/json",
+	}, {
+		name:     "by the content",
+		filename: "a",
+		head:     []byte("<html><body></body></html>"),
+		want:     "text/html; charset=utf-8",
+	}, {
+		name:     "unknown",
+		filename: "a",
+		want:     "application/octet-stream",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectContentType(tt.filename, tt.contentType, tt.head))
+		})
+	}
+}
+
+func Test_isInlineSafe(t *testing.T) {
+	assert.True(t, isInlineSafe("text/plain; charset=utf-8"))
+	assert.True(t, isInlineSafe("image/PNG"))
+	assert.True(t, isInlineSafe("application/pdf"))
+	assert.False(t, isInlineSafe("text/html; charset=utf-8"))
+	assert.False(t, isInlineSafe("image/svg+xml"))
+	assert.False(t, isInlineSafe("application/octet-stream"))
+	assert.False(t, isInlineSafe(""))
+}
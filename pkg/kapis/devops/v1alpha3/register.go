@@ -28,21 +28,44 @@ import (
 	restfulspec "github.com/emicklei/go-restful-openapi"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/history"
 	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/client/s3"
+	apiserverconfig "kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/approval"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/badge"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/bulkoperation"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/common"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/converter"
+	costapi "kubesphere.io/devops/pkg/kapis/devops/v1alpha3/cost"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/credential"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/dashboard"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/findings"
+	historyapi "kubesphere.io/devops/pkg/kapis/devops/v1alpha3/history"
+	issueapi "kubesphere.io/devops/pkg/kapis/devops/v1alpha3/issue"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/jenkinsscript"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/lint"
+	logarchiveapi "kubesphere.io/devops/pkg/kapis/devops/v1alpha3/logarchive"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/onboarding"
+	ownershipapi "kubesphere.io/devops/pkg/kapis/devops/v1alpha3/ownership"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipeline"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/releasenotes"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/scm"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/template"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/triggertoken"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/apiserver/authorization/subjectaccessreview"
 	"kubesphere.io/devops/pkg/apiserver/query"
 	"kubesphere.io/devops/pkg/apiserver/runtime"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/stats"
 	"kubesphere.io/devops/pkg/server/params"
 )
 
@@ -50,13 +73,23 @@ import (
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;update;delete;create;watch
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get;list;update;delete;create;watch
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;update;delete;create;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;delete;create;watch
 
 // GroupVersion describes CRD group and its version.
 var GroupVersion = schema.GroupVersion{Group: api.GroupName, Version: "v1alpha3"}
 
-// AddToContainer adds web service into container.
+// AddToContainer adds web service into container. The Jenkins scripts are only authorized if the auth mode is
+// verified, and their audit records are kept unlimited unless scriptAuditMaxSize is positive.
 func AddToContainer(container *restful.Container, devopsClient devopsClient.Interface, k8sClient k8s.Client,
-	client client.Client, tokenIssue token.Issuer, jenkins core.JenkinsCore) (wss []*restful.WebService) {
+	client client.Client, tokenIssue token.Issuer, jenkins core.JenkinsCore, statsCollector *stats.Collector,
+	historyClient history.Interface, s3Client s3.Interface, authMode apiserverconfig.AuthMode,
+	scriptAuditMaxSize int) (wss []*restful.WebService) {
+	// the Jenkins scripts run with the account of the apiserver, the user in the context is not trusted unless the
+	// signature of its token is verified, so all the requests are forbidden without an authorizer
+	var scriptAuthorizer authorizer.Authorizer
+	if authMode == apiserverconfig.AuthModeVerified {
+		scriptAuthorizer = subjectaccessreview.New(k8sClient.Kubernetes().AuthorizationV1().SubjectAccessReviews())
+	}
 
 	services := []*restful.WebService{
 		runtime.NewWebService(v1alpha3.GroupVersion),
@@ -74,6 +107,23 @@ func AddToContainer(container *restful.Container, devopsClient devopsClient.Inte
 			GenericClient: client,
 		})
 		webhook.RegisterWebhooks(client, service, tokenIssue, jenkins)
+		converter.RegisterRoutes(service)
+		lint.RegisterRoutes(service, client)
+		credential.RegisterRoutes(service, client)
+		triggertoken.RegisterRoutes(service, client, tokenIssue)
+		approval.RegisterRoutes(service, client, devopsClient, tokenIssue)
+		badge.RegisterRoutes(service, client)
+		dashboard.RegisterRoutes(service, statsCollector)
+		historyapi.RegisterRoutes(service, historyClient)
+		logarchiveapi.RegisterRoutes(service, client, s3Client)
+		costapi.RegisterRoutes(service, client)
+		ownershipapi.RegisterRoutes(service, client)
+		onboarding.RegisterRoutes(service, client)
+		jenkinsscript.RegisterRoutes(service, client, devopsClient, scriptAuthorizer, scriptAuditMaxSize)
+		bulkoperation.RegisterRoutes(service, client)
+		findings.RegisterRoutes(service, client)
+		releasenotes.RegisterRoutes(service, client)
+		issueapi.RegisterRoutes(service, client)
 		container.Add(service)
 	}
 	return services
@@ -35,7 +35,9 @@ import (
 	fakeclientset "kubesphere.io/devops/pkg/client/clientset/versioned/fake"
 	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
 	"kubesphere.io/devops/pkg/client/k8s"
+	apiserverconfig "kubesphere.io/devops/pkg/config"
 	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/stats"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -61,7 +63,8 @@ func TestAPIsExist(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "fake", Namespace: "fake",
 		},
-	}), &token.FakeIssuer{}, core.JenkinsCore{})
+	}), &token.FakeIssuer{}, core.JenkinsCore{}, stats.NewCollector(stats.DefaultRetention), nil, nil,
+		apiserverconfig.AuthModeToken, 0)
 
 	type args struct {
 		method string
@@ -93,6 +96,14 @@ func TestAPIsExist(t *testing.T) {
 		},
 		body:       `{}`,
 		expectCode: 200,
+	}, {
+		name: "create an invalid credential",
+		args: args{
+			method: http.MethodPost,
+			uri:    "/devops/fake/credentials",
+		},
+		body:       `{"metadata":{"name":"token"},"type":"credential.devops.kubesphere.io/bearer-token"}`,
+		expectCode: 400,
 	}, {
 		name: "get a credential",
 		args: args{
@@ -114,6 +125,14 @@ func TestAPIsExist(t *testing.T) {
 		},
 		body:       `{}`,
 		expectCode: 200,
+	}, {
+		name: "update a credential with invalid data",
+		args: args{
+			method: http.MethodPut,
+			uri:    "/devops/fake/credentials/fake",
+		},
+		body:       `{"metadata":{"name":"fake"},"type":"credential.devops.kubesphere.io/docker-registry","data":{"server":"ZG9ja2VyLmlv"}}`,
+		expectCode: 400,
 	}, {
 		name: "delete a credential",
 		args: args{
@@ -271,7 +290,9 @@ func TestGetDevOpsProject(t *testing.T) {
 					constants.WorkspaceLabelKey: "ws",
 				},
 			},
-		})), fake.NewFakeClientWithScheme(schema), &token.FakeIssuer{}, core.JenkinsCore{})
+		})), fake.NewFakeClientWithScheme(schema), &token.FakeIssuer{}, core.JenkinsCore{},
+		stats.NewCollector(stats.DefaultRetention), nil, nil,
+		apiserverconfig.AuthModeToken, 0)
 
 	type args struct {
 		method string
@@ -315,3 +336,20 @@ func TestGetDevOpsProject(t *testing.T) {
 		})
 	}
 }
+
+func TestJenkinsScriptsRequireVerifiedAuthMode(t *testing.T) {
+	schema, err := v1alpha1.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	container := restful.NewContainer()
+	AddToContainer(container, fakedevops.NewFakeDevops(nil), k8s.NewFakeClientSets(k8sfake.NewSimpleClientset(), nil, nil, "", nil,
+		fakeclientset.NewSimpleClientset()), fake.NewFakeClientWithScheme(schema), &token.FakeIssuer{}, core.JenkinsCore{},
+		stats.NewCollector(stats.DefaultRetention), nil, nil,
+		apiserverconfig.AuthModeToken, 0)
+
+	for _, uri := range []string{"/jenkinsscripts", "/jenkinsscriptexecutions"} {
+		req := httptest.NewRequest(http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusForbidden, recorder.Code, uri)
+	}
+}
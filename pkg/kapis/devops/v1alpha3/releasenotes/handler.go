@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releasenotes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-x/go-scm/scm"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/release"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseNotes are the release notes with the rendered Markdown
+type ReleaseNotes struct {
+	*release.Notes
+	// PreviousTag is the tag of the previous release, it's only available for a PipelineRun
+	PreviousTag string `json:"previousTag,omitempty"`
+	Markdown    string `json:"markdown"`
+}
+
+type handler struct {
+	client           client.Client
+	scmClientFactory git.SCMClientFactory
+}
+
+func newHandler(c client.Client) *handler {
+	return &handler{client: c, scmClientFactory: git.NewSCMClientFactory(c)}
+}
+
+func (h *handler) getPipelineNotes(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	from, to := req.QueryParameter("from"), req.QueryParameter("to")
+	if to == "" {
+		kapis.HandleBadRequest(resp, req, errors.New("the revision 'to' is required"))
+		return
+	}
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: req.PathParameter("namespace"),
+		Name: req.PathParameter("pipeline")}, pipeline); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	h.writeNotes(req, resp, pipeline.Namespace, &pipeline.Spec, "", from, to)
+}
+
+func (h *handler) getPipelineRunNotes(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	pipelineRun := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: req.PathParameter("namespace"),
+		Name: req.PathParameter("pipelinerun")}, pipelineRun); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	to := release.GetRevision(pipelineRun)
+	if to == "" || pipelineRun.Spec.PipelineSpec == nil {
+		kapis.HandleBadRequest(resp, req, fmt.Errorf("the revision of PipelineRun '%s/%s' is unknown",
+			pipelineRun.Namespace, pipelineRun.Name))
+		return
+	}
+
+	// the notes start from the previous release of the same branch by default
+	from, previousTag := req.QueryParameter("from"), ""
+	if from == "" {
+		previous, err := release.FindPreviousRelease(ctx, h.client, pipelineRun)
+		if err != nil {
+			kapis.HandleError(req, resp, err)
+			return
+		}
+		if previous != nil {
+			from, previousTag = release.GetRevision(previous), previous.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey]
+		}
+	}
+	h.writeNotes(req, resp, pipelineRun.Namespace, pipelineRun.Spec.PipelineSpec, previousTag, from, to)
+}
+
+func (h *handler) writeNotes(req *restful.Request, resp *restful.Response, namespace string, spec *v1alpha3.PipelineSpec,
+	previousTag, from, to string) {
+	if spec.Type != v1alpha3.MultiBranchPipelineType || spec.MultiBranchPipeline == nil {
+		kapis.HandleBadRequest(resp, req, errors.New("the release notes are only available for the multi-branch Pipelines"))
+		return
+	}
+	notes, err := h.generate(req.Request.Context(), namespace, spec.MultiBranchPipeline, from, to)
+	if err == git.ErrUnsupportedSource {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	} else if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	title := previousTag
+	if title == "" {
+		title = from
+	}
+	_ = resp.WriteEntity(&ReleaseNotes{Notes: notes, PreviousTag: previousTag, Markdown: notes.Markdown(title)})
+}
+
+// generate generates the release notes by the API of the SCM provider with the credential of the Pipeline
+func (h *handler) generate(ctx context.Context, namespace string, pipeline *v1alpha3.MultiBranchPipeline, from, to string) (
+	notes *release.Notes, err error) {
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(pipeline); err != nil {
+		return
+	}
+	var secretRef *v1.SecretReference
+	if credentialID := pipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: namespace, Name: credentialID}
+	}
+	var scmClient *scm.Client
+	if scmClient, err = h.scmClientFactory(provider, server, secretRef); err == nil {
+		notes, err = release.GenerateNotes(ctx, scmClient, repo, from, to)
+	}
+	return
+}
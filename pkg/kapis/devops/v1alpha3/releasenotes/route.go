@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releasenotes
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelines,verbs=get
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// RegisterRoutes registers the APIs of the release notes which are generated from the SCM
+func RegisterRoutes(ws *restful.WebService, c client.Client) {
+	registerRoutes(ws, newHandler(c))
+}
+
+func registerRoutes(ws *restful.WebService, h *handler) {
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/releasenotes").
+		To(h.getPipelineNotes).
+		Doc("Generate the release notes of a multi-branch Pipeline between two revisions, including the merged pull "+
+			"requests, the commits, and the closed issues").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Param(ws.QueryParameter("from", "The commit ID, branch, or tag of the previous release, "+
+			"all the commits are included if it is empty")).
+		Param(ws.QueryParameter("to", "The commit ID, branch, or tag of the release").Required(true)).
+		Returns(http.StatusOK, api.StatusOK, ReleaseNotes{}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/releasenotes").
+		To(h.getPipelineRunNotes).
+		Doc("Generate the release notes of the revision which a PipelineRun was built from, since the previous "+
+			"release of the same branch").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRun")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("from", "The commit ID, branch, or tag to start from, "+
+			"it's the revision of the previous release by default")).
+		Returns(http.StatusOK, api.StatusOK, ReleaseNotes{}))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releasenotes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/release"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReleaseNotesAPIs(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/app/commits/v1.0", "/repos/org/app/commits/c1":
+			_, _ = w.Write([]byte(`{"sha":"c1"}`))
+		case "/repos/org/app/commits":
+			_, _ = w.Write([]byte(`[{"sha":"c3","commit":{"message":"Add the health check (#2)"}},
+{"sha":"c2","commit":{"message":"Fix the build\n\nFixes #1"}},{"sha":"c1","commit":{"message":"First"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	spec := v1alpha3.PipelineSpec{
+		Type: v1alpha3.MultiBranchPipelineType,
+		MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+			SourceType:   v1alpha3.SourceTypeGithub,
+			GitHubSource: &v1alpha3.GithubSource{Owner: "org", Repo: "app"},
+		},
+	}
+	newRun := func(name, revision string) *v1alpha3.PipelineRun {
+		completed := metav1.NewTime(time.Now())
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name,
+				Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+				Annotations: map[string]string{v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey: revision}},
+			Spec:   v1alpha3.PipelineRunSpec{PipelineSpec: spec.DeepCopy(), SCM: &v1alpha3.SCM{RefName: "master"}},
+			Status: v1alpha3.PipelineRunStatus{CompletionTime: &completed},
+		}
+	}
+	released := newRun("run-1", "c1")
+	released.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] = "v1.0"
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		&v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app"}, Spec: spec},
+		&v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "simple"},
+			Spec: v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType}},
+		released, newRun("run-2", "c3"), newRun("run-3", "")).Build()
+
+	container := restful.NewContainer()
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	registerRoutes(ws, &handler{client: c, scmClientFactory: func(string, string, *v1.SecretReference) (*scm.Client, error) {
+		return github.New(server.URL)
+	}})
+	container.Add(ws)
+	request := func(uri string) (*httptest.ResponseRecorder, *ReleaseNotes) {
+		httpRequest, _ := http.NewRequest(http.MethodGet, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, nil)
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		notes := &ReleaseNotes{}
+		if httpWriter.Code == http.StatusOK {
+			assert.Nil(t, json.Unmarshal(httpWriter.Body.Bytes(), notes))
+		}
+		return httpWriter, notes
+	}
+
+	resp, notes := request("/namespaces/ns/pipelines/app/releasenotes?from=v1.0&to=master")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []release.PullRequest{{Number: 2, Title: "Add the health check"}}, notes.PullRequests)
+	assert.Equal(t, []release.Commit{{Sha: "c2", Subject: "Fix the build"}}, notes.Commits)
+	assert.Equal(t, []int{1}, notes.Issues)
+	assert.Equal(t, "## Changes since v1.0\n\n### Pull requests\n\n* Add the health check (#2)\n\n"+
+		"### Commits\n\n* Fix the build (c2)\n\n### Issues\n\n#1\n", notes.Markdown)
+
+	resp, _ = request("/namespaces/ns/pipelines/app/releasenotes?from=v1.0")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp, _ = request("/namespaces/ns/pipelines/simple/releasenotes?to=master")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp, _ = request("/namespaces/ns/pipelines/fake/releasenotes?to=master")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// the notes of a PipelineRun start from the previous release
+	resp, notes = request("/namespaces/ns/pipelineruns/run-2/releasenotes")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "v1.0", notes.PreviousTag)
+	assert.Equal(t, "c1", notes.From)
+	assert.Equal(t, "c3", notes.To)
+	assert.Len(t, notes.Commits, 1)
+
+	resp, _ = request("/namespaces/ns/pipelineruns/run-3/releasenotes")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
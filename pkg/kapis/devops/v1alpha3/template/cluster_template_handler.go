@@ -72,7 +72,7 @@ func (h *handler) renderClusterTemplate(templateName string, parameters []Parame
 		return nil, err
 	}
 
-	return render(template, parameters)
+	return Render(template, parameters)
 }
 
 func clusterTemplatesToObjects(templates []v1alpha3.ClusterTemplate) []runtime.Object {
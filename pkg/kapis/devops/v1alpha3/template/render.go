@@ -33,7 +33,9 @@ type Parameter struct {
 	Value interface{} `json:"value"`
 }
 
-func render(templateObject v1alpha3.TemplateObject, parameters []Parameter) (v1alpha3.TemplateObject, error) {
+// Render renders a template with the parameters, the result is in the annotation devops.kubesphere.io/render-result
+// of the returned copy
+func Render(templateObject v1alpha3.TemplateObject, parameters []Parameter) (v1alpha3.TemplateObject, error) {
 	templateObject = templateObject.DeepCopyObject().(v1alpha3.TemplateObject)
 	rawTemplate := templateObject.TemplateSpec().Template
 	templateName := types.NamespacedName{
@@ -24,7 +24,7 @@ import (
 	"testing"
 )
 
-func Test_render(t *testing.T) {
+func TestRender(t *testing.T) {
 	createTemplate := func(name, template string) v1alpha3.TemplateObject {
 		return &v1alpha3.Template{
 			ObjectMeta: metav1.ObjectMeta{
@@ -126,8 +126,8 @@ func Test_render(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			template, err := render(tt.args.template, tt.args.parameters)
-			if !tt.wantErr(t, err, fmt.Sprintf("render(%v, %v)", tt.args.template, tt.args.parameters)) {
+			template, err := Render(tt.args.template, tt.args.parameters)
+			if !tt.wantErr(t, err, fmt.Sprintf("Render(%v, %v)", tt.args.template, tt.args.parameters)) {
 				return
 			}
 			tt.verify(t, template)
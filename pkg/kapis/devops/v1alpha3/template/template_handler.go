@@ -81,7 +81,7 @@ func (h *handler) renderTemplate(devopsName, templateName string, parameters []P
 	if err != nil {
 		return nil, err
 	}
-	return render(tmpl, parameters)
+	return Render(tmpl, parameters)
 }
 
 func templatesToObjects(templates []v1alpha3.Template) []runtime.Object {
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triggertoken
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/models/triggertoken"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type handler struct {
+	client client.Client
+	issuer token.Issuer
+	now    func() time.Time
+}
+
+func newHandler(c client.Client, issuer token.Issuer) *handler {
+	return &handler{client: c, issuer: issuer, now: time.Now}
+}
+
+func (h *handler) list(req *restful.Request, resp *restful.Response) {
+	tokens, err := triggertoken.List(req.Request.Context(), h.client,
+		req.PathParameter("namespace"), req.PathParameter("pipeline"), h.now())
+	if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	_ = resp.WriteEntity(tokens)
+}
+
+func (h *handler) mint(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	options := triggertoken.MintOptions{}
+	if err := req.ReadEntity(&options); err != nil && err != io.EOF {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	user, ok := apiserverrequest.UserFrom(ctx)
+	if !ok || user == nil || user.GetName() == "" {
+		kapis.HandleUnauthorized(resp, req, fmt.Errorf("a login user is required to mint trigger tokens"))
+		return
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: req.PathParameter("namespace"), Name: req.PathParameter("pipeline")}, pipeline); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+
+	minted, err := triggertoken.Mint(ctx, h.client, h.issuer, pipeline, user.GetName(), options, h.now())
+	if err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	_ = resp.WriteEntity(minted)
+}
+
+func (h *handler) revoke(req *restful.Request, resp *restful.Response) {
+	if err := triggertoken.Revoke(req.Request.Context(), h.client, req.PathParameter("namespace"),
+		req.PathParameter("pipeline"), req.PathParameter("token")); err != nil {
+		kapis.HandleError(req, resp, err)
+	}
+}
+
+func (h *handler) trigger(req *restful.Request, resp *restful.Response) {
+	ctx := req.Request.Context()
+	namespace, pipelineName := req.PathParameter("namespace"), req.PathParameter("pipeline")
+
+	tokenString := strings.TrimSpace(strings.TrimPrefix(req.HeaderParameter("Authorization"), "Bearer "))
+	if tokenString == "" {
+		kapis.HandleUnauthorized(resp, req, fmt.Errorf("the trigger token is required"))
+		return
+	}
+	verified, err := triggertoken.Verify(ctx, h.client, h.issuer, namespace, pipelineName, tokenString, h.now())
+	if err != nil {
+		kapis.HandleUnauthorized(resp, req, err)
+		return
+	}
+
+	payload := devops.RunPayload{}
+	if err = req.ReadEntity(&payload); err != nil && err != io.EOF {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	pipeline := &v1alpha3.Pipeline{}
+	if err = h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pipelineName}, pipeline); err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if pipeline.IsSuspended() {
+		kapis.HandleConflict(resp, req, fmt.Errorf("pipeline %s is suspended", pipelineName))
+		return
+	}
+	var scm *v1alpha3.SCM
+	if scm, err = pipelinerun.CreateScm(&pipeline.Spec, req.QueryParameter("branch")); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+	var key string
+	if key, err = pipelinerun.GetIdempotencyKey(req.HeaderParameter(pipelinerun.IdempotencyKeyHeader), payload.IdempotencyKey); err != nil {
+		kapis.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	run := pipelinerun.CreatePipelineRun(pipeline, &payload, scm)
+	run.Annotations[v1alpha3.PipelineRunCreatorAnnoKey] = verified.Creator
+	run.Annotations[v1alpha3.PipelineRunTriggerTokenAnnoKey] = verified.ID
+	pipelinerun.SetIdempotencyKey(run, key)
+	created, replayed, err := pipelinerun.CreateIdempotently(ctx, h.client, run)
+	if errors.Is(err, pipelinerun.ErrIdempotencyKeyReused) {
+		kapis.HandleConflict(resp, req, err)
+		return
+	} else if err != nil {
+		kapis.HandleError(req, resp, err)
+		return
+	}
+	if replayed {
+		resp.AddHeader(pipelinerun.IdempotentReplayedHeader, "true")
+	}
+	_ = resp.WriteEntity(created)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triggertoken
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/models/triggertoken"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registry the handlers of the trigger tokens
+func RegisterRoutes(ws *restful.WebService, c client.Client, issuer token.Issuer) {
+	h := newHandler(c, issuer)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/triggertokens").
+		To(h.list).
+		Doc("List the trigger tokens of a Pipeline").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Returns(http.StatusOK, api.StatusOK, []triggertoken.Token{}))
+
+	ws.Route(ws.POST("/namespaces/{namespace}/pipelines/{pipeline}/triggertokens").
+		To(h.mint).
+		Doc("Mint a trigger token of a Pipeline, the token is only returned once").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Reads(triggertoken.MintOptions{}).
+		Returns(http.StatusOK, api.StatusOK, triggertoken.Token{}))
+
+	ws.Route(ws.DELETE("/namespaces/{namespace}/pipelines/{pipeline}/triggertokens/{token}").
+		To(h.revoke).
+		Doc("Revoke a trigger token of a Pipeline").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Param(ws.PathParameter("token", "ID of the trigger token")).
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	ws.Route(ws.POST("/webhooks/trigger/namespaces/{namespace}/pipelines/{pipeline}").
+		To(h.trigger).
+		Doc("Create a PipelineRun with a trigger token of the Pipeline, the token is passed by the header Authorization: Bearer <token>").
+		Param(ws.PathParameter("namespace", "Namespace of the Pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the Pipeline")).
+		Param(ws.QueryParameter("branch", "The name of SCM reference, only for multi-branch pipeline")).
+		Param(ws.HeaderParameter("Authorization", "The trigger token, such as: Bearer <token>")).
+		Param(ws.HeaderParameter(pipelinerun.IdempotencyKeyHeader, "The retried requests with the same key create only "+
+			"one PipelineRun")).
+		Reads(devops.RunPayload{}).
+		Returns(http.StatusCreated, api.StatusOK, v1alpha3.PipelineRun{}).
+		Returns(http.StatusConflict, "The idempotency key was used by a different request", nil))
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triggertoken
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverrequest "kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/triggertoken"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTriggerTokenAPIs(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec:       v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType},
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline).Build()
+
+	container := restful.NewContainer()
+	ws := runtime.NewWebService(v1alpha3.GroupVersion)
+	RegisterRoutes(ws, c, token.NewTokenIssuer("secret", 0))
+	container.Add(ws)
+	container.Filter(func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		if name := req.HeaderParameter("X-User"); name != "" {
+			req.Request = req.Request.WithContext(apiserverrequest.WithUser(req.Request.Context(), &user.DefaultInfo{Name: name}))
+		}
+		chain.ProcessFilter(req, resp)
+	})
+	request := func(method, uri, body string, header map[string]string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(method, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, strings.NewReader(body))
+		httpRequest.Header.Set("Content-Type", restful.MIME_JSON)
+		for k, v := range header {
+			httpRequest.Header.Set(k, v)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	tokensURI := "/namespaces/ns/pipelines/demo/triggertokens"
+	triggerURI := "/webhooks/trigger/namespaces/ns/pipelines/demo"
+
+	// a login user is required
+	resp := request(http.MethodPost, tokensURI, `{}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = request(http.MethodPost, tokensURI, `{"description":"ci"}`, map[string]string{"X-User": "alice"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	minted := &triggertoken.Token{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), minted))
+	assert.NotEmpty(t, minted.Token)
+
+	resp = request(http.MethodGet, tokensURI, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var tokens []triggertoken.Token
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &tokens))
+	if assert.Equal(t, 1, len(tokens)) {
+		assert.Equal(t, minted.ID, tokens[0].ID)
+		assert.Empty(t, tokens[0].Token)
+	}
+
+	// trigger the Pipeline with the token
+	resp = request(http.MethodPost, triggerURI, `{"parameters":[{"name":"env","value":"staging"}]}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	resp = request(http.MethodPost, "/webhooks/trigger/namespaces/ns/pipelines/another", `{}`,
+		map[string]string{"Authorization": "Bearer " + minted.Token})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	resp = request(http.MethodPost, triggerURI, `{"parameters":[{"name":"env","value":"staging"}]}`,
+		map[string]string{"Authorization": "Bearer " + minted.Token})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	run := &v1alpha3.PipelineRun{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), run))
+	assert.Equal(t, "alice", run.Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+	assert.Equal(t, minted.ID, run.Annotations[v1alpha3.PipelineRunTriggerTokenAnnoKey])
+	assert.Equal(t, []v1alpha3.Parameter{{Name: "env", Value: "staging"}}, run.Spec.Parameters)
+
+	// the suspended Pipeline ignores the trigger
+	suspended := &v1alpha3.Pipeline{}
+	assert.Nil(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "demo"}, suspended))
+	suspended.Spec.Suspend = true
+	assert.Nil(t, c.Update(context.TODO(), suspended))
+	resp = request(http.MethodPost, triggerURI, `{}`, map[string]string{"Authorization": "Bearer " + minted.Token})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// the revoked token cannot trigger the Pipeline
+	resp = request(http.MethodDelete, tokensURI+"/"+minted.ID, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = request(http.MethodDelete, tokensURI+"/"+minted.ID, "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = request(http.MethodPost, triggerURI, `{}`, map[string]string{"Authorization": "Bearer " + minted.Token})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/api/devops"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/models/chatops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// authorizeFunc returns true if the user is allowed to run the Pipelines in the namespace
+type authorizeFunc func(ctx context.Context, user, namespace string) (bool, error)
+
+// chatOpsHandler handles the commands from chats, such as Slack slash commands or GitHub comments
+type chatOpsHandler struct {
+	client.Client
+	now       func() time.Time
+	authorize authorizeFunc
+}
+
+func newChatOpsHandler(genericClient client.Client) *chatOpsHandler {
+	handler := &chatOpsHandler{Client: genericClient, now: time.Now}
+	handler.authorize = handler.subjectAccessReview
+	return handler
+}
+
+// slack handles the slash commands from Slack, the text should be like "/deploy namespace/pipeline staging"
+func (h *chatOpsHandler) slack(request *restful.Request, response *restful.Response) {
+	body, ok := h.readAndVerify(request, response, chatops.SourceSlack, func(secret string, body []byte) error {
+		return chatops.VerifySlack(request.Request.Header, body, secret, h.now())
+	})
+	if !ok {
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		_ = response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	command := chatops.Parse(values.Get("command") + " " + values.Get("text"))
+	if command == nil {
+		_ = response.WriteError(http.StatusBadRequest, fmt.Errorf("invalid command %q", values.Get("command")))
+		return
+	}
+	command.Source = chatops.SourceSlack
+	command.ReplyURL = values.Get("response_url")
+	ctx := request.Request.Context()
+	if message := h.resolveUser(ctx, command, values.Get("user_id")); message != "" {
+		_ = response.WriteAsJson(chatops.NewReply(chatops.SourceSlack, message))
+		return
+	}
+	_ = response.WriteAsJson(chatops.NewReply(chatops.SourceSlack, h.runCommand(ctx, command)))
+}
+
+// dingTalkMessage is the message from the outgoing robot of DingTalk
+type dingTalkMessage struct {
+	Text struct {
+		Content string `json:"content"`
+	} `json:"text"`
+	SenderStaffID  string `json:"senderStaffId"`
+	SessionWebhook string `json:"sessionWebhook"`
+}
+
+// dingTalk handles the messages which mention the outgoing robot of DingTalk
+func (h *chatOpsHandler) dingTalk(request *restful.Request, response *restful.Response) {
+	body, ok := h.readAndVerify(request, response, chatops.SourceDingTalk, func(secret string, _ []byte) error {
+		return chatops.VerifyDingTalk(request.Request.Header, secret, h.now())
+	})
+	if !ok {
+		return
+	}
+
+	message := &dingTalkMessage{}
+	if err := json.Unmarshal(body, message); err != nil {
+		_ = response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	command := chatops.Parse(message.Text.Content)
+	if command == nil {
+		_ = response.WriteAsJson(chatops.NewReply(chatops.SourceDingTalk, "usage: /<command> <namespace>/<pipeline>[@branch] [args...]"))
+		return
+	}
+	command.Source = chatops.SourceDingTalk
+	command.ReplyURL = message.SessionWebhook
+	ctx := request.Request.Context()
+	if reply := h.resolveUser(ctx, command, message.SenderStaffID); reply != "" {
+		_ = response.WriteAsJson(chatops.NewReply(chatops.SourceDingTalk, reply))
+		return
+	}
+	_ = response.WriteAsJson(chatops.NewReply(chatops.SourceDingTalk, h.runCommand(ctx, command)))
+}
+
+// issueCommentEvent is the issue_comment event of GitHub
+type issueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int       `json:"number"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+		User struct {
+			ID    int64  `json:"id"`
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		HTMLURL  string `json:"html_url"`
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+	} `json:"repository"`
+}
+
+// gitHub handles the comments of pull requests, such as "/retest".
+// The Pipelines of the repository which accept the commands are triggered,
+// the multi-branch Pipelines run against the branch of the pull request.
+func (h *chatOpsHandler) gitHub(request *restful.Request, response *restful.Response) {
+	body, ok := h.readAndVerify(request, response, chatops.SourceGitHub, func(secret string, body []byte) error {
+		return chatops.VerifyGitHub(request.Request.Header, body, secret)
+	})
+	if !ok {
+		return
+	}
+
+	event := &issueCommentEvent{}
+	if request.HeaderParameter("X-GitHub-Event") != "issue_comment" {
+		_, _ = response.Write([]byte("ignored event"))
+		return
+	} else if err := json.Unmarshal(body, event); err != nil {
+		_ = response.WriteError(http.StatusBadRequest, err)
+		return
+	} else if event.Action != "created" || event.Issue.PullRequest == nil {
+		_, _ = response.Write([]byte("ignored event"))
+		return
+	}
+
+	ctx := request.Request.Context()
+	pipelineList := &v1alpha3.PipelineList{}
+	if err := h.List(ctx, pipelineList); err != nil {
+		_ = response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	var messages []string
+	for _, command := range chatops.ParseAll(event.Comment.Body) {
+		command.Source = chatops.SourceGitHub
+		if message := h.resolveUser(ctx, command, strconv.FormatInt(event.Comment.User.ID, 10)); message != "" {
+			messages = append(messages, message)
+			continue
+		}
+		for i := range pipelineList.Items {
+			pipeline := &pipelineList.Items[i]
+			if !chatops.IsAllowed(pipeline, command.Name) || !matchRepository(pipeline, event) {
+				continue
+			}
+			branch := ""
+			if pipeline.IsMultiBranch() {
+				branch = fmt.Sprintf("PR-%d", event.Issue.Number)
+			}
+			messages = append(messages, h.trigger(ctx, pipeline, branch, command, command.Args))
+		}
+	}
+	if len(messages) == 0 {
+		_, _ = response.Write([]byte("no pipeline matched"))
+		return
+	}
+	_, _ = response.Write([]byte(strings.Join(messages, "\n")))
+}
+
+func matchRepository(pipeline *v1alpha3.Pipeline, event *issueCommentEvent) bool {
+	gitURL := pipeline.GetAnnotations()[scmAnnotationKey]
+	if pipeline.IsMultiBranch() {
+		gitURL = pipeline.Spec.MultiBranchPipeline.GetGitURL()
+	}
+	return gitURL != "" && gitRepoMatch(gitURL, event.Repository.HTMLURL, event.Repository.CloneURL, event.Repository.SSHURL)
+}
+
+// readAndVerify reads the body of the request and verifies its signature with the secret of the chat source
+func (h *chatOpsHandler) readAndVerify(request *restful.Request, response *restful.Response, source string,
+	verify func(secret string, body []byte) error) (body []byte, ok bool) {
+	var err error
+	if body, err = ioutil.ReadAll(request.Request.Body); err != nil {
+		_ = response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+	var secret string
+	if secret, err = chatops.GetSecret(request.Request.Context(), h, source); err != nil {
+		klog.V(4).Infof("failed to get the secret of %s, error: %v", source, err)
+		_ = response.WriteErrorString(http.StatusForbidden, fmt.Sprintf("ChatOps is not configured for %s", source))
+		return
+	}
+	if err = verify(secret, body); err != nil {
+		_ = response.WriteError(http.StatusUnauthorized, err)
+		return
+	}
+	ok = true
+	return
+}
+
+// resolveUser maps the sender of the command to a KubeSphere user by the immutable user ID of the chat source, it
+// returns the reply message if the sender is not mapped. The display names are never trusted, since they're chosen by
+// the users themselves.
+func (h *chatOpsHandler) resolveUser(ctx context.Context, command *chatops.Command, userID string) (message string) {
+	user, err := chatops.GetUser(ctx, h, command.Source, userID)
+	if err != nil {
+		klog.Errorf("failed to get the KubeSphere user of %s user %q, error: %v", command.Source, userID, err)
+	}
+	if user == "" {
+		return fmt.Sprintf("%s user %q is not mapped to any KubeSphere user", command.Source, userID)
+	}
+	command.User = user
+	return
+}
+
+// runCommand runs a command whose first argument is the Pipeline, like namespace/pipeline@branch
+func (h *chatOpsHandler) runCommand(ctx context.Context, command *chatops.Command) string {
+	if len(command.Args) == 0 {
+		return fmt.Sprintf("usage: /%s <namespace>/<pipeline>[@branch] [args...]", command.Name)
+	}
+	target, branch := command.Args[0], ""
+	if index := strings.Index(target, "@"); index > 0 {
+		target, branch = target[:index], target[index+1:]
+	}
+	namespaceAndName := strings.SplitN(target, "/", 2)
+	if len(namespaceAndName) != 2 {
+		return fmt.Sprintf("invalid Pipeline %q, expect <namespace>/<pipeline>", command.Args[0])
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.Get(ctx, client.ObjectKey{Namespace: namespaceAndName[0], Name: namespaceAndName[1]}, pipeline); err != nil {
+		return fmt.Sprintf("failed to get Pipeline %s, error: %v", target, client.IgnoreNotFound(err))
+	}
+	return h.trigger(ctx, pipeline, branch, command, command.Args[1:])
+}
+
+// trigger creates a PipelineRun if the command is allowed, returns the message which replies to the chat
+func (h *chatOpsHandler) trigger(ctx context.Context, pipeline *v1alpha3.Pipeline, branch string, command *chatops.Command, args []string) string {
+	pipelineKey := pipeline.Namespace + "/" + pipeline.Name
+	if !chatops.IsAllowed(pipeline, command.Name) {
+		return fmt.Sprintf("command /%s is not allowed for Pipeline %s", command.Name, pipelineKey)
+	}
+	if allowed, err := h.authorize(ctx, command.User, pipeline.Namespace); err != nil || !allowed {
+		return fmt.Sprintf("user %s is not allowed to run Pipeline %s", command.User, pipelineKey)
+	}
+	if pipeline.IsSuspended() {
+		return fmt.Sprintf("Pipeline %s is suspended", pipelineKey)
+	}
+
+	scm, err := pipelinerun.CreateScm(&pipeline.Spec, branch)
+	if err != nil {
+		return fmt.Sprintf("failed to run Pipeline %s, error: %v", pipelineKey, err)
+	}
+	run := pipelinerun.CreateBarePipelineRun(pipeline, chatops.GetParameters(pipeline, args), scm)
+	run.Annotations[triggerAnnotationKey] = "chatops"
+	run.Annotations[v1alpha3.PipelineRunCreatorAnnoKey] = command.User
+	run.Annotations[v1alpha3.PipelineRunChatOpsSourceAnnoKey] = command.Source
+	if command.ReplyURL != "" && chatops.IsTrustedReplyURL(command.Source, command.ReplyURL) {
+		run.Annotations[v1alpha3.PipelineRunChatOpsReplyAnnoKey] = command.ReplyURL
+	}
+	if err = h.Create(ctx, run); err != nil {
+		return fmt.Sprintf("failed to run Pipeline %s, error: %v", pipelineKey, err)
+	}
+	return fmt.Sprintf("PipelineRun %s/%s is triggered by /%s", run.Namespace, run.Name, command.Name)
+}
+
+// subjectAccessReview checks if the user is allowed to create PipelineRuns in the namespace
+func (h *chatOpsHandler) subjectAccessReview(ctx context.Context, user, namespace string) (bool, error) {
+	if user == "" {
+		return false, nil
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: user,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "create",
+				Group:     devops.GroupName,
+				Resource:  "pipelineruns",
+			},
+		},
+	}
+	if err := h.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/models/chatops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestChatOpsHandler(t *testing.T) {
+	utilruntime.Must(v1alpha3.AddToScheme(scheme.Scheme))
+	now := time.Now()
+
+	newPipeline := func(name string, multiBranch bool) *v1alpha3.Pipeline {
+		pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Annotations: map[string]string{
+				v1alpha3.PipelineChatOpsCommandsAnnoKey: "deploy,retest",
+				v1alpha3.PipelineChatOpsArgsAnnoKey:     "env",
+				scmAnnotationKey:                        "https://github.com/kubesphere/ks-devops",
+			},
+		}}
+		if multiBranch {
+			pipeline.Spec.Type = v1alpha3.MultiBranchPipelineType
+			pipeline.Spec.MultiBranchPipeline = &v1alpha3.MultiBranchPipeline{
+				SourceType: v1alpha3.SourceTypeGithub,
+				GitHubSource: &v1alpha3.GithubSource{
+					Owner: "kubesphere", Repo: "ks-devops",
+				},
+			}
+		}
+		return pipeline
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: chatops.DefaultSecretNamespace, Name: chatops.DefaultSecretName},
+		Data:       map[string][]byte{chatops.SourceSlack: []byte("slack"), chatops.SourceGitHub: []byte("github")},
+	}
+	// the users are mapped by the immutable IDs, the display names are ignored
+	slackUsers := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: chatops.DefaultSecretNamespace,
+			Name: chatops.GetUsersConfigMapName(chatops.SourceSlack)},
+		Data: map[string]string{"U-alice": "alice", "U-bob": "bob"},
+	}
+	gitHubUsers := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: chatops.DefaultSecretNamespace,
+			Name: chatops.GetUsersConfigMapName(chatops.SourceGitHub)},
+		Data: map[string]string{"1": "alice"},
+	}
+	sign := func(secret, message string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(message))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	setup := func(allowed bool, objects ...client.Object) (client.Client, func(uri, contentType string, body string, header map[string]string) *httptest.ResponseRecorder) {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+		handler := newChatOpsHandler(c)
+		handler.now = func() time.Time { return now }
+		handler.authorize = func(ctx context.Context, user, namespace string) (bool, error) {
+			return allowed && user == "alice", nil
+		}
+
+		container := restful.NewContainer()
+		ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+		ws.Route(ws.POST("/webhooks/chatops/slack").To(handler.slack).Consumes("application/x-www-form-urlencoded"))
+		ws.Route(ws.POST("/webhooks/chatops/github").To(handler.gitHub))
+		container.Add(ws)
+		return c, func(uri, contentType string, body string, header map[string]string) *httptest.ResponseRecorder {
+			httpRequest, _ := http.NewRequest(http.MethodPost, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, strings.NewReader(body))
+			httpRequest.Header.Set("Content-Type", contentType)
+			for k, v := range header {
+				httpRequest.Header.Set(k, v)
+			}
+			httpWriter := httptest.NewRecorder()
+			container.Dispatch(httpWriter, httpRequest)
+			return httpWriter
+		}
+	}
+	slackRequest := func(request func(string, string, string, map[string]string) *httptest.ResponseRecorder,
+		secret, user, text string) (int, string) {
+		body := url.Values{
+			"command":      {"/deploy"},
+			"text":         {text},
+			"user_id":      {"U-" + user},
+			"user_name":    {"alice"},
+			"response_url": {"https://hooks.slack.com/commands/1"},
+		}.Encode()
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		resp := request("/webhooks/chatops/slack", "application/x-www-form-urlencoded", body, map[string]string{
+			"X-Slack-Request-Timestamp": timestamp,
+			"X-Slack-Signature":         "v0=" + sign(secret, "v0:"+timestamp+":"+body),
+		})
+		reply := map[string]string{}
+		_ = json.Unmarshal(resp.Body.Bytes(), &reply)
+		return resp.Code, reply["text"]
+	}
+	listRuns := func(c client.Client) []v1alpha3.PipelineRun {
+		runList := &v1alpha3.PipelineRunList{}
+		assert.Nil(t, c.List(context.Background(), runList))
+		return runList.Items
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		_, request := setup(true, newPipeline("demo", false))
+		code, _ := slackRequest(request, "slack", "alice", "ns/demo staging")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		c, request := setup(true, secret, newPipeline("demo", false))
+		code, _ := slackRequest(request, "another", "alice", "ns/demo staging")
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Empty(t, listRuns(c))
+	})
+
+	t.Run("trigger from Slack", func(t *testing.T) {
+		c, request := setup(true, secret, slackUsers, newPipeline("demo", false))
+		code, text := slackRequest(request, "slack", "alice", "ns/demo staging")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, text, "is triggered by /deploy")
+
+		runs := listRuns(c)
+		if assert.Equal(t, 1, len(runs)) {
+			assert.Equal(t, []v1alpha3.Parameter{{Name: "env", Value: "staging"}}, runs[0].Spec.Parameters)
+			assert.Equal(t, "alice", runs[0].Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+			assert.Equal(t, "https://hooks.slack.com/commands/1", runs[0].Annotations[v1alpha3.PipelineRunChatOpsReplyAnnoKey])
+		}
+	})
+
+	t.Run("the user is not mapped", func(t *testing.T) {
+		c, request := setup(true, secret, newPipeline("demo", false))
+		code, text := slackRequest(request, "slack", "alice", "ns/demo staging")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, `slack user "U-alice" is not mapped to any KubeSphere user`, text)
+
+		// the display name cannot impersonate another user
+		_, text = slackRequest(request, "slack", "mallory", "ns/demo staging")
+		assert.Equal(t, `slack user "U-mallory" is not mapped to any KubeSphere user`, text)
+		assert.Empty(t, listRuns(c))
+	})
+
+	t.Run("the user is not allowed", func(t *testing.T) {
+		c, request := setup(true, secret, slackUsers, newPipeline("demo", false))
+		code, text := slackRequest(request, "slack", "bob", "ns/demo staging")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "user bob is not allowed to run Pipeline ns/demo", text)
+		assert.Empty(t, listRuns(c))
+	})
+
+	t.Run("the Pipeline is suspended", func(t *testing.T) {
+		pipeline := newPipeline("demo", false)
+		pipeline.Spec.Suspend = true
+		c, request := setup(true, secret, slackUsers, pipeline)
+		code, text := slackRequest(request, "slack", "alice", "ns/demo staging")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Pipeline ns/demo is suspended", text)
+		assert.Empty(t, listRuns(c))
+	})
+
+	t.Run("invalid Pipeline", func(t *testing.T) {
+		_, request := setup(true, secret, slackUsers)
+		_, text := slackRequest(request, "slack", "alice", "demo")
+		assert.Contains(t, text, "invalid Pipeline")
+		_, text = slackRequest(request, "slack", "alice", "")
+		assert.Equal(t, "usage: /deploy <namespace>/<pipeline>[@branch] [args...]", text)
+	})
+
+	t.Run("retest a pull request from GitHub", func(t *testing.T) {
+		c, request := setup(true, secret, gitHubUsers, newPipeline("demo", false), newPipeline("multi", true))
+		body := `{"action":"created","issue":{"number":12,"pull_request":{}},"comment":{"body":"/retest","user":{"id":1,"login":"bob"}},
+"repository":{"html_url":"https://github.com/kubesphere/ks-devops","clone_url":"https://github.com/kubesphere/ks-devops.git"}}`
+		resp := request("/webhooks/chatops/github", "application/json", body, map[string]string{
+			"X-GitHub-Event":      "issue_comment",
+			"X-Hub-Signature-256": "sha256=" + sign("github", body),
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		runs := listRuns(c)
+		if assert.Equal(t, 2, len(runs), resp.Body.String()) {
+			for _, run := range runs {
+				if run.Spec.SCM != nil {
+					assert.Equal(t, "PR-12", run.Spec.SCM.RefName)
+				}
+				assert.Equal(t, "alice", run.Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+			}
+		}
+	})
+
+	t.Run("the GitHub user is not mapped", func(t *testing.T) {
+		c, request := setup(true, secret, gitHubUsers, newPipeline("demo", false))
+		body := `{"action":"created","issue":{"number":12,"pull_request":{}},"comment":{"body":"/retest","user":{"id":2,"login":"alice"}},
+"repository":{"html_url":"https://github.com/kubesphere/ks-devops"}}`
+		resp := request("/webhooks/chatops/github", "application/json", body, map[string]string{
+			"X-GitHub-Event":      "issue_comment",
+			"X-Hub-Signature-256": "sha256=" + sign("github", body),
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `github user "2" is not mapped to any KubeSphere user`, resp.Body.String())
+		assert.Empty(t, listRuns(c))
+	})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/kapis"
+	"kubesphere.io/devops/pkg/models/webhook"
+)
+
+const (
+	// pathParameterNamespace is the path parameter of the namespace where the failed deliveries are stored
+	pathParameterNamespace = "namespace"
+	// pathParameterDelivery is the path parameter of the failed delivery ID
+	pathParameterDelivery = "delivery"
+)
+
+var errDeliveryNotFound = errors.New("the failed delivery is not found")
+
+// isDeadLetterNamespace returns true if the failed deliveries are stored in the namespace of the request,
+// there is no failed delivery in the other namespaces
+func (h *SCMHandler) isDeadLetterNamespace(request *restful.Request) bool {
+	return request.PathParameter(pathParameterNamespace) == h.deadLetters.Namespace
+}
+
+func (h *SCMHandler) listDeliveries(request *restful.Request, response *restful.Response) {
+	if !h.isDeadLetterNamespace(request) {
+		_ = response.WriteEntity(api.ListResult{Items: []interface{}{}})
+		return
+	}
+	deliveries, err := h.deadLetters.List(request.Request.Context())
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	items := make([]interface{}, len(deliveries))
+	for i := range deliveries {
+		items[i] = deliveries[i]
+	}
+	_ = response.WriteEntity(api.ListResult{Items: items, TotalItems: len(items)})
+}
+
+func (h *SCMHandler) getDelivery(request *restful.Request, response *restful.Response) {
+	if !h.isDeadLetterNamespace(request) {
+		kapis.HandleNotFound(response, request, errDeliveryNotFound)
+		return
+	}
+	delivery, err := h.deadLetters.Get(request.Request.Context(), request.PathParameter(pathParameterDelivery))
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(delivery)
+}
+
+func (h *SCMHandler) deleteDelivery(request *restful.Request, response *restful.Response) {
+	if !h.isDeadLetterNamespace(request) {
+		kapis.HandleNotFound(response, request, errDeliveryNotFound)
+		return
+	}
+	if err := h.deadLetters.Delete(request.Request.Context(), request.PathParameter(pathParameterDelivery)); err != nil {
+		kapis.HandleError(request, response, err)
+	}
+}
+
+// replayDelivery processes a failed delivery again. It will be removed from the dead-letter queue if it succeeds.
+func (h *SCMHandler) replayDelivery(request *restful.Request, response *restful.Response) {
+	if !h.isDeadLetterNamespace(request) {
+		kapis.HandleNotFound(response, request, errDeliveryNotFound)
+		return
+	}
+	ctx := request.Request.Context()
+	delivery, err := h.deadLetters.Get(ctx, request.PathParameter(pathParameterDelivery))
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+
+	if err = h.replay(ctx, delivery); err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteEntity(delivery)
+}
+
+func (h *SCMHandler) replay(ctx context.Context, delivery *webhook.Delivery) (err error) {
+	var failedPipelines []string
+	if _, failedPipelines, err = h.processSCMWebhook(delivery.Headers, []byte(delivery.Body), delivery.FailedPipelines); err == nil {
+		return h.deadLetters.Delete(ctx, delivery.ID)
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptTime = metav1.Now()
+	delivery.Error = err.Error()
+	if len(failedPipelines) > 0 {
+		delivery.FailedPipelines = failedPipelines
+	}
+	if updateErr := h.deadLetters.Update(ctx, delivery); updateErr != nil {
+		err = updateErr
+	}
+	return
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	apiserverruntime "kubesphere.io/devops/pkg/apiserver/runtime"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFailedDeliveries(t *testing.T) {
+	utilruntime.Must(v1alpha3.AddToScheme(scheme.Scheme))
+	pipeline := &v1alpha3.Pipeline{}
+	pipeline.SetName("fake")
+	pipeline.SetNamespace("default")
+	pipeline.SetAnnotations(map[string]string{
+		scmRefAnnotationKey: `["master"]`,
+		scmAnnotationKey:    "https://gitlab.com/linuxsuren/another",
+	})
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, pipeline)
+	deliveriesPath := "/namespaces/" + webhook.DefaultDeadLetterNamespace + "/webhookdeliveries"
+
+	container := restful.NewContainer()
+	ws := apiserverruntime.NewWebService(v1alpha3.GroupVersion)
+	RegisterWebhooks(fakeClient, ws, &token.FakeIssuer{}, core.JenkinsCore{})
+	container.Add(ws)
+
+	request := func(method, uri string, body io.Reader, header map[string]string) *httptest.ResponseRecorder {
+		httpRequest, _ := http.NewRequest(method, "http://fake.com/kapis/devops.kubesphere.io/v1alpha3"+uri, body)
+		httpRequest.Header.Set("Content-Type", "application/json")
+		for k, v := range header {
+			httpRequest.Header.Set(k, v)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	listDeliveries := func() (deliveries []webhook.Delivery) {
+		resp := request(http.MethodGet, deliveriesPath, nil, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		result := &struct {
+			Items []webhook.Delivery `json:"items"`
+		}{}
+		assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), result))
+		return result.Items
+	}
+
+	// the delivery which is not an event of any SCM will be ignored
+	resp := request(http.MethodPost, "/webhooks/scm", strings.NewReader("{}"), nil)
+	assert.Equal(t, "unknown SCM type", resp.Body.String())
+	assert.Empty(t, listDeliveries())
+
+	// the Pipeline has a different git URL, so the delivery will fail, but it's not stored without the secret
+	resp = request(http.MethodPost, "/webhooks/scm", strings.NewReader(gitlabWebhookBody), map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "secret",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Empty(t, listDeliveries())
+
+	assert.Nil(t, fakeClient.Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: webhook.DefaultDeadLetterNamespace, Name: webhook.DefaultSecretName},
+		Data:       map[string][]byte{webhook.SecretKey: []byte("secret")},
+	}))
+
+	// the delivery with an invalid token is rejected, and not stored
+	resp = request(http.MethodPost, "/webhooks/scm", strings.NewReader(gitlabWebhookBody), map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "fake",
+	})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Empty(t, listDeliveries())
+
+	// the verified delivery is stored
+	resp = request(http.MethodPost, "/webhooks/scm", strings.NewReader(gitlabWebhookBody), map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "secret",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	deliveries := listDeliveries()
+	if !assert.Equal(t, 1, len(deliveries)) {
+		return
+	}
+	delivery := deliveries[0]
+	assert.Equal(t, []string{"default/fake"}, delivery.FailedPipelines)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, gitlabWebhookBody, delivery.Body)
+	assert.Equal(t, "Push Hook", delivery.Headers.Get("X-Gitlab-Event"))
+	assert.Empty(t, delivery.Headers.Get("X-Gitlab-Token"))
+
+	// the failed deliveries are not in the other namespaces
+	resp = request(http.MethodGet, "/namespaces/default/webhookdeliveries", nil, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), delivery.ID)
+	resp = request(http.MethodGet, "/namespaces/default/webhookdeliveries/"+delivery.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = request(http.MethodPost, "/namespaces/default/webhookdeliveries/"+delivery.ID+"/replay", nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = request(http.MethodDelete, "/namespaces/default/webhookdeliveries/"+delivery.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// replay it without fixing the problem
+	resp = request(http.MethodPost, deliveriesPath+"/"+delivery.ID+"/replay", nil, nil)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	resp = request(http.MethodGet, deliveriesPath+"/"+delivery.ID, nil, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &delivery))
+	assert.Equal(t, 2, delivery.Attempts)
+
+	// replay it after fixing the git URL
+	pipeline = &v1alpha3.Pipeline{}
+	assert.Nil(t, fakeClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "fake"}, pipeline))
+	pipeline.Annotations[scmAnnotationKey] = "https://gitlab.com/linuxsuren/test"
+	assert.Nil(t, fakeClient.Update(context.TODO(), pipeline))
+	resp = request(http.MethodPost, deliveriesPath+"/"+delivery.ID+"/replay", nil, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	pipelineRuns := &v1alpha3.PipelineRunList{}
+	assert.Nil(t, fakeClient.List(context.TODO(), pipelineRuns))
+	assert.Equal(t, 1, len(pipelineRuns.Items))
+	assert.Empty(t, listDeliveries())
+
+	resp = request(http.MethodGet, deliveriesPath+"/"+delivery.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = request(http.MethodDelete, deliveriesPath+"/"+delivery.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// delete a failed delivery directly
+	pipeline.Annotations[scmAnnotationKey] = "https://gitlab.com/linuxsuren/another"
+	assert.Nil(t, fakeClient.Update(context.TODO(), pipeline))
+	resp = request(http.MethodPost, "/webhooks/scm", strings.NewReader(gitlabWebhookBody), map[string]string{
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "secret",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	deliveries = listDeliveries()
+	if assert.Equal(t, 1, len(deliveries)) {
+		resp = request(http.MethodDelete, deliveriesPath+"/"+deliveries[0].ID, nil, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, listDeliveries())
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/emicklei/go-restful"
 	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/models/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -37,4 +38,49 @@ func RegisterWebhooks(genericClient client.Client, ws *restful.WebService, issue
 	scmHandler := NewSCMHandler(genericClient, issue, jenkins)
 	ws.Route(ws.POST("/webhooks/scm").
 		To(scmHandler.scmWebhook))
+
+	// the failed deliveries are stored in a namespace of the system, so the requests of them are authorized in it
+	ws.Route(ws.GET("/namespaces/{namespace}/webhookdeliveries").
+		To(scmHandler.listDeliveries).
+		Param(ws.PathParameter(pathParameterNamespace, "The namespace where the failed deliveries are stored")).
+		Doc("List the SCM webhook deliveries which failed to be processed, the latest one comes first").
+		Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{webhook.Delivery{}}}))
+
+	ws.Route(ws.GET("/namespaces/{namespace}/webhookdeliveries/{delivery}").
+		To(scmHandler.getDelivery).
+		Param(ws.PathParameter(pathParameterNamespace, "The namespace where the failed deliveries are stored")).
+		Param(ws.PathParameter(pathParameterDelivery, "The ID of the failed delivery")).
+		Doc("Get a failed SCM webhook delivery").
+		Returns(http.StatusOK, api.StatusOK, webhook.Delivery{}))
+
+	ws.Route(ws.POST("/namespaces/{namespace}/webhookdeliveries/{delivery}/replay").
+		To(scmHandler.replayDelivery).
+		Param(ws.PathParameter(pathParameterNamespace, "The namespace where the failed deliveries are stored")).
+		Param(ws.PathParameter(pathParameterDelivery, "The ID of the failed delivery")).
+		Doc("Replay a failed SCM webhook delivery, it will be removed if it succeeds").
+		Returns(http.StatusOK, api.StatusOK, webhook.Delivery{}))
+
+	ws.Route(ws.DELETE("/namespaces/{namespace}/webhookdeliveries/{delivery}").
+		To(scmHandler.deleteDelivery).
+		Param(ws.PathParameter(pathParameterNamespace, "The namespace where the failed deliveries are stored")).
+		Param(ws.PathParameter(pathParameterDelivery, "The ID of the failed delivery")).
+		Doc("Delete a failed SCM webhook delivery").
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	chatOpsHandler := newChatOpsHandler(genericClient)
+	ws.Route(ws.POST("/webhooks/chatops/slack").
+		To(chatOpsHandler.slack).
+		Consumes("application/x-www-form-urlencoded").
+		Doc("Receive the slash commands from Slack, such as /deploy namespace/pipeline staging").
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	ws.Route(ws.POST("/webhooks/chatops/dingtalk").
+		To(chatOpsHandler.dingTalk).
+		Doc("Receive the commands from the outgoing robot of DingTalk").
+		Returns(http.StatusOK, api.StatusOK, nil))
+
+	ws.Route(ws.POST("/webhooks/chatops/github").
+		To(chatOpsHandler.gitHub).
+		Doc("Receive the commands in the comments of GitHub pull requests, such as /retest").
+		Returns(http.StatusOK, api.StatusOK, nil))
 }
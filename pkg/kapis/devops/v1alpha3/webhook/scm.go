@@ -17,8 +17,10 @@ limitations under the License.
 package webhook
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/emicklei/go-restful"
 	"github.com/jenkins-x/go-scm/scm"
@@ -27,12 +29,22 @@ import (
 	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog/v2"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/git"
 	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/kapis"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/models/webhook"
+	"kubesphere.io/devops/pkg/utils/pathutil"
+	"kubesphere.io/devops/pkg/utils/sliceutil"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
@@ -43,22 +55,58 @@ import (
 const tokenExpireIn time.Duration = 5 * time.Minute
 const scmAnnotationKey = "scm.devops.kubesphere.io"
 const scmRefAnnotationKey = "scm.devops.kubesphere.io/ref"
-const triggerAnnotationKey = "devops.kubesphere.io/trigger"
+const triggerAnnotationKey = v1alpha3.PipelineRunTriggerAnnoKey
+
+// maxPushCommits is the maximum number of commits carried by the payload of a push event, GitHub and GitLab drop
+// the rest of them
+const maxPushCommits = 20
+
+// changesPageSize and maxChangesPages limit the requests of listing the changes
+const (
+	changesPageSize = 100
+	maxChangesPages = 30
+)
 
 // SCMHandler handles requests from webhooks.
 type SCMHandler struct {
 	client.Client
 	issue   token.Issuer
 	jenkins core.JenkinsCore
+
+	deadLetters *webhook.DeadLetterQueue
+	// scmClientFactory creates the SCM client to query the changed files of a webhook event
+	scmClientFactory func(pipeline *v1alpha3.Pipeline, driver scm.Driver, repo scm.Repository) (*scm.Client, error)
 }
 
 // NewSCMHandler creates a new handler for handling webhooks.
 func NewSCMHandler(genericClient client.Client, issue token.Issuer, jenkins core.JenkinsCore) *SCMHandler {
-	return &SCMHandler{
+	handler := &SCMHandler{
 		Client:  genericClient,
 		issue:   issue,
 		jenkins: jenkins,
+		deadLetters: &webhook.DeadLetterQueue{
+			Client:    genericClient,
+			Namespace: webhook.DefaultDeadLetterNamespace,
+			MaxSize:   webhook.DefaultDeadLetterMaxSize,
+		},
 	}
+	handler.scmClientFactory = handler.newSCMClient
+	return handler
+}
+
+var errUnknownSCM = errors.New("unknown SCM type")
+
+// deliveryIDHeaders are the headers of the unique ID of a webhook delivery, the redelivery keeps the same ID
+var deliveryIDHeaders = []string{"X-GitHub-Delivery", "X-Gitlab-Event-UUID", "X-Request-UUID", "X-Request-Id"}
+
+// getDeliveryID returns the unique ID of the webhook delivery, it's empty if the SCM doesn't provide it
+func getDeliveryID(header http.Header) string {
+	for _, key := range deliveryIDHeaders {
+		if id := header.Get(key); id != "" {
+			return id
+		}
+	}
+	return ""
 }
 
 func getSCMClient(request *http.Request) *scm.Client {
@@ -77,70 +125,294 @@ func getSCMClient(request *http.Request) *scm.Client {
 }
 
 func (h *SCMHandler) scmWebhook(request *restful.Request, response *restful.Response) {
-	scmClient := getSCMClient(request.Request)
+	var body []byte
+	if request.Request.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(request.Request.Body); err != nil {
+			_ = response.WriteError(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	secret, err := webhook.GetSecret(request.Request.Context(), h)
+	if err != nil {
+		kapis.HandleError(request, response, err)
+		return
+	}
+	if err = verifySignature(request.Request.Header, body, secret); err != nil {
+		_ = response.WriteError(http.StatusUnauthorized, err)
+		return
+	}
+
+	found, failedPipelines, err := h.processSCMWebhook(request.Request.Header, body, nil)
+	switch {
+	case err == errUnknownSCM || scm.IsUnknownWebhook(err):
+		// it's not an event which we care about, no need to store it
+		_, _ = response.Write([]byte(err.Error()))
+	case err != nil:
+		// store the failed delivery, then it could be replayed after the problem is solved. The deliveries which
+		// are not verified by the secret are never stored, in case the anonymous requests flood the queue.
+		if secret != "" {
+			delivery := webhook.NewDelivery(request.Request.Header, body, failedPipelines, err)
+			if addErr := h.deadLetters.Add(context.TODO(), delivery); addErr != nil {
+				klog.Errorf("failed to store the failed webhook delivery, error: %v", addErr)
+			}
+		} else {
+			klog.V(4).Infof("the failed webhook delivery is not stored because the secret is not configured, error: %v", err)
+		}
+		if found {
+			_ = response.WriteError(http.StatusBadRequest, err)
+		} else {
+			_, _ = response.Write([]byte(err.Error()))
+		}
+	case !found:
+		_ = response.WriteErrorString(http.StatusOK, "no pipeline matched")
+	default:
+		_, _ = response.Write([]byte("ok"))
+	}
+}
+
+// verifySignature verifies the signature (or token) of the webhook delivery by the shared secret, the deliveries are
+// not verified if the secret is empty
+func verifySignature(header http.Header, body []byte, secret string) (err error) {
+	if secret == "" {
+		return
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	request.Header = header.Clone()
+	scmClient := getSCMClient(request)
+	if scmClient == nil {
+		return
+	}
+	if _, err = scmClient.Webhooks.Parse(request, func(webhook scm.Webhook) (string, error) {
+		return secret, nil
+	}); err != scm.ErrSignatureInvalid {
+		// the other errors are handled when processing the delivery
+		err = nil
+	}
+	return
+}
+
+// processSCMWebhook triggers the Pipelines which match the webhook.
+// Only the given target Pipelines (in the format of namespace/name) will be triggered if they are not empty.
+// It returns the Pipelines which failed to be triggered.
+func (h *SCMHandler) processSCMWebhook(header http.Header, body []byte, targets []string) (found bool, failedPipelines []string, err error) {
+	request, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	request.Header = header.Clone()
+	scmClient := getSCMClient(request)
 	if scmClient == nil {
-		_, _ = response.Write([]byte("unknown SCM type"))
+		err = errUnknownSCM
 		return
 	}
 
-	webhook, err := scmClient.Webhooks.Parse(request.Request, func(webhook scm.Webhook) (string, error) {
+	var hook scm.Webhook
+	if hook, err = scmClient.Webhooks.Parse(request, func(webhook scm.Webhook) (string, error) {
 		return "", nil
-	})
-	if err != nil {
-		_, _ = response.Write([]byte(err.Error()))
+	}); err != nil {
+		return
+	}
+
+	// only the multi-branch Pipelines are scanned when a pull request changes
+	var ref string
+	pushHook, isPush := hook.(*scm.PushHook)
+	pullRequestHook, isPullRequest := hook.(*scm.PullRequestHook)
+	switch {
+	case isPush:
+		ref = pushHook.Ref
+		if pushHook.Before == "" {
+			// some drivers (e.g. GitLab) drop the previous commit which is needed for comparing the changes
+			payload := struct {
+				Before string `json:"before"`
+			}{}
+			if json.Unmarshal(body, &payload) == nil {
+				pushHook.Before = payload.Before
+			}
+		}
+	case isPullRequest && isPullRequestChanged(pullRequestHook.Action):
+		ref = pullRequestHook.PullRequest.Target
+	default:
 		return
 	}
 
 	ctx := context.TODO()
-	found := false
-	if webhook.Kind() == scm.WebhookKindPush {
-		repo := webhook.Repository()
-		pushHook := webhook.(*scm.PushHook)
-
-		pipelineList := &v1alpha3.PipelineList{}
-		if err = h.List(ctx, pipelineList); err == nil {
-			for i := range pipelineList.Items {
-				pipeline := pipelineList.Items[i]
-				if !branchMatch(pipeline, pushHook.Ref) {
-					continue
+	repo := hook.Repository()
+
+	pipelineList := &v1alpha3.PipelineList{}
+	if err = h.List(ctx, pipelineList); err != nil {
+		return
+	}
+	var errs []error
+	for i := range pipelineList.Items {
+		pipeline := pipelineList.Items[i]
+		pipelineKey := pipeline.Namespace + "/" + pipeline.Name
+		if len(targets) > 0 && !sliceutil.HasString(targets, pipelineKey) {
+			continue
+		}
+		if !branchMatch(pipeline, ref) || (isPullRequest && !pipeline.IsMultiBranch()) {
+			continue
+		}
+		found = true
+		if pipeline.IsSuspended() {
+			klog.V(4).Infof("ignore the webhook event because Pipeline %s is suspended", pipelineKey)
+			continue
+		}
+
+		var triggerErr error
+		var changed bool
+		gitURL := pipeline.GetAnnotations()[scmAnnotationKey]
+		if pipeline.IsMultiBranch() {
+			gitURL = pipeline.Spec.MultiBranchPipeline.GetGitURL()
+			if gitURL != "" && gitRepoMatch(gitURL, repo.Link, repo.Clone, repo.CloneSSH) {
+				if changed, triggerErr = h.pathsChanged(ctx, &pipeline, scmClient.Driver, hook); changed {
+					triggerErr = scanJenkinsMultiBranchPipeline(pipeline, h.jenkins, h.issue)
 				}
-				found = true
-
-				gitURL := pipeline.GetAnnotations()[scmAnnotationKey]
-				if pipeline.IsMultiBranch() {
-					gitURL = pipeline.Spec.MultiBranchPipeline.GetGitURL()
-					if gitURL != "" && gitRepoMatch(gitURL, repo.Link, repo.Clone, repo.CloneSSH) {
-						err = scanJenkinsMultiBranchPipeline(pipeline, h.jenkins, h.issue)
-					}
-				} else if gitURL != "" {
-					if gitRepoMatch(gitURL, repo.Link, repo.Clone, repo.CloneSSH) {
-						err = h.createPipelineRun(pipeline, pushHook)
-					} else {
-						err = fmt.Errorf("expect URL: %s, got: %v", gitURL, []string{repo.Link, repo.Clone, repo.CloneSSH})
-					}
+			}
+		} else if gitURL != "" {
+			if gitRepoMatch(gitURL, repo.Link, repo.Clone, repo.CloneSSH) {
+				if changed, triggerErr = h.pathsChanged(ctx, &pipeline, scmClient.Driver, hook); changed {
+					triggerErr = h.createPipelineRun(pipeline, pushHook, getDeliveryID(header))
 				}
+			} else {
+				triggerErr = fmt.Errorf("expect URL: %s, got: %v", gitURL, []string{repo.Link, repo.Clone, repo.CloneSSH})
 			}
 		}
+		if triggerErr != nil {
+			failedPipelines = append(failedPipelines, pipelineKey)
+			errs = append(errs, triggerErr)
+		}
 	}
+	err = utilerrors.NewAggregate(errs)
+	return
+}
 
-	if !found {
-		_ = response.WriteErrorString(http.StatusOK, "no pipeline matched")
+// pathsChanged returns true if the webhook event changes any file watched by the Pipeline.
+// It's always true if the Pipeline has no path filter.
+func (h *SCMHandler) pathsChanged(ctx context.Context, pipeline *v1alpha3.Pipeline, driver scm.Driver, hook scm.Webhook) (changed bool, err error) {
+	filter := pipeline.Spec.GetPathFilter()
+	if filter == nil || (len(filter.Include) == 0 && len(filter.Exclude) == 0) {
+		changed = true
+		return
+	}
+
+	var files []string
+	if files, err = h.changedFiles(ctx, pipeline, driver, hook); err != nil {
+		err = fmt.Errorf("failed to get the changed files of Pipeline %s/%s, error: %v", pipeline.Namespace, pipeline.Name, err)
 		return
-	} else if err != nil {
-		_ = response.WriteError(http.StatusBadRequest, err)
-	} else {
-		_, _ = response.Write([]byte("ok"))
 	}
+	for _, file := range files {
+		if (len(filter.Include) == 0 || pathutil.MatchAny(filter.Include, file)) && !pathutil.MatchAny(filter.Exclude, file) {
+			changed = true
+			break
+		}
+	}
+	return
 }
 
-func (h *SCMHandler) createPipelineRun(pipeline v1alpha3.Pipeline, hook *scm.PushHook) (err error) {
+// changedFiles returns the files changed by a push or pull request event. The files of a push event are taken from
+// its commits, the SCM API is requested only if they are absent or might be truncated from the payload.
+func (h *SCMHandler) changedFiles(ctx context.Context, pipeline *v1alpha3.Pipeline, driver scm.Driver, hook scm.Webhook) (files []string, err error) {
+	pushHook, isPush := hook.(*scm.PushHook)
+	if isPush {
+		if files = pushedFiles(pushHook); len(files) > 0 {
+			return
+		}
+	}
+
+	repo := hook.Repository()
+	var scmClient *scm.Client
+	if scmClient, err = h.scmClientFactory(pipeline, driver, repo); err != nil {
+		return
+	}
+
+	var changes []*scm.Change
+	switch {
+	case isPush && (pushHook.Before == "" || strings.Trim(pushHook.Before, "0") == ""):
+		// it's a new branch, there is no commit to compare with
+		changes, err = listAllChanges(func(opts *scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+			return scmClient.Git.ListChanges(ctx, repo.FullName, pushHook.After, opts)
+		})
+	case isPush:
+		changes, err = listAllChanges(func(opts *scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+			return scmClient.Git.CompareCommits(ctx, repo.FullName, pushHook.Before, pushHook.After, opts)
+		})
+	default:
+		number := hook.(*scm.PullRequestHook).PullRequest.Number
+		changes, err = listAllChanges(func(opts *scm.ListOptions) ([]*scm.Change, *scm.Response, error) {
+			return scmClient.PullRequests.ListChanges(ctx, repo.FullName, number, opts)
+		})
+	}
+	for _, change := range changes {
+		files = append(files, change.Path)
+		if change.Renamed && change.PreviousPath != "" {
+			files = append(files, change.PreviousPath)
+		}
+	}
+	return
+}
+
+// pushedFiles returns the files in the commits of a push event, or nil if the commits might be truncated
+func pushedFiles(hook *scm.PushHook) (files []string) {
+	if len(hook.Commits) >= maxPushCommits {
+		return
+	}
+	for _, commit := range hook.Commits {
+		files = append(files, commit.Added...)
+		files = append(files, commit.Modified...)
+		files = append(files, commit.Removed...)
+	}
+	return
+}
+
+func listAllChanges(list func(opts *scm.ListOptions) ([]*scm.Change, *scm.Response, error)) (changes []*scm.Change, err error) {
+	for page := 1; page <= maxChangesPages; page++ {
+		var items []*scm.Change
+		var res *scm.Response
+		if items, res, err = list(&scm.ListOptions{Page: page, Size: changesPageSize}); err != nil {
+			return
+		}
+		changes = append(changes, items...)
+		if len(items) < changesPageSize || (res != nil && res.Page.Next == 0) {
+			break
+		}
+	}
+	return
+}
+
+// newSCMClient creates an SCM client with the credential of the Pipeline
+func (h *SCMHandler) newSCMClient(pipeline *v1alpha3.Pipeline, driver scm.Driver, repo scm.Repository) (*scm.Client, error) {
+	var secretRef *v1.SecretReference
+	if pipeline.IsMultiBranch() {
+		if credentialID := pipeline.Spec.MultiBranchPipeline.GetCredentialID(); credentialID != "" {
+			secretRef = &v1.SecretReference{Namespace: pipeline.Namespace, Name: credentialID}
+		}
+	}
+
+	clientFactory := git.NewClientFactory(driver.String(), secretRef, h.Client)
+	// the default server is used for github.com, or it's a self-hosted server
+	if link, err := url.Parse(repo.Link); err == nil && link.Host != "" && link.Host != "github.com" {
+		clientFactory.Server = fmt.Sprintf("%s://%s", link.Scheme, link.Host)
+	}
+	return clientFactory.GetClient()
+}
+
+// isPullRequestChanged returns true if the code of the pull request changed
+func isPullRequestChanged(action scm.Action) bool {
+	return action == scm.ActionOpen || action == scm.ActionReopen || action == scm.ActionSync
+}
+
+// createPipelineRun creates a PipelineRun for the push event. The duplicate deliveries of the event, including the
+// replayed ones, create only one PipelineRun of a Pipeline.
+func (h *SCMHandler) createPipelineRun(pipeline v1alpha3.Pipeline, hook *scm.PushHook, deliveryID string) (err error) {
 	branch := strings.TrimPrefix(hook.Ref, "refs/heads/")
 
 	var scmObj *v1alpha3.SCM
 	if scmObj, err = pipelinerun.CreateScm(&pipeline.Spec, branch); err == nil {
 		run := pipelinerun.CreatePipelineRun(&pipeline, &devops.RunPayload{}, scmObj)
 		run.Annotations[triggerAnnotationKey] = "webhook"
-		err = h.Create(context.Background(), run)
+		if deliveryID != "" {
+			pipelinerun.SetIdempotencyKey(run, "webhook-"+deliveryID)
+		}
+		_, _, err = pipelinerun.CreateIdempotently(context.Background(), h.Client, run)
 	}
 	return
 }
@@ -17,14 +17,23 @@ limitations under the License.
 package webhook
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/bitbucket"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
 	"github.com/jenkins-x/go-scm/scm/driver/github"
 	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/jwt/token"
 	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
 
@@ -134,3 +143,205 @@ func Test_branchMatch(t *testing.T) {
 		})
 	}
 }
+
+func Test_pushedFiles(t *testing.T) {
+	hook := &scm.PushHook{Commits: []scm.PushCommit{{
+		Added:    []string{"services/api/main.go"},
+		Modified: []string{"README.md"},
+	}, {
+		Removed: []string{"services/web/index.html"},
+	}}}
+	assert.Equal(t, []string{"services/api/main.go", "README.md", "services/web/index.html"}, pushedFiles(hook))
+
+	// the commits might be truncated
+	hook.Commits = make([]scm.PushCommit, maxPushCommits)
+	hook.Commits[0].Added = []string{"README.md"}
+	assert.Nil(t, pushedFiles(hook))
+}
+
+func TestSCMHandler_pathsChanged(t *testing.T) {
+	withPathFilter := func(filter *v1alpha3.PathFilter) *v1alpha3.Pipeline {
+		pipeline := &v1alpha3.Pipeline{}
+		pipeline.SetName("fake")
+		pipeline.SetNamespace("default")
+		if filter != nil {
+			pipeline.Spec.Triggers = &v1alpha3.PipelineTriggers{Paths: filter}
+		}
+		return pipeline
+	}
+	pushHook := &scm.PushHook{Commits: []scm.PushCommit{{
+		Added:    []string{"services/api/main.go"},
+		Modified: []string{"docs/README.md"},
+	}}}
+
+	scmClient, data := fakescm.NewDefault()
+	data.PullRequestChanges[1] = []*scm.Change{{Path: "services/web/index.html"}}
+	pullRequestHook := &scm.PullRequestHook{PullRequest: scm.PullRequest{Number: 1}}
+
+	tests := []struct {
+		name        string
+		pipeline    *v1alpha3.Pipeline
+		hook        scm.Webhook
+		clientErr   error
+		wantChanged bool
+		wantErr     bool
+	}{{
+		name:        "no path filter",
+		pipeline:    withPathFilter(nil),
+		hook:        pushHook,
+		clientErr:   errors.New("should not request the SCM"),
+		wantChanged: true,
+	}, {
+		name:        "empty path filter",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{}),
+		hook:        pushHook,
+		wantChanged: true,
+	}, {
+		name:        "include the changed files",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Include: []string{"services/api/**"}}),
+		hook:        pushHook,
+		clientErr:   errors.New("should not request the SCM"),
+		wantChanged: true,
+	}, {
+		name:        "exclude the changed files",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Exclude: []string{"services/**", "**/*.md"}}),
+		hook:        pushHook,
+		wantChanged: false,
+	}, {
+		name:        "exclude part of the changed files",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Exclude: []string{"**/*.md"}}),
+		hook:        pushHook,
+		wantChanged: true,
+	}, {
+		name:        "not include the changed files",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Include: []string{"services/web/**"}}),
+		hook:        pushHook,
+		wantChanged: false,
+	}, {
+		name:        "pull request changes the watched files",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Include: []string{"services/web/**"}}),
+		hook:        pullRequestHook,
+		wantChanged: true,
+	}, {
+		name:        "failed to create the SCM client",
+		pipeline:    withPathFilter(&v1alpha3.PathFilter{Include: []string{"services/web/**"}}),
+		hook:        pullRequestHook,
+		clientErr:   errors.New("fake"),
+		wantChanged: false,
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSCMHandler(fake.NewFakeClientWithScheme(scheme.Scheme), &token.FakeIssuer{}, core.JenkinsCore{})
+			h.scmClientFactory = func(*v1alpha3.Pipeline, scm.Driver, scm.Repository) (*scm.Client, error) {
+				return scmClient, tt.clientErr
+			}
+
+			changed, err := h.pathsChanged(context.TODO(), tt.pipeline, scm.DriverFake, tt.hook)
+			assert.Equal(t, tt.wantChanged, changed)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSCMHandler_processSCMWebhookWithPathFilter(t *testing.T) {
+	// the GitLab push hook has no changed files, they are taken from the comparison API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/projects/linuxsuren/test/repository/compare", r.URL.Path)
+		assert.Equal(t, "8f4b347e7d6b7647b51647dcd07ddafd4bded19f", r.URL.Query().Get("from"))
+		_, _ = fmt.Fprint(w, `{"diffs": [{"old_path": "Jenkinsfile", "new_path": "Jenkinsfile", "new_file": true}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		filter   *v1alpha3.PathFilter
+		suspend  bool
+		wantRuns int
+	}{{
+		name:     "changes the watched paths",
+		filter:   &v1alpha3.PathFilter{Include: []string{"Jenkinsfile", "services/**"}},
+		wantRuns: 1,
+	}, {
+		name:     "does not change the watched paths",
+		filter:   &v1alpha3.PathFilter{Include: []string{"services/**"}},
+		wantRuns: 0,
+	}, {
+		name:     "the Pipeline is suspended",
+		suspend:  true,
+		wantRuns: 0,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &v1alpha3.Pipeline{}
+			pipeline.SetName("fake")
+			pipeline.SetNamespace("default")
+			pipeline.SetAnnotations(map[string]string{
+				scmAnnotationKey: "https://gitlab.com/linuxsuren/test",
+			})
+			pipeline.Spec.Triggers = &v1alpha3.PipelineTriggers{Paths: tt.filter}
+			pipeline.Spec.Suspend = tt.suspend
+			assert.Nil(t, v1alpha3.AddToScheme(scheme.Scheme))
+			fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, pipeline)
+
+			h := NewSCMHandler(fakeClient, &token.FakeIssuer{}, core.JenkinsCore{})
+			h.scmClientFactory = func(*v1alpha3.Pipeline, scm.Driver, scm.Repository) (*scm.Client, error) {
+				return gitlab.New(server.URL)
+			}
+
+			found, failedPipelines, err := h.processSCMWebhook(http.Header{"X-Gitlab-Event": []string{"Push Hook"}},
+				[]byte(gitlabWebhookBody), nil)
+			assert.True(t, found)
+			assert.Empty(t, failedPipelines)
+			assert.NoError(t, err)
+
+			pipelineruns := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, fakeClient.List(context.Background(), pipelineruns))
+			assert.Equal(t, tt.wantRuns, len(pipelineruns.Items))
+		})
+	}
+}
+
+func TestSCMHandler_processSCMWebhookDuplicateDeliveries(t *testing.T) {
+	pipeline := &v1alpha3.Pipeline{}
+	pipeline.SetName("fake")
+	pipeline.SetNamespace("default")
+	pipeline.SetAnnotations(map[string]string{
+		scmAnnotationKey: "https://gitlab.com/linuxsuren/test",
+	})
+	assert.Nil(t, v1alpha3.AddToScheme(scheme.Scheme))
+	fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme, pipeline)
+	h := NewSCMHandler(fakeClient, &token.FakeIssuer{}, core.JenkinsCore{})
+
+	deliver := func(deliveryID string) {
+		header := http.Header{"X-Gitlab-Event": []string{"Push Hook"}}
+		if deliveryID != "" {
+			header.Set("X-Gitlab-Event-UUID", deliveryID)
+		}
+		found, failedPipelines, err := h.processSCMWebhook(header, []byte(gitlabWebhookBody), nil)
+		assert.True(t, found)
+		assert.Empty(t, failedPipelines)
+		assert.NoError(t, err)
+	}
+	countRuns := func() int {
+		pipelineruns := &v1alpha3.PipelineRunList{}
+		assert.Nil(t, fakeClient.List(context.Background(), pipelineruns))
+		return len(pipelineruns.Items)
+	}
+
+	// the redelivery of the same event is ignored
+	deliver("uuid-1")
+	deliver("uuid-1")
+	assert.Equal(t, 1, countRuns())
+	// another event
+	deliver("uuid-2")
+	assert.Equal(t, 2, countRuns())
+	// the deliveries without ID are not deduplicated
+	deliver("")
+	deliver("")
+	assert.Equal(t, 4, countRuns())
+}
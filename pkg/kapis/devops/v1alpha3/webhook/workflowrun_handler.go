@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -91,8 +92,27 @@ func extractPipelineRunIdentifier(workflowRunData *workflowrun.Data) *pipelineRu
 	return identifier
 }
 
-func (handler *Handler) handleWorkflowRunInitialize(workflowRunData *workflowrun.Data) error {
+// getPipelineRunIdentifier extracts the identifier of the Jenkins run, the Jenkins folder in it is replaced with the
+// namespace of the DevOps project.
+func (handler *Handler) getPipelineRunIdentifier(workflowRunData *workflowrun.Data) (*pipelineRunIdentifier, error) {
 	identifier := extractPipelineRunIdentifier(workflowRunData)
+	if identifier == nil {
+		return nil, nil
+	}
+	namespace, err := jenkins.NewFolderResolver(handler.Client).NamespaceOf(context.Background(),
+		identifier.namespaceName)
+	if err != nil {
+		return nil, err
+	}
+	identifier.namespaceName = namespace
+	return identifier, nil
+}
+
+func (handler *Handler) handleWorkflowRunInitialize(workflowRunData *workflowrun.Data) error {
+	identifier, err := handler.getPipelineRunIdentifier(workflowRunData)
+	if err != nil {
+		return err
+	}
 	if identifier == nil {
 		// we should skip this event if the Pipeline is not a standard Pipeline in ks-devops.
 		return nil
@@ -125,6 +145,60 @@ func (handler *Handler) handleWorkflowRunInitialize(workflowRunData *workflowrun
 	return nil
 }
 
+// handleWorkflowRunChanged notifies the PipelineRun controller that the state of a Jenkins run was changed by
+// annotating the corresponding PipelineRun, then the controller could reconcile it without waiting for next polling.
+func (handler *Handler) handleWorkflowRunChanged(eventType string) workflowrun.Handler {
+	return func(workflowRunData *workflowrun.Data) error {
+		identifier, err := handler.getPipelineRunIdentifier(workflowRunData)
+		if err != nil {
+			return err
+		}
+		if identifier == nil {
+			// we should skip this event if the Pipeline is not a standard Pipeline in ks-devops.
+			return nil
+		}
+
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pipelineRun, err := handler.findPipelineRun(identifier)
+			if err != nil || pipelineRun == nil {
+				return err
+			}
+			if pipelineRun.Annotations[v1alpha3.JenkinsPipelineRunEventAnnoKey] == eventType {
+				return nil
+			}
+			if pipelineRun.Annotations == nil {
+				pipelineRun.Annotations = map[string]string{}
+			}
+			pipelineRun.Annotations[v1alpha3.JenkinsPipelineRunEventAnnoKey] = eventType
+			return handler.Update(context.Background(), pipelineRun)
+		})
+	}
+}
+
+// findPipelineRun finds the PipelineRun which corresponds to the Jenkins run, it returns nil if not found.
+func (handler *Handler) findPipelineRun(id *pipelineRunIdentifier) (*v1alpha3.PipelineRun, error) {
+	pipelineRunList := &v1alpha3.PipelineRunList{}
+	if err := handler.List(context.Background(), pipelineRunList, client.InNamespace(id.namespaceName),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: id.pipelineName}); err != nil {
+		return nil, err
+	}
+	for i := range pipelineRunList.Items {
+		pipelineRun := &pipelineRunList.Items[i]
+		runID, _ := pipelineRun.GetPipelineRunID()
+		if runID != id.buildNumber {
+			continue
+		}
+		var refName string
+		if pipelineRun.Spec.SCM != nil {
+			refName = pipelineRun.Spec.SCM.RefName
+		}
+		if refName == id.scmRefName {
+			return pipelineRun, nil
+		}
+	}
+	return nil, nil
+}
+
 func (handler *Handler) retryCheckPipelineRunList(id *pipelineRunIdentifier) error {
 	return retry.OnError(retry.DefaultRetry, func(err error) bool {
 		return true
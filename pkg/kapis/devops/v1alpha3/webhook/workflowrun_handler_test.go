@@ -291,3 +291,98 @@ func TestHandler_handleWorkflowRunInitialize(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_handleWorkflowRunChanged(t *testing.T) {
+	createPipelineRun := func(name, pipeline, runID, refName string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: "fake-namespace",
+				Labels: map[string]string{
+					v1alpha3.PipelineNameLabelKey: pipeline,
+				},
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunIDAnnoKey: runID,
+				},
+			},
+		}
+		if refName != "" {
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: refName}
+		}
+		return pr
+	}
+	tests := []struct {
+		name            string
+		workflowRunData *workflowrun.Data
+		initObjs        []runtime.Object
+		wantEvents      map[string]string
+	}{{
+		name:            "Should annotate the PipelineRun with the same run ID",
+		workflowRunData: createWorkflowRun("fake-namespace", "fake-pipeline", "2", false),
+		initObjs: []runtime.Object{
+			createPipelineRun("run-1", "fake-pipeline", "1", ""),
+			createPipelineRun("run-2", "fake-pipeline", "2", ""),
+			createPipelineRun("other-run-2", "other-pipeline", "2", ""),
+		},
+		wantEvents: map[string]string{
+			"run-1":       "",
+			"run-2":       "run.started",
+			"other-run-2": "",
+		},
+	}, {
+		name:            "Should annotate the PipelineRun of the same branch",
+		workflowRunData: createWorkflowRun("fake-namespace/fake-pipeline", "main", "1", true),
+		initObjs: []runtime.Object{
+			createPipelineRun("dev-1", "fake-pipeline", "1", "dev"),
+			createPipelineRun("main-1", "fake-pipeline", "1", "main"),
+		},
+		wantEvents: map[string]string{
+			"dev-1":  "",
+			"main-1": "run.started",
+		},
+	}, {
+		name:            "Should annotate the PipelineRun in the namespace of the Jenkins folder",
+		workflowRunData: createWorkflowRun("host-fake-namespace", "fake-pipeline", "1", false),
+		initObjs: []runtime.Object{
+			&v1alpha3.DevOpsProject{
+				ObjectMeta: v1.ObjectMeta{Name: "fake-namespace", Annotations: map[string]string{
+					v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-fake-namespace",
+				}},
+				Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "fake-namespace"},
+			},
+			createPipelineRun("run-1", "fake-pipeline", "1", ""),
+		},
+		wantEvents: map[string]string{
+			"run-1": "run.started",
+		},
+	}, {
+		name:            "Should do nothing if the PipelineRun not found",
+		workflowRunData: createWorkflowRun("fake-namespace", "fake-pipeline", "3", false),
+		initObjs: []runtime.Object{
+			createPipelineRun("run-1", "fake-pipeline", "1", ""),
+		},
+		wantEvents: map[string]string{
+			"run-1": "",
+		},
+	}, {
+		name:            "Should do nothing if WorkflowRunData is invalid",
+		workflowRunData: createWorkflowRun("", "", "", false),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = v1alpha3.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tt.initObjs...).Build()
+			handler := &Handler{Client: fakeClient}
+
+			err := handler.handleWorkflowRunChanged("run.started")(tt.workflowRunData)
+			assert.Nil(t, err)
+			for name, wantEvent := range tt.wantEvents {
+				pipelineRun := &v1alpha3.PipelineRun{}
+				err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "fake-namespace", Name: name}, pipelineRun)
+				assert.Nil(t, err)
+				assert.Equal(t, wantEvent, pipelineRun.Annotations[v1alpha3.JenkinsPipelineRunEventAnnoKey], name)
+			}
+		})
+	}
+}
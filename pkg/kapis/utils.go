@@ -65,6 +65,11 @@ func HandleConflict(response *restful.Response, req *restful.Request, err error)
 	handle(http.StatusConflict, req, response, err)
 }
 
+// statusCoder is an error which carries its HTTP status code, such as the errors of the DevOps client
+type statusCoder interface {
+	StatusCode() int
+}
+
 // HandleError detects proper status code, then write it and log error.
 func HandleError(request *restful.Request, response *restful.Response, err error) {
 	var statusCode int
@@ -73,6 +78,8 @@ func HandleError(request *restful.Request, response *restful.Response, err error
 		statusCode = int(t.Status().Code)
 	case restful.ServiceError:
 		statusCode = t.Code
+	case statusCoder:
+		statusCode = t.StatusCode()
 	default:
 		statusCode = http.StatusInternalServerError
 	}
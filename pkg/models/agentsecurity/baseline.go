@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsecurity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapKeyBaseline is the key of the security baseline in the ConfigMap
+	ConfigMapKeyBaseline = "baseline.yaml"
+
+	// ExemptVerb is the verb of the RBAC rule which allows exempting a Pipeline from the security baseline
+	ExemptVerb = "exempt"
+	// ExemptSubresource is the subresource of Pipelines in the RBAC rule, like pipelines/agentsecurity
+	ExemptSubresource = "agentsecurity"
+)
+
+// Baseline is the security settings which are enforced on the Jenkins agent pods. The fields which are set in the
+// baseline override the ones of the pods, the others are kept.
+type Baseline struct {
+	// Pod is merged into the security context of the pods
+	Pod *v1.PodSecurityContext `json:"pod,omitempty"`
+	// Container is merged into the security context of each container and init container
+	Container *v1.SecurityContext `json:"container,omitempty"`
+}
+
+// ParseBaseline parses a baseline in YAML or JSON
+func ParseBaseline(data []byte) (baseline *Baseline, err error) {
+	baseline = &Baseline{}
+	if err = yaml.UnmarshalStrict(data, baseline); err != nil {
+		return nil, fmt.Errorf("invalid agent security baseline: %v", err)
+	}
+	return
+}
+
+// IsEmpty checks if the baseline does not have any settings
+func (b *Baseline) IsEmpty() bool {
+	return b == nil || b.Pod == nil && b.Container == nil
+}
+
+// Apply merges the baseline into the security contexts of the pod and its containers
+func (b *Baseline) Apply(spec *v1.PodSpec) (err error) {
+	if b.Pod != nil {
+		if spec.SecurityContext == nil {
+			spec.SecurityContext = &v1.PodSecurityContext{}
+		}
+		if err = merge(spec.SecurityContext, b.Pod); err != nil {
+			return
+		}
+	}
+	if b.Container == nil {
+		return
+	}
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil {
+				containers[i].SecurityContext = &v1.SecurityContext{}
+			}
+			if err = merge(containers[i].SecurityContext, b.Container); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// merge overrides the fields of the target with the ones which are set in the source, the nested objects are merged
+// recursively. Both of them must be pointers of the same type.
+func merge(target, source interface{}) (err error) {
+	var targetMap, sourceMap map[string]interface{}
+	if targetMap, err = toMap(target); err != nil {
+		return
+	}
+	if sourceMap, err = toMap(source); err != nil {
+		return
+	}
+	mergeMap(targetMap, sourceMap)
+
+	var data []byte
+	if data, err = json.Marshal(targetMap); err == nil {
+		err = json.Unmarshal(data, target)
+	}
+	return
+}
+
+func toMap(obj interface{}) (result map[string]interface{}, err error) {
+	var data []byte
+	if data, err = json.Marshal(obj); err == nil {
+		err = json.Unmarshal(data, &result)
+	}
+	return
+}
+
+func mergeMap(target, source map[string]interface{}) {
+	for key, value := range source {
+		sourceChild, isMap := value.(map[string]interface{})
+		targetChild, targetIsMap := target[key].(map[string]interface{})
+		if isMap && targetIsMap {
+			mergeMap(targetChild, sourceChild)
+			continue
+		}
+		target[key] = value
+	}
+}
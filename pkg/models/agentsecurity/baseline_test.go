@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsecurity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestParseBaseline(t *testing.T) {
+	baseline, err := ParseBaseline([]byte(`
+pod:
+  runAsNonRoot: true
+  seccompProfile:
+    type: RuntimeDefault
+container:
+  readOnlyRootFilesystem: true
+  allowPrivilegeEscalation: false
+  capabilities:
+    drop: [ALL]
+`))
+	assert.Nil(t, err)
+	assert.False(t, baseline.IsEmpty())
+	assert.Equal(t, v1.SeccompProfileTypeRuntimeDefault, baseline.Pod.SeccompProfile.Type)
+	assert.Equal(t, []v1.Capability{"ALL"}, baseline.Container.Capabilities.Drop)
+
+	baseline, err = ParseBaseline(nil)
+	assert.Nil(t, err)
+	assert.True(t, baseline.IsEmpty())
+	assert.True(t, (*Baseline)(nil).IsEmpty())
+
+	_, err = ParseBaseline([]byte(`pod: {runAsRoot: true}`))
+	assert.NotNil(t, err)
+}
+
+func TestBaseline_Apply(t *testing.T) {
+	baseline := &Baseline{
+		Pod: &v1.PodSecurityContext{
+			RunAsNonRoot:   pointer.Bool(true),
+			SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		},
+		Container: &v1.SecurityContext{
+			ReadOnlyRootFilesystem: pointer.Bool(true),
+			Capabilities:           &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+		},
+	}
+	spec := &v1.PodSpec{
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot: pointer.Bool(false),
+			RunAsUser:    pointer.Int64(1000),
+		},
+		InitContainers: []v1.Container{{Name: "init"}},
+		Containers: []v1.Container{{
+			Name: "maven",
+			SecurityContext: &v1.SecurityContext{
+				ReadOnlyRootFilesystem: pointer.Bool(false),
+				Capabilities:           &v1.Capabilities{Add: []v1.Capability{"NET_ADMIN"}},
+			},
+		}},
+	}
+	assert.Nil(t, baseline.Apply(spec))
+
+	assert.Equal(t, &v1.PodSecurityContext{
+		RunAsNonRoot:   pointer.Bool(true),
+		RunAsUser:      pointer.Int64(1000),
+		SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+	}, spec.SecurityContext)
+	assert.Equal(t, baseline.Container, spec.InitContainers[0].SecurityContext)
+	assert.Equal(t, &v1.SecurityContext{
+		ReadOnlyRootFilesystem: pointer.Bool(true),
+		Capabilities:           &v1.Capabilities{Add: []v1.Capability{"NET_ADMIN"}, Drop: []v1.Capability{"ALL"}},
+	}, spec.Containers[0].SecurityContext)
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/smtp"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Actions of the links in the approval emails
+const (
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+// Path is the path of the approval links, it's public since the tokens are verified by the handler
+const Path = "/kapis/devops.kubesphere.io/v1alpha3/webhooks/approvals"
+
+const (
+	pipelineRunClaim = "pipelinerun"
+	nodeClaim        = "node"
+	stepClaim        = "step"
+	inputClaim       = "input"
+	actionClaim      = "action"
+	nonceClaim       = "nonce"
+)
+
+// Approver is a user who receives the approval emails
+type Approver struct {
+	Name  string
+	Email string
+}
+
+// UserGVK is the GroupVersionKind of the users of KubeSphere, their emails are managed by the administrators
+var UserGVK = schema.GroupVersionKind{
+	Group:   "iam.kubesphere.io",
+	Version: "v1alpha2",
+	Kind:    "User",
+}
+
+// GetApproverNames parses the names of approvers from the annotation of a Pipeline, such as alice,bob.
+// The emails in the legacy format alice=alice@example.com are ignored.
+func GetApproverNames(pipeline *v1alpha3.Pipeline) (names []string) {
+	for _, item := range strings.Split(pipeline.GetAnnotations()[v1alpha3.PipelineApproversAnnoKey], ",") {
+		if name := strings.TrimSpace(strings.SplitN(item, "=", 2)[0]); name != "" {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// GetApprovers returns the approvers of a Pipeline with the emails of their KubeSphere users. The emails are never
+// taken from the Pipeline, since its editors could send the approval links to anyone. The users which are not found
+// or have no email are ignored.
+func GetApprovers(ctx context.Context, c client.Reader, pipeline *v1alpha3.Pipeline) (approvers []Approver, err error) {
+	for _, name := range GetApproverNames(pipeline) {
+		user := &unstructured.Unstructured{}
+		user.SetGroupVersionKind(UserGVK)
+		if err = c.Get(ctx, client.ObjectKey{Name: name}, user); err != nil {
+			if apierrors.IsNotFound(err) {
+				err = nil
+				continue
+			}
+			return
+		}
+		if email, _, _ := unstructured.NestedString(user.Object, "spec", "email"); strings.Contains(email, "@") {
+			approvers = append(approvers, Approver{Name: name, Email: email})
+		}
+	}
+	return
+}
+
+// GetRecipients returns the approvers who are allowed to submit the input, all of them are allowed if the input has no
+// submitter
+func GetRecipients(approvers []Approver, input *job.Input) (recipients []Approver) {
+	submitters := (&devops.Input{Submitter: input.Submitter}).GetSubmitters()
+	if len(submitters) == 0 {
+		return approvers
+	}
+	for _, approver := range approvers {
+		for _, submitter := range submitters {
+			if approver.Name == submitter {
+				recipients = append(recipients, approver)
+				break
+			}
+		}
+	}
+	return
+}
+
+// PausedStep is an input step which is waiting for approval
+type PausedStep struct {
+	NodeID   string
+	NodeName string
+	StepID   string
+	Input    *job.Input
+}
+
+// Key returns the key of the step in the nonces of PipelineRun
+func (s *PausedStep) Key() string {
+	return s.NodeID + "/" + s.StepID
+}
+
+// GetPausedSteps returns the input steps which are waiting for approval
+func GetPausedSteps(stages []pipelinerun.NodeDetail) (steps []PausedStep) {
+	for i := range stages {
+		for j := range stages[i].Steps {
+			step := &stages[i].Steps[j]
+			if step.State == "PAUSED" && step.Input != nil {
+				steps = append(steps, PausedStep{
+					NodeID:   stages[i].ID,
+					NodeName: stages[i].DisplayName,
+					StepID:   step.ID,
+					Input:    step.Input,
+				})
+			}
+		}
+	}
+	return
+}
+
+// Request is what an approval token approves or rejects on behalf of the approver
+type Request struct {
+	Namespace   string
+	PipelineRun string
+	NodeID      string
+	StepID      string
+	InputID     string
+	Action      string
+	Approver    string
+	Nonce       string
+}
+
+// Issue issues a token of the request, it's valid until it expires or the nonce is consumed
+func Issue(issuer token.Issuer, request *Request, expiresIn time.Duration) (string, error) {
+	return issuer.IssueTo(&user.DefaultInfo{
+		Name: request.Approver,
+		Extra: map[string][]string{
+			pipelineRunClaim: {request.Namespace + "/" + request.PipelineRun},
+			nodeClaim:        {request.NodeID},
+			stepClaim:        {request.StepID},
+			inputClaim:       {request.InputID},
+			actionClaim:      {request.Action},
+			nonceClaim:       {request.Nonce},
+		},
+	}, token.ApprovalToken, expiresIn)
+}
+
+// Parse verifies the signature and expiration of an approval token, then returns its request
+func Parse(issuer token.Issuer, tokenString string) (request *Request, err error) {
+	var info user.Info
+	var tokenType token.TokenType
+	if info, tokenType, err = issuer.Verify(tokenString); err != nil {
+		return
+	}
+	if tokenType != token.ApprovalToken {
+		err = fmt.Errorf("not an approval token")
+		return
+	}
+	extra := info.GetExtra()
+	claim := func(key string) string {
+		if len(extra[key]) != 1 {
+			return ""
+		}
+		return extra[key][0]
+	}
+	request = &Request{
+		NodeID:   claim(nodeClaim),
+		StepID:   claim(stepClaim),
+		InputID:  claim(inputClaim),
+		Action:   claim(actionClaim),
+		Approver: info.GetName(),
+		Nonce:    claim(nonceClaim),
+	}
+	namespaceAndName := strings.SplitN(claim(pipelineRunClaim), "/", 2)
+	if len(namespaceAndName) == 2 {
+		request.Namespace, request.PipelineRun = namespaceAndName[0], namespaceAndName[1]
+	}
+	if request.PipelineRun == "" || request.StepID == "" || request.Nonce == "" ||
+		request.Action != ActionApprove && request.Action != ActionReject {
+		request, err = nil, fmt.Errorf("invalid approval token")
+	}
+	return
+}
+
+// NewNonce returns a random nonce for the approval tokens of an input step
+func NewNonce() string {
+	return utilrand.String(16)
+}
+
+// GetNonces returns the nonces of the input steps whose approval emails were sent, the keys are PausedStep.Key
+func GetNonces(pr *v1alpha3.PipelineRun) (nonces map[string]string) {
+	nonces = map[string]string{}
+	if value := pr.GetAnnotations()[v1alpha3.PipelineRunApprovalNoncesAnnoKey]; value != "" {
+		_ = json.Unmarshal([]byte(value), &nonces)
+	}
+	return
+}
+
+// SetNonces sets the nonces of the input steps, the annotation is removed if there is no nonce
+func SetNonces(pr *v1alpha3.PipelineRun, nonces map[string]string) {
+	if len(nonces) == 0 {
+		delete(pr.Annotations, v1alpha3.PipelineRunApprovalNoncesAnnoKey)
+		return
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	value, _ := json.Marshal(nonces)
+	pr.Annotations[v1alpha3.PipelineRunApprovalNoncesAnnoKey] = string(value)
+}
+
+// Consume removes the nonce of the request from the PipelineRun, so that none of the links of the input step could be
+// used again. It fails if the nonce does not match, or the PipelineRun was changed by others at the same time.
+func Consume(ctx context.Context, c client.Client, request *Request) (pr *v1alpha3.PipelineRun, err error) {
+	pr = &v1alpha3.PipelineRun{}
+	if err = c.Get(ctx, client.ObjectKey{Namespace: request.Namespace, Name: request.PipelineRun}, pr); err != nil {
+		return
+	}
+	nonces := GetNonces(pr)
+	key := (&PausedStep{NodeID: request.NodeID, StepID: request.StepID}).Key()
+	if subtle.ConstantTimeCompare([]byte(nonces[key]), []byte(request.Nonce)) != 1 || pr.HasCompleted() {
+		err = fmt.Errorf("the link has been used or the input step is not waiting for approval anymore")
+		return
+	}
+	delete(nonces, key)
+	SetNonces(pr, nonces)
+	err = c.Update(ctx, pr)
+	return
+}
+
+// Submit proceeds or aborts the input step in Jenkins
+func Submit(devopsClient devops.Interface, pr *v1alpha3.PipelineRun, request *Request) (err error) {
+	runID, ok := pr.GetPipelineRunID()
+	if !ok {
+		return fmt.Errorf("PipelineRun %s/%s has not started", pr.Namespace, pr.Name)
+	}
+	payload := &devops.CheckPlayload{ID: request.InputID, Abort: request.Action == ActionReject}
+	if !payload.Abort {
+		payload.Parameters = []devops.CheckPlayloadParameters{}
+	}
+	var body []byte
+	if body, err = json.Marshal(payload); err != nil {
+		return
+	}
+	params := &devops.HttpParameters{
+		Method: http.MethodPost,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   ioutil.NopCloser(bytes.NewReader(body)),
+		Url:    &url.URL{},
+	}
+	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	if pr.Spec.IsMultiBranchPipeline() && pr.Spec.SCM != nil {
+		_, err = devopsClient.SubmitBranchInputStep(pr.Namespace, pipelineName, pr.Spec.SCM.RefName, runID,
+			request.NodeID, request.StepID, params)
+	} else {
+		_, err = devopsClient.SubmitInputStep(pr.Namespace, pipelineName, runID, request.NodeID, request.StepID, params)
+	}
+	return
+}
+
+// GetLink returns the link of an approval token which points to the external URL of the apiserver
+func GetLink(externalURL, tokenString string) string {
+	return strings.TrimSuffix(externalURL, "/") + Path + "?" + url.Values{"token": []string{tokenString}}.Encode()
+}
+
+var messageTemplate = template.Must(template.New("approval").Parse(`<p>PipelineRun <b>{{.Namespace}}/{{.Name}}</b> is waiting for approval in stage <b>{{.Stage}}</b>.</p>
+<p>{{.Message}}</p>
+<p><a href="{{.ApproveLink}}">{{.Ok}}</a> | <a href="{{.RejectLink}}">Abort</a></p>
+<p>The links are for {{.Approver}} only, and expire at {{.Expiration}}. They cannot be used once the step is approved or aborted.</p>
+`))
+
+// NewMessage returns the approval email of an input step for the approver
+func NewMessage(pr *v1alpha3.PipelineRun, step *PausedStep, approver Approver,
+	approveLink, rejectLink string, expiration time.Time) (*smtp.Message, error) {
+	ok := step.Input.Ok
+	if ok == "" {
+		ok = "Proceed"
+	}
+	buf := &bytes.Buffer{}
+	if err := messageTemplate.Execute(buf, map[string]string{
+		"Namespace":   pr.Namespace,
+		"Name":        pr.Name,
+		"Stage":       step.NodeName,
+		"Message":     step.Input.Message,
+		"Ok":          ok,
+		"ApproveLink": approveLink,
+		"RejectLink":  rejectLink,
+		"Approver":    approver.Name,
+		"Expiration":  expiration.UTC().Format(time.RFC1123),
+	}); err != nil {
+		return nil, err
+	}
+	return &smtp.Message{
+		To:      []string{approver.Email},
+		Subject: fmt.Sprintf("[Approval required] PipelineRun %s/%s", pr.Namespace, pr.Name),
+		HTML:    buf.String(),
+	}, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newUser(name, email string) *unstructured.Unstructured {
+	user := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"email": email},
+	}}
+	user.SetGroupVersionKind(UserGVK)
+	user.SetName(name)
+	return user
+}
+
+func TestGetRecipients(t *testing.T) {
+	// the emails in the annotation are ignored, carol has no email, and dave is not a user
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		v1alpha3.PipelineApproversAnnoKey: "alice=attacker@example.com, bob ,carol,dave,=erin@example.com",
+	}}}
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"}, GetApproverNames(pipeline))
+
+	c := fake.NewClientBuilder().WithObjects(newUser("alice", "alice@example.com"), newUser("bob", "bob@example.com"),
+		newUser("carol", "")).Build()
+	approvers, err := GetApprovers(context.TODO(), c, pipeline)
+	assert.Nil(t, err)
+	assert.Equal(t, []Approver{{Name: "alice", Email: "alice@example.com"}, {Name: "bob", Email: "bob@example.com"}}, approvers)
+
+	assert.Equal(t, approvers, GetRecipients(approvers, &job.Input{}))
+	assert.Equal(t, []Approver{{Name: "bob", Email: "bob@example.com"}}, GetRecipients(approvers, &job.Input{Submitter: "bob, carol"}))
+	assert.Empty(t, GetRecipients(approvers, &job.Input{Submitter: "carol"}))
+}
+
+func TestGetPausedSteps(t *testing.T) {
+	input := &job.Input{ID: "Deploy"}
+	stages := []pipelinerun.NodeDetail{{
+		Node:  job.Node{ID: "10", DisplayName: "build", State: "FINISHED"},
+		Steps: []pipelinerun.Step{{Step: job.Step{ID: "11", State: "FINISHED"}}},
+	}, {
+		Node: job.Node{ID: "20", DisplayName: "deploy", State: "PAUSED"},
+		Steps: []pipelinerun.Step{
+			{Step: job.Step{ID: "21", State: "FINISHED"}},
+			{Step: job.Step{ID: "22", State: "PAUSED", Input: input}},
+		},
+	}}
+	steps := GetPausedSteps(stages)
+	assert.Equal(t, []PausedStep{{NodeID: "20", NodeName: "deploy", StepID: "22", Input: input}}, steps)
+	assert.Equal(t, "20/22", steps[0].Key())
+}
+
+func TestIssueAndParse(t *testing.T) {
+	issuer := token.NewTokenIssuer("secret", time.Second)
+	request := &Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "20", StepID: "22", InputID: "Deploy",
+		Action: ActionReject, Approver: "alice", Nonce: "nonce"}
+	tokenString, err := Issue(issuer, request, time.Hour)
+	assert.Nil(t, err)
+	parsed, err := Parse(issuer, tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, request, parsed)
+
+	// signed by another secret
+	_, err = Parse(token.NewTokenIssuer("another", time.Second), tokenString)
+	assert.NotNil(t, err)
+
+	// not an approval token
+	accessToken, err := issuer.IssueTo(&user.DefaultInfo{Name: "alice"}, token.AccessToken, time.Hour)
+	assert.Nil(t, err)
+	_, err = Parse(issuer, accessToken)
+	assert.NotNil(t, err)
+
+	// unknown action
+	invalid := *request
+	invalid.Action = "delete"
+	tokenString, err = Issue(issuer, &invalid, time.Hour)
+	assert.Nil(t, err)
+	_, err = Parse(issuer, tokenString)
+	assert.NotNil(t, err)
+}
+
+func TestConsumeAndSubmit(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc",
+		Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "demo"},
+		Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}}}
+	SetNonces(pr, map[string]string{"20/22": "nonce", "30/31": "another"})
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pr).Build()
+
+	request := &Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "20", StepID: "22", Action: ActionApprove, Nonce: "nonce"}
+	consumed, err := Consume(context.Background(), c, request)
+	assert.Nil(t, err)
+	assert.Nil(t, Submit(fakedevops.NewFakeDevops(nil), consumed, request))
+
+	updated := &v1alpha3.PipelineRun{}
+	assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(pr), updated))
+	assert.Equal(t, map[string]string{"30/31": "another"}, GetNonces(updated))
+
+	// the links of the step cannot be used again
+	_, err = Consume(context.Background(), c, request)
+	assert.NotNil(t, err)
+	_, err = Consume(context.Background(), c, &Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "30", StepID: "31", Nonce: "forged"})
+	assert.NotNil(t, err)
+
+	// not started
+	assert.NotNil(t, Submit(fakedevops.NewFakeDevops(nil), &v1alpha3.PipelineRun{}, request))
+}
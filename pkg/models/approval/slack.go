@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/slack"
+	"kubesphere.io/devops/pkg/models/chatops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SlackUsersConfigMapName is the name of ConfigMap in the namespace of ChatOps secrets which maps the Slack users to
+// the KubeSphere users, the keys are the IDs of the Slack users, such as U0123456789
+const SlackUsersConfigMapName = "devops-slack-users"
+
+// SlackMessage is a posted message which asks to approve an input step
+type SlackMessage struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// GetSlackChannel returns the Slack channel which the approval messages of a Pipeline are posted to
+func GetSlackChannel(pipeline *v1alpha3.Pipeline) string {
+	return strings.TrimSpace(pipeline.GetAnnotations()[v1alpha3.PipelineSlackApprovalChannelAnnoKey])
+}
+
+// GetSlackMessages returns the approval messages of the input steps, the keys are PausedStep.Key
+func GetSlackMessages(pr *v1alpha3.PipelineRun) (messages map[string]SlackMessage) {
+	messages = map[string]SlackMessage{}
+	if value := pr.GetAnnotations()[v1alpha3.PipelineRunSlackApprovalsAnnoKey]; value != "" {
+		_ = json.Unmarshal([]byte(value), &messages)
+	}
+	return
+}
+
+// SetSlackMessages sets the approval messages of the input steps, the annotation is removed if there is no message
+func SetSlackMessages(pr *v1alpha3.PipelineRun, messages map[string]SlackMessage) {
+	if len(messages) == 0 {
+		delete(pr.Annotations, v1alpha3.PipelineRunSlackApprovalsAnnoKey)
+		return
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	value, _ := json.Marshal(messages)
+	pr.Annotations[v1alpha3.PipelineRunSlackApprovalsAnnoKey] = string(value)
+}
+
+// slackActionValue is the value of the buttons, it identifies the input step
+type slackActionValue struct {
+	Namespace   string `json:"namespace"`
+	PipelineRun string `json:"pipelinerun"`
+	NodeID      string `json:"node"`
+	StepID      string `json:"step"`
+	InputID     string `json:"input"`
+}
+
+// NewSlackMessage returns the message with the buttons which approve or reject the input step
+func NewSlackMessage(pr *v1alpha3.PipelineRun, step *PausedStep, channel string) *slack.Message {
+	ok := step.Input.Ok
+	if ok == "" {
+		ok = "Proceed"
+	}
+	value, _ := json.Marshal(&slackActionValue{
+		Namespace:   pr.Namespace,
+		PipelineRun: pr.Name,
+		NodeID:      step.NodeID,
+		StepID:      step.StepID,
+		InputID:     step.Input.ID,
+	})
+	text := fmt.Sprintf("PipelineRun *%s/%s* is waiting for approval in stage *%s*.", pr.Namespace, pr.Name,
+		escapeSlack(step.NodeName))
+	if step.Input.Message != "" {
+		text += "\n" + escapeSlack(step.Input.Message)
+	}
+	button := func(action, text, style string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":      "button",
+			"action_id": action,
+			"text":      map[string]string{"type": "plain_text", "text": text},
+			"style":     style,
+			"value":     string(value),
+		}
+	}
+	return &slack.Message{
+		Channel: channel,
+		Text:    text,
+		Blocks: []interface{}{
+			newSlackSection(text),
+			map[string]interface{}{
+				"type": "actions",
+				"elements": []interface{}{
+					button(ActionApprove, ok, "primary"),
+					button(ActionReject, "Abort", "danger"),
+				},
+			},
+		},
+	}
+}
+
+// NewSlackResult returns the message which replaces an approval message, the buttons are removed
+func NewSlackResult(message SlackMessage, text string) *slack.Message {
+	return &slack.Message{
+		Channel: message.Channel,
+		TS:      message.TS,
+		Text:    text,
+		Blocks:  []interface{}{newSlackSection(text)},
+	}
+}
+
+func newSlackSection(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": text},
+	}
+}
+
+// escapeSlack escapes the control characters of the Slack markup
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// SlackAction is a click on the buttons of an approval message, the approver of the request is unknown until the
+// Slack user is mapped to a KubeSphere user
+type SlackAction struct {
+	Request
+	UserID  string
+	Message SlackMessage
+}
+
+// slackPayload is the payload of the block_actions interactions
+type slackPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Container struct {
+		ChannelID string `json:"channel_id"`
+		MessageTS string `json:"message_ts"`
+	} `json:"container"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// ParseSlackAction parses the payload of an interaction, it returns nil if the payload is not a click on the buttons
+// of an approval message
+func ParseSlackAction(payload []byte) (action *SlackAction, err error) {
+	data := &slackPayload{}
+	if err = json.Unmarshal(payload, data); err != nil || data.Type != "block_actions" || len(data.Actions) != 1 {
+		return
+	}
+	actionID := data.Actions[0].ActionID
+	if actionID != ActionApprove && actionID != ActionReject {
+		return
+	}
+	value := &slackActionValue{}
+	if err = json.Unmarshal([]byte(data.Actions[0].Value), value); err != nil {
+		return
+	}
+	if value.PipelineRun == "" || value.StepID == "" || data.User.ID == "" {
+		err = fmt.Errorf("invalid approval action")
+		return
+	}
+	action = &SlackAction{
+		Request: Request{
+			Namespace:   value.Namespace,
+			PipelineRun: value.PipelineRun,
+			NodeID:      value.NodeID,
+			StepID:      value.StepID,
+			InputID:     value.InputID,
+			Action:      actionID,
+		},
+		UserID:  data.User.ID,
+		Message: SlackMessage{Channel: data.Container.ChannelID, TS: data.Container.MessageTS},
+	}
+	return
+}
+
+// GetSlackUser returns the KubeSphere user which the Slack user is mapped to, it's empty if the Slack user is not mapped
+func GetSlackUser(ctx context.Context, c client.Reader, slackUserID string) (user string, err error) {
+	return chatops.GetUser(ctx, c, chatops.SourceSlack, slackUserID)
+}
+
+// IsSubmitter returns true if the user is allowed to submit the input, everyone is allowed if the input has no submitter
+func IsSubmitter(input *job.Input, user string) bool {
+	devopsInput := &devops.Input{Submitter: input.Submitter}
+	return len(devopsInput.GetSubmitters()) == 0 || devopsInput.Approvable(user)
+}
+
+// ConsumeSlackMessage removes the approval message of the action from the PipelineRun, so that the buttons of the
+// message could not be used again. It fails if the message does not match, or the PipelineRun was changed by others
+// at the same time.
+func ConsumeSlackMessage(ctx context.Context, c client.Client, action *SlackAction) (pr *v1alpha3.PipelineRun, err error) {
+	pr = &v1alpha3.PipelineRun{}
+	if err = c.Get(ctx, client.ObjectKey{Namespace: action.Namespace, Name: action.PipelineRun}, pr); err != nil {
+		return
+	}
+	messages := GetSlackMessages(pr)
+	key := (&PausedStep{NodeID: action.NodeID, StepID: action.StepID}).Key()
+	if message, ok := messages[key]; !ok || message != action.Message || pr.HasCompleted() {
+		err = fmt.Errorf("the input step has been handled or is not waiting for approval anymore")
+		return
+	}
+	delete(messages, key)
+	SetSlackMessages(pr, messages)
+	err = c.Update(ctx, pr)
+	return
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/chatops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSlackMessageAndAction(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc"}}
+	step := &PausedStep{NodeID: "10", NodeName: "deploy", StepID: "11",
+		Input: &job.Input{ID: "Deploy", Message: "Deploy <prod>?", Ok: "Yes"}}
+	message := NewSlackMessage(pr, step, "C1")
+	assert.Equal(t, "C1", message.Channel)
+	assert.Equal(t, "PipelineRun *ns/demo-abc* is waiting for approval in stage *deploy*.\nDeploy &lt;prod&gt;?", message.Text)
+
+	// click the button to approve
+	data, err := json.Marshal(message.Blocks[1])
+	assert.Nil(t, err)
+	actions := struct {
+		Elements []struct {
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"elements"`
+	}{}
+	assert.Nil(t, json.Unmarshal(data, &actions))
+	assert.Equal(t, ActionApprove, actions.Elements[0].ActionID)
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":      "block_actions",
+		"user":      map[string]string{"id": "U1"},
+		"container": map[string]string{"channel_id": "C1", "message_ts": "1.2"},
+		"actions":   []interface{}{actions.Elements[0]},
+	})
+	assert.Nil(t, err)
+	action, err := ParseSlackAction(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, &SlackAction{
+		Request: Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "10", StepID: "11", InputID: "Deploy",
+			Action: ActionApprove},
+		UserID:  "U1",
+		Message: SlackMessage{Channel: "C1", TS: "1.2"},
+	}, action)
+
+	// not an approval action
+	action, err = ParseSlackAction([]byte(`{"type":"block_actions","actions":[{"action_id":"other"}]}`))
+	assert.Nil(t, err)
+	assert.Nil(t, action)
+	action, err = ParseSlackAction([]byte(`{"type":"view_submission"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, action)
+	_, err = ParseSlackAction([]byte(`{"type":"block_actions","actions":[{"action_id":"approve","value":"{}"}]}`))
+	assert.NotNil(t, err)
+
+	result := NewSlackResult(SlackMessage{Channel: "C1", TS: "1.2"}, "approved")
+	assert.Equal(t, "1.2", result.TS)
+	assert.Len(t, result.Blocks, 1)
+}
+
+func TestGetSlackUser(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	c := fake.NewClientBuilder().WithScheme(schema).Build()
+	user, err := GetSlackUser(context.Background(), c, "U1")
+	assert.Nil(t, err)
+	assert.Empty(t, user)
+
+	c = fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: chatops.DefaultSecretNamespace, Name: SlackUsersConfigMapName},
+		Data:       map[string]string{"U1": " alice "},
+	}).Build()
+	user, err = GetSlackUser(context.Background(), c, "U1")
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", user)
+	user, err = GetSlackUser(context.Background(), c, "U2")
+	assert.Nil(t, err)
+	assert.Empty(t, user)
+}
+
+func TestIsSubmitter(t *testing.T) {
+	assert.True(t, IsSubmitter(&job.Input{}, "alice"))
+	assert.True(t, IsSubmitter(&job.Input{Submitter: "bob, alice"}, "alice"))
+	assert.False(t, IsSubmitter(&job.Input{Submitter: "bob"}, "alice"))
+}
+
+func TestConsumeSlackMessage(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-abc"}}
+	SetSlackMessages(pr, map[string]SlackMessage{"10/11": {Channel: "C1", TS: "1.2"}, "20/21": {Channel: "C1", TS: "3.4"}})
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pr).Build()
+
+	action := &SlackAction{Request: Request{Namespace: "ns", PipelineRun: "demo-abc", NodeID: "10", StepID: "11"},
+		Message: SlackMessage{Channel: "C1", TS: "1.2"}}
+	_, err = ConsumeSlackMessage(context.Background(), c, action)
+	assert.Nil(t, err)
+
+	updated := &v1alpha3.PipelineRun{}
+	assert.Nil(t, c.Get(context.Background(), client.ObjectKeyFromObject(pr), updated))
+	assert.Equal(t, map[string]SlackMessage{"20/21": {Channel: "C1", TS: "3.4"}}, GetSlackMessages(updated))
+
+	// the buttons cannot be used again
+	_, err = ConsumeSlackMessage(context.Background(), c, action)
+	assert.NotNil(t, err)
+	// the message does not match
+	_, err = ConsumeSlackMessage(context.Background(), c, &SlackAction{Request: Request{Namespace: "ns",
+		PipelineRun: "demo-abc", NodeID: "20", StepID: "21"}, Message: SlackMessage{Channel: "C1", TS: "1.2"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,619 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argoworkflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/lint"
+)
+
+// WorkflowGVK is the GroupVersionKind of Argo Workflow
+var WorkflowGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}
+
+const (
+	// DefaultImage is the image of the steps which are not in a specific container
+	DefaultImage = "alpine:3.16"
+	// DefaultWorkspaceSize is the size of the volume which is shared by all steps
+	DefaultWorkspaceSize = "1Gi"
+
+	gitImage        = "alpine/git:v2.36.2"
+	entrypoint      = "main"
+	workspaceVolume = "workspace"
+	workspacePath   = "/workspace"
+)
+
+// Options are the options of compiling a PipelineRun into an Argo Workflow.
+type Options struct {
+	// DefaultImage is the image of the steps which are not in a specific container
+	DefaultImage string
+	// WorkspaceSize is the size of the volume which is shared by all steps
+	WorkspaceSize string
+	// ServiceAccountName is the service account to run the Workflow
+	ServiceAccountName string
+}
+
+// Result is the result of compiling a PipelineRun.
+type Result struct {
+	Workflow *unstructured.Unstructured
+	// Warnings are the parts of the Jenkinsfile which were ignored
+	Warnings []string
+}
+
+// Compile compiles a PipelineRun into an Argo Workflow. Only the declarative Jenkinsfile of a regular Pipeline is
+// supported, each stage is compiled to a template, and the files are shared by a workspace volume between stages.
+func Compile(pipeline *v1alpha3.Pipeline, pipelineRun *v1alpha3.PipelineRun, options Options) (*Result, error) {
+	spec := &pipeline.Spec
+	if pipelineRun.Spec.PipelineSpec != nil {
+		spec = pipelineRun.Spec.PipelineSpec
+	}
+	if spec.Type != v1alpha3.NoScmPipelineType || spec.Pipeline == nil {
+		return nil, fmt.Errorf("only the Pipeline type %q is supported by Argo Workflows", v1alpha3.NoScmPipelineType)
+	}
+	if options.DefaultImage == "" {
+		options.DefaultImage = DefaultImage
+	}
+	if options.WorkspaceSize == "" {
+		options.WorkspaceSize = DefaultWorkspaceSize
+	}
+	workspaceSize, err := resource.ParseQuantity(options.WorkspaceSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace size %q: %v", options.WorkspaceSize, err)
+	}
+
+	nodes, issue := lint.ParseJenkinsfile(spec.Pipeline.Jenkinsfile)
+	if issue != nil {
+		return nil, fmt.Errorf("invalid Jenkinsfile at line %d: %s", issue.Line, issue.Message)
+	}
+	var pipelineNode *lint.Node
+	for _, node := range nodes {
+		if node.Name == "pipeline" && node.HasBody {
+			pipelineNode = node
+			break
+		}
+	}
+	if pipelineNode == nil {
+		return nil, fmt.Errorf("the declarative 'pipeline' block is not found in the Jenkinsfile")
+	}
+
+	c := &compiler{
+		options:       options,
+		templateNames: map[string]bool{entrypoint: true},
+	}
+	wf := &workflow{
+		APIVersion: WorkflowGVK.GroupVersion().String(),
+		Kind:       WorkflowGVK.Kind,
+		Metadata: metav1.ObjectMeta{
+			Name:      pipelineRun.Name,
+			Namespace: pipelineRun.Namespace,
+			Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey: pipeline.Name,
+			},
+		},
+		Spec: workflowSpec{
+			Entrypoint:         entrypoint,
+			ServiceAccountName: options.ServiceAccountName,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: workspaceVolume},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: workspaceSize},
+					},
+				},
+			}},
+		},
+	}
+	if parameters := getParameters(spec.Pipeline.Parameters, pipelineRun.Spec.Parameters); len(parameters) > 0 {
+		wf.Spec.Arguments = &arguments{Parameters: parameters}
+		for _, param := range parameters {
+			c.parameterEnv = append(c.parameterEnv, corev1.EnvVar{
+				Name:  param.Name,
+				Value: fmt.Sprintf("{{workflow.parameters.%s}}", param.Name),
+			})
+		}
+	}
+
+	pipelineAgent := &agent{image: options.DefaultImage}
+	var env []corev1.EnvVar
+	var stages, post *lint.Node
+	for _, node := range pipelineNode.Body {
+		switch node.Name {
+		case "agent":
+			pipelineAgent = c.parseAgent(node, pipelineAgent)
+		case "environment":
+			env = append(env, parseEnvironment(node)...)
+		case "stages":
+			stages = node
+		case "post":
+			post = node
+		default:
+			c.warnf("section '%s' is not supported and was ignored", node.Name)
+		}
+	}
+	if stages == nil {
+		return nil, fmt.Errorf("the 'stages' section is not found in the Jenkinsfile")
+	}
+	c.compileStages(stages.Body, nil, pipelineAgent, env)
+	if len(c.tasks) == 0 {
+		return nil, fmt.Errorf("there are no stages with steps in the Jenkinsfile")
+	}
+	// the post conditions of the pipeline are handled by the exit handler of the Workflow, the cleanup steps of the
+	// Pipeline spec run after the post section of the Jenkinsfile
+	var exitSteps [][]workflowStep
+	if post != nil {
+		exitSteps = c.compilePost(post, "pipeline", "", "{{workflow.status}}", pipelineAgent, env)
+	}
+	exitSteps = append(exitSteps, c.compileCleanup(spec.Post, "{{workflow.status}}", pipelineAgent, env)...)
+	if len(exitSteps) > 0 {
+		wf.Spec.OnExit = c.uniqueName("exit handler")
+		c.templates = append(c.templates, template{Name: wf.Spec.OnExit, Steps: exitSteps})
+	}
+
+	wf.Spec.Templates = append([]template{{
+		Name: entrypoint,
+		DAG:  &dagTemplate{Tasks: c.tasks},
+	}}, c.templates...)
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(wf)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Workflow: &unstructured.Unstructured{Object: object},
+		Warnings: c.warnings,
+	}, nil
+}
+
+// getParameters returns the parameters of the Workflow, the default values come from the Pipeline definition
+func getParameters(definitions []v1alpha3.ParameterDefinition, values []v1alpha3.Parameter) (parameters []parameter) {
+	index := map[string]int{}
+	for _, definition := range definitions {
+		index[definition.Name] = len(parameters)
+		parameters = append(parameters, parameter{Name: definition.Name, Value: definition.DefaultValue})
+	}
+	for _, value := range values {
+		if i, ok := index[value.Name]; ok {
+			parameters[i].Value = value.Value
+			continue
+		}
+		index[value.Name] = len(parameters)
+		parameters = append(parameters, parameter{Name: value.Name, Value: value.Value})
+	}
+	return
+}
+
+// agent describes the images of an agent
+type agent struct {
+	// image is the image of the steps which are not in a specific container
+	image string
+	// containers are the images of the named containers
+	containers map[string]string
+}
+
+type compiler struct {
+	options       Options
+	parameterEnv  []corev1.EnvVar
+	tasks         []dagTask
+	templates     []template
+	templateNames map[string]bool
+	warnings      []string
+	// failTemplate is the template which fails a stage after its post conditions
+	failTemplate string
+}
+
+func (c *compiler) warnf(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// parseAgent parses the agent section, the Kubernetes container templates and the Docker image are supported
+func (c *compiler) parseAgent(node *lint.Node, parent *agent) *agent {
+	result := &agent{image: parent.image, containers: parent.containers}
+	var walk func(nodes []*lint.Node)
+	walk = func(nodes []*lint.Node) {
+		for _, node := range nodes {
+			switch node.Name {
+			case "docker":
+				// both "docker 'image'" and "docker { image 'image' }" are supported
+				image := node.Arg
+				if node.HasBody {
+					image = findArg(node, "image")
+				}
+				if image != "" {
+					result.image = image
+				}
+			case "containerTemplate":
+				name, image := findArg(node, "name"), findArg(node, "image")
+				if name != "" && image != "" {
+					containers := map[string]string{}
+					for k, v := range result.containers {
+						containers[k] = v
+					}
+					containers[name] = image
+					result.containers = containers
+				}
+			case "yaml", "yamlFile":
+				c.warnf("agent: '%s' is not supported and was ignored", node.Name)
+			default:
+				walk(node.Body)
+			}
+		}
+	}
+	walk(node.Body)
+	return result
+}
+
+// findArg returns the argument of the child with the given name
+func findArg(node *lint.Node, name string) string {
+	for _, child := range node.Body {
+		if child.Name == name {
+			return child.Arg
+		}
+	}
+	return ""
+}
+
+func parseEnvironment(node *lint.Node) (env []corev1.EnvVar) {
+	for _, item := range node.Body {
+		if item.Name != "" {
+			env = append(env, corev1.EnvVar{Name: item.Name, Value: item.Arg})
+		}
+	}
+	return
+}
+
+// compileStages compiles sequential stages, and returns the names of the tasks which the next stage depends on
+func (c *compiler) compileStages(nodes []*lint.Node, dependencies []string, parent *agent, env []corev1.EnvVar) []string {
+	for _, node := range nodes {
+		if node.Name != "stage" || !node.HasBody {
+			c.warnf("'%s' is not a stage and was ignored", node.Name)
+			continue
+		}
+		dependencies = c.compileStage(node, dependencies, parent, env)
+	}
+	return dependencies
+}
+
+func (c *compiler) compileStage(stage *lint.Node, dependencies []string, parent *agent, env []corev1.EnvVar) []string {
+	stageAgent := parent
+	stageEnv := append([]corev1.EnvVar{}, env...)
+	var steps, stages, parallel, post *lint.Node
+	for _, node := range stage.Body {
+		switch node.Name {
+		case "agent":
+			stageAgent = c.parseAgent(node, parent)
+		case "environment":
+			stageEnv = append(stageEnv, parseEnvironment(node)...)
+		case "steps":
+			steps = node
+		case "stages":
+			stages = node
+		case "parallel":
+			parallel = node
+		case "post":
+			post = node
+		default:
+			c.warnf("stage '%s': '%s' is not supported and was ignored", stage.Arg, node.Name)
+		}
+	}
+
+	if post != nil && steps == nil {
+		c.warnf("stage '%s': 'post' is only supported in the stage with steps and was ignored", stage.Arg)
+	}
+
+	switch {
+	case parallel != nil:
+		var exits []string
+		for _, node := range parallel.Body {
+			if node.Name != "stage" || !node.HasBody {
+				continue
+			}
+			exits = append(exits, c.compileStage(node, dependencies, stageAgent, stageEnv)...)
+		}
+		return exits
+	case stages != nil:
+		return c.compileStages(stages.Body, dependencies, stageAgent, stageEnv)
+	case steps != nil:
+		name := c.uniqueName(stage.Arg)
+		if post != nil {
+			c.compileStageWithPost(name, stage.Arg, steps, post, stageAgent, stageEnv)
+		} else {
+			c.compileSteps(name, stage.Arg, steps.Body, stageAgent, stageEnv)
+		}
+		c.tasks = append(c.tasks, dagTask{Name: name, Template: name, Dependencies: dependencies})
+		return []string{name}
+	default:
+		c.warnf("stage '%s' has no steps and was ignored", stage.Arg)
+		return dependencies
+	}
+}
+
+// postConditions are the supported post conditions in the order of Jenkins, and the operators comparing the status
+// with Succeeded, the condition matches any status if the operator is empty
+var postConditions = []struct {
+	name     string
+	operator string
+}{
+	{name: "always"},
+	{name: "failure", operator: "!="},
+	{name: "success", operator: "=="},
+	{name: "unsuccessful", operator: "!="},
+	{name: "cleanup"},
+}
+
+// compilePost compiles the post conditions of a stage, or the pipeline if the stage name is empty, into sequential
+// steps. The templates are named after the prefix, and status is the expression of the status to check.
+func (c *compiler) compilePost(post *lint.Node, prefix, stageName, status string, postAgent *agent,
+	env []corev1.EnvVar) (steps [][]workflowStep) {
+	label, warnLabel := "post", "pipeline"
+	if stageName != "" {
+		label, warnLabel = stageName, fmt.Sprintf("stage '%s'", stageName)
+	}
+
+	bodies := map[string]*lint.Node{}
+	for _, node := range post.Body {
+		bodies[node.Name] = node
+	}
+
+	for _, condition := range postConditions {
+		node := bodies[condition.name]
+		delete(bodies, condition.name)
+		if node == nil || !node.HasBody {
+			continue
+		}
+		name := c.uniqueName(prefix + " post " + condition.name)
+		c.compileSteps(name, label, node.Body, postAgent, env)
+		step := workflowStep{Name: "post-" + condition.name, Template: name}
+		if condition.operator != "" {
+			step.When = fmt.Sprintf("%s %s Succeeded", status, condition.operator)
+		}
+		steps = append(steps, []workflowStep{step})
+	}
+	for _, node := range post.Body {
+		if _, ignored := bodies[node.Name]; ignored {
+			c.warnf("%s: post condition '%s' is not supported and was ignored", warnLabel, node.Name)
+		}
+	}
+	return
+}
+
+// compileCleanup compiles the cleanup steps of the post actions in the Pipeline spec into sequential steps, they run
+// in the image of the pipeline agent
+func (c *compiler) compileCleanup(post *v1alpha3.PipelinePost, status string, postAgent *agent,
+	env []corev1.EnvVar) (steps [][]workflowStep) {
+	for _, condition := range post.Conditions() {
+		if len(condition.Actions.Cleanup) == 0 {
+			continue
+		}
+		name := c.uniqueName("spec post " + condition.Name)
+		c.templates = append(c.templates, c.scriptTemplate(name, &scriptGroup{
+			image: postAgent.image,
+			lines: condition.Actions.Cleanup,
+		}, env))
+		step := workflowStep{Name: "spec-post-" + condition.Name, Template: name}
+		for _, item := range postConditions {
+			if item.name == condition.Name && item.operator != "" {
+				step.When = fmt.Sprintf("%s %s Succeeded", status, item.operator)
+			}
+		}
+		steps = append(steps, []workflowStep{step})
+	}
+	return
+}
+
+// compileStageWithPost compiles a stage into a steps template, the post conditions are executed after the steps even
+// if they failed, then the stage fails if the steps failed
+func (c *compiler) compileStageWithPost(name, stageName string, steps, post *lint.Node, stageAgent *agent, env []corev1.EnvVar) {
+	mainName := c.uniqueName(name + " main")
+	c.compileSteps(mainName, stageName, steps.Body, stageAgent, env)
+	postSteps := c.compilePost(post, name, stageName, "{{steps.main.status}}", stageAgent, env)
+
+	if c.failTemplate == "" {
+		c.failTemplate = c.uniqueName("stage failed")
+		c.templates = append(c.templates, template{
+			Name: c.failTemplate,
+			Script: &scriptTemplate{
+				Image:   c.options.DefaultImage,
+				Command: []string{"sh"},
+				Source:  "echo 'the steps of the stage failed' >&2\nexit 1",
+			},
+		})
+	}
+
+	stageTemplate := template{Name: name}
+	stageTemplate.Steps = append(stageTemplate.Steps, []workflowStep{{
+		Name:       "main",
+		Template:   mainName,
+		ContinueOn: &continueOn{Failed: true},
+	}})
+	stageTemplate.Steps = append(stageTemplate.Steps, postSteps...)
+	stageTemplate.Steps = append(stageTemplate.Steps, []workflowStep{{
+		Name:     "fail",
+		Template: c.failTemplate,
+		When:     "{{steps.main.status}} != Succeeded",
+	}})
+	c.templates = append(c.templates, stageTemplate)
+}
+
+// scriptGroup is a group of steps which are executed in the same container
+type scriptGroup struct {
+	image     string
+	dir       string
+	lines     []string
+	artifacts []artifact
+}
+
+// compileSteps compiles the steps of a stage into a script template, or a steps template if the steps run in
+// different containers
+func (c *compiler) compileSteps(name, stageName string, nodes []*lint.Node, stageAgent *agent, env []corev1.EnvVar) {
+	sc := &stepsCompiler{compiler: c, stage: stageName, agent: stageAgent}
+	sc.compile(nodes, stageAgent.image, "")
+	if len(sc.groups) == 0 {
+		sc.current(stageAgent.image, "")
+	}
+
+	if len(sc.groups) == 1 {
+		c.templates = append(c.templates, c.scriptTemplate(name, sc.groups[0], env))
+		return
+	}
+	stepsTemplate := template{Name: name}
+	var scriptTemplates []template
+	for i, group := range sc.groups {
+		scriptTemplate := c.scriptTemplate(fmt.Sprintf("%s-%d", name, i), group, env)
+		scriptTemplates = append(scriptTemplates, scriptTemplate)
+		stepsTemplate.Steps = append(stepsTemplate.Steps, []workflowStep{{
+			Name:     fmt.Sprintf("step-%d", i),
+			Template: scriptTemplate.Name,
+		}})
+	}
+	c.templates = append(c.templates, stepsTemplate)
+	c.templates = append(c.templates, scriptTemplates...)
+}
+
+func (c *compiler) scriptTemplate(name string, group *scriptGroup, env []corev1.EnvVar) template {
+	result := template{
+		Name: name,
+		Script: &scriptTemplate{
+			Image:      group.image,
+			Command:    []string{"sh"},
+			Source:     strings.Join(append([]string{"set -e"}, group.lines...), "\n"),
+			WorkingDir: workspacePath,
+			Env: append(append([]corev1.EnvVar{{Name: "WORKSPACE", Value: workspacePath}},
+				c.parameterEnv...), env...),
+			VolumeMounts: []corev1.VolumeMount{{Name: workspaceVolume, MountPath: workspacePath}},
+		},
+	}
+	if len(group.artifacts) > 0 {
+		result.Outputs = &outputs{Artifacts: group.artifacts}
+	}
+	return result
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// uniqueName returns a valid and unique template name according to the stage name
+func (c *compiler) uniqueName(stageName string) string {
+	base := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(stageName), "-"), "-")
+	if len(base) > 50 {
+		base = strings.Trim(base[:50], "-")
+	}
+	if base == "" {
+		base = "stage"
+	}
+	name := base
+	for i := 2; c.templateNames[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	c.templateNames[name] = true
+	return name
+}
+
+type stepsCompiler struct {
+	*compiler
+	stage     string
+	agent     *agent
+	groups    []*scriptGroup
+	artifacts int
+}
+
+// current returns the group of the given image, and changes the directory if needed
+func (sc *stepsCompiler) current(image, dir string) *scriptGroup {
+	var group *scriptGroup
+	if len(sc.groups) > 0 && sc.groups[len(sc.groups)-1].image == image {
+		group = sc.groups[len(sc.groups)-1]
+	} else {
+		group = &scriptGroup{image: image}
+		sc.groups = append(sc.groups, group)
+	}
+	if group.dir != dir {
+		if dir == "" {
+			group.lines = append(group.lines, `cd "$WORKSPACE"`)
+		} else {
+			group.lines = append(group.lines, fmt.Sprintf(`cd "$WORKSPACE"/%s`, shellQuote(dir)))
+		}
+		group.dir = dir
+	}
+	return group
+}
+
+func (sc *stepsCompiler) compile(nodes []*lint.Node, image, dir string) {
+	for _, node := range nodes {
+		switch node.Name {
+		case "sh":
+			if node.Arg == "" {
+				sc.warnf("stage '%s': only the literal script of 'sh' is supported, line %d was ignored", sc.stage, node.Line)
+				continue
+			}
+			group := sc.current(image, dir)
+			group.lines = append(group.lines, node.Arg)
+		case "echo":
+			group := sc.current(image, dir)
+			group.lines = append(group.lines, "echo "+shellQuote(node.Arg))
+		case "git":
+			if node.Arg == "" {
+				sc.warnf("stage '%s': the URL of 'git' is required, line %d was ignored", sc.stage, node.Line)
+				continue
+			}
+			group := sc.current(gitImage, dir)
+			group.lines = append(group.lines, "git clone "+shellQuote(node.Arg)+" .")
+		case "archiveArtifacts":
+			if node.Arg == "" || strings.ContainsAny(node.Arg, "*?,") {
+				sc.warnf("stage '%s': only a single path of 'archiveArtifacts' is supported, line %d was ignored", sc.stage, node.Line)
+				continue
+			}
+			group := sc.current(image, dir)
+			path := workspacePath + "/" + strings.TrimPrefix(node.Arg, "/")
+			if dir != "" {
+				path = workspacePath + "/" + dir + "/" + strings.TrimPrefix(node.Arg, "/")
+			}
+			group.artifacts = append(group.artifacts, artifact{Name: fmt.Sprintf("artifact-%d", sc.artifacts), Path: path})
+			sc.artifacts++
+		case "container":
+			containerImage, ok := sc.agent.containers[node.Arg]
+			if !ok {
+				containerImage = sc.agent.image
+				sc.warnf("stage '%s': container '%s' is not defined in the agent, the image %s is used", sc.stage, node.Arg, containerImage)
+			}
+			sc.compile(node.Body, containerImage, dir)
+		case "dir":
+			subDir := strings.Trim(node.Arg, "/")
+			if dir != "" {
+				subDir = dir + "/" + subDir
+			}
+			sc.compile(node.Body, image, subDir)
+		case "script", "withEnv", "timeout", "retry", "catchError", "warnError", "timestamps", "ansiColor":
+			sc.warnf("stage '%s': only the steps in '%s' are compiled, line %d", sc.stage, node.Name, node.Line)
+			sc.compile(node.Body, image, dir)
+		default:
+			sc.warnf("stage '%s': step '%s' is not supported and was ignored", sc.stage, node.Name)
+		}
+	}
+}
+
+// shellQuote quotes a string for the shell
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
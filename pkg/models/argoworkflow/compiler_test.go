@@ -0,0 +1,452 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argoworkflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestCompile(t *testing.T) {
+	newPipeline := func(jenkinsfile string) *v1alpha3.Pipeline {
+		return &v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+			Spec: v1alpha3.PipelineSpec{
+				Type: v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{
+					Name:        "demo",
+					Jenkinsfile: jenkinsfile,
+					Parameters: []v1alpha3.ParameterDefinition{{
+						Name:         "VERSION",
+						DefaultValue: "latest",
+					}},
+				},
+			},
+		}
+	}
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns"},
+	}
+	workspaceEnv := corev1.EnvVar{Name: "WORKSPACE", Value: "/workspace"}
+	versionEnv := corev1.EnvVar{Name: "VERSION", Value: "{{workflow.parameters.VERSION}}"}
+	volumeMounts := []corev1.VolumeMount{{Name: "workspace", MountPath: "/workspace"}}
+
+	tests := []struct {
+		name         string
+		pipeline     *v1alpha3.Pipeline
+		pipelineRun  *v1alpha3.PipelineRun
+		options      Options
+		wantErr      bool
+		wantWarnings []string
+		verify       func(t *testing.T, wf *workflow)
+	}{{
+		name: "multi-branch Pipeline",
+		pipeline: &v1alpha3.Pipeline{Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+		}},
+		pipelineRun: pipelineRun,
+		wantErr:     true,
+	}, {
+		name:        "invalid Jenkinsfile",
+		pipeline:    newPipeline("pipeline {"),
+		pipelineRun: pipelineRun,
+		wantErr:     true,
+	}, {
+		name:        "scripted Pipeline",
+		pipeline:    newPipeline("node { sh 'make' }"),
+		pipelineRun: pipelineRun,
+		wantErr:     true,
+	}, {
+		name:        "no stages",
+		pipeline:    newPipeline("pipeline { agent any }"),
+		pipelineRun: pipelineRun,
+		wantErr:     true,
+	}, {
+		name:        "invalid workspace size",
+		pipeline:    newPipeline("pipeline { stages { stage('a') { steps { sh 'make' } } } }"),
+		pipelineRun: pipelineRun,
+		options:     Options{WorkspaceSize: "fake"},
+		wantErr:     true,
+	}, {
+		name: "sequential stages",
+		pipeline: newPipeline(`
+pipeline {
+  agent {
+    docker { image 'golang:1.17' }
+  }
+  environment {
+    GO111MODULE = 'on'
+  }
+  stages {
+    stage('Build') {
+      steps {
+        git 'https://github.com/kubesphere/ks-devops'
+        sh 'make build'
+        archiveArtifacts 'bin/manager'
+      }
+    }
+    stage('Test') {
+      environment {
+        CGO_ENABLED = '0'
+      }
+      steps {
+        dir('pkg') {
+          sh 'go test ./...'
+        }
+        echo "it's done"
+      }
+    }
+  }
+}
+`),
+		pipelineRun: &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns"},
+			Spec: v1alpha3.PipelineRunSpec{
+				Parameters: []v1alpha3.Parameter{{Name: "VERSION", Value: "v1"}},
+			},
+		},
+		options: Options{ServiceAccountName: "argo"},
+		verify: func(t *testing.T, wf *workflow) {
+			assert.Equal(t, "argoproj.io/v1alpha1", wf.APIVersion)
+			assert.Equal(t, "Workflow", wf.Kind)
+			assert.Equal(t, "demo-abc", wf.Metadata.Name)
+			assert.Equal(t, "ns", wf.Metadata.Namespace)
+			assert.Equal(t, map[string]string{v1alpha3.PipelineNameLabelKey: "demo"}, wf.Metadata.Labels)
+			assert.Equal(t, "main", wf.Spec.Entrypoint)
+			assert.Equal(t, "argo", wf.Spec.ServiceAccountName)
+			assert.Equal(t, &arguments{Parameters: []parameter{{Name: "VERSION", Value: "v1"}}}, wf.Spec.Arguments)
+			assert.Equal(t, 1, len(wf.Spec.VolumeClaimTemplates))
+			assert.Equal(t, "1Gi", wf.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String())
+
+			goEnv := corev1.EnvVar{Name: "GO111MODULE", Value: "on"}
+			assert.Equal(t, []template{{
+				Name: "main",
+				DAG: &dagTemplate{Tasks: []dagTask{
+					{Name: "build", Template: "build"},
+					{Name: "test", Template: "test", Dependencies: []string{"build"}},
+				}},
+			}, {
+				Name: "build",
+				Steps: [][]workflowStep{
+					{{Name: "step-0", Template: "build-0"}},
+					{{Name: "step-1", Template: "build-1"}},
+				},
+			}, {
+				Name: "build-0",
+				Script: &scriptTemplate{
+					Image:        "alpine/git:v2.36.2",
+					Command:      []string{"sh"},
+					Source:       "set -e\ngit clone 'https://github.com/kubesphere/ks-devops' .",
+					WorkingDir:   "/workspace",
+					Env:          []corev1.EnvVar{workspaceEnv, versionEnv, goEnv},
+					VolumeMounts: volumeMounts,
+				},
+			}, {
+				Name: "build-1",
+				Script: &scriptTemplate{
+					Image:        "golang:1.17",
+					Command:      []string{"sh"},
+					Source:       "set -e\nmake build",
+					WorkingDir:   "/workspace",
+					Env:          []corev1.EnvVar{workspaceEnv, versionEnv, goEnv},
+					VolumeMounts: volumeMounts,
+				},
+				Outputs: &outputs{Artifacts: []artifact{{Name: "artifact-0", Path: "/workspace/bin/manager"}}},
+			}, {
+				Name: "test",
+				Script: &scriptTemplate{
+					Image:        "golang:1.17",
+					Command:      []string{"sh"},
+					Source:       "set -e\ncd \"$WORKSPACE\"/'pkg'\ngo test ./...\ncd \"$WORKSPACE\"\necho 'it'\\''s done'",
+					WorkingDir:   "/workspace",
+					Env:          []corev1.EnvVar{workspaceEnv, versionEnv, goEnv, {Name: "CGO_ENABLED", Value: "0"}},
+					VolumeMounts: volumeMounts,
+				},
+			}}, wf.Spec.Templates)
+		},
+	}, {
+		name: "parallel stages, containers and unsupported sections",
+		pipeline: newPipeline(`
+pipeline {
+  agent {
+    kubernetes {
+      inheritFrom 'base'
+      containerTemplate {
+        name 'go'
+        image 'golang:1.17'
+      }
+    }
+  }
+  options {
+    timeout(time: 1, unit: 'HOURS')
+  }
+  stages {
+    stage('Checks') {
+      parallel {
+        stage('Lint') {
+          steps {
+            container('go') {
+              sh 'go vet ./...'
+            }
+          }
+        }
+        stage('Unit test') {
+          when { branch 'main' }
+          steps {
+            container('go') {
+              sh 'go test ./...'
+            }
+            junit 'report.xml'
+          }
+        }
+      }
+    }
+    stage('Lint') {
+      steps {
+        container('missing') {
+          sh 'make lint'
+        }
+      }
+    }
+    stage('Empty') {
+    }
+  }
+}
+`),
+		pipelineRun: pipelineRun,
+		wantWarnings: []string{
+			"section 'options' is not supported and was ignored",
+			"stage 'Unit test': 'when' is not supported and was ignored",
+			"stage 'Unit test': step 'junit' is not supported and was ignored",
+			"stage 'Lint': container 'missing' is not defined in the agent, the image alpine:3.16 is used",
+			"stage 'Empty' has no steps and was ignored",
+		},
+		verify: func(t *testing.T, wf *workflow) {
+			assert.Equal(t, &arguments{Parameters: []parameter{{Name: "VERSION", Value: "latest"}}}, wf.Spec.Arguments)
+			assert.Equal(t, []dagTask{
+				{Name: "lint", Template: "lint"},
+				{Name: "unit-test", Template: "unit-test"},
+				{Name: "lint-2", Template: "lint-2", Dependencies: []string{"lint", "unit-test"}},
+			}, wf.Spec.Templates[0].DAG.Tasks)
+			assert.Equal(t, 4, len(wf.Spec.Templates))
+			assert.Equal(t, "golang:1.17", wf.Spec.Templates[1].Script.Image)
+			assert.Equal(t, "set -e\ngo vet ./...", wf.Spec.Templates[1].Script.Source)
+			assert.Equal(t, "golang:1.17", wf.Spec.Templates[2].Script.Image)
+			assert.Equal(t, "alpine:3.16", wf.Spec.Templates[3].Script.Image)
+		},
+	}, {
+		name: "post conditions",
+		pipeline: newPipeline(`
+pipeline {
+  stages {
+    stage('Build') {
+      steps {
+        sh 'make build'
+      }
+      post {
+        success {
+          echo 'built'
+        }
+        always {
+          sh 'make clean'
+        }
+        fixed {
+          echo 'fixed'
+        }
+      }
+    }
+    stage('Deploy') {
+      stages {
+        stage('Apply') {
+          steps {
+            sh 'make deploy'
+          }
+        }
+      }
+      post {
+        always {
+          echo 'deployed'
+        }
+      }
+    }
+  }
+  post {
+    cleanup {
+      sh 'rm -rf bin'
+    }
+    failure {
+      sh 'curl -X POST https://chat.example.com/hooks/failed'
+    }
+  }
+}
+`),
+		pipelineRun: pipelineRun,
+		wantWarnings: []string{
+			"stage 'Build': post condition 'fixed' is not supported and was ignored",
+			"stage 'Deploy': 'post' is only supported in the stage with steps and was ignored",
+		},
+		verify: func(t *testing.T, wf *workflow) {
+			assert.Equal(t, "exit-handler", wf.Spec.OnExit)
+			assert.Equal(t, []dagTask{
+				{Name: "build", Template: "build"},
+				{Name: "apply", Template: "apply", Dependencies: []string{"build"}},
+			}, wf.Spec.Templates[0].DAG.Tasks)
+
+			templates := map[string]template{}
+			for _, item := range wf.Spec.Templates {
+				templates[item.Name] = item
+			}
+			assert.Equal(t, [][]workflowStep{
+				{{Name: "main", Template: "build-main", ContinueOn: &continueOn{Failed: true}}},
+				{{Name: "post-always", Template: "build-post-always"}},
+				{{Name: "post-success", Template: "build-post-success", When: "{{steps.main.status}} == Succeeded"}},
+				{{Name: "fail", Template: "stage-failed", When: "{{steps.main.status}} != Succeeded"}},
+			}, templates["build"].Steps)
+			assert.Equal(t, "set -e\nmake build", templates["build-main"].Script.Source)
+			assert.Equal(t, "set -e\nmake clean", templates["build-post-always"].Script.Source)
+			assert.Equal(t, "set -e\necho 'built'", templates["build-post-success"].Script.Source)
+			assert.Contains(t, templates["stage-failed"].Script.Source, "exit 1")
+
+			assert.Equal(t, [][]workflowStep{
+				{{Name: "post-failure", Template: "pipeline-post-failure", When: "{{workflow.status}} != Succeeded"}},
+				{{Name: "post-cleanup", Template: "pipeline-post-cleanup"}},
+			}, templates["exit-handler"].Steps)
+			assert.Equal(t, "set -e\nrm -rf bin", templates["pipeline-post-cleanup"].Script.Source)
+		},
+	}, {
+		name: "cleanup steps of the Pipeline spec",
+		pipeline: func() *v1alpha3.Pipeline {
+			pipeline := newPipeline(`
+pipeline {
+  agent {
+    docker {
+      image 'golang:1.17'
+    }
+  }
+  stages {
+    stage('Build') {
+      steps {
+        sh 'make build'
+      }
+    }
+  }
+  post {
+    always {
+      sh 'make clean'
+    }
+  }
+}
+`)
+			pipeline.Spec.Post = &v1alpha3.PipelinePost{
+				Always:  &v1alpha3.PostActions{Cleanup: []string{"rm -rf bin", "rm -rf dist"}},
+				Success: &v1alpha3.PostActions{Notifications: []v1alpha3.PostNotification{{Chat: "slack"}}},
+				Failure: &v1alpha3.PostActions{Cleanup: []string{"kubectl delete ns preview"}},
+			}
+			return pipeline
+		}(),
+		pipelineRun: pipelineRun,
+		verify: func(t *testing.T, wf *workflow) {
+			assert.Equal(t, "exit-handler", wf.Spec.OnExit)
+			templates := map[string]template{}
+			for _, item := range wf.Spec.Templates {
+				templates[item.Name] = item
+			}
+			assert.Equal(t, [][]workflowStep{
+				{{Name: "post-always", Template: "pipeline-post-always"}},
+				{{Name: "spec-post-always", Template: "spec-post-always"}},
+				{{Name: "spec-post-failure", Template: "spec-post-failure", When: "{{workflow.status}} != Succeeded"}},
+			}, templates["exit-handler"].Steps)
+			assert.Equal(t, "golang:1.17", templates["spec-post-always"].Script.Image)
+			assert.Equal(t, "set -e\nrm -rf bin\nrm -rf dist", templates["spec-post-always"].Script.Source)
+			assert.Equal(t, "set -e\nkubectl delete ns preview", templates["spec-post-failure"].Script.Source)
+		},
+	}, {
+		name: "cleanup steps of the Pipeline spec without the post section",
+		pipeline: func() *v1alpha3.Pipeline {
+			pipeline := newPipeline(`
+pipeline {
+  stages {
+    stage('Build') {
+      steps {
+        sh 'make build'
+      }
+    }
+  }
+}
+`)
+			pipeline.Spec.Post = &v1alpha3.PipelinePost{
+				Success: &v1alpha3.PostActions{Cleanup: []string{"rm -rf bin"}},
+			}
+			return pipeline
+		}(),
+		pipelineRun: pipelineRun,
+		verify: func(t *testing.T, wf *workflow) {
+			assert.Equal(t, "exit-handler", wf.Spec.OnExit)
+			assert.Equal(t, template{Name: "exit-handler", Steps: [][]workflowStep{
+				{{Name: "spec-post-success", Template: "spec-post-success", When: "{{workflow.status}} == Succeeded"}},
+			}}, wf.Spec.Templates[len(wf.Spec.Templates)-1])
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compile(tt.pipeline, tt.pipelineRun, tt.options)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantWarnings, result.Warnings)
+			assert.Equal(t, WorkflowGVK, result.Workflow.GroupVersionKind())
+
+			wf := &workflow{}
+			assert.Nil(t, runtime.DefaultUnstructuredConverter.FromUnstructured(result.Workflow.Object, wf))
+			if tt.verify != nil {
+				tt.verify(t, wf)
+			}
+		})
+	}
+}
+
+func TestCompile_PipelineSpecOfPipelineRun(t *testing.T) {
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec: v1alpha3.PipelineSpec{
+			Type:     v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: "pipeline { stages { stage('a') { steps { sh 'new' } } } }"},
+		},
+	}
+	pipelineRun := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-abc", Namespace: "ns"},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineSpec: &v1alpha3.PipelineSpec{
+				Type:     v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: "pipeline { stages { stage('a') { steps { sh 'old' } } } }"},
+			},
+		},
+	}
+	result, err := Compile(pipeline, pipelineRun, Options{})
+	assert.Nil(t, err)
+	wf := &workflow{}
+	assert.Nil(t, runtime.DefaultUnstructuredConverter.FromUnstructured(result.Workflow.Object, wf))
+	assert.Nil(t, wf.Spec.Arguments)
+	assert.Equal(t, "set -e\nold", wf.Spec.Templates[1].Script.Source)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argoworkflow
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below are a subset of the Argo Workflow API, only the fields we need are defined.
+// See also https://argoproj.github.io/argo-workflows/fields/
+
+type workflow struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   metav1.ObjectMeta `json:"metadata"`
+	Spec       workflowSpec      `json:"spec"`
+}
+
+type workflowSpec struct {
+	Entrypoint           string                         `json:"entrypoint"`
+	OnExit               string                         `json:"onExit,omitempty"`
+	ServiceAccountName   string                         `json:"serviceAccountName,omitempty"`
+	Arguments            *arguments                     `json:"arguments,omitempty"`
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	Templates            []template                     `json:"templates"`
+}
+
+type arguments struct {
+	Parameters []parameter `json:"parameters,omitempty"`
+}
+
+type parameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type template struct {
+	Name    string           `json:"name"`
+	DAG     *dagTemplate     `json:"dag,omitempty"`
+	Steps   [][]workflowStep `json:"steps,omitempty"`
+	Script  *scriptTemplate  `json:"script,omitempty"`
+	Outputs *outputs         `json:"outputs,omitempty"`
+}
+
+type dagTemplate struct {
+	Tasks []dagTask `json:"tasks"`
+}
+
+type dagTask struct {
+	Name         string   `json:"name"`
+	Template     string   `json:"template"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+type workflowStep struct {
+	Name       string      `json:"name"`
+	Template   string      `json:"template"`
+	When       string      `json:"when,omitempty"`
+	ContinueOn *continueOn `json:"continueOn,omitempty"`
+}
+
+type continueOn struct {
+	Failed bool `json:"failed,omitempty"`
+}
+
+type scriptTemplate struct {
+	Image        string               `json:"image"`
+	Command      []string             `json:"command"`
+	Source       string               `json:"source"`
+	WorkingDir   string               `json:"workingDir,omitempty"`
+	Env          []corev1.EnvVar      `json:"env,omitempty"`
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+type outputs struct {
+	// Artifacts are saved into the artifact repository which is configured in Argo Workflows
+	Artifacts []artifact `json:"artifacts,omitempty"`
+}
+
+type artifact struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifactrouting
+
+import (
+	"sort"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+// Route is where and how the objects of a DevOps project are stored, the zero value is the default bucket without a
+// prefix and encrypted by the default KMS key
+type Route struct {
+	// Policy is the name of the ArtifactRoutingPolicy which the route comes from
+	Policy string
+	Bucket string
+	Prefix string
+	// KMSKeyID is the KMS key of the DevOpsProject which the objects are encrypted by
+	KMSKeyID string
+}
+
+// Key prepends the prefix to the key of an object
+func (r Route) Key(key string) string {
+	return r.Prefix + key
+}
+
+// UploadOptions returns the options of uploading the objects, they are encrypted by the KMS key of the route
+func (r Route) UploadOptions() (options []s3.UploadOption) {
+	if r.KMSKeyID != "" {
+		options = append(options, s3.WithKMSKey(r.KMSKeyID))
+	}
+	return
+}
+
+// Storage returns the storage of the bucket, which uploads the objects with the options of the route
+func (r Route) Storage(storage s3.Interface) (s3.Interface, error) {
+	storage, err := s3.InBucket(storage, r.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	return s3.WithUploadOptions(storage, r.UploadOptions()...), nil
+}
+
+// Match returns the route of a DevOpsProject. The policies which select the project by its name take precedence over
+// the ones which select it by its workspace, then the first one sorted by the names wins. The KMS key comes from the
+// project whether any policy selects it or not.
+func Match(project *v1alpha3.DevOpsProject, policies []v1alpha3.ArtifactRoutingPolicy) (route Route) {
+	if project == nil {
+		return
+	}
+	if policy := matchPolicy(project, policies); policy != nil {
+		route = newRoute(policy)
+	}
+	if encryption := project.Spec.StorageEncryption; encryption != nil {
+		route.KMSKeyID = encryption.KMSKeyID
+	}
+	return
+}
+
+func matchPolicy(project *v1alpha3.DevOpsProject, policies []v1alpha3.ArtifactRoutingPolicy) *v1alpha3.ArtifactRoutingPolicy {
+	sorted := make([]*v1alpha3.ArtifactRoutingPolicy, 0, len(policies))
+	for i := range policies {
+		sorted = append(sorted, &policies[i])
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var byWorkspace *v1alpha3.ArtifactRoutingPolicy
+	workspace := project.Labels[constants.WorkspaceLabelKey]
+	for _, policy := range sorted {
+		if contains(policy.Spec.Projects, project.Name) {
+			return policy
+		}
+		if byWorkspace == nil && workspace != "" && contains(policy.Spec.Workspaces, workspace) {
+			byWorkspace = policy
+		}
+	}
+	return byWorkspace
+}
+
+func newRoute(policy *v1alpha3.ArtifactRoutingPolicy) Route {
+	return Route{Policy: policy.Name, Bucket: policy.Spec.Bucket, Prefix: policy.Spec.Prefix}
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
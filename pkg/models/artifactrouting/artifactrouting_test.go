@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifactrouting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/s3/fake"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+func policy(name, bucket, prefix string, projects, workspaces []string) v1alpha3.ArtifactRoutingPolicy {
+	return v1alpha3.ArtifactRoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha3.ArtifactRoutingPolicySpec{
+			Projects:   projects,
+			Workspaces: workspaces,
+			Bucket:     bucket,
+			Prefix:     prefix,
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	project := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo",
+		Labels: map[string]string{constants.WorkspaceLabelKey: "tenant-a"},
+	}}
+
+	tests := []struct {
+		name     string
+		project  *v1alpha3.DevOpsProject
+		policies []v1alpha3.ArtifactRoutingPolicy
+		expect   Route
+	}{{
+		name:    "no policies",
+		project: project,
+	}, {
+		name:     "no project",
+		policies: []v1alpha3.ArtifactRoutingPolicy{policy("a", "bucket-a", "", []string{"demo"}, nil)},
+	}, {
+		name:    "no matched policies",
+		project: project,
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("a", "bucket-a", "", []string{"other"}, []string{"tenant-b"}),
+		},
+	}, {
+		name:    "matched by the workspace",
+		project: project,
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("a", "bucket-a", "a/", []string{"other"}, []string{"tenant-a"}),
+		},
+		expect: Route{Policy: "a", Bucket: "bucket-a", Prefix: "a/"},
+	}, {
+		name:    "the project takes precedence over the workspace",
+		project: project,
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("a", "bucket-a", "", nil, []string{"tenant-a"}),
+			policy("b", "bucket-b", "demo/", []string{"demo"}, nil),
+		},
+		expect: Route{Policy: "b", Bucket: "bucket-b", Prefix: "demo/"},
+	}, {
+		name:    "the first one sorted by the names wins",
+		project: project,
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("d", "bucket-d", "", []string{"demo"}, nil),
+			policy("c", "bucket-c", "", []string{"demo"}, nil),
+			policy("b", "bucket-b", "", nil, []string{"tenant-a"}),
+			policy("a", "bucket-a", "", nil, []string{"tenant-a"}),
+		},
+		expect: Route{Policy: "c", Bucket: "bucket-c"},
+	}, {
+		name: "the KMS key of the project",
+		project: &v1alpha3.DevOpsProject{
+			ObjectMeta: project.ObjectMeta,
+			Spec: v1alpha3.DevOpsProjectSpec{
+				StorageEncryption: &v1alpha3.StorageEncryption{KMSKeyID: "demo-key"},
+			},
+		},
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("a", "bucket-a", "a/", nil, []string{"tenant-a"}),
+		},
+		expect: Route{Policy: "a", Bucket: "bucket-a", Prefix: "a/", KMSKeyID: "demo-key"},
+	}, {
+		name: "the KMS key without any policies",
+		project: &v1alpha3.DevOpsProject{
+			ObjectMeta: project.ObjectMeta,
+			Spec: v1alpha3.DevOpsProjectSpec{
+				StorageEncryption: &v1alpha3.StorageEncryption{KMSKeyID: "demo-key"},
+			},
+		},
+		expect: Route{KMSKeyID: "demo-key"},
+	}, {
+		name:    "a project without the workspace",
+		project: &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{Name: "demo"}},
+		policies: []v1alpha3.ArtifactRoutingPolicy{
+			policy("a", "bucket-a", "", nil, []string{""}),
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Match(tt.project, tt.policies))
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	assert.Equal(t, "key", Route{}.Key("key"))
+	assert.Equal(t, "tenant-a/key", Route{Prefix: "tenant-a/"}.Key("key"))
+
+	storage := fake.NewFakeS3()
+	routed, err := Route{}.Storage(storage)
+	assert.Nil(t, err)
+	assert.Same(t, storage, routed)
+
+	routed, err = Route{Bucket: "tenant-a"}.Storage(storage)
+	assert.Nil(t, err)
+	assert.Nil(t, routed.Upload("key", "file", nil))
+	assert.Contains(t, storage.Buckets["tenant-a"].Storage, "key")
+	assert.Empty(t, storage.Storage)
+	assert.Empty(t, Route{}.UploadOptions())
+
+	// the objects are encrypted by the KMS key of the route, unless an upload has its own key
+	routed, err = Route{KMSKeyID: "demo-key"}.Storage(storage)
+	assert.Nil(t, err)
+	assert.Nil(t, routed.Upload("encrypted", "file", nil))
+	assert.Equal(t, "demo-key", storage.Storage["encrypted"].KMSKeyID)
+	assert.Nil(t, routed.Upload("overridden", "file", nil, s3.WithKMSKey("other-key")))
+	assert.Equal(t, "other-key", storage.Storage["overridden"].KMSKeyID)
+
+	routed, err = Route{Bucket: "tenant-a", KMSKeyID: "demo-key"}.Storage(storage)
+	assert.Nil(t, err)
+	assert.Nil(t, routed.Upload("encrypted", "file", nil))
+	assert.Equal(t, "demo-key", storage.Buckets["tenant-a"].Storage["encrypted"].KMSKeyID)
+}
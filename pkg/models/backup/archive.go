@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+)
+
+// ArchiveVersion is the version of the archive format
+const ArchiveVersion = "v1"
+
+// Archive contains the DevOps resources which were exported from a cluster
+type Archive struct {
+	Version        string                   `json:"version"`
+	CreationTime   metav1.Time              `json:"creationTime"`
+	DevOpsProjects []v1alpha3.DevOpsProject `json:"devopsProjects,omitempty"`
+	Pipelines      []v1alpha3.Pipeline      `json:"pipelines,omitempty"`
+	PipelineRuns   []v1alpha3.PipelineRun   `json:"pipelineRuns,omitempty"`
+	Credentials    []Credential             `json:"credentials,omitempty"`
+	// Salt is the random salt of deriving the encryption key of the credentials from the passphrase
+	Salt []byte `json:"salt,omitempty"`
+}
+
+// Credential is a credential Secret whose data was encrypted
+type Credential struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Type              v1.SecretType `json:"type"`
+	// EncryptedData is the encrypted JSON of the Secret data
+	EncryptedData []byte `json:"encryptedData"`
+}
+
+// Encode writes the archive as gzipped JSON
+func (a *Archive) Encode(w io.Writer) (err error) {
+	gzipWriter := gzip.NewWriter(w)
+	if err = json.NewEncoder(gzipWriter).Encode(a); err != nil {
+		return
+	}
+	err = gzipWriter.Close()
+	return
+}
+
+// Decode reads an archive from gzipped JSON
+func Decode(r io.Reader) (archive *Archive, err error) {
+	var gzipReader *gzip.Reader
+	if gzipReader, err = gzip.NewReader(r); err != nil {
+		return
+	}
+	defer func() {
+		_ = gzipReader.Close()
+	}()
+
+	archive = &Archive{}
+	if err = json.NewDecoder(gzipReader).Decode(archive); err == nil && archive.Version != ArchiveVersion {
+		err = fmt.Errorf("unsupported archive version: %q", archive.Version)
+	}
+	return
+}
+
+// Upload encodes the archive and uploads it into the object storage
+func Upload(storage s3.Interface, key string, archive *Archive) (err error) {
+	buf := &bytes.Buffer{}
+	if err = archive.Encode(buf); err == nil {
+		err = storage.Upload(key, key, buf)
+	}
+	return
+}
+
+// Download downloads an archive from the object storage
+func Download(storage s3.Interface, key string) (archive *Archive, err error) {
+	var data []byte
+	if data, err = storage.Read(key); err == nil {
+		archive, err = Decode(bytes.NewReader(data))
+	}
+	return
+}
+
+// the cost parameters of scrypt which are recommended for the interactive logins
+const (
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptP     = 1
+	keySize     = 32
+	saltSize    = 16
+	minSaltSize = 8
+)
+
+// newSalt returns a random salt for an archive
+func newSalt() (salt []byte, err error) {
+	salt = make([]byte, saltSize)
+	_, err = io.ReadFull(rand.Reader, salt)
+	return
+}
+
+// deriveKey derives the AES key from the passphrase and the salt of an archive by scrypt, so the same passphrase
+// leads to different keys in different archives, and the brute force of the passphrase is expensive
+func deriveKey(passphrase string, salt []byte) (key []byte, err error) {
+	if passphrase == "" {
+		err = errors.New("the encryption key is required")
+		return
+	}
+	if len(salt) < minSaltSize {
+		err = errors.New("the salt of the archive is missing")
+		return
+	}
+	key, err = scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	return
+}
+
+func encrypt(key []byte, data []byte) (encrypted []byte, err error) {
+	var gcm cipher.AEAD
+	if gcm, err = newGCM(key); err != nil {
+		return
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	encrypted = gcm.Seal(nonce, nonce, data, nil)
+	return
+}
+
+func decrypt(key []byte, encrypted []byte) (data []byte, err error) {
+	var gcm cipher.AEAD
+	if gcm, err = newGCM(key); err != nil {
+		return
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		err = errors.New("the encrypted data is too short")
+		return
+	}
+	nonce, cipherText := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	if data, err = gcm.Open(nil, nonce, cipherText, nil); err != nil {
+		err = errors.New("failed to decrypt the credential, please check the encryption key")
+	}
+	return
+}
+
+func newGCM(key []byte) (gcm cipher.AEAD, err error) {
+	if len(key) == 0 {
+		err = errors.New("the encryption key is required")
+		return
+	}
+	var block cipher.Block
+	if block, err = aes.NewCipher(key); err == nil {
+		gcm, err = cipher.NewGCM(block)
+	}
+	return
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Options represents the options of exporting the DevOps resources
+type Options struct {
+	// DevOpsProjects are the names of DevOpsProject to export, export all of them if it is empty
+	DevOpsProjects []string
+	// IncludeRunHistory indicates whether to export the completed PipelineRuns
+	IncludeRunHistory bool
+	// EncryptionKey is the passphrase to encrypt the credentials
+	EncryptionKey string
+}
+
+// Export exports the DevOpsProjects together with their Pipelines, credentials and run history
+func Export(ctx context.Context, c client.Reader, opts Options) (archive *Archive, err error) {
+	var projects []v1alpha3.DevOpsProject
+	if projects, err = getDevOpsProjects(ctx, c, opts.DevOpsProjects); err != nil {
+		return
+	}
+
+	archive = &Archive{
+		Version:      ArchiveVersion,
+		CreationTime: metav1.Now(),
+	}
+	if archive.Salt, err = newSalt(); err != nil {
+		return
+	}
+	// the key is derived once for all the credentials, deriving is expensive on purpose
+	var key []byte
+	if opts.EncryptionKey != "" {
+		if key, err = deriveKey(opts.EncryptionKey, archive.Salt); err != nil {
+			return
+		}
+	}
+	for i := range projects {
+		project := projects[i]
+		namespace := getAdminNamespace(&project)
+		project.ObjectMeta = cleanObjectMeta(project.ObjectMeta)
+		archive.DevOpsProjects = append(archive.DevOpsProjects, project)
+
+		if err = exportNamespace(ctx, c, namespace, opts, key, archive); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func getDevOpsProjects(ctx context.Context, c client.Reader, names []string) (projects []v1alpha3.DevOpsProject, err error) {
+	if len(names) == 0 {
+		projectList := &v1alpha3.DevOpsProjectList{}
+		if err = c.List(ctx, projectList); err == nil {
+			projects = projectList.Items
+		}
+		return
+	}
+
+	for _, name := range names {
+		project := v1alpha3.DevOpsProject{}
+		if err = c.Get(ctx, types.NamespacedName{Name: name}, &project); err != nil {
+			return
+		}
+		projects = append(projects, project)
+	}
+	return
+}
+
+func exportNamespace(ctx context.Context, c client.Reader, namespace string, opts Options, key []byte,
+	archive *Archive) (err error) {
+	pipelineList := &v1alpha3.PipelineList{}
+	if err = c.List(ctx, pipelineList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	for i := range pipelineList.Items {
+		pipeline := pipelineList.Items[i]
+		pipeline.ObjectMeta = cleanObjectMeta(pipeline.ObjectMeta)
+		archive.Pipelines = append(archive.Pipelines, pipeline)
+	}
+
+	secretList := &v1.SecretList{}
+	if err = c.List(ctx, secretList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	for i := range secretList.Items {
+		secret := secretList.Items[i]
+		if !strings.HasPrefix(string(secret.Type), v1alpha3.DevOpsCredentialPrefix) {
+			continue
+		}
+
+		var credential *Credential
+		if credential, err = encryptCredential(&secret, key); err != nil {
+			return
+		}
+		archive.Credentials = append(archive.Credentials, *credential)
+	}
+
+	if !opts.IncludeRunHistory {
+		return
+	}
+	pipelineRunList := &v1alpha3.PipelineRunList{}
+	if err = c.List(ctx, pipelineRunList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	for i := range pipelineRunList.Items {
+		pipelineRun := pipelineRunList.Items[i]
+		// the running PipelineRuns cannot be restored
+		if !pipelineRun.HasCompleted() {
+			continue
+		}
+		pipelineRun.ObjectMeta = cleanObjectMeta(pipelineRun.ObjectMeta)
+		archive.PipelineRuns = append(archive.PipelineRuns, pipelineRun)
+	}
+	return
+}
+
+func encryptCredential(secret *v1.Secret, key []byte) (credential *Credential, err error) {
+	var data []byte
+	if data, err = json.Marshal(secret.Data); err != nil {
+		return
+	}
+
+	credential = &Credential{
+		ObjectMeta: cleanObjectMeta(secret.ObjectMeta),
+		Type:       secret.Type,
+	}
+	credential.EncryptedData, err = encrypt(key, data)
+	return
+}
+
+func getAdminNamespace(project *v1alpha3.DevOpsProject) string {
+	if project.Status.AdminNamespace != "" {
+		return project.Status.AdminNamespace
+	}
+	return project.Name
+}
+
+// cleanObjectMeta only keeps the fields which are meaningful in another cluster
+func cleanObjectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"kubesphere.io/devops/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.SchemeBuilder.AddToScheme(schema))
+	return schema
+}
+
+func getSourceObjects() []client.Object {
+	now := metav1.Now()
+	return []client.Object{&v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "demo",
+			UID:             "uid",
+			ResourceVersion: "99",
+			Finalizers:      []string{v1alpha3.DevOpsProjectFinalizerName},
+			Annotations: map[string]string{
+				v1alpha3.DevOpeProjectSyncStatusAnnoKey: "successful",
+			},
+		},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo-abcde"},
+	}, &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: "other"},
+	}, &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "demo-abcde",
+			Name:      "build",
+			Annotations: map[string]string{
+				v1alpha3.PipelineSyncStatusAnnoKey: "successful",
+			},
+		},
+		Spec: v1alpha3.PipelineSpec{
+			Type:     v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{Name: "build", Jenkinsfile: "pipeline {}"},
+		},
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-abcde", Name: "git"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			v1.BasicAuthUsernameKey: []byte("admin"),
+			v1.BasicAuthPasswordKey: []byte("password"),
+		},
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-abcde", Name: "token"},
+		Type:       v1.SecretTypeOpaque,
+	}, &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-abcde", Name: "build-1"},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef: &v1.ObjectReference{Name: "build"},
+		},
+		Status: v1alpha3.PipelineRunStatus{
+			Phase:          v1alpha3.Succeeded,
+			StartTime:      &now,
+			CompletionTime: &now,
+		},
+	}, &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-abcde", Name: "build-2"},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef: &v1.ObjectReference{Name: "build"},
+		},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running},
+	}}
+}
+
+func TestExport(t *testing.T) {
+	schema := newScheme(t)
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(getSourceObjects()...).Build()
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+		verify  func(t *testing.T, archive *Archive)
+	}{{
+		name:    "without encryption key",
+		opts:    Options{},
+		wantErr: true,
+	}, {
+		name:    "not found DevOpsProject",
+		opts:    Options{DevOpsProjects: []string{"fake"}, EncryptionKey: "key"},
+		wantErr: true,
+	}, {
+		name: "all DevOpsProjects without run history",
+		opts: Options{EncryptionKey: "key"},
+		verify: func(t *testing.T, archive *Archive) {
+			assert.Equal(t, ArchiveVersion, archive.Version)
+			assert.Equal(t, saltSize, len(archive.Salt))
+			assert.Equal(t, 2, len(archive.DevOpsProjects))
+			assert.Equal(t, 1, len(archive.Pipelines))
+			assert.Equal(t, 1, len(archive.Credentials))
+			assert.Equal(t, 0, len(archive.PipelineRuns))
+		},
+	}, {
+		name: "a specific DevOpsProject with run history",
+		opts: Options{DevOpsProjects: []string{"demo"}, IncludeRunHistory: true, EncryptionKey: "key"},
+		verify: func(t *testing.T, archive *Archive) {
+			assert.Equal(t, 1, len(archive.DevOpsProjects))
+			project := archive.DevOpsProjects[0]
+			assert.Equal(t, "demo-abcde", project.Status.AdminNamespace)
+			assert.Empty(t, project.UID)
+			assert.Empty(t, project.ResourceVersion)
+			assert.Empty(t, project.Finalizers)
+
+			assert.Equal(t, "git", archive.Credentials[0].Name)
+			assert.NotContains(t, string(archive.Credentials[0].EncryptedData), "password")
+
+			// only the completed PipelineRuns are exported
+			assert.Equal(t, 1, len(archive.PipelineRuns))
+			assert.Equal(t, "build-1", archive.PipelineRuns[0].Name)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := Export(context.TODO(), c, tt.opts)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if tt.verify != nil {
+				tt.verify(t, archive)
+			}
+		})
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	schema := newScheme(t)
+	source := fake.NewClientBuilder().WithScheme(schema).WithObjects(getSourceObjects()...).Build()
+
+	archive, err := Export(context.TODO(), source, Options{
+		DevOpsProjects:    []string{"demo"},
+		IncludeRunHistory: true,
+		EncryptionKey:     "key",
+	})
+	assert.Nil(t, err)
+
+	storage := fakes3.NewFakeS3()
+	assert.Nil(t, Upload(storage, "backups/demo.json.gz", archive))
+	archive, err = Download(storage, "backups/demo.json.gz")
+	assert.Nil(t, err)
+
+	// a wrong encryption key
+	target := fake.NewClientBuilder().WithScheme(schema).Build()
+	_, err = Restore(context.TODO(), target, archive, "wrong")
+	assert.NotNil(t, err)
+	assert.NotNil(t, target.Get(context.TODO(), types.NamespacedName{Name: "demo"}, &v1alpha3.DevOpsProject{}))
+
+	// the credentials cannot be decrypted without the salt of the archive
+	withoutSalt := *archive
+	withoutSalt.Salt = nil
+	_, err = Restore(context.TODO(), target, &withoutSalt, "key")
+	assert.NotNil(t, err)
+
+	target = fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo-abcde", Name: "build"},
+	}).Build()
+	report, err := Restore(context.TODO(), target, archive, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"namespace demo-abcde",
+		"devopsproject demo",
+		"credential demo-abcde/git",
+		"pipelinerun demo-abcde/build-1",
+	}, report.Created)
+	assert.Equal(t, []string{"pipeline demo-abcde/build"}, report.Skipped)
+
+	ns := &v1.Namespace{}
+	assert.Nil(t, target.Get(context.TODO(), types.NamespacedName{Name: "demo-abcde"}, ns))
+	assert.Equal(t, "demo", ns.Labels[constants.DevOpsProjectLabelKey])
+
+	project := &v1alpha3.DevOpsProject{}
+	assert.Nil(t, target.Get(context.TODO(), types.NamespacedName{Name: "demo"}, project))
+	assert.Empty(t, project.Annotations[v1alpha3.DevOpeProjectSyncStatusAnnoKey])
+	assert.Empty(t, project.Status.AdminNamespace)
+
+	secret := &v1.Secret{}
+	assert.Nil(t, target.Get(context.TODO(), types.NamespacedName{Namespace: "demo-abcde", Name: "git"}, secret))
+	assert.Equal(t, "password", string(secret.Data[v1.BasicAuthPasswordKey]))
+
+	pipelineRun := &v1alpha3.PipelineRun{}
+	assert.Nil(t, target.Get(context.TODO(), types.NamespacedName{Namespace: "demo-abcde", Name: "build-1"}, pipelineRun))
+	assert.Equal(t, v1alpha3.Succeeded, pipelineRun.Status.Phase)
+	assert.True(t, pipelineRun.HasCompleted())
+	assert.False(t, pipelineRun.Buildable())
+	assert.Equal(t, "build", pipelineRun.OwnerReferences[0].Name)
+
+	// restore again, all the resources exist
+	report, err = Restore(context.TODO(), target, archive, "key")
+	assert.Nil(t, err)
+	assert.Empty(t, report.Created)
+}
+
+func TestDecode(t *testing.T) {
+	_, err := Decode(bytes.NewBufferString("invalid"))
+	assert.NotNil(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, (&Archive{Version: "v0"}).Encode(buf))
+	_, err = Decode(buf)
+	assert.NotNil(t, err)
+}
+
+func TestEncryptByDerivedKey(t *testing.T) {
+	salt, err := newSalt()
+	assert.Nil(t, err)
+	key, err := deriveKey("passphrase", salt)
+	assert.Nil(t, err)
+
+	encrypted, err := encrypt(key, []byte("password"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(encrypted), "password")
+	data, err := decrypt(key, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "password", string(data))
+
+	// a wrong passphrase
+	wrongKey, err := deriveKey("wrong", salt)
+	assert.Nil(t, err)
+	_, err = decrypt(wrongKey, encrypted)
+	assert.NotNil(t, err)
+
+	// the same passphrase leads to a different key in another archive
+	anotherSalt, err := newSalt()
+	assert.Nil(t, err)
+	anotherKey, err := deriveKey("passphrase", anotherSalt)
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, anotherKey)
+	_, err = decrypt(anotherKey, encrypted)
+	assert.NotNil(t, err)
+
+	_, err = deriveKey("", salt)
+	assert.NotNil(t, err)
+	_, err = deriveKey("passphrase", nil)
+	assert.NotNil(t, err)
+}
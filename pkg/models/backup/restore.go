@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Report describes the result of a restore
+type Report struct {
+	// Created contains the resources which were created
+	Created []string `json:"created,omitempty"`
+	// Skipped contains the resources which already exist
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// the annotations which let the controllers skip the synchronization with Jenkins
+var syncAnnotations = []string{
+	v1alpha3.DevOpeProjectSyncStatusAnnoKey, v1alpha3.DevOpeProjectSyncTimeAnnoKey,
+	v1alpha3.PipelineSyncStatusAnnoKey, v1alpha3.PipelineSyncTimeAnnoKey, v1alpha3.PipelineSyncMsgAnnoKey,
+	v1alpha3.CredentialSyncStatusAnnoKey, v1alpha3.CredentialSyncTimeAnnoKey, v1alpha3.CredentialSyncMsgAnnoKey,
+	v1alpha3.DevOpsCredentialDataHash,
+}
+
+// Restore creates the resources of the archive, the existing resources will be skipped.
+// The DevOpsProjects are restored into the namespaces with the same names as before.
+func Restore(ctx context.Context, c client.Client, archive *Archive, encryptionKey string) (report *Report, err error) {
+	// decrypt the credentials before creating anything, in case of a wrong encryption key
+	var secrets []*v1.Secret
+	var key []byte
+	if len(archive.Credentials) > 0 {
+		if key, err = deriveKey(encryptionKey, archive.Salt); err != nil {
+			return
+		}
+	}
+	for i := range archive.Credentials {
+		var secret *v1.Secret
+		if secret, err = decryptCredential(&archive.Credentials[i], key); err != nil {
+			return
+		}
+		secrets = append(secrets, secret)
+	}
+
+	report = &Report{}
+	restorer := &restorer{Client: c, report: report}
+	for i := range archive.DevOpsProjects {
+		if err = restorer.restoreDevOpsProject(ctx, archive.DevOpsProjects[i].DeepCopy()); err != nil {
+			return
+		}
+	}
+	for _, secret := range secrets {
+		if _, err = restorer.createIfNotExist(ctx, secret, fmt.Sprintf("credential %s/%s", secret.Namespace, secret.Name)); err != nil {
+			return
+		}
+	}
+	for i := range archive.Pipelines {
+		if err = restorer.restorePipeline(ctx, archive.Pipelines[i].DeepCopy()); err != nil {
+			return
+		}
+	}
+	for i := range archive.PipelineRuns {
+		if err = restorer.restorePipelineRun(ctx, archive.PipelineRuns[i].DeepCopy()); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type restorer struct {
+	client.Client
+	report *Report
+}
+
+func (r *restorer) restoreDevOpsProject(ctx context.Context, project *v1alpha3.DevOpsProject) (err error) {
+	// the DevOpsProject controller adopts the namespace which has the label of the project
+	namespace := &v1.Namespace{}
+	nsName := getAdminNamespace(project)
+	if err = r.Get(ctx, types.NamespacedName{Name: nsName}, namespace); apierrors.IsNotFound(err) {
+		namespace = &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nsName,
+				Labels: map[string]string{
+					constants.DevOpsProjectLabelKey: project.Name,
+				},
+			},
+		}
+		_, err = r.createIfNotExist(ctx, namespace, "namespace "+nsName)
+	}
+	if err != nil {
+		return
+	}
+
+	project.Status = v1alpha3.DevOpsProjectStatus{}
+	removeSyncAnnotations(&project.ObjectMeta)
+	_, err = r.createIfNotExist(ctx, project, "devopsproject "+project.Name)
+	return
+}
+
+func decryptCredential(credential *Credential, key []byte) (secret *v1.Secret, err error) {
+	var data []byte
+	if data, err = decrypt(key, credential.EncryptedData); err != nil {
+		return
+	}
+
+	secret = &v1.Secret{
+		ObjectMeta: *credential.ObjectMeta.DeepCopy(),
+		Type:       credential.Type,
+	}
+	if err = json.Unmarshal(data, &secret.Data); err == nil {
+		removeSyncAnnotations(&secret.ObjectMeta)
+	}
+	return
+}
+
+func (r *restorer) restorePipeline(ctx context.Context, pipeline *v1alpha3.Pipeline) (err error) {
+	removeSyncAnnotations(&pipeline.ObjectMeta)
+	_, err = r.createIfNotExist(ctx, pipeline, fmt.Sprintf("pipeline %s/%s", pipeline.Namespace, pipeline.Name))
+	return
+}
+
+func (r *restorer) restorePipelineRun(ctx context.Context, pipelineRun *v1alpha3.PipelineRun) (err error) {
+	name := fmt.Sprintf("pipelinerun %s/%s", pipelineRun.Namespace, pipelineRun.Name)
+	if pipelineRun.Spec.PipelineRef != nil {
+		pipeline := &v1alpha3.Pipeline{}
+		if err = r.Get(ctx, types.NamespacedName{
+			Namespace: pipelineRun.Namespace,
+			Name:      pipelineRun.Spec.PipelineRef.Name,
+		}, pipeline); err != nil {
+			return
+		}
+		if err = controllerutil.SetOwnerReference(pipeline, pipelineRun, r.Scheme()); err != nil {
+			return
+		}
+	}
+
+	// the restored PipelineRun is not buildable, even before its status was restored
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[v1alpha3.PipelineRunRestoredAnnoKey] = "true"
+
+	status := pipelineRun.Status.DeepCopy()
+	var created bool
+	if created, err = r.createIfNotExist(ctx, pipelineRun, name); err != nil || !created {
+		return
+	}
+	// the status is a subresource of PipelineRun, we have to update it separately
+	pipelineRun.Status = *status
+	err = r.Status().Update(ctx, pipelineRun)
+	return
+}
+
+// createIfNotExist creates the object and records it into the report, it skips the existing one
+func (r *restorer) createIfNotExist(ctx context.Context, obj client.Object, name string) (created bool, err error) {
+	if err = r.Create(ctx, obj); err == nil {
+		created = true
+		r.report.Created = append(r.report.Created, name)
+	} else if apierrors.IsAlreadyExists(err) {
+		r.report.Skipped = append(r.report.Skipped, name)
+		err = nil
+	}
+	return
+}
+
+func removeSyncAnnotations(meta *metav1.ObjectMeta) {
+	for _, key := range syncAnnotations {
+		delete(meta.Annotations, key)
+	}
+}
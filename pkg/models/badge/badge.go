@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package badge
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"unicode/utf8"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// DefaultLabel is the label on the left side of a badge
+const DefaultLabel = "build"
+
+// Badge is the status of a Pipeline, its fields follow the endpoint schema of shields.io
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// colors are the colors of shields.io, which are used in the SVG badges
+var colors = map[string]string{
+	"brightgreen": "#4c1",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"yellow":      "#dfb317",
+	"lightgrey":   "#9f9f9f",
+}
+
+// New returns the badge of the latest PipelineRun, the runs should belong to the same Pipeline and branch
+func New(label string, runs []v1alpha3.PipelineRun) *Badge {
+	if label == "" {
+		label = DefaultLabel
+	}
+	badge := &Badge{SchemaVersion: 1, Label: label, Message: "unknown", Color: "lightgrey"}
+	latest := Latest(runs)
+	if latest == nil {
+		return badge
+	}
+	switch latest.Status.Phase {
+	case v1alpha3.Succeeded:
+		badge.Message, badge.Color = "passing", "brightgreen"
+	case v1alpha3.Failed:
+		badge.Message, badge.Color = "failing", "red"
+	case v1alpha3.Running:
+		badge.Message, badge.Color = "running", "blue"
+	case v1alpha3.Pending:
+		badge.Message, badge.Color = "pending", "yellow"
+	case v1alpha3.Cancelled:
+		badge.Message = "cancelled"
+	}
+	return badge
+}
+
+// Latest returns the latest created PipelineRun, or nil if there is no PipelineRun
+func Latest(runs []v1alpha3.PipelineRun) *v1alpha3.PipelineRun {
+	if len(runs) == 0 {
+		return nil
+	}
+	sorted := make([]*v1alpha3.PipelineRun, 0, len(runs))
+	for i := range runs {
+		sorted = append(sorted, &runs[i])
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[j].CreationTimestamp.Before(&sorted[i].CreationTimestamp)
+		}
+		return sorted[i].Name > sorted[j].Name
+	})
+	return sorted[0]
+}
+
+var svgTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">` +
+	`<title>{{.Label}}: {{.Message}}</title>` +
+	`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` +
+	`<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>` +
+	`<g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="20" fill="#555"/>` +
+	`<rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/>` +
+	`<rect width="{{.Width}}" height="20" fill="url(#s)"/></g>` +
+	`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` +
+	`<text x="{{.LabelX}}" y="14">{{.Label}}</text><text x="{{.MessageX}}" y="14">{{.Message}}</text></g></svg>`))
+
+// SVG renders the badge as a flat SVG image like shields.io
+func (b *Badge) SVG() ([]byte, error) {
+	labelWidth, messageWidth := textWidth(b.Label), textWidth(b.Message)
+	color, ok := colors[b.Color]
+	if !ok {
+		color = colors["lightgrey"]
+	}
+	buf := &bytes.Buffer{}
+	err := svgTemplate.Execute(buf, map[string]interface{}{
+		"Label":        b.Label,
+		"Message":      b.Message,
+		"Color":        color,
+		"Width":        labelWidth + messageWidth,
+		"LabelWidth":   labelWidth,
+		"MessageWidth": messageWidth,
+		"LabelX":       labelWidth / 2,
+		"MessageX":     labelWidth + messageWidth/2,
+	})
+	return buf.Bytes(), err
+}
+
+// textWidth estimates the width of a text in 11px Verdana with the padding
+func textWidth(text string) int {
+	return utf8.RuneCountInString(text)*7 + 10
+}
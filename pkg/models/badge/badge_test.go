@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package badge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestNew(t *testing.T) {
+	now := time.Now()
+	newPipelineRun := func(name string, phase v1alpha3.RunPhase, created time.Time) v1alpha3.PipelineRun {
+		return v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Status:     v1alpha3.PipelineRunStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		label       string
+		runs        []v1alpha3.PipelineRun
+		wantMessage string
+		wantColor   string
+	}{{
+		name:        "no runs",
+		wantMessage: "unknown",
+		wantColor:   "lightgrey",
+	}, {
+		name: "the latest one is running",
+		runs: []v1alpha3.PipelineRun{
+			newPipelineRun("a", v1alpha3.Succeeded, now.Add(-time.Hour)),
+			newPipelineRun("b", v1alpha3.Running, now),
+		},
+		wantMessage: "running",
+		wantColor:   "blue",
+	}, {
+		name:  "the same creation time",
+		label: "ci",
+		runs: []v1alpha3.PipelineRun{
+			newPipelineRun("b", v1alpha3.Failed, now),
+			newPipelineRun("a", v1alpha3.Succeeded, now),
+		},
+		wantMessage: "failing",
+		wantColor:   "red",
+	}, {
+		name:        "cancelled",
+		runs:        []v1alpha3.PipelineRun{newPipelineRun("a", v1alpha3.Cancelled, now)},
+		wantMessage: "cancelled",
+		wantColor:   "lightgrey",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := New(tt.label, tt.runs)
+			assert.Equal(t, tt.wantMessage, badge.Message)
+			assert.Equal(t, tt.wantColor, badge.Color)
+			if tt.label == "" {
+				assert.Equal(t, DefaultLabel, badge.Label)
+			} else {
+				assert.Equal(t, tt.label, badge.Label)
+			}
+		})
+	}
+}
+
+func TestBadge_SVG(t *testing.T) {
+	svg, err := (&Badge{Label: "<script>", Message: "passing", Color: "brightgreen"}).SVG()
+	assert.Nil(t, err)
+	text := string(svg)
+	assert.True(t, strings.HasPrefix(text, `<svg xmlns="http://www.w3.org/2000/svg" width="125"`))
+	assert.Contains(t, text, `fill="#4c1"`)
+	assert.NotContains(t, text, "<script>")
+}
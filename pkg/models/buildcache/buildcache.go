@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const (
+	// TypeEnv is the environment variable of the cache backend, it's registry or s3
+	TypeEnv = "BUILD_CACHE_TYPE"
+	// RepositoryEnv is the environment variable of the repository of the registry cache
+	RepositoryEnv = "BUILD_CACHE_REPOSITORY"
+	// InsecureEnv is the environment variable which is true if the cache registry is accessed through plain HTTP
+	InsecureEnv = "BUILD_CACHE_INSECURE"
+	// S3Env is the environment variable of the BuildKit attributes of the S3 cache, such as bucket=cache,region=us-east-1
+	S3Env = "BUILD_CACHE_S3"
+
+	// TypeRegistry stores the cache in a registry
+	TypeRegistry = "registry"
+	// TypeS3 stores the cache in a bucket
+	TypeS3 = "s3"
+
+	// AccessKeyIDKey is the key of the access key ID in the Secret of the S3 cache, BuildKit reads it from the environment
+	AccessKeyIDKey = "AWS_ACCESS_KEY_ID"
+	// SecretAccessKeyKey is the key of the secret access key in the Secret of the S3 cache
+	SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+)
+
+// GetSecretName returns the name of the Secret which contains the S3 credential of the build cache of a DevOpsProject,
+// it's in the namespace of the Jenkins agent pods
+func GetSecretName(project string) string {
+	return "buildcache-" + project
+}
+
+// CredentialGetter returns the Secret of a credential in the DevOpsProject by its name
+type CredentialGetter func(name string) (*v1.Secret, error)
+
+// RenderSecret returns the data of the Secret which is referred by the environment variables of the agent pods.
+// Only the S3 cache needs a Secret, the data is nil otherwise.
+func RenderSecret(cache *v1alpha3.BuildCache, getCredential CredentialGetter) (data map[string][]byte, err error) {
+	if cache.S3 == nil {
+		return
+	}
+	var credential *v1.Secret
+	if credential, err = getCredential(cache.S3.CredentialID); err != nil {
+		err = fmt.Errorf("failed to get the credential %s, error: %v", cache.S3.CredentialID, err)
+		return
+	}
+	if credential.Type != v1alpha3.SecretTypeBasicAuth {
+		err = fmt.Errorf("the credential %s is not a basic-auth credential", cache.S3.CredentialID)
+		return
+	}
+	data = map[string][]byte{
+		AccessKeyIDKey:     credential.Data[v1alpha3.BasicAuthUsernameKey],
+		SecretAccessKeyKey: credential.Data[v1alpha3.BasicAuthPasswordKey],
+	}
+	return
+}
+
+// Env returns the environment variables which tell the build image step where the cache of the DevOpsProject is
+func Env(project string, cache *v1alpha3.BuildCache) (env []v1.EnvVar) {
+	switch {
+	case cache.Registry != nil:
+		env = append(env, v1.EnvVar{Name: TypeEnv, Value: TypeRegistry},
+			v1.EnvVar{Name: RepositoryEnv, Value: cache.Registry.Repository})
+		if cache.Registry.Insecure {
+			env = append(env, v1.EnvVar{Name: InsecureEnv, Value: "true"})
+		}
+	case cache.S3 != nil:
+		env = append(env, v1.EnvVar{Name: TypeEnv, Value: TypeS3},
+			v1.EnvVar{Name: S3Env, Value: GetS3Attributes(project, cache.S3)})
+		// the Secret is optional, so the agent could start before the credential is synced
+		optional := true
+		for _, key := range []string{AccessKeyIDKey, SecretAccessKeyKey} {
+			env = append(env, v1.EnvVar{Name: key, ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: GetSecretName(project)},
+				Key:                  key,
+				Optional:             &optional,
+			}}})
+		}
+	}
+	return
+}
+
+// GetS3Attributes returns the attributes of the BuildKit S3 cache, the caches of DevOpsProjects are separated by the
+// prefixes, so they could share a bucket
+func GetS3Attributes(project string, cache *v1alpha3.S3BuildCache) string {
+	attributes := []string{
+		"bucket=" + cache.Bucket,
+		"region=" + cache.Region,
+		"prefix=" + project + "/",
+	}
+	if cache.Endpoint != "" {
+		attributes = append(attributes, "endpoint_url="+cache.Endpoint)
+	}
+	if cache.UsePathStyle {
+		attributes = append(attributes, "use_path_style=true")
+	}
+	return strings.Join(attributes, ",")
+}
+
+// reportPattern matches the reports which the build image step prints after building an image
+var reportPattern = regexp.MustCompile(`\[build-cache] builder=(\w+) steps=(\d+) cached=(\d+)`)
+
+// Report is the cache usage of an image build
+type Report struct {
+	// Builder is kaniko or buildkit
+	Builder string
+	// Steps is the number of the cacheable steps of the build
+	Steps int
+	// Cached is the number of the steps which hit the cache
+	Cached int
+}
+
+// ParseReports returns the cache reports of the image builds in the log of a PipelineRun
+func ParseReports(log string) (reports []Report) {
+	for _, matches := range reportPattern.FindAllStringSubmatch(log, -1) {
+		steps, _ := strconv.Atoi(matches[2])
+		cached, _ := strconv.Atoi(matches[3])
+		if cached > steps {
+			cached = steps
+		}
+		reports = append(reports, Report{Builder: matches[1], Steps: steps, Cached: cached})
+	}
+	return
+}
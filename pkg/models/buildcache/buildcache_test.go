@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		cache  *v1alpha3.BuildCache
+		verify func(t *testing.T, env []v1.EnvVar)
+	}{{
+		name:  "registry",
+		cache: &v1alpha3.BuildCache{Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"}},
+		verify: func(t *testing.T, env []v1.EnvVar) {
+			assert.Equal(t, []v1.EnvVar{
+				{Name: TypeEnv, Value: TypeRegistry},
+				{Name: RepositoryEnv, Value: "registry.example.com/cache"},
+			}, env)
+		},
+	}, {
+		name: "insecure registry",
+		cache: &v1alpha3.BuildCache{Registry: &v1alpha3.RegistryBuildCache{
+			Repository: "cache.kubesphere-devops-worker:5000/cache", Insecure: true}},
+		verify: func(t *testing.T, env []v1.EnvVar) {
+			assert.Equal(t, 3, len(env))
+			assert.Equal(t, v1.EnvVar{Name: InsecureEnv, Value: "true"}, env[2])
+		},
+	}, {
+		name: "s3",
+		cache: &v1alpha3.BuildCache{S3: &v1alpha3.S3BuildCache{
+			Bucket: "cache", Region: "us-east-1", Endpoint: "http://minio:9000", UsePathStyle: true, CredentialID: "s3"}},
+		verify: func(t *testing.T, env []v1.EnvVar) {
+			assert.Equal(t, 4, len(env))
+			assert.Equal(t, v1.EnvVar{Name: TypeEnv, Value: TypeS3}, env[0])
+			assert.Equal(t, v1.EnvVar{Name: S3Env,
+				Value: "bucket=cache,region=us-east-1,prefix=project/,endpoint_url=http://minio:9000,use_path_style=true"}, env[1])
+			assert.Equal(t, AccessKeyIDKey, env[2].Name)
+			assert.Equal(t, "buildcache-project", env[2].ValueFrom.SecretKeyRef.Name)
+			assert.Equal(t, AccessKeyIDKey, env[2].ValueFrom.SecretKeyRef.Key)
+			assert.Equal(t, SecretAccessKeyKey, env[3].ValueFrom.SecretKeyRef.Key)
+		},
+	}, {
+		name:  "no backend",
+		cache: &v1alpha3.BuildCache{},
+		verify: func(t *testing.T, env []v1.EnvVar) {
+			assert.Empty(t, env)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, Env("project", tt.cache))
+		})
+	}
+}
+
+func TestRenderSecret(t *testing.T) {
+	credentials := map[string]*v1.Secret{
+		"s3": {
+			Type: v1alpha3.SecretTypeBasicAuth,
+			Data: map[string][]byte{
+				v1alpha3.BasicAuthUsernameKey: []byte("access"),
+				v1alpha3.BasicAuthPasswordKey: []byte("secret"),
+			},
+		},
+		"token": {Type: v1alpha3.SecretTypeSecretText},
+	}
+	getCredential := func(name string) (*v1.Secret, error) {
+		if credential, ok := credentials[name]; ok {
+			return credential, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	tests := []struct {
+		name    string
+		cache   *v1alpha3.BuildCache
+		data    map[string][]byte
+		wantErr bool
+	}{{
+		name:  "registry",
+		cache: &v1alpha3.BuildCache{Registry: &v1alpha3.RegistryBuildCache{Repository: "registry.example.com/cache"}},
+	}, {
+		name:  "s3",
+		cache: &v1alpha3.BuildCache{S3: &v1alpha3.S3BuildCache{Bucket: "cache", CredentialID: "s3"}},
+		data: map[string][]byte{
+			AccessKeyIDKey:     []byte("access"),
+			SecretAccessKeyKey: []byte("secret"),
+		},
+	}, {
+		name:    "not a basic-auth credential",
+		cache:   &v1alpha3.BuildCache{S3: &v1alpha3.S3BuildCache{Bucket: "cache", CredentialID: "token"}},
+		wantErr: true,
+	}, {
+		name:    "credential not found",
+		cache:   &v1alpha3.BuildCache{S3: &v1alpha3.S3BuildCache{Bucket: "cache", CredentialID: "missing"}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := RenderSecret(tt.cache, getCredential)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.data, data)
+		})
+	}
+}
+
+func TestParseReports(t *testing.T) {
+	log := `[Pipeline] sh
++ printf '[build-cache] builder=%s steps=%d cached=%d\n' buildkit 5 3
+[build-cache] builder=buildkit steps=5 cached=3
+[Pipeline] sh
+[build-cache] builder=kaniko steps=4 cached=0
+[build-cache] builder=kaniko steps=2 cached=9
+`
+	assert.Equal(t, []Report{
+		{Builder: "buildkit", Steps: 5, Cached: 3},
+		{Builder: "kaniko", Steps: 4, Cached: 0},
+		{Builder: "kaniko", Steps: 2, Cached: 2},
+	}, ParseReports(log))
+	assert.Empty(t, ParseReports("no report"))
+}
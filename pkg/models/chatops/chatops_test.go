@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParse(t *testing.T) {
+	assert.Nil(t, Parse(""))
+	assert.Nil(t, Parse("deploy staging"))
+	assert.Nil(t, Parse("/ deploy"))
+	assert.Equal(t, &Command{Name: "retest", Args: []string{}}, Parse("/retest"))
+	assert.Equal(t, &Command{Name: "deploy", Args: []string{"ns/demo", "staging"}}, Parse("  /deploy ns/demo  staging "))
+
+	commands := ParseAll("LGTM\n/retest\r\n/deploy staging")
+	if assert.Equal(t, 2, len(commands)) {
+		assert.Equal(t, "retest", commands[0].Name)
+		assert.Equal(t, []string{"staging"}, commands[1].Args)
+	}
+}
+
+func TestIsAllowedAndGetParameters(t *testing.T) {
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		v1alpha3.PipelineChatOpsCommandsAnnoKey: "deploy, retest",
+		v1alpha3.PipelineChatOpsArgsAnnoKey:     "env,version",
+	}}}
+	assert.True(t, IsAllowed(pipeline, "deploy"))
+	assert.True(t, IsAllowed(pipeline, "retest"))
+	assert.False(t, IsAllowed(pipeline, "rollback"))
+	assert.False(t, IsAllowed(&v1alpha3.Pipeline{}, "deploy"))
+
+	assert.Nil(t, GetParameters(pipeline, nil))
+	assert.Equal(t, []v1alpha3.Parameter{{Name: "env", Value: "staging"}}, GetParameters(pipeline, []string{"staging"}))
+	assert.Equal(t, []v1alpha3.Parameter{{Name: "env", Value: "prod"}, {Name: "version", Value: "v1"}},
+		GetParameters(pipeline, []string{"prod", "v1", "redundant"}))
+}
+
+func hmacSHA256(secret, message string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1657000000, 0)
+	body := []byte("command=/deploy&text=ns/demo")
+
+	// Slack
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(hmacSHA256("secret", "v0:"+timestamp+":"+string(body))))
+	assert.Nil(t, VerifySlack(header, body, "secret", now))
+	assert.NotNil(t, VerifySlack(header, body, "another", now))
+	assert.NotNil(t, VerifySlack(header, body, "secret", now.Add(time.Hour)))
+
+	// DingTalk
+	timestamp = strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10)
+	header = http.Header{}
+	header.Set("timestamp", timestamp)
+	header.Set("sign", base64.StdEncoding.EncodeToString(hmacSHA256("secret", timestamp+"\nsecret")))
+	assert.Nil(t, VerifyDingTalk(header, "secret", now))
+	assert.NotNil(t, VerifyDingTalk(header, "another", now))
+	header.Set("timestamp", "invalid")
+	assert.NotNil(t, VerifyDingTalk(header, "secret", now))
+
+	// GitHub
+	header = http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(hmacSHA256("secret", string(body))))
+	assert.Nil(t, VerifyGitHub(header, body, "secret"))
+	assert.NotNil(t, VerifyGitHub(header, []byte("changed"), "secret"))
+}
+
+func TestIsTrustedReplyURL(t *testing.T) {
+	assert.True(t, IsTrustedReplyURL(SourceSlack, "https://hooks.slack.com/commands/T123/456/abc"))
+	assert.False(t, IsTrustedReplyURL(SourceSlack, "http://hooks.slack.com/commands/T123/456/abc"))
+	assert.False(t, IsTrustedReplyURL(SourceSlack, "https://hooks.slack.com.evil.com/commands"))
+	assert.True(t, IsTrustedReplyURL(SourceDingTalk, "https://oapi.dingtalk.com/robot/sendBySession?session=xxx"))
+	assert.False(t, IsTrustedReplyURL(SourceGitHub, "https://api.github.com"))
+}
+
+func TestGetUser(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: DefaultSecretNamespace, Name: "devops-dingtalk-users"},
+		Data:       map[string]string{"staff-1": " alice "},
+	}).Build()
+
+	user, err := GetUser(context.Background(), c, SourceDingTalk, "staff-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", user)
+
+	// not mapped
+	user, err = GetUser(context.Background(), c, SourceDingTalk, "staff-2")
+	assert.Nil(t, err)
+	assert.Empty(t, user)
+	user, err = GetUser(context.Background(), c, SourceDingTalk, "")
+	assert.Nil(t, err)
+	assert.Empty(t, user)
+	user, err = GetUser(context.Background(), c, SourceGitHub, "staff-1")
+	assert.Nil(t, err)
+	assert.Empty(t, user)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// Sources of the commands
+const (
+	SourceSlack    = "slack"
+	SourceDingTalk = "dingtalk"
+	SourceGitHub   = "github"
+)
+
+// Command is a command sent from a chat, such as "/deploy staging"
+type Command struct {
+	Name string
+	Args []string
+	// User is the KubeSphere user which the sender of the command is mapped to
+	User string
+	// Source is where the command comes from, such as slack
+	Source string
+	// ReplyURL is the URL to reply the result, it's optional
+	ReplyURL string
+}
+
+// Parse parses a command like "/deploy staging", returns nil if it's not a command
+func Parse(text string) *Command {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") || len(fields[0]) == 1 {
+		return nil
+	}
+	return &Command{Name: strings.TrimPrefix(fields[0], "/"), Args: fields[1:]}
+}
+
+// ParseAll parses all the commands in a multi-line text, one command per line
+func ParseAll(text string) (commands []*Command) {
+	for _, line := range strings.Split(text, "\n") {
+		if command := Parse(line); command != nil {
+			commands = append(commands, command)
+		}
+	}
+	return
+}
+
+// IsAllowed returns true if the Pipeline accepts the command
+func IsAllowed(pipeline *v1alpha3.Pipeline, name string) bool {
+	for _, allowed := range splitAnnotation(pipeline, v1alpha3.PipelineChatOpsCommandsAnnoKey) {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetParameters maps the arguments of the command to the parameters of the Pipeline in order,
+// the redundant arguments are ignored
+func GetParameters(pipeline *v1alpha3.Pipeline, args []string) (parameters []v1alpha3.Parameter) {
+	names := splitAnnotation(pipeline, v1alpha3.PipelineChatOpsArgsAnnoKey)
+	for i, name := range names {
+		if i >= len(args) {
+			break
+		}
+		parameters = append(parameters, v1alpha3.Parameter{Name: name, Value: args[i]})
+	}
+	return
+}
+
+func splitAnnotation(pipeline *v1alpha3.Pipeline, key string) (items []string) {
+	for _, item := range strings.Split(pipeline.Annotations[key], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultSecretNamespace is the namespace of the Secret which contains the secrets of chats
+	DefaultSecretNamespace = "kubesphere-devops-system"
+	// DefaultSecretName is the name of the Secret which contains the secrets of chats, the keys are the sources
+	DefaultSecretName = "devops-chatops"
+	// SecretKeySlackBotToken is the key of the bot token of the Slack app in the Secret, it posts the approval messages
+	SecretKeySlackBotToken = "slack-bot-token"
+	// SecretKeySlackAppToken is the key of the app-level token of the Slack app in the Secret, it receives the clicks on
+	// the buttons of the approval messages in the Socket Mode
+	SecretKeySlackAppToken = "slack-app-token"
+)
+
+// GetSecret returns the secret of a chat source which verifies the requests
+func GetSecret(ctx context.Context, c client.Reader, source string) (secret string, err error) {
+	data := &v1.Secret{}
+	if err = c.Get(ctx, client.ObjectKey{Namespace: DefaultSecretNamespace, Name: DefaultSecretName}, data); err != nil {
+		return
+	}
+	if secret = string(data.Data[source]); secret == "" {
+		err = fmt.Errorf("ChatOps is not configured for %s", source)
+	}
+	return
+}
+
+// GetUsersConfigMapName returns the name of ConfigMap in the namespace of ChatOps secrets which maps the users of a chat
+// source to the KubeSphere users, such as devops-slack-users. The keys are the immutable IDs of the users in the source.
+func GetUsersConfigMapName(source string) string {
+	return "devops-" + source + "-users"
+}
+
+// GetUser returns the KubeSphere user which the user of a chat source is mapped to, it's empty if the user is not mapped.
+// The display names are chosen by the users themselves, so only the immutable IDs are mapped by the admins.
+func GetUser(ctx context.Context, c client.Reader, source, userID string) (user string, err error) {
+	if userID == "" {
+		return
+	}
+	users := &v1.ConfigMap{}
+	if err = c.Get(ctx, client.ObjectKey{Namespace: DefaultSecretNamespace, Name: GetUsersConfigMapName(source)},
+		users); err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+	user = strings.TrimSpace(users.Data[userID])
+	return
+}
+
+// replyHosts are the hosts of the reply URLs of chat sources, the other URLs are not trusted
+var replyHosts = map[string][]string{
+	SourceSlack:    {"hooks.slack.com"},
+	SourceDingTalk: {"oapi.dingtalk.com", "api.dingtalk.com"},
+}
+
+// IsTrustedReplyURL returns true if the reply URL belongs to the chat source
+func IsTrustedReplyURL(source, replyURL string) bool {
+	parsed, err := url.Parse(replyURL)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+	for _, host := range replyHosts[source] {
+		if parsed.Hostname() == host || strings.HasSuffix(parsed.Hostname(), "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewReply returns the message which replies to a chat source
+func NewReply(source, text string) interface{} {
+	switch source {
+	case SourceSlack:
+		return map[string]string{"response_type": "in_channel", "text": text}
+	case SourceDingTalk:
+		return map[string]interface{}{"msgtype": "text", "text": map[string]string{"content": text}}
+	default:
+		return map[string]string{"text": text}
+	}
+}
+
+// Reply sends a message to the reply URL of a chat source
+func Reply(httpClient *http.Client, source, url, text string) (err error) {
+	var data []byte
+	if data, err = json.Marshal(NewReply(source, text)); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = httpClient.Post(url, "application/json", bytes.NewReader(data)); err != nil {
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("failed to reply to %s, status code: %d", source, resp.StatusCode)
+	}
+	return
+}
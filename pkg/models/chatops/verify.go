@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxClockSkew is the max difference between the timestamp of a request and now, it prevents replay attacks
+const maxClockSkew = 5 * time.Minute
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// VerifySlack verifies the signature of a request from Slack.
+// See also https://api.slack.com/authentication/verifying-requests-from-slack
+func VerifySlack(header http.Header, body []byte, secret string, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	if err := checkTimestamp(timestamp, time.Second, now); err != nil {
+		return err
+	}
+	expected := "v0=" + hex.EncodeToString(sign(secret, "v0:"+timestamp+":"+string(body)))
+	return compare(expected, header.Get("X-Slack-Signature"))
+}
+
+// VerifyDingTalk verifies the signature of a request from the outgoing robot of DingTalk.
+// See also https://open.dingtalk.com/document/robots/enterprise-created-chatbot
+func VerifyDingTalk(header http.Header, secret string, now time.Time) error {
+	timestamp := header.Get("timestamp")
+	if err := checkTimestamp(timestamp, time.Millisecond, now); err != nil {
+		return err
+	}
+	expected := base64.StdEncoding.EncodeToString(sign(secret, timestamp+"\n"+secret))
+	return compare(expected, header.Get("sign"))
+}
+
+// VerifyGitHub verifies the signature of a webhook from GitHub.
+// See also https://docs.github.com/en/developers/webhooks-and-events/webhooks/securing-your-webhooks
+func VerifyGitHub(header http.Header, body []byte, secret string) error {
+	expected := "sha256=" + hex.EncodeToString(sign(secret, string(body)))
+	return compare(expected, header.Get("X-Hub-Signature-256"))
+}
+
+func sign(secret, message string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+func compare(expected, actual string) error {
+	if !hmac.Equal([]byte(expected), []byte(actual)) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func checkTimestamp(timestamp string, unit time.Duration, now time.Time) error {
+	value, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if diff := now.Sub(time.Unix(0, value*int64(unit))); diff > maxClockSkew || diff < -maxClockSkew {
+		return fmt.Errorf("the timestamp %q is expired", timestamp)
+	}
+	return nil
+}
@@ -0,0 +1,768 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// defaultAgentLabel is the Jenkins agent label used when a job does not declare an image.
+const defaultAgentLabel = "base"
+
+// gitlabReservedKeys are the top-level keywords of .gitlab-ci.yml which are not jobs.
+var gitlabReservedKeys = map[string]bool{
+	"stages":        true,
+	"variables":     true,
+	"image":         true,
+	"services":      true,
+	"cache":         true,
+	"before_script": true,
+	"after_script":  true,
+	"default":       true,
+	"include":       true,
+	"workflow":      true,
+	"types":         true,
+}
+
+// gitlabDefaultStages is the stage list of GitLab CI when "stages" is absent.
+var gitlabDefaultStages = []string{".pre", "build", "test", "deploy", ".post"}
+
+// Result is the output of a conversion.
+type Result struct {
+	// Pipeline is the converted Pipeline, the Jenkinsfile is in spec.pipeline.jenkinsfile.
+	Pipeline *v1alpha3.Pipeline `json:"pipeline"`
+	// Warnings contains the keywords or values which could not be translated exactly.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// stringList accepts both a single string and a list of strings.
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *stringList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*s = []string{value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range value.Content {
+			// nested lists are allowed by GitLab via YAML anchors
+			var nested stringList
+			if err := nested.UnmarshalYAML(item); err != nil {
+				return err
+			}
+			items = append(items, nested...)
+		}
+		*s = items
+		return nil
+	}
+	return fmt.Errorf("line %d: expected a string or a list of strings", value.Line)
+}
+
+// gitlabImage accepts both "image: name" and "image: {name: name}".
+type gitlabImage struct {
+	Name string `yaml:"name"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (i *gitlabImage) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		i.Name = value.Value
+		return nil
+	}
+	type plain gitlabImage
+	return value.Decode((*plain)(i))
+}
+
+type gitlabCache struct {
+	Key   string     `yaml:"key"`
+	Paths stringList `yaml:"paths"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, the key could be a string or a map like {files: [...]}.
+func (c *gitlabCache) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: cache should be a map", value.Line)
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i].Value, value.Content[i+1]
+		switch key {
+		case "key":
+			if val.Kind == yaml.ScalarNode {
+				c.Key = val.Value
+			} else {
+				c.Key = "default"
+			}
+		case "paths":
+			if err := c.Paths.UnmarshalYAML(val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type gitlabArtifacts struct {
+	Paths    stringList `yaml:"paths"`
+	Exclude  stringList `yaml:"exclude"`
+	When     string     `yaml:"when"`
+	ExpireIn string     `yaml:"expire_in"`
+}
+
+type gitlabRefs struct {
+	Refs stringList `yaml:"refs"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, supports both "only: [main]" and "only: {refs: [main]}".
+func (r *gitlabRefs) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		type plain gitlabRefs
+		return value.Decode((*plain)(r))
+	}
+	return r.Refs.UnmarshalYAML(value)
+}
+
+type gitlabJob struct {
+	name string
+
+	Stage        string            `yaml:"stage"`
+	Image        *gitlabImage      `yaml:"image"`
+	Script       stringList        `yaml:"script"`
+	BeforeScript *stringList       `yaml:"before_script"`
+	AfterScript  *stringList       `yaml:"after_script"`
+	Variables    map[string]string `yaml:"variables"`
+	Cache        *gitlabCache      `yaml:"cache"`
+	Artifacts    *gitlabArtifacts  `yaml:"artifacts"`
+	Only         *gitlabRefs       `yaml:"only"`
+	Except       *gitlabRefs       `yaml:"except"`
+	When         string            `yaml:"when"`
+	AllowFailure bool              `yaml:"allow_failure"`
+	Tags         stringList        `yaml:"tags"`
+	Timeout      string            `yaml:"timeout"`
+	Extends      stringList        `yaml:"extends"`
+	Rules        []interface{}     `yaml:"rules"`
+	Services     []interface{}     `yaml:"services"`
+	Needs        []interface{}     `yaml:"needs"`
+}
+
+type gitlabDefault struct {
+	Image        *gitlabImage `yaml:"image"`
+	BeforeScript *stringList  `yaml:"before_script"`
+	AfterScript  *stringList  `yaml:"after_script"`
+	Cache        *gitlabCache `yaml:"cache"`
+	Tags         stringList   `yaml:"tags"`
+}
+
+type gitlabCI struct {
+	Stages    []string
+	Variables map[string]string
+	Default   gitlabDefault
+	Jobs      []*gitlabJob
+}
+
+// GitLabCIConverter converts a .gitlab-ci.yml into a Pipeline.
+type GitLabCIConverter struct {
+	warnings []string
+}
+
+// Convert parses the content of a .gitlab-ci.yml and returns an equivalent Pipeline with the given name.
+func (c *GitLabCIConverter) Convert(name, content string) (result *Result, err error) {
+	c.warnings = nil
+	var ci *gitlabCI
+	if ci, err = c.parse(content); err != nil {
+		return
+	}
+
+	if name == "" {
+		name = "gitlab-ci"
+	}
+	jenkinsfile := c.render(ci)
+	result = &Result{
+		Pipeline: &v1alpha3.Pipeline{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       v1alpha3.ResourceKindPipeline,
+				APIVersion: v1alpha3.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					v1alpha3.PipelineJenkinsfileEditModeAnnoKey: v1alpha3.PipelineJenkinsfileEditModeRaw,
+				},
+			},
+			Spec: v1alpha3.PipelineSpec{
+				Type: v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{
+					Name:        name,
+					Description: "Converted from .gitlab-ci.yml",
+					Jenkinsfile: jenkinsfile,
+				},
+			},
+		},
+		Warnings: c.warnings,
+	}
+	return
+}
+
+func (c *GitLabCIConverter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *GitLabCIConverter) parse(content string) (ci *gitlabCI, err error) {
+	root := &yaml.Node{}
+	if err = yaml.Unmarshal([]byte(content), root); err != nil {
+		return
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		err = errors.New("the content of .gitlab-ci.yml should be a YAML map")
+		return
+	}
+	doc := root.Content[0]
+
+	ci = &gitlabCI{}
+	templates := map[string]*yaml.Node{}
+	var jobNodes []*yaml.Node
+	var jobNames []string
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, val := doc.Content[i].Value, doc.Content[i+1]
+		switch {
+		case key == "stages":
+			err = val.Decode(&ci.Stages)
+		case key == "variables":
+			ci.Variables, err = decodeVariables(val)
+		case key == "default":
+			err = val.Decode(&ci.Default)
+		case key == "image":
+			err = val.Decode(&ci.Default.Image)
+		case key == "before_script":
+			err = val.Decode(&ci.Default.BeforeScript)
+		case key == "after_script":
+			err = val.Decode(&ci.Default.AfterScript)
+		case key == "cache":
+			err = val.Decode(&ci.Default.Cache)
+		case strings.HasPrefix(key, "."):
+			// hidden jobs are only used as templates
+			templates[key] = val
+		case gitlabReservedKeys[key]:
+			c.warn("keyword '%s' is not supported and was ignored", key)
+		default:
+			jobNames = append(jobNames, key)
+			jobNodes = append(jobNodes, val)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to parse '%s': %v", key, err)
+			return
+		}
+	}
+
+	if len(ci.Stages) == 0 {
+		ci.Stages = gitlabDefaultStages
+	}
+	for i, val := range jobNodes {
+		job := &gitlabJob{name: jobNames[i]}
+		if err = decodeJob(val, templates, job, 0); err != nil {
+			err = fmt.Errorf("failed to parse job '%s': %v", jobNames[i], err)
+			return
+		}
+		if job.Stage == "" {
+			job.Stage = "test"
+		}
+		ci.Jobs = append(ci.Jobs, job)
+	}
+	if len(ci.Jobs) == 0 {
+		err = errors.New("no job was found in .gitlab-ci.yml")
+	}
+	return
+}
+
+// decodeJob decodes a job, the templates referenced by "extends" are decoded first so that the job could override them.
+func decodeJob(node *yaml.Node, templates map[string]*yaml.Node, job *gitlabJob, depth int) (err error) {
+	if depth > 10 {
+		return errors.New("too deep nesting of 'extends'")
+	}
+	parents := &struct {
+		Extends stringList `yaml:"extends"`
+	}{}
+	if err = node.Decode(parents); err != nil {
+		return
+	}
+	for _, parent := range parents.Extends {
+		template, ok := templates[parent]
+		if !ok {
+			return fmt.Errorf("cannot find the template '%s'", parent)
+		}
+		if err = decodeJob(template, templates, job, depth+1); err != nil {
+			return
+		}
+	}
+	return node.Decode(job)
+}
+
+func decodeVariables(node *yaml.Node) (variables map[string]string, err error) {
+	variables = map[string]string{}
+	if node.Kind != yaml.MappingNode {
+		err = errors.New("variables should be a map")
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i].Value, node.Content[i+1]
+		if val.Kind == yaml.MappingNode {
+			// the expanded form like {value: xxx, description: xxx}
+			value := &struct {
+				Value string `yaml:"value"`
+			}{}
+			if err = val.Decode(value); err != nil {
+				return
+			}
+			variables[key] = value.Value
+		} else {
+			variables[key] = val.Value
+		}
+	}
+	return
+}
+
+// render generates a declarative Jenkinsfile from the parsed GitLab CI.
+func (c *GitLabCIConverter) render(ci *gitlabCI) string {
+	w := &jenkinsfileWriter{}
+	w.line("pipeline {")
+	w.indent++
+	w.line("agent none")
+
+	if len(ci.Variables) > 0 {
+		w.line("environment {")
+		w.indent++
+		for _, key := range sortedKeys(ci.Variables) {
+			w.line("%s = %s", key, quote(ci.Variables[key]))
+		}
+		w.indent--
+		w.line("}")
+	}
+
+	w.line("stages {")
+	w.indent++
+	stashed := map[string]bool{}
+	for _, stage := range ci.Stages {
+		var jobs []*gitlabJob
+		for _, job := range ci.Jobs {
+			if job.Stage == stage {
+				jobs = append(jobs, job)
+			}
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+
+		// all jobs in the same stage run in parallel
+		if len(jobs) == 1 {
+			c.renderJob(w, ci, jobs[0], stashed)
+		} else {
+			w.line("stage(%s) {", quote(stage))
+			w.indent++
+			w.line("parallel {")
+			w.indent++
+			for _, job := range jobs {
+				c.renderJob(w, ci, job, stashed)
+			}
+			w.indent--
+			w.line("}")
+			w.indent--
+			w.line("}")
+		}
+		// artifacts of the former stages are available in the later stages
+		for _, job := range jobs {
+			if job.Artifacts != nil && len(job.Artifacts.Paths) > 0 && job.Artifacts.When != "on_failure" {
+				stashed[artifactsStashName(job)] = true
+			}
+			if cache := c.jobCache(ci, job); cache != nil {
+				stashed[cacheStashName(cache)] = true
+			}
+		}
+	}
+	w.indent--
+	w.line("}")
+	w.indent--
+	w.line("}")
+
+	for _, job := range ci.Jobs {
+		if !containsString(ci.Stages, job.Stage) {
+			c.warn("job '%s' refers to an undefined stage '%s' and was ignored", job.name, job.Stage)
+		}
+	}
+	return w.String()
+}
+
+func (c *GitLabCIConverter) jobCache(ci *gitlabCI, job *gitlabJob) *gitlabCache {
+	cache := job.Cache
+	if cache == nil {
+		cache = ci.Default.Cache
+	}
+	if cache == nil || len(cache.Paths) == 0 {
+		return nil
+	}
+	return cache
+}
+
+func (c *GitLabCIConverter) renderJob(w *jenkinsfileWriter, ci *gitlabCI, job *gitlabJob, stashed map[string]bool) {
+	w.line("stage(%s) {", quote(job.name))
+	w.indent++
+
+	image := ci.Default.Image
+	if job.Image != nil {
+		image = job.Image
+	}
+	tags := ci.Default.Tags
+	if len(job.Tags) > 0 {
+		tags = job.Tags
+	}
+	container := renderAgent(w, image, tags)
+
+	if when := refsCondition(job); when != "" {
+		w.line("when {")
+		w.indent++
+		w.line(when)
+		w.indent--
+		w.line("}")
+	}
+	if job.Timeout != "" {
+		if timeout, ok := parseTimeoutMinutes(job.Timeout); ok {
+			w.line("options {")
+			w.indent++
+			w.line("timeout(time: %d, unit: 'MINUTES')", timeout)
+			w.indent--
+			w.line("}")
+		} else {
+			c.warn("job '%s': cannot parse timeout '%s'", job.name, job.Timeout)
+		}
+	}
+	if len(job.Variables) > 0 {
+		w.line("environment {")
+		w.indent++
+		for _, key := range sortedKeys(job.Variables) {
+			w.line("%s = %s", key, quote(job.Variables[key]))
+		}
+		w.indent--
+		w.line("}")
+	}
+
+	w.line("steps {")
+	w.indent++
+	if job.When == "manual" {
+		w.line("input(message: %s)", quote(fmt.Sprintf("Run job %s?", job.name)))
+	} else if job.When != "" && job.When != "on_success" {
+		c.warn("job '%s': 'when: %s' is not supported and treated as 'on_success'", job.name, job.When)
+	}
+	if len(job.Rules) > 0 {
+		c.warn("job '%s': 'rules' is not supported and was ignored", job.name)
+	}
+	if len(job.Services) > 0 {
+		c.warn("job '%s': 'services' is not supported and was ignored", job.name)
+	}
+
+	for _, other := range ci.Jobs {
+		if other.Artifacts != nil && stashed[artifactsStashName(other)] {
+			w.line("unstash %s", quote(artifactsStashName(other)))
+		}
+	}
+	cache := c.jobCache(ci, job)
+	if cache != nil && stashed[cacheStashName(cache)] {
+		w.line("unstash %s", quote(cacheStashName(cache)))
+	}
+
+	beforeScript := ci.Default.BeforeScript
+	if job.BeforeScript != nil {
+		beforeScript = job.BeforeScript
+	}
+	var script []string
+	if beforeScript != nil {
+		script = append(script, *beforeScript...)
+	}
+	script = append(script, job.Script...)
+
+	wrapped := 0
+	if container != "" {
+		w.line("container(%s) {", quote(container))
+		w.indent++
+		wrapped++
+	}
+	if job.AllowFailure {
+		w.line("catchError(buildResult: 'SUCCESS', stageResult: 'FAILURE') {")
+		w.indent++
+		wrapped++
+	}
+	if len(script) > 0 {
+		w.line("sh %s", quoteMultiline(strings.Join(script, "\n")))
+	}
+	for ; wrapped > 0; wrapped-- {
+		w.indent--
+		w.line("}")
+	}
+
+	if cache != nil {
+		w.line("stash name: %s, includes: %s, allowEmpty: true", quote(cacheStashName(cache)), quote(strings.Join(cache.Paths, ",")))
+	}
+	w.indent--
+	w.line("}")
+
+	afterScript := ci.Default.AfterScript
+	if job.AfterScript != nil {
+		afterScript = job.AfterScript
+	}
+	renderPost(w, job, afterScript, container)
+
+	w.indent--
+	w.line("}")
+}
+
+func renderAgent(w *jenkinsfileWriter, image *gitlabImage, tags []string) (container string) {
+	if image == nil || image.Name == "" {
+		label := defaultAgentLabel
+		if len(tags) > 0 {
+			label = strings.Join(tags, " && ")
+		}
+		w.line("agent {")
+		w.indent++
+		w.line("node {")
+		w.indent++
+		w.line("label %s", quote(label))
+		w.indent--
+		w.line("}")
+		w.indent--
+		w.line("}")
+		return defaultAgentLabel
+	}
+
+	container = "gitlab-job"
+	w.line("agent {")
+	w.indent++
+	w.line("kubernetes {")
+	w.indent++
+	w.line("inheritFrom %s", quote(defaultAgentLabel))
+	w.line("containerTemplate {")
+	w.indent++
+	w.line("name %s", quote(container))
+	w.line("image %s", quote(image.Name))
+	w.line("command 'cat'")
+	w.line("ttyEnabled true")
+	w.indent--
+	w.line("}")
+	w.indent--
+	w.line("}")
+	w.indent--
+	w.line("}")
+	return
+}
+
+func renderPost(w *jenkinsfileWriter, job *gitlabJob, afterScript *stringList, container string) {
+	var archive string
+	var when string
+	if job.Artifacts != nil && len(job.Artifacts.Paths) > 0 {
+		paths := strings.Join(job.Artifacts.Paths, ",")
+		archive = fmt.Sprintf("archiveArtifacts artifacts: %s, allowEmptyArchive: true", quote(paths))
+		if len(job.Artifacts.Exclude) > 0 {
+			archive += fmt.Sprintf(", excludes: %s", quote(strings.Join(job.Artifacts.Exclude, ",")))
+		}
+		switch job.Artifacts.When {
+		case "always":
+			when = "always"
+		case "on_failure":
+			when = "failure"
+		default:
+			when = "success"
+		}
+	}
+	if archive == "" && (afterScript == nil || len(*afterScript) == 0) {
+		return
+	}
+
+	w.line("post {")
+	w.indent++
+	if afterScript != nil && len(*afterScript) > 0 {
+		w.line("always {")
+		w.indent++
+		if container != "" {
+			w.line("container(%s) {", quote(container))
+			w.indent++
+		}
+		w.line("sh %s", quoteMultiline(strings.Join(*afterScript, "\n")))
+		if container != "" {
+			w.indent--
+			w.line("}")
+		}
+		if when == "always" {
+			w.line(archive)
+			w.line("stash name: %s, includes: %s, allowEmpty: true", quote(artifactsStashName(job)), quote(strings.Join(job.Artifacts.Paths, ",")))
+			archive = ""
+		}
+		w.indent--
+		w.line("}")
+	}
+	if archive != "" {
+		w.line("%s {", when)
+		w.indent++
+		w.line(archive)
+		w.line("stash name: %s, includes: %s, allowEmpty: true", quote(artifactsStashName(job)), quote(strings.Join(job.Artifacts.Paths, ",")))
+		w.indent--
+		w.line("}")
+	}
+	w.indent--
+	w.line("}")
+}
+
+func refsCondition(job *gitlabJob) string {
+	var conditions []string
+	if job.Only != nil && len(job.Only.Refs) > 0 {
+		if branches := refsToBranches(job.Only.Refs); len(branches) > 0 {
+			conditions = append(conditions, anyOf(branches))
+		}
+	}
+	if job.Except != nil && len(job.Except.Refs) > 0 {
+		if branches := refsToBranches(job.Except.Refs); len(branches) > 0 {
+			conditions = append(conditions, fmt.Sprintf("not { %s }", anyOf(branches)))
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		return ""
+	case 1:
+		return conditions[0]
+	}
+	return fmt.Sprintf("allOf { %s }", strings.Join(conditions, "; "))
+}
+
+func refsToBranches(refs []string) (branches []string) {
+	for _, ref := range refs {
+		switch ref {
+		case "branches", "pushes", "web", "api", "triggers", "schedules", "pipelines":
+			// all branches match these keywords
+			continue
+		case "tags":
+			branches = append(branches, "buildingTag()")
+		case "merge_requests":
+			branches = append(branches, "changeRequest()")
+		default:
+			if strings.HasPrefix(ref, "/") && strings.HasSuffix(ref, "/") && len(ref) > 1 {
+				branches = append(branches, fmt.Sprintf("branch pattern: %s, comparator: 'REGEXP'", quote(strings.Trim(ref, "/"))))
+			} else {
+				branches = append(branches, fmt.Sprintf("branch %s", quote(ref)))
+			}
+		}
+	}
+	return
+}
+
+func anyOf(conditions []string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return fmt.Sprintf("anyOf { %s }", strings.Join(conditions, "; "))
+}
+
+// parseTimeoutMinutes parses the GitLab timeout like "1h 30m" or "45 minutes" into minutes.
+func parseTimeoutMinutes(timeout string) (minutes int, ok bool) {
+	fields := strings.Fields(strings.NewReplacer("hours", "h", "hour", "h", "minutes", "m", "minute", "m", "mins", "m", "min", "m").Replace(timeout))
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		// support "1 h" as well as "1h"
+		if i+1 < len(fields) && (fields[i+1] == "h" || fields[i+1] == "m") {
+			field += fields[i+1]
+			i++
+		}
+		var value int
+		var unit string
+		if n, err := fmt.Sscanf(field, "%d%s", &value, &unit); err != nil || n != 2 {
+			return 0, false
+		}
+		switch unit {
+		case "h":
+			minutes += value * 60
+		case "m":
+			minutes += value
+		default:
+			return 0, false
+		}
+	}
+	return minutes, minutes > 0
+}
+
+func artifactsStashName(job *gitlabJob) string {
+	return "artifacts-" + job.name
+}
+
+func cacheStashName(cache *gitlabCache) string {
+	key := cache.Key
+	if key == "" {
+		key = "default"
+	}
+	return "cache-" + key
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quote returns a single-quoted Groovy string.
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`).Replace(s) + "'"
+}
+
+// quoteMultiline returns a triple single-quoted Groovy string.
+func quoteMultiline(s string) string {
+	if !strings.Contains(s, "\n") {
+		return quote(s)
+	}
+	return "'''" + strings.NewReplacer(`\`, `\\`, `'''`, `\'\'\'`).Replace(s) + "'''"
+}
+
+type jenkinsfileWriter struct {
+	builder strings.Builder
+	indent  int
+}
+
+func (w *jenkinsfileWriter) line(format string, args ...interface{}) {
+	w.builder.WriteString(strings.Repeat("  ", w.indent))
+	if len(args) > 0 {
+		format = fmt.Sprintf(format, args...)
+	}
+	w.builder.WriteString(format)
+	w.builder.WriteString("\n")
+}
+
+func (w *jenkinsfileWriter) String() string {
+	return w.builder.String()
+}
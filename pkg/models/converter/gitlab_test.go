@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestGitLabCIConverter_Convert(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantErr      bool
+		wantWarnings []string
+		verify       func(t *testing.T, jenkinsfile string)
+	}{{
+		name:    "not a map",
+		content: "- a\n- b",
+		wantErr: true,
+	}, {
+		name:    "invalid YAML",
+		content: "stages: [",
+		wantErr: true,
+	}, {
+		name:    "no jobs",
+		content: "stages: [build]",
+		wantErr: true,
+	}, {
+		name:    "unknown template",
+		content: "build:\n  extends: .missing\n  script: make",
+		wantErr: true,
+	}, {
+		name: "single job without image",
+		content: `
+build:
+  stage: build
+  script: make build
+`,
+		verify: func(t *testing.T, jenkinsfile string) {
+			assert.Equal(t, `pipeline {
+  agent none
+  stages {
+    stage('build') {
+      agent {
+        node {
+          label 'base'
+        }
+      }
+      steps {
+        container('base') {
+          sh 'make build'
+        }
+      }
+    }
+  }
+}
+`, jenkinsfile)
+		},
+	}, {
+		name: "stages, parallel jobs, cache and artifacts",
+		content: `
+stages:
+  - build
+  - test
+variables:
+  GO111MODULE: "on"
+image: golang:1.17
+cache:
+  key: go-mod
+  paths:
+    - .cache/
+.common:
+  before_script:
+    - go env
+build:
+  extends: .common
+  stage: build
+  script:
+    - go build -o bin/app ./...
+  artifacts:
+    paths:
+      - bin/
+unit-test:
+  stage: test
+  script: go test ./...
+  allow_failure: true
+lint:
+  stage: test
+  image:
+    name: golangci/golangci-lint
+  script: golangci-lint run
+  only:
+    - main
+    - /^release-.*$/
+`,
+		verify: func(t *testing.T, jenkinsfile string) {
+			assert.Equal(t, `pipeline {
+  agent none
+  environment {
+    GO111MODULE = 'on'
+  }
+  stages {
+    stage('build') {
+      agent {
+        kubernetes {
+          inheritFrom 'base'
+          containerTemplate {
+            name 'gitlab-job'
+            image 'golang:1.17'
+            command 'cat'
+            ttyEnabled true
+          }
+        }
+      }
+      steps {
+        container('gitlab-job') {
+          sh '''go env
+go build -o bin/app ./...'''
+        }
+        stash name: 'cache-go-mod', includes: '.cache/', allowEmpty: true
+      }
+      post {
+        success {
+          archiveArtifacts artifacts: 'bin/', allowEmptyArchive: true
+          stash name: 'artifacts-build', includes: 'bin/', allowEmpty: true
+        }
+      }
+    }
+    stage('test') {
+      parallel {
+        stage('unit-test') {
+          agent {
+            kubernetes {
+              inheritFrom 'base'
+              containerTemplate {
+                name 'gitlab-job'
+                image 'golang:1.17'
+                command 'cat'
+                ttyEnabled true
+              }
+            }
+          }
+          steps {
+            unstash 'artifacts-build'
+            unstash 'cache-go-mod'
+            container('gitlab-job') {
+              catchError(buildResult: 'SUCCESS', stageResult: 'FAILURE') {
+                sh 'go test ./...'
+              }
+            }
+            stash name: 'cache-go-mod', includes: '.cache/', allowEmpty: true
+          }
+        }
+        stage('lint') {
+          agent {
+            kubernetes {
+              inheritFrom 'base'
+              containerTemplate {
+                name 'gitlab-job'
+                image 'golangci/golangci-lint'
+                command 'cat'
+                ttyEnabled true
+              }
+            }
+          }
+          when {
+            anyOf { branch 'main'; branch pattern: '^release-.*$', comparator: 'REGEXP' }
+          }
+          steps {
+            unstash 'artifacts-build'
+            unstash 'cache-go-mod'
+            container('gitlab-job') {
+              sh 'golangci-lint run'
+            }
+            stash name: 'cache-go-mod', includes: '.cache/', allowEmpty: true
+          }
+        }
+      }
+    }
+  }
+}
+`, jenkinsfile)
+		},
+	}, {
+		name: "manual job, after_script, timeout and unsupported keywords",
+		content: `
+include: other.yml
+deploy:
+  stage: deploy
+  tags: [docker]
+  when: manual
+  timeout: 1h 30m
+  variables:
+    ENV: 'it''s prod'
+  script: ./deploy.sh
+  after_script: ./cleanup.sh
+  rules:
+    - if: $CI_COMMIT_TAG
+  except:
+    refs: [tags]
+orphan:
+  stage: missing
+  script: echo
+`,
+		wantWarnings: []string{
+			"keyword 'include' is not supported and was ignored",
+			"job 'deploy': 'rules' is not supported and was ignored",
+			"job 'orphan' refers to an undefined stage 'missing' and was ignored",
+		},
+		verify: func(t *testing.T, jenkinsfile string) {
+			assert.Equal(t, `pipeline {
+  agent none
+  stages {
+    stage('deploy') {
+      agent {
+        node {
+          label 'docker'
+        }
+      }
+      when {
+        not { buildingTag() }
+      }
+      options {
+        timeout(time: 90, unit: 'MINUTES')
+      }
+      environment {
+        ENV = 'it\'s prod'
+      }
+      steps {
+        input(message: 'Run job deploy?')
+        container('base') {
+          sh './deploy.sh'
+        }
+      }
+      post {
+        always {
+          container('base') {
+            sh './cleanup.sh'
+          }
+        }
+      }
+    }
+  }
+}
+`, jenkinsfile)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &GitLabCIConverter{}
+			result, err := converter.Convert("demo", tt.content)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "demo", result.Pipeline.Name)
+			assert.Equal(t, v1alpha3.NoScmPipelineType, result.Pipeline.Spec.Type)
+			assert.Equal(t, tt.wantWarnings, result.Warnings)
+			if tt.verify != nil {
+				tt.verify(t, result.Pipeline.Spec.Pipeline.Jenkinsfile)
+			}
+		})
+	}
+}
+
+func TestParseTimeoutMinutes(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    int
+		wantOK  bool
+	}{
+		{timeout: "30m", want: 30, wantOK: true},
+		{timeout: "1h 30m", want: 90, wantOK: true},
+		{timeout: "2 hours", want: 120, wantOK: true},
+		{timeout: "45 minutes", want: 45, wantOK: true},
+		{timeout: "3 days", wantOK: false},
+		{timeout: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timeout, func(t *testing.T) {
+			got, ok := parseTimeoutMinutes(tt.timeout)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
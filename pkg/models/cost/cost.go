@@ -0,0 +1,316 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapKeyPriceTable is the key of the price table in the ConfigMap
+	ConfigMapKeyPriceTable = "prices.yaml"
+	// DefaultCurrency is the currency of the price table if it's not specified
+	DefaultCurrency = "USD"
+
+	bytesPerGiB = 1 << 30
+)
+
+// Price is the price of the resources per hour
+type Price struct {
+	CPUCoreHour   float64 `json:"cpuCoreHour"`
+	MemoryGiBHour float64 `json:"memoryGiBHour"`
+}
+
+// PriceRule is the price of the agent pods which run on the nodes matching the selector
+type PriceRule struct {
+	NodeSelector map[string]string `json:"nodeSelector"`
+	Price        `json:",inline"`
+}
+
+// PriceTable decides the price of an agent pod by the labels of its node, the first matched rule wins
+type PriceTable struct {
+	Currency string      `json:"currency,omitempty"`
+	Default  Price       `json:"default"`
+	Rules    []PriceRule `json:"rules,omitempty"`
+}
+
+// ParsePriceTable parses a price table in YAML or JSON
+func ParsePriceTable(data []byte) (table *PriceTable, err error) {
+	table = &PriceTable{}
+	if err = yaml.Unmarshal(data, table); err != nil {
+		return nil, fmt.Errorf("invalid price table: %v", err)
+	}
+	if table.Currency == "" {
+		table.Currency = DefaultCurrency
+	}
+	for _, price := range append([]Price{table.Default}, rulePrices(table.Rules)...) {
+		if price.CPUCoreHour < 0 || price.MemoryGiBHour < 0 {
+			return nil, fmt.Errorf("invalid price table: the price cannot be negative")
+		}
+	}
+	return
+}
+
+func rulePrices(rules []PriceRule) (prices []Price) {
+	for _, rule := range rules {
+		prices = append(prices, rule.Price)
+	}
+	return
+}
+
+// PriceOf returns the price of the node which has the labels
+func (t *PriceTable) PriceOf(nodeLabels map[string]string) Price {
+	for _, rule := range t.Rules {
+		if len(rule.NodeSelector) > 0 && labels.SelectorFromSet(rule.NodeSelector).Matches(labels.Set(nodeLabels)) {
+			return rule.Price
+		}
+	}
+	return t.Default
+}
+
+// Usage is the resources used by an agent pod
+type Usage struct {
+	Pod       string  `json:"pod"`
+	Node      string  `json:"node,omitempty"`
+	CPUCores  float64 `json:"cpuCores"`
+	MemoryGiB float64 `json:"memoryGiB"`
+	Seconds   int64   `json:"seconds"`
+	Cost      float64 `json:"cost"`
+	// Finished indicates that the pod was terminated, the usage doesn't change anymore
+	Finished bool `json:"finished"`
+}
+
+// CPUCoreSeconds returns the CPU cores multiplied by the seconds
+func (u *Usage) CPUCoreSeconds() float64 {
+	return u.CPUCores * float64(u.Seconds)
+}
+
+// MemoryGiBSeconds returns the memory in GiB multiplied by the seconds
+func (u *Usage) MemoryGiBSeconds() float64 {
+	return u.MemoryGiB * float64(u.Seconds)
+}
+
+// PodUsage returns the usage of an agent pod until now. The resources are the requests of the pod, or the limits
+// if a container has no requests. The duration is from the start of the pod to its termination.
+func PodUsage(pod *v1.Pod, price Price, now time.Time) (usage Usage) {
+	usage = Usage{Pod: pod.Name, Node: pod.Spec.NodeName}
+	cpu, memory := podResources(&pod.Spec)
+	usage.CPUCores = float64(cpu.MilliValue()) / 1000
+	usage.MemoryGiB = float64(memory.Value()) / bytesPerGiB
+
+	if pod.Status.StartTime == nil {
+		usage.Finished = isPodTerminated(pod)
+		return
+	}
+	end := now
+	if usage.Finished = isPodTerminated(pod); usage.Finished {
+		if finishedAt := lastTerminatedTime(pod); !finishedAt.IsZero() {
+			end = finishedAt
+		} else if pod.DeletionTimestamp != nil {
+			end = pod.DeletionTimestamp.Time
+		}
+	}
+	if seconds := int64(end.Sub(pod.Status.StartTime.Time).Seconds()); seconds > 0 {
+		usage.Seconds = seconds
+	}
+	usage.Cost = round((usage.CPUCoreSeconds()*price.CPUCoreHour + usage.MemoryGiBSeconds()*price.MemoryGiBHour) / 3600)
+	return
+}
+
+// podResources returns the effective resources of a pod, which is the larger one of the sum of containers and
+// any init container, like the scheduler does
+func podResources(spec *v1.PodSpec) (cpu, memory resource.Quantity) {
+	for _, container := range spec.Containers {
+		cpu.Add(containerResource(container.Resources, v1.ResourceCPU))
+		memory.Add(containerResource(container.Resources, v1.ResourceMemory))
+	}
+	for _, container := range spec.InitContainers {
+		if quantity := containerResource(container.Resources, v1.ResourceCPU); quantity.Cmp(cpu) > 0 {
+			cpu = quantity
+		}
+		if quantity := containerResource(container.Resources, v1.ResourceMemory); quantity.Cmp(memory) > 0 {
+			memory = quantity
+		}
+	}
+	return
+}
+
+func containerResource(resources v1.ResourceRequirements, name v1.ResourceName) resource.Quantity {
+	if quantity, ok := resources.Requests[name]; ok {
+		return quantity
+	}
+	return resources.Limits[name]
+}
+
+func isPodTerminated(pod *v1.Pod) bool {
+	return pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+func lastTerminatedTime(pod *v1.Pod) (finishedAt time.Time) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.FinishedAt.After(finishedAt) {
+			finishedAt = status.State.Terminated.FinishedAt.Time
+		}
+	}
+	return
+}
+
+// round keeps six decimal places to avoid the noise of float numbers
+func round(value float64) float64 {
+	return math.Round(value*1e6) / 1e6
+}
+
+// Cost is the compute cost of a PipelineRun
+type Cost struct {
+	Currency         string  `json:"currency"`
+	Total            float64 `json:"total"`
+	CPUCoreSeconds   float64 `json:"cpuCoreSeconds"`
+	MemoryGiBSeconds float64 `json:"memoryGiBSeconds"`
+	Pods             []Usage `json:"pods"`
+}
+
+// Set puts the usage of a pod into the cost, returns the previous usage of the same pod if it exists
+func (c *Cost) Set(usage Usage) (previous *Usage) {
+	for i := range c.Pods {
+		if c.Pods[i].Pod == usage.Pod {
+			old := c.Pods[i]
+			previous = &old
+			c.Pods[i] = usage
+			break
+		}
+	}
+	if previous == nil {
+		c.Pods = append(c.Pods, usage)
+	}
+	c.Total, c.CPUCoreSeconds, c.MemoryGiBSeconds = 0, 0, 0
+	for i := range c.Pods {
+		c.Total += c.Pods[i].Cost
+		c.CPUCoreSeconds += c.Pods[i].CPUCoreSeconds()
+		c.MemoryGiBSeconds += c.Pods[i].MemoryGiBSeconds()
+	}
+	c.Total, c.CPUCoreSeconds, c.MemoryGiBSeconds = round(c.Total), round(c.CPUCoreSeconds), round(c.MemoryGiBSeconds)
+	return
+}
+
+// GetCost returns the cost of a PipelineRun, it's nil if the PipelineRun has no cost
+func GetCost(pr *v1alpha3.PipelineRun) (cost *Cost, err error) {
+	value := pr.Annotations[v1alpha3.PipelineRunCostAnnoKey]
+	if value == "" {
+		return
+	}
+	cost = &Cost{}
+	if err = json.Unmarshal([]byte(value), cost); err != nil {
+		cost = nil
+	}
+	return
+}
+
+// SetCost stores the cost into the annotations of a PipelineRun
+func SetCost(pr *v1alpha3.PipelineRun, cost *Cost) error {
+	data, err := json.Marshal(cost)
+	if err != nil {
+		return err
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	pr.Annotations[v1alpha3.PipelineRunCostAnnoKey] = string(data)
+	return nil
+}
+
+// Totals are the sum of the cost of PipelineRuns
+type Totals struct {
+	Runs             int     `json:"runs"`
+	Total            float64 `json:"total"`
+	CPUCoreSeconds   float64 `json:"cpuCoreSeconds"`
+	MemoryGiBSeconds float64 `json:"memoryGiBSeconds"`
+}
+
+func (t *Totals) add(cost *Cost) {
+	t.Runs++
+	t.Total = round(t.Total + cost.Total)
+	t.CPUCoreSeconds = round(t.CPUCoreSeconds + cost.CPUCoreSeconds)
+	t.MemoryGiBSeconds = round(t.MemoryGiBSeconds + cost.MemoryGiBSeconds)
+}
+
+// PipelineCost is the cost of the PipelineRuns of a Pipeline
+type PipelineCost struct {
+	Pipeline string `json:"pipeline"`
+	Totals   `json:",inline"`
+}
+
+// Report is the cost of the PipelineRuns in a DevOps project which were created in a period
+type Report struct {
+	Namespace string    `json:"namespace"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Currency  string    `json:"currency,omitempty"`
+	Totals    `json:",inline"`
+	// Pipelines are sorted by the total cost descending
+	Pipelines []PipelineCost `json:"pipelines"`
+}
+
+// Aggregate sums the cost of the PipelineRuns which were created in [start, end)
+func Aggregate(namespace string, pipelineRuns []v1alpha3.PipelineRun, start, end time.Time) *Report {
+	report := &Report{Namespace: namespace, Start: start, End: end, Pipelines: []PipelineCost{}}
+	pipelines := map[string]*PipelineCost{}
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		created := pr.CreationTimestamp.Time
+		if created.Before(start) || !created.Before(end) {
+			continue
+		}
+		cost, err := GetCost(pr)
+		if err != nil || cost == nil {
+			continue
+		}
+		if report.Currency == "" {
+			report.Currency = cost.Currency
+		}
+		pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+		if pipelineName == "" && pr.Spec.PipelineRef != nil {
+			pipelineName = pr.Spec.PipelineRef.Name
+		}
+		pipelineCost, ok := pipelines[pipelineName]
+		if !ok {
+			pipelineCost = &PipelineCost{Pipeline: pipelineName}
+			pipelines[pipelineName] = pipelineCost
+		}
+		pipelineCost.add(cost)
+		report.add(cost)
+	}
+	for _, pipelineCost := range pipelines {
+		report.Pipelines = append(report.Pipelines, *pipelineCost)
+	}
+	sort.Slice(report.Pipelines, func(i, j int) bool {
+		if report.Pipelines[i].Total != report.Pipelines[j].Total {
+			return report.Pipelines[i].Total > report.Pipelines[j].Total
+		}
+		return report.Pipelines[i].Pipeline < report.Pipelines[j].Pipeline
+	})
+	return report
+}
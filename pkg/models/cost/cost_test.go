@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestParsePriceTable(t *testing.T) {
+	table, err := ParsePriceTable([]byte(`
+default:
+  cpuCoreHour: 0.04
+  memoryGiBHour: 0.005
+rules:
+- nodeSelector:
+    node.kubernetes.io/lifecycle: spot
+  cpuCoreHour: 0.01
+  memoryGiBHour: 0.001
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultCurrency, table.Currency)
+	assert.Equal(t, Price{CPUCoreHour: 0.04, MemoryGiBHour: 0.005}, table.PriceOf(nil))
+	assert.Equal(t, Price{CPUCoreHour: 0.01, MemoryGiBHour: 0.001}, table.PriceOf(map[string]string{
+		"node.kubernetes.io/lifecycle": "spot", "kubernetes.io/os": "linux",
+	}))
+	assert.Equal(t, table.Default, table.PriceOf(map[string]string{"node.kubernetes.io/lifecycle": "normal"}))
+
+	_, err = ParsePriceTable([]byte(`{"currency": "CNY", "default": {"cpuCoreHour": -1}}`))
+	assert.NotNil(t, err)
+	_, err = ParsePriceTable([]byte(`default: []`))
+	assert.NotNil(t, err)
+}
+
+func TestPodUsage(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	startTime := metav1.NewTime(start)
+	price := Price{CPUCoreHour: 0.04, MemoryGiBHour: 0.005}
+	container := func(requests, limits v1.ResourceList) v1.Container {
+		return v1.Container{Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{
+				container(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m"), v1.ResourceMemory: resource.MustParse("2Gi")}, nil),
+				container(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("2Gi")}),
+			},
+			InitContainers: []v1.Container{
+				container(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil),
+			},
+		},
+	}
+
+	// not started
+	usage := PodUsage(pod, price, start.Add(time.Hour))
+	assert.Equal(t, Usage{Pod: "agent", Node: "node1", CPUCores: 2, MemoryGiB: 4}, usage)
+
+	// running
+	pod.Status.StartTime = &startTime
+	usage = PodUsage(pod, price, start.Add(30*time.Minute))
+	assert.Equal(t, int64(1800), usage.Seconds)
+	assert.False(t, usage.Finished)
+	assert.Equal(t, 0.05, usage.Cost)
+	assert.Equal(t, float64(3600), usage.CPUCoreSeconds())
+	assert.Equal(t, float64(7200), usage.MemoryGiBSeconds())
+
+	// terminated
+	pod.Status.Phase = v1.PodSucceeded
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: metav1.NewTime(start.Add(time.Hour))}},
+	}}
+	usage = PodUsage(pod, price, start.Add(2*time.Hour))
+	assert.Equal(t, int64(3600), usage.Seconds)
+	assert.True(t, usage.Finished)
+	assert.Equal(t, 0.1, usage.Cost)
+
+	// deleted before the containers terminated
+	deletionTime := metav1.NewTime(start.Add(10 * time.Minute))
+	pod.Status.Phase = v1.PodRunning
+	pod.Status.ContainerStatuses = nil
+	pod.DeletionTimestamp = &deletionTime
+	usage = PodUsage(pod, price, start.Add(2*time.Hour))
+	assert.Equal(t, int64(600), usage.Seconds)
+	assert.True(t, usage.Finished)
+}
+
+func TestCost(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{}
+	runCost, err := GetCost(pr)
+	assert.Nil(t, err)
+	assert.Nil(t, runCost)
+
+	runCost = &Cost{Currency: DefaultCurrency}
+	assert.Nil(t, runCost.Set(Usage{Pod: "a", CPUCores: 1, Seconds: 60, Cost: 0.1}))
+	assert.Nil(t, runCost.Set(Usage{Pod: "b", MemoryGiB: 2, Seconds: 30, Cost: 0.2}))
+	previous := runCost.Set(Usage{Pod: "a", CPUCores: 1, Seconds: 120, Cost: 0.2, Finished: true})
+	assert.Equal(t, &Usage{Pod: "a", CPUCores: 1, Seconds: 60, Cost: 0.1}, previous)
+	assert.Equal(t, 0.4, runCost.Total)
+	assert.Equal(t, float64(120), runCost.CPUCoreSeconds)
+	assert.Equal(t, float64(60), runCost.MemoryGiBSeconds)
+	assert.Len(t, runCost.Pods, 2)
+
+	assert.Nil(t, SetCost(pr, runCost))
+	got, err := GetCost(pr)
+	assert.Nil(t, err)
+	assert.Equal(t, runCost, got)
+
+	pr.Annotations[v1alpha3.PipelineRunCostAnnoKey] = "invalid"
+	got, err = GetCost(pr)
+	assert.NotNil(t, err)
+	assert.Nil(t, got)
+}
+
+func TestAggregate(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	newPipelineRun := func(pipeline string, created time.Time, cost *Cost) v1alpha3.PipelineRun {
+		pr := v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+		if cost != nil {
+			assert.Nil(t, SetCost(&pr, cost))
+		}
+		return pr
+	}
+	runs := []v1alpha3.PipelineRun{
+		newPipelineRun("a", start.Add(time.Hour), &Cost{Currency: "CNY", Total: 1, CPUCoreSeconds: 10}),
+		newPipelineRun("a", start.Add(2*time.Hour), &Cost{Currency: "CNY", Total: 2, CPUCoreSeconds: 20}),
+		newPipelineRun("b", start.Add(3*time.Hour), &Cost{Currency: "CNY", Total: 4, MemoryGiBSeconds: 30}),
+		newPipelineRun("b", start.Add(4*time.Hour), nil),
+		newPipelineRun("b", start.Add(-time.Hour), &Cost{Currency: "CNY", Total: 8}),
+		newPipelineRun("c", start.Add(24*time.Hour), &Cost{Currency: "CNY", Total: 16}),
+	}
+
+	report := Aggregate("ns", runs, start, start.Add(24*time.Hour))
+	assert.Equal(t, "CNY", report.Currency)
+	assert.Equal(t, Totals{Runs: 3, Total: 7, CPUCoreSeconds: 30, MemoryGiBSeconds: 30}, report.Totals)
+	assert.Equal(t, []PipelineCost{
+		{Pipeline: "b", Totals: Totals{Runs: 1, Total: 4, MemoryGiBSeconds: 30}},
+		{Pipeline: "a", Totals: Totals{Runs: 2, Total: 3, CPUCoreSeconds: 30}},
+	}, report.Pipelines)
+
+	report = Aggregate("ns", nil, start, start.Add(time.Hour))
+	assert.Equal(t, 0, report.Runs)
+	assert.Empty(t, report.Pipelines)
+}
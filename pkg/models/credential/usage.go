@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/lint"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReferenceSCM indicates the credential is used to access the SCM of a multi-branch Pipeline
+	ReferenceSCM = "scm"
+	// ReferenceJenkinsfile indicates the credential is referenced in the Jenkinsfile
+	ReferenceJenkinsfile = "jenkinsfile"
+)
+
+// Usage describes the Pipelines and PipelineRuns which reference a credential
+type Usage struct {
+	Credential string `json:"credential"`
+	// Pipelines are the Pipelines which reference the credential
+	Pipelines []PipelineUsage `json:"pipelines"`
+	// PipelineRuns are the unfinished PipelineRuns which reference the credential
+	PipelineRuns []PipelineRunUsage `json:"pipelineRuns"`
+}
+
+// PipelineUsage describes how a Pipeline references a credential
+type PipelineUsage struct {
+	Name string `json:"name"`
+	// References are the places where the credential is referenced, such as scm and jenkinsfile
+	References []string `json:"references"`
+}
+
+// PipelineRunUsage describes a PipelineRun which references a credential
+type PipelineRunUsage struct {
+	Name      string            `json:"name"`
+	Pipeline  string            `json:"pipeline"`
+	Phase     v1alpha3.RunPhase `json:"phase,omitempty"`
+	StartTime *metav1.Time      `json:"startTime,omitempty"`
+}
+
+// GetReferencedCredentials returns the credentials referenced by a Pipeline spec, and where they are referenced.
+// Only the literal credential IDs in the Jenkinsfile could be found.
+func GetReferencedCredentials(spec *v1alpha3.PipelineSpec) map[string][]string {
+	references := map[string][]string{}
+	if spec == nil {
+		return references
+	}
+	addReference := func(credential, reference string) {
+		if credential == "" {
+			return
+		}
+		for _, item := range references[credential] {
+			if item == reference {
+				return
+			}
+		}
+		references[credential] = append(references[credential], reference)
+	}
+
+	if multiBranch := spec.MultiBranchPipeline; multiBranch != nil {
+		if multiBranch.GitSource != nil {
+			addReference(multiBranch.GitSource.CredentialId, ReferenceSCM)
+		}
+		if multiBranch.GitHubSource != nil {
+			addReference(multiBranch.GitHubSource.CredentialId, ReferenceSCM)
+		}
+		if multiBranch.GitlabSource != nil {
+			addReference(multiBranch.GitlabSource.CredentialId, ReferenceSCM)
+		}
+		if multiBranch.BitbucketServerSource != nil {
+			addReference(multiBranch.BitbucketServerSource.CredentialId, ReferenceSCM)
+		}
+		if multiBranch.SvnSource != nil {
+			addReference(multiBranch.SvnSource.CredentialId, ReferenceSCM)
+		}
+		if multiBranch.SingleSvnSource != nil {
+			addReference(multiBranch.SingleSvnSource.CredentialId, ReferenceSCM)
+		}
+	}
+	if spec.Pipeline != nil {
+		for credential := range lint.FindCredentials(spec.Pipeline.Jenkinsfile) {
+			addReference(credential, ReferenceJenkinsfile)
+		}
+	}
+	return references
+}
+
+// FindUsage finds the Pipelines and the unfinished PipelineRuns which reference the credential
+func FindUsage(credential string, pipelines []v1alpha3.Pipeline, pipelineRuns []v1alpha3.PipelineRun) *Usage {
+	usage := &Usage{
+		Credential:   credential,
+		Pipelines:    []PipelineUsage{},
+		PipelineRuns: []PipelineRunUsage{},
+	}
+
+	pipelineSpecs := map[string]*v1alpha3.PipelineSpec{}
+	for i := range pipelines {
+		pipeline := &pipelines[i]
+		pipelineSpecs[pipeline.Name] = &pipeline.Spec
+		if references, ok := GetReferencedCredentials(&pipeline.Spec)[credential]; ok {
+			usage.Pipelines = append(usage.Pipelines, PipelineUsage{
+				Name:       pipeline.Name,
+				References: references,
+			})
+		}
+	}
+
+	for i := range pipelineRuns {
+		pipelineRun := &pipelineRuns[i]
+		if pipelineRun.HasCompleted() {
+			continue
+		}
+		pipelineName := pipelineRun.Labels[v1alpha3.PipelineNameLabelKey]
+		// the Pipeline spec might be changed after the PipelineRun was created
+		spec := pipelineRun.Spec.PipelineSpec
+		if spec == nil {
+			spec = pipelineSpecs[pipelineName]
+		}
+		if _, ok := GetReferencedCredentials(spec)[credential]; ok {
+			usage.PipelineRuns = append(usage.PipelineRuns, PipelineRunUsage{
+				Name:      pipelineRun.Name,
+				Pipeline:  pipelineName,
+				Phase:     pipelineRun.Status.Phase,
+				StartTime: pipelineRun.Status.StartTime,
+			})
+		}
+	}
+
+	sort.Slice(usage.Pipelines, func(i, j int) bool {
+		return usage.Pipelines[i].Name < usage.Pipelines[j].Name
+	})
+	sort.Slice(usage.PipelineRuns, func(i, j int) bool {
+		return usage.PipelineRuns[i].Name < usage.PipelineRuns[j].Name
+	})
+	return usage
+}
+
+// GetUsage returns the Pipelines and the unfinished PipelineRuns which reference the credential in a namespace
+func GetUsage(ctx context.Context, c client.Reader, namespace, credential string) (usage *Usage, err error) {
+	// make sure the credential exists
+	if err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credential}, &v1.Secret{}); err != nil {
+		return
+	}
+	pipelineList := &v1alpha3.PipelineList{}
+	if err = c.List(ctx, pipelineList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	pipelineRunList := &v1alpha3.PipelineRunList{}
+	if err = c.List(ctx, pipelineRunList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	usage = FindUsage(credential, pipelineList.Items, pipelineRunList.Items)
+	return
+}
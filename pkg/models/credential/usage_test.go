@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPipeline(name, scmCredential, jenkinsfile string) v1alpha3.Pipeline {
+	pipeline := v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+	}
+	if scmCredential != "" {
+		pipeline.Spec.Type = v1alpha3.MultiBranchPipelineType
+		pipeline.Spec.MultiBranchPipeline = &v1alpha3.MultiBranchPipeline{
+			SourceType: v1alpha3.SourceTypeGit,
+			GitSource:  &v1alpha3.GitSource{CredentialId: scmCredential},
+		}
+	} else {
+		pipeline.Spec.Type = v1alpha3.NoScmPipelineType
+		pipeline.Spec.Pipeline = &v1alpha3.NoScmPipeline{Jenkinsfile: jenkinsfile}
+	}
+	return pipeline
+}
+
+func newPipelineRun(name, pipeline string, spec *v1alpha3.PipelineSpec, completed bool) v1alpha3.PipelineRun {
+	pipelineRun := v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+		},
+		Spec: v1alpha3.PipelineRunSpec{PipelineSpec: spec},
+	}
+	if completed {
+		now := metav1.Now()
+		pipelineRun.Status.Phase = v1alpha3.Succeeded
+		pipelineRun.Status.CompletionTime = &now
+	} else {
+		pipelineRun.Status.Phase = v1alpha3.Running
+	}
+	return pipelineRun
+}
+
+func TestGetReferencedCredentials(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *v1alpha3.PipelineSpec
+		want map[string][]string
+	}{{
+		name: "nil spec",
+		want: map[string][]string{},
+	}, {
+		name: "multi-branch Pipelines",
+		spec: &v1alpha3.PipelineSpec{MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+			GitHubSource: &v1alpha3.GithubSource{CredentialId: "github"},
+			SvnSource:    &v1alpha3.SvnSource{CredentialId: "svn"},
+		}},
+		want: map[string][]string{"github": {ReferenceSCM}, "svn": {ReferenceSCM}},
+	}, {
+		name: "Jenkinsfile",
+		spec: &v1alpha3.PipelineSpec{Pipeline: &v1alpha3.NoScmPipeline{
+			Jenkinsfile: `pipeline {
+  agent any
+  stages {
+    stage('a') {
+      steps {
+        git credentialsId: 'git', url: 'x'
+        withCredentials([string(credentialsId: 'token', variable: 'TOKEN')]) {
+          sh 'echo'
+        }
+        withCredentials([string(credentialsId: "${ID}", variable: 'TOKEN')]) {
+          sh 'echo'
+        }
+      }
+    }
+  }
+}`,
+		}},
+		want: map[string][]string{"git": {ReferenceJenkinsfile}, "token": {ReferenceJenkinsfile}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetReferencedCredentials(tt.spec))
+		})
+	}
+}
+
+func TestFindUsage(t *testing.T) {
+	pipelines := []v1alpha3.Pipeline{
+		newPipeline("b", "git", ""),
+		newPipeline("a", "", "git credentialsId: 'git', url: 'x'"),
+		newPipeline("c", "", "echo 'hello'"),
+	}
+	pipelineC := newPipeline("c", "", "git credentialsId: 'git', url: 'x'")
+	pipelineRuns := []v1alpha3.PipelineRun{
+		newPipelineRun("b-2", "b", nil, false),
+		newPipelineRun("b-1", "b", nil, true),
+		newPipelineRun("a-1", "a", &pipelines[2].Spec, false),
+		newPipelineRun("c-1", "c", &pipelineC.Spec, false),
+	}
+
+	usage := FindUsage("git", pipelines, pipelineRuns)
+	assert.Equal(t, "git", usage.Credential)
+	assert.Equal(t, []PipelineUsage{
+		{Name: "a", References: []string{ReferenceJenkinsfile}},
+		{Name: "b", References: []string{ReferenceSCM}},
+	}, usage.Pipelines)
+	assert.Equal(t, []PipelineRunUsage{
+		{Name: "b-2", Pipeline: "b", Phase: v1alpha3.Running},
+		{Name: "c-1", Pipeline: "c", Phase: v1alpha3.Running},
+	}, usage.PipelineRuns)
+
+	usage = FindUsage("unused", pipelines, pipelineRuns)
+	assert.Empty(t, usage.Pipelines)
+	assert.Empty(t, usage.PipelineRuns)
+}
+
+func TestGetUsage(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	assert.Nil(t, v1alpha3.AddToScheme(schema))
+
+	pipeline := newPipeline("a", "git", "")
+	pipelineRun := newPipelineRun("a-1", "a", nil, false)
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "git"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+	}, &pipeline, &pipelineRun).Build()
+
+	usage, err := GetUsage(context.TODO(), c, "ns", "git")
+	assert.Nil(t, err)
+	assert.Equal(t, []PipelineUsage{{Name: "a", References: []string{ReferenceSCM}}}, usage.Pipelines)
+	assert.Equal(t, []PipelineRunUsage{{Name: "a-1", Pipeline: "a", Phase: v1alpha3.Running}}, usage.PipelineRuns)
+
+	_, err = GetUsage(context.TODO(), c, "ns", "missing")
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+const (
+	// DefaultGoVersion is the version of Go if go.mod doesn't declare it
+	DefaultGoVersion = "1.17"
+	// DefaultJavaVersion is the version of Java if the build file doesn't declare it
+	DefaultJavaVersion = "11"
+	// DefaultNodeVersion is the version of Node.js if package.json doesn't declare it
+	DefaultNodeVersion = "lts"
+	// DefaultPythonVersion is the version of Python
+	DefaultPythonVersion = "3"
+)
+
+var (
+	goVersionPattern     = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	mavenJavaPattern     = regexp.MustCompile(`<(?:java\.version|maven\.compiler\.release|maven\.compiler\.source)>\s*(?:1\.)?(\d+)`)
+	gradleJavaPattern    = regexp.MustCompile(`sourceCompatibility\s*=\s*['"]?(?:JavaVersion\.VERSION_)?(?:1[._])?(\d+)`)
+	nodeVersionPattern   = regexp.MustCompile(`\d+`)
+	versionParameterKeys = map[string]string{"go": "goVersion", "java": "javaVersion", "nodejs": "nodeVersion",
+		"python": "pythonVersion"}
+)
+
+// Toolchain is a build toolchain of a repository
+type Toolchain struct {
+	// Name is the name of the build tool, such as go, maven, gradle, npm, yarn, pip and docker
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	// File is the file which the toolchain was detected from
+	File    string `json:"file"`
+	Version string `json:"version,omitempty"`
+	// BuilderImage is the recommended image to build the repository
+	BuilderImage string `json:"builderImage"`
+	// CacheKeys are the files whose checksums key the cache of the dependencies, such as go.sum
+	CacheKeys []string `json:"cacheKeys,omitempty"`
+	// CachePaths are the directories of the dependencies in the builder image
+	CachePaths []string `json:"cachePaths,omitempty"`
+}
+
+// Result is the toolchains of a repository
+type Result struct {
+	// Language is the language of the first toolchain, it's empty if there are no toolchains
+	Language   string      `json:"language,omitempty"`
+	Toolchains []Toolchain `json:"toolchains"`
+	// Parameters are the recommended parameters of the templates, such as builderImage and goVersion
+	Parameters map[string]string `json:"parameters"`
+}
+
+// rule detects a toolchain from a file in the root of a repository, the former rules have higher priority
+type rule struct {
+	file string
+	// read tells whether the content of the file is required
+	read   bool
+	detect func(content string, files map[string]bool) Toolchain
+}
+
+var rules = []rule{
+	{file: "go.mod", read: true, detect: detectGo},
+	{file: "pom.xml", read: true, detect: detectMaven},
+	{file: "build.gradle", read: true, detect: detectGradle},
+	{file: "build.gradle.kts", read: true, detect: detectGradle},
+	{file: "package.json", read: true, detect: detectNode},
+	{file: "pyproject.toml", detect: detectPython},
+	{file: "setup.py", detect: detectPython},
+	{file: "requirements.txt", detect: detectPython},
+	{file: "Dockerfile", detect: detectDocker},
+}
+
+// Detect inspects the files in the root of a repository at the ref by the API of the SCM provider. The files are
+// listed if they are nil.
+func Detect(ctx context.Context, c *scm.Client, repo, ref string, files []string) (result *Result, err error) {
+	if files == nil {
+		if files, err = ListFiles(ctx, c, repo, ref); err != nil {
+			return
+		}
+	}
+	return detect(files, func(file string) (string, error) {
+		content, _, err := c.Contents.Find(ctx, repo, file, ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to load %s of %s at %s, error: %v", file, repo, ref, err)
+		}
+		return string(content.Data), nil
+	})
+}
+
+// ListFiles returns the paths of the files in the root of a repository at the ref
+func ListFiles(ctx context.Context, c *scm.Client, repo, ref string) (files []string, err error) {
+	var entries []*scm.FileEntry
+	if entries, _, err = c.Contents.List(ctx, repo, "", ref); err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s at %s, error: %v", repo, ref, err)
+	}
+	files = []string{}
+	for _, entry := range entries {
+		files = append(files, entry.Path)
+	}
+	return
+}
+
+func detect(files []string, read func(file string) (string, error)) (result *Result, err error) {
+	existing := map[string]bool{}
+	for _, file := range files {
+		existing[path.Base(file)] = true
+	}
+
+	result = &Result{Toolchains: []Toolchain{}, Parameters: map[string]string{}}
+	detected := map[string]bool{}
+	for _, r := range rules {
+		if !existing[r.file] {
+			continue
+		}
+		var content string
+		if r.read {
+			if content, err = read(r.file); err != nil {
+				return nil, err
+			}
+		}
+		toolchain := r.detect(content, existing)
+		if detected[toolchain.Name] {
+			continue
+		}
+		detected[toolchain.Name] = true
+		toolchain.File = r.file
+		result.Toolchains = append(result.Toolchains, toolchain)
+
+		if key, ok := versionParameterKeys[toolchain.Language]; ok && result.Parameters[key] == "" {
+			result.Parameters[key] = toolchain.Version
+		}
+		if toolchain.Name == "docker" {
+			result.Parameters["dockerfile"] = r.file
+		}
+	}
+	if len(result.Toolchains) > 0 {
+		primary := result.Toolchains[0]
+		result.Language = primary.Language
+		result.Parameters["builderImage"] = primary.BuilderImage
+		result.Parameters["cacheKeys"] = strings.Join(primary.CacheKeys, ",")
+		result.Parameters["cachePaths"] = strings.Join(primary.CachePaths, ",")
+	}
+	return
+}
+
+func detectGo(content string, files map[string]bool) Toolchain {
+	version := DefaultGoVersion
+	if matches := goVersionPattern.FindStringSubmatch(content); len(matches) > 1 {
+		version = matches[1]
+	}
+	return Toolchain{Name: "go", Language: "go", Version: version,
+		BuilderImage: "golang:" + version,
+		CacheKeys:    firstExisting(files, "go.sum", "go.mod"),
+		CachePaths:   []string{"/go/pkg/mod"},
+	}
+}
+
+func detectMaven(content string, files map[string]bool) Toolchain {
+	version := DefaultJavaVersion
+	if matches := mavenJavaPattern.FindStringSubmatch(content); len(matches) > 1 {
+		version = matches[1]
+	}
+	return Toolchain{Name: "maven", Language: "java", Version: version,
+		BuilderImage: "maven:3-openjdk-" + version,
+		CacheKeys:    []string{"pom.xml"},
+		CachePaths:   []string{"/root/.m2/repository"},
+	}
+}
+
+func detectGradle(content string, files map[string]bool) Toolchain {
+	version := DefaultJavaVersion
+	if matches := gradleJavaPattern.FindStringSubmatch(content); len(matches) > 1 {
+		version = matches[1]
+	}
+	return Toolchain{Name: "gradle", Language: "java", Version: version,
+		BuilderImage: "gradle:jdk" + version,
+		CacheKeys:    firstExisting(files, "gradle.lockfile", "build.gradle", "build.gradle.kts"),
+		CachePaths:   []string{"/home/gradle/.gradle/caches"},
+	}
+}
+
+func detectNode(content string, files map[string]bool) Toolchain {
+	version := DefaultNodeVersion
+	pkg := &struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}{}
+	if json.Unmarshal([]byte(content), pkg) == nil {
+		// the lowest major version of the range, such as 16 of >=16.0.0
+		if major := nodeVersionPattern.FindString(pkg.Engines.Node); major != "" {
+			version = major
+		}
+	}
+	toolchain := Toolchain{Name: "npm", Language: "nodejs", Version: version,
+		BuilderImage: "node:" + version,
+		CacheKeys:    firstExisting(files, "package-lock.json", "package.json"),
+		CachePaths:   []string{"/root/.npm"},
+	}
+	if files["yarn.lock"] {
+		toolchain.Name = "yarn"
+		toolchain.CacheKeys = []string{"yarn.lock"}
+		toolchain.CachePaths = []string{"/usr/local/share/.cache/yarn"}
+	}
+	return toolchain
+}
+
+func detectPython(_ string, files map[string]bool) Toolchain {
+	return Toolchain{Name: "pip", Language: "python", Version: DefaultPythonVersion,
+		BuilderImage: "python:" + DefaultPythonVersion,
+		CacheKeys:    firstExisting(files, "requirements.txt", "poetry.lock", "pyproject.toml", "setup.py"),
+		CachePaths:   []string{"/root/.cache/pip"},
+	}
+}
+
+func detectDocker(_ string, _ map[string]bool) Toolchain {
+	return Toolchain{Name: "docker", Language: "docker",
+		BuilderImage: "docker:20.10",
+		CacheKeys:    []string{"Dockerfile"},
+	}
+}
+
+// firstExisting returns the first file which exists in the repository
+func firstExisting(files map[string]bool, candidates ...string) []string {
+	for _, candidate := range candidates {
+		if files[candidate] {
+			return []string{candidate}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detector
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/app/contents/":
+			_, _ = w.Write([]byte(`[{"name":"go.mod","path":"go.mod","type":"file"},{"name":"go.sum","path":"go.sum","type":"file"},` +
+				`{"name":"Dockerfile","path":"Dockerfile","type":"file"}]`))
+		case "/repos/org/app/contents/go.mod":
+			assert.Equal(t, "main", r.URL.Query().Get("ref"))
+			_, _ = fmt.Fprintf(w, `{"path":"go.mod","content":"%s"}`,
+				base64.StdEncoding.EncodeToString([]byte("module example.com/app\n\ngo 1.18\n")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c, err := github.New(server.URL)
+	assert.Nil(t, err)
+
+	result, err := Detect(context.Background(), c, "org/app", "main", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &Result{
+		Language: "go",
+		Toolchains: []Toolchain{{Name: "go", Language: "go", File: "go.mod", Version: "1.18", BuilderImage: "golang:1.18",
+			CacheKeys: []string{"go.sum"}, CachePaths: []string{"/go/pkg/mod"}},
+			{Name: "docker", Language: "docker", File: "Dockerfile", BuilderImage: "docker:20.10",
+				CacheKeys: []string{"Dockerfile"}}},
+		Parameters: map[string]string{"goVersion": "1.18", "dockerfile": "Dockerfile", "builderImage": "golang:1.18",
+			"cacheKeys": "go.sum", "cachePaths": "/go/pkg/mod"},
+	}, result)
+
+	_, err = Detect(context.Background(), c, "org/fake", "main", nil)
+	assert.NotNil(t, err)
+}
+
+func Test_detect(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		wantToolchain []string
+		wantImage     string
+		wantCacheKeys string
+		wantParameter map[string]string
+	}{{
+		name:          "maven",
+		files:         map[string]string{"pom.xml": "<properties><java.version>1.8</java.version></properties>"},
+		wantToolchain: []string{"maven"},
+		wantImage:     "maven:3-openjdk-8",
+		wantCacheKeys: "pom.xml",
+		wantParameter: map[string]string{"javaVersion": "8"},
+	}, {
+		name: "gradle and maven",
+		files: map[string]string{"pom.xml": "<project/>", "build.gradle": "sourceCompatibility = JavaVersion.VERSION_17",
+			"build.gradle.kts": ""},
+		wantToolchain: []string{"maven", "gradle"},
+		wantImage:     "maven:3-openjdk-11",
+		wantCacheKeys: "pom.xml",
+		wantParameter: map[string]string{"javaVersion": "11"},
+	}, {
+		name:          "yarn",
+		files:         map[string]string{"package.json": `{"engines":{"node":">=16.0.0"}}`, "yarn.lock": ""},
+		wantToolchain: []string{"yarn"},
+		wantImage:     "node:16",
+		wantCacheKeys: "yarn.lock",
+		wantParameter: map[string]string{"nodeVersion": "16"},
+	}, {
+		name:          "npm without engines",
+		files:         map[string]string{"package.json": `{}`, "package-lock.json": ""},
+		wantToolchain: []string{"npm"},
+		wantImage:     "node:lts",
+		wantCacheKeys: "package-lock.json",
+		wantParameter: map[string]string{"nodeVersion": "lts"},
+	}, {
+		name:          "python",
+		files:         map[string]string{"setup.py": "", "requirements.txt": ""},
+		wantToolchain: []string{"pip"},
+		wantImage:     "python:3",
+		wantCacheKeys: "requirements.txt",
+		wantParameter: map[string]string{"pythonVersion": "3"},
+	}, {
+		name:          "unknown",
+		files:         map[string]string{"README.md": ""},
+		wantToolchain: []string{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []string
+			for file := range tt.files {
+				files = append(files, file)
+			}
+			result, err := detect(files, func(file string) (string, error) {
+				return tt.files[file], nil
+			})
+			assert.Nil(t, err)
+			toolchains := []string{}
+			for _, toolchain := range result.Toolchains {
+				toolchains = append(toolchains, toolchain.Name)
+			}
+			assert.Equal(t, tt.wantToolchain, toolchains)
+			assert.Equal(t, tt.wantImage, result.Parameters["builderImage"])
+			assert.Equal(t, tt.wantCacheKeys, result.Parameters["cacheKeys"])
+			for key, value := range tt.wantParameter {
+				assert.Equal(t, value, result.Parameters[key], key)
+			}
+		})
+	}
+
+	_, err := detect([]string{"go.mod"}, func(string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	assert.NotNil(t, err)
+}
@@ -45,6 +45,7 @@ import (
 	"kubesphere.io/devops/pkg/apiserver/query"
 	kubesphere "kubesphere.io/devops/pkg/client/clientset/versioned"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/models/logmask"
 	resourcesV1alpha3 "kubesphere.io/devops/pkg/models/resources/v1alpha3"
 )
 
@@ -558,7 +559,7 @@ func (d devopsOperator) GetRunLog(projectName, pipelineName, runId string, req *
 		return nil, err
 	}
 
-	return res, err
+	return d.maskLog(projectName, pipelineName, res)
 }
 
 func (d devopsOperator) GetStepLog(projectName, pipelineName, runId, nodeId, stepId string, req *http.Request) ([]byte, http.Header, error) {
@@ -569,6 +570,7 @@ func (d devopsOperator) GetStepLog(projectName, pipelineName, runId, nodeId, ste
 		return nil, nil, err
 	}
 
+	resBody, err = d.maskLog(projectName, pipelineName, resBody)
 	return resBody, header, err
 }
 
@@ -730,7 +732,7 @@ func (d devopsOperator) GetBranchRunLog(projectName, pipelineName, branchName, r
 		return nil, err
 	}
 
-	return res, err
+	return d.maskLog(projectName, pipelineName, res)
 }
 
 func (d devopsOperator) GetBranchStepLog(projectName, pipelineName, branchName, runId, nodeId, stepId string, req *http.Request) ([]byte, http.Header, error) {
@@ -741,6 +743,7 @@ func (d devopsOperator) GetBranchStepLog(projectName, pipelineName, branchName,
 		return nil, nil, err
 	}
 
+	resBody, err = d.maskLog(projectName, pipelineName, resBody)
 	return resBody, header, err
 }
 
@@ -858,7 +861,30 @@ func (d devopsOperator) GetConsoleLog(projectName, pipelineName string, req *htt
 		return nil, err
 	}
 
-	return resBody, err
+	return d.maskLog(projectName, pipelineName, resBody)
+}
+
+// maskLog masks the credentials referenced by the Pipeline and the well-known secrets in the log
+func (d devopsOperator) maskLog(projectName, pipelineName string, log []byte) ([]byte, error) {
+	if d.ksclient == nil || d.k8sclient == nil {
+		return logmask.New(nil, logmask.DefaultPatterns...).Mask(log), nil
+	}
+
+	var spec *v1alpha3.PipelineSpec
+	pipeline, err := d.ksclient.DevopsV1alpha3().Pipelines(projectName).Get(d.context, pipelineName, metav1.GetOptions{})
+	if err == nil {
+		spec = &pipeline.Spec
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	masker, err := logmask.ForPipeline(spec, func(name string) (*v1.Secret, error) {
+		return d.k8sclient.CoreV1().Secrets(projectName).Get(d.context, name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return masker.Mask(log), nil
 }
 
 func (d devopsOperator) GetCrumb(req *http.Request) (*devops.Crumb, error) {
@@ -74,7 +74,8 @@ func Test_projectCredentialGetter_GetProjectCredentialUsage(t *testing.T) {
 			credentialID: "credentialID",
 		},
 		want: &devops.Credential{
-			Id: "credentialID",
+			Id:     "credentialID",
+			Domain: "_",
 		},
 		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 			assert.Nil(t, err)
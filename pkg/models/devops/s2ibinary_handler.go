@@ -28,11 +28,15 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	awsS3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"kubesphere.io/devops/pkg/api/devops/v1alpha1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/artifactrouting"
 
 	"kubesphere.io/devops/pkg/client/clientset/versioned"
 	"kubesphere.io/devops/pkg/client/informers/externalversions"
@@ -108,7 +112,24 @@ func (s *s2iBinaryUploader) UploadS2iBinary(namespace, name, md5 string, fileHea
 	copy.Spec.FileName = fileHeader.Filename
 	copy.Spec.DownloadURL = fmt.Sprintf(GetS2iBinaryURL, namespace, name, copy.Spec.FileName)
 
-	err = s.s3Client.Upload(fmt.Sprintf("%s-%s", namespace, name), copy.Spec.FileName, binFile)
+	// the binary is encrypted by the KMS key of the DevOpsProject through the storage of the route
+	var storage s3.Interface
+	route, err := s.getRoute(namespace)
+	if err == nil {
+		storage, err = route.Storage(s.s3Client)
+	}
+	if err != nil {
+		klog.Error(err)
+		_, serr := s.SetS2iBinaryStatusWithRetry(copy, origin.Status.Phase)
+		if serr != nil {
+			klog.Error(serr)
+		}
+		return nil, err
+	}
+	// record where the binary is stored, so it's still downloadable after the routing policies changed
+	objectKey := route.Key(fmt.Sprintf("%s-%s", namespace, name))
+	setS2iBinaryLocation(copy, route.Bucket, objectKey)
+	err = storage.Upload(objectKey, copy.Spec.FileName, binFile)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -150,6 +171,69 @@ func (s *s2iBinaryUploader) UploadS2iBinary(namespace, name, md5 string, fileHea
 	return copy, nil
 }
 
+// getRoute returns where the binaries of a namespace are stored by the ArtifactRoutingPolicies, and the KMS key of
+// the DevOpsProject which owns the namespace
+func (s *s2iBinaryUploader) getRoute(namespace string) (route artifactrouting.Route, err error) {
+	var project *v1alpha3.DevOpsProject
+	if project, err = s.getProject(namespace); err != nil || project == nil {
+		return
+	}
+	var policies *v1alpha3.ArtifactRoutingPolicyList
+	if policies, err = s.client.DevopsV1alpha3().ArtifactRoutingPolicies().List(context.Background(),
+		metav1.ListOptions{}); err != nil {
+		return
+	}
+	route = artifactrouting.Match(project, policies.Items)
+	return
+}
+
+// getProject returns the DevOpsProject which owns the namespace, it's nil if there is no such one
+func (s *s2iBinaryUploader) getProject(namespace string) (project *v1alpha3.DevOpsProject, err error) {
+	if s.k8sClient == nil {
+		return
+	}
+	ns, err := s.k8sClient.Kubernetes().CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return
+	}
+	if project, err = s.client.DevopsV1alpha3().DevOpsProjects().Get(context.Background(), projectName,
+		metav1.GetOptions{}); err != nil {
+		project = nil
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+	}
+	return
+}
+
+// setS2iBinaryLocation records the bucket and the object key of a binary, the default ones are not recorded
+func setS2iBinaryLocation(s2ibin *v1alpha1.S2iBinary, bucket, objectKey string) {
+	if s2ibin.Annotations == nil {
+		s2ibin.Annotations = map[string]string{}
+	}
+	delete(s2ibin.Annotations, v1alpha1.S2iBinaryBucketAnnoKey)
+	delete(s2ibin.Annotations, v1alpha1.S2iBinaryObjectKeyAnnoKey)
+	if bucket != "" {
+		s2ibin.Annotations[v1alpha1.S2iBinaryBucketAnnoKey] = bucket
+	}
+	if objectKey != fmt.Sprintf("%s-%s", s2ibin.Namespace, s2ibin.Name) {
+		s2ibin.Annotations[v1alpha1.S2iBinaryObjectKeyAnnoKey] = objectKey
+	}
+}
+
+// getS2iBinaryLocation returns the bucket and the object key of a binary
+func getS2iBinaryLocation(s2ibin *v1alpha1.S2iBinary) (bucket, objectKey string) {
+	bucket = s2ibin.Annotations[v1alpha1.S2iBinaryBucketAnnoKey]
+	if objectKey = s2ibin.Annotations[v1alpha1.S2iBinaryObjectKeyAnnoKey]; objectKey == "" {
+		objectKey = fmt.Sprintf("%s-%s", s2ibin.Namespace, s2ibin.Name)
+	}
+	return
+}
+
 func (s *s2iBinaryUploader) DownloadS2iBinary(namespace, name, fileName string) (string, error) {
 
 	origin, err := s.informers.Devops().V1alpha1().S2iBinaries().Lister().S2iBinaries(namespace).Get(name)
@@ -169,7 +253,13 @@ func (s *s2iBinaryUploader) DownloadS2iBinary(namespace, name, fileName string)
 		klog.Error(err)
 		return "", err
 	}
-	return s.s3Client.GetDownloadURL(fmt.Sprintf("%s-%s", namespace, name), fileName)
+	bucket, objectKey := getS2iBinaryLocation(origin)
+	storage, err := s3.InBucket(s.s3Client, bucket)
+	if err != nil {
+		klog.Error(err)
+		return "", err
+	}
+	return storage.GetDownloadURL(objectKey, fileName)
 }
 
 func (s *s2iBinaryUploader) SetS2iBinaryStatus(s2ibin *v1alpha1.S2iBinary, status string) (*v1alpha1.S2iBinary, error) {
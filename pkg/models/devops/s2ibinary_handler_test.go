@@ -19,6 +19,19 @@ package devops
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/clientset/versioned/fake"
+	"kubesphere.io/devops/pkg/client/informers/externalversions"
+	"kubesphere.io/devops/pkg/client/k8s"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/artifactrouting"
 )
 
 //
@@ -142,3 +155,143 @@ func TestNewS2iBinaryUploader(t *testing.T) {
 	uploader := NewS2iBinaryUploader(nil, nil, nil, nil)
 	assert.NotNil(t, uploader)
 }
+
+func TestGetRouteWithKMSKey(t *testing.T) {
+	namespace := func(name, project string) *v1.Namespace {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if project != "" {
+			ns.Labels = map[string]string{constants.DevOpsProjectLabelKey: project}
+		}
+		return ns
+	}
+	project := func(name, kmsKeyID string) *v1alpha3.DevOpsProject {
+		project := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if kmsKeyID != "" {
+			project.Spec.StorageEncryption = &v1alpha3.StorageEncryption{KMSKeyID: kmsKeyID}
+		}
+		return project
+	}
+
+	tests := []struct {
+		name           string
+		namespace      string
+		k8sObjects     []runtime.Object
+		objects        []runtime.Object
+		expectKMSKeyID string
+		expectErr      bool
+	}{{
+		name:       "namespace not found",
+		namespace:  "demo",
+		k8sObjects: []runtime.Object{},
+		expectErr:  true,
+	}, {
+		name:       "namespace without DevOpsProject",
+		namespace:  "demo",
+		k8sObjects: []runtime.Object{namespace("demo", "")},
+	}, {
+		name:       "DevOpsProject not found",
+		namespace:  "demo",
+		k8sObjects: []runtime.Object{namespace("demo", "demo-project")},
+	}, {
+		name:       "DevOpsProject without encryption",
+		namespace:  "demo",
+		k8sObjects: []runtime.Object{namespace("demo", "demo-project")},
+		objects:    []runtime.Object{project("demo-project", "")},
+	}, {
+		name:           "DevOpsProject with a KMS key",
+		namespace:      "demo",
+		k8sObjects:     []runtime.Object{namespace("demo", "demo-project")},
+		objects:        []runtime.Object{project("demo-project", "arn:aws:kms:us-east-1:111122223333:key/demo")},
+		expectKMSKeyID: "arn:aws:kms:us-east-1:111122223333:key/demo",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := k8s.NewFakeClientSets(fakek8s.NewSimpleClientset(tt.k8sObjects...), nil, nil, "", nil, nil)
+			uploader := &s2iBinaryUploader{k8sClient: k8sClient, client: fake.NewSimpleClientset(tt.objects...)}
+
+			route, err := uploader.getRoute(tt.namespace)
+			assert.Equal(t, tt.expectErr, err != nil, err)
+			assert.Equal(t, tt.expectKMSKeyID, route.KMSKeyID)
+
+			// the binaries are encrypted by the storage of the route
+			storage := fakes3.NewFakeS3()
+			routed, err := route.Storage(storage)
+			assert.Nil(t, err)
+			assert.Nil(t, routed.Upload("key", "app.jar", nil))
+			assert.Equal(t, tt.expectKMSKeyID, storage.Storage["key"].KMSKeyID)
+		})
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo",
+		Labels: map[string]string{constants.DevOpsProjectLabelKey: "demo-project"},
+	}}
+	project := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo-project",
+		Labels: map[string]string{constants.WorkspaceLabelKey: "tenant-a"},
+	}}
+	policy := &v1alpha3.ArtifactRoutingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+		Spec: v1alpha3.ArtifactRoutingPolicySpec{
+			Workspaces: []string{"tenant-a"},
+			Bucket:     "tenant-a",
+			Prefix:     "s2i/",
+		},
+	}
+
+	k8sClient := k8s.NewFakeClientSets(fakek8s.NewSimpleClientset(ns), nil, nil, "", nil, nil)
+	uploader := &s2iBinaryUploader{k8sClient: k8sClient, client: fake.NewSimpleClientset(project, policy)}
+	route, err := uploader.getRoute("demo")
+	assert.Nil(t, err)
+	assert.Equal(t, artifactrouting.Route{Policy: "tenant-a", Bucket: "tenant-a", Prefix: "s2i/"}, route)
+
+	uploader = &s2iBinaryUploader{k8sClient: k8sClient, client: fake.NewSimpleClientset(project)}
+	route, err = uploader.getRoute("demo")
+	assert.Nil(t, err)
+	assert.Equal(t, artifactrouting.Route{}, route)
+
+	route, err = (&s2iBinaryUploader{}).getRoute("demo")
+	assert.Nil(t, err)
+	assert.Equal(t, artifactrouting.Route{}, route)
+}
+
+func TestDownloadS2iBinary(t *testing.T) {
+	newS2iBinary := func(name string, annotations map[string]string) *v1alpha1.S2iBinary {
+		return &v1alpha1.S2iBinary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: name, Annotations: annotations},
+			Spec:       v1alpha1.S2iBinarySpec{FileName: "app.jar"},
+			Status:     v1alpha1.S2iBinaryStatus{Phase: v1alpha1.StatusReady},
+		}
+	}
+	legacy := newS2iBinary("legacy", nil)
+	routed := newS2iBinary("routed", nil)
+	setS2iBinaryLocation(routed, "tenant-a", "s2i/demo-routed")
+	assert.Equal(t, map[string]string{
+		v1alpha1.S2iBinaryBucketAnnoKey:    "tenant-a",
+		v1alpha1.S2iBinaryObjectKeyAnnoKey: "s2i/demo-routed",
+	}, routed.Annotations)
+
+	informers := externalversions.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := informers.Devops().V1alpha1().S2iBinaries().Informer().GetIndexer()
+	assert.Nil(t, indexer.Add(legacy))
+	assert.Nil(t, indexer.Add(routed))
+
+	storage := fakes3.NewFakeS3()
+	assert.Nil(t, storage.Upload("demo-legacy", "app.jar", nil))
+	assert.Nil(t, storage.InBucket("tenant-a").Upload("s2i/demo-routed", "app.jar", nil))
+	uploader := &s2iBinaryUploader{informers: informers, s3Client: storage}
+
+	url, err := uploader.DownloadS2iBinary("demo", "legacy", "app.jar")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://demo-legacy/app.jar", url)
+
+	url, err = uploader.DownloadS2iBinary("demo", "routed", "app.jar")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://s2i/demo-routed/app.jar", url)
+
+	// the default location is not recorded
+	setS2iBinaryLocation(routed, "", "demo-routed")
+	assert.Empty(t, routed.Annotations)
+}
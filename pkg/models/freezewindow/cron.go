@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freezewindow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed cron expression with five fields: minute hour day-of-month month day-of-week
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar follow the cron rule: if both of them are restricted, either of them matches
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max uint
+}
+
+var fieldBounds = []bounds{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+func parseSchedule(expr string) (*schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(fieldBounds) {
+		return nil, fmt.Errorf("expected %d fields in cron expression %q, got %d", len(fieldBounds), expr, len(fields))
+	}
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseField(field, fieldBounds[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+		}
+	}
+	// both 0 and 7 are Sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &schedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"), dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseField parses a field like "*", "*/5", "1-10/2" or "1,3,5" into bits
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, item := range strings.Split(field, ",") {
+		rangeAndStep := strings.SplitN(item, "/", 2)
+		start, end, step := b.min, b.max, uint(1)
+		if rangeAndStep[0] != "*" {
+			startAndEnd := strings.SplitN(rangeAndStep[0], "-", 2)
+			if start, err = parseNumber(startAndEnd[0], b); err != nil {
+				return
+			}
+			end = start
+			if len(startAndEnd) == 2 {
+				if end, err = parseNumber(startAndEnd[1], b); err != nil {
+					return
+				}
+			}
+		}
+		if len(rangeAndStep) == 2 {
+			var n int
+			if n, err = strconv.Atoi(rangeAndStep[1]); err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", rangeAndStep[1])
+			}
+			step = uint(n)
+			if rangeAndStep[0] != "*" && !strings.Contains(rangeAndStep[0], "-") {
+				end = b.max
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", item)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return
+}
+
+func parseNumber(value string, b bounds) (uint, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < int(b.min) || n > int(b.max) {
+		return 0, fmt.Errorf("%q is not in range %d-%d", value, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+// matches returns true if the given time matches the schedule, the seconds are ignored
+func (s *schedule) matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 || s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatched := s.dom&(1<<uint(t.Day())) != 0
+	dowMatched := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatched && dowMatched
+	}
+	return domMatched || dowMatched
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freezewindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxDuration is the maximum duration of a recurring period
+const MaxDuration = 31 * 24 * time.Hour
+
+const (
+	queuedReason   = "Queued"
+	rejectedReason = "Rejected"
+	endedReason    = "Ended"
+)
+
+// Window is an active freeze window
+type Window struct {
+	// Name is the name of FreezeWindow or ClusterFreezeWindow, the latter has a "cluster/" prefix
+	Name    string
+	Policy  v1alpha3.FreezePolicy
+	Message string
+	// End is when the current period ends
+	End time.Time
+}
+
+// ActiveUntil returns the end of the current period if the spec is active at the given time
+func ActiveUntil(spec *v1alpha3.FreezeWindowSpec, now time.Time) (end time.Time, active bool, err error) {
+	for _, freezeRange := range spec.Ranges {
+		if !now.Before(freezeRange.Start.Time) && now.Before(freezeRange.End.Time) && freezeRange.End.After(end) {
+			end, active = freezeRange.End.Time, true
+		}
+	}
+
+	if spec.Schedule == "" {
+		return
+	}
+	if spec.Duration == nil || spec.Duration.Duration <= 0 || spec.Duration.Duration > MaxDuration {
+		err = fmt.Errorf("the duration of schedule must be in (0, %v]", MaxDuration)
+		return
+	}
+	location := time.UTC
+	if spec.TimeZone != "" {
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return
+		}
+	}
+	var s *schedule
+	if s, err = parseSchedule(spec.Schedule); err != nil {
+		return
+	}
+
+	// find the latest start of the periods which covers the given time
+	localNow := now.In(location)
+	for start := localNow.Truncate(time.Minute); localNow.Sub(start) < spec.Duration.Duration; start = start.Add(-time.Minute) {
+		if s.matches(start) {
+			if periodEnd := start.Add(spec.Duration.Duration); periodEnd.After(end) {
+				end, active = periodEnd, true
+			}
+			break
+		}
+	}
+	return
+}
+
+// Find returns the active freeze window of the Pipeline at the given time, or nil if the Pipeline is not frozen.
+// A rejecting window takes precedence over the queuing ones, otherwise the one which ends last is returned.
+// The windows with an invalid spec are ignored.
+func Find(ctx context.Context, c client.Reader, pipeline *v1alpha3.Pipeline, now time.Time) (window *Window, err error) {
+	windowList := &v1alpha3.FreezeWindowList{}
+	if err = c.List(ctx, windowList, client.InNamespace(pipeline.Namespace)); err != nil {
+		return
+	}
+	clusterWindowList := &v1alpha3.ClusterFreezeWindowList{}
+	if err = c.List(ctx, clusterWindowList); err != nil {
+		return
+	}
+
+	candidates := make(map[string]*v1alpha3.FreezeWindowSpec)
+	for i := range windowList.Items {
+		candidates[windowList.Items[i].Name] = &windowList.Items[i].Spec
+	}
+	for i := range clusterWindowList.Items {
+		candidates["cluster/"+clusterWindowList.Items[i].Name] = &clusterWindowList.Items[i].Spec
+	}
+
+	for name, spec := range candidates {
+		if !selects(spec, pipeline) {
+			continue
+		}
+		end, active, specErr := ActiveUntil(spec, now)
+		if specErr != nil || !active {
+			continue
+		}
+		candidate := &Window{Name: name, Policy: spec.GetPolicy(), Message: spec.Message, End: end}
+		if window == nil || precedes(candidate, window) {
+			window = candidate
+		}
+	}
+	return
+}
+
+func selects(spec *v1alpha3.FreezeWindowSpec, pipeline *v1alpha3.Pipeline) bool {
+	if spec.PipelineSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.PipelineSelector)
+	return err == nil && selector.Matches(labels.Set(pipeline.Labels))
+}
+
+func precedes(a, b *Window) bool {
+	if a.Policy != b.Policy {
+		return a.Policy == v1alpha3.FreezePolicyReject
+	}
+	if !a.End.Equal(b.End) {
+		return a.End.After(b.End)
+	}
+	return a.Name < b.Name
+}
+
+// Hold applies the freeze window to the status of a PipelineRun which has not been triggered.
+// The queued PipelineRun is pending until the window ends, and the rejected one is cancelled.
+// Returns true if the status was changed.
+func Hold(status *v1alpha3.PipelineRunStatus, window *Window, now time.Time) bool {
+	metaNow := metav1.NewTime(now)
+	message := fmt.Sprintf("frozen by %s until %s", window.Name, window.End.UTC().Format(time.RFC3339))
+	if window.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, window.Message)
+	}
+
+	if window.Policy == v1alpha3.FreezePolicyReject {
+		status.Phase = v1alpha3.Cancelled
+		status.CompletionTime = &metaNow
+		status.UpdateTime = &metaNow
+		status.AddCondition(&v1alpha3.Condition{
+			Type:          v1alpha3.ConditionSucceeded,
+			Status:        v1alpha3.ConditionFalse,
+			Reason:        v1alpha3.Frozen,
+			Message:       message,
+			LastProbeTime: metaNow,
+		})
+		status.AddCondition(&v1alpha3.Condition{
+			Type:          v1alpha3.ConditionFrozen,
+			Status:        v1alpha3.ConditionTrue,
+			Reason:        rejectedReason,
+			Message:       message,
+			LastProbeTime: metaNow,
+		})
+		return true
+	}
+
+	if frozen := getCondition(status, v1alpha3.ConditionFrozen); frozen != nil &&
+		frozen.Status == v1alpha3.ConditionTrue && frozen.Message == message {
+		return false
+	}
+	status.Phase = v1alpha3.Pending
+	status.UpdateTime = &metaNow
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionFrozen,
+		Status:        v1alpha3.ConditionTrue,
+		Reason:        queuedReason,
+		Message:       message,
+		LastProbeTime: metaNow,
+	})
+	return true
+}
+
+// Thaw marks the Frozen condition as false once the queued PipelineRun is triggered
+func Thaw(status *v1alpha3.PipelineRunStatus, now time.Time) {
+	if frozen := getCondition(status, v1alpha3.ConditionFrozen); frozen == nil || frozen.Status != v1alpha3.ConditionTrue {
+		return
+	}
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionFrozen,
+		Status:        v1alpha3.ConditionFalse,
+		Reason:        endedReason,
+		Message:       "the freeze window has ended",
+		LastProbeTime: metav1.NewTime(now),
+	})
+}
+
+func getCondition(status *v1alpha3.PipelineRunStatus, conditionType v1alpha3.ConditionType) *v1alpha3.Condition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freezewindow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// 2022-07-01 is a Friday
+	friday := time.Date(2022, 7, 1, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr    string
+		wantErr bool
+		matches bool
+	}{
+		{expr: "* * * * *", matches: true},
+		{expr: "30 18 * * 5", matches: true},
+		{expr: "*/15 18 * * 1-5", matches: true},
+		{expr: "0 18 * * 1-5", matches: false},
+		{expr: "30 18 1 * 0", matches: true},
+		{expr: "30 18 2 * 5", matches: true},
+		{expr: "30 18 2 * 0,6", matches: false},
+		{expr: "30 18 * 7 7", matches: false},
+		{expr: "* * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := parseSchedule(tt.expr)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.matches, s.matches(friday))
+		})
+	}
+}
+
+func TestActiveUntil(t *testing.T) {
+	now := time.Date(2022, 7, 1, 18, 30, 0, 0, time.UTC)
+	hours := func(n int) *metav1.Duration {
+		return &metav1.Duration{Duration: time.Duration(n) * time.Hour}
+	}
+	tests := []struct {
+		name       string
+		spec       v1alpha3.FreezeWindowSpec
+		wantEnd    time.Time
+		wantActive bool
+		wantErr    bool
+	}{{
+		name: "empty spec",
+	}, {
+		name: "in an absolute range",
+		spec: v1alpha3.FreezeWindowSpec{Ranges: []v1alpha3.FreezeRange{{
+			Start: metav1.NewTime(now.Add(-time.Hour)), End: metav1.NewTime(now.Add(time.Hour)),
+		}}},
+		wantEnd:    now.Add(time.Hour),
+		wantActive: true,
+	}, {
+		name: "out of the absolute range",
+		spec: v1alpha3.FreezeWindowSpec{Ranges: []v1alpha3.FreezeRange{{
+			Start: metav1.NewTime(now.Add(-2 * time.Hour)), End: metav1.NewTime(now),
+		}}},
+	}, {
+		name:       "weekend freeze from Friday 18:00",
+		spec:       v1alpha3.FreezeWindowSpec{Schedule: "0 18 * * 5", Duration: hours(62)},
+		wantEnd:    time.Date(2022, 7, 4, 8, 0, 0, 0, time.UTC),
+		wantActive: true,
+	}, {
+		name: "schedule in another time zone",
+		// 18:30 UTC is 02:30 in Shanghai
+		spec:       v1alpha3.FreezeWindowSpec{Schedule: "0 2 * * *", Duration: hours(1), TimeZone: "Asia/Shanghai"},
+		wantEnd:    time.Date(2022, 7, 1, 19, 0, 0, 0, time.UTC),
+		wantActive: true,
+	}, {
+		name: "the period has ended",
+		spec: v1alpha3.FreezeWindowSpec{Schedule: "0 17 * * *", Duration: hours(1)},
+	}, {
+		name:    "schedule without duration",
+		spec:    v1alpha3.FreezeWindowSpec{Schedule: "0 17 * * *"},
+		wantErr: true,
+	}, {
+		name:    "invalid time zone",
+		spec:    v1alpha3.FreezeWindowSpec{Schedule: "0 17 * * *", Duration: hours(1), TimeZone: "invalid"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, active, err := ActiveUntil(&tt.spec, now)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantActive, active)
+			if tt.wantActive {
+				assert.True(t, tt.wantEnd.Equal(end), end)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	activeRange := []v1alpha3.FreezeRange{{
+		Start: metav1.NewTime(now.Add(-time.Hour)), End: metav1.NewTime(now.Add(time.Hour)),
+	}}
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{
+		Name: "demo", Namespace: "ns", Labels: map[string]string{"app": "demo"},
+	}}
+
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		&v1alpha3.FreezeWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: "queue", Namespace: "ns"},
+			Spec:       v1alpha3.FreezeWindowSpec{Ranges: activeRange},
+		},
+		&v1alpha3.FreezeWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"},
+			Spec:       v1alpha3.FreezeWindowSpec{Ranges: activeRange, Policy: v1alpha3.FreezePolicyReject},
+		},
+		&v1alpha3.ClusterFreezeWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: "reject"},
+			Spec: v1alpha3.FreezeWindowSpec{Ranges: activeRange, Policy: v1alpha3.FreezePolicyReject,
+				PipelineSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}},
+		}).Build()
+
+	window, err := Find(context.Background(), c, pipeline, now)
+	assert.Nil(t, err)
+	if assert.NotNil(t, window) {
+		assert.Equal(t, "queue", window.Name)
+		assert.Equal(t, v1alpha3.FreezePolicyQueue, window.Policy)
+	}
+
+	// the rejecting window takes precedence
+	pipeline.Labels["app"] = "other"
+	window, err = Find(context.Background(), c, pipeline, now)
+	assert.Nil(t, err)
+	if assert.NotNil(t, window) {
+		assert.Equal(t, "cluster/reject", window.Name)
+	}
+
+	// not frozen after all the windows have ended
+	window, err = Find(context.Background(), c, pipeline, now.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Nil(t, window)
+}
+
+func TestHoldAndThaw(t *testing.T) {
+	now := time.Date(2022, 7, 1, 18, 30, 0, 0, time.UTC)
+	window := &Window{Name: "release", Policy: v1alpha3.FreezePolicyQueue, Message: "release 3.3", End: now.Add(time.Hour)}
+
+	status := &v1alpha3.PipelineRunStatus{}
+	assert.True(t, Hold(status, window, now))
+	assert.Equal(t, v1alpha3.Pending, status.Phase)
+	frozen := getCondition(status, v1alpha3.ConditionFrozen)
+	assert.Equal(t, v1alpha3.ConditionTrue, frozen.Status)
+	assert.Equal(t, "frozen by release until 2022-07-01T19:30:00Z: release 3.3", frozen.Message)
+	// nothing changed
+	assert.False(t, Hold(status, window, now.Add(time.Minute)))
+
+	Thaw(status, now)
+	assert.Equal(t, v1alpha3.ConditionFalse, getCondition(status, v1alpha3.ConditionFrozen).Status)
+
+	window.Policy = v1alpha3.FreezePolicyReject
+	status = &v1alpha3.PipelineRunStatus{}
+	assert.True(t, Hold(status, window, now))
+	assert.Equal(t, v1alpha3.Cancelled, status.Phase)
+	assert.NotNil(t, status.CompletionTime)
+	assert.Equal(t, v1alpha3.Frozen, getCondition(status, v1alpha3.ConditionSucceeded).Reason)
+}
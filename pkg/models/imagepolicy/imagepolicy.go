@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapKeyPolicy is the key of the cluster-wide policy in the ConfigMap
+	ConfigMapKeyPolicy = "policy.yaml"
+
+	defaultRegistry  = "docker.io"
+	officialLibrary  = "library"
+	latestTag        = "latest"
+	digestSeparator  = "@"
+	digestAlgorithm  = "sha256:"
+	legacyDockerHost = "index.docker.io"
+)
+
+// Image is a parsed image reference, the repository is fully qualified, such as docker.io/library/alpine
+type Image struct {
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParsePolicy parses a policy in YAML or JSON
+func ParsePolicy(data []byte) (policy *v1alpha3.AgentImagePolicy, err error) {
+	policy = &v1alpha3.AgentImagePolicy{}
+	if err = yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid agent image policy: %v", err)
+	}
+	switch policy.Mode {
+	case "", v1alpha3.GateModeEnforce, v1alpha3.GateModeWarn:
+	default:
+		return nil, fmt.Errorf("invalid agent image policy: unknown mode %q", policy.Mode)
+	}
+	return
+}
+
+// ParseImage parses an image reference like the container runtimes do, the registry is docker.io if it's omitted
+func ParseImage(image string) (result Image, err error) {
+	name := image
+	if index := strings.Index(name, digestSeparator); index >= 0 {
+		name, result.Digest = name[:index], name[index+1:]
+		if !strings.HasPrefix(result.Digest, digestAlgorithm) || len(result.Digest) == len(digestAlgorithm) {
+			return result, fmt.Errorf("invalid digest of image %q", image)
+		}
+	}
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		if name, result.Tag = name[:index], name[index+1:]; result.Tag == "" {
+			return result, fmt.Errorf("invalid tag of image %q", image)
+		}
+	}
+	if name == "" || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//") {
+		return result, fmt.Errorf("invalid image %q", image)
+	}
+
+	items := strings.SplitN(name, "/", 2)
+	if len(items) == 1 || !isRegistry(items[0]) {
+		items = []string{defaultRegistry, name}
+	}
+	if items[0] == legacyDockerHost {
+		items[0] = defaultRegistry
+	}
+	if items[0] == defaultRegistry && !strings.Contains(items[1], "/") {
+		items[1] = officialLibrary + "/" + items[1]
+	}
+	result.Repository = strings.ToLower(items[0]) + "/" + items[1]
+	return
+}
+
+// isRegistry checks if the first component of an image name is a registry host rather than a namespace
+func isRegistry(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// Check returns the violations of an image against the policy, the image is allowed if there is no violation
+func Check(policy *v1alpha3.AgentImagePolicy, image string) (violations []string) {
+	parsed, err := ParseImage(image)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if len(policy.AllowedRegistries) > 0 && !isAllowedRepository(policy.AllowedRegistries, parsed.Repository) {
+		violations = append(violations, fmt.Sprintf("image %q is not from the allowed registries %s",
+			image, strings.Join(policy.AllowedRegistries, ", ")))
+	}
+	if policy.RequireDigest && parsed.Digest == "" {
+		violations = append(violations, fmt.Sprintf("image %q is not referenced by a digest", image))
+	} else if policy.PinTags && parsed.Digest == "" && (parsed.Tag == "" || parsed.Tag == latestTag) {
+		violations = append(violations, fmt.Sprintf("image %q does not have a pinned tag", image))
+	}
+	return
+}
+
+// isAllowedRepository checks if the repository is one of the allowed registries or repository prefixes
+func isAllowedRepository(allowed []string, repository string) bool {
+	for _, item := range allowed {
+		prefix := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(item, "https://"), "http://"), "/"))
+		if prefix == legacyDockerHost {
+			prefix = defaultRegistry
+		}
+		if prefix != "" && (repository == prefix || strings.HasPrefix(repository, prefix+"/")) {
+			return true
+		}
+	}
+	return false
+}
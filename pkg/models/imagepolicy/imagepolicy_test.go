@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const digest = "sha256:e7d88de73db3d3fd9b2d63aa7f447a10fd0220b7cbf39803c803f2af9ba256b3"
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+mode: Warn
+allowedRegistries:
+- harbor.example.com
+pinTags: true
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, &v1alpha3.AgentImagePolicy{
+		Mode:              v1alpha3.GateModeWarn,
+		AllowedRegistries: []string{"harbor.example.com"},
+		PinTags:           true,
+	}, policy)
+
+	_, err = ParsePolicy([]byte(`mode: Audit`))
+	assert.NotNil(t, err)
+	_, err = ParsePolicy([]byte(`allowedRegistries: harbor.example.com`))
+	assert.NotNil(t, err)
+}
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image   string
+		want    Image
+		wantErr bool
+	}{{
+		image: "alpine",
+		want:  Image{Repository: "docker.io/library/alpine"},
+	}, {
+		image: "jenkins/inbound-agent:4.10-3",
+		want:  Image{Repository: "docker.io/jenkins/inbound-agent", Tag: "4.10-3"},
+	}, {
+		image: "index.docker.io/library/alpine:3.16",
+		want:  Image{Repository: "docker.io/library/alpine", Tag: "3.16"},
+	}, {
+		image: "harbor.example.com:8443/ci/maven:3.8@" + digest,
+		want:  Image{Repository: "harbor.example.com:8443/ci/maven", Tag: "3.8", Digest: digest},
+	}, {
+		image: "localhost/maven",
+		want:  Image{Repository: "localhost/maven"},
+	}, {
+		image:   "alpine@md5:fake",
+		wantErr: true,
+	}, {
+		image:   "alpine:",
+		wantErr: true,
+	}, {
+		image:   "harbor.example.com/",
+		wantErr: true,
+	}, {
+		image:   "",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := ParseImage(tt.image)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy := &v1alpha3.AgentImagePolicy{AllowedRegistries: []string{"https://harbor.example.com/", "docker.io/jenkins"}}
+	assert.Empty(t, Check(policy, "harbor.example.com/ci/maven"))
+	assert.Empty(t, Check(policy, "jenkins/inbound-agent"))
+	assert.Len(t, Check(policy, "jenkinsci/inbound-agent"), 1)
+	assert.Len(t, Check(policy, "harbor.example.com.evil.io/ci/maven"), 1)
+	assert.Len(t, Check(policy, "alpine:"), 1)
+
+	policy = &v1alpha3.AgentImagePolicy{PinTags: true}
+	assert.Empty(t, Check(policy, "alpine:3.16"))
+	assert.Empty(t, Check(policy, "alpine@"+digest))
+	assert.Len(t, Check(policy, "alpine"), 1)
+	assert.Len(t, Check(policy, "alpine:latest"), 1)
+
+	policy = &v1alpha3.AgentImagePolicy{RequireDigest: true, PinTags: true, AllowedRegistries: []string{"quay.io"}}
+	assert.Empty(t, Check(policy, "quay.io/ci/maven:3.8@"+digest))
+	assert.Len(t, Check(policy, "alpine:latest"), 2)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/release"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// keyPattern matches the Jira issue keys, such as DEVOPS-12
+var keyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+)-[1-9][0-9]*\b`)
+
+// ParseKeys returns the distinct issue keys in the texts by the order they appear.
+// Only the issues of the projects are returned if the project keys are given.
+func ParseKeys(projectKeys []string, texts ...string) (keys []string) {
+	keys = []string{}
+	found := map[string]bool{}
+	for _, text := range texts {
+		for _, matches := range keyPattern.FindAllStringSubmatch(text, -1) {
+			if found[matches[0]] || (len(projectKeys) > 0 && !contains(projectKeys, matches[1])) {
+				continue
+			}
+			found[matches[0]] = true
+			keys = append(keys, matches[0])
+		}
+	}
+	return
+}
+
+// GetKeys returns the keys of the issues which are linked to a PipelineRun
+func GetKeys(pr *v1alpha3.PipelineRun) []string {
+	return split(pr.Annotations[v1alpha3.PipelineRunIssuesAnnoKey])
+}
+
+// GetReported returns the keys of the issues which the result of a PipelineRun was commented on
+func GetReported(pr *v1alpha3.PipelineRun) []string {
+	return split(pr.Annotations[v1alpha3.PipelineRunIssuesReportedAnnoKey])
+}
+
+// Comment returns the result of a completed PipelineRun in the Jira wiki markup
+func Comment(pr *v1alpha3.PipelineRun, revision string) string {
+	runID, _ := pr.GetPipelineRunID()
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "Pipeline *%s* #%s", pr.Labels[v1alpha3.PipelineNameLabelKey], runID)
+	if pr.Spec.SCM != nil && pr.Spec.SCM.RefName != "" {
+		fmt.Fprintf(buf, " of branch {{%s}}", pr.Spec.SCM.RefName)
+	}
+	fmt.Fprintf(buf, " *%s*.", pr.Status.Phase)
+	if revision != "" {
+		fmt.Fprintf(buf, "\nRevision: {{%s}}", revision)
+	}
+	if environments := split(pr.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey]); len(environments) > 0 {
+		fmt.Fprintf(buf, "\nEnvironments: %s", strings.Join(environments, ", "))
+	}
+	fmt.Fprintf(buf, "\nPipelineRun: {{%s/%s}}", pr.Namespace, pr.Name)
+	return buf.String()
+}
+
+// Trace is a PipelineRun which is linked to an issue, it tells where the changes of the issue were built and deployed
+type Trace struct {
+	PipelineRun    string            `json:"pipelineRun"`
+	Pipeline       string            `json:"pipeline"`
+	RunID          string            `json:"runID,omitempty"`
+	Branch         string            `json:"branch,omitempty"`
+	Revision       string            `json:"revision,omitempty"`
+	Phase          v1alpha3.RunPhase `json:"phase,omitempty"`
+	StartTime      *metav1.Time      `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time      `json:"completionTime,omitempty"`
+	// Environments are the PipelineEnvironments which were injected into the PipelineRun
+	Environments []string `json:"environments,omitempty"`
+	// ReleaseTag is the Git tag which was created for the PipelineRun
+	ReleaseTag string `json:"releaseTag,omitempty"`
+}
+
+// ListTraces returns the PipelineRuns in the namespace which are linked to the issue, from the latest one
+func ListTraces(ctx context.Context, c client.Reader, namespace, key string) (traces []Trace, err error) {
+	pipelineRuns := &v1alpha3.PipelineRunList{}
+	if err = c.List(ctx, pipelineRuns, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	var linked []*v1alpha3.PipelineRun
+	for i := range pipelineRuns.Items {
+		if contains(GetKeys(&pipelineRuns.Items[i]), key) {
+			linked = append(linked, &pipelineRuns.Items[i])
+		}
+	}
+	sort.SliceStable(linked, func(i, j int) bool {
+		if !linked[i].CreationTimestamp.Equal(&linked[j].CreationTimestamp) {
+			return linked[j].CreationTimestamp.Before(&linked[i].CreationTimestamp)
+		}
+		return linked[i].Name > linked[j].Name
+	})
+
+	traces = []Trace{}
+	for _, pr := range linked {
+		trace := Trace{
+			PipelineRun:    pr.Name,
+			Pipeline:       pr.Labels[v1alpha3.PipelineNameLabelKey],
+			Revision:       release.GetRevision(pr),
+			Phase:          pr.Status.Phase,
+			StartTime:      pr.Status.StartTime,
+			CompletionTime: pr.Status.CompletionTime,
+			Environments:   split(pr.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey]),
+			ReleaseTag:     pr.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey],
+		}
+		trace.RunID, _ = pr.GetPipelineRunID()
+		if pr.Spec.SCM != nil {
+			trace.Branch = pr.Spec.SCM.RefName
+		}
+		traces = append(traces, trace)
+	}
+	return
+}
+
+func split(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
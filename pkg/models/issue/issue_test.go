@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectKeys []string
+		texts       []string
+		want        []string
+	}{{
+		name: "no keys",
+		want: []string{},
+	}, {
+		name:  "branch and commit message",
+		texts: []string{"feature/DEVOPS-12-login", "DEVOPS-13 Fix the login\n\nRelated to DEVOPS-12 and OPS-1"},
+		want:  []string{"DEVOPS-12", "DEVOPS-13", "OPS-1"},
+	}, {
+		name:        "filtered by the project keys",
+		projectKeys: []string{"DEVOPS"},
+		texts:       []string{"DEVOPS-12 Convert the files to UTF-8"},
+		want:        []string{"DEVOPS-12"},
+	}, {
+		name:  "not issue keys",
+		texts: []string{"devops-12 ABC-0 A-1 XDEVOPS-12X"},
+		want:  []string{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseKeys(tt.projectKeys, tt.texts...))
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "app-x7k2p",
+			Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+			Annotations: map[string]string{
+				v1alpha3.JenkinsPipelineRunIDAnnoKey:    "12",
+				v1alpha3.PipelineRunEnvironmentsAnnoKey: "production,shared",
+			},
+		},
+		Spec:   v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "master"}},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+	}
+	assert.Equal(t, "Pipeline *app* #12 of branch {{master}} *Succeeded*.\nRevision: {{c1}}\n"+
+		"Environments: production, shared\nPipelineRun: {{ns/app-x7k2p}}", Comment(pr, "c1"))
+}
+
+func TestListTraces(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newRun := func(name, issues string, created time.Time) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "ns",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+				Annotations: map[string]string{
+					v1alpha3.PipelineRunIssuesAnnoKey:              issues,
+					v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey: "c-" + name,
+				},
+			},
+			Spec:   v1alpha3.PipelineRunSpec{SCM: &v1alpha3.SCM{RefName: "master"}},
+			Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+		}
+	}
+	deployed := newRun("deployed", "DEVOPS-1,DEVOPS-2", now)
+	deployed.Annotations[v1alpha3.PipelineRunEnvironmentsAnnoKey] = "production"
+	deployed.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] = "v1.0.2"
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(deployed, newRun("built", "DEVOPS-1", now.Add(-time.Hour)),
+		newRun("other", "DEVOPS-3", now)).Build()
+
+	traces, err := ListTraces(context.Background(), c, "ns", "DEVOPS-1")
+	assert.Nil(t, err)
+	if assert.Len(t, traces, 2) {
+		assert.Equal(t, Trace{
+			PipelineRun:  "deployed",
+			Pipeline:     "app",
+			Branch:       "master",
+			Revision:     "c-deployed",
+			Phase:        v1alpha3.Succeeded,
+			Environments: []string{"production"},
+			ReleaseTag:   "v1.0.2",
+		}, traces[0])
+		assert.Equal(t, "built", traces[1].PipelineRun)
+	}
+
+	traces, err = ListTraces(context.Background(), c, "ns", "DEVOPS-4")
+	assert.Nil(t, err)
+	assert.Empty(t, traces)
+}
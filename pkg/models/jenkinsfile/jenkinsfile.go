@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsfile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/pmezard/go-difflib/difflib"
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+)
+
+const (
+	// ConfigMapKeyJenkinsfile is the key of the Jenkinsfile in the ConfigMap
+	ConfigMapKeyJenkinsfile = "Jenkinsfile"
+	// DefaultScriptPath is the path of the Jenkinsfile in the repository if it's not specified
+	DefaultScriptPath = "Jenkinsfile"
+)
+
+// GetConfigMapName returns the name of the ConfigMap which stores the Jenkinsfile of a PipelineRun
+func GetConfigMapName(pr *v1alpha3.PipelineRun) string {
+	return pr.Name + "-jenkinsfile"
+}
+
+// Digest returns the SHA-256 digest of the Jenkinsfile in hex
+func Digest(content string) string {
+	digest := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(digest[:])
+}
+
+// GetRevision returns the commit ID which the Jenkinsfile of a PipelineRun was loaded from,
+// it's empty if the PipelineRun has not checked out the SCM yet.
+func GetRevision(pr *v1alpha3.PipelineRun) string {
+	run := &job.PipelineRun{}
+	if err := json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), run); err != nil {
+		return ""
+	}
+	return run.CommitID
+}
+
+// IsRecorded returns true if the Jenkinsfile of the PipelineRun was recorded
+func IsRecorded(pr *v1alpha3.PipelineRun) bool {
+	return pr.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey] != "" ||
+		pr.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey] != ""
+}
+
+// Load returns the Jenkinsfile of a multi-branch Pipeline at the revision
+func Load(ctx context.Context, factory git.SCMClientFactory, namespace string, pipeline *v1alpha3.MultiBranchPipeline,
+	revision string) (content string, err error) {
+	var provider, server, repo string
+	if provider, server, repo, err = git.GetRepository(pipeline); err != nil {
+		return
+	}
+	var secretRef *v1.SecretReference
+	if credentialID := pipeline.GetCredentialID(); credentialID != "" {
+		secretRef = &v1.SecretReference{Namespace: namespace, Name: credentialID}
+	}
+
+	var scmClient *scm.Client
+	if scmClient, err = factory(provider, server, secretRef); err != nil {
+		return
+	}
+	scriptPath := pipeline.ScriptPath
+	if scriptPath == "" {
+		scriptPath = DefaultScriptPath
+	}
+	var file *scm.Content
+	if file, _, err = scmClient.Contents.Find(ctx, repo, scriptPath, revision); err != nil {
+		err = fmt.Errorf("failed to load %s of %s at %s, error: %v", scriptPath, repo, revision, err)
+		return
+	}
+	content = string(file.Data)
+	return
+}
+
+// RunJenkinsfile is the recorded Jenkinsfile of a PipelineRun
+type RunJenkinsfile struct {
+	Name string `json:"name"`
+	// Revision is the commit ID which the Jenkinsfile was loaded from, it's empty for the Pipelines without SCM
+	Revision string `json:"revision,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+}
+
+// Diff is the difference of the Jenkinsfile between two PipelineRuns
+type Diff struct {
+	Base    RunJenkinsfile `json:"base"`
+	Target  RunJenkinsfile `json:"target"`
+	Changed bool           `json:"changed"`
+	// Unified is the difference in the unified format, it's empty if the Jenkinsfile didn't change
+	Unified string `json:"unified,omitempty"`
+}
+
+// Compare returns the difference from the Jenkinsfile of the base PipelineRun to the target one
+func Compare(base, target *v1alpha3.PipelineRun, baseContent, targetContent string) (diff *Diff, err error) {
+	diff = &Diff{
+		Base:   getRunJenkinsfile(base),
+		Target: getRunJenkinsfile(target),
+	}
+	if diff.Changed = Digest(baseContent) != Digest(targetContent); !diff.Changed {
+		return
+	}
+	diff.Unified, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(baseContent),
+		B:        splitLines(targetContent),
+		FromFile: base.Name,
+		ToFile:   target.Name,
+		Context:  3,
+	})
+	return
+}
+
+func getRunJenkinsfile(pr *v1alpha3.PipelineRun) RunJenkinsfile {
+	return RunJenkinsfile{
+		Name:     pr.Name,
+		Revision: pr.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey],
+		Digest:   pr.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey],
+	}
+}
+
+// splitLines splits the content into the lines which end with a newline, it's different from difflib.SplitLines
+// which always appends an empty line
+func splitLines(content string) (lines []string) {
+	if content == "" {
+		return
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	lines = strings.SplitAfter(content, "\n")
+	return lines[:len(lines)-1]
+}
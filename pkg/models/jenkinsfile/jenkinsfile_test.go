@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsfile
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/git"
+)
+
+func TestGetRevision(t *testing.T) {
+	newPipelineRun := func(status string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunStatusAnnoKey: status},
+		}}
+	}
+	assert.Equal(t, "", GetRevision(&v1alpha3.PipelineRun{}))
+	assert.Equal(t, "", GetRevision(newPipelineRun("invalid")))
+	assert.Equal(t, "", GetRevision(newPipelineRun(`{"id":"1"}`)))
+	assert.Equal(t, "abc", GetRevision(newPipelineRun(`{"id":"1","commitId":"abc"}`)))
+}
+
+func TestLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "abc" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 File Not Found"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"file_path":"ci/Jenkinsfile","content":"%s"}`,
+			base64.StdEncoding.EncodeToString([]byte("pipeline {}")))
+	}))
+	defer server.Close()
+
+	var secretRef *v1.SecretReference
+	factory := func(provider, _ string, ref *v1.SecretReference) (*scm.Client, error) {
+		assert.Equal(t, "gitlab", provider)
+		secretRef = ref
+		return gitlab.New(server.URL)
+	}
+	pipeline := &v1alpha3.MultiBranchPipeline{
+		SourceType:   v1alpha3.SourceTypeGitlab,
+		GitlabSource: &v1alpha3.GitlabSource{Owner: "group", Repo: "app", CredentialId: "token"},
+		ScriptPath:   "ci/Jenkinsfile",
+	}
+
+	content, err := Load(context.Background(), factory, "ns", pipeline, "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "pipeline {}", content)
+	assert.Equal(t, &v1.SecretReference{Namespace: "ns", Name: "token"}, secretRef)
+
+	_, err = Load(context.Background(), factory, "ns", pipeline, "def")
+	assert.NotNil(t, err)
+
+	_, err = Load(context.Background(), factory, "ns", &v1alpha3.MultiBranchPipeline{SourceType: v1alpha3.SourceTypeSVN}, "abc")
+	assert.Equal(t, git.ErrUnsupportedSource, err)
+}
+
+func TestCompare(t *testing.T) {
+	newPipelineRun := func(name, content string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1alpha3.PipelineRunJenkinsfileDigestAnnoKey: Digest(content)},
+		}}
+	}
+	base, target := newPipelineRun("pr-1", "stage('a')\n"), newPipelineRun("pr-2", "stage('b')\n")
+
+	diff, err := Compare(base, base, "stage('a')\n", "stage('a')\n")
+	assert.Nil(t, err)
+	assert.False(t, diff.Changed)
+	assert.Empty(t, diff.Unified)
+
+	diff, err = Compare(base, target, "stage('a')\n", "stage('b')\n")
+	assert.Nil(t, err)
+	assert.True(t, diff.Changed)
+	assert.Equal(t, "pr-1", diff.Base.Name)
+	assert.Equal(t, Digest("stage('b')\n"), diff.Target.Digest)
+	assert.Equal(t, "--- pr-1\n+++ pr-2\n@@ -1 +1 @@\n-stage('a')\n+stage('b')\n", diff.Unified)
+
+	diff, err = Compare(base, target, "", "stage('b')")
+	assert.Nil(t, err)
+	assert.Equal(t, "--- pr-1\n+++ pr-2\n@@ -0,0 +1 @@\n+stage('b')\n", diff.Unified)
+}
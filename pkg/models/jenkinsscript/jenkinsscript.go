@@ -0,0 +1,310 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsscript
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kubesphere.io/devops/pkg/api/devops"
+	devopsclient "kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultNamespace is the namespace of the allowlist and the audit records
+	DefaultNamespace = "kubesphere-devops-system"
+	// DefaultAllowlistName is the name of the ConfigMap which contains the approved scripts, the keys are the names
+	DefaultAllowlistName = "jenkins-maintenance-scripts"
+
+	// auditLabelKey is the label key of the ConfigMaps which store the audit records
+	auditLabelKey = devops.GroupName + "/jenkins-script-audit"
+	auditDataKey  = "execution"
+	// maxOutputSize is the max size of the output kept in an audit record
+	maxOutputSize = 512 * 1024
+)
+
+// Phase is the phase of a script execution
+type Phase string
+
+const (
+	// PhaseRunning indicates the script is running, it stays if the apiserver was interrupted
+	PhaseRunning Phase = "Running"
+	// PhaseSucceeded indicates the script was executed by Jenkins
+	PhaseSucceeded Phase = "Succeeded"
+	// PhaseFailed indicates the script failed to be sent to Jenkins
+	PhaseFailed Phase = "Failed"
+)
+
+// Script is an approved Groovy script
+type Script struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	// Digest is the SHA-256 digest of the content
+	Digest string `json:"digest"`
+}
+
+// Execution is the audit record of running a script
+type Execution struct {
+	// ID is the identity of the audit record
+	ID         string            `json:"id"`
+	Script     string            `json:"script"`
+	Digest     string            `json:"digest"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	User       string            `json:"user"`
+	Phase      Phase             `json:"phase"`
+	// Output is the output of the script console, it's truncated if it's too large
+	Output           string      `json:"output,omitempty"`
+	Error            string      `json:"error,omitempty"`
+	StartTime        metav1.Time `json:"startTime"`
+	DurationInMillis int64       `json:"durationInMillis"`
+}
+
+// Runner runs the approved scripts on Jenkins, and keeps an audit record for each execution
+type Runner struct {
+	Client  client.Client
+	Jenkins devopsclient.ScriptOperator
+	// Namespace is where the allowlist and the audit records are
+	Namespace string
+	// AllowlistName is the name of the ConfigMap which contains the approved scripts
+	AllowlistName string
+	// MaxAuditSize is the max number of the audit records, the oldest ones will be dropped if it's positive.
+	// All the records are kept by default, the retention is opted in by the administrators.
+	MaxAuditSize int
+}
+
+// NewRunner creates a Runner with the default allowlist
+func NewRunner(c client.Client, jenkins devopsclient.ScriptOperator) *Runner {
+	return &Runner{
+		Client:        c,
+		Jenkins:       jenkins,
+		Namespace:     DefaultNamespace,
+		AllowlistName: DefaultAllowlistName,
+	}
+}
+
+// ListScripts returns the approved scripts ordered by the name
+func (r *Runner) ListScripts(ctx context.Context) (scripts []Script, err error) {
+	allowlist := &v1.ConfigMap{}
+	if err = r.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.AllowlistName}, allowlist); err != nil {
+		if apierrors.IsNotFound(err) {
+			// no script is approved
+			return []Script{}, nil
+		}
+		return
+	}
+	scripts = make([]Script, 0, len(allowlist.Data))
+	for name, content := range allowlist.Data {
+		scripts = append(scripts, Script{Name: name, Content: content, Digest: digest(content)})
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+	return
+}
+
+// GetScript returns an approved script by its name
+func (r *Runner) GetScript(ctx context.Context, name string) (*Script, error) {
+	scripts, err := r.ListScripts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scripts {
+		if scripts[i].Name == name {
+			return &scripts[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Group: devops.GroupName, Resource: "jenkinsscripts"}, name)
+}
+
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Run runs an approved script on Jenkins on behalf of the user. The parameters are passed to the script as
+// the map 'params' of strings. The audit record is created before running, then updated with the result.
+func (r *Runner) Run(ctx context.Context, name string, parameters map[string]string, user string) (
+	execution *Execution, err error) {
+	var script *Script
+	if script, err = r.GetScript(ctx, name); err != nil {
+		return
+	}
+	var prelude string
+	if prelude, err = toGroovyParams(parameters); err != nil {
+		err = apierrors.NewBadRequest(err.Error())
+		return
+	}
+
+	execution = &Execution{
+		Script:     script.Name,
+		Digest:     script.Digest,
+		Parameters: parameters,
+		User:       user,
+		Phase:      PhaseRunning,
+		StartTime:  metav1.Now(),
+	}
+	if err = r.addExecution(ctx, execution); err != nil {
+		// the script never runs without an audit record
+		execution, err = nil, fmt.Errorf("failed to record the audit of script '%s', error: %v", name, err)
+		return
+	}
+	klog.Infof("user '%s' is running Jenkins script '%s' (%s), audit record: %s", user, name, script.Digest,
+		execution.ID)
+
+	var output string
+	if output, err = r.Jenkins.RunScript(prelude + script.Content); err != nil {
+		execution.Phase, execution.Error = PhaseFailed, err.Error()
+	} else {
+		execution.Phase = PhaseSucceeded
+	}
+	if len(output) > maxOutputSize {
+		output = output[:maxOutputSize]
+	}
+	execution.Output = output
+	execution.DurationInMillis = time.Since(execution.StartTime.Time).Milliseconds()
+	if updateErr := r.updateExecution(ctx, execution); updateErr != nil {
+		klog.Errorf("failed to update the audit record %s of Jenkins script '%s', error: %v", execution.ID, name,
+			updateErr)
+	}
+	return
+}
+
+// toGroovyParams declares the parameters as a map of Groovy strings
+func toGroovyParams(parameters map[string]string) (string, error) {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		if !parameterNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid parameter name '%s'", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, fmt.Sprintf("'%s': '%s'", name, escaper.Replace(parameters[name])))
+	}
+	if len(entries) == 0 {
+		return "def params = [:]\n", nil
+	}
+	return fmt.Sprintf("def params = [%s]\n", strings.Join(entries, ", ")), nil
+}
+
+func digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListExecutions returns the audit records, the latest one comes first
+func (r *Runner) ListExecutions(ctx context.Context) (executions []Execution, err error) {
+	configMapList := &v1.ConfigMapList{}
+	if err = r.Client.List(ctx, configMapList, client.InNamespace(r.Namespace), client.HasLabels{auditLabelKey}); err != nil {
+		return
+	}
+	executions = make([]Execution, 0, len(configMapList.Items))
+	for i := range configMapList.Items {
+		var execution *Execution
+		if execution, err = getExecution(&configMapList.Items[i]); err != nil {
+			return
+		}
+		executions = append(executions, *execution)
+	}
+	sort.SliceStable(executions, func(i, j int) bool {
+		return executions[j].StartTime.Before(&executions[i].StartTime)
+	})
+	return
+}
+
+func (r *Runner) addExecution(ctx context.Context, execution *Execution) (err error) {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    r.Namespace,
+			GenerateName: "jenkins-script-",
+			Labels:       map[string]string{auditLabelKey: "true"},
+		},
+	}
+	if err = setExecution(configMap, execution); err != nil {
+		return
+	}
+	if err = r.Client.Create(ctx, configMap); err != nil {
+		return
+	}
+	execution.ID = configMap.Name
+	return r.evict(ctx)
+}
+
+func (r *Runner) updateExecution(ctx context.Context, execution *Execution) (err error) {
+	configMap := &v1.ConfigMap{}
+	if err = r.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: execution.ID}, configMap); err != nil {
+		return
+	}
+	if err = setExecution(configMap, execution); err == nil {
+		err = r.Client.Update(ctx, configMap)
+	}
+	return
+}
+
+// evict drops the oldest audit records which are out of the max size, nothing is dropped unless the max size is positive
+func (r *Runner) evict(ctx context.Context) (err error) {
+	if r.MaxAuditSize <= 0 {
+		return
+	}
+	var executions []Execution
+	if executions, err = r.ListExecutions(ctx); err != nil {
+		return
+	}
+	for i := r.MaxAuditSize; i < len(executions); i++ {
+		configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: r.Namespace, Name: executions[i].ID}}
+		if err = client.IgnoreNotFound(r.Client.Delete(ctx, configMap)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func setExecution(configMap *v1.ConfigMap, execution *Execution) (err error) {
+	// the ID is the name of ConfigMap, no need to store it
+	data := *execution
+	data.ID = ""
+	var raw []byte
+	if raw, err = json.Marshal(data); err == nil {
+		configMap.Data = map[string]string{auditDataKey: string(raw)}
+	}
+	return
+}
+
+func getExecution(configMap *v1.ConfigMap) (execution *Execution, err error) {
+	execution = &Execution{}
+	if err = json.Unmarshal([]byte(configMap.Data[auditDataKey]), execution); err != nil {
+		err = fmt.Errorf("failed to parse the audit record %s, error: %v", configMap.Name, err)
+		return
+	}
+	execution.ID = configMap.Name
+	return
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkinsscript
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeRunner(t *testing.T, jenkins *fakedevops.Devops, objects ...runtime.Object) *Runner {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	return NewRunner(fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build(), jenkins)
+}
+
+func newAllowlist() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: DefaultNamespace, Name: DefaultAllowlistName},
+		Data: map[string]string{
+			"reload":       "Jenkins.instance.reload()",
+			"clean-builds": "println params.job",
+		},
+	}
+}
+
+func TestListScripts(t *testing.T) {
+	ctx := context.TODO()
+	scripts, err := newFakeRunner(t, fakedevops.New()).ListScripts(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, scripts)
+
+	runner := newFakeRunner(t, fakedevops.New(), newAllowlist())
+	scripts, err = runner.ListScripts(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []Script{{
+		Name: "clean-builds", Content: "println params.job",
+		Digest: "ee2b40066111081621e769c5d0dcae5e817aad16fd23729880652082f8e3b126",
+	}, {
+		Name: "reload", Content: "Jenkins.instance.reload()",
+		Digest: "52b655e0e7a0cb6dff6cdd94ab441ca25aa1e8753401651532729272badb4d82",
+	}}, scripts)
+
+	_, err = runner.GetScript(ctx, "fake")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.TODO()
+	jenkins := fakedevops.New()
+	jenkins.Data = map[string]interface{}{"scriptOutput": "done"}
+	runner := newFakeRunner(t, jenkins, newAllowlist())
+	runner.MaxAuditSize = 2
+
+	execution, err := runner.Run(ctx, "clean-builds", map[string]string{"job": "it's\na"}, "admin")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, execution.ID)
+	assert.Equal(t, PhaseSucceeded, execution.Phase)
+	assert.Equal(t, "done", execution.Output)
+	assert.Equal(t, []string{"def params = ['job': 'it\\'s\\na']\nprintln params.job"}, jenkins.Data["scripts"])
+
+	executions, err := runner.ListExecutions(ctx)
+	assert.Nil(t, err)
+	if assert.Len(t, executions, 1) {
+		assert.Equal(t, execution.ID, executions[0].ID)
+		assert.Equal(t, "admin", executions[0].User)
+		assert.Equal(t, "clean-builds", executions[0].Script)
+		assert.Equal(t, PhaseSucceeded, executions[0].Phase)
+		assert.Equal(t, map[string]string{"job": "it's\na"}, executions[0].Parameters)
+	}
+
+	// the failures are audited as well
+	jenkins.Data["scriptError"] = errors.New("jenkins is down")
+	execution, err = runner.Run(ctx, "reload", nil, "admin")
+	assert.NotNil(t, err)
+	assert.Equal(t, PhaseFailed, execution.Phase)
+	assert.Equal(t, "jenkins is down", execution.Error)
+	assert.Equal(t, "def params = [:]\nJenkins.instance.reload()", jenkins.Data["scripts"].([]string)[1])
+
+	// the oldest records are dropped
+	_, _ = runner.Run(ctx, "reload", nil, "admin")
+	executions, err = runner.ListExecutions(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, executions, 2)
+
+	// the scripts out of the allowlist and invalid parameters are rejected without auditing
+	_, err = runner.Run(ctx, "rm -rf", nil, "admin")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = runner.Run(ctx, "reload", map[string]string{"a'": "b"}, "admin")
+	assert.True(t, apierrors.IsBadRequest(err))
+	assert.Len(t, jenkins.Data["scripts"], 3)
+}
+
+func TestRunKeepsAllAuditRecordsByDefault(t *testing.T) {
+	ctx := context.TODO()
+	jenkins := fakedevops.New()
+	runner := newFakeRunner(t, jenkins, newAllowlist())
+	assert.Zero(t, runner.MaxAuditSize)
+
+	for i := 0; i < 3; i++ {
+		_, err := runner.Run(ctx, "reload", nil, "admin")
+		assert.Nil(t, err)
+	}
+	executions, err := runner.ListExecutions(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, executions, 3)
+}
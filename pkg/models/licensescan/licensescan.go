@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensescan
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// BOM is the part of a CycloneDX SBOM which the licenses are checked from
+type BOM struct {
+	BOMFormat  string      `json:"bomFormat"`
+	Components []Component `json:"components"`
+}
+
+// Component is a dependency in the SBOM
+type Component struct {
+	Name     string          `json:"name"`
+	Group    string          `json:"group,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Licenses []LicenseChoice `json:"licenses,omitempty"`
+	Nested   []Component     `json:"components,omitempty"`
+}
+
+// LicenseChoice is either a license or an SPDX license expression
+type LicenseChoice struct {
+	License    *License `json:"license,omitempty"`
+	Expression string   `json:"expression,omitempty"`
+}
+
+// License is identified by an SPDX license ID or a name
+type License struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Parse parses a CycloneDX SBOM in JSON format
+func Parse(data []byte) (bom *BOM, err error) {
+	bom = &BOM{}
+	if err = json.Unmarshal(data, bom); err == nil && bom.BOMFormat != "CycloneDX" {
+		err = fmt.Errorf("unsupported SBOM format '%s', only CycloneDX is supported", bom.BOMFormat)
+	}
+	return
+}
+
+// GetComponents returns all the components including the nested ones
+func (b *BOM) GetComponents() (components []Component) {
+	var walk func([]Component)
+	walk = func(items []Component) {
+		for _, item := range items {
+			components = append(components, item)
+			walk(item.Nested)
+		}
+	}
+	walk(b.Components)
+	return
+}
+
+// GetID returns the name and version of the component
+func (c *Component) GetID() string {
+	name := c.Name
+	if c.Group != "" {
+		name = c.Group + "/" + c.Name
+	}
+	if c.Version != "" {
+		name += "@" + c.Version
+	}
+	return name
+}
+
+// GetLicenses returns the license IDs, names and expressions of the component
+func (c *Component) GetLicenses() (licenses []string) {
+	for _, choice := range c.Licenses {
+		switch {
+		case choice.Expression != "":
+			licenses = append(licenses, choice.Expression)
+		case choice.License != nil && choice.License.ID != "":
+			licenses = append(licenses, choice.License.ID)
+		case choice.License != nil && choice.License.Name != "":
+			licenses = append(licenses, choice.License.Name)
+		}
+	}
+	return
+}
+
+// Checker checks the licenses of the components against a policy
+type Checker struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+// NewChecker creates a Checker of the policy, the license IDs are case-insensitive
+func NewChecker(policy *v1alpha3.LicenseScanPolicy) *Checker {
+	return &Checker{allow: toSet(policy.Allow), deny: toSet(policy.Deny)}
+}
+
+// Check returns the components whose licenses violate the policy, sorted by the components
+func (c *Checker) Check(components []Component) (violations []v1alpha3.LicenseViolation) {
+	for i := range components {
+		component := &components[i]
+		licenses := component.GetLicenses()
+		if reason, ok := c.checkComponent(licenses); !ok {
+			violations = append(violations, v1alpha3.LicenseViolation{
+				Component: component.GetID(),
+				Licenses:  licenses,
+				Reason:    reason,
+			})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Component < violations[j].Component
+	})
+	return
+}
+
+// checkComponent checks the licenses of a component, it's compliant if any of the licenses is compliant
+func (c *Checker) checkComponent(licenses []string) (reason v1alpha3.LicenseViolationReason, ok bool) {
+	if len(licenses) == 0 {
+		if len(c.allow) > 0 {
+			return v1alpha3.LicenseUnknown, false
+		}
+		return "", true
+	}
+	reason = v1alpha3.LicenseNotAllowed
+	for _, license := range licenses {
+		var licenseReason v1alpha3.LicenseViolationReason
+		if licenseReason, ok = c.checkExpression(license); ok {
+			return
+		}
+		if licenseReason == v1alpha3.LicenseDenied {
+			reason = licenseReason
+		}
+	}
+	return
+}
+
+// checkExpression checks an SPDX license expression, such as "MIT OR Apache-2.0". The expression with OR is compliant
+// if any of the alternatives is compliant, and the one with AND is compliant if all the licenses are compliant.
+// The parentheses are ignored, so AND always takes precedence over OR. The WITH exceptions are ignored as well.
+func (c *Checker) checkExpression(expression string) (reason v1alpha3.LicenseViolationReason, ok bool) {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	if alternatives := splitExpression(expression, "OR"); len(alternatives) > 1 {
+		reason = v1alpha3.LicenseNotAllowed
+		for _, alternative := range alternatives {
+			var alternativeReason v1alpha3.LicenseViolationReason
+			if alternativeReason, ok = c.checkExpression(alternative); ok {
+				return
+			}
+			if alternativeReason == v1alpha3.LicenseDenied {
+				reason = alternativeReason
+			}
+		}
+		return
+	}
+	for _, license := range splitExpression(expression, "AND") {
+		license = strings.TrimSpace(splitExpression(license, "WITH")[0])
+		if c.deny[strings.ToLower(license)] {
+			return v1alpha3.LicenseDenied, false
+		}
+		if len(c.allow) > 0 && !c.allow[strings.ToLower(license)] {
+			return v1alpha3.LicenseNotAllowed, false
+		}
+	}
+	return "", true
+}
+
+// splitExpression splits an expression by an operator which is surrounded by spaces
+func splitExpression(expression, operator string) (parts []string) {
+	fields := strings.Fields(expression)
+	var part []string
+	for _, field := range fields {
+		if strings.EqualFold(field, operator) {
+			parts = append(parts, strings.Join(part, " "))
+			part = nil
+			continue
+		}
+		part = append(part, field)
+	}
+	return append(parts, strings.Join(part, " "))
+}
+
+func toSet(items []string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range items {
+		set[strings.ToLower(item)] = true
+	}
+	return set
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensescan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestParse(t *testing.T) {
+	bom, err := Parse([]byte(`{
+  "bomFormat": "CycloneDX",
+  "components": [{
+    "group": "org.apache.commons",
+    "name": "commons-lang3",
+    "version": "3.12.0",
+    "licenses": [{"license": {"id": "Apache-2.0"}}],
+    "components": [{"name": "nested", "licenses": [{"license": {"name": "Custom License"}}]}]
+  }, {
+    "name": "github.com/stretchr/testify",
+    "version": "v1.8.0",
+    "licenses": [{"expression": "MIT"}]
+  }]
+}`))
+	assert.Nil(t, err)
+	components := bom.GetComponents()
+	if assert.Len(t, components, 3) {
+		assert.Equal(t, "org.apache.commons/commons-lang3@3.12.0", components[0].GetID())
+		assert.Equal(t, []string{"Apache-2.0"}, components[0].GetLicenses())
+		assert.Equal(t, "nested", components[1].GetID())
+		assert.Equal(t, []string{"Custom License"}, components[1].GetLicenses())
+		assert.Equal(t, []string{"MIT"}, components[2].GetLicenses())
+	}
+
+	_, err = Parse([]byte(`{"spdxVersion": "SPDX-2.3"}`))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte(`invalid`))
+	assert.NotNil(t, err)
+}
+
+func TestChecker_Check(t *testing.T) {
+	newComponent := func(name string, licenses ...string) Component {
+		component := Component{Name: name}
+		for _, license := range licenses {
+			component.Licenses = append(component.Licenses, LicenseChoice{Expression: license})
+		}
+		return component
+	}
+	components := []Component{
+		newComponent("mit", "MIT"),
+		newComponent("agpl", "AGPL-3.0-only"),
+		newComponent("dual", "GPL-2.0-only OR MIT"),
+		newComponent("both", "MIT AND GPL-2.0-only WITH Classpath-exception-2.0"),
+		newComponent("unknown"),
+		newComponent("multiple", "GPL-3.0-only", "apache-2.0"),
+	}
+
+	tests := []struct {
+		name   string
+		policy *v1alpha3.LicenseScanPolicy
+		want   []v1alpha3.LicenseViolation
+	}{{
+		name:   "deny list",
+		policy: &v1alpha3.LicenseScanPolicy{Deny: []string{"AGPL-3.0-only", "GPL-2.0-only"}},
+		want: []v1alpha3.LicenseViolation{
+			{Component: "agpl", Licenses: []string{"AGPL-3.0-only"}, Reason: v1alpha3.LicenseDenied},
+			{Component: "both", Licenses: []string{"MIT AND GPL-2.0-only WITH Classpath-exception-2.0"},
+				Reason: v1alpha3.LicenseDenied},
+		},
+	}, {
+		name:   "allow list",
+		policy: &v1alpha3.LicenseScanPolicy{Allow: []string{"MIT", "Apache-2.0"}},
+		want: []v1alpha3.LicenseViolation{
+			{Component: "agpl", Licenses: []string{"AGPL-3.0-only"}, Reason: v1alpha3.LicenseNotAllowed},
+			{Component: "both", Licenses: []string{"MIT AND GPL-2.0-only WITH Classpath-exception-2.0"},
+				Reason: v1alpha3.LicenseNotAllowed},
+			{Component: "unknown", Reason: v1alpha3.LicenseUnknown},
+		},
+	}, {
+		name:   "allow and deny lists",
+		policy: &v1alpha3.LicenseScanPolicy{Allow: []string{"MIT", "GPL-2.0-only"}, Deny: []string{"GPL-2.0-only"}},
+		want: []v1alpha3.LicenseViolation{
+			{Component: "agpl", Licenses: []string{"AGPL-3.0-only"}, Reason: v1alpha3.LicenseNotAllowed},
+			{Component: "both", Licenses: []string{"MIT AND GPL-2.0-only WITH Classpath-exception-2.0"},
+				Reason: v1alpha3.LicenseDenied},
+			{Component: "multiple", Licenses: []string{"GPL-3.0-only", "apache-2.0"}, Reason: v1alpha3.LicenseNotAllowed},
+			{Component: "unknown", Reason: v1alpha3.LicenseUnknown},
+		},
+	}, {
+		name:   "empty policy",
+		policy: &v1alpha3.LicenseScanPolicy{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewChecker(tt.policy).Check(components))
+		})
+	}
+}
@@ -0,0 +1,717 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// StageStatus is the predicted status of a stage in a dry run
+type StageStatus string
+
+const (
+	// StageStatusRun indicates the stage is going to run
+	StageStatusRun StageStatus = "run"
+	// StageStatusSkipped indicates the stage is going to be skipped by its when conditions or its parent stage
+	StageStatusSkipped StageStatus = "skipped"
+	// StageStatusUndetermined indicates the when conditions cannot be evaluated before running the Pipeline
+	StageStatusUndetermined StageStatus = "undetermined"
+)
+
+// DryRunOptions are the inputs of a dry run
+type DryRunOptions struct {
+	// Branch is used by the 'branch' condition and env.BRANCH_NAME
+	Branch string `json:"branch,omitempty"`
+	// Tag is the tag being built, it's used by the 'tag' and 'buildingTag' conditions and env.TAG_NAME
+	Tag string `json:"tag,omitempty"`
+	// ChangeRequest indicates a pull request is being built
+	ChangeRequest bool `json:"changeRequest,omitempty"`
+	// Parameters override the default values of the parameters
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// PlannedStage is a stage of the predicted execution plan
+type PlannedStage struct {
+	Name   string      `json:"name"`
+	Line   int         `json:"line,omitempty"`
+	Status StageStatus `json:"status"`
+	// Reason explains why the stage is skipped or undetermined
+	Reason string `json:"reason,omitempty"`
+	// Parallel is true if the nested stages run in parallel
+	Parallel bool `json:"parallel,omitempty"`
+	// Steps are the names of the steps, they are never executed in a dry run
+	Steps  []string       `json:"steps,omitempty"`
+	Stages []PlannedStage `json:"stages,omitempty"`
+}
+
+// Plan is the predicted execution plan of a Pipeline
+type Plan struct {
+	// Valid is false if the Jenkinsfile has any error, there is no stage in this case
+	Valid  bool    `json:"valid"`
+	Issues []Issue `json:"issues"`
+	// Parameters are the values of the parameters used to evaluate the when conditions
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Stages     []PlannedStage    `json:"stages"`
+}
+
+// DryRunJenkinsfile walks through the stages of a declarative Pipeline without executing any steps,
+// the when conditions are evaluated against the branch and the parameters.
+func DryRunJenkinsfile(jenkinsfile string, options DryRunOptions) *Plan {
+	return dryRun(jenkinsfile, nil, options)
+}
+
+// DryRunPipeline is similar to DryRunJenkinsfile, but takes the parameters defined in the Pipeline into account
+func DryRunPipeline(pipeline *v1alpha3.Pipeline, options DryRunOptions) (*Plan, error) {
+	if pipeline.Spec.Type != v1alpha3.NoScmPipelineType || pipeline.Spec.Pipeline == nil {
+		return nil, fmt.Errorf("dry run is only supported by the Pipeline of type '%s'", v1alpha3.NoScmPipelineType)
+	}
+	return dryRun(pipeline.Spec.Pipeline.Jenkinsfile, pipeline.Spec.Pipeline.Parameters, options), nil
+}
+
+func dryRun(jenkinsfile string, definitions []v1alpha3.ParameterDefinition, options DryRunOptions) *Plan {
+	plan := &Plan{Issues: []Issue{}, Stages: []PlannedStage{}}
+	if strings.TrimSpace(jenkinsfile) == "" {
+		plan.Issues = append(plan.Issues, Issue{Severity: SeverityError, Message: "the Jenkinsfile is empty"})
+		return plan
+	}
+	plan.Valid = true
+	for _, issue := range checkJenkinsfile(jenkinsfile) {
+		plan.Issues = append(plan.Issues, issue)
+		if issue.Severity == SeverityError {
+			plan.Valid = false
+		}
+	}
+	if !plan.Valid {
+		return plan
+	}
+
+	tokens, _ := tokenize(jenkinsfile)
+	var pipeline *statement
+	for _, s := range (&parser{tokens: tokens}).parseStatements() {
+		if s.name == "pipeline" && s.hasBody {
+			pipeline = s
+		}
+	}
+	if pipeline == nil {
+		return plan
+	}
+
+	p := &planner{options: options, params: map[string]value{}}
+	for _, definition := range definitions {
+		if definition.Type == "boolean" {
+			p.params[definition.Name] = known(definition.DefaultValue == "true")
+		} else {
+			p.params[definition.Name] = known(definition.DefaultValue)
+		}
+	}
+	env := map[string]value{}
+	if options.Branch != "" {
+		env["BRANCH_NAME"] = known(options.Branch)
+	}
+	if options.Tag != "" {
+		env["TAG_NAME"] = known(options.Tag)
+	}
+	sections := sectionsOf(pipeline)
+	p.parseParameters(sections["parameters"])
+	p.applyParameters()
+	env = withEnvironment(env, sections["environment"])
+
+	if len(p.params) > 0 {
+		plan.Parameters = map[string]string{}
+		for name, param := range p.params {
+			plan.Parameters[name] = fmt.Sprint(param.v)
+		}
+	}
+	if stages := sections["stages"]; stages != nil {
+		plan.Stages = p.planStages(stages, StageStatusRun, env)
+	}
+	return plan
+}
+
+// value is the value of a Groovy expression, it's unknown if it can only be evaluated at runtime
+type value struct {
+	// v is a string, a bool, or nil
+	v     interface{}
+	known bool
+}
+
+func known(v interface{}) value {
+	return value{v: v, known: true}
+}
+
+func (v value) truth() bool {
+	switch t := v.v.(type) {
+	case bool:
+		return t
+	case string:
+		return t != ""
+	}
+	return false
+}
+
+type planner struct {
+	options DryRunOptions
+	params  map[string]value
+	// booleans are the names of the boolean parameters
+	booleans map[string]bool
+}
+
+func sectionsOf(block *statement) map[string]*statement {
+	sections := map[string]*statement{}
+	for _, s := range block.body {
+		if sections[s.name] == nil {
+			sections[s.name] = s
+		}
+	}
+	return sections
+}
+
+// parseParameters takes the default values from the parameters section of the Jenkinsfile
+func (p *planner) parseParameters(parameters *statement) {
+	p.booleans = map[string]bool{}
+	if parameters == nil {
+		return
+	}
+	for _, s := range parameters.body {
+		args := namedArgs(s.tokens)
+		name := stringArg(args["name"])
+		if name == "" {
+			continue
+		}
+		switch s.name {
+		case "booleanParam":
+			p.booleans[name] = true
+			p.params[name] = known(len(args["defaultValue"]) == 1 && args["defaultValue"][0].is(tokenIdent, "true"))
+		case "choice":
+			// the first choice is the default one
+			for _, t := range args["choices"] {
+				if t.kind == tokenString {
+					p.params[name] = known(strings.SplitN(t.value, "\n", 2)[0])
+					break
+				}
+			}
+		default:
+			p.params[name] = known(stringArg(args["defaultValue"]))
+		}
+	}
+}
+
+func (p *planner) applyParameters() {
+	for name, param := range p.options.Parameters {
+		if _, ok := p.params[name].v.(bool); ok || p.booleans[name] {
+			b, _ := strconv.ParseBool(param)
+			p.params[name] = known(b)
+		} else {
+			p.params[name] = known(param)
+		}
+	}
+}
+
+// withEnvironment returns a copy of the environment variables with the literal ones of the environment section
+func withEnvironment(env map[string]value, environment *statement) map[string]value {
+	if environment == nil {
+		return env
+	}
+	result := make(map[string]value, len(env))
+	for k, v := range env {
+		result[k] = v
+	}
+	for _, s := range environment.body {
+		tokens := s.tokens
+		if len(tokens) < 2 || tokens[0].kind != tokenIdent || !tokens[1].is(tokenOther, "=") {
+			continue
+		}
+		if len(tokens) == 3 && tokens[2].kind == tokenString && !strings.Contains(tokens[2].value, "$") {
+			result[tokens[0].value] = known(unescape(tokens[2].value))
+		} else {
+			// it's assigned by a function or an interpolated string, like credentials('id')
+			result[tokens[0].value] = value{}
+		}
+	}
+	return result
+}
+
+func (p *planner) planStages(stages *statement, parent StageStatus, env map[string]value) (planned []PlannedStage) {
+	for _, s := range stages.body {
+		if s.name == "stage" && s.hasBody {
+			planned = append(planned, p.planStage(s, parent, env))
+		}
+	}
+	return
+}
+
+func (p *planner) planStage(stage *statement, parent StageStatus, env map[string]value) PlannedStage {
+	planned := PlannedStage{Name: stage.arg, Line: stage.line, Status: parent}
+	sections := sectionsOf(stage)
+	env = withEnvironment(env, sections["environment"])
+
+	switch parent {
+	case StageStatusSkipped:
+		planned.Reason = "the parent stage is skipped"
+	case StageStatusUndetermined:
+		planned.Reason = "the parent stage is undetermined"
+	}
+	if when := sections["when"]; when != nil && parent != StageStatusSkipped {
+		switch result, reason := p.evalConditions(when.body, env, true); {
+		case !result.known:
+			planned.Status, planned.Reason = StageStatusUndetermined, reason
+		case !result.truth():
+			planned.Status, planned.Reason = StageStatusSkipped, reason
+		}
+	}
+
+	switch {
+	case sections["steps"] != nil:
+		for _, s := range sections["steps"].body {
+			planned.Steps = append(planned.Steps, statementName(s))
+		}
+	case sections["stages"] != nil:
+		planned.Stages = p.planStages(sections["stages"], planned.Status, env)
+	case sections["parallel"] != nil:
+		planned.Parallel = true
+		planned.Stages = p.planStages(sections["parallel"], planned.Status, env)
+	case sections["matrix"] != nil:
+		// the combinations of the axes are not expanded
+		planned.Parallel = true
+		if stages := sectionsOf(sections["matrix"])["stages"]; stages != nil {
+			planned.Stages = p.planStages(stages, planned.Status, env)
+		}
+	}
+	return planned
+}
+
+// evalConditions evaluates the conditions, all of them need to be met if all is true, otherwise any of them.
+// The reason explains the first condition which is not met or cannot be evaluated.
+func (p *planner) evalConditions(conditions []*statement, env map[string]value, all bool) (result value, reason string) {
+	result = known(all)
+	for _, condition := range conditions {
+		switch condition.name {
+		case "beforeAgent", "beforeInput", "beforeOptions":
+			continue
+		}
+		r, why := p.evalCondition(condition, env)
+		switch {
+		case !r.known:
+			if result.known {
+				result, reason = value{}, why
+			}
+		case r.truth() != all:
+			// the result is decided by this condition
+			return known(!all), why
+		}
+	}
+	return
+}
+
+func (p *planner) evalCondition(condition *statement, env map[string]value) (value, string) {
+	args := namedArgs(condition.tokens)
+	notMet := fmt.Sprintf("condition '%s' at line %d is not met", condition.name, condition.line)
+	unknown := func(format string, a ...interface{}) (value, string) {
+		return value{}, fmt.Sprintf("condition '%s' at line %d cannot be evaluated: %s", condition.name,
+			condition.line, fmt.Sprintf(format, a...))
+	}
+	result := func(met bool) (value, string) {
+		if met {
+			return known(true), fmt.Sprintf("condition '%s' at line %d is met", condition.name, condition.line)
+		}
+		return known(false), notMet
+	}
+
+	switch condition.name {
+	case "branch", "tag":
+		actual := p.options.Branch
+		if condition.name == "tag" {
+			actual = p.options.Tag
+		}
+		pattern := stringArg(args["pattern"])
+		if pattern == "" {
+			pattern = condition.arg
+		}
+		if actual == "" {
+			return result(false)
+		}
+		matched, err := compare(pattern, actual, stringArg(args["comparator"]))
+		if err != nil {
+			return unknown("%v", err)
+		}
+		return result(matched)
+	case "buildingTag":
+		return result(p.options.Tag != "")
+	case "changeRequest":
+		if !p.options.ChangeRequest {
+			return result(false)
+		}
+		if len(args) > 0 {
+			return unknown("the attributes of the change request are unknown")
+		}
+		return result(true)
+	case "environment":
+		name := stringArg(args["name"])
+		actual, ok := env[name]
+		if !ok || !actual.known {
+			return unknown("the environment variable '%s' is unknown", name)
+		}
+		return result(actual.v == stringArg(args["value"]))
+	case "equals":
+		expected, why := p.evalExpression(args["expected"], env)
+		if why != "" {
+			return unknown("%s", why)
+		}
+		actual, why := p.evalExpression(args["actual"], env)
+		if why != "" {
+			return unknown("%s", why)
+		}
+		return result(expected.v == actual.v)
+	case "expression":
+		if len(condition.body) == 0 {
+			return unknown("the expression is empty")
+		}
+		// the value of the last statement is the result of a closure
+		tokens := condition.body[len(condition.body)-1].tokens
+		if len(tokens) > 0 && tokens[0].is(tokenIdent, "return") {
+			tokens = tokens[1:]
+		}
+		v, why := p.evalExpression(tokens, env)
+		if why != "" {
+			return unknown("%s", why)
+		}
+		return result(v.truth())
+	case "not":
+		if len(condition.body) != 1 {
+			return unknown("it requires exactly one nested condition")
+		}
+		v, why := p.evalCondition(condition.body[0], env)
+		if v.known {
+			return result(!v.truth())
+		}
+		return v, why
+	case "allOf", "anyOf":
+		v, why := p.evalConditions(condition.body, env, condition.name == "allOf")
+		if v.known && !v.truth() && why == "" {
+			why = notMet
+		}
+		return v, why
+	}
+	return unknown("it depends on the runtime")
+}
+
+// compare matches the actual value with the pattern by the comparator of the branch and tag conditions
+func compare(pattern, actual, comparator string) (bool, error) {
+	switch comparator {
+	case "EQUALS":
+		return pattern == actual, nil
+	case "REGEXP":
+		r, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression '%s'", pattern)
+		}
+		return r.MatchString(actual), nil
+	case "", "GLOB":
+		return globToRegexp(pattern).MatchString(actual), nil
+	}
+	return false, fmt.Errorf("unknown comparator '%s'", comparator)
+}
+
+// globToRegexp converts an Ant style pattern, '**' matches any characters, '*' and '?' do not match '/'
+func globToRegexp(pattern string) *regexp.Regexp {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			builder.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			builder.WriteString("[^/]*")
+		case pattern[i] == '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.MustCompile(builder.String())
+}
+
+// namedArgs returns the tokens of the named arguments by name, like "name: 'a', value: 'b'"
+func namedArgs(tokens []token) map[string][]token {
+	args := map[string][]token{}
+	// skip the name of the method, and the parentheses around the arguments
+	if len(tokens) > 0 && tokens[0].kind == tokenIdent {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 1 && tokens[0].is(tokenPunct, "(") && tokens[len(tokens)-1].is(tokenPunct, ")") {
+		tokens = tokens[1 : len(tokens)-1]
+	}
+
+	depth := 0
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) {
+			switch t := tokens[i]; {
+			case t.is(tokenPunct, "(") || t.is(tokenPunct, "["):
+				depth++
+				continue
+			case t.is(tokenPunct, ")") || t.is(tokenPunct, "]"):
+				depth--
+				continue
+			case depth > 0 || !t.is(tokenOther, ","):
+				continue
+			}
+		}
+		if arg := tokens[start:i]; len(arg) > 2 && arg[0].kind == tokenIdent && arg[1].is(tokenOther, ":") {
+			args[arg[0].value] = arg[2:]
+		}
+		start = i + 1
+	}
+	return args
+}
+
+// stringArg returns the value of an argument if it's a string literal
+func stringArg(tokens []token) string {
+	if len(tokens) == 1 && tokens[0].kind == tokenString {
+		return unescape(tokens[0].value)
+	}
+	return ""
+}
+
+// unescape replaces the escaped backslashes and quotes of a string literal
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`).Replace(s)
+}
+
+// evalExpression evaluates a simple Groovy expression, the reason is not empty if it cannot be evaluated
+func (p *planner) evalExpression(tokens []token, env map[string]value) (value, string) {
+	e := &expression{planner: p, env: env, tokens: mergeOperators(tokens)}
+	if len(e.tokens) == 0 {
+		return value{}, "the expression is empty"
+	}
+	v := e.parseOr()
+	if e.reason == "" && e.pos < len(e.tokens) {
+		e.unsupported()
+	}
+	switch {
+	case e.reason != "":
+		return value{}, e.reason
+	case !v.known:
+		return value{}, e.unknown
+	}
+	return v, ""
+}
+
+// mergeOperators drops the line breaks, and merges the operators and numbers which are split by the tokenizer
+func mergeOperators(tokens []token) (merged []token) {
+	isDigit := func(t token) bool {
+		return t.kind == tokenOther && t.value >= "0" && t.value <= "9"
+	}
+	for _, t := range tokens {
+		if t.kind == tokenNewline {
+			continue
+		}
+		if n := len(merged); n > 0 && t.kind == tokenOther {
+			last := &merged[n-1]
+			switch op := last.value + t.value; {
+			case last.kind == tokenOther && (op == "==" || op == "!=" || op == "&&" || op == "||"):
+				last.value = op
+				continue
+			case isDigit(t) && last.kind == tokenOther && strings.Trim(last.value, "0123456789") == "":
+				last.value = op
+				continue
+			}
+		}
+		merged = append(merged, t)
+	}
+	return
+}
+
+type expression struct {
+	planner *planner
+	env     map[string]value
+	tokens  []token
+	pos     int
+	// reason is the first reason why the expression is not supported
+	reason string
+	// unknown is the first reason why a value is unknown, it's ignored if the result is known anyway
+	unknown string
+}
+
+func (e *expression) peek(value string) bool {
+	return e.pos < len(e.tokens) && e.tokens[e.pos].kind != tokenString && e.tokens[e.pos].value == value
+}
+
+func (e *expression) fail(format string, a ...interface{}) value {
+	if e.reason == "" {
+		e.reason = fmt.Sprintf(format, a...)
+	}
+	return value{}
+}
+
+func (e *expression) unsupported() value {
+	if e.pos >= len(e.tokens) {
+		return e.fail("the expression is incomplete")
+	}
+	return e.fail("unsupported expression near '%s' at line %d", e.tokens[e.pos].value, e.tokens[e.pos].line)
+}
+
+func (e *expression) parseOr() value {
+	left := e.parseAnd()
+	for e.peek("||") {
+		e.pos++
+		right := e.parseAnd()
+		switch {
+		case left.known && left.truth(), right.known && right.truth():
+			left = known(true)
+		case left.known && right.known:
+			left = known(false)
+		default:
+			left = value{}
+		}
+	}
+	return left
+}
+
+func (e *expression) parseAnd() value {
+	left := e.parseUnary()
+	for e.peek("&&") {
+		e.pos++
+		right := e.parseUnary()
+		switch {
+		case left.known && !left.truth(), right.known && !right.truth():
+			left = known(false)
+		case left.known && right.known:
+			left = known(true)
+		default:
+			left = value{}
+		}
+	}
+	return left
+}
+
+func (e *expression) parseUnary() value {
+	if e.peek("!") {
+		e.pos++
+		if v := e.parseUnary(); v.known {
+			return known(!v.truth())
+		}
+		return value{}
+	}
+	left := e.parsePrimary()
+	if e.peek("==") || e.peek("!=") {
+		equal := e.peek("==")
+		e.pos++
+		right := e.parsePrimary()
+		if !left.known || !right.known {
+			return value{}
+		}
+		return known((left.v == right.v) == equal)
+	}
+	return left
+}
+
+func (e *expression) parsePrimary() value {
+	if e.pos >= len(e.tokens) {
+		return e.unsupported()
+	}
+	t := e.tokens[e.pos]
+	switch {
+	case t.is(tokenPunct, "("):
+		e.pos++
+		v := e.parseOr()
+		if !e.peek(")") {
+			return e.unsupported()
+		}
+		e.pos++
+		return v
+	case t.kind == tokenString:
+		e.pos++
+		if strings.Contains(t.value, "$") {
+			return e.fail("the interpolated string at line %d is not supported", t.line)
+		}
+		return known(unescape(t.value))
+	case t.kind == tokenOther && strings.Trim(t.value, "0123456789") == "":
+		e.pos++
+		return known(t.value)
+	case t.kind == tokenIdent:
+		return e.parseProperty()
+	}
+	return e.unsupported()
+}
+
+// parseProperty evaluates the literals, and the properties like params.NAME, env.NAME, or NAME
+func (e *expression) parseProperty() value {
+	name := e.tokens[e.pos].value
+	e.pos++
+	switch name {
+	case "true", "false":
+		return known(name == "true")
+	case "null":
+		return known(nil)
+	}
+
+	var v value
+	switch {
+	case name != "params" && name != "env":
+		// an environment variable could be referenced without the prefix
+		v = e.lookup(e.env, "environment variable", name)
+	case e.peek(".") && e.pos+1 < len(e.tokens) && e.tokens[e.pos+1].kind == tokenIdent:
+		e.pos += 2
+		v = e.property(name, e.tokens[e.pos-1].value)
+	case e.peek("[") && e.pos+2 < len(e.tokens) && e.tokens[e.pos+1].kind == tokenString &&
+		e.tokens[e.pos+2].is(tokenPunct, "]"):
+		e.pos += 3
+		v = e.property(name, e.tokens[e.pos-2].value)
+	default:
+		return e.unsupported()
+	}
+
+	// only the methods which do not change the value or convert it to a boolean are supported
+	for e.peek(".") && e.pos+3 < len(e.tokens) && e.tokens[e.pos+2].is(tokenPunct, "(") &&
+		e.tokens[e.pos+3].is(tokenPunct, ")") {
+		method := e.tokens[e.pos+1].value
+		e.pos += 4
+		switch method {
+		case "toString", "trim":
+		case "toBoolean":
+			if v.known {
+				v = known(strings.TrimSpace(fmt.Sprint(v.v)) == "true")
+			}
+		default:
+			return e.fail("method '%s' is not supported", method)
+		}
+	}
+	return v
+}
+
+func (e *expression) property(object, name string) value {
+	if object == "params" {
+		return e.lookup(e.planner.params, "parameter", name)
+	}
+	return e.lookup(e.env, "environment variable", name)
+}
+
+func (e *expression) lookup(values map[string]value, kind, name string) value {
+	v, ok := values[name]
+	if (!ok || !v.known) && e.unknown == "" {
+		e.unknown = fmt.Sprintf("the %s '%s' is unknown", kind, name)
+	}
+	return v
+}
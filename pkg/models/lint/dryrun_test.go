@@ -0,0 +1,276 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const dryRunJenkinsfile = `pipeline {
+  agent any
+  parameters {
+    booleanParam(name: 'DEPLOY', defaultValue: false)
+    choice(name: 'ENV', choices: ['dev', 'prod'])
+    string(name: 'VERSION', defaultValue: '1.0')
+  }
+  environment {
+    REGION = 'cn'
+    TOKEN = credentials('token')
+  }
+  stages {
+    stage('build') {
+      steps {
+        sh 'make'
+        archiveArtifacts 'bin/*'
+      }
+    }
+    stage('release') {
+      when {
+        branch 'release/*'
+      }
+      stages {
+        stage('tag') {
+          when { buildingTag() }
+          steps { sh 'make tag' }
+        }
+        stage('push') {
+          steps { sh 'make push' }
+        }
+      }
+    }
+    stage('deploy') {
+      when {
+        beforeAgent true
+        expression { return params.DEPLOY && params.ENV != 'dev' }
+      }
+      parallel {
+        stage('cn') {
+          when { environment name: 'REGION', value: 'cn' }
+          steps { sh 'deploy cn' }
+        }
+        stage('us') {
+          when { not { environment name: 'REGION', value: 'cn' } }
+          steps { sh 'deploy us' }
+        }
+      }
+    }
+    stage('notify') {
+      when {
+        anyOf {
+          equals expected: '2.0', actual: params.VERSION
+          expression { env.TOKEN }
+        }
+      }
+      steps { mail to: 'a@b.com' }
+    }
+  }
+}`
+
+func TestDryRunJenkinsfile(t *testing.T) {
+	plan := DryRunJenkinsfile(dryRunJenkinsfile, DryRunOptions{})
+	assert.True(t, plan.Valid)
+	assert.Empty(t, plan.Issues)
+	assert.Equal(t, map[string]string{"DEPLOY": "false", "ENV": "dev", "VERSION": "1.0"}, plan.Parameters)
+	assert.Equal(t, []PlannedStage{{
+		Name: "build", Line: 13, Status: StageStatusRun, Steps: []string{"sh", "archiveArtifacts"},
+	}, {
+		Name: "release", Line: 19, Status: StageStatusSkipped, Reason: "condition 'branch' at line 21 is not met",
+		Stages: []PlannedStage{{
+			Name: "tag", Line: 24, Status: StageStatusSkipped, Reason: "the parent stage is skipped",
+			Steps: []string{"sh"},
+		}, {
+			Name: "push", Line: 28, Status: StageStatusSkipped, Reason: "the parent stage is skipped",
+			Steps: []string{"sh"},
+		}},
+	}, {
+		Name: "deploy", Line: 33, Status: StageStatusSkipped, Reason: "condition 'expression' at line 36 is not met",
+		Parallel: true,
+		Stages: []PlannedStage{{
+			Name: "cn", Line: 39, Status: StageStatusSkipped, Reason: "the parent stage is skipped",
+			Steps: []string{"sh"},
+		}, {
+			Name: "us", Line: 43, Status: StageStatusSkipped, Reason: "the parent stage is skipped",
+			Steps: []string{"sh"},
+		}},
+	}, {
+		Name: "notify", Line: 49, Status: StageStatusUndetermined,
+		Reason: "condition 'expression' at line 53 cannot be evaluated: the environment variable 'TOKEN' is unknown",
+		Steps:  []string{"mail"},
+	}}, plan.Stages)
+
+	plan = DryRunJenkinsfile(dryRunJenkinsfile, DryRunOptions{
+		Branch:     "release/v1",
+		Tag:        "v1.0",
+		Parameters: map[string]string{"DEPLOY": "true", "ENV": "prod", "VERSION": "2.0"},
+	})
+	assert.Equal(t, map[string]string{"DEPLOY": "true", "ENV": "prod", "VERSION": "2.0"}, plan.Parameters)
+	statuses := map[string]StageStatus{}
+	var walk func([]PlannedStage)
+	walk = func(stages []PlannedStage) {
+		for _, stage := range stages {
+			statuses[stage.Name] = stage.Status
+			walk(stage.Stages)
+		}
+	}
+	walk(plan.Stages)
+	assert.Equal(t, map[string]StageStatus{
+		"build":   StageStatusRun,
+		"release": StageStatusRun,
+		"tag":     StageStatusRun,
+		"push":    StageStatusRun,
+		"deploy":  StageStatusRun,
+		"cn":      StageStatusRun,
+		"us":      StageStatusSkipped,
+		"notify":  StageStatusRun,
+	}, statuses)
+}
+
+func TestDryRunJenkinsfileWithInvalidContent(t *testing.T) {
+	plan := DryRunJenkinsfile(" ", DryRunOptions{})
+	assert.False(t, plan.Valid)
+	assert.Equal(t, []Issue{{Severity: SeverityError, Message: "the Jenkinsfile is empty"}}, plan.Issues)
+
+	plan = DryRunJenkinsfile("pipeline {", DryRunOptions{})
+	assert.False(t, plan.Valid)
+	assert.Len(t, plan.Issues, 1)
+	assert.Empty(t, plan.Stages)
+
+	plan = DryRunJenkinsfile("node { sh 'make' }", DryRunOptions{})
+	assert.True(t, plan.Valid)
+	assert.Equal(t, SeverityWarning, plan.Issues[0].Severity)
+	assert.Empty(t, plan.Stages)
+}
+
+func TestDryRunPipeline(t *testing.T) {
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{
+				Parameters: []v1alpha3.ParameterDefinition{{Name: "SKIP", Type: "boolean", DefaultValue: "true"}},
+				Jenkinsfile: `pipeline {
+  agent any
+  stages {
+    stage('test') {
+      when { expression { !params.SKIP } }
+      steps { sh 'make test' }
+    }
+  }
+}`,
+			},
+		},
+	}
+	plan, err := DryRunPipeline(pipeline, DryRunOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, StageStatusSkipped, plan.Stages[0].Status)
+
+	plan, err = DryRunPipeline(pipeline, DryRunOptions{Parameters: map[string]string{"SKIP": "false"}})
+	assert.Nil(t, err)
+	assert.Equal(t, StageStatusRun, plan.Stages[0].Status)
+
+	_, err = DryRunPipeline(&v1alpha3.Pipeline{Spec: v1alpha3.PipelineSpec{Type: v1alpha3.MultiBranchPipelineType}},
+		DryRunOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestEvalCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		options   DryRunOptions
+		want      value
+	}{{
+		name:      "branch equals",
+		condition: "branch pattern: 'main', comparator: 'EQUALS'",
+		options:   DryRunOptions{Branch: "main"},
+		want:      known(true),
+	}, {
+		name:      "branch regexp",
+		condition: "branch pattern: 'feat-\\\\d+', comparator: 'REGEXP'",
+		options:   DryRunOptions{Branch: "feat-12"},
+		want:      known(true),
+	}, {
+		name:      "glob does not cross slashes",
+		condition: "branch 'release-*'",
+		options:   DryRunOptions{Branch: "release-a/b"},
+		want:      known(false),
+	}, {
+		name:      "double star glob",
+		condition: "branch '**/b'",
+		options:   DryRunOptions{Branch: "release/a/b"},
+		want:      known(true),
+	}, {
+		name:      "tag",
+		condition: "tag 'v*'",
+		options:   DryRunOptions{Tag: "v1.0"},
+		want:      known(true),
+	}, {
+		name:      "change request with attributes",
+		condition: "changeRequest target: 'main'",
+		options:   DryRunOptions{ChangeRequest: true},
+		want:      value{},
+	}, {
+		name:      "not a change request",
+		condition: "changeRequest()",
+		want:      known(false),
+	}, {
+		name:      "runtime condition",
+		condition: "triggeredBy 'TimerTrigger'",
+		want:      value{},
+	}, {
+		name:      "comparing numbers",
+		condition: "expression { params.COUNT == 10 || env.BRANCH_NAME == 'main' }",
+		options:   DryRunOptions{Parameters: map[string]string{"COUNT": "10"}},
+		want:      known(true),
+	}, {
+		name:      "boolean conversion",
+		condition: "expression { params['FLAG'].toBoolean() && BRANCH_NAME.trim() != 'dev' }",
+		options:   DryRunOptions{Branch: "main", Parameters: map[string]string{"FLAG": "true"}},
+		want:      known(true),
+	}, {
+		name:      "unsupported method",
+		condition: "expression { params.NAME.startsWith('a') }",
+		options:   DryRunOptions{Parameters: map[string]string{"NAME": "abc"}},
+		want:      value{},
+	}, {
+		name:      "false and unknown",
+		condition: "expression { false && currentBuild.number > 1 }",
+		want:      value{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, issue := tokenize(tt.condition)
+			assert.Nil(t, issue)
+			statements := (&parser{tokens: tokens}).parseStatements()
+			assert.Len(t, statements, 1)
+
+			p := &planner{options: tt.options, params: map[string]value{}}
+			p.parseParameters(nil)
+			p.applyParameters()
+			env := map[string]value{}
+			if tt.options.Branch != "" {
+				env["BRANCH_NAME"] = known(tt.options.Branch)
+			}
+			got, _ := p.evalCondition(statements[0], env)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,459 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenPunct
+	tokenNewline
+	tokenOther
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+func (t token) is(kind tokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// tokenize splits a Groovy script into tokens, comments are dropped
+func tokenize(script string) ([]token, *Issue) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, token{kind: tokenNewline, line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(script[i:], "//"):
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, &Issue{Severity: SeverityError, Line: line, Message: "the comment is not closed"}
+			}
+			line += strings.Count(script[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'' || c == '"':
+			start, startLine := i, line
+			quote := string(c)
+			if strings.HasPrefix(script[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			i += len(quote)
+			closed := false
+			for i < len(script) {
+				if script[i] == '\\' {
+					i += 2
+					continue
+				}
+				if strings.HasPrefix(script[i:], quote) {
+					closed = true
+					break
+				}
+				if script[i] == '\n' {
+					if len(quote) == 1 {
+						break
+					}
+					line++
+				}
+				i++
+			}
+			if !closed {
+				return nil, &Issue{Severity: SeverityError, Line: startLine, Message: "the string is not closed"}
+			}
+			tokens = append(tokens, token{kind: tokenString, value: script[start+len(quote) : i], line: startLine})
+			i += len(quote)
+		case isIdentStart(c):
+			start := i
+			for i < len(script) && (isIdentStart(script[i]) || (script[i] >= '0' && script[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: script[start:i], line: line})
+		case strings.IndexByte("{}()[];", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c), line: line})
+			i++
+		default:
+			tokens = append(tokens, token{kind: tokenOther, value: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+var closingBrackets = map[string]string{"{": "}", "(": ")", "[": "]"}
+
+// checkBrackets makes sure all the brackets are paired
+func checkBrackets(tokens []token) *Issue {
+	var stack []token
+	for _, t := range tokens {
+		if t.kind != tokenPunct {
+			continue
+		}
+		switch t.value {
+		case "{", "(", "[":
+			stack = append(stack, t)
+		case "}", ")", "]":
+			if len(stack) == 0 {
+				return &Issue{Severity: SeverityError, Line: t.line, Message: fmt.Sprintf("unexpected '%s'", t.value)}
+			}
+			open := stack[len(stack)-1]
+			if closingBrackets[open.value] != t.value {
+				return &Issue{Severity: SeverityError, Line: t.line,
+					Message: fmt.Sprintf("expected '%s' to close '%s' at line %d, but got '%s'",
+						closingBrackets[open.value], open.value, open.line, t.value)}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		return &Issue{Severity: SeverityError, Line: open.line, Message: fmt.Sprintf("'%s' is not closed", open.value)}
+	}
+	return nil
+}
+
+// statement is a simplified Groovy statement, like "stage('build') { ... }"
+type statement struct {
+	// name is the leading identifier, it's empty if the statement does not start with an identifier
+	name string
+	// arg is the first string argument
+	arg  string
+	line int
+	// body holds the statements of the trailing closures
+	body    []*statement
+	hasBody bool
+	// tokens are the tokens of the statement except the trailing closures
+	tokens []token
+}
+
+// Node is a simplified Groovy statement of a Jenkinsfile, like "stage('build') { ... }".
+type Node struct {
+	// Name is the leading identifier, it's empty if the statement does not start with an identifier
+	Name string
+	// Arg is the first string argument
+	Arg  string
+	Line int
+	// Body holds the statements of the trailing closures
+	Body    []*Node
+	HasBody bool
+}
+
+// ParseJenkinsfile parses a Jenkinsfile into simplified statements, it returns an issue if the syntax is invalid.
+func ParseJenkinsfile(jenkinsfile string) ([]*Node, *Issue) {
+	tokens, issue := tokenize(jenkinsfile)
+	if issue == nil {
+		issue = checkBrackets(tokens)
+	}
+	if issue != nil {
+		return nil, issue
+	}
+	return toNodes((&parser{tokens: tokens}).parseStatements()), nil
+}
+
+func toNodes(statements []*statement) []*Node {
+	var nodes []*Node
+	for _, s := range statements {
+		nodes = append(nodes, &Node{
+			Name:    s.name,
+			Arg:     s.arg,
+			Line:    s.line,
+			Body:    toNodes(s.body),
+			HasBody: s.hasBody,
+		})
+	}
+	return nodes
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseStatements parses the statements until the end of the current block
+func (p *parser) parseStatements() (statements []*statement) {
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		switch {
+		case t.is(tokenPunct, "}"):
+			p.pos++
+			return
+		case t.kind == tokenNewline || t.is(tokenPunct, ";"):
+			p.pos++
+		default:
+			statements = append(statements, p.parseStatement())
+		}
+	}
+	return
+}
+
+func (p *parser) parseStatement() *statement {
+	s := &statement{line: p.tokens[p.pos].line}
+	if p.tokens[p.pos].kind == tokenIdent {
+		s.name = p.tokens[p.pos].value
+	}
+	depth := 0
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		switch {
+		case t.kind == tokenString:
+			if s.arg == "" {
+				s.arg = t.value
+			}
+		case t.is(tokenPunct, "(") || t.is(tokenPunct, "["):
+			depth++
+		case t.is(tokenPunct, ")") || t.is(tokenPunct, "]"):
+			depth--
+		case t.is(tokenPunct, "{"):
+			p.pos++
+			body := p.parseStatements()
+			if depth == 0 {
+				s.body = append(s.body, body...)
+				s.hasBody = true
+			}
+			continue
+		case depth > 0:
+		case t.is(tokenPunct, "}"):
+			return s
+		case t.kind == tokenNewline || t.is(tokenPunct, ";"):
+			p.pos++
+			return s
+		}
+		s.tokens = append(s.tokens, t)
+		p.pos++
+	}
+	return s
+}
+
+var (
+	pipelineSections = toSet("agent", "environment", "options", "parameters", "triggers", "tools", "stages",
+		"post", "libraries")
+	stageSections = toSet("agent", "environment", "options", "input", "when", "tools", "steps", "stages",
+		"parallel", "matrix", "post", "failFast")
+	postConditions = toSet("always", "changed", "fixed", "regression", "aborted", "failure", "success",
+		"unstable", "unsuccessful", "cleanup")
+	// knownSteps are the common steps provided by the plugins of ks-devops Jenkins
+	knownSteps = toSet(
+		// basic steps
+		"echo", "error", "sh", "bat", "powershell", "pwsh", "script", "sleep", "timeout", "retry", "dir",
+		"deleteDir", "pwd", "writeFile", "readFile", "fileExists", "isUnix", "mail", "tool", "withEnv",
+		"catchError", "warnError", "unstable", "stash", "unstash", "archiveArtifacts", "archive", "unarchive",
+		"input", "build", "milestone", "lock", "node", "ws", "step", "waitUntil", "parallel", "checkout",
+		"git", "svn", "load", "junit", "timestamps", "ansiColor", "cleanWs", "fingerprint", "emailext",
+		"publishHTML", "readJSON", "readYaml", "writeJSON", "writeYaml", "readProperties", "zip", "unzip",
+		"findFiles", "sha1", "touch", "currentBuild",
+		// credentials
+		"withCredentials", "sshagent", "withDockerRegistry", "withKubeConfig", "kubeconfigFile",
+		// kubernetes
+		"container", "podTemplate", "kubernetesDeploy",
+		// sonarqube
+		"withSonarQubeEnv", "waitForQualityGate",
+		// docker
+		"docker", "withDockerContainer", "withDockerServer",
+	)
+)
+
+func toSet(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// checkJenkinsfile checks the syntax of a Jenkinsfile, and the structure if it's a declarative Pipeline
+func checkJenkinsfile(jenkinsfile string) []Issue {
+	tokens, issue := tokenize(jenkinsfile)
+	if issue == nil {
+		issue = checkBrackets(tokens)
+	}
+	if issue != nil {
+		return []Issue{*issue}
+	}
+
+	c := &checker{}
+	statements := (&parser{tokens: tokens}).parseStatements()
+	for _, s := range statements {
+		if s.name == "pipeline" && s.hasBody {
+			if c.pipeline != nil {
+				c.errorf(s.line, "only one 'pipeline' block is allowed")
+				continue
+			}
+			c.pipeline = s
+			c.checkPipeline(s)
+		}
+	}
+	if c.pipeline == nil {
+		c.warnf(0, "the declarative 'pipeline' block is not found, only the syntax is checked")
+	}
+	return c.issues
+}
+
+type checker struct {
+	pipeline   *statement
+	stageNames map[string]bool
+	issues     []Issue
+}
+
+func (c *checker) errorf(line int, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Severity: SeverityError, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(line int, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Severity: SeverityWarning, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) checkPipeline(pipeline *statement) {
+	c.stageNames = map[string]bool{}
+	sections := c.checkSections(pipeline, "pipeline", pipelineSections)
+	for _, required := range []string{"agent", "stages"} {
+		if sections[required] == nil {
+			c.errorf(pipeline.line, "section '%s' is required in 'pipeline'", required)
+		}
+	}
+	if stages := sections["stages"]; stages != nil {
+		c.checkStages(stages)
+	}
+	if post := sections["post"]; post != nil {
+		c.checkPost(post)
+	}
+}
+
+// checkSections checks the sections of a block, and returns the known sections by name
+func (c *checker) checkSections(block *statement, blockName string, allowed map[string]bool) map[string]*statement {
+	sections := map[string]*statement{}
+	for _, s := range block.body {
+		switch {
+		case !allowed[s.name]:
+			c.errorf(s.line, "unknown section '%s' in '%s'", statementName(s), blockName)
+		case sections[s.name] != nil:
+			c.errorf(s.line, "duplicated section '%s' in '%s'", s.name, blockName)
+		default:
+			sections[s.name] = s
+		}
+	}
+	return sections
+}
+
+func (c *checker) checkStages(stages *statement) {
+	if len(stages.body) == 0 {
+		c.errorf(stages.line, "no stage found in '%s'", stages.name)
+	}
+	for _, s := range stages.body {
+		if s.name != "stage" || !s.hasBody {
+			c.errorf(s.line, "only 'stage' is allowed in '%s', but got '%s'", stages.name, statementName(s))
+			continue
+		}
+		c.checkStage(s)
+	}
+}
+
+func (c *checker) checkStage(stage *statement) {
+	if stage.arg == "" {
+		c.errorf(stage.line, "the name of the stage is required")
+	} else if c.stageNames[stage.arg] {
+		c.errorf(stage.line, "duplicated stage name '%s'", stage.arg)
+	}
+	c.stageNames[stage.arg] = true
+
+	sections := c.checkSections(stage, "stage", stageSections)
+	var contents []string
+	for _, name := range []string{"steps", "stages", "parallel", "matrix"} {
+		if sections[name] != nil {
+			contents = append(contents, name)
+		}
+	}
+	if len(contents) != 1 {
+		c.errorf(stage.line, "stage '%s' must have only one of 'steps', 'stages', 'parallel' or 'matrix'", stage.arg)
+	}
+
+	if steps := sections["steps"]; steps != nil {
+		if len(steps.body) == 0 {
+			c.errorf(steps.line, "no step found in stage '%s'", stage.arg)
+		}
+		c.checkSteps(steps.body)
+	}
+	if stages := sections["stages"]; stages != nil {
+		c.checkStages(stages)
+	}
+	if parallel := sections["parallel"]; parallel != nil {
+		c.checkStages(parallel)
+	}
+	if matrix := sections["matrix"]; matrix != nil {
+		for _, s := range matrix.body {
+			if s.name == "stages" {
+				c.checkStages(s)
+			}
+		}
+	}
+	if post := sections["post"]; post != nil {
+		c.checkPost(post)
+	}
+}
+
+func (c *checker) checkPost(post *statement) {
+	for _, s := range post.body {
+		if !postConditions[s.name] || !s.hasBody {
+			c.errorf(s.line, "unknown condition '%s' in 'post'", statementName(s))
+			continue
+		}
+		c.checkSteps(s.body)
+	}
+}
+
+func (c *checker) checkSteps(steps []*statement) {
+	for _, s := range steps {
+		switch {
+		case s.name == "def":
+			c.errorf(s.line, "variable declarations are only allowed in a 'script' block")
+		case s.name == "script":
+			// any Groovy code is allowed in a script block
+		case s.name == "" || !knownSteps[s.name]:
+			c.warnf(s.line, "unknown step '%s'", statementName(s))
+		default:
+			c.checkSteps(s.body)
+		}
+	}
+}
+
+func statementName(s *statement) string {
+	if s.name == "" {
+		return "<expression>"
+	}
+	return s.name
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Severity is the severity level of an issue
+type Severity string
+
+const (
+	// SeverityError indicates the Pipeline cannot work
+	SeverityError Severity = "error"
+	// SeverityWarning indicates the Pipeline might not work as expected
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a Pipeline definition
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Field is the path of the field which has the problem, it's empty for a Jenkinsfile
+	Field string `json:"field,omitempty"`
+	// Line is the line number of the Jenkinsfile, it's zero if unknown
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result is the result of linting a Pipeline definition
+type Result struct {
+	// Valid is false if there is any issue with the error severity
+	Valid  bool    `json:"valid"`
+	Issues []Issue `json:"issues"`
+}
+
+func (r *Result) addIssue(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+func (r *Result) complete() *Result {
+	r.Valid = true
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			r.Valid = false
+			break
+		}
+	}
+	if r.Issues == nil {
+		r.Issues = []Issue{}
+	}
+	return r
+}
+
+// Linter validates Pipeline definitions without creating any resources
+type Linter struct {
+	// Client is used to check the referenced credentials, the checking is skipped if it's nil
+	Client client.Reader
+	// Namespace is the DevOps project which the credentials belong to, the checking is skipped if it's empty
+	Namespace string
+}
+
+// LintJenkinsfile validates the syntax, steps, and the referenced credentials of a Jenkinsfile
+func (l *Linter) LintJenkinsfile(ctx context.Context, jenkinsfile string) (*Result, error) {
+	result := &Result{}
+	if err := l.lintJenkinsfile(ctx, jenkinsfile, "", result); err != nil {
+		return nil, err
+	}
+	return result.complete(), nil
+}
+
+func (l *Linter) lintJenkinsfile(ctx context.Context, jenkinsfile, field string, result *Result) error {
+	if strings.TrimSpace(jenkinsfile) == "" {
+		result.addIssue(Issue{Severity: SeverityError, Field: field, Message: "the Jenkinsfile is empty"})
+		return nil
+	}
+	for _, issue := range checkJenkinsfile(jenkinsfile) {
+		issue.Field = field
+		result.addIssue(issue)
+	}
+	return l.checkCredentials(ctx, FindCredentials(jenkinsfile), field, result)
+}
+
+// FindCredentials returns the literal credential IDs referenced in a Jenkinsfile, and the line numbers of them
+func FindCredentials(jenkinsfile string) map[string]int {
+	credentials := map[string]int{}
+	tokens, issue := tokenize(jenkinsfile)
+	if issue != nil {
+		return credentials
+	}
+	for i := 0; i+2 < len(tokens); i++ {
+		var id token
+		switch {
+		// credentialsId: 'id'
+		case tokens[i].is(tokenIdent, "credentialsId") && tokens[i+1].is(tokenOther, ":"):
+			id = tokens[i+2]
+		// credentials('id')
+		case tokens[i].is(tokenIdent, "credentials") && tokens[i+1].is(tokenPunct, "("):
+			id = tokens[i+2]
+		default:
+			continue
+		}
+		if id.kind != tokenString || id.value == "" || strings.Contains(id.value, "$") {
+			continue
+		}
+		if _, ok := credentials[id.value]; !ok {
+			credentials[id.value] = id.line
+		}
+	}
+	return credentials
+}
+
+func (l *Linter) checkCredentials(ctx context.Context, credentials map[string]int, field string, result *Result) error {
+	if l.Client == nil || l.Namespace == "" {
+		return nil
+	}
+
+	ids := make([]string, 0, len(credentials))
+	for id := range credentials {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		secret := &v1.Secret{}
+		if err := l.Client.Get(ctx, types.NamespacedName{Namespace: l.Namespace, Name: id}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			result.addIssue(Issue{
+				Severity: SeverityError,
+				Field:    field,
+				Line:     credentials[id],
+				Message:  fmt.Sprintf("credential '%s' is not found in DevOps project '%s'", id, l.Namespace),
+			})
+		} else if !strings.HasPrefix(string(secret.Type), v1alpha3.DevOpsCredentialPrefix) {
+			result.addIssue(Issue{
+				Severity: SeverityWarning,
+				Field:    field,
+				Line:     credentials[id],
+				Message:  fmt.Sprintf("secret '%s' is not a DevOps credential", id),
+			})
+		}
+	}
+	return nil
+}
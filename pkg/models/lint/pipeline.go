@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"fmt"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+// LintPipeline validates a Pipeline in YAML format, including the Jenkinsfile inside of it
+func (l *Linter) LintPipeline(ctx context.Context, content string) (result *Result, err error) {
+	result = &Result{}
+	pipeline := &v1alpha3.Pipeline{}
+	if err = yaml.UnmarshalStrict([]byte(content), pipeline); err != nil {
+		result.addIssue(Issue{Severity: SeverityError, Message: fmt.Sprintf("invalid Pipeline: %v", err)})
+		return result.complete(), nil
+	}
+
+	if pipeline.Kind != "" && pipeline.Kind != v1alpha3.ResourceKindPipeline {
+		result.addIssue(Issue{Severity: SeverityError, Field: "kind",
+			Message: fmt.Sprintf("expected kind '%s', but got '%s'", v1alpha3.ResourceKindPipeline, pipeline.Kind)})
+	}
+	if pipeline.Name == "" && pipeline.GenerateName == "" {
+		result.addIssue(Issue{Severity: SeverityError, Field: "metadata.name", Message: "the name is required"})
+	}
+
+	spec := pipeline.Spec
+	switch spec.Type {
+	case v1alpha3.NoScmPipelineType:
+		if spec.Pipeline == nil {
+			result.addIssue(Issue{Severity: SeverityError, Field: "spec.pipeline",
+				Message: fmt.Sprintf("it's required by the type '%s'", spec.Type)})
+			break
+		}
+		if err = l.lintJenkinsfile(ctx, spec.Pipeline.Jenkinsfile, "spec.pipeline.jenkinsfile", result); err != nil {
+			return nil, err
+		}
+	case v1alpha3.MultiBranchPipelineType:
+		if spec.MultiBranchPipeline == nil {
+			result.addIssue(Issue{Severity: SeverityError, Field: "spec.multi_branch_pipeline",
+				Message: fmt.Sprintf("it's required by the type '%s'", spec.Type)})
+			break
+		}
+		if err = l.lintMultiBranchPipeline(ctx, spec.MultiBranchPipeline, result); err != nil {
+			return nil, err
+		}
+	default:
+		result.addIssue(Issue{Severity: SeverityError, Field: "spec.type",
+			Message: fmt.Sprintf("unknown type '%s', expected '%s' or '%s'", spec.Type,
+				v1alpha3.NoScmPipelineType, v1alpha3.MultiBranchPipelineType)})
+	}
+	return result.complete(), nil
+}
+
+func (l *Linter) lintMultiBranchPipeline(ctx context.Context, pipeline *v1alpha3.MultiBranchPipeline, result *Result) error {
+	var field, credentialID string
+	var found bool
+	switch pipeline.SourceType {
+	case v1alpha3.SourceTypeGit:
+		field = "git_source"
+		if source := pipeline.GitSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	case v1alpha3.SourceTypeGithub:
+		field = "github_source"
+		if source := pipeline.GitHubSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	case v1alpha3.SourceTypeGitlab:
+		field = "gitlab_source"
+		if source := pipeline.GitlabSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	case v1alpha3.SourceTypeBitbucket:
+		field = "bitbucket_server_source"
+		if source := pipeline.BitbucketServerSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	case v1alpha3.SourceTypeSVN:
+		field = "svn_source"
+		if source := pipeline.SvnSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	case v1alpha3.SourceTypeSingleSVN:
+		field = "single_svn_source"
+		if source := pipeline.SingleSvnSource; source != nil {
+			found, credentialID = true, source.CredentialId
+		}
+	default:
+		result.addIssue(Issue{Severity: SeverityError, Field: "spec.multi_branch_pipeline.source_type",
+			Message: fmt.Sprintf("unknown source type '%s'", pipeline.SourceType)})
+		return nil
+	}
+
+	field = "spec.multi_branch_pipeline." + field
+	if !found {
+		result.addIssue(Issue{Severity: SeverityError, Field: field,
+			Message: fmt.Sprintf("it's required by the source type '%s'", pipeline.SourceType)})
+		return nil
+	}
+	if pipeline.ScriptPath == "" {
+		result.addIssue(Issue{Severity: SeverityWarning, Field: "spec.multi_branch_pipeline.script_path",
+			Message: "the script path is empty, 'Jenkinsfile' will be used"})
+	}
+	if credentialID == "" {
+		return nil
+	}
+	return l.checkCredentials(ctx, map[string]int{credentialID: 0}, field+".credential_id", result)
+}
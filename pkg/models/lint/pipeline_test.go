@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLinter_LintPipeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantValid  bool
+		wantIssues []Issue
+	}{{
+		name:      "valid Pipeline",
+		wantValid: true,
+		content: `
+apiVersion: devops.kubesphere.io/v1alpha3
+kind: Pipeline
+metadata:
+  name: demo
+spec:
+  type: pipeline
+  pipeline:
+    name: demo
+    jenkinsfile: |
+      pipeline {
+        agent any
+        stages {
+          stage('a') {
+            steps {
+              echo 'a'
+            }
+          }
+        }
+      }
+`,
+		wantIssues: []Issue{},
+	}, {
+		name:    "invalid YAML",
+		content: "spec: [",
+		wantIssues: []Issue{{Severity: SeverityError,
+			Message: "invalid Pipeline: error converting YAML to JSON: yaml: line 1: did not find expected node content"}},
+	}, {
+		name:    "unknown field",
+		content: "metadata:\n  name: demo\nspec:\n  type: pipeline\n  pipelines: {}",
+		wantIssues: []Issue{{Severity: SeverityError,
+			Message: `invalid Pipeline: error unmarshaling JSON: while decoding JSON: json: unknown field "pipelines"`}},
+	}, {
+		name:    "wrong kind, no name and unknown type",
+		content: "kind: PipelineRun\nspec:\n  type: fake",
+		wantIssues: []Issue{
+			{Severity: SeverityError, Field: "kind", Message: "expected kind 'Pipeline', but got 'PipelineRun'"},
+			{Severity: SeverityError, Field: "metadata.name", Message: "the name is required"},
+			{Severity: SeverityError, Field: "spec.type",
+				Message: "unknown type 'fake', expected 'pipeline' or 'multi-branch-pipeline'"},
+		},
+	}, {
+		name:    "missing the Pipeline",
+		content: "metadata:\n  name: demo\nspec:\n  type: pipeline",
+		wantIssues: []Issue{{Severity: SeverityError, Field: "spec.pipeline",
+			Message: "it's required by the type 'pipeline'"}},
+	}, {
+		name:    "invalid Jenkinsfile",
+		content: "metadata:\n  name: demo\nspec:\n  type: pipeline\n  pipeline:\n    name: demo\n    jenkinsfile: 'pipeline {'",
+		wantIssues: []Issue{{Severity: SeverityError, Field: "spec.pipeline.jenkinsfile", Line: 1,
+			Message: "'{' is not closed"}},
+	}, {
+		name:    "missing the multi-branch Pipeline",
+		content: "metadata:\n  name: demo\nspec:\n  type: multi-branch-pipeline",
+		wantIssues: []Issue{{Severity: SeverityError, Field: "spec.multi_branch_pipeline",
+			Message: "it's required by the type 'multi-branch-pipeline'"}},
+	}, {
+		name: "unknown source type",
+		content: `metadata:
+  name: demo
+spec:
+  type: multi-branch-pipeline
+  multi_branch_pipeline:
+    name: demo
+    source_type: fake
+    script_path: Jenkinsfile`,
+		wantIssues: []Issue{{Severity: SeverityError, Field: "spec.multi_branch_pipeline.source_type",
+			Message: "unknown source type 'fake'"}},
+	}, {
+		name: "missing the source",
+		content: `metadata:
+  name: demo
+spec:
+  type: multi-branch-pipeline
+  multi_branch_pipeline:
+    name: demo
+    source_type: github
+    script_path: Jenkinsfile`,
+		wantIssues: []Issue{{Severity: SeverityError, Field: "spec.multi_branch_pipeline.github_source",
+			Message: "it's required by the source type 'github'"}},
+	}, {
+		name: "missing the credential and script path",
+		content: `metadata:
+  name: demo
+spec:
+  type: multi-branch-pipeline
+  multi_branch_pipeline:
+    name: demo
+    source_type: git
+    script_path: ""
+    git_source:
+      url: https://a.com/b.git
+      credential_id: git`,
+		wantIssues: []Issue{{Severity: SeverityWarning, Field: "spec.multi_branch_pipeline.script_path",
+			Message: "the script path is empty, 'Jenkinsfile' will be used"}, {Severity: SeverityError,
+			Field: "spec.multi_branch_pipeline.git_source.credential_id", Message: "credential 'git' is not found in DevOps project 'ns'"}},
+	}}
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linter := &Linter{Client: fake.NewClientBuilder().WithScheme(schema).Build(), Namespace: "ns"}
+			result, err := linter.LintPipeline(context.TODO(), tt.content)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantValid, result.Valid)
+			assert.Equal(t, tt.wantIssues, result.Issues)
+		})
+	}
+}
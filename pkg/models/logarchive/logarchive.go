@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3"
+)
+
+const (
+	// CompressionGzip compresses the chunks with gzip
+	CompressionGzip = "gzip"
+	// CompressionNone stores the chunks as plain text
+	CompressionNone = "none"
+
+	// KeyPrefix is the prefix of the object keys of the archived logs
+	KeyPrefix = "pipelinerun-logs"
+)
+
+// Progress is the progress of archiving the log of a PipelineRun, it's stored in the annotation of the PipelineRun
+type Progress struct {
+	// Prefix is the prefix of the object keys of the chunks
+	Prefix string `json:"prefix"`
+	// Bucket is the bucket of the chunks, it's the default bucket if it's empty
+	Bucket string `json:"bucket,omitempty"`
+	// Compression is how the chunks are compressed
+	Compression string `json:"compression"`
+	// Offset is the size of the log text which has been archived
+	Offset int64 `json:"offset"`
+	// Chunks is the number of the archived chunks
+	Chunks int `json:"chunks"`
+	// SyncTime is the last time when the log was fetched from Jenkins
+	SyncTime *metav1.Time `json:"syncTime,omitempty"`
+	// Completed indicates that the whole log has been archived
+	Completed bool `json:"completed,omitempty"`
+}
+
+// NewProgress creates the progress of a PipelineRun which has not been archived
+func NewProgress(pr *v1alpha3.PipelineRun, compression string) *Progress {
+	return &Progress{
+		Prefix:      path.Join(KeyPrefix, pr.Namespace, pr.Name, string(pr.UID)),
+		Compression: compression,
+	}
+}
+
+// GetProgress returns the progress of archiving the log of a PipelineRun, it's nil if the log has not been archived
+func GetProgress(pr *v1alpha3.PipelineRun) (progress *Progress, err error) {
+	value, ok := pr.Annotations[v1alpha3.PipelineRunLogArchiveAnnoKey]
+	if !ok {
+		return
+	}
+	progress = &Progress{}
+	if err = json.Unmarshal([]byte(value), progress); err != nil {
+		progress = nil
+		err = fmt.Errorf("invalid log archive progress: %v", err)
+	}
+	return
+}
+
+// String returns the progress in JSON
+func (p *Progress) String() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// ChunkKey returns the object key of a chunk, the keys are sorted by the indexes
+func (p *Progress) ChunkKey(index int) string {
+	key := path.Join(p.Prefix, fmt.Sprintf("%06d.log", index))
+	if p.Compression == CompressionGzip {
+		key += ".gz"
+	}
+	return key
+}
+
+// ValidateCompression checks if the compression is supported
+func ValidateCompression(compression string) error {
+	switch compression {
+	case CompressionGzip, CompressionNone:
+		return nil
+	}
+	return fmt.Errorf("unsupported log compression: %q, should be %s or %s", compression, CompressionGzip, CompressionNone)
+}
+
+// compress compresses a chunk of the log
+func (p *Progress) compress(data []byte) ([]byte, error) {
+	if p.Compression != CompressionGzip {
+		return data, nil
+	}
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decompresses a chunk of the log
+func (p *Progress) decompress(data []byte) ([]byte, error) {
+	if p.Compression != CompressionGzip {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return io.ReadAll(reader)
+}
+
+// Upload compresses a chunk of the log and uploads it with the next index, then moves the offset to the next one.
+// Uploading the same chunk again overwrites the object, so it's safe to retry if the progress was not saved.
+func (p *Progress) Upload(storage s3.Interface, data []byte, next int64, options ...s3.UploadOption) (err error) {
+	var compressed []byte
+	if compressed, err = p.compress(data); err != nil {
+		return
+	}
+	if storage, err = s3.InBucket(storage, p.Bucket); err != nil {
+		return
+	}
+	key := p.ChunkKey(p.Chunks)
+	if err = storage.Upload(key, path.Base(key), bytes.NewReader(compressed), options...); err == nil {
+		p.Chunks++
+		p.Offset = next
+	}
+	return
+}
+
+// Copy downloads the archived chunks one by one, then writes the decompressed log into the writer
+func (p *Progress) Copy(storage s3.Interface, writer io.Writer) (err error) {
+	if storage, err = s3.InBucket(storage, p.Bucket); err != nil {
+		return
+	}
+	for i := 0; i < p.Chunks; i++ {
+		var data []byte
+		if data, err = storage.Read(p.ChunkKey(i)); err != nil {
+			return
+		}
+		if data, err = p.decompress(data); err != nil {
+			return
+		}
+		if _, err = writer.Write(data); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/s3/fake"
+)
+
+func TestProgress(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pr", UID: "uid"}}
+	progress, err := GetProgress(pr)
+	assert.Nil(t, err)
+	assert.Nil(t, progress)
+
+	pr.Annotations = map[string]string{v1alpha3.PipelineRunLogArchiveAnnoKey: "invalid"}
+	_, err = GetProgress(pr)
+	assert.NotNil(t, err)
+
+	for _, compression := range []string{CompressionGzip, CompressionNone} {
+		t.Run(compression, func(t *testing.T) {
+			storage := fake.NewFakeS3()
+			progress := NewProgress(pr, compression)
+			assert.Nil(t, progress.Upload(storage, []byte("line 1\n"), 7))
+			// the chunk is overwritten if the progress was not saved
+			retried := *progress
+			assert.Nil(t, progress.Upload(storage, []byte("line 2\n"), 14))
+			assert.Nil(t, retried.Upload(storage, []byte("line 2\n"), 14))
+			assert.Equal(t, 2, len(storage.Storage))
+
+			pr.Annotations[v1alpha3.PipelineRunLogArchiveAnnoKey] = progress.String()
+			saved, err := GetProgress(pr)
+			assert.Nil(t, err)
+			assert.Equal(t, progress, saved)
+			assert.Equal(t, int64(14), saved.Offset)
+
+			buf := &bytes.Buffer{}
+			assert.Nil(t, saved.Copy(storage, buf))
+			assert.Equal(t, "line 1\nline 2\n", buf.String())
+		})
+	}
+	assert.Equal(t, "pipelinerun-logs/ns/pr/uid/000001.log.gz", NewProgress(pr, CompressionGzip).ChunkKey(1))
+	assert.Equal(t, "pipelinerun-logs/ns/pr/uid/000001.log", NewProgress(pr, CompressionNone).ChunkKey(1))
+}
+
+func TestProgressInBucket(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pr", UID: "uid"}}
+	storage := fake.NewFakeS3()
+	progress := NewProgress(pr, CompressionNone)
+	progress.Bucket = "tenant-a"
+	assert.Nil(t, progress.Upload(storage, []byte("line 1\n"), 7))
+	assert.Empty(t, storage.Storage)
+	assert.Contains(t, storage.Buckets["tenant-a"].Storage, "pipelinerun-logs/ns/pr/uid/000000.log")
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, progress.Copy(storage, buf))
+	assert.Equal(t, "line 1\n", buf.String())
+}
+
+func TestValidateCompression(t *testing.T) {
+	assert.Nil(t, ValidateCompression(CompressionGzip))
+	assert.Nil(t, ValidateCompression(CompressionNone))
+	assert.NotNil(t, ValidateCompression("zstd"))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logmask
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/credential"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Mask is the text which replaces the secrets
+const Mask = "****"
+
+const (
+	// minValueLength is the minimum length of a credential value to mask, shorter values are too common to mask
+	minValueLength = 4
+	// minFieldLength is the minimum length of a field in a multi-line credential value to mask
+	minFieldLength = 16
+	// secretGroup is the name of the sub-match to mask in a pattern, the whole match is masked if it does not exist
+	secretGroup = "secret"
+)
+
+// DefaultPatterns are the patterns of well-known secrets
+var DefaultPatterns = []*regexp.Regexp{
+	// AWS access key ID
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// AWS secret access key
+	regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`),
+	// JSON Web Token
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`),
+	// GitHub token
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	// private key
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+}
+
+// publicKeys are the keys of credential data which are not secrets
+var publicKeys = map[string]bool{
+	v1alpha3.BasicAuthUsernameKey:    true,
+	v1alpha3.DockerRegistryServerKey: true,
+}
+
+// Masker masks the secrets in logs
+type Masker struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// New creates a Masker which masks the given values and the texts matched the patterns
+func New(values []string, patterns ...*regexp.Regexp) *Masker {
+	unique := map[string]bool{}
+	masker := &Masker{patterns: patterns}
+	for _, value := range values {
+		if len(value) >= minValueLength && !unique[value] {
+			unique[value] = true
+			masker.values = append(masker.values, value)
+		}
+	}
+	// mask the longer values first, in case a value contains another one
+	sort.SliceStable(masker.values, func(i, j int) bool {
+		return len(masker.values[i]) > len(masker.values[j])
+	})
+	return masker
+}
+
+// Mask replaces the secrets in the log with the mask
+func (m *Masker) Mask(log []byte) []byte {
+	if m == nil || len(log) == 0 {
+		return log
+	}
+	text := string(log)
+	for _, value := range m.values {
+		text = strings.ReplaceAll(text, value, Mask)
+	}
+	for _, pattern := range m.patterns {
+		text = maskPattern(text, pattern)
+	}
+	return []byte(text)
+}
+
+// maskPattern masks the sub-match named secret, or the whole match of the pattern
+func maskPattern(text string, pattern *regexp.Regexp) string {
+	group := pattern.SubexpIndex(secretGroup)
+	var builder strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+		if group > 0 && match[2*group] >= 0 {
+			start, end = match[2*group], match[2*group+1]
+		}
+		builder.WriteString(text[last:start])
+		builder.WriteString(Mask)
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// GetCredentialValues returns the secret values of a credential. Each long field of a multi-line value is
+// returned as well, because the value might be printed line by line, like a kubeconfig.
+func GetCredentialValues(secret *v1.Secret) (values []string) {
+	for key, data := range secret.Data {
+		if publicKeys[key] {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		values = append(values, value)
+		if !strings.Contains(value, "\n") {
+			continue
+		}
+		for _, field := range strings.Fields(value) {
+			if len(field) >= minFieldLength {
+				values = append(values, field)
+			}
+		}
+	}
+	return
+}
+
+// SecretGetter gets a secret by its name
+type SecretGetter func(name string) (*v1.Secret, error)
+
+// ForPipeline creates a Masker for the logs of a Pipeline, it masks the credentials referenced by the Pipeline
+// and the default patterns
+func ForPipeline(spec *v1alpha3.PipelineSpec, getSecret SecretGetter) (*Masker, error) {
+	var values []string
+	for name := range credential.GetReferencedCredentials(spec) {
+		secret, err := getSecret(name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		values = append(values, GetCredentialValues(secret)...)
+	}
+	return New(values, DefaultPatterns...), nil
+}
+
+// ForPipelineRun creates a Masker for the logs of a PipelineRun, it masks the credentials referenced by the Pipeline
+// which the PipelineRun was created from
+func ForPipelineRun(ctx context.Context, reader client.Reader, pr *v1alpha3.PipelineRun) (*Masker, error) {
+	spec := pr.Spec.PipelineSpec
+	if spec == nil {
+		pipeline := &v1alpha3.Pipeline{}
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: pr.Namespace, Name: pr.Labels[v1alpha3.PipelineNameLabelKey]},
+			pipeline); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		spec = &pipeline.Spec
+	}
+	return ForPipeline(spec, func(name string) (*v1.Secret, error) {
+		secret := &v1.Secret{}
+		err := reader.Get(ctx, client.ObjectKey{Namespace: pr.Namespace, Name: name}, secret)
+		return secret, err
+	})
+}
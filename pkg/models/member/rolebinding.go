@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package member
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewRoleBinding creates a RoleBinding which binds a user to a Role in the namespace of DevOpsProject
+func NewRoleBinding(namespace, name, username, role string, labels map[string]string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     role,
+		},
+		Subjects: []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.UserKind,
+			Name:     username,
+		}},
+	}
+}
+
+// SyncRoleBindings makes the RoleBindings which have the label key in the namespace consistent with the desired ones.
+// The RoleBindings without the label key are never touched, so the members who were added by the other ways are kept.
+func SyncRoleBindings(ctx context.Context, c client.Client, namespace, labelKey string,
+	desired []*rbacv1.RoleBinding) (created, revoked []string, err error) {
+	desiredMap := make(map[string]*rbacv1.RoleBinding, len(desired))
+	for i := range desired {
+		desiredMap[desired[i].Name] = desired[i]
+	}
+
+	existing := &rbacv1.RoleBindingList{}
+	if err = c.List(ctx, existing, client.InNamespace(namespace), client.HasLabels{labelKey}); err != nil {
+		return
+	}
+	for i := range existing.Items {
+		item := &existing.Items[i]
+		if binding, ok := desiredMap[item.Name]; ok && item.Labels[labelKey] == binding.Labels[labelKey] &&
+			item.RoleRef == binding.RoleRef {
+			delete(desiredMap, item.Name)
+			continue
+		}
+		if err = c.Delete(ctx, item); err != nil && !apierrors.IsNotFound(err) {
+			return
+		}
+		revoked = append(revoked, item.Name)
+	}
+
+	for i := range desired {
+		if _, ok := desiredMap[desired[i].Name]; !ok {
+			continue
+		}
+		if err = c.Create(ctx, desired[i]); err != nil {
+			return
+		}
+		created = append(created, desired[i].Name)
+	}
+	return
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/detector"
+)
+
+// BranchPrefix is the prefix of the branches which add the Jenkinsfiles of the onboarded repositories
+const BranchPrefix = "onboarding/"
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// PullRequest is the pull request which adds the Jenkinsfile to the repository
+type PullRequest struct {
+	Number int    `json:"number"`
+	Link   string `json:"link,omitempty"`
+	Branch string `json:"branch"`
+}
+
+// Suggestion is the suggested Pipeline of a repository which is generated from a ClusterTemplate
+type Suggestion struct {
+	// Language is the detected language of the repository, it's empty if the language is unknown
+	Language    string             `json:"language,omitempty"`
+	Template    string             `json:"template"`
+	Pipeline    *v1alpha3.Pipeline `json:"pipeline"`
+	Jenkinsfile string             `json:"jenkinsfile"`
+	PullRequest *PullRequest       `json:"pullRequest,omitempty"`
+
+	// Detection is the toolchains of the repository, their parameters are passed to the template
+	Detection *detector.Result `json:"detection,omitempty"`
+}
+
+// SelectTemplate returns the ClusterTemplate which bootstraps the Pipelines of the language, the one with the
+// smallest name wins if there are several. It returns nil if there is none.
+func SelectTemplate(templates []v1alpha3.ClusterTemplate, language string) *v1alpha3.ClusterTemplate {
+	var candidates []*v1alpha3.ClusterTemplate
+	for i := range templates {
+		if value, ok := templates[i].Labels[v1alpha3.OnboardingLanguageLabelKey]; ok && value == language {
+			candidates = append(candidates, &templates[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0]
+}
+
+// GetPipelineName returns a Pipeline name from the URL of a repository, such as app of https://github.com/org/app.git
+func GetPipelineName(repoURL string) string {
+	name := strings.ToLower(strings.TrimSuffix(path.Base(strings.TrimRight(repoURL, "/")), ".git"))
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+}
+
+// NewPipeline returns a multi-branch Pipeline which loads the Jenkinsfile from the repository
+func NewPipeline(namespace, name, repoURL, credentialID, scriptPath string) *v1alpha3.Pipeline {
+	return &v1alpha3.Pipeline{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha3.GroupVersion.String(),
+			Kind:       v1alpha3.ResourceKindPipeline,
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+				Name:       name,
+				SourceType: v1alpha3.SourceTypeGit,
+				GitSource: &v1alpha3.GitSource{
+					Url:              repoURL,
+					CredentialId:     credentialID,
+					DiscoverBranches: true,
+				},
+				ScriptPath: scriptPath,
+			},
+		},
+	}
+}
+
+// OpenPullRequest creates a branch from the base branch, commits the file into it, and opens a pull request
+func OpenPullRequest(ctx context.Context, c *scm.Client, provider, repo, base, branch, filePath string,
+	content []byte) (pr *PullRequest, err error) {
+	var baseRef *scm.Reference
+	if baseRef, _, err = c.Git.FindBranch(ctx, repo, base); err != nil {
+		return nil, fmt.Errorf("failed to find the branch %s of %s, error: %v", base, repo, err)
+	}
+	ref := branch
+	if provider == "github" {
+		// GitHub creates the fully qualified references, GitLab creates the branches by their names
+		ref = "refs/heads/" + branch
+	}
+	if _, _, err = c.Git.CreateRef(ctx, repo, ref, baseRef.Sha); err != nil {
+		return nil, fmt.Errorf("failed to create the branch %s of %s, error: %v", branch, repo, err)
+	}
+	if _, err = c.Contents.Create(ctx, repo, filePath, &scm.ContentParams{
+		Branch:  branch,
+		Message: fmt.Sprintf("Add %s", filePath),
+		Data:    content,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to commit %s to %s, error: %v", filePath, repo, err)
+	}
+
+	var created *scm.PullRequest
+	if created, _, err = c.PullRequests.Create(ctx, repo, &scm.PullRequestInput{
+		Title: fmt.Sprintf("Add %s", filePath),
+		Head:  branch,
+		Base:  base,
+		Body:  fmt.Sprintf("The %s is generated by the onboarding of KubeSphere DevOps.", filePath),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to open the pull request of %s, error: %v", repo, err)
+	}
+	return &PullRequest{Number: created.Number, Link: created.Link, Branch: branch}, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestSelectTemplate(t *testing.T) {
+	newTemplate := func(name, language string) v1alpha3.ClusterTemplate {
+		return v1alpha3.ClusterTemplate{ObjectMeta: metav1.ObjectMeta{Name: name,
+			Labels: map[string]string{v1alpha3.OnboardingLanguageLabelKey: language}}}
+	}
+	templates := []v1alpha3.ClusterTemplate{
+		newTemplate("go-b", "go"),
+		newTemplate("go-a", "go"),
+		newTemplate("java", "java"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+	}
+	assert.Equal(t, "go-a", SelectTemplate(templates, "go").Name)
+	assert.Equal(t, "java", SelectTemplate(templates, "java").Name)
+	assert.Nil(t, SelectTemplate(templates, "python"))
+	assert.Nil(t, SelectTemplate(templates, ""))
+}
+
+func TestGetPipelineName(t *testing.T) {
+	assert.Equal(t, "app", GetPipelineName("https://github.com/org/app.git"))
+	assert.Equal(t, "my-app", GetPipelineName("https://gitlab.com/org/My_App/"))
+	assert.Equal(t, "", GetPipelineName(""))
+}
+
+func TestNewPipeline(t *testing.T) {
+	pipeline := NewPipeline("ns", "app", "https://github.com/org/app", "git", "Jenkinsfile")
+	assert.Equal(t, v1alpha3.MultiBranchPipelineType, pipeline.Spec.Type)
+	assert.Equal(t, "https://github.com/org/app", pipeline.Spec.MultiBranchPipeline.GetGitURL())
+	assert.Equal(t, "git", pipeline.Spec.MultiBranchPipeline.GetCredentialID())
+	assert.Equal(t, "Jenkinsfile", pipeline.Spec.MultiBranchPipeline.ScriptPath)
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/org/app/branches/main":
+			_, _ = w.Write([]byte(`{"name":"main","commit":{"sha":"c1"}}`))
+		case "POST /repos/org/app/git/refs":
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string]string{"ref": "refs/heads/onboarding/app", "sha": "c1"}, body)
+			_, _ = w.Write([]byte(`{"ref":"refs/heads/onboarding/app","object":{"sha":"c1"}}`))
+		case "PUT /repos/org/app/contents/Jenkinsfile":
+			_, _ = w.Write([]byte(`{}`))
+		case "POST /repos/org/app/pulls":
+			_, _ = w.Write([]byte(`{"number":3,"html_url":"https://github.com/org/app/pull/3"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c, err := github.New(server.URL)
+	assert.Nil(t, err)
+
+	pr, err := OpenPullRequest(context.Background(), c, "github", "org/app", "main", "onboarding/app",
+		"Jenkinsfile", []byte("pipeline {}"))
+	assert.Nil(t, err)
+	assert.Equal(t, &PullRequest{Number: 3, Link: "https://github.com/org/app/pull/3", Branch: "onboarding/app"}, pr)
+	assert.Equal(t, []string{"GET /repos/org/app/branches/main", "POST /repos/org/app/git/refs",
+		"PUT /repos/org/app/contents/Jenkinsfile", "POST /repos/org/app/pulls"}, requests)
+
+	_, err = OpenPullRequest(context.Background(), c, "github", "org/app", "dev", "onboarding/app",
+		"Jenkinsfile", []byte("pipeline {}"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// DefaultDays is the number of days without PipelineRuns after which a Pipeline is stale
+const DefaultDays = 30
+
+// Reason is the reason why a Pipeline is listed in the stale report
+type Reason string
+
+const (
+	// ReasonNoRecentRuns means the Pipeline has not run in the days of the report
+	ReasonNoRecentRuns Reason = "NoRecentRuns"
+	// ReasonNoOwner means the owner or the team of the Pipeline is not set
+	ReasonNoOwner Reason = "NoOwner"
+	// ReasonInvalidOwner means the owner is not a member of the team, or the team doesn't exist
+	ReasonInvalidOwner Reason = "InvalidOwner"
+)
+
+// StalePipeline is a Pipeline which should be cleaned up or handed over
+type StalePipeline struct {
+	Namespace string                      `json:"namespace"`
+	Name      string                      `json:"name"`
+	Ownership *v1alpha3.PipelineOwnership `json:"ownership,omitempty"`
+	// LastRunTime is the time of the latest PipelineRun, it's empty if the Pipeline never ran
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	Reasons     []Reason     `json:"reasons"`
+}
+
+// Report lists the Pipelines which have no runs in the last days or have no valid owner
+type Report struct {
+	Days  int             `json:"days"`
+	Items []StalePipeline `json:"items"`
+}
+
+// Validate checks the ownership of a Pipeline against the members of the LDAP groups, it returns the reason
+// if the ownership is invalid
+func Validate(ownership *v1alpha3.PipelineOwnership, members map[string][]string) (valid bool, reason string) {
+	if ownership == nil || ownership.Owner == "" || ownership.Team == "" {
+		return false, "the owner and the team are required"
+	}
+	users, ok := members[ownership.Team]
+	if !ok {
+		return false, fmt.Sprintf("the team %s does not exist", ownership.Team)
+	}
+	for _, user := range users {
+		if user == ownership.Owner {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("%s is not a member of the team %s", ownership.Owner, ownership.Team)
+}
+
+// IsInvalid returns true if the current ownership of a Pipeline was validated and it's invalid
+func IsInvalid(pipeline *v1alpha3.Pipeline) bool {
+	ownership, status := pipeline.Spec.Ownership, pipeline.Status.Ownership
+	return ownership != nil && status != nil && !status.Valid &&
+		status.Owner == ownership.Owner && status.Team == ownership.Team
+}
+
+// GetReport returns the Pipelines which have no PipelineRuns in the last days or have no valid owner.
+// The Pipelines created in the last days are not considered as stale even if they never ran.
+func GetReport(pipelines []v1alpha3.Pipeline, pipelineRuns []v1alpha3.PipelineRun, days int, now time.Time) *Report {
+	lastRunTimes := map[string]*metav1.Time{}
+	observe := func(namespace, pipeline string, t *metav1.Time) {
+		key := namespace + "/" + pipeline
+		if t != nil && !t.IsZero() && (lastRunTimes[key] == nil || lastRunTimes[key].Before(t)) {
+			lastRunTimes[key] = t.DeepCopy()
+		}
+	}
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		observe(pr.Namespace, pr.Labels[v1alpha3.PipelineNameLabelKey], &pr.CreationTimestamp)
+	}
+	for i := range pipelines {
+		// the PipelineRuns might have been cleaned up, the summary keeps the latest ones
+		if runs := pipelines[i].Status.Runs; runs != nil {
+			for j := range runs.LatestRuns {
+				observe(pipelines[i].Namespace, pipelines[i].Name, runs.LatestRuns[j].StartTime)
+			}
+		}
+	}
+
+	since := metav1.NewTime(now.Add(-time.Duration(days) * 24 * time.Hour))
+	report := &Report{Days: days, Items: []StalePipeline{}}
+	for i := range pipelines {
+		pipeline := &pipelines[i]
+		if !pipeline.DeletionTimestamp.IsZero() {
+			continue
+		}
+		item := StalePipeline{
+			Namespace:   pipeline.Namespace,
+			Name:        pipeline.Name,
+			Ownership:   pipeline.Spec.Ownership,
+			LastRunTime: lastRunTimes[pipeline.Namespace+"/"+pipeline.Name],
+		}
+		lastActive := item.LastRunTime
+		if lastActive == nil {
+			lastActive = &pipeline.CreationTimestamp
+		}
+		if lastActive.Before(&since) {
+			item.Reasons = append(item.Reasons, ReasonNoRecentRuns)
+		}
+		if ownership := pipeline.Spec.Ownership; ownership == nil || ownership.Owner == "" || ownership.Team == "" {
+			item.Reasons = append(item.Reasons, ReasonNoOwner)
+		} else if IsInvalid(pipeline) {
+			item.Reasons = append(item.Reasons, ReasonInvalidOwner)
+		}
+		if len(item.Reasons) > 0 {
+			report.Items = append(report.Items, item)
+		}
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		if report.Items[i].Namespace != report.Items[j].Namespace {
+			return report.Items[i].Namespace < report.Items[j].Namespace
+		}
+		return report.Items[i].Name < report.Items[j].Name
+	})
+	return report
+}
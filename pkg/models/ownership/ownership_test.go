@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestValidate(t *testing.T) {
+	members := map[string][]string{"devs": {"alice", "bob"}}
+	tests := []struct {
+		name      string
+		ownership *v1alpha3.PipelineOwnership
+		wantValid bool
+	}{{
+		name:      "valid",
+		ownership: &v1alpha3.PipelineOwnership{Owner: "alice", Team: "devs"},
+		wantValid: true,
+	}, {
+		name: "no ownership",
+	}, {
+		name:      "no team",
+		ownership: &v1alpha3.PipelineOwnership{Owner: "alice"},
+	}, {
+		name:      "team not found",
+		ownership: &v1alpha3.PipelineOwnership{Owner: "alice", Team: "ops"},
+	}, {
+		name:      "not a member",
+		ownership: &v1alpha3.PipelineOwnership{Owner: "carol", Team: "devs"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, reason := Validate(tt.ownership, members)
+			assert.Equal(t, tt.wantValid, valid)
+			assert.Equal(t, tt.wantValid, reason == "", reason)
+		})
+	}
+}
+
+func TestGetReport(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) metav1.Time {
+		return metav1.NewTime(now.Add(-time.Duration(days) * 24 * time.Hour))
+	}
+	owned := &v1alpha3.PipelineOwnership{Owner: "alice", Team: "devs"}
+	newPipeline := func(name string, created int, ownership *v1alpha3.PipelineOwnership) v1alpha3.Pipeline {
+		return v1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, CreationTimestamp: daysAgo(created)},
+			Spec:       v1alpha3.PipelineSpec{Ownership: ownership},
+		}
+	}
+	newPipelineRun := func(pipeline string, created int) v1alpha3.PipelineRun {
+		return v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              pipeline + "-run",
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: pipeline},
+			CreationTimestamp: daysAgo(created),
+		}}
+	}
+
+	active := newPipeline("active", 100, owned)
+	summarized := newPipeline("summarized", 100, owned)
+	startTime := daysAgo(5)
+	summarized.Status.Runs = &v1alpha3.PipelineRunsSummary{LatestRuns: []v1alpha3.BranchRunSummary{{Name: "a", StartTime: &startTime}}}
+	invalid := newPipeline("invalid", 1, &v1alpha3.PipelineOwnership{Owner: "bob", Team: "devs"})
+	invalid.Status.Ownership = &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Reason: "not a member"}
+	outdated := newPipeline("outdated", 1, owned)
+	outdated.Status.Ownership = &v1alpha3.OwnershipStatus{Owner: "bob", Team: "devs", Reason: "not a member"}
+	pipelines := []v1alpha3.Pipeline{
+		newPipeline("unowned", 100, nil),
+		active,
+		summarized,
+		newPipeline("idle", 100, owned),
+		newPipeline("new", 1, nil),
+		invalid,
+		outdated,
+	}
+	pipelineRuns := []v1alpha3.PipelineRun{
+		newPipelineRun("active", 50),
+		newPipelineRun("active", 2),
+		newPipelineRun("idle", 40),
+	}
+
+	report := GetReport(pipelines, pipelineRuns, 30, now)
+	assert.Equal(t, 30, report.Days)
+	reasons := map[string][]Reason{}
+	for _, item := range report.Items {
+		reasons[item.Name] = item.Reasons
+	}
+	assert.Equal(t, map[string][]Reason{
+		"idle":    {ReasonNoRecentRuns},
+		"invalid": {ReasonInvalidOwner},
+		"new":     {ReasonNoOwner},
+		"unowned": {ReasonNoRecentRuns, ReasonNoOwner},
+	}, reasons)
+	assert.Equal(t, "idle", report.Items[0].Name)
+	lastRunTime := daysAgo(40)
+	assert.Equal(t, &lastRunTime, report.Items[0].LastRunTime)
+	assert.Nil(t, report.Items[3].LastRunTime)
+
+	// an empty list rather than null
+	assert.NotNil(t, GetReport(nil, nil, 30, now).Items)
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packagemanager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const (
+	// MavenSettingsKey is the key of the settings.xml of Maven in the rendered Secret
+	MavenSettingsKey = "settings.xml"
+	// NPMConfigKey is the key of the .npmrc of npm in the rendered Secret
+	NPMConfigKey = ".npmrc"
+	// MountPath is the directory which the rendered Secret is mounted to in the agent containers
+	MountPath = "/etc/devops/packages"
+)
+
+// GetSecretName returns the name of the Secret which contains the package manager settings of a DevOpsProject, it's
+// in the namespace of the Jenkins agent pods
+func GetSecretName(project string) string {
+	return "packages-" + project
+}
+
+// CredentialGetter returns the Secret of a credential in the DevOpsProject by its name
+type CredentialGetter func(name string) (*v1.Secret, error)
+
+// Render renders the configuration into the files of the package managers, the keys are the names of the files
+func Render(config *v1alpha3.PackageManagers, getCredential CredentialGetter) (data map[string][]byte, err error) {
+	data = map[string][]byte{}
+	if config.Maven != nil || config.Proxy != nil {
+		if data[MavenSettingsKey], err = RenderMavenSettings(config, getCredential); err != nil {
+			return
+		}
+	}
+	if config.NPM != nil || config.Proxy != nil {
+		data[NPMConfigKey], err = RenderNPMConfig(config, getCredential)
+	}
+	return
+}
+
+type mavenSettings struct {
+	XMLName        xml.Name      `xml:"settings"`
+	XMLNS          string        `xml:"xmlns,attr"`
+	XSI            string        `xml:"xmlns:xsi,attr"`
+	SchemaLocation string        `xml:"xsi:schemaLocation,attr"`
+	Servers        []mavenServer `xml:"servers>server,omitempty"`
+	Mirrors        []mavenMirror `xml:"mirrors>mirror,omitempty"`
+	Proxies        []mavenProxy  `xml:"proxies>proxy,omitempty"`
+}
+
+type mavenServer struct {
+	ID       string `xml:"id"`
+	Username string `xml:"username"`
+	Password string `xml:"password"`
+}
+
+type mavenMirror struct {
+	ID       string `xml:"id"`
+	MirrorOf string `xml:"mirrorOf"`
+	URL      string `xml:"url"`
+}
+
+type mavenProxy struct {
+	ID            string `xml:"id"`
+	Active        bool   `xml:"active"`
+	Protocol      string `xml:"protocol"`
+	Host          string `xml:"host"`
+	Port          int32  `xml:"port"`
+	Username      string `xml:"username,omitempty"`
+	Password      string `xml:"password,omitempty"`
+	NonProxyHosts string `xml:"nonProxyHosts,omitempty"`
+}
+
+// RenderMavenSettings renders the settings.xml of Maven. The credentials of the mirrors are rendered as the servers
+// whose IDs are the IDs of the mirrors.
+func RenderMavenSettings(config *v1alpha3.PackageManagers, getCredential CredentialGetter) ([]byte, error) {
+	settings := &mavenSettings{
+		XMLNS:          "http://maven.apache.org/SETTINGS/1.0.0",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://maven.apache.org/SETTINGS/1.0.0 https://maven.apache.org/xsd/settings-1.0.0.xsd",
+	}
+	if maven := config.Maven; maven != nil {
+		for _, mirror := range maven.Mirrors {
+			settings.Mirrors = append(settings.Mirrors, mavenMirror{ID: mirror.ID, MirrorOf: mirror.GetMirrorOf(), URL: mirror.URL})
+			if mirror.CredentialID == "" {
+				continue
+			}
+			server, err := newMavenServer(mirror.ID, mirror.CredentialID, getCredential)
+			if err != nil {
+				return nil, err
+			}
+			settings.Servers = append(settings.Servers, server)
+		}
+		for _, item := range maven.Servers {
+			server, err := newMavenServer(item.ID, item.CredentialID, getCredential)
+			if err != nil {
+				return nil, err
+			}
+			settings.Servers = append(settings.Servers, server)
+		}
+	}
+	if proxy := config.Proxy; proxy != nil {
+		var username, password string
+		if proxy.CredentialID != "" {
+			var err error
+			if username, password, err = getBasicAuth(proxy.CredentialID, getCredential); err != nil {
+				return nil, err
+			}
+		}
+		// Maven selects the proxy by the protocol of the repository
+		for _, protocol := range []string{"http", "https"} {
+			settings.Proxies = append(settings.Proxies, mavenProxy{
+				ID:            "proxy-" + protocol,
+				Active:        true,
+				Protocol:      protocol,
+				Host:          proxy.Host,
+				Port:          proxy.Port,
+				Username:      username,
+				Password:      password,
+				NonProxyHosts: strings.Join(proxy.NonProxyHosts, "|"),
+			})
+		}
+	}
+
+	buf := bytes.NewBufferString(xml.Header)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(settings); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func newMavenServer(id, credentialID string, getCredential CredentialGetter) (server mavenServer, err error) {
+	server.ID = id
+	server.Username, server.Password, err = getBasicAuth(credentialID, getCredential)
+	return
+}
+
+// RenderNPMConfig renders the .npmrc of npm, the credentials are bound to their registries
+func RenderNPMConfig(config *v1alpha3.PackageManagers, getCredential CredentialGetter) ([]byte, error) {
+	lines := []string{}
+	if npm := config.NPM; npm != nil {
+		if npm.Registry != "" {
+			lines = append(lines, "registry="+npm.Registry)
+			auth, err := renderNPMAuth(npm.Registry, npm.CredentialID, getCredential)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, auth...)
+		}
+		for _, scope := range npm.Scopes {
+			lines = append(lines, fmt.Sprintf("%s:registry=%s", normalizeScope(scope.Scope), scope.Registry))
+			auth, err := renderNPMAuth(scope.Registry, scope.CredentialID, getCredential)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, auth...)
+		}
+	}
+	if proxy := config.Proxy; proxy != nil {
+		proxyURL := &url.URL{Scheme: "http", Host: net.JoinHostPort(proxy.Host, strconv.Itoa(int(proxy.Port)))}
+		if proxy.CredentialID != "" {
+			username, password, err := getBasicAuth(proxy.CredentialID, getCredential)
+			if err != nil {
+				return nil, err
+			}
+			proxyURL.User = url.UserPassword(username, password)
+		}
+		lines = append(lines, "proxy="+proxyURL.String(), "https-proxy="+proxyURL.String())
+		if len(proxy.NonProxyHosts) > 0 {
+			// npm only matches the domain suffixes
+			hosts := make([]string, len(proxy.NonProxyHosts))
+			for i, host := range proxy.NonProxyHosts {
+				hosts[i] = strings.TrimPrefix(host, "*")
+			}
+			lines = append(lines, "noproxy="+strings.Join(hosts, ","))
+		}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// renderNPMAuth returns the auth settings of a registry, a secret-text credential is taken as the auth token
+func renderNPMAuth(registry, credentialID string, getCredential CredentialGetter) ([]string, error) {
+	if credentialID == "" {
+		return nil, nil
+	}
+	prefix, err := getNerfDart(registry)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := getCredential(credentialID)
+	if err != nil {
+		return nil, err
+	}
+	switch secret.Type {
+	case v1alpha3.SecretTypeSecretText:
+		return []string{fmt.Sprintf("%s:_authToken=%s", prefix, secret.Data[v1alpha3.SecretTextSecretKey])}, nil
+	case v1alpha3.SecretTypeBasicAuth:
+		auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s",
+			secret.Data[v1alpha3.BasicAuthUsernameKey], secret.Data[v1alpha3.BasicAuthPasswordKey])))
+		return []string{fmt.Sprintf("%s:_auth=%s", prefix, auth)}, nil
+	default:
+		return nil, fmt.Errorf("credential %s is neither basic-auth nor secret-text", credentialID)
+	}
+}
+
+// getNerfDart returns the registry without the protocol, which npm binds the credentials to, such as
+// //registry.example.com/npm/
+func getNerfDart(registry string) (string, error) {
+	registryURL, err := url.Parse(registry)
+	if err != nil || registryURL.Host == "" {
+		return "", fmt.Errorf("invalid registry %q", registry)
+	}
+	path := registryURL.Path
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return "//" + registryURL.Host + path, nil
+}
+
+func normalizeScope(scope string) string {
+	if strings.HasPrefix(scope, "@") {
+		return scope
+	}
+	return "@" + scope
+}
+
+func getBasicAuth(credentialID string, getCredential CredentialGetter) (username, password string, err error) {
+	var secret *v1.Secret
+	if secret, err = getCredential(credentialID); err != nil {
+		return
+	}
+	if secret.Type != v1alpha3.SecretTypeBasicAuth {
+		err = fmt.Errorf("credential %s is not basic-auth", credentialID)
+		return
+	}
+	username, password = string(secret.Data[v1alpha3.BasicAuthUsernameKey]), string(secret.Data[v1alpha3.BasicAuthPasswordKey])
+	return
+}
+
+// GetCredentialIDs returns the names of the credentials which the configuration refers to
+func GetCredentialIDs(config *v1alpha3.PackageManagers) []string {
+	found := map[string]bool{}
+	add := func(id string) {
+		if id != "" {
+			found[id] = true
+		}
+	}
+	if config.Proxy != nil {
+		add(config.Proxy.CredentialID)
+	}
+	if config.Maven != nil {
+		for _, mirror := range config.Maven.Mirrors {
+			add(mirror.CredentialID)
+		}
+		for _, server := range config.Maven.Servers {
+			add(server.CredentialID)
+		}
+	}
+	if config.NPM != nil {
+		add(config.NPM.CredentialID)
+		for _, scope := range config.NPM.Scopes {
+			add(scope.CredentialID)
+		}
+	}
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelineenvironment
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Find returns the PipelineEnvironments which select the Pipeline and the branch of the PipelineRun, sorted by name
+func Find(ctx context.Context, c client.Reader, pipeline *v1alpha3.Pipeline, pr *v1alpha3.PipelineRun) (
+	environments []v1alpha3.PipelineEnvironment, err error) {
+	environmentList := &v1alpha3.PipelineEnvironmentList{}
+	if err = c.List(ctx, environmentList, client.InNamespace(pipeline.Namespace)); err != nil {
+		return
+	}
+
+	var branch string
+	if pr.Spec.SCM != nil {
+		branch = pr.Spec.SCM.RefName
+	}
+	for i := range environmentList.Items {
+		if selects(&environmentList.Items[i].Spec, pipeline, branch) {
+			environments = append(environments, environmentList.Items[i])
+		}
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Name < environments[j].Name
+	})
+	return
+}
+
+func selects(spec *v1alpha3.PipelineEnvironmentSpec, pipeline *v1alpha3.Pipeline, branch string) bool {
+	if spec.PipelineSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.PipelineSelector)
+		if err != nil || !selector.Matches(labels.Set(pipeline.Labels)) {
+			return false
+		}
+	}
+	if len(spec.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range spec.Branches {
+		if matched, _ := path.Match(pattern, branch); matched && branch != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the variables of the PipelineEnvironments as parameters. The latter environment overrides the
+// variable of the former one which has the same name. The ConfigMaps and credentials must exist in the namespace.
+func Resolve(ctx context.Context, c client.Reader, namespace string, environments []v1alpha3.PipelineEnvironment) (
+	parameters []v1alpha3.Parameter, err error) {
+	set := func(name, value string) {
+		parameters = setParameter(parameters, name, value)
+	}
+	for _, environment := range environments {
+		for _, env := range environment.Spec.Env {
+			set(env.Name, env.Value)
+		}
+		for _, file := range environment.Spec.ConfigFiles {
+			configMap := &v1.ConfigMap{}
+			if err = c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: file.ConfigMap}, configMap); err != nil {
+				err = fmt.Errorf("failed to get the ConfigMap %s of PipelineEnvironment %s, error: %v",
+					file.ConfigMap, environment.Name, err)
+				return
+			}
+			value, ok := configMap.Data[file.Key]
+			if !ok {
+				err = fmt.Errorf("key %s not found in the ConfigMap %s of PipelineEnvironment %s",
+					file.Key, file.ConfigMap, environment.Name)
+				return
+			}
+			set(file.Name, value)
+		}
+		for _, ref := range environment.Spec.SecretRefs {
+			// only the ID of credential is passed, the value is bound by the Jenkinsfile
+			if err = c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.SecretName}, &v1.Secret{}); err != nil {
+				err = fmt.Errorf("failed to get the credential %s of PipelineEnvironment %s, error: %v",
+					ref.SecretName, environment.Name, err)
+				return
+			}
+			set(ref.Name, ref.SecretName)
+		}
+	}
+	return
+}
+
+// Inject adds the variables to the parameters of a PipelineRun, the parameters which were given explicitly take
+// precedence over the variables
+func Inject(parameters, variables []v1alpha3.Parameter) []v1alpha3.Parameter {
+	result := append([]v1alpha3.Parameter{}, parameters...)
+	for _, variable := range variables {
+		if !hasParameter(parameters, variable.Name) {
+			result = append(result, variable)
+		}
+	}
+	return result
+}
+
+// GetNames returns the names of the PipelineEnvironments
+func GetNames(environments []v1alpha3.PipelineEnvironment) (names []string) {
+	for _, environment := range environments {
+		names = append(names, environment.Name)
+	}
+	return
+}
+
+func setParameter(parameters []v1alpha3.Parameter, name, value string) []v1alpha3.Parameter {
+	for i := range parameters {
+		if parameters[i].Name == name {
+			parameters[i].Value = value
+			return parameters
+		}
+	}
+	return append(parameters, v1alpha3.Parameter{Name: name, Value: value})
+}
+
+func hasParameter(parameters []v1alpha3.Parameter, name string) bool {
+	for _, parameter := range parameters {
+		if parameter.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelineenvironment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newEnvironment(name string, spec v1alpha3.PipelineEnvironmentSpec) *v1alpha3.PipelineEnvironment {
+	return &v1alpha3.PipelineEnvironment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}, Spec: spec}
+}
+
+func TestFind(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "pipeline", Labels: map[string]string{"app": "web"},
+	}}
+	objects := []client.Object{
+		newEnvironment("shared", v1alpha3.PipelineEnvironmentSpec{}),
+		newEnvironment("production", v1alpha3.PipelineEnvironmentSpec{Branches: []string{"release-*", "v*"}}),
+		newEnvironment("web", v1alpha3.PipelineEnvironmentSpec{PipelineSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "web"},
+		}}),
+		newEnvironment("api", v1alpha3.PipelineEnvironmentSpec{PipelineSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "api"},
+		}}),
+		&v1alpha3.PipelineEnvironment{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "other"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(objects...).Build()
+
+	tests := []struct {
+		name   string
+		branch string
+		want   []string
+	}{{
+		name: "without branch",
+		want: []string{"shared", "web"},
+	}, {
+		name:   "branch not matched",
+		branch: "master",
+		want:   []string{"shared", "web"},
+	}, {
+		name:   "branch matched",
+		branch: "release-1.0",
+		want:   []string{"production", "shared", "web"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &v1alpha3.PipelineRun{}
+			if tt.branch != "" {
+				pr.Spec.SCM = &v1alpha3.SCM{RefName: tt.branch}
+			}
+			environments, err := Find(context.Background(), c, pipeline, pr)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, GetNames(environments))
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+		Data:       map[string]string{"app.yaml": "replicas: 3"},
+	}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "registry"}}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(configMap, secret).Build()
+
+	shared := *newEnvironment("shared", v1alpha3.PipelineEnvironmentSpec{
+		Env: []v1alpha3.EnvironmentVariable{{Name: "REGION", Value: "us"}, {Name: "REPLICAS", Value: "1"}},
+	})
+	staging := *newEnvironment("staging", v1alpha3.PipelineEnvironmentSpec{
+		Env:         []v1alpha3.EnvironmentVariable{{Name: "REPLICAS", Value: "2"}},
+		ConfigFiles: []v1alpha3.EnvironmentConfigFile{{Name: "APP_CONFIG", ConfigMap: "config", Key: "app.yaml"}},
+		SecretRefs:  []v1alpha3.EnvironmentSecretRef{{Name: "REGISTRY_CREDENTIAL", SecretName: "registry"}},
+	})
+
+	parameters, err := Resolve(context.Background(), c, "ns", []v1alpha3.PipelineEnvironment{shared, staging})
+	assert.Nil(t, err)
+	assert.Equal(t, []v1alpha3.Parameter{
+		{Name: "REGION", Value: "us"},
+		{Name: "REPLICAS", Value: "2"},
+		{Name: "APP_CONFIG", Value: "replicas: 3"},
+		{Name: "REGISTRY_CREDENTIAL", Value: "registry"},
+	}, parameters)
+
+	// the referenced resources must exist
+	missingKey := *newEnvironment("missing-key", v1alpha3.PipelineEnvironmentSpec{
+		ConfigFiles: []v1alpha3.EnvironmentConfigFile{{Name: "APP_CONFIG", ConfigMap: "config", Key: "fake"}},
+	})
+	_, err = Resolve(context.Background(), c, "ns", []v1alpha3.PipelineEnvironment{missingKey})
+	assert.NotNil(t, err)
+	missingSecret := *newEnvironment("missing-secret", v1alpha3.PipelineEnvironmentSpec{
+		SecretRefs: []v1alpha3.EnvironmentSecretRef{{Name: "TOKEN", SecretName: "fake"}},
+	})
+	_, err = Resolve(context.Background(), c, "ns", []v1alpha3.PipelineEnvironment{missingSecret})
+	assert.NotNil(t, err)
+}
+
+func TestInject(t *testing.T) {
+	parameters := []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}}
+	result := Inject(parameters, []v1alpha3.Parameter{{Name: "REGION", Value: "us"}, {Name: "REPLICAS", Value: "2"}})
+	assert.Equal(t, []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}, {Name: "REGION", Value: "us"}}, result)
+	// the given parameters are not changed
+	assert.Equal(t, []v1alpha3.Parameter{{Name: "REPLICAS", Value: "5"}}, parameters)
+}
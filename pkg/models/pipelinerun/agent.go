@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RunURLAnnoKey is the annotation key of the Jenkins build which an agent pod runs for, like job/folder/job/name/1/
+const RunURLAnnoKey = "runUrl"
+
+// AgentBuild is the Jenkins build which an agent pod runs for
+type AgentBuild struct {
+	Namespace string
+	Pipeline  string
+	// Branch is the branch of a multi-branch Pipeline, it's empty for the others
+	Branch string
+	RunID  string
+}
+
+// ParseRunURL parses the run URL of an agent pod, like job/folder/job/name/1/ or job/folder/job/name/job/branch/1/.
+// The Jenkins folder is resolved to the namespace of the DevOps project, because the folder might be named by a
+// strategy other than the namespace. The build is nil if it's not a run URL.
+func ParseRunURL(ctx context.Context, resolver *jenkins.FolderResolver, runURL string) (build *AgentBuild, err error) {
+	items := strings.Split(strings.Trim(runURL, "/"), "/")
+	if len(items) != 5 && len(items) != 7 {
+		return
+	}
+	for i := 0; i < len(items)-1; i += 2 {
+		if items[i] != "job" {
+			return
+		}
+	}
+	var namespace string
+	if namespace, err = resolver.NamespaceOf(ctx, items[1]); err != nil {
+		return
+	}
+	build = &AgentBuild{Namespace: namespace, Pipeline: items[3], RunID: items[len(items)-1]}
+	if len(items) == 7 {
+		// Jenkins encodes the branch names twice, such as feature%252Fa
+		build.Branch = unescape(unescape(items[5]))
+	}
+	return
+}
+
+func unescape(value string) string {
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+// FindPipelineRun returns the PipelineRun of the build, it's nil if the build was not triggered by a PipelineRun
+func (b *AgentBuild) FindPipelineRun(ctx context.Context, reader client.Reader) (*v1alpha3.PipelineRun, error) {
+	pipelineRuns := &v1alpha3.PipelineRunList{}
+	if err := reader.List(ctx, pipelineRuns, client.InNamespace(b.Namespace),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: b.Pipeline}); err != nil {
+		return nil, err
+	}
+	for i := range pipelineRuns.Items {
+		pr := &pipelineRuns.Items[i]
+		if runID, ok := pr.GetPipelineRunID(); !ok || runID != b.RunID {
+			continue
+		}
+		if b.Branch == "" || (pr.Spec.SCM != nil && pr.Spec.SCM.RefName == b.Branch) {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
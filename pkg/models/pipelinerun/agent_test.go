@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseRunURL(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	// the folder of the DevOps project is named by the prefix strategy
+	resolver := jenkins.NewFolderResolver(fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: map[string]string{
+			v1alpha3.DevOpsProjectJenkinsFolderAnnoKey: "host-demo",
+		}},
+		Status: v1alpha3.DevOpsProjectStatus{AdminNamespace: "demo"},
+	}).Build())
+
+	tests := []struct {
+		runURL string
+		want   *AgentBuild
+	}{{
+		runURL: "job/ns/job/pipeline/3/",
+		want:   &AgentBuild{Namespace: "ns", Pipeline: "pipeline", RunID: "3"},
+	}, {
+		runURL: "job/ns/job/pipeline/job/feature%252Fa/12/",
+		want:   &AgentBuild{Namespace: "ns", Pipeline: "pipeline", Branch: "feature/a", RunID: "12"},
+	}, {
+		runURL: "job/ns/job/pipeline/job/main/1",
+		want:   &AgentBuild{Namespace: "ns", Pipeline: "pipeline", Branch: "main", RunID: "1"},
+	}, {
+		runURL: "/job/ns/job/pipeline/job/main/1/",
+		want:   &AgentBuild{Namespace: "ns", Pipeline: "pipeline", Branch: "main", RunID: "1"},
+	}, {
+		runURL: "job/host-demo/job/pipeline/3/",
+		want:   &AgentBuild{Namespace: "demo", Pipeline: "pipeline", RunID: "3"},
+	}, {
+		runURL: "job/host-demo/job/pipeline/job/main/1/",
+		want:   &AgentBuild{Namespace: "demo", Pipeline: "pipeline", Branch: "main", RunID: "1"},
+	}, {
+		runURL: "",
+	}, {
+		runURL: "view/all",
+	}, {
+		runURL: "job/ns/3/",
+	}, {
+		runURL: "job/ns/view/pipeline/3/",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.runURL, func(t *testing.T) {
+			build, err := ParseRunURL(context.Background(), resolver, tt.runURL)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, build)
+		})
+	}
+}
+
+func TestAgentBuild_FindPipelineRun(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	newPipelineRun := func(name, runID, branch string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        name,
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: runID},
+		}, Spec: v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}}}
+		if branch != "" {
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		return pr
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		newPipelineRun("a", "1", ""),
+		newPipelineRun("b", "1", "main"),
+		newPipelineRun("c", "2", "feature/a"),
+	).Build()
+
+	tests := []struct {
+		build *AgentBuild
+		want  string
+	}{{
+		build: &AgentBuild{Namespace: "ns", Pipeline: "pipeline", RunID: "1"},
+		want:  "a",
+	}, {
+		build: &AgentBuild{Namespace: "ns", Pipeline: "pipeline", Branch: "feature/a", RunID: "2"},
+		want:  "c",
+	}, {
+		build: &AgentBuild{Namespace: "ns", Pipeline: "pipeline", Branch: "main", RunID: "2"},
+	}, {
+		build: &AgentBuild{Namespace: "other", Pipeline: "pipeline", RunID: "1"},
+	}}
+	for _, tt := range tests {
+		pr, err := tt.build.FindPipelineRun(context.Background(), c)
+		assert.Nil(t, err)
+		if tt.want == "" {
+			assert.Nil(t, pr)
+		} else if assert.NotNil(t, pr) {
+			assert.Equal(t, tt.want, pr.Name)
+		}
+	}
+}
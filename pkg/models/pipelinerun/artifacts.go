@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"path"
+	"sort"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// DefaultSizeIncreaseThreshold is the percentage of the size increase which flags an artifact by default
+const DefaultSizeIncreaseThreshold = 20
+
+// ArtifactSnapshot contains the archived files of a PipelineRun with their sizes and checksums
+type ArtifactSnapshot struct {
+	PipelineRun  *v1alpha3.PipelineRun
+	Artifacts    []devops.Artifacts
+	Fingerprints []devops.Fingerprint
+}
+
+// ArtifactStatus is how an artifact changed from the base PipelineRun to the target one
+type ArtifactStatus string
+
+const (
+	// ArtifactAdded means the artifact only exists in the target
+	ArtifactAdded ArtifactStatus = "Added"
+	// ArtifactRemoved means the artifact only exists in the base
+	ArtifactRemoved ArtifactStatus = "Removed"
+	// ArtifactChanged means the size or the checksum of the artifact changed
+	ArtifactChanged ArtifactStatus = "Changed"
+	// ArtifactUnchanged means neither the size nor the checksum of the artifact changed
+	ArtifactUnchanged ArtifactStatus = "Unchanged"
+)
+
+// ArtifactFlag is the reason why an artifact needs review
+type ArtifactFlag string
+
+const (
+	// ArtifactMissing flags an artifact of the base which is not archived by the target
+	ArtifactMissing ArtifactFlag = "Missing"
+	// ArtifactSizeIncreased flags an artifact whose size increased more than the threshold
+	ArtifactSizeIncreased ArtifactFlag = "SizeIncreased"
+)
+
+// ArtifactChange is the difference of an artifact, the fields of a side are absent if the artifact doesn't exist in it
+type ArtifactChange struct {
+	Path       string         `json:"path"`
+	Status     ArtifactStatus `json:"status"`
+	BaseSize   *int           `json:"baseSize,omitempty"`
+	TargetSize *int           `json:"targetSize,omitempty"`
+	// BaseHash and TargetHash are the MD5 checksums, they are only available if the artifacts were fingerprinted
+	BaseHash   string `json:"baseHash,omitempty"`
+	TargetHash string `json:"targetHash,omitempty"`
+	// SizeIncreasePercent is the percentage of the size increase, it's negative if the size decreased
+	SizeIncreasePercent *int         `json:"sizeIncreasePercent,omitempty"`
+	Flag                ArtifactFlag `json:"flag,omitempty"`
+}
+
+// ArtifactReport is the difference of the artifacts between two PipelineRuns of the same Pipeline. All artifacts are
+// listed, the flagged ones need review before the target is promoted.
+type ArtifactReport struct {
+	Base   RunSummary `json:"base"`
+	Target RunSummary `json:"target"`
+	// SizeIncreaseThreshold is the percentage of the size increase which flags an artifact
+	SizeIncreaseThreshold int              `json:"sizeIncreaseThreshold"`
+	Artifacts             []ArtifactChange `json:"artifacts"`
+	// Flagged is the number of the flagged artifacts
+	Flagged int `json:"flagged"`
+}
+
+// CompareArtifacts returns the difference of the artifacts from the base PipelineRun to the target one. The artifacts
+// are matched by their paths, an artifact is flagged if it's missing in the target, or its size increased more than
+// the threshold percentage.
+func CompareArtifacts(base, target *ArtifactSnapshot, threshold int) *ArtifactReport {
+	report := &ArtifactReport{
+		Base:                  summarize(base.PipelineRun),
+		Target:                summarize(target.PipelineRun),
+		SizeIncreaseThreshold: threshold,
+		Artifacts:             []ArtifactChange{},
+	}
+	baseArtifacts := indexArtifacts(base)
+	targetArtifacts := indexArtifacts(target)
+	for artifactPath, baseArtifact := range baseArtifacts {
+		change := ArtifactChange{
+			Path:     artifactPath,
+			BaseSize: baseArtifact.size,
+			BaseHash: baseArtifact.hash,
+		}
+		targetArtifact, ok := targetArtifacts[artifactPath]
+		if !ok {
+			change.Status = ArtifactRemoved
+			change.Flag = ArtifactMissing
+			report.Artifacts = append(report.Artifacts, change)
+			continue
+		}
+		change.TargetSize = targetArtifact.size
+		change.TargetHash = targetArtifact.hash
+		change.Status = ArtifactUnchanged
+		if *change.BaseSize != *change.TargetSize || change.BaseHash != change.TargetHash {
+			change.Status = ArtifactChanged
+		}
+		if *change.BaseSize > 0 {
+			percent := (*change.TargetSize - *change.BaseSize) * 100 / *change.BaseSize
+			change.SizeIncreasePercent = &percent
+			if percent > threshold {
+				change.Flag = ArtifactSizeIncreased
+			}
+		}
+		report.Artifacts = append(report.Artifacts, change)
+	}
+	for artifactPath, targetArtifact := range targetArtifacts {
+		if _, ok := baseArtifacts[artifactPath]; !ok {
+			report.Artifacts = append(report.Artifacts, ArtifactChange{
+				Path:       artifactPath,
+				Status:     ArtifactAdded,
+				TargetSize: targetArtifact.size,
+				TargetHash: targetArtifact.hash,
+			})
+		}
+	}
+	sort.Slice(report.Artifacts, func(i, j int) bool {
+		return report.Artifacts[i].Path < report.Artifacts[j].Path
+	})
+	for _, change := range report.Artifacts {
+		if change.Flag != "" {
+			report.Flagged++
+		}
+	}
+	return report
+}
+
+type artifact struct {
+	size *int
+	hash string
+}
+
+// indexArtifacts returns the artifacts by their paths. The fingerprints are matched by the paths, or by the file names
+// because Jenkins records the file names of the fingerprinted artifacts only.
+func indexArtifacts(snapshot *ArtifactSnapshot) map[string]artifact {
+	hashes := map[string]string{}
+	for _, fingerprint := range snapshot.Fingerprints {
+		hashes[fingerprint.FileName] = fingerprint.Hash
+	}
+	artifacts := map[string]artifact{}
+	for i := range snapshot.Artifacts {
+		item := &snapshot.Artifacts[i]
+		artifactPath := item.Path
+		if artifactPath == "" {
+			artifactPath = item.Name
+		}
+		hash, ok := hashes[artifactPath]
+		if !ok {
+			hash = hashes[path.Base(artifactPath)]
+		}
+		artifacts[artifactPath] = artifact{size: &item.Size, hash: hash}
+	}
+	return artifacts
+}
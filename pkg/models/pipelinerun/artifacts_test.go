@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestCompareArtifacts(t *testing.T) {
+	newSnapshot := func(name string, artifacts []devops.Artifacts, fingerprints ...devops.Fingerprint) *ArtifactSnapshot {
+		return &ArtifactSnapshot{
+			PipelineRun:  &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			Artifacts:    artifacts,
+			Fingerprints: fingerprints,
+		}
+	}
+	base := newSnapshot("pr-1", []devops.Artifacts{
+		{Name: "app.jar", Path: "target/app.jar", Size: 1000},
+		{Name: "README.md", Path: "README.md", Size: 10},
+		{Name: "report.html", Path: "report.html", Size: 100},
+		{Name: "empty.txt", Path: "empty.txt", Size: 0},
+	}, devops.Fingerprint{FileName: "app.jar", Hash: "1"}, devops.Fingerprint{FileName: "README.md", Hash: "a"})
+	target := newSnapshot("pr-2", []devops.Artifacts{
+		{Name: "app.jar", Path: "target/app.jar", Size: 1500},
+		{Name: "README.md", Path: "README.md", Size: 10},
+		{Name: "empty.txt", Path: "empty.txt", Size: 20},
+		{Name: "app.war", Path: "target/app.war", Size: 50},
+	}, devops.Fingerprint{FileName: "app.jar", Hash: "2"}, devops.Fingerprint{FileName: "README.md", Hash: "a"})
+
+	report := CompareArtifacts(base, target, DefaultSizeIncreaseThreshold)
+	assert.Equal(t, "pr-1", report.Base.Name)
+	assert.Equal(t, "pr-2", report.Target.Name)
+	assert.Equal(t, 20, report.SizeIncreaseThreshold)
+	assert.Equal(t, 2, report.Flagged)
+	assert.Equal(t, []ArtifactChange{{
+		Path:                "README.md",
+		Status:              ArtifactUnchanged,
+		BaseSize:            intPtr(10),
+		TargetSize:          intPtr(10),
+		BaseHash:            "a",
+		TargetHash:          "a",
+		SizeIncreasePercent: intPtr(0),
+	}, {
+		// the size of an empty artifact can't increase by percentage
+		Path:       "empty.txt",
+		Status:     ArtifactChanged,
+		BaseSize:   intPtr(0),
+		TargetSize: intPtr(20),
+	}, {
+		Path:     "report.html",
+		Status:   ArtifactRemoved,
+		BaseSize: intPtr(100),
+		Flag:     ArtifactMissing,
+	}, {
+		Path:                "target/app.jar",
+		Status:              ArtifactChanged,
+		BaseSize:            intPtr(1000),
+		TargetSize:          intPtr(1500),
+		BaseHash:            "1",
+		TargetHash:          "2",
+		SizeIncreasePercent: intPtr(50),
+		Flag:                ArtifactSizeIncreased,
+	}, {
+		Path:       "target/app.war",
+		Status:     ArtifactAdded,
+		TargetSize: intPtr(50),
+	}}, report.Artifacts)
+
+	// the size increase is allowed by a higher threshold
+	report = CompareArtifacts(base, target, 50)
+	assert.Equal(t, 1, report.Flagged)
+
+	report = CompareArtifacts(newSnapshot("pr-1", nil), newSnapshot("pr-2", nil), DefaultSizeIncreaseThreshold)
+	assert.Equal(t, []ArtifactChange{}, report.Artifacts)
+	assert.Equal(t, 0, report.Flagged)
+}
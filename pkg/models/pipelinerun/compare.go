@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// RunSnapshot contains the data of a PipelineRun which takes part in a comparison
+type RunSnapshot struct {
+	PipelineRun  *v1alpha3.PipelineRun
+	TestCases    []devops.TestCase
+	Fingerprints []devops.Fingerprint
+}
+
+// Comparison is the difference between two PipelineRuns of the same Pipeline.
+// Only the changed items are listed.
+type Comparison struct {
+	Base   RunSummary `json:"base"`
+	Target RunSummary `json:"target"`
+
+	Parameters []ValueChange `json:"parameters,omitempty"`
+	Stages     []StageChange `json:"stages,omitempty"`
+	Tests      TestChange    `json:"tests"`
+	Artifacts  []ValueChange `json:"artifacts,omitempty"`
+}
+
+// RunSummary is the summary of a PipelineRun in a comparison
+type RunSummary struct {
+	Name             string            `json:"name"`
+	RunID            string            `json:"runId,omitempty"`
+	Phase            v1alpha3.RunPhase `json:"phase,omitempty"`
+	DurationInMillis *int64            `json:"durationInMillis,omitempty"`
+	// Revision is the commit ID which the Jenkinsfile was loaded from
+	Revision string `json:"revision,omitempty"`
+	// JenkinsfileDigest is the SHA-256 digest of the Jenkinsfile
+	JenkinsfileDigest string `json:"jenkinsfileDigest,omitempty"`
+}
+
+// ValueChange is a changed value, the base or target is empty if it was added or removed
+type ValueChange struct {
+	Name   string `json:"name"`
+	Base   string `json:"base,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// StageChange is the difference of a stage, the durations are absent if the stage didn't run
+type StageChange struct {
+	Name                   string `json:"name"`
+	BaseResult             string `json:"baseResult,omitempty"`
+	TargetResult           string `json:"targetResult,omitempty"`
+	BaseDurationInMillis   *int   `json:"baseDurationInMillis,omitempty"`
+	TargetDurationInMillis *int   `json:"targetDurationInMillis,omitempty"`
+}
+
+// TestChange contains the test failures which were introduced or fixed by the target PipelineRun
+type TestChange struct {
+	Introduced []string `json:"introduced,omitempty"`
+	Fixed      []string `json:"fixed,omitempty"`
+}
+
+// Compare returns the difference from the base PipelineRun to the target one
+func Compare(base, target *RunSnapshot) *Comparison {
+	return &Comparison{
+		Base:       summarize(base.PipelineRun),
+		Target:     summarize(target.PipelineRun),
+		Parameters: compareParameters(base.PipelineRun.Spec.Parameters, target.PipelineRun.Spec.Parameters),
+		Stages:     compareStages(getStages(base.PipelineRun), getStages(target.PipelineRun)),
+		Tests:      compareTests(base.TestCases, target.TestCases),
+		Artifacts:  compareFingerprints(base.Fingerprints, target.Fingerprints),
+	}
+}
+
+func summarize(pr *v1alpha3.PipelineRun) (summary RunSummary) {
+	summary.Name = pr.Name
+	summary.RunID, _ = pr.GetPipelineRunID()
+	summary.Phase = pr.Status.Phase
+	if pr.Status.StartTime != nil && pr.Status.CompletionTime != nil {
+		duration := pr.Status.CompletionTime.Sub(pr.Status.StartTime.Time).Milliseconds()
+		summary.DurationInMillis = &duration
+	}
+
+	// prefer the recorded Jenkinsfile, it covers the Jenkinsfile loaded from the SCM as well
+	summary.Revision = pr.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey]
+	summary.JenkinsfileDigest = pr.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey]
+	run := &job.PipelineRun{}
+	if err := json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), run); err == nil && summary.Revision == "" {
+		summary.Revision = run.CommitID
+	}
+	if spec := pr.Spec.PipelineSpec; spec != nil && spec.Pipeline != nil && spec.Pipeline.Jenkinsfile != "" &&
+		summary.JenkinsfileDigest == "" {
+		digest := sha256.Sum256([]byte(spec.Pipeline.Jenkinsfile))
+		summary.JenkinsfileDigest = hex.EncodeToString(digest[:])
+	}
+	return
+}
+
+func compareParameters(base, target []v1alpha3.Parameter) []ValueChange {
+	baseValues := map[string]string{}
+	for _, param := range base {
+		baseValues[param.Name] = param.Value
+	}
+	targetValues := map[string]string{}
+	for _, param := range target {
+		targetValues[param.Name] = param.Value
+	}
+	return compareValues(baseValues, targetValues)
+}
+
+func compareFingerprints(base, target []devops.Fingerprint) []ValueChange {
+	baseHashes := map[string]string{}
+	for _, fingerprint := range base {
+		baseHashes[fingerprint.FileName] = fingerprint.Hash
+	}
+	targetHashes := map[string]string{}
+	for _, fingerprint := range target {
+		targetHashes[fingerprint.FileName] = fingerprint.Hash
+	}
+	return compareValues(baseHashes, targetHashes)
+}
+
+func compareValues(base, target map[string]string) (changes []ValueChange) {
+	for name, baseValue := range base {
+		if targetValue, ok := target[name]; !ok || targetValue != baseValue {
+			changes = append(changes, ValueChange{Name: name, Base: baseValue, Target: targetValue})
+		}
+	}
+	for name, targetValue := range target {
+		if _, ok := base[name]; !ok {
+			changes = append(changes, ValueChange{Name: name, Target: targetValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return
+}
+
+func getStages(pr *v1alpha3.PipelineRun) (nodes []NodeDetail) {
+	// the stages are not available if the PipelineRun is not started yet
+	_ = json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey]), &nodes)
+	return
+}
+
+// compareStages lists all stages in order of the target PipelineRun, then the stages which only exist in the base.
+// The stages are matched by the display name, because the node IDs change once the Jenkinsfile is changed.
+func compareStages(base, target []NodeDetail) (changes []StageChange) {
+	baseStages := map[string]NodeDetail{}
+	for _, node := range base {
+		baseStages[node.DisplayName] = node
+	}
+	matched := map[string]bool{}
+	for i := range target {
+		change := StageChange{
+			Name:                   target[i].DisplayName,
+			TargetResult:           target[i].Result,
+			TargetDurationInMillis: &target[i].DurationInMillis,
+		}
+		if node, ok := baseStages[target[i].DisplayName]; ok {
+			change.BaseResult = node.Result
+			change.BaseDurationInMillis = &node.DurationInMillis
+			matched[node.DisplayName] = true
+		}
+		changes = append(changes, change)
+	}
+	for i := range base {
+		if !matched[base[i].DisplayName] {
+			changes = append(changes, StageChange{
+				Name:                 base[i].DisplayName,
+				BaseResult:           base[i].Result,
+				BaseDurationInMillis: &base[i].DurationInMillis,
+			})
+		}
+	}
+	return
+}
+
+// compareTests finds out the failures which are new in the target PipelineRun, and the failures which
+// passed in the target PipelineRun. The failed tests which were removed from the target are not fixed.
+func compareTests(base, target []devops.TestCase) (change TestChange) {
+	baseFailures := getTestResults(base)
+	targetResults := getTestResults(target)
+	for name, failed := range targetResults {
+		if failed && !baseFailures[name] {
+			change.Introduced = append(change.Introduced, name)
+		}
+	}
+	for name, failed := range baseFailures {
+		if targetFailed, ok := targetResults[name]; failed && ok && !targetFailed {
+			change.Fixed = append(change.Fixed, name)
+		}
+	}
+	sort.Strings(change.Introduced)
+	sort.Strings(change.Fixed)
+	return
+}
+
+// getTestResults returns whether the test cases failed, the key is the full name of the test case
+func getTestResults(testCases []devops.TestCase) map[string]bool {
+	results := map[string]bool{}
+	for _, testCase := range testCases {
+		results[testCase.ClassName+"."+testCase.Name] = testCase.IsFailed()
+	}
+	return results
+}
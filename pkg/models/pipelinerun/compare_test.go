@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func newRunSnapshot(name, commitID, jenkinsfile string, stages []NodeDetail, parameters ...v1alpha3.Parameter) *RunSnapshot {
+	stagesData, _ := json.Marshal(stages)
+	runData, _ := json.Marshal(&job.PipelineRun{CommitID: commitID})
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(time.Minute))
+	return &RunSnapshot{PipelineRun: &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: string(stagesData),
+				v1alpha3.JenkinsPipelineRunStatusAnnoKey:       string(runData),
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			Parameters:   parameters,
+			PipelineSpec: &v1alpha3.PipelineSpec{Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: jenkinsfile}},
+		},
+		Status: v1alpha3.PipelineRunStatus{StartTime: &start, CompletionTime: &completion},
+	}}
+}
+
+func newStage(name, result string, duration int) NodeDetail {
+	return NodeDetail{Node: job.Node{DisplayName: name, Result: result, DurationInMillis: duration}}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestCompare(t *testing.T) {
+	base := newRunSnapshot("base", "abc", "pipeline {}",
+		[]NodeDetail{newStage("build", "SUCCESS", 100), newStage("lint", "SUCCESS", 10)},
+		v1alpha3.Parameter{Name: "version", Value: "v1"}, v1alpha3.Parameter{Name: "debug", Value: "true"})
+	base.TestCases = []devops.TestCase{
+		{ClassName: "a", Name: "fixed", Status: "FAILED"},
+		{ClassName: "a", Name: "broken", Status: "PASSED"},
+		{ClassName: "a", Name: "removed", Status: "FAILED"},
+		{ClassName: "a", Name: "flaky", Status: "REGRESSION"},
+	}
+	base.Fingerprints = []devops.Fingerprint{{FileName: "app.jar", Hash: "1"}, {FileName: "same.txt", Hash: "s"}}
+
+	target := newRunSnapshot("target", "def", "pipeline { }",
+		[]NodeDetail{newStage("build", "FAILURE", 300), newStage("test", "SUCCESS", 20)},
+		v1alpha3.Parameter{Name: "version", Value: "v2"}, v1alpha3.Parameter{Name: "debug", Value: "true"},
+		v1alpha3.Parameter{Name: "new", Value: "value"})
+	target.TestCases = []devops.TestCase{
+		{ClassName: "a", Name: "fixed", Status: "FIXED"},
+		{ClassName: "a", Name: "broken", Status: "REGRESSION"},
+		{ClassName: "a", Name: "flaky", Status: "FAILED"},
+		{ClassName: "a", Name: "added", Status: "FAILED"},
+	}
+	target.Fingerprints = []devops.Fingerprint{{FileName: "app.jar", Hash: "2"}, {FileName: "same.txt", Hash: "s"},
+		{FileName: "new.txt", Hash: "n"}}
+
+	comparison := Compare(base, target)
+	assert.Equal(t, "base", comparison.Base.Name)
+	assert.Equal(t, "abc", comparison.Base.Revision)
+	assert.Equal(t, "def", comparison.Target.Revision)
+	assert.NotEmpty(t, comparison.Base.JenkinsfileDigest)
+	assert.NotEqual(t, comparison.Base.JenkinsfileDigest, comparison.Target.JenkinsfileDigest)
+	if assert.NotNil(t, comparison.Target.DurationInMillis) {
+		assert.Equal(t, int64(60000), *comparison.Target.DurationInMillis)
+	}
+	assert.Equal(t, []ValueChange{{Name: "new", Target: "value"}, {Name: "version", Base: "v1", Target: "v2"}},
+		comparison.Parameters)
+	assert.Equal(t, []StageChange{{
+		Name: "build", BaseResult: "SUCCESS", TargetResult: "FAILURE",
+		BaseDurationInMillis: intPtr(100), TargetDurationInMillis: intPtr(300),
+	}, {
+		Name: "test", TargetResult: "SUCCESS", TargetDurationInMillis: intPtr(20),
+	}, {
+		Name: "lint", BaseResult: "SUCCESS", BaseDurationInMillis: intPtr(10),
+	}}, comparison.Stages)
+	assert.Equal(t, TestChange{Introduced: []string{"a.added", "a.broken"}, Fixed: []string{"a.fixed"}}, comparison.Tests)
+	assert.Equal(t, []ValueChange{{Name: "app.jar", Base: "1", Target: "2"}, {Name: "new.txt", Target: "n"}},
+		comparison.Artifacts)
+}
+
+func TestCompareNotStarted(t *testing.T) {
+	base := &RunSnapshot{PipelineRun: &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "base"}}}
+	target := &RunSnapshot{PipelineRun: &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "target"}}}
+
+	comparison := Compare(base, target)
+	assert.Equal(t, &Comparison{
+		Base:   RunSummary{Name: "base"},
+		Target: RunSummary{Name: "target"},
+	}, comparison)
+}
+
+func TestCompareRecordedJenkinsfile(t *testing.T) {
+	base := newRunSnapshot("base", "abc", "", nil)
+	base.PipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey] = "abc"
+	base.PipelineRun.Annotations[v1alpha3.PipelineRunJenkinsfileDigestAnnoKey] = "digest"
+	target := newRunSnapshot("target", "def", "pipeline {}", nil)
+
+	comparison := Compare(base, target)
+	assert.Equal(t, "abc", comparison.Base.Revision)
+	assert.Equal(t, "digest", comparison.Base.JenkinsfileDigest)
+	assert.Equal(t, "def", comparison.Target.Revision)
+	assert.NotEmpty(t, comparison.Target.JenkinsfileDigest)
+}
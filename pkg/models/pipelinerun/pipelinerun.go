@@ -16,7 +16,15 @@ limitations under the License.
 
 package pipelinerun
 
-import "github.com/jenkins-zh/jenkins-client/pkg/job"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	cmstore "kubesphere.io/devops/pkg/store/configmap"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 // NodeDetail contains metadata of node and an array of steps.
 type NodeDetail struct {
@@ -30,3 +38,24 @@ type Step struct {
 	// Approvable is a transient field for different users and should not be persisted.
 	Approvable bool `json:"approvable,omitempty"`
 }
+
+// GetStages returns the stages of a PipelineRun from its annotations, or the store if they were moved out
+func GetStages(ctx context.Context, c client.Client, pr *v1alpha3.PipelineRun) (stages []NodeDetail, err error) {
+	stagesJSON, ok := pr.Annotations[v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey]
+	if !ok {
+		if pipelineRunStore, err := cmstore.NewConfigMapStore(ctx, client.ObjectKey{
+			Namespace: pr.Namespace,
+			Name:      pr.Name,
+		}, c); err != nil {
+			// If the stages status does not exist, set it as an empty array
+			stagesJSON = "[]"
+		} else {
+			stagesJSON = pipelineRunStore.GetStages()
+		}
+	}
+	if stagesJSON == "" {
+		return
+	}
+	err = json.Unmarshal([]byte(stagesJSON), &stages)
+	return
+}
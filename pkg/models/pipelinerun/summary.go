@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"sort"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// Summarize aggregates the PipelineRuns of a Pipeline. The PipelineRuns which are being deleted are ignored.
+func Summarize(pipelineRuns []v1alpha3.PipelineRun) *v1alpha3.PipelineRunsSummary {
+	summary := &v1alpha3.PipelineRunsSummary{}
+	latestRuns := map[string]*v1alpha3.PipelineRun{}
+	var lastSuccessfulRun *v1alpha3.PipelineRun
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		if !pr.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		branch := getBranch(pr)
+		if latest, ok := latestRuns[branch]; !ok || isCreatedAfter(pr, latest) {
+			latestRuns[branch] = pr
+		}
+
+		switch pr.Status.Phase {
+		case v1alpha3.Succeeded:
+			summary.FinishedRuns++
+			summary.SucceededRuns++
+			if lastSuccessfulRun == nil || lastSuccessfulRun.Status.CompletionTime.Before(pr.Status.CompletionTime) {
+				lastSuccessfulRun = pr
+			}
+		case v1alpha3.Failed:
+			summary.FinishedRuns++
+		}
+	}
+
+	if summary.FinishedRuns > 0 {
+		summary.SuccessRate = summary.SucceededRuns * 100 / summary.FinishedRuns
+	}
+	if lastSuccessfulRun != nil {
+		summary.LastSuccessfulRun = lastSuccessfulRun.Name
+	}
+	for branch, pr := range latestRuns {
+		summary.LatestRuns = append(summary.LatestRuns, v1alpha3.BranchRunSummary{
+			Branch:         branch,
+			Name:           pr.Name,
+			Phase:          pr.Status.Phase,
+			StartTime:      pr.Status.StartTime,
+			CompletionTime: pr.Status.CompletionTime,
+		})
+	}
+	sort.Slice(summary.LatestRuns, func(i, j int) bool {
+		return summary.LatestRuns[i].Branch < summary.LatestRuns[j].Branch
+	})
+	return summary
+}
+
+func getBranch(pr *v1alpha3.PipelineRun) string {
+	if pr.Spec.SCM == nil {
+		return ""
+	}
+	return pr.Spec.SCM.RefName
+}
+
+func isCreatedAfter(pr, another *v1alpha3.PipelineRun) bool {
+	if pr.CreationTimestamp.Equal(&another.CreationTimestamp) {
+		return pr.Name > another.Name
+	}
+	return another.CreationTimestamp.Before(&pr.CreationTimestamp)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func newSummarizedRun(name, branch string, created time.Time, phase v1alpha3.RunPhase) v1alpha3.PipelineRun {
+	pr := v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "pipeline"}},
+	}
+	if branch != "" {
+		pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+	}
+	pr.Status.Phase = phase
+	if phase == v1alpha3.Succeeded || phase == v1alpha3.Failed {
+		completed := metav1.NewTime(created.Add(time.Minute))
+		pr.Status.CompletionTime = &completed
+	}
+	return pr
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("no PipelineRuns", func(t *testing.T) {
+		assert.Equal(t, &v1alpha3.PipelineRunsSummary{}, Summarize(nil))
+	})
+
+	t.Run("multi-branch Pipeline", func(t *testing.T) {
+		deleting := newSummarizedRun("deleting", "main", now.Add(time.Hour), v1alpha3.Failed)
+		deleting.DeletionTimestamp = &metav1.Time{Time: now}
+		summary := Summarize([]v1alpha3.PipelineRun{
+			newSummarizedRun("main-1", "main", now, v1alpha3.Succeeded),
+			newSummarizedRun("main-2", "main", now.Add(time.Minute), v1alpha3.Succeeded),
+			newSummarizedRun("main-3", "main", now.Add(2*time.Minute), v1alpha3.Running),
+			newSummarizedRun("dev-1", "dev", now, v1alpha3.Failed),
+			newSummarizedRun("dev-2", "dev", now.Add(time.Minute), v1alpha3.Cancelled),
+			deleting,
+		})
+		assert.Equal(t, 3, summary.FinishedRuns)
+		assert.Equal(t, 2, summary.SucceededRuns)
+		assert.Equal(t, 66, summary.SuccessRate)
+		assert.Equal(t, "main-2", summary.LastSuccessfulRun)
+		if assert.Len(t, summary.LatestRuns, 2) {
+			assert.Equal(t, "dev", summary.LatestRuns[0].Branch)
+			assert.Equal(t, "dev-2", summary.LatestRuns[0].Name)
+			assert.Equal(t, v1alpha3.Cancelled, summary.LatestRuns[0].Phase)
+			assert.Equal(t, "main", summary.LatestRuns[1].Branch)
+			assert.Equal(t, "main-3", summary.LatestRuns[1].Name)
+			assert.Equal(t, v1alpha3.Running, summary.LatestRuns[1].Phase)
+		}
+	})
+
+	t.Run("the PipelineRuns were created at the same time", func(t *testing.T) {
+		summary := Summarize([]v1alpha3.PipelineRun{
+			newSummarizedRun("run-b", "", now, v1alpha3.Failed),
+			newSummarizedRun("run-a", "", now, v1alpha3.Failed),
+		})
+		assert.Equal(t, 0, summary.SuccessRate)
+		assert.Empty(t, summary.LastSuccessfulRun)
+		if assert.Len(t, summary.LatestRuns, 1) {
+			assert.Equal(t, "run-b", summary.LatestRuns[0].Name)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// TimelineItemType is the type of an item of the timeline
+type TimelineItemType string
+
+const (
+	// TimelineItemPending is the time from the creation of a PipelineRun to entering the Jenkins queue
+	TimelineItemPending TimelineItemType = "pending"
+	// TimelineItemQueue is the time waiting in the Jenkins queue, or waiting for an executor inside a stage
+	TimelineItemQueue TimelineItemType = "queue"
+	// TimelineItemStage is a stage
+	TimelineItemStage TimelineItemType = "stage"
+	// TimelineItemParallel is a branch of the parallel stages
+	TimelineItemParallel TimelineItemType = "parallel"
+	// TimelineItemStep is a step of a stage
+	TimelineItemStep TimelineItemType = "step"
+)
+
+// Timeline contains the items of a PipelineRun ordered by the start time, it is structured for Gantt rendering
+type Timeline struct {
+	StartTime *time.Time     `json:"startTime,omitempty"`
+	EndTime   *time.Time     `json:"endTime,omitempty"`
+	Items     []TimelineItem `json:"items"`
+}
+
+// TimelineItem is a bar of the Gantt chart. The end time is absent if the item is not finished,
+// the duration is calculated to the current time in this case.
+type TimelineItem struct {
+	ID   string           `json:"id"`
+	Name string           `json:"name"`
+	Type TimelineItemType `json:"type"`
+	// Parent is the ID of the stage which the item belongs to
+	Parent string `json:"parent,omitempty"`
+	// Dependencies are the IDs of the items which need to be finished before this one
+	Dependencies     []string   `json:"dependencies,omitempty"`
+	StartTime        *time.Time `json:"startTime,omitempty"`
+	EndTime          *time.Time `json:"endTime,omitempty"`
+	DurationInMillis int64      `json:"durationInMillis"`
+	State            string     `json:"state,omitempty"`
+	Result           string     `json:"result,omitempty"`
+}
+
+// BuildTimeline builds the timeline of a PipelineRun from the Jenkins run and the workflow node graph.
+// The run and nodes are optional, they are absent before the PipelineRun is started.
+func BuildTimeline(pr *v1alpha3.PipelineRun, run *job.PipelineRun, nodes []NodeDetail, now time.Time) *Timeline {
+	timeline := &Timeline{Items: []TimelineItem{}}
+	created := pr.CreationTimestamp.Time
+	if !created.IsZero() {
+		timeline.StartTime = &created
+	}
+	if pr.Status.CompletionTime != nil {
+		timeline.EndTime = &pr.Status.CompletionTime.Time
+	}
+
+	if run != nil && !run.EnQueueTime.IsZero() {
+		enqueued := run.EnQueueTime.Time
+		if !created.IsZero() && enqueued.After(created) {
+			timeline.addItem(now, TimelineItem{ID: string(TimelineItemPending), Name: "Pending",
+				Type: TimelineItemPending}, created, &enqueued)
+		}
+		var started *time.Time
+		if !run.StartTime.IsZero() {
+			started = &run.StartTime.Time
+		}
+		name := run.CauseOfBlockage
+		if name == "" {
+			name = "Waiting in the queue"
+		}
+		timeline.addItem(now, TimelineItem{ID: string(TimelineItemQueue), Name: name, Type: TimelineItemQueue},
+			enqueued, started)
+	}
+
+	// the edges point to the next nodes, they are reversed to find the dependencies
+	dependencies := map[string][]string{}
+	for _, node := range nodes {
+		for _, edge := range node.Edges {
+			dependencies[edge.ID] = append(dependencies[edge.ID], node.ID)
+		}
+	}
+	for _, node := range nodes {
+		if node.StartTime.IsZero() {
+			// the node is not started yet, or skipped by the when conditions
+			continue
+		}
+		item := TimelineItem{
+			ID:     node.ID,
+			Name:   node.DisplayName,
+			Type:   TimelineItemStage,
+			State:  node.State,
+			Result: node.Result,
+		}
+		if strings.EqualFold(node.Type, string(TimelineItemParallel)) {
+			item.Type, item.Parent = TimelineItemParallel, node.FirstParent
+		}
+		for _, dependency := range dependencies[node.ID] {
+			if dependency != item.Parent {
+				item.Dependencies = append(item.Dependencies, dependency)
+			}
+		}
+		start := node.StartTime.Time
+		timeline.addItem(now, item, start, finishedAt(node.State, start, int64(node.DurationInMillis)))
+
+		if node.CauseOfBlockage != "" {
+			timeline.addItem(now, TimelineItem{ID: node.ID + "-queue", Name: node.CauseOfBlockage,
+				Type: TimelineItemQueue, Parent: node.ID, State: node.State}, start, nil)
+		}
+		for _, step := range node.Steps {
+			if step.StartTime.IsZero() {
+				continue
+			}
+			stepStart := step.StartTime.Time
+			timeline.addItem(now, TimelineItem{ID: step.ID, Name: step.DisplayName, Type: TimelineItemStep,
+				Parent: node.ID, State: step.State, Result: step.Result},
+				stepStart, finishedAt(step.State, stepStart, step.DurationInMillis))
+		}
+	}
+
+	sort.SliceStable(timeline.Items, func(i, j int) bool {
+		return timeline.Items[i].StartTime.Before(*timeline.Items[j].StartTime)
+	})
+	return timeline
+}
+
+func (t *Timeline) addItem(now time.Time, item TimelineItem, start time.Time, end *time.Time) {
+	item.StartTime, item.EndTime = &start, end
+	if end == nil {
+		end = &now
+	}
+	if item.DurationInMillis = end.Sub(start).Milliseconds(); item.DurationInMillis < 0 {
+		item.DurationInMillis = 0
+	}
+	t.Items = append(t.Items, item)
+}
+
+// finishedAt returns the end time of a node or step, it's nil if it's still in progress
+func finishedAt(state string, start time.Time, durationInMillis int64) *time.Time {
+	switch state {
+	case "RUNNING", "PAUSED", "QUEUED":
+		return nil
+	}
+	end := start.Add(time.Duration(durationInMillis) * time.Millisecond)
+	return &end
+}
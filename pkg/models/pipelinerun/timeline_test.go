@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	base := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	at := func(millis int64) time.Time {
+		return base.Add(time.Duration(millis) * time.Millisecond)
+	}
+	ptr := func(millis int64) *time.Time {
+		t := at(millis)
+		return &t
+	}
+
+	pr := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr", CreationTimestamp: metav1.NewTime(base)}}
+	t.Run("not started", func(t *testing.T) {
+		timeline := BuildTimeline(pr, nil, nil, at(1000))
+		assert.Equal(t, &base, timeline.StartTime)
+		assert.Nil(t, timeline.EndTime)
+		assert.Empty(t, timeline.Items)
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		run := &job.PipelineRun{BlueItemRun: job.BlueItemRun{EnQueueTime: job.Time{Time: at(500)},
+			CauseOfBlockage: "Waiting for next available executor"}}
+		timeline := BuildTimeline(pr, run, nil, at(2000))
+		assert.Equal(t, []TimelineItem{{
+			ID: "pending", Name: "Pending", Type: TimelineItemPending,
+			StartTime: ptr(0), EndTime: ptr(500), DurationInMillis: 500,
+		}, {
+			ID: "queue", Name: "Waiting for next available executor", Type: TimelineItemQueue,
+			StartTime: ptr(500), DurationInMillis: 1500,
+		}}, timeline.Items)
+	})
+
+	t.Run("running", func(t *testing.T) {
+		run := &job.PipelineRun{BlueItemRun: job.BlueItemRun{
+			EnQueueTime: job.Time{Time: at(0)}, StartTime: job.Time{Time: at(100)}}}
+		var nodes []NodeDetail
+		assert.Nil(t, json.Unmarshal([]byte(`[{
+  "id": "3", "displayName": "build", "type": "STAGE", "state": "FINISHED", "result": "SUCCESS",
+  "startTime": "2022-10-01T08:00:00.200+0000", "durationInMillis": 1000, "edges": [{"id": "9"}, {"id": "10"}],
+  "steps": [{"id": "5", "displayName": "Shell Script", "state": "FINISHED", "result": "SUCCESS",
+    "startTime": "2022-10-01T08:00:00.300+0000", "durationInMillis": 800}]
+}, {
+  "id": "9", "displayName": "unit", "type": "PARALLEL", "firstParent": "3", "state": "RUNNING",
+  "startTime": "2022-10-01T08:00:01.200+0000", "durationInMillis": 300, "edges": [{"id": "20"}],
+  "causeOfBlockage": "Waiting for next available executor"
+}, {
+  "id": "10", "displayName": "lint", "type": "PARALLEL", "firstParent": "3", "state": "FINISHED",
+  "result": "FAILURE", "startTime": "2022-10-01T08:00:01.250+0000", "durationInMillis": 250,
+  "edges": [{"id": "20"}]
+}, {
+  "id": "20", "displayName": "deploy", "type": "STAGE", "startTime": null
+}]`), &nodes))
+
+		timeline := BuildTimeline(pr, run, nodes, at(2000))
+		assert.Equal(t, []TimelineItem{{
+			ID: "queue", Name: "Waiting in the queue", Type: TimelineItemQueue,
+			StartTime: ptr(0), EndTime: ptr(100), DurationInMillis: 100,
+		}, {
+			ID: "3", Name: "build", Type: TimelineItemStage, State: "FINISHED", Result: "SUCCESS",
+			StartTime: ptr(200), EndTime: ptr(1200), DurationInMillis: 1000,
+		}, {
+			ID: "5", Name: "Shell Script", Type: TimelineItemStep, Parent: "3", State: "FINISHED", Result: "SUCCESS",
+			StartTime: ptr(300), EndTime: ptr(1100), DurationInMillis: 800,
+		}, {
+			ID: "9", Name: "unit", Type: TimelineItemParallel, Parent: "3", State: "RUNNING",
+			StartTime: ptr(1200), DurationInMillis: 800,
+		}, {
+			ID: "9-queue", Name: "Waiting for next available executor", Type: TimelineItemQueue, Parent: "9",
+			State: "RUNNING", StartTime: ptr(1200), DurationInMillis: 800,
+		}, {
+			ID: "10", Name: "lint", Type: TimelineItemParallel, Parent: "3", State: "FINISHED", Result: "FAILURE",
+			StartTime: ptr(1250), EndTime: ptr(1500), DurationInMillis: 250,
+		}}, normalize(timeline.Items))
+	})
+}
+
+// normalize converts the times to UTC, they are parsed in the local time zone
+func normalize(items []TimelineItem) []TimelineItem {
+	for i := range items {
+		start := items[i].StartTime.UTC()
+		items[i].StartTime = &start
+		if items[i].EndTime != nil {
+			end := items[i].EndTime.UTC()
+			items[i].EndTime = &end
+		}
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// AddAgentUsageSample adds a sample from the metrics server into the resource usage of an agent pod,
+// it returns false if the sample has been added
+func AddAgentUsageSample(usage *v1alpha3.AgentPodUsage, pod *v1.Pod, metrics *metricsv1beta1.PodMetrics) bool {
+	if usage.LastSampleTime != nil && !metrics.Timestamp.After(usage.LastSampleTime.Time) {
+		return false
+	}
+	// the usage in a sample is the average of its window, it lasts until the next sample
+	interval := metrics.Window.Duration
+	if usage.LastSampleTime != nil {
+		interval = metrics.Timestamp.Sub(usage.LastSampleTime.Time)
+	}
+	usage.Samples++
+	for _, containerMetrics := range metrics.Containers {
+		containerUsage := getContainerUsage(usage, pod, containerMetrics.Name)
+		cpu, memory := containerMetrics.Usage[v1.ResourceCPU], containerMetrics.Usage[v1.ResourceMemory]
+		if cpu.Cmp(containerUsage.PeakCPU) > 0 {
+			containerUsage.PeakCPU = cpu.DeepCopy()
+		}
+		if memory.Cmp(containerUsage.PeakMemory) > 0 {
+			containerUsage.PeakMemory = memory.DeepCopy()
+		}
+		samples := int64(usage.Samples)
+		averageCPU := containerUsage.AverageCPU.MilliValue()
+		containerUsage.AverageCPU = *resource.NewMilliQuantity(averageCPU+(cpu.MilliValue()-averageCPU)/samples, resource.DecimalSI)
+		averageMemory := containerUsage.AverageMemory.Value()
+		containerUsage.AverageMemory = *resource.NewQuantity(averageMemory+(memory.Value()-averageMemory)/samples, resource.BinarySI)
+		containerUsage.CPUTimeMillis += cpu.MilliValue() * interval.Milliseconds() / 1000
+	}
+	sampleTime := metav1.NewTime(metrics.Timestamp.Time)
+	usage.LastSampleTime = &sampleTime
+	return true
+}
+
+func getContainerUsage(usage *v1alpha3.AgentPodUsage, pod *v1.Pod, name string) *v1alpha3.ContainerUsage {
+	for i := range usage.Containers {
+		if usage.Containers[i].Name == name {
+			return &usage.Containers[i]
+		}
+	}
+	containerUsage := v1alpha3.ContainerUsage{Name: name}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			containerUsage.Resources = *container.Resources.DeepCopy()
+		}
+	}
+	usage.Containers = append(usage.Containers, containerUsage)
+	return &usage.Containers[len(usage.Containers)-1]
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestAddAgentUsageSample(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{
+		Name: "maven",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+		},
+	}}}}
+	newMetrics := func(offset time.Duration, cpu, memory string) *metricsv1beta1.PodMetrics {
+		return &metricsv1beta1.PodMetrics{
+			Timestamp: metav1.NewTime(start.Add(offset)),
+			Window:    metav1.Duration{Duration: 10 * time.Second},
+			Containers: []metricsv1beta1.ContainerMetrics{{
+				Name:  "maven",
+				Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)},
+			}, {
+				Name:  "jnlp",
+				Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+			}},
+		}
+	}
+
+	usage := &v1alpha3.AgentPodUsage{Pod: "agent"}
+	assert.True(t, AddAgentUsageSample(usage, pod, newMetrics(0, "500m", "1Gi")))
+	assert.True(t, AddAgentUsageSample(usage, pod, newMetrics(30*time.Second, "1500m", "3Gi")))
+	// the same sample is not added twice
+	assert.False(t, AddAgentUsageSample(usage, pod, newMetrics(30*time.Second, "1500m", "3Gi")))
+	assert.True(t, AddAgentUsageSample(usage, pod, newMetrics(60*time.Second, "1", "2Gi")))
+
+	assert.Equal(t, int32(3), usage.Samples)
+	assert.Equal(t, start.Add(time.Minute), usage.LastSampleTime.Time)
+	if assert.Len(t, usage.Containers, 2) {
+		maven := usage.Containers[0]
+		assert.Equal(t, "maven", maven.Name)
+		assert.Equal(t, pod.Spec.Containers[0].Resources, maven.Resources)
+		assert.Equal(t, "1500m", maven.PeakCPU.String())
+		assert.Equal(t, "3Gi", maven.PeakMemory.String())
+		assert.Equal(t, "1", maven.AverageCPU.String())
+		assert.Equal(t, "2Gi", maven.AverageMemory.String())
+		// 0.5 * 10s + 1.5 * 30s + 1 * 30s
+		assert.Equal(t, int64(80000), maven.CPUTimeMillis)
+
+		jnlp := usage.Containers[1]
+		assert.Equal(t, "jnlp", jnlp.Name)
+		assert.Empty(t, jnlp.Resources.Requests)
+		assert.Equal(t, "10m", jnlp.PeakCPU.String())
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinesource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Definition is a Pipeline or Template which is defined in a file of the repository
+type Definition struct {
+	// Path is the file path in the repository
+	Path   string
+	Object client.Object
+}
+
+// Kind returns the kind of the definition
+func (d *Definition) Kind() string {
+	switch d.Object.(type) {
+	case *v1alpha3.Pipeline:
+		return v1alpha3.ResourceKindPipeline
+	case *v1alpha3.Template:
+		return "Template"
+	}
+	return ""
+}
+
+// Load reads the definitions from the YAML files in a directory of the repository
+func Load(ctx context.Context, contents scm.ContentService, repo, dir, ref string, recursive bool) (definitions []Definition, err error) {
+	var entries []*scm.FileEntry
+	if entries, _, err = contents.List(ctx, repo, dir, ref); err != nil {
+		return nil, fmt.Errorf("failed to list the files in %q, error: %v", dir, err)
+	}
+
+	for _, entry := range entries {
+		// the path of entries is not consistent between the providers
+		filePath := path.Join(dir, entry.Name)
+		switch {
+		case entry.Type == "dir" || entry.Type == "tree":
+			if !recursive {
+				continue
+			}
+			var children []Definition
+			if children, err = Load(ctx, contents, repo, filePath, ref, recursive); err != nil {
+				return
+			}
+			definitions = append(definitions, children...)
+		case strings.HasSuffix(entry.Name, ".yaml") || strings.HasSuffix(entry.Name, ".yml"):
+			var content *scm.Content
+			if content, _, err = contents.Find(ctx, repo, filePath, ref); err != nil {
+				return nil, fmt.Errorf("failed to read file %q, error: %v", filePath, err)
+			}
+			var found []Definition
+			if found, err = Parse(filePath, content.Data); err != nil {
+				return
+			}
+			definitions = append(definitions, found...)
+		}
+	}
+	return
+}
+
+// Parse parses the Pipelines and Templates from a YAML file which might contain many documents,
+// the other kinds of objects are ignored
+func Parse(filePath string, data []byte) (definitions []Definition, err error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err = decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+				return
+			}
+			return nil, fmt.Errorf("failed to parse file %q, error: %v", filePath, err)
+		}
+		if len(obj.Object) == 0 || obj.GetAPIVersion() != v1alpha3.GroupVersion.String() {
+			continue
+		}
+
+		var typed client.Object
+		switch obj.GetKind() {
+		case "Pipeline":
+			typed = &v1alpha3.Pipeline{}
+		case "Template":
+			typed = &v1alpha3.Template{}
+		default:
+			continue
+		}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in file %q, error: %v", obj.GetKind(), filePath, err)
+		}
+		if typed.GetName() == "" {
+			return nil, fmt.Errorf("the name of %s in file %q is required", obj.GetKind(), filePath)
+		}
+		definitions = append(definitions, Definition{Path: filePath, Object: typed})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`apiVersion: devops.kubesphere.io/v1alpha3
+kind: Pipeline
+metadata:
+  name: build
+spec:
+  type: pipeline
+  pipeline:
+    name: build
+---
+apiVersion: devops.kubesphere.io/v1alpha3
+kind: Template
+metadata:
+  name: maven
+spec:
+  template: echo
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+`)
+	definitions, err := Parse("a.yaml", data)
+	assert.Nil(t, err)
+	if assert.Len(t, definitions, 2) {
+		assert.Equal(t, "Pipeline", definitions[0].Kind())
+		assert.Equal(t, "build", definitions[0].Object.GetName())
+		assert.Equal(t, "build", definitions[0].Object.(*v1alpha3.Pipeline).Spec.Pipeline.Name)
+		assert.Equal(t, "Template", definitions[1].Kind())
+		assert.Equal(t, "echo", definitions[1].Object.(*v1alpha3.Template).Spec.Template)
+		assert.Equal(t, "a.yaml", definitions[1].Path)
+	}
+
+	_, err = Parse("b.yaml", []byte("apiVersion: devops.kubesphere.io/v1alpha3\nkind: Pipeline\n"))
+	assert.EqualError(t, err, `the name of Pipeline in file "b.yaml" is required`)
+
+	_, err = Parse("c.yaml", []byte("kind: [Pipeline"))
+	assert.NotNil(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		name = filepath.Join(dir, "owner", "repo", name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+		assert.Nil(t, os.WriteFile(name, []byte(content), 0644))
+	}
+	writeFile("pipelines/a.yaml", "apiVersion: devops.kubesphere.io/v1alpha3\nkind: Pipeline\nmetadata:\n  name: a\n")
+	writeFile("pipelines/README.md", "not a definition")
+	writeFile("pipelines/sub/b.yml", "apiVersion: devops.kubesphere.io/v1alpha3\nkind: Template\nmetadata:\n  name: b\n")
+
+	client, data := fake.NewDefault()
+	data.ContentDir = dir
+
+	definitions, err := Load(context.TODO(), client.Contents, "owner/repo", "pipelines", "master", false)
+	assert.Nil(t, err)
+	if assert.Len(t, definitions, 1) {
+		assert.Equal(t, "pipelines/a.yaml", definitions[0].Path)
+	}
+
+	definitions, err = Load(context.TODO(), client.Contents, "owner/repo", "pipelines", "master", true)
+	assert.Nil(t, err)
+	if assert.Len(t, definitions, 2) {
+		assert.Equal(t, "pipelines/sub/b.yml", definitions[1].Path)
+	}
+
+	_, err = Load(context.TODO(), client.Contents, "owner/repo", "missing", "master", true)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+const (
+	// StatementType is the type of in-toto statement
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PredicateType is the predicate type of SLSA provenance
+	PredicateType = "https://slsa.dev/provenance/v0.2"
+	// BuildType is the type of the builds which are executed by Jenkins
+	BuildType = "https://kubesphere.io/devops/JenkinsPipelineRun@v1"
+	// DefaultBuilderID is the builder ID when it's not specified
+	DefaultBuilderID = "https://kubesphere.io/devops/jenkins"
+
+	// ConfigMapKeyStatement is the key of the provenance statement in ConfigMap
+	ConfigMapKeyStatement = "provenance.json"
+	// ConfigMapKeyEnvelope is the key of the signed DSSE envelope in ConfigMap
+	ConfigMapKeyEnvelope = "provenance.dsse.json"
+	// SecretKeySigningKey is the key of the PEM encoded private key in the signing key Secret
+	SecretKeySigningKey = "key.pem"
+)
+
+// GetConfigMapName returns the name of ConfigMap which stores the provenance of a PipelineRun
+func GetConfigMapName(pr *v1alpha3.PipelineRun) string {
+	return pr.Name + "-provenance"
+}
+
+// Statement is an in-toto statement which attests the SLSA provenance of the subjects
+type Statement struct {
+	Type          string    `json:"_type"`
+	PredicateType string    `json:"predicateType"`
+	Subject       []Subject `json:"subject"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact which was produced by the build
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is the SLSA provenance v0.2
+type Predicate struct {
+	Builder    Builder    `json:"builder"`
+	BuildType  string     `json:"buildType"`
+	Invocation Invocation `json:"invocation"`
+	Metadata   Metadata   `json:"metadata"`
+	Materials  []Material `json:"materials,omitempty"`
+}
+
+// Builder is the identity of the platform which executed the build
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Invocation describes how the build was started
+type Invocation struct {
+	ConfigSource ConfigSource      `json:"configSource"`
+	Parameters   map[string]string `json:"parameters,omitempty"`
+}
+
+// ConfigSource is where the Jenkinsfile came from
+type ConfigSource struct {
+	URI        string            `json:"uri,omitempty"`
+	Digest     map[string]string `json:"digest,omitempty"`
+	EntryPoint string            `json:"entryPoint,omitempty"`
+}
+
+// Metadata contains other properties of the build
+type Metadata struct {
+	BuildInvocationID string       `json:"buildInvocationId"`
+	BuildStartedOn    *time.Time   `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn   *time.Time   `json:"buildFinishedOn,omitempty"`
+	Completeness      Completeness `json:"completeness"`
+	Reproducible      bool         `json:"reproducible"`
+}
+
+// Completeness indicates whether the claims are complete
+type Completeness struct {
+	Parameters  bool `json:"parameters"`
+	Environment bool `json:"environment"`
+	Materials   bool `json:"materials"`
+}
+
+// Material is an input of the build, such as the source repository
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// Generate returns the provenance of a completed PipelineRun.
+// The subjects are the archived files of the build, their digests come from the fingerprints of Jenkins.
+func Generate(pr *v1alpha3.PipelineRun, builderID string, fingerprints []devops.Fingerprint) *Statement {
+	if builderID == "" {
+		builderID = DefaultBuilderID
+	}
+	statement := &Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Subject:       []Subject{},
+		Predicate: Predicate{
+			Builder:   Builder{ID: builderID},
+			BuildType: BuildType,
+			Metadata: Metadata{
+				BuildInvocationID: string(pr.UID),
+				// the parameters are passed by the PipelineRun only
+				Completeness: Completeness{Parameters: true},
+			},
+		},
+	}
+	for _, fingerprint := range fingerprints {
+		statement.Subject = append(statement.Subject, Subject{
+			Name:   fingerprint.FileName,
+			Digest: map[string]string{"md5": fingerprint.Hash},
+		})
+	}
+	if len(pr.Spec.Parameters) > 0 {
+		statement.Predicate.Invocation.Parameters = map[string]string{}
+		for _, param := range pr.Spec.Parameters {
+			statement.Predicate.Invocation.Parameters[param.Name] = param.Value
+		}
+	}
+	if pr.Status.StartTime != nil {
+		startTime := pr.Status.StartTime.UTC()
+		statement.Predicate.Metadata.BuildStartedOn = &startTime
+	}
+	if pr.Status.CompletionTime != nil {
+		completionTime := pr.Status.CompletionTime.UTC()
+		statement.Predicate.Metadata.BuildFinishedOn = &completionTime
+	}
+	statement.Predicate.Invocation.ConfigSource = getConfigSource(pr)
+	if source := statement.Predicate.Invocation.ConfigSource; source.URI != "" && len(source.Digest) > 0 {
+		statement.Predicate.Materials = []Material{{URI: source.URI, Digest: source.Digest}}
+	}
+	return statement
+}
+
+// getConfigSource returns the SCM repository and commit of a multi-branch Pipeline,
+// or the digest of the inline Jenkinsfile.
+func getConfigSource(pr *v1alpha3.PipelineRun) (source ConfigSource) {
+	spec := pr.Spec.PipelineSpec
+	if spec == nil {
+		return
+	}
+	if spec.MultiBranchPipeline != nil {
+		source.URI = spec.MultiBranchPipeline.GetGitURL()
+		source.EntryPoint = spec.MultiBranchPipeline.ScriptPath
+		if source.EntryPoint == "" {
+			source.EntryPoint = "Jenkinsfile"
+		}
+		run := &job.PipelineRun{}
+		if err := json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStatusAnnoKey]), run); err == nil && run.CommitID != "" {
+			source.Digest = map[string]string{"sha1": run.CommitID}
+		}
+	} else if spec.Pipeline != nil {
+		source.URI = fmt.Sprintf("pipelines.devops.kubesphere.io/%s/%s", pr.Namespace, pr.Labels[v1alpha3.PipelineNameLabelKey])
+		digest := sha256.Sum256([]byte(spec.Pipeline.Jenkinsfile))
+		source.Digest = map[string]string{"sha256": hex.EncodeToString(digest[:])}
+	}
+	return
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestGenerate(t *testing.T) {
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(time.Minute))
+	runData, _ := json.Marshal(&job.PipelineRun{CommitID: "abc"})
+	multiBranch := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "pr",
+			UID:         "uid",
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunStatusAnnoKey: string(runData)},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			Parameters: []v1alpha3.Parameter{{Name: "version", Value: "v1"}},
+			PipelineSpec: &v1alpha3.PipelineSpec{
+				Type: v1alpha3.MultiBranchPipelineType,
+				MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+					SourceType: v1alpha3.SourceTypeGit,
+					GitSource:  &v1alpha3.GitSource{Url: "https://github.com/kubesphere/ks-devops"},
+					ScriptPath: "ci/Jenkinsfile",
+				},
+			},
+		},
+		Status: v1alpha3.PipelineRunStatus{StartTime: &start, CompletionTime: &completion},
+	}
+
+	statement := Generate(multiBranch, "", []devops.Fingerprint{{FileName: "app.jar", Hash: "123"}})
+	assert.Equal(t, StatementType, statement.Type)
+	assert.Equal(t, PredicateType, statement.PredicateType)
+	assert.Equal(t, []Subject{{Name: "app.jar", Digest: map[string]string{"md5": "123"}}}, statement.Subject)
+	assert.Equal(t, DefaultBuilderID, statement.Predicate.Builder.ID)
+	assert.Equal(t, Invocation{
+		ConfigSource: ConfigSource{
+			URI:        "https://github.com/kubesphere/ks-devops",
+			Digest:     map[string]string{"sha1": "abc"},
+			EntryPoint: "ci/Jenkinsfile",
+		},
+		Parameters: map[string]string{"version": "v1"},
+	}, statement.Predicate.Invocation)
+	assert.Equal(t, []Material{{URI: "https://github.com/kubesphere/ks-devops", Digest: map[string]string{"sha1": "abc"}}},
+		statement.Predicate.Materials)
+	assert.Equal(t, "uid", statement.Predicate.Metadata.BuildInvocationID)
+	assert.Equal(t, start.UTC(), *statement.Predicate.Metadata.BuildStartedOn)
+	assert.Equal(t, completion.UTC(), *statement.Predicate.Metadata.BuildFinishedOn)
+	assert.True(t, statement.Predicate.Metadata.Completeness.Parameters)
+
+	inline := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "pr",
+			Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineSpec: &v1alpha3.PipelineSpec{
+				Type:     v1alpha3.NoScmPipelineType,
+				Pipeline: &v1alpha3.NoScmPipeline{Jenkinsfile: "pipeline {}"},
+			},
+		},
+	}
+	statement = Generate(inline, "https://example.com/builder", nil)
+	assert.Equal(t, "https://example.com/builder", statement.Predicate.Builder.ID)
+	assert.Equal(t, []Subject{}, statement.Subject)
+	assert.Equal(t, "pipelines.devops.kubesphere.io/ns/pipeline", statement.Predicate.Invocation.ConfigSource.URI)
+	assert.Len(t, statement.Predicate.Invocation.ConfigSource.Digest["sha256"], 64)
+	assert.Nil(t, statement.Predicate.Invocation.Parameters)
+	assert.Nil(t, statement.Predicate.Metadata.BuildStartedOn)
+}
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	data, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data})
+}
+
+func TestSigner(t *testing.T) {
+	key, keyPEM := generateKey(t)
+	signer, err := NewSigner(keyPEM)
+	assert.Nil(t, err)
+
+	statement := &Statement{Type: StatementType, PredicateType: PredicateType}
+	envelope, err := signer.Sign(statement)
+	assert.Nil(t, err)
+	assert.Equal(t, PayloadType, envelope.PayloadType)
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	assert.Nil(t, err)
+	signed := &Statement{}
+	assert.Nil(t, json.Unmarshal(payload, signed))
+	assert.Equal(t, statement, signed)
+	assert.Nil(t, signer.Verify(envelope))
+
+	// the payload was tampered
+	envelope.Payload = base64.StdEncoding.EncodeToString([]byte("{}"))
+	assert.NotNil(t, signer.Verify(envelope))
+
+	publicKeyPEM, err := signer.PublicKeyPEM()
+	assert.Nil(t, err)
+	block, _ := pem.Decode(publicKeyPEM)
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	assert.Nil(t, err)
+	assert.True(t, key.PublicKey.Equal(publicKey))
+
+	// PKCS #8 format
+	data, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	_, err = NewSigner(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}))
+	assert.Nil(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	data, err = x509.MarshalPKCS8PrivateKey(rsaKey)
+	assert.Nil(t, err)
+	_, err = NewSigner(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}))
+	assert.NotNil(t, err)
+
+	_, err = NewSigner([]byte("invalid"))
+	assert.NotNil(t, err)
+}
+
+func TestUploadToRekor(t *testing.T) {
+	var entries []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/log/entries" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		entry := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&entry)
+		if len(entries) > 0 {
+			w.Header().Set("Location", "/api/v1/log/entries/existing")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		entries = append(entries, entry)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"created":{"logIndex":1}}`))
+	}))
+	defer server.Close()
+
+	envelope := &Envelope{PayloadType: PayloadType, Payload: "e30="}
+	uuid, err := UploadToRekor(server.Client(), server.URL+"/", envelope, []byte("public key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "created", uuid)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "intoto", entries[0]["kind"])
+		spec := entries[0]["spec"].(map[string]interface{})
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("public key")), spec["publicKey"])
+	}
+
+	uuid, err = UploadToRekor(server.Client(), server.URL, envelope, []byte("public key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "existing", uuid)
+
+	_, err = UploadToRekor(server.Client(), server.URL+"/fake", envelope, []byte("public key"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// UploadToRekor adds the signed envelope into the transparency log of Rekor, then returns the UUID of the log entry.
+// See also https://github.com/sigstore/rekor/tree/main/pkg/types/intoto
+func UploadToRekor(httpClient *http.Client, rekorURL string, envelope *Envelope, publicKeyPEM []byte) (uuid string, err error) {
+	var envelopeData []byte
+	if envelopeData, err = json.Marshal(envelope); err != nil {
+		return
+	}
+	entry := map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "intoto",
+		"spec": map[string]interface{}{
+			"content": map[string]string{
+				"envelope": string(envelopeData),
+			},
+			"publicKey": base64.StdEncoding.EncodeToString(publicKeyPEM),
+		},
+	}
+	var data []byte
+	if data, err = json.Marshal(entry); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = httpClient.Post(strings.TrimSuffix(rekorURL, "/")+"/api/v1/log/entries",
+		"application/json", bytes.NewReader(data)); err != nil {
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusConflict {
+		// the entry exists already, the location is the URL of the existing entry
+		location := resp.Header.Get("Location")
+		uuid = location[strings.LastIndex(location, "/")+1:]
+		return
+	}
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = fmt.Errorf("unexpected status code %d from Rekor: %s", resp.StatusCode, string(body))
+		return
+	}
+
+	entries := map[string]json.RawMessage{}
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return
+	}
+	for uuid = range entries {
+		return
+	}
+	err = fmt.Errorf("no log entry is returned from Rekor")
+	return
+}
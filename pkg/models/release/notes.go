@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+var (
+	// pullRequestPatterns match the merge commits of GitHub and GitLab, and the squashed commits of GitHub
+	pullRequestPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^Merge pull request #(\d+) `),
+		regexp.MustCompile(`(?m)^See merge request \S*!(\d+)$`),
+		regexp.MustCompile(`^[^\n]*\(#(\d+)\)\s*(?:\n|$)`),
+	}
+	// issuePattern matches the closing keywords of GitHub and GitLab, such as "Fixes #12"
+	issuePattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+#(\d+)`)
+)
+
+// Notes are the release notes between two revisions
+type Notes struct {
+	// From is the revision of the previous release, it's empty if there is no previous release
+	From string `json:"from,omitempty"`
+	// To is the revision of the release
+	To           string        `json:"to"`
+	PullRequests []PullRequest `json:"pullRequests"`
+	// Commits are the commits which are not linked to the pull requests
+	Commits []Commit `json:"commits"`
+	// Issues are the numbers of the issues which are closed by the commits or pull requests
+	Issues []int `json:"issues"`
+	// Truncated is true if there are more than MaxChangelogCommits commits
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// PullRequest is a merged pull request, or a merge request of GitLab
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Author string `json:"author,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+// Commit is a commit of the release notes
+type Commit struct {
+	Sha     string `json:"sha"`
+	Subject string `json:"subject"`
+	Author  string `json:"author,omitempty"`
+}
+
+// GenerateNotes returns the release notes of the commits from the revision "to" back to the revision "from",
+// both of them could be a commit ID, branch, or tag. All the commits until "to" are included if "from" is empty.
+// The titles of the pull requests are taken from the commit messages if they are not found.
+func GenerateNotes(ctx context.Context, c *scm.Client, repo, from, to string) (notes *Notes, err error) {
+	notes = &Notes{From: from, To: to, PullRequests: []PullRequest{}, Commits: []Commit{}, Issues: []int{}}
+	var fromSha string
+	if from != "" {
+		var commit *scm.Commit
+		if commit, _, err = c.Git.FindCommit(ctx, repo, from); err != nil {
+			err = fmt.Errorf("failed to find the revision %s, error: %v", from, err)
+			return
+		}
+		fromSha = commit.Sha
+	}
+	var commits []*scm.Commit
+	if commits, notes.Truncated, err = listCommits(ctx, c, repo, to, fromSha); err != nil {
+		err = fmt.Errorf("failed to list the commits of %s, error: %v", to, err)
+		return
+	}
+
+	issues := map[int]bool{}
+	for _, commit := range commits {
+		collectIssues(commit.Message, issues)
+		number := parsePullRequest(commit.Message)
+		if number == 0 {
+			notes.Commits = append(notes.Commits, Commit{
+				Sha:     commit.Sha,
+				Subject: getSubject(commit.Message),
+				Author:  commit.Author.Name,
+			})
+			continue
+		}
+
+		if containsPullRequest(notes.PullRequests, number) {
+			continue
+		}
+		// the squashed commits end with the number of the pull request, it's not a part of the title
+		title := strings.TrimSpace(strings.TrimSuffix(getSubject(commit.Message), fmt.Sprintf("(#%d)", number)))
+		pullRequest := PullRequest{Number: number, Title: title}
+		if found, _, findErr := c.PullRequests.Find(ctx, repo, number); findErr == nil {
+			pullRequest.Title, pullRequest.Author, pullRequest.Link = found.Title, found.Author.Login, found.Link
+			collectIssues(found.Body, issues)
+		}
+		notes.PullRequests = append(notes.PullRequests, pullRequest)
+	}
+	for number := range issues {
+		notes.Issues = append(notes.Issues, number)
+	}
+	sort.Ints(notes.Issues)
+	return
+}
+
+// Markdown returns the release notes in Markdown, the pull requests and issues are linked by their numbers
+func (n *Notes) Markdown(previousTag string) string {
+	buf := &strings.Builder{}
+	if previousTag != "" {
+		fmt.Fprintf(buf, "## Changes since %s\n\n", previousTag)
+	} else {
+		buf.WriteString("## Changes\n\n")
+	}
+	if len(n.PullRequests) == 0 && len(n.Commits) == 0 {
+		buf.WriteString("No changes.\n")
+	}
+	if len(n.PullRequests) > 0 {
+		buf.WriteString("### Pull requests\n\n")
+		for _, pullRequest := range n.PullRequests {
+			reference := fmt.Sprintf("#%d", pullRequest.Number)
+			if pullRequest.Link != "" {
+				reference = fmt.Sprintf("[%s](%s)", reference, pullRequest.Link)
+			}
+			fmt.Fprintf(buf, "* %s (%s)", pullRequest.Title, reference)
+			if pullRequest.Author != "" {
+				fmt.Fprintf(buf, " @%s", pullRequest.Author)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	if len(n.Commits) > 0 {
+		buf.WriteString("### Commits\n\n")
+		for _, commit := range n.Commits {
+			fmt.Fprintf(buf, "* %s (%s)\n", commit.Subject, shorten(commit.Sha))
+		}
+		buf.WriteString("\n")
+	}
+	if len(n.Issues) > 0 {
+		references := make([]string, len(n.Issues))
+		for i, number := range n.Issues {
+			references[i] = fmt.Sprintf("#%d", number)
+		}
+		fmt.Fprintf(buf, "### Issues\n\n%s\n\n", strings.Join(references, ", "))
+	}
+	if n.Truncated {
+		fmt.Fprintf(buf, "Only the latest %d commits are listed.\n", MaxChangelogCommits)
+	}
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
+
+// listCommits returns the commits from the revision back to the previous one, at most MaxChangelogCommits
+func listCommits(ctx context.Context, c *scm.Client, repo, revision, previous string) (
+	commits []*scm.Commit, truncated bool, err error) {
+	const pageSize = MaxChangelogCommits
+	for page := 1; ; page++ {
+		var items []*scm.Commit
+		// GitHub starts from the sha, and GitLab starts from the ref
+		if items, _, err = c.Git.ListCommits(ctx, repo, scm.CommitListOptions{
+			Ref: revision, Sha: revision, Page: page, Size: pageSize,
+		}); err != nil {
+			return
+		}
+		for _, commit := range items {
+			if commit.Sha == previous {
+				return
+			}
+			if len(commits) >= MaxChangelogCommits {
+				truncated = true
+				return
+			}
+			commits = append(commits, commit)
+		}
+		if len(items) < pageSize {
+			return
+		}
+	}
+}
+
+func parsePullRequest(message string) int {
+	for _, pattern := range pullRequestPatterns {
+		if matches := pattern.FindStringSubmatch(message); matches != nil {
+			number, _ := strconv.Atoi(matches[1])
+			return number
+		}
+	}
+	return 0
+}
+
+func collectIssues(text string, issues map[int]bool) {
+	for _, matches := range issuePattern.FindAllStringSubmatch(text, -1) {
+		if number, err := strconv.Atoi(matches[1]); err == nil {
+			issues[number] = true
+		}
+	}
+}
+
+func containsPullRequest(pullRequests []PullRequest, number int) bool {
+	for _, pullRequest := range pullRequests {
+		if pullRequest.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+func getSubject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateNotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/app/commits/v1.0":
+			_, _ = w.Write([]byte(`{"sha":"c0"}`))
+		case "/repos/org/app/commits":
+			_, _ = w.Write([]byte(`[
+{"sha":"c5","commit":{"message":"Merge pull request #12 from alice/health\n\nAdd the health check","author":{"name":"alice"}}},
+{"sha":"c4","commit":{"message":"Fix the timeout (#11)\n\nCloses #3","author":{"name":"bob"}}},
+{"sha":"c3","commit":{"message":"Update the docs\n\nFixes #5, fixes #3","author":{"name":"bob"}}},
+{"sha":"c2","commit":{"message":"Merge branch 'feature' into 'master'\n\nSee merge request group/app!9","author":{"name":"carol"}}},
+{"sha":"c0","commit":{"message":"Initial commit","author":{"name":"alice"}}}]`))
+		case "/repos/org/app/pulls/12":
+			_, _ = w.Write([]byte(`{"number":12,"title":"Add the health check","body":"Resolves #7",
+"html_url":"https://github.com/org/app/pull/12","user":{"login":"alice"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c, err := github.New(server.URL)
+	assert.Nil(t, err)
+
+	notes, err := GenerateNotes(context.Background(), c, "org/app", "v1.0", "master")
+	assert.Nil(t, err)
+	assert.Equal(t, &Notes{
+		From: "v1.0",
+		To:   "master",
+		PullRequests: []PullRequest{
+			{Number: 12, Title: "Add the health check", Author: "alice", Link: "https://github.com/org/app/pull/12"},
+			// the titles are taken from the commit messages if the pull requests are not found
+			{Number: 11, Title: "Fix the timeout"},
+			{Number: 9, Title: "Merge branch 'feature' into 'master'"},
+		},
+		Commits: []Commit{{Sha: "c3", Subject: "Update the docs", Author: "bob"}},
+		Issues:  []int{3, 5, 7},
+	}, notes)
+
+	assert.Equal(t, `## Changes since v1.0
+
+### Pull requests
+
+* Add the health check ([#12](https://github.com/org/app/pull/12)) @alice
+* Fix the timeout (#11)
+* Merge branch 'feature' into 'master' (#9)
+
+### Commits
+
+* Update the docs (c3)
+
+### Issues
+
+#3, #5, #7
+`, notes.Markdown("v1.0"))
+
+	_, err = GenerateNotes(context.Background(), c, "org/app", "fake", "master")
+	assert.NotNil(t, err)
+}
+
+func TestGenerateNotes_Truncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		commits := make([]string, MaxChangelogCommits)
+		for i := range commits {
+			commits[i] = fmt.Sprintf(`{"sha":"%s-%d","commit":{"message":"commit"}}`, page, i)
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(commits, ",") + "]"))
+	}))
+	defer server.Close()
+	c, err := github.New(server.URL)
+	assert.Nil(t, err)
+
+	notes, err := GenerateNotes(context.Background(), c, "org/app", "", "master")
+	assert.Nil(t, err)
+	assert.True(t, notes.Truncated)
+	assert.Len(t, notes.Commits, MaxChangelogCommits)
+	assert.True(t, strings.HasSuffix(notes.Markdown(""), "Only the latest 100 commits are listed.\n"))
+}
+
+func TestNotes_Markdown(t *testing.T) {
+	assert.Equal(t, "## Changes\n\nNo changes.\n", (&Notes{}).Markdown(""))
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/jenkinsfile"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultMessage is the template of the tag message if it's not specified
+	DefaultMessage = "Release {{.Tag}}"
+	// MaxChangelogCommits is the maximum number of commits in the release notes
+	MaxChangelogCommits = 100
+
+	shortRevisionLength = 7
+)
+
+// ErrUnsupportedProvider means the tags and releases cannot be created by the API of the SCM provider
+var ErrUnsupportedProvider = errors.New("only GitHub and GitLab are supported to create tags and releases")
+
+// Data is the data of the templates of the tag name and message
+type Data struct {
+	Pipeline      string
+	Branch        string
+	RunID         string
+	Revision      string
+	ShortRevision string
+	Date          string
+	Parameters    map[string]string
+	// Tag is only available in the template of the message
+	Tag string
+}
+
+// NewData returns the data of the templates from a PipelineRun which was built from the revision
+func NewData(pr *v1alpha3.PipelineRun, revision string, now time.Time) *Data {
+	data := &Data{
+		Pipeline:      pr.Labels[v1alpha3.PipelineNameLabelKey],
+		Revision:      revision,
+		ShortRevision: shorten(revision),
+		Date:          now.UTC().Format("20060102"),
+		Parameters:    map[string]string{},
+	}
+	data.RunID, _ = pr.GetPipelineRunID()
+	if pr.Spec.SCM != nil {
+		data.Branch = pr.Spec.SCM.RefName
+	}
+	for _, param := range pr.Spec.Parameters {
+		data.Parameters[param.Name] = param.Value
+	}
+	return data
+}
+
+// Render executes a template with the data, the missing fields are not allowed
+func Render(text string, data *Data) (string, error) {
+	tpl, err := template.New("release").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err = tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Selects returns true if the PipelineRun should be released, the pull requests are never released
+func Selects(options *v1alpha3.Release, pr *v1alpha3.PipelineRun) bool {
+	if pr.Spec.SCM == nil || pr.Spec.SCM.RefName == "" || pr.Spec.SCM.IsPullRequest() {
+		return false
+	}
+	if len(options.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range options.Branches {
+		if matched, _ := path.Match(pattern, pr.Spec.SCM.RefName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRevision returns the commit ID which the PipelineRun was built from, it's empty if it's unknown
+func GetRevision(pr *v1alpha3.PipelineRun) string {
+	if revision := pr.Annotations[v1alpha3.PipelineRunJenkinsfileRevisionAnnoKey]; revision != "" {
+		return revision
+	}
+	return jenkinsfile.GetRevision(pr)
+}
+
+// FindPreviousRelease returns the latest released PipelineRun of the same Pipeline and branch, it's nil if there is none
+func FindPreviousRelease(ctx context.Context, c client.Reader, pr *v1alpha3.PipelineRun) (
+	previous *v1alpha3.PipelineRun, err error) {
+	if pr.Spec.SCM == nil {
+		return
+	}
+	pipelineRuns := &v1alpha3.PipelineRunList{}
+	if err = c.List(ctx, pipelineRuns, client.InNamespace(pr.Namespace), client.MatchingLabels{
+		v1alpha3.PipelineNameLabelKey: pr.Labels[v1alpha3.PipelineNameLabelKey],
+	}); err != nil {
+		return
+	}
+	for i := range pipelineRuns.Items {
+		item := &pipelineRuns.Items[i]
+		if item.Name == pr.Name || item.Annotations[v1alpha3.PipelineRunReleaseTagAnnoKey] == "" ||
+			item.Spec.SCM == nil || item.Spec.SCM.RefName != pr.Spec.SCM.RefName || item.Status.CompletionTime == nil {
+			continue
+		}
+		if previous == nil || previous.Status.CompletionTime.Before(item.Status.CompletionTime) {
+			previous = item
+		}
+	}
+	return
+}
+
+// Input is what to publish
+type Input struct {
+	Tag      string
+	Message  string
+	Revision string
+	// PreviousTag and PreviousRevision are the last release, the changelog starts from it
+	PreviousTag      string
+	PreviousRevision string
+	Options          *v1alpha3.Release
+}
+
+// Publish creates the annotated tag of the revision, and the SCM release of the tag if it's asked for.
+// The existing tag and release are kept as they are, so it's safe to publish again after a failure.
+func Publish(ctx context.Context, c *scm.Client, provider, repo string, input *Input) (err error) {
+	var tags tagService
+	switch provider {
+	case "github":
+		tags = &githubTags{client: c}
+	case "gitlab":
+		tags = &gitlabTags{client: c}
+	default:
+		return ErrUnsupportedProvider
+	}
+
+	var exists bool
+	if exists, err = tags.exists(ctx, repo, input.Tag); err != nil {
+		return
+	}
+	if !exists {
+		if err = tags.create(ctx, repo, input.Tag, input.Message, input.Revision); err != nil {
+			return fmt.Errorf("failed to create tag %s, error: %v", input.Tag, err)
+		}
+	}
+	if !input.Options.CreateRelease {
+		return
+	}
+
+	// GitLab doesn't return scm.ErrNotFound, so the status code is checked
+	var res *scm.Response
+	if _, res, err = c.Releases.FindByTag(ctx, repo, input.Tag); err == nil {
+		return
+	} else if res == nil || res.Status != http.StatusNotFound {
+		return fmt.Errorf("failed to find the release of tag %s, error: %v", input.Tag, err)
+	}
+	var notes *Notes
+	if notes, err = GenerateNotes(ctx, c, repo, input.PreviousRevision, input.Revision); err != nil {
+		return
+	}
+	_, _, err = c.Releases.Create(ctx, repo, &scm.ReleaseInput{
+		Title:       input.Tag,
+		Description: notes.Markdown(input.PreviousTag),
+		Tag:         input.Tag,
+		Commitish:   input.Revision,
+		Draft:       input.Options.Draft,
+		Prerelease:  input.Options.Prerelease,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to create the release of tag %s, error: %v", input.Tag, err)
+	}
+	return
+}
+
+func shorten(revision string) string {
+	if len(revision) > shortRevisionLength {
+		return revision[:shortRevisionLength]
+	}
+	return revision
+}
+
+// tagService creates the annotated tags, neither of them is provided by go-scm
+type tagService interface {
+	exists(ctx context.Context, repo, tag string) (bool, error)
+	create(ctx context.Context, repo, tag, message, revision string) error
+}
+
+type githubTags struct {
+	client *scm.Client
+}
+
+func (s *githubTags) exists(ctx context.Context, repo, tag string) (bool, error) {
+	return exists(ctx, s.client, fmt.Sprintf("repos/%s/git/ref/tags/%s", repo, url.PathEscape(tag)))
+}
+
+// create creates the tag object first, then the reference of it
+func (s *githubTags) create(ctx context.Context, repo, tag, message, revision string) (err error) {
+	tagObject := &struct {
+		Sha string `json:"sha"`
+	}{}
+	if err = do(ctx, s.client, http.MethodPost, fmt.Sprintf("repos/%s/git/tags", repo), map[string]string{
+		"tag":     tag,
+		"message": message,
+		"object":  revision,
+		"type":    "commit",
+	}, tagObject); err != nil {
+		return
+	}
+	return do(ctx, s.client, http.MethodPost, fmt.Sprintf("repos/%s/git/refs", repo), map[string]string{
+		"ref": "refs/tags/" + tag,
+		"sha": tagObject.Sha,
+	}, nil)
+}
+
+type gitlabTags struct {
+	client *scm.Client
+}
+
+func (s *gitlabTags) exists(ctx context.Context, repo, tag string) (bool, error) {
+	return exists(ctx, s.client, fmt.Sprintf("api/v4/projects/%s/repository/tags/%s",
+		url.PathEscape(repo), url.PathEscape(tag)))
+}
+
+// create creates an annotated tag because the message is not empty
+func (s *gitlabTags) create(ctx context.Context, repo, tag, message, revision string) error {
+	return do(ctx, s.client, http.MethodPost, fmt.Sprintf("api/v4/projects/%s/repository/tags", url.PathEscape(repo)),
+		map[string]string{
+			"tag_name": tag,
+			"ref":      revision,
+			"message":  message,
+		}, nil)
+}
+
+func exists(ctx context.Context, c *scm.Client, api string) (bool, error) {
+	res, err := c.Do(ctx, &scm.Request{Method: http.MethodGet, Path: api})
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	switch {
+	case res.Status == http.StatusNotFound:
+		return false, nil
+	case res.Status < http.StatusMultipleChoices:
+		return true, nil
+	}
+	return false, fmt.Errorf("unexpected status code %d of %s", res.Status, api)
+}
+
+// do sends a JSON request, then decodes the response into out if it's not nil
+func do(ctx context.Context, c *scm.Client, method, api string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	res, err := c.Do(ctx, &scm.Request{
+		Method: method,
+		Path:   api,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.Status >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status code %d of %s, %s", res.Status, api, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(res.Body).Decode(out)
+	}
+	return nil
+}
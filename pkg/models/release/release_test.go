@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+	"github.com/jenkins-x/go-scm/scm/driver/gitlab"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestRender(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "app"},
+			Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "12"},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			SCM:        &v1alpha3.SCM{RefName: "master"},
+			Parameters: []v1alpha3.Parameter{{Name: "VERSION", Value: "1.2"}},
+		},
+	}
+	data := NewData(pr, "0123456789abcdef", time.Date(2022, 5, 4, 23, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{{
+		name: "run id",
+		text: "v1.0.{{.RunID}}",
+		want: "v1.0.12",
+	}, {
+		name: "all fields",
+		text: "{{.Pipeline}}-{{.Branch}}-{{.Date}}-{{.ShortRevision}}-v{{.Parameters.VERSION}}",
+		want: "app-master-20220504-0123456-v1.2",
+	}, {
+		name:    "missing parameter",
+		text:    "v{{.Parameters.FAKE}}",
+		wantErr: true,
+	}, {
+		name:    "invalid template",
+		text:    "v{{.RunID",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, data)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelects(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches []string
+		scm      *v1alpha3.SCM
+		want     bool
+	}{{
+		name: "without SCM",
+	}, {
+		name: "all branches",
+		scm:  &v1alpha3.SCM{RefName: "master"},
+		want: true,
+	}, {
+		name: "pull request",
+		scm:  &v1alpha3.SCM{RefName: "PR-1", RefType: "pr"},
+	}, {
+		name:     "branch matched",
+		branches: []string{"master", "release-*"},
+		scm:      &v1alpha3.SCM{RefName: "release-1.0"},
+		want:     true,
+	}, {
+		name:     "branch not matched",
+		branches: []string{"master", "release-*"},
+		scm:      &v1alpha3.SCM{RefName: "feature"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{SCM: tt.scm}}
+			assert.Equal(t, tt.want, Selects(&v1alpha3.Release{Branches: tt.branches}, pr))
+		})
+	}
+}
+
+func TestPublish_GitHub(t *testing.T) {
+	var requests []string
+	var release map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/org/app/git/ref/tags/v1.1", "GET /repos/org/app/releases/tags/v1.1":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /repos/org/app/git/tags":
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string]string{"tag": "v1.1", "message": "Release v1.1", "object": "c3", "type": "commit"}, body)
+			_, _ = w.Write([]byte(`{"sha":"tag-object"}`))
+		case "POST /repos/org/app/git/refs":
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string]string{"ref": "refs/tags/v1.1", "sha": "tag-object"}, body)
+			w.WriteHeader(http.StatusCreated)
+		case "GET /repos/org/app/commits/c1":
+			_, _ = w.Write([]byte(`{"sha":"c1"}`))
+		case "GET /repos/org/app/commits":
+			assert.Equal(t, "c3", r.URL.Query().Get("sha"))
+			_, _ = w.Write([]byte(`[{"sha":"c3","commit":{"message":"Third"}},{"sha":"c2","commit":{"message":"Second"}},
+{"sha":"c1","commit":{"message":"First"}}]`))
+		case "POST /repos/org/app/releases":
+			_ = json.NewDecoder(r.Body).Decode(&release)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c, err := github.New(server.URL)
+	assert.Nil(t, err)
+	err = Publish(context.Background(), c, "github", "org/app", &Input{
+		Tag:              "v1.1",
+		Message:          "Release v1.1",
+		Revision:         "c3",
+		PreviousTag:      "v1.0",
+		PreviousRevision: "c1",
+		Options:          &v1alpha3.Release{CreateRelease: true, Prerelease: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"GET /repos/org/app/git/ref/tags/v1.1",
+		"POST /repos/org/app/git/tags",
+		"POST /repos/org/app/git/refs",
+		"GET /repos/org/app/releases/tags/v1.1",
+		"GET /repos/org/app/commits/c1",
+		"GET /repos/org/app/commits",
+		"POST /repos/org/app/releases",
+	}, requests)
+	assert.Equal(t, "v1.1", release["tag_name"])
+	assert.Equal(t, "c3", release["target_commitish"])
+	assert.Equal(t, true, release["prerelease"])
+	assert.Equal(t, "## Changes since v1.0\n\n### Commits\n\n* Third (c3)\n* Second (c2)\n", release["body"])
+}
+
+func TestPublish_GitLab(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v4/projects/group%2Fapp/repository/tags/v1.0":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /api/v4/projects/group%2Fapp/repository/tags":
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, map[string]string{"tag_name": "v1.0", "ref": "c1", "message": "Release v1.0"}, body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c, err := gitlab.New(server.URL)
+	assert.Nil(t, err)
+	input := &Input{Tag: "v1.0", Message: "Release v1.0", Revision: "c1", Options: &v1alpha3.Release{}}
+	assert.Nil(t, Publish(context.Background(), c, "gitlab", "group/app", input))
+	assert.Equal(t, []string{
+		"GET /api/v4/projects/group%2Fapp/repository/tags/v1.0",
+		"POST /api/v4/projects/group%2Fapp/repository/tags",
+	}, requests)
+
+	assert.Equal(t, ErrUnsupportedProvider, Publish(context.Background(), c, "bitbucket-server", "group/app", input))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runevents
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+)
+
+const (
+	// ConfigMapKeyEvents is the key of the events in the ConfigMap
+	ConfigMapKeyEvents = "events.json"
+	// MaxEvents is the max number of the events of a PipelineRun, the later events are dropped
+	MaxEvents = 500
+)
+
+// EventType is the type of run events
+type EventType string
+
+const (
+	// EventSubmitted means the PipelineRun was created
+	EventSubmitted EventType = "Submitted"
+	// EventStarted means the PipelineRun was triggered in Jenkins
+	EventStarted EventType = "Started"
+	// EventStageStarted means a stage or a parallel branch started
+	EventStageStarted EventType = "StageStarted"
+	// EventStageFinished means a stage or a parallel branch finished, the result is in the event
+	EventStageFinished EventType = "StageFinished"
+	// EventApprovalRequested means an input step is waiting for approval
+	EventApprovalRequested EventType = "ApprovalRequested"
+	// EventApprovalResolved means an input step was approved or rejected, the result is in the event
+	EventApprovalResolved EventType = "ApprovalResolved"
+	// EventCompleted means the PipelineRun completed, the phase is in the event
+	EventCompleted EventType = "Completed"
+	// EventTruncated means the events exceeded MaxEvents, no more events are recorded
+	EventTruncated EventType = "Truncated"
+)
+
+// Event is a significant event of a PipelineRun
+type Event struct {
+	// Sequence is the order of the event, it starts from 1
+	Sequence int         `json:"sequence"`
+	Type     EventType   `json:"type"`
+	Time     metav1.Time `json:"time"`
+	NodeID   string      `json:"nodeId,omitempty"`
+	NodeName string      `json:"nodeName,omitempty"`
+	StepID   string      `json:"stepId,omitempty"`
+	Result   string      `json:"result,omitempty"`
+	Message  string      `json:"message,omitempty"`
+}
+
+func (e *Event) key() string {
+	return string(e.Type) + "/" + e.NodeID + "/" + e.StepID
+}
+
+// GetConfigMapName returns the name of the ConfigMap which stores the events of a PipelineRun
+func GetConfigMapName(pr *v1alpha3.PipelineRun) string {
+	return pr.Name + "-events"
+}
+
+// Parse parses the events stored in a ConfigMap, it returns nil if there are no events
+func Parse(data string) (events []Event, err error) {
+	if data == "" {
+		return
+	}
+	err = json.Unmarshal([]byte(data), &events)
+	return
+}
+
+// Observe returns the events of a PipelineRun which have not been recorded yet, ordered by the time.
+// The timestamps reported by Jenkins are preferred, now is used when an event has no timestamp.
+func Observe(pr *v1alpha3.PipelineRun, stages []pipelinerun.NodeDetail, recorded []Event, now time.Time) (events []Event) {
+	seen := map[string]bool{}
+	for i := range recorded {
+		seen[recorded[i].key()] = true
+	}
+	add := func(event Event) {
+		if key := event.key(); !seen[key] {
+			seen[key] = true
+			events = append(events, event)
+		}
+	}
+
+	add(Event{Type: EventSubmitted, Time: pr.CreationTimestamp})
+	if pr.Status.StartTime != nil {
+		add(Event{Type: EventStarted, Time: *pr.Status.StartTime})
+	}
+	for i := range stages {
+		stage := &stages[i]
+		if stage.StartTime.IsZero() {
+			continue
+		}
+		add(Event{Type: EventStageStarted, Time: metav1.NewTime(stage.StartTime.Time),
+			NodeID: stage.ID, NodeName: stage.DisplayName})
+
+		for j := range stage.Steps {
+			step := &stage.Steps[j]
+			requested := Event{Type: EventApprovalRequested, NodeID: stage.ID, NodeName: stage.DisplayName, StepID: step.ID}
+			if step.State == "PAUSED" && step.Input != nil {
+				requested.Time = timeOf(step.StartTime, 0, now)
+				requested.Message = step.Input.Message
+				add(requested)
+			} else if seen[requested.key()] && step.State == "FINISHED" {
+				add(Event{Type: EventApprovalResolved, Time: timeOf(step.StartTime, step.DurationInMillis, now),
+					NodeID: stage.ID, NodeName: stage.DisplayName, StepID: step.ID, Result: step.Result})
+			}
+		}
+
+		if stage.State == "FINISHED" {
+			add(Event{Type: EventStageFinished, Time: timeOf(stage.StartTime, int64(stage.DurationInMillis), now),
+				NodeID: stage.ID, NodeName: stage.DisplayName, Result: stage.Result})
+		}
+	}
+	if pr.HasCompleted() {
+		add(Event{Type: EventCompleted, Time: *pr.Status.CompletionTime, Message: string(pr.Status.Phase)})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(&events[j].Time)
+	})
+	return
+}
+
+// Append appends the events to the recorded ones with increasing sequences. Once the events exceed MaxEvents,
+// a Truncated event is appended and the later events are dropped.
+func Append(recorded, events []Event, now time.Time) []Event {
+	for i := range events {
+		if len(recorded) > 0 && recorded[len(recorded)-1].Type == EventTruncated {
+			break
+		}
+		event := events[i]
+		if len(recorded) == MaxEvents-1 {
+			event = Event{Type: EventTruncated, Time: metav1.NewTime(now)}
+		}
+		event.Sequence = len(recorded) + 1
+		recorded = append(recorded, event)
+	}
+	return recorded
+}
+
+func timeOf(start job.Time, durationInMillis int64, now time.Time) metav1.Time {
+	if start.IsZero() {
+		return metav1.NewTime(now)
+	}
+	return metav1.NewTime(start.Add(time.Duration(durationInMillis) * time.Millisecond))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+)
+
+func TestObserve(t *testing.T) {
+	base := time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC)
+	now := base.Add(time.Hour)
+	at := func(seconds int) metav1.Time {
+		return metav1.NewTime(base.Add(time.Duration(seconds) * time.Second))
+	}
+	startedAt := at(5)
+	completedAt := at(100)
+
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr", CreationTimestamp: at(0)},
+		Status:     v1alpha3.PipelineRunStatus{StartTime: &startedAt},
+	}
+	stages := []pipelinerun.NodeDetail{{
+		Node: job.Node{ID: "1", DisplayName: "build", State: "FINISHED", Result: "SUCCESS",
+			StartTime: job.Time{Time: base.Add(10 * time.Second)}, DurationInMillis: 20000},
+	}, {
+		Node: job.Node{ID: "2", DisplayName: "deploy", State: "PAUSED",
+			StartTime: job.Time{Time: base.Add(30 * time.Second)}},
+		Steps: []pipelinerun.Step{{Step: job.Step{ID: "3", State: "PAUSED",
+			StartTime: job.Time{Time: base.Add(40 * time.Second)}, Input: &job.Input{Message: "deploy?"}}}},
+	}, {
+		Node: job.Node{ID: "4", DisplayName: "notify"},
+	}}
+
+	events := Observe(pr, stages, nil, now)
+	assert.Equal(t, []Event{
+		{Type: EventSubmitted, Time: at(0)},
+		{Type: EventStarted, Time: at(5)},
+		{Type: EventStageStarted, Time: at(10), NodeID: "1", NodeName: "build"},
+		{Type: EventStageFinished, Time: at(30), NodeID: "1", NodeName: "build", Result: "SUCCESS"},
+		{Type: EventStageStarted, Time: at(30), NodeID: "2", NodeName: "deploy"},
+		{Type: EventApprovalRequested, Time: at(40), NodeID: "2", NodeName: "deploy", StepID: "3", Message: "deploy?"},
+	}, events)
+	recorded := Append(nil, events, now)
+	assert.Equal(t, 1, recorded[0].Sequence)
+	assert.Equal(t, 6, recorded[5].Sequence)
+
+	// nothing new is observed
+	assert.Empty(t, Observe(pr, stages, recorded, now))
+
+	// the approval was resolved and the PipelineRun completed
+	stages[1].State, stages[1].Result, stages[1].DurationInMillis = "FINISHED", "SUCCESS", 50000
+	stages[1].Steps[0].State, stages[1].Steps[0].Result, stages[1].Steps[0].DurationInMillis = "FINISHED", "SUCCESS", 30000
+	pr.Status.CompletionTime = &completedAt
+	pr.Status.Phase = v1alpha3.Succeeded
+	events = Observe(pr, stages, recorded, now)
+	assert.Equal(t, []Event{
+		{Type: EventApprovalResolved, Time: at(70), NodeID: "2", NodeName: "deploy", StepID: "3", Result: "SUCCESS"},
+		{Type: EventStageFinished, Time: at(80), NodeID: "2", NodeName: "deploy", Result: "SUCCESS"},
+		{Type: EventCompleted, Time: at(100), Message: string(v1alpha3.Succeeded)},
+	}, events)
+	recorded = Append(recorded, events, now)
+	assert.Equal(t, 9, recorded[8].Sequence)
+}
+
+func TestAppend(t *testing.T) {
+	now := time.Now()
+	events := make([]Event, MaxEvents+10)
+	for i := range events {
+		events[i] = Event{Type: EventStageStarted}
+	}
+
+	recorded := Append(nil, events, now)
+	assert.Len(t, recorded, MaxEvents)
+	assert.Equal(t, EventTruncated, recorded[MaxEvents-1].Type)
+	assert.Equal(t, MaxEvents, recorded[MaxEvents-1].Sequence)
+
+	// no more events are recorded after truncated
+	assert.Len(t, Append(recorded, events, now), MaxEvents)
+}
+
+func TestParse(t *testing.T) {
+	events, err := Parse("")
+	assert.Nil(t, err)
+	assert.Nil(t, events)
+
+	events, err = Parse(`[{"sequence":1,"type":"Submitted","time":"2022-10-01T10:00:00Z"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, EventSubmitted, events[0].Type)
+
+	_, err = Parse("invalid")
+	assert.NotNil(t, err)
+}
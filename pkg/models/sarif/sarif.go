@@ -0,0 +1,271 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const (
+	// ConfigMapKeyFindings is the key of the findings in the ConfigMap
+	ConfigMapKeyFindings = "findings.json"
+	// LevelWarning is the default level of a result in SARIF
+	LevelWarning = "warning"
+)
+
+// GetConfigMapName returns the name of the ConfigMap which stores the findings of a PipelineRun
+func GetConfigMapName(pr *v1alpha3.PipelineRun) string {
+	return pr.Name + "-findings"
+}
+
+// Log is the part of a SARIF 2.1.0 log which the findings are parsed from
+type Log struct {
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the results of a tool
+type Run struct {
+	Tool struct {
+		Driver struct {
+			Name string `json:"name"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Result is a problem which is detected by the tool
+type Result struct {
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level,omitempty"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine,omitempty"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+// Finding is a normalized result of a static analysis tool
+type Finding struct {
+	Tool    string `json:"tool"`
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	// Fingerprint identifies the same finding across the PipelineRuns, it does not change when the line moves
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Parse parses the findings from a SARIF 2.1.0 log
+func Parse(data []byte) (findings []Finding, err error) {
+	log := &Log{}
+	if err = json.Unmarshal(data, log); err != nil {
+		return
+	}
+	if log.Version != "2.1.0" {
+		err = fmt.Errorf("unsupported SARIF version '%s', only 2.1.0 is supported", log.Version)
+		return
+	}
+	findings = []Finding{}
+	for _, run := range log.Runs {
+		for _, result := range run.Results {
+			finding := Finding{
+				Tool:    run.Tool.Driver.Name,
+				RuleID:  result.RuleID,
+				Level:   result.Level,
+				Message: result.Message.Text,
+			}
+			if finding.Level == "" {
+				finding.Level = LevelWarning
+			}
+			if len(result.Locations) > 0 {
+				location := result.Locations[0].PhysicalLocation
+				finding.File = strings.TrimPrefix(location.ArtifactLocation.URI, "file://")
+				finding.Line = location.Region.StartLine
+			}
+			finding.Fingerprint = getFingerprint(&finding, result.PartialFingerprints)
+			findings = append(findings, finding)
+		}
+	}
+	return
+}
+
+// getFingerprint returns the partial fingerprints provided by the tool, or the digest of the finding without the line
+func getFingerprint(finding *Finding, partialFingerprints map[string]string) string {
+	var keys []string
+	for key := range partialFingerprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := []string{finding.Tool, finding.RuleID}
+	if len(keys) > 0 {
+		for _, key := range keys {
+			items = append(items, key+"="+partialFingerprints[key])
+		}
+	} else {
+		items = append(items, finding.File, finding.Message)
+	}
+	digest := sha256.Sum256([]byte(strings.Join(items, "\n")))
+	return hex.EncodeToString(digest[:])
+}
+
+// Merge replaces the findings of the tools which are in the uploaded findings, so the reports of different
+// tools could be uploaded separately
+func Merge(existing, uploaded []Finding) (findings []Finding) {
+	tools := map[string]bool{}
+	for _, finding := range uploaded {
+		tools[finding.Tool] = true
+	}
+	findings = []Finding{}
+	for _, finding := range existing {
+		if !tools[finding.Tool] {
+			findings = append(findings, finding)
+		}
+	}
+	return append(findings, uploaded...)
+}
+
+// Report is the aggregated findings of a PipelineRun, compared with a base PipelineRun
+type Report struct {
+	PipelineRun string `json:"pipelineRun"`
+	// Base is the PipelineRun which the findings are compared with, all findings are new if it's empty
+	Base string `json:"base,omitempty"`
+	// Levels is the number of the findings of each level, such as error and warning
+	Levels   map[string]int  `json:"levels"`
+	Total    int             `json:"total"`
+	New      int             `json:"new"`
+	Existing int             `json:"existing"`
+	Fixed    int             `json:"fixed"`
+	Findings []ReportFinding `json:"findings"`
+}
+
+// ReportFinding is a finding in a report
+type ReportFinding struct {
+	Finding
+	// New is true if the finding does not exist in the base PipelineRun
+	New bool `json:"new"`
+}
+
+// Aggregate compares the findings with the base ones, the findings are sorted by the level, file and line
+func Aggregate(pipelineRun, base string, findings, baseFindings []Finding) *Report {
+	report := &Report{
+		PipelineRun: pipelineRun,
+		Base:        base,
+		Levels:      map[string]int{},
+		Total:       len(findings),
+		Findings:    []ReportFinding{},
+	}
+	baseFingerprints := map[string]bool{}
+	for _, finding := range baseFindings {
+		baseFingerprints[finding.Fingerprint] = true
+	}
+	fingerprints := map[string]bool{}
+	for _, finding := range findings {
+		fingerprints[finding.Fingerprint] = true
+		isNew := !baseFingerprints[finding.Fingerprint]
+		if isNew {
+			report.New++
+		} else {
+			report.Existing++
+		}
+		report.Levels[finding.Level]++
+		report.Findings = append(report.Findings, ReportFinding{Finding: finding, New: isNew})
+	}
+	for fingerprint := range baseFingerprints {
+		if !fingerprints[fingerprint] {
+			report.Fixed++
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if levelOrder(a.Level) != levelOrder(b.Level) {
+			return levelOrder(a.Level) < levelOrder(b.Level)
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+// levelOrder sorts the levels from the most severe one
+func levelOrder(level string) int {
+	switch level {
+	case "error":
+		return 0
+	case LevelWarning:
+		return 1
+	case "note":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// maxCommentFindings is the maximum number of the findings which are listed in a comment
+const maxCommentFindings = 50
+
+// Comment returns the summary of the new findings in Markdown, it's empty if there are no new findings
+func Comment(report *Report) string {
+	if report.New == 0 {
+		return ""
+	}
+	builder := &strings.Builder{}
+	_, _ = fmt.Fprintf(builder, "**Static analysis**: %d new findings, %d existing, %d fixed\n\n",
+		report.New, report.Existing, report.Fixed)
+	builder.WriteString("| Level | Tool | Rule | Location | Message |\n|---|---|---|---|---|\n")
+	count := 0
+	for _, finding := range report.Findings {
+		if !finding.New {
+			continue
+		}
+		if count++; count > maxCommentFindings {
+			_, _ = fmt.Fprintf(builder, "\nand %d more findings\n", report.New-maxCommentFindings)
+			break
+		}
+		location := finding.File
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+		}
+		_, _ = fmt.Fprintf(builder, "| %s | %s | %s | %s | %s |\n", finding.Level, finding.Tool, finding.RuleID,
+			location, escapeTableCell(finding.Message))
+	}
+	return builder.String()
+}
+
+func escapeTableCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ").Replace(text)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleLog = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "golangci-lint"}},
+    "results": [{
+      "ruleId": "errcheck",
+      "level": "error",
+      "message": {"text": "Error return value is not checked"},
+      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 10}}}]
+    }, {
+      "ruleId": "unused",
+      "message": {"text": "func foo is unused"},
+      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file://util.go"}, "region": {"startLine": 3}}}],
+      "partialFingerprints": {"primaryLocationLineHash": "abc"}
+    }]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	findings, err := Parse([]byte(sampleLog))
+	assert.Nil(t, err)
+	if assert.Len(t, findings, 2) {
+		assert.Equal(t, Finding{Tool: "golangci-lint", RuleID: "errcheck", Level: "error",
+			Message: "Error return value is not checked", File: "main.go", Line: 10,
+			Fingerprint: findings[0].Fingerprint}, findings[0])
+		assert.Equal(t, LevelWarning, findings[1].Level)
+		assert.Equal(t, "util.go", findings[1].File)
+		assert.NotEqual(t, findings[0].Fingerprint, findings[1].Fingerprint)
+	}
+
+	// the fingerprint does not change when the line moves
+	moved, err := Parse([]byte(strings.Replace(sampleLog, `"startLine": 10`, `"startLine": 12`, 1)))
+	assert.Nil(t, err)
+	assert.Equal(t, findings[0].Fingerprint, moved[0].Fingerprint)
+
+	_, err = Parse([]byte(`{"version": "1.0.0"}`))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte(`invalid`))
+	assert.NotNil(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	existing := []Finding{{Tool: "a", RuleID: "1"}, {Tool: "b", RuleID: "2"}}
+	uploaded := []Finding{{Tool: "b", RuleID: "3"}}
+	assert.Equal(t, []Finding{{Tool: "a", RuleID: "1"}, {Tool: "b", RuleID: "3"}}, Merge(existing, uploaded))
+	assert.Equal(t, []Finding{}, Merge(nil, nil))
+}
+
+func TestAggregate(t *testing.T) {
+	findings := []Finding{
+		{Level: "note", File: "a.go", Fingerprint: "1"},
+		{Level: "error", File: "b.go", Line: 2, Fingerprint: "2"},
+		{Level: "error", File: "b.go", Line: 1, Fingerprint: "3"},
+	}
+	baseFindings := []Finding{{Fingerprint: "1"}, {Fingerprint: "4"}}
+
+	report := Aggregate("pr-2", "pr-1", findings, baseFindings)
+	assert.Equal(t, "pr-1", report.Base)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.New)
+	assert.Equal(t, 1, report.Existing)
+	assert.Equal(t, 1, report.Fixed)
+	assert.Equal(t, map[string]int{"error": 2, "note": 1}, report.Levels)
+	var order []string
+	for _, finding := range report.Findings {
+		order = append(order, finding.Fingerprint)
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, order)
+	assert.False(t, report.Findings[2].New)
+
+	report = Aggregate("pr-1", "", nil, nil)
+	assert.Equal(t, 0, report.Total)
+	assert.Empty(t, Comment(report))
+}
+
+func TestComment(t *testing.T) {
+	var findings []Finding
+	for i := 0; i < maxCommentFindings+1; i++ {
+		findings = append(findings, Finding{Tool: "lint", RuleID: "rule", Level: "error", File: "main.go", Line: i + 1,
+			Message: "a | b", Fingerprint: string(rune('a' + i))})
+	}
+	comment := Comment(Aggregate("pr-2", "pr-1", findings, findings[1:]))
+	assert.Contains(t, comment, "1 new findings, 50 existing, 0 fixed")
+	assert.Contains(t, comment, "| error | lint | rule | main.go:1 | a \\| b |")
+	assert.NotContains(t, comment, "main.go:2 ")
+
+	comment = Comment(Aggregate("pr-2", "", findings, nil))
+	assert.Contains(t, comment, "and 1 more findings")
+}
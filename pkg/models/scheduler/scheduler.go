@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+const (
+	waitingReason   = "WaitingForExecutors"
+	capacityReason  = "WaitingForCapacity"
+	admittedReason  = "Admitted"
+	preemptedReason = "Preempted"
+
+	resourceReason    = "WaitingForResource"
+	quietPeriodReason = "InQuietPeriod"
+	blockedReason     = "Blocked"
+)
+
+// Decision is the result of scheduling a PipelineRun which is ready to be triggered
+type Decision struct {
+	// Admitted is true if the PipelineRun could be triggered now
+	Admitted bool
+	// Position is the 1-based position of the PipelineRun in the queue if it's not admitted
+	Position int
+	// Victim is the lower priority PipelineRun which should be taken out of the Jenkins queue before
+	// triggering the admitted PipelineRun
+	Victim *v1alpha3.PipelineRun
+}
+
+// Schedule decides if the PipelineRun could be triggered when there are at most capacity PipelineRuns in Jenkins.
+// The runs are all the PipelineRuns, the started ones take the capacity, and the queued ones are ordered by Less.
+// The head of the queue preempts the lowest priority PipelineRun which is accepted by the preemptible function
+// if there is no free capacity.
+func Schedule(run *v1alpha3.PipelineRun, runs []v1alpha3.PipelineRun, capacity int,
+	preemptible func(*v1alpha3.PipelineRun) bool) (decision Decision) {
+	if capacity <= 0 {
+		decision.Admitted = true
+		return
+	}
+
+	var active []*v1alpha3.PipelineRun
+	ahead := 0
+	for i := range runs {
+		item := &runs[i]
+		if item.Namespace == run.Namespace && item.Name == run.Name {
+			continue
+		}
+		if item.HasStarted() && !item.HasCompleted() {
+			active = append(active, item)
+		} else if IsQueued(item) && Less(item, run) {
+			ahead++
+		}
+	}
+
+	free := capacity - len(active)
+	if ahead < free {
+		decision.Admitted = true
+		return
+	}
+	decision.Position = ahead + 1
+	if ahead > 0 || free > 0 {
+		return
+	}
+
+	// preempt the lowest priority and the latest one
+	sort.SliceStable(active, func(i, j int) bool {
+		return Less(active[j], active[i])
+	})
+	for _, item := range active {
+		if item.Spec.Priority >= run.Spec.Priority {
+			break
+		}
+		if preemptible(item) {
+			decision.Admitted, decision.Position, decision.Victim = true, 0, item
+			break
+		}
+	}
+	return
+}
+
+// Less returns true if the PipelineRun a should be triggered before b.
+// The higher priority goes first, then the earlier created one.
+func Less(a, b *v1alpha3.PipelineRun) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// IsQueued returns true if the PipelineRun is waiting for the executors
+func IsQueued(run *v1alpha3.PipelineRun) bool {
+	if run.HasStarted() || run.HasCompleted() || run.IsStopRequested() {
+		return false
+	}
+	// the PipelineRun in the quiet period is not ready to be triggered yet
+	queued := getCondition(&run.Status, v1alpha3.ConditionQueued)
+	return queued != nil && queued.Status == v1alpha3.ConditionTrue && queued.Reason != quietPeriodReason
+}
+
+// Enqueue marks the PipelineRun as waiting for the executors, returns true if the status was changed
+func Enqueue(status *v1alpha3.PipelineRunStatus, position int, now time.Time) bool {
+	return setQueued(status, waitingReason,
+		fmt.Sprintf("waiting for the executors, position %d in the queue", position), metav1.NewTime(now))
+}
+
+// WaitForCapacity marks the PipelineRun as pending until the load of Jenkins is below the limit,
+// returns true if the status was changed
+func WaitForCapacity(status *v1alpha3.PipelineRunStatus, message string, now time.Time) bool {
+	return setQueued(status, capacityReason, message, metav1.NewTime(now))
+}
+
+// WaitForResource marks the PipelineRun as pending until it acquires the shared resources,
+// returns true if the status was changed
+func WaitForResource(status *v1alpha3.PipelineRunStatus, message string, now time.Time) bool {
+	return setQueued(status, resourceReason, message, metav1.NewTime(now))
+}
+
+// WaitForQuietPeriod marks the PipelineRun as pending until the end of the quiet period, in which the successive
+// triggers are coalesced. It returns true if the status was changed.
+func WaitForQuietPeriod(status *v1alpha3.PipelineRunStatus, end time.Time, now time.Time) bool {
+	return setQueued(status, quietPeriodReason,
+		fmt.Sprintf("waiting for the successive triggers until %s", end.UTC().Format(time.RFC3339)), metav1.NewTime(now))
+}
+
+// setQueued marks the PipelineRun as pending with the reason and message of the Queued condition,
+// returns false if the condition is not changed
+func setQueued(status *v1alpha3.PipelineRunStatus, reason, message string, now metav1.Time) bool {
+	if queued := getCondition(status, v1alpha3.ConditionQueued); queued != nil &&
+		queued.Status == v1alpha3.ConditionTrue && queued.Reason == reason && queued.Message == message {
+		return false
+	}
+	status.Phase = v1alpha3.Pending
+	status.UpdateTime = &now
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionQueued,
+		Status:        v1alpha3.ConditionTrue,
+		Reason:        reason,
+		Message:       message,
+		LastProbeTime: now,
+	})
+	return true
+}
+
+// WaitInJenkinsQueue marks the triggered PipelineRun as waiting in the Jenkins queue, the reason is decided by the
+// cause of blockage from Jenkins. It returns true if the status was changed.
+func WaitInJenkinsQueue(status *v1alpha3.PipelineRunStatus, causeOfBlockage string, now time.Time) bool {
+	reason := getJenkinsQueueReason(causeOfBlockage)
+	if causeOfBlockage == "" {
+		causeOfBlockage = "waiting in the Jenkins queue"
+	}
+	if queued := getCondition(status, v1alpha3.ConditionQueued); queued != nil &&
+		queued.Status == v1alpha3.ConditionTrue && queued.Reason == reason && queued.Message == causeOfBlockage {
+		return false
+	}
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionQueued,
+		Status:        v1alpha3.ConditionTrue,
+		Reason:        reason,
+		Message:       causeOfBlockage,
+		LastProbeTime: metav1.NewTime(now),
+	})
+	return true
+}
+
+// getJenkinsQueueReason converts the cause of blockage of Jenkins to a reason, for instance,
+// "Waiting for next available executor" or "Waiting for resources [db]" of the Lockable Resources plugin.
+func getJenkinsQueueReason(causeOfBlockage string) string {
+	cause := strings.ToLower(causeOfBlockage)
+	switch {
+	case strings.Contains(cause, "executor"), strings.Contains(cause, "offline"),
+		strings.Contains(cause, "no nodes"), strings.Contains(cause, "label"):
+		return waitingReason
+	case strings.Contains(cause, "resource"), strings.Contains(cause, "lock"):
+		return resourceReason
+	case strings.Contains(cause, "quiet period"):
+		return quietPeriodReason
+	}
+	return blockedReason
+}
+
+// Dequeue marks the Queued condition as false once the PipelineRun is triggered
+func Dequeue(status *v1alpha3.PipelineRunStatus, now time.Time) {
+	if queued := getCondition(status, v1alpha3.ConditionQueued); queued == nil || queued.Status != v1alpha3.ConditionTrue {
+		return
+	}
+	status.AddCondition(&v1alpha3.Condition{
+		Type:          v1alpha3.ConditionQueued,
+		Status:        v1alpha3.ConditionFalse,
+		Reason:        admittedReason,
+		Message:       "the executors are available",
+		LastProbeTime: metav1.NewTime(now),
+	})
+}
+
+// Preempt puts a triggered PipelineRun back to the queue, the build in Jenkins should be stopped by the caller
+func Preempt(victim *v1alpha3.PipelineRun, by *v1alpha3.PipelineRun, now time.Time) {
+	for _, key := range []string{
+		v1alpha3.JenkinsPipelineRunIDAnnoKey,
+		v1alpha3.JenkinsPipelineRunStatusAnnoKey,
+		v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey,
+		v1alpha3.JenkinsPipelineRunEventAnnoKey,
+	} {
+		delete(victim.Annotations, key)
+	}
+
+	metaNow := metav1.NewTime(now)
+	victim.Status.StartTime = nil
+	victim.Status.UpdateTime = &metaNow
+	victim.Status.Phase = v1alpha3.Pending
+	victim.Status.AddCondition(&v1alpha3.Condition{
+		Type:   v1alpha3.ConditionQueued,
+		Status: v1alpha3.ConditionTrue,
+		Reason: preemptedReason,
+		Message: fmt.Sprintf("preempted by %s/%s with priority %d",
+			by.Namespace, by.Name, by.Spec.Priority),
+		LastProbeTime: metaNow,
+	})
+}
+
+func getCondition(status *v1alpha3.PipelineRunStatus, conditionType v1alpha3.ConditionType) *v1alpha3.Condition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
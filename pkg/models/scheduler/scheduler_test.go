@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Now()
+	newRun := func(name string, priority int32, created time.Duration, started, queued bool) v1alpha3.PipelineRun {
+		run := v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "ns",
+				CreationTimestamp: metav1.NewTime(now.Add(created)),
+				Annotations:       map[string]string{},
+			},
+			Spec: v1alpha3.PipelineRunSpec{Priority: priority},
+		}
+		if started {
+			run.Annotations[v1alpha3.JenkinsPipelineRunIDAnnoKey] = "1"
+		}
+		if queued {
+			Enqueue(&run.Status, 1, now)
+		}
+		return run
+	}
+	never := func(*v1alpha3.PipelineRun) bool { return false }
+	always := func(*v1alpha3.PipelineRun) bool { return true }
+
+	tests := []struct {
+		name        string
+		run         v1alpha3.PipelineRun
+		runs        []v1alpha3.PipelineRun
+		capacity    int
+		preemptible func(*v1alpha3.PipelineRun) bool
+		expect      Decision
+		victim      string
+	}{{
+		name:     "unlimited capacity",
+		run:      newRun("a", 0, 0, false, false),
+		runs:     []v1alpha3.PipelineRun{newRun("b", 0, 0, true, false)},
+		capacity: 0,
+		expect:   Decision{Admitted: true},
+	}, {
+		name:     "free capacity",
+		run:      newRun("a", 0, 0, false, false),
+		runs:     []v1alpha3.PipelineRun{newRun("b", 0, 0, true, false)},
+		capacity: 2,
+		expect:   Decision{Admitted: true},
+	}, {
+		name: "the completed PipelineRuns do not take the capacity",
+		run:  newRun("a", 0, 0, false, false),
+		runs: func() []v1alpha3.PipelineRun {
+			completed := newRun("b", 0, 0, true, false)
+			completed.Status.CompletionTime = &metav1.Time{Time: now}
+			return []v1alpha3.PipelineRun{completed}
+		}(),
+		capacity: 1,
+		expect:   Decision{Admitted: true},
+	}, {
+		name: "wait for the higher priority PipelineRuns",
+		run:  newRun("a", 1, 0, false, true),
+		runs: []v1alpha3.PipelineRun{
+			newRun("a", 1, 0, false, true),
+			newRun("b", 2, time.Second, false, true),
+			newRun("c", 1, -time.Second, false, true),
+			newRun("d", 1, time.Second, false, true),
+			newRun("e", 0, -time.Second, false, true),
+		},
+		capacity:    1,
+		preemptible: always,
+		expect:      Decision{Position: 3},
+	}, {
+		name: "the head of queue is admitted",
+		run:  newRun("a", 1, 0, false, true),
+		runs: []v1alpha3.PipelineRun{
+			newRun("b", 0, 0, true, false),
+			newRun("c", 0, -time.Second, false, true),
+		},
+		capacity: 2,
+		expect:   Decision{Admitted: true},
+	}, {
+		name: "no capacity and nothing to preempt",
+		run:  newRun("a", 1, 0, false, false),
+		runs: []v1alpha3.PipelineRun{
+			newRun("b", 0, 0, true, false),
+		},
+		capacity:    1,
+		preemptible: never,
+		expect:      Decision{Position: 1},
+	}, {
+		name: "the same or higher priority ones are not preempted",
+		run:  newRun("a", 1, 0, false, false),
+		runs: []v1alpha3.PipelineRun{
+			newRun("b", 1, 0, true, false),
+			newRun("c", 2, 0, true, false),
+		},
+		capacity:    2,
+		preemptible: always,
+		expect:      Decision{Position: 1},
+	}, {
+		name: "preempt the lowest priority and latest one",
+		run:  newRun("a", 2, 0, false, true),
+		runs: []v1alpha3.PipelineRun{
+			newRun("b", 1, -time.Minute, true, false),
+			newRun("c", 0, -time.Minute, true, false),
+			newRun("d", 0, -time.Second, true, false),
+			newRun("e", 0, 0, true, false),
+		},
+		capacity: 4,
+		preemptible: func(run *v1alpha3.PipelineRun) bool {
+			// e is running
+			return run.Name != "e"
+		},
+		expect: Decision{Admitted: true},
+		victim: "d",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := Schedule(&tt.run, tt.runs, tt.capacity, tt.preemptible)
+			if tt.victim == "" {
+				assert.Equal(t, tt.expect, decision)
+			} else if assert.NotNil(t, decision.Victim) {
+				assert.Equal(t, tt.victim, decision.Victim.Name)
+				assert.True(t, decision.Admitted)
+			}
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	now := time.Now()
+	run := &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns"}}
+	assert.False(t, IsQueued(run))
+
+	assert.True(t, Enqueue(&run.Status, 2, now))
+	assert.False(t, Enqueue(&run.Status, 2, now))
+	assert.True(t, IsQueued(run))
+	assert.Equal(t, v1alpha3.Pending, run.Status.Phase)
+	assert.True(t, Enqueue(&run.Status, 1, now))
+
+	assert.True(t, WaitForCapacity(&run.Status, "the queue of Jenkins is full", now))
+	assert.False(t, WaitForCapacity(&run.Status, "the queue of Jenkins is full", now))
+	assert.Equal(t, capacityReason, getCondition(&run.Status, v1alpha3.ConditionQueued).Reason)
+	assert.True(t, IsQueued(run))
+
+	Dequeue(&run.Status, now)
+	assert.False(t, IsQueued(run))
+
+	// put a triggered PipelineRun back to the queue
+	run.Annotations = map[string]string{
+		v1alpha3.JenkinsPipelineRunIDAnnoKey:     "1",
+		v1alpha3.JenkinsPipelineRunStatusAnnoKey: "{}",
+	}
+	run.Status.StartTime = &metav1.Time{Time: now}
+	Preempt(run, &v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns"},
+		Spec: v1alpha3.PipelineRunSpec{Priority: 1}}, now)
+	assert.Empty(t, run.Annotations)
+	assert.Nil(t, run.Status.StartTime)
+	assert.True(t, IsQueued(run))
+	assert.Equal(t, "preempted by ns/b with priority 1", getCondition(&run.Status, v1alpha3.ConditionQueued).Message)
+}
+
+func TestWaitInJenkinsQueue(t *testing.T) {
+	now := time.Now()
+	status := &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Running}
+
+	assert.True(t, WaitInJenkinsQueue(status, "Waiting for next available executor on ‘linux’", now))
+	assert.False(t, WaitInJenkinsQueue(status, "Waiting for next available executor on ‘linux’", now))
+	queued := getCondition(status, v1alpha3.ConditionQueued)
+	assert.Equal(t, v1alpha3.ConditionTrue, queued.Status)
+	assert.Equal(t, waitingReason, queued.Reason)
+	assert.Equal(t, "Waiting for next available executor on ‘linux’", queued.Message)
+	// the phase is decided by the Jenkins build
+	assert.Equal(t, v1alpha3.Running, status.Phase)
+
+	assert.True(t, WaitInJenkinsQueue(status, "", now))
+	assert.Equal(t, "waiting in the Jenkins queue", getCondition(status, v1alpha3.ConditionQueued).Message)
+
+	Dequeue(status, now)
+	assert.Equal(t, v1alpha3.ConditionFalse, getCondition(status, v1alpha3.ConditionQueued).Status)
+}
+
+func Test_getJenkinsQueueReason(t *testing.T) {
+	tests := map[string]string{
+		"Waiting for next available executor":          waitingReason,
+		"There are no nodes with the label ‘windows’":  waitingReason,
+		"‘agent-1’ is offline":                         waitingReason,
+		"Waiting for resources [database]":             resourceReason,
+		"In the quiet period. Expires in 4.9 sec":      quietPeriodReason,
+		"Build #3 is already in progress (ETA: 1 min)": blockedReason,
+		"": blockedReason,
+	}
+	for cause, reason := range tests {
+		assert.Equal(t, reason, getJenkinsQueueReason(cause), cause)
+	}
+}
+
+func TestWaitForResource(t *testing.T) {
+	now := time.Now()
+	status := &v1alpha3.PipelineRunStatus{}
+
+	assert.True(t, WaitForResource(status, "waiting for SharedResource staging", now))
+	assert.False(t, WaitForResource(status, "waiting for SharedResource staging", now))
+	queued := getCondition(status, v1alpha3.ConditionQueued)
+	assert.Equal(t, resourceReason, queued.Reason)
+	assert.Equal(t, v1alpha3.Pending, status.Phase)
+}
+
+func TestWaitForQuietPeriod(t *testing.T) {
+	now := time.Now()
+	status := &v1alpha3.PipelineRunStatus{}
+
+	assert.True(t, WaitForQuietPeriod(status, now.Add(time.Minute), now))
+	assert.False(t, WaitForQuietPeriod(status, now.Add(time.Minute), now))
+	queued := getCondition(status, v1alpha3.ConditionQueued)
+	assert.Equal(t, quietPeriodReason, queued.Reason)
+	assert.Equal(t, v1alpha3.Pending, status.Phase)
+	assert.False(t, IsQueued(&v1alpha3.PipelineRun{Status: *status}))
+}
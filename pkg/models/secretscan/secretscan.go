@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretscan
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/logmask"
+)
+
+const (
+	// secretGroup is the name of the sub-match which is the secret, the whole match is the secret if it does not exist
+	secretGroup = "secret"
+	// redactedPrefixLength is the number of the leading characters which are kept in a redacted secret
+	redactedPrefixLength = 4
+)
+
+// Rule detects a kind of secrets
+type Rule struct {
+	ID      string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules are the rules of well-known secrets
+var DefaultRules = []Rule{
+	{ID: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{ID: "aws-secret-access-key", Pattern: regexp.MustCompile(
+		`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`)},
+	{ID: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{ID: "gitlab-token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{ID: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{ID: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+	{ID: "private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// hunkHeader is the header of a hunk in a unified diff, such as @@ -1,2 +3,4 @@
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Scanner detects the secrets in the changes of a commit
+type Scanner struct {
+	Rules []Rule
+	// ExcludePaths are the glob patterns of the files which are not scanned
+	ExcludePaths []string
+}
+
+// NewScanner creates a Scanner with the default rules
+func NewScanner(excludePaths []string) *Scanner {
+	return &Scanner{Rules: DefaultRules, ExcludePaths: excludePaths}
+}
+
+// Scan returns the secrets in the added lines of the changes, the secrets are redacted
+func (s *Scanner) Scan(changes []*scm.Change) (findings []v1alpha3.SecretFinding) {
+	for _, change := range changes {
+		if change == nil || change.Deleted || s.isExcluded(change.Path) {
+			continue
+		}
+		findings = append(findings, s.scanPatch(change.Path, change.Patch)...)
+	}
+	return
+}
+
+// scanPatch scans the added lines of a unified diff, the line numbers are the ones in the new file
+func (s *Scanner) scanPatch(file, patch string) (findings []v1alpha3.SecretFinding) {
+	var line int32
+	for _, text := range strings.Split(patch, "\n") {
+		if matches := hunkHeader.FindStringSubmatch(text); matches != nil {
+			start, _ := strconv.ParseInt(matches[1], 10, 32)
+			line = int32(start)
+			continue
+		}
+		switch {
+		case line == 0, strings.HasPrefix(text, "-"), strings.HasPrefix(text, "\\"):
+			// the file headers before the first hunk, removed lines and "\ No newline at end of file"
+			// are not in the new file
+			continue
+		case strings.HasPrefix(text, "+"):
+			for _, rule := range s.Rules {
+				for _, secret := range findSecrets(rule.Pattern, text[1:]) {
+					findings = append(findings, v1alpha3.SecretFinding{
+						Rule:   rule.ID,
+						File:   file,
+						Line:   line,
+						Secret: Redact(secret),
+					})
+				}
+			}
+		}
+		line++
+	}
+	return
+}
+
+// isExcluded checks if a file matches any of the excluded paths, a pattern without a slash matches the file name
+func (s *Scanner) isExcluded(file string) bool {
+	for _, pattern := range s.ExcludePaths {
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(file)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findSecrets returns the sub-matches named secret, or the whole matches of the pattern
+func findSecrets(pattern *regexp.Regexp, text string) (secrets []string) {
+	index := pattern.SubexpIndex(secretGroup)
+	for _, matches := range pattern.FindAllStringSubmatch(text, -1) {
+		if index >= 0 {
+			secrets = append(secrets, matches[index])
+		} else {
+			secrets = append(secrets, matches[0])
+		}
+	}
+	return
+}
+
+// Redact keeps the first few characters of a secret and masks the rest
+func Redact(secret string) string {
+	if len(secret) <= redactedPrefixLength*2 {
+		return logmask.Mask
+	}
+	return secret[:redactedPrefixLength] + logmask.Mask
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedresource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/scheduler"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Claims are the PipelineRuns of a namespace grouped by the SharedResources they ask for
+type Claims struct {
+	runs     map[string]*v1alpha3.PipelineRun
+	requests map[string][]*v1alpha3.PipelineRun
+}
+
+// NewClaims collects the PipelineRuns which ask for SharedResources in the namespace
+func NewClaims(ctx context.Context, c client.Reader, namespace string) (claims *Claims, err error) {
+	runList := &v1alpha3.PipelineRunList{}
+	if err = c.List(ctx, runList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	pipelineList := &v1alpha3.PipelineList{}
+	if err = c.List(ctx, pipelineList, client.InNamespace(namespace)); err != nil {
+		return
+	}
+	pipelines := make(map[string]*v1alpha3.Pipeline, len(pipelineList.Items))
+	for i := range pipelineList.Items {
+		pipelines[pipelineList.Items[i].Name] = &pipelineList.Items[i]
+	}
+
+	claims = &Claims{
+		runs:     make(map[string]*v1alpha3.PipelineRun, len(runList.Items)),
+		requests: make(map[string][]*v1alpha3.PipelineRun),
+	}
+	for i := range runList.Items {
+		run := &runList.Items[i]
+		claims.runs[run.Name] = run
+		var pipeline *v1alpha3.Pipeline
+		if run.Spec.PipelineRef != nil {
+			pipeline = pipelines[run.Spec.PipelineRef.Name]
+		}
+		for _, name := range run.GetSharedResources(pipeline) {
+			claims.requests[name] = append(claims.requests[name], run)
+		}
+	}
+	return
+}
+
+// Refresh releases the resource from the completed or deleted PipelineRuns, and rebuilds the wait queue with the
+// pending PipelineRuns in the order of scheduling. It returns true if the status was changed.
+func (c *Claims) Refresh(resource *v1alpha3.SharedResource, now time.Time) bool {
+	status := &resource.Status
+	holding := make(map[string]bool, len(status.Holders))
+	holders := make([]v1alpha3.SharedResourceClaim, 0, len(status.Holders))
+	for _, holder := range status.Holders {
+		if run, ok := c.runs[holder.PipelineRun]; ok && !run.HasCompleted() && run.DeletionTimestamp.IsZero() {
+			holders = append(holders, holder)
+			holding[holder.PipelineRun] = true
+		}
+	}
+
+	waitingSince := make(map[string]metav1.Time, len(status.Waiting))
+	for _, waiting := range status.Waiting {
+		waitingSince[waiting.PipelineRun] = waiting.Since
+	}
+	var pending []*v1alpha3.PipelineRun
+	for _, run := range c.requests[resource.Name] {
+		if !holding[run.Name] && !run.HasStarted() && !run.HasCompleted() && !run.IsStopRequested() &&
+			run.DeletionTimestamp.IsZero() {
+			pending = append(pending, run)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return scheduler.Less(pending[i], pending[j])
+	})
+	waiting := make([]v1alpha3.SharedResourceClaim, 0, len(pending))
+	for _, run := range pending {
+		since, ok := waitingSince[run.Name]
+		if !ok {
+			since = metav1.NewTime(now)
+		}
+		waiting = append(waiting, v1alpha3.SharedResourceClaim{PipelineRun: run.Name, Since: since})
+	}
+
+	changed := !equalClaims(status.Holders, holders) || !equalClaims(status.Waiting, waiting)
+	status.Holders, status.Waiting = holders, waiting
+	return changed
+}
+
+// acquire locks the resource for the PipelineRun if it's the head of the wait queue and the resource is not full.
+// The status must be refreshed before.
+func acquire(resource *v1alpha3.SharedResource, run string, now time.Time) (acquired bool, position int) {
+	status := &resource.Status
+	for _, holder := range status.Holders {
+		if holder.PipelineRun == run {
+			return true, 0
+		}
+	}
+	for i, waiting := range status.Waiting {
+		if waiting.PipelineRun == run {
+			position = i + 1
+			break
+		}
+	}
+	if position == 0 || len(status.Holders)+position > resource.Spec.GetCapacity() {
+		return
+	}
+	status.Waiting = append(status.Waiting[:position-1:position-1], status.Waiting[position:]...)
+	status.Holders = append(status.Holders, v1alpha3.SharedResourceClaim{PipelineRun: run, Since: metav1.NewTime(now)})
+	return true, 0
+}
+
+// Acquire locks all the SharedResources for a pending PipelineRun. The PipelineRun either holds all of them or none
+// of them, it returns a message which tells what the PipelineRun is waiting for if it failed to acquire them.
+func Acquire(ctx context.Context, c client.Client, run *v1alpha3.PipelineRun, names []string,
+	now time.Time) (acquired bool, message string, err error) {
+	names = uniqueNames(names)
+	var locked []string
+	defer func() {
+		if !acquired && len(locked) > 0 {
+			if releaseErr := Release(ctx, c, run, locked); releaseErr != nil && err == nil {
+				err = releaseErr
+			}
+		}
+	}()
+
+	for _, name := range names {
+		var ok bool
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			resource := &v1alpha3.SharedResource{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: run.Namespace, Name: name}, resource); err != nil {
+				return err
+			}
+			claims, err := NewClaims(ctx, c, run.Namespace)
+			if err != nil {
+				return err
+			}
+			changed := claims.Refresh(resource, now)
+			var position int
+			if ok, position = acquire(resource, run.Name, now); ok {
+				changed = true
+			} else {
+				message = getWaitingMessage(resource, position)
+			}
+			if !changed {
+				return nil
+			}
+			return c.Status().Update(ctx, resource)
+		})
+		if apierrors.IsNotFound(err) {
+			// the resource might be created later
+			return false, fmt.Sprintf("waiting for SharedResource %s which is not found", name), nil
+		} else if err != nil || !ok {
+			return
+		}
+		locked = append(locked, name)
+	}
+	acquired = true
+	return
+}
+
+// Release unlocks the SharedResources which are held by the PipelineRun
+func Release(ctx context.Context, c client.Client, run *v1alpha3.PipelineRun, names []string) error {
+	for _, name := range uniqueNames(names) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			resource := &v1alpha3.SharedResource{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: run.Namespace, Name: name}, resource); err != nil {
+				return err
+			}
+			holders := make([]v1alpha3.SharedResourceClaim, 0, len(resource.Status.Holders))
+			for _, holder := range resource.Status.Holders {
+				if holder.PipelineRun != run.Name {
+					holders = append(holders, holder)
+				}
+			}
+			if len(holders) == len(resource.Status.Holders) {
+				return nil
+			}
+			resource.Status.Holders = holders
+			return c.Status().Update(ctx, resource)
+		})
+		if err = client.IgnoreNotFound(err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getWaitingMessage(resource *v1alpha3.SharedResource, position int) string {
+	holders := make([]string, 0, len(resource.Status.Holders))
+	for _, holder := range resource.Status.Holders {
+		holders = append(holders, holder.PipelineRun)
+	}
+	return fmt.Sprintf("waiting for SharedResource %s held by [%s], position %d in the queue",
+		resource.Name, strings.Join(holders, ", "), position)
+}
+
+func uniqueNames(names []string) []string {
+	unique := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	// lock the resources in the same order to avoid starving each other
+	sort.Strings(unique)
+	return unique
+}
+
+func equalClaims(a, b []v1alpha3.SharedResourceClaim) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].PipelineRun != b[i].PipelineRun || !a[i].Since.Equal(&b[i].Since) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedresource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPipelineRun(name string, created time.Time, priority int32, resources ...string) *v1alpha3.PipelineRun {
+	return &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef:  &v1.ObjectReference{Name: "pipeline"},
+			PipelineSpec: &v1alpha3.PipelineSpec{SharedResources: resources},
+			Priority:     priority,
+		},
+	}
+}
+
+func TestAcquireAndRelease(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	first := newPipelineRun("first", now, 0, "staging")
+	second := newPipelineRun("second", now.Add(time.Second), 0, "staging", "license")
+	urgent := newPipelineRun("urgent", now.Add(2*time.Second), 1, "staging")
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(first, second, urgent,
+		&v1alpha3.SharedResource{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "staging"}},
+		&v1alpha3.SharedResource{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "license"}}).Build()
+	ctx := context.Background()
+	getResource := func(name string) *v1alpha3.SharedResource {
+		resource := &v1alpha3.SharedResource{}
+		assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: name}, resource))
+		return resource
+	}
+
+	// the higher priority goes first
+	acquired, message, err := Acquire(ctx, c, first, first.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "waiting for SharedResource staging held by [], position 2 in the queue", message)
+
+	acquired, _, err = Acquire(ctx, c, urgent, urgent.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	// acquiring again is fine
+	acquired, _, err = Acquire(ctx, c, urgent, urgent.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+
+	staging := getResource("staging")
+	assert.Equal(t, "urgent", staging.Status.Holders[0].PipelineRun)
+	assert.Equal(t, []string{"first", "second"}, []string{staging.Status.Waiting[0].PipelineRun,
+		staging.Status.Waiting[1].PipelineRun})
+
+	acquired, message, err = Acquire(ctx, c, first, first.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "waiting for SharedResource staging held by [urgent], position 1 in the queue", message)
+
+	// the completed PipelineRun releases the resource
+	completionTime := metav1.NewTime(now)
+	urgent.Status.CompletionTime = &completionTime
+	assert.Nil(t, c.Update(ctx, urgent))
+	acquired, _, err = Acquire(ctx, c, first, first.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+
+	// all or nothing, the license is released since the staging is not available
+	acquired, _, err = Acquire(ctx, c, second, second.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Empty(t, getResource("license").Status.Holders)
+
+	first.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}
+	assert.Nil(t, c.Update(ctx, first))
+	assert.Nil(t, Release(ctx, c, first, []string{"staging", "missing"}))
+	assert.Empty(t, getResource("staging").Status.Holders)
+	acquired, _, err = Acquire(ctx, c, second, second.Spec.PipelineSpec.SharedResources, now)
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "second", getResource("license").Status.Holders[0].PipelineRun)
+
+	// the resource is not found
+	acquired, message, err = Acquire(ctx, c, second, []string{"missing"}, now)
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "waiting for SharedResource missing which is not found", message)
+}
+
+func TestRefresh(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	since := metav1.NewTime(now.Add(-time.Minute))
+	running := newPipelineRun("running", now, 0)
+	running.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunIDAnnoKey: "1"}
+	pending := newPipelineRun("pending", now, 0)
+	pending.Spec.PipelineSpec = nil
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(running, pending, &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+		Spec:       v1alpha3.PipelineSpec{SharedResources: []string{"staging"}},
+	}).Build()
+
+	claims, err := NewClaims(context.Background(), c, "ns")
+	assert.Nil(t, err)
+	resource := &v1alpha3.SharedResource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "staging"},
+		Status: v1alpha3.SharedResourceStatus{
+			Holders: []v1alpha3.SharedResourceClaim{{PipelineRun: "deleted", Since: since}, {PipelineRun: "running", Since: since}},
+			Waiting: []v1alpha3.SharedResourceClaim{{PipelineRun: "pending", Since: since}},
+		},
+	}
+	assert.True(t, claims.Refresh(resource, now))
+	assert.Equal(t, []v1alpha3.SharedResourceClaim{{PipelineRun: "running", Since: since}}, resource.Status.Holders)
+	assert.Equal(t, []v1alpha3.SharedResourceClaim{{PipelineRun: "pending", Since: since}}, resource.Status.Waiting)
+	assert.False(t, claims.Refresh(resource, now))
+}
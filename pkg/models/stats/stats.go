@@ -0,0 +1,370 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/models/pipelinerun"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// DefaultRetention is the longest window of the statistics
+const DefaultRetention = 30 * 24 * time.Hour
+
+// DefaultWindow is the window of the statistics if it's not specified
+const DefaultWindow = 7 * 24 * time.Hour
+
+// bucketSize is the granularity of the statistics
+const bucketSize = time.Hour
+
+// Counts are the numbers of completed PipelineRuns
+type Counts struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	// SuccessRate is the ratio of succeeded ones to the succeeded and failed ones, the cancelled ones are excluded
+	SuccessRate float64 `json:"successRate"`
+	// AverageDurationInMillis is the average duration of the PipelineRuns which have the start time
+	AverageDurationInMillis int64 `json:"averageDurationInMillis"`
+
+	durationInMillis int64
+	durations        int
+}
+
+// PipelineStats are the statistics of a Pipeline
+type PipelineStats struct {
+	Pipeline string `json:"pipeline"`
+	Counts   `json:",inline"`
+}
+
+// StageFailures is the number of failures of a stage
+type StageFailures struct {
+	Pipeline string `json:"pipeline"`
+	Stage    string `json:"stage"`
+	Failed   int    `json:"failed"`
+}
+
+// Point is the statistics of a period in the trend
+type Point struct {
+	Start  time.Time `json:"start"`
+	Total  int       `json:"total"`
+	Failed int       `json:"failed"`
+}
+
+// Summary are the statistics of a DevOps project in a window
+type Summary struct {
+	Namespace string    `json:"namespace"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Counts    `json:",inline"`
+	// BusiestPipelines are the Pipelines with the most PipelineRuns
+	BusiestPipelines []PipelineStats `json:"busiestPipelines"`
+	// FailingPipelines are the Pipelines with the most failures
+	FailingPipelines []PipelineStats `json:"failingPipelines"`
+	// FailingStages are the stages with the most failures
+	FailingStages []StageFailures `json:"failingStages"`
+	// Trend is the numbers of PipelineRuns in each hour if the window is not longer than two days, otherwise in each day
+	Trend []Point `json:"trend"`
+}
+
+type bucket struct {
+	counts       Counts
+	failedStages map[string]int
+}
+
+// Collector counts the completed PipelineRuns incrementally, each PipelineRun is counted once when it completes
+type Collector struct {
+	lock      sync.RWMutex
+	retention time.Duration
+	now       func() time.Time
+	// buckets are indexed by the namespace, the Pipeline, then the start of an hour
+	buckets map[string]map[string]map[int64]*bucket
+	// observed are the completion time of the counted PipelineRuns
+	observed map[types.NamespacedName]time.Time
+}
+
+// NewCollector creates a collector which keeps the statistics in the retention
+func NewCollector(retention time.Duration) *Collector {
+	return &Collector{
+		retention: retention,
+		now:       time.Now,
+		buckets:   make(map[string]map[string]map[int64]*bucket),
+		observed:  make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Watch feeds the collector with the PipelineRuns in the cache, it must be called before the cache starts
+func (c *Collector) Watch(ctx context.Context, informers runtimecache.Informers) error {
+	informer, err := informers.GetInformer(ctx, &v1alpha3.PipelineRun{})
+	if err != nil {
+		return err
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pr, ok := obj.(*v1alpha3.PipelineRun); ok {
+				c.Observe(pr)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			if pr, ok := newObj.(*v1alpha3.PipelineRun); ok {
+				c.Observe(pr)
+			}
+		},
+	})
+	return nil
+}
+
+// Observe counts the PipelineRun if it has completed and was not counted
+func (c *Collector) Observe(pr *v1alpha3.PipelineRun) {
+	if !pr.HasCompleted() {
+		return
+	}
+	completionTime := pr.Status.CompletionTime.Time
+	key := types.NamespacedName{Namespace: pr.Namespace, Name: pr.Name}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.observed[key]; ok || c.now().Sub(completionTime) > c.retention {
+		return
+	}
+	c.observed[key] = completionTime
+
+	pipelines, ok := c.buckets[pr.Namespace]
+	if !ok {
+		pipelines = make(map[string]map[int64]*bucket)
+		c.buckets[pr.Namespace] = pipelines
+	}
+	pipelineName := getPipelineName(pr)
+	buckets, ok := pipelines[pipelineName]
+	if !ok {
+		buckets = make(map[int64]*bucket)
+		pipelines[pipelineName] = buckets
+	}
+	start := completionTime.Truncate(bucketSize).Unix()
+	b, ok := buckets[start]
+	if !ok {
+		b = &bucket{failedStages: map[string]int{}}
+		buckets[start] = b
+	}
+
+	b.counts.Total++
+	switch pr.Status.Phase {
+	case v1alpha3.Succeeded:
+		b.counts.Succeeded++
+	case v1alpha3.Failed:
+		b.counts.Failed++
+		for _, stage := range getFailedStages(pr) {
+			b.failedStages[stage]++
+		}
+	case v1alpha3.Cancelled:
+		b.counts.Cancelled++
+	}
+	if pr.Status.StartTime != nil {
+		b.counts.durationInMillis += completionTime.Sub(pr.Status.StartTime.Time).Milliseconds()
+		b.counts.durations++
+	}
+}
+
+// Summarize returns the statistics of a namespace in the window before now, at most limit Pipelines or stages are
+// listed in the rankings
+func (c *Collector) Summarize(namespace string, window time.Duration, limit int) (summary *Summary) {
+	now := c.now()
+	c.prune(now)
+
+	end := now.Truncate(bucketSize).Add(bucketSize)
+	summary = &Summary{Namespace: namespace, Start: now.Add(-window), End: now}
+	pointSize := bucketSize
+	if window > 48*time.Hour {
+		pointSize = 24 * time.Hour
+	}
+	points := map[int64]*Point{}
+	for start := summary.Start.Truncate(pointSize); start.Before(end); start = start.Add(pointSize) {
+		point := &Point{Start: start.UTC()}
+		points[start.Unix()] = point
+		summary.Trend = append(summary.Trend, *point)
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var pipelineStats []PipelineStats
+	var stageFailures []StageFailures
+	for pipelineName, buckets := range c.buckets[namespace] {
+		stats := PipelineStats{Pipeline: pipelineName}
+		failedStages := map[string]int{}
+		for start, b := range buckets {
+			if time.Unix(start, 0).Add(bucketSize).Before(summary.Start) {
+				continue
+			}
+			stats.add(&b.counts)
+			for stage, failed := range b.failedStages {
+				failedStages[stage] += failed
+			}
+			if point, ok := points[time.Unix(start, 0).Truncate(pointSize).Unix()]; ok {
+				point.Total += b.counts.Total
+				point.Failed += b.counts.Failed
+			}
+		}
+		if stats.Total == 0 {
+			continue
+		}
+		stats.complete()
+		summary.add(&stats.Counts)
+		pipelineStats = append(pipelineStats, stats)
+		for stage, failed := range failedStages {
+			stageFailures = append(stageFailures, StageFailures{Pipeline: pipelineName, Stage: stage, Failed: failed})
+		}
+	}
+	summary.complete()
+	for i := range summary.Trend {
+		summary.Trend[i] = *points[summary.Trend[i].Start.Unix()]
+	}
+
+	summary.BusiestPipelines = rankPipelines(pipelineStats, limit, func(stats *PipelineStats) int { return stats.Total })
+	summary.FailingPipelines = rankPipelines(pipelineStats, limit, func(stats *PipelineStats) int { return stats.Failed })
+	sort.Slice(stageFailures, func(i, j int) bool {
+		if stageFailures[i].Failed != stageFailures[j].Failed {
+			return stageFailures[i].Failed > stageFailures[j].Failed
+		}
+		if stageFailures[i].Pipeline != stageFailures[j].Pipeline {
+			return stageFailures[i].Pipeline < stageFailures[j].Pipeline
+		}
+		return stageFailures[i].Stage < stageFailures[j].Stage
+	})
+	if len(stageFailures) > limit {
+		stageFailures = stageFailures[:limit]
+	}
+	summary.FailingStages = append([]StageFailures{}, stageFailures...)
+	return
+}
+
+// prune removes the statistics out of the retention
+func (c *Collector) prune(now time.Time) {
+	expired := now.Add(-c.retention)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, completionTime := range c.observed {
+		if completionTime.Before(expired) {
+			delete(c.observed, key)
+		}
+	}
+	for namespace, pipelines := range c.buckets {
+		for pipelineName, buckets := range pipelines {
+			for start := range buckets {
+				if time.Unix(start, 0).Add(bucketSize).Before(expired) {
+					delete(buckets, start)
+				}
+			}
+			if len(buckets) == 0 {
+				delete(pipelines, pipelineName)
+			}
+		}
+		if len(pipelines) == 0 {
+			delete(c.buckets, namespace)
+		}
+	}
+}
+
+func (counts *Counts) add(other *Counts) {
+	counts.Total += other.Total
+	counts.Succeeded += other.Succeeded
+	counts.Failed += other.Failed
+	counts.Cancelled += other.Cancelled
+	counts.durationInMillis += other.durationInMillis
+	counts.durations += other.durations
+}
+
+func (counts *Counts) complete() {
+	if finished := counts.Succeeded + counts.Failed; finished > 0 {
+		counts.SuccessRate = float64(counts.Succeeded) / float64(finished)
+	}
+	if counts.durations > 0 {
+		counts.AverageDurationInMillis = counts.durationInMillis / int64(counts.durations)
+	}
+}
+
+func rankPipelines(pipelineStats []PipelineStats, limit int, value func(*PipelineStats) int) []PipelineStats {
+	ranked := make([]PipelineStats, 0, len(pipelineStats))
+	for i := range pipelineStats {
+		if value(&pipelineStats[i]) > 0 {
+			ranked = append(ranked, pipelineStats[i])
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if value(&ranked[i]) != value(&ranked[j]) {
+			return value(&ranked[i]) > value(&ranked[j])
+		}
+		return ranked[i].Pipeline < ranked[j].Pipeline
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+func getPipelineName(pr *v1alpha3.PipelineRun) string {
+	if name := pr.Labels[v1alpha3.PipelineNameLabelKey]; name != "" {
+		return name
+	}
+	if pr.Spec.PipelineRef != nil {
+		return pr.Spec.PipelineRef.Name
+	}
+	return ""
+}
+
+// getFailedStages returns the display names of the failed stages of a PipelineRun
+func getFailedStages(pr *v1alpha3.PipelineRun) (stages []string) {
+	var nodes []pipelinerun.NodeDetail
+	// the stages are not available if the PipelineRun failed before starting
+	_ = json.Unmarshal([]byte(pr.Annotations[v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey]), &nodes)
+	for _, node := range nodes {
+		if node.Result == "FAILURE" {
+			stages = append(stages, node.DisplayName)
+		}
+	}
+	return
+}
+
+// ParseWindow parses a window like 24h or 7d, the window is not allowed to be longer than the retention
+func ParseWindow(window string, retention time.Duration) (duration time.Duration, err error) {
+	if window == "" {
+		duration = DefaultWindow
+	} else if days := strings.TrimSuffix(window, "d"); days != window {
+		var n int
+		if n, err = strconv.Atoi(days); err != nil {
+			return 0, fmt.Errorf("invalid window: %s", window)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else if duration, err = time.ParseDuration(window); err != nil {
+		return 0, fmt.Errorf("invalid window: %s", window)
+	}
+	if duration <= 0 || duration > retention {
+		return 0, fmt.Errorf("the window must be in (0, %v]", retention)
+	}
+	return
+}
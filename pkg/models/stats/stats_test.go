@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestCollector(t *testing.T) {
+	now := time.Date(2022, 7, 10, 12, 30, 0, 0, time.UTC)
+	collector := NewCollector(DefaultRetention)
+	collector.now = func() time.Time { return now }
+
+	newPipelineRun := func(name, pipeline string, phase v1alpha3.RunPhase, age, duration time.Duration) *v1alpha3.PipelineRun {
+		startTime := metav1.NewTime(now.Add(-age - duration))
+		completionTime := metav1.NewTime(now.Add(-age))
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey: pipeline,
+			}},
+			Status: v1alpha3.PipelineRunStatus{Phase: phase, StartTime: &startTime, CompletionTime: &completionTime},
+		}
+	}
+	failed := newPipelineRun("deploy-1", "deploy", v1alpha3.Failed, time.Hour, time.Minute)
+	failed.Annotations = map[string]string{v1alpha3.JenkinsPipelineRunStagesStatusAnnoKey: `[` +
+		`{"displayName":"build","result":"SUCCESS"},{"displayName":"test","result":"FAILURE"}]`}
+	for _, pr := range []*v1alpha3.PipelineRun{
+		newPipelineRun("build-1", "build", v1alpha3.Succeeded, time.Hour, time.Minute),
+		newPipelineRun("build-2", "build", v1alpha3.Succeeded, 2*time.Hour, 3*time.Minute),
+		newPipelineRun("build-3", "build", v1alpha3.Cancelled, 3*24*time.Hour, time.Minute),
+		failed,
+		// counted once
+		failed,
+		// out of the retention
+		newPipelineRun("deploy-0", "deploy", v1alpha3.Failed, 31*24*time.Hour, time.Minute),
+		// not completed
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "running"}},
+		newPipelineRun("other", "other", v1alpha3.Failed, time.Hour, time.Minute),
+	} {
+		collector.Observe(pr)
+	}
+	collector.Observe(&v1alpha3.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "pr"}})
+
+	summary := collector.Summarize("ns", 24*time.Hour, 5)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, 2, summary.Succeeded)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, 0.5, summary.SuccessRate)
+	assert.Equal(t, int64(90*time.Second/time.Millisecond), summary.AverageDurationInMillis)
+	assert.Equal(t, []string{"build", "deploy", "other"}, []string{summary.BusiestPipelines[0].Pipeline,
+		summary.BusiestPipelines[1].Pipeline, summary.BusiestPipelines[2].Pipeline})
+	assert.Equal(t, 2, len(summary.FailingPipelines))
+	assert.Equal(t, []StageFailures{{Pipeline: "deploy", Stage: "test", Failed: 1}}, summary.FailingStages)
+	// hourly points
+	assert.Equal(t, 25, len(summary.Trend))
+	assert.Equal(t, Point{Start: time.Date(2022, 7, 10, 11, 0, 0, 0, time.UTC), Total: 3, Failed: 2},
+		summary.Trend[len(summary.Trend)-2])
+
+	summary = collector.Summarize("ns", 7*24*time.Hour, 1)
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 1, summary.Cancelled)
+	assert.Equal(t, 1, len(summary.BusiestPipelines))
+	assert.Equal(t, 3, summary.BusiestPipelines[0].Total)
+	// daily points
+	assert.Equal(t, 8, len(summary.Trend))
+
+	// the statistics are pruned after the retention
+	now = now.Add(DefaultRetention + 2*time.Hour)
+	summary = collector.Summarize("ns", DefaultRetention, 5)
+	assert.Equal(t, 0, summary.Total)
+	assert.Empty(t, collector.buckets)
+	assert.Empty(t, collector.observed)
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{window: "", want: DefaultWindow},
+		{window: "24h", want: 24 * time.Hour},
+		{window: "30d", want: 30 * 24 * time.Hour},
+		{window: "31d", wantErr: true},
+		{window: "0h", wantErr: true},
+		{window: "xd", wantErr: true},
+		{window: "week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			got, err := ParseWindow(tt.window, DefaultRetention)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triggertoken
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretType is the type of Secrets which keep the trigger tokens, the Secrets are not credentials
+	SecretType v1.SecretType = devops.GroupName + "/trigger-token"
+	// DefaultExpiration is the expiration of a trigger token if it's not specified
+	DefaultExpiration = 30 * 24 * time.Hour
+	// MaxExpiration is the maximum expiration of a trigger token
+	MaxExpiration = 365 * 24 * time.Hour
+
+	secretPrefix       = "trigger-token-"
+	descriptionAnnoKey = devops.GroupName + "/trigger-token-description"
+	expirationAnnoKey  = devops.GroupName + "/trigger-token-expiration"
+	hashKey            = "sha256"
+
+	pipelineClaim = "pipeline"
+	idClaim       = "trigger-token-id"
+)
+
+// Token is a trigger token of a Pipeline, the token itself is only returned when it's minted
+type Token struct {
+	ID             string      `json:"id"`
+	Description    string      `json:"description,omitempty"`
+	Creator        string      `json:"creator,omitempty"`
+	CreationTime   metav1.Time `json:"creationTime"`
+	ExpirationTime metav1.Time `json:"expirationTime"`
+	Expired        bool        `json:"expired"`
+	Token          string      `json:"token,omitempty"`
+}
+
+// MintOptions are the options of minting a trigger token
+type MintOptions struct {
+	Description string `json:"description,omitempty"`
+	// ExpiresIn is the expiration in seconds, DefaultExpiration is used if it's zero
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
+
+// Mint issues a token which triggers the Pipeline on behalf of the creator until it expires or is revoked
+func Mint(ctx context.Context, c client.Client, issuer token.Issuer, pipeline *v1alpha3.Pipeline,
+	creator string, options MintOptions, now time.Time) (result *Token, err error) {
+	if creator == "" {
+		err = fmt.Errorf("the creator of trigger token is required")
+		return
+	}
+	expiresIn := time.Duration(options.ExpiresIn) * time.Second
+	if expiresIn == 0 {
+		expiresIn = DefaultExpiration
+	} else if expiresIn < 0 || expiresIn > MaxExpiration {
+		err = fmt.Errorf("the expiration of trigger token should be between 1s and %v", MaxExpiration)
+		return
+	}
+
+	id := utilrand.String(16)
+	var tokenString string
+	if tokenString, err = issuer.IssueTo(&user.DefaultInfo{
+		Name: creator,
+		Extra: map[string][]string{
+			pipelineClaim: {pipelineKey(pipeline.Namespace, pipeline.Name)},
+			idClaim:       {id},
+		},
+	}, token.TriggerToken, expiresIn); err != nil {
+		return
+	}
+
+	expiration := metav1.NewTime(now.Add(expiresIn))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretPrefix + id,
+			Namespace: pipeline.Namespace,
+			Labels:    map[string]string{v1alpha3.PipelineNameLabelKey: pipeline.Name},
+			Annotations: map[string]string{
+				v1alpha3.PipelineRunCreatorAnnoKey: creator,
+				descriptionAnnoKey:                 options.Description,
+				expirationAnnoKey:                  expiration.UTC().Format(time.RFC3339),
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha3.GroupVersion.String(),
+				Kind:       "Pipeline",
+				Name:       pipeline.Name,
+				UID:        pipeline.UID,
+			}},
+		},
+		Type: SecretType,
+		Data: map[string][]byte{hashKey: []byte(hash(tokenString))},
+	}
+	if err = c.Create(ctx, secret); err != nil {
+		return
+	}
+	result = toToken(secret, now)
+	result.CreationTime = metav1.NewTime(now)
+	result.Token = tokenString
+	return
+}
+
+// List returns the trigger tokens of a Pipeline, the latest one comes first
+func List(ctx context.Context, c client.Reader, namespace, pipeline string, now time.Time) (tokens []Token, err error) {
+	secretList := &v1.SecretList{}
+	if err = c.List(ctx, secretList, client.InNamespace(namespace),
+		client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipeline}); err != nil {
+		return
+	}
+	tokens = make([]Token, 0, len(secretList.Items))
+	for i := range secretList.Items {
+		if secretList.Items[i].Type == SecretType {
+			tokens = append(tokens, *toToken(&secretList.Items[i], now))
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[j].CreationTime.Before(&tokens[i].CreationTime)
+	})
+	return
+}
+
+// Revoke deletes a trigger token of a Pipeline
+func Revoke(ctx context.Context, c client.Client, namespace, pipeline, id string) (err error) {
+	var secret *v1.Secret
+	if secret, err = get(ctx, c, namespace, pipeline, id); err == nil {
+		err = c.Delete(ctx, secret)
+	}
+	return
+}
+
+// Verify checks if the token is allowed to trigger the Pipeline, returns the trigger token without the token itself
+func Verify(ctx context.Context, c client.Reader, issuer token.Issuer, namespace, pipeline, tokenString string,
+	now time.Time) (result *Token, err error) {
+	var info user.Info
+	var tokenType token.TokenType
+	if info, tokenType, err = issuer.Verify(tokenString); err != nil {
+		return
+	}
+	extra := info.GetExtra()
+	if tokenType != token.TriggerToken || len(extra[idClaim]) != 1 ||
+		len(extra[pipelineClaim]) != 1 || extra[pipelineClaim][0] != pipelineKey(namespace, pipeline) {
+		err = fmt.Errorf("the token is not allowed to trigger Pipeline %s", pipelineKey(namespace, pipeline))
+		return
+	}
+
+	var secret *v1.Secret
+	if secret, err = get(ctx, c, namespace, pipeline, extra[idClaim][0]); err != nil ||
+		subtle.ConstantTimeCompare(secret.Data[hashKey], []byte(hash(tokenString))) != 1 {
+		err = fmt.Errorf("the token was revoked")
+		return
+	}
+	if result = toToken(secret, now); result.Expired {
+		result, err = nil, fmt.Errorf("the token has expired")
+	}
+	return
+}
+
+func get(ctx context.Context, c client.Reader, namespace, pipeline, id string) (secret *v1.Secret, err error) {
+	secret = &v1.Secret{}
+	if err = c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretPrefix + id}, secret); err != nil {
+		return
+	}
+	if secret.Type != SecretType || secret.Labels[v1alpha3.PipelineNameLabelKey] != pipeline {
+		err = apierrors.NewNotFound(v1.Resource("secrets"), secret.Name)
+	}
+	return
+}
+
+func toToken(secret *v1.Secret, now time.Time) *Token {
+	result := &Token{
+		ID:           secret.Name[len(secretPrefix):],
+		Description:  secret.Annotations[descriptionAnnoKey],
+		Creator:      secret.Annotations[v1alpha3.PipelineRunCreatorAnnoKey],
+		CreationTime: secret.CreationTimestamp,
+	}
+	// treat the token as expired if its expiration is invalid
+	if expiration, err := time.Parse(time.RFC3339, secret.Annotations[expirationAnnoKey]); err == nil {
+		result.ExpirationTime = metav1.NewTime(expiration)
+	}
+	result.Expired = !now.Before(result.ExpirationTime.Time)
+	return result
+}
+
+func pipelineKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func hash(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package triggertoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/jwt/token"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTriggerToken(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	ctx := context.Background()
+	now := time.Now()
+	issuer := token.NewTokenIssuer("secret", 0)
+	pipeline := &v1alpha3.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "uid"}}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline).Build()
+
+	// invalid options
+	_, err = Mint(ctx, c, issuer, pipeline, "", MintOptions{}, now)
+	assert.NotNil(t, err)
+	_, err = Mint(ctx, c, issuer, pipeline, "alice", MintOptions{ExpiresIn: int64(MaxExpiration.Seconds()) + 1}, now)
+	assert.NotNil(t, err)
+
+	minted, err := Mint(ctx, c, issuer, pipeline, "alice", MintOptions{Description: "ci", ExpiresIn: 60}, now)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, minted.Token)
+	assert.Equal(t, "alice", minted.Creator)
+	assert.Equal(t, "ci", minted.Description)
+	assert.False(t, minted.Expired)
+
+	tokens, err := List(ctx, c, "ns", "demo", now)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(tokens)) {
+		assert.Equal(t, minted.ID, tokens[0].ID)
+		assert.Empty(t, tokens[0].Token)
+	}
+	tokens, err = List(ctx, c, "ns", "another", now)
+	assert.Nil(t, err)
+	assert.Empty(t, tokens)
+
+	// verify the token
+	verified, err := Verify(ctx, c, issuer, "ns", "demo", minted.Token, now)
+	assert.Nil(t, err)
+	if assert.NotNil(t, verified) {
+		assert.Equal(t, minted.ID, verified.ID)
+		assert.Equal(t, "alice", verified.Creator)
+	}
+	_, err = Verify(ctx, c, issuer, "ns", "another", minted.Token, now)
+	assert.NotNil(t, err)
+	_, err = Verify(ctx, c, issuer, "ns", "demo", minted.Token, now.Add(time.Minute))
+	assert.EqualError(t, err, "the token has expired")
+	_, err = Verify(ctx, c, token.NewTokenIssuer("another", 0), "ns", "demo", minted.Token, now)
+	assert.NotNil(t, err)
+
+	// the access tokens of users are not trigger tokens
+	accessToken, err := issuer.IssueTo(&user.DefaultInfo{Name: "alice"}, token.AccessToken, time.Minute)
+	assert.Nil(t, err)
+	_, err = Verify(ctx, c, issuer, "ns", "demo", accessToken, now)
+	assert.NotNil(t, err)
+
+	// revoke the token
+	assert.NotNil(t, Revoke(ctx, c, "ns", "another", minted.ID))
+	assert.Nil(t, Revoke(ctx, c, "ns", "demo", minted.ID))
+	_, err = Verify(ctx, c, issuer, "ns", "demo", minted.Token, now)
+	assert.EqualError(t, err, "the token was revoked")
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultDeadLetterNamespace is the namespace where the failed deliveries are stored
+	DefaultDeadLetterNamespace = "kubesphere-devops-system"
+	// DefaultDeadLetterMaxSize is the max number of the failed deliveries, the oldest ones will be dropped
+	DefaultDeadLetterMaxSize = 100
+
+	// deliveryLabelKey is the label key of the ConfigMaps which store the failed deliveries
+	deliveryLabelKey = devops.GroupName + "/webhook-delivery"
+	deliveryDataKey  = "delivery"
+	// maxDeliverySize is the max size of a delivery, it's a little bit smaller than the limit of a ConfigMap
+	maxDeliverySize = 1000 * 1024
+)
+
+// sensitiveHeaders are not persisted, the webhook handler does not depend on them
+var sensitiveHeaders = []string{"Authorization", "Cookie", "X-Gitlab-Token"}
+
+// Delivery is an inbound SCM webhook delivery which failed to be processed
+type Delivery struct {
+	// ID is the identity of the failed delivery
+	ID      string      `json:"id"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	// FailedPipelines are the Pipelines (in the format of namespace/name) which failed to be triggered.
+	// All the matched Pipelines will be triggered when replaying if it's empty.
+	FailedPipelines []string `json:"failedPipelines,omitempty"`
+	// Error is the error message of the last attempt
+	Error string `json:"error"`
+	// Attempts is the number of the attempts, including the first delivery
+	Attempts        int         `json:"attempts"`
+	ReceivedTime    metav1.Time `json:"receivedTime"`
+	LastAttemptTime metav1.Time `json:"lastAttemptTime"`
+}
+
+// DeadLetterQueue persists the failed webhook deliveries into ConfigMaps, then they could be inspected and replayed
+type DeadLetterQueue struct {
+	Client    client.Client
+	Namespace string
+	// MaxSize is the max number of the deliveries, the oldest ones will be dropped if it's positive
+	MaxSize int
+}
+
+// NewDelivery creates a failed delivery from a webhook request
+func NewDelivery(header http.Header, body []byte, failedPipelines []string, err error) *Delivery {
+	header = header.Clone()
+	for _, key := range sensitiveHeaders {
+		header.Del(key)
+	}
+	now := metav1.Now()
+	return &Delivery{
+		Headers:         header,
+		Body:            string(body),
+		FailedPipelines: failedPipelines,
+		Error:           err.Error(),
+		Attempts:        1,
+		ReceivedTime:    now,
+		LastAttemptTime: now,
+	}
+}
+
+// Add puts a failed delivery into the queue
+func (q *DeadLetterQueue) Add(ctx context.Context, delivery *Delivery) (err error) {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    q.Namespace,
+			GenerateName: "webhook-delivery-",
+			Labels:       map[string]string{deliveryLabelKey: "failed"},
+		},
+	}
+	if err = setDelivery(configMap, delivery); err != nil {
+		return
+	}
+	if err = q.Client.Create(ctx, configMap); err != nil {
+		return
+	}
+	delivery.ID = configMap.Name
+	return q.evict(ctx)
+}
+
+// evict drops the oldest deliveries which are out of the max size
+func (q *DeadLetterQueue) evict(ctx context.Context) (err error) {
+	if q.MaxSize <= 0 {
+		return
+	}
+	var deliveries []Delivery
+	if deliveries, err = q.List(ctx); err != nil {
+		return
+	}
+	for i := q.MaxSize; i < len(deliveries); i++ {
+		if err = client.IgnoreNotFound(q.Delete(ctx, deliveries[i].ID)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// List returns all the failed deliveries, the latest one comes first
+func (q *DeadLetterQueue) List(ctx context.Context) (deliveries []Delivery, err error) {
+	configMapList := &v1.ConfigMapList{}
+	if err = q.Client.List(ctx, configMapList, client.InNamespace(q.Namespace), client.HasLabels{deliveryLabelKey}); err != nil {
+		return
+	}
+	deliveries = make([]Delivery, 0, len(configMapList.Items))
+	for i := range configMapList.Items {
+		var delivery *Delivery
+		if delivery, err = getDelivery(&configMapList.Items[i]); err != nil {
+			return
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[j].ReceivedTime.Before(&deliveries[i].ReceivedTime)
+	})
+	return
+}
+
+// Get returns a failed delivery by its ID
+func (q *DeadLetterQueue) Get(ctx context.Context, id string) (delivery *Delivery, err error) {
+	var configMap *v1.ConfigMap
+	if configMap, err = q.get(ctx, id); err == nil {
+		delivery, err = getDelivery(configMap)
+	}
+	return
+}
+
+func (q *DeadLetterQueue) get(ctx context.Context, id string) (configMap *v1.ConfigMap, err error) {
+	configMap = &v1.ConfigMap{}
+	if err = q.Client.Get(ctx, types.NamespacedName{Namespace: q.Namespace, Name: id}, configMap); err != nil {
+		return
+	}
+	if _, ok := configMap.Labels[deliveryLabelKey]; !ok {
+		err = apierrors.NewNotFound(schema.GroupResource{Resource: "deliveries"}, id)
+	}
+	return
+}
+
+// Update updates a failed delivery, such as the attempts and the error message
+func (q *DeadLetterQueue) Update(ctx context.Context, delivery *Delivery) (err error) {
+	var configMap *v1.ConfigMap
+	if configMap, err = q.get(ctx, delivery.ID); err != nil {
+		return
+	}
+	if err = setDelivery(configMap, delivery); err == nil {
+		err = q.Client.Update(ctx, configMap)
+	}
+	return
+}
+
+// Delete removes a failed delivery from the queue
+func (q *DeadLetterQueue) Delete(ctx context.Context, id string) (err error) {
+	var configMap *v1.ConfigMap
+	if configMap, err = q.get(ctx, id); err == nil {
+		err = q.Client.Delete(ctx, configMap)
+	}
+	return
+}
+
+func setDelivery(configMap *v1.ConfigMap, delivery *Delivery) (err error) {
+	// the ID is the name of ConfigMap, no need to store it
+	data := *delivery
+	data.ID = ""
+	var raw []byte
+	if raw, err = json.Marshal(data); err != nil {
+		return
+	}
+	if len(raw) > maxDeliverySize {
+		err = fmt.Errorf("the size of delivery %d is out of the limit %d", len(raw), maxDeliverySize)
+		return
+	}
+	configMap.Data = map[string]string{deliveryDataKey: string(raw)}
+	return
+}
+
+func getDelivery(configMap *v1.ConfigMap) (delivery *Delivery, err error) {
+	delivery = &Delivery{}
+	if err = json.Unmarshal([]byte(configMap.Data[deliveryDataKey]), delivery); err != nil {
+		err = fmt.Errorf("failed to parse the delivery %s, error: %v", configMap.Name, err)
+		return
+	}
+	delivery.ID = configMap.Name
+	return
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeQueue(t *testing.T, maxSize int, objects ...runtime.Object) *DeadLetterQueue {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+	return &DeadLetterQueue{
+		Client:    fake.NewClientBuilder().WithScheme(schema).WithRuntimeObjects(objects...).Build(),
+		Namespace: "ns",
+		MaxSize:   maxSize,
+	}
+}
+
+func TestNewDelivery(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("Authorization", "Bearer token")
+	header.Set("X-Gitlab-Token", "token")
+
+	delivery := NewDelivery(header, []byte("body"), []string{"ns/a"}, errors.New("jenkins is down"))
+	assert.Equal(t, http.Header{"X-Github-Event": []string{"push"}}, delivery.Headers)
+	assert.Equal(t, "body", delivery.Body)
+	assert.Equal(t, []string{"ns/a"}, delivery.FailedPipelines)
+	assert.Equal(t, "jenkins is down", delivery.Error)
+	assert.Equal(t, 1, delivery.Attempts)
+	// the original header should not be changed
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	ctx := context.TODO()
+	queue := newFakeQueue(t, 2, &v1.ConfigMap{
+		// it is not a delivery
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+	})
+
+	now := time.Now()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		delivery := NewDelivery(http.Header{}, []byte("body"), nil, errors.New("error"))
+		delivery.ReceivedTime = metav1.NewTime(now.Add(time.Duration(i) * time.Minute))
+		assert.Nil(t, queue.Add(ctx, delivery))
+		assert.NotEmpty(t, delivery.ID)
+		ids = append(ids, delivery.ID)
+	}
+
+	// the oldest one was dropped
+	deliveries, err := queue.List(ctx)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(deliveries)) {
+		assert.Equal(t, ids[2], deliveries[0].ID)
+		assert.Equal(t, ids[1], deliveries[1].ID)
+	}
+	_, err = queue.Get(ctx, ids[0])
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = queue.Get(ctx, "config")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	delivery, err := queue.Get(ctx, ids[1])
+	assert.Nil(t, err)
+	delivery.Attempts++
+	delivery.Error = "another error"
+	assert.Nil(t, queue.Update(ctx, delivery))
+	delivery, err = queue.Get(ctx, ids[1])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, "another error", delivery.Error)
+
+	assert.Nil(t, queue.Delete(ctx, ids[1]))
+	assert.True(t, apierrors.IsNotFound(queue.Delete(ctx, ids[1])))
+	assert.True(t, apierrors.IsNotFound(queue.Delete(ctx, "config")))
+	deliveries, err = queue.List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deliveries))
+}
+
+func TestDeadLetterQueue_AddTooLarge(t *testing.T) {
+	queue := newFakeQueue(t, 0)
+	delivery := NewDelivery(http.Header{}, []byte(strings.Repeat("a", maxDeliverySize)), nil, errors.New("error"))
+	assert.NotNil(t, queue.Add(context.TODO(), delivery))
+}
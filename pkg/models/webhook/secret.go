@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultSecretName is the name of the Secret which has the shared secret of the SCM webhooks,
+	// it's in the same namespace as the failed deliveries
+	DefaultSecretName = "devops-scm-webhook"
+	// SecretKey is the key of the shared secret in the Secret
+	SecretKey = "secret"
+)
+
+// GetSecret returns the shared secret of the SCM webhooks, it's empty if the secret is not configured
+func GetSecret(ctx context.Context, c client.Reader) (secret string, err error) {
+	data := &v1.Secret{}
+	if err = c.Get(ctx, types.NamespacedName{Namespace: DefaultDeadLetterNamespace, Name: DefaultSecretName}, data); err == nil {
+		secret = string(data.Data[SecretKey])
+	} else if apierrors.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
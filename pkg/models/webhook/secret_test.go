@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetSecret(t *testing.T) {
+	schema := runtime.NewScheme()
+	assert.Nil(t, v1.AddToScheme(schema))
+
+	// not configured
+	secret, err := GetSecret(context.TODO(), fake.NewClientBuilder().WithScheme(schema).Build())
+	assert.Nil(t, err)
+	assert.Empty(t, secret)
+
+	secret, err = GetSecret(context.TODO(), fake.NewClientBuilder().WithScheme(schema).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: DefaultDeadLetterNamespace, Name: DefaultSecretName},
+		Data:       map[string][]byte{SecretKey: []byte("secret")},
+	}).Build())
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", secret)
+
+	// the scheme doesn't know Secret
+	_, err = GetSecret(context.TODO(), fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/models/logmask"
+	"kubesphere.io/devops/pkg/rpc/pb"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultPollInterval is the interval of checking the changes of a PipelineRun and its log
+const defaultPollInterval = time.Second
+
+// PipelineService implements the gRPC service of Pipelines on top of the same client and Jenkins as the REST APIs
+type PipelineService struct {
+	pb.UnimplementedPipelineServiceServer
+
+	client       client.Client
+	devopsClient devops.Interface
+	// authz authorizes the requests, all the authenticated requests are allowed if it's nil
+	authz        authorizer.Authorizer
+	pollInterval time.Duration
+}
+
+// NewPipelineService creates a PipelineService
+func NewPipelineService(c client.Client, devopsClient devops.Interface, authz authorizer.Authorizer) *PipelineService {
+	return &PipelineService{
+		client:       c,
+		devopsClient: devopsClient,
+		authz:        authz,
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// TriggerRun creates a PipelineRun of a Pipeline, the same as the REST API does
+func (s *PipelineService) TriggerRun(ctx context.Context, req *pb.TriggerRunRequest) (*pb.PipelineRun, error) {
+	if err := s.authorize(ctx, "create", req.Namespace, "pipelines", req.Pipeline, "pipelineruns"); err != nil {
+		return nil, err
+	}
+
+	pipeline := &v1alpha3.Pipeline{}
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Pipeline}, pipeline); err != nil {
+		return nil, toStatusError(err)
+	}
+	scm, err := pipelinerun.CreateScm(&pipeline.Spec, req.Branch)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	payload := &devops.RunPayload{}
+	for _, param := range req.Parameters {
+		payload.Parameters = append(payload.Parameters, devops.Parameter{Name: param.Name, Value: param.Value})
+	}
+	pr := pipelinerun.CreatePipelineRun(pipeline, payload, scm)
+	if user, ok := request.UserFrom(ctx); ok && user.GetName() != "" {
+		pr.GetAnnotations()[v1alpha3.PipelineRunCreatorAnnoKey] = user.GetName()
+	}
+	if err := s.client.Create(ctx, pr); err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPipelineRun(pr), nil
+}
+
+// WatchRun sends the PipelineRun whenever it changes, the stream ends once the PipelineRun completes
+func (s *PipelineService) WatchRun(req *pb.WatchRunRequest, stream pb.PipelineService_WatchRunServer) error {
+	ctx := stream.Context()
+	if err := s.authorize(ctx, "get", req.Namespace, "pipelineruns", req.Name, ""); err != nil {
+		return err
+	}
+
+	var resourceVersion string
+	return s.poll(ctx, func() (bool, error) {
+		pr := &v1alpha3.PipelineRun{}
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Name}, pr); err != nil {
+			return false, toStatusError(err)
+		}
+		if pr.ResourceVersion != resourceVersion {
+			resourceVersion = pr.ResourceVersion
+			if err := stream.Send(toPipelineRun(pr)); err != nil {
+				return false, err
+			}
+		}
+		return pr.HasCompleted(), nil
+	})
+}
+
+// StreamLog sends the new log of the PipelineRun once it's available from Jenkins, the credentials in the log are
+// masked as the REST API does. The stream ends once the PipelineRun completes and all the log has been sent.
+func (s *PipelineService) StreamLog(req *pb.StreamLogRequest, stream pb.PipelineService_StreamLogServer) error {
+	ctx := stream.Context()
+	if err := s.authorize(ctx, "get", req.Namespace, "pipelineruns", req.Name, ""); err != nil {
+		return err
+	}
+	if req.Start < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid start %d, it should be a non-negative integer", req.Start)
+	}
+	if s.devopsClient == nil {
+		return status.Error(codes.Unavailable, "Jenkins is not configured")
+	}
+
+	offset := req.Start
+	var masker *logmask.Masker
+	return s.poll(ctx, func() (bool, error) {
+		pr := &v1alpha3.PipelineRun{}
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Name}, pr); err != nil {
+			return false, toStatusError(err)
+		}
+		runID, started := pr.GetPipelineRunID()
+		if !started {
+			// a PipelineRun might be cancelled before it starts, then there is no log at all
+			return pr.HasCompleted(), nil
+		}
+
+		if masker == nil {
+			var err error
+			if masker, err = logmask.ForPipelineRun(ctx, s.client, pr); err != nil {
+				return false, toStatusError(err)
+			}
+		}
+		log, header, err := s.getLog(pr, runID, offset)
+		if err != nil {
+			return false, toStatusError(err)
+		}
+		next, moreData := parseLogHeader(header, offset, len(log))
+		if len(log) > 0 {
+			if err := stream.Send(&pb.LogChunk{Content: masker.Mask(log), Offset: next}); err != nil {
+				return false, err
+			}
+		}
+		offset = next
+		return !moreData && pr.HasCompleted(), nil
+	})
+}
+
+// poll calls the condition func at every interval until it's done or fails
+func (s *PipelineService) poll(ctx context.Context, condition func() (bool, error)) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		if done, err := condition(); err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// getLog fetches the progressive log of the PipelineRun from Jenkins which starts from the offset
+func (s *PipelineService) getLog(pr *v1alpha3.PipelineRun, runID string, offset int64) ([]byte, http.Header, error) {
+	params := &devops.HttpParameters{
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Url:    &url.URL{RawQuery: url.Values{"start": []string{strconv.FormatInt(offset, 10)}}.Encode()},
+	}
+	pipelineName := pr.Labels[v1alpha3.PipelineNameLabelKey]
+	if pr.Spec.IsMultiBranchPipeline() && pr.Spec.SCM != nil {
+		return s.devopsClient.GetBranchProgressiveRunLog(pr.Namespace, pipelineName, pr.Spec.SCM.RefName, runID, params)
+	}
+	return s.devopsClient.GetProgressiveRunLog(pr.Namespace, pipelineName, runID, params)
+}
+
+// parseLogHeader returns the offset which the next fetching starts from, and whether there is more log
+func parseLogHeader(header http.Header, offset int64, length int) (int64, bool) {
+	next, err := strconv.ParseInt(header.Get("X-Text-Size"), 10, 64)
+	if err != nil {
+		next = offset + int64(length)
+	}
+	moreData, _ := strconv.ParseBool(header.Get("X-More-Data"))
+	return next, moreData
+}
+
+func toPipelineRun(pr *v1alpha3.PipelineRun) *pb.PipelineRun {
+	result := &pb.PipelineRun{
+		Namespace:      pr.Namespace,
+		Name:           pr.Name,
+		Pipeline:       pr.Labels[v1alpha3.PipelineNameLabelKey],
+		Phase:          string(pr.Status.Phase),
+		StartTime:      toTimestamp(pr.Status.StartTime),
+		CompletionTime: toTimestamp(pr.Status.CompletionTime),
+	}
+	result.RunId, _ = pr.GetPipelineRunID()
+	if pr.Spec.SCM != nil {
+		result.Branch = pr.Spec.SCM.RefName
+	}
+	return result
+}
+
+func toTimestamp(t *metav1.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakedevops "kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/rpc/pb"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testToken = "token"
+
+// newTestClient starts a gRPC server of the service in memory, then returns a client connected to it
+func newTestClient(t *testing.T, service *PipelineService) pb.PipelineServiceClient {
+	auth := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		if token != testToken {
+			return nil, false, nil
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: "admin"}}, true, nil
+	})
+	service.pollInterval = 10 * time.Millisecond
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(service, auth)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return pb.NewPipelineServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+token)
+}
+
+func TestPipelineService_TriggerRun(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	pipeline := &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+		Spec:       v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType},
+	}
+	forbidden := authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+		if a.GetVerb() == "create" && a.GetResource() == "pipelines" && a.GetSubresource() == "pipelineruns" {
+			return authorizer.DecisionDeny, "not allowed", nil
+		}
+		return authorizer.DecisionAllow, "", nil
+	})
+
+	tests := []struct {
+		name     string
+		token    string
+		authz    authorizer.Authorizer
+		request  *pb.TriggerRunRequest
+		wantCode codes.Code
+	}{{
+		name:     "without token",
+		request:  &pb.TriggerRunRequest{Namespace: "ns", Pipeline: "pipeline"},
+		wantCode: codes.Unauthenticated,
+	}, {
+		name:     "invalid token",
+		token:    "invalid",
+		request:  &pb.TriggerRunRequest{Namespace: "ns", Pipeline: "pipeline"},
+		wantCode: codes.Unauthenticated,
+	}, {
+		name:     "not allowed",
+		token:    testToken,
+		authz:    forbidden,
+		request:  &pb.TriggerRunRequest{Namespace: "ns", Pipeline: "pipeline"},
+		wantCode: codes.PermissionDenied,
+	}, {
+		name:     "Pipeline not found",
+		token:    testToken,
+		request:  &pb.TriggerRunRequest{Namespace: "ns", Pipeline: "fake"},
+		wantCode: codes.NotFound,
+	}, {
+		name:  "normal",
+		token: testToken,
+		request: &pb.TriggerRunRequest{Namespace: "ns", Pipeline: "pipeline",
+			Parameters: []*pb.Parameter{{Name: "version", Value: "v1"}}},
+		wantCode: codes.OK,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(pipeline.DeepCopy()).Build()
+			rpcClient := newTestClient(t, NewPipelineService(c, nil, tt.authz))
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = withToken(tt.token)
+			}
+			pr, err := rpcClient.TriggerRun(ctx, tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err), err)
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			assert.Equal(t, "pipeline", pr.Pipeline)
+			created := &v1alpha3.PipelineRun{}
+			assert.Nil(t, c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: pr.Name}, created))
+			assert.Equal(t, "admin", created.Annotations[v1alpha3.PipelineRunCreatorAnnoKey])
+			assert.Equal(t, []v1alpha3.Parameter{{Name: "version", Value: "v1"}}, created.Spec.Parameters)
+		})
+	}
+}
+
+func TestPipelineService_WatchRun(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+
+	now := metav1.Now()
+	completed := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "run", Labels: map[string]string{
+			v1alpha3.PipelineNameLabelKey: "pipeline",
+		}, Annotations: map[string]string{
+			v1alpha3.JenkinsPipelineRunIDAnnoKey: "1",
+		}},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded, StartTime: &now, CompletionTime: &now},
+	}
+	c := fake.NewClientBuilder().WithScheme(schema).WithObjects(completed).Build()
+	rpcClient := newTestClient(t, NewPipelineService(c, nil, nil))
+
+	// the stream ends once the PipelineRun completes
+	stream, err := rpcClient.WatchRun(withToken(testToken), &pb.WatchRunRequest{Namespace: "ns", Name: "run"})
+	assert.Nil(t, err)
+	pr, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, "1", pr.RunId)
+	assert.Equal(t, string(v1alpha3.Succeeded), pr.Phase)
+	assert.Equal(t, now.Unix(), pr.CompletionTime.AsTime().Unix())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = rpcClient.WatchRun(withToken(testToken), &pb.WatchRunRequest{Namespace: "ns", Name: "fake"})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestPipelineService_StreamLog(t *testing.T) {
+	schema, err := v1alpha3.SchemeBuilder.Register().Build()
+	assert.Nil(t, err)
+	err = v1.SchemeBuilder.AddToScheme(schema)
+	assert.Nil(t, err)
+
+	now := metav1.Now()
+	completed := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "run", Labels: map[string]string{
+			v1alpha3.PipelineNameLabelKey: "pipeline",
+		}, Annotations: map[string]string{
+			v1alpha3.JenkinsPipelineRunIDAnnoKey: "1",
+		}},
+		Spec:   v1alpha3.PipelineRunSpec{PipelineSpec: &v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType}},
+		Status: v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded, CompletionTime: &now},
+	}
+	notStarted := &v1alpha3.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cancelled"},
+		Status:     v1alpha3.PipelineRunStatus{Phase: v1alpha3.Cancelled, CompletionTime: &now},
+	}
+
+	tests := []struct {
+		name       string
+		request    *pb.StreamLogRequest
+		wantChunks []*pb.LogChunk
+		wantCode   codes.Code
+	}{{
+		name:       "from the beginning",
+		request:    &pb.StreamLogRequest{Namespace: "ns", Name: "run"},
+		wantChunks: []*pb.LogChunk{{Content: []byte("hello world"), Offset: 11}},
+	}, {
+		name:       "resume from an offset",
+		request:    &pb.StreamLogRequest{Namespace: "ns", Name: "run", Start: 6},
+		wantChunks: []*pb.LogChunk{{Content: []byte("world"), Offset: 11}},
+	}, {
+		name:    "not started",
+		request: &pb.StreamLogRequest{Namespace: "ns", Name: "cancelled"},
+	}, {
+		name:     "invalid start",
+		request:  &pb.StreamLogRequest{Namespace: "ns", Name: "run", Start: -1},
+		wantCode: codes.InvalidArgument,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(schema).WithObjects(completed.DeepCopy(), notStarted.DeepCopy()).Build()
+			devopsClient := fakedevops.NewFakeDevops(map[string]interface{}{"ns-pipeline-1": "hello world"})
+			rpcClient := newTestClient(t, NewPipelineService(c, devopsClient, nil))
+
+			stream, err := rpcClient.StreamLog(withToken(testToken), tt.request)
+			assert.Nil(t, err)
+			var chunks []*pb.LogChunk
+			for {
+				chunk, err := stream.Recv()
+				if err != nil {
+					if err != io.EOF {
+						assert.Equal(t, tt.wantCode, status.Code(err), err)
+					}
+					break
+				}
+				chunks = append(chunks, &pb.LogChunk{Content: chunk.Content, Offset: chunk.Offset})
+			}
+			assert.Equal(t, tt.wantChunks, chunks)
+		})
+	}
+}
+
+func Test_parseLogHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Text-Size", "20")
+	header.Set("X-More-Data", "true")
+	next, moreData := parseLogHeader(header, 10, 5)
+	assert.Equal(t, int64(20), next)
+	assert.True(t, moreData)
+
+	// count the offset if the header is missing
+	next, moreData = parseLogHeader(http.Header{}, 10, 5)
+	assert.Equal(t, int64(15), next)
+	assert.False(t, moreData)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rpc serves the gRPC APIs alongside the REST APIs, the protobuf definitions are in the pb package
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/request"
+	"kubesphere.io/devops/pkg/rpc/pb"
+)
+
+// authorizationKey is the metadata key of the bearer token, the same as the HTTP header
+const authorizationKey = "authorization"
+
+// NewServer creates a gRPC server of the service, the requests are authenticated by the bearer tokens in the metadata
+func NewServer(service pb.PipelineServiceServer, auth authenticator.Token, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := authenticate(ctx, auth)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			ctx, err := authenticate(stream.Context(), auth)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+		}))
+
+	server := grpc.NewServer(opts...)
+	pb.RegisterPipelineServiceServer(server, service)
+	return server
+}
+
+// authenticatedStream carries the context with the authenticated user
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate returns a copy of the context with the user of the bearer token, the anonymous requests are rejected
+func authenticate(ctx context.Context, auth authenticator.Token) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get(authorizationKey); len(values) > 0 {
+		token = strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "the bearer token is required")
+	}
+
+	resp, ok, err := auth.AuthenticateToken(ctx, token)
+	if err != nil || !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	return request.WithUser(ctx, resp.User), nil
+}
+
+// authorize checks if the user is allowed to operate the resource, the attributes are the same as the REST APIs
+func (s *PipelineService) authorize(ctx context.Context, verb, namespace, resource, name, subresource string) error {
+	if s.authz == nil {
+		return nil
+	}
+	user, _ := request.UserFrom(ctx)
+	decision, reason, err := s.authz.Authorize(ctx, authorizer.AttributesRecord{
+		User:            user,
+		Verb:            verb,
+		Namespace:       namespace,
+		APIGroup:        v1alpha3.GroupVersion.Group,
+		APIVersion:      v1alpha3.GroupVersion.Version,
+		Resource:        resource,
+		Subresource:     subresource,
+		Name:            name,
+		ResourceRequest: true,
+	})
+	if decision == authorizer.DecisionAllow {
+		return nil
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return status.Error(codes.PermissionDenied, reason)
+}
+
+// toStatusError converts the errors of Kubernetes to the gRPC status errors
+func toStatusError(err error) error {
+	code := codes.Internal
+	switch {
+	case apierrors.IsNotFound(err):
+		code = codes.NotFound
+	case apierrors.IsAlreadyExists(err):
+		code = codes.AlreadyExists
+	case apierrors.IsBadRequest(err), apierrors.IsInvalid(err):
+		code = codes.InvalidArgument
+	case apierrors.IsForbidden(err):
+		code = codes.PermissionDenied
+	case apierrors.IsUnauthorized(err):
+		code = codes.Unauthenticated
+	}
+	return status.Error(code, err.Error())
+}
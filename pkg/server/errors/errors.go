@@ -44,6 +44,8 @@ func New(format string, args ...interface{}) error {
 func GetServiceErrorCode(err error) int {
 	if svcErr, ok := err.(restful.ServiceError); ok {
 		return svcErr.Code
+	} else if codeErr, ok := err.(interface{ StatusCode() int }); ok {
+		return codeErr.StatusCode()
 	} else {
 		return http.StatusInternalServerError
 	}
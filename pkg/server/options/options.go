@@ -40,6 +40,9 @@ type ServerRunOptions struct {
 
 	// tls private key file
 	TlsPrivateKey string
+
+	// gRPC port number, the gRPC server is disabled if it's zero
+	GRPCPort int
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -62,6 +65,10 @@ func (s *ServerRunOptions) Validate() []error {
 		errs = append(errs, fmt.Errorf("insecure and secure port can not be disabled at the same time"))
 	}
 
+	if s.GRPCPort != 0 && !net.IsValidPort(s.GRPCPort) {
+		errs = append(errs, fmt.Errorf("invalid gRPC port %d", s.GRPCPort))
+	}
+
 	if net.IsValidPort(s.SecurePort) {
 		if s.TlsCertFile == "" {
 			errs = append(errs, fmt.Errorf("tls cert file is empty while secure serving"))
@@ -90,4 +97,5 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet, c *ServerRunOptions) {
 	fs.IntVar(&s.SecurePort, "secure-port", s.SecurePort, "secure port number")
 	fs.StringVar(&s.TlsCertFile, "tls-cert-file", c.TlsCertFile, "tls cert file")
 	fs.StringVar(&s.TlsPrivateKey, "tls-private-key", c.TlsPrivateKey, "tls private key")
+	fs.IntVar(&s.GRPCPort, "grpc-port", c.GRPCPort, "gRPC port number, the gRPC server is disabled if it's zero")
 }
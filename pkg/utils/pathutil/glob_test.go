@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pathutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "README.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "docs/README.md", want: false},
+		{pattern: "**/*.md", name: "docs/README.md", want: true},
+		{pattern: "**/*.md", name: "README.md", want: true},
+		{pattern: "services/api/**", name: "services/api/main.go", want: true},
+		{pattern: "services/api/**", name: "services/api/internal/handler.go", want: true},
+		{pattern: "services/api/**", name: "services/web/main.go", want: false},
+		{pattern: "services/api/", name: "services/api/internal/handler.go", want: true},
+		{pattern: "services/*/go.mod", name: "services/api/go.mod", want: true},
+		{pattern: "services/**/test/*.go", name: "services/api/test/a.go", want: true},
+		{pattern: "services/**/test/*.go", name: "services/test/a.go", want: true},
+		{pattern: "services/**/test/*.go", name: "services/api/a.go", want: false},
+		{pattern: "services/?pi/**", name: "services/api/a.go", want: true},
+		{pattern: "[", name: "[", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.pattern, tt.name))
+		})
+	}
+
+	assert.True(t, MatchAny([]string{"docs/**", "*.md"}, "README.md"))
+	assert.False(t, MatchAny(nil, "README.md"))
+}
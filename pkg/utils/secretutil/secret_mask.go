@@ -47,6 +47,16 @@ func kubeconfigCredentialMask(secret *v1.Secret) *v1.Secret {
 	return secret
 }
 
+func dockerRegistryCredentialMask(secret *v1.Secret) *v1.Secret {
+	secret.Data[v1alpha3.DockerRegistryPasswordKey] = defaultMasque
+	return secret
+}
+
+func bearerTokenCredentialMask(secret *v1.Secret) *v1.Secret {
+	secret.Data[v1alpha3.BearerTokenKey] = defaultMasque
+	return secret
+}
+
 // MaskCredential masks sensetive data inside credential.
 func MaskCredential(secret *v1.Secret) *v1.Secret {
 	if secret == nil || secret.Data == nil {
@@ -65,4 +75,6 @@ func init() {
 	credentialMaskHolder[v1alpha3.SecretTypeSSHAuth] = sshAuthCredentialMask
 	credentialMaskHolder[v1alpha3.SecretTypeSecretText] = secretTextCredentialMask
 	credentialMaskHolder[v1alpha3.SecretTypeKubeConfig] = kubeconfigCredentialMask
+	credentialMaskHolder[v1alpha3.SecretTypeDockerRegistry] = dockerRegistryCredentialMask
+	credentialMaskHolder[v1alpha3.SecretTypeBearerToken] = bearerTokenCredentialMask
 }
@@ -86,6 +86,42 @@ func Test_maskCredential(t *testing.T) {
 				v1alpha3.SecretTextSecretKey: []byte(""),
 			},
 		},
+	}, {
+		name: "Mask docker registry secret",
+		args: args{
+			secret: &v1.Secret{
+				Type: v1alpha3.SecretTypeDockerRegistry,
+				Data: map[string][]byte{
+					v1alpha3.DockerRegistryServerKey:   []byte("docker.io"),
+					v1alpha3.DockerRegistryUsernameKey: []byte("fake username"),
+					v1alpha3.DockerRegistryPasswordKey: []byte("fake password"),
+				},
+			},
+		},
+		want: &v1.Secret{
+			Type: v1alpha3.SecretTypeDockerRegistry,
+			Data: map[string][]byte{
+				v1alpha3.DockerRegistryServerKey:   []byte("docker.io"),
+				v1alpha3.DockerRegistryUsernameKey: []byte("fake username"),
+				v1alpha3.DockerRegistryPasswordKey: []byte(""),
+			},
+		},
+	}, {
+		name: "Mask bearer token secret",
+		args: args{
+			secret: &v1.Secret{
+				Type: v1alpha3.SecretTypeBearerToken,
+				Data: map[string][]byte{
+					v1alpha3.BearerTokenKey: []byte("fake token"),
+				},
+			},
+		},
+		want: &v1.Secret{
+			Type: v1alpha3.SecretTypeBearerToken,
+			Data: map[string][]byte{
+				v1alpha3.BearerTokenKey: []byte(""),
+			},
+		},
 	}, {
 		name: "Mask kubeconfig secret",
 		args: args{
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+type credentialValidator func(*v1.Secret) error
+
+var credentialValidatorHolder = map[v1.SecretType]credentialValidator{}
+
+func basicAuthCredentialValidator(secret *v1.Secret) error {
+	if len(secret.Data[v1alpha3.BasicAuthUsernameKey]) == 0 && len(secret.Data[v1alpha3.BasicAuthPasswordKey]) == 0 {
+		return fmt.Errorf("at least one of %s and %s is required", v1alpha3.BasicAuthUsernameKey, v1alpha3.BasicAuthPasswordKey)
+	}
+	return nil
+}
+
+func sshAuthCredentialValidator(secret *v1.Secret) error {
+	privateKey := secret.Data[v1alpha3.SSHAuthPrivateKey]
+	if len(privateKey) == 0 {
+		return requiredError(v1alpha3.SSHAuthPrivateKey)
+	}
+	if block, _ := pem.Decode(privateKey); block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return fmt.Errorf("%s is not a PEM encoded private key", v1alpha3.SSHAuthPrivateKey)
+	}
+	return nil
+}
+
+func secretTextCredentialValidator(secret *v1.Secret) error {
+	if len(secret.Data[v1alpha3.SecretTextSecretKey]) == 0 {
+		return requiredError(v1alpha3.SecretTextSecretKey)
+	}
+	return nil
+}
+
+func kubeconfigCredentialValidator(secret *v1.Secret) error {
+	content := secret.Data[v1alpha3.KubeConfigSecretKey]
+	if len(content) == 0 {
+		return requiredError(v1alpha3.KubeConfigSecretKey)
+	}
+	config, err := clientcmd.Load(content)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid kubeconfig: %v", v1alpha3.KubeConfigSecretKey, err)
+	}
+	if len(config.Clusters) == 0 {
+		return fmt.Errorf("%s is not a valid kubeconfig: no cluster found", v1alpha3.KubeConfigSecretKey)
+	}
+	return nil
+}
+
+func dockerRegistryCredentialValidator(secret *v1.Secret) error {
+	server := string(secret.Data[v1alpha3.DockerRegistryServerKey])
+	if server == "" {
+		return requiredError(v1alpha3.DockerRegistryServerKey)
+	}
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	if registryURL, err := url.Parse(server); err != nil || registryURL.Host == "" {
+		return fmt.Errorf("%s is not a valid registry address", v1alpha3.DockerRegistryServerKey)
+	}
+	if len(secret.Data[v1alpha3.DockerRegistryUsernameKey]) == 0 {
+		return requiredError(v1alpha3.DockerRegistryUsernameKey)
+	}
+	if len(secret.Data[v1alpha3.DockerRegistryPasswordKey]) == 0 {
+		return requiredError(v1alpha3.DockerRegistryPasswordKey)
+	}
+	return nil
+}
+
+func bearerTokenCredentialValidator(secret *v1.Secret) error {
+	token := string(secret.Data[v1alpha3.BearerTokenKey])
+	if token == "" {
+		return requiredError(v1alpha3.BearerTokenKey)
+	}
+	if strings.ContainsAny(token, " \t\r\n") {
+		return fmt.Errorf("%s should not contain whitespaces", v1alpha3.BearerTokenKey)
+	}
+	return nil
+}
+
+func requiredError(key string) error {
+	return fmt.Errorf("%s is required", key)
+}
+
+// IsExternalCredential returns true if the data of the credential is materialized by an ExternalSecret.
+func IsExternalCredential(secret *v1.Secret) bool {
+	return secret != nil && secret.Annotations[v1alpha3.CredentialExternalSecretAnnoKey] != ""
+}
+
+// ValidateCredential validates data inside credential according to its type.
+// The secrets which are not DevOps credentials will be ignored.
+// The data of an external credential is allowed to be empty until the ExternalSecret materializes it.
+func ValidateCredential(secret *v1.Secret) error {
+	if secret == nil || !strings.HasPrefix(string(secret.Type), v1alpha3.DevOpsCredentialPrefix) {
+		return nil
+	}
+	if IsExternalCredential(secret) && len(secret.Data) == 0 && len(secret.StringData) == 0 {
+		if credentialValidatorHolder[secret.Type] == nil {
+			return fmt.Errorf("unsupported credential type: %s", secret.Type)
+		}
+		return nil
+	}
+	credentialValidator := credentialValidatorHolder[secret.Type]
+	if credentialValidator == nil {
+		return fmt.Errorf("unsupported credential type: %s", secret.Type)
+	}
+	if len(secret.StringData) > 0 {
+		// the stringData will be merged into the data when writing the secret
+		secret = secret.DeepCopy()
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for key, val := range secret.StringData {
+			secret.Data[key] = []byte(val)
+		}
+	}
+	if err := credentialValidator(secret); err != nil {
+		return fmt.Errorf("invalid %s credential: %v", strings.TrimPrefix(string(secret.Type), v1alpha3.DevOpsCredentialPrefix), err)
+	}
+	return nil
+}
+
+func init() {
+	// register credential validators
+	credentialValidatorHolder[v1alpha3.SecretTypeBasicAuth] = basicAuthCredentialValidator
+	credentialValidatorHolder[v1alpha3.SecretTypeSSHAuth] = sshAuthCredentialValidator
+	credentialValidatorHolder[v1alpha3.SecretTypeSecretText] = secretTextCredentialValidator
+	credentialValidatorHolder[v1alpha3.SecretTypeKubeConfig] = kubeconfigCredentialValidator
+	credentialValidatorHolder[v1alpha3.SecretTypeDockerRegistry] = dockerRegistryCredentialValidator
+	credentialValidatorHolder[v1alpha3.SecretTypeBearerToken] = bearerTokenCredentialValidator
+}
@@ -0,0 +1,204 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package framework runs the controllers against a Kubernetes API server of envtest and a real Jenkins,
+// so the e2e tests are able to exercise the whole lifecycle of the DevOps resources.
+package framework
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/klogr"
+	"kubesphere.io/devops/controllers/jenkins/devopscredential"
+	"kubesphere.io/devops/controllers/jenkins/devopsproject"
+	jenkinspipeline "kubesphere.io/devops/controllers/jenkins/pipeline"
+	"kubesphere.io/devops/controllers/jenkins/pipelinerun"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/jclient"
+	"kubesphere.io/devops/pkg/client/devops/jenkins"
+	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/indexers"
+	"kubesphere.io/devops/pkg/informers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// DefaultTimeout is the timeout of waiting for the resources to be reconciled
+	DefaultTimeout = 5 * time.Minute
+	// pollInterval is the interval of checking the resources
+	pollInterval = 2 * time.Second
+)
+
+// Framework holds the environment of the e2e tests
+type Framework struct {
+	// Client talks to the Kubernetes API server of envtest
+	Client client.Client
+	// DevOpsClient talks to Jenkins, the tests check the Jenkins jobs through it
+	DevOpsClient devops.Interface
+	Jenkins      *Jenkins
+
+	env    *envtest.Environment
+	cancel context.CancelFunc
+}
+
+// Start starts a Jenkins and a Kubernetes API server, then runs the controllers against them.
+// The binaries of envtest are located by KUBEBUILDER_ASSETS, see also https://book.kubebuilder.io/reference/envtest.html
+func Start(ctx context.Context) (f *Framework, err error) {
+	f = &Framework{
+		env: &envtest.Environment{
+			CRDDirectoryPaths:     []string{filepath.Join(rootDir(), "config", "crd", "bases")},
+			ErrorIfCRDPathMissing: true,
+		},
+	}
+	if f.Jenkins, err = StartJenkins(ctx); err != nil {
+		return nil, err
+	}
+
+	var cfg *rest.Config
+	if cfg, err = f.env.Start(); err != nil {
+		_ = f.Jenkins.Stop(ctx)
+		return nil, fmt.Errorf("failed to start envtest: %v", err)
+	}
+	if err = f.startControllers(ctx, cfg); err != nil {
+		_ = f.Stop(ctx)
+		return nil, err
+	}
+	return
+}
+
+// startControllers runs the controllers which talk to Jenkins
+func (f *Framework) startControllers(ctx context.Context, cfg *rest.Config) (err error) {
+	if err = v1alpha3.AddToScheme(scheme.Scheme); err != nil {
+		return
+	}
+	ctrl.SetLogger(klogr.New())
+
+	options := jenkins.NewJenkinsOptions()
+	options.Host = f.Jenkins.URL
+	options.Username = f.Jenkins.Username
+	options.Password = f.Jenkins.Password
+	if f.DevOpsClient, err = jclient.NewJenkinsClient(options); err != nil {
+		return
+	}
+	jenkinsCore := core.JenkinsCore{
+		URL:      f.Jenkins.URL,
+		UserName: f.Jenkins.Username,
+		Token:    f.Jenkins.Password,
+	}
+
+	var kubernetesClient k8s.Client
+	if kubernetesClient, err = k8s.NewKubernetesClientWithConfig(cfg); err != nil {
+		return
+	}
+	var mgr manager.Manager
+	if mgr, err = manager.New(cfg, manager.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"}); err != nil {
+		return
+	}
+	f.Client = mgr.GetClient()
+	if err = indexers.CreateControllerIndexers(mgr.GetCache()); err != nil {
+		return
+	}
+
+	informerFactory := informers.NewInformerFactories(kubernetesClient.Kubernetes(), kubernetesClient.KubeSphere(), nil)
+	k8sInformers := informerFactory.KubernetesSharedInformerFactory()
+	ksInformers := informerFactory.KubeSphereSharedInformerFactory()
+	runnables := []manager.Runnable{
+		devopsproject.NewController(kubernetesClient.Kubernetes(), kubernetesClient.KubeSphere(), f.DevOpsClient,
+			k8sInformers.Core().V1().Namespaces(), ksInformers.Devops().V1alpha3().DevOpsProjects()),
+		jenkinspipeline.NewController(kubernetesClient.Kubernetes(), kubernetesClient.KubeSphere(), f.DevOpsClient,
+			k8sInformers.Core().V1().Namespaces(), ksInformers.Devops().V1alpha3().Pipelines()),
+		devopscredential.NewController(kubernetesClient.Kubernetes(), f.DevOpsClient,
+			k8sInformers.Core().V1().Namespaces(), k8sInformers.Core().V1().Secrets()),
+	}
+	for _, runnable := range runnables {
+		if err = mgr.Add(runnable); err != nil {
+			return
+		}
+	}
+	if err = (&pipelinerun.Reconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		DevOpsClient: f.DevOpsClient,
+		JenkinsCore:  jenkinsCore,
+	}).SetupWithManager(mgr); err != nil {
+		return
+	}
+
+	ctx, f.cancel = context.WithCancel(ctx)
+	informerFactory.Start(ctx.Done())
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			klog.Errorf("failed to run the manager: %v", err)
+		}
+	}()
+	if !mgr.GetCache().WaitForCacheSync(ctx) {
+		return fmt.Errorf("failed to wait for the caches to sync")
+	}
+	return
+}
+
+// Stop stops the controllers, the Kubernetes API server and the Jenkins
+func (f *Framework) Stop(ctx context.Context) error {
+	if f.cancel != nil {
+		f.cancel()
+	}
+	envErr := f.env.Stop()
+	if err := f.Jenkins.Stop(ctx); err != nil {
+		return err
+	}
+	return envErr
+}
+
+// WaitFor waits until the condition is true, the errors of the condition are treated as not ready
+func WaitFor(ctx context.Context, timeout time.Duration, condition func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var lastErr error
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		done, err := condition(ctx)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		return done, nil
+	}, ctx.Done())
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("%v, last error: %v", err, lastErr)
+	}
+	return err
+}
+
+// rootDir returns the root directory of the repository
+func rootDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..")
+}
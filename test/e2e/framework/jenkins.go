@@ -0,0 +1,145 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultJenkinsImage is the image of the Jenkins container, it has the plugins which the controllers depend on
+	DefaultJenkinsImage = "kubesphere/ks-jenkins:2.249.1"
+
+	defaultJenkinsUsername = "admin"
+	defaultJenkinsPassword = "P@88w0rd"
+	defaultJenkinsTimeout  = 10 * time.Minute
+)
+
+// Jenkins is the Jenkins which the e2e tests talk to. It's a container started by the docker CLI, or an existing
+// Jenkins if E2E_JENKINS_URL is set.
+type Jenkins struct {
+	URL      string
+	Username string
+	Password string
+
+	// containerID is the ID of the container, it's empty if the Jenkins was not started by the tests
+	containerID string
+}
+
+// StartJenkins starts a Jenkins container, then waits until it's ready. The following environment variables
+// change how the Jenkins is started:
+//   - E2E_JENKINS_URL: the address of an existing Jenkins, no container is started if it's set
+//   - E2E_JENKINS_USERNAME and E2E_JENKINS_PASSWORD: the credential of the Jenkins admin
+//   - E2E_JENKINS_IMAGE: the image of the Jenkins container
+//   - E2E_JENKINS_TIMEOUT: how long to wait for the Jenkins, such as 10m
+func StartJenkins(ctx context.Context) (jenkins *Jenkins, err error) {
+	jenkins = &Jenkins{
+		URL:      os.Getenv("E2E_JENKINS_URL"),
+		Username: getEnv("E2E_JENKINS_USERNAME", defaultJenkinsUsername),
+		Password: getEnv("E2E_JENKINS_PASSWORD", defaultJenkinsPassword),
+	}
+	timeout := defaultJenkinsTimeout
+	if value := os.Getenv("E2E_JENKINS_TIMEOUT"); value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid E2E_JENKINS_TIMEOUT: %v", err)
+		}
+	}
+
+	if jenkins.URL == "" {
+		image := getEnv("E2E_JENKINS_IMAGE", DefaultJenkinsImage)
+		klog.Infof("starting the Jenkins container of image %s", image)
+		if jenkins.containerID, err = docker(ctx, "run", "-d", "-P", image); err != nil {
+			return nil, err
+		}
+		var address string
+		if address, err = docker(ctx, "port", jenkins.containerID, "8080/tcp"); err != nil {
+			_ = jenkins.Stop(ctx)
+			return nil, err
+		}
+		// the address might be listed for both IPv4 and IPv6
+		jenkins.URL = "http://" + strings.Replace(strings.Fields(address)[0], "0.0.0.0", "127.0.0.1", 1)
+	}
+
+	if err = jenkins.waitForReady(ctx, timeout); err != nil {
+		_ = jenkins.Stop(ctx)
+		return nil, err
+	}
+	return
+}
+
+// waitForReady waits until the Jenkins API is available to the admin
+func (j *Jenkins) waitForReady(ctx context.Context, timeout time.Duration) error {
+	klog.Infof("waiting for Jenkins %s", j.URL)
+	var lastErr error
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.URL+"/api/json", nil)
+		if err != nil {
+			return false, err
+		}
+		req.SetBasicAuth(j.Username, j.Password)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Jenkins %s is not ready: %v, last error: %v", j.URL, err, lastErr)
+	}
+	return nil
+}
+
+// Stop removes the Jenkins container if it was started by the tests
+func (j *Jenkins) Stop(ctx context.Context) (err error) {
+	if j.containerID != "" {
+		_, err = docker(ctx, "rm", "-f", j.containerID)
+	}
+	return
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, getEnv("CONTAINER_CLI", "docker"), args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run docker %s: %v, output: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
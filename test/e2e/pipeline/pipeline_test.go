@@ -0,0 +1,187 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/test/e2e/framework"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var f *framework.Framework
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	var err error
+	if f, err = framework.Start(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start the e2e framework: %v\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	if err = f.Stop(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stop the e2e framework: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// createProject creates a DevOpsProject, then returns its admin namespace once the Jenkins folder was created
+func createProject(ctx context.Context, t *testing.T) string {
+	project := &v1alpha3.DevOpsProject{ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-"}}
+	if !assert.Nil(t, f.Client.Create(ctx, project)) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		_ = f.Client.Delete(context.Background(), project)
+	})
+
+	var namespace string
+	err := framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		if err := f.Client.Get(ctx, types.NamespacedName{Name: project.Name}, project); err != nil {
+			return false, err
+		}
+		namespace = project.Status.AdminNamespace
+		if namespace == "" {
+			return false, nil
+		}
+		_, err := f.DevOpsClient.GetDevOpsProject(namespace)
+		return err == nil, err
+	})
+	if !assert.Nil(t, err, "the Jenkins folder of the DevOpsProject was not created") {
+		t.FailNow()
+	}
+	return namespace
+}
+
+func TestPipelineLifecycle(t *testing.T) {
+	ctx := context.Background()
+	namespace := createProject(ctx, t)
+
+	// the Jenkins job is created once the Pipeline was created
+	pipeline := &v1alpha3.Pipeline{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha3.GroupVersion.String(),
+			Kind:       v1alpha3.ResourceKindPipeline,
+		},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "lifecycle"},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{
+				Name: "lifecycle",
+				Jenkinsfile: `pipeline {
+  agent any
+  stages {
+    stage('greet') {
+      steps {
+        echo 'hello e2e'
+      }
+    }
+  }
+}`,
+			},
+		},
+	}
+	if !assert.Nil(t, f.Client.Create(ctx, pipeline.DeepCopy())) {
+		return
+	}
+	err := framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		current := &v1alpha3.Pipeline{}
+		if err := f.Client.Get(ctx, client.ObjectKeyFromObject(pipeline), current); err != nil {
+			return false, err
+		}
+		if current.Annotations[v1alpha3.PipelineSyncStatusAnnoKey] != constants.StatusSuccessful {
+			return false, fmt.Errorf("the Pipeline is not synced: %s", current.Annotations[v1alpha3.PipelineSyncMsgAnnoKey])
+		}
+		_, err := f.DevOpsClient.GetProjectPipelineConfig(namespace, pipeline.Name)
+		return err == nil, err
+	})
+	if !assert.Nil(t, err, "the Jenkins job of the Pipeline was not created") {
+		return
+	}
+
+	// the build is triggered and synced by the PipelineRun
+	pipelineRun := pipelinerun.CreatePipelineRun(pipeline, nil, nil)
+	if !assert.Nil(t, f.Client.Create(ctx, pipelineRun)) {
+		return
+	}
+	err = framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		if err := f.Client.Get(ctx, client.ObjectKeyFromObject(pipelineRun), pipelineRun); err != nil {
+			return false, err
+		}
+		return pipelineRun.HasCompleted(), nil
+	})
+	if !assert.Nil(t, err, "the PipelineRun did not complete") {
+		return
+	}
+	assert.Equal(t, v1alpha3.Succeeded, pipelineRun.Status.Phase)
+	runID, _ := pipelineRun.GetPipelineRunID()
+	log, err := f.DevOpsClient.GetRunLog(namespace, pipeline.Name, runID, &devops.HttpParameters{})
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(log), "hello e2e"), "unexpected log: %s", log)
+
+	// the Jenkins job is removed once the Pipeline was deleted
+	assert.Nil(t, f.Client.Delete(ctx, pipeline))
+	err = framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		_, err := f.DevOpsClient.GetProjectPipelineConfig(namespace, pipeline.Name)
+		return devops.IsNotFound(err), nil
+	})
+	assert.Nil(t, err, "the Jenkins job of the Pipeline was not removed")
+}
+
+func TestCredentialLifecycle(t *testing.T) {
+	ctx := context.Background()
+	namespace := createProject(ctx, t)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "basic"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+		StringData: map[string]string{
+			v1.BasicAuthUsernameKey: "e2e",
+			v1.BasicAuthPasswordKey: "e2e",
+		},
+	}
+	if !assert.Nil(t, f.Client.Create(ctx, secret)) {
+		return
+	}
+	err := framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		_, err := f.DevOpsClient.GetCredentialInProject(namespace, secret.Name)
+		return err == nil, err
+	})
+	assert.Nil(t, err, "the Jenkins credential was not created")
+
+	assert.Nil(t, f.Client.Delete(ctx, secret))
+	err = framework.WaitFor(ctx, framework.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		_, err := f.DevOpsClient.GetCredentialInProject(namespace, secret.Name)
+		return devops.IsNotFound(err), nil
+	})
+	assert.Nil(t, err, "the Jenkins credential was not removed")
+}